@@ -0,0 +1,260 @@
+// Package audit keeps the trail of operations that change assessments: who
+// created, edited or published a test, submitted, restored or deleted an
+// answer, changed a grade or extended a deadline, and when, including what
+// the service does on its own such as autograding and retention.
+// Administrators query the trail; nothing edits it.
+package audit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/id"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// Jobs of the service named as the principal of the operations they perform.
+const (
+	Autograder = "autograder"
+	Retention  = "retention"
+)
+
+// Log records entries in a repository.
+type Log struct {
+	repo repository.AuditRepository
+	now  func() time.Time
+}
+
+// NewLog creates a log stored in repo.
+func NewLog(repo repository.AuditRepository) *Log {
+	return &Log{repo: repo, now: time.Now}
+}
+
+// Record stores entry, filling in its ID and time when missing.
+func (l *Log) Record(ctx context.Context, entry domain.AuditEntry) error {
+	if entry.ID == "" {
+		entry.ID = domain.AuditEntryID(id.New())
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = l.now().UTC()
+	}
+	return l.repo.SaveAuditEntry(ctx, &entry)
+}
+
+// Query returns one page of the entries matching filter, newest first.
+func (l *Log) Query(ctx context.Context, filter repository.AuditFilter, page repository.PageRequest) (repository.Page[domain.AuditEntry], error) {
+	return l.repo.ListAuditEntries(ctx, filter, page)
+}
+
+// TestCreated describes a teacher creating a test.
+func TestCreated(teacherID domain.TeacherID, test domain.Test) domain.AuditEntry {
+	return byTeacher(teacherID, domain.AuditTestCreated, test.ID, string(test.ID))
+}
+
+// TestPublished describes a teacher publishing a draft test.
+func TestPublished(teacherID domain.TeacherID, test domain.Test) domain.AuditEntry {
+	return byTeacher(teacherID, domain.AuditTestPublished, test.ID, string(test.ID))
+}
+
+// TestUnpublished describes a teacher returning a test to draft.
+func TestUnpublished(teacherID domain.TeacherID, test domain.Test) domain.AuditEntry {
+	return byTeacher(teacherID, domain.AuditTestUnpublished, test.ID, string(test.ID))
+}
+
+// TestUpdated describes a teacher changing a setting of a test, such as its
+// details or its window.
+func TestUpdated(teacherID domain.TeacherID, test domain.Test, setting string) domain.AuditEntry {
+	entry := byTeacher(teacherID, domain.AuditTestUpdated, test.ID, string(test.ID))
+	entry.Reason = setting
+	return entry
+}
+
+// QuestionUpdated describes a teacher editing a question of a draft test.
+func QuestionUpdated(teacherID domain.TeacherID, question domain.Question) domain.AuditEntry {
+	return byTeacher(teacherID, domain.AuditQuestionUpdated, question.TestID, string(question.ID))
+}
+
+// QuestionsReordered describes a teacher putting the questions of a draft
+// test in a new order.
+func QuestionsReordered(teacherID domain.TeacherID, testID domain.TestID) domain.AuditEntry {
+	return byTeacher(teacherID, domain.AuditQuestionsReordered, testID, string(testID))
+}
+
+// AssigneeAdded describes a teacher assigning a test to a student.
+func AssigneeAdded(teacherID domain.TeacherID, testID domain.TestID, studentID domain.StudentID) domain.AuditEntry {
+	entry := byTeacher(teacherID, domain.AuditAssigneeAdded, testID, string(testID))
+	entry.StudentID = studentID
+	return entry
+}
+
+// AssigneeRemoved describes a teacher unassigning a test from a student.
+func AssigneeRemoved(teacherID domain.TeacherID, testID domain.TestID, studentID domain.StudentID) domain.AuditEntry {
+	entry := byTeacher(teacherID, domain.AuditAssigneeRemoved, testID, string(testID))
+	entry.StudentID = studentID
+	return entry
+}
+
+// CurveApplied describes a teacher applying a curve to every result of a
+// test.
+func CurveApplied(teacherID domain.TeacherID, test domain.Test) domain.AuditEntry {
+	entry := byTeacher(teacherID, domain.AuditCurveApplied, test.ID, string(test.ID))
+	if test.Curve != nil {
+		entry.Reason = string(test.Curve.Kind)
+	}
+	return entry
+}
+
+// CurveReverted describes a teacher restoring the graded scores of a test.
+func CurveReverted(teacherID domain.TeacherID, test domain.Test) domain.AuditEntry {
+	return byTeacher(teacherID, domain.AuditCurveReverted, test.ID, string(test.ID))
+}
+
+// AnswerSubmitted describes a student submitting or revising an answer.
+func AnswerSubmitted(answer domain.Answer) domain.AuditEntry {
+	return domain.AuditEntry{
+		Action:        domain.AuditAnswerSubmitted,
+		PrincipalRole: domain.RoleStudent,
+		PrincipalID:   string(answer.StudentID),
+		TestID:        answer.TestID,
+		StudentID:     answer.StudentID,
+		Subject:       string(answer.ID),
+	}
+}
+
+// AnswerImported describes a teacher importing a student's answer.
+func AnswerImported(teacherID domain.TeacherID, answer domain.Answer) domain.AuditEntry {
+	entry := byTeacher(teacherID, domain.AuditAnswerImported, answer.TestID, string(answer.ID))
+	entry.StudentID = answer.StudentID
+	return entry
+}
+
+// AnswerRestored describes a student restoring an earlier revision of an
+// answer.
+func AnswerRestored(answer domain.Answer, revision int) domain.AuditEntry {
+	entry := AnswerSubmitted(answer)
+	entry.Action = domain.AuditAnswerRestored
+	entry.Reason = fmt.Sprintf("revision %d", revision)
+	return entry
+}
+
+// AnswerAutograded describes the autograder scoring an answer. previous is
+// the answer's score before, nil when it was not graded yet.
+func AnswerAutograded(answer domain.Answer, previous *domain.Score, score domain.Score) domain.AuditEntry {
+	entry := GradeChanged("", answer, previous, score)
+	entry.Action = domain.AuditAnswerAutograded
+	entry.PrincipalRole, entry.PrincipalID = "", Autograder
+	return entry
+}
+
+// AnswerErased describes retention deleting an answer and its result.
+func AnswerErased(answer domain.Answer) domain.AuditEntry {
+	entry := byService(Retention, domain.AuditAnswerErased, answer.TestID, string(answer.ID))
+	entry.StudentID = answer.StudentID
+	return entry
+}
+
+// AnswerPurged describes retention erasing the response and note of an
+// answer.
+func AnswerPurged(answer domain.Answer) domain.AuditEntry {
+	entry := byService(Retention, domain.AuditAnswerPurged, answer.TestID, string(answer.ID))
+	entry.StudentID = answer.StudentID
+	return entry
+}
+
+// ResultReleased describes a teacher releasing a result held for review.
+func ResultReleased(teacherID domain.TeacherID, testID domain.TestID, studentID domain.StudentID, result domain.Result) domain.AuditEntry {
+	entry := byTeacher(teacherID, domain.AuditResultReleased, testID, string(result.ID))
+	entry.StudentID = studentID
+	score := result.Score
+	entry.NewScore = &score
+	return entry
+}
+
+// DelegationGranted describes a teacher handing their tests to a delegate.
+func DelegationGranted(delegation domain.Delegation) domain.AuditEntry {
+	entry := byTeacher(delegation.TeacherID, domain.AuditDelegationGranted, "", string(delegation.ID))
+	entry.Reason = fmt.Sprintf("to %s until %s", delegation.DelegateID, delegation.ExpiresAt.Format(time.RFC3339))
+	return entry
+}
+
+// DelegationRevoked describes a teacher ending a delegation early.
+func DelegationRevoked(delegation domain.Delegation) domain.AuditEntry {
+	entry := byTeacher(delegation.TeacherID, domain.AuditDelegationRevoked, "", string(delegation.ID))
+	entry.Reason = fmt.Sprintf("to %s", delegation.DelegateID)
+	return entry
+}
+
+// AnswerDeleted describes a teacher deleting a student's answer.
+func AnswerDeleted(teacherID domain.TeacherID, answer domain.Answer) domain.AuditEntry {
+	entry := byTeacher(teacherID, domain.AuditAnswerDeleted, answer.TestID, string(answer.ID))
+	entry.StudentID = answer.StudentID
+	return entry
+}
+
+// GradeChanged describes a teacher grading an answer. previous is the
+// answer's score before the change, nil when it was not graded yet.
+func GradeChanged(teacherID domain.TeacherID, answer domain.Answer, previous *domain.Score, score domain.Score) domain.AuditEntry {
+	entry := byTeacher(teacherID, domain.AuditGradeChanged, answer.TestID, string(answer.ID))
+	entry.StudentID = answer.StudentID
+	if previous != nil {
+		old := *previous
+		entry.OldScore = &old
+	}
+	entry.NewScore = &score
+	return entry
+}
+
+// TestExtended describes a teacher moving a test's close time from previous
+// to closesAt for every student.
+func TestExtended(teacherID domain.TeacherID, testID domain.TestID, previous, closesAt time.Time, reason string) domain.AuditEntry {
+	entry := byTeacher(teacherID, domain.AuditTestExtended, testID, string(testID))
+	entry.OldClosesAt = &previous
+	entry.NewClosesAt = &closesAt
+	entry.Reason = reason
+	return entry
+}
+
+// ExtensionGranted describes a teacher giving one student longer to sit a
+// test. previous is the extension it replaces, if any.
+func ExtensionGranted(test domain.Test, ext domain.DeadlineExtension, previous *domain.DeadlineExtension) domain.AuditEntry {
+	entry := byTeacher(ext.GrantedBy, domain.AuditExtensionGranted, test.ID, string(test.ID))
+	entry.StudentID = ext.StudentID
+	if previous != nil {
+		old := previous.ClosesAt
+		entry.OldClosesAt = &old
+	}
+	closes := ext.ClosesAt
+	entry.NewClosesAt = &closes
+	entry.Reason = ext.Reason
+	return entry
+}
+
+// ExtensionRevoked describes a teacher withdrawing a student's extension.
+func ExtensionRevoked(teacherID domain.TeacherID, test domain.Test, ext domain.DeadlineExtension) domain.AuditEntry {
+	entry := byTeacher(teacherID, domain.AuditExtensionRevoked, test.ID, string(test.ID))
+	entry.StudentID = ext.StudentID
+	old := ext.ClosesAt
+	entry.OldClosesAt = &old
+	return entry
+}
+
+func byService(job string, action domain.AuditAction, testID domain.TestID, subject string) domain.AuditEntry {
+	return domain.AuditEntry{
+		Action:      action,
+		PrincipalID: job,
+		TestID:      testID,
+		Subject:     subject,
+	}
+}
+
+func byTeacher(teacherID domain.TeacherID, action domain.AuditAction, testID domain.TestID, subject string) domain.AuditEntry {
+	return domain.AuditEntry{
+		Action:        action,
+		PrincipalRole: domain.RoleTeacher,
+		PrincipalID:   string(teacherID),
+		TestID:        testID,
+		Subject:       subject,
+	}
+}
@@ -0,0 +1,153 @@
+package audit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/audit"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestLogRecordsAssessmentChanges(t *testing.T) {
+	fx := fixtures.NewSchool().Build()
+	log := audit.NewLog(fx.Repo)
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	service.SetAuditLog(log)
+	ctx := context.Background()
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Quiz",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "Q1", Points: 10}},
+		StudentIDs: []domain.StudentID{fx.Student(0)},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	answer, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: fx.Student(0), Response: "x"})
+	if err != nil {
+		t.Fatalf("SubmitAnswer failed: %v", err)
+	}
+	for _, score := range []domain.Score{4, 7} {
+		if _, err := service.GradeAnswer(ctx, usecase.GradeInput{TeacherID: fx.Teacher(0), TestID: test.ID, QuestionID: questions[0].ID, StudentID: fx.Student(0), Score: score}); err != nil {
+			t.Fatalf("GradeAnswer failed: %v", err)
+		}
+	}
+
+	all, err := log.Query(ctx, repository.AuditFilter{TestID: test.ID}, repository.All)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(all.Items) != 4 {
+		t.Fatalf("expected 4 entries, got %+v", all.Items)
+	}
+
+	grades, err := log.Query(ctx, repository.AuditFilter{Action: domain.AuditGradeChanged}, repository.PageRequest{Limit: 1})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	latest := grades.Items[0]
+	if latest.PrincipalID != string(fx.Teacher(0)) || latest.Subject != string(answer.ID) ||
+		latest.OldScore == nil || *latest.OldScore != 4 || *latest.NewScore != 7 || grades.NextCursor == "" {
+		t.Fatalf("expected the regrade from 4 to 7 first, got %+v", grades)
+	}
+	first, err := log.Query(ctx, repository.AuditFilter{Action: domain.AuditGradeChanged}, repository.PageRequest{Limit: 1, Cursor: grades.NextCursor})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if entry := first.Items[0]; entry.OldScore != nil || *entry.NewScore != 4 {
+		t.Fatalf("expected the first grade without an old score, got %+v", entry)
+	}
+
+	submitted, err := log.Query(ctx, repository.AuditFilter{PrincipalID: string(fx.Student(0))}, repository.All)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(submitted.Items) != 1 || submitted.Items[0].Action != domain.AuditAnswerSubmitted || submitted.Items[0].PrincipalRole != domain.RoleStudent {
+		t.Fatalf("expected the student's submission, got %+v", submitted.Items)
+	}
+}
+
+func TestLogRecordsEditsCurvesAndDelegations(t *testing.T) {
+	fx := fixtures.NewSchool().WithStudents(2).WithTeachers(2).Build()
+	log := audit.NewLog(fx.Repo)
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	service.SetAuditLog(log)
+	delegations := usecase.NewDelegationService(fx.Repo, fx.Repo, fx.Repo)
+	delegations.SetAuditLog(log)
+	ctx := context.Background()
+	teacher := fx.Teacher(0)
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Quiz",
+		TeacherID:  teacher,
+		Questions:  []usecase.QuestionDraft{{Prompt: "Q1", Points: 10}, {Prompt: "Q2", Points: 10}},
+		StudentIDs: []domain.StudentID{fx.Student(0)},
+		Draft:      true,
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	if _, err := service.ReorderQuestions(ctx, teacher, test.ID, []domain.QuestionID{questions[1].ID, questions[0].ID}); err != nil {
+		t.Fatalf("ReorderQuestions failed: %v", err)
+	}
+	if _, err := service.UpdateAssignees(ctx, teacher, test.ID, usecase.AssigneeChange{AddStudentIDs: []domain.StudentID{fx.Student(1)}}); err != nil {
+		t.Fatalf("UpdateAssignees failed: %v", err)
+	}
+	passing := domain.Score(10)
+	if _, err := service.SetPassingScore(ctx, teacher, test.ID, &passing); err != nil {
+		t.Fatalf("SetPassingScore failed: %v", err)
+	}
+	if _, err := service.PublishTest(ctx, teacher, test.ID); err != nil {
+		t.Fatalf("PublishTest failed: %v", err)
+	}
+	if _, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: fx.Student(0), Response: "x"}); err != nil {
+		t.Fatalf("SubmitAnswer failed: %v", err)
+	}
+	if _, err := service.GradeAnswer(ctx, usecase.GradeInput{TeacherID: teacher, TestID: test.ID, QuestionID: questions[0].ID, StudentID: fx.Student(0), Score: 4}); err != nil {
+		t.Fatalf("GradeAnswer failed: %v", err)
+	}
+	if _, err := service.ApplyCurve(ctx, usecase.CurveInput{TeacherID: teacher, TestID: test.ID, Kind: domain.CurveAdd, Points: 2}); err != nil {
+		t.Fatalf("ApplyCurve failed: %v", err)
+	}
+	if _, err := service.RevertCurve(ctx, teacher, test.ID); err != nil {
+		t.Fatalf("RevertCurve failed: %v", err)
+	}
+	delegation, err := delegations.Grant(ctx, usecase.DelegationInput{TeacherID: teacher, DelegateID: fx.Teacher(1), ExpiresAt: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("Grant failed: %v", err)
+	}
+	if err := delegations.Revoke(ctx, teacher, delegation.ID); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+
+	for _, c := range []struct {
+		action  domain.AuditAction
+		subject string
+		reason  string
+	}{
+		{domain.AuditQuestionsReordered, string(test.ID), ""},
+		{domain.AuditAssigneeAdded, string(test.ID), ""},
+		{domain.AuditTestUpdated, string(test.ID), "passing score"},
+		{domain.AuditCurveApplied, string(test.ID), string(domain.CurveAdd)},
+		{domain.AuditCurveReverted, string(test.ID), ""},
+		{domain.AuditDelegationGranted, string(delegation.ID), ""},
+		{domain.AuditDelegationRevoked, string(delegation.ID), ""},
+	} {
+		found, err := log.Query(ctx, repository.AuditFilter{Action: c.action}, repository.All)
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		if len(found.Items) != 1 {
+			t.Fatalf("expected one %s entry, got %+v", c.action, found.Items)
+		}
+		entry := found.Items[0]
+		if entry.PrincipalID != string(teacher) || entry.Subject != c.subject || c.reason != "" && entry.Reason != c.reason {
+			t.Fatalf("unexpected %s entry %+v", c.action, entry)
+		}
+	}
+}
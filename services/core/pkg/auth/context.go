@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+type principalKey struct{}
+
+type tokenIDKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the authenticated principal.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the principal stored in ctx, if any.
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// WithTokenID returns a copy of ctx carrying the ID of the access token the
+// request was authenticated with.
+func WithTokenID(ctx context.Context, tokenID string) context.Context {
+	return context.WithValue(ctx, tokenIDKey{}, tokenID)
+}
+
+// TokenIDFrom returns the access token ID stored in ctx, or "" when the
+// request was not authenticated with an access token.
+func TokenIDFrom(ctx context.Context) string {
+	tokenID, _ := ctx.Value(tokenIDKey{}).(string)
+	return tokenID
+}
+
+// IsTeacher reports whether the request in ctx was authenticated as the
+// teacher. Handlers use it to check the {teacherID} of a path.
+func IsTeacher(ctx context.Context, teacherID domain.TeacherID) bool {
+	p, ok := PrincipalFrom(ctx)
+	return ok && p.Role == domain.RoleTeacher && p.ID == string(teacherID)
+}
+
+// IsGuardianOf reports whether the request in ctx was authenticated as a
+// guardian of the student.
+func IsGuardianOf(ctx context.Context, studentID domain.StudentID) bool {
+	p, ok := PrincipalFrom(ctx)
+	return ok && p.Role == domain.RoleGuardian && p.ID == string(studentID)
+}
+
+// IsStudent reports whether the request in ctx was authenticated as the
+// student. Handlers use it to check the {studentID} of a path.
+func IsStudent(ctx context.Context, studentID domain.StudentID) bool {
+	p, ok := PrincipalFrom(ctx)
+	return ok && p.Role == domain.RoleStudent && p.ID == string(studentID)
+}
@@ -0,0 +1,100 @@
+// Package auth issues and verifies the JWTs that identify the teacher or
+// student behind a request, and carries that identity through contexts.
+package auth
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/hmactoken"
+	"github.com/sky0621/go_work_sample/core/pkg/id"
+)
+
+var (
+	// ErrInvalidToken is returned for malformed, tampered or expired tokens.
+	ErrInvalidToken = errors.New("invalid access token")
+	// ErrInvalidPrincipal is returned when issuing a token for an unknown
+	// role or an empty subject.
+	ErrInvalidPrincipal = errors.New("invalid token principal")
+)
+
+// Principal is the authenticated user behind a request.
+type Principal struct {
+	Role domain.Role
+	ID   string
+}
+
+// Valid reports whether the principal names a known role and a subject.
+func (p Principal) Valid() bool {
+	switch p.Role {
+	case domain.RoleTeacher, domain.RoleStudent, domain.RoleDistrictStaff, domain.RoleGuardian:
+		return strings.TrimSpace(p.ID) != ""
+	}
+	return false
+}
+
+// Claims are the registered and custom JWT claims of an access token. Times
+// are seconds since the Unix epoch as the JWT spec requires.
+type Claims struct {
+	TokenID   string      `json:"jti"`
+	Subject   string      `json:"sub"`
+	Role      domain.Role `json:"role"`
+	IssuedAt  int64       `json:"iat"`
+	ExpiresAt int64       `json:"exp"`
+}
+
+// Principal returns the user the claims identify.
+func (c Claims) Principal() Principal {
+	return Principal{Role: c.Role, ID: c.Subject}
+}
+
+// Expiry returns ExpiresAt as a time.
+func (c Claims) Expiry() time.Time {
+	return time.Unix(c.ExpiresAt, 0).UTC()
+}
+
+// Signer issues and verifies HS256-signed access tokens.
+type Signer struct {
+	tokens *hmactoken.Signer
+	now    func() time.Time
+}
+
+// NewSigner creates a signer using secret.
+func NewSigner(secret string) *Signer {
+	return &Signer{tokens: hmactoken.NewSigner(secret, hmactoken.TypeAccess), now: func() time.Time { return time.Now().UTC() }}
+}
+
+// Issue creates a token for the principal, valid for ttl.
+func (s *Signer) Issue(p Principal, ttl time.Duration) (string, Claims, error) {
+	if !p.Valid() {
+		return "", Claims{}, ErrInvalidPrincipal
+	}
+	now := s.now()
+	claims := Claims{
+		TokenID:   id.New(),
+		Subject:   p.ID,
+		Role:      p.Role,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+	}
+	token, err := s.tokens.Sign(claims)
+	if err != nil {
+		return "", Claims{}, err
+	}
+	return token, claims, nil
+}
+
+// Verify checks the header, signature and expiry of token and returns its
+// claims.
+func (s *Signer) Verify(token string) (Claims, error) {
+	var claims Claims
+	if err := s.tokens.Verify(token, &claims); err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	if !claims.Principal().Valid() || s.now().Unix() >= claims.ExpiresAt {
+		return Claims{}, ErrInvalidToken
+	}
+	return claims, nil
+}
@@ -0,0 +1,83 @@
+package auth_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+func TestSignerRoundTrip(t *testing.T) {
+	signer := auth.NewSigner("secret")
+	principal := auth.Principal{Role: domain.RoleTeacher, ID: "teacher-001"}
+
+	token, issued, err := signer.Issue(principal, time.Hour)
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+	claims, err := signer.Verify(token)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if claims != issued || claims.Principal() != principal {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+}
+
+func TestSignerRejectsInvalidTokens(t *testing.T) {
+	signer := auth.NewSigner("secret")
+	principal := auth.Principal{Role: domain.RoleStudent, ID: "student-001"}
+
+	token, _, err := signer.Issue(principal, time.Hour)
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+	expired, _, err := signer.Issue(principal, -time.Minute)
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+	foreign, _, err := auth.NewSigner("other").Issue(principal, time.Hour)
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+	parts := strings.Split(token, ".")
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`)) + "." + parts[1] + "."
+
+	for name, tok := range map[string]string{
+		"expired":   expired,
+		"foreign":   foreign,
+		"tampered":  token + "x",
+		"alg none":  unsigned,
+		"malformed": "not-a-token",
+	} {
+		if _, err := signer.Verify(tok); !errors.Is(err, auth.ErrInvalidToken) {
+			t.Fatalf("%s: expected ErrInvalidToken, got %v", name, err)
+		}
+	}
+
+	if _, _, err := signer.Issue(auth.Principal{Role: "admin", ID: "x"}, time.Hour); !errors.Is(err, auth.ErrInvalidPrincipal) {
+		t.Fatalf("expected ErrInvalidPrincipal, got %v", err)
+	}
+}
+
+func TestPrincipalContextHelpers(t *testing.T) {
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{Role: domain.RoleTeacher, ID: "teacher-001"})
+
+	if !auth.IsTeacher(ctx, "teacher-001") {
+		t.Fatalf("expected principal to match its own teacher ID")
+	}
+	if auth.IsTeacher(ctx, "teacher-002") {
+		t.Fatalf("expected principal not to match another teacher")
+	}
+	if auth.IsStudent(ctx, "teacher-001") {
+		t.Fatalf("expected a teacher principal not to match a student ID")
+	}
+	if auth.IsTeacher(context.Background(), "teacher-001") {
+		t.Fatalf("expected unauthenticated context not to match")
+	}
+}
@@ -0,0 +1,231 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/health"
+)
+
+const (
+	filePrefix = "state-"
+	fileSuffix = ".bak"
+	timeLayout = "20060102T150405.000000000Z"
+)
+
+// Source produces a point-in-time copy of a data store. The file backend
+// streams its JSON state; SQL backends are expected to stream a dump.
+type Source interface {
+	Snapshot(ctx context.Context, w io.Writer) error
+}
+
+// Info describes a stored backup.
+type Info struct {
+	Name      string
+	Size      int64
+	CreatedAt time.Time
+}
+
+// Manager writes backups into a directory and prunes old ones.
+type Manager struct {
+	mu         sync.Mutex
+	dir        string
+	restoreDir string
+	retention  int
+	source     Source
+	now        func() time.Time
+}
+
+// NewManager creates a manager storing backups under dir and keeping at most
+// retention of them. Backups are only restored into restoreDir.
+func NewManager(dir, restoreDir string, retention int, source Source) (*Manager, error) {
+	if dir == "" {
+		return nil, errors.New("backup: dir must be provided")
+	}
+	if restoreDir == "" {
+		return nil, errors.New("backup: restore dir must be provided")
+	}
+	if retention < 1 {
+		return nil, errors.New("backup: retention must be positive")
+	}
+	if source == nil {
+		return nil, errors.New("backup: source must be provided")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Manager{
+		dir:        dir,
+		restoreDir: restoreDir,
+		retention:  retention,
+		source:     source,
+		now:        func() time.Time { return time.Now().UTC() },
+	}, nil
+}
+
+// Create takes a backup now and applies the retention policy.
+func (m *Manager) Create(ctx context.Context) (Info, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	createdAt := m.now()
+	name := filePrefix + createdAt.Format(timeLayout) + fileSuffix
+	path := filepath.Join(m.dir, name)
+	tmp := path + ".tmp"
+
+	file, err := os.Create(tmp)
+	if err != nil {
+		return Info{}, err
+	}
+	if err := m.source.Snapshot(ctx, file); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return Info{}, err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return Info{}, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return Info{}, err
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return Info{}, err
+	}
+
+	if err := m.prune(); err != nil {
+		return Info{}, err
+	}
+
+	return Info{Name: name, Size: stat.Size(), CreatedAt: createdAt}, nil
+}
+
+// List returns stored backups, newest first.
+func (m *Manager) List() ([]Info, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.list()
+}
+
+// Restore copies the named backup to dst, a path relative to the restore
+// directory; absolute paths and paths leaving the directory are refused. The
+// destination must not exist so a restore never overwrites live data.
+func (m *Manager) Restore(name, dst string) error {
+	if !validName(name) {
+		return errs.ErrBackupNotFound
+	}
+	if dst == "" || !filepath.IsLocal(dst) {
+		return errs.ErrInvalidRestoreTarget
+	}
+	dst = filepath.Join(m.restoreDir, dst)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	src, err := os.Open(filepath.Join(m.dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return errs.ErrBackupNotFound
+	}
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		return errs.ErrInvalidRestoreTarget
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, src); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
+
+// Run takes a backup every interval until ctx is cancelled.
+func (m *Manager) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := m.Create(ctx)
+			health.Report(ctx, err)
+			if err != nil {
+				log.Printf("backup failed: %v", err)
+				continue
+			}
+			log.Printf("backup written: %s (%d bytes)", info.Name, info.Size)
+		}
+	}
+}
+
+func (m *Manager) list() ([]Info, error) {
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	backups := make([]Info, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !validName(entry.Name()) {
+			continue
+		}
+		createdAt, err := time.Parse(timeLayout, strings.TrimSuffix(strings.TrimPrefix(entry.Name(), filePrefix), fileSuffix))
+		if err != nil {
+			continue
+		}
+		stat, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, Info{Name: entry.Name(), Size: stat.Size(), CreatedAt: createdAt})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+
+	return backups, nil
+}
+
+func (m *Manager) prune() error {
+	backups, err := m.list()
+	if err != nil {
+		return err
+	}
+	for i := m.retention; i < len(backups); i++ {
+		if err := os.Remove(filepath.Join(m.dir, backups[i].Name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func validName(name string) bool {
+	return strings.HasPrefix(name, filePrefix) &&
+		strings.HasSuffix(name, fileSuffix) &&
+		!strings.ContainsAny(name, `/\`)
+}
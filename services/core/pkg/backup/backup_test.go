@@ -0,0 +1,80 @@
+package backup_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/backup"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/memory"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+	"github.com/sky0621/go_work_sample/core/pkg/storage/filedb"
+)
+
+func TestManagerRetentionAndRestore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	repo, err := filedb.NewRepository(filepath.Join(dir, "state.json"), fixtures.NewSchool().Seed())
+	if err != nil {
+		t.Fatalf("NewRepository failed: %v", err)
+	}
+
+	manager, err := backup.NewManager(filepath.Join(dir, "backups"), filepath.Join(dir, "restores"), 2, repo)
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := manager.Create(ctx); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	backups, err := manager.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected retention to keep 2 backups, got %d", len(backups))
+	}
+
+	restored := filepath.Join("restored", "state.json")
+	if err := manager.Restore(backups[0].Name, restored); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	reloaded, err := filedb.NewRepository(filepath.Join(dir, "restores", restored), memory.SeedData{})
+	if err != nil {
+		t.Fatalf("loading restored state failed: %v", err)
+	}
+	schools, err := repository.Collect(reloaded.ListSchools(ctx, repository.All))
+	if err != nil {
+		t.Fatalf("ListSchools failed: %v", err)
+	}
+	if len(schools) != 1 {
+		t.Fatalf("expected restored state to contain seed school, got %d", len(schools))
+	}
+
+	if err := manager.Restore(backups[0].Name, restored); !errors.Is(err, errs.ErrInvalidRestoreTarget) {
+		t.Fatalf("expected restore onto existing path to fail, got %v", err)
+	}
+	if err := manager.Restore("../state.json", filepath.Join(dir, "other.json")); !errors.Is(err, errs.ErrBackupNotFound) {
+		t.Fatalf("expected unknown backup to be rejected, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "other.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected no file to be written for rejected restore")
+	}
+
+	for _, escaping := range []string{"../other.json", "restored/../../other.json", filepath.Join(dir, "other.json"), ""} {
+		if err := manager.Restore(backups[0].Name, escaping); !errors.Is(err, errs.ErrInvalidRestoreTarget) {
+			t.Fatalf("expected restore to %q outside the restore dir to be refused, got %v", escaping, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "other.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected no file to be written outside the restore dir")
+	}
+}
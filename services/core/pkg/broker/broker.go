@@ -0,0 +1,276 @@
+// Package broker publishes the domain events recorded by the assessment
+// workflow to downstream systems, such as analytics or notification
+// services, through pluggable publishers: in-process subscribers, NATS or
+// Kafka. Use cases only enqueue events; a background worker publishes them.
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/health"
+	"github.com/sky0621/go_work_sample/core/pkg/id"
+)
+
+// Event types, as published in Message.Type.
+const (
+	TypeTestCreated   = "test.created"
+	TypeTestPublished = "test.published"
+	TypeTestChanged   = "test.changed"
+	TypeTestAssignees = "test.assignees_changed"
+	TypeAnswerSaved   = "answer.saved"
+	TypeAnswerDeleted = "answer.deleted"
+	TypeResultSaved   = "result.saved"
+)
+
+// ErrQueueFull is returned when the publisher has fallen too far behind to
+// accept more events.
+var ErrQueueFull = errors.New("event queue is full")
+
+// Message is a domain event ready to publish. Its ID stays the same across
+// retries so consumers can drop duplicates.
+type Message struct {
+	ID         string
+	Type       string
+	TestID     domain.TestID
+	OccurredAt time.Time
+	Event      domain.Event
+}
+
+// NewMessage wraps event for publishing.
+func NewMessage(event domain.Event, now time.Time) (Message, error) {
+	var typ string
+	switch event.(type) {
+	case domain.TestCreated:
+		typ = TypeTestCreated
+	case domain.TestPublished:
+		typ = TypeTestPublished
+	case domain.TestChanged:
+		typ = TypeTestChanged
+	case domain.AssigneesChanged:
+		typ = TypeTestAssignees
+	case domain.AnswerSaved:
+		typ = TypeAnswerSaved
+	case domain.AnswerDeleted:
+		typ = TypeAnswerDeleted
+	case domain.ResultSaved:
+		typ = TypeResultSaved
+	default:
+		return Message{}, fmt.Errorf("broker: unknown event %T", event)
+	}
+	return Message{ID: id.New(), Type: typ, TestID: event.Aggregate(), OccurredAt: now.UTC(), Event: event}, nil
+}
+
+type envelope struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	TestID     string    `json:"test_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+type testData struct {
+	TestID      string   `json:"test_id"`
+	TeacherID   string   `json:"teacher_id"`
+	Title       string   `json:"title"`
+	Published   bool     `json:"published"`
+	StudentIDs  []string `json:"student_ids"`
+	QuestionIDs []string `json:"question_ids,omitempty"`
+}
+
+type assigneesData struct {
+	TestID  string   `json:"test_id"`
+	Added   []string `json:"added_student_ids"`
+	Removed []string `json:"removed_student_ids"`
+}
+
+type answerData struct {
+	AnswerID   string `json:"answer_id,omitempty"`
+	TestID     string `json:"test_id"`
+	QuestionID string `json:"question_id"`
+	StudentID  string `json:"student_id"`
+}
+
+type resultData struct {
+	ResultID   string `json:"result_id"`
+	AnswerID   string `json:"answer_id"`
+	TestID     string `json:"test_id"`
+	QuestionID string `json:"question_id"`
+	StudentID  string `json:"student_id"`
+	Score      int    `json:"score"`
+	Completed  bool   `json:"completed"`
+}
+
+// MarshalJSON encodes the message as the envelope sent to brokers. Answers
+// are identified but their responses are not included.
+func (m Message) MarshalJSON() ([]byte, error) {
+	env := envelope{ID: m.ID, Type: m.Type, TestID: string(m.TestID), OccurredAt: m.OccurredAt}
+	switch e := m.Event.(type) {
+	case domain.TestCreated:
+		data := newTestData(e.Test)
+		for _, q := range e.Questions {
+			data.QuestionIDs = append(data.QuestionIDs, string(q.ID))
+		}
+		env.Data = data
+	case domain.TestPublished:
+		env.Data = newTestData(e.Test)
+	case domain.TestChanged:
+		env.Data = map[string]string{"test_id": string(e.TestID)}
+	case domain.AssigneesChanged:
+		env.Data = assigneesData{TestID: string(e.Test.ID), Added: studentIDStrings(e.Added), Removed: studentIDStrings(e.Removed)}
+	case domain.AnswerSaved:
+		env.Data = answerData{
+			AnswerID:   string(e.Answer.ID),
+			TestID:     string(e.Answer.TestID),
+			QuestionID: string(e.Answer.QuestionID),
+			StudentID:  string(e.Answer.StudentID),
+		}
+	case domain.AnswerDeleted:
+		env.Data = answerData{TestID: string(e.TestID), QuestionID: string(e.QuestionID), StudentID: string(e.StudentID)}
+	case domain.ResultSaved:
+		env.Data = resultData{
+			ResultID:   string(e.Result.ID),
+			AnswerID:   string(e.Result.AnswerID),
+			TestID:     string(e.TestID),
+			QuestionID: string(e.QuestionID),
+			StudentID:  string(e.StudentID),
+			Score:      int(e.Result.Score),
+			Completed:  e.Result.Completed,
+		}
+	}
+	return json.Marshal(env)
+}
+
+func newTestData(test domain.Test) testData {
+	data := testData{
+		TestID:     string(test.ID),
+		TeacherID:  string(test.TeacherID),
+		Title:      test.Title,
+		Published:  test.Published,
+		StudentIDs: studentIDStrings(test.AssignedTo),
+	}
+	return data
+}
+
+func studentIDStrings(ids []domain.StudentID) []string {
+	out := make([]string, len(ids))
+	for i, sid := range ids {
+		out[i] = string(sid)
+	}
+	return out
+}
+
+// Publisher delivers messages to a downstream system.
+type Publisher interface {
+	Publish(ctx context.Context, msg Message) error
+}
+
+// InProcess hands messages to subscribers in the same process, in the
+// order they subscribed.
+type InProcess struct {
+	mu       sync.RWMutex
+	handlers []func(Message)
+}
+
+// NewInProcess creates a publisher without subscribers.
+func NewInProcess() *InProcess {
+	return &InProcess{}
+}
+
+// Subscribe calls handler with every message published from now on.
+func (p *InProcess) Subscribe(handler func(Message)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handlers = append(p.handlers, handler)
+}
+
+func (p *InProcess) Publish(_ context.Context, msg Message) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, handler := range p.handlers {
+		handler(msg)
+	}
+	return nil
+}
+
+// Queue buffers events for a publisher so use cases never wait on the
+// broker. Failed messages are retried a few times and then dropped: events
+// are published at most once, and consumers that cannot lose any rebuild
+// from storage.
+type Queue struct {
+	publisher Publisher
+	pending   chan Message
+	attempts  int
+	delay     time.Duration
+	now       func() time.Time
+}
+
+// NewQueue creates a queue holding up to capacity events for publisher.
+func NewQueue(publisher Publisher, capacity int) *Queue {
+	return &Queue{
+		publisher: publisher,
+		pending:   make(chan Message, capacity),
+		attempts:  3,
+		delay:     time.Second,
+		now:       time.Now,
+	}
+}
+
+// Enqueue schedules event for publishing.
+func (q *Queue) Enqueue(event domain.Event) error {
+	msg, err := NewMessage(event, q.now())
+	if err != nil {
+		return err
+	}
+	select {
+	case q.pending <- msg:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Depth returns the number of events waiting to be published.
+func (q *Queue) Depth() int {
+	return len(q.pending)
+}
+
+// Run publishes queued events until ctx is cancelled.
+func (q *Queue) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-q.pending:
+			err := q.publish(ctx, msg)
+			if err != nil {
+				log.Printf("publish event %s %s: %v", msg.Type, msg.ID, err)
+			}
+			health.Report(ctx, err)
+		}
+	}
+}
+
+func (q *Queue) publish(ctx context.Context, msg Message) error {
+	var err error
+	for attempt := 1; attempt <= q.attempts; attempt++ {
+		if err = q.publisher.Publish(ctx, msg); err == nil {
+			return nil
+		}
+		if attempt == q.attempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(q.delay * time.Duration(attempt)):
+		}
+	}
+	return err
+}
@@ -0,0 +1,173 @@
+package broker_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/broker"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+var graded = domain.ResultSaved{
+	TestID:     "test-1",
+	QuestionID: "q-1",
+	StudentID:  "student-001",
+	Result:     domain.Result{ID: "r-1", AnswerID: "a-1", Score: 4, Completed: true},
+}
+
+func TestQueuePublishesToInProcessSubscribers(t *testing.T) {
+	inProcess := broker.NewInProcess()
+	received := make(chan broker.Message, 2)
+	inProcess.Subscribe(func(msg broker.Message) { received <- msg })
+	queue := broker.NewQueue(inProcess, 1)
+
+	if err := queue.Enqueue(graded); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if err := queue.Enqueue(graded); err != broker.ErrQueueFull {
+		t.Fatalf("expected ErrQueueFull, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go queue.Run(ctx)
+
+	msg := <-received
+	if msg.Type != broker.TypeResultSaved || msg.TestID != "test-1" || msg.ID == "" {
+		t.Fatalf("unexpected message %+v", msg)
+	}
+	if _, ok := msg.Event.(domain.ResultSaved); !ok {
+		t.Fatalf("expected subscribers to receive the domain event, got %T", msg.Event)
+	}
+}
+
+func TestMessageEncodesEnvelope(t *testing.T) {
+	msg, err := broker.NewMessage(graded, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("NewMessage failed: %v", err)
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var env struct {
+		Type       string         `json:"type"`
+		TestID     string         `json:"test_id"`
+		OccurredAt string         `json:"occurred_at"`
+		Data       map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(data, &env); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if env.Type != "result.saved" || env.TestID != "test-1" || env.OccurredAt != "2026-01-02T03:04:05Z" {
+		t.Fatalf("unexpected envelope %s", data)
+	}
+	if env.Data["student_id"] != "student-001" || env.Data["score"] != float64(4) || env.Data["completed"] != true {
+		t.Fatalf("unexpected data %s", data)
+	}
+}
+
+func TestNATSPublishesOnTypedSubjects(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer ln.Close()
+
+	commands := make(chan string, 4)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.WriteString(conn, "INFO {\"server_id\":\"test\"}\r\n")
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimSpace(line)
+			switch {
+			case strings.HasPrefix(line, "PUB "):
+				fields := strings.Fields(line)
+				size, _ := strconv.Atoi(fields[2])
+				payload := make([]byte, size+2)
+				if _, err := io.ReadFull(r, payload); err != nil {
+					return
+				}
+				commands <- fields[1] + " " + string(payload[:size])
+			case line == "PING":
+				_, _ = io.WriteString(conn, "PONG\r\n")
+			case strings.HasPrefix(line, "CONNECT "):
+				commands <- line
+			}
+		}
+	}()
+
+	nats, err := broker.NewNATS("nats://secret-token@"+ln.Addr().String(), "assessment")
+	if err != nil {
+		t.Fatalf("NewNATS failed: %v", err)
+	}
+	defer nats.Close()
+	msg, _ := broker.NewMessage(graded, time.Now())
+	if err := nats.Publish(context.Background(), msg); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	if connect := <-commands; !strings.Contains(connect, `"auth_token":"secret-token"`) {
+		t.Fatalf("expected the token in CONNECT, got %s", connect)
+	}
+	pub := <-commands
+	subject, payload, _ := strings.Cut(pub, " ")
+	if subject != "assessment.result.saved" || !strings.Contains(payload, `"id":"`+msg.ID+`"`) {
+		t.Fatalf("unexpected publish %s", pub)
+	}
+}
+
+func TestKafkaRESTPostsKeyedRecords(t *testing.T) {
+	var body struct {
+		Records []struct {
+			Key   string         `json:"key"`
+			Value map[string]any `json:"value"`
+		} `json:"records"`
+	}
+	var path, contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, contentType = r.URL.Path, r.Header.Get("Content-Type")
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Records[0].Value["type"] == broker.TypeTestChanged {
+			_, _ = io.WriteString(w, `{"offsets":[{"partition":null,"offset":null,"error_code":50002,"error":"topic not found"}]}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"offsets":[{"partition":0,"offset":7,"error_code":null,"error":null}]}`)
+	}))
+	defer server.Close()
+
+	kafka := broker.NewKafkaREST(server.URL+"/", "assessment-events", nil)
+	msg, _ := broker.NewMessage(graded, time.Now())
+	if err := kafka.Publish(context.Background(), msg); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if path != "/topics/assessment-events" || contentType != "application/vnd.kafka.json.v2+json" {
+		t.Fatalf("unexpected request to %s as %s", path, contentType)
+	}
+	if len(body.Records) != 1 || body.Records[0].Key != "test-1" || body.Records[0].Value["id"] != msg.ID {
+		t.Fatalf("unexpected records %+v", body.Records)
+	}
+
+	rejected, _ := broker.NewMessage(domain.TestChanged{TestID: "test-1"}, time.Now())
+	if err := kafka.Publish(context.Background(), rejected); err == nil || !strings.Contains(err.Error(), "topic not found") {
+		t.Fatalf("expected the record error, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package broker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// KafkaREST publishes messages to a Kafka topic through a Kafka REST proxy
+// (the v2 API of Confluent's REST Proxy and compatible gateways). Messages
+// are keyed by test so the events of a test stay in order on one partition.
+type KafkaREST struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewKafkaREST creates a publisher posting to topic on the proxy at baseURL.
+func NewKafkaREST(baseURL, topic string, client *http.Client) *KafkaREST {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &KafkaREST{
+		endpoint: strings.TrimRight(baseURL, "/") + "/topics/" + url.PathEscape(topic),
+		client:   client,
+	}
+}
+
+type kafkaRecord struct {
+	Key   string  `json:"key"`
+	Value Message `json:"value"`
+}
+
+type kafkaResponse struct {
+	Offsets []struct {
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+func (k *KafkaREST) Publish(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(map[string][]kafkaRecord{
+		"records": {{Key: string(msg.TestID), Value: msg}},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("broker: kafka proxy responded %d", resp.StatusCode)
+	}
+
+	// The proxy answers 200 even when the broker rejected a record.
+	var result kafkaResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil
+	}
+	for _, offset := range result.Offsets {
+		if offset.ErrorCode != nil {
+			return fmt.Errorf("broker: kafka error %d: %s", *offset.ErrorCode, offset.Error)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTTLExpiresAndInvalidates(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewTTL[string, int](time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("expected cached value, got %d %v", v, ok)
+	}
+
+	c.Invalidate("a")
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("expected invalidated entry to be gone")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("expected entry to expire after ttl")
+	}
+}
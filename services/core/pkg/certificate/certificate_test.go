@@ -0,0 +1,45 @@
+package certificate_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/certificate"
+)
+
+func TestSignerVerifiesOnlyUntamperedCodes(t *testing.T) {
+	signer := certificate.NewSigner("secret")
+	cert := certificate.Certificate{ID: "c-1", StudentName: "Ada", TestTitle: "Algebra (I)", Score: 9, MaxScore: 10, IssuedAt: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)}
+	code, err := signer.Sign(cert)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	// A code copied off the PDF arrives broken over lines.
+	wrapped := code[:40] + "\n" + code[40:]
+	if got, err := certificate.NewSigner("secret").Verify(wrapped); err != nil || got != cert {
+		t.Fatalf("expected the wrapped code to verify, got %+v, %v", got, err)
+	}
+	if _, err := certificate.NewSigner("other").Verify(code); err != certificate.ErrInvalidCode {
+		t.Fatalf("expected another key to refuse the code, got %v", err)
+	}
+	body, sig, _ := strings.Cut(code, ".")
+	forged, _ := signer.Sign(certificate.Certificate{ID: "c-1", Score: 10, MaxScore: 10})
+	forgedBody, _, _ := strings.Cut(forged, ".")
+	if _, err := signer.Verify(forgedBody + "." + sig); err != certificate.ErrInvalidCode {
+		t.Fatalf("expected an altered certificate to be refused, got %v", err)
+	}
+	if _, err := signer.Verify(body); err != certificate.ErrInvalidCode {
+		t.Fatalf("expected a code without signature to be refused, got %v", err)
+	}
+
+	var pdf bytes.Buffer
+	if err := certificate.WritePDF(&pdf, cert, code, time.UTC); err != nil {
+		t.Fatalf("WritePDF failed: %v", err)
+	}
+	if !bytes.HasPrefix(pdf.Bytes(), []byte("%PDF-")) || !bytes.Contains(pdf.Bytes(), []byte(`(Algebra \(I\))`)) || !bytes.HasSuffix(pdf.Bytes(), []byte("%%EOF\n")) {
+		t.Fatalf("unexpected PDF:\n%s", pdf.String())
+	}
+}
@@ -0,0 +1,159 @@
+package certificate
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ContentTypePDF is the media type of a certificate document.
+const ContentTypePDF = "application/pdf"
+
+// A4 landscape, in points.
+const (
+	pageWidth  = 842
+	pageHeight = 595
+)
+
+// codeLineLength is how many characters of the code fit on a line in the
+// monospaced font.
+const codeLineLength = 96
+
+type font struct {
+	resource string
+	name     string
+	// widths are the glyph widths of printable ASCII in thousandths of
+	// the font size, starting at the space.
+	widths []int
+}
+
+var (
+	regular = font{resource: "F1", name: "Helvetica", widths: []int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}}
+	bold = font{resource: "F2", name: "Helvetica-Bold", widths: []int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}}
+	mono = font{resource: "F3", name: "Courier"}
+)
+
+// width returns the width of s set in f at size, in points. Glyphs outside
+// printable ASCII are taken to be as wide as a digit.
+func (f font) width(s string, size float64) float64 {
+	total := 0
+	for _, r := range s {
+		switch {
+		case f.widths == nil:
+			total += 600
+		case r >= ' ' && r <= '~':
+			total += f.widths[r-' ']
+		default:
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// WritePDF renders the certificate as a one-page PDF with its code printed
+// at the bottom, giving dates in loc. It uses the standard PDF fonts, which
+// cover Western European scripts only; other characters print as "?".
+func WritePDF(w io.Writer, c Certificate, code string, loc *time.Location) error {
+	var content bytes.Buffer
+	centred := func(f font, size float64, y int, text string) {
+		x := (pageWidth - f.width(text, size)) / 2
+		fmt.Fprintf(&content, "BT /%s %s Tf %s %d Td (%s) Tj ET\n", f.resource, number(size), number(x), y, escape(text))
+	}
+
+	// A double border frames the page.
+	content.WriteString("0.2 0.3 0.5 RG 3 w 30 30 782 535 re S 1 w 40 40 762 515 re S 0 g\n")
+	centred(bold, 34, 470, "Certificate of Completion")
+	centred(regular, 14, 420, "This certifies that")
+	centred(bold, 28, 380, c.StudentName)
+	centred(regular, 14, 340, "has passed")
+	centred(bold, 22, 305, c.TestTitle)
+	percent := 0
+	if c.MaxScore > 0 {
+		percent = c.Score * 100 / c.MaxScore
+	}
+	centred(regular, 14, 270, fmt.Sprintf("with a score of %d out of %d (%d%%)", c.Score, c.MaxScore, percent))
+	if c.SchoolName != "" {
+		centred(regular, 14, 235, c.SchoolName)
+	}
+	centred(regular, 12, 200, "Issued "+c.IssuedAt.In(loc).Format("2 January 2006"))
+	centred(regular, 9, 150, "Certificate "+c.ID+". Verify it with the code below.")
+	y := 132
+	for len(code) > 0 {
+		line := code[:min(codeLineLength, len(code))]
+		code = code[len(line):]
+		centred(mono, 7, y, line)
+		y -= 9
+	}
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Contents 4 0 R /Resources << /Font << /F1 5 0 R /F2 6 0 R /F3 7 0 R >> >> >>", pageWidth, pageHeight),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+		fontObject(regular),
+		fontObject(bold),
+		fontObject(mono),
+		fmt.Sprintf("<< /Title (%s) /Producer (go_work_sample) >>", escape("Certificate of Completion - "+c.TestTitle)),
+	}
+
+	var doc bytes.Buffer
+	doc.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = doc.Len()
+		fmt.Fprintf(&doc, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := doc.Len()
+	fmt.Fprintf(&doc, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&doc, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&doc, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, len(objects), xref)
+	_, err := w.Write(doc.Bytes())
+	return err
+}
+
+func fontObject(f font) string {
+	return "<< /Type /Font /Subtype /Type1 /BaseFont /" + f.name + " /Encoding /WinAnsiEncoding >>"
+}
+
+// escape encodes s as the body of a PDF string in WinAnsiEncoding, which
+// matches Latin-1 outside 0x80 to 0x9F.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= ' ' && r <= '~':
+			b.WriteRune(r)
+		case r >= 0xA0 && r <= 0xFF:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+func number(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
@@ -0,0 +1,912 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/broker"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/memory"
+	"github.com/sky0621/go_work_sample/core/pkg/notify"
+	"github.com/sky0621/go_work_sample/core/pkg/ops"
+	"github.com/sky0621/go_work_sample/core/pkg/storage/filedb"
+	"github.com/sky0621/go_work_sample/core/pkg/storage/router"
+	"github.com/sky0621/go_work_sample/core/pkg/storage/sqlite"
+	"github.com/sky0621/go_work_sample/core/pkg/tracing"
+	"github.com/sky0621/go_work_sample/core/pkg/webhook"
+)
+
+// Backup controls scheduled backups of the data store. Backups are only
+// restored into RestoreDir.
+type Backup struct {
+	Dir        string
+	RestoreDir string
+	Interval   time.Duration
+	Retention  int
+}
+
+// Enabled reports whether backups should be taken on a schedule.
+func (b Backup) Enabled() bool {
+	return b.Interval > 0
+}
+
+// LoadBackup reads backup settings from the environment.
+func LoadBackup() (Backup, error) {
+	interval, err := envDuration("BACKUP_INTERVAL", 24*time.Hour)
+	if err != nil {
+		return Backup{}, err
+	}
+	retention, err := envInt("BACKUP_RETENTION", 7)
+	if err != nil {
+		return Backup{}, err
+	}
+	if retention < 1 {
+		return Backup{}, fmt.Errorf("config: BACKUP_RETENTION must be positive, got %d", retention)
+	}
+
+	return Backup{
+		Dir:        envString("BACKUP_DIR", "./data/backups"),
+		RestoreDir: envString("BACKUP_RESTORE_DIR", "./data/restores"),
+		Interval:   interval,
+		Retention:  retention,
+	}, nil
+}
+
+// Notify controls outgoing notification email.
+type Notify struct {
+	SMTPAddr   string
+	From       string
+	DigestHour int
+}
+
+// LoadNotify reads notification settings from the environment. Without
+// SMTP_ADDR emails are written to the log.
+func LoadNotify() (Notify, error) {
+	hour, err := envInt("NOTIFY_DIGEST_HOUR", 7)
+	if err != nil {
+		return Notify{}, err
+	}
+	if hour < 0 || hour > 23 {
+		return Notify{}, fmt.Errorf("config: NOTIFY_DIGEST_HOUR must be between 0 and 23, got %d", hour)
+	}
+
+	return Notify{
+		SMTPAddr:   getenv("SMTP_ADDR"),
+		From:       envString("NOTIFY_FROM", "no-reply@example.com"),
+		DigestHour: hour,
+	}, nil
+}
+
+// Mailer builds the mailer described by the configuration.
+func (n Notify) Mailer() notify.Mailer {
+	if n.SMTPAddr == "" {
+		return notify.LogMailer{}
+	}
+	return notify.SMTPMailer{Addr: n.SMTPAddr, From: n.From}
+}
+
+// Kiosk controls scoped kiosk tokens for proctored test-taking machines.
+type Kiosk struct {
+	Secret string
+	TTL    time.Duration
+	MaxTTL time.Duration
+}
+
+// LoadKiosk reads kiosk token settings from the environment. The secret must
+// be shared by the teacher service issuing tokens and the student service
+// accepting them.
+func LoadKiosk() (Kiosk, error) {
+	ttl, err := envDuration("KIOSK_TOKEN_TTL", 90*time.Minute)
+	if err != nil {
+		return Kiosk{}, err
+	}
+	maxTTL, err := envDuration("KIOSK_TOKEN_MAX_TTL", 8*time.Hour)
+	if err != nil {
+		return Kiosk{}, err
+	}
+	if ttl <= 0 || ttl > maxTTL {
+		return Kiosk{}, fmt.Errorf("config: KIOSK_TOKEN_TTL must be between 0 and %s, got %s", maxTTL, ttl)
+	}
+
+	return Kiosk{
+		Secret: envString("KIOSK_TOKEN_SECRET", "kiosk-secret"),
+		TTL:    ttl,
+		MaxTTL: maxTTL,
+	}, nil
+}
+
+// Auth controls the access tokens identifying teachers and students.
+type Auth struct {
+	Secret string
+	TTL    time.Duration
+	MaxTTL time.Duration
+}
+
+// LoadAuth reads access token settings from the environment. The secret must
+// be shared by the organization service issuing tokens and every service
+// accepting them.
+func LoadAuth() (Auth, error) {
+	ttl, err := envDuration("AUTH_TOKEN_TTL", 12*time.Hour)
+	if err != nil {
+		return Auth{}, err
+	}
+	maxTTL, err := envDuration("AUTH_TOKEN_MAX_TTL", 7*24*time.Hour)
+	if err != nil {
+		return Auth{}, err
+	}
+	if ttl <= 0 || ttl > maxTTL {
+		return Auth{}, fmt.Errorf("config: AUTH_TOKEN_TTL must be between 0 and %s, got %s", maxTTL, ttl)
+	}
+
+	return Auth{
+		Secret: envString("AUTH_TOKEN_SECRET", "auth-secret"),
+		TTL:    ttl,
+		MaxTTL: maxTTL,
+	}, nil
+}
+
+// Statistics controls caching of computed test statistics.
+type Statistics struct {
+	CacheTTL        time.Duration
+	RefreshInterval time.Duration
+}
+
+// LoadStatistics reads statistics cache settings from the environment.
+func LoadStatistics() (Statistics, error) {
+	ttl, err := envDuration("STATISTICS_CACHE_TTL", 30*time.Second)
+	if err != nil {
+		return Statistics{}, err
+	}
+	refresh, err := envDuration("STATISTICS_REFRESH_INTERVAL", 10*time.Second)
+	if err != nil {
+		return Statistics{}, err
+	}
+	if ttl <= 0 || refresh <= 0 {
+		return Statistics{}, fmt.Errorf("config: statistics cache durations must be positive")
+	}
+	return Statistics{CacheTTL: ttl, RefreshInterval: refresh}, nil
+}
+
+// ReadModel controls the gradebook projections analytics are served from.
+type ReadModel struct {
+	MaxAge time.Duration
+}
+
+// LoadReadModel reads read model settings from the environment. Projections
+// are rebuilt from storage once they are READ_MODEL_MAX_AGE old, to pick up
+// writes made by other services; zero, the default, disables the read model.
+func LoadReadModel() (ReadModel, error) {
+	maxAge, err := envDuration("READ_MODEL_MAX_AGE", 0)
+	if err != nil {
+		return ReadModel{}, err
+	}
+	if maxAge < 0 {
+		return ReadModel{}, fmt.Errorf("config: READ_MODEL_MAX_AGE must not be negative, got %s", maxAge)
+	}
+	return ReadModel{MaxAge: maxAge}, nil
+}
+
+// Search controls the keyword search over teachers' tests.
+type Search struct {
+	MaxAge time.Duration
+}
+
+// LoadSearch reads search settings from the environment. A test is indexed
+// from storage again once it is SEARCH_MAX_AGE old, to pick up answers
+// submitted through other services; zero keeps it until the test changes.
+func LoadSearch() (Search, error) {
+	maxAge, err := envDuration("SEARCH_MAX_AGE", time.Minute)
+	if err != nil {
+		return Search{}, err
+	}
+	if maxAge < 0 {
+		return Search{}, fmt.Errorf("config: SEARCH_MAX_AGE must not be negative, got %s", maxAge)
+	}
+	return Search{MaxAge: maxAge}, nil
+}
+
+// GradingReminders controls reminders about approaching grading deadlines.
+type GradingReminders struct {
+	Interval time.Duration
+	Lead     time.Duration
+}
+
+// LoadGradingReminders reads grading reminder settings from the environment.
+func LoadGradingReminders() (GradingReminders, error) {
+	interval, err := envDuration("GRADING_REMINDER_INTERVAL", time.Hour)
+	if err != nil {
+		return GradingReminders{}, err
+	}
+	lead, err := envDuration("GRADING_REMINDER_LEAD", 48*time.Hour)
+	if err != nil {
+		return GradingReminders{}, err
+	}
+	if interval <= 0 || lead < 0 {
+		return GradingReminders{}, fmt.Errorf("config: grading reminder interval must be positive and lead non-negative")
+	}
+	return GradingReminders{Interval: interval, Lead: lead}, nil
+}
+
+// Scheduling controls warnings about students' exam schedules.
+type Scheduling struct {
+	MaxTestsPerDay int
+}
+
+// LoadScheduling reads exam scheduling settings from the environment. Zero
+// EXAM_MAX_TESTS_PER_DAY disables the daily capacity warning.
+func LoadScheduling() (Scheduling, error) {
+	maxPerDay, err := envInt("EXAM_MAX_TESTS_PER_DAY", 2)
+	if err != nil {
+		return Scheduling{}, err
+	}
+	if maxPerDay < 0 {
+		return Scheduling{}, fmt.Errorf("config: EXAM_MAX_TESTS_PER_DAY must not be negative, got %d", maxPerDay)
+	}
+	return Scheduling{MaxTestsPerDay: maxPerDay}, nil
+}
+
+// DraftLocks controls the locks co-teachers take on drafts they edit.
+type DraftLocks struct {
+	TTL time.Duration
+}
+
+// LoadDraftLocks reads draft lock settings from the environment.
+func LoadDraftLocks() (DraftLocks, error) {
+	ttl, err := envDuration("DRAFT_LOCK_TTL", 5*time.Minute)
+	if err != nil {
+		return DraftLocks{}, err
+	}
+	if ttl <= 0 {
+		return DraftLocks{}, fmt.Errorf("config: DRAFT_LOCK_TTL must be positive, got %s", ttl)
+	}
+	return DraftLocks{TTL: ttl}, nil
+}
+
+// Delegation controls teachers' delegations to substitutes.
+type Delegation struct {
+	ExpiryInterval time.Duration
+}
+
+// LoadDelegation reads delegation settings from the environment.
+func LoadDelegation() (Delegation, error) {
+	interval, err := envDuration("DELEGATION_EXPIRY_INTERVAL", time.Minute)
+	if err != nil {
+		return Delegation{}, err
+	}
+	if interval <= 0 {
+		return Delegation{}, fmt.Errorf("config: DELEGATION_EXPIRY_INTERVAL must be positive, got %s", interval)
+	}
+	return Delegation{ExpiryInterval: interval}, nil
+}
+
+// Retention controls the job applying the schools' retention policies.
+type Retention struct {
+	Interval time.Duration
+}
+
+// LoadRetention reads retention settings from the environment.
+func LoadRetention() (Retention, error) {
+	interval, err := envDuration("RETENTION_INTERVAL", 24*time.Hour)
+	if err != nil {
+		return Retention{}, err
+	}
+	if interval <= 0 {
+		return Retention{}, fmt.Errorf("config: RETENTION_INTERVAL must be positive, got %s", interval)
+	}
+	return Retention{Interval: interval}, nil
+}
+
+// MagicLink controls passwordless sign-in through emailed links.
+type MagicLink struct {
+	Secret string
+	// BaseURL is the sign-in page of the front end; the link appends the
+	// token as the token query parameter.
+	BaseURL    string
+	LinkTTL    time.Duration
+	SessionTTL time.Duration
+	// InvitationTTL is how long the invitation links of provisioned
+	// accounts work.
+	InvitationTTL time.Duration
+	// PruneInterval is how often the records of expired sign-ins are
+	// removed.
+	PruneInterval time.Duration
+}
+
+// LoadMagicLink reads magic-link settings from the environment.
+func LoadMagicLink() (MagicLink, error) {
+	linkTTL, err := envDuration("MAGIC_LINK_TTL", 15*time.Minute)
+	if err != nil {
+		return MagicLink{}, err
+	}
+	if linkTTL <= 0 || linkTTL > time.Hour {
+		return MagicLink{}, fmt.Errorf("config: MAGIC_LINK_TTL must be between 0 and 1h, got %s", linkTTL)
+	}
+	sessionTTL, err := envDuration("MAGIC_LINK_SESSION_TTL", 12*time.Hour)
+	if err != nil {
+		return MagicLink{}, err
+	}
+	if sessionTTL <= 0 {
+		return MagicLink{}, fmt.Errorf("config: MAGIC_LINK_SESSION_TTL must be positive, got %s", sessionTTL)
+	}
+	invitationTTL, err := envDuration("MAGIC_LINK_INVITATION_TTL", 7*24*time.Hour)
+	if err != nil {
+		return MagicLink{}, err
+	}
+	if invitationTTL <= 0 || invitationTTL > 30*24*time.Hour {
+		return MagicLink{}, fmt.Errorf("config: MAGIC_LINK_INVITATION_TTL must be between 0 and 720h, got %s", invitationTTL)
+	}
+	pruneInterval, err := envDuration("MAGIC_LINK_SESSION_PRUNE_INTERVAL", time.Hour)
+	if err != nil {
+		return MagicLink{}, err
+	}
+	if pruneInterval <= 0 {
+		return MagicLink{}, fmt.Errorf("config: MAGIC_LINK_SESSION_PRUNE_INTERVAL must be positive, got %s", pruneInterval)
+	}
+
+	return MagicLink{
+		Secret:        envString("MAGIC_LINK_SECRET", "magic-link-secret"),
+		BaseURL:       envString("MAGIC_LINK_BASE_URL", "http://localhost:3000/sign-in"),
+		LinkTTL:       linkTTL,
+		SessionTTL:    sessionTTL,
+		InvitationTTL: invitationTTL,
+		PruneInterval: pruneInterval,
+	}, nil
+}
+
+// Certificates controls the signing of completion certificates.
+type Certificates struct {
+	Secret string
+}
+
+// LoadCertificates reads certificate settings from the environment. The
+// signing key is derived from CERTIFICATE_SECRET, so changing the secret
+// stops earlier certificates from verifying.
+func LoadCertificates() Certificates {
+	return Certificates{Secret: envString("CERTIFICATE_SECRET", "certificate-secret")}
+}
+
+// Ops controls the maintenance operations of the admin runbook.
+type Ops struct {
+	// Verbs lists the operations admins may run; nil allows all of them.
+	Verbs        []string
+	DrainTimeout time.Duration
+}
+
+// LoadOps reads runbook settings from the environment. OPS_VERBS is a
+// comma-separated list of the verbs to allow. The drain timeout should stay
+// below HTTP_WRITE_TIMEOUT for the caller to see the outcome.
+func LoadOps() (Ops, error) {
+	drain, err := envDuration("OPS_DRAIN_TIMEOUT", 5*time.Second)
+	if err != nil {
+		return Ops{}, err
+	}
+	if drain <= 0 {
+		return Ops{}, fmt.Errorf("config: OPS_DRAIN_TIMEOUT must be positive, got %s", drain)
+	}
+	cfg := Ops{DrainTimeout: drain}
+	if v := getenv("OPS_VERBS"); v != "" {
+		cfg.Verbs = []string{}
+		for _, verb := range splitList(v) {
+			if !slices.Contains(ops.Verbs, verb) {
+				return Ops{}, fmt.Errorf("config: OPS_VERBS: unknown verb %q, want one of %s", verb, strings.Join(ops.Verbs, ", "))
+			}
+			cfg.Verbs = append(cfg.Verbs, verb)
+		}
+	}
+	return cfg, nil
+}
+
+// Settings returns the runbook settings.
+func (o Ops) Settings() ops.Settings {
+	return ops.Settings{Allowed: o.Verbs, DrainTimeout: o.DrainTimeout}
+}
+
+// Dataset controls anonymized dataset exports.
+type Dataset struct {
+	Salt string
+	MinK int
+}
+
+// LoadDataset reads dataset export settings from the environment. Student IDs
+// are hashed with DATASET_SALT, so hashes only match across exports that share
+// the salt.
+func LoadDataset() (Dataset, error) {
+	minK, err := envInt("DATASET_MIN_K", 5)
+	if err != nil {
+		return Dataset{}, err
+	}
+	if minK < 2 {
+		return Dataset{}, fmt.Errorf("config: DATASET_MIN_K must be at least 2, got %d", minK)
+	}
+	return Dataset{
+		Salt: envString("DATASET_SALT", "dataset-salt"),
+		MinK: minK,
+	}, nil
+}
+
+// Storage backends.
+const (
+	BackendFile   = "file"
+	BackendSQLite = "sqlite"
+)
+
+// Storage locates the data stores. Schools listed in Schools keep their
+// data in a store of their own; every other school uses the shared one.
+type Storage struct {
+	// Backend is BackendFile for JSON files or BackendSQLite for SQLite
+	// databases opened with the database/sql driver named SQLiteDriver,
+	// by default the one the sqlite package links.
+	Backend      string
+	SQLiteDriver string
+	Path         string
+	Schools      map[domain.SchoolID]string
+	// Lazy loads answers and results per test on demand instead of keeping
+	// them all in memory, keeping at most LoadedTests tests loaded.
+	Lazy        bool
+	LoadedTests int
+	// Journal appends answer and result writes to a write-ahead log that
+	// is compacted into the state file every CompactAfter entries.
+	Journal      bool
+	CompactAfter int
+}
+
+// LoadStorage reads storage settings from the environment. SCHOOL_STORES is
+// a comma-separated list of school=path pairs, for example
+// "school-001=/mnt/eu/school-001.json".
+func LoadStorage() (Storage, error) {
+	backend := envString("DATA_STORE_BACKEND", BackendFile)
+	defaultPath := "./data/state.json"
+	switch backend {
+	case BackendFile:
+	case BackendSQLite:
+		defaultPath = "./data/state.db"
+	default:
+		return Storage{}, fmt.Errorf("config: DATA_STORE_BACKEND must be %s or %s, got %q", BackendFile, BackendSQLite, backend)
+	}
+	lazy, err := envBool("DATA_STORE_LAZY", false)
+	if err != nil {
+		return Storage{}, err
+	}
+	loaded, err := envInt("DATA_STORE_LOADED_TESTS", 64)
+	if err != nil {
+		return Storage{}, err
+	}
+	if loaded < 1 {
+		return Storage{}, fmt.Errorf("config: DATA_STORE_LOADED_TESTS must be positive, got %d", loaded)
+	}
+	journal, err := envBool("DATA_STORE_JOURNAL", false)
+	if err != nil {
+		return Storage{}, err
+	}
+	compactAfter, err := envInt("DATA_STORE_JOURNAL_COMPACT_AFTER", 1000)
+	if err != nil {
+		return Storage{}, err
+	}
+	if compactAfter < 1 {
+		return Storage{}, fmt.Errorf("config: DATA_STORE_JOURNAL_COMPACT_AFTER must be positive, got %d", compactAfter)
+	}
+	if journal && lazy {
+		return Storage{}, fmt.Errorf("config: DATA_STORE_JOURNAL cannot be combined with DATA_STORE_LAZY")
+	}
+	cfg := Storage{
+		Backend:      backend,
+		SQLiteDriver: envString("DATA_STORE_SQLITE_DRIVER", sqlite.Driver),
+		Path:         envString("DATA_STORE_PATH", defaultPath),
+		Schools:      make(map[domain.SchoolID]string),
+		Lazy:         lazy,
+		LoadedTests:  loaded,
+		Journal:      journal,
+		CompactAfter: compactAfter,
+	}
+	for _, entry := range strings.Split(getenv("SCHOOL_STORES"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		school, path, ok := strings.Cut(entry, "=")
+		school, path = strings.TrimSpace(school), strings.TrimSpace(path)
+		if !ok || school == "" || path == "" {
+			return Storage{}, fmt.Errorf("config: SCHOOL_STORES: want school=path, got %q", entry)
+		}
+		if _, dup := cfg.Schools[domain.SchoolID(school)]; dup {
+			return Storage{}, fmt.Errorf("config: SCHOOL_STORES: school %s listed twice", school)
+		}
+		cfg.Schools[domain.SchoolID(school)] = path
+	}
+	return cfg, nil
+}
+
+// FileOptions returns the options for opening the file stores.
+func (c Storage) FileOptions() filedb.Options {
+	return filedb.Options{Lazy: c.Lazy, MaxLoadedTests: c.LoadedTests, Journal: c.Journal, CompactAfter: c.CompactAfter}
+}
+
+// Open opens the configured stores, seeding new ones with seed, and routes
+// between them. The shared store is returned as well for features that work
+// on one store, such as backups.
+func (c Storage) Open(seed memory.SeedData) (*router.Router, router.SnapshotStore, error) {
+	if c.Backend == BackendSQLite {
+		return router.OpenSQLite(c.Path, c.Schools, seed, c.SQLiteDriver)
+	}
+	return router.OpenFiles(c.Path, c.Schools, seed, c.FileOptions())
+}
+
+// RPC controls calls between services over the gRPC API.
+type RPC struct {
+	// ScoringTarget is the host:port of the scoring service's gRPC API.
+	// Empty grades in process against the local store.
+	ScoringTarget string
+	// Timeout bounds each call.
+	Timeout time.Duration
+	// TokenTTL is the lifetime of the tokens forwarding the caller's
+	// identity to the remote service.
+	TokenTTL time.Duration
+}
+
+// LoadRPC reads service-to-service settings from the environment.
+func LoadRPC() (RPC, error) {
+	timeout, err := envDuration("RPC_TIMEOUT", 5*time.Second)
+	if err != nil {
+		return RPC{}, err
+	}
+	if timeout <= 0 {
+		return RPC{}, fmt.Errorf("config: RPC_TIMEOUT must be positive, got %s", timeout)
+	}
+	return RPC{
+		ScoringTarget: envString("SCORING_GRPC_TARGET", ""),
+		Timeout:       timeout,
+		TokenTTL:      time.Minute,
+	}, nil
+}
+
+// Scoring modes.
+const (
+	ScoringShared = "shared"
+	ScoringRemote = "remote"
+)
+
+// Scoring chooses where the scoring service reads tests and stores grades.
+type Scoring struct {
+	// Mode is ScoringShared to open the stores of LoadStorage, files or a
+	// database shared with the other services, or ScoringRemote to keep no
+	// state and grade through the teacher service's gRPC API.
+	Mode string
+	// TeacherTarget is the host:port of the teacher service's gRPC API,
+	// required in remote mode. The teacher service must then grade in
+	// process, without SCORING_GRPC_TARGET, or calls would go round in a
+	// loop.
+	TeacherTarget string
+}
+
+// LoadScoring reads the scoring service's mode from the environment.
+func LoadScoring() (Scoring, error) {
+	cfg := Scoring{
+		Mode:          envString("SCORING_MODE", ScoringShared),
+		TeacherTarget: envString("TEACHER_GRPC_TARGET", ""),
+	}
+	switch cfg.Mode {
+	case ScoringShared:
+	case ScoringRemote:
+		if cfg.TeacherTarget == "" {
+			return Scoring{}, fmt.Errorf("config: TEACHER_GRPC_TARGET is required when SCORING_MODE is %s", ScoringRemote)
+		}
+	default:
+		return Scoring{}, fmt.Errorf("config: SCORING_MODE must be %s or %s, got %q", ScoringShared, ScoringRemote, cfg.Mode)
+	}
+	return cfg, nil
+}
+
+// Blob controls where binary artifacts such as exports are stored.
+type Blob struct {
+	Dir string
+}
+
+// LoadBlob reads blob storage settings from the environment.
+func LoadBlob() Blob {
+	return Blob{Dir: envString("BLOB_DIR", "./data/blobs")}
+}
+
+// Upload controls resumable uploads of large attachments.
+type Upload struct {
+	PartSize int64
+	MaxSize  int64
+	TTL      time.Duration
+}
+
+// LoadUpload reads upload settings from the environment. UPLOAD_PART_SIZE
+// and UPLOAD_MAX_SIZE are in bytes; an upload not completed within
+// UPLOAD_TTL of being started is discarded.
+func LoadUpload() (Upload, error) {
+	partSize, err := envInt("UPLOAD_PART_SIZE", 5<<20)
+	if err != nil {
+		return Upload{}, err
+	}
+	maxSize, err := envInt("UPLOAD_MAX_SIZE", 1<<30)
+	if err != nil {
+		return Upload{}, err
+	}
+	ttl, err := envDuration("UPLOAD_TTL", 24*time.Hour)
+	if err != nil {
+		return Upload{}, err
+	}
+	if partSize <= 0 || maxSize <= 0 || ttl <= 0 {
+		return Upload{}, fmt.Errorf("config: UPLOAD_PART_SIZE, UPLOAD_MAX_SIZE and UPLOAD_TTL must be positive")
+	}
+	return Upload{PartSize: int64(partSize), MaxSize: int64(maxSize), TTL: ttl}, nil
+}
+
+// Webhooks controls outgoing webhook deliveries.
+type Webhooks struct {
+	Endpoints    []webhook.Endpoint
+	MaxAttempts  int
+	BaseDelay    time.Duration
+	MaxDelay     time.Duration
+	PollInterval time.Duration
+}
+
+type webhookEndpointFile struct {
+	ID     string   `json:"id"`
+	URL    string   `json:"url"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
+}
+
+// LoadWebhooks reads webhook settings from the environment. Endpoints and
+// their signing secrets are listed in the JSON file named by
+// WEBHOOK_ENDPOINTS_FILE; without it no webhooks are sent.
+func LoadWebhooks() (Webhooks, error) {
+	attempts, err := envInt("WEBHOOK_MAX_ATTEMPTS", 8)
+	if err != nil {
+		return Webhooks{}, err
+	}
+	base, err := envDuration("WEBHOOK_BASE_DELAY", 30*time.Second)
+	if err != nil {
+		return Webhooks{}, err
+	}
+	maxDelay, err := envDuration("WEBHOOK_MAX_DELAY", time.Hour)
+	if err != nil {
+		return Webhooks{}, err
+	}
+	poll, err := envDuration("WEBHOOK_POLL_INTERVAL", 5*time.Second)
+	if err != nil {
+		return Webhooks{}, err
+	}
+	if attempts < 1 || base <= 0 || maxDelay < base || poll <= 0 {
+		return Webhooks{}, fmt.Errorf("config: invalid webhook retry settings")
+	}
+
+	cfg := Webhooks{MaxAttempts: attempts, BaseDelay: base, MaxDelay: maxDelay, PollInterval: poll}
+
+	path := getenv("WEBHOOK_ENDPOINTS_FILE")
+	if path == "" {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Webhooks{}, fmt.Errorf("config: WEBHOOK_ENDPOINTS_FILE: %w", err)
+	}
+	var entries []webhookEndpointFile
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return Webhooks{}, fmt.Errorf("config: WEBHOOK_ENDPOINTS_FILE: %w", err)
+	}
+	for _, e := range entries {
+		if e.ID == "" || e.URL == "" || e.Secret == "" {
+			return Webhooks{}, fmt.Errorf("config: webhook endpoint needs id, url and secret")
+		}
+		cfg.Endpoints = append(cfg.Endpoints, webhook.Endpoint{ID: e.ID, URL: e.URL, Secret: e.Secret, Events: e.Events})
+	}
+	return cfg, nil
+}
+
+// Dispatcher builds the webhook dispatcher described by the configuration.
+func (c Webhooks) Dispatcher() *webhook.Dispatcher {
+	return webhook.NewDispatcher(c.Endpoints, webhook.Options{
+		MaxAttempts: c.MaxAttempts,
+		BaseDelay:   c.BaseDelay,
+		MaxDelay:    c.MaxDelay,
+	})
+}
+
+// Broker controls publishing domain events to a message broker.
+type Broker struct {
+	// Kind is "nats", "kafka" or empty, which keeps events in the process.
+	Kind string
+	// URL is the NATS server, or the Kafka REST proxy.
+	URL string
+	// Subject prefixes the NATS subjects, or names the Kafka topic.
+	Subject   string
+	QueueSize int
+}
+
+// LoadBroker reads event broker settings from the environment.
+func LoadBroker() (Broker, error) {
+	size, err := envInt("EVENT_BROKER_QUEUE_SIZE", 1024)
+	if err != nil {
+		return Broker{}, err
+	}
+	cfg := Broker{
+		Kind:      strings.ToLower(strings.TrimSpace(getenv("EVENT_BROKER"))),
+		URL:       getenv("EVENT_BROKER_URL"),
+		Subject:   envString("EVENT_BROKER_SUBJECT", "assessment"),
+		QueueSize: size,
+	}
+	switch cfg.Kind {
+	case "":
+		return cfg, nil
+	case "nats", "kafka":
+	default:
+		return Broker{}, fmt.Errorf("config: EVENT_BROKER must be nats or kafka, got %q", cfg.Kind)
+	}
+	if cfg.URL == "" {
+		return Broker{}, fmt.Errorf("config: EVENT_BROKER_URL is required for %s", cfg.Kind)
+	}
+	if size < 1 {
+		return Broker{}, fmt.Errorf("config: EVENT_BROKER_QUEUE_SIZE must be positive, got %d", size)
+	}
+	return cfg, nil
+}
+
+// Queue builds the queue publishing to the configured broker, or returns nil
+// when no broker is configured.
+func (c Broker) Queue() (*broker.Queue, error) {
+	var publisher broker.Publisher
+	switch c.Kind {
+	case "nats":
+		nats, err := broker.NewNATS(c.URL, c.Subject)
+		if err != nil {
+			return nil, err
+		}
+		publisher = nats
+	case "kafka":
+		publisher = broker.NewKafkaREST(c.URL, c.Subject, nil)
+	default:
+		return nil, nil
+	}
+	return broker.NewQueue(publisher, c.QueueSize), nil
+}
+
+// Stream controls the student event stream.
+type Stream struct {
+	// WebhookSecret authenticates the webhooks of other services, such as
+	// the teacher service, that feed their assignments and grades to the
+	// stream. The receiver is off when it is empty.
+	WebhookSecret string
+}
+
+// LoadStream reads event stream settings from the environment.
+func LoadStream() Stream {
+	return Stream{WebhookSecret: getenv("STREAM_WEBHOOK_SECRET")}
+}
+
+// Tracing controls span export to an OpenTelemetry collector. Tracing is
+// off when Endpoint is empty.
+type Tracing struct {
+	// Endpoint is the URL spans are posted to over OTLP/HTTP.
+	Endpoint    string
+	Headers     map[string]string
+	ServiceName string
+	// SampleRatio is the share of new traces recorded.
+	SampleRatio float64
+	// Interval is how often queued spans are exported.
+	Interval time.Duration
+}
+
+// LoadTracing reads the standard OpenTelemetry variables:
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is used as is, while
+// OTEL_EXPORTER_OTLP_ENDPOINT gets "/v1/traces" appended.
+// OTEL_EXPORTER_OTLP_HEADERS is a comma-separated list of key=value pairs
+// with URL-encoded values. OTEL_SERVICE_NAME defaults to service,
+// OTEL_TRACES_SAMPLER_ARG to 1, and OTEL_SDK_DISABLED=true turns tracing
+// off. OTEL_BSP_SCHEDULE_DELAY sets the export interval in milliseconds.
+func LoadTracing(service string) (Tracing, error) {
+	cfg := Tracing{
+		Endpoint:    getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"),
+		Headers:     make(map[string]string),
+		ServiceName: envString("OTEL_SERVICE_NAME", service),
+		SampleRatio: 1,
+	}
+	if cfg.Endpoint == "" {
+		if base := getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); base != "" {
+			cfg.Endpoint = strings.TrimSuffix(base, "/") + "/v1/traces"
+		}
+	}
+	disabled, err := envBool("OTEL_SDK_DISABLED", false)
+	if err != nil {
+		return Tracing{}, err
+	}
+	if disabled {
+		cfg.Endpoint = ""
+	}
+	if v := getenv("OTEL_TRACES_SAMPLER_ARG"); v != "" {
+		ratio, err := strconv.ParseFloat(v, 64)
+		if err != nil || ratio < 0 || ratio > 1 {
+			return Tracing{}, fmt.Errorf("config: OTEL_TRACES_SAMPLER_ARG must be between 0 and 1, got %q", v)
+		}
+		cfg.SampleRatio = ratio
+	}
+	delay, err := envInt("OTEL_BSP_SCHEDULE_DELAY", 5000)
+	if err != nil {
+		return Tracing{}, err
+	}
+	if delay < 1 {
+		return Tracing{}, fmt.Errorf("config: OTEL_BSP_SCHEDULE_DELAY must be positive, got %d", delay)
+	}
+	cfg.Interval = time.Duration(delay) * time.Millisecond
+	for _, pair := range splitList(getenv("OTEL_EXPORTER_OTLP_HEADERS")) {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return Tracing{}, fmt.Errorf("config: OTEL_EXPORTER_OTLP_HEADERS: want key=value, got %q", pair)
+		}
+		decoded, err := url.QueryUnescape(strings.TrimSpace(value))
+		if err != nil {
+			return Tracing{}, fmt.Errorf("config: OTEL_EXPORTER_OTLP_HEADERS: %w", err)
+		}
+		cfg.Headers[strings.TrimSpace(key)] = decoded
+	}
+	return cfg, nil
+}
+
+// Provider builds the span provider described by the configuration, or
+// returns nil when tracing is off.
+func (c Tracing) Provider() *tracing.Provider {
+	if c.Endpoint == "" {
+		return nil
+	}
+	return tracing.NewProvider(tracing.Options{
+		Exporter: tracing.NewOTLPExporter(tracing.OTLPOptions{
+			Endpoint:    c.Endpoint,
+			Headers:     c.Headers,
+			ServiceName: c.ServiceName,
+		}),
+		SampleRatio: c.SampleRatio,
+	})
+}
+
+func envString(key, fallback string) string {
+	if v := getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func envInt(key string, fallback int) (int, error) {
+	v := getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("config: %s: %w", key, err)
+	}
+	return n, nil
+}
+
+func envBool(key string, fallback bool) (bool, error) {
+	v := getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("config: %s: %w", key, err)
+	}
+	return b, nil
+}
+
+func envDuration(key string, fallback time.Duration) (time.Duration, error) {
+	v := getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("config: %s: %w", key, err)
+	}
+	return d, nil
+}
@@ -0,0 +1,326 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/sky0621/go_work_sample/core/pkg/httpmw"
+)
+
+// ReloadPath is where admins trigger a runtime configuration reload.
+const ReloadPath = "/admin/config/reload"
+
+// Runtime holds the settings a service can change without restarting.
+// Structural settings such as storage paths and listen addresses are read
+// once at startup by the other loaders.
+type Runtime struct {
+	LogLevel slog.Level
+	// AllowedOrigins lists the browser origins admitted by CORS. "*" admits
+	// every origin; empty admits none.
+	AllowedOrigins []string
+	Features       map[string]bool
+	RateLimits     RateLimits
+	// Deprecations lists the routes answered with Deprecation and Sunset
+	// headers. They are only read from RUNTIME_CONFIG_FILE.
+	Deprecations []httpmw.DeprecatedRoute
+}
+
+// RateLimits are the request caps that can be tuned at runtime. The
+// magic-link caps count requests per hour for one address and for one
+// client. Routes meter requests per caller, the first matching rule
+// applying.
+type RateLimits struct {
+	MagicLinkPerEmail  int               `json:"magic_link_per_email"`
+	MagicLinkPerClient int               `json:"magic_link_per_client"`
+	Routes             []httpmw.RateRule `json:"routes"`
+}
+
+// defaultRouteLimits protects the store from bursts of answer submissions:
+// a student may save 10 answers a second, 20 at once.
+const defaultRouteLimits = "POST /api/students/*/tests/*/answers=10:20"
+
+// Enabled reports whether the named feature flag is on.
+func (r Runtime) Enabled(feature string) bool {
+	return r.Features[feature]
+}
+
+// runtimeFile is the layout of RUNTIME_CONFIG_FILE. Fields left out keep the
+// value from the environment.
+type runtimeFile struct {
+	LogLevel       *string         `json:"log_level"`
+	AllowedOrigins []string        `json:"allowed_origins"`
+	Features       map[string]bool `json:"features"`
+	RateLimits     struct {
+		MagicLinkPerEmail  *int               `json:"magic_link_per_email"`
+		MagicLinkPerClient *int               `json:"magic_link_per_client"`
+		Routes             *[]httpmw.RateRule `json:"routes"`
+	} `json:"rate_limits"`
+	Deprecations *[]httpmw.DeprecatedRoute `json:"deprecations"`
+}
+
+// LoadRuntime reads runtime settings from the environment, then applies
+// RUNTIME_CONFIG_FILE when set. Only the file is meant to change while the
+// service runs; the environment of a running process is fixed.
+//
+// FEATURE_FLAGS is a comma-separated list of flags to enable, where
+// "name=false" disables one explicitly. ALLOWED_ORIGINS is comma-separated.
+func LoadRuntime() (Runtime, error) {
+	level, err := parseLogLevel(envString("LOG_LEVEL", "info"))
+	if err != nil {
+		return Runtime{}, err
+	}
+	perEmail, err := envInt("MAGIC_LINK_PER_EMAIL", 5)
+	if err != nil {
+		return Runtime{}, err
+	}
+	perClient, err := envInt("MAGIC_LINK_PER_CLIENT", 30)
+	if err != nil {
+		return Runtime{}, err
+	}
+	routes, err := parseRouteLimits(envString("RATE_LIMIT_ROUTES", defaultRouteLimits))
+	if err != nil {
+		return Runtime{}, err
+	}
+	cfg := Runtime{
+		LogLevel:       level,
+		AllowedOrigins: splitList(getenv("ALLOWED_ORIGINS")),
+		Features:       make(map[string]bool),
+		RateLimits:     RateLimits{MagicLinkPerEmail: perEmail, MagicLinkPerClient: perClient, Routes: routes},
+	}
+	for _, flag := range splitList(getenv("FEATURE_FLAGS")) {
+		name, value, ok := strings.Cut(flag, "=")
+		enabled := true
+		if ok {
+			if enabled, err = strconv.ParseBool(value); err != nil {
+				return Runtime{}, fmt.Errorf("config: FEATURE_FLAGS: invalid value for %s: %q", name, value)
+			}
+		}
+		cfg.Features[strings.TrimSpace(name)] = enabled
+	}
+
+	if path := getenv("RUNTIME_CONFIG_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Runtime{}, fmt.Errorf("config: RUNTIME_CONFIG_FILE: %w", err)
+		}
+		var file runtimeFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return Runtime{}, fmt.Errorf("config: RUNTIME_CONFIG_FILE: %w", err)
+		}
+		if file.LogLevel != nil {
+			if cfg.LogLevel, err = parseLogLevel(*file.LogLevel); err != nil {
+				return Runtime{}, err
+			}
+		}
+		if file.AllowedOrigins != nil {
+			cfg.AllowedOrigins = file.AllowedOrigins
+		}
+		for name, enabled := range file.Features {
+			cfg.Features[name] = enabled
+		}
+		if v := file.RateLimits.MagicLinkPerEmail; v != nil {
+			cfg.RateLimits.MagicLinkPerEmail = *v
+		}
+		if v := file.RateLimits.MagicLinkPerClient; v != nil {
+			cfg.RateLimits.MagicLinkPerClient = *v
+		}
+		if v := file.RateLimits.Routes; v != nil {
+			cfg.RateLimits.Routes = *v
+		}
+		if v := file.Deprecations; v != nil {
+			cfg.Deprecations = *v
+		}
+	}
+
+	if cfg.RateLimits.MagicLinkPerEmail < 1 || cfg.RateLimits.MagicLinkPerClient < 1 {
+		return Runtime{}, fmt.Errorf("config: magic link rate limits must be positive")
+	}
+	for _, rule := range cfg.RateLimits.Routes {
+		if !strings.HasPrefix(rule.Path, "/") || rule.PerSecond <= 0 || rule.Burst < 0 {
+			return Runtime{}, fmt.Errorf("config: route rate limit for %q needs an absolute path and a positive rate", rule.Path)
+		}
+	}
+	for _, route := range cfg.Deprecations {
+		if !strings.HasPrefix(route.Path, "/") || route.Since.IsZero() || (!route.Sunset.IsZero() && !route.Sunset.After(route.Since)) {
+			return Runtime{}, fmt.Errorf("config: deprecated route %q needs an absolute path, a since date and any sunset after it", route.Path)
+		}
+	}
+	return cfg, nil
+}
+
+// parseRouteLimits reads rules of the form "[METHOD ]PATH=RATE[:BURST]",
+// separated by commas, for example "POST /api/students/*/tests/*/answers=10:20".
+// The burst defaults to the rate rounded up.
+func parseRouteLimits(s string) ([]httpmw.RateRule, error) {
+	var rules []httpmw.RateRule
+	for _, entry := range splitList(s) {
+		route, limit, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("config: RATE_LIMIT_ROUTES: missing rate in %q", entry)
+		}
+		var rule httpmw.RateRule
+		route = strings.TrimSpace(route)
+		if method, path, ok := strings.Cut(route, " "); ok {
+			rule.Method, rule.Path = strings.ToUpper(method), strings.TrimSpace(path)
+		} else {
+			rule.Path = route
+		}
+		rate, burst, hasBurst := strings.Cut(strings.TrimSpace(limit), ":")
+		var err error
+		if rule.PerSecond, err = strconv.ParseFloat(rate, 64); err != nil {
+			return nil, fmt.Errorf("config: RATE_LIMIT_ROUTES: invalid rate in %q", entry)
+		}
+		rule.Burst = int(math.Ceil(rule.PerSecond))
+		if hasBurst {
+			if rule.Burst, err = strconv.Atoi(burst); err != nil {
+				return nil, fmt.Errorf("config: RATE_LIMIT_ROUTES: invalid burst in %q", entry)
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func parseLogLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("config: invalid log level %q", s)
+	}
+	return level, nil
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// Reloader keeps the current runtime settings and tells subscribers when
+// they change. A reload that fails validation leaves the settings as they
+// were.
+type Reloader struct {
+	load func() (Runtime, error)
+	// reloading serializes reloads so they apply in the order they ran.
+	reloading sync.Mutex
+
+	mu          sync.Mutex
+	current     Runtime
+	subscribers []func(Runtime)
+}
+
+// NewReloader loads the runtime settings with LoadRuntime.
+func NewReloader() (*Reloader, error) {
+	return newReloader(LoadRuntime)
+}
+
+func newReloader(load func() (Runtime, error)) (*Reloader, error) {
+	cfg, err := load()
+	if err != nil {
+		return nil, err
+	}
+	return &Reloader{load: load, current: cfg}, nil
+}
+
+// Current returns the settings in effect.
+func (r *Reloader) Current() Runtime {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// Subscribe calls fn with the current settings now and again after every
+// successful reload, so one function covers startup and changes.
+func (r *Reloader) Subscribe(fn func(Runtime)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers = append(r.subscribers, fn)
+	fn(r.current)
+}
+
+// Reload reads the settings again and notifies subscribers. Subscribers
+// run one reload at a time, in the order they subscribed.
+func (r *Reloader) Reload() (Runtime, error) {
+	r.reloading.Lock()
+	defer r.reloading.Unlock()
+	cfg, err := r.load()
+	if err != nil {
+		return r.Current(), err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.current = cfg
+	for _, fn := range r.subscribers {
+		fn(cfg)
+	}
+	return cfg, nil
+}
+
+// WatchSignals reloads on every SIGHUP until ctx is done.
+func (r *Reloader) WatchSignals(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			if _, err := r.Reload(); err != nil {
+				slog.Error("config reload failed", "error", err)
+				continue
+			}
+			slog.Info("config reloaded", "trigger", "SIGHUP")
+		}
+	}
+}
+
+// ServeHTTP reloads on POST and reports the settings now in effect. Mount
+// it at ReloadPath behind admin authentication.
+func (r *Reloader) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	cfg, err := r.Reload()
+	if err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		return
+	}
+	slog.InfoContext(req.Context(), "config reloaded", "trigger", "admin")
+	writeJSON(w, http.StatusOK, runtimeResponse{
+		LogLevel:       cfg.LogLevel.String(),
+		AllowedOrigins: cfg.AllowedOrigins,
+		Features:       cfg.Features,
+		RateLimits:     cfg.RateLimits,
+		Deprecations:   cfg.Deprecations,
+	})
+}
+
+type runtimeResponse struct {
+	LogLevel       string                   `json:"log_level"`
+	AllowedOrigins []string                 `json:"allowed_origins"`
+	Features       map[string]bool          `json:"features"`
+	RateLimits     RateLimits               `json:"rate_limits"`
+	Deprecations   []httpmw.DeprecatedRoute `json:"deprecations"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, payload any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
@@ -0,0 +1,87 @@
+package config_test
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/config"
+	"github.com/sky0621/go_work_sample/core/pkg/httpmw"
+)
+
+func TestReloaderAppliesRuntimeFileChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runtime.json")
+	write := func(body string) {
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	write(`{"features": {"beta": true}}`)
+	t.Setenv("RUNTIME_CONFIG_FILE", path)
+	t.Setenv("LOG_LEVEL", "warn")
+	t.Setenv("FEATURE_FLAGS", "beta=false,exports")
+	t.Setenv("ALLOWED_ORIGINS", "https://a.example.com, https://b.example.com")
+	t.Setenv("RATE_LIMIT_ROUTES", "post /api/students/*/tests/*/answers=10:20, /api/=2.5")
+
+	reloader, err := config.NewReloader()
+	if err != nil {
+		t.Fatalf("NewReloader failed: %v", err)
+	}
+	var seen []config.Runtime
+	reloader.Subscribe(func(c config.Runtime) { seen = append(seen, c) })
+	initial := reloader.Current()
+	if initial.LogLevel != slog.LevelWarn || !initial.Enabled("beta") || !initial.Enabled("exports") || len(initial.AllowedOrigins) != 2 {
+		t.Fatalf("unexpected initial settings %+v", initial)
+	}
+	if len(seen) != 1 || initial.RateLimits.MagicLinkPerEmail != 5 {
+		t.Fatalf("expected subscribers to get the current settings, got %+v", seen)
+	}
+	want := []httpmw.RateRule{
+		{Method: http.MethodPost, Path: "/api/students/*/tests/*/answers", PerSecond: 10, Burst: 20},
+		{Path: "/api/", PerSecond: 2.5, Burst: 3},
+	}
+	if !reflect.DeepEqual(initial.RateLimits.Routes, want) {
+		t.Fatalf("expected route limits %+v, got %+v", want, initial.RateLimits.Routes)
+	}
+
+	write(`{"log_level": "debug", "allowed_origins": [], "rate_limits": {"magic_link_per_email": 2, "routes": []}}`)
+	rr := httptest.NewRecorder()
+	reloader.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, config.ReloadPath, nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("reload failed: %d %s", rr.Code, rr.Body.String())
+	}
+	var body struct {
+		LogLevel   string `json:"log_level"`
+		RateLimits struct {
+			MagicLinkPerEmail int `json:"magic_link_per_email"`
+		} `json:"rate_limits"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil || body.LogLevel != "DEBUG" || body.RateLimits.MagicLinkPerEmail != 2 {
+		t.Fatalf("unexpected reload response %s", rr.Body.String())
+	}
+	reloaded := seen[len(seen)-1]
+	if len(seen) != 2 || reloaded.LogLevel != slog.LevelDebug || len(reloaded.AllowedOrigins) != 0 || reloaded.Enabled("beta") || len(reloaded.RateLimits.Routes) != 0 {
+		t.Fatalf("expected subscribers to see the reloaded settings, got %+v", seen)
+	}
+
+	write(`{"rate_limits": {"magic_link_per_client": 0}}`)
+	if _, err := reloader.Reload(); err == nil {
+		t.Fatalf("expected invalid settings to be rejected")
+	}
+	write(`{"rate_limits": {"routes": [{"path": "/api/", "per_second": 0}]}}`)
+	if _, err := reloader.Reload(); err == nil {
+		t.Fatalf("expected a route limit without a rate to be rejected")
+	}
+	write(`{"deprecations": [{"path": "/api/old", "since": "2026-06-01T00:00:00Z", "sunset": "2026-01-01T00:00:00Z"}]}`)
+	if _, err := reloader.Reload(); err == nil {
+		t.Fatalf("expected a deprecated route sunset before its deprecation to be rejected")
+	}
+	if len(seen) != 2 || reloader.Current().LogLevel != slog.LevelDebug {
+		t.Fatalf("expected a failed reload to keep the previous settings")
+	}
+}
@@ -0,0 +1,199 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// ServerDefaults are a binary's own defaults for the settings of LoadServer.
+type ServerDefaults struct {
+	// Name prefixes the binary's address settings: "student" reads
+	// STUDENT_API_ADDR and STUDENT_GRPC_ADDR.
+	Name string
+	Addr string
+	// GRPCAddr is empty for binaries without a gRPC listener.
+	GRPCAddr        string
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	ShutdownTimeout time.Duration
+}
+
+// Server holds the listener settings of a binary.
+type Server struct {
+	Addr     string
+	GRPCAddr string
+	// AdminKey authorizes the admin endpoints.
+	AdminKey          string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// ShutdownTimeout bounds the wait for requests in flight on shutdown.
+	ShutdownTimeout time.Duration
+	TLS             TLS
+	// LogFile is where the log is written; empty writes to standard
+	// output.
+	LogFile string
+	// File is the configuration file the settings were read from, if any.
+	File string
+}
+
+// TLS names the certificate and key the HTTP API is served with. The gRPC
+// listener stays on cleartext HTTP/2 inside the deployment.
+type TLS struct {
+	CertFile string
+	KeyFile  string
+}
+
+// Enabled reports whether the HTTP API is served over TLS.
+func (t TLS) Enabled() bool {
+	return t.CertFile != ""
+}
+
+// LoadServer reads the listener settings and the configuration file. It
+// must run before the other loaders, which read the file's settings too.
+//
+// A setting comes from the first of: a command-line flag, the environment,
+// the configuration file and the default. The flags are -config, -addr,
+// -grpc-addr, -tls-cert, -tls-key and -log-file; the file is named by
+// -config or CONFIG_FILE. The file is read once, so runtime settings meant
+// to change while the service runs belong in RUNTIME_CONFIG_FILE.
+func LoadServer(defaults ServerDefaults, args []string) (Server, error) {
+	flags := flag.NewFlagSet(defaults.Name+"-api", flag.ExitOnError)
+	file := flags.String("config", "", "configuration `file` (CONFIG_FILE)")
+	addr := flags.String("addr", "", "HTTP listen `address` ("+addrKey(defaults.Name, "API")+")")
+	grpcAddr := new(string)
+	if defaults.GRPCAddr != "" {
+		grpcAddr = flags.String("grpc-addr", "", "gRPC listen `address` ("+addrKey(defaults.Name, "GRPC")+")")
+	}
+	certFile := flags.String("tls-cert", "", "TLS certificate `file` (TLS_CERT_FILE)")
+	keyFile := flags.String("tls-key", "", "TLS key `file` (TLS_KEY_FILE)")
+	logFile := flags.String("log-file", "", "log `file`, rotated by the rotate-logs operation (LOG_FILE)")
+	_ = flags.Parse(args)
+
+	cfg := Server{File: *file}
+	if cfg.File == "" {
+		cfg.File = os.Getenv("CONFIG_FILE")
+	}
+	var values map[string]string
+	if cfg.File != "" {
+		var err error
+		if values, err = readSettingsFile(cfg.File); err != nil {
+			return Server{}, err
+		}
+	}
+	useFile(values)
+
+	cfg.Addr = firstOf(*addr, envString(addrKey(defaults.Name, "API"), defaults.Addr))
+	if defaults.GRPCAddr != "" {
+		cfg.GRPCAddr = firstOf(*grpcAddr, envString(addrKey(defaults.Name, "GRPC"), defaults.GRPCAddr))
+	}
+	cfg.AdminKey = envString("ADMIN_API_KEY", "admin-secret")
+	cfg.LogFile = firstOf(*logFile, getenv("LOG_FILE"))
+	cfg.TLS = TLS{
+		CertFile: firstOf(*certFile, getenv("TLS_CERT_FILE")),
+		KeyFile:  firstOf(*keyFile, getenv("TLS_KEY_FILE")),
+	}
+
+	var err error
+	if cfg.ReadTimeout, err = envDuration("HTTP_READ_TIMEOUT", defaults.ReadTimeout); err != nil {
+		return Server{}, err
+	}
+	if cfg.ReadHeaderTimeout, err = envDuration("HTTP_READ_HEADER_TIMEOUT", cfg.ReadTimeout); err != nil {
+		return Server{}, err
+	}
+	if cfg.WriteTimeout, err = envDuration("HTTP_WRITE_TIMEOUT", defaults.WriteTimeout); err != nil {
+		return Server{}, err
+	}
+	if cfg.IdleTimeout, err = envDuration("HTTP_IDLE_TIMEOUT", 120*time.Second); err != nil {
+		return Server{}, err
+	}
+	if cfg.ShutdownTimeout, err = envDuration("SHUTDOWN_TIMEOUT", defaults.ShutdownTimeout); err != nil {
+		return Server{}, err
+	}
+	return cfg, cfg.validate(defaults.Name)
+}
+
+func (s Server) validate(name string) error {
+	addrs := map[string]string{addrKey(name, "API"): s.Addr}
+	if s.GRPCAddr != "" {
+		addrs[addrKey(name, "GRPC")] = s.GRPCAddr
+	}
+	for key, addr := range addrs {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return fmt.Errorf("config: %s: %w", key, err)
+		}
+	}
+	if s.GRPCAddr != "" && s.GRPCAddr == s.Addr {
+		return fmt.Errorf("config: %s and %s must differ, both are %s", addrKey(name, "API"), addrKey(name, "GRPC"), s.Addr)
+	}
+	if s.AdminKey == "" {
+		return fmt.Errorf("config: ADMIN_API_KEY must not be empty")
+	}
+	timeouts := []struct {
+		key   string
+		value time.Duration
+	}{
+		{"HTTP_READ_TIMEOUT", s.ReadTimeout},
+		{"HTTP_READ_HEADER_TIMEOUT", s.ReadHeaderTimeout},
+		{"HTTP_WRITE_TIMEOUT", s.WriteTimeout},
+		{"HTTP_IDLE_TIMEOUT", s.IdleTimeout},
+		{"SHUTDOWN_TIMEOUT", s.ShutdownTimeout},
+	}
+	for _, t := range timeouts {
+		if t.value <= 0 {
+			return fmt.Errorf("config: %s must be positive, got %s", t.key, t.value)
+		}
+	}
+	if (s.TLS.CertFile == "") != (s.TLS.KeyFile == "") {
+		return fmt.Errorf("config: TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	for key, path := range map[string]string{"TLS_CERT_FILE": s.TLS.CertFile, "TLS_KEY_FILE": s.TLS.KeyFile} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("config: %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// HTTPServer returns the server of the HTTP API with the configured
+// timeouts.
+func (s Server) HTTPServer(handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              s.Addr,
+		Handler:           handler,
+		ReadTimeout:       s.ReadTimeout,
+		ReadHeaderTimeout: s.ReadHeaderTimeout,
+		WriteTimeout:      s.WriteTimeout,
+		IdleTimeout:       s.IdleTimeout,
+	}
+}
+
+// ListenAndServe serves the HTTP API on server, over TLS when configured.
+func (s Server) ListenAndServe(server *http.Server) error {
+	if s.TLS.Enabled() {
+		return server.ListenAndServeTLS(s.TLS.CertFile, s.TLS.KeyFile)
+	}
+	return server.ListenAndServe()
+}
+
+func addrKey(name, listener string) string {
+	return strings.ToUpper(name) + "_" + listener + "_ADDR"
+}
+
+func firstOf(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
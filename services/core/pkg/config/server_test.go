@@ -0,0 +1,106 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/config"
+)
+
+var teacherDefaults = config.ServerDefaults{
+	Name:            "teacher",
+	Addr:            ":8080",
+	GRPCAddr:        ":9090",
+	ReadTimeout:     5 * time.Second,
+	WriteTimeout:    10 * time.Second,
+	ShutdownTimeout: 10 * time.Second,
+}
+
+func writeConfigFile(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	return path
+}
+
+func TestLoadServerPrefersFlagsThenEnvironmentThenFile(t *testing.T) {
+	path := writeConfigFile(t, `---
+# teacher-api
+teacher_api_addr: ":7000"
+TEACHER_GRPC_ADDR: ':7001'   # cleartext HTTP/2
+http_write_timeout: 30s
+admin_api_key: "file \"key\""
+auth_token_ttl: 2h
+`)
+	t.Setenv("TEACHER_GRPC_ADDR", ":8001")
+
+	cfg, err := config.LoadServer(teacherDefaults, []string{"-config", path, "-addr", "127.0.0.1:6000"})
+	if err != nil {
+		t.Fatalf("LoadServer failed: %v", err)
+	}
+	if cfg.Addr != "127.0.0.1:6000" || cfg.GRPCAddr != ":8001" || cfg.File != path {
+		t.Fatalf("expected the flag, then the environment, to win: %+v", cfg)
+	}
+	if cfg.WriteTimeout != 30*time.Second || cfg.AdminKey != `file "key"` {
+		t.Fatalf("expected settings from the file: %+v", cfg)
+	}
+	if cfg.ReadTimeout != 5*time.Second || cfg.ReadHeaderTimeout != 5*time.Second || cfg.IdleTimeout != 120*time.Second {
+		t.Fatalf("expected defaults for the rest: %+v", cfg)
+	}
+
+	// The other loaders read the file too.
+	auth, err := config.LoadAuth()
+	if err != nil || auth.TTL != 2*time.Hour {
+		t.Fatalf("expected LoadAuth to read the file, got %+v, %v", auth, err)
+	}
+
+	if _, err := config.LoadServer(teacherDefaults, nil); err != nil {
+		t.Fatalf("LoadServer failed: %v", err)
+	}
+	if auth, _ := config.LoadAuth(); auth.TTL != 12*time.Hour {
+		t.Fatalf("expected the file to be dropped without -config, got %s", auth.TTL)
+	}
+}
+
+func TestLoadServerValidates(t *testing.T) {
+	cert := writeConfigFile(t, "")
+	tests := map[string]struct {
+		env  map[string]string
+		args []string
+		file string
+		want string
+	}{
+		"bad address":       {env: map[string]string{"TEACHER_API_ADDR": "8080"}, want: "TEACHER_API_ADDR"},
+		"shared port":       {args: []string{"-grpc-addr", ":8080"}, want: "must differ"},
+		"zero timeout":      {env: map[string]string{"SHUTDOWN_TIMEOUT": "0s"}, want: "SHUTDOWN_TIMEOUT must be positive"},
+		"cert without key":  {args: []string{"-tls-cert", cert}, want: "set together"},
+		"missing key file":  {args: []string{"-tls-cert", cert, "-tls-key", cert + ".missing"}, want: "TLS_KEY_FILE"},
+		"nested file value": {file: "storage:\n  backend: sqlite\n", want: ":2: nested values"},
+		"unterminated":      {file: "admin_api_key: \"open\n", want: "unterminated"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			args := tc.args
+			if tc.file != "" {
+				args = append(args, "-config", writeConfigFile(t, tc.file))
+			}
+			_, err := config.LoadServer(teacherDefaults, args)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected an error mentioning %q, got %v", tc.want, err)
+			}
+		})
+	}
+
+	cfg, err := config.LoadServer(teacherDefaults, []string{"-tls-cert", cert, "-tls-key", cert})
+	if err != nil || !cfg.TLS.Enabled() {
+		t.Fatalf("expected TLS to be enabled, got %+v, %v", cfg.TLS, err)
+	}
+}
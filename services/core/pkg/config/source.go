@@ -0,0 +1,139 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// fileSettings holds the settings read from the configuration file by
+// LoadServer. The environment takes precedence over it.
+var fileSettings struct {
+	sync.RWMutex
+	values map[string]string
+}
+
+// getenv returns the setting named key from the environment, else from the
+// configuration file. Every loader reads its settings through it, so the
+// file can hold any setting the environment can.
+func getenv(key string) string {
+	if v, ok := os.LookupEnv(key); ok && v != "" {
+		return v
+	}
+	fileSettings.RLock()
+	defer fileSettings.RUnlock()
+	return fileSettings.values[key]
+}
+
+// useFile replaces the settings read from the configuration file.
+func useFile(values map[string]string) {
+	fileSettings.Lock()
+	fileSettings.values = values
+	fileSettings.Unlock()
+}
+
+// readSettingsFile reads a configuration file: a flat YAML mapping from
+// setting names to values, such as
+//
+//	# student-api
+//	student_api_addr: ":8081"
+//	data_store_backend: sqlite
+//	http_write_timeout: 10s
+//
+// Names are the environment variable names in either case. Lists are
+// written as in the environment, comma-separated in one value.
+func readSettingsFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if trimmed := strings.TrimSpace(line); trimmed == "" || trimmed == "---" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			return nil, fmt.Errorf("config: %s:%d: nested values are not supported", path, n)
+		}
+		key, raw, ok := strings.Cut(line, ":")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \"'") {
+			return nil, fmt.Errorf("config: %s:%d: expected \"name: value\"", path, n)
+		}
+		value, err := yamlScalar(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("config: %s:%d: %w", path, n, err)
+		}
+		values[strings.ToUpper(key)] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("config: %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// yamlScalar decodes a plain, single-quoted or double-quoted YAML scalar,
+// dropping a trailing comment.
+func yamlScalar(raw string) (string, error) {
+	switch {
+	case strings.HasPrefix(raw, `"`):
+		end := closingQuote(raw)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated string %s", raw)
+		}
+		value, err := strconv.Unquote(raw[:end+1])
+		if err != nil {
+			return "", fmt.Errorf("invalid string %s", raw[:end+1])
+		}
+		return value, checkTrailing(raw[end+1:])
+	case strings.HasPrefix(raw, "'"):
+		var b strings.Builder
+		for i := 1; i < len(raw); i++ {
+			if raw[i] != '\'' {
+				b.WriteByte(raw[i])
+				continue
+			}
+			if i+1 < len(raw) && raw[i+1] == '\'' {
+				b.WriteByte('\'')
+				i++
+				continue
+			}
+			return b.String(), checkTrailing(raw[i+1:])
+		}
+		return "", fmt.Errorf("unterminated string %s", raw)
+	case strings.HasPrefix(raw, "[") || strings.HasPrefix(raw, "{") || raw == "|" || raw == ">":
+		return "", fmt.Errorf("only plain values are supported, got %s", raw)
+	}
+	if i := strings.Index(raw, " #"); i >= 0 {
+		raw = strings.TrimSpace(raw[:i])
+	}
+	if raw == "~" || raw == "null" {
+		return "", nil
+	}
+	return raw, nil
+}
+
+func closingQuote(raw string) int {
+	for i := 1; i < len(raw); i++ {
+		switch raw[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+func checkTrailing(rest string) error {
+	if rest = strings.TrimSpace(rest); rest != "" && !strings.HasPrefix(rest, "#") {
+		return fmt.Errorf("unexpected %q after the value", rest)
+	}
+	return nil
+}
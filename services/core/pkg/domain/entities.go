@@ -11,17 +11,155 @@ type (
 	StudentID  string
 	TestID     string
 	QuestionID string
+	SectionID  string
 	AnswerID   string
 	ResultID   string
+
+	NotificationID     string
+	DigestItemID       string
+	QuestionCommentID  string
+	AnswerCommentID    string
+	RubricID           string
+	CriterionID        string
+	FeedbackTemplateID string
+	DelegationID       string
+	DeviceSessionID    string
+	BankQuestionID     string
+	DistrictID         string
+	DistrictStaffID    string
+	AuditEntryID       string
 )
 
-// School groups grades, classes, teachers, and tests.
-type School struct {
-	ID        SchoolID
+// Role distinguishes the kinds of users interacting with the system.
+type Role string
+
+const (
+	RoleStudent Role = "student"
+	RoleTeacher Role = "teacher"
+	// RoleDistrictStaff is district office staff, who view the schools of
+	// their district and modify only those they manage.
+	RoleDistrictStaff Role = "district_staff"
+	// RoleGuardian is a parent or guardian following one student read-only.
+	// Guardian tokens name the student as their subject.
+	RoleGuardian Role = "guardian"
+)
+
+// District groups schools under one administration.
+type District struct {
+	ID        DistrictID
 	Name      string
 	CreatedAt time.Time
 }
 
+// DistrictStaff works for a district office. Staff can view every school of
+// their district but only modify the schools listed in ManagedSchools.
+type DistrictStaff struct {
+	ID             DistrictStaffID
+	DistrictID     DistrictID
+	Name           string
+	Email          string
+	ManagedSchools []SchoolID
+	CreatedAt      time.Time
+}
+
+// Manages reports whether the staff member was granted changes to the school.
+func (s DistrictStaff) Manages(schoolID SchoolID) bool {
+	for _, id := range s.ManagedSchools {
+		if id == schoolID {
+			return true
+		}
+	}
+	return false
+}
+
+// School groups grades, classes, teachers, and tests. DistrictID is empty
+// for schools outside any district.
+type School struct {
+	ID         SchoolID
+	DistrictID DistrictID
+	Name       string
+	Settings   SchoolSettings
+	CreatedAt  time.Time
+}
+
+// SchoolSettings holds per-school configuration managed by administrators.
+type SchoolSettings struct {
+	Quotas    SchoolQuotas
+	Retention RetentionPolicy
+	// Timezone is the IANA zone the school's timestamps are shown in when a
+	// user has none of their own. Empty means UTC.
+	Timezone   string
+	Moderation ModerationSettings
+}
+
+// ModerationSettings decide which released results are held for a teacher
+// to review first. Zero turns a check off.
+type ModerationSettings struct {
+	// ScoreJump is how many percentage points a student's test score must
+	// exceed their average on earlier tests by to be held.
+	ScoreJump int
+	// PerfectClass is the fewest students who, all scoring full marks on a
+	// test, get its last result held.
+	PerfectClass int
+}
+
+// Valid reports whether the thresholds are in range.
+func (m ModerationSettings) Valid() bool {
+	return m.ScoreJump >= 0 && m.ScoreJump <= 100 && m.PerfectClass >= 0
+}
+
+// SchoolQuotas caps load a single school may put on a shared deployment.
+// Zero means unlimited.
+type SchoolQuotas struct {
+	SubmissionsPerMinute int
+	ExportJobsPerDay     int
+	// MaxResponseLength caps the characters of an answer's response;
+	// ResponseLengthPolicy decides what happens to longer ones.
+	MaxResponseLength    int
+	ResponseLengthPolicy LengthPolicy
+}
+
+// LengthPolicy is how an answer response over the school's cap is handled.
+type LengthPolicy string
+
+const (
+	// LengthPolicyReject refuses the answer. An empty policy rejects too.
+	LengthPolicyReject LengthPolicy = "reject"
+	// LengthPolicyTruncate keeps the first MaxResponseLength characters and
+	// marks the answer as truncated.
+	LengthPolicyTruncate LengthPolicy = "truncate"
+)
+
+// Valid reports whether no cap is negative and the length policy is known.
+func (q SchoolQuotas) Valid() bool {
+	if q.SubmissionsPerMinute < 0 || q.ExportJobsPerDay < 0 || q.MaxResponseLength < 0 {
+		return false
+	}
+	switch q.ResponseLengthPolicy {
+	case "", LengthPolicyReject, LengthPolicyTruncate:
+		return true
+	}
+	return false
+}
+
+// RetentionPolicy is how long a school keeps students' work, counted in
+// years since it last changed. Once AnswerYears pass, the responses and notes
+// of answers are erased while their scores stay; once ResultYears pass, the
+// answers are deleted together with their results. Zero keeps them forever.
+type RetentionPolicy struct {
+	AnswerYears int
+	ResultYears int
+}
+
+// Valid reports whether no period is negative and results are not kept for
+// less time than the answers they grade.
+func (p RetentionPolicy) Valid() bool {
+	if p.AnswerYears < 0 || p.ResultYears < 0 {
+		return false
+	}
+	return p.AnswerYears == 0 || p.ResultYears == 0 || p.ResultYears >= p.AnswerYears
+}
+
 // Grade belongs to a school and groups classes.
 type Grade struct {
 	ID        GradeID
@@ -40,61 +178,674 @@ type Class struct {
 
 // Teacher teaches within a school.
 type Teacher struct {
-	ID        TeacherID
-	SchoolID  SchoolID
-	Name      string
-	Email     string
+	ID            TeacherID
+	SchoolID      SchoolID
+	Name          string
+	DisplayName   string
+	Email         string
+	Notifications NotificationPreferences
+	// Timezone overrides the school's time zone for this teacher.
+	Timezone  string
 	CreatedAt time.Time
 }
 
 // Student belongs to a class and takes tests.
 type Student struct {
-	ID        StudentID
-	ClassID   ClassID
-	Name      string
-	Email     string
-	CreatedAt time.Time
+	ID          StudentID
+	ClassID     ClassID
+	Name        string
+	DisplayName string
+	Email       string
+	// GuardianEmail lets a parent or guardian sign in to follow the
+	// student. Empty when no guardian is registered.
+	GuardianEmail string
+	// Locale is the student's preferred language for translated tests.
+	Locale        string
+	Notifications NotificationPreferences
+	// Timezone overrides the school's time zone for this student.
+	Timezone string
+	// InvitationID is the token ID of the sign-in invitation handed out
+	// when the account was provisioned. It is cleared once the invitation
+	// is used; issuing a new one replaces it.
+	InvitationID string
+	CreatedAt    time.Time
+}
+
+// NotificationDelivery controls how notifications reach a user.
+type NotificationDelivery string
+
+const (
+	DeliveryImmediate NotificationDelivery = "immediate"
+	DeliveryDigest    NotificationDelivery = "digest"
+	DeliveryOff       NotificationDelivery = "off"
+)
+
+// NotificationPreferences lists the events a user muted and how the rest are
+// delivered. Users hear about every event until they mute it; an empty
+// Delivery means immediate.
+type NotificationPreferences struct {
+	Delivery           NotificationDelivery
+	MuteTestAssigned   bool
+	MuteResultReleased bool
 }
 
-// Test authored by a teacher and assigned to students.
+// Test authored by a teacher and assigned to students. Instructions and
+// section instructions are rich text stored verbatim for clients to render.
+// A nil PassingScore means the test has no pass/fail threshold. OpensAt and
+// ClosesAt bound the window in which students sit the test; a nil bound
+// leaves that side of the window open. Until it is Published a test is a
+// draft that only its teacher sees. Graders award each answer between zero
+// and the question's points unless UnboundedScores allows penalties and
+// extra credit. A positive DurationMinutes times the test: each student has
+// that long to answer from when they start it. Students who pass a test with
+// Certificates on may download a signed completion certificate. Extensions
+// keep the test open longer for individual students.
 type Test struct {
-	ID         TestID
-	TeacherID  TeacherID
-	Title      string
-	Published  bool
+	ID              TestID
+	TeacherID       TeacherID
+	Title           string
+	Instructions    string
+	Sections        []Section
+	PassingScore    *Score
+	Curve           *Curve
+	Published       bool
+	GradingDeadline *time.Time
+	OpensAt         *time.Time
+	ClosesAt        *time.Time
+	Limits          SubmissionLimits
+	UnboundedScores bool
+	DurationMinutes int
+	Certificates    bool
+	ResultsLink     *ResultsLink
+	Extensions      []DeadlineExtension
+	// Version is 1 for a new test and grows with every saved change, so an
+	// edit made from an older copy can be refused. Tests saved before
+	// versions were kept start at 0.
+	Version    int
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
 	AssignedTo []StudentID
 }
 
+// SubmissionLimits protect a test from scripted answer spam. Zero values
+// disable a limit.
+type SubmissionLimits struct {
+	// PerQuestionPerMinute caps how often a student may submit an answer to
+	// one question each minute.
+	PerQuestionPerMinute int
+	// DuplicateWindow is how long after an answer was saved an identical
+	// resubmission is refused.
+	DuplicateWindow time.Duration
+}
+
+// Duration returns how long each student has to answer a timed test, and
+// false for an untimed one.
+func (t Test) Duration() (time.Duration, bool) {
+	if t.DurationMinutes <= 0 {
+		return 0, false
+	}
+	return time.Duration(t.DurationMinutes) * time.Minute, true
+}
+
+// Window returns the bounds of a test scheduled for a fixed window, and false
+// when either bound is missing.
+func (t Test) Window() (opensAt, closesAt time.Time, ok bool) {
+	if t.OpensAt == nil || t.ClosesAt == nil {
+		return time.Time{}, time.Time{}, false
+	}
+	return *t.OpensAt, *t.ClosesAt, true
+}
+
+// DeadlineExtension lets one student sit a test until ClosesAt, after the
+// test has closed for the others. GrantedBy is the teacher who granted it
+// and Reason why, such as an illness or an accommodation.
+type DeadlineExtension struct {
+	StudentID StudentID
+	ClosesAt  time.Time
+	Reason    string
+	GrantedBy TeacherID
+	GrantedAt time.Time
+}
+
+// Extension returns the deadline extension granted to the student, if any.
+func (t Test) Extension(studentID StudentID) (DeadlineExtension, bool) {
+	for _, ext := range t.Extensions {
+		if ext.StudentID == studentID {
+			return ext, true
+		}
+	}
+	return DeadlineExtension{}, false
+}
+
+// ClosesAtFor returns when the test closes for the student: the later of
+// ClosesAt and the student's extension. It is nil when the test never
+// closes.
+func (t Test) ClosesAtFor(studentID StudentID) *time.Time {
+	if t.ClosesAt == nil {
+		return nil
+	}
+	closes := *t.ClosesAt
+	if ext, ok := t.Extension(studentID); ok && ext.ClosesAt.After(closes) {
+		closes = ext.ClosesAt
+	}
+	return &closes
+}
+
+// Availability tells whether students can sit a test at a given time.
+type Availability string
+
+const (
+	// TestUpcoming means the test has not opened yet.
+	TestUpcoming Availability = "upcoming"
+	// TestOpen means answers are accepted.
+	TestOpen Availability = "open"
+	// TestClosed means the test has closed.
+	TestClosed Availability = "closed"
+)
+
+// AvailabilityAt reports whether the test is upcoming, open or closed at now.
+// The window includes OpensAt and excludes ClosesAt.
+func (t Test) AvailabilityAt(now time.Time) Availability {
+	switch {
+	case t.OpensAt != nil && now.Before(*t.OpensAt):
+		return TestUpcoming
+	case t.ClosesAt != nil && !now.Before(*t.ClosesAt):
+		return TestClosed
+	default:
+		return TestOpen
+	}
+}
+
+// AvailabilityFor is AvailabilityAt for one student, whose extension may
+// keep the test open after it closed for the others.
+func (t Test) AvailabilityFor(studentID StudentID, now time.Time) Availability {
+	t.ClosesAt = t.ClosesAtFor(studentID)
+	return t.AvailabilityAt(now)
+}
+
+// Reassign removes the students in remove from AssignedTo and appends those
+// in add, keeping each student once and the others in their order.
+func (t *Test) Reassign(add, remove []StudentID) {
+	removed := make(map[StudentID]bool, len(remove))
+	for _, studentID := range remove {
+		removed[studentID] = true
+	}
+	seen := make(map[StudentID]bool, len(t.AssignedTo)+len(add))
+	assigned := make([]StudentID, 0, len(t.AssignedTo)+len(add))
+	for _, studentID := range append(append([]StudentID(nil), t.AssignedTo...), add...) {
+		if removed[studentID] || seen[studentID] {
+			continue
+		}
+		seen[studentID] = true
+		assigned = append(assigned, studentID)
+	}
+	t.AssignedTo = assigned
+}
+
 // Question represents a test question.
 type Question struct {
-	ID        QuestionID
-	TestID    TestID
-	Sequence  int
-	Prompt    string
-	Points    int
-	CreatedAt time.Time
+	ID         QuestionID
+	TestID     TestID
+	SectionID  SectionID
+	Sequence   int
+	Prompt     string
+	Points     Points
+	Difficulty Difficulty
+	Type       QuestionType
+	Choices    []Choice
+	// ExpectedResponse is the correct answer of an objective question, which
+	// lets it be graded automatically. Empty leaves grading to the teacher.
+	ExpectedResponse string
+	// Translations holds the question in other languages, keyed by
+	// canonical locale (see CanonicalLocale).
+	Translations map[string]Translation
+	// BankQuestionID is the bank question this question was copied from,
+	// if any. Later edits to the bank question do not reach the copy.
+	BankQuestionID BankQuestionID
+	CreatedAt      time.Time
+}
+
+// BankQuestion is a standalone question in a teacher's question bank. Tests
+// reference bank questions by copying them, so a bank question can be edited
+// or deleted without changing the tests that used it. A Shared question is
+// also in its school's pool, where every teacher of the school can find and
+// use it; only its owner edits it.
+type BankQuestion struct {
+	ID               BankQuestionID
+	TeacherID        TeacherID
+	Prompt           string
+	Points           Points
+	Difficulty       Difficulty
+	Type             QuestionType
+	Choices          []Choice
+	ExpectedResponse string
+	Tags             []string
+	Shared           bool
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// Question returns the bank question as a question of a test, ready to be
+// validated and copied into a test.
+func (b BankQuestion) Question() Question {
+	return Question{
+		Prompt:           b.Prompt,
+		Points:           b.Points,
+		Difficulty:       b.Difficulty,
+		Type:             b.Type,
+		Choices:          append([]Choice(nil), b.Choices...),
+		ExpectedResponse: b.ExpectedResponse,
+		BankQuestionID:   b.ID,
+	}
+}
+
+// QuestionType decides what a valid answer to a question looks like.
+// Questions stored before types existed have an empty type and behave as
+// free text.
+type QuestionType string
+
+const (
+	QuestionFreeText       QuestionType = "free_text"
+	QuestionMultipleChoice QuestionType = "multiple_choice"
+	QuestionTrueFalse      QuestionType = "true_false"
+)
+
+// Choice is one option of a multiple-choice question. Students answer with
+// its Key.
+type Choice struct {
+	Key   string
+	Label string
+}
+
+// AnswerType returns the type of the question, treating an empty type as
+// free text.
+func (q Question) AnswerType() QuestionType {
+	if q.Type == "" {
+		return QuestionFreeText
+	}
+	return q.Type
+}
+
+// Difficulty is a teacher's rating of how hard a question is. Untagged
+// questions have an empty difficulty.
+type Difficulty string
+
+const (
+	DifficultyEasy   Difficulty = "easy"
+	DifficultyMedium Difficulty = "medium"
+	DifficultyHard   Difficulty = "hard"
+)
+
+// Section groups consecutive questions of a test under shared instructions.
+type Section struct {
+	ID           SectionID
+	Title        string
+	Instructions string
 }
 
-// Answer submitted by a student for a question.
+// Answer submitted by a student for a question. Note is the student's optional
+// remark on it, such as how they read the question.
 type Answer struct {
 	ID         AnswerID
 	TestID     TestID
 	QuestionID QuestionID
 	StudentID  StudentID
 	Response   string
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
+	Note       string
+	// Truncated reports that the response was cut to the school's maximum
+	// length when it was submitted.
+	Truncated bool
+	// PurgedAt is when the school's retention policy erased the response
+	// and note, or nil while they are kept.
+	PurgedAt *time.Time
+	// Revisions holds the earlier versions of the response, oldest first.
+	// Repositories maintain it on UpsertAnswer; callers leave it alone.
+	Revisions []AnswerRevision
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// MaxAnswerRevisions caps how many earlier versions an answer keeps; the
+// oldest are dropped first.
+const MaxAnswerRevisions = 50
+
+// AnswerRevision is an earlier version of an answer, kept when the student
+// saved a different response or note over it. Revisions are numbered from 1
+// in the order they were replaced.
+type AnswerRevision struct {
+	Revision  int
+	Response  string
+	Note      string
+	Truncated bool
+	SavedAt   time.Time
+}
+
+// CurrentRevision returns the number the answer's current version would
+// have as a revision.
+func (a Answer) CurrentRevision() int {
+	if len(a.Revisions) == 0 {
+		return 1
+	}
+	return a.Revisions[len(a.Revisions)-1].Revision + 1
+}
+
+// Revise returns the answer with the revisions of prev, the stored version
+// of the same answer, plus prev itself when the answer changes its response
+// or note. Purged answers keep no revisions, and a nil prev starts a new
+// history.
+func (a Answer) Revise(prev *Answer) Answer {
+	a.Revisions = nil
+	if prev == nil || prev.ID != a.ID || a.PurgedAt != nil {
+		return a
+	}
+	revisions := append([]AnswerRevision(nil), prev.Revisions...)
+	if prev.Response != a.Response || prev.Note != a.Note {
+		revisions = append(revisions, AnswerRevision{
+			Revision:  prev.CurrentRevision(),
+			Response:  prev.Response,
+			Note:      prev.Note,
+			Truncated: prev.Truncated,
+			SavedAt:   prev.UpdatedAt,
+		})
+	}
+	if len(revisions) > MaxAnswerRevisions {
+		revisions = revisions[len(revisions)-MaxAnswerRevisions:]
+	}
+	if len(revisions) > 0 {
+		a.Revisions = revisions
+	}
+	return a
+}
+
+// Revision returns the earlier version numbered n, or false when it was never
+// kept or has been dropped.
+func (a Answer) Revision(n int) (AnswerRevision, bool) {
+	for _, rev := range a.Revisions {
+		if rev.Revision == n {
+			return rev, true
+		}
+	}
+	return AnswerRevision{}, false
+}
+
+// TestSession is a student's working state while taking a test, kept apart
+// from the answers so it can be restored when the student resumes. StartedAt
+// is when the student started the test, which starts the timer of a timed
+// test.
+type TestSession struct {
+	TestID    TestID
+	StudentID StudentID
+	Flagged   []QuestionID
+	StartedAt *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
 
-// Result represents grading feedback for an answer.
+// EndsAt returns when the student's time for a timed test runs out, and
+// false when the test is untimed or not started yet.
+func (s TestSession) EndsAt(test Test) (time.Time, bool) {
+	duration, timed := test.Duration()
+	if !timed || s.StartedAt == nil {
+		return time.Time{}, false
+	}
+	return s.StartedAt.Add(duration), true
+}
+
+// Submission records that a student finalized a test. Their answers to it
+// can no longer be changed afterwards.
+type Submission struct {
+	TestID      TestID
+	StudentID   StudentID
+	SubmittedAt time.Time
+}
+
+// Result represents grading feedback for an answer. RawScore holds the score as graded while a curve is applied to Score.
 type Result struct {
 	ID        ResultID
 	AnswerID  AnswerID
-	Score     int
+	Score     Score
+	RawScore  *Score
 	Feedback  string
 	Completed bool
+	// GradedBy is the teacher who last graded the answer. It is empty for
+	// automatic grading and for results graded before it was recorded.
+	GradedBy TeacherID
+	// Hold keeps a result the teacher marked completed from the student
+	// until the teacher reviews it; the result stays incomplete meanwhile.
+	Hold *ResultHold
+	// Version is 1 for the first grade and grows with every saved change,
+	// like Test.Version.
+	Version   int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// HoldReason is why a result was held for review.
+type HoldReason string
+
+const (
+	// HoldScoreJump flags a test score far above the student's history.
+	HoldScoreJump HoldReason = "score_jump"
+	// HoldPerfectClass flags a test on which every student scored full
+	// marks.
+	HoldPerfectClass HoldReason = "perfect_class"
+)
+
+// ResultHold records why and when a result was held for review.
+type ResultHold struct {
+	Reasons   []HoldReason
+	FlaggedAt time.Time
+}
+
+// CurveKind identifies how a curve adjusts scores.
+type CurveKind string
+
+const (
+	// CurveAdd adds Points to every score.
+	CurveAdd CurveKind = "add"
+	// CurveScaleToTop scales scores of each question so the top score
+	// becomes full points.
+	CurveScaleToTop CurveKind = "scale_to_top"
+	// CurveMapping replaces scores found in Mapping.
+	CurveMapping CurveKind = "mapping"
+)
+
+// Curve is a score adjustment applied to every result of a test. Adjusted
+// scores are clamped to the question's points.
+type Curve struct {
+	Kind      CurveKind
+	Points    Score
+	Mapping   map[Score]Score
+	AppliedBy TeacherID
+	AppliedAt time.Time
+}
+
+// ResultsLink shares a test's anonymized results with anyone holding the
+// token, such as parents, until it expires or the teacher revokes it.
+type ResultsLink struct {
+	Token     string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// Active reports whether the link may still be followed at now.
+func (l *ResultsLink) Active(now time.Time) bool {
+	return l != nil && now.Before(l.ExpiresAt)
+}
+
+// Rubric is a teacher's reusable grading scale made of scored criteria.
+type Rubric struct {
+	ID        RubricID
+	TeacherID TeacherID
+	Title     string
+	Criteria  []RubricCriterion
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
+
+// RubricCriterion is one scored aspect of a rubric.
+type RubricCriterion struct {
+	ID          CriterionID
+	Title       string
+	Description string
+	Points      Points
+}
+
+// FeedbackTemplate is a reusable feedback comment from a teacher's bank. It
+// may be tied to a rubric criterion it is typically given for.
+type FeedbackTemplate struct {
+	ID          FeedbackTemplateID
+	TeacherID   TeacherID
+	RubricID    RubricID
+	CriterionID CriterionID
+	Title       string
+	Body        string
+	CreatedAt   time.Time
+}
+
+// Delegation lets a substitute teacher work on another teacher's tests and
+// grading until ExpiresAt, for example during sick leave.
+type Delegation struct {
+	ID         DelegationID
+	TeacherID  TeacherID
+	DelegateID TeacherID
+	ExpiresAt  time.Time
+	CreatedAt  time.Time
+}
+
+// Active reports whether the delegation still grants access at now.
+func (d Delegation) Active(now time.Time) bool {
+	return now.Before(d.ExpiresAt)
+}
+
+// DeviceSession is a sign-in of a student, or of their guardian, on one
+// device. Its ID is the ID of the access token issued at sign-in, so a
+// revoked session stops that token before it expires.
+type DeviceSession struct {
+	ID        DeviceSessionID
+	StudentID StudentID
+	Role      Role
+	// Device is the user agent that signed in and Client its IP address.
+	Device    string
+	Client    string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+}
+
+// Active reports whether the session's token is still accepted at now.
+func (s DeviceSession) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// Notification is an in-app message kept in a user's inbox.
+type Notification struct {
+	ID          NotificationID
+	Role        Role
+	RecipientID string
+	Kind        string
+	Subject     string
+	Body        string
+	CreatedAt   time.Time
+	ReadAt      *time.Time
+}
+
+// DigestItem is a notification queued for the next daily digest email to
+// Email, kept until the digest is sent.
+type DigestItem struct {
+	ID          DigestItemID
+	Role        Role
+	RecipientID string
+	Email       string
+	Kind        string
+	Subject     string
+	Body        string
+	OccurredAt  time.Time
+}
+
+// QuestionComment is an authoring note left by a teacher on a question.
+// Replies reference the comment they answer through ParentID.
+type QuestionComment struct {
+	ID         QuestionCommentID
+	TestID     TestID
+	QuestionID QuestionID
+	ParentID   QuestionCommentID
+	AuthorID   TeacherID
+	Body       string
+	Mentions   []TeacherID
+	CreatedAt  time.Time
+}
+
+// AnswerComment is a message in the feedback thread on an answer, between
+// the teachers of the test and the student who gave the answer. Replies
+// reference the comment they answer through ParentID. ReadAt is when the
+// other side first read it: the student for a teacher's comment, a teacher
+// for the student's.
+type AnswerComment struct {
+	ID         AnswerCommentID
+	TestID     TestID
+	AnswerID   AnswerID
+	StudentID  StudentID
+	ParentID   AnswerCommentID
+	AuthorRole Role
+	AuthorID   string
+	Body       string
+	CreatedAt  time.Time
+	ReadAt     *time.Time
+}
+
+// AuditAction names an operation recorded in the audit trail.
+type AuditAction string
+
+const (
+	AuditTestCreated      AuditAction = "test.created"
+	AuditTestPublished    AuditAction = "test.published"
+	AuditAnswerSubmitted  AuditAction = "answer.submitted"
+	AuditAnswerDeleted    AuditAction = "answer.deleted"
+	AuditGradeChanged     AuditAction = "grade.changed"
+	AuditTestExtended     AuditAction = "test.extended"
+	AuditExtensionGranted AuditAction = "extension.granted"
+	AuditExtensionRevoked AuditAction = "extension.revoked"
+
+	AuditTestUpdated        AuditAction = "test.updated"
+	AuditTestUnpublished    AuditAction = "test.unpublished"
+	AuditQuestionUpdated    AuditAction = "question.updated"
+	AuditQuestionsReordered AuditAction = "questions.reordered"
+	AuditAssigneeAdded      AuditAction = "assignee.added"
+	AuditAssigneeRemoved    AuditAction = "assignee.removed"
+	AuditCurveApplied       AuditAction = "curve.applied"
+	AuditCurveReverted      AuditAction = "curve.reverted"
+	AuditAnswerAutograded   AuditAction = "answer.autograded"
+	AuditAnswerImported     AuditAction = "answer.imported"
+	AuditAnswerRestored     AuditAction = "answer.restored"
+	AuditAnswerErased       AuditAction = "answer.erased"
+	AuditAnswerPurged       AuditAction = "answer.purged"
+	AuditResultReleased     AuditAction = "result.released"
+	AuditDelegationGranted  AuditAction = "delegation.granted"
+	AuditDelegationRevoked  AuditAction = "delegation.revoked"
+)
+
+// AuditEntry records who performed an operation on a test and when.
+// Subject is the ID of the record the operation changed, such as the test
+// or the answer. OldScore and NewScore are set on grade changes; OldScore
+// is nil for an answer's first grade. Deadline changes set OldClosesAt and
+// NewClosesAt, nil when there was or is no extension, and the Reason the
+// teacher gave; other entries describe the change in Reason, such as the
+// setting of a test that was updated. Operations the service performs on
+// its own, such as autograding and retention, have no PrincipalRole and
+// name the job as PrincipalID.
+type AuditEntry struct {
+	ID            AuditEntryID
+	Action        AuditAction
+	PrincipalRole Role
+	PrincipalID   string
+	TestID        TestID
+	StudentID     StudentID
+	Subject       string
+	OldScore      *Score
+	NewScore      *Score
+	OldClosesAt   *time.Time
+	NewClosesAt   *time.Time
+	Reason        string
+	CreatedAt     time.Time
+}
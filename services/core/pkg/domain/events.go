@@ -0,0 +1,61 @@
+package domain
+
+// Event is a fact recorded by the assessment workflow once it is stored.
+// Read models apply events to keep their projections current instead of
+// querying the repositories. Every event belongs to a test, its aggregate.
+type Event interface {
+	Aggregate() TestID
+}
+
+// TestCreated records a new test with its questions and assignments.
+type TestCreated struct {
+	Test      Test
+	Questions []Question
+}
+
+// TestPublished records a draft test opening to its assigned students.
+type TestPublished struct {
+	Test Test
+}
+
+// TestChanged records a change to a test that may affect its questions,
+// assignments or scores as a whole, such as an edited question or a curve.
+type TestChanged struct {
+	TestID TestID
+}
+
+// AssigneesChanged records students added to or removed from a test after
+// it was created. Test is the test with its new assignments.
+type AssigneesChanged struct {
+	Test    Test
+	Added   []StudentID
+	Removed []StudentID
+}
+
+// AnswerSaved records a student's new or revised answer.
+type AnswerSaved struct {
+	Answer Answer
+}
+
+// AnswerDeleted records the removal of a student's answer and its result.
+type AnswerDeleted struct {
+	TestID     TestID
+	QuestionID QuestionID
+	StudentID  StudentID
+}
+
+// ResultSaved records the grading of an answer.
+type ResultSaved struct {
+	TestID     TestID
+	QuestionID QuestionID
+	StudentID  StudentID
+	Result     Result
+}
+
+func (e TestCreated) Aggregate() TestID      { return e.Test.ID }
+func (e TestPublished) Aggregate() TestID    { return e.Test.ID }
+func (e TestChanged) Aggregate() TestID      { return e.TestID }
+func (e AssigneesChanged) Aggregate() TestID { return e.Test.ID }
+func (e AnswerSaved) Aggregate() TestID      { return e.Answer.TestID }
+func (e AnswerDeleted) Aggregate() TestID    { return e.TestID }
+func (e ResultSaved) Aggregate() TestID      { return e.TestID }
@@ -0,0 +1,271 @@
+package domain
+
+import (
+	"fmt"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+)
+
+// MaxInstructionsLength caps the characters of test and section instructions.
+const MaxInstructionsLength = 10000
+
+// MaxChoices caps the choices of a multiple-choice question.
+const MaxChoices = 26
+
+// InvariantError reports an entity that breaks a domain rule. Err is the
+// sentinel for the kind of entity, so callers can still match it with
+// errors.Is while the message names the offending field.
+type InvariantError struct {
+	Field  string
+	Reason string
+	Err    error
+}
+
+func (e *InvariantError) Error() string {
+	return fmt.Sprintf("%s: %s %s", e.Err, e.Field, e.Reason)
+}
+
+func (e *InvariantError) Unwrap() error {
+	return e.Err
+}
+
+// NewTest builds a test without sections or assignments, enforcing the test
+// invariants.
+func NewTest(id TestID, teacherID TeacherID, title, instructions string, now time.Time) (*Test, error) {
+	test := &Test{
+		ID:           id,
+		TeacherID:    teacherID,
+		Title:        title,
+		Instructions: instructions,
+		Version:      1,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := test.Validate(); err != nil {
+		return nil, err
+	}
+	return test, nil
+}
+
+// Validate checks that the test has an owner and a title, that neither its
+// instructions nor its sections' exceed MaxInstructionsLength, and that its
+// window closes after it opens.
+func (t *Test) Validate() error {
+	if t.TeacherID == "" {
+		return invalidTest("teacher_id", "is required")
+	}
+	if strings.TrimSpace(t.Title) == "" {
+		return invalidTest("title", "must not be empty")
+	}
+	if utf8.RuneCountInString(t.Instructions) > MaxInstructionsLength {
+		return invalidTest("instructions", fmt.Sprintf("must be at most %d characters", MaxInstructionsLength))
+	}
+	for _, sec := range t.Sections {
+		if err := sec.Validate(); err != nil {
+			return err
+		}
+	}
+	if t.OpensAt != nil && t.ClosesAt != nil && !t.ClosesAt.After(*t.OpensAt) {
+		return invalidTest("closes_at", "must be after opens_at")
+	}
+	if t.DurationMinutes < 0 {
+		return invalidTest("duration_minutes", "must not be negative")
+	}
+	return nil
+}
+
+// NewSection builds a test section, enforcing the section invariants.
+func NewSection(id SectionID, title, instructions string) (Section, error) {
+	sec := Section{ID: id, Title: title, Instructions: instructions}
+	if err := sec.Validate(); err != nil {
+		return Section{}, err
+	}
+	return sec, nil
+}
+
+// Validate checks that the section has a title and bounded instructions.
+func (s Section) Validate() error {
+	if strings.TrimSpace(s.Title) == "" {
+		return invalidTest("section.title", "must not be empty")
+	}
+	if utf8.RuneCountInString(s.Instructions) > MaxInstructionsLength {
+		return invalidTest("section.instructions", fmt.Sprintf("must be at most %d characters", MaxInstructionsLength))
+	}
+	return nil
+}
+
+// NewQuestion builds a question of a test, enforcing the question invariants.
+func NewQuestion(id QuestionID, testID TestID, sequence int, prompt string, points Points, difficulty Difficulty, now time.Time) (*Question, error) {
+	q := &Question{
+		ID:         id,
+		TestID:     testID,
+		Sequence:   sequence,
+		Prompt:     prompt,
+		Points:     points,
+		Difficulty: difficulty,
+		CreatedAt:  now,
+	}
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Validate checks that the question has a prompt, is worth a positive number
+// of points and carries a known difficulty and type. Multiple-choice
+// questions need between two and MaxChoices choices with distinct keys;
+// other types take no choices. An expected response must be a valid answer,
+// and translations must match the question's choices.
+func (q *Question) Validate() error {
+	if strings.TrimSpace(q.Prompt) == "" {
+		return invalidQuestion("prompt", "must not be empty")
+	}
+	if !q.Points.Valid() {
+		return invalidQuestion("points", "must be positive")
+	}
+	if !q.Difficulty.Valid() {
+		return invalidQuestion("difficulty", "must be easy, medium or hard")
+	}
+	switch q.Type {
+	case "", QuestionFreeText, QuestionTrueFalse:
+		if len(q.Choices) > 0 {
+			return invalidQuestion("choices", "are only allowed for multiple_choice questions")
+		}
+	case QuestionMultipleChoice:
+		if len(q.Choices) < 2 || len(q.Choices) > MaxChoices {
+			return invalidQuestion("choices", fmt.Sprintf("must number between 2 and %d", MaxChoices))
+		}
+		seen := make(map[string]struct{}, len(q.Choices))
+		for _, c := range q.Choices {
+			if strings.TrimSpace(c.Key) == "" || strings.TrimSpace(c.Label) == "" {
+				return invalidQuestion("choices", "need a key and a label")
+			}
+			if _, dup := seen[c.Key]; dup {
+				return invalidQuestion("choices", "must have distinct keys")
+			}
+			seen[c.Key] = struct{}{}
+		}
+	default:
+		return invalidQuestion("type", "must be free_text, multiple_choice or true_false")
+	}
+	if q.ExpectedResponse != "" && q.ValidateResponse(q.ExpectedResponse) != nil {
+		return invalidQuestion("expected_response", "must be a valid answer to the question")
+	}
+	return q.validateTranslations()
+}
+
+// NormalizeSequences orders questions by their sequence and renumbers them
+// from one without gaps. Sequences must be positive and distinct; gaps are
+// closed rather than rejected.
+func NormalizeSequences(questions []Question) error {
+	seen := make(map[int]struct{}, len(questions))
+	for _, q := range questions {
+		if q.Sequence <= 0 {
+			return invalidQuestion("sequence", "must be positive")
+		}
+		if _, dup := seen[q.Sequence]; dup {
+			return invalidQuestion("sequence", fmt.Sprintf("%d is used more than once", q.Sequence))
+		}
+		seen[q.Sequence] = struct{}{}
+	}
+	sort.Slice(questions, func(i, j int) bool {
+		return questions[i].Sequence < questions[j].Sequence
+	})
+	for i := range questions {
+		questions[i].Sequence = i + 1
+	}
+	return nil
+}
+
+// ValidateResponse checks that response is a possible answer to the
+// question: a choice key for multiple choice, "true" or "false" for
+// true/false, and anything for free text.
+func (q Question) ValidateResponse(response string) error {
+	switch q.AnswerType() {
+	case QuestionMultipleChoice:
+		for _, c := range q.Choices {
+			if c.Key == response {
+				return nil
+			}
+		}
+		return invalidAnswer("response", "must be the key of one of the choices")
+	case QuestionTrueFalse:
+		if response != "true" && response != "false" {
+			return invalidAnswer("response", "must be true or false")
+		}
+	}
+	return nil
+}
+
+// Valid reports whether d is a known difficulty or empty.
+func (d Difficulty) Valid() bool {
+	switch d {
+	case "", DifficultyEasy, DifficultyMedium, DifficultyHard:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewTeacher builds a teacher record, enforcing the person invariants.
+func NewTeacher(id TeacherID, schoolID SchoolID, name, email string, now time.Time) (*Teacher, error) {
+	if err := validatePerson(name, email); err != nil {
+		return nil, err
+	}
+	return &Teacher{ID: id, SchoolID: schoolID, Name: name, Email: email, CreatedAt: now}, nil
+}
+
+// Validate checks that the teacher has a name and a valid email address.
+func (t *Teacher) Validate() error {
+	return validatePerson(t.Name, t.Email)
+}
+
+// NewStudent builds a student record, enforcing the person invariants.
+func NewStudent(id StudentID, classID ClassID, name, email string, now time.Time) (*Student, error) {
+	if err := validatePerson(name, email); err != nil {
+		return nil, err
+	}
+	return &Student{ID: id, ClassID: classID, Name: name, Email: email, CreatedAt: now}, nil
+}
+
+// Validate checks that the student has a name and a valid email address.
+func (s *Student) Validate() error {
+	return validatePerson(s.Name, s.Email)
+}
+
+func validatePerson(name, email string) error {
+	if strings.TrimSpace(name) == "" {
+		return &InvariantError{Field: "name", Reason: "must not be empty", Err: errs.ErrInvalidProfile}
+	}
+	if !ValidEmail(email) {
+		return &InvariantError{Field: "email", Reason: "must be a plain address such as name@example.com", Err: errs.ErrInvalidProfile}
+	}
+	return nil
+}
+
+// ValidEmail reports whether email is a bare address with a domain part,
+// without a display name or angle brackets.
+func ValidEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || addr.Name != "" {
+		return false
+	}
+	return strings.Contains(email[strings.LastIndex(email, "@")+1:], ".")
+}
+
+func invalidTest(field, reason string) error {
+	return &InvariantError{Field: field, Reason: reason, Err: errs.ErrInvalidTest}
+}
+
+func invalidQuestion(field, reason string) error {
+	return &InvariantError{Field: field, Reason: reason, Err: errs.ErrInvalidQuestion}
+}
+
+func invalidAnswer(field, reason string) error {
+	return &InvariantError{Field: field, Reason: reason, Err: errs.ErrInvalidAnswer}
+}
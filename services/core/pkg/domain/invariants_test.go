@@ -0,0 +1,130 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+)
+
+func TestNewQuestionInvariants(t *testing.T) {
+	now := time.Now()
+	cases := []struct {
+		name       string
+		prompt     string
+		points     domain.Points
+		difficulty domain.Difficulty
+		field      string
+	}{
+		{"empty prompt", "  ", 1, "", "prompt"},
+		{"zero points", "Q", 0, "", "points"},
+		{"unknown difficulty", "Q", 1, "brutal", "difficulty"},
+	}
+	for _, tc := range cases {
+		_, err := domain.NewQuestion("q", "t", 1, tc.prompt, tc.points, tc.difficulty, now)
+		var invariant *domain.InvariantError
+		if !errors.As(err, &invariant) || invariant.Field != tc.field || !errors.Is(err, errs.ErrInvalidQuestion) {
+			t.Fatalf("%s: expected invariant error on %s, got %v", tc.name, tc.field, err)
+		}
+	}
+
+	if _, err := domain.NewQuestion("q", "t", 1, "2+2?", 3, domain.DifficultyEasy, now); err != nil {
+		t.Fatalf("NewQuestion failed: %v", err)
+	}
+}
+
+func TestQuestionTypes(t *testing.T) {
+	choices := []domain.Choice{{Key: "a", Label: "Paris"}, {Key: "b", Label: "Rome"}}
+	cases := []struct {
+		name    string
+		typ     domain.QuestionType
+		choices []domain.Choice
+		field   string
+	}{
+		{"unknown type", "essay", nil, "type"},
+		{"one choice", domain.QuestionMultipleChoice, choices[:1], "choices"},
+		{"duplicate keys", domain.QuestionMultipleChoice, []domain.Choice{{Key: "a", Label: "x"}, {Key: "a", Label: "y"}}, "choices"},
+		{"choices on true/false", domain.QuestionTrueFalse, choices, "choices"},
+	}
+	for _, tc := range cases {
+		q := domain.Question{Prompt: "Q", Points: 1, Type: tc.typ, Choices: tc.choices}
+		var invariant *domain.InvariantError
+		if err := q.Validate(); !errors.As(err, &invariant) || invariant.Field != tc.field {
+			t.Fatalf("%s: expected invariant error on %s, got %v", tc.name, tc.field, err)
+		}
+	}
+
+	mc := domain.Question{Prompt: "Capital of France?", Points: 1, Type: domain.QuestionMultipleChoice, Choices: choices}
+	if err := mc.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if err := mc.ValidateResponse("a"); err != nil {
+		t.Fatalf("expected a choice key to be accepted, got %v", err)
+	}
+	if err := mc.ValidateResponse("Paris"); !errors.Is(err, errs.ErrInvalidAnswer) {
+		t.Fatalf("expected ErrInvalidAnswer for a label, got %v", err)
+	}
+	tf := domain.Question{Prompt: "The earth is flat", Points: 1, Type: domain.QuestionTrueFalse}
+	if err := tf.ValidateResponse("maybe"); !errors.Is(err, errs.ErrInvalidAnswer) {
+		t.Fatalf("expected ErrInvalidAnswer for a non-boolean, got %v", err)
+	}
+	legacy := domain.Question{Prompt: "Explain", Points: 1}
+	if legacy.AnswerType() != domain.QuestionFreeText || legacy.ValidateResponse("anything") != nil {
+		t.Fatalf("expected an untyped question to behave as free text")
+	}
+}
+
+func TestNewTestInvariants(t *testing.T) {
+	if _, err := domain.NewTest("t", "teacher", "", "", time.Now()); !errors.Is(err, errs.ErrInvalidTest) {
+		t.Fatalf("expected ErrInvalidTest for an empty title, got %v", err)
+	}
+	if _, err := domain.NewSection("s", "Part A", string(make([]byte, domain.MaxInstructionsLength+1))); !errors.Is(err, errs.ErrInvalidTest) {
+		t.Fatalf("expected ErrInvalidTest for oversized instructions, got %v", err)
+	}
+}
+
+func TestValidEmail(t *testing.T) {
+	for email, want := range map[string]bool{
+		"alice@example.com":         true,
+		"":                          false,
+		"alice":                     false,
+		"alice@localhost":           false,
+		"Alice <alice@example.com>": false,
+	} {
+		if got := domain.ValidEmail(email); got != want {
+			t.Fatalf("ValidEmail(%q) = %v, want %v", email, got, want)
+		}
+	}
+	if _, err := domain.NewStudent("s", "c", "Alice", "nope", time.Now()); !errors.Is(err, errs.ErrInvalidProfile) {
+		t.Fatalf("expected ErrInvalidProfile, got %v", err)
+	}
+}
+
+func TestNormalizeSequences(t *testing.T) {
+	questions := []domain.Question{{ID: "c", Sequence: 9}, {ID: "a", Sequence: 2}, {ID: "b", Sequence: 5}}
+	if err := domain.NormalizeSequences(questions); err != nil {
+		t.Fatalf("NormalizeSequences failed: %v", err)
+	}
+	for i, want := range []domain.QuestionID{"a", "b", "c"} {
+		if questions[i].ID != want || questions[i].Sequence != i+1 {
+			t.Fatalf("expected %s at sequence %d, got %+v", want, i+1, questions[i])
+		}
+	}
+
+	if err := domain.NormalizeSequences(nil); err != nil {
+		t.Fatalf("expected no questions to be fine, got %v", err)
+	}
+	cases := map[string][]domain.Question{
+		"zero":      {{ID: "a", Sequence: 0}},
+		"negative":  {{ID: "a", Sequence: 1}, {ID: "b", Sequence: -2}},
+		"duplicate": {{ID: "a", Sequence: 3}, {ID: "b", Sequence: 3}},
+	}
+	for name, qs := range cases {
+		var invariant *domain.InvariantError
+		if err := domain.NormalizeSequences(qs); !errors.As(err, &invariant) || invariant.Field != "sequence" || !errors.Is(err, errs.ErrInvalidQuestion) {
+			t.Fatalf("%s: expected an invariant error on sequence, got %v", name, err)
+		}
+	}
+}
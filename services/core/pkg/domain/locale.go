@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+)
+
+// Translation is a question rendered in another language. Choices carry the
+// translated labels under the keys of the canonical question, so answers to
+// a translation are graded exactly like answers to the original.
+type Translation struct {
+	Prompt  string
+	Choices []Choice
+}
+
+// CanonicalLocale normalises a language tag such as "pt_br" to "pt-BR": a
+// two- or three-letter lowercase language, optionally followed by subtags
+// of letters and digits. It reports false for anything else.
+func CanonicalLocale(locale string) (string, bool) {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"), "-")
+	if len(parts[0]) < 2 || len(parts[0]) > 3 || !isLetters(parts[0]) {
+		return "", false
+	}
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		sub := parts[i]
+		if sub == "" || len(sub) > 8 || !isAlphanumeric(sub) {
+			return "", false
+		}
+		switch {
+		case len(sub) == 2 && isLetters(sub):
+			parts[i] = strings.ToUpper(sub)
+		case len(sub) == 4 && isLetters(sub):
+			parts[i] = strings.ToUpper(sub[:1]) + strings.ToLower(sub[1:])
+		default:
+			parts[i] = strings.ToLower(sub)
+		}
+	}
+	return strings.Join(parts, "-"), true
+}
+
+// Localized returns the question as seen by a reader preferring locale,
+// together with the locale of the translation used. It prefers an exact
+// match, then the bare language ("pt" for "pt-BR"), then any other variant
+// of the language; without a match it returns the canonical question and an
+// empty locale. The ID is unchanged, so answers map to the canonical
+// question.
+func (q Question) Localized(locale string) (Question, string) {
+	locale, ok := CanonicalLocale(locale)
+	if !ok || len(q.Translations) == 0 {
+		return q, ""
+	}
+	match := ""
+	if _, ok := q.Translations[locale]; ok {
+		match = locale
+	} else {
+		language, _, _ := strings.Cut(locale, "-")
+		if _, ok := q.Translations[language]; ok {
+			match = language
+		} else {
+			variants := make([]string, 0, len(q.Translations))
+			for candidate := range q.Translations {
+				if strings.HasPrefix(candidate, language+"-") {
+					variants = append(variants, candidate)
+				}
+			}
+			if len(variants) == 0 {
+				return q, ""
+			}
+			sort.Strings(variants)
+			match = variants[0]
+		}
+	}
+
+	tr := q.Translations[match]
+	out := q
+	out.Prompt = tr.Prompt
+	if len(q.Choices) > 0 {
+		labels := make(map[string]string, len(tr.Choices))
+		for _, c := range tr.Choices {
+			labels[c.Key] = c.Label
+		}
+		out.Choices = make([]Choice, len(q.Choices))
+		for i, c := range q.Choices {
+			if label, ok := labels[c.Key]; ok {
+				c.Label = label
+			}
+			out.Choices[i] = c
+		}
+	}
+	return out, match
+}
+
+// validateTranslations checks that every translation is keyed by a
+// canonical locale, has a prompt and translates exactly the question's
+// choices.
+func (q *Question) validateTranslations() error {
+	for locale, tr := range q.Translations {
+		if canonical, ok := CanonicalLocale(locale); !ok || canonical != locale {
+			return invalidQuestion("translations", "must be keyed by locales such as en or pt-BR")
+		}
+		if strings.TrimSpace(tr.Prompt) == "" {
+			return invalidQuestion("translations.prompt", "must not be empty")
+		}
+		if len(tr.Choices) != len(q.Choices) {
+			return invalidQuestion("translations.choices", "must translate every choice of the question")
+		}
+		keys := make(map[string]bool, len(q.Choices))
+		for _, c := range q.Choices {
+			keys[c.Key] = true
+		}
+		for _, c := range tr.Choices {
+			if !keys[c.Key] || strings.TrimSpace(c.Label) == "" {
+				return invalidQuestion("translations.choices", "need the keys of the question's choices and a label")
+			}
+			delete(keys, c.Key)
+		}
+	}
+	return nil
+}
+
+func isLetters(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlphanumeric(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
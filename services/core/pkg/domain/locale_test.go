@@ -0,0 +1,78 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+func TestCanonicalLocale(t *testing.T) {
+	cases := map[string]string{
+		"ja":         "ja",
+		"EN":         "en",
+		"pt_br":      "pt-BR",
+		"zh-hant-tw": "zh-Hant-TW",
+		"es-419":     "es-419",
+	}
+	for in, want := range cases {
+		if got, ok := domain.CanonicalLocale(in); !ok || got != want {
+			t.Fatalf("CanonicalLocale(%q) = %q, %v; want %q", in, got, ok, want)
+		}
+	}
+	for _, in := range []string{"", "e", "english", "en-", "ja JP", "1a"} {
+		if _, ok := domain.CanonicalLocale(in); ok {
+			t.Fatalf("expected %q to be rejected", in)
+		}
+	}
+}
+
+func TestQuestionLocalized(t *testing.T) {
+	q := domain.Question{
+		ID:      "q1",
+		Prompt:  "Pick a colour",
+		Points:  1,
+		Type:    domain.QuestionMultipleChoice,
+		Choices: []domain.Choice{{Key: "a", Label: "Red"}, {Key: "b", Label: "Blue"}},
+		Translations: map[string]domain.Translation{
+			"ja":    {Prompt: "色を選んで", Choices: []domain.Choice{{Key: "b", Label: "青"}, {Key: "a", Label: "赤"}}},
+			"pt-PT": {Prompt: "Escolha uma cor", Choices: []domain.Choice{{Key: "a", Label: "Vermelho"}, {Key: "b", Label: "Azul"}}},
+		},
+	}
+	if err := q.Validate(); err != nil {
+		t.Fatalf("expected valid question, got %v", err)
+	}
+
+	cases := []struct {
+		locale, match, prompt, firstLabel string
+	}{
+		{"ja", "ja", "色を選んで", "赤"},
+		{"ja-JP", "ja", "色を選んで", "赤"},
+		{"pt-BR", "pt-PT", "Escolha uma cor", "Vermelho"},
+		{"fr", "", "Pick a colour", "Red"},
+		{"", "", "Pick a colour", "Red"},
+	}
+	for _, tc := range cases {
+		got, match := q.Localized(tc.locale)
+		if match != tc.match || got.Prompt != tc.prompt || got.Choices[0].Label != tc.firstLabel || got.Choices[0].Key != "a" {
+			t.Fatalf("Localized(%q) = %q %q %+v", tc.locale, match, got.Prompt, got.Choices)
+		}
+	}
+	if q.Choices[0].Label != "Red" {
+		t.Fatalf("Localized must not modify the canonical choices")
+	}
+
+	invalid := []map[string]domain.Translation{
+		{"Japanese": {Prompt: "x", Choices: q.Choices}},
+		{"ja": {Prompt: " ", Choices: q.Choices}},
+		{"ja": {Prompt: "x", Choices: q.Choices[:1]}},
+		{"ja": {Prompt: "x", Choices: []domain.Choice{{Key: "a", Label: "x"}, {Key: "c", Label: "y"}}}},
+		{"ja": {Prompt: "x", Choices: []domain.Choice{{Key: "a", Label: "x"}, {Key: "a", Label: "y"}}}},
+	}
+	for i, translations := range invalid {
+		bad := q
+		bad.Translations = translations
+		if err := bad.Validate(); err == nil {
+			t.Fatalf("case %d: expected invalid translations to be rejected", i)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package domain
+
+import "math"
+
+// Points is what a question, a rubric criterion or a whole test is worth.
+type Points int
+
+// Score is what was awarded, for one answer or summed over several.
+type Score int
+
+// Valid reports whether p is a usable question worth, which must be positive.
+func (p Points) Valid() bool {
+	return p > 0
+}
+
+// Within reports whether s lies between zero and full inclusive.
+func (s Score) Within(full Points) bool {
+	return s >= 0 && int(s) <= int(full)
+}
+
+// Clamp limits s to the range from zero to full.
+func (s Score) Clamp(full Points) Score {
+	return Score(min(max(int(s), 0), int(full)))
+}
+
+// Percent returns s as a percentage of full, or zero when full is not
+// positive.
+func (s Score) Percent(full Points) float64 {
+	if full <= 0 {
+		return 0
+	}
+	return float64(s) * 100 / float64(full)
+}
+
+// Rescale maps s from a scale topping out at from onto one topping out at to,
+// rounding to the nearest whole score. A non-positive from leaves s unchanged.
+func (s Score) Rescale(from Score, to Points) Score {
+	if from <= 0 {
+		return s
+	}
+	return Score(math.Round(float64(s) * float64(to) / float64(from)))
+}
+
+// TotalPoints sums what the questions are worth.
+func TotalPoints(questions []Question) Points {
+	var total Points
+	for _, q := range questions {
+		total += q.Points
+	}
+	return total
+}
+
+// TotalScore sums the scores of the results.
+func TotalScore(results []Result) Score {
+	var total Score
+	for _, res := range results {
+		total += res.Score
+	}
+	return total
+}
+
+// MeanScore returns the average score of the results, or zero without any.
+func MeanScore(results []Result) float64 {
+	if len(results) == 0 {
+		return 0
+	}
+	return float64(TotalScore(results)) / float64(len(results))
+}
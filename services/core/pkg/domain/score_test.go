@@ -0,0 +1,34 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+func TestScoreArithmetic(t *testing.T) {
+	if got := domain.Score(12).Clamp(10); got != 10 {
+		t.Fatalf("Clamp above full = %d, want 10", got)
+	}
+	if got := domain.Score(-3).Clamp(10); got != 0 {
+		t.Fatalf("Clamp below zero = %d, want 0", got)
+	}
+	if got := domain.Score(3).Percent(4); got != 75 {
+		t.Fatalf("Percent = %v, want 75", got)
+	}
+	if got := domain.Score(3).Percent(0); got != 0 {
+		t.Fatalf("Percent of nothing = %v, want 0", got)
+	}
+	if got := domain.Score(4).Rescale(8, 10); got != 5 {
+		t.Fatalf("Rescale = %d, want 5", got)
+	}
+	if !domain.Score(10).Within(10) || domain.Score(11).Within(10) {
+		t.Fatal("Within must include full points and exclude more")
+	}
+
+	questions := []domain.Question{{Points: 2}, {Points: 3}}
+	results := []domain.Result{{Score: 1}, {Score: 2}}
+	if domain.TotalPoints(questions) != 5 || domain.TotalScore(results) != 3 || domain.MeanScore(results) != 1.5 {
+		t.Fatalf("unexpected totals: %d, %d, %v", domain.TotalPoints(questions), domain.TotalScore(results), domain.MeanScore(results))
+	}
+}
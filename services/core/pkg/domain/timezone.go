@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"strings"
+	"time"
+	// Embed the zone database so zones load in minimal containers too.
+	_ "time/tzdata"
+)
+
+// LoadTimezone returns the location of an IANA time zone name such as
+// "Asia/Tokyo". It reports false for unknown names and for "Local", whose
+// meaning depends on the server. Timestamps are always stored in UTC; time
+// zones only decide how they are shown.
+func LoadTimezone(name string) (*time.Location, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == "Local" {
+		return nil, false
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, false
+	}
+	return loc, true
+}
+
+// ResolveTimezone returns the location of the first zone name that is set
+// and known, falling back to UTC. Callers list names from the most specific
+// setting, such as a user's own, to the least specific.
+func ResolveTimezone(names ...string) *time.Location {
+	for _, name := range names {
+		if loc, ok := LoadTimezone(name); ok {
+			return loc
+		}
+	}
+	return time.UTC
+}
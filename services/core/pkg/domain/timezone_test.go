@@ -0,0 +1,28 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+func TestLoadTimezone(t *testing.T) {
+	if loc, ok := domain.LoadTimezone(" Asia/Tokyo "); !ok || loc.String() != "Asia/Tokyo" {
+		t.Fatalf("expected Asia/Tokyo to load, got %v, %v", loc, ok)
+	}
+	for _, name := range []string{"", "Local", "Mars/Olympus", "../etc"} {
+		if _, ok := domain.LoadTimezone(name); ok {
+			t.Fatalf("expected %q to be rejected", name)
+		}
+	}
+}
+
+func TestResolveTimezone(t *testing.T) {
+	if loc := domain.ResolveTimezone("", "Europe/Paris", "Asia/Tokyo"); loc.String() != "Europe/Paris" {
+		t.Fatalf("expected the first set zone, got %v", loc)
+	}
+	if loc := domain.ResolveTimezone("nowhere", ""); loc != time.UTC {
+		t.Fatalf("expected UTC without a known zone, got %v", loc)
+	}
+}
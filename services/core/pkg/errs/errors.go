@@ -14,8 +14,88 @@ var (
 	ErrResultNotFound     = errors.New("result not found")
 	ErrStudentNotAssigned = errors.New("student not assigned to test")
 	ErrForbiddenTeacher   = errors.New("teacher cannot access this resource")
+	ErrForbiddenStudent   = errors.New("student cannot access this resource")
 	ErrInvalidTest        = errors.New("invalid test payload")
 	ErrInvalidQuestion    = errors.New("invalid question payload")
 	ErrInvalidAnswer      = errors.New("invalid answer payload")
 	ErrNoQuestions        = errors.New("no questions provided")
+	ErrInvalidProfile     = errors.New("invalid profile payload")
+	ErrCommentNotFound    = errors.New("comment not found")
+	ErrInvalidComment     = errors.New("invalid comment payload")
+	ErrUnsupportedFormat  = errors.New("unsupported export format")
+	ErrInvalidAnswerCSV   = errors.New("invalid answer csv: need a header with student_id, question and response")
+	ErrQuotaExceeded      = errors.New("school quota exceeded")
+	ErrInvalidQuota       = errors.New("invalid quota settings")
+	ErrResponseTooLong    = errors.New("answer response exceeds the school's maximum length")
+	ErrAnswerThrottled    = errors.New("too many submissions for this question; wait a minute before answering it again")
+	ErrDuplicateAnswer    = errors.New("this answer was just submitted unchanged; it is already saved")
+	ErrSectionNotFound    = errors.New("section not found")
+	ErrInvalidCurve       = errors.New("invalid curve")
+	ErrNoCurve            = errors.New("no curve applied to test")
+	ErrRubricNotFound     = errors.New("rubric not found")
+	ErrInvalidRubric      = errors.New("invalid rubric payload")
+	ErrInvalidComposition = errors.New("invalid test composition")
+	ErrNotEnoughQuestions = errors.New("not enough questions to compose test")
+	ErrInvalidAnonymity   = errors.New("k must be at least the configured minimum")
+	ErrDatasetTooSmall    = errors.New("no test has enough students to release anonymously")
+	ErrInvalidCursor      = errors.New("invalid page cursor")
+	ErrDelegationNotFound = errors.New("delegation not found")
+	ErrInvalidDelegation  = errors.New("invalid delegation payload")
+	ErrTestPublished      = errors.New("test is published")
+	ErrTestAnswered       = errors.New("test already has answers")
+	ErrStudentAnswered    = errors.New("student already answered this test")
+	ErrDraftLocked        = errors.New("draft is locked")
+	ErrTestClosed         = errors.New("test is not open for answers")
+	ErrTestSubmitted      = errors.New("test already submitted; answers can no longer be changed")
+	ErrSubmitUnavailable  = errors.New("tests cannot be submitted on this service")
+	ErrNotMultipleChoice  = errors.New("question is not multiple choice")
+	ErrDistrictNotFound   = errors.New("district not found")
+	ErrStaffNotFound      = errors.New("district staff not found")
+	ErrInvalidDistrict    = errors.New("invalid district payload")
+	ErrForbiddenDistrict  = errors.New("district staff cannot access this resource")
+	ErrInvalidMagicLink   = errors.New("invalid or expired sign-in link")
+	ErrInvalidEmail       = errors.New("invalid email address")
+	ErrTooManyRequests    = errors.New("too many requests, try again later")
+	ErrDeviceNotFound     = errors.New("device session not found")
+	ErrInvalidRoster      = errors.New("invalid roster: need between 1 and 500 students with distinct emails")
+	ErrEmailTaken         = errors.New("email address is already used by another student")
+
+	ErrBackupNotFound       = errors.New("backup not found")
+	ErrInvalidRestoreTarget = errors.New("restore target must be a new path")
+	ErrInvalidSnapshot      = errors.New("invalid state snapshot")
+	ErrNoCandidate          = errors.New("no candidate snapshot staged")
+	ErrStaleCandidate       = errors.New("live data changed since the candidate was staged; stage it again")
+	ErrBlobNotFound         = errors.New("blob not found")
+	ErrDeliveryNotFound     = errors.New("webhook delivery not found")
+	ErrDeliveryNotDead      = errors.New("webhook delivery is not dead-lettered")
+	ErrInvalidSignature     = errors.New("invalid webhook signature")
+	ErrBankQuestionNotFound = errors.New("bank question not found")
+	ErrInvalidRetention     = errors.New("invalid retention policy")
+	ErrRevisionNotFound     = errors.New("answer revision not found")
+	ErrInvalidTimezone      = errors.New("invalid time zone")
+	ErrScoreOutOfRange      = errors.New("score is outside the question's points")
+	ErrTestNotStarted       = errors.New("timed test has not been started")
+	ErrTimeExpired          = errors.New("time for the test has run out")
+	ErrTimerUnavailable     = errors.New("timed tests cannot be started on this service")
+	ErrNoCertificate        = errors.New("test does not award certificates")
+	ErrTestNotPassed        = errors.New("student has not passed the test")
+	ErrSigningUnavailable   = errors.New("certificates cannot be issued on this service")
+	ErrUnknownOperation     = errors.New("unknown maintenance operation")
+	ErrOperationDisabled    = errors.New("maintenance operation is disabled on this service")
+	ErrOperationRunning     = errors.New("maintenance operation is already running")
+	ErrOperatorRequired     = errors.New("the X-Operator header must name who runs the operation")
+	ErrInvalidResultsLink   = errors.New("results link must expire within 90 days")
+	ErrResultsLinkNotFound  = errors.New("results link not found or expired")
+	ErrCommentsUnavailable  = errors.New("answer comments are not available on this service")
+	ErrInvalidQuickImport   = errors.New("invalid quick import")
+	ErrInvalidModeration    = errors.New("invalid moderation settings")
+	ErrResultNotHeld        = errors.New("result is not held for review")
+	ErrInvalidSearch        = errors.New("search query has no words")
+	ErrUploadNotFound       = errors.New("upload not found or expired")
+	ErrInvalidUpload        = errors.New("invalid upload")
+	ErrUploadIncomplete     = errors.New("upload is missing parts")
+	ErrChecksumMismatch     = errors.New("checksum does not match the uploaded data")
+	ErrConflict             = errors.New("changed by another request; reload and try again")
+	ErrInvalidExtension     = errors.New("an extension needs a reason and a close time later than the test's")
+	ErrExtensionNotFound    = errors.New("student has no deadline extension")
 )
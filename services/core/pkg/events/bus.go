@@ -0,0 +1,179 @@
+// Package events pushes what happens to a student's tests, such as a new
+// assignment or a saved grade, to the connections the student keeps open, so
+// clients no longer poll for them.
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/id"
+)
+
+// Kind identifies what happened.
+type Kind string
+
+const (
+	KindTestAssigned Kind = "test.assigned"
+	KindResultSaved  Kind = "result.saved"
+)
+
+// DefaultBuffer is the number of events a subscription holds before it is
+// considered too slow to keep up.
+const DefaultBuffer = 32
+
+// Event is a notification addressed to one student. Title is set on
+// assignments; QuestionID, Score and Completed on saved results.
+type Event struct {
+	ID         string
+	Kind       Kind
+	StudentID  domain.StudentID
+	TestID     domain.TestID
+	Title      string
+	QuestionID domain.QuestionID
+	Score      domain.Score
+	Completed  bool
+	OccurredAt time.Time
+}
+
+// Subscription receives the events of one student on C. C is closed when
+// the subscription is closed or falls DefaultBuffer events behind; a client
+// that lost events that way reconnects and reloads what it shows.
+type Subscription struct {
+	C <-chan Event
+
+	bus       *Bus
+	studentID domain.StudentID
+	ch        chan Event
+}
+
+// Close stops the subscription. Closing it twice is harmless.
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.drop(s)
+}
+
+// Bus fans events out to the subscriptions of their student. It lives in
+// one process: events raised by other services reach it through Publish,
+// for example from their webhooks.
+type Bus struct {
+	now func() time.Time
+
+	mu   sync.Mutex
+	subs map[domain.StudentID]map[*Subscription]struct{}
+}
+
+// NewBus creates an empty bus.
+func NewBus() *Bus {
+	return &Bus{
+		now:  time.Now,
+		subs: make(map[domain.StudentID]map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe starts receiving the events of a student.
+func (b *Bus) Subscribe(studentID domain.StudentID) *Subscription {
+	ch := make(chan Event, DefaultBuffer)
+	sub := &Subscription{C: ch, bus: b, studentID: studentID, ch: ch}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subs[studentID] == nil {
+		b.subs[studentID] = make(map[*Subscription]struct{})
+	}
+	b.subs[studentID][sub] = struct{}{}
+	return sub
+}
+
+// Subscribers counts the open subscriptions.
+func (b *Bus) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for _, subs := range b.subs {
+		n += len(subs)
+	}
+	return n
+}
+
+// Publish delivers e to the subscriptions of its student without blocking.
+// Missing IDs and times are filled in.
+func (b *Bus) Publish(e Event) {
+	if e.ID == "" {
+		e.ID = id.New()
+	}
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = b.now().UTC()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subs[e.StudentID] {
+		select {
+		case sub.ch <- e:
+		default:
+			b.drop(sub)
+		}
+	}
+}
+
+// Apply publishes the student events behind a domain event recorded by the
+// assessment workflow: every assigned student hears of a test once it is
+// published, and a student hears of each grade of their answers.
+func (b *Bus) Apply(event domain.Event) {
+	switch e := event.(type) {
+	case domain.TestCreated:
+		if e.Test.Published {
+			b.assigned(e.Test)
+		}
+	case domain.TestPublished:
+		b.assigned(e.Test)
+	case domain.AssigneesChanged:
+		if e.Test.Published {
+			added := e.Test
+			added.AssignedTo = e.Added
+			b.assigned(added)
+		}
+	case domain.ResultSaved:
+		if e.Result.Hold != nil {
+			return
+		}
+		b.Publish(Event{
+			Kind:       KindResultSaved,
+			StudentID:  e.StudentID,
+			TestID:     e.TestID,
+			QuestionID: e.QuestionID,
+			Score:      e.Result.Score,
+			Completed:  e.Result.Completed,
+			OccurredAt: e.Result.UpdatedAt,
+		})
+	}
+}
+
+func (b *Bus) assigned(test domain.Test) {
+	now := b.now().UTC()
+	for _, studentID := range test.AssignedTo {
+		b.Publish(Event{
+			Kind:       KindTestAssigned,
+			StudentID:  studentID,
+			TestID:     test.ID,
+			Title:      test.Title,
+			OccurredAt: now,
+		})
+	}
+}
+
+// drop removes a subscription and closes its channel. The caller holds mu.
+func (b *Bus) drop(sub *Subscription) {
+	subs := b.subs[sub.studentID]
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(b.subs, sub.studentID)
+	}
+	close(sub.ch)
+}
@@ -0,0 +1,59 @@
+package events_test
+
+import (
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/events"
+)
+
+func TestBusDeliversDomainEventsToTheirStudents(t *testing.T) {
+	bus := events.NewBus()
+	alice := bus.Subscribe("student-001")
+	defer alice.Close()
+	bob := bus.Subscribe("student-002")
+	defer bob.Close()
+
+	draft := domain.Test{ID: "test-1", Title: "Algebra", AssignedTo: []domain.StudentID{"student-001", "student-002"}}
+	bus.Apply(domain.TestCreated{Test: draft})
+	if len(alice.C) != 0 {
+		t.Fatalf("expected drafts to stay quiet")
+	}
+
+	published := draft
+	published.Published = true
+	bus.Apply(domain.TestPublished{Test: published})
+	bus.Apply(domain.ResultSaved{TestID: "test-1", QuestionID: "q-1", StudentID: "student-002", Result: domain.Result{Score: 4, Completed: true}})
+
+	if e := <-alice.C; e.Kind != events.KindTestAssigned || e.TestID != "test-1" || e.Title != "Algebra" || e.ID == "" {
+		t.Fatalf("unexpected assignment %+v", e)
+	}
+	if len(alice.C) != 0 {
+		t.Fatalf("expected another student's grade to stay private")
+	}
+	<-bob.C
+	if e := <-bob.C; e.Kind != events.KindResultSaved || e.QuestionID != "q-1" || e.Score != 4 || !e.Completed {
+		t.Fatalf("unexpected grade %+v", e)
+	}
+}
+
+func TestBusClosesSubscriptionsThatFallBehind(t *testing.T) {
+	bus := events.NewBus()
+	slow := bus.Subscribe("student-001")
+	defer slow.Close()
+
+	for range events.DefaultBuffer + 1 {
+		bus.Publish(events.Event{Kind: events.KindResultSaved, StudentID: "student-001"})
+	}
+	received := 0
+	for range slow.C {
+		received++
+	}
+	if received != events.DefaultBuffer {
+		t.Fatalf("expected %d buffered events before the close, got %d", events.DefaultBuffer, received)
+	}
+	if bus.Subscribers() != 0 {
+		t.Fatalf("expected the slow subscription to be dropped")
+	}
+	slow.Close()
+}
@@ -0,0 +1,87 @@
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+)
+
+// ContentTypeCSV is the media type of comma-separated values.
+const ContentTypeCSV = "text/csv"
+
+// MaxAnswerCSVRows caps the data rows of one answer upload.
+const MaxAnswerCSVRows = 10000
+
+// AnswerCSVRow is one data row of an answer upload. Question holds either a
+// question ID or the question's sequence number, as printed on paper.
+type AnswerCSVRow struct {
+	// Line is the row's line number in the file, counting the header as 1.
+	Line      int
+	StudentID string
+	Question  string
+	Response  string
+}
+
+// answerCSVColumns are the required header names of an answer upload.
+var answerCSVColumns = []string{"student_id", "question", "response"}
+
+// ReadAnswerCSV decodes an answer upload. The header row must name the
+// student_id, question and response columns in any order; other columns are
+// ignored. Structural problems reject the whole file, while the values of
+// each row are left to the importer to check.
+func ReadAnswerCSV(r io.Reader) ([]AnswerCSVRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errs.ErrInvalidAnswerCSV
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, dup := index[name]; !dup {
+			index[name] = i
+		}
+	}
+	for _, column := range answerCSVColumns {
+		if _, ok := index[column]; !ok {
+			return nil, errs.ErrInvalidAnswerCSV
+		}
+	}
+
+	field := func(record []string, column string) string {
+		if i := index[column]; i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	rows := make([]AnswerCSVRow, 0)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errs.ErrInvalidAnswerCSV
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		if len(rows) == MaxAnswerCSVRows {
+			return nil, errs.ErrInvalidAnswerCSV
+		}
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, AnswerCSVRow{
+			Line:      line,
+			StudentID: field(record, "student_id"),
+			Question:  field(record, "question"),
+			Response:  field(record, "response"),
+		})
+	}
+	return rows, nil
+}
@@ -0,0 +1,39 @@
+package export_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/export"
+)
+
+func TestReadAnswerCSV(t *testing.T) {
+	input := "\ufeffResponse,student_id,Question,comment\n" +
+		"\"Paris, France\",student-1,1,neat\n" +
+		"\n" +
+		"b,student-2,q-2\n"
+
+	rows, err := export.ReadAnswerCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadAnswerCSV failed: %v", err)
+	}
+	want := []export.AnswerCSVRow{
+		{Line: 2, StudentID: "student-1", Question: "1", Response: "Paris, France"},
+		{Line: 4, StudentID: "student-2", Question: "q-2", Response: "b"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("expected %d rows, got %+v", len(want), rows)
+	}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Fatalf("row %d: expected %+v, got %+v", i, want[i], rows[i])
+		}
+	}
+
+	for _, bad := range []string{"", "student_id,response\ns,r\n", "student_id,question,response\n\"unterminated,q,r\n"} {
+		if _, err := export.ReadAnswerCSV(strings.NewReader(bad)); err != errs.ErrInvalidAnswerCSV {
+			t.Fatalf("expected ErrInvalidAnswerCSV for %q, got %v", bad, err)
+		}
+	}
+}
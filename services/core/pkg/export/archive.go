@@ -0,0 +1,162 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"path"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+// GradingPackage gathers everything needed to archive a graded test.
+type GradingPackage struct {
+	Test      domain.Test
+	Questions []domain.Question
+	Students  []StudentPackage
+	// Location is the zone timestamps are written in; nil writes UTC.
+	Location *time.Location
+}
+
+// local returns t in the package's zone.
+func (p GradingPackage) local(t time.Time) time.Time {
+	if p.Location == nil {
+		return t.UTC()
+	}
+	return t.In(p.Location)
+}
+
+// StudentPackage holds one student's submission and grading.
+type StudentPackage struct {
+	Student domain.Student
+	Answers []domain.Answer
+	Results map[domain.AnswerID]domain.Result
+}
+
+type testEntry struct {
+	TestID     string    `json:"test_id"`
+	TeacherID  string    `json:"teacher_id"`
+	Title      string    `json:"title"`
+	Published  bool      `json:"published"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	StudentIDs []string  `json:"student_ids"`
+}
+
+type questionEntry struct {
+	QuestionID string `json:"question_id"`
+	Sequence   int    `json:"sequence"`
+	Prompt     string `json:"prompt"`
+	Points     int    `json:"points"`
+}
+
+type studentEntry struct {
+	StudentID string            `json:"student_id"`
+	Name      string            `json:"name"`
+	Email     string            `json:"email"`
+	Answers   []submissionEntry `json:"answers"`
+}
+
+type submissionEntry struct {
+	AnswerID    string      `json:"answer_id"`
+	QuestionID  string      `json:"question_id"`
+	Response    string      `json:"response"`
+	Note        string      `json:"note,omitempty"`
+	SubmittedAt time.Time   `json:"submitted_at"`
+	Result      *gradeEntry `json:"result,omitempty"`
+}
+
+type gradeEntry struct {
+	ResultID  string    `json:"result_id"`
+	Score     int       `json:"score"`
+	Feedback  string    `json:"feedback"`
+	Completed bool      `json:"completed"`
+	GradedAt  time.Time `json:"graded_at"`
+}
+
+// WriteZIP writes pkg as a ZIP archive laid out as:
+//
+//	test.json
+//	questions.json
+//	students/<student-id>/submission.json
+//
+// Uploaded files are not archived: uploads are not linked to the answers
+// they belong to, so there is nothing to tell which student's files to
+// include.
+func WriteZIP(w io.Writer, pkg GradingPackage) error {
+	zw := zip.NewWriter(w)
+
+	test := testEntry{
+		TestID:     string(pkg.Test.ID),
+		TeacherID:  string(pkg.Test.TeacherID),
+		Title:      pkg.Test.Title,
+		Published:  pkg.Test.Published,
+		CreatedAt:  pkg.local(pkg.Test.CreatedAt),
+		UpdatedAt:  pkg.local(pkg.Test.UpdatedAt),
+		StudentIDs: make([]string, len(pkg.Test.AssignedTo)),
+	}
+	for i, sid := range pkg.Test.AssignedTo {
+		test.StudentIDs[i] = string(sid)
+	}
+	if err := writeJSONEntry(zw, "test.json", test); err != nil {
+		return err
+	}
+
+	questions := make([]questionEntry, len(pkg.Questions))
+	for i, q := range pkg.Questions {
+		questions[i] = questionEntry{
+			QuestionID: string(q.ID),
+			Sequence:   q.Sequence,
+			Prompt:     q.Prompt,
+			Points:     int(q.Points),
+		}
+	}
+	if err := writeJSONEntry(zw, "questions.json", questions); err != nil {
+		return err
+	}
+
+	for _, sp := range pkg.Students {
+		dir := path.Join("students", string(sp.Student.ID))
+		entry := studentEntry{
+			StudentID: string(sp.Student.ID),
+			Name:      sp.Student.Name,
+			Email:     sp.Student.Email,
+			Answers:   make([]submissionEntry, len(sp.Answers)),
+		}
+		for i, ans := range sp.Answers {
+			sub := submissionEntry{
+				AnswerID:    string(ans.ID),
+				QuestionID:  string(ans.QuestionID),
+				Response:    ans.Response,
+				Note:        ans.Note,
+				SubmittedAt: pkg.local(ans.UpdatedAt),
+			}
+			if res, ok := sp.Results[ans.ID]; ok {
+				sub.Result = &gradeEntry{
+					ResultID:  string(res.ID),
+					Score:     int(res.Score),
+					Feedback:  res.Feedback,
+					Completed: res.Completed,
+					GradedAt:  pkg.local(res.UpdatedAt),
+				}
+			}
+			entry.Answers[i] = sub
+		}
+		if err := writeJSONEntry(zw, path.Join(dir, "submission.json"), entry); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+func writeJSONEntry(zw *zip.Writer, name string, payload any) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(payload)
+}
@@ -0,0 +1,43 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"time"
+)
+
+// CredentialSheetRow is one student's line of a credential sheet: who they
+// are and the invitation link that signs them in for the first time.
+type CredentialSheetRow struct {
+	StudentID   string
+	StudentName string
+	Email       string
+	SignInLink  string
+	ExpiresAt   time.Time
+}
+
+// WriteCredentialSheet writes a class's credential sheet as CSV, one student
+// per row, for staff to print or mail merge.
+func WriteCredentialSheet(w io.Writer, rows []CredentialSheetRow) error {
+	if _, err := io.WriteString(w, utf8BOM); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"student_id", "student_name", "email", "sign_in_link", "expires_at"}); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := []string{
+			spreadsheetCell(row.StudentID),
+			spreadsheetCell(row.StudentName),
+			spreadsheetCell(row.Email),
+			row.SignInLink,
+			row.ExpiresAt.UTC().Format(time.RFC3339),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,29 @@
+package export_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/export"
+)
+
+func TestWriteCredentialSheet(t *testing.T) {
+	var out bytes.Buffer
+	err := export.WriteCredentialSheet(&out, []export.CredentialSheetRow{{
+		StudentID:   "s-1",
+		StudentName: "@Ann",
+		Email:       "ann@example.com",
+		SignInLink:  "https://example.com/sign-in?token=abc",
+		ExpiresAt:   time.Date(2024, 1, 8, 9, 0, 0, 0, time.FixedZone("JST", 9*60*60)),
+	}})
+	if err != nil {
+		t.Fatalf("WriteCredentialSheet failed: %v", err)
+	}
+
+	want := "\ufeffstudent_id,student_name,email,sign_in_link,expires_at\n" +
+		"s-1,'@Ann,ann@example.com,https://example.com/sign-in?token=abc,2024-01-08T00:00:00Z\n"
+	if out.String() != want {
+		t.Fatalf("expected %q, got %q", want, out.String())
+	}
+}
@@ -0,0 +1,14 @@
+package export
+
+// DatasetRow is one graded answer in an anonymized training dataset. Students
+// appear only as a keyed hash, stable within one salt, so rows by the same
+// student can be grouped without revealing who they are.
+type DatasetRow struct {
+	StudentHash string `json:"student_hash"`
+	TestID      string `json:"test_id"`
+	QuestionID  string `json:"question_id"`
+	Question    string `json:"question"`
+	MaxPoints   int    `json:"max_points"`
+	Answer      string `json:"answer"`
+	Score       int    `json:"score"`
+}
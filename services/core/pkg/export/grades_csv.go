@@ -0,0 +1,82 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+// utf8BOM lets spreadsheet programs such as Excel recognise the sheet as
+// UTF-8, so student names keep their accents.
+const utf8BOM = "\ufeff"
+
+// GradeSheetRow is one student's line of a grade sheet. Scores follows the
+// questions the sheet was opened with; a nil score is an unanswered or
+// ungraded question.
+type GradeSheetRow struct {
+	StudentID   string
+	StudentName string
+	Scores      []*int
+	Total       int
+	Feedback    string
+}
+
+// GradeSheetWriter streams a grade sheet as CSV, one student per row: the
+// student, a score column per question, the total and the feedback.
+type GradeSheetWriter struct {
+	csv *csv.Writer
+}
+
+// NewGradeSheetWriter writes the header of a grade sheet for questions, in
+// the order given, and returns a writer for its rows.
+func NewGradeSheetWriter(w io.Writer, questions []domain.Question) (*GradeSheetWriter, error) {
+	if _, err := io.WriteString(w, utf8BOM); err != nil {
+		return nil, err
+	}
+	header := []string{"student_id", "student_name"}
+	for _, q := range questions {
+		header = append(header, "q"+strconv.Itoa(q.Sequence))
+	}
+	header = append(header, "total", "feedback")
+
+	sheet := &GradeSheetWriter{csv: csv.NewWriter(w)}
+	if err := sheet.write(header); err != nil {
+		return nil, err
+	}
+	return sheet, nil
+}
+
+// Write writes a student's row and flushes it, so rows reach the client as
+// they are produced rather than once the sheet is complete.
+func (g *GradeSheetWriter) Write(row GradeSheetRow) error {
+	record := []string{spreadsheetCell(row.StudentID), spreadsheetCell(row.StudentName)}
+	for _, score := range row.Scores {
+		if score == nil {
+			record = append(record, "")
+			continue
+		}
+		record = append(record, strconv.Itoa(*score))
+	}
+	record = append(record, strconv.Itoa(row.Total), spreadsheetCell(row.Feedback))
+	return g.write(record)
+}
+
+func (g *GradeSheetWriter) write(record []string) error {
+	if err := g.csv.Write(record); err != nil {
+		return err
+	}
+	g.csv.Flush()
+	return g.csv.Error()
+}
+
+// spreadsheetCell keeps free text from being evaluated as a formula when the
+// sheet is opened in a spreadsheet program.
+func spreadsheetCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,27 @@
+package export_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/export"
+)
+
+func TestGradeSheetWriter(t *testing.T) {
+	var out bytes.Buffer
+	sheet, err := export.NewGradeSheetWriter(&out, []domain.Question{{Sequence: 1}, {Sequence: 2}})
+	if err != nil {
+		t.Fatalf("NewGradeSheetWriter failed: %v", err)
+	}
+	score := 5
+	if err := sheet.Write(export.GradeSheetRow{StudentID: "s-1", StudentName: "=HYPERLINK(\"x\")", Scores: []*int{&score, nil}, Total: 5, Feedback: "Fine, mostly"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	want := "\ufeffstudent_id,student_name,q1,q2,total,feedback\n" +
+		"s-1,\"'=HYPERLINK(\"\"x\"\")\",5,,5,\"Fine, mostly\"\n"
+	if out.String() != want {
+		t.Fatalf("expected %q, got %q", want, out.String())
+	}
+}
@@ -0,0 +1,92 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+)
+
+// Format selects how list and export endpoints encode their rows.
+type Format string
+
+const (
+	FormatJSON   Format = "json"
+	FormatNDJSON Format = "ndjson"
+)
+
+// ContentTypeNDJSON is the media type of newline-delimited JSON.
+const ContentTypeNDJSON = "application/x-ndjson"
+
+// ParseFormat maps a ?format= query value to a Format. An empty value selects
+// the regular JSON envelope.
+func ParseFormat(value string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(value))) {
+	case "", FormatJSON:
+		return FormatJSON, nil
+	case FormatNDJSON:
+		return FormatNDJSON, nil
+	default:
+		return "", errs.ErrUnsupportedFormat
+	}
+}
+
+// WriteNDJSON writes each row as a compact JSON document followed by a
+// newline, so the output can be streamed line by line into data pipelines.
+func WriteNDJSON[T any](w io.Writer, rows []T) error {
+	encoder := json.NewEncoder(w)
+	for _, row := range rows {
+		if err := encoder.Encode(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SubmissionRow is one answer of a grading package flattened together with
+// its grading, suited to row-based formats.
+type SubmissionRow struct {
+	TestID      string     `json:"test_id"`
+	StudentID   string     `json:"student_id"`
+	AnswerID    string     `json:"answer_id"`
+	QuestionID  string     `json:"question_id"`
+	Response    string     `json:"response"`
+	Note        string     `json:"note"`
+	SubmittedAt time.Time  `json:"submitted_at"`
+	Graded      bool       `json:"graded"`
+	Score       int        `json:"score"`
+	Feedback    string     `json:"feedback"`
+	Completed   bool       `json:"completed"`
+	GradedAt    *time.Time `json:"graded_at,omitempty"`
+}
+
+// SubmissionRows flattens pkg into one row per answer, ordered by student and
+// then by answer as stored in the package.
+func SubmissionRows(pkg GradingPackage) []SubmissionRow {
+	rows := make([]SubmissionRow, 0)
+	for _, sp := range pkg.Students {
+		for _, ans := range sp.Answers {
+			row := SubmissionRow{
+				TestID:      string(pkg.Test.ID),
+				StudentID:   string(sp.Student.ID),
+				AnswerID:    string(ans.ID),
+				QuestionID:  string(ans.QuestionID),
+				Response:    ans.Response,
+				Note:        ans.Note,
+				SubmittedAt: pkg.local(ans.UpdatedAt),
+			}
+			if res, ok := sp.Results[ans.ID]; ok {
+				gradedAt := pkg.local(res.UpdatedAt)
+				row.Graded = true
+				row.Score = int(res.Score)
+				row.Feedback = res.Feedback
+				row.Completed = res.Completed
+				row.GradedAt = &gradedAt
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
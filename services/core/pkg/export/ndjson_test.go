@@ -0,0 +1,91 @@
+package export_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/export"
+)
+
+func TestWriteNDJSONSubmissionRows(t *testing.T) {
+	now := time.Now().UTC()
+	pkg := export.GradingPackage{
+		Test: domain.Test{ID: "test-1"},
+		Students: []export.StudentPackage{{
+			Student: domain.Student{ID: "student-1"},
+			Answers: []domain.Answer{
+				{ID: "answer-1", QuestionID: "q-1", Response: "first", UpdatedAt: now},
+				{ID: "answer-2", QuestionID: "q-2", Response: "second\nline", UpdatedAt: now},
+			},
+			Results: map[domain.AnswerID]domain.Result{
+				"answer-1": {ID: "result-1", AnswerID: "answer-1", Score: 7, UpdatedAt: now},
+			},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := export.WriteNDJSON(&buf, export.SubmissionRows(pkg)); err != nil {
+		t.Fatalf("WriteNDJSON failed: %v", err)
+	}
+
+	var rows []export.SubmissionRow
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var row export.SubmissionRow
+		if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+			t.Fatalf("line is not a JSON document: %v", err)
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(rows))
+	}
+	if !rows[0].Graded || rows[0].Score != 7 || rows[1].Graded {
+		t.Fatalf("unexpected grading in rows: %+v", rows)
+	}
+	if rows[1].Response != "second\nline" {
+		t.Fatalf("expected embedded newline to survive, got %q", rows[1].Response)
+	}
+}
+
+func TestSubmissionRowsUseLocation(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("zone database unavailable: %v", err)
+	}
+	saved := time.Date(2026, 3, 1, 15, 30, 0, 0, time.UTC)
+	pkg := export.GradingPackage{
+		Test: domain.Test{ID: "test-1"},
+		Students: []export.StudentPackage{{
+			Student: domain.Student{ID: "student-1"},
+			Answers: []domain.Answer{{ID: "answer-1", QuestionID: "q-1", UpdatedAt: saved}},
+		}},
+		Location: tokyo,
+	}
+
+	var buf bytes.Buffer
+	if err := export.WriteNDJSON(&buf, export.SubmissionRows(pkg)); err != nil {
+		t.Fatalf("WriteNDJSON failed: %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"submitted_at":"2026-03-02T00:30:00+09:00"`)) {
+		t.Fatalf("expected the submission time in Tokyo time, got %s", buf.String())
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if f, err := export.ParseFormat(""); err != nil || f != export.FormatJSON {
+		t.Fatalf("expected default json, got %q %v", f, err)
+	}
+	if f, err := export.ParseFormat("NDJSON"); err != nil || f != export.FormatNDJSON {
+		t.Fatalf("expected ndjson, got %q %v", f, err)
+	}
+	if _, err := export.ParseFormat("xml"); !errors.Is(err, errs.ErrUnsupportedFormat) {
+		t.Fatalf("expected ErrUnsupportedFormat, got %v", err)
+	}
+}
@@ -0,0 +1,312 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// ContentTypeParquet is the media type used for Parquet files.
+const ContentTypeParquet = "application/vnd.apache.parquet"
+
+// Parquet physical types, repetition types, converted types and encodings as
+// defined by the format specification.
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetByteArray = 6
+
+	parquetRequired = 0
+	parquetOptional = 1
+
+	parquetUTF8            = 0
+	parquetTimestampMillis = 9
+
+	parquetPlain = 0
+	parquetRLE   = 3
+)
+
+// parquetColumn is one flat column; nil values are nulls and are only allowed
+// in optional columns.
+type parquetColumn struct {
+	name      string
+	physical  int32
+	converted int32 // -1 when the column has no converted type
+	optional  bool
+	values    []any
+}
+
+// WriteSubmissionsParquet writes rows as a single row-group Parquet file with
+// one column per SubmissionRow field. Pages are PLAIN encoded and stored
+// uncompressed so the output needs no third-party codecs.
+func WriteSubmissionsParquet(w io.Writer, rows []SubmissionRow) error {
+	columns := []*parquetColumn{
+		{name: "test_id", physical: parquetByteArray, converted: parquetUTF8},
+		{name: "student_id", physical: parquetByteArray, converted: parquetUTF8},
+		{name: "answer_id", physical: parquetByteArray, converted: parquetUTF8},
+		{name: "question_id", physical: parquetByteArray, converted: parquetUTF8},
+		{name: "response", physical: parquetByteArray, converted: parquetUTF8},
+		{name: "note", physical: parquetByteArray, converted: parquetUTF8},
+		{name: "submitted_at", physical: parquetInt64, converted: parquetTimestampMillis},
+		{name: "graded", physical: parquetBoolean, converted: -1},
+		{name: "score", physical: parquetInt64, converted: -1},
+		{name: "feedback", physical: parquetByteArray, converted: parquetUTF8},
+		{name: "completed", physical: parquetBoolean, converted: -1},
+		{name: "graded_at", physical: parquetInt64, converted: parquetTimestampMillis, optional: true},
+	}
+	for _, row := range rows {
+		var gradedAt any
+		if row.GradedAt != nil {
+			gradedAt = row.GradedAt.UnixMilli()
+		}
+		values := []any{
+			row.TestID,
+			row.StudentID,
+			row.AnswerID,
+			row.QuestionID,
+			row.Response,
+			row.Note,
+			row.SubmittedAt.UnixMilli(),
+			row.Graded,
+			int64(row.Score),
+			row.Feedback,
+			row.Completed,
+			gradedAt,
+		}
+		for i, v := range values {
+			columns[i].values = append(columns[i].values, v)
+		}
+	}
+	return writeParquet(w, columns, len(rows))
+}
+
+func writeParquet(w io.Writer, columns []*parquetColumn, numRows int) error {
+	var file bytes.Buffer
+	file.WriteString("PAR1")
+
+	type chunk struct {
+		offset int64
+		size   int64
+	}
+	chunks := make([]chunk, len(columns))
+	var rowGroupSize int64
+
+	for i, col := range columns {
+		page := encodeParquetPage(col)
+
+		var header thriftWriter
+		header.beginStruct()
+		header.i32(1, 0) // DATA_PAGE
+		header.i32(2, int32(len(page)))
+		header.i32(3, int32(len(page)))
+		header.structField(5)
+		header.i32(1, int32(len(col.values)))
+		header.i32(2, parquetPlain)
+		header.i32(3, parquetRLE)
+		header.i32(4, parquetRLE)
+		header.endStruct()
+		header.endStruct()
+
+		chunks[i] = chunk{offset: int64(file.Len()), size: int64(header.buf.Len() + len(page))}
+		rowGroupSize += chunks[i].size
+		file.Write(header.buf.Bytes())
+		file.Write(page)
+	}
+
+	var meta thriftWriter
+	meta.beginStruct()
+	meta.i32(1, 1)
+	meta.listField(2, thriftStruct, len(columns)+1)
+	meta.beginStruct()
+	meta.binary(4, []byte("schema"))
+	meta.i32(5, int32(len(columns)))
+	meta.endStruct()
+	for _, col := range columns {
+		meta.beginStruct()
+		meta.i32(1, col.physical)
+		repetition := int32(parquetRequired)
+		if col.optional {
+			repetition = parquetOptional
+		}
+		meta.i32(3, repetition)
+		meta.binary(4, []byte(col.name))
+		if col.converted >= 0 {
+			meta.i32(6, col.converted)
+		}
+		meta.endStruct()
+	}
+	meta.i64(3, int64(numRows))
+	meta.listField(4, thriftStruct, 1)
+	meta.beginStruct()
+	meta.listField(1, thriftStruct, len(columns))
+	for i, col := range columns {
+		meta.beginStruct()
+		meta.i64(2, chunks[i].offset)
+		meta.structField(3)
+		meta.i32(1, col.physical)
+		meta.listField(2, thriftI32, 2)
+		meta.varint(zigzag(parquetPlain))
+		meta.varint(zigzag(parquetRLE))
+		meta.listField(3, thriftBinary, 1)
+		meta.rawBinary([]byte(col.name))
+		meta.i32(4, 0) // UNCOMPRESSED
+		meta.i64(5, int64(len(col.values)))
+		meta.i64(6, chunks[i].size)
+		meta.i64(7, chunks[i].size)
+		meta.i64(9, chunks[i].offset)
+		meta.endStruct()
+		meta.endStruct()
+	}
+	meta.i64(2, rowGroupSize)
+	meta.i64(3, int64(numRows))
+	meta.endStruct()
+	meta.binary(6, []byte("go_work_sample export"))
+	meta.endStruct()
+
+	file.Write(meta.buf.Bytes())
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(meta.buf.Len()))
+	file.Write(length[:])
+	file.WriteString("PAR1")
+
+	_, err := w.Write(file.Bytes())
+	return err
+}
+
+// encodeParquetPage encodes the definition levels (optional columns only)
+// followed by the PLAIN encoded non-null values.
+func encodeParquetPage(col *parquetColumn) []byte {
+	var page bytes.Buffer
+
+	if col.optional {
+		var levels bytes.Buffer
+		for i := 0; i < len(col.values); {
+			defined := col.values[i] != nil
+			run := 1
+			for i+run < len(col.values) && (col.values[i+run] != nil) == defined {
+				run++
+			}
+			levels.Write(binary.AppendUvarint(nil, uint64(run)<<1))
+			if defined {
+				levels.WriteByte(1)
+			} else {
+				levels.WriteByte(0)
+			}
+			i += run
+		}
+		var length [4]byte
+		binary.LittleEndian.PutUint32(length[:], uint32(levels.Len()))
+		page.Write(length[:])
+		page.Write(levels.Bytes())
+	}
+
+	var bits []bool
+	for _, v := range col.values {
+		switch v := v.(type) {
+		case nil:
+		case bool:
+			bits = append(bits, v)
+		case int64:
+			var b [8]byte
+			binary.LittleEndian.PutUint64(b[:], uint64(v))
+			page.Write(b[:])
+		case string:
+			var b [4]byte
+			binary.LittleEndian.PutUint32(b[:], uint32(len(v)))
+			page.Write(b[:])
+			page.WriteString(v)
+		}
+	}
+	if len(bits) > 0 {
+		packed := make([]byte, (len(bits)+7)/8)
+		for i, bit := range bits {
+			if bit {
+				packed[i/8] |= 1 << (i % 8)
+			}
+		}
+		page.Write(packed)
+	}
+
+	return page.Bytes()
+}
+
+// Thrift compact protocol type identifiers used by the Parquet metadata.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter emits the subset of the Thrift compact protocol needed for
+// Parquet page headers and file metadata.
+type thriftWriter struct {
+	buf    bytes.Buffer
+	fields []int16
+}
+
+func (t *thriftWriter) beginStruct() {
+	t.fields = append(t.fields, 0)
+}
+
+func (t *thriftWriter) endStruct() {
+	t.buf.WriteByte(0)
+	t.fields = t.fields[:len(t.fields)-1]
+}
+
+func (t *thriftWriter) fieldHeader(id int16, typ byte) {
+	last := &t.fields[len(t.fields)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.varint(zigzag(int64(id)))
+	}
+	*last = id
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.fieldHeader(id, thriftI32)
+	t.varint(zigzag(int64(v)))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.fieldHeader(id, thriftI64)
+	t.varint(zigzag(v))
+}
+
+func (t *thriftWriter) binary(id int16, v []byte) {
+	t.fieldHeader(id, thriftBinary)
+	t.rawBinary(v)
+}
+
+func (t *thriftWriter) rawBinary(v []byte) {
+	t.varint(uint64(len(v)))
+	t.buf.Write(v)
+}
+
+// structField starts a nested struct field; close it with endStruct.
+func (t *thriftWriter) structField(id int16) {
+	t.fieldHeader(id, thriftStruct)
+	t.beginStruct()
+}
+
+// listField writes a list header; the caller writes size elements after it.
+func (t *thriftWriter) listField(id int16, elem byte, size int) {
+	t.fieldHeader(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elem)
+		return
+	}
+	t.buf.WriteByte(0xF0 | elem)
+	t.varint(uint64(size))
+}
+
+func (t *thriftWriter) varint(v uint64) {
+	t.buf.Write(binary.AppendUvarint(nil, v))
+}
+
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
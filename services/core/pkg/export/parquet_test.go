@@ -0,0 +1,38 @@
+package export_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/export"
+)
+
+func TestWriteSubmissionsParquetLayout(t *testing.T) {
+	gradedAt := time.Now().UTC()
+	rows := []export.SubmissionRow{
+		{TestID: "test-1", StudentID: "student-1", AnswerID: "answer-1", QuestionID: "q-1", Response: "a", Graded: true, Score: 5, GradedAt: &gradedAt},
+		{TestID: "test-1", StudentID: "student-2", AnswerID: "answer-2", QuestionID: "q-1", Response: "b"},
+	}
+
+	var buf bytes.Buffer
+	if err := export.WriteSubmissionsParquet(&buf, rows); err != nil {
+		t.Fatalf("WriteSubmissionsParquet failed: %v", err)
+	}
+
+	data := buf.Bytes()
+	if !bytes.HasPrefix(data, []byte("PAR1")) || !bytes.HasSuffix(data, []byte("PAR1")) {
+		t.Fatal("expected Parquet magic at both ends")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8 : len(data)-4]))
+	if footerLen <= 0 || footerLen > len(data)-12 {
+		t.Fatalf("invalid footer length %d for %d bytes", footerLen, len(data))
+	}
+	footer := data[len(data)-8-footerLen : len(data)-8]
+	for _, column := range []string{"test_id", "response", "score", "graded_at"} {
+		if !bytes.Contains(footer, []byte(column)) {
+			t.Fatalf("expected column %q in footer schema", column)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+)
+
+// RubricBankVersion is the version written into and accepted from rubric
+// bank documents.
+const RubricBankVersion = 1
+
+// RubricBank is the portable JSON form of a teacher's rubrics and feedback
+// templates. IDs are those of the exporting installation and only serve to
+// link templates to rubric criteria; importers assign fresh IDs.
+type RubricBank struct {
+	Version    int                     `json:"version"`
+	ExportedAt time.Time               `json:"exported_at"`
+	Rubrics    []RubricEntry           `json:"rubrics"`
+	Templates  []FeedbackTemplateEntry `json:"feedback_templates"`
+}
+
+// RubricEntry is one rubric of a bank.
+type RubricEntry struct {
+	RubricID string           `json:"rubric_id"`
+	Title    string           `json:"title"`
+	Criteria []CriterionEntry `json:"criteria"`
+}
+
+// CriterionEntry is one criterion of a rubric.
+type CriterionEntry struct {
+	CriterionID string `json:"criterion_id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Points      int    `json:"points"`
+}
+
+// FeedbackTemplateEntry is one feedback template of a bank.
+type FeedbackTemplateEntry struct {
+	TemplateID  string `json:"template_id"`
+	RubricID    string `json:"rubric_id,omitempty"`
+	CriterionID string `json:"criterion_id,omitempty"`
+	Title       string `json:"title"`
+	Body        string `json:"body"`
+}
+
+// WriteRubricBank writes bank as indented JSON.
+func WriteRubricBank(w io.Writer, bank RubricBank) error {
+	bank.Version = RubricBankVersion
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(bank)
+}
+
+// ReadRubricBank decodes a bank document, rejecting unknown versions.
+func ReadRubricBank(r io.Reader) (*RubricBank, error) {
+	var bank RubricBank
+	if err := json.NewDecoder(r).Decode(&bank); err != nil {
+		return nil, errs.ErrInvalidRubric
+	}
+	if bank.Version != RubricBankVersion {
+		return nil, errs.ErrInvalidRubric
+	}
+	return &bank, nil
+}
@@ -0,0 +1,213 @@
+// Package fixtures builds deterministic organization data for tests.
+//
+//	fx := fixtures.NewSchool().WithClasses(2).WithStudents(30).Build()
+//	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+//
+// IDs are sequential and zero padded ("teacher-001", "student-042"), and
+// timestamps advance one minute per entity from a fixed epoch, so listings
+// sort the same way on every run.
+package fixtures
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/memory"
+)
+
+// Epoch is the creation time of the first entity of every fixture.
+var Epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// SchoolBuilder describes a school and the size of its hierarchy.
+type SchoolBuilder struct {
+	prefix   string
+	name     string
+	grades   int
+	classes  int
+	students int
+	teachers int
+	settings domain.SchoolSettings
+}
+
+// NewSchool starts a school with one grade, one class, one student and one
+// teacher.
+func NewSchool() *SchoolBuilder {
+	return &SchoolBuilder{
+		name:     "Fixture School",
+		grades:   1,
+		classes:  1,
+		students: 1,
+		teachers: 1,
+	}
+}
+
+// WithPrefix prefixes every generated ID, keeping several schools in one
+// seed apart.
+func (b *SchoolBuilder) WithPrefix(prefix string) *SchoolBuilder {
+	b.prefix = prefix
+	return b
+}
+
+// WithName sets the school name.
+func (b *SchoolBuilder) WithName(name string) *SchoolBuilder {
+	b.name = name
+	return b
+}
+
+// WithGrades sets the number of grades.
+func (b *SchoolBuilder) WithGrades(n int) *SchoolBuilder {
+	b.grades = n
+	return b
+}
+
+// WithClasses sets the number of classes in every grade.
+func (b *SchoolBuilder) WithClasses(n int) *SchoolBuilder {
+	b.classes = n
+	return b
+}
+
+// WithStudents sets the number of students in every class.
+func (b *SchoolBuilder) WithStudents(n int) *SchoolBuilder {
+	b.students = n
+	return b
+}
+
+// WithTeachers sets the number of teachers.
+func (b *SchoolBuilder) WithTeachers(n int) *SchoolBuilder {
+	b.teachers = n
+	return b
+}
+
+// WithSettings sets the school settings, such as quotas.
+func (b *SchoolBuilder) WithSettings(settings domain.SchoolSettings) *SchoolBuilder {
+	b.settings = settings
+	return b
+}
+
+// Seed generates the seed data described by the builder.
+func (b *SchoolBuilder) Seed() memory.SeedData {
+	var seed memory.SeedData
+	clock := Epoch
+
+	tick := func() time.Time {
+		t := clock
+		clock = clock.Add(time.Minute)
+		return t
+	}
+
+	school := domain.School{
+		ID:        domain.SchoolID(b.id("school", 1)),
+		Name:      b.name,
+		Settings:  b.settings,
+		CreatedAt: tick(),
+	}
+	seed.Schools = append(seed.Schools, school)
+
+	for t := 1; t <= b.teachers; t++ {
+		seed.Teachers = append(seed.Teachers, domain.Teacher{
+			ID:        domain.TeacherID(b.id("teacher", t)),
+			SchoolID:  school.ID,
+			Name:      fmt.Sprintf("Teacher %d", t),
+			Email:     b.id("teacher", t) + "@example.com",
+			CreatedAt: tick(),
+		})
+	}
+
+	classNo, studentNo := 0, 0
+	for g := 1; g <= b.grades; g++ {
+		grade := domain.Grade{
+			ID:        domain.GradeID(b.id("grade", g)),
+			SchoolID:  school.ID,
+			Name:      fmt.Sprintf("Grade %d", g),
+			CreatedAt: tick(),
+		}
+		seed.Grades = append(seed.Grades, grade)
+
+		for c := 1; c <= b.classes; c++ {
+			classNo++
+			class := domain.Class{
+				ID:        domain.ClassID(b.id("class", classNo)),
+				GradeID:   grade.ID,
+				Name:      fmt.Sprintf("Class %d-%d", g, c),
+				CreatedAt: tick(),
+			}
+			seed.Classes = append(seed.Classes, class)
+
+			for s := 1; s <= b.students; s++ {
+				studentNo++
+				seed.Students = append(seed.Students, domain.Student{
+					ID:        domain.StudentID(b.id("student", studentNo)),
+					ClassID:   class.ID,
+					Name:      fmt.Sprintf("Student %d", studentNo),
+					Email:     b.id("student", studentNo) + "@example.com",
+					CreatedAt: tick(),
+				})
+			}
+		}
+	}
+
+	return seed
+}
+
+// Build seeds a fresh in-memory repository.
+func (b *SchoolBuilder) Build() *Fixture {
+	seed := b.Seed()
+	return &Fixture{
+		Repo:     memory.NewRepository(seed),
+		School:   seed.Schools[0],
+		Grades:   seed.Grades,
+		Classes:  seed.Classes,
+		Teachers: seed.Teachers,
+		Students: seed.Students,
+	}
+}
+
+func (b *SchoolBuilder) id(kind string, n int) string {
+	return fmt.Sprintf("%s%s-%03d", b.prefix, kind, n)
+}
+
+// Fixture is a seeded repository together with the entities it was seeded
+// with, so tests can refer to them without hard-coding IDs.
+type Fixture struct {
+	Repo     *memory.Repository
+	School   domain.School
+	Grades   []domain.Grade
+	Classes  []domain.Class
+	Teachers []domain.Teacher
+	Students []domain.Student
+}
+
+// Teacher returns the ID of the i-th teacher, counting from zero.
+func (f *Fixture) Teacher(i int) domain.TeacherID {
+	return f.Teachers[i].ID
+}
+
+// Student returns the ID of the i-th student, counting from zero.
+func (f *Fixture) Student(i int) domain.StudentID {
+	return f.Students[i].ID
+}
+
+// StudentsOf returns the students of a class.
+func (f *Fixture) StudentsOf(classID domain.ClassID) []domain.StudentID {
+	var ids []domain.StudentID
+	for _, s := range f.Students {
+		if s.ClassID == classID {
+			ids = append(ids, s.ID)
+		}
+	}
+	return ids
+}
+
+// Merge combines seeds of several schools; use WithPrefix to keep IDs unique.
+func Merge(seeds ...memory.SeedData) memory.SeedData {
+	var out memory.SeedData
+	for _, s := range seeds {
+		out.Schools = append(out.Schools, s.Schools...)
+		out.Grades = append(out.Grades, s.Grades...)
+		out.Classes = append(out.Classes, s.Classes...)
+		out.Teachers = append(out.Teachers, s.Teachers...)
+		out.Students = append(out.Students, s.Students...)
+	}
+	return out
+}
@@ -0,0 +1,50 @@
+package fixtures_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/memory"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+func TestSchoolBuilderShapesHierarchy(t *testing.T) {
+	ctx := context.Background()
+	fx := fixtures.NewSchool().WithGrades(2).WithClasses(2).WithStudents(30).WithTeachers(3).Build()
+
+	if len(fx.Classes) != 4 || len(fx.Students) != 120 || len(fx.Teachers) != 3 {
+		t.Fatalf("unexpected shape: %d classes, %d students, %d teachers", len(fx.Classes), len(fx.Students), len(fx.Teachers))
+	}
+	if fx.Student(41) != "student-042" || fx.Teacher(0) != "teacher-001" {
+		t.Fatalf("expected sequential IDs, got %s and %s", fx.Student(41), fx.Teacher(0))
+	}
+
+	students, err := repository.Collect(fx.Repo.ListStudents(ctx, fx.Classes[1].ID, repository.All))
+	if err != nil {
+		t.Fatalf("ListStudents failed: %v", err)
+	}
+	if len(students) != 30 || len(fx.StudentsOf(fx.Classes[1].ID)) != 30 {
+		t.Fatalf("expected 30 students in class, got %d", len(students))
+	}
+	if err := memory.CheckIntegrity(fx.Repo.ExportState()); err != nil {
+		t.Fatalf("fixture is not consistent: %v", err)
+	}
+}
+
+func TestMergeKeepsPrefixedSchoolsApart(t *testing.T) {
+	ctx := context.Background()
+	seed := fixtures.Merge(
+		fixtures.NewSchool().WithPrefix("a-").Seed(),
+		fixtures.NewSchool().WithPrefix("b-").Seed(),
+	)
+	repo := memory.NewRepository(seed)
+
+	schools, err := repository.Collect(repo.ListSchools(ctx, repository.All))
+	if err != nil || len(schools) != 2 {
+		t.Fatalf("expected two schools, got %d (%v)", len(schools), err)
+	}
+	if student, _ := repo.GetStudent(ctx, "b-student-001"); student == nil || student.ClassID != "b-class-001" {
+		t.Fatalf("expected prefixed student in prefixed class, got %+v", student)
+	}
+}
@@ -0,0 +1,390 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Request is a GraphQL request as frontends send it.
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// Response is the result of a request. Data is missing when the request was
+// refused before it ran and null when a non-null root field failed.
+type Response struct {
+	Data   json.RawMessage `json:"data,omitempty"`
+	Errors []*Error        `json:"errors,omitempty"`
+}
+
+// Error is an error of a request. Path leads to the field that failed.
+type Error struct {
+	Message   string     `json:"message"`
+	Locations []Location `json:"locations,omitempty"`
+	Path      []any      `json:"path,omitempty"`
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// Execute parses, validates and runs the request. Fields resolve one after
+// another, so resolvers may share per-request state without locking.
+func (s *Schema) Execute(ctx context.Context, req Request) *Response {
+	doc, err := parse(req.Query)
+	if err != nil {
+		return &Response{Errors: []*Error{err.(*Error)}}
+	}
+	op, err := doc.operation(req.OperationName)
+	if err != nil {
+		return &Response{Errors: []*Error{err.(*Error)}}
+	}
+	if errs := s.validate(doc, op); len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+	vars, errs := s.coerceVariables(op, req.Variables)
+	if len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+
+	e := &executor{doc: doc, vars: vars}
+	data, ok := e.selectionSet(ctx, s.query, nil, op.selections, nil)
+	raw := json.RawMessage("null")
+	if ok {
+		if raw, err = json.Marshal(data); err != nil {
+			return &Response{Data: json.RawMessage("null"), Errors: append(e.errs, &Error{Message: err.Error()})}
+		}
+	}
+	return &Response{Data: raw, Errors: e.errs}
+}
+
+// operation picks the operation to run: the named one, or the only one.
+func (d *document) operation(name string) (*operation, error) {
+	if name == "" {
+		if len(d.operations) > 1 {
+			return nil, &Error{Message: "operationName is required when the document has several operations"}
+		}
+		return d.operations[0], nil
+	}
+	for _, op := range d.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, &Error{Message: fmt.Sprintf("Unknown operation %q", name)}
+}
+
+func (s *Schema) coerceVariables(op *operation, input map[string]any) (map[string]any, []*Error) {
+	vars := make(map[string]any, len(op.vars))
+	var errs []*Error
+	for _, def := range op.vars {
+		t, _ := s.inputType(def.typ)
+		given, ok := input[def.name]
+		if !ok && def.hasDefault {
+			given, ok = def.def, true
+		}
+		if !ok {
+			if def.typ.nonNull {
+				errs = append(errs, &Error{Message: fmt.Sprintf("Variable \"$%s\" of required type %s was not provided", def.name, def.typ), Locations: []Location{def.loc}})
+			}
+			continue
+		}
+		v, err := coerceInput(t, given, nil)
+		if err != nil {
+			errs = append(errs, &Error{Message: fmt.Sprintf("Variable \"$%s\" got an invalid value: %v", def.name, err), Locations: []Location{def.loc}})
+			continue
+		}
+		vars[def.name] = v
+	}
+	return vars, errs
+}
+
+// coerceInput turns a literal or a JSON value given where t is expected into
+// the value resolvers receive. Variable references take their coerced value
+// from vars; a single value given for a list becomes a list of one.
+func coerceInput(t Type, v value, vars map[string]any) (any, error) {
+	if ref, ok := v.(variableRef); ok {
+		v = vars[string(ref)]
+		if _, required := t.(*NonNull); required && v == nil {
+			return nil, fmt.Errorf("variable $%s is null", ref)
+		}
+		return v, nil
+	}
+	switch t := t.(type) {
+	case *NonNull:
+		if v == nil {
+			return nil, fmt.Errorf("expected %s, found null", t)
+		}
+		return coerceInput(t.Of, v, vars)
+	case *List:
+		if v == nil {
+			return nil, nil
+		}
+		items, ok := v.([]any)
+		if !ok {
+			item, err := coerceInput(t.Of, v, vars)
+			if err != nil {
+				return nil, err
+			}
+			return []any{item}, nil
+		}
+		out := make([]any, len(items))
+		for i, item := range items {
+			var err error
+			if out[i], err = coerceInput(t.Of, item, vars); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	case *Scalar:
+		if v == nil {
+			return nil, nil
+		}
+		if _, ok := v.([]any); ok {
+			return nil, fmt.Errorf("expected %s, found a list", t)
+		}
+		return t.Parse(v)
+	}
+	return nil, fmt.Errorf("%s is not an input type", t)
+}
+
+type executor struct {
+	doc  *document
+	vars map[string]any
+	errs []*Error
+}
+
+// fieldGroups are the fields of a selection set by response key, in the
+// order the keys first appear.
+type fieldGroups struct {
+	order  []string
+	fields map[string][]*field
+}
+
+// collectFields groups the fields of sels, following fragments. skip, when
+// given, drops selections by their directives.
+func collectFields(doc *document, sels []selection, skip func([]*directive) bool) *fieldGroups {
+	groups := &fieldGroups{fields: make(map[string][]*field)}
+	visited := make(map[string]bool)
+	var collect func([]selection)
+	collect = func(sels []selection) {
+		for _, sel := range sels {
+			switch sel := sel.(type) {
+			case *field:
+				if skip != nil && skip(sel.directives) {
+					continue
+				}
+				key := sel.responseKey()
+				if _, ok := groups.fields[key]; !ok {
+					groups.order = append(groups.order, key)
+				}
+				groups.fields[key] = append(groups.fields[key], sel)
+			case *fragmentSpread:
+				frag, ok := doc.fragments[sel.name]
+				if !ok || visited[sel.name] || skip != nil && skip(sel.directives) {
+					continue
+				}
+				visited[sel.name] = true
+				collect(frag.selections)
+			case *inlineFragment:
+				if skip != nil && skip(sel.directives) {
+					continue
+				}
+				collect(sel.selections)
+			}
+		}
+	}
+	collect(sels)
+	return groups
+}
+
+// mergeSelections joins the selection sets of fields sharing a response key.
+func mergeSelections(fields []*field) []selection {
+	var sels []selection
+	for _, f := range fields {
+		sels = append(sels, f.selections...)
+	}
+	return sels
+}
+
+// skip reports whether @skip or @include leave the selection out.
+func (e *executor) skip(dirs []*directive) bool {
+	for _, d := range dirs {
+		cond, _ := coerceInput(directiveArgs["if"].Type, d.args[0].value, e.vars)
+		if (d.name == "skip") == (cond == true) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectionSet resolves the selected fields of source. It reports false
+// when a non-null field failed, which nulls the object.
+func (e *executor) selectionSet(ctx context.Context, obj *Object, source any, sels []selection, path []any) (orderedObject, bool) {
+	groups := collectFields(e.doc, sels, e.skip)
+	out := make(orderedObject, 0, len(groups.order))
+	for _, key := range groups.order {
+		fields := groups.fields[key]
+		v, ok := e.field(ctx, obj, source, fields, appendPath(path, key))
+		if !ok {
+			return nil, false
+		}
+		out = append(out, member{key: key, value: v})
+	}
+	return out, true
+}
+
+// field resolves and completes one field. A failed nullable field becomes
+// null; a failed non-null field reports false to null its parent.
+func (e *executor) field(ctx context.Context, obj *Object, source any, fields []*field, path []any) (any, bool) {
+	f := fields[0]
+	if f.name == "__typename" {
+		return obj.Name, true
+	}
+	def := obj.Fields[f.name]
+	_, required := def.Type.(*NonNull)
+
+	args, err := e.arguments(def.Args, f.args)
+	if err == nil {
+		var resolved any
+		if resolved, err = def.Resolve(ctx, source, args); err == nil {
+			v, ok := e.complete(ctx, def.Type, obj, fields, resolved, path)
+			return v, ok || !required
+		}
+	}
+	e.fail(f, path, err.Error())
+	return nil, !required
+}
+
+func (e *executor) arguments(defs Args, given []*argument) (map[string]any, error) {
+	values := make(map[string]value, len(given))
+	for _, arg := range given {
+		values[arg.name] = arg.value
+	}
+	args := make(map[string]any, len(defs))
+	for name, def := range defs {
+		v, ok := values[name]
+		if ref, isVar := v.(variableRef); ok && isVar {
+			_, ok = e.vars[string(ref)]
+		}
+		if !ok {
+			if def.Default != nil {
+				args[name] = def.Default
+			}
+			continue
+		}
+		coerced, err := coerceInput(def.Type, v, e.vars)
+		if err != nil {
+			return nil, fmt.Errorf("argument %q: %w", name, err)
+		}
+		args[name] = coerced
+	}
+	return args, nil
+}
+
+// complete turns a resolved value into the JSON value of type t, resolving
+// the selections of objects. It reports false when the value must be null
+// but t is non-null, or the value does not fit t.
+func (e *executor) complete(ctx context.Context, t Type, parent *Object, fields []*field, v any, path []any) (any, bool) {
+	if nn, ok := t.(*NonNull); ok {
+		out, ok := e.complete(ctx, nn.Of, parent, fields, v, path)
+		if ok && out == nil {
+			e.fail(fields[0], path, fmt.Sprintf("Cannot return null for non-nullable field %s.%s.", parent.Name, fields[0].name))
+			return nil, false
+		}
+		return out, ok
+	}
+	if isNull(v) {
+		return nil, true
+	}
+
+	switch t := t.(type) {
+	case *List:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			e.fail(fields[0], path, fmt.Sprintf("Expected a list for field %s.%s.", parent.Name, fields[0].name))
+			return nil, false
+		}
+		_, required := t.Of.(*NonNull)
+		items := make([]any, rv.Len())
+		for i := range items {
+			item, ok := e.complete(ctx, t.Of, parent, fields, rv.Index(i).Interface(), appendPath(path, i))
+			if !ok && required {
+				return nil, false
+			}
+			items[i] = item
+		}
+		return items, true
+	case *Scalar:
+		rv := reflect.ValueOf(v)
+		for rv.Kind() == reflect.Pointer {
+			rv = rv.Elem()
+		}
+		out, err := t.Serialize(rv.Interface())
+		if err != nil {
+			e.fail(fields[0], path, err.Error())
+			return nil, false
+		}
+		return out, true
+	case *Object:
+		obj, ok := e.selectionSet(ctx, t, v, mergeSelections(fields), path)
+		if !ok {
+			return nil, false
+		}
+		return obj, true
+	}
+	return nil, false
+}
+
+func (e *executor) fail(f *field, path []any, message string) {
+	e.errs = append(e.errs, &Error{Message: message, Locations: []Location{f.loc}, Path: path})
+}
+
+// isNull reports whether v is nil or a nil pointer, map or interface. Nil
+// slices are empty lists rather than null.
+func isNull(v any) bool {
+	if v == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Interface, reflect.Func:
+		return rv.IsNil()
+	}
+	return false
+}
+
+func appendPath(path []any, key any) []any {
+	return append(append(make([]any, 0, len(path)+1), path...), key)
+}
+
+// orderedObject is a response object, which keeps its fields in the order
+// they were selected.
+type orderedObject []member
+
+type member struct {
+	key   string
+	value any
+}
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(m.key)
+		b.Write(key)
+		b.WriteByte(':')
+		value, err := json.Marshal(m.value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
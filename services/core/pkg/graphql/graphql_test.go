@@ -0,0 +1,247 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/graphql"
+)
+
+type book struct {
+	ID     string
+	Title  string
+	Pages  int
+	Author *author
+}
+
+type author struct {
+	Name  string
+	Books []string
+}
+
+var library = map[string]*book{
+	"b1": {ID: "b1", Title: "Go", Pages: 300, Author: &author{Name: "Ann", Books: []string{"b1", "b2"}}},
+	"b2": {ID: "b2", Title: "SQL", Pages: 120, Author: &author{Name: "Ann", Books: []string{"b1", "b2"}}},
+	"b3": {ID: "b3", Title: "Anonymous"},
+}
+
+func newSchema(t *testing.T) *graphql.Schema {
+	t.Helper()
+	authorType := &graphql.Object{Name: "Author"}
+	bookType := &graphql.Object{Name: "Book", Description: "A book of the library."}
+	bookType.Fields = graphql.Fields{
+		"id":    {Type: graphql.NonNullOf(graphql.ID), Resolve: func(_ context.Context, src any, _ map[string]any) (any, error) { return src.(*book).ID, nil }},
+		"title": {Type: graphql.NonNullOf(graphql.String), Resolve: func(_ context.Context, src any, _ map[string]any) (any, error) { return src.(*book).Title, nil }},
+		"pages": {Type: graphql.Int, Resolve: func(_ context.Context, src any, _ map[string]any) (any, error) { return src.(*book).Pages, nil }},
+		"author": {Type: graphql.NonNullOf(authorType), Resolve: func(_ context.Context, src any, _ map[string]any) (any, error) {
+			return src.(*book).Author, nil
+		}},
+		"secret": {Type: graphql.String, Resolve: func(context.Context, any, map[string]any) (any, error) {
+			return nil, errors.New("forbidden")
+		}},
+	}
+	authorType.Fields = graphql.Fields{
+		"name": {Type: graphql.NonNullOf(graphql.String), Resolve: func(_ context.Context, src any, _ map[string]any) (any, error) { return src.(*author).Name, nil }},
+		"books": {Type: graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(bookType))), Resolve: func(_ context.Context, src any, _ map[string]any) (any, error) {
+			var books []*book
+			for _, id := range src.(*author).Books {
+				books = append(books, library[id])
+			}
+			return books, nil
+		}},
+	}
+	query := &graphql.Object{Name: "Query", Fields: graphql.Fields{
+		"book": {
+			Type: bookType,
+			Args: graphql.Args{"id": {Type: graphql.NonNullOf(graphql.ID)}},
+			Resolve: func(_ context.Context, _ any, args map[string]any) (any, error) {
+				return library[args["id"].(string)], nil
+			},
+		},
+		"books": {
+			Type: graphql.ListOf(bookType),
+			Args: graphql.Args{
+				"ids":   {Type: graphql.ListOf(graphql.NonNullOf(graphql.ID))},
+				"first": {Type: graphql.Int, Default: 10},
+			},
+			Resolve: func(_ context.Context, _ any, args map[string]any) (any, error) {
+				var books []*book
+				ids, _ := args["ids"].([]any)
+				for _, id := range ids {
+					if len(books) < args["first"].(int) {
+						books = append(books, library[id.(string)])
+					}
+				}
+				return books, nil
+			},
+		},
+	}}
+	schema, err := graphql.NewSchema(query)
+	if err != nil {
+		t.Fatalf("NewSchema: %v", err)
+	}
+	return schema
+}
+
+func execute(t *testing.T, schema *graphql.Schema, req graphql.Request) (string, []*graphql.Error) {
+	t.Helper()
+	resp := schema.Execute(context.Background(), req)
+	return string(resp.Data), resp.Errors
+}
+
+func TestExecuteResolvesNestedFieldsInOrder(t *testing.T) {
+	schema := newSchema(t)
+	data, errs := execute(t, schema, graphql.Request{
+		Query: `query Shelf($id: ID!, $skipPages: Boolean = false) {
+			first: book(id: $id) {
+				__typename
+				...bookFields
+				pages @skip(if: $skipPages)
+				author { name books { id } }
+			}
+			other: book(id: "b2") { ... on Book { title } pages @include(if: false) }
+		}
+		fragment bookFields on Book { id title }`,
+		Variables: map[string]any{"id": "b1", "skipPages": true},
+	})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs[0])
+	}
+	want := `{"first":{"__typename":"Book","id":"b1","title":"Go","author":{"name":"Ann","books":[{"id":"b1"},{"id":"b2"}]}},"other":{"title":"SQL"}}`
+	if data != want {
+		t.Fatalf("expected %s, got %s", want, data)
+	}
+}
+
+func TestExecuteCoercesArguments(t *testing.T) {
+	schema := newSchema(t)
+	data, errs := execute(t, schema, graphql.Request{
+		Query:     `query($ids: [ID!]) { all: books(ids: $ids) { id } one: books(ids: "b3", first: 1) { title } }`,
+		Variables: map[string]any{"ids": []any{"b1", "b2", "b3"}},
+	})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs[0])
+	}
+	want := `{"all":[{"id":"b1"},{"id":"b2"},{"id":"b3"}],"one":[{"title":"Anonymous"}]}`
+	if data != want {
+		t.Fatalf("expected %s, got %s", want, data)
+	}
+}
+
+func TestExecuteReportsFieldErrorsWithPaths(t *testing.T) {
+	schema := newSchema(t)
+	data, errs := execute(t, schema, graphql.Request{Query: `{ book(id: "b1") { title secret } }`})
+	if data != `{"book":{"title":"Go","secret":null}}` {
+		t.Fatalf("expected the failed field to be null, got %s", data)
+	}
+	if len(errs) != 1 || errs[0].Message != "forbidden" {
+		t.Fatalf("expected the resolver error, got %v", errs)
+	}
+	path, _ := json.Marshal(errs[0].Path)
+	if string(path) != `["book","secret"]` {
+		t.Fatalf("expected the path of the field, got %s", path)
+	}
+}
+
+func TestExecutePropagatesNullsToNullableParent(t *testing.T) {
+	schema := newSchema(t)
+	data, errs := execute(t, schema, graphql.Request{Query: `{ book(id: "b3") { title author { name } } }`})
+	if data != `{"book":null}` {
+		t.Fatalf("expected the book to be nulled, got %s", data)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Message, "Book.author") {
+		t.Fatalf("expected a non-null error for Book.author, got %v", errs)
+	}
+}
+
+func TestExecuteRefusesInvalidDocuments(t *testing.T) {
+	schema := newSchema(t)
+	for _, tc := range []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"syntax", `{ book(id: "b1") { title }`, "Syntax Error"},
+		{"unknown field", `{ book(id: "b1") { isbn } }`, `Cannot query field "isbn"`},
+		{"missing argument", `{ book { title } }`, `Argument "id" of type ID! is required`},
+		{"missing selection", `{ book(id: "b1") }`, "must have a selection"},
+		{"leaf selection", `{ book(id: "b1") { title { x } } }`, "must not have a selection"},
+		{"undefined variable", `{ book(id: $id) { title } }`, `Variable "$id" is not defined`},
+		{"variable type", `query($id: Int!) { book(id: $id) { title } }`, "cannot be used where ID! is expected"},
+		{"wrong literal", `{ books(first: "ten") { id } }`, `Argument "first" expects Int`},
+		{"unknown fragment", `{ book(id: "b1") { ...missing } }`, `Unknown fragment "missing"`},
+		{"fragment cycle", `{ book(id: "b1") { ...a } } fragment a on Book { author { books { ...a } } }`, `Fragment "a" spreads itself`},
+		{"type condition", `{ book(id: "b1") { ... on Author { name } } }`, "cannot be spread within"},
+		{"conflict", `{ book(id: "b1") { x: title x: id } }`, `Fields "x" conflict`},
+		{"mutation", `mutation { book(id: "b1") { title } }`, "mutation operations are not supported"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := schema.Execute(context.Background(), graphql.Request{Query: tc.query})
+			if resp.Data != nil {
+				t.Fatalf("expected no data, got %s", resp.Data)
+			}
+			if len(resp.Errors) == 0 || !strings.Contains(resp.Errors[0].Message, tc.want) {
+				t.Fatalf("expected an error containing %q, got %v", tc.want, resp.Errors)
+			}
+		})
+	}
+}
+
+func TestExecuteRequiresVariables(t *testing.T) {
+	schema := newSchema(t)
+	_, errs := execute(t, schema, graphql.Request{Query: `query($id: ID!) { book(id: $id) { title } }`})
+	if len(errs) != 1 || !strings.Contains(errs[0].Message, "was not provided") {
+		t.Fatalf("expected a missing variable error, got %v", errs)
+	}
+}
+
+func TestHandlerServesGetAndPost(t *testing.T) {
+	handler := graphql.Handler(newSchema(t))
+
+	rec := httptest.NewRecorder()
+	body := `{"query":"query($id: ID!) { book(id: $id) { title } }","variables":{"id":"b2"}}`
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body)))
+	if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != `{"data":{"book":{"title":"SQL"}}}` {
+		t.Fatalf("unexpected POST response %d %s", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graphql?query=%7B+book%28id%3A+%22b1%22%29+%7B+pages+%7D+%7D", nil))
+	if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != `{"data":{"book":{"pages":300}}}` {
+		t.Fatalf("unexpected GET response %d %s", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":"{ nope }"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid query, got %d", rec.Code)
+	}
+}
+
+func TestSchemaSDL(t *testing.T) {
+	sdl := newSchema(t).SDL()
+	for _, want := range []string{
+		"type Query {\n  book(id: ID!): Book\n  books(first: Int = 10, ids: [ID!]): [Book]\n}",
+		"\"A book of the library.\"\ntype Book {",
+		"  books: [Book!]!\n",
+	} {
+		if !strings.Contains(sdl, want) {
+			t.Fatalf("expected SDL to contain %q, got:\n%s", want, sdl)
+		}
+	}
+	if !strings.HasPrefix(sdl, "type Query") {
+		t.Fatalf("expected the query type first, got:\n%s", sdl)
+	}
+}
+
+func TestNewSchemaRejectsFieldsWithoutResolvers(t *testing.T) {
+	query := &graphql.Object{Name: "Query", Fields: graphql.Fields{"x": {Type: graphql.Int}}}
+	if _, err := graphql.NewSchema(query); err == nil {
+		t.Fatalf("expected an error for a field without a resolver")
+	}
+}
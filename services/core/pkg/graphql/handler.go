@@ -0,0 +1,67 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Handler serves the schema over HTTP. It takes POST requests with a JSON
+// body and GET requests with query, operationName and variables in the
+// query string. Requests refused before running answer 400; requests that
+// ran answer 200 with any field errors listed in the body.
+func Handler(schema *Schema) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		switch r.Method {
+		case http.MethodGet:
+			q := r.URL.Query()
+			req.Query = q.Get("query")
+			req.OperationName = q.Get("operationName")
+			if vars := q.Get("variables"); vars != "" {
+				if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+					writeResponse(w, http.StatusBadRequest, &Response{Errors: []*Error{{Message: "variables must be a JSON object"}}})
+					return
+				}
+			}
+		case http.MethodPost:
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeResponse(w, http.StatusBadRequest, &Response{Errors: []*Error{{Message: "invalid request body"}}})
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if req.Query == "" {
+			writeResponse(w, http.StatusBadRequest, &Response{Errors: []*Error{{Message: "query is required"}}})
+			return
+		}
+
+		resp := schema.Execute(r.Context(), req)
+		status := http.StatusOK
+		if resp.Data == nil {
+			status = http.StatusBadRequest
+		}
+		writeResponse(w, status, resp)
+	})
+}
+
+// SDLHandler serves the schema in the schema definition language.
+func SDLHandler(schema *Schema) http.Handler {
+	sdl := schema.SDL()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte(sdl))
+	})
+}
+
+func writeResponse(w http.ResponseWriter, status int, resp *Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,147 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// Scalar is a leaf type. Serialize turns a resolved Go value into its JSON
+// form; Parse turns an argument, given as a literal or a JSON variable, into
+// the Go value resolvers receive.
+type Scalar struct {
+	Name        string
+	Description string
+	Serialize   func(v any) (any, error)
+	Parse       func(v any) (any, error)
+}
+
+func (s *Scalar) String() string { return s.Name }
+func (*Scalar) isType()          {}
+
+// The built-in scalars. Int arguments reach resolvers as int, Float as
+// float64, String and ID as string and Boolean as bool.
+var (
+	Int = &Scalar{
+		Name:      "Int",
+		Serialize: func(v any) (any, error) { return toInt(v) },
+		Parse:     func(v any) (any, error) { return toInt(v) },
+	}
+	Float = &Scalar{
+		Name:      "Float",
+		Serialize: func(v any) (any, error) { return toFloat(v) },
+		Parse:     func(v any) (any, error) { return toFloat(v) },
+	}
+	String = &Scalar{
+		Name:      "String",
+		Serialize: func(v any) (any, error) { return toString(v, false) },
+		Parse:     func(v any) (any, error) { return toString(v, false) },
+	}
+	Boolean = &Scalar{
+		Name: "Boolean",
+		Serialize: func(v any) (any, error) {
+			b, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("Boolean cannot represent %v", v)
+			}
+			return b, nil
+		},
+		Parse: func(v any) (any, error) {
+			b, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("Boolean cannot represent %v", v)
+			}
+			return b, nil
+		},
+	}
+	ID = &Scalar{
+		Name:      "ID",
+		Serialize: func(v any) (any, error) { return toString(v, true) },
+		Parse:     func(v any) (any, error) { return toString(v, true) },
+	}
+)
+
+var builtinScalars = []*Scalar{Int, Float, String, Boolean, ID}
+
+func isBuiltin(name string) bool {
+	for _, sc := range builtinScalars {
+		if sc.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func toInt(v any) (any, error) {
+	switch n := v.(type) {
+	case int:
+		return n, nil
+	case int32:
+		return int(n), nil
+	case int64:
+		if n >= math.MinInt32 && n <= math.MaxInt32 {
+			return int(n), nil
+		}
+	case float64:
+		if n == math.Trunc(n) && n >= math.MinInt32 && n <= math.MaxInt32 {
+			return int(n), nil
+		}
+	case json.Number:
+		if i, err := n.Int64(); err == nil {
+			return toInt(i)
+		}
+	default:
+		// Named integer types such as scores.
+		if rv := reflect.ValueOf(v); rv.IsValid() && rv.CanInt() {
+			return toInt(rv.Int())
+		}
+	}
+	return nil, fmt.Errorf("Int cannot represent %v", v)
+}
+
+func toFloat(v any) (any, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case float32:
+		return float64(n), nil
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case json.Number:
+		if f, err := n.Float64(); err == nil {
+			return f, nil
+		}
+	default:
+		if rv := reflect.ValueOf(v); rv.IsValid() && rv.CanFloat() {
+			return rv.Float(), nil
+		}
+		if rv := reflect.ValueOf(v); rv.IsValid() && rv.CanInt() {
+			return float64(rv.Int()), nil
+		}
+	}
+	return nil, fmt.Errorf("Float cannot represent %v", v)
+}
+
+// toString accepts strings and, for IDs, integers too.
+func toString(v any, id bool) (any, error) {
+	switch s := v.(type) {
+	case string:
+		return s, nil
+	case fmt.Stringer:
+		return s.String(), nil
+	}
+	if id {
+		if n, err := toInt(v); err == nil {
+			return strconv.Itoa(n.(int)), nil
+		}
+	}
+	// Named string types such as IDs of the domain.
+	if rv := reflect.ValueOf(v); rv.IsValid() && rv.Kind() == reflect.String {
+		return rv.String(), nil
+	}
+	return nil, fmt.Errorf("String cannot represent %v", v)
+}
@@ -0,0 +1,229 @@
+// Package graphql serves a read-only GraphQL API from a schema of Go
+// resolvers. It implements the part of the query language frontends use:
+// fields with aliases and arguments, variables, named and inline fragments,
+// and the @skip and @include directives. Mutations, subscriptions and
+// introspection beyond __typename are not supported; Schema.SDL documents
+// the schema instead.
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Type is the type of a field or argument: a *Scalar, an *Object, or a
+// *List or *NonNull wrapping one.
+type Type interface {
+	String() string
+	isType()
+}
+
+// ResolveFunc computes a field of source, the value its parent resolved to
+// (nil for the fields of the query type). args holds the coerced arguments;
+// arguments left out without a default are missing from it.
+type ResolveFunc func(ctx context.Context, source any, args map[string]any) (any, error)
+
+// Object is a type with fields. Fields may refer back to the object, so it
+// can be declared first and have its fields assigned afterwards.
+type Object struct {
+	Name        string
+	Description string
+	Fields      Fields
+}
+
+// Fields maps field names to their definitions.
+type Fields map[string]*Field
+
+// Field is a field of an object.
+type Field struct {
+	Type        Type
+	Description string
+	Args        Args
+	Resolve     ResolveFunc
+}
+
+// Args maps argument names to their definitions.
+type Args map[string]*Argument
+
+// Argument is an argument of a field. Arguments take scalars or lists of
+// them. Default is used when the argument is left out.
+type Argument struct {
+	Type        Type
+	Description string
+	Default     any
+}
+
+// List is a list of values of a type.
+type List struct {
+	Of Type
+}
+
+// NonNull is a type whose values are never null.
+type NonNull struct {
+	Of Type
+}
+
+// ListOf returns the list type of t.
+func ListOf(t Type) *List { return &List{Of: t} }
+
+// NonNullOf returns the non-null type of t.
+func NonNullOf(t Type) *NonNull { return &NonNull{Of: t} }
+
+func (o *Object) String() string  { return o.Name }
+func (l *List) String() string    { return "[" + l.Of.String() + "]" }
+func (n *NonNull) String() string { return n.Of.String() + "!" }
+func (*Object) isType()           {}
+func (*List) isType()             {}
+func (*NonNull) isType()          {}
+
+// Schema is a validated set of types reachable from the query type.
+type Schema struct {
+	query   *Object
+	objects map[string]*Object
+	scalars map[string]*Scalar
+}
+
+// NewSchema validates the types reachable from query: names must be unique,
+// every field needs a resolver, and arguments may only take scalars.
+func NewSchema(query *Object) (*Schema, error) {
+	if query == nil {
+		return nil, fmt.Errorf("graphql: schema needs a query type")
+	}
+	s := &Schema{
+		query:   query,
+		objects: make(map[string]*Object),
+		scalars: make(map[string]*Scalar),
+	}
+	for _, sc := range builtinScalars {
+		s.scalars[sc.Name] = sc
+	}
+	if err := s.addObject(query); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Schema) addObject(obj *Object) error {
+	if seen, ok := s.objects[obj.Name]; ok {
+		if seen != obj {
+			return fmt.Errorf("graphql: two types named %s", obj.Name)
+		}
+		return nil
+	}
+	if _, ok := s.scalars[obj.Name]; ok || obj.Name == "" || strings.HasPrefix(obj.Name, "__") {
+		return fmt.Errorf("graphql: invalid type name %q", obj.Name)
+	}
+	s.objects[obj.Name] = obj
+	for name, f := range obj.Fields {
+		if f.Type == nil || f.Resolve == nil {
+			return fmt.Errorf("graphql: field %s.%s needs a type and a resolver", obj.Name, name)
+		}
+		for argName, arg := range f.Args {
+			if _, ok := namedType(arg.Type).(*Scalar); !ok {
+				return fmt.Errorf("graphql: argument %s.%s(%s) must take a scalar", obj.Name, name, argName)
+			}
+			if err := s.addNamed(arg.Type); err != nil {
+				return err
+			}
+		}
+		if err := s.addNamed(f.Type); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) addNamed(t Type) error {
+	switch named := namedType(t).(type) {
+	case *Object:
+		return s.addObject(named)
+	case *Scalar:
+		if seen, ok := s.scalars[named.Name]; ok && seen != named {
+			return fmt.Errorf("graphql: two scalars named %s", named.Name)
+		}
+		if _, ok := s.objects[named.Name]; ok {
+			return fmt.Errorf("graphql: two types named %s", named.Name)
+		}
+		s.scalars[named.Name] = named
+	}
+	return nil
+}
+
+// SDL prints the schema in the GraphQL schema definition language, types
+// and fields sorted by name.
+func (s *Schema) SDL() string {
+	var b strings.Builder
+	for _, name := range sortedKeys(s.scalars) {
+		if isBuiltin(name) {
+			continue
+		}
+		writeDescription(&b, "", s.scalars[name].Description)
+		fmt.Fprintf(&b, "scalar %s\n\n", name)
+	}
+	names := sortedKeys(s.objects)
+	// The query type leads, the rest follow by name.
+	sort.SliceStable(names, func(i, j int) bool { return names[i] == s.query.Name && names[j] != s.query.Name })
+	for i, name := range names {
+		obj := s.objects[name]
+		writeDescription(&b, "", obj.Description)
+		fmt.Fprintf(&b, "type %s {\n", name)
+		for _, fieldName := range sortedKeys(obj.Fields) {
+			f := obj.Fields[fieldName]
+			writeDescription(&b, "  ", f.Description)
+			fmt.Fprintf(&b, "  %s%s: %s\n", fieldName, sdlArgs(f.Args), f.Type)
+		}
+		b.WriteString("}\n")
+		if i < len(names)-1 {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+func sdlArgs(args Args) string {
+	if len(args) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(args))
+	for _, name := range sortedKeys(args) {
+		arg := args[name]
+		part := name + ": " + arg.Type.String()
+		if arg.Default != nil {
+			part += " = " + fmt.Sprintf("%#v", arg.Default)
+		}
+		parts = append(parts, part)
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+func writeDescription(b *strings.Builder, indent, description string) {
+	if description == "" {
+		return
+	}
+	fmt.Fprintf(b, "%s%q\n", indent, description)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// namedType strips the list and non-null wrappers off t.
+func namedType(t Type) Type {
+	for {
+		switch w := t.(type) {
+		case *List:
+			t = w.Of
+		case *NonNull:
+			t = w.Of
+		default:
+			return t
+		}
+	}
+}
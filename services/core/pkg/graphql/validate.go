@@ -0,0 +1,295 @@
+package graphql
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// validator checks an operation against the schema before it runs, so
+// execution only meets errors raised by resolvers.
+type validator struct {
+	schema   *Schema
+	doc      *document
+	vars     map[string]*varDef
+	used     map[string]bool
+	visiting map[string]bool
+	errs     []*Error
+}
+
+func (s *Schema) validate(doc *document, op *operation) []*Error {
+	v := &validator{
+		schema:   s,
+		doc:      doc,
+		vars:     make(map[string]*varDef),
+		used:     make(map[string]bool),
+		visiting: make(map[string]bool),
+	}
+	if op.kind != "query" {
+		v.fail(op.loc, "%s operations are not supported", op.kind)
+		return v.errs
+	}
+	for _, def := range op.vars {
+		if _, dup := v.vars[def.name]; dup {
+			v.fail(def.loc, "Variable \"$%s\" is defined twice", def.name)
+			continue
+		}
+		v.vars[def.name] = def
+		t, ok := s.inputType(def.typ)
+		if !ok {
+			v.fail(def.loc, "Variable \"$%s\" cannot be of type %s", def.name, def.typ)
+			continue
+		}
+		if def.hasDefault {
+			v.value(t, def.def, def.loc, "Variable \"$"+def.name+"\"")
+		}
+	}
+	v.selections(s.query, op.selections)
+	if len(v.errs) == 0 {
+		v.conflicts(s.query, op.selections)
+	}
+	for _, def := range op.vars {
+		if !v.used[def.name] {
+			v.fail(def.loc, "Variable \"$%s\" is never used", def.name)
+		}
+	}
+	return v.errs
+}
+
+func (v *validator) fail(loc Location, format string, args ...any) {
+	v.errs = append(v.errs, &Error{Message: fmt.Sprintf(format, args...), Locations: []Location{loc}})
+}
+
+func (v *validator) selections(parent *Object, sels []selection) {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *field:
+			v.directives(sel.directives)
+			v.field(parent, sel)
+		case *fragmentSpread:
+			v.directives(sel.directives)
+			frag, ok := v.doc.fragments[sel.name]
+			if !ok {
+				v.fail(sel.loc, "Unknown fragment %q", sel.name)
+				continue
+			}
+			if v.visiting[sel.name] {
+				v.fail(sel.loc, "Fragment %q spreads itself", sel.name)
+				continue
+			}
+			if !v.typeCondition(parent, frag.typeCond, frag.loc) {
+				continue
+			}
+			v.visiting[sel.name] = true
+			v.selections(parent, frag.selections)
+			delete(v.visiting, sel.name)
+		case *inlineFragment:
+			v.directives(sel.directives)
+			if sel.typeCond == "" || v.typeCondition(parent, sel.typeCond, sel.loc) {
+				v.selections(parent, sel.selections)
+			}
+		}
+	}
+}
+
+// typeCondition reports whether a fragment on the named type can be spread
+// in parent. The schema has no interfaces or unions, so only the parent
+// itself matches.
+func (v *validator) typeCondition(parent *Object, name string, loc Location) bool {
+	if _, ok := v.schema.objects[name]; !ok {
+		v.fail(loc, "Unknown type %q", name)
+		return false
+	}
+	if name != parent.Name {
+		v.fail(loc, "Fragment on %s cannot be spread within %s", name, parent.Name)
+		return false
+	}
+	return true
+}
+
+func (v *validator) field(parent *Object, f *field) {
+	if f.name == "__typename" {
+		if len(f.args) > 0 || f.selections != nil {
+			v.fail(f.loc, "Field \"__typename\" takes no arguments or selections")
+		}
+		return
+	}
+	def, ok := parent.Fields[f.name]
+	if !ok {
+		v.fail(f.loc, "Cannot query field %q on type %q", f.name, parent.Name)
+		return
+	}
+	v.arguments(fmt.Sprintf("%s.%s", parent.Name, f.name), def.Args, f.args, f.loc)
+
+	switch named := namedType(def.Type).(type) {
+	case *Object:
+		if f.selections == nil {
+			v.fail(f.loc, "Field %q of type %q must have a selection of subfields", f.name, def.Type)
+			return
+		}
+		v.selections(named, f.selections)
+	case *Scalar:
+		if f.selections != nil {
+			v.fail(f.loc, "Field %q must not have a selection since type %q has no subfields", f.name, def.Type)
+		}
+	}
+}
+
+func (v *validator) arguments(owner string, defs Args, args []*argument, loc Location) {
+	given := make(map[string]bool, len(args))
+	for _, arg := range args {
+		if given[arg.name] {
+			v.fail(arg.loc, "Argument %q is given twice", arg.name)
+			continue
+		}
+		given[arg.name] = true
+		def, ok := defs[arg.name]
+		if !ok {
+			v.fail(arg.loc, "Unknown argument %q on %s", arg.name, owner)
+			continue
+		}
+		v.value(def.Type, arg.value, arg.loc, fmt.Sprintf("Argument %q", arg.name))
+	}
+	for _, name := range sortedKeys(defs) {
+		def := defs[name]
+		if _, required := def.Type.(*NonNull); required && def.Default == nil && !given[name] {
+			v.fail(loc, "Argument %q of type %s is required on %s", name, def.Type, owner)
+		}
+	}
+}
+
+// directiveArgs declares the argument of @skip and @include.
+var directiveArgs = Args{"if": {Type: NonNullOf(Boolean)}}
+
+func (v *validator) directives(dirs []*directive) {
+	for _, d := range dirs {
+		if d.name != "skip" && d.name != "include" {
+			v.fail(d.loc, "Unknown directive \"@%s\"", d.name)
+			continue
+		}
+		v.arguments("@"+d.name, directiveArgs, d.args, d.loc)
+	}
+}
+
+// value checks a value given where t is expected.
+func (v *validator) value(t Type, val value, loc Location, what string) {
+	if ref, ok := val.(variableRef); ok {
+		def, ok := v.vars[string(ref)]
+		if !ok {
+			v.fail(loc, "Variable \"$%s\" is not defined", ref)
+			return
+		}
+		v.used[def.name] = true
+		if !compatible(def.typ, def.hasDefault, t) {
+			v.fail(loc, "Variable \"$%s\" of type %s cannot be used where %s is expected", ref, def.typ, t)
+		}
+		return
+	}
+	switch t := t.(type) {
+	case *NonNull:
+		if val == nil {
+			v.fail(loc, "%s expects %s, found null", what, t)
+			return
+		}
+		v.value(t.Of, val, loc, what)
+	case *List:
+		items, ok := val.([]any)
+		if !ok {
+			v.value(t.Of, val, loc, what)
+			return
+		}
+		for _, item := range items {
+			v.value(t.Of, item, loc, what)
+		}
+	case *Scalar:
+		if val == nil {
+			return
+		}
+		if _, ok := val.([]any); ok {
+			v.fail(loc, "%s expects %s, found a list", what, t)
+			return
+		}
+		if _, err := t.Parse(val); err != nil {
+			v.fail(loc, "%s expects %s: %v", what, t, err)
+		}
+	}
+}
+
+// compatible reports whether a variable of type ref can be used where t is
+// expected. A nullable variable with a default may fill a non-null position.
+func compatible(ref *typeRef, hasDefault bool, t Type) bool {
+	if nn, ok := t.(*NonNull); ok {
+		if !ref.nonNull && !hasDefault {
+			return false
+		}
+		t = nn.Of
+	}
+	switch t := t.(type) {
+	case *List:
+		return ref.list != nil && compatible(ref.list, false, t.Of)
+	case *Scalar:
+		return ref.list == nil && ref.name == t.Name
+	}
+	return false
+}
+
+// conflicts refuses selections that give one response key to different
+// fields or to one field with different arguments.
+func (v *validator) conflicts(parent *Object, sels []selection) {
+	groups := collectFields(v.doc, sels, nil)
+	for _, group := range groups.order {
+		fields := groups.fields[group]
+		first := fields[0]
+		for _, f := range fields[1:] {
+			if f.name != first.name || !sameArguments(f.args, first.args) {
+				v.fail(f.loc, "Fields %q conflict: use different aliases", group)
+				break
+			}
+		}
+		def, ok := parent.Fields[first.name]
+		if !ok {
+			continue
+		}
+		if obj, ok := namedType(def.Type).(*Object); ok {
+			v.conflicts(obj, mergeSelections(fields))
+		}
+	}
+}
+
+func sameArguments(a, b []*argument) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	values := make(map[string]value, len(a))
+	for _, arg := range a {
+		values[arg.name] = arg.value
+	}
+	for _, arg := range b {
+		other, ok := values[arg.name]
+		if !ok || !reflect.DeepEqual(other, arg.value) {
+			return false
+		}
+	}
+	return true
+}
+
+// inputType resolves a variable's type against the schema's scalars.
+func (s *Schema) inputType(ref *typeRef) (Type, bool) {
+	var t Type
+	if ref.list != nil {
+		of, ok := s.inputType(ref.list)
+		if !ok {
+			return nil, false
+		}
+		t = ListOf(of)
+	} else {
+		sc, ok := s.scalars[ref.name]
+		if !ok {
+			return nil, false
+		}
+		t = sc
+	}
+	if ref.nonNull {
+		t = NonNullOf(t)
+	}
+	return t, true
+}
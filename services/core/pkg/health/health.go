@@ -0,0 +1,209 @@
+// Package health tracks the background workers of a process and reports them
+// on a readiness endpoint.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Path is where the readiness handler is conventionally mounted.
+const Path = "/readyz"
+
+// WorkerOptions describe how a worker is judged.
+type WorkerOptions struct {
+	// Critical workers make the process not ready while unhealthy, and their
+	// death is announced on Registry.Dead so the process can exit.
+	Critical bool
+	// StaleAfter marks a running worker unhealthy when it has not reported
+	// for this long. Zero disables the check, for workers that may be idle.
+	StaleAfter time.Duration
+	// QueueDepth, when set, reports how much work is waiting.
+	QueueDepth func() int
+}
+
+// WorkerStatus is a point-in-time view of one worker.
+type WorkerStatus struct {
+	Name        string     `json:"name"`
+	Critical    bool       `json:"critical"`
+	Alive       bool       `json:"alive"`
+	Healthy     bool       `json:"healthy"`
+	QueueDepth  *int       `json:"queue_depth,omitempty"`
+	StartedAt   time.Time  `json:"started_at"`
+	LastReport  *time.Time `json:"last_report,omitempty"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+}
+
+type worker struct {
+	name string
+	opts WorkerOptions
+
+	mu          sync.Mutex
+	alive       bool
+	startedAt   time.Time
+	lastReport  time.Time
+	lastSuccess time.Time
+	lastError   string
+}
+
+// Registry tracks the workers started through it.
+type Registry struct {
+	mu      sync.Mutex
+	workers []*worker
+	dead    chan string
+	now     func() time.Time
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{dead: make(chan string, 16), now: time.Now}
+}
+
+type workerKey struct{}
+
+// Go runs fn in a new goroutine as the named worker. fn should return only
+// once ctx is cancelled; returning or panicking earlier counts as the worker
+// dying.
+func (r *Registry) Go(ctx context.Context, name string, opts WorkerOptions, fn func(ctx context.Context)) {
+	w := &worker{name: name, opts: opts, alive: true, startedAt: r.now().UTC()}
+	r.mu.Lock()
+	r.workers = append(r.workers, w)
+	r.mu.Unlock()
+
+	go func() {
+		defer func() {
+			recovered := recover()
+			if ctx.Err() != nil && recovered == nil {
+				w.stop("")
+				return
+			}
+			reason := "worker returned"
+			if recovered != nil {
+				reason = fmt.Sprintf("worker panicked: %v", recovered)
+			}
+			w.stop(reason)
+			log.Printf("background worker %s died: %s", name, reason)
+			if opts.Critical {
+				select {
+				case r.dead <- name:
+				default:
+				}
+			}
+		}()
+		fn(context.WithValue(ctx, workerKey{}, w))
+	}()
+}
+
+// Dead delivers the names of critical workers that died while the process
+// was still running.
+func (r *Registry) Dead() <-chan string {
+	return r.dead
+}
+
+// Report records the outcome of one unit of work by the worker running ctx.
+// It does nothing outside a worker started by Registry.Go.
+func Report(ctx context.Context, err error) {
+	w, ok := ctx.Value(workerKey{}).(*worker)
+	if !ok {
+		return
+	}
+	now := time.Now().UTC()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lastReport = now
+	if err != nil {
+		w.lastError = err.Error()
+		return
+	}
+	w.lastSuccess = now
+	w.lastError = ""
+}
+
+func (w *worker) stop(reason string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.alive = false
+	if reason != "" {
+		w.lastError = reason
+	}
+}
+
+func (w *worker) status(now time.Time) WorkerStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	st := WorkerStatus{
+		Name:      w.name,
+		Critical:  w.opts.Critical,
+		Alive:     w.alive,
+		StartedAt: w.startedAt,
+		LastError: w.lastError,
+	}
+	if !w.lastReport.IsZero() {
+		t := w.lastReport
+		st.LastReport = &t
+	}
+	if !w.lastSuccess.IsZero() {
+		t := w.lastSuccess
+		st.LastSuccess = &t
+	}
+	if w.opts.QueueDepth != nil {
+		depth := w.opts.QueueDepth()
+		st.QueueDepth = &depth
+	}
+
+	st.Healthy = w.alive
+	if st.Healthy && w.opts.StaleAfter > 0 {
+		last := w.startedAt
+		if !w.lastReport.IsZero() {
+			last = w.lastReport
+		}
+		st.Healthy = now.Sub(last) <= w.opts.StaleAfter
+	}
+	return st
+}
+
+// Status reports every worker and whether the process is ready, which it is
+// while all critical workers are healthy.
+func (r *Registry) Status() (bool, []WorkerStatus) {
+	r.mu.Lock()
+	workers := append([]*worker(nil), r.workers...)
+	r.mu.Unlock()
+
+	now := r.now().UTC()
+	ready := true
+	statuses := make([]WorkerStatus, len(workers))
+	for i, w := range workers {
+		statuses[i] = w.status(now)
+		if statuses[i].Critical && !statuses[i].Healthy {
+			ready = false
+		}
+	}
+	return ready, statuses
+}
+
+// ServeHTTP answers readiness probes with 200 when ready and 503 otherwise,
+// listing every worker in the body.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	ready, workers := r.Status()
+	status, code := "ready", http.StatusOK
+	if !ready {
+		status, code = "not_ready", http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"status":  status,
+		"workers": workers,
+	})
+}
@@ -0,0 +1,113 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/health"
+)
+
+func TestCriticalWorkerDeathIsAnnounced(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	workers := health.NewRegistry()
+	workers.Go(ctx, "optional", health.WorkerOptions{}, func(ctx context.Context) {})
+	workers.Go(ctx, "dispatcher", health.WorkerOptions{Critical: true}, func(ctx context.Context) {
+		panic("boom")
+	})
+
+	select {
+	case name := <-workers.Dead():
+		if name != "dispatcher" {
+			t.Fatalf("expected dispatcher to die, got %s", name)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("critical worker death was not announced")
+	}
+
+	ready, statuses := workers.Status()
+	if ready {
+		t.Fatalf("expected not ready after critical worker died")
+	}
+	if dispatcher := statuses[1]; dispatcher.Alive || dispatcher.LastError == "" {
+		t.Fatalf("expected dispatcher to be reported dead with a reason, got %+v", dispatcher)
+	}
+
+	rec := httptest.NewRecorder()
+	workers.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, health.Path, nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+}
+
+func TestReportRecordsLastSuccess(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	workers := health.NewRegistry()
+	reported := make(chan struct{})
+	stopped := make(chan struct{})
+	workers.Go(ctx, "refresher", health.WorkerOptions{
+		Critical:   true,
+		QueueDepth: func() int { return 3 },
+	}, func(ctx context.Context) {
+		defer close(stopped)
+		health.Report(ctx, nil)
+		health.Report(ctx, errors.New("transient"))
+		close(reported)
+		<-ctx.Done()
+	})
+	<-reported
+
+	rec := httptest.NewRecorder()
+	workers.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, health.Path, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var body struct {
+		Status  string                `json:"status"`
+		Workers []health.WorkerStatus `json:"workers"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Status != "ready" || len(body.Workers) != 1 {
+		t.Fatalf("unexpected body: %+v", body)
+	}
+	st := body.Workers[0]
+	if st.LastSuccess == nil || st.LastError != "transient" {
+		t.Fatalf("expected last success and last error to be recorded, got %+v", st)
+	}
+	if st.QueueDepth == nil || *st.QueueDepth != 3 {
+		t.Fatalf("expected queue depth 3, got %v", st.QueueDepth)
+	}
+
+	cancel()
+	<-stopped
+	select {
+	case name := <-workers.Dead():
+		t.Fatalf("worker %s stopped on shutdown but was reported dead", name)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestStaleWorkerIsUnhealthy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	workers := health.NewRegistry()
+	workers.Go(ctx, "reminders", health.WorkerOptions{Critical: true, StaleAfter: 10 * time.Millisecond}, func(ctx context.Context) {
+		<-ctx.Done()
+	})
+
+	time.Sleep(30 * time.Millisecond)
+	ready, statuses := workers.Status()
+	if ready || statuses[0].Healthy || !statuses[0].Alive {
+		t.Fatalf("expected a live but stale worker to make the process not ready, got %+v", statuses[0])
+	}
+}
@@ -0,0 +1,66 @@
+package hmactoken_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/hmactoken"
+	"github.com/sky0621/go_work_sample/core/pkg/kiosk"
+	"github.com/sky0621/go_work_sample/core/pkg/magiclink"
+)
+
+type claims struct {
+	Subject string `json:"sub"`
+}
+
+func TestSignerRoundTrip(t *testing.T) {
+	signer := hmactoken.NewSigner("secret", hmactoken.TypeKiosk)
+	token, err := signer.Sign(claims{Subject: "student-001"})
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	var got claims
+	if err := signer.Verify(token, &got); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if got.Subject != "student-001" {
+		t.Fatalf("unexpected claims: %+v", got)
+	}
+}
+
+func TestSignerRejectsTokensOfAnotherType(t *testing.T) {
+	token, err := hmactoken.NewSigner("secret", hmactoken.TypeMagicLink).Sign(claims{Subject: "student-001"})
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	for _, typ := range []string{hmactoken.TypeAccess, hmactoken.TypeKiosk} {
+		if err := hmactoken.NewSigner("secret", typ).Verify(token, &claims{}); !errors.Is(err, hmactoken.ErrInvalidToken) {
+			t.Fatalf("expected a %s signer to refuse a sign-in link token, got %v", typ, err)
+		}
+	}
+	if err := hmactoken.NewSigner("other", hmactoken.TypeMagicLink).Verify(token, &claims{}); !errors.Is(err, hmactoken.ErrInvalidToken) {
+		t.Fatalf("expected a signer with another secret to refuse the token, got %v", err)
+	}
+}
+
+func TestKioskAndSignInLinkTokensAreNotInterchangeable(t *testing.T) {
+	kiosks := kiosk.NewSigner("shared")
+	links := magiclink.NewSigner("shared")
+
+	kioskToken, _, err := kiosks.Issue("student-001", "test-001", time.Hour)
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+	if _, err := links.Verify(kioskToken); !errors.Is(err, magiclink.ErrInvalidToken) {
+		t.Fatalf("expected a kiosk token to be refused as a sign-in link, got %v", err)
+	}
+	linkToken, _, err := links.Issue(domain.RoleStudent, "student-001", time.Hour)
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+	if _, err := kiosks.Verify(linkToken); !errors.Is(err, kiosk.ErrInvalidToken) {
+		t.Fatalf("expected a sign-in link token to be refused as a kiosk token, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package httpmw
+
+import (
+	"net/http"
+	"slices"
+)
+
+// CORSConfig defines options for cross-origin request middleware.
+type CORSConfig struct {
+	// AllowedOrigins returns the origins admitted when a request arrives,
+	// so the list can change while the service runs. "*" admits every
+	// origin.
+	AllowedOrigins func() []string
+}
+
+// CORS lets browsers on admitted origins call the API. Preflight requests
+// are answered here; other requests pass through with the CORS headers
+// added, or without them for origins that are not admitted, which the
+// browser then blocks.
+func CORS(cfg CORSConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Add("Vary", "Origin")
+			allowed := cfg.AllowedOrigins()
+			admitted := slices.Contains(allowed, "*") || slices.Contains(allowed, origin)
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+			if !admitted {
+				if preflight {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			if !preflight {
+				w.Header().Set("Access-Control-Expose-Headers", RequestIDHeader+", "+SandboxHeader+", "+TimezoneHeader+", Deprecation, Sunset, Link")
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE")
+			if headers := r.Header.Get("Access-Control-Request-Headers"); headers != "" {
+				w.Header().Set("Access-Control-Allow-Headers", headers)
+			}
+			w.Header().Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package httpmw_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/httpmw"
+)
+
+func TestCORSFollowsTheCurrentOrigins(t *testing.T) {
+	origins := []string{"https://app.example.com"}
+	handler := httpmw.CORS(httpmw.CORSConfig{AllowedOrigins: func() []string { return origins }})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	cases := []struct {
+		method    string
+		origin    string
+		preflight bool
+		status    int
+		allow     string
+	}{
+		{http.MethodGet, "", false, http.StatusOK, ""},
+		{http.MethodGet, "https://app.example.com", false, http.StatusOK, "https://app.example.com"},
+		{http.MethodGet, "https://evil.example.com", false, http.StatusOK, ""},
+		{http.MethodOptions, "https://app.example.com", true, http.StatusNoContent, "https://app.example.com"},
+		{http.MethodOptions, "https://evil.example.com", true, http.StatusForbidden, ""},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, "/api/tests", nil)
+		if c.origin != "" {
+			req.Header.Set("Origin", c.origin)
+		}
+		if c.preflight {
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			req.Header.Set("Access-Control-Request-Headers", "Authorization")
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != c.status || rr.Header().Get("Access-Control-Allow-Origin") != c.allow {
+			t.Fatalf("%s from %q: got %d allowing %q, want %d allowing %q", c.method, c.origin, rr.Code, rr.Header().Get("Access-Control-Allow-Origin"), c.status, c.allow)
+		}
+	}
+
+	origins = []string{"*"}
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Header().Get("Access-Control-Allow-Origin") != "https://evil.example.com" {
+		t.Fatalf("expected a changed origin list to apply to the next request")
+	}
+}
@@ -0,0 +1,188 @@
+package httpmw
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DeprecationsPath is where admins read how much deprecated routes are
+// still used.
+const DeprecationsPath = "/admin/deprecations"
+
+// maxTrackedCallers bounds the callers remembered for each deprecated
+// route; requests from callers beyond it are still counted.
+const maxTrackedCallers = 1000
+
+// DeprecatedRoute announces that a route is going away. Method and Path
+// match requests as in RateRule.
+type DeprecatedRoute struct {
+	Method string `json:"method,omitempty"`
+	Path   string `json:"path"`
+	// Since is when the route was deprecated, sent in the Deprecation
+	// header (RFC 9745).
+	Since time.Time `json:"since"`
+	// Sunset is when the route is expected to stop answering, sent in the
+	// Sunset header (RFC 8594). Zero when no date is set yet.
+	Sunset time.Time `json:"sunset"`
+	// Successor is the route to move to and Docs explains the change; each
+	// is linked from the response when set.
+	Successor string `json:"successor,omitempty"`
+	Docs      string `json:"docs,omitempty"`
+}
+
+// Matches reports whether the request calls the deprecated route.
+func (route DeprecatedRoute) Matches(r *http.Request) bool {
+	return routeMatches(route.Method, route.Path, r)
+}
+
+// DeprecationConfig defines options for deprecation middleware.
+type DeprecationConfig struct {
+	// Routes returns the deprecated routes when a request arrives, so they
+	// can change while the service runs. The first matching route applies.
+	Routes func() []DeprecatedRoute
+	// Usage counts the requests to deprecated routes; nil counts nothing.
+	Usage *DeprecationUsage
+	// Caller identifies who made a request. It defaults to CallerKey.
+	Caller func(r *http.Request) string
+}
+
+// Deprecation adds the Deprecation, Sunset and Link headers to responses
+// from deprecated routes and counts who still calls them, so a route can
+// be removed once nobody does. The request is served as usual. It must run
+// after authentication for the signed-in principal to be the caller.
+func Deprecation(cfg DeprecationConfig) func(http.Handler) http.Handler {
+	caller := cfg.Caller
+	if caller == nil {
+		caller = CallerKey
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, route := range cfg.Routes() {
+				if !route.Matches(r) {
+					continue
+				}
+				h := w.Header()
+				h.Set("Deprecation", "@"+strconv.FormatInt(route.Since.Unix(), 10))
+				if !route.Sunset.IsZero() {
+					h.Set("Sunset", route.Sunset.UTC().Format(http.TimeFormat))
+				}
+				if route.Successor != "" {
+					h.Add("Link", "<"+route.Successor+`>; rel="successor-version"`)
+				}
+				if route.Docs != "" {
+					h.Add("Link", "<"+route.Docs+`>; rel="deprecation"; type="text/html"`)
+				}
+				if cfg.Usage != nil {
+					cfg.Usage.record(route, caller(r))
+				}
+				break
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// DeprecationUsage counts the requests to each deprecated route since the
+// service started, in total and per caller.
+type DeprecationUsage struct {
+	mu     sync.Mutex
+	routes map[string]*routeUsage
+	now    func() time.Time
+}
+
+type routeUsage struct {
+	route    DeprecatedRoute
+	requests int64
+	lastSeen time.Time
+	callers  map[string]int64
+}
+
+// NewDeprecationUsage creates an empty usage count.
+func NewDeprecationUsage() *DeprecationUsage {
+	return &DeprecationUsage{routes: make(map[string]*routeUsage), now: time.Now}
+}
+
+func (u *DeprecationUsage) record(route DeprecatedRoute, caller string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	key := route.Method + " " + route.Path
+	usage, ok := u.routes[key]
+	if !ok {
+		usage = &routeUsage{callers: make(map[string]int64)}
+		u.routes[key] = usage
+	}
+	usage.route = route
+	usage.requests++
+	usage.lastSeen = u.now().UTC()
+	if _, seen := usage.callers[caller]; seen || len(usage.callers) < maxTrackedCallers {
+		usage.callers[caller]++
+	}
+}
+
+// DeprecatedRouteUsage reports the use of one deprecated route. Callers
+// lists who called it, busiest first.
+type DeprecatedRouteUsage struct {
+	Route    DeprecatedRoute `json:"route"`
+	Requests int64           `json:"requests"`
+	LastSeen time.Time       `json:"last_seen"`
+	Callers  []CallerUsage   `json:"callers"`
+}
+
+// CallerUsage counts one caller's requests to a deprecated route.
+type CallerUsage struct {
+	Caller   string `json:"caller"`
+	Requests int64  `json:"requests"`
+}
+
+// Snapshot returns the usage of every deprecated route called so far,
+// most requested first.
+func (u *DeprecationUsage) Snapshot() []DeprecatedRouteUsage {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	out := make([]DeprecatedRouteUsage, 0, len(u.routes))
+	for _, usage := range u.routes {
+		callers := make([]CallerUsage, 0, len(usage.callers))
+		for caller, n := range usage.callers {
+			callers = append(callers, CallerUsage{Caller: caller, Requests: n})
+		}
+		sort.Slice(callers, func(i, j int) bool {
+			if callers[i].Requests != callers[j].Requests {
+				return callers[i].Requests > callers[j].Requests
+			}
+			return callers[i].Caller < callers[j].Caller
+		})
+		out = append(out, DeprecatedRouteUsage{
+			Route:    usage.route,
+			Requests: usage.requests,
+			LastSeen: usage.lastSeen,
+			Callers:  callers,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Requests != out[j].Requests {
+			return out[i].Requests > out[j].Requests
+		}
+		return out[i].Route.Path < out[j].Route.Path
+	})
+	return out
+}
+
+// ServeHTTP reports the usage on GET. Mount it at DeprecationsPath behind
+// admin authentication.
+func (u *DeprecationUsage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, _ = w.Write([]byte(`{"error":"method not allowed"}`))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]any{"routes": u.Snapshot()})
+}
@@ -0,0 +1,77 @@
+package httpmw_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/httpmw"
+)
+
+func TestDeprecationAnnouncesAndCountsLegacyRoutes(t *testing.T) {
+	since := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	routes := []httpmw.DeprecatedRoute{{
+		Method:    http.MethodGet,
+		Path:      "/api/teachers/*/tests",
+		Since:     since,
+		Sunset:    since.AddDate(0, 6, 0),
+		Successor: "/v1/teachers/{id}/tests",
+		Docs:      "https://docs.example.com/migrate",
+	}}
+	usage := httpmw.NewDeprecationUsage()
+	handler := httpmw.Deprecation(httpmw.DeprecationConfig{Routes: func() []httpmw.DeprecatedRoute { return routes }, Usage: usage})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	send := func(method, path, teacherID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req = req.WithContext(auth.WithPrincipal(req.Context(), auth.Principal{Role: domain.RoleTeacher, ID: teacherID}))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := send(http.MethodGet, "/api/teachers/t-1/tests", "t-1")
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("expected the deprecated route to be served, got %d", rr.Code)
+	}
+	if got := rr.Header().Get("Deprecation"); got != "@1780272000" {
+		t.Fatalf("unexpected Deprecation header %q", got)
+	}
+	if got := rr.Header().Get("Sunset"); got != "Tue, 01 Dec 2026 00:00:00 GMT" {
+		t.Fatalf("unexpected Sunset header %q", got)
+	}
+	if links := rr.Header().Values("Link"); len(links) != 2 {
+		t.Fatalf("expected successor and docs links, got %v", links)
+	}
+	send(http.MethodGet, "/api/teachers/t-1/tests", "t-1")
+	send(http.MethodGet, "/api/teachers/t-2/tests", "t-2")
+	if rr := send(http.MethodPost, "/api/teachers/t-1/tests", "t-1"); rr.Header().Get("Deprecation") != "" {
+		t.Fatalf("expected other methods not to be deprecated, got %v", rr.Header())
+	}
+
+	snapshot := usage.Snapshot()
+	if len(snapshot) != 1 || snapshot[0].Requests != 3 || len(snapshot[0].Callers) != 2 {
+		t.Fatalf("expected 3 requests from 2 callers, got %+v", snapshot)
+	}
+	if top := snapshot[0].Callers[0]; top.Caller != "principal:teacher:t-1" || top.Requests != 2 {
+		t.Fatalf("expected the busiest caller first, got %+v", snapshot[0].Callers)
+	}
+
+	rr = httptest.NewRecorder()
+	usage.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, httpmw.DeprecationsPath, nil))
+	var body struct {
+		Routes []httpmw.DeprecatedRouteUsage `json:"routes"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil || len(body.Routes) != 1 || body.Routes[0].Route.Path != "/api/teachers/*/tests" {
+		t.Fatalf("unexpected usage report %d %s", rr.Code, rr.Body.String())
+	}
+
+	routes = nil
+	if rr := send(http.MethodGet, "/api/teachers/t-1/tests", "t-1"); rr.Header().Get("Deprecation") != "" {
+		t.Fatalf("expected removed deprecations to stop the headers, got %v", rr.Header())
+	}
+}
@@ -0,0 +1,89 @@
+package httpmw
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+// JWTConfig defines options for access token authentication middleware.
+type JWTConfig struct {
+	Header string
+	Prefix string
+	Signer *auth.Signer
+	// Roles lists the roles admitted; empty admits every role.
+	Roles []domain.Role
+	// Revoked, when set, reports whether a token was signed out before it
+	// expired. Revoked tokens are rejected like invalid ones.
+	Revoked func(ctx context.Context, tokenID string) (bool, error)
+}
+
+// JWT requires a valid access token and stores its principal in the request
+// context, where handlers read it through the auth package.
+func JWT(cfg JWTConfig) func(http.Handler) http.Handler {
+	header := cfg.Header
+	if header == "" {
+		header = "Authorization"
+	}
+	prefix := cfg.Prefix
+	if prefix == "" {
+		prefix = "Bearer "
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			value := strings.TrimSpace(r.Header.Get(header))
+			if !strings.HasPrefix(strings.ToLower(value), strings.ToLower(prefix)) {
+				unauthorized(w)
+				return
+			}
+
+			claims, err := cfg.Signer.Verify(strings.TrimSpace(value[len(prefix):]))
+			if err != nil {
+				unauthorized(w)
+				return
+			}
+			if cfg.Revoked != nil {
+				revoked, err := cfg.Revoked(r.Context(), claims.TokenID)
+				if err != nil {
+					log.Printf("token revocation check failed: %v", err)
+					unavailable(w)
+					return
+				}
+				if revoked {
+					unauthorized(w)
+					return
+				}
+			}
+			if !roleAdmitted(cfg.Roles, claims.Role) {
+				forbidden(w)
+				return
+			}
+
+			ctx := auth.WithTokenID(auth.WithPrincipal(r.Context(), claims.Principal()), claims.TokenID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func roleAdmitted(roles []domain.Role, role domain.Role) bool {
+	if len(roles) == 0 {
+		return true
+	}
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func unavailable(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	_, _ = w.Write([]byte(`{"error":"service unavailable"}`))
+}
@@ -0,0 +1,90 @@
+package httpmw_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/httpmw"
+)
+
+func TestJWTMiddleware(t *testing.T) {
+	signer := auth.NewSigner("secret")
+	handler := httpmw.JWT(httpmw.JWTConfig{
+		Signer: signer,
+		Roles:  []domain.Role{domain.RoleTeacher},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !auth.IsTeacher(r.Context(), "teacher-001") {
+			w.WriteHeader(http.StatusTeapot)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	teacher, _, err := signer.Issue(auth.Principal{Role: domain.RoleTeacher, ID: "teacher-001"}, time.Hour)
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+	student, _, err := signer.Issue(auth.Principal{Role: domain.RoleStudent, ID: "student-001"}, time.Hour)
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+
+	cases := []struct {
+		name string
+		auth string
+		want int
+	}{
+		{"teacher", "Bearer " + teacher, http.StatusOK},
+		{"wrong role", "Bearer " + student, http.StatusForbidden},
+		{"tampered", "Bearer " + teacher + "x", http.StatusUnauthorized},
+		{"static key", "Bearer secret", http.StatusUnauthorized},
+		{"missing", "", http.StatusUnauthorized},
+	}
+
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/api/teachers/teacher-001/tests", nil)
+		if tc.auth != "" {
+			req.Header.Set("Authorization", tc.auth)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Result().StatusCode != tc.want {
+			t.Fatalf("%s: expected %d, got %d", tc.name, tc.want, rr.Result().StatusCode)
+		}
+	}
+}
+
+func TestJWTMiddlewareRejectsRevokedTokens(t *testing.T) {
+	signer := auth.NewSigner("secret")
+	kept, keptClaims, _ := signer.Issue(auth.Principal{Role: domain.RoleStudent, ID: "student-001"}, time.Hour)
+	revoked, revokedClaims, _ := signer.Issue(auth.Principal{Role: domain.RoleStudent, ID: "student-001"}, time.Hour)
+
+	var seen string
+	handler := httpmw.JWT(httpmw.JWTConfig{
+		Signer: signer,
+		Revoked: func(_ context.Context, tokenID string) (bool, error) {
+			return tokenID == revokedClaims.TokenID, nil
+		},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = auth.TokenIDFrom(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for token, want := range map[string]int{kept: http.StatusOK, revoked: http.StatusUnauthorized} {
+		req := httptest.NewRequest(http.MethodGet, "/api/students/student-001/tests", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Result().StatusCode != want {
+			t.Fatalf("expected %d, got %d", want, rr.Result().StatusCode)
+		}
+	}
+	if seen != keptClaims.TokenID {
+		t.Fatalf("expected the token ID %q in the context, got %q", keptClaims.TokenID, seen)
+	}
+}
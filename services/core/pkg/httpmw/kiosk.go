@@ -0,0 +1,92 @@
+package httpmw
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/id"
+	"github.com/sky0621/go_work_sample/core/pkg/kiosk"
+)
+
+// kioskSessionCookie names the cookie a kiosk keeps the session its token
+// was redeemed by in.
+const kioskSessionCookie = "kiosk_session"
+
+// KioskConfig defines options for kiosk token authentication middleware.
+type KioskConfig struct {
+	Header   string
+	Scheme   string
+	Signer   *kiosk.Signer
+	Fallback func(http.Handler) http.Handler
+}
+
+// Kiosk admits requests carrying a kiosk token for the routes the token is
+// scoped to, as the token's student, and hands every other request to the
+// fallback authentication. The first request redeeming a token starts a
+// session kept in a cookie; the token is refused outside that session.
+func Kiosk(cfg KioskConfig) func(http.Handler) http.Handler {
+	header := cfg.Header
+	if header == "" {
+		header = "Authorization"
+	}
+	scheme := cfg.Scheme
+	if scheme == "" {
+		scheme = "Kiosk "
+	}
+
+	return func(next http.Handler) http.Handler {
+		fallback := next
+		if cfg.Fallback != nil {
+			fallback = cfg.Fallback(next)
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			value := strings.TrimSpace(r.Header.Get(header))
+			if !strings.HasPrefix(strings.ToLower(value), strings.ToLower(scheme)) {
+				fallback.ServeHTTP(w, r)
+				return
+			}
+
+			claims, err := cfg.Signer.Verify(strings.TrimSpace(value[len(scheme):]))
+			if err != nil {
+				unauthorized(w)
+				return
+			}
+			if !claims.Permits(r.Method, r.URL.Path) {
+				forbidden(w)
+				return
+			}
+			cookie, err := r.Cookie(kioskSessionCookie)
+			fresh := err != nil
+			if fresh {
+				cookie = &http.Cookie{
+					Name:     kioskSessionCookie,
+					Value:    id.New(),
+					Path:     "/",
+					Expires:  claims.ExpiresAt,
+					HttpOnly: true,
+					Secure:   r.TLS != nil,
+					SameSite: http.SameSiteStrictMode,
+				}
+			}
+			if err := cfg.Signer.Redeem(claims, cookie.Value); err != nil {
+				unauthorized(w)
+				return
+			}
+			if fresh {
+				http.SetCookie(w, cookie)
+			}
+
+			principal := auth.Principal{Role: domain.RoleStudent, ID: string(claims.StudentID)}
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+func forbidden(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	_, _ = w.Write([]byte(`{"error":"forbidden"}`))
+}
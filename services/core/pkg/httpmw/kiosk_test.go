@@ -0,0 +1,80 @@
+package httpmw_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/httpmw"
+	"github.com/sky0621/go_work_sample/core/pkg/kiosk"
+)
+
+func TestKioskMiddleware(t *testing.T) {
+	signer := kiosk.NewSigner("kiosk")
+	handler := httpmw.Kiosk(httpmw.KioskConfig{
+		Signer:   signer,
+		Fallback: httpmw.APIKey(httpmw.APIKeyConfig{Key: "secret", Prefix: "Bearer "}),
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	token, _, err := signer.Issue("student-001", "test-001", time.Hour)
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+	expired, _, err := signer.Issue("student-001", "test-001", -time.Minute)
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		auth   string
+		want   int
+	}{
+		{"questions", http.MethodGet, "/api/students/student-001/tests/test-001/questions", "Kiosk " + token, http.StatusOK},
+		{"answers", http.MethodPost, "/api/students/student-001/tests/test-001/answers", "Kiosk " + token, http.StatusOK},
+		{"results", http.MethodGet, "/api/students/student-001/tests/test-001/results", "Kiosk " + token, http.StatusForbidden},
+		{"other test", http.MethodGet, "/api/students/student-001/tests/test-002/questions", "Kiosk " + token, http.StatusForbidden},
+		{"other student", http.MethodGet, "/api/students/student-002/tests/test-001/questions", "Kiosk " + token, http.StatusForbidden},
+		{"expired", http.MethodGet, "/api/students/student-001/tests/test-001/questions", "Kiosk " + expired, http.StatusUnauthorized},
+		{"tampered", http.MethodGet, "/api/students/student-001/tests/test-001/questions", "Kiosk " + token + "x", http.StatusUnauthorized},
+		{"api key", http.MethodGet, "/api/students/student-001/tests", "Bearer secret", http.StatusOK},
+		{"missing", http.MethodGet, "/api/students/student-001/tests", "", http.StatusUnauthorized},
+	}
+
+	// The kiosk keeps the session cookie handed out when it redeems the token.
+	var session []*http.Cookie
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		if tc.auth != "" {
+			req.Header.Set("Authorization", tc.auth)
+		}
+		for _, cookie := range session {
+			req.AddCookie(cookie)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Result().StatusCode != tc.want {
+			t.Fatalf("%s: expected %d, got %d", tc.name, tc.want, rr.Result().StatusCode)
+		}
+		if cookies := rr.Result().Cookies(); len(cookies) > 0 {
+			session = cookies
+		}
+	}
+	if len(session) == 0 {
+		t.Fatal("expected redeeming the token to start a session")
+	}
+
+	// Another machine holding a copy of the redeemed token is refused.
+	req := httptest.NewRequest(http.MethodGet, "/api/students/student-001/tests/test-001/questions", nil)
+	req.Header.Set("Authorization", "Kiosk "+token)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Result().StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected a second use of the token to be refused, got %d", rr.Result().StatusCode)
+	}
+}
@@ -0,0 +1,47 @@
+package httpmw_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/httpmw"
+)
+
+func TestLoggingTagsRequestsWithAnID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	var seen string
+	handler := httpmw.Logging(httpmw.LoggingConfig{Logger: logger})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = httpmw.RequestIDFrom(r.Context())
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = w.Write([]byte("short and stout"))
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/tests", nil))
+	id := rr.Header().Get(httpmw.RequestIDHeader)
+	if len(id) != 32 || seen != id {
+		t.Fatalf("expected a generated ID in the context and response, got %q and %q", seen, id)
+	}
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("expected one JSON record, got %q: %v", buf.String(), err)
+	}
+	if record["request_id"] != id || record["method"] != "GET" || record["path"] != "/api/tests" || record["status"] != float64(http.StatusTeapot) || record["bytes"] != float64(15) {
+		t.Fatalf("unexpected record %v", record)
+	}
+
+	for header, reused := range map[string]bool{"upstream-42": true, "has space": false} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(httpmw.RequestIDHeader, header)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if got := rr.Header().Get(httpmw.RequestIDHeader); (got == header) != reused || seen != got {
+			t.Fatalf("header %q: got ID %q, reused=%v", header, got, reused)
+		}
+	}
+}
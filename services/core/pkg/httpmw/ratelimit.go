@@ -0,0 +1,115 @@
+package httpmw
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
+	"github.com/sky0621/go_work_sample/core/pkg/ratelimit"
+)
+
+// RateRule caps the requests to a route for each caller.
+type RateRule struct {
+	// Method restricts the rule to one HTTP method; empty matches any.
+	Method string `json:"method,omitempty"`
+	// Path is matched segment by segment, where "*" matches any one
+	// segment, as in /api/students/*/tests/*/answers. A path ending in "/"
+	// matches everything below it.
+	Path string `json:"path"`
+	// PerSecond is the sustained rate and Burst the number of requests
+	// admitted at once before it applies.
+	PerSecond float64 `json:"per_second"`
+	Burst     int     `json:"burst,omitempty"`
+}
+
+// Matches reports whether the rule applies to the request.
+func (rule RateRule) Matches(r *http.Request) bool {
+	return routeMatches(rule.Method, rule.Path, r)
+}
+
+// routeMatches reports whether the request has the method, unless it is
+// empty, and a path matching the pattern: "*" matches any one segment and
+// a pattern ending in "/" matches everything below it.
+func routeMatches(method, pattern string, r *http.Request) bool {
+	if method != "" && !strings.EqualFold(method, r.Method) {
+		return false
+	}
+	if pattern == "/" {
+		return true
+	}
+	segments := strings.Split(strings.Trim(pattern, "/"), "/")
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	prefix := strings.HasSuffix(pattern, "/")
+	if len(path) < len(segments) || (!prefix && len(path) != len(segments)) {
+		return false
+	}
+	for i, segment := range segments {
+		if segment != "*" && segment != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// RateLimitConfig defines options for rate limiting middleware.
+type RateLimitConfig struct {
+	// Rules returns the rules when a request arrives, so they can change
+	// while the service runs. The first matching rule applies; requests
+	// matching none pass unmetered.
+	Rules func() []RateRule
+	// Caller identifies whose budget a request spends. It defaults to
+	// CallerKey.
+	Caller func(r *http.Request) string
+}
+
+// RateLimit meters requests per caller with token buckets, one per rule and
+// caller, and answers 429 with Retry-After once a caller runs out. It must
+// run after authentication for the signed-in principal to be the caller.
+func RateLimit(cfg RateLimitConfig) func(http.Handler) http.Handler {
+	caller := cfg.Caller
+	if caller == nil {
+		caller = CallerKey
+	}
+	buckets := ratelimit.NewBuckets()
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for i, rule := range cfg.Rules() {
+				if !rule.Matches(r) {
+					continue
+				}
+				key := strconv.Itoa(i) + " " + rule.Method + " " + rule.Path + " " + caller(r)
+				if ok, wait := buckets.Take(key, rule.PerSecond, rule.Burst); !ok {
+					w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusTooManyRequests)
+					_, _ = w.Write([]byte(`{"error":"too many requests, try again later"}`))
+					return
+				}
+				break
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// CallerKey identifies the caller by the signed-in principal, else by a
+// digest of the API key in the Authorization header, else by IP address.
+func CallerKey(r *http.Request) string {
+	if p, ok := auth.PrincipalFrom(r.Context()); ok {
+		return "principal:" + string(p.Role) + ":" + p.ID
+	}
+	if key := r.Header.Get("Authorization"); key != "" {
+		sum := sha256.Sum256([]byte(key))
+		return "key:" + hex.EncodeToString(sum[:8])
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "client:" + host
+}
@@ -0,0 +1,61 @@
+package httpmw_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/httpmw"
+)
+
+func TestRateLimitMetersEachPrincipalPerRoute(t *testing.T) {
+	rules := []httpmw.RateRule{
+		{Method: http.MethodPost, Path: "/api/students/*/tests/*/answers", PerSecond: 0.001, Burst: 2},
+		{Path: "/api/", PerSecond: 0.001, Burst: 1},
+	}
+	handler := httpmw.RateLimit(httpmw.RateLimitConfig{Rules: func() []httpmw.RateRule { return rules }})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	send := func(method, path, studentID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if studentID != "" {
+			req = req.WithContext(auth.WithPrincipal(req.Context(), auth.Principal{Role: domain.RoleStudent, ID: studentID}))
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	answers := "/api/students/s-1/tests/t-1/answers"
+	for i := 0; i < 2; i++ {
+		if rr := send(http.MethodPost, answers, "s-1"); rr.Code != http.StatusNoContent {
+			t.Fatalf("expected submission %d within the burst to pass, got %d", i+1, rr.Code)
+		}
+	}
+	rr := send(http.MethodPost, answers, "s-1")
+	if rr.Code != http.StatusTooManyRequests || rr.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected 429 with Retry-After once the burst is spent, got %d %v", rr.Code, rr.Header())
+	}
+	if rr := send(http.MethodPost, "/api/students/s-2/tests/t-1/answers", "s-2"); rr.Code != http.StatusNoContent {
+		t.Fatalf("expected another student to have their own budget, got %d", rr.Code)
+	}
+
+	// Only the first matching rule applies, so the catch-all rule still has
+	// its budget for the first student.
+	if rr := send(http.MethodGet, "/api/students/s-1/tests", "s-1"); rr.Code != http.StatusNoContent {
+		t.Fatalf("expected the catch-all rule to pass the first request, got %d", rr.Code)
+	}
+	if rr := send(http.MethodGet, "/api/students/s-1/tests", "s-1"); rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected the catch-all rule to refuse the second request, got %d", rr.Code)
+	}
+	if rr := send(http.MethodGet, "/health", ""); rr.Code != http.StatusNoContent {
+		t.Fatalf("expected unmatched routes to pass, got %d", rr.Code)
+	}
+
+	rules = nil
+	if rr := send(http.MethodPost, answers, "s-1"); rr.Code != http.StatusNoContent {
+		t.Fatalf("expected removed rules to stop limiting, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,135 @@
+package httpmw
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
+)
+
+// SandboxHeader marks a request as targeting the sandbox dataset.
+const SandboxHeader = "X-Sandbox"
+
+// Sandbox routes requests carrying a truthy X-Sandbox header to sandbox
+// instead of the live handler, letting integrators exercise whole flows
+// without touching real data. Sandbox responses echo the header so clients
+// can confirm which dataset served them.
+func Sandbox(sandbox http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			value := strings.TrimSpace(r.Header.Get(SandboxHeader))
+			if value == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"invalid X-Sandbox header"}`))
+				return
+			}
+			if !enabled {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set(SandboxHeader, "true")
+			sandbox.ServeHTTP(w, r)
+		})
+	}
+}
+
+// DefaultSandboxIdle is how long a SandboxPool keeps a principal's sandbox
+// after their last sandbox request.
+const DefaultSandboxIdle = time.Hour
+
+// SandboxPool serves every authenticated principal from a sandbox of their
+// own, so nothing one principal tries is seen by another. A principal's
+// sandbox is built on their first sandbox request and dropped once it has
+// been idle for the pool's idle time; the next request builds a fresh one.
+type SandboxPool struct {
+	build func(auth.Principal) (http.Handler, error)
+	idle  time.Duration
+
+	mu        sync.Mutex
+	sandboxes map[auth.Principal]*pooledSandbox
+}
+
+type pooledSandbox struct {
+	handler http.Handler
+	usedAt  time.Time
+}
+
+// NewSandboxPool creates a pool building sandboxes with build. A zero idle
+// uses DefaultSandboxIdle.
+func NewSandboxPool(build func(auth.Principal) (http.Handler, error), idle time.Duration) *SandboxPool {
+	if idle <= 0 {
+		idle = DefaultSandboxIdle
+	}
+	return &SandboxPool{build: build, idle: idle, sandboxes: make(map[auth.Principal]*pooledSandbox)}
+}
+
+// ServeHTTP serves r from the sandbox of the request's principal. Requests
+// without one are refused, as there is no sandbox to serve them from.
+func (p *SandboxPool) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, ok := auth.PrincipalFrom(r.Context())
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"the sandbox needs an authenticated principal"}`))
+		return
+	}
+	handler, err := p.sandbox(principal, time.Now())
+	if err != nil {
+		log.Printf("sandbox for %s %s: %v", principal.Role, principal.ID, err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"error":"the sandbox could not be prepared"}`))
+		return
+	}
+	handler.ServeHTTP(w, r)
+}
+
+// Len returns the number of sandboxes the pool holds.
+func (p *SandboxPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.sandboxes)
+}
+
+// sandbox returns the principal's sandbox, building it when there is none,
+// and drops the sandboxes idle at now. Building happens outside the lock so
+// one principal's copy does not hold up the others' requests.
+func (p *SandboxPool) sandbox(principal auth.Principal, now time.Time) (http.Handler, error) {
+	p.mu.Lock()
+	for key, s := range p.sandboxes {
+		if now.Sub(s.usedAt) >= p.idle {
+			delete(p.sandboxes, key)
+		}
+	}
+	if s, ok := p.sandboxes[principal]; ok {
+		s.usedAt = now
+		p.mu.Unlock()
+		return s.handler, nil
+	}
+	p.mu.Unlock()
+
+	handler, err := p.build(principal)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if s, ok := p.sandboxes[principal]; ok {
+		// A concurrent request built it first; keep one copy.
+		s.usedAt = now
+		return s.handler, nil
+	}
+	p.sandboxes[principal] = &pooledSandbox{handler: handler, usedAt: now}
+	return handler, nil
+}
@@ -0,0 +1,87 @@
+package httpmw_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/httpmw"
+)
+
+func TestSandboxRoutesFlaggedRequests(t *testing.T) {
+	respond := func(body string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(body))
+		})
+	}
+	handler := httpmw.Sandbox(respond("sandbox"))(respond("live"))
+
+	cases := []struct {
+		header string
+		status int
+		body   string
+	}{
+		{"", http.StatusOK, "live"},
+		{"false", http.StatusOK, "live"},
+		{"1", http.StatusOK, "sandbox"},
+		{"true", http.StatusOK, "sandbox"},
+		{"maybe", http.StatusBadRequest, `{"error":"invalid X-Sandbox header"}`},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if c.header != "" {
+			req.Header.Set(httpmw.SandboxHeader, c.header)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != c.status || rr.Body.String() != c.body {
+			t.Fatalf("header %q: got %d %q, want %d %q", c.header, rr.Code, rr.Body.String(), c.status, c.body)
+		}
+	}
+}
+
+func TestSandboxPoolKeepsPrincipalsApart(t *testing.T) {
+	built := 0
+	pool := httpmw.NewSandboxPool(func(p auth.Principal) (http.Handler, error) {
+		built++
+		copyNo := built
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "%s#%d", p.ID, copyNo)
+		}), nil
+	}, time.Hour)
+
+	serve := func(p *auth.Principal) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if p != nil {
+			req = req.WithContext(auth.WithPrincipal(req.Context(), *p))
+		}
+		rr := httptest.NewRecorder()
+		pool.ServeHTTP(rr, req)
+		return rr
+	}
+	alice := auth.Principal{Role: domain.RoleTeacher, ID: "teacher-001"}
+	bob := auth.Principal{Role: domain.RoleTeacher, ID: "teacher-002"}
+
+	for _, want := range []struct {
+		p    auth.Principal
+		body string
+	}{
+		{alice, "teacher-001#1"},
+		{bob, "teacher-002#2"},
+		{alice, "teacher-001#1"},
+	} {
+		if rr := serve(&want.p); rr.Body.String() != want.body {
+			t.Fatalf("got %q, want %q", rr.Body.String(), want.body)
+		}
+	}
+	if pool.Len() != 2 {
+		t.Fatalf("expected 2 sandboxes, got %d", pool.Len())
+	}
+	if rr := serve(nil); rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without a principal, got %d", rr.Code)
+	}
+}
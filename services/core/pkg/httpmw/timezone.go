@@ -0,0 +1,51 @@
+package httpmw
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+// TimezoneHeader names the IANA time zone a caller wants timestamps shown
+// in, such as "Asia/Tokyo". Responses echo the zone they used.
+const TimezoneHeader = "X-Timezone"
+
+type locationKey struct{}
+
+// WithLocation returns ctx carrying the zone timestamps are shown in.
+func WithLocation(ctx context.Context, loc *time.Location) context.Context {
+	return context.WithValue(ctx, locationKey{}, loc)
+}
+
+// LocationFrom returns the zone stored in ctx, and false when the request
+// named none.
+func LocationFrom(ctx context.Context) (*time.Location, bool) {
+	loc, ok := ctx.Value(locationKey{}).(*time.Location)
+	return loc, ok && loc != nil
+}
+
+// Timezone stores the zone named by the X-Timezone header in the request
+// context, where it overrides the user's and school's settings. Unknown
+// zones are refused so a typo is not silently shown as UTC.
+func Timezone() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			name := strings.TrimSpace(r.Header.Get(TimezoneHeader))
+			if name == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			loc, ok := domain.LoadTimezone(name)
+			if !ok {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"invalid X-Timezone header"}`))
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(WithLocation(r.Context(), loc)))
+		})
+	}
+}
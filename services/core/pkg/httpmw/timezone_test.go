@@ -0,0 +1,42 @@
+package httpmw_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/httpmw"
+)
+
+func TestTimezoneStoresRequestedZone(t *testing.T) {
+	handler := httpmw.Timezone()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		loc, ok := httpmw.LocationFrom(r.Context())
+		if !ok {
+			_, _ = w.Write([]byte("none"))
+			return
+		}
+		_, _ = w.Write([]byte(loc.String()))
+	}))
+
+	cases := []struct {
+		header string
+		status int
+		body   string
+	}{
+		{"", http.StatusOK, "none"},
+		{"Asia/Tokyo", http.StatusOK, "Asia/Tokyo"},
+		{"Local", http.StatusBadRequest, `{"error":"invalid X-Timezone header"}`},
+		{"Mars/Olympus", http.StatusBadRequest, `{"error":"invalid X-Timezone header"}`},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if c.header != "" {
+			req.Header.Set(httpmw.TimezoneHeader, c.header)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != c.status || rr.Body.String() != c.body {
+			t.Fatalf("header %q: got %d %q, want %d %q", c.header, rr.Code, rr.Body.String(), c.status, c.body)
+		}
+	}
+}
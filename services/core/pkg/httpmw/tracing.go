@@ -0,0 +1,34 @@
+package httpmw
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/sky0621/go_work_sample/core/pkg/tracing"
+)
+
+// Tracing records a server span for every request, joining the caller's
+// trace when the request carries a traceparent header. Place it outside
+// Logging so log records carry the trace ID.
+func Tracing() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := tracing.Extract(r.Context(), r.Header)
+			ctx, span := tracing.Start(ctx, r.Method+" "+r.URL.Path,
+				tracing.WithKind(tracing.KindServer),
+				tracing.WithAttributes(
+					tracing.String("http.request.method", r.Method),
+					tracing.String("url.path", r.URL.Path),
+				),
+			)
+			defer span.End()
+
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, r.WithContext(ctx))
+			span.SetAttributes(tracing.Int("http.response.status_code", sw.status))
+			if sw.status >= http.StatusInternalServerError {
+				span.SetError(fmt.Errorf("HTTP %d", sw.status))
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/health"
+	"github.com/sky0621/go_work_sample/core/pkg/id"
+)
+
+// Status describes where a job is in its lifecycle.
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// ErrQueueFull is returned when no more jobs can be accepted.
+var ErrQueueFull = errors.New("job queue is full")
+
+// DefaultRetention is how long a queue keeps finished jobs and their
+// artifacts.
+const DefaultRetention = 24 * time.Hour
+
+// Artifact is the downloadable output of a job. Small outputs are kept inline
+// in Data; larger ones are written to blob storage and referenced by BlobKey.
+type Artifact struct {
+	Name        string
+	ContentType string
+	Data        []byte
+	BlobKey     string
+}
+
+// Func performs the work of a job.
+type Func func(ctx context.Context) (*Artifact, error)
+
+// Job is a snapshot of a queued unit of work.
+type Job struct {
+	ID        string
+	Kind      string
+	Owner     string
+	Status    Status
+	Error     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type entry struct {
+	job      Job
+	fn       Func
+	artifact *Artifact
+}
+
+// Queue runs jobs on a fixed pool of in-process workers. Finished jobs are
+// forgotten once they are older than the retention, pruned as new jobs are
+// enqueued.
+type Queue struct {
+	mu        sync.RWMutex
+	entries   map[string]*entry
+	pending   chan string
+	workers   int
+	retention time.Duration
+	evicted   func(Artifact)
+}
+
+// NewQueue creates a queue with the given number of workers and capacity for
+// pending jobs.
+func NewQueue(workers, capacity int) *Queue {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Queue{
+		entries:   make(map[string]*entry),
+		pending:   make(chan string, capacity),
+		workers:   workers,
+		retention: DefaultRetention,
+	}
+}
+
+// SetRetention keeps finished jobs and their artifacts for d after they
+// finish instead of DefaultRetention.
+func (q *Queue) SetRetention(d time.Duration) {
+	q.mu.Lock()
+	q.retention = d
+	q.mu.Unlock()
+}
+
+// OnEvict calls fn with the artifact of every pruned job, so outputs stored
+// outside the queue, such as blobs, are deleted with it.
+func (q *Queue) OnEvict(fn func(Artifact)) {
+	q.mu.Lock()
+	q.evicted = fn
+	q.mu.Unlock()
+}
+
+// Start launches the workers; they stop when ctx is cancelled.
+func (q *Queue) Start(ctx context.Context) {
+	go q.Run(ctx)
+}
+
+// Run works through jobs like Start but blocks until ctx is cancelled and
+// every worker has stopped.
+func (q *Queue) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < q.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// Depth returns the number of jobs waiting for a worker.
+func (q *Queue) Depth() int {
+	return len(q.pending)
+}
+
+// Unfinished returns the number of jobs queued or running.
+func (q *Queue) Unfinished() int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	n := 0
+	for _, e := range q.entries {
+		if e.job.Status == StatusQueued || e.job.Status == StatusRunning {
+			n++
+		}
+	}
+	return n
+}
+
+// Enqueue schedules fn and returns the queued job.
+func (q *Queue) Enqueue(kind, owner string, fn Func) (Job, error) {
+	now := time.Now().UTC()
+	e := &entry{
+		job: Job{
+			ID:        id.New(),
+			Kind:      kind,
+			Owner:     owner,
+			Status:    StatusQueued,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		fn: fn,
+	}
+
+	q.mu.Lock()
+	evicted := q.prune(now)
+	onEvict := q.evicted
+	select {
+	case q.pending <- e.job.ID:
+	default:
+		q.mu.Unlock()
+		return Job{}, ErrQueueFull
+	}
+	q.entries[e.job.ID] = e
+	q.mu.Unlock()
+
+	if onEvict != nil {
+		for _, artifact := range evicted {
+			onEvict(artifact)
+		}
+	}
+	return e.job, nil
+}
+
+// prune forgets the jobs that finished more than the retention before now
+// and returns their artifacts. The caller holds q.mu.
+func (q *Queue) prune(now time.Time) []Artifact {
+	var evicted []Artifact
+	for jobID, e := range q.entries {
+		finished := e.job.Status == StatusSucceeded || e.job.Status == StatusFailed
+		if !finished || now.Sub(e.job.UpdatedAt) < q.retention {
+			continue
+		}
+		delete(q.entries, jobID)
+		if e.artifact != nil {
+			evicted = append(evicted, *e.artifact)
+		}
+	}
+	return evicted
+}
+
+// Get returns the job with the given ID.
+func (q *Queue) Get(jobID string) (Job, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	e, ok := q.entries[jobID]
+	if !ok {
+		return Job{}, false
+	}
+	return e.job, true
+}
+
+// Artifact returns the output of a succeeded job.
+func (q *Queue) Artifact(jobID string) (*Artifact, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	e, ok := q.entries[jobID]
+	if !ok || e.artifact == nil {
+		return nil, false
+	}
+	return e.artifact, true
+}
+
+func (q *Queue) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case jobID := <-q.pending:
+			q.run(ctx, jobID)
+			// A failing job is the job's problem, not the worker's.
+			health.Report(ctx, nil)
+		}
+	}
+}
+
+func (q *Queue) run(ctx context.Context, jobID string) {
+	q.mu.Lock()
+	e, ok := q.entries[jobID]
+	if !ok {
+		q.mu.Unlock()
+		return
+	}
+	e.job.Status = StatusRunning
+	e.job.UpdatedAt = time.Now().UTC()
+	fn := e.fn
+	q.mu.Unlock()
+
+	artifact, err := fn(ctx)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	e.job.UpdatedAt = time.Now().UTC()
+	e.fn = nil
+	if err != nil {
+		e.job.Status = StatusFailed
+		e.job.Error = err.Error()
+		log.Printf("job %s (%s) failed: %v", e.job.ID, e.job.Kind, err)
+		return
+	}
+	e.job.Status = StatusSucceeded
+	e.artifact = artifact
+}
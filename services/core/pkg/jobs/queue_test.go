@@ -0,0 +1,104 @@
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/jobs"
+)
+
+func TestQueueRunsJobs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	queue := jobs.NewQueue(1, 4)
+	queue.Start(ctx)
+
+	ok, err := queue.Enqueue("export", "teacher-001", func(context.Context) (*jobs.Artifact, error) {
+		return &jobs.Artifact{Name: "out.txt", ContentType: "text/plain", Data: []byte("done")}, nil
+	})
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	failed, err := queue.Enqueue("export", "teacher-001", func(context.Context) (*jobs.Artifact, error) {
+		return nil, errors.New("boom")
+	})
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	waitFor(t, queue, ok.ID, jobs.StatusSucceeded)
+	waitFor(t, queue, failed.ID, jobs.StatusFailed)
+
+	artifact, found := queue.Artifact(ok.ID)
+	if !found || string(artifact.Data) != "done" {
+		t.Fatalf("expected artifact for succeeded job, got %+v", artifact)
+	}
+	if _, found := queue.Artifact(failed.ID); found {
+		t.Fatal("expected no artifact for failed job")
+	}
+}
+
+func TestQueueRejectsWhenFull(t *testing.T) {
+	queue := jobs.NewQueue(1, 1)
+	noop := func(context.Context) (*jobs.Artifact, error) { return nil, nil }
+
+	if _, err := queue.Enqueue("noop", "", noop); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if _, err := queue.Enqueue("noop", "", noop); !errors.Is(err, jobs.ErrQueueFull) {
+		t.Fatalf("expected ErrQueueFull, got %v", err)
+	}
+}
+
+func TestQueuePrunesFinishedJobs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	queue := jobs.NewQueue(1, 4)
+	queue.SetRetention(10 * time.Millisecond)
+	var evicted []string
+	queue.OnEvict(func(a jobs.Artifact) { evicted = append(evicted, a.BlobKey) })
+	queue.Start(ctx)
+
+	old, err := queue.Enqueue("export", "teacher-001", func(context.Context) (*jobs.Artifact, error) {
+		return &jobs.Artifact{Name: "out.parquet", BlobKey: "exports/out.parquet"}, nil
+	})
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	waitFor(t, queue, old.ID, jobs.StatusSucceeded)
+	time.Sleep(20 * time.Millisecond)
+
+	fresh, err := queue.Enqueue("export", "teacher-001", func(context.Context) (*jobs.Artifact, error) { return nil, nil })
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if _, found := queue.Get(old.ID); found {
+		t.Fatal("expected the expired job to be pruned")
+	}
+	if _, found := queue.Artifact(old.ID); found {
+		t.Fatal("expected the expired artifact to be pruned")
+	}
+	if len(evicted) != 1 || evicted[0] != "exports/out.parquet" {
+		t.Fatalf("expected the pruned artifact to be handed over, got %v", evicted)
+	}
+	if _, found := queue.Get(fresh.ID); !found {
+		t.Fatal("expected the new job to be kept")
+	}
+}
+
+func waitFor(t *testing.T, queue *jobs.Queue, jobID string, status jobs.Status) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if job, ok := queue.Get(jobID); ok && job.Status == status {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	job, _ := queue.Get(jobID)
+	t.Fatalf("job %s did not reach %s, last status %s", jobID, status, job.Status)
+}
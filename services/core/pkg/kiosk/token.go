@@ -0,0 +1,120 @@
+package kiosk
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/hmactoken"
+	"github.com/sky0621/go_work_sample/core/pkg/id"
+)
+
+var (
+	// ErrInvalidToken is returned for malformed, tampered or expired tokens.
+	ErrInvalidToken = errors.New("invalid kiosk token")
+	// ErrTokenRedeemed is returned when a token already redeemed by one
+	// session is used by another.
+	ErrTokenRedeemed = errors.New("kiosk token already redeemed")
+)
+
+// Claims describe what a kiosk token allows.
+type Claims struct {
+	TokenID   string           `json:"jti"`
+	StudentID domain.StudentID `json:"sub"`
+	TestID    domain.TestID    `json:"test"`
+	ExpiresAt time.Time        `json:"exp"`
+}
+
+// Permits reports whether the claims allow the request: only fetching the
+// questions of, and submitting answers to, the scoped test.
+func (c Claims) Permits(method, path string) bool {
+	prefix := "/api/students/" + string(c.StudentID) + "/tests/" + string(c.TestID) + "/"
+	switch strings.TrimSuffix(path, "/") {
+	case prefix + "questions":
+		return method == http.MethodGet
+	case prefix + "answers":
+		return method == http.MethodPost
+	default:
+		return false
+	}
+}
+
+// Signer issues and verifies HMAC-signed kiosk tokens, and remembers which
+// session redeemed each token. Redemptions are held in the memory of the
+// process until the token expires: a restart forgets them, and instances of
+// the student service behind a load balancer each keep their own. Either
+// lets a second session redeem a token, so a token is only bound to one
+// machine while a single student service keeps running.
+type Signer struct {
+	tokens *hmactoken.Signer
+	now    func() time.Time
+
+	mu       sync.Mutex
+	redeemed map[string]redemption
+}
+
+type redemption struct {
+	session   string
+	expiresAt time.Time
+}
+
+// NewSigner creates a signer using secret.
+func NewSigner(secret string) *Signer {
+	return &Signer{
+		tokens:   hmactoken.NewSigner(secret, hmactoken.TypeKiosk),
+		now:      func() time.Time { return time.Now().UTC() },
+		redeemed: make(map[string]redemption),
+	}
+}
+
+// Issue creates a token scoped to the student and test, valid for ttl.
+func (s *Signer) Issue(studentID domain.StudentID, testID domain.TestID, ttl time.Duration) (string, Claims, error) {
+	claims := Claims{
+		TokenID:   id.New(),
+		StudentID: studentID,
+		TestID:    testID,
+		ExpiresAt: s.now().Add(ttl).Truncate(time.Second),
+	}
+	token, err := s.tokens.Sign(claims)
+	if err != nil {
+		return "", Claims{}, err
+	}
+	return token, claims, nil
+}
+
+// Verify checks the signature and expiry of token and returns its claims.
+func (s *Signer) Verify(token string) (Claims, error) {
+	var claims Claims
+	if err := s.tokens.Verify(token, &claims); err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	if !s.now().Before(claims.ExpiresAt) {
+		return Claims{}, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// Redeem binds the token of claims to session on its first use. Later uses
+// are only accepted from that session, so a token is good for one machine.
+func (s *Signer) Redeem(claims Claims, session string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for tokenID, r := range s.redeemed {
+		if !now.Before(r.expiresAt) {
+			delete(s.redeemed, tokenID)
+		}
+	}
+	if r, ok := s.redeemed[claims.TokenID]; ok {
+		if session == "" || r.session != session {
+			return ErrTokenRedeemed
+		}
+		return nil
+	}
+	s.redeemed[claims.TokenID] = redemption{session: session, expiresAt: claims.ExpiresAt}
+	return nil
+}
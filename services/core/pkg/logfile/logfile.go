@@ -0,0 +1,90 @@
+// Package logfile writes a service's log to a file that operators rotate
+// while the service runs, or to standard output when no file is set.
+package logfile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrNotRotatable is returned by Rotate when the log goes to standard output,
+// which whatever collects it rotates instead.
+var ErrNotRotatable = errors.New("log goes to standard output")
+
+// File is a log destination safe for concurrent writes.
+type File struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+	now  func() time.Time
+}
+
+// Open appends to the file at path, creating it if needed. An empty path
+// writes to standard output.
+func Open(path string) (*File, error) {
+	if path == "" {
+		return &File{file: os.Stdout, now: time.Now}, nil
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("logfile: %w", err)
+	}
+	return &File{path: path, file: file, now: time.Now}, nil
+}
+
+// Path returns the file's path, or "" for standard output.
+func (f *File) Path() string {
+	return f.path
+}
+
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Write(p)
+}
+
+// Rotate moves the current file aside, suffixed with the time, and starts a
+// new one at the path. It returns the name the old file was moved to.
+func (f *File) Rotate() (string, error) {
+	if f.path == "" {
+		return "", ErrNotRotatable
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	stamp := f.path + "." + f.now().UTC().Format("20060102T150405Z")
+	rotated := stamp
+	// Rotations within a second get a counter rather than overwrite.
+	for n := 2; exists(rotated); n++ {
+		rotated = fmt.Sprintf("%s-%d", stamp, n)
+	}
+	if err := os.Rename(f.path, rotated); err != nil {
+		return "", fmt.Errorf("logfile: %w", err)
+	}
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		// Keep writing to the moved file rather than losing lines.
+		return rotated, fmt.Errorf("logfile: %w", err)
+	}
+	old := f.file
+	f.file = file
+	return rotated, old.Close()
+}
+
+// Close closes the file. Standard output is left open.
+func (f *File) Close() error {
+	if f.path == "" {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
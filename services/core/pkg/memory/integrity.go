@@ -0,0 +1,224 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+// CheckIntegrity verifies referential integrity of a snapshot before it is
+// loaded, reporting every problem found rather than stopping at the first.
+func CheckIntegrity(state State) error {
+	var problems []error
+	report := func(format string, args ...any) {
+		problems = append(problems, fmt.Errorf(format, args...))
+	}
+
+	districts := make(map[domain.DistrictID]struct{}, len(state.Districts))
+	for _, d := range state.Districts {
+		if _, dup := districts[d.ID]; dup {
+			report("duplicate district %q", d.ID)
+		}
+		districts[d.ID] = struct{}{}
+	}
+
+	schools := make(map[domain.SchoolID]struct{}, len(state.Schools))
+	for _, s := range state.Schools {
+		if _, dup := schools[s.ID]; dup {
+			report("duplicate school %q", s.ID)
+		}
+		schools[s.ID] = struct{}{}
+		if _, ok := districts[s.DistrictID]; s.DistrictID != "" && !ok {
+			report("school %q references unknown district %q", s.ID, s.DistrictID)
+		}
+	}
+
+	for _, staff := range state.DistrictStaff {
+		if _, ok := districts[staff.DistrictID]; !ok {
+			report("district staff %q references unknown district %q", staff.ID, staff.DistrictID)
+		}
+		for _, schoolID := range staff.ManagedSchools {
+			if _, ok := schools[schoolID]; !ok {
+				report("district staff %q manages unknown school %q", staff.ID, schoolID)
+			}
+		}
+	}
+
+	grades := make(map[domain.GradeID]struct{}, len(state.Grades))
+	for _, g := range state.Grades {
+		if _, dup := grades[g.ID]; dup {
+			report("duplicate grade %q", g.ID)
+		}
+		grades[g.ID] = struct{}{}
+		if _, ok := schools[g.SchoolID]; !ok {
+			report("grade %q references unknown school %q", g.ID, g.SchoolID)
+		}
+	}
+
+	classes := make(map[domain.ClassID]struct{}, len(state.Classes))
+	for _, c := range state.Classes {
+		if _, dup := classes[c.ID]; dup {
+			report("duplicate class %q", c.ID)
+		}
+		classes[c.ID] = struct{}{}
+		if _, ok := grades[c.GradeID]; !ok {
+			report("class %q references unknown grade %q", c.ID, c.GradeID)
+		}
+	}
+
+	teachers := make(map[domain.TeacherID]struct{}, len(state.Teachers))
+	for _, t := range state.Teachers {
+		if _, dup := teachers[t.ID]; dup {
+			report("duplicate teacher %q", t.ID)
+		}
+		teachers[t.ID] = struct{}{}
+		if _, ok := schools[t.SchoolID]; !ok {
+			report("teacher %q references unknown school %q", t.ID, t.SchoolID)
+		}
+	}
+
+	students := make(map[domain.StudentID]struct{}, len(state.Students))
+	for _, st := range state.Students {
+		if _, dup := students[st.ID]; dup {
+			report("duplicate student %q", st.ID)
+		}
+		students[st.ID] = struct{}{}
+		if _, ok := classes[st.ClassID]; !ok {
+			report("student %q references unknown class %q", st.ID, st.ClassID)
+		}
+	}
+
+	tests := make(map[domain.TestID]struct{}, len(state.Tests))
+	for _, test := range state.Tests {
+		if _, dup := tests[test.ID]; dup {
+			report("duplicate test %q", test.ID)
+		}
+		tests[test.ID] = struct{}{}
+		if _, ok := teachers[test.TeacherID]; !ok {
+			report("test %q references unknown teacher %q", test.ID, test.TeacherID)
+		}
+	}
+
+	questions := make(map[domain.QuestionID]domain.TestID, len(state.Questions))
+	for _, q := range state.Questions {
+		if _, dup := questions[q.ID]; dup {
+			report("duplicate question %q", q.ID)
+		}
+		questions[q.ID] = q.TestID
+		if _, ok := tests[q.TestID]; !ok {
+			report("question %q references unknown test %q", q.ID, q.TestID)
+		}
+	}
+
+	for testID, assigned := range state.Assignments {
+		if _, ok := tests[domain.TestID(testID)]; !ok {
+			report("assignment references unknown test %q", testID)
+		}
+		for _, sid := range assigned {
+			if _, ok := students[sid]; !ok {
+				report("test %q is assigned to unknown student %q", testID, sid)
+			}
+		}
+	}
+
+	answers := make(map[domain.AnswerID]struct{}, len(state.Answers))
+	for _, ans := range state.Answers {
+		if _, dup := answers[ans.ID]; dup {
+			report("duplicate answer %q", ans.ID)
+		}
+		answers[ans.ID] = struct{}{}
+		if testID, ok := questions[ans.QuestionID]; !ok || testID != ans.TestID {
+			report("answer %q references unknown question %q for test %q", ans.ID, ans.QuestionID, ans.TestID)
+		}
+		if _, ok := students[ans.StudentID]; !ok {
+			report("answer %q references unknown student %q", ans.ID, ans.StudentID)
+		}
+	}
+
+	results := make(map[domain.ResultID]struct{}, len(state.Results))
+	for _, res := range state.Results {
+		if _, dup := results[res.ID]; dup {
+			report("duplicate result %q", res.ID)
+		}
+		results[res.ID] = struct{}{}
+		if _, ok := answers[res.AnswerID]; !ok {
+			report("result %q references unknown answer %q", res.ID, res.AnswerID)
+		}
+	}
+
+	rubrics := make(map[domain.RubricID]map[domain.CriterionID]struct{}, len(state.Rubrics))
+	for _, rubric := range state.Rubrics {
+		if _, dup := rubrics[rubric.ID]; dup {
+			report("duplicate rubric %q", rubric.ID)
+		}
+		criteria := make(map[domain.CriterionID]struct{}, len(rubric.Criteria))
+		for _, c := range rubric.Criteria {
+			criteria[c.ID] = struct{}{}
+		}
+		rubrics[rubric.ID] = criteria
+		if _, ok := teachers[rubric.TeacherID]; !ok {
+			report("rubric %q references unknown teacher %q", rubric.ID, rubric.TeacherID)
+		}
+	}
+
+	for _, tmpl := range state.Templates {
+		if _, ok := teachers[tmpl.TeacherID]; !ok {
+			report("feedback template %q references unknown teacher %q", tmpl.ID, tmpl.TeacherID)
+		}
+		if tmpl.RubricID == "" {
+			continue
+		}
+		criteria, ok := rubrics[tmpl.RubricID]
+		if !ok {
+			report("feedback template %q references unknown rubric %q", tmpl.ID, tmpl.RubricID)
+			continue
+		}
+		if _, ok := criteria[tmpl.CriterionID]; tmpl.CriterionID != "" && !ok {
+			report("feedback template %q references unknown criterion %q", tmpl.ID, tmpl.CriterionID)
+		}
+	}
+
+	for _, session := range state.Sessions {
+		if _, ok := students[session.StudentID]; !ok {
+			report("test session references unknown student %q", session.StudentID)
+		}
+		for _, qid := range session.Flagged {
+			if testID, ok := questions[qid]; !ok || testID != session.TestID {
+				report("test session of student %q flags unknown question %q for test %q", session.StudentID, qid, session.TestID)
+			}
+		}
+	}
+
+	for _, s := range state.Submissions {
+		if _, ok := tests[s.TestID]; !ok {
+			report("submission of student %q references unknown test %q", s.StudentID, s.TestID)
+		}
+		if _, ok := students[s.StudentID]; !ok {
+			report("submission references unknown student %q", s.StudentID)
+		}
+	}
+
+	for _, d := range state.Delegations {
+		if _, ok := teachers[d.TeacherID]; !ok {
+			report("delegation %q references unknown teacher %q", d.ID, d.TeacherID)
+		}
+		if _, ok := teachers[d.DelegateID]; !ok {
+			report("delegation %q references unknown delegate %q", d.ID, d.DelegateID)
+		}
+	}
+
+	for _, d := range state.Devices {
+		if _, ok := students[d.StudentID]; !ok {
+			report("device session %q references unknown student %q", d.ID, d.StudentID)
+		}
+	}
+
+	for _, q := range state.BankQuestions {
+		if _, ok := teachers[q.TeacherID]; !ok {
+			report("bank question %q references unknown teacher %q", q.ID, q.TeacherID)
+		}
+	}
+
+	return errors.Join(problems...)
+}
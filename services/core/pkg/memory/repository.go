@@ -1,8 +1,10 @@
 package memory
 
 import (
+	"context"
 	"errors"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -39,20 +41,49 @@ type Repository struct {
 	answersByTest  map[domain.TestID]map[domain.AnswerID]struct{}
 	results        map[domain.ResultID]domain.Result
 	resultByAnswer map[domain.AnswerID]domain.ResultID
+	notifications  map[domain.NotificationID]domain.Notification
+	inbox          map[string][]domain.NotificationID
+	digests        map[domain.DigestItemID]domain.DigestItem
+	comments       map[domain.QuestionCommentID]domain.QuestionComment
+	answerComments map[domain.AnswerCommentID]domain.AnswerComment
+	sessions       map[string]domain.TestSession
+	submissions    map[string]domain.Submission
+	rubrics        map[domain.RubricID]domain.Rubric
+	templates      map[domain.FeedbackTemplateID]domain.FeedbackTemplate
+	delegations    map[domain.DelegationID]domain.Delegation
+	devices        map[domain.DeviceSessionID]domain.DeviceSession
+	bank           map[domain.BankQuestionID]domain.BankQuestion
+	districts      map[domain.DistrictID]domain.District
+	districtStaff  map[domain.DistrictStaffID]domain.DistrictStaff
+	audit          map[domain.AuditEntryID]domain.AuditEntry
 }
 
 // State represents a serialisable snapshot of the repository.
 type State struct {
-	Schools     []domain.School               `json:"schools"`
-	Grades      []domain.Grade                `json:"grades"`
-	Classes     []domain.Class                `json:"classes"`
-	Teachers    []domain.Teacher              `json:"teachers"`
-	Students    []domain.Student              `json:"students"`
-	Tests       []domain.Test                 `json:"tests"`
-	Questions   []domain.Question             `json:"questions"`
-	Assignments map[string][]domain.StudentID `json:"assignments"`
-	Answers     []domain.Answer               `json:"answers"`
-	Results     []domain.Result               `json:"results"`
+	Schools       []domain.School               `json:"schools"`
+	Grades        []domain.Grade                `json:"grades"`
+	Classes       []domain.Class                `json:"classes"`
+	Teachers      []domain.Teacher              `json:"teachers"`
+	Students      []domain.Student              `json:"students"`
+	Tests         []domain.Test                 `json:"tests"`
+	Questions     []domain.Question             `json:"questions"`
+	Assignments   map[string][]domain.StudentID `json:"assignments"`
+	Answers       []domain.Answer               `json:"answers"`
+	Results       []domain.Result               `json:"results"`
+	Notifications []domain.Notification         `json:"notifications"`
+	Digests       []domain.DigestItem           `json:"digest_items"`
+	Comments      []domain.QuestionComment      `json:"question_comments"`
+	Feedback      []domain.AnswerComment        `json:"answer_comments"`
+	Sessions      []domain.TestSession          `json:"test_sessions"`
+	Submissions   []domain.Submission           `json:"submissions"`
+	Rubrics       []domain.Rubric               `json:"rubrics"`
+	Templates     []domain.FeedbackTemplate     `json:"feedback_templates"`
+	Delegations   []domain.Delegation           `json:"delegations"`
+	Devices       []domain.DeviceSession        `json:"device_sessions"`
+	BankQuestions []domain.BankQuestion         `json:"bank_questions"`
+	Districts     []domain.District             `json:"districts"`
+	DistrictStaff []domain.DistrictStaff        `json:"district_staff"`
+	Audit         []domain.AuditEntry           `json:"audit_entries"`
 }
 
 // NewRepository creates a repository loaded with the provided seed.
@@ -86,6 +117,21 @@ func newRepository() *Repository {
 		answersByTest:  make(map[domain.TestID]map[domain.AnswerID]struct{}),
 		results:        make(map[domain.ResultID]domain.Result),
 		resultByAnswer: make(map[domain.AnswerID]domain.ResultID),
+		notifications:  make(map[domain.NotificationID]domain.Notification),
+		inbox:          make(map[string][]domain.NotificationID),
+		digests:        make(map[domain.DigestItemID]domain.DigestItem),
+		comments:       make(map[domain.QuestionCommentID]domain.QuestionComment),
+		answerComments: make(map[domain.AnswerCommentID]domain.AnswerComment),
+		sessions:       make(map[string]domain.TestSession),
+		submissions:    make(map[string]domain.Submission),
+		rubrics:        make(map[domain.RubricID]domain.Rubric),
+		templates:      make(map[domain.FeedbackTemplateID]domain.FeedbackTemplate),
+		delegations:    make(map[domain.DelegationID]domain.Delegation),
+		devices:        make(map[domain.DeviceSessionID]domain.DeviceSession),
+		bank:           make(map[domain.BankQuestionID]domain.BankQuestion),
+		districts:      make(map[domain.DistrictID]domain.District),
+		districtStaff:  make(map[domain.DistrictStaffID]domain.DistrictStaff),
+		audit:          make(map[domain.AuditEntryID]domain.AuditEntry),
 	}
 }
 
@@ -93,10 +139,22 @@ var _ repository.OrganizationRepository = (*Repository)(nil)
 var _ repository.TestRepository = (*Repository)(nil)
 var _ repository.AnswerRepository = (*Repository)(nil)
 var _ repository.ResultRepository = (*Repository)(nil)
+var _ repository.NotificationRepository = (*Repository)(nil)
+var _ repository.DigestRepository = (*Repository)(nil)
+var _ repository.QuestionCommentRepository = (*Repository)(nil)
+var _ repository.AnswerCommentRepository = (*Repository)(nil)
+var _ repository.TestSessionRepository = (*Repository)(nil)
+var _ repository.SubmissionRepository = (*Repository)(nil)
+var _ repository.RubricRepository = (*Repository)(nil)
+var _ repository.DelegationRepository = (*Repository)(nil)
+var _ repository.DeviceSessionRepository = (*Repository)(nil)
+var _ repository.QuestionBankRepository = (*Repository)(nil)
+var _ repository.DistrictRepository = (*Repository)(nil)
+var _ repository.AuditRepository = (*Repository)(nil)
 
 // OrganizationRepository implementation.
 
-func (r *Repository) GetSchool(id domain.SchoolID) (*domain.School, error) {
+func (r *Repository) GetSchool(_ context.Context, id domain.SchoolID) (*domain.School, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -108,7 +166,7 @@ func (r *Repository) GetSchool(id domain.SchoolID) (*domain.School, error) {
 	return &s, nil
 }
 
-func (r *Repository) ListSchools() ([]domain.School, error) {
+func (r *Repository) ListSchools(_ context.Context, page repository.PageRequest) (repository.Page[domain.School], error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -118,13 +176,13 @@ func (r *Repository) ListSchools() ([]domain.School, error) {
 	}
 
 	sort.Slice(schools, func(i, j int) bool {
-		return schools[i].CreatedAt.Before(schools[j].CreatedAt)
+		return createdBefore(schools[i].CreatedAt, schools[i].ID, schools[j].CreatedAt, schools[j].ID)
 	})
 
-	return schools, nil
+	return repository.Paginate(schools, page, false, schoolPageKey)
 }
 
-func (r *Repository) GetGrade(id domain.GradeID) (*domain.Grade, error) {
+func (r *Repository) GetGrade(_ context.Context, id domain.GradeID) (*domain.Grade, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -136,7 +194,7 @@ func (r *Repository) GetGrade(id domain.GradeID) (*domain.Grade, error) {
 	return &g, nil
 }
 
-func (r *Repository) GetClass(id domain.ClassID) (*domain.Class, error) {
+func (r *Repository) GetClass(_ context.Context, id domain.ClassID) (*domain.Class, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -148,7 +206,7 @@ func (r *Repository) GetClass(id domain.ClassID) (*domain.Class, error) {
 	return &c, nil
 }
 
-func (r *Repository) GetTeacher(id domain.TeacherID) (*domain.Teacher, error) {
+func (r *Repository) GetTeacher(_ context.Context, id domain.TeacherID) (*domain.Teacher, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -160,7 +218,7 @@ func (r *Repository) GetTeacher(id domain.TeacherID) (*domain.Teacher, error) {
 	return &t, nil
 }
 
-func (r *Repository) GetStudent(id domain.StudentID) (*domain.Student, error) {
+func (r *Repository) GetStudent(_ context.Context, id domain.StudentID) (*domain.Student, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -172,7 +230,28 @@ func (r *Repository) GetStudent(id domain.StudentID) (*domain.Student, error) {
 	return &s, nil
 }
 
-func (r *Repository) ListGrades(schoolID domain.SchoolID) ([]domain.Grade, error) {
+func (r *Repository) FindStudentsByEmail(_ context.Context, email string) ([]domain.Student, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	email = strings.TrimSpace(email)
+	students := make([]domain.Student, 0)
+	if email == "" {
+		return students, nil
+	}
+	for _, st := range r.students {
+		if strings.EqualFold(st.Email, email) || strings.EqualFold(st.GuardianEmail, email) {
+			students = append(students, cloneStudent(st))
+		}
+	}
+
+	sort.Slice(students, func(i, j int) bool {
+		return createdBefore(students[i].CreatedAt, students[i].ID, students[j].CreatedAt, students[j].ID)
+	})
+	return students, nil
+}
+
+func (r *Repository) ListGrades(_ context.Context, schoolID domain.SchoolID, page repository.PageRequest) (repository.Page[domain.Grade], error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -184,13 +263,13 @@ func (r *Repository) ListGrades(schoolID domain.SchoolID) ([]domain.Grade, error
 	}
 
 	sort.Slice(grades, func(i, j int) bool {
-		return grades[i].CreatedAt.Before(grades[j].CreatedAt)
+		return createdBefore(grades[i].CreatedAt, grades[i].ID, grades[j].CreatedAt, grades[j].ID)
 	})
 
-	return grades, nil
+	return repository.Paginate(grades, page, false, gradePageKey)
 }
 
-func (r *Repository) ListClasses(gradeID domain.GradeID) ([]domain.Class, error) {
+func (r *Repository) ListClasses(_ context.Context, gradeID domain.GradeID, page repository.PageRequest) (repository.Page[domain.Class], error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -202,13 +281,13 @@ func (r *Repository) ListClasses(gradeID domain.GradeID) ([]domain.Class, error)
 	}
 
 	sort.Slice(classes, func(i, j int) bool {
-		return classes[i].CreatedAt.Before(classes[j].CreatedAt)
+		return createdBefore(classes[i].CreatedAt, classes[i].ID, classes[j].CreatedAt, classes[j].ID)
 	})
 
-	return classes, nil
+	return repository.Paginate(classes, page, false, classPageKey)
 }
 
-func (r *Repository) ListStudents(classID domain.ClassID) ([]domain.Student, error) {
+func (r *Repository) ListStudents(_ context.Context, classID domain.ClassID, page repository.PageRequest) (repository.Page[domain.Student], error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -220,13 +299,13 @@ func (r *Repository) ListStudents(classID domain.ClassID) ([]domain.Student, err
 	}
 
 	sort.Slice(students, func(i, j int) bool {
-		return students[i].CreatedAt.Before(students[j].CreatedAt)
+		return createdBefore(students[i].CreatedAt, students[i].ID, students[j].CreatedAt, students[j].ID)
 	})
 
-	return students, nil
+	return repository.Paginate(students, page, false, studentPageKey)
 }
 
-func (r *Repository) ListTeachers(schoolID domain.SchoolID) ([]domain.Teacher, error) {
+func (r *Repository) ListTeachers(_ context.Context, schoolID domain.SchoolID, page repository.PageRequest) (repository.Page[domain.Teacher], error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -238,15 +317,75 @@ func (r *Repository) ListTeachers(schoolID domain.SchoolID) ([]domain.Teacher, e
 	}
 
 	sort.Slice(teachers, func(i, j int) bool {
-		return teachers[i].CreatedAt.Before(teachers[j].CreatedAt)
+		return createdBefore(teachers[i].CreatedAt, teachers[i].ID, teachers[j].CreatedAt, teachers[j].ID)
 	})
 
-	return teachers, nil
+	return repository.Paginate(teachers, page, false, teacherPageKey)
+}
+
+func (r *Repository) UpdateSchool(_ context.Context, school *domain.School) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.schools[school.ID]; !ok {
+		return errors.New("school not found")
+	}
+	if _, ok := r.districts[school.DistrictID]; school.DistrictID != "" && !ok {
+		return errors.New("district not found")
+	}
+	r.schools[school.ID] = cloneSchool(*school)
+	return nil
+}
+
+func (r *Repository) UpdateTeacher(_ context.Context, teacher *domain.Teacher) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.teachers[teacher.ID]; !ok {
+		return errors.New("teacher not found")
+	}
+	r.teachers[teacher.ID] = cloneTeacher(*teacher)
+	return nil
+}
+
+func (r *Repository) UpdateStudent(_ context.Context, student *domain.Student) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.students[student.ID]; !ok {
+		return errors.New("student not found")
+	}
+	r.students[student.ID] = cloneStudent(*student)
+	return nil
+}
+
+func (r *Repository) CreateStudents(_ context.Context, classID domain.ClassID, students []domain.Student) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.classes[classID]; !ok {
+		return errors.New("class not found")
+	}
+	for i, st := range students {
+		if _, exists := r.students[st.ID]; exists {
+			return errors.New("student already exists")
+		}
+		for _, other := range students[:i] {
+			if other.ID == st.ID {
+				return errors.New("student already exists")
+			}
+		}
+	}
+	for _, st := range students {
+		st.ClassID = classID
+		r.students[st.ID] = cloneStudent(st)
+	}
+	return nil
 }
 
 // TestRepository implementation.
 
-func (r *Repository) CreateTest(test *domain.Test, questions []domain.Question, studentIDs []domain.StudentID) error {
+func (r *Repository) CreateTest(_ context.Context, test *domain.Test, questions []domain.Question, studentIDs []domain.StudentID) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -289,7 +428,7 @@ func (r *Repository) CreateTest(test *domain.Test, questions []domain.Question,
 	return nil
 }
 
-func (r *Repository) UpdateTest(test *domain.Test) error {
+func (r *Repository) UpdateTest(_ context.Context, test *domain.Test) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -301,7 +440,43 @@ func (r *Repository) UpdateTest(test *domain.Test) error {
 	return nil
 }
 
-func (r *Repository) GetTest(id domain.TestID) (*domain.Test, error) {
+func (r *Repository) UpdateAssignments(_ context.Context, testID domain.TestID, add, remove []domain.StudentID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	test, ok := r.tests[testID]
+	if !ok {
+		return errors.New("test not found")
+	}
+	for _, studentID := range add {
+		if _, ok := r.students[studentID]; !ok {
+			return errors.New("student not found")
+		}
+	}
+
+	assigned := r.assignments[testID]
+	if assigned == nil {
+		assigned = make(map[domain.StudentID]struct{})
+		r.assignments[testID] = assigned
+	}
+	for _, studentID := range remove {
+		delete(assigned, studentID)
+		delete(r.studentTests[studentID], testID)
+	}
+	for _, studentID := range add {
+		assigned[studentID] = struct{}{}
+		if _, ok := r.studentTests[studentID]; !ok {
+			r.studentTests[studentID] = make(map[domain.TestID]struct{})
+		}
+		r.studentTests[studentID][testID] = struct{}{}
+	}
+
+	test.Reassign(add, remove)
+	r.tests[testID] = test
+	return nil
+}
+
+func (r *Repository) GetTest(_ context.Context, id domain.TestID) (*domain.Test, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -313,7 +488,7 @@ func (r *Repository) GetTest(id domain.TestID) (*domain.Test, error) {
 	return &t, nil
 }
 
-func (r *Repository) ListTestsByTeacher(teacherID domain.TeacherID) ([]domain.Test, error) {
+func (r *Repository) ListTestsByTeacher(_ context.Context, teacherID domain.TeacherID, page repository.PageRequest) (repository.Page[domain.Test], error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -325,19 +500,19 @@ func (r *Repository) ListTestsByTeacher(teacherID domain.TeacherID) ([]domain.Te
 	}
 
 	sort.Slice(tests, func(i, j int) bool {
-		return tests[i].CreatedAt.Before(tests[j].CreatedAt)
+		return createdBefore(tests[i].CreatedAt, tests[i].ID, tests[j].CreatedAt, tests[j].ID)
 	})
 
-	return tests, nil
+	return repository.Paginate(tests, page, false, testPageKey)
 }
 
-func (r *Repository) ListTestsForStudent(studentID domain.StudentID) ([]domain.Test, error) {
+func (r *Repository) ListTestsForStudent(_ context.Context, studentID domain.StudentID, page repository.PageRequest) (repository.Page[domain.Test], error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	testRefs, ok := r.studentTests[studentID]
 	if !ok {
-		return []domain.Test{}, nil
+		return repository.Page[domain.Test]{Items: []domain.Test{}}, nil
 	}
 
 	tests := make([]domain.Test, 0, len(testRefs))
@@ -348,13 +523,32 @@ func (r *Repository) ListTestsForStudent(studentID domain.StudentID) ([]domain.T
 	}
 
 	sort.Slice(tests, func(i, j int) bool {
-		return tests[i].CreatedAt.Before(tests[j].CreatedAt)
+		return createdBefore(tests[i].CreatedAt, tests[i].ID, tests[j].CreatedAt, tests[j].ID)
+	})
+
+	return repository.Paginate(tests, page, false, testPageKey)
+}
+
+func (r *Repository) ListTestsInWindow(_ context.Context, from, to time.Time) ([]domain.Test, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tests := make([]domain.Test, 0)
+	for _, test := range r.tests {
+		opens, closes, ok := test.Window()
+		if ok && opens.Before(to) && from.Before(closes) {
+			tests = append(tests, cloneTest(test))
+		}
+	}
+
+	sort.Slice(tests, func(i, j int) bool {
+		return createdBefore(tests[i].CreatedAt, tests[i].ID, tests[j].CreatedAt, tests[j].ID)
 	})
 
 	return tests, nil
 }
 
-func (r *Repository) ListQuestions(testID domain.TestID) ([]domain.Question, error) {
+func (r *Repository) ListQuestions(_ context.Context, testID domain.TestID) ([]domain.Question, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -371,13 +565,65 @@ func (r *Repository) ListQuestions(testID domain.TestID) ([]domain.Question, err
 	}
 
 	sort.Slice(questions, func(i, j int) bool {
-		return questions[i].Sequence < questions[j].Sequence
+		if questions[i].Sequence != questions[j].Sequence {
+			return questions[i].Sequence < questions[j].Sequence
+		}
+		return questions[i].ID < questions[j].ID
 	})
 
 	return questions, nil
 }
 
-func (r *Repository) IsStudentAssigned(testID domain.TestID, studentID domain.StudentID) (bool, error) {
+func (r *Repository) UpdateQuestion(_ context.Context, question *domain.Question) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.questions[question.ID]
+	if !ok || existing.TestID != question.TestID {
+		return errors.New("question not found")
+	}
+	r.questions[question.ID] = cloneQuestion(*question)
+	return nil
+}
+
+func (r *Repository) ReorderQuestions(_ context.Context, testID domain.TestID, sequences map[domain.QuestionID]int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for questionID := range sequences {
+		if q, ok := r.questions[questionID]; !ok || q.TestID != testID {
+			return errors.New("question not found")
+		}
+	}
+	for questionID, sequence := range sequences {
+		q := r.questions[questionID]
+		q.Sequence = sequence
+		r.questions[questionID] = q
+	}
+	return nil
+}
+
+func (r *Repository) GetQuestion(_ context.Context, testID domain.TestID, questionID domain.QuestionID) (*domain.Question, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	q, ok := r.questions[questionID]
+	if !ok || q.TestID != testID {
+		return nil, nil
+	}
+	cloned := cloneQuestion(q)
+	return &cloned, nil
+}
+
+func (r *Repository) HasQuestion(_ context.Context, testID domain.TestID, questionID domain.QuestionID) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	q, ok := r.questions[questionID]
+	return ok && q.TestID == testID, nil
+}
+
+func (r *Repository) IsStudentAssigned(_ context.Context, testID domain.TestID, studentID domain.StudentID) (bool, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -392,12 +638,16 @@ func (r *Repository) IsStudentAssigned(testID domain.TestID, studentID domain.St
 
 // AnswerRepository implementation.
 
-func (r *Repository) UpsertAnswer(answer *domain.Answer) error {
+func (r *Repository) UpsertAnswer(_ context.Context, answer *domain.Answer) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	key := answerKey(answer.TestID, answer.QuestionID, answer.StudentID)
-	r.answers[answer.ID] = cloneAnswer(*answer)
+	var prev *domain.Answer
+	if stored, ok := r.answers[answer.ID]; ok {
+		prev = &stored
+	}
+	r.answers[answer.ID] = cloneAnswer(answer.Revise(prev))
 	r.answerIndex[key] = answer.ID
 
 	if _, ok := r.answersByTest[answer.TestID]; !ok {
@@ -408,7 +658,7 @@ func (r *Repository) UpsertAnswer(answer *domain.Answer) error {
 	return nil
 }
 
-func (r *Repository) GetAnswer(testID domain.TestID, questionID domain.QuestionID, studentID domain.StudentID) (*domain.Answer, error) {
+func (r *Repository) GetAnswer(_ context.Context, testID domain.TestID, questionID domain.QuestionID, studentID domain.StudentID) (*domain.Answer, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -426,7 +676,16 @@ func (r *Repository) GetAnswer(testID domain.TestID, questionID domain.QuestionI
 	return &cloned, nil
 }
 
-func (r *Repository) ListAnswers(testID domain.TestID, studentID domain.StudentID) ([]domain.Answer, error) {
+// HasAnswer reports whether the answer is stored here.
+func (r *Repository) HasAnswer(_ context.Context, id domain.AnswerID) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.answers[id]
+	return ok, nil
+}
+
+func (r *Repository) ListAnswers(_ context.Context, testID domain.TestID, studentID domain.StudentID) ([]domain.Answer, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -443,19 +702,38 @@ func (r *Repository) ListAnswers(testID domain.TestID, studentID domain.StudentI
 	}
 
 	sort.Slice(answers, func(i, j int) bool {
-		return answers[i].CreatedAt.Before(answers[j].CreatedAt)
+		return createdBefore(answers[i].CreatedAt, answers[i].ID, answers[j].CreatedAt, answers[j].ID)
 	})
 
 	return answers, nil
 }
 
-func (r *Repository) ListAnswersByTest(testID domain.TestID) ([]domain.Answer, error) {
+func (r *Repository) DeleteAnswer(_ context.Context, testID domain.TestID, questionID domain.QuestionID, studentID domain.StudentID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := answerKey(testID, questionID, studentID)
+	id, ok := r.answerIndex[key]
+	if !ok {
+		return nil
+	}
+	delete(r.answerIndex, key)
+	delete(r.answers, id)
+	delete(r.answersByTest[testID], id)
+	if resultID, ok := r.resultByAnswer[id]; ok {
+		delete(r.results, resultID)
+		delete(r.resultByAnswer, id)
+	}
+	return nil
+}
+
+func (r *Repository) ListAnswersByTest(_ context.Context, testID domain.TestID, page repository.PageRequest) (repository.Page[domain.Answer], error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	ids, ok := r.answersByTest[testID]
 	if !ok {
-		return []domain.Answer{}, nil
+		return repository.Page[domain.Answer]{Items: []domain.Answer{}}, nil
 	}
 
 	answers := make([]domain.Answer, 0, len(ids))
@@ -466,15 +744,15 @@ func (r *Repository) ListAnswersByTest(testID domain.TestID) ([]domain.Answer, e
 	}
 
 	sort.Slice(answers, func(i, j int) bool {
-		return answers[i].CreatedAt.Before(answers[j].CreatedAt)
+		return createdBefore(answers[i].CreatedAt, answers[i].ID, answers[j].CreatedAt, answers[j].ID)
 	})
 
-	return answers, nil
+	return repository.Paginate(answers, page, false, answerPageKey)
 }
 
 // ResultRepository implementation.
 
-func (r *Repository) SaveResult(result *domain.Result) error {
+func (r *Repository) SaveResult(_ context.Context, result *domain.Result) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -484,7 +762,23 @@ func (r *Repository) SaveResult(result *domain.Result) error {
 	return nil
 }
 
-func (r *Repository) GetResult(answerID domain.AnswerID) (*domain.Result, error) {
+func (r *Repository) SaveResults(_ context.Context, results []domain.Result) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, result := range results {
+		if _, ok := r.answers[result.AnswerID]; !ok {
+			return errors.New("answer not found")
+		}
+	}
+	for _, result := range results {
+		r.results[result.ID] = cloneResult(result)
+		r.resultByAnswer[result.AnswerID] = result.ID
+	}
+	return nil
+}
+
+func (r *Repository) GetResult(_ context.Context, answerID domain.AnswerID) (*domain.Result, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -501,13 +795,13 @@ func (r *Repository) GetResult(answerID domain.AnswerID) (*domain.Result, error)
 	return &cloned, nil
 }
 
-func (r *Repository) ListResultsByTest(testID domain.TestID) ([]domain.Result, error) {
+func (r *Repository) ListResultsByTest(_ context.Context, testID domain.TestID, page repository.PageRequest) (repository.Page[domain.Result], error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	answerIDs, ok := r.answersByTest[testID]
 	if !ok {
-		return []domain.Result{}, nil
+		return repository.Page[domain.Result]{Items: []domain.Result{}}, nil
 	}
 
 	results := make([]domain.Result, 0)
@@ -520,13 +814,13 @@ func (r *Repository) ListResultsByTest(testID domain.TestID) ([]domain.Result, e
 	}
 
 	sort.Slice(results, func(i, j int) bool {
-		return results[i].CreatedAt.Before(results[j].CreatedAt)
+		return createdBefore(results[i].CreatedAt, results[i].ID, results[j].CreatedAt, results[j].ID)
 	})
 
-	return results, nil
+	return repository.Paginate(results, page, false, resultPageKey)
 }
 
-func (r *Repository) ListResultsByStudent(testID domain.TestID, studentID domain.StudentID) ([]domain.Result, error) {
+func (r *Repository) ListResultsByStudent(_ context.Context, testID domain.TestID, studentID domain.StudentID) ([]domain.Result, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -549,134 +843,1117 @@ func (r *Repository) ListResultsByStudent(testID domain.TestID, studentID domain
 	}
 
 	sort.Slice(results, func(i, j int) bool {
-		return results[i].CreatedAt.Before(results[j].CreatedAt)
+		return createdBefore(results[i].CreatedAt, results[i].ID, results[j].CreatedAt, results[j].ID)
 	})
 
 	return results, nil
 }
 
-// Helpers.
-
-func answerKey(testID domain.TestID, questionID domain.QuestionID, studentID domain.StudentID) string {
-	return string(testID) + "|" + string(questionID) + "|" + string(studentID)
-}
+// NotificationRepository implementation.
 
-func cloneSchool(in domain.School) domain.School    { return in }
-func cloneGrade(in domain.Grade) domain.Grade       { return in }
-func cloneClass(in domain.Class) domain.Class       { return in }
-func cloneTeacher(in domain.Teacher) domain.Teacher { return in }
-func cloneStudent(in domain.Student) domain.Student { return in }
+func (r *Repository) SaveNotification(_ context.Context, notification *domain.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-func cloneTest(in domain.Test) domain.Test {
-	clone := in
-	clone.AssignedTo = append([]domain.StudentID(nil), in.AssignedTo...)
-	return clone
+	key := inboxKey(notification.Role, notification.RecipientID)
+	if _, exists := r.notifications[notification.ID]; !exists {
+		r.inbox[key] = append(r.inbox[key], notification.ID)
+	}
+	r.notifications[notification.ID] = cloneNotification(*notification)
+	return nil
 }
 
-func cloneQuestion(in domain.Question) domain.Question { return in }
-func cloneAnswer(in domain.Answer) domain.Answer       { return in }
-func cloneResult(in domain.Result) domain.Result       { return in }
-
-// ExportState renders a snapshot suitable for persistence.
-func (r *Repository) ExportState() State {
+func (r *Repository) ListNotifications(_ context.Context, role domain.Role, recipientID string, page repository.PageRequest) (repository.Page[domain.Notification], error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	state := State{
-		Schools:     make([]domain.School, 0, len(r.schools)),
-		Grades:      make([]domain.Grade, 0, len(r.grades)),
-		Classes:     make([]domain.Class, 0, len(r.classes)),
-		Teachers:    make([]domain.Teacher, 0, len(r.teachers)),
-		Students:    make([]domain.Student, 0, len(r.students)),
-		Tests:       make([]domain.Test, 0, len(r.tests)),
-		Questions:   make([]domain.Question, 0, len(r.questions)),
-		Assignments: make(map[string][]domain.StudentID, len(r.assignments)),
-		Answers:     make([]domain.Answer, 0, len(r.answers)),
-		Results:     make([]domain.Result, 0, len(r.results)),
+	ids := r.inbox[inboxKey(role, recipientID)]
+	notifications := make([]domain.Notification, 0, len(ids))
+	for _, id := range ids {
+		if n, ok := r.notifications[id]; ok {
+			notifications = append(notifications, cloneNotification(n))
+		}
 	}
 
-	for _, s := range r.schools {
-		state.Schools = append(state.Schools, cloneSchool(s))
-	}
-	sort.Slice(state.Schools, func(i, j int) bool {
-		return state.Schools[i].CreatedAt.Before(state.Schools[j].CreatedAt)
+	sort.Slice(notifications, func(i, j int) bool {
+		return createdBefore(notifications[j].CreatedAt, notifications[j].ID, notifications[i].CreatedAt, notifications[i].ID)
 	})
 
-	for _, g := range r.grades {
-		state.Grades = append(state.Grades, cloneGrade(g))
-	}
-	sort.Slice(state.Grades, func(i, j int) bool {
-		return state.Grades[i].CreatedAt.Before(state.Grades[j].CreatedAt)
-	})
+	return repository.Paginate(notifications, page, true, notificationPageKey)
+}
 
-	for _, c := range r.classes {
-		state.Classes = append(state.Classes, cloneClass(c))
-	}
-	sort.Slice(state.Classes, func(i, j int) bool {
-		return state.Classes[i].CreatedAt.Before(state.Classes[j].CreatedAt)
-	})
+func (r *Repository) MarkNotificationsRead(_ context.Context, role domain.Role, recipientID string, ids []domain.NotificationID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	for _, t := range r.teachers {
-		state.Teachers = append(state.Teachers, cloneTeacher(t))
+	targets := ids
+	if len(targets) == 0 {
+		targets = r.inbox[inboxKey(role, recipientID)]
 	}
-	sort.Slice(state.Teachers, func(i, j int) bool {
-		return state.Teachers[i].CreatedAt.Before(state.Teachers[j].CreatedAt)
-	})
 
-	for _, st := range r.students {
-		state.Students = append(state.Students, cloneStudent(st))
+	for _, id := range targets {
+		n, ok := r.notifications[id]
+		if !ok || n.Role != role || n.RecipientID != recipientID || n.ReadAt != nil {
+			continue
+		}
+		readAt := at
+		n.ReadAt = &readAt
+		r.notifications[id] = n
 	}
-	sort.Slice(state.Students, func(i, j int) bool {
-		return state.Students[i].CreatedAt.Before(state.Students[j].CreatedAt)
-	})
+	return nil
+}
 
-	for _, test := range r.tests {
-		state.Tests = append(state.Tests, cloneTest(test))
+// DigestRepository implementation.
+
+func (r *Repository) QueueDigestItem(_ context.Context, item *domain.DigestItem) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.digests[item.ID] = *item
+	return nil
+}
+
+func (r *Repository) ListDigestItems(_ context.Context) ([]domain.DigestItem, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	items := make([]domain.DigestItem, 0, len(r.digests))
+	for _, item := range r.digests {
+		items = append(items, item)
 	}
-	sort.Slice(state.Tests, func(i, j int) bool {
-		return state.Tests[i].CreatedAt.Before(state.Tests[j].CreatedAt)
+	sort.Slice(items, func(i, j int) bool {
+		return createdBefore(items[i].OccurredAt, items[i].ID, items[j].OccurredAt, items[j].ID)
 	})
+	return items, nil
+}
 
-	for _, q := range r.questions {
-		state.Questions = append(state.Questions, cloneQuestion(q))
+func (r *Repository) DeleteDigestItems(_ context.Context, ids []domain.DigestItemID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, id := range ids {
+		delete(r.digests, id)
 	}
-	sort.Slice(state.Questions, func(i, j int) bool {
-		if state.Questions[i].TestID == state.Questions[j].TestID {
-			return state.Questions[i].Sequence < state.Questions[j].Sequence
-		}
-		return state.Questions[i].CreatedAt.Before(state.Questions[j].CreatedAt)
-	})
+	return nil
+}
 
-	for testID, students := range r.assignments {
-		list := make([]domain.StudentID, 0, len(students))
-		for sid := range students {
-			list = append(list, sid)
-		}
-		sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
-		state.Assignments[string(testID)] = list
+// QuestionCommentRepository implementation.
+
+func (r *Repository) SaveQuestionComment(_ context.Context, comment *domain.QuestionComment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.questions[comment.QuestionID]; !ok {
+		return errors.New("question not found")
 	}
+	r.comments[comment.ID] = cloneQuestionComment(*comment)
+	return nil
+}
+
+func (r *Repository) GetQuestionComment(_ context.Context, id domain.QuestionCommentID) (*domain.QuestionComment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
-	for _, ans := range r.answers {
-		state.Answers = append(state.Answers, cloneAnswer(ans))
+	comment, ok := r.comments[id]
+	if !ok {
+		return nil, nil
 	}
-	sort.Slice(state.Answers, func(i, j int) bool {
-		return state.Answers[i].CreatedAt.Before(state.Answers[j].CreatedAt)
-	})
+	c := cloneQuestionComment(comment)
+	return &c, nil
+}
+
+func (r *Repository) ListQuestionComments(_ context.Context, testID domain.TestID, questionID domain.QuestionID) ([]domain.QuestionComment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
-	for _, res := range r.results {
-		state.Results = append(state.Results, cloneResult(res))
+	comments := make([]domain.QuestionComment, 0)
+	for _, c := range r.comments {
+		if c.TestID == testID && c.QuestionID == questionID {
+			comments = append(comments, cloneQuestionComment(c))
+		}
 	}
-	sort.Slice(state.Results, func(i, j int) bool {
-		return state.Results[i].CreatedAt.Before(state.Results[j].CreatedAt)
+
+	sort.Slice(comments, func(i, j int) bool {
+		return createdBefore(comments[i].CreatedAt, comments[i].ID, comments[j].CreatedAt, comments[j].ID)
 	})
 
-	return state
+	return comments, nil
 }
 
-func (r *Repository) applySeed(seed SeedData) {
-	for _, s := range seed.Schools {
-		r.schools[s.ID] = cloneSchool(s)
-	}
+// AnswerCommentRepository implementation.
+
+func (r *Repository) SaveAnswerComment(_ context.Context, comment *domain.AnswerComment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tests[comment.TestID]; !ok {
+		return errors.New("test not found")
+	}
+	r.answerComments[comment.ID] = cloneAnswerComment(*comment)
+	return nil
+}
+
+func (r *Repository) GetAnswerComment(_ context.Context, id domain.AnswerCommentID) (*domain.AnswerComment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	comment, ok := r.answerComments[id]
+	if !ok {
+		return nil, nil
+	}
+	c := cloneAnswerComment(comment)
+	return &c, nil
+}
+
+func (r *Repository) ListAnswerComments(_ context.Context, testID domain.TestID, answerID domain.AnswerID) ([]domain.AnswerComment, error) {
+	return r.listAnswerComments(func(c domain.AnswerComment) bool {
+		return c.TestID == testID && c.AnswerID == answerID
+	}), nil
+}
+
+func (r *Repository) ListAnswerCommentsByStudent(_ context.Context, studentID domain.StudentID) ([]domain.AnswerComment, error) {
+	return r.listAnswerComments(func(c domain.AnswerComment) bool {
+		return c.StudentID == studentID
+	}), nil
+}
+
+func (r *Repository) listAnswerComments(match func(domain.AnswerComment) bool) []domain.AnswerComment {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	comments := make([]domain.AnswerComment, 0)
+	for _, c := range r.answerComments {
+		if match(c) {
+			comments = append(comments, cloneAnswerComment(c))
+		}
+	}
+	sortAnswerComments(comments)
+	return comments
+}
+
+// TestSessionRepository implementation.
+
+func (r *Repository) GetTestSession(_ context.Context, testID domain.TestID, studentID domain.StudentID) (*domain.TestSession, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	session, ok := r.sessions[sessionKey(testID, studentID)]
+	if !ok {
+		return nil, nil
+	}
+	s := cloneTestSession(session)
+	return &s, nil
+}
+
+func (r *Repository) SaveTestSession(_ context.Context, session *domain.TestSession) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tests[session.TestID]; !ok {
+		return errors.New("test not found")
+	}
+	r.sessions[sessionKey(session.TestID, session.StudentID)] = cloneTestSession(*session)
+	return nil
+}
+
+// SubmissionRepository implementation.
+
+func (r *Repository) GetSubmission(_ context.Context, testID domain.TestID, studentID domain.StudentID) (*domain.Submission, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	submission, ok := r.submissions[sessionKey(testID, studentID)]
+	if !ok {
+		return nil, nil
+	}
+	return &submission, nil
+}
+
+func (r *Repository) ListSubmissionsByTest(_ context.Context, testID domain.TestID) ([]domain.Submission, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	submissions := make([]domain.Submission, 0)
+	for _, s := range r.submissions {
+		if s.TestID == testID {
+			submissions = append(submissions, s)
+		}
+	}
+	sortSubmissions(submissions)
+	return submissions, nil
+}
+
+func (r *Repository) SaveSubmission(_ context.Context, submission *domain.Submission) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tests[submission.TestID]; !ok {
+		return errors.New("test not found")
+	}
+	r.submissions[sessionKey(submission.TestID, submission.StudentID)] = *submission
+	return nil
+}
+
+// RubricRepository implementation.
+
+func (r *Repository) SaveRubrics(_ context.Context, rubrics []domain.Rubric, templates []domain.FeedbackTemplate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, rubric := range rubrics {
+		if _, ok := r.teachers[rubric.TeacherID]; !ok {
+			return errors.New("teacher not found")
+		}
+	}
+	for _, tmpl := range templates {
+		if _, ok := r.teachers[tmpl.TeacherID]; !ok {
+			return errors.New("teacher not found")
+		}
+	}
+	for _, rubric := range rubrics {
+		r.rubrics[rubric.ID] = cloneRubric(rubric)
+	}
+	for _, tmpl := range templates {
+		r.templates[tmpl.ID] = tmpl
+	}
+	return nil
+}
+
+func (r *Repository) GetRubric(_ context.Context, id domain.RubricID) (*domain.Rubric, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rubric, ok := r.rubrics[id]
+	if !ok {
+		return nil, nil
+	}
+	clone := cloneRubric(rubric)
+	return &clone, nil
+}
+
+func (r *Repository) ListRubrics(_ context.Context, teacherID domain.TeacherID, page repository.PageRequest) (repository.Page[domain.Rubric], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rubrics := make([]domain.Rubric, 0)
+	for _, rubric := range r.rubrics {
+		if rubric.TeacherID == teacherID {
+			rubrics = append(rubrics, cloneRubric(rubric))
+		}
+	}
+
+	sort.Slice(rubrics, func(i, j int) bool {
+		return createdBefore(rubrics[i].CreatedAt, rubrics[i].ID, rubrics[j].CreatedAt, rubrics[j].ID)
+	})
+
+	return repository.Paginate(rubrics, page, false, rubricPageKey)
+}
+
+func (r *Repository) ListFeedbackTemplates(_ context.Context, teacherID domain.TeacherID, page repository.PageRequest) (repository.Page[domain.FeedbackTemplate], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	templates := make([]domain.FeedbackTemplate, 0)
+	for _, tmpl := range r.templates {
+		if tmpl.TeacherID == teacherID {
+			templates = append(templates, tmpl)
+		}
+	}
+
+	sort.Slice(templates, func(i, j int) bool {
+		return createdBefore(templates[i].CreatedAt, templates[i].ID, templates[j].CreatedAt, templates[j].ID)
+	})
+
+	return repository.Paginate(templates, page, false, feedbackTemplatePageKey)
+}
+
+// DelegationRepository implementation.
+
+func (r *Repository) SaveDelegation(_ context.Context, delegation *domain.Delegation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.teachers[delegation.TeacherID]; !ok {
+		return errors.New("teacher not found")
+	}
+	if _, ok := r.teachers[delegation.DelegateID]; !ok {
+		return errors.New("teacher not found")
+	}
+	r.delegations[delegation.ID] = *delegation
+	return nil
+}
+
+func (r *Repository) GetDelegation(_ context.Context, id domain.DelegationID) (*domain.Delegation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	delegation, ok := r.delegations[id]
+	if !ok {
+		return nil, nil
+	}
+	return &delegation, nil
+}
+
+func (r *Repository) ListDelegationsByTeacher(_ context.Context, teacherID domain.TeacherID, page repository.PageRequest) (repository.Page[domain.Delegation], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	delegations := make([]domain.Delegation, 0)
+	for _, d := range r.delegations {
+		if d.TeacherID == teacherID {
+			delegations = append(delegations, d)
+		}
+	}
+
+	sort.Slice(delegations, func(i, j int) bool {
+		return createdBefore(delegations[i].CreatedAt, delegations[i].ID, delegations[j].CreatedAt, delegations[j].ID)
+	})
+
+	return repository.Paginate(delegations, page, false, delegationPageKey)
+}
+
+func (r *Repository) ListDelegationsForDelegate(_ context.Context, delegateID domain.TeacherID) ([]domain.Delegation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	delegations := make([]domain.Delegation, 0)
+	for _, d := range r.delegations {
+		if d.DelegateID == delegateID {
+			delegations = append(delegations, d)
+		}
+	}
+
+	sort.Slice(delegations, func(i, j int) bool {
+		return createdBefore(delegations[i].CreatedAt, delegations[i].ID, delegations[j].CreatedAt, delegations[j].ID)
+	})
+
+	return delegations, nil
+}
+
+func (r *Repository) DeleteDelegation(_ context.Context, id domain.DelegationID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.delegations, id)
+	return nil
+}
+
+func (r *Repository) DeleteExpiredDelegations(_ context.Context, now time.Time) ([]domain.Delegation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	expired := make([]domain.Delegation, 0)
+	for id, d := range r.delegations {
+		if !d.Active(now) {
+			expired = append(expired, d)
+			delete(r.delegations, id)
+		}
+	}
+
+	sort.Slice(expired, func(i, j int) bool {
+		return createdBefore(expired[i].CreatedAt, expired[i].ID, expired[j].CreatedAt, expired[j].ID)
+	})
+
+	return expired, nil
+}
+
+// DeviceSessionRepository implementation.
+
+func (r *Repository) SaveDeviceSession(_ context.Context, session *domain.DeviceSession) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.students[session.StudentID]; !ok {
+		return errors.New("student not found")
+	}
+	r.devices[session.ID] = cloneDeviceSession(*session)
+	return nil
+}
+
+func (r *Repository) GetDeviceSession(_ context.Context, id domain.DeviceSessionID) (*domain.DeviceSession, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	session, ok := r.devices[id]
+	if !ok {
+		return nil, nil
+	}
+	clone := cloneDeviceSession(session)
+	return &clone, nil
+}
+
+func (r *Repository) ListDeviceSessions(_ context.Context, studentID domain.StudentID) ([]domain.DeviceSession, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sessions := make([]domain.DeviceSession, 0)
+	for _, d := range r.devices {
+		if d.StudentID == studentID {
+			sessions = append(sessions, cloneDeviceSession(d))
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return createdBefore(sessions[i].CreatedAt, sessions[i].ID, sessions[j].CreatedAt, sessions[j].ID)
+	})
+
+	return sessions, nil
+}
+
+func (r *Repository) DeleteExpiredDeviceSessions(_ context.Context, now time.Time) ([]domain.DeviceSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	expired := make([]domain.DeviceSession, 0)
+	for id, d := range r.devices {
+		if !now.Before(d.ExpiresAt) {
+			expired = append(expired, d)
+			delete(r.devices, id)
+		}
+	}
+
+	sort.Slice(expired, func(i, j int) bool {
+		return createdBefore(expired[i].CreatedAt, expired[i].ID, expired[j].CreatedAt, expired[j].ID)
+	})
+
+	return expired, nil
+}
+
+// QuestionBankRepository implementation.
+
+func (r *Repository) SaveBankQuestion(_ context.Context, question *domain.BankQuestion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.teachers[question.TeacherID]; !ok {
+		return errors.New("teacher not found")
+	}
+	r.bank[question.ID] = cloneBankQuestion(*question)
+	return nil
+}
+
+func (r *Repository) GetBankQuestion(_ context.Context, id domain.BankQuestionID) (*domain.BankQuestion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	question, ok := r.bank[id]
+	if !ok {
+		return nil, nil
+	}
+	clone := cloneBankQuestion(question)
+	return &clone, nil
+}
+
+func (r *Repository) ListBankQuestions(_ context.Context, teacherID domain.TeacherID, page repository.PageRequest) (repository.Page[domain.BankQuestion], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	questions := make([]domain.BankQuestion, 0)
+	for _, q := range r.bank {
+		if q.TeacherID == teacherID {
+			questions = append(questions, cloneBankQuestion(q))
+		}
+	}
+
+	sort.Slice(questions, func(i, j int) bool {
+		return createdBefore(questions[i].CreatedAt, questions[i].ID, questions[j].CreatedAt, questions[j].ID)
+	})
+
+	return repository.Paginate(questions, page, false, bankQuestionPageKey)
+}
+
+func (r *Repository) DeleteBankQuestion(_ context.Context, id domain.BankQuestionID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.bank, id)
+	return nil
+}
+
+// DistrictRepository implementation.
+
+func (r *Repository) SaveDistrict(_ context.Context, district *domain.District) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.districts[district.ID] = *district
+	return nil
+}
+
+func (r *Repository) GetDistrict(_ context.Context, id domain.DistrictID) (*domain.District, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	district, ok := r.districts[id]
+	if !ok {
+		return nil, nil
+	}
+	return &district, nil
+}
+
+func (r *Repository) ListDistricts(_ context.Context, page repository.PageRequest) (repository.Page[domain.District], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	districts := make([]domain.District, 0, len(r.districts))
+	for _, d := range r.districts {
+		districts = append(districts, d)
+	}
+
+	sort.Slice(districts, func(i, j int) bool {
+		return createdBefore(districts[i].CreatedAt, districts[i].ID, districts[j].CreatedAt, districts[j].ID)
+	})
+
+	return repository.Paginate(districts, page, false, districtPageKey)
+}
+
+func (r *Repository) ListSchoolsByDistrict(_ context.Context, districtID domain.DistrictID, page repository.PageRequest) (repository.Page[domain.School], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schools := make([]domain.School, 0)
+	for _, school := range r.schools {
+		if school.DistrictID == districtID {
+			schools = append(schools, cloneSchool(school))
+		}
+	}
+
+	sort.Slice(schools, func(i, j int) bool {
+		return createdBefore(schools[i].CreatedAt, schools[i].ID, schools[j].CreatedAt, schools[j].ID)
+	})
+
+	return repository.Paginate(schools, page, false, schoolPageKey)
+}
+
+func (r *Repository) SaveDistrictStaff(_ context.Context, staff *domain.DistrictStaff) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.districts[staff.DistrictID]; !ok {
+		return errors.New("district not found")
+	}
+	r.districtStaff[staff.ID] = cloneDistrictStaff(*staff)
+	return nil
+}
+
+func (r *Repository) GetDistrictStaff(_ context.Context, id domain.DistrictStaffID) (*domain.DistrictStaff, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	staff, ok := r.districtStaff[id]
+	if !ok {
+		return nil, nil
+	}
+	clone := cloneDistrictStaff(staff)
+	return &clone, nil
+}
+
+// AuditRepository implementation.
+
+func (r *Repository) SaveAuditEntry(_ context.Context, entry *domain.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.audit[entry.ID] = cloneAuditEntry(*entry)
+	return nil
+}
+
+func (r *Repository) ListAuditEntries(_ context.Context, filter repository.AuditFilter, page repository.PageRequest) (repository.Page[domain.AuditEntry], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]domain.AuditEntry, 0)
+	for _, e := range r.audit {
+		if filter.Match(e) {
+			entries = append(entries, cloneAuditEntry(e))
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return createdBefore(entries[j].CreatedAt, entries[j].ID, entries[i].CreatedAt, entries[i].ID)
+	})
+
+	return repository.Paginate(entries, page, true, auditEntryPageKey)
+}
+
+// Page keys.
+
+func schoolPageKey(v domain.School) (time.Time, string) {
+	return v.CreatedAt, string(v.ID)
+}
+
+func gradePageKey(v domain.Grade) (time.Time, string) {
+	return v.CreatedAt, string(v.ID)
+}
+
+func classPageKey(v domain.Class) (time.Time, string) {
+	return v.CreatedAt, string(v.ID)
+}
+
+func studentPageKey(v domain.Student) (time.Time, string) {
+	return v.CreatedAt, string(v.ID)
+}
+
+func teacherPageKey(v domain.Teacher) (time.Time, string) {
+	return v.CreatedAt, string(v.ID)
+}
+
+func testPageKey(v domain.Test) (time.Time, string) {
+	return v.CreatedAt, string(v.ID)
+}
+
+func answerPageKey(v domain.Answer) (time.Time, string) {
+	return v.CreatedAt, string(v.ID)
+}
+
+func resultPageKey(v domain.Result) (time.Time, string) {
+	return v.CreatedAt, string(v.ID)
+}
+
+func notificationPageKey(v domain.Notification) (time.Time, string) {
+	return v.CreatedAt, string(v.ID)
+}
+
+func rubricPageKey(v domain.Rubric) (time.Time, string) {
+	return v.CreatedAt, string(v.ID)
+}
+
+func districtPageKey(v domain.District) (time.Time, string) {
+	return v.CreatedAt, string(v.ID)
+}
+
+func auditEntryPageKey(v domain.AuditEntry) (time.Time, string) {
+	return v.CreatedAt, string(v.ID)
+}
+
+func delegationPageKey(v domain.Delegation) (time.Time, string) {
+	return v.CreatedAt, string(v.ID)
+}
+
+func bankQuestionPageKey(v domain.BankQuestion) (time.Time, string) {
+	return v.CreatedAt, string(v.ID)
+}
+
+func feedbackTemplatePageKey(v domain.FeedbackTemplate) (time.Time, string) {
+	return v.CreatedAt, string(v.ID)
+}
+
+// Helpers.
+
+func inboxKey(role domain.Role, recipientID string) string {
+	return string(role) + "|" + recipientID
+}
+
+func answerKey(testID domain.TestID, questionID domain.QuestionID, studentID domain.StudentID) string {
+	return string(testID) + "|" + string(questionID) + "|" + string(studentID)
+}
+
+// createdBefore orders records oldest first and breaks ties on ID, so lists
+// of records sharing a timestamp, as seed data does, come back in the same
+// order on every call and can be paged through with a cursor.
+func createdBefore[ID ~string](ti time.Time, idi ID, tj time.Time, idj ID) bool {
+	if !ti.Equal(tj) {
+		return ti.Before(tj)
+	}
+	return idi < idj
+}
+
+func sessionKey(testID domain.TestID, studentID domain.StudentID) string {
+	return string(testID) + "|" + string(studentID)
+}
+
+// sortSubmissions orders submissions by when they were made.
+func sortSubmissions(submissions []domain.Submission) {
+	sort.Slice(submissions, func(i, j int) bool {
+		a, b := submissions[i], submissions[j]
+		return createdBefore(a.SubmittedAt, sessionKey(a.TestID, a.StudentID), b.SubmittedAt, sessionKey(b.TestID, b.StudentID))
+	})
+}
+
+func cloneSchool(in domain.School) domain.School    { return in }
+func cloneGrade(in domain.Grade) domain.Grade       { return in }
+func cloneClass(in domain.Class) domain.Class       { return in }
+func cloneTeacher(in domain.Teacher) domain.Teacher { return in }
+func cloneStudent(in domain.Student) domain.Student { return in }
+
+func cloneAuditEntry(in domain.AuditEntry) domain.AuditEntry {
+	clone := in
+	if in.OldScore != nil {
+		old := *in.OldScore
+		clone.OldScore = &old
+	}
+	if in.NewScore != nil {
+		score := *in.NewScore
+		clone.NewScore = &score
+	}
+	if in.OldClosesAt != nil {
+		closes := *in.OldClosesAt
+		clone.OldClosesAt = &closes
+	}
+	if in.NewClosesAt != nil {
+		closes := *in.NewClosesAt
+		clone.NewClosesAt = &closes
+	}
+	return clone
+}
+
+func cloneDistrictStaff(in domain.DistrictStaff) domain.DistrictStaff {
+	clone := in
+	clone.ManagedSchools = append([]domain.SchoolID(nil), in.ManagedSchools...)
+	return clone
+}
+
+func cloneTest(in domain.Test) domain.Test {
+	clone := in
+	clone.AssignedTo = append([]domain.StudentID(nil), in.AssignedTo...)
+	clone.Sections = append([]domain.Section(nil), in.Sections...)
+	clone.Extensions = append([]domain.DeadlineExtension(nil), in.Extensions...)
+	if in.PassingScore != nil {
+		score := *in.PassingScore
+		clone.PassingScore = &score
+	}
+	if in.Curve != nil {
+		curve := *in.Curve
+		if in.Curve.Mapping != nil {
+			curve.Mapping = make(map[domain.Score]domain.Score, len(in.Curve.Mapping))
+			for from, to := range in.Curve.Mapping {
+				curve.Mapping[from] = to
+			}
+		}
+		clone.Curve = &curve
+	}
+	if in.GradingDeadline != nil {
+		deadline := *in.GradingDeadline
+		clone.GradingDeadline = &deadline
+	}
+	if in.OpensAt != nil {
+		opens := *in.OpensAt
+		clone.OpensAt = &opens
+	}
+	if in.ClosesAt != nil {
+		closes := *in.ClosesAt
+		clone.ClosesAt = &closes
+	}
+	if in.ResultsLink != nil {
+		link := *in.ResultsLink
+		clone.ResultsLink = &link
+	}
+	return clone
+}
+
+func cloneAnswer(in domain.Answer) domain.Answer {
+	out := in
+	out.Revisions = append([]domain.AnswerRevision(nil), in.Revisions...)
+	return out
+}
+
+func cloneQuestion(in domain.Question) domain.Question {
+	clone := in
+	clone.Choices = append([]domain.Choice(nil), in.Choices...)
+	if in.Translations != nil {
+		clone.Translations = make(map[string]domain.Translation, len(in.Translations))
+		for locale, tr := range in.Translations {
+			tr.Choices = append([]domain.Choice(nil), tr.Choices...)
+			clone.Translations[locale] = tr
+		}
+	}
+	return clone
+}
+
+func cloneResult(in domain.Result) domain.Result {
+	clone := in
+	if in.RawScore != nil {
+		raw := *in.RawScore
+		clone.RawScore = &raw
+	}
+	if in.Hold != nil {
+		hold := *in.Hold
+		hold.Reasons = append([]domain.HoldReason(nil), in.Hold.Reasons...)
+		clone.Hold = &hold
+	}
+	return clone
+}
+
+func cloneQuestionComment(in domain.QuestionComment) domain.QuestionComment {
+	clone := in
+	clone.Mentions = append([]domain.TeacherID(nil), in.Mentions...)
+	return clone
+}
+
+func cloneAnswerComment(in domain.AnswerComment) domain.AnswerComment {
+	clone := in
+	if in.ReadAt != nil {
+		read := *in.ReadAt
+		clone.ReadAt = &read
+	}
+	return clone
+}
+
+func sortAnswerComments(comments []domain.AnswerComment) {
+	sort.Slice(comments, func(i, j int) bool {
+		return createdBefore(comments[i].CreatedAt, comments[i].ID, comments[j].CreatedAt, comments[j].ID)
+	})
+}
+
+func cloneRubric(in domain.Rubric) domain.Rubric {
+	clone := in
+	clone.Criteria = append([]domain.RubricCriterion(nil), in.Criteria...)
+	return clone
+}
+
+func cloneDeviceSession(in domain.DeviceSession) domain.DeviceSession {
+	out := in
+	if in.RevokedAt != nil {
+		revokedAt := *in.RevokedAt
+		out.RevokedAt = &revokedAt
+	}
+	return out
+}
+
+func cloneBankQuestion(in domain.BankQuestion) domain.BankQuestion {
+	out := in
+	out.Choices = append([]domain.Choice(nil), in.Choices...)
+	out.Tags = append([]string(nil), in.Tags...)
+	return out
+}
+
+func cloneTestSession(in domain.TestSession) domain.TestSession {
+	clone := in
+	clone.Flagged = append([]domain.QuestionID(nil), in.Flagged...)
+	return clone
+}
+
+func cloneNotification(in domain.Notification) domain.Notification {
+	clone := in
+	if in.ReadAt != nil {
+		readAt := *in.ReadAt
+		clone.ReadAt = &readAt
+	}
+	return clone
+}
+
+// ExportState renders a snapshot suitable for persistence.
+func (r *Repository) ExportState() State {
+	return r.exportState(true)
+}
+
+// ExportStateWithoutAnswers is ExportState leaving out answers and results,
+// for stores that persist those per test.
+func (r *Repository) ExportStateWithoutAnswers() State {
+	return r.exportState(false)
+}
+
+func (r *Repository) exportState(withAnswers bool) State {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	state := State{
+		Schools:       make([]domain.School, 0, len(r.schools)),
+		Grades:        make([]domain.Grade, 0, len(r.grades)),
+		Classes:       make([]domain.Class, 0, len(r.classes)),
+		Teachers:      make([]domain.Teacher, 0, len(r.teachers)),
+		Students:      make([]domain.Student, 0, len(r.students)),
+		Tests:         make([]domain.Test, 0, len(r.tests)),
+		Questions:     make([]domain.Question, 0, len(r.questions)),
+		Assignments:   make(map[string][]domain.StudentID, len(r.assignments)),
+		Answers:       make([]domain.Answer, 0),
+		Results:       make([]domain.Result, 0),
+		Notifications: make([]domain.Notification, 0, len(r.notifications)),
+		Digests:       make([]domain.DigestItem, 0, len(r.digests)),
+		Comments:      make([]domain.QuestionComment, 0, len(r.comments)),
+		Feedback:      make([]domain.AnswerComment, 0, len(r.answerComments)),
+		Sessions:      make([]domain.TestSession, 0, len(r.sessions)),
+		Submissions:   make([]domain.Submission, 0, len(r.submissions)),
+		Rubrics:       make([]domain.Rubric, 0, len(r.rubrics)),
+		Templates:     make([]domain.FeedbackTemplate, 0, len(r.templates)),
+		Delegations:   make([]domain.Delegation, 0, len(r.delegations)),
+		Devices:       make([]domain.DeviceSession, 0, len(r.devices)),
+		BankQuestions: make([]domain.BankQuestion, 0, len(r.bank)),
+		Districts:     make([]domain.District, 0, len(r.districts)),
+		DistrictStaff: make([]domain.DistrictStaff, 0, len(r.districtStaff)),
+		Audit:         make([]domain.AuditEntry, 0, len(r.audit)),
+	}
+
+	for _, s := range r.schools {
+		state.Schools = append(state.Schools, cloneSchool(s))
+	}
+	sort.Slice(state.Schools, func(i, j int) bool {
+		return createdBefore(state.Schools[i].CreatedAt, state.Schools[i].ID, state.Schools[j].CreatedAt, state.Schools[j].ID)
+	})
+
+	for _, g := range r.grades {
+		state.Grades = append(state.Grades, cloneGrade(g))
+	}
+	sort.Slice(state.Grades, func(i, j int) bool {
+		return createdBefore(state.Grades[i].CreatedAt, state.Grades[i].ID, state.Grades[j].CreatedAt, state.Grades[j].ID)
+	})
+
+	for _, c := range r.classes {
+		state.Classes = append(state.Classes, cloneClass(c))
+	}
+	sort.Slice(state.Classes, func(i, j int) bool {
+		return createdBefore(state.Classes[i].CreatedAt, state.Classes[i].ID, state.Classes[j].CreatedAt, state.Classes[j].ID)
+	})
+
+	for _, t := range r.teachers {
+		state.Teachers = append(state.Teachers, cloneTeacher(t))
+	}
+	sort.Slice(state.Teachers, func(i, j int) bool {
+		return createdBefore(state.Teachers[i].CreatedAt, state.Teachers[i].ID, state.Teachers[j].CreatedAt, state.Teachers[j].ID)
+	})
+
+	for _, st := range r.students {
+		state.Students = append(state.Students, cloneStudent(st))
+	}
+	sort.Slice(state.Students, func(i, j int) bool {
+		return createdBefore(state.Students[i].CreatedAt, state.Students[i].ID, state.Students[j].CreatedAt, state.Students[j].ID)
+	})
+
+	for _, test := range r.tests {
+		state.Tests = append(state.Tests, cloneTest(test))
+	}
+	sort.Slice(state.Tests, func(i, j int) bool {
+		return createdBefore(state.Tests[i].CreatedAt, state.Tests[i].ID, state.Tests[j].CreatedAt, state.Tests[j].ID)
+	})
+
+	for _, q := range r.questions {
+		state.Questions = append(state.Questions, cloneQuestion(q))
+	}
+	sort.Slice(state.Questions, func(i, j int) bool {
+		qi, qj := state.Questions[i], state.Questions[j]
+		if !qi.CreatedAt.Equal(qj.CreatedAt) {
+			return qi.CreatedAt.Before(qj.CreatedAt)
+		}
+		if qi.TestID != qj.TestID {
+			return qi.TestID < qj.TestID
+		}
+		if qi.Sequence != qj.Sequence {
+			return qi.Sequence < qj.Sequence
+		}
+		return qi.ID < qj.ID
+	})
+
+	for testID, students := range r.assignments {
+		list := make([]domain.StudentID, 0, len(students))
+		for sid := range students {
+			list = append(list, sid)
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+		state.Assignments[string(testID)] = list
+	}
+
+	if withAnswers {
+		for _, ans := range r.answers {
+			state.Answers = append(state.Answers, cloneAnswer(ans))
+		}
+		sortAnswers(state.Answers)
+		for _, res := range r.results {
+			state.Results = append(state.Results, cloneResult(res))
+		}
+		sortResults(state.Results)
+	}
+
+	for _, n := range r.notifications {
+		state.Notifications = append(state.Notifications, cloneNotification(n))
+	}
+	sort.Slice(state.Notifications, func(i, j int) bool {
+		return createdBefore(state.Notifications[i].CreatedAt, state.Notifications[i].ID, state.Notifications[j].CreatedAt, state.Notifications[j].ID)
+	})
+
+	for _, c := range r.comments {
+		state.Comments = append(state.Comments, cloneQuestionComment(c))
+	}
+	sort.Slice(state.Comments, func(i, j int) bool {
+		return createdBefore(state.Comments[i].CreatedAt, state.Comments[i].ID, state.Comments[j].CreatedAt, state.Comments[j].ID)
+	})
+
+	for _, c := range r.answerComments {
+		state.Feedback = append(state.Feedback, cloneAnswerComment(c))
+	}
+	sortAnswerComments(state.Feedback)
+
+	for _, s := range r.sessions {
+		state.Sessions = append(state.Sessions, cloneTestSession(s))
+	}
+	sort.Slice(state.Sessions, func(i, j int) bool {
+		return createdBefore(state.Sessions[i].CreatedAt, sessionKey(state.Sessions[i].TestID, state.Sessions[i].StudentID), state.Sessions[j].CreatedAt, sessionKey(state.Sessions[j].TestID, state.Sessions[j].StudentID))
+	})
+
+	for _, s := range r.submissions {
+		state.Submissions = append(state.Submissions, s)
+	}
+	sortSubmissions(state.Submissions)
+
+	for _, rubric := range r.rubrics {
+		state.Rubrics = append(state.Rubrics, cloneRubric(rubric))
+	}
+	sort.Slice(state.Rubrics, func(i, j int) bool {
+		return createdBefore(state.Rubrics[i].CreatedAt, state.Rubrics[i].ID, state.Rubrics[j].CreatedAt, state.Rubrics[j].ID)
+	})
+
+	for _, tmpl := range r.templates {
+		state.Templates = append(state.Templates, tmpl)
+	}
+	sort.Slice(state.Templates, func(i, j int) bool {
+		return createdBefore(state.Templates[i].CreatedAt, state.Templates[i].ID, state.Templates[j].CreatedAt, state.Templates[j].ID)
+	})
+
+	for _, item := range r.digests {
+		state.Digests = append(state.Digests, item)
+	}
+	sort.Slice(state.Digests, func(i, j int) bool {
+		return createdBefore(state.Digests[i].OccurredAt, state.Digests[i].ID, state.Digests[j].OccurredAt, state.Digests[j].ID)
+	})
+
+	for _, d := range r.delegations {
+		state.Delegations = append(state.Delegations, d)
+	}
+	sort.Slice(state.Delegations, func(i, j int) bool {
+		return createdBefore(state.Delegations[i].CreatedAt, state.Delegations[i].ID, state.Delegations[j].CreatedAt, state.Delegations[j].ID)
+	})
+
+	for _, d := range r.devices {
+		state.Devices = append(state.Devices, cloneDeviceSession(d))
+	}
+	sort.Slice(state.Devices, func(i, j int) bool {
+		return createdBefore(state.Devices[i].CreatedAt, state.Devices[i].ID, state.Devices[j].CreatedAt, state.Devices[j].ID)
+	})
+
+	for _, q := range r.bank {
+		state.BankQuestions = append(state.BankQuestions, cloneBankQuestion(q))
+	}
+	sort.Slice(state.BankQuestions, func(i, j int) bool {
+		return createdBefore(state.BankQuestions[i].CreatedAt, state.BankQuestions[i].ID, state.BankQuestions[j].CreatedAt, state.BankQuestions[j].ID)
+	})
+
+	for _, d := range r.districts {
+		state.Districts = append(state.Districts, d)
+	}
+	sort.Slice(state.Districts, func(i, j int) bool {
+		return createdBefore(state.Districts[i].CreatedAt, state.Districts[i].ID, state.Districts[j].CreatedAt, state.Districts[j].ID)
+	})
+
+	for _, staff := range r.districtStaff {
+		state.DistrictStaff = append(state.DistrictStaff, cloneDistrictStaff(staff))
+	}
+	sort.Slice(state.DistrictStaff, func(i, j int) bool {
+		return createdBefore(state.DistrictStaff[i].CreatedAt, state.DistrictStaff[i].ID, state.DistrictStaff[j].CreatedAt, state.DistrictStaff[j].ID)
+	})
+
+	for _, e := range r.audit {
+		state.Audit = append(state.Audit, cloneAuditEntry(e))
+	}
+	sort.Slice(state.Audit, func(i, j int) bool {
+		return createdBefore(state.Audit[i].CreatedAt, state.Audit[i].ID, state.Audit[j].CreatedAt, state.Audit[j].ID)
+	})
+
+	return state
+}
+
+func (r *Repository) applySeed(seed SeedData) {
+	for _, s := range seed.Schools {
+		r.schools[s.ID] = cloneSchool(s)
+	}
 	for _, g := range seed.Grades {
 		r.grades[g.ID] = cloneGrade(g)
 	}
@@ -736,21 +2013,60 @@ func (r *Repository) applyState(state State) {
 		}
 	}
 
-	for _, ans := range state.Answers {
-		clone := cloneAnswer(ans)
-		r.answers[clone.ID] = clone
-		key := answerKey(clone.TestID, clone.QuestionID, clone.StudentID)
-		r.answerIndex[key] = clone.ID
-		if _, ok := r.answersByTest[clone.TestID]; !ok {
-			r.answersByTest[clone.TestID] = make(map[domain.AnswerID]struct{})
-		}
-		r.answersByTest[clone.TestID][clone.ID] = struct{}{}
+	r.applyAnswers(state.Answers, state.Results)
+
+	for _, n := range state.Notifications {
+		clone := cloneNotification(n)
+		r.notifications[clone.ID] = clone
+		key := inboxKey(clone.Role, clone.RecipientID)
+		r.inbox[key] = append(r.inbox[key], clone.ID)
 	}
 
-	for _, res := range state.Results {
-		clone := cloneResult(res)
-		r.results[clone.ID] = clone
-		r.resultByAnswer[clone.AnswerID] = clone.ID
+	for _, c := range state.Comments {
+		clone := cloneQuestionComment(c)
+		r.comments[clone.ID] = clone
+	}
+
+	for _, c := range state.Feedback {
+		clone := cloneAnswerComment(c)
+		r.answerComments[clone.ID] = clone
+	}
+
+	for _, s := range state.Sessions {
+		clone := cloneTestSession(s)
+		r.sessions[sessionKey(clone.TestID, clone.StudentID)] = clone
+	}
+
+	for _, s := range state.Submissions {
+		r.submissions[sessionKey(s.TestID, s.StudentID)] = s
+	}
+
+	for _, rubric := range state.Rubrics {
+		r.rubrics[rubric.ID] = cloneRubric(rubric)
+	}
+	for _, tmpl := range state.Templates {
+		r.templates[tmpl.ID] = tmpl
+	}
+	for _, item := range state.Digests {
+		r.digests[item.ID] = item
+	}
+	for _, d := range state.Delegations {
+		r.delegations[d.ID] = d
+	}
+	for _, d := range state.Devices {
+		r.devices[d.ID] = cloneDeviceSession(d)
+	}
+	for _, q := range state.BankQuestions {
+		r.bank[q.ID] = cloneBankQuestion(q)
+	}
+	for _, d := range state.Districts {
+		r.districts[d.ID] = d
+	}
+	for _, staff := range state.DistrictStaff {
+		r.districtStaff[staff.ID] = cloneDistrictStaff(staff)
+	}
+	for _, e := range state.Audit {
+		r.audit[e.ID] = cloneAuditEntry(e)
 	}
 }
 
@@ -779,3 +2095,85 @@ func SampleSeed() SeedData {
 		},
 	}
 }
+
+func (r *Repository) applyAnswers(answers []domain.Answer, results []domain.Result) {
+	for _, ans := range answers {
+		clone := cloneAnswer(ans)
+		r.answers[clone.ID] = clone
+		key := answerKey(clone.TestID, clone.QuestionID, clone.StudentID)
+		r.answerIndex[key] = clone.ID
+		if _, ok := r.answersByTest[clone.TestID]; !ok {
+			r.answersByTest[clone.TestID] = make(map[domain.AnswerID]struct{})
+		}
+		r.answersByTest[clone.TestID][clone.ID] = struct{}{}
+	}
+
+	for _, res := range results {
+		clone := cloneResult(res)
+		r.results[clone.ID] = clone
+		r.resultByAnswer[clone.AnswerID] = clone.ID
+	}
+}
+
+// LoadAnswers adds answers and their results, such as the answers of one
+// test read from storage on demand.
+func (r *Repository) LoadAnswers(answers []domain.Answer, results []domain.Result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.applyAnswers(answers, results)
+}
+
+// ExportAnswers returns the answers of the test and their results.
+func (r *Repository) ExportAnswers(testID domain.TestID) ([]domain.Answer, []domain.Result) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	answers := make([]domain.Answer, 0, len(r.answersByTest[testID]))
+	results := make([]domain.Result, 0)
+	for id := range r.answersByTest[testID] {
+		answers = append(answers, cloneAnswer(r.answers[id]))
+		if resultID, ok := r.resultByAnswer[id]; ok {
+			results = append(results, cloneResult(r.results[resultID]))
+		}
+	}
+	sortAnswers(answers)
+	sortResults(results)
+	return answers, results
+}
+
+// SnapshotGrading reads the test's answers and results under one lock.
+func (r *Repository) SnapshotGrading(_ context.Context, testID domain.TestID) (repository.GradingSnapshot, error) {
+	answers, results := r.ExportAnswers(testID)
+	return repository.GradingSnapshot{Answers: answers, Results: results}, nil
+}
+
+// UnloadAnswers drops the answers of the test and their results from
+// memory, leaving the rest of the data alone.
+func (r *Repository) UnloadAnswers(testID domain.TestID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id := range r.answersByTest[testID] {
+		ans := r.answers[id]
+		delete(r.answerIndex, answerKey(ans.TestID, ans.QuestionID, ans.StudentID))
+		delete(r.answers, id)
+		if resultID, ok := r.resultByAnswer[id]; ok {
+			delete(r.results, resultID)
+			delete(r.resultByAnswer, id)
+		}
+	}
+	delete(r.answersByTest, testID)
+}
+
+func sortAnswers(answers []domain.Answer) {
+	sort.Slice(answers, func(i, j int) bool {
+		return createdBefore(answers[i].CreatedAt, answers[i].ID, answers[j].CreatedAt, answers[j].ID)
+	})
+}
+
+func sortResults(results []domain.Result) {
+	sort.Slice(results, func(i, j int) bool {
+		return createdBefore(results[i].CreatedAt, results[i].ID, results[j].CreatedAt, results[j].ID)
+	})
+}
@@ -0,0 +1,245 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/smtp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/health"
+	"github.com/sky0621/go_work_sample/core/pkg/id"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// Kind identifies the event behind a notification.
+type Kind string
+
+const (
+	KindTestAssigned   Kind = "test_assigned"
+	KindResultReleased Kind = "result_released"
+	KindMention        Kind = "mention"
+	KindGradingDue     Kind = "grading_due"
+	KindFeedback       Kind = "feedback"
+)
+
+// Recipient is the user a notification is addressed to.
+type Recipient struct {
+	Role        domain.Role
+	ID          string
+	Name        string
+	Email       string
+	Preferences domain.NotificationPreferences
+}
+
+// Notification is a single user-facing message.
+type Notification struct {
+	Kind       Kind
+	Subject    string
+	Body       string
+	OccurredAt time.Time
+}
+
+// Mailer sends email.
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// Service delivers notifications according to each recipient's preferences,
+// batching digest subscribers into a single daily email. Every notification a
+// recipient subscribed to is also kept in their in-app inbox, whatever the
+// email delivery mode. Digest emails wait in a persistent queue, so a restart
+// between two flushes loses none of them.
+type Service struct {
+	mailer  Mailer
+	inbox   repository.NotificationWriter
+	digests repository.DigestRepository
+}
+
+// NewService creates a notification service sending through mailer,
+// recording into inbox and queueing digest emails in digests. A nil inbox
+// disables in-app notifications.
+func NewService(mailer Mailer, inbox repository.NotificationWriter, digests repository.DigestRepository) *Service {
+	return &Service{
+		mailer:  mailer,
+		inbox:   inbox,
+		digests: digests,
+	}
+}
+
+// Deliver stores n in the recipient's inbox and sends, queues or drops the
+// email depending on the recipient's preferences.
+func (s *Service) Deliver(ctx context.Context, to Recipient, n Notification) error {
+	if !wants(to.Preferences, n.Kind) {
+		return nil
+	}
+
+	if s.inbox != nil && to.ID != "" {
+		if err := s.inbox.SaveNotification(ctx, &domain.Notification{
+			ID:          domain.NotificationID(id.New()),
+			Role:        to.Role,
+			RecipientID: to.ID,
+			Kind:        string(n.Kind),
+			Subject:     n.Subject,
+			Body:        n.Body,
+			CreatedAt:   n.OccurredAt,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if to.Email == "" {
+		return nil
+	}
+
+	switch to.Preferences.Delivery {
+	case domain.DeliveryOff:
+		return nil
+	case domain.DeliveryDigest:
+		return s.digests.QueueDigestItem(ctx, &domain.DigestItem{
+			ID:          domain.DigestItemID(id.New()),
+			Role:        to.Role,
+			RecipientID: to.ID,
+			Email:       to.Email,
+			Kind:        string(n.Kind),
+			Subject:     n.Subject,
+			Body:        n.Body,
+			OccurredAt:  n.OccurredAt,
+		})
+	default:
+		return s.mailer.Send(ctx, to.Email, n.Subject, n.Body)
+	}
+}
+
+// FlushDigests sends one email per recipient summarising queued notifications
+// and removes them from the queue. Recipients whose email fails keep their
+// notifications for the next run.
+func (s *Service) FlushDigests(ctx context.Context) error {
+	items, err := s.digests.ListDigestItems(ctx)
+	if err != nil {
+		return err
+	}
+	pending := make(map[string][]domain.DigestItem)
+	for _, item := range items {
+		pending[item.Email] = append(pending[item.Email], item)
+	}
+	emails := make([]string, 0, len(pending))
+	for email := range pending {
+		emails = append(emails, email)
+	}
+	sort.Strings(emails)
+
+	var failures []error
+	for _, email := range emails {
+		queued := pending[email]
+		if err := s.mailer.Send(ctx, email, digestSubject(len(queued)), digestBody(queued)); err != nil {
+			failures = append(failures, fmt.Errorf("digest to %s: %w", email, err))
+			continue
+		}
+		sent := make([]domain.DigestItemID, len(queued))
+		for i, item := range queued {
+			sent[i] = item.ID
+		}
+		if err := s.digests.DeleteDigestItems(ctx, sent); err != nil {
+			failures = append(failures, fmt.Errorf("digest to %s: %w", email, err))
+		}
+	}
+	return errors.Join(failures...)
+}
+
+// RunDigests flushes digests every day at the given UTC hour until ctx is
+// cancelled.
+func (s *Service) RunDigests(ctx context.Context, hour int) {
+	for {
+		timer := time.NewTimer(time.Until(nextRun(time.Now().UTC(), hour)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			err := s.FlushDigests(ctx)
+			if err != nil {
+				log.Printf("notification digest failed: %v", err)
+			}
+			health.Report(ctx, err)
+		}
+	}
+}
+
+func wants(prefs domain.NotificationPreferences, kind Kind) bool {
+	switch kind {
+	case KindTestAssigned:
+		return !prefs.MuteTestAssigned
+	case KindResultReleased:
+		return !prefs.MuteResultReleased
+	default:
+		return true
+	}
+}
+
+func nextRun(now time.Time, hour int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.Add(24 * time.Hour)
+	}
+	return next
+}
+
+func digestSubject(count int) string {
+	if count == 1 {
+		return "Your daily summary: 1 update"
+	}
+	return fmt.Sprintf("Your daily summary: %d updates", count)
+}
+
+func digestBody(items []domain.DigestItem) string {
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].OccurredAt.Before(items[j].OccurredAt)
+	})
+
+	var b strings.Builder
+	for _, n := range items {
+		fmt.Fprintf(&b, "- %s (%s)\n", n.Subject, n.OccurredAt.Format(time.RFC3339))
+		if n.Body != "" {
+			fmt.Fprintf(&b, "  %s\n", n.Body)
+		}
+	}
+	return b.String()
+}
+
+// LogMailer writes emails to the process log instead of sending them.
+type LogMailer struct{}
+
+// Send logs the email.
+func (LogMailer) Send(_ context.Context, to, subject, body string) error {
+	log.Printf("email to=%s subject=%q body=%q", to, subject, body)
+	return nil
+}
+
+// SMTPMailer sends plain-text email through an SMTP relay.
+type SMTPMailer struct {
+	Addr string
+	From string
+	Auth smtp.Auth
+}
+
+// Send delivers the email via SMTP.
+func (m SMTPMailer) Send(_ context.Context, to, subject, body string) error {
+	msg := "From: " + headerValue(m.From) + "\r\n" +
+		"To: " + headerValue(to) + "\r\n" +
+		"Subject: " + headerValue(subject) + "\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"\r\n" + body
+	return smtp.SendMail(m.Addr, m.Auth, m.From, []string{to}, []byte(msg))
+}
+
+var headerReplacer = strings.NewReplacer("\r", " ", "\n", " ")
+
+// headerValue prevents header injection through user-controlled values.
+func headerValue(v string) string {
+	return headerReplacer.Replace(v)
+}
@@ -0,0 +1,98 @@
+package notify_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/notify"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+type recordingMailer struct {
+	sent []string
+}
+
+func (m *recordingMailer) Send(_ context.Context, to, subject, body string) error {
+	m.sent = append(m.sent, to+"|"+subject+"|"+body)
+	return nil
+}
+
+func TestServiceHonoursPreferences(t *testing.T) {
+	mailer := &recordingMailer{}
+	queue := fixtures.NewSchool().Build().Repo
+	service := notify.NewService(mailer, nil, queue)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	all := domain.NotificationPreferences{}
+	immediate := notify.Recipient{Email: "now@example.com", Preferences: all}
+	digest := notify.Recipient{Email: "digest@example.com", Preferences: all}
+	digest.Preferences.Delivery = domain.DeliveryDigest
+	off := notify.Recipient{Email: "off@example.com", Preferences: all}
+	off.Preferences.Delivery = domain.DeliveryOff
+
+	for _, to := range []notify.Recipient{immediate, digest, off} {
+		for _, subject := range []string{"Quiz 1", "Quiz 2"} {
+			if err := service.Deliver(ctx, to, notify.Notification{Kind: notify.KindTestAssigned, Subject: subject, OccurredAt: now}); err != nil {
+				t.Fatalf("Deliver failed: %v", err)
+			}
+		}
+	}
+	if len(mailer.sent) != 2 {
+		t.Fatalf("expected 2 immediate emails, got %d", len(mailer.sent))
+	}
+
+	// The queue outlives the service, as it does a restart.
+	service = notify.NewService(mailer, nil, queue)
+	if err := service.FlushDigests(ctx); err != nil {
+		t.Fatalf("FlushDigests failed: %v", err)
+	}
+	if len(mailer.sent) != 3 {
+		t.Fatalf("expected a single digest email, got %d emails total", len(mailer.sent))
+	}
+	if got := mailer.sent[2]; !strings.HasPrefix(got, "digest@example.com|") || !strings.Contains(got, "Quiz 1") || !strings.Contains(got, "Quiz 2") {
+		t.Fatalf("unexpected digest %q", got)
+	}
+
+	if err := service.FlushDigests(ctx); err != nil {
+		t.Fatalf("FlushDigests failed: %v", err)
+	}
+	if len(mailer.sent) != 3 {
+		t.Fatal("expected digest queue to be empty after flush")
+	}
+}
+
+func TestServiceRecordsInbox(t *testing.T) {
+	ctx := context.Background()
+	repo := fixtures.NewSchool().Build().Repo
+	mailer := &recordingMailer{}
+	service := notify.NewService(mailer, repo, repo)
+
+	to := notify.Recipient{
+		Role:        domain.RoleStudent,
+		ID:          "student-001",
+		Email:       "alice@example.com",
+		Preferences: domain.NotificationPreferences{Delivery: domain.DeliveryOff, MuteTestAssigned: true},
+	}
+	if err := service.Deliver(context.Background(), to, notify.Notification{Kind: notify.KindResultReleased, Subject: "Graded", OccurredAt: time.Now().UTC()}); err != nil {
+		t.Fatalf("Deliver failed: %v", err)
+	}
+	if err := service.Deliver(context.Background(), to, notify.Notification{Kind: notify.KindTestAssigned, Subject: "Unwanted", OccurredAt: time.Now().UTC()}); err != nil {
+		t.Fatalf("Deliver failed: %v", err)
+	}
+
+	if len(mailer.sent) != 0 {
+		t.Fatalf("expected no email with delivery off, got %d", len(mailer.sent))
+	}
+	inbox, err := repository.Collect(repo.ListNotifications(ctx, domain.RoleStudent, "student-001", repository.All))
+	if err != nil {
+		t.Fatalf("ListNotifications failed: %v", err)
+	}
+	if len(inbox) != 1 || inbox[0].Subject != "Graded" || inbox[0].ReadAt != nil {
+		t.Fatalf("expected one unread inbox entry, got %+v", inbox)
+	}
+}
@@ -0,0 +1,50 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"html"
+	"net/http"
+	"strings"
+)
+
+const (
+	// SpecPath serves the document as JSON.
+	SpecPath = "/openapi.json"
+	// DocsPath serves the Swagger UI page.
+	DocsPath = "/docs/"
+)
+
+// swaggerPage renders SpecPath with Swagger UI. The page itself is compiled
+// into the binary; the Swagger UI scripts and styles load from a CDN.
+//
+//go:embed swagger.html
+var swaggerPage string
+
+// Register serves doc at SpecPath and the Swagger UI page at DocsPath. Both
+// are public and belong outside the authentication middleware.
+func Register(mux *http.ServeMux, doc *Document) {
+	spec, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		panic("openapi: " + err.Error())
+	}
+	page := strings.ReplaceAll(swaggerPage, "{{title}}", html.EscapeString(doc.Info.Title))
+
+	mux.HandleFunc(SpecPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		_, _ = w.Write(spec)
+	})
+	mux.HandleFunc(DocsPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(page))
+	})
+}
@@ -0,0 +1,395 @@
+// Package openapi builds OpenAPI 3 documents describing the service APIs.
+// Schemas are generated from the Go types the handlers encode, following
+// their json tags, so the documents stay in step with the wire format.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SpecVersion is the OpenAPI version of generated documents.
+const SpecVersion = "3.0.3"
+
+// bearerScheme names the JWT security scheme every document declares.
+const bearerScheme = "bearerAuth"
+
+// Document is an OpenAPI 3 document.
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []SecurityRequirement `json:"security,omitempty"`
+}
+
+// Info describes the API.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem maps lowercase HTTP methods to the operations of one path.
+type PathItem map[string]*Operation
+
+// Operation is one endpoint.
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []SecurityRequirement `json:"security,omitempty"`
+}
+
+// Parameter is a path or query parameter.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes the body an operation accepts.
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// MediaType holds the schema of one content type.
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Response describes one response status.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// Schema is the subset of the OpenAPI schema object the generator emits.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Components holds the named schemas and security schemes.
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme describes how callers authenticate.
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// SecurityRequirement names the schemes an operation accepts. An empty
+// requirement marks an operation that needs no credentials.
+type SecurityRequirement map[string][]string
+
+// Object describes an inline JSON object, such as a response envelope built
+// from a map, by example: each value's type gives the property's schema.
+// Every property is required.
+type Object map[string]any
+
+// Binary stands for a body of raw bytes, such as a ZIP archive.
+type Binary struct{}
+
+// Route describes an endpoint for Builder.Add. Request and Response are
+// example values whose types give the body schemas; nil means no body.
+type Route struct {
+	Summary string
+	Tag     string
+	Query   []Parameter
+	Request any
+	// RequestType is the request media type, application/json by default.
+	RequestType string
+	// Status is the success status, 200 by default.
+	Status   int
+	Response any
+	// ResponseType is the response media type, application/json by default.
+	ResponseType string
+	// Public routes need no access token.
+	Public bool
+}
+
+// Builder assembles a document route by route.
+type Builder struct {
+	doc   Document
+	names map[reflect.Type]string
+	types map[string]reflect.Type
+}
+
+// errorBody is the body every error response carries.
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+// New starts a document whose operations require a bearer access token
+// unless marked public.
+func New(info Info) *Builder {
+	b := &Builder{
+		doc: Document{
+			OpenAPI: SpecVersion,
+			Info:    info,
+			Paths:   make(map[string]PathItem),
+			Components: Components{
+				Schemas: make(map[string]*Schema),
+				SecuritySchemes: map[string]SecurityScheme{
+					bearerScheme: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+				},
+			},
+			Security: []SecurityRequirement{{bearerScheme: {}}},
+		},
+		names: make(map[reflect.Type]string),
+		types: make(map[string]reflect.Type),
+	}
+	b.schemaOf(reflect.TypeOf(errorBody{}))
+	return b
+}
+
+// Query describes an optional string query parameter.
+func Query(name, description string) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: &Schema{Type: "string"}}
+}
+
+// Header describes a required string request header, listed with the
+// query parameters of a Route.
+func Header(name, description string) Parameter {
+	return Parameter{Name: name, In: "header", Description: description, Required: true, Schema: &Schema{Type: "string"}}
+}
+
+// PageQuery describes the cursor pagination parameters of list endpoints.
+func PageQuery() []Parameter {
+	return []Parameter{
+		{Name: "limit", In: "query", Description: "Page size.", Schema: &Schema{Type: "integer"}},
+		Query("cursor", "The next_cursor of the previous page."),
+	}
+}
+
+var pathParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+// Add documents the route at method and path. Path parameters are taken
+// from the {braced} segments of path.
+func (b *Builder) Add(method, path string, route Route) *Builder {
+	method = strings.ToLower(method)
+	op := &Operation{
+		OperationID: operationID(method, path),
+		Summary:     route.Summary,
+		Responses:   make(map[string]Response),
+	}
+	if route.Tag != "" {
+		op.Tags = []string{route.Tag}
+	}
+	for _, m := range pathParam.FindAllStringSubmatch(path, -1) {
+		op.Parameters = append(op.Parameters, Parameter{Name: m[1], In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+	op.Parameters = append(op.Parameters, route.Query...)
+	if route.Request != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{orDefault(route.RequestType, "application/json"): {Schema: b.schemaOfValue(route.Request)}},
+		}
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := Response{Description: http.StatusText(status)}
+	if route.Response != nil {
+		success.Content = map[string]MediaType{orDefault(route.ResponseType, "application/json"): {Schema: b.schemaOfValue(route.Response)}}
+	}
+	op.Responses[fmt.Sprint(status)] = success
+	op.Responses["default"] = Response{
+		Description: "Error",
+		Content:     map[string]MediaType{"application/json": {Schema: b.schemaOf(reflect.TypeOf(errorBody{}))}},
+	}
+	if route.Public {
+		op.Security = []SecurityRequirement{{}}
+	}
+
+	item, ok := b.doc.Paths[path]
+	if !ok {
+		item = make(PathItem)
+		b.doc.Paths[path] = item
+	}
+	item[method] = op
+	return b
+}
+
+// Document returns the assembled document.
+func (b *Builder) Document() *Document {
+	return &b.doc
+}
+
+func (b *Builder) schemaOfValue(v any) *Schema {
+	if obj, ok := v.(Object); ok {
+		s := &Schema{Type: "object", Properties: make(map[string]*Schema, len(obj))}
+		for name, value := range obj {
+			s.Properties[name] = b.schemaOfValue(value)
+			s.Required = append(s.Required, name)
+		}
+		sort.Strings(s.Required)
+		return s
+	}
+	return b.schemaOf(reflect.TypeOf(v))
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	binaryType  = reflect.TypeOf(Binary{})
+	rawJSONType = reflect.TypeOf(json.RawMessage{})
+)
+
+func (b *Builder) schemaOf(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case binaryType:
+		return &Schema{Type: "string", Format: "binary"}
+	case rawJSONType:
+		// Embedded JSON of any shape.
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := b.schemaOf(t.Elem())
+		if s.Ref != "" {
+			return s
+		}
+		nullable := *s
+		nullable.Nullable = true
+		return &nullable
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.objectSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + b.register(t)}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schemaOf(t.Elem())}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	default:
+		return &Schema{}
+	}
+}
+
+// register adds the named struct type to the components once and returns
+// its component name.
+func (b *Builder) register(t reflect.Type) string {
+	if name, ok := b.names[t]; ok {
+		return name
+	}
+	name := exportedName(t.Name())
+	if other, taken := b.types[name]; taken && other != t {
+		name = exportedName(t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]) + name
+	}
+	b.names[t] = name
+	b.types[name] = t
+	b.doc.Components.Schemas[name] = b.objectSchema(t)
+	return name
+}
+
+// objectSchema describes a struct by its json-encoded fields. Fields without
+// omitempty are required; embedded structs contribute their own fields.
+func (b *Builder) objectSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	var walk func(reflect.Type)
+	walk = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+				walk(f.Type)
+				continue
+			}
+			if !f.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			s.Properties[name] = b.schemaOf(f.Type)
+			if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Pointer {
+				s.Required = append(s.Required, name)
+			}
+		}
+	}
+	walk(t)
+	sort.Strings(s.Required)
+	return s
+}
+
+// operationID derives an identifier such as getStudentTests from the
+// method and the literal segments of the path.
+func operationID(method, path string) string {
+	var sb strings.Builder
+	sb.WriteString(method)
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" || segment == "api" {
+			continue
+		}
+		if strings.HasPrefix(segment, "{") {
+			segment = "by-" + strings.Trim(segment, "{}")
+		}
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+			sb.WriteString(exportedName(word))
+		}
+	}
+	return sb.String()
+}
+
+func exportedName(name string) string {
+	if name == "" {
+		return name
+	}
+	r := []rune(name)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
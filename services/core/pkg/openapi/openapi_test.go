@@ -0,0 +1,116 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/openapi"
+)
+
+type itemResponse struct {
+	ItemID   string          `json:"item_id"`
+	Note     string          `json:"note,omitempty"`
+	DueAt    *time.Time      `json:"due_at"`
+	Children []itemResponse  `json:"children"`
+	Extra    json.RawMessage `json:"extra,omitempty"`
+	audit
+}
+
+type audit struct {
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func TestBuilder_Schemas(t *testing.T) {
+	doc := openapi.New(openapi.Info{Title: "Test", Version: "1"}).
+		Add("GET", "/api/items/{itemID}", openapi.Route{Summary: "Get an item", Response: itemResponse{}}).
+		Document()
+
+	op := doc.Paths["/api/items/{itemID}"]["get"]
+	if op == nil {
+		t.Fatalf("operation missing: %+v", doc.Paths)
+	}
+	if op.OperationID != "getItemsByItemID" {
+		t.Fatalf("unexpected operation id %q", op.OperationID)
+	}
+	if len(op.Parameters) != 1 || op.Parameters[0].Name != "itemID" || op.Parameters[0].In != "path" || !op.Parameters[0].Required {
+		t.Fatalf("unexpected parameters %+v", op.Parameters)
+	}
+	if ref := op.Responses["200"].Content["application/json"].Schema.Ref; ref != "#/components/schemas/ItemResponse" {
+		t.Fatalf("unexpected response ref %q", ref)
+	}
+	if _, ok := op.Responses["default"]; !ok {
+		t.Fatal("error response missing")
+	}
+
+	s := doc.Components.Schemas["ItemResponse"]
+	if s == nil {
+		t.Fatalf("schema not registered: %v", doc.Components.Schemas)
+	}
+	if got := strings.Join(s.Required, ","); got != "children,created_at,item_id" {
+		t.Fatalf("unexpected required fields %q", got)
+	}
+	if due := s.Properties["due_at"]; due.Format != "date-time" || !due.Nullable {
+		t.Fatalf("pointer time should be a nullable date-time: %+v", due)
+	}
+	if items := s.Properties["children"].Items; items == nil || items.Ref != "#/components/schemas/ItemResponse" {
+		t.Fatalf("recursive field should reference its own schema: %+v", s.Properties["children"])
+	}
+	if extra := s.Properties["extra"]; extra.Type != "" {
+		t.Fatalf("raw json should accept any value: %+v", extra)
+	}
+}
+
+func TestBuilder_ObjectsAndSecurity(t *testing.T) {
+	doc := openapi.New(openapi.Info{Title: "Test", Version: "1"}).
+		Add("POST", "/api/auth/sign-in", openapi.Route{
+			Request:  openapi.Object{"email": "", "remember": false},
+			Status:   http.StatusCreated,
+			Response: openapi.Object{"items": []itemResponse{}, "count": 0},
+			Public:   true,
+		}).
+		Add("DELETE", "/api/items/{itemID}", openapi.Route{Status: http.StatusNoContent}).
+		Document()
+
+	signIn := doc.Paths["/api/auth/sign-in"]["post"]
+	if len(signIn.Security) != 1 || len(signIn.Security[0]) != 0 {
+		t.Fatalf("public route should clear security: %+v", signIn.Security)
+	}
+	req := signIn.RequestBody.Content["application/json"].Schema
+	if req.Properties["remember"].Type != "boolean" || strings.Join(req.Required, ",") != "email,remember" {
+		t.Fatalf("unexpected request schema %+v", req)
+	}
+	resp := signIn.Responses["201"].Content["application/json"].Schema
+	if resp.Properties["count"].Type != "integer" || resp.Properties["items"].Items.Ref != "#/components/schemas/ItemResponse" {
+		t.Fatalf("unexpected response schema %+v", resp)
+	}
+
+	del := doc.Paths["/api/items/{itemID}"]["delete"]
+	if del.Security != nil {
+		t.Fatalf("protected route should inherit the document security: %+v", del.Security)
+	}
+	if r := del.Responses["204"]; r.Description != "No Content" || r.Content != nil {
+		t.Fatalf("unexpected no-content response %+v", r)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	mux := http.NewServeMux()
+	openapi.Register(mux, openapi.New(openapi.Info{Title: "Items <API>", Version: "1"}).Document())
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, openapi.SpecPath, nil))
+	var doc openapi.Document
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil || doc.OpenAPI != openapi.SpecVersion {
+		t.Fatalf("unexpected spec response %d %q: %v", rec.Code, rec.Body.String(), err)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, openapi.DocsPath, nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Items &lt;API&gt;") {
+		t.Fatalf("unexpected docs response %d %q", rec.Code, rec.Body.String())
+	}
+}
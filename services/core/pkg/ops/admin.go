@@ -0,0 +1,110 @@
+package ops
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+)
+
+// AdminPrefix is the path the runbook must be mounted on, behind the admin
+// key:
+//
+//	GET  /api/admin/ops/         the verbs and the recent runs
+//	POST /api/admin/ops/{verb}   run a verb; X-Operator names who runs it
+const AdminPrefix = "/api/admin/ops/"
+
+// OperatorHeader names the person running a verb, for the record.
+const OperatorHeader = "X-Operator"
+
+type verbResponse struct {
+	Verb        string   `json:"verb"`
+	Description string   `json:"description"`
+	Enabled     bool     `json:"enabled"`
+	Targets     []string `json:"targets"`
+}
+
+// ServeHTTP lists the verbs and history on GET and runs a verb on POST
+// {verb}, answering with the run.
+func (b *Runbook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	verb := strings.Trim(strings.TrimPrefix(r.URL.Path, AdminPrefix), "/")
+	switch {
+	case verb == "" && r.Method == http.MethodGet:
+		verbs := make([]verbResponse, len(Verbs))
+		for i, v := range Verbs {
+			verbs[i] = verbResponse{Verb: v, Description: descriptions[v], Enabled: b.Allowed(v), Targets: b.targets(v)}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"service": b.service, "verbs": verbs, "history": b.History()})
+	case verb != "" && !strings.Contains(verb, "/") && r.Method == http.MethodPost:
+		var req struct {
+			Reason string `json:"reason"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			writeError(w, http.StatusBadRequest, "invalid json payload")
+			return
+		}
+		run, err := b.Run(r.Context(), verb, strings.TrimSpace(r.Header.Get(OperatorHeader)), req.Reason)
+		switch err {
+		case nil:
+		case errs.ErrUnknownOperation:
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		case errs.ErrOperationDisabled:
+			writeError(w, http.StatusForbidden, err.Error())
+			return
+		case errs.ErrOperatorRequired:
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		case errs.ErrOperationRunning:
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		default:
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		status := http.StatusOK
+		if run.Failed {
+			status = http.StatusInternalServerError
+		}
+		writeJSON(w, status, run)
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// targets names the components verb acts on.
+func (b *Runbook) targets(verb string) []string {
+	names := []string{}
+	switch verb {
+	case FlushCaches:
+		for _, c := range b.caches {
+			names = append(names, c.name)
+		}
+	case RebuildIndexes:
+		for _, c := range b.indexes {
+			names = append(names, c.name)
+		}
+	case DrainQueues:
+		for _, c := range b.queues {
+			names = append(names, c.name)
+		}
+	case RotateLogs:
+		if b.logs != nil {
+			names = append(names, "log")
+		}
+	}
+	return names
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, payload any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
@@ -0,0 +1,270 @@
+// Package ops runs routine maintenance on a live service, so operators flush
+// caches, rebuild indexes, drain queues and rotate logs without restarting
+// it. Every run names its operator and is logged and kept in a history.
+package ops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/id"
+	"github.com/sky0621/go_work_sample/core/pkg/logfile"
+)
+
+// The maintenance verbs.
+const (
+	FlushCaches    = "flush-caches"
+	RebuildIndexes = "rebuild-indexes"
+	DrainQueues    = "drain-queues"
+	RotateLogs     = "rotate-logs"
+)
+
+// Verbs lists every verb in the order they are documented.
+var Verbs = []string{FlushCaches, RebuildIndexes, DrainQueues, RotateLogs}
+
+var descriptions = map[string]string{
+	FlushCaches:    "Drop cached computations; they are rebuilt from storage on the next read.",
+	RebuildIndexes: "Rebuild the storage indexes from the stored records.",
+	DrainQueues:    "Wait until the in-process queues have no pending work.",
+	RotateLogs:     "Move the log file aside and start a new one.",
+}
+
+// historySize is how many runs the runbook remembers.
+const historySize = 100
+
+// drainPoll is how often drain-queues checks the queues.
+const drainPoll = 100 * time.Millisecond
+
+// Target is the outcome of a verb on one component, such as one cache.
+type Target struct {
+	Name   string `json:"name"`
+	Detail string `json:"detail,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Run records one run of a verb.
+type Run struct {
+	ID         string    `json:"id"`
+	Verb       string    `json:"verb"`
+	Operator   string    `json:"operator"`
+	Reason     string    `json:"reason,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Targets    []Target  `json:"targets"`
+	Failed     bool      `json:"failed"`
+}
+
+type component[F any] struct {
+	name string
+	fn   F
+}
+
+// Runbook holds the components of a service its verbs act on.
+type Runbook struct {
+	service string
+	logger  *slog.Logger
+	allowed []string
+	drain   time.Duration
+
+	caches  []component[func() int]
+	indexes []component[func(context.Context) error]
+	queues  []component[func() int]
+	logs    *logfile.File
+
+	mu      sync.Mutex
+	running map[string]bool
+	history []Run
+}
+
+// Settings restricts and tunes the verbs.
+type Settings struct {
+	// Allowed lists the verbs operators may run; nil allows all of them.
+	Allowed []string
+	// DrainTimeout bounds how long drain-queues waits.
+	DrainTimeout time.Duration
+}
+
+// NewRunbook creates the runbook of service, logging runs to logger.
+func NewRunbook(service string, logger *slog.Logger, settings Settings) *Runbook {
+	allowed := settings.Allowed
+	if allowed == nil {
+		allowed = Verbs
+	}
+	return &Runbook{
+		service: service,
+		logger:  logger,
+		allowed: allowed,
+		drain:   settings.DrainTimeout,
+		running: make(map[string]bool),
+	}
+}
+
+// AddCache registers a cache for flush-caches. flush returns the number of
+// entries dropped.
+func (b *Runbook) AddCache(name string, flush func() int) {
+	b.caches = append(b.caches, component[func() int]{name, flush})
+}
+
+// AddIndex registers storage for rebuild-indexes.
+func (b *Runbook) AddIndex(name string, rebuild func(context.Context) error) {
+	b.indexes = append(b.indexes, component[func(context.Context) error]{name, rebuild})
+}
+
+// AddQueue registers a queue for drain-queues. pending returns the work
+// not finished yet.
+func (b *Runbook) AddQueue(name string, pending func() int) {
+	b.queues = append(b.queues, component[func() int]{name, pending})
+}
+
+// SetLogs registers the log rotate-logs rotates.
+func (b *Runbook) SetLogs(logs *logfile.File) {
+	b.logs = logs
+}
+
+// Allowed reports whether operators may run verb.
+func (b *Runbook) Allowed(verb string) bool {
+	return slices.Contains(b.allowed, verb)
+}
+
+// Run runs verb for operator. A verb runs once at a time; the run is logged
+// and recorded whether or not a component fails.
+func (b *Runbook) Run(ctx context.Context, verb, operator, reason string) (Run, error) {
+	if _, ok := descriptions[verb]; !ok {
+		return Run{}, errs.ErrUnknownOperation
+	}
+	if !b.Allowed(verb) {
+		return Run{}, errs.ErrOperationDisabled
+	}
+	if operator == "" {
+		return Run{}, errs.ErrOperatorRequired
+	}
+	b.mu.Lock()
+	if b.running[verb] {
+		b.mu.Unlock()
+		return Run{}, errs.ErrOperationRunning
+	}
+	b.running[verb] = true
+	b.mu.Unlock()
+
+	run := Run{ID: id.New(), Verb: verb, Operator: operator, Reason: reason, StartedAt: time.Now().UTC()}
+	switch verb {
+	case FlushCaches:
+		run.Targets = b.flushCaches()
+	case RebuildIndexes:
+		run.Targets = b.rebuildIndexes(ctx)
+	case DrainQueues:
+		run.Targets = b.drainQueues(ctx)
+	case RotateLogs:
+		run.Targets = b.rotateLogs()
+	}
+	run.FinishedAt = time.Now().UTC()
+	for _, t := range run.Targets {
+		run.Failed = run.Failed || t.Error != ""
+	}
+
+	b.mu.Lock()
+	delete(b.running, verb)
+	b.history = append(b.history, run)
+	if len(b.history) > historySize {
+		b.history = b.history[len(b.history)-historySize:]
+	}
+	b.mu.Unlock()
+
+	level := slog.LevelInfo
+	if run.Failed {
+		level = slog.LevelError
+	}
+	b.logger.Log(ctx, level, "maintenance operation",
+		"service", b.service,
+		"run_id", run.ID,
+		"verb", verb,
+		"operator", operator,
+		"reason", reason,
+		"targets", run.Targets,
+		"duration", run.FinishedAt.Sub(run.StartedAt).String(),
+	)
+	return run, nil
+}
+
+// History returns the recent runs, newest first.
+func (b *Runbook) History() []Run {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := slices.Clone(b.history)
+	slices.Reverse(out)
+	return out
+}
+
+func (b *Runbook) flushCaches() []Target {
+	targets := make([]Target, len(b.caches))
+	for i, c := range b.caches {
+		targets[i] = Target{Name: c.name, Detail: fmt.Sprintf("dropped %d entries", c.fn())}
+	}
+	return targets
+}
+
+func (b *Runbook) rebuildIndexes(ctx context.Context) []Target {
+	targets := make([]Target, len(b.indexes))
+	for i, c := range b.indexes {
+		targets[i] = Target{Name: c.name, Detail: "rebuilt"}
+		if err := c.fn(ctx); err != nil {
+			targets[i] = Target{Name: c.name, Error: err.Error()}
+		}
+	}
+	return targets
+}
+
+func (b *Runbook) drainQueues(ctx context.Context) []Target {
+	if b.drain > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.drain)
+		defer cancel()
+	}
+	targets := make([]Target, len(b.queues))
+	for i, q := range b.queues {
+		started := q.fn()
+		if left, err := waitEmpty(ctx, q.fn); err != nil {
+			targets[i] = Target{Name: q.name, Error: fmt.Sprintf("%d still pending: %v", left, err)}
+			continue
+		}
+		targets[i] = Target{Name: q.name, Detail: fmt.Sprintf("drained %d pending", started)}
+	}
+	return targets
+}
+
+// waitEmpty polls pending until it reports no work or ctx ends.
+func waitEmpty(ctx context.Context, pending func() int) (int, error) {
+	ticker := time.NewTicker(drainPoll)
+	defer ticker.Stop()
+	for {
+		n := pending()
+		if n == 0 {
+			return 0, nil
+		}
+		select {
+		case <-ctx.Done():
+			return n, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (b *Runbook) rotateLogs() []Target {
+	if b.logs == nil {
+		return []Target{}
+	}
+	rotated, err := b.logs.Rotate()
+	switch {
+	case errors.Is(err, logfile.ErrNotRotatable):
+		return []Target{{Name: "log", Detail: "log goes to standard output; nothing to rotate"}}
+	case err != nil:
+		return []Target{{Name: b.logs.Path(), Error: err.Error()}}
+	}
+	return []Target{{Name: b.logs.Path(), Detail: "moved to " + rotated}}
+}
@@ -0,0 +1,138 @@
+package ops_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/logfile"
+	"github.com/sky0621/go_work_sample/core/pkg/ops"
+)
+
+func newRunbook(settings ops.Settings) *ops.Runbook {
+	return ops.NewRunbook("test-api", slog.New(slog.NewTextHandler(io.Discard, nil)), settings)
+}
+
+func TestRunbookRunsVerbsAndKeepsHistory(t *testing.T) {
+	runbook := newRunbook(ops.Settings{})
+	runbook.AddCache("statistics", func() int { return 3 })
+	reindexed := false
+	runbook.AddIndex("storage", func(context.Context) error { reindexed = true; return nil })
+
+	flush, err := runbook.Run(context.Background(), ops.FlushCaches, "alice", "stale stats")
+	if err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if flush.Failed || len(flush.Targets) != 1 || flush.Targets[0].Detail != "dropped 3 entries" {
+		t.Fatalf("unexpected flush run: %+v", flush)
+	}
+	if _, err := runbook.Run(context.Background(), ops.RebuildIndexes, "bob", ""); err != nil {
+		t.Fatalf("reindex: %v", err)
+	}
+	if !reindexed {
+		t.Fatalf("expected the index to be rebuilt")
+	}
+
+	history := runbook.History()
+	if len(history) != 2 || history[0].Verb != ops.RebuildIndexes || history[1].Operator != "alice" {
+		t.Fatalf("expected newest run first, got %+v", history)
+	}
+}
+
+func TestRunbookRejectsUnknownDisabledAndAnonymousRuns(t *testing.T) {
+	runbook := newRunbook(ops.Settings{Allowed: []string{ops.FlushCaches}})
+	cases := []struct {
+		verb, operator string
+		want           error
+	}{
+		{"defragment", "alice", errs.ErrUnknownOperation},
+		{ops.RotateLogs, "alice", errs.ErrOperationDisabled},
+		{ops.FlushCaches, "", errs.ErrOperatorRequired},
+	}
+	for _, c := range cases {
+		if _, err := runbook.Run(context.Background(), c.verb, c.operator, ""); !errors.Is(err, c.want) {
+			t.Errorf("%s by %q: expected %v, got %v", c.verb, c.operator, c.want, err)
+		}
+	}
+	if len(runbook.History()) != 0 {
+		t.Fatalf("rejected runs must not be recorded")
+	}
+}
+
+func TestDrainQueuesFailsWhenWorkOutlastsTheTimeout(t *testing.T) {
+	runbook := newRunbook(ops.Settings{DrainTimeout: 50 * time.Millisecond})
+	runbook.AddQueue("idle", func() int { return 0 })
+	runbook.AddQueue("stuck", func() int { return 2 })
+
+	run, err := runbook.Run(context.Background(), ops.DrainQueues, "alice", "")
+	if err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	if !run.Failed || run.Targets[0].Error != "" || !strings.HasPrefix(run.Targets[1].Error, "2 still pending") {
+		t.Fatalf("expected only the stuck queue to fail, got %+v", run)
+	}
+}
+
+func TestRotateLogsMovesTheFileAside(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.log")
+	logs, err := logfile.Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer logs.Close()
+	if _, err := logs.Write([]byte("before\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	runbook := newRunbook(ops.Settings{})
+	runbook.SetLogs(logs)
+	run, err := runbook.Run(context.Background(), ops.RotateLogs, "alice", "")
+	if err != nil || run.Failed {
+		t.Fatalf("rotate: %+v, %v", run, err)
+	}
+	if _, err := logs.Write([]byte("after\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	current, _ := os.ReadFile(path)
+	rotated, _ := os.ReadFile(strings.TrimPrefix(run.Targets[0].Detail, "moved to "))
+	if string(current) != "after\n" || string(rotated) != "before\n" {
+		t.Fatalf("expected the old lines moved aside, got current %q and rotated %q", current, rotated)
+	}
+}
+
+func TestAdminRequiresAnOperatorHeader(t *testing.T) {
+	runbook := newRunbook(ops.Settings{})
+	runbook.AddCache("statistics", func() int { return 0 })
+
+	rec := httptest.NewRecorder()
+	runbook.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, ops.AdminPrefix+ops.FlushCaches, nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without an operator, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, ops.AdminPrefix+ops.FlushCaches, strings.NewReader(`{"reason":"deploy"}`))
+	req.Header.Set(ops.OperatorHeader, "alice")
+	rec = httptest.NewRecorder()
+	runbook.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var run ops.Run
+	if err := json.NewDecoder(rec.Body).Decode(&run); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if run.Operator != "alice" || run.Reason != "deploy" {
+		t.Fatalf("unexpected run: %+v", run)
+	}
+}
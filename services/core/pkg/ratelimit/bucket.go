@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Buckets meters events per key with token buckets. A key starts with a full
+// bucket of burst tokens; every event takes one, and tokens flow back at rate
+// per second. Unlike Limiter it smooths bursts instead of resetting at window
+// boundaries, which suits per-second limits.
+type Buckets struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	// full is when the bucket refills completely, after which it is
+	// indistinguishable from a new one and can be forgotten.
+	full time.Time
+}
+
+// NewBuckets creates an empty set of buckets.
+func NewBuckets() *Buckets {
+	return &Buckets{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Take takes a token from the bucket of key, holding at most burst tokens
+// refilled at rate per second, and reports whether one was available. When
+// none was, wait is how long until one is. A rate of zero or less disables
+// limiting.
+func (b *Buckets) Take(key string, rate float64, burst int) (ok bool, wait time.Duration) {
+	if rate <= 0 {
+		return true, 0
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.prune(now)
+	bk, found := b.buckets[key]
+	if !found {
+		bk = &bucket{tokens: float64(burst), updated: now}
+		b.buckets[key] = bk
+	}
+	bk.tokens = math.Min(float64(burst), bk.tokens+now.Sub(bk.updated).Seconds()*rate)
+	bk.updated = now
+	if bk.tokens < 1 {
+		return false, time.Duration((1 - bk.tokens) / rate * float64(time.Second))
+	}
+	bk.tokens--
+	bk.full = now.Add(time.Duration((float64(burst) - bk.tokens) / rate * float64(time.Second)))
+	return true, 0
+}
+
+// prune forgets full buckets at most once a minute, so idle keys do not
+// accumulate.
+func (b *Buckets) prune(now time.Time) {
+	if now.Sub(b.lastPrune) < time.Minute {
+		return
+	}
+	b.lastPrune = now
+	for key, bk := range b.buckets {
+		if !now.Before(bk.full) {
+			delete(b.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter counts events per key in fixed windows. Windows are aligned to the
+// period, so a limit of 100 per minute resets at the start of every minute.
+type Limiter struct {
+	mu       sync.Mutex
+	counters map[string]*window
+	now      func() time.Time
+}
+
+type window struct {
+	start time.Time
+	count int
+}
+
+// NewLimiter creates an empty limiter.
+func NewLimiter() *Limiter {
+	return &Limiter{
+		counters: make(map[string]*window),
+		now:      time.Now,
+	}
+}
+
+// Allow records one event for key and reports whether it stays within limit
+// events per period. Rejected events are not counted. A limit of zero or
+// less disables limiting.
+func (l *Limiter) Allow(key string, limit int, period time.Duration) bool {
+	if limit <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	start := l.now().UTC().Truncate(period)
+	w, ok := l.counters[key]
+	if !ok || !w.start.Equal(start) {
+		w = &window{start: start}
+		l.counters[key] = w
+		l.prune(start)
+	}
+	if w.count >= limit {
+		return false
+	}
+	w.count++
+	return true
+}
+
+// prune forgets windows that started more than two days before current so
+// idle keys do not accumulate. Periods longer than a day are not supported.
+func (l *Limiter) prune(current time.Time) {
+	for key, w := range l.counters {
+		if current.Sub(w.start) > 48*time.Hour {
+			delete(l.counters, key)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package readmodel
+
+import (
+	"sort"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// Cell is a student's answer to one question as the gradebook sees it. A
+// cell without an answer ID is unanswered.
+type Cell struct {
+	AnswerID  domain.AnswerID
+	Graded    bool
+	Score     domain.Score
+	Completed bool
+
+	gradedAt time.Time
+}
+
+// Answered reports whether the student answered the question.
+func (c Cell) Answered() bool {
+	return c.AnswerID != ""
+}
+
+// Counters are the dashboard totals of a test, kept up to date as answers
+// are submitted and graded.
+type Counters struct {
+	Answers    int
+	Graded     int
+	Completed  int
+	TotalScore domain.Score
+}
+
+// Ungraded counts the answers still waiting for a grade.
+func (c Counters) Ungraded() int {
+	return c.Answers - c.Graded
+}
+
+// MeanScore is the average score of the graded answers.
+func (c Counters) MeanScore() float64 {
+	if c.Graded == 0 {
+		return 0
+	}
+	return float64(c.TotalScore) / float64(c.Graded)
+}
+
+func (c *Counters) add(cell Cell, sign int) {
+	if !cell.Answered() {
+		return
+	}
+	c.Answers += sign
+	if !cell.Graded {
+		return
+	}
+	c.Graded += sign
+	c.TotalScore += domain.Score(sign) * cell.Score
+	if cell.Completed {
+		c.Completed += sign
+	}
+}
+
+// Gradebook is the student-by-question matrix of a test. Questions are in
+// sequence order and every row has a cell per question. Assigned students
+// come first, in assignment order, followed by any other student who
+// answered.
+type Gradebook struct {
+	TestID    domain.TestID
+	Questions []domain.QuestionID
+	MaxScore  domain.Points
+	Rows      []GradebookRow
+	Counters  Counters
+	BuiltAt   time.Time
+}
+
+// GradebookRow is one student's line of a gradebook.
+type GradebookRow struct {
+	StudentID domain.StudentID
+	Assigned  bool
+	Cells     []Cell
+}
+
+// Score sums the student's graded cells.
+func (r GradebookRow) Score() domain.Score {
+	var total domain.Score
+	for _, c := range r.Cells {
+		if c.Graded {
+			total += c.Score
+		}
+	}
+	return total
+}
+
+// ScoreRange returns the lowest and highest score of the graded cells, or
+// zeros when nothing is graded.
+func (g *Gradebook) ScoreRange() (domain.Score, domain.Score) {
+	var low, high domain.Score
+	first := true
+	for _, row := range g.Rows {
+		for _, c := range row.Cells {
+			if !c.Graded {
+				continue
+			}
+			if first || c.Score < low {
+				low = c.Score
+			}
+			if first || c.Score > high {
+				high = c.Score
+			}
+			first = false
+		}
+	}
+	return low, high
+}
+
+// projection is the mutable gradebook of one test held by the projector.
+type projection struct {
+	builtAt   time.Time
+	questions []domain.QuestionID
+	column    map[domain.QuestionID]int
+	maxScore  domain.Points
+	assigned  []domain.StudentID
+	rows      map[domain.StudentID][]Cell
+	counters  Counters
+}
+
+func newProjection(test domain.Test, questions []domain.Question, now time.Time) *projection {
+	sorted := append([]domain.Question(nil), questions...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Sequence < sorted[j].Sequence
+	})
+	p := &projection{
+		builtAt:   now,
+		questions: make([]domain.QuestionID, len(sorted)),
+		column:    make(map[domain.QuestionID]int, len(sorted)),
+		maxScore:  domain.TotalPoints(sorted),
+		assigned:  append([]domain.StudentID(nil), test.AssignedTo...),
+		rows:      make(map[domain.StudentID][]Cell, len(test.AssignedTo)),
+	}
+	for i, q := range sorted {
+		p.questions[i] = q.ID
+		p.column[q.ID] = i
+	}
+	for _, studentID := range test.AssignedTo {
+		p.rows[studentID] = make([]Cell, len(sorted))
+	}
+	return p
+}
+
+// seed fills in the answers and results of a snapshot.
+func (p *projection) seed(snapshot repository.GradingSnapshot) {
+	results := make(map[domain.AnswerID]domain.Result, len(snapshot.Results))
+	for _, res := range snapshot.Results {
+		results[res.AnswerID] = res
+	}
+	for _, ans := range snapshot.Answers {
+		cell := Cell{AnswerID: ans.ID}
+		if res, ok := results[ans.ID]; ok {
+			cell = gradedCell(cell, res)
+		}
+		p.set(ans.StudentID, ans.QuestionID, cell)
+	}
+}
+
+func (p *projection) apply(event domain.Event) {
+	switch e := event.(type) {
+	case domain.AnswerSaved:
+		if current, ok := p.get(e.Answer.StudentID, e.Answer.QuestionID); ok && current.AnswerID == e.Answer.ID {
+			return
+		}
+		p.set(e.Answer.StudentID, e.Answer.QuestionID, Cell{AnswerID: e.Answer.ID})
+	case domain.AnswerDeleted:
+		p.set(e.StudentID, e.QuestionID, Cell{})
+	case domain.ResultSaved:
+		current, _ := p.get(e.StudentID, e.QuestionID)
+		if current.AnswerID != e.Result.AnswerID {
+			current = Cell{AnswerID: e.Result.AnswerID}
+		} else if current.gradedAt.After(e.Result.UpdatedAt) {
+			// A later grade was applied first.
+			return
+		}
+		p.set(e.StudentID, e.QuestionID, gradedCell(current, e.Result))
+	}
+}
+
+func gradedCell(cell Cell, res domain.Result) Cell {
+	cell.Graded = true
+	cell.Score = res.Score
+	cell.Completed = res.Completed
+	cell.gradedAt = res.UpdatedAt
+	return cell
+}
+
+func (p *projection) get(studentID domain.StudentID, questionID domain.QuestionID) (Cell, bool) {
+	col, ok := p.column[questionID]
+	if !ok {
+		return Cell{}, false
+	}
+	row, ok := p.rows[studentID]
+	if !ok {
+		return Cell{}, false
+	}
+	return row[col], true
+}
+
+// set replaces a cell and adjusts the counters. Answers to questions the
+// test no longer has are ignored.
+func (p *projection) set(studentID domain.StudentID, questionID domain.QuestionID, cell Cell) {
+	col, ok := p.column[questionID]
+	if !ok {
+		return
+	}
+	row, ok := p.rows[studentID]
+	if !ok {
+		if !cell.Answered() {
+			return
+		}
+		row = make([]Cell, len(p.questions))
+		p.rows[studentID] = row
+	}
+	p.counters.add(row[col], -1)
+	p.counters.add(cell, 1)
+	row[col] = cell
+}
+
+// gradebook copies the projection out for readers.
+func (p *projection) gradebook(testID domain.TestID) *Gradebook {
+	g := &Gradebook{
+		TestID:    testID,
+		Questions: append([]domain.QuestionID(nil), p.questions...),
+		MaxScore:  p.maxScore,
+		Rows:      make([]GradebookRow, 0, len(p.rows)),
+		Counters:  p.counters,
+		BuiltAt:   p.builtAt,
+	}
+	assigned := make(map[domain.StudentID]bool, len(p.assigned))
+	for _, studentID := range p.assigned {
+		assigned[studentID] = true
+		g.Rows = append(g.Rows, GradebookRow{StudentID: studentID, Assigned: true, Cells: append([]Cell(nil), p.rows[studentID]...)})
+	}
+	others := make([]domain.StudentID, 0)
+	for studentID := range p.rows {
+		if !assigned[studentID] {
+			others = append(others, studentID)
+		}
+	}
+	sort.Slice(others, func(i, j int) bool { return others[i] < others[j] })
+	for _, studentID := range others {
+		g.Rows = append(g.Rows, GradebookRow{StudentID: studentID, Cells: append([]Cell(nil), p.rows[studentID]...)})
+	}
+	return g
+}
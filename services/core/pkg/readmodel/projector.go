@@ -0,0 +1,142 @@
+// Package readmodel maintains denormalized projections of tests, such as
+// gradebooks and dashboard counters, from the domain events the assessment
+// workflow records, so analytics read them instead of the repositories.
+package readmodel
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// Source is the storage a projection of a test is built from.
+type Source interface {
+	GetTest(ctx context.Context, testID domain.TestID) (*domain.Test, error)
+	ListQuestions(ctx context.Context, testID domain.TestID) ([]domain.Question, error)
+	SnapshotGrading(ctx context.Context, testID domain.TestID) (repository.GradingSnapshot, error)
+}
+
+// Projector keeps a projection per test. A projection is built from the
+// source when a test is first read and then follows the events applied to
+// the projector. Writes made by other processes sharing the storage produce
+// no events here, so a projection older than maxAge is built again on the
+// next read; a zero maxAge keeps projections until their test changes.
+type Projector struct {
+	source Source
+	maxAge time.Duration
+	now    func() time.Time
+
+	mu    sync.Mutex
+	tests map[domain.TestID]*projection
+}
+
+// NewProjector creates a projector reading from source.
+func NewProjector(source Source, maxAge time.Duration) *Projector {
+	return &Projector{
+		source: source,
+		maxAge: maxAge,
+		now:    time.Now,
+		tests:  make(map[domain.TestID]*projection),
+	}
+}
+
+// Apply updates the projection of the event's test. Events of tests that are
+// not projected yet are dropped: their projection will be built from storage,
+// which already holds the change.
+func (p *Projector) Apply(event domain.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	testID := event.Aggregate()
+	switch e := event.(type) {
+	case domain.TestCreated:
+		p.tests[testID] = newProjection(e.Test, e.Questions, p.now())
+	case domain.TestChanged, domain.AssigneesChanged:
+		delete(p.tests, testID)
+	default:
+		if proj, ok := p.tests[testID]; ok {
+			proj.apply(event)
+		}
+	}
+}
+
+// Reset drops every projection, so each is built from storage again on its
+// next read. It returns how many were dropped.
+func (p *Projector) Reset() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := len(p.tests)
+	p.tests = make(map[domain.TestID]*projection)
+	return n
+}
+
+// Gradebook returns the gradebook of a test.
+func (p *Projector) Gradebook(ctx context.Context, testID domain.TestID) (*Gradebook, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	proj, err := p.load(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	return proj.gradebook(testID), nil
+}
+
+// Counters returns the dashboard counters of a test without copying its
+// gradebook.
+func (p *Projector) Counters(ctx context.Context, testID domain.TestID) (Counters, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	proj, err := p.load(ctx, testID)
+	if err != nil {
+		return Counters{}, err
+	}
+	return proj.counters, nil
+}
+
+// load returns the current projection of a test, building it when it is
+// missing or expired. Expired projections of other tests are dropped at the
+// same time, so only recently read tests stay in memory. The caller holds mu,
+// which keeps events from slipping in between reading storage and
+// installing the projection.
+func (p *Projector) load(ctx context.Context, testID domain.TestID) (*projection, error) {
+	now := p.now()
+	if proj, ok := p.tests[testID]; ok && !p.expired(proj, now) {
+		return proj, nil
+	}
+
+	test, err := p.source.GetTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	if test == nil {
+		return nil, errs.ErrTestNotFound
+	}
+	questions, err := p.source.ListQuestions(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := p.source.SnapshotGrading(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	proj := newProjection(*test, questions, now)
+	proj.seed(snapshot)
+
+	for id, other := range p.tests {
+		if p.expired(other, now) {
+			delete(p.tests, id)
+		}
+	}
+	p.tests[testID] = proj
+	return proj, nil
+}
+
+func (p *Projector) expired(proj *projection, now time.Time) bool {
+	return p.maxAge > 0 && now.Sub(proj.builtAt) >= p.maxAge
+}
@@ -0,0 +1,102 @@
+package readmodel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+)
+
+func TestProjectorFollowsEventsAndStorage(t *testing.T) {
+	ctx := context.Background()
+	fx := fixtures.NewSchool().WithStudents(3).Build()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := NewProjector(fx.Repo, time.Minute)
+	p.now = func() time.Time { return now }
+
+	test := &domain.Test{ID: "test-001", TeacherID: fx.Teacher(0), Title: "Quiz", AssignedTo: []domain.StudentID{fx.Student(1), fx.Student(0)}, CreatedAt: now}
+	questions := []domain.Question{
+		{ID: "q-2", TestID: test.ID, Sequence: 2, Prompt: "?", Points: 5},
+		{ID: "q-1", TestID: test.ID, Sequence: 1, Prompt: "?", Points: 5},
+	}
+	if err := fx.Repo.CreateTest(ctx, test, questions, test.AssignedTo); err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	save := func(answer domain.Answer, result *domain.Result) {
+		t.Helper()
+		if err := fx.Repo.UpsertAnswer(ctx, &answer); err != nil {
+			t.Fatalf("UpsertAnswer failed: %v", err)
+		}
+		p.Apply(domain.AnswerSaved{Answer: answer})
+		if result == nil {
+			return
+		}
+		if err := fx.Repo.SaveResult(ctx, result); err != nil {
+			t.Fatalf("SaveResult failed: %v", err)
+		}
+		p.Apply(domain.ResultSaved{TestID: answer.TestID, QuestionID: answer.QuestionID, StudentID: answer.StudentID, Result: *result})
+	}
+
+	// Built from storage on first read.
+	save(domain.Answer{ID: "a-1", TestID: test.ID, QuestionID: "q-1", StudentID: fx.Student(0)}, &domain.Result{ID: "r-1", AnswerID: "a-1", Score: 4, Completed: true, UpdatedAt: now})
+	gradebook, err := p.Gradebook(ctx, test.ID)
+	if err != nil {
+		t.Fatalf("Gradebook failed: %v", err)
+	}
+	if len(gradebook.Questions) != 2 || gradebook.Questions[0] != "q-1" || gradebook.MaxScore != 10 {
+		t.Fatalf("expected questions in sequence order worth 10 points, got %+v", gradebook)
+	}
+	if len(gradebook.Rows) != 2 || gradebook.Rows[0].StudentID != fx.Student(1) || gradebook.Rows[1].Cells[0].Score != 4 {
+		t.Fatalf("expected assigned students in assignment order with the seeded grade, got %+v", gradebook.Rows)
+	}
+
+	// Followed by events afterwards.
+	save(domain.Answer{ID: "a-2", TestID: test.ID, QuestionID: "q-2", StudentID: fx.Student(0)}, nil)
+	save(domain.Answer{ID: "a-3", TestID: test.ID, QuestionID: "q-1", StudentID: fx.Student(2)}, &domain.Result{ID: "r-3", AnswerID: "a-3", Score: 2, UpdatedAt: now})
+	counters, err := p.Counters(ctx, test.ID)
+	if err != nil {
+		t.Fatalf("Counters failed: %v", err)
+	}
+	if counters != (Counters{Answers: 3, Graded: 2, Completed: 1, TotalScore: 6}) || counters.Ungraded() != 1 || counters.MeanScore() != 3 {
+		t.Fatalf("unexpected counters %+v", counters)
+	}
+	gradebook, _ = p.Gradebook(ctx, test.ID)
+	if last := gradebook.Rows[2]; last.StudentID != fx.Student(2) || last.Assigned || last.Score() != 2 {
+		t.Fatalf("expected the unassigned student who answered last, got %+v", last)
+	}
+	if low, high := gradebook.ScoreRange(); low != 2 || high != 4 {
+		t.Fatalf("expected scores between 2 and 4, got %d and %d", low, high)
+	}
+
+	// A grade arriving after a later one is dropped.
+	p.Apply(domain.ResultSaved{TestID: test.ID, QuestionID: "q-1", StudentID: fx.Student(0), Result: domain.Result{AnswerID: "a-1", Score: 1, UpdatedAt: now.Add(-time.Second)}})
+	if err := fx.Repo.DeleteAnswer(ctx, test.ID, "q-1", fx.Student(2)); err != nil {
+		t.Fatalf("DeleteAnswer failed: %v", err)
+	}
+	p.Apply(domain.AnswerDeleted{TestID: test.ID, QuestionID: "q-1", StudentID: fx.Student(2)})
+	if counters, _ := p.Counters(ctx, test.ID); counters != (Counters{Answers: 2, Graded: 1, Completed: 1, TotalScore: 4}) {
+		t.Fatalf("expected the stale grade ignored and the deletion applied, got %+v", counters)
+	}
+
+	// Writes without events show up once the projection expires.
+	if err := fx.Repo.SaveResult(ctx, &domain.Result{ID: "r-2", AnswerID: "a-2", Score: 5}); err != nil {
+		t.Fatalf("SaveResult failed: %v", err)
+	}
+	if counters, _ := p.Counters(ctx, test.ID); counters.Graded != 1 {
+		t.Fatalf("expected the fresh projection to be served, got %+v", counters)
+	}
+	now = now.Add(time.Minute)
+	if counters, _ := p.Counters(ctx, test.ID); counters != (Counters{Answers: 2, Graded: 2, Completed: 1, TotalScore: 9}) {
+		t.Fatalf("expected the expired projection to be rebuilt from storage, got %+v", counters)
+	}
+
+	p.Apply(domain.TestChanged{TestID: test.ID})
+	if _, ok := p.tests[test.ID]; ok {
+		t.Fatalf("expected a changed test to be dropped")
+	}
+	if _, err := p.Gradebook(ctx, "missing"); err == nil {
+		t.Fatalf("expected an unknown test to fail")
+	}
+}
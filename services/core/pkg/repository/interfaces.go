@@ -1,45 +1,384 @@
 package repository
 
-import "github.com/sky0621/go_work_sample/core/pkg/domain"
+import (
+	"context"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+// Each repository is split into a Reader and a Writer so components can be
+// wired with only the access they need, for example against a read-only
+// replica. The Repository interfaces combine both halves.
+//
+// List methods take a PageRequest and return one Page; pass All for the
+// whole list.
+
+// OrganizationReader exposes read access to the hierarchy.
+type OrganizationReader interface {
+	ListSchools(ctx context.Context, page PageRequest) (Page[domain.School], error)
+	GetSchool(ctx context.Context, id domain.SchoolID) (*domain.School, error)
+	GetGrade(ctx context.Context, id domain.GradeID) (*domain.Grade, error)
+	GetClass(ctx context.Context, id domain.ClassID) (*domain.Class, error)
+	GetTeacher(ctx context.Context, id domain.TeacherID) (*domain.Teacher, error)
+	GetStudent(ctx context.Context, id domain.StudentID) (*domain.Student, error)
+
+	ListGrades(ctx context.Context, schoolID domain.SchoolID, page PageRequest) (Page[domain.Grade], error)
+	ListClasses(ctx context.Context, gradeID domain.GradeID, page PageRequest) (Page[domain.Class], error)
+	ListStudents(ctx context.Context, classID domain.ClassID, page PageRequest) (Page[domain.Student], error)
+	ListTeachers(ctx context.Context, schoolID domain.SchoolID, page PageRequest) (Page[domain.Teacher], error)
+	// FindStudentsByEmail returns the students whose own or guardian email
+	// matches, ignoring case.
+	FindStudentsByEmail(ctx context.Context, email string) ([]domain.Student, error)
+}
+
+// OrganizationWriter updates hierarchy records.
+type OrganizationWriter interface {
+	UpdateSchool(ctx context.Context, school *domain.School) error
+	UpdateTeacher(ctx context.Context, teacher *domain.Teacher) error
+	UpdateStudent(ctx context.Context, student *domain.Student) error
+	// CreateStudents adds students to an existing class, all of them or
+	// none. It fails when any of their IDs is taken.
+	CreateStudents(ctx context.Context, classID domain.ClassID, students []domain.Student) error
+}
 
 // OrganizationRepository exposes hierarchy data access.
 type OrganizationRepository interface {
-	ListSchools() ([]domain.School, error)
-	GetSchool(id domain.SchoolID) (*domain.School, error)
-	GetGrade(id domain.GradeID) (*domain.Grade, error)
-	GetClass(id domain.ClassID) (*domain.Class, error)
-	GetTeacher(id domain.TeacherID) (*domain.Teacher, error)
-	GetStudent(id domain.StudentID) (*domain.Student, error)
+	OrganizationReader
+	OrganizationWriter
+}
+
+// TestReader reads tests and questions.
+type TestReader interface {
+	GetTest(ctx context.Context, id domain.TestID) (*domain.Test, error)
+	ListTestsByTeacher(ctx context.Context, teacherID domain.TeacherID, page PageRequest) (Page[domain.Test], error)
+	ListTestsForStudent(ctx context.Context, studentID domain.StudentID, page PageRequest) (Page[domain.Test], error)
+	// ListTestsInWindow returns the tests of every teacher scheduled for a
+	// window overlapping [from, to).
+	ListTestsInWindow(ctx context.Context, from, to time.Time) ([]domain.Test, error)
+	ListQuestions(ctx context.Context, testID domain.TestID) ([]domain.Question, error)
+	// GetQuestion returns a question of the test, or nil when the test has
+	// no such question.
+	GetQuestion(ctx context.Context, testID domain.TestID, questionID domain.QuestionID) (*domain.Question, error)
+	HasQuestion(ctx context.Context, testID domain.TestID, questionID domain.QuestionID) (bool, error)
+	IsStudentAssigned(ctx context.Context, testID domain.TestID, studentID domain.StudentID) (bool, error)
+}
 
-	ListGrades(schoolID domain.SchoolID) ([]domain.Grade, error)
-	ListClasses(gradeID domain.GradeID) ([]domain.Class, error)
-	ListStudents(classID domain.ClassID) ([]domain.Student, error)
-	ListTeachers(schoolID domain.SchoolID) ([]domain.Teacher, error)
+// TestWriter creates and updates tests and questions.
+type TestWriter interface {
+	CreateTest(ctx context.Context, test *domain.Test, questions []domain.Question, studentIDs []domain.StudentID) error
+	UpdateTest(ctx context.Context, test *domain.Test) error
+	UpdateQuestion(ctx context.Context, question *domain.Question) error
+	// ReorderQuestions sets the sequences of questions of the test in one
+	// write. If any question is not the test's, no sequence is changed.
+	ReorderQuestions(ctx context.Context, testID domain.TestID, sequences map[domain.QuestionID]int) error
+	// UpdateAssignments assigns the test to the students in add and
+	// unassigns those in remove. Students already assigned or not assigned
+	// are left as they are.
+	UpdateAssignments(ctx context.Context, testID domain.TestID, add, remove []domain.StudentID) error
 }
 
 // TestRepository manages tests and questions.
 type TestRepository interface {
-	CreateTest(test *domain.Test, questions []domain.Question, studentIDs []domain.StudentID) error
-	UpdateTest(test *domain.Test) error
-	GetTest(id domain.TestID) (*domain.Test, error)
-	ListTestsByTeacher(teacherID domain.TeacherID) ([]domain.Test, error)
-	ListTestsForStudent(studentID domain.StudentID) ([]domain.Test, error)
-	ListQuestions(testID domain.TestID) ([]domain.Question, error)
-	IsStudentAssigned(testID domain.TestID, studentID domain.StudentID) (bool, error)
+	TestReader
+	TestWriter
+}
+
+// AnswerReader reads student answers.
+type AnswerReader interface {
+	GetAnswer(ctx context.Context, testID domain.TestID, questionID domain.QuestionID, studentID domain.StudentID) (*domain.Answer, error)
+	ListAnswers(ctx context.Context, testID domain.TestID, studentID domain.StudentID) ([]domain.Answer, error)
+	ListAnswersByTest(ctx context.Context, testID domain.TestID, page PageRequest) (Page[domain.Answer], error)
+}
+
+// AnswerWriter stores student answers.
+type AnswerWriter interface {
+	UpsertAnswer(ctx context.Context, answer *domain.Answer) error
+	// DeleteAnswer removes a student's answer to a question together with
+	// its result. Deleting an answer that does not exist is not an error.
+	DeleteAnswer(ctx context.Context, testID domain.TestID, questionID domain.QuestionID, studentID domain.StudentID) error
 }
 
 // AnswerRepository persists student answers.
 type AnswerRepository interface {
-	UpsertAnswer(answer *domain.Answer) error
-	GetAnswer(testID domain.TestID, questionID domain.QuestionID, studentID domain.StudentID) (*domain.Answer, error)
-	ListAnswers(testID domain.TestID, studentID domain.StudentID) ([]domain.Answer, error)
-	ListAnswersByTest(testID domain.TestID) ([]domain.Answer, error)
+	AnswerReader
+	AnswerWriter
+}
+
+// ResultReader reads grading results.
+type ResultReader interface {
+	GetResult(ctx context.Context, answerID domain.AnswerID) (*domain.Result, error)
+	ListResultsByTest(ctx context.Context, testID domain.TestID, page PageRequest) (Page[domain.Result], error)
+	ListResultsByStudent(ctx context.Context, testID domain.TestID, studentID domain.StudentID) ([]domain.Result, error)
+	// SnapshotGrading reads the answers of a test and their results as of a
+	// single moment, so reports never pair answers with results graded
+	// after the answers were read.
+	SnapshotGrading(ctx context.Context, testID domain.TestID) (GradingSnapshot, error)
+}
+
+// GradingSnapshot holds the answers of a test and their results, oldest
+// first, as read at one point in time.
+type GradingSnapshot struct {
+	Answers []domain.Answer
+	Results []domain.Result
+}
+
+// ResultWriter stores grading results.
+type ResultWriter interface {
+	SaveResult(ctx context.Context, result *domain.Result) error
+	// SaveResults saves every result or, on error, none of them.
+	SaveResults(ctx context.Context, results []domain.Result) error
 }
 
 // ResultRepository persists grading results.
 type ResultRepository interface {
-	SaveResult(result *domain.Result) error
-	GetResult(answerID domain.AnswerID) (*domain.Result, error)
-	ListResultsByTest(testID domain.TestID) ([]domain.Result, error)
-	ListResultsByStudent(testID domain.TestID, studentID domain.StudentID) ([]domain.Result, error)
+	ResultReader
+	ResultWriter
+}
+
+// NotificationReader reads in-app notifications.
+type NotificationReader interface {
+	ListNotifications(ctx context.Context, role domain.Role, recipientID string, page PageRequest) (Page[domain.Notification], error)
+}
+
+// NotificationWriter stores in-app notifications and their read state.
+type NotificationWriter interface {
+	SaveNotification(ctx context.Context, notification *domain.Notification) error
+	MarkNotificationsRead(ctx context.Context, role domain.Role, recipientID string, ids []domain.NotificationID, at time.Time) error
+}
+
+// NotificationRepository persists in-app notifications per user.
+type NotificationRepository interface {
+	NotificationReader
+	NotificationWriter
+}
+
+// DigestReader reads the notifications queued for digest emails.
+type DigestReader interface {
+	// ListDigestItems returns every queued item, oldest first.
+	ListDigestItems(ctx context.Context) ([]domain.DigestItem, error)
+}
+
+// DigestWriter queues notifications for digest emails and removes them once
+// sent.
+type DigestWriter interface {
+	QueueDigestItem(ctx context.Context, item *domain.DigestItem) error
+	// DeleteDigestItems removes the items with the given IDs; unknown IDs are
+	// ignored.
+	DeleteDigestItems(ctx context.Context, ids []domain.DigestItemID) error
+}
+
+// DigestRepository persists the queue of digest emails.
+type DigestRepository interface {
+	DigestReader
+	DigestWriter
+}
+
+// TestSessionReader reads students' in-progress test state.
+type TestSessionReader interface {
+	GetTestSession(ctx context.Context, testID domain.TestID, studentID domain.StudentID) (*domain.TestSession, error)
+}
+
+// TestSessionWriter stores students' in-progress test state.
+type TestSessionWriter interface {
+	SaveTestSession(ctx context.Context, session *domain.TestSession) error
+}
+
+// SubmissionReader reads students' finalized tests.
+type SubmissionReader interface {
+	GetSubmission(ctx context.Context, testID domain.TestID, studentID domain.StudentID) (*domain.Submission, error)
+	ListSubmissionsByTest(ctx context.Context, testID domain.TestID) ([]domain.Submission, error)
+}
+
+// SubmissionWriter records students finalizing tests.
+type SubmissionWriter interface {
+	SaveSubmission(ctx context.Context, submission *domain.Submission) error
+}
+
+// SubmissionRepository persists students' finalized tests.
+type SubmissionRepository interface {
+	SubmissionReader
+	SubmissionWriter
+}
+
+// TestSessionRepository persists students' in-progress test state.
+type TestSessionRepository interface {
+	TestSessionReader
+	TestSessionWriter
+}
+
+// RubricReader reads teachers' rubrics and feedback templates.
+type RubricReader interface {
+	GetRubric(ctx context.Context, id domain.RubricID) (*domain.Rubric, error)
+	ListRubrics(ctx context.Context, teacherID domain.TeacherID, page PageRequest) (Page[domain.Rubric], error)
+	ListFeedbackTemplates(ctx context.Context, teacherID domain.TeacherID, page PageRequest) (Page[domain.FeedbackTemplate], error)
+}
+
+// RubricWriter stores teachers' rubrics and feedback templates.
+type RubricWriter interface {
+	// SaveRubrics saves every rubric and template or, on error, none of them.
+	SaveRubrics(ctx context.Context, rubrics []domain.Rubric, templates []domain.FeedbackTemplate) error
+}
+
+// RubricRepository persists teachers' rubrics and feedback templates.
+type RubricRepository interface {
+	RubricReader
+	RubricWriter
+}
+
+// QuestionBankReader reads teachers' question banks.
+type QuestionBankReader interface {
+	GetBankQuestion(ctx context.Context, id domain.BankQuestionID) (*domain.BankQuestion, error)
+	ListBankQuestions(ctx context.Context, teacherID domain.TeacherID, page PageRequest) (Page[domain.BankQuestion], error)
+}
+
+// QuestionBankWriter stores teachers' question banks.
+type QuestionBankWriter interface {
+	SaveBankQuestion(ctx context.Context, question *domain.BankQuestion) error
+	DeleteBankQuestion(ctx context.Context, id domain.BankQuestionID) error
+}
+
+// QuestionBankRepository persists teachers' question banks.
+type QuestionBankRepository interface {
+	QuestionBankReader
+	QuestionBankWriter
+}
+
+// QuestionCommentReader reads authoring comments on questions.
+type QuestionCommentReader interface {
+	GetQuestionComment(ctx context.Context, id domain.QuestionCommentID) (*domain.QuestionComment, error)
+	ListQuestionComments(ctx context.Context, testID domain.TestID, questionID domain.QuestionID) ([]domain.QuestionComment, error)
+}
+
+// QuestionCommentWriter stores authoring comments on questions.
+type QuestionCommentWriter interface {
+	SaveQuestionComment(ctx context.Context, comment *domain.QuestionComment) error
+}
+
+// QuestionCommentRepository persists authoring comments on questions.
+type QuestionCommentRepository interface {
+	QuestionCommentReader
+	QuestionCommentWriter
+}
+
+// AnswerCommentReader reads the feedback threads on answers.
+type AnswerCommentReader interface {
+	GetAnswerComment(ctx context.Context, id domain.AnswerCommentID) (*domain.AnswerComment, error)
+	// ListAnswerComments returns the comments on an answer, oldest first.
+	ListAnswerComments(ctx context.Context, testID domain.TestID, answerID domain.AnswerID) ([]domain.AnswerComment, error)
+	// ListAnswerCommentsByStudent returns the comments on every answer of
+	// the student, oldest first.
+	ListAnswerCommentsByStudent(ctx context.Context, studentID domain.StudentID) ([]domain.AnswerComment, error)
+}
+
+// AnswerCommentWriter stores the feedback threads on answers.
+type AnswerCommentWriter interface {
+	SaveAnswerComment(ctx context.Context, comment *domain.AnswerComment) error
+}
+
+// AnswerCommentRepository persists the feedback threads on answers.
+type AnswerCommentRepository interface {
+	AnswerCommentReader
+	AnswerCommentWriter
+}
+
+// DelegationReader reads teachers' delegations to substitutes.
+type DelegationReader interface {
+	GetDelegation(ctx context.Context, id domain.DelegationID) (*domain.Delegation, error)
+	ListDelegationsByTeacher(ctx context.Context, teacherID domain.TeacherID, page PageRequest) (Page[domain.Delegation], error)
+	ListDelegationsForDelegate(ctx context.Context, delegateID domain.TeacherID) ([]domain.Delegation, error)
+}
+
+// DelegationWriter stores and removes delegations.
+type DelegationWriter interface {
+	SaveDelegation(ctx context.Context, delegation *domain.Delegation) error
+	DeleteDelegation(ctx context.Context, id domain.DelegationID) error
+	// DeleteExpiredDelegations removes every delegation no longer active at
+	// now and returns the removed ones.
+	DeleteExpiredDelegations(ctx context.Context, now time.Time) ([]domain.Delegation, error)
+}
+
+// DelegationRepository persists teachers' delegations to substitutes.
+type DelegationRepository interface {
+	DelegationReader
+	DelegationWriter
+}
+
+// DeviceSessionReader reads the sign-ins of students and guardians.
+type DeviceSessionReader interface {
+	GetDeviceSession(ctx context.Context, id domain.DeviceSessionID) (*domain.DeviceSession, error)
+	// ListDeviceSessions returns the sessions of the student and of their
+	// guardians, oldest first.
+	ListDeviceSessions(ctx context.Context, studentID domain.StudentID) ([]domain.DeviceSession, error)
+}
+
+// DeviceSessionWriter stores and prunes sign-ins.
+type DeviceSessionWriter interface {
+	SaveDeviceSession(ctx context.Context, session *domain.DeviceSession) error
+	// DeleteExpiredDeviceSessions removes every session whose token expired
+	// by now and returns the removed ones.
+	DeleteExpiredDeviceSessions(ctx context.Context, now time.Time) ([]domain.DeviceSession, error)
+}
+
+// DeviceSessionRepository persists the sign-ins of students and guardians.
+type DeviceSessionRepository interface {
+	DeviceSessionReader
+	DeviceSessionWriter
+}
+
+// DistrictReader reads districts and their staff.
+type DistrictReader interface {
+	GetDistrict(ctx context.Context, id domain.DistrictID) (*domain.District, error)
+	ListDistricts(ctx context.Context, page PageRequest) (Page[domain.District], error)
+	ListSchoolsByDistrict(ctx context.Context, districtID domain.DistrictID, page PageRequest) (Page[domain.School], error)
+	GetDistrictStaff(ctx context.Context, id domain.DistrictStaffID) (*domain.DistrictStaff, error)
+}
+
+// DistrictWriter stores districts and their staff. Schools join a district
+// through OrganizationWriter.UpdateSchool.
+type DistrictWriter interface {
+	SaveDistrict(ctx context.Context, district *domain.District) error
+	SaveDistrictStaff(ctx context.Context, staff *domain.DistrictStaff) error
+}
+
+// DistrictRepository persists districts and their staff.
+type DistrictRepository interface {
+	DistrictReader
+	DistrictWriter
+}
+
+// AuditFilter narrows a query of the audit trail. Zero fields match every
+// entry; Since and Until bound CreatedAt, Until exclusively.
+type AuditFilter struct {
+	Action      domain.AuditAction
+	PrincipalID string
+	TestID      domain.TestID
+	Since       time.Time
+	Until       time.Time
+}
+
+// Match reports whether the entry passes the filter.
+func (f AuditFilter) Match(e domain.AuditEntry) bool {
+	return (f.Action == "" || e.Action == f.Action) &&
+		(f.PrincipalID == "" || e.PrincipalID == f.PrincipalID) &&
+		(f.TestID == "" || e.TestID == f.TestID) &&
+		(f.Since.IsZero() || !e.CreatedAt.Before(f.Since)) &&
+		(f.Until.IsZero() || e.CreatedAt.Before(f.Until))
+}
+
+// AuditReader queries the audit trail.
+type AuditReader interface {
+	// ListAuditEntries returns the entries matching filter, newest first.
+	ListAuditEntries(ctx context.Context, filter AuditFilter, page PageRequest) (Page[domain.AuditEntry], error)
+}
+
+// AuditWriter appends to the audit trail. Entries are never changed.
+type AuditWriter interface {
+	SaveAuditEntry(ctx context.Context, entry *domain.AuditEntry) error
+}
+
+// AuditRepository persists the audit trail.
+type AuditRepository interface {
+	AuditReader
+	AuditWriter
 }
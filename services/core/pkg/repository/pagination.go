@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"encoding/base64"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+)
+
+// PageRequest selects one page of a list. Lists are ordered by creation time
+// with ties broken on ID, and Cursor resumes after the last item of the
+// previous page. A zero Limit selects every remaining item.
+type PageRequest struct {
+	Limit  int
+	Cursor string
+}
+
+// All selects every item of a list, for callers that need the whole set.
+var All = PageRequest{}
+
+// Page is one page of a list. NextCursor is empty on the last page.
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+}
+
+// EncodeCursor builds the opaque cursor pointing just after an item.
+func EncodeCursor(createdAt time.Time, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(createdAt.UTC().Format(time.RFC3339Nano) + "|" + id))
+}
+
+// DecodeCursor reverses EncodeCursor, failing with errs.ErrInvalidCursor.
+func DecodeCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", errs.ErrInvalidCursor
+	}
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return time.Time{}, "", errs.ErrInvalidCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return time.Time{}, "", errs.ErrInvalidCursor
+	}
+	return createdAt, id, nil
+}
+
+// Paginate cuts the page selected by req out of items, which must already be
+// in list order: oldest first, or newest first when newestFirst is set. key
+// returns an item's creation time and ID.
+func Paginate[T any](items []T, req PageRequest, newestFirst bool, key func(T) (time.Time, string)) (Page[T], error) {
+	start := 0
+	if req.Cursor != "" {
+		afterAt, afterID, err := DecodeCursor(req.Cursor)
+		if err != nil {
+			return Page[T]{}, err
+		}
+		start = sort.Search(len(items), func(i int) bool {
+			at, id := key(items[i])
+			if newestFirst {
+				return at.Before(afterAt) || (at.Equal(afterAt) && id < afterID)
+			}
+			return at.After(afterAt) || (at.Equal(afterAt) && id > afterID)
+		})
+	}
+
+	end := len(items)
+	if req.Limit > 0 && start+req.Limit < end {
+		end = start + req.Limit
+	}
+	page := Page[T]{Items: items[start:end]}
+	if end < len(items) {
+		page.NextCursor = EncodeCursor(key(items[end-1]))
+	}
+	return page, nil
+}
+
+// Collect unwraps the items of a list fetched with All, so callers needing
+// the whole list can write
+//
+//	tests, err := repository.Collect(repo.ListTestsByTeacher(teacherID, repository.All))
+func Collect[T any](page Page[T], err error) ([]T, error) {
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
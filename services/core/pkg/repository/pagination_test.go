@@ -0,0 +1,80 @@
+package repository_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+type item struct {
+	id string
+	at time.Time
+}
+
+func itemKey(i item) (time.Time, string) { return i.at, i.id }
+
+func TestPaginateWalksEveryItemOnce(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	// Items share creation times so pages must break ties on ID.
+	items := []item{
+		{"a", base}, {"b", base}, {"c", base},
+		{"d", base.Add(time.Second)}, {"e", base.Add(time.Second)},
+	}
+
+	for _, newestFirst := range []bool{false, true} {
+		ordered := items
+		if newestFirst {
+			ordered = make([]item, len(items))
+			for i := range items {
+				ordered[i] = items[len(items)-1-i]
+			}
+		}
+
+		var seen []string
+		req := repository.PageRequest{Limit: 2}
+		for pages := 0; ; pages++ {
+			if pages > len(items) {
+				t.Fatalf("newestFirst=%v: pagination did not terminate", newestFirst)
+			}
+			page, err := repository.Paginate(ordered, req, newestFirst, itemKey)
+			if err != nil {
+				t.Fatalf("Paginate failed: %v", err)
+			}
+			for _, it := range page.Items {
+				seen = append(seen, it.id)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			req.Cursor = page.NextCursor
+		}
+
+		if len(seen) != len(ordered) {
+			t.Fatalf("newestFirst=%v: expected %d items, got %v", newestFirst, len(ordered), seen)
+		}
+		for i := range ordered {
+			if seen[i] != ordered[i].id {
+				t.Fatalf("newestFirst=%v: expected order %v, got %v", newestFirst, ordered, seen)
+			}
+		}
+	}
+}
+
+func TestPaginateAllAndInvalidCursor(t *testing.T) {
+	items := []item{{"a", time.Now()}, {"b", time.Now()}}
+
+	page, err := repository.Paginate(items, repository.All, false, itemKey)
+	if err != nil {
+		t.Fatalf("Paginate failed: %v", err)
+	}
+	if len(page.Items) != 2 || page.NextCursor != "" {
+		t.Fatalf("expected every item and no cursor, got %+v", page)
+	}
+
+	if _, err := repository.Paginate(items, repository.PageRequest{Cursor: "not-a-cursor"}, false, itemKey); !errors.Is(err, errs.ErrInvalidCursor) {
+		t.Fatalf("expected ErrInvalidCursor, got %v", err)
+	}
+}
@@ -0,0 +1,515 @@
+package repotest
+
+import (
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+var assessmentCases = []suiteCase{
+	{"Tests/CreatesAndGets", testCreatesTests},
+	{"Tests/RefusesInvalidCreates", testRefusesInvalidTests},
+	{"Tests/Updates", testUpdatesTests},
+	{"Tests/UpdatesAssignments", testUpdatesAssignments},
+	{"Tests/ReordersQuestionsAllOrNone", testReordersQuestions},
+	{"Tests/ListsByTeacherStudentAndWindow", testListsTests},
+	{"Tests/ReturnsCopies", testReturnsCopies},
+	{"Answers/UpsertsAndKeepsRevisions", testUpsertsAnswers},
+	{"Answers/ListsByStudentAndTest", testListsAnswers},
+	{"Answers/DeletesWithResult", testDeletesAnswers},
+	{"Results/SavesAndLists", testSavesResults},
+	{"Results/SavesBatchAllOrNone", testSavesResultBatch},
+	{"Results/SnapshotsGrading", testSnapshotsGrading},
+	{"Sessions/SavesPerStudent", testSavesSessions},
+	{"Submissions/SavesAndLists", testSavesSubmissions},
+}
+
+func testCreatesTests(t *testing.T, e env) {
+	passing := domain.Score(60)
+	opens, closes := minutes(60), minutes(120)
+	test := domain.Test{
+		ID:              "test-1",
+		TeacherID:       e.fx.Teacher(0),
+		Title:           "Fractions",
+		Instructions:    "<p>Show your work.</p>",
+		Sections:        []domain.Section{{ID: "section-1", Title: "Part A"}},
+		PassingScore:    &passing,
+		Published:       true,
+		OpensAt:         &opens,
+		ClosesAt:        &closes,
+		Limits:          domain.SubmissionLimits{PerQuestionPerMinute: 3},
+		DurationMinutes: 45,
+		Extensions: []domain.DeadlineExtension{{
+			StudentID: e.fx.Student(0), ClosesAt: minutes(180), Reason: "ill", GrantedBy: e.fx.Teacher(0), GrantedAt: minutes(1),
+		}},
+		Version:   1,
+		CreatedAt: minutes(0),
+		UpdatedAt: minutes(0),
+	}
+	questions := []domain.Question{
+		{ID: "test-1-q2", TestID: "test-1", SectionID: "section-1", Sequence: 2, Prompt: "1/2 + 1/4", Points: 5, Type: domain.QuestionType("text"), CreatedAt: minutes(0)},
+		{ID: "test-1-q1", TestID: "test-1", SectionID: "section-1", Sequence: 1, Prompt: "Pick one", Points: 10,
+			Choices: []domain.Choice{{Key: "a", Label: "1/2"}, {Key: "b", Label: "3/4"}}, ExpectedResponse: "b", CreatedAt: minutes(0)},
+	}
+	check(t, e.store.CreateTest(e.ctx, &test, questions, []domain.StudentID{e.fx.Student(0), e.fx.Student(1)}), "CreateTest")
+
+	got, err := e.store.GetTest(e.ctx, "test-1")
+	check(t, err, "GetTest")
+	switch {
+	case got == nil:
+		t.Fatalf("expected the created test")
+	case got.Title != test.Title || got.Instructions != test.Instructions || !got.Published || got.DurationMinutes != 45 || got.Version != 1:
+		t.Fatalf("expected the test's fields to be stored, got %+v", got)
+	case got.PassingScore == nil || *got.PassingScore != passing:
+		t.Fatalf("expected the passing score to be stored, got %v", got.PassingScore)
+	case got.OpensAt == nil || !got.OpensAt.Equal(opens) || got.ClosesAt == nil || !got.ClosesAt.Equal(closes):
+		t.Fatalf("expected the window to be stored, got %v - %v", got.OpensAt, got.ClosesAt)
+	case len(got.Sections) != 1 || got.Limits.PerQuestionPerMinute != 3:
+		t.Fatalf("expected sections and limits to be stored, got %+v", got)
+	case len(got.Extensions) != 1 || got.Extensions[0].Reason != "ill" || !got.Extensions[0].ClosesAt.Equal(minutes(180)):
+		t.Fatalf("expected the extensions to be stored, got %+v", got.Extensions)
+	}
+	sameIDs(t, "AssignedTo", ids(got.AssignedTo, func(s domain.StudentID) domain.StudentID { return s }), string(e.fx.Student(0)), string(e.fx.Student(1)))
+
+	stored, err := e.store.ListQuestions(e.ctx, "test-1")
+	check(t, err, "ListQuestions")
+	sameIDs(t, "ListQuestions by sequence", ids(stored, func(q domain.Question) domain.QuestionID { return q.ID }), "test-1-q1", "test-1-q2")
+	if len(stored[0].Choices) != 2 || stored[0].ExpectedResponse != "b" {
+		t.Fatalf("expected the choices to be stored, got %+v", stored[0])
+	}
+
+	for _, c := range []struct {
+		test     domain.TestID
+		question domain.QuestionID
+		want     bool
+	}{{"test-1", "test-1-q1", true}, {"test-1", "missing", false}, {"missing", "test-1-q1", false}} {
+		has, err := e.store.HasQuestion(e.ctx, c.test, c.question)
+		check(t, err, "HasQuestion")
+		if has != c.want {
+			t.Fatalf("HasQuestion(%s, %s): expected %v", c.test, c.question, c.want)
+		}
+		question, err := e.store.GetQuestion(e.ctx, c.test, c.question)
+		check(t, err, "GetQuestion")
+		if (question != nil) != c.want || (question != nil && question.ExpectedResponse != "b") {
+			t.Fatalf("GetQuestion(%s, %s): expected found %v, got %+v", c.test, c.question, c.want, question)
+		}
+	}
+	for _, c := range []struct {
+		test    domain.TestID
+		student domain.StudentID
+		want    bool
+	}{{"test-1", e.fx.Student(0), true}, {"test-1", e.fx.Student(2), false}, {"missing", e.fx.Student(0), false}} {
+		assigned, err := e.store.IsStudentAssigned(e.ctx, c.test, c.student)
+		check(t, err, "IsStudentAssigned")
+		if assigned != c.want {
+			t.Fatalf("IsStudentAssigned(%s, %s): expected %v", c.test, c.student, c.want)
+		}
+	}
+
+	if missing, err := e.store.GetTest(e.ctx, "missing"); missing != nil || err != nil {
+		t.Fatalf("expected no test, got %+v, %v", missing, err)
+	}
+	none, err := e.store.ListQuestions(e.ctx, "missing")
+	check(t, err, "ListQuestions")
+	if none == nil || len(none) != 0 {
+		t.Fatalf("expected no questions, got %+v", none)
+	}
+}
+
+func testRefusesInvalidTests(t *testing.T, e env) {
+	createTest(t, e, "test-1", e.fx.Teacher(0), 0, e.fx.Student(0))
+
+	refuse(t, e.store.CreateTest(e.ctx, &domain.Test{ID: "test-1", TeacherID: e.fx.Teacher(0), CreatedAt: minutes(1)}, nil, nil), "a test with a taken ID")
+	refuse(t, e.store.CreateTest(e.ctx, &domain.Test{ID: "test-2", TeacherID: "missing", CreatedAt: minutes(1)}, nil, nil), "a test of an unknown teacher")
+	refuse(t, e.store.CreateTest(e.ctx, &domain.Test{ID: "test-3", TeacherID: e.fx.Teacher(0), CreatedAt: minutes(1)}, nil, []domain.StudentID{"missing"}), "a test for an unknown student")
+	for _, id := range []domain.TestID{"test-2", "test-3"} {
+		if got, _ := e.store.GetTest(e.ctx, id); got != nil {
+			t.Fatalf("expected a refused test not to be stored, found %+v", got)
+		}
+	}
+	if got, _ := e.store.GetTest(e.ctx, "test-1"); got == nil || got.Title != "Test test-1" {
+		t.Fatalf("expected a refused duplicate to leave the test alone, got %+v", got)
+	}
+}
+
+func testUpdatesTests(t *testing.T, e env) {
+	test, questions := createTest(t, e, "test-1", e.fx.Teacher(0), 0, e.fx.Student(0))
+
+	test.Title = "Renamed"
+	test.Published = true
+	test.Version = 2
+	test.Curve = &domain.Curve{Mapping: map[domain.Score]domain.Score{4: 5}}
+	test.UpdatedAt = minutes(5)
+	check(t, e.store.UpdateTest(e.ctx, &test), "UpdateTest")
+	got, err := e.store.GetTest(e.ctx, test.ID)
+	check(t, err, "GetTest")
+	if got == nil || got.Title != "Renamed" || !got.Published || got.Version != 2 || !got.UpdatedAt.Equal(minutes(5)) {
+		t.Fatalf("expected the test update to be stored, got %+v", got)
+	}
+	if got.Curve == nil || got.Curve.Mapping[4] != 5 {
+		t.Fatalf("expected the curve to be stored, got %+v", got.Curve)
+	}
+	refuse(t, e.store.UpdateTest(e.ctx, &domain.Test{ID: "missing", TeacherID: e.fx.Teacher(0)}), "an update of an unknown test")
+
+	question := questions[0]
+	question.Prompt = "Edited"
+	question.Points = 7
+	check(t, e.store.UpdateQuestion(e.ctx, &question), "UpdateQuestion")
+	stored, err := e.store.ListQuestions(e.ctx, test.ID)
+	check(t, err, "ListQuestions")
+	if len(stored) != 2 || stored[1].Prompt != "Edited" || stored[1].Points != 7 {
+		t.Fatalf("expected the question update to be stored, got %+v", stored)
+	}
+	moved := question
+	moved.TestID = "missing"
+	refuse(t, e.store.UpdateQuestion(e.ctx, &moved), "an update of a question under another test")
+	refuse(t, e.store.UpdateQuestion(e.ctx, &domain.Question{ID: "missing", TestID: test.ID}), "an update of an unknown question")
+}
+
+func testReordersQuestions(t *testing.T, e env) {
+	test, _ := createTest(t, e, "test-1", e.fx.Teacher(0), 0)
+	createTest(t, e, "test-2", e.fx.Teacher(0), 0)
+
+	check(t, e.store.ReorderQuestions(e.ctx, test.ID, map[domain.QuestionID]int{"test-1-q1": 2, "test-1-q2": 1}), "ReorderQuestions")
+	stored, err := e.store.ListQuestions(e.ctx, test.ID)
+	check(t, err, "ListQuestions")
+	sameIDs(t, "ListQuestions after reorder", ids(stored, func(q domain.Question) domain.QuestionID { return q.ID }), "test-1-q2", "test-1-q1")
+	if stored[0].Prompt != "Second" || stored[0].Points != 5 {
+		t.Fatalf("expected a reorder to keep the rest of the question, got %+v", stored[0])
+	}
+
+	refuse(t, e.store.ReorderQuestions(e.ctx, test.ID, map[domain.QuestionID]int{"test-1-q1": 1, "test-2-q2": 2}), "a reorder naming a question of another test")
+	refuse(t, e.store.ReorderQuestions(e.ctx, test.ID, map[domain.QuestionID]int{"test-1-q1": 1, "missing": 2}), "a reorder naming an unknown question")
+	stored, err = e.store.ListQuestions(e.ctx, test.ID)
+	check(t, err, "ListQuestions")
+	sameIDs(t, "ListQuestions after refused reorders", ids(stored, func(q domain.Question) domain.QuestionID { return q.ID }), "test-1-q2", "test-1-q1")
+}
+
+func testUpdatesAssignments(t *testing.T, e env) {
+	test, _ := createTest(t, e, "test-1", e.fx.Teacher(0), 0, e.fx.Student(0), e.fx.Student(1))
+
+	check(t, e.store.UpdateAssignments(e.ctx, test.ID, []domain.StudentID{e.fx.Student(2), e.fx.Student(0)}, []domain.StudentID{e.fx.Student(1)}), "UpdateAssignments")
+	got, err := e.store.GetTest(e.ctx, test.ID)
+	check(t, err, "GetTest")
+	sameIDs(t, "AssignedTo", ids(got.AssignedTo, func(s domain.StudentID) domain.StudentID { return s }), string(e.fx.Student(0)), string(e.fx.Student(2)))
+	for i, want := range []bool{true, false, true} {
+		assigned, err := e.store.IsStudentAssigned(e.ctx, test.ID, e.fx.Student(i))
+		check(t, err, "IsStudentAssigned")
+		if assigned != want {
+			t.Fatalf("student %d: expected assigned=%v", i, want)
+		}
+	}
+	removed, err := e.store.ListTestsForStudent(e.ctx, e.fx.Student(1), repository.All)
+	check(t, err, "ListTestsForStudent")
+	if len(removed.Items) != 0 {
+		t.Fatalf("expected no tests for the unassigned student, got %+v", removed.Items)
+	}
+
+	// Removing a student who is not assigned changes nothing.
+	check(t, e.store.UpdateAssignments(e.ctx, test.ID, nil, []domain.StudentID{e.fx.Student(1)}), "UpdateAssignments")
+	refuse(t, e.store.UpdateAssignments(e.ctx, test.ID, []domain.StudentID{"missing"}, nil), "assigning an unknown student")
+	refuse(t, e.store.UpdateAssignments(e.ctx, "missing", []domain.StudentID{e.fx.Student(0)}, nil), "assigning an unknown test")
+	if got, _ := e.store.IsStudentAssigned(e.ctx, test.ID, "missing"); got {
+		t.Fatalf("expected a refused assignment not to be stored")
+	}
+}
+
+func testListsTests(t *testing.T, e env) {
+	// Created out of order; lists follow CreatedAt.
+	createTest(t, e, "test-c", e.fx.Teacher(0), 30, e.fx.Student(0))
+	createTest(t, e, "test-a", e.fx.Teacher(0), 10, e.fx.Student(0), e.fx.Student(1))
+	createTest(t, e, "test-b", e.fx.Teacher(0), 20)
+	createTest(t, e, "test-d", e.fx.Teacher(1), 40, e.fx.Student(0))
+
+	byTeacher := pages(t, "ListTestsByTeacher", func(p repository.PageRequest) (repository.Page[domain.Test], error) {
+		return e.store.ListTestsByTeacher(e.ctx, e.fx.Teacher(0), p)
+	})
+	sameIDs(t, "ListTestsByTeacher", ids(byTeacher, func(tc domain.Test) domain.TestID { return tc.ID }), "test-a", "test-b", "test-c")
+
+	forStudent := pages(t, "ListTestsForStudent", func(p repository.PageRequest) (repository.Page[domain.Test], error) {
+		return e.store.ListTestsForStudent(e.ctx, e.fx.Student(0), p)
+	})
+	sameIDs(t, "ListTestsForStudent", ids(forStudent, func(tc domain.Test) domain.TestID { return tc.ID }), "test-a", "test-c", "test-d")
+	none, err := e.store.ListTestsForStudent(e.ctx, e.fx.Student(2), repository.All)
+	check(t, err, "ListTestsForStudent")
+	if none.Items == nil || len(none.Items) != 0 {
+		t.Fatalf("expected an empty page, got %+v", none)
+	}
+
+	// Windows: a is 10:00-11:00, c is 11:00-12:00, d has no close time.
+	window := func(id domain.TestID, opens, closes int) {
+		test, err := e.store.GetTest(e.ctx, id)
+		check(t, err, "GetTest")
+		o, c := minutes(opens), minutes(closes)
+		test.OpensAt = &o
+		if closes > 0 {
+			test.ClosesAt = &c
+		}
+		check(t, e.store.UpdateTest(e.ctx, test), "UpdateTest")
+	}
+	window("test-a", 60, 120)
+	window("test-c", 120, 180)
+	window("test-d", 60, 0)
+	for _, c := range []struct {
+		from, to int
+		want     []string
+	}{
+		{0, 60, nil},
+		{0, 61, []string{"test-a"}},
+		{119, 121, []string{"test-a", "test-c"}},
+		{120, 240, []string{"test-c"}},
+		{180, 240, nil},
+	} {
+		tests, err := e.store.ListTestsInWindow(e.ctx, minutes(c.from), minutes(c.to))
+		check(t, err, "ListTestsInWindow")
+		sameIDs(t, "ListTestsInWindow", ids(tests, func(tc domain.Test) domain.TestID { return tc.ID }), c.want...)
+	}
+}
+
+func testReturnsCopies(t *testing.T, e env) {
+	createTest(t, e, "test-1", e.fx.Teacher(0), 0, e.fx.Student(0))
+
+	got, err := e.store.GetTest(e.ctx, "test-1")
+	check(t, err, "GetTest")
+	got.Title = "Changed"
+	got.AssignedTo[0] = "changed"
+	questions, err := e.store.ListQuestions(e.ctx, "test-1")
+	check(t, err, "ListQuestions")
+	questions[0].Prompt = "Changed"
+
+	again, err := e.store.GetTest(e.ctx, "test-1")
+	check(t, err, "GetTest")
+	if again.Title == "Changed" || again.AssignedTo[0] != e.fx.Student(0) {
+		t.Fatalf("expected changes to a returned test not to reach the store, got %+v", again)
+	}
+	if questions, _ = e.store.ListQuestions(e.ctx, "test-1"); questions[0].Prompt == "Changed" {
+		t.Fatalf("expected changes to a returned question not to reach the store")
+	}
+}
+
+func testUpsertsAnswers(t *testing.T, e env) {
+	_, questions := createTest(t, e, "test-1", e.fx.Teacher(0), 0, e.fx.Student(0))
+	first := answer(t, e, "answer-1", questions[1], e.fx.Student(0), 1)
+
+	got, err := e.store.GetAnswer(e.ctx, "test-1", questions[1].ID, e.fx.Student(0))
+	check(t, err, "GetAnswer")
+	if got == nil || got.ID != first.ID || got.Response != first.Response || len(got.Revisions) != 0 {
+		t.Fatalf("expected the answer, got %+v", got)
+	}
+
+	// Revising the response keeps the earlier one as a revision; saving it
+	// unchanged adds none.
+	revised := first
+	revised.Response = "second thoughts"
+	revised.UpdatedAt = minutes(2)
+	check(t, e.store.UpsertAnswer(e.ctx, &revised), "UpsertAnswer")
+	check(t, e.store.UpsertAnswer(e.ctx, &revised), "UpsertAnswer")
+	got, err = e.store.GetAnswer(e.ctx, "test-1", questions[1].ID, e.fx.Student(0))
+	check(t, err, "GetAnswer")
+	if got.Response != "second thoughts" || len(got.Revisions) != 1 || got.Revisions[0].Response != first.Response {
+		t.Fatalf("expected one revision of the first response, got %+v", got)
+	}
+
+	for _, c := range []struct {
+		question domain.QuestionID
+		student  domain.StudentID
+	}{{questions[0].ID, e.fx.Student(0)}, {questions[1].ID, e.fx.Student(1)}, {"missing", e.fx.Student(0)}} {
+		if a, err := e.store.GetAnswer(e.ctx, "test-1", c.question, c.student); a != nil || err != nil {
+			t.Fatalf("expected no answer for %s/%s, got %+v, %v", c.question, c.student, a, err)
+		}
+	}
+}
+
+func testListsAnswers(t *testing.T, e env) {
+	_, questions := createTest(t, e, "test-1", e.fx.Teacher(0), 0, e.fx.Student(0), e.fx.Student(1))
+	_, other := createTest(t, e, "test-2", e.fx.Teacher(0), 0, e.fx.Student(0))
+	answer(t, e, "answer-3", questions[0], e.fx.Student(0), 3)
+	answer(t, e, "answer-1", questions[1], e.fx.Student(0), 1)
+	answer(t, e, "answer-2", questions[1], e.fx.Student(1), 2)
+	answer(t, e, "answer-4", other[0], e.fx.Student(0), 4)
+
+	mine, err := e.store.ListAnswers(e.ctx, "test-1", e.fx.Student(0))
+	check(t, err, "ListAnswers")
+	sameIDs(t, "ListAnswers", ids(mine, func(a domain.Answer) domain.AnswerID { return a.ID }), "answer-1", "answer-3")
+
+	all := pages(t, "ListAnswersByTest", func(p repository.PageRequest) (repository.Page[domain.Answer], error) {
+		return e.store.ListAnswersByTest(e.ctx, "test-1", p)
+	})
+	sameIDs(t, "ListAnswersByTest", ids(all, func(a domain.Answer) domain.AnswerID { return a.ID }), "answer-1", "answer-2", "answer-3")
+
+	none, err := e.store.ListAnswers(e.ctx, "test-1", e.fx.Student(2))
+	check(t, err, "ListAnswers")
+	if none == nil || len(none) != 0 {
+		t.Fatalf("expected no answers, got %+v", none)
+	}
+	empty, err := e.store.ListAnswersByTest(e.ctx, "missing", repository.All)
+	check(t, err, "ListAnswersByTest")
+	if empty.Items == nil || len(empty.Items) != 0 {
+		t.Fatalf("expected an empty page, got %+v", empty)
+	}
+}
+
+func testDeletesAnswers(t *testing.T, e env) {
+	_, questions := createTest(t, e, "test-1", e.fx.Teacher(0), 0, e.fx.Student(0))
+	a := answer(t, e, "answer-1", questions[0], e.fx.Student(0), 1)
+	check(t, e.store.SaveResult(e.ctx, &domain.Result{ID: "result-1", AnswerID: a.ID, Score: 3, CreatedAt: minutes(2)}), "SaveResult")
+
+	check(t, e.store.DeleteAnswer(e.ctx, "test-1", questions[0].ID, e.fx.Student(0)), "DeleteAnswer")
+	if got, err := e.store.GetAnswer(e.ctx, "test-1", questions[0].ID, e.fx.Student(0)); got != nil || err != nil {
+		t.Fatalf("expected the answer to be deleted, got %+v, %v", got, err)
+	}
+	if got, err := e.store.GetResult(e.ctx, a.ID); got != nil || err != nil {
+		t.Fatalf("expected the answer's result to be deleted with it, got %+v, %v", got, err)
+	}
+	results, err := e.store.ListResultsByTest(e.ctx, "test-1", repository.All)
+	check(t, err, "ListResultsByTest")
+	if len(results.Items) != 0 {
+		t.Fatalf("expected no results left, got %+v", results.Items)
+	}
+
+	check(t, e.store.DeleteAnswer(e.ctx, "test-1", questions[0].ID, e.fx.Student(0)), "DeleteAnswer of a missing answer")
+
+	// The student can answer again.
+	answer(t, e, "answer-2", questions[0], e.fx.Student(0), 3)
+	if got, _ := e.store.GetAnswer(e.ctx, "test-1", questions[0].ID, e.fx.Student(0)); got == nil || got.ID != "answer-2" || len(got.Revisions) != 0 {
+		t.Fatalf("expected a fresh answer, got %+v", got)
+	}
+}
+
+func testSavesResults(t *testing.T, e env) {
+	_, questions := createTest(t, e, "test-1", e.fx.Teacher(0), 0, e.fx.Student(0), e.fx.Student(1))
+	a1 := answer(t, e, "answer-1", questions[0], e.fx.Student(0), 1)
+	a2 := answer(t, e, "answer-2", questions[1], e.fx.Student(0), 2)
+	a3 := answer(t, e, "answer-3", questions[0], e.fx.Student(1), 3)
+
+	raw := domain.Score(4)
+	check(t, e.store.SaveResult(e.ctx, &domain.Result{ID: "result-2", AnswerID: a2.ID, Score: 5, RawScore: &raw, Feedback: "ok", Version: 1, CreatedAt: minutes(5)}), "SaveResult")
+	check(t, e.store.SaveResult(e.ctx, &domain.Result{ID: "result-1", AnswerID: a1.ID, Score: 8, CreatedAt: minutes(4)}), "SaveResult")
+	check(t, e.store.SaveResult(e.ctx, &domain.Result{ID: "result-3", AnswerID: a3.ID, Score: 1, CreatedAt: minutes(6)}), "SaveResult")
+
+	got, err := e.store.GetResult(e.ctx, a2.ID)
+	check(t, err, "GetResult")
+	if got == nil || got.ID != "result-2" || got.Score != 5 || got.RawScore == nil || *got.RawScore != 4 || got.Feedback != "ok" || got.Version != 1 {
+		t.Fatalf("expected the result, got %+v", got)
+	}
+
+	// Saving under the same ID regrades.
+	regraded := *got
+	regraded.Score = 9
+	regraded.Completed = true
+	regraded.GradedBy = e.fx.Teacher(1)
+	regraded.Version = 2
+	check(t, e.store.SaveResult(e.ctx, &regraded), "SaveResult")
+	if got, _ = e.store.GetResult(e.ctx, a2.ID); got.Score != 9 || !got.Completed || got.GradedBy != e.fx.Teacher(1) || got.Version != 2 {
+		t.Fatalf("expected the regrade to be stored, got %+v", got)
+	}
+
+	byTest := pages(t, "ListResultsByTest", func(p repository.PageRequest) (repository.Page[domain.Result], error) {
+		return e.store.ListResultsByTest(e.ctx, "test-1", p)
+	})
+	sameIDs(t, "ListResultsByTest", ids(byTest, func(r domain.Result) domain.ResultID { return r.ID }), "result-1", "result-2", "result-3")
+
+	byStudent, err := e.store.ListResultsByStudent(e.ctx, "test-1", e.fx.Student(0))
+	check(t, err, "ListResultsByStudent")
+	sameIDs(t, "ListResultsByStudent", ids(byStudent, func(r domain.Result) domain.ResultID { return r.ID }), "result-1", "result-2")
+
+	if r, err := e.store.GetResult(e.ctx, "missing"); r != nil || err != nil {
+		t.Fatalf("expected no result, got %+v, %v", r, err)
+	}
+	none, err := e.store.ListResultsByStudent(e.ctx, "test-1", e.fx.Student(2))
+	check(t, err, "ListResultsByStudent")
+	if none == nil || len(none) != 0 {
+		t.Fatalf("expected no results, got %+v", none)
+	}
+}
+
+func testSavesResultBatch(t *testing.T, e env) {
+	_, questions := createTest(t, e, "test-1", e.fx.Teacher(0), 0, e.fx.Student(0))
+	a1 := answer(t, e, "answer-1", questions[0], e.fx.Student(0), 1)
+	a2 := answer(t, e, "answer-2", questions[1], e.fx.Student(0), 2)
+
+	check(t, e.store.SaveResults(e.ctx, []domain.Result{
+		{ID: "result-1", AnswerID: a1.ID, Score: 1, CreatedAt: minutes(3)},
+		{ID: "result-2", AnswerID: a2.ID, Score: 2, CreatedAt: minutes(3)},
+	}), "SaveResults")
+
+	refuse(t, e.store.SaveResults(e.ctx, []domain.Result{
+		{ID: "result-1", AnswerID: a1.ID, Score: 10, CreatedAt: minutes(3)},
+		{ID: "result-x", AnswerID: "missing", Score: 10, CreatedAt: minutes(3)},
+	}), "a batch with a result of an unknown answer")
+	if got, _ := e.store.GetResult(e.ctx, a1.ID); got == nil || got.Score != 1 {
+		t.Fatalf("expected a refused batch to change nothing, got %+v", got)
+	}
+	check(t, e.store.SaveResults(e.ctx, nil), "SaveResults of nothing")
+}
+
+func testSnapshotsGrading(t *testing.T, e env) {
+	_, questions := createTest(t, e, "test-1", e.fx.Teacher(0), 0, e.fx.Student(0), e.fx.Student(1))
+	a2 := answer(t, e, "answer-2", questions[0], e.fx.Student(1), 2)
+	a1 := answer(t, e, "answer-1", questions[0], e.fx.Student(0), 1)
+	check(t, e.store.SaveResult(e.ctx, &domain.Result{ID: "result-2", AnswerID: a2.ID, Score: 2, CreatedAt: minutes(4)}), "SaveResult")
+	check(t, e.store.SaveResult(e.ctx, &domain.Result{ID: "result-1", AnswerID: a1.ID, Score: 1, CreatedAt: minutes(3)}), "SaveResult")
+
+	snapshot, err := e.store.SnapshotGrading(e.ctx, "test-1")
+	check(t, err, "SnapshotGrading")
+	sameIDs(t, "snapshot answers", ids(snapshot.Answers, func(a domain.Answer) domain.AnswerID { return a.ID }), "answer-1", "answer-2")
+	sameIDs(t, "snapshot results", ids(snapshot.Results, func(r domain.Result) domain.ResultID { return r.ID }), "result-1", "result-2")
+
+	empty, err := e.store.SnapshotGrading(e.ctx, "missing")
+	check(t, err, "SnapshotGrading")
+	if len(empty.Answers) != 0 || len(empty.Results) != 0 {
+		t.Fatalf("expected an empty snapshot, got %+v", empty)
+	}
+}
+
+func testSavesSessions(t *testing.T, e env) {
+	createTest(t, e, "test-1", e.fx.Teacher(0), 0, e.fx.Student(0), e.fx.Student(1))
+
+	started := minutes(5)
+	session := domain.TestSession{TestID: "test-1", StudentID: e.fx.Student(0), Flagged: []domain.QuestionID{"test-1-q2"}, StartedAt: &started, CreatedAt: minutes(5), UpdatedAt: minutes(5)}
+	check(t, e.store.SaveTestSession(e.ctx, &session), "SaveTestSession")
+	got, err := e.store.GetTestSession(e.ctx, "test-1", e.fx.Student(0))
+	check(t, err, "GetTestSession")
+	if got == nil || got.StartedAt == nil || !got.StartedAt.Equal(started) || len(got.Flagged) != 1 {
+		t.Fatalf("expected the session, got %+v", got)
+	}
+
+	session.Flagged = nil
+	session.UpdatedAt = minutes(6)
+	check(t, e.store.SaveTestSession(e.ctx, &session), "SaveTestSession")
+	if got, _ = e.store.GetTestSession(e.ctx, "test-1", e.fx.Student(0)); len(got.Flagged) != 0 || !got.UpdatedAt.Equal(minutes(6)) {
+		t.Fatalf("expected the session to be replaced, got %+v", got)
+	}
+
+	if other, err := e.store.GetTestSession(e.ctx, "test-1", e.fx.Student(1)); other != nil || err != nil {
+		t.Fatalf("expected no session for another student, got %+v, %v", other, err)
+	}
+	refuse(t, e.store.SaveTestSession(e.ctx, &domain.TestSession{TestID: "missing", StudentID: e.fx.Student(0)}), "a session of an unknown test")
+}
+
+func testSavesSubmissions(t *testing.T, e env) {
+	createTest(t, e, "test-1", e.fx.Teacher(0), 0, e.fx.Student(0), e.fx.Student(1))
+
+	check(t, e.store.SaveSubmission(e.ctx, &domain.Submission{TestID: "test-1", StudentID: e.fx.Student(1), SubmittedAt: minutes(20)}), "SaveSubmission")
+	check(t, e.store.SaveSubmission(e.ctx, &domain.Submission{TestID: "test-1", StudentID: e.fx.Student(0), SubmittedAt: minutes(10)}), "SaveSubmission")
+
+	got, err := e.store.GetSubmission(e.ctx, "test-1", e.fx.Student(1))
+	check(t, err, "GetSubmission")
+	if got == nil || !got.SubmittedAt.Equal(minutes(20)) {
+		t.Fatalf("expected the submission, got %+v", got)
+	}
+	list, err := e.store.ListSubmissionsByTest(e.ctx, "test-1")
+	check(t, err, "ListSubmissionsByTest")
+	sameIDs(t, "ListSubmissionsByTest", ids(list, func(s domain.Submission) domain.StudentID { return s.StudentID }), string(e.fx.Student(0)), string(e.fx.Student(1)))
+
+	if none, err := e.store.GetSubmission(e.ctx, "test-1", e.fx.Student(2)); none != nil || err != nil {
+		t.Fatalf("expected no submission, got %+v, %v", none, err)
+	}
+	empty, err := e.store.ListSubmissionsByTest(e.ctx, "missing")
+	check(t, err, "ListSubmissionsByTest")
+	if empty == nil || len(empty) != 0 {
+		t.Fatalf("expected no submissions, got %+v", empty)
+	}
+	refuse(t, e.store.SaveSubmission(e.ctx, &domain.Submission{TestID: "missing", StudentID: e.fx.Student(0)}), "a submission of an unknown test")
+}
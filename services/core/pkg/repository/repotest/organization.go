@@ -0,0 +1,192 @@
+package repotest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+var organizationCases = []suiteCase{
+	{"Organization/GetsSeededRecords", testGetsSeededRecords},
+	{"Organization/ListsInCreationOrder", testListsOrganization},
+	{"Organization/FindsStudentsByEmail", testFindsStudentsByEmail},
+	{"Organization/UpdatesExistingRecordsOnly", testUpdatesOrganization},
+	{"Organization/CreatesStudentsAllOrNone", testCreatesStudents},
+}
+
+func testGetsSeededRecords(t *testing.T, e env) {
+	school, err := e.store.GetSchool(e.ctx, e.fx.School.ID)
+	check(t, err, "GetSchool")
+	if school == nil || school.Name != e.fx.School.Name || !school.CreatedAt.Equal(e.fx.School.CreatedAt) {
+		t.Fatalf("expected the seeded school, got %+v", school)
+	}
+	grade, err := e.store.GetGrade(e.ctx, e.fx.Grades[0].ID)
+	check(t, err, "GetGrade")
+	if grade == nil || grade.SchoolID != school.ID {
+		t.Fatalf("expected the seeded grade, got %+v", grade)
+	}
+	class, err := e.store.GetClass(e.ctx, e.fx.Classes[0].ID)
+	check(t, err, "GetClass")
+	if class == nil || class.GradeID != grade.ID {
+		t.Fatalf("expected the seeded class, got %+v", class)
+	}
+	teacher, err := e.store.GetTeacher(e.ctx, e.fx.Teacher(1))
+	check(t, err, "GetTeacher")
+	if teacher == nil || teacher.SchoolID != school.ID || teacher.Email != e.fx.Teachers[1].Email {
+		t.Fatalf("expected the seeded teacher, got %+v", teacher)
+	}
+	student, err := e.store.GetStudent(e.ctx, e.fx.Student(2))
+	check(t, err, "GetStudent")
+	if student == nil || student.ClassID != class.ID || student.Name != e.fx.Students[2].Name {
+		t.Fatalf("expected the seeded student, got %+v", student)
+	}
+
+	// Missing records are nil without an error.
+	if s, err := e.store.GetSchool(e.ctx, "missing"); s != nil || err != nil {
+		t.Fatalf("expected no school, got %+v, %v", s, err)
+	}
+	if g, err := e.store.GetGrade(e.ctx, "missing"); g != nil || err != nil {
+		t.Fatalf("expected no grade, got %+v, %v", g, err)
+	}
+	if c, err := e.store.GetClass(e.ctx, "missing"); c != nil || err != nil {
+		t.Fatalf("expected no class, got %+v, %v", c, err)
+	}
+	if tc, err := e.store.GetTeacher(e.ctx, "missing"); tc != nil || err != nil {
+		t.Fatalf("expected no teacher, got %+v, %v", tc, err)
+	}
+	if st, err := e.store.GetStudent(e.ctx, "missing"); st != nil || err != nil {
+		t.Fatalf("expected no student, got %+v, %v", st, err)
+	}
+}
+
+func testListsOrganization(t *testing.T, e env) {
+	schools := pages(t, "ListSchools", func(p repository.PageRequest) (repository.Page[domain.School], error) {
+		return e.store.ListSchools(e.ctx, p)
+	})
+	sameIDs(t, "ListSchools", ids(schools, func(s domain.School) domain.SchoolID { return s.ID }), string(e.fx.School.ID))
+
+	grades := pages(t, "ListGrades", func(p repository.PageRequest) (repository.Page[domain.Grade], error) {
+		return e.store.ListGrades(e.ctx, e.fx.School.ID, p)
+	})
+	sameIDs(t, "ListGrades", ids(grades, func(g domain.Grade) domain.GradeID { return g.ID }), string(e.fx.Grades[0].ID))
+
+	classes := pages(t, "ListClasses", func(p repository.PageRequest) (repository.Page[domain.Class], error) {
+		return e.store.ListClasses(e.ctx, e.fx.Grades[0].ID, p)
+	})
+	sameIDs(t, "ListClasses", ids(classes, func(c domain.Class) domain.ClassID { return c.ID }), string(e.fx.Classes[0].ID))
+
+	students := pages(t, "ListStudents", func(p repository.PageRequest) (repository.Page[domain.Student], error) {
+		return e.store.ListStudents(e.ctx, e.fx.Classes[0].ID, p)
+	})
+	sameIDs(t, "ListStudents", ids(students, func(s domain.Student) domain.StudentID { return s.ID }),
+		string(e.fx.Student(0)), string(e.fx.Student(1)), string(e.fx.Student(2)))
+
+	teachers := pages(t, "ListTeachers", func(p repository.PageRequest) (repository.Page[domain.Teacher], error) {
+		return e.store.ListTeachers(e.ctx, e.fx.School.ID, p)
+	})
+	sameIDs(t, "ListTeachers", ids(teachers, func(tc domain.Teacher) domain.TeacherID { return tc.ID }),
+		string(e.fx.Teacher(0)), string(e.fx.Teacher(1)))
+
+	// Lists of unknown parents are empty, not nil.
+	none, err := e.store.ListStudents(e.ctx, "missing", repository.All)
+	check(t, err, "ListStudents")
+	if none.Items == nil || len(none.Items) != 0 || none.NextCursor != "" {
+		t.Fatalf("expected an empty page, got %+v", none)
+	}
+	if _, err := e.store.ListStudents(e.ctx, e.fx.Classes[0].ID, repository.PageRequest{Limit: 1, Cursor: "not a cursor"}); err == nil {
+		t.Fatalf("expected an invalid cursor to be refused")
+	}
+}
+
+func testFindsStudentsByEmail(t *testing.T, e env) {
+	student := e.fx.Students[1]
+	found, err := e.store.FindStudentsByEmail(e.ctx, "  "+strings.ToUpper(student.Email)+" ")
+	check(t, err, "FindStudentsByEmail")
+	sameIDs(t, "FindStudentsByEmail", ids(found, func(s domain.Student) domain.StudentID { return s.ID }), string(student.ID))
+
+	// A guardian of two students finds both.
+	for _, s := range e.fx.Students[:2] {
+		s.GuardianEmail = "parent@example.com"
+		check(t, e.store.UpdateStudent(e.ctx, &s), "UpdateStudent")
+	}
+	found, err = e.store.FindStudentsByEmail(e.ctx, "Parent@Example.com")
+	check(t, err, "FindStudentsByEmail")
+	sameIDs(t, "FindStudentsByEmail guardian", ids(found, func(s domain.Student) domain.StudentID { return s.ID }),
+		string(e.fx.Student(0)), string(e.fx.Student(1)))
+
+	for _, email := range []string{"", "nobody@example.com"} {
+		found, err := e.store.FindStudentsByEmail(e.ctx, email)
+		check(t, err, "FindStudentsByEmail")
+		if found == nil || len(found) != 0 {
+			t.Fatalf("expected no students for %q, got %+v", email, found)
+		}
+	}
+}
+
+func testUpdatesOrganization(t *testing.T, e env) {
+	school := e.fx.School
+	school.Name = "Renamed School"
+	school.Settings.Timezone = "Asia/Tokyo"
+	check(t, e.store.UpdateSchool(e.ctx, &school), "UpdateSchool")
+	if got, _ := e.store.GetSchool(e.ctx, school.ID); got == nil || got.Name != "Renamed School" || got.Settings.Timezone != "Asia/Tokyo" {
+		t.Fatalf("expected the school update to be stored, got %+v", got)
+	}
+	school.DistrictID = "missing"
+	refuse(t, e.store.UpdateSchool(e.ctx, &school), "a school joining an unknown district")
+	refuse(t, e.store.UpdateSchool(e.ctx, &domain.School{ID: "missing"}), "an update of an unknown school")
+
+	teacher := e.fx.Teachers[0]
+	teacher.DisplayName = "Ms. T"
+	teacher.Notifications = domain.NotificationPreferences{Delivery: domain.DeliveryDigest}
+	check(t, e.store.UpdateTeacher(e.ctx, &teacher), "UpdateTeacher")
+	if got, _ := e.store.GetTeacher(e.ctx, teacher.ID); got == nil || got.DisplayName != "Ms. T" || got.Notifications.Delivery != domain.DeliveryDigest {
+		t.Fatalf("expected the teacher update to be stored, got %+v", got)
+	}
+	refuse(t, e.store.UpdateTeacher(e.ctx, &domain.Teacher{ID: "missing"}), "an update of an unknown teacher")
+
+	student := e.fx.Students[0]
+	student.Locale = "ja"
+	student.InvitationID = "invitation-1"
+	check(t, e.store.UpdateStudent(e.ctx, &student), "UpdateStudent")
+	if got, _ := e.store.GetStudent(e.ctx, student.ID); got == nil || got.Locale != "ja" || got.InvitationID != "invitation-1" {
+		t.Fatalf("expected the student update to be stored, got %+v", got)
+	}
+	refuse(t, e.store.UpdateStudent(e.ctx, &domain.Student{ID: "missing"}), "an update of an unknown student")
+}
+
+func testCreatesStudents(t *testing.T, e env) {
+	classID := e.fx.Classes[0].ID
+	created := []domain.Student{
+		{ID: "new-student-1", Name: "New 1", Email: "new1@example.com", CreatedAt: minutes(1)},
+		{ID: "new-student-2", Name: "New 2", Email: "new2@example.com", CreatedAt: minutes(2)},
+	}
+	check(t, e.store.CreateStudents(e.ctx, classID, created), "CreateStudents")
+	got, err := e.store.GetStudent(e.ctx, "new-student-2")
+	check(t, err, "GetStudent")
+	if got == nil || got.ClassID != classID {
+		t.Fatalf("expected the new student in the class, got %+v", got)
+	}
+
+	refuse(t, e.store.CreateStudents(e.ctx, classID, []domain.Student{
+		{ID: "new-student-3", CreatedAt: minutes(3)},
+		{ID: e.fx.Student(0), CreatedAt: minutes(3)},
+	}), "students with a taken ID")
+	refuse(t, e.store.CreateStudents(e.ctx, classID, []domain.Student{
+		{ID: "new-student-4", CreatedAt: minutes(4)},
+		{ID: "new-student-4", CreatedAt: minutes(4)},
+	}), "students with the same ID")
+	refuse(t, e.store.CreateStudents(e.ctx, "missing", []domain.Student{{ID: "new-student-5", CreatedAt: minutes(5)}}), "students of an unknown class")
+	for _, id := range []domain.StudentID{"new-student-3", "new-student-4", "new-student-5"} {
+		if got, _ := e.store.GetStudent(e.ctx, id); got != nil {
+			t.Fatalf("expected a refused batch to store nothing, found %+v", got)
+		}
+	}
+
+	students, err := e.store.ListStudents(e.ctx, classID, repository.All)
+	check(t, err, "ListStudents")
+	if len(students.Items) != 5 {
+		t.Fatalf("expected 5 students in the class, got %d", len(students.Items))
+	}
+}
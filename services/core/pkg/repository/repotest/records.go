@@ -0,0 +1,481 @@
+package repotest
+
+import (
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+var recordCases = []suiteCase{
+	{"Notifications/ListsNewestFirst", testListsNotifications},
+	{"Notifications/MarksRead", testMarksNotificationsRead},
+	{"Digests/QueuesAndDeletes", testQueuesDigestItems},
+	{"Comments/SavesQuestionComments", testSavesQuestionComments},
+	{"Comments/SavesAnswerComments", testSavesAnswerComments},
+	{"Rubrics/SavesAllOrNone", testSavesRubrics},
+	{"Delegations/SavesAndExpires", testSavesDelegations},
+	{"DeviceSessions/SavesAndExpires", testSavesDeviceSessions},
+	{"QuestionBank/SavesAndDeletes", testSavesBankQuestions},
+	{"Districts/SavesDistrictsAndStaff", testSavesDistricts},
+	{"Audit/ListsNewestFirstAndFilters", testListsAuditEntries},
+}
+
+func notification(id domain.NotificationID, role domain.Role, recipientID string, minute int) *domain.Notification {
+	return &domain.Notification{ID: id, Role: role, RecipientID: recipientID, Kind: "test.assigned", Subject: "Subject " + string(id), CreatedAt: minutes(minute)}
+}
+
+func testListsNotifications(t *testing.T, e env) {
+	student := string(e.fx.Student(0))
+	check(t, e.store.SaveNotification(e.ctx, notification("n-2", domain.RoleStudent, student, 2)), "SaveNotification")
+	check(t, e.store.SaveNotification(e.ctx, notification("n-1", domain.RoleStudent, student, 1)), "SaveNotification")
+	check(t, e.store.SaveNotification(e.ctx, notification("n-3", domain.RoleStudent, student, 3)), "SaveNotification")
+	check(t, e.store.SaveNotification(e.ctx, notification("n-4", domain.RoleStudent, string(e.fx.Student(1)), 4)), "SaveNotification")
+	// The same ID under another role is someone else's inbox.
+	check(t, e.store.SaveNotification(e.ctx, notification("n-5", domain.RoleTeacher, student, 5)), "SaveNotification")
+
+	inbox := pages(t, "ListNotifications", func(p repository.PageRequest) (repository.Page[domain.Notification], error) {
+		return e.store.ListNotifications(e.ctx, domain.RoleStudent, student, p)
+	})
+	sameIDs(t, "ListNotifications", ids(inbox, func(n domain.Notification) domain.NotificationID { return n.ID }), "n-3", "n-2", "n-1")
+	if inbox[0].Subject != "Subject n-3" || inbox[0].ReadAt != nil {
+		t.Fatalf("expected the notification's fields to be stored, got %+v", inbox[0])
+	}
+
+	// Saving a notification again replaces it without listing it twice.
+	updated := notification("n-2", domain.RoleStudent, student, 2)
+	updated.Subject = "Updated"
+	check(t, e.store.SaveNotification(e.ctx, updated), "SaveNotification")
+	again, err := e.store.ListNotifications(e.ctx, domain.RoleStudent, student, repository.All)
+	check(t, err, "ListNotifications")
+	sameIDs(t, "ListNotifications after resave", ids(again.Items, func(n domain.Notification) domain.NotificationID { return n.ID }), "n-3", "n-2", "n-1")
+	if again.Items[1].Subject != "Updated" {
+		t.Fatalf("expected the resaved notification, got %+v", again.Items[1])
+	}
+
+	empty, err := e.store.ListNotifications(e.ctx, domain.RoleStudent, "nobody", repository.All)
+	check(t, err, "ListNotifications")
+	if empty.Items == nil || len(empty.Items) != 0 {
+		t.Fatalf("expected an empty inbox, got %+v", empty)
+	}
+}
+
+func testMarksNotificationsRead(t *testing.T, e env) {
+	student := string(e.fx.Student(0))
+	for i, id := range []domain.NotificationID{"n-1", "n-2", "n-3"} {
+		check(t, e.store.SaveNotification(e.ctx, notification(id, domain.RoleStudent, student, i)), "SaveNotification")
+	}
+	check(t, e.store.SaveNotification(e.ctx, notification("n-4", domain.RoleStudent, string(e.fx.Student(1)), 4)), "SaveNotification")
+	readAt := func(recipientID string) map[domain.NotificationID]*domain.Notification {
+		page, err := e.store.ListNotifications(e.ctx, domain.RoleStudent, recipientID, repository.All)
+		check(t, err, "ListNotifications")
+		out := make(map[domain.NotificationID]*domain.Notification)
+		for i := range page.Items {
+			out[page.Items[i].ID] = &page.Items[i]
+		}
+		return out
+	}
+
+	// Another recipient's notification in the list is left alone.
+	check(t, e.store.MarkNotificationsRead(e.ctx, domain.RoleStudent, student, []domain.NotificationID{"n-1", "n-4"}, minutes(10)), "MarkNotificationsRead")
+	inbox := readAt(student)
+	if inbox["n-1"].ReadAt == nil || !inbox["n-1"].ReadAt.Equal(minutes(10)) || inbox["n-2"].ReadAt != nil {
+		t.Fatalf("expected only n-1 to be read, got %+v", inbox)
+	}
+	if readAt(string(e.fx.Student(1)))["n-4"].ReadAt != nil {
+		t.Fatalf("expected another student's notification to stay unread")
+	}
+
+	// No IDs marks the whole inbox, keeping the time earlier reads were made.
+	check(t, e.store.MarkNotificationsRead(e.ctx, domain.RoleStudent, student, nil, minutes(20)), "MarkNotificationsRead")
+	inbox = readAt(student)
+	for id, n := range inbox {
+		if n.ReadAt == nil {
+			t.Fatalf("expected %s to be read", id)
+		}
+	}
+	if !inbox["n-1"].ReadAt.Equal(minutes(10)) || !inbox["n-3"].ReadAt.Equal(minutes(20)) {
+		t.Fatalf("expected read times to be kept, got %v and %v", inbox["n-1"].ReadAt, inbox["n-3"].ReadAt)
+	}
+	if readAt(string(e.fx.Student(1)))["n-4"].ReadAt != nil {
+		t.Fatalf("expected another student's inbox to stay unread")
+	}
+	check(t, e.store.MarkNotificationsRead(e.ctx, domain.RoleStudent, student, []domain.NotificationID{"missing"}, minutes(30)), "MarkNotificationsRead of an unknown notification")
+}
+
+func testQueuesDigestItems(t *testing.T, e env) {
+	item := func(id domain.DigestItemID, role domain.Role, recipientID string, minute int) *domain.DigestItem {
+		return &domain.DigestItem{ID: id, Role: role, RecipientID: recipientID, Email: recipientID + "@example.com", Kind: "test.assigned", Subject: "Subject " + string(id), OccurredAt: minutes(minute)}
+	}
+	check(t, e.store.QueueDigestItem(e.ctx, item("d-2", domain.RoleStudent, string(e.fx.Student(0)), 2)), "QueueDigestItem")
+	check(t, e.store.QueueDigestItem(e.ctx, item("d-1", domain.RoleTeacher, string(e.fx.Teacher(0)), 1)), "QueueDigestItem")
+	check(t, e.store.QueueDigestItem(e.ctx, item("d-3", domain.RoleStudent, string(e.fx.Student(1)), 3)), "QueueDigestItem")
+
+	queued, err := e.store.ListDigestItems(e.ctx)
+	check(t, err, "ListDigestItems")
+	sameIDs(t, "ListDigestItems", ids(queued, func(d domain.DigestItem) domain.DigestItemID { return d.ID }), "d-1", "d-2", "d-3")
+	if queued[1].Email != string(e.fx.Student(0))+"@example.com" || queued[1].Subject != "Subject d-2" {
+		t.Fatalf("expected the queued item, got %+v", queued[1])
+	}
+
+	check(t, e.store.DeleteDigestItems(e.ctx, []domain.DigestItemID{"d-1", "d-3", "missing"}), "DeleteDigestItems")
+	left, err := e.store.ListDigestItems(e.ctx)
+	check(t, err, "ListDigestItems")
+	sameIDs(t, "ListDigestItems after delete", ids(left, func(d domain.DigestItem) domain.DigestItemID { return d.ID }), "d-2")
+	check(t, e.store.DeleteDigestItems(e.ctx, nil), "DeleteDigestItems of nothing")
+}
+
+func testSavesQuestionComments(t *testing.T, e env) {
+	_, questions := createTest(t, e, "test-1", e.fx.Teacher(0), 0)
+	q := questions[1]
+	comment := func(id domain.QuestionCommentID, parent domain.QuestionCommentID, minute int) *domain.QuestionComment {
+		return &domain.QuestionComment{ID: id, TestID: q.TestID, QuestionID: q.ID, ParentID: parent, AuthorID: e.fx.Teacher(0), Body: "Body " + string(id), CreatedAt: minutes(minute)}
+	}
+	reply := comment("c-2", "c-1", 2)
+	reply.Mentions = []domain.TeacherID{e.fx.Teacher(1)}
+	check(t, e.store.SaveQuestionComment(e.ctx, reply), "SaveQuestionComment")
+	check(t, e.store.SaveQuestionComment(e.ctx, comment("c-1", "", 1)), "SaveQuestionComment")
+
+	got, err := e.store.GetQuestionComment(e.ctx, "c-2")
+	check(t, err, "GetQuestionComment")
+	if got == nil || got.ParentID != "c-1" || got.Body != "Body c-2" || len(got.Mentions) != 1 || got.Mentions[0] != e.fx.Teacher(1) {
+		t.Fatalf("expected the comment, got %+v", got)
+	}
+	list, err := e.store.ListQuestionComments(e.ctx, q.TestID, q.ID)
+	check(t, err, "ListQuestionComments")
+	sameIDs(t, "ListQuestionComments", ids(list, func(c domain.QuestionComment) domain.QuestionCommentID { return c.ID }), "c-1", "c-2")
+
+	none, err := e.store.ListQuestionComments(e.ctx, q.TestID, questions[0].ID)
+	check(t, err, "ListQuestionComments")
+	if none == nil || len(none) != 0 {
+		t.Fatalf("expected no comments, got %+v", none)
+	}
+	if c, err := e.store.GetQuestionComment(e.ctx, "missing"); c != nil || err != nil {
+		t.Fatalf("expected no comment, got %+v, %v", c, err)
+	}
+	refuse(t, e.store.SaveQuestionComment(e.ctx, &domain.QuestionComment{ID: "c-3", TestID: q.TestID, QuestionID: "missing", CreatedAt: minutes(3)}), "a comment on an unknown question")
+}
+
+func testSavesAnswerComments(t *testing.T, e env) {
+	_, questions := createTest(t, e, "test-1", e.fx.Teacher(0), 0, e.fx.Student(0), e.fx.Student(1))
+	a1 := answer(t, e, "answer-1", questions[0], e.fx.Student(0), 1)
+	a2 := answer(t, e, "answer-2", questions[0], e.fx.Student(1), 1)
+	comment := func(id domain.AnswerCommentID, a domain.Answer, minute int) *domain.AnswerComment {
+		return &domain.AnswerComment{ID: id, TestID: a.TestID, AnswerID: a.ID, StudentID: a.StudentID, AuthorRole: domain.RoleTeacher, AuthorID: string(e.fx.Teacher(0)), Body: "Body " + string(id), CreatedAt: minutes(minute)}
+	}
+	check(t, e.store.SaveAnswerComment(e.ctx, comment("c-3", a1, 3)), "SaveAnswerComment")
+	check(t, e.store.SaveAnswerComment(e.ctx, comment("c-1", a1, 1)), "SaveAnswerComment")
+	check(t, e.store.SaveAnswerComment(e.ctx, comment("c-2", a2, 2)), "SaveAnswerComment")
+
+	// Saving again marks the comment read.
+	read := comment("c-1", a1, 1)
+	readAt := minutes(5)
+	read.ReadAt = &readAt
+	check(t, e.store.SaveAnswerComment(e.ctx, read), "SaveAnswerComment")
+	got, err := e.store.GetAnswerComment(e.ctx, "c-1")
+	check(t, err, "GetAnswerComment")
+	if got == nil || got.Body != "Body c-1" || got.ReadAt == nil || !got.ReadAt.Equal(readAt) {
+		t.Fatalf("expected the read comment, got %+v", got)
+	}
+
+	byAnswer, err := e.store.ListAnswerComments(e.ctx, "test-1", a1.ID)
+	check(t, err, "ListAnswerComments")
+	sameIDs(t, "ListAnswerComments", ids(byAnswer, func(c domain.AnswerComment) domain.AnswerCommentID { return c.ID }), "c-1", "c-3")
+	byStudent, err := e.store.ListAnswerCommentsByStudent(e.ctx, e.fx.Student(1))
+	check(t, err, "ListAnswerCommentsByStudent")
+	sameIDs(t, "ListAnswerCommentsByStudent", ids(byStudent, func(c domain.AnswerComment) domain.AnswerCommentID { return c.ID }), "c-2")
+
+	none, err := e.store.ListAnswerCommentsByStudent(e.ctx, e.fx.Student(2))
+	check(t, err, "ListAnswerCommentsByStudent")
+	if none == nil || len(none) != 0 {
+		t.Fatalf("expected no comments, got %+v", none)
+	}
+	if c, err := e.store.GetAnswerComment(e.ctx, "missing"); c != nil || err != nil {
+		t.Fatalf("expected no comment, got %+v, %v", c, err)
+	}
+	refuse(t, e.store.SaveAnswerComment(e.ctx, &domain.AnswerComment{ID: "c-4", TestID: "missing", AnswerID: a1.ID, CreatedAt: minutes(4)}), "a comment on an unknown test")
+}
+
+func testSavesRubrics(t *testing.T, e env) {
+	teacher := e.fx.Teacher(0)
+	rubric := func(id domain.RubricID, minute int) domain.Rubric {
+		return domain.Rubric{ID: id, TeacherID: teacher, Title: "Rubric " + string(id), CreatedAt: minutes(minute), UpdatedAt: minutes(minute),
+			Criteria: []domain.RubricCriterion{{ID: "accuracy", Title: "Accuracy", Points: 4}, {ID: "clarity", Title: "Clarity", Points: 2}}}
+	}
+	template := func(id domain.FeedbackTemplateID, minute int) domain.FeedbackTemplate {
+		return domain.FeedbackTemplate{ID: id, TeacherID: teacher, RubricID: "r-1", CriterionID: "accuracy", Title: "Template " + string(id), Body: "Check your units.", CreatedAt: minutes(minute)}
+	}
+	check(t, e.store.SaveRubrics(e.ctx,
+		[]domain.Rubric{rubric("r-3", 3), rubric("r-1", 1), rubric("r-2", 2)},
+		[]domain.FeedbackTemplate{template("f-2", 2), template("f-1", 1), template("f-3", 3)},
+	), "SaveRubrics")
+
+	got, err := e.store.GetRubric(e.ctx, "r-1")
+	check(t, err, "GetRubric")
+	if got == nil || got.Title != "Rubric r-1" || len(got.Criteria) != 2 || got.Criteria[1].ID != "clarity" || got.Criteria[0].Points != 4 {
+		t.Fatalf("expected the rubric, got %+v", got)
+	}
+	rubrics := pages(t, "ListRubrics", func(p repository.PageRequest) (repository.Page[domain.Rubric], error) {
+		return e.store.ListRubrics(e.ctx, teacher, p)
+	})
+	sameIDs(t, "ListRubrics", ids(rubrics, func(r domain.Rubric) domain.RubricID { return r.ID }), "r-1", "r-2", "r-3")
+	templates := pages(t, "ListFeedbackTemplates", func(p repository.PageRequest) (repository.Page[domain.FeedbackTemplate], error) {
+		return e.store.ListFeedbackTemplates(e.ctx, teacher, p)
+	})
+	sameIDs(t, "ListFeedbackTemplates", ids(templates, func(f domain.FeedbackTemplate) domain.FeedbackTemplateID { return f.ID }), "f-1", "f-2", "f-3")
+	if templates[0].Body != "Check your units." || templates[0].CriterionID != "accuracy" {
+		t.Fatalf("expected the template's fields to be stored, got %+v", templates[0])
+	}
+
+	edited := rubric("r-1", 1)
+	edited.Title = "Edited"
+	stray := template("f-4", 4)
+	stray.TeacherID = "missing"
+	refuse(t, e.store.SaveRubrics(e.ctx, []domain.Rubric{edited}, []domain.FeedbackTemplate{stray}), "a template of an unknown teacher")
+	orphan := rubric("r-4", 4)
+	orphan.TeacherID = "missing"
+	refuse(t, e.store.SaveRubrics(e.ctx, []domain.Rubric{edited, orphan}, nil), "a rubric of an unknown teacher")
+	if got, _ := e.store.GetRubric(e.ctx, "r-1"); got == nil || got.Title != "Rubric r-1" {
+		t.Fatalf("expected a refused save to change nothing, got %+v", got)
+	}
+	if got, _ := e.store.GetRubric(e.ctx, "r-4"); got != nil {
+		t.Fatalf("expected a refused save to store nothing, got %+v", got)
+	}
+
+	none, err := e.store.ListRubrics(e.ctx, e.fx.Teacher(1), repository.All)
+	check(t, err, "ListRubrics")
+	if none.Items == nil || len(none.Items) != 0 {
+		t.Fatalf("expected no rubrics, got %+v", none)
+	}
+	if r, err := e.store.GetRubric(e.ctx, "missing"); r != nil || err != nil {
+		t.Fatalf("expected no rubric, got %+v, %v", r, err)
+	}
+}
+
+func testSavesDelegations(t *testing.T, e env) {
+	owner, delegate := e.fx.Teacher(0), e.fx.Teacher(1)
+	delegation := func(id domain.DelegationID, created, expires int) *domain.Delegation {
+		return &domain.Delegation{ID: id, TeacherID: owner, DelegateID: delegate, CreatedAt: minutes(created), ExpiresAt: minutes(expires)}
+	}
+	check(t, e.store.SaveDelegation(e.ctx, delegation("d-3", 3, 60)), "SaveDelegation")
+	check(t, e.store.SaveDelegation(e.ctx, delegation("d-1", 1, 10)), "SaveDelegation")
+	check(t, e.store.SaveDelegation(e.ctx, delegation("d-2", 2, 20)), "SaveDelegation")
+
+	got, err := e.store.GetDelegation(e.ctx, "d-1")
+	check(t, err, "GetDelegation")
+	if got == nil || got.DelegateID != delegate || !got.ExpiresAt.Equal(minutes(10)) {
+		t.Fatalf("expected the delegation, got %+v", got)
+	}
+	byTeacher := pages(t, "ListDelegationsByTeacher", func(p repository.PageRequest) (repository.Page[domain.Delegation], error) {
+		return e.store.ListDelegationsByTeacher(e.ctx, owner, p)
+	})
+	sameIDs(t, "ListDelegationsByTeacher", ids(byTeacher, func(d domain.Delegation) domain.DelegationID { return d.ID }), "d-1", "d-2", "d-3")
+	forDelegate, err := e.store.ListDelegationsForDelegate(e.ctx, delegate)
+	check(t, err, "ListDelegationsForDelegate")
+	sameIDs(t, "ListDelegationsForDelegate", ids(forDelegate, func(d domain.Delegation) domain.DelegationID { return d.ID }), "d-1", "d-2", "d-3")
+
+	// A delegation expiring at the moment of the sweep has expired.
+	expired, err := e.store.DeleteExpiredDelegations(e.ctx, minutes(20))
+	check(t, err, "DeleteExpiredDelegations")
+	sameIDs(t, "DeleteExpiredDelegations", ids(expired, func(d domain.Delegation) domain.DelegationID { return d.ID }), "d-1", "d-2")
+	if got, _ := e.store.GetDelegation(e.ctx, "d-2"); got != nil {
+		t.Fatalf("expected the expired delegation to be deleted, got %+v", got)
+	}
+	expired, err = e.store.DeleteExpiredDelegations(e.ctx, minutes(20))
+	check(t, err, "DeleteExpiredDelegations")
+	if expired == nil || len(expired) != 0 {
+		t.Fatalf("expected nothing left to expire, got %+v", expired)
+	}
+
+	check(t, e.store.DeleteDelegation(e.ctx, "d-3"), "DeleteDelegation")
+	if got, _ := e.store.GetDelegation(e.ctx, "d-3"); got != nil {
+		t.Fatalf("expected the delegation to be deleted, got %+v", got)
+	}
+	check(t, e.store.DeleteDelegation(e.ctx, "d-3"), "DeleteDelegation of a missing delegation")
+
+	unknown := delegation("d-4", 4, 60)
+	unknown.DelegateID = "missing"
+	refuse(t, e.store.SaveDelegation(e.ctx, unknown), "a delegation to an unknown teacher")
+	unknown = delegation("d-5", 4, 60)
+	unknown.TeacherID = "missing"
+	refuse(t, e.store.SaveDelegation(e.ctx, unknown), "a delegation from an unknown teacher")
+	none, err := e.store.ListDelegationsForDelegate(e.ctx, delegate)
+	check(t, err, "ListDelegationsForDelegate")
+	if none == nil || len(none) != 0 {
+		t.Fatalf("expected no delegations, got %+v", none)
+	}
+}
+
+func testSavesDeviceSessions(t *testing.T, e env) {
+	student := e.fx.Student(0)
+	session := func(id domain.DeviceSessionID, created, expires int) *domain.DeviceSession {
+		return &domain.DeviceSession{ID: id, StudentID: student, Role: domain.RoleStudent, Device: "tablet", Client: "192.0.2.1", CreatedAt: minutes(created), ExpiresAt: minutes(expires)}
+	}
+	check(t, e.store.SaveDeviceSession(e.ctx, session("s-2", 2, 20)), "SaveDeviceSession")
+	check(t, e.store.SaveDeviceSession(e.ctx, session("s-1", 1, 10)), "SaveDeviceSession")
+	check(t, e.store.SaveDeviceSession(e.ctx, session("s-3", 3, 60)), "SaveDeviceSession")
+
+	revoked := session("s-3", 3, 60)
+	revokedAt := minutes(4)
+	revoked.RevokedAt = &revokedAt
+	check(t, e.store.SaveDeviceSession(e.ctx, revoked), "SaveDeviceSession")
+	got, err := e.store.GetDeviceSession(e.ctx, "s-3")
+	check(t, err, "GetDeviceSession")
+	if got == nil || got.Device != "tablet" || got.Client != "192.0.2.1" || got.RevokedAt == nil || !got.RevokedAt.Equal(revokedAt) {
+		t.Fatalf("expected the revoked session, got %+v", got)
+	}
+	list, err := e.store.ListDeviceSessions(e.ctx, student)
+	check(t, err, "ListDeviceSessions")
+	sameIDs(t, "ListDeviceSessions", ids(list, func(d domain.DeviceSession) domain.DeviceSessionID { return d.ID }), "s-1", "s-2", "s-3")
+
+	expired, err := e.store.DeleteExpiredDeviceSessions(e.ctx, minutes(20))
+	check(t, err, "DeleteExpiredDeviceSessions")
+	sameIDs(t, "DeleteExpiredDeviceSessions", ids(expired, func(d domain.DeviceSession) domain.DeviceSessionID { return d.ID }), "s-1", "s-2")
+	list, err = e.store.ListDeviceSessions(e.ctx, student)
+	check(t, err, "ListDeviceSessions")
+	sameIDs(t, "ListDeviceSessions after expiry", ids(list, func(d domain.DeviceSession) domain.DeviceSessionID { return d.ID }), "s-3")
+
+	none, err := e.store.ListDeviceSessions(e.ctx, e.fx.Student(1))
+	check(t, err, "ListDeviceSessions")
+	if none == nil || len(none) != 0 {
+		t.Fatalf("expected no sessions, got %+v", none)
+	}
+	if s, err := e.store.GetDeviceSession(e.ctx, "missing"); s != nil || err != nil {
+		t.Fatalf("expected no session, got %+v, %v", s, err)
+	}
+	unknown := session("s-4", 4, 60)
+	unknown.StudentID = "missing"
+	refuse(t, e.store.SaveDeviceSession(e.ctx, unknown), "a session of an unknown student")
+}
+
+func testSavesBankQuestions(t *testing.T, e env) {
+	teacher := e.fx.Teacher(0)
+	question := func(id domain.BankQuestionID, minute int) *domain.BankQuestion {
+		return &domain.BankQuestion{ID: id, TeacherID: teacher, Prompt: "Prompt " + string(id), Points: 3, Type: domain.QuestionType("choice"),
+			Choices: []domain.Choice{{Key: "a", Label: "Yes"}, {Key: "b", Label: "No"}}, ExpectedResponse: "a", Tags: []string{"algebra"}, CreatedAt: minutes(minute), UpdatedAt: minutes(minute)}
+	}
+	check(t, e.store.SaveBankQuestion(e.ctx, question("b-2", 2)), "SaveBankQuestion")
+	check(t, e.store.SaveBankQuestion(e.ctx, question("b-1", 1)), "SaveBankQuestion")
+	check(t, e.store.SaveBankQuestion(e.ctx, question("b-3", 3)), "SaveBankQuestion")
+
+	shared := question("b-1", 1)
+	shared.Shared = true
+	shared.Tags = []string{"algebra", "review"}
+	check(t, e.store.SaveBankQuestion(e.ctx, shared), "SaveBankQuestion")
+	got, err := e.store.GetBankQuestion(e.ctx, "b-1")
+	check(t, err, "GetBankQuestion")
+	if got == nil || !got.Shared || len(got.Tags) != 2 || len(got.Choices) != 2 || got.ExpectedResponse != "a" || got.Points != 3 {
+		t.Fatalf("expected the updated bank question, got %+v", got)
+	}
+	list := pages(t, "ListBankQuestions", func(p repository.PageRequest) (repository.Page[domain.BankQuestion], error) {
+		return e.store.ListBankQuestions(e.ctx, teacher, p)
+	})
+	sameIDs(t, "ListBankQuestions", ids(list, func(q domain.BankQuestion) domain.BankQuestionID { return q.ID }), "b-1", "b-2", "b-3")
+
+	check(t, e.store.DeleteBankQuestion(e.ctx, "b-2"), "DeleteBankQuestion")
+	if got, _ := e.store.GetBankQuestion(e.ctx, "b-2"); got != nil {
+		t.Fatalf("expected the bank question to be deleted, got %+v", got)
+	}
+	check(t, e.store.DeleteBankQuestion(e.ctx, "b-2"), "DeleteBankQuestion of a missing question")
+
+	unknown := question("b-4", 4)
+	unknown.TeacherID = "missing"
+	refuse(t, e.store.SaveBankQuestion(e.ctx, unknown), "a bank question of an unknown teacher")
+	none, err := e.store.ListBankQuestions(e.ctx, e.fx.Teacher(1), repository.All)
+	check(t, err, "ListBankQuestions")
+	if none.Items == nil || len(none.Items) != 0 {
+		t.Fatalf("expected no bank questions, got %+v", none)
+	}
+}
+
+func testSavesDistricts(t *testing.T, e env) {
+	check(t, e.store.SaveDistrict(e.ctx, &domain.District{ID: "district-2", Name: "North", CreatedAt: minutes(2)}), "SaveDistrict")
+	check(t, e.store.SaveDistrict(e.ctx, &domain.District{ID: "district-1", Name: "South", CreatedAt: minutes(1)}), "SaveDistrict")
+
+	got, err := e.store.GetDistrict(e.ctx, "district-2")
+	check(t, err, "GetDistrict")
+	if got == nil || got.Name != "North" {
+		t.Fatalf("expected the district, got %+v", got)
+	}
+	districts := pages(t, "ListDistricts", func(p repository.PageRequest) (repository.Page[domain.District], error) {
+		return e.store.ListDistricts(e.ctx, p)
+	})
+	sameIDs(t, "ListDistricts", ids(districts, func(d domain.District) domain.DistrictID { return d.ID }), "district-1", "district-2")
+
+	school := e.fx.School
+	school.DistrictID = "district-1"
+	check(t, e.store.UpdateSchool(e.ctx, &school), "UpdateSchool")
+	schools := pages(t, "ListSchoolsByDistrict", func(p repository.PageRequest) (repository.Page[domain.School], error) {
+		return e.store.ListSchoolsByDistrict(e.ctx, "district-1", p)
+	})
+	sameIDs(t, "ListSchoolsByDistrict", ids(schools, func(s domain.School) domain.SchoolID { return s.ID }), string(school.ID))
+	none, err := e.store.ListSchoolsByDistrict(e.ctx, "district-2", repository.All)
+	check(t, err, "ListSchoolsByDistrict")
+	if none.Items == nil || len(none.Items) != 0 {
+		t.Fatalf("expected no schools, got %+v", none)
+	}
+
+	staff := domain.DistrictStaff{ID: "staff-1", DistrictID: "district-1", Name: "Superintendent", Email: "super@example.com", ManagedSchools: []domain.SchoolID{school.ID}, CreatedAt: minutes(3)}
+	check(t, e.store.SaveDistrictStaff(e.ctx, &staff), "SaveDistrictStaff")
+	gotStaff, err := e.store.GetDistrictStaff(e.ctx, "staff-1")
+	check(t, err, "GetDistrictStaff")
+	if gotStaff == nil || gotStaff.Email != staff.Email || len(gotStaff.ManagedSchools) != 1 || gotStaff.ManagedSchools[0] != school.ID {
+		t.Fatalf("expected the staff member, got %+v", gotStaff)
+	}
+	staff.ID, staff.DistrictID = "staff-2", "missing"
+	refuse(t, e.store.SaveDistrictStaff(e.ctx, &staff), "staff of an unknown district")
+
+	if d, err := e.store.GetDistrict(e.ctx, "missing"); d != nil || err != nil {
+		t.Fatalf("expected no district, got %+v, %v", d, err)
+	}
+	if s, err := e.store.GetDistrictStaff(e.ctx, "staff-2"); s != nil || err != nil {
+		t.Fatalf("expected no staff member, got %+v, %v", s, err)
+	}
+}
+
+func testListsAuditEntries(t *testing.T, e env) {
+	teacher := string(e.fx.Teacher(0))
+	oldScore, newScore := domain.Score(3), domain.Score(5)
+	oldCloses, newCloses := minutes(60), minutes(120)
+	entries := []domain.AuditEntry{
+		{ID: "a-1", Action: domain.AuditAction("grade.changed"), PrincipalRole: domain.RoleTeacher, PrincipalID: teacher, TestID: "test-1", StudentID: e.fx.Student(0), OldScore: &oldScore, NewScore: &newScore, CreatedAt: minutes(1)},
+		{ID: "a-2", Action: domain.AuditTestExtended, PrincipalRole: domain.RoleTeacher, PrincipalID: teacher, TestID: "test-1", OldClosesAt: &oldCloses, NewClosesAt: &newCloses, Reason: "snow day", CreatedAt: minutes(2)},
+		{ID: "a-3", Action: domain.AuditAction("grade.changed"), PrincipalRole: domain.RoleTeacher, PrincipalID: string(e.fx.Teacher(1)), TestID: "test-2", CreatedAt: minutes(3)},
+		{ID: "a-4", Action: domain.AuditAction("grade.changed"), PrincipalRole: domain.RoleTeacher, PrincipalID: teacher, TestID: "test-2", CreatedAt: minutes(4)},
+	}
+	for i := range entries {
+		check(t, e.store.SaveAuditEntry(e.ctx, &entries[i]), "SaveAuditEntry")
+	}
+
+	all := pages(t, "ListAuditEntries", func(p repository.PageRequest) (repository.Page[domain.AuditEntry], error) {
+		return e.store.ListAuditEntries(e.ctx, repository.AuditFilter{}, p)
+	})
+	sameIDs(t, "ListAuditEntries", ids(all, func(a domain.AuditEntry) domain.AuditEntryID { return a.ID }), "a-4", "a-3", "a-2", "a-1")
+	graded, extended := all[3], all[2]
+	if graded.OldScore == nil || *graded.OldScore != oldScore || graded.NewScore == nil || *graded.NewScore != newScore || graded.StudentID != e.fx.Student(0) {
+		t.Fatalf("expected the scores to be stored, got %+v", graded)
+	}
+	if extended.OldClosesAt == nil || !extended.OldClosesAt.Equal(oldCloses) || extended.NewClosesAt == nil || !extended.NewClosesAt.Equal(newCloses) || extended.Reason != "snow day" {
+		t.Fatalf("expected the close times to be stored, got %+v", extended)
+	}
+
+	for _, c := range []struct {
+		name   string
+		filter repository.AuditFilter
+		want   []string
+	}{
+		{"action", repository.AuditFilter{Action: "grade.changed"}, []string{"a-4", "a-3", "a-1"}},
+		{"principal", repository.AuditFilter{PrincipalID: teacher}, []string{"a-4", "a-2", "a-1"}},
+		{"test", repository.AuditFilter{TestID: "test-2", PrincipalID: teacher}, []string{"a-4"}},
+		{"since is inclusive", repository.AuditFilter{Since: minutes(2)}, []string{"a-4", "a-3", "a-2"}},
+		{"until is exclusive", repository.AuditFilter{Until: minutes(2)}, []string{"a-1"}},
+		{"nothing", repository.AuditFilter{Action: "missing"}, nil},
+	} {
+		page, err := e.store.ListAuditEntries(e.ctx, c.filter, repository.All)
+		check(t, err, "ListAuditEntries")
+		sameIDs(t, "ListAuditEntries by "+c.name, ids(page.Items, func(a domain.AuditEntry) domain.AuditEntryID { return a.ID }), c.want...)
+		if page.Items == nil {
+			t.Fatalf("ListAuditEntries by %s: expected an empty page, not nil", c.name)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+// Package repotest is a conformance suite for storage backends. A backend
+// proves it implements the repository interfaces the way the rest of the
+// service expects by running the suite against itself:
+//
+//	func TestConformance(t *testing.T) {
+//		repotest.Run(t, func(t *testing.T, seed memory.SeedData) repotest.Store {
+//			return memory.NewRepository(seed)
+//		})
+//	}
+//
+// The in-memory repository is the reference: every case describes what it
+// does, including the edge cases callers rely on such as missing records
+// being nil rather than an error, lists being ordered by creation time and
+// batch writes saving all records or none.
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/memory"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// Store is every repository a storage backend implements.
+type Store interface {
+	repository.OrganizationRepository
+	repository.TestRepository
+	repository.AnswerRepository
+	repository.ResultRepository
+	repository.NotificationRepository
+	repository.DigestRepository
+	repository.QuestionCommentRepository
+	repository.AnswerCommentRepository
+	repository.TestSessionRepository
+	repository.SubmissionRepository
+	repository.RubricRepository
+	repository.DelegationRepository
+	repository.DeviceSessionRepository
+	repository.QuestionBankRepository
+	repository.DistrictRepository
+	repository.AuditRepository
+}
+
+// Open returns a new backend holding nothing but seed. It is called once for
+// every case; resources it opens are released with t.Cleanup.
+type Open func(t *testing.T, seed memory.SeedData) Store
+
+// at is the time every case counts from. Timestamps are whole seconds in
+// UTC so that backends which serialize them compare equal.
+var at = time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC)
+
+// env is the backend of one case and the organization it was seeded with:
+// one school with one grade, one class, two teachers and three students.
+// Every call of the case is made under ctx.
+type env struct {
+	ctx   context.Context
+	store Store
+	fx    *fixtures.Fixture
+}
+
+type suiteCase struct {
+	name string
+	run  func(t *testing.T, e env)
+}
+
+// Run runs every case of the suite against a fresh backend from open.
+func Run(t *testing.T, open Open) {
+	t.Helper()
+	var cases []suiteCase
+	cases = append(cases, organizationCases...)
+	cases = append(cases, assessmentCases...)
+	cases = append(cases, recordCases...)
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			school := fixtures.NewSchool().WithTeachers(2).WithStudents(3)
+			seed := school.Seed()
+			store := open(t, seed)
+			if store == nil {
+				t.Fatalf("Open returned no store")
+			}
+			c.run(t, env{ctx: context.Background(), store: store, fx: school.Build()})
+		})
+	}
+}
+
+// check fails the case when err is not nil.
+func check(t *testing.T, err error, op string) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("%s failed: %v", op, err)
+	}
+}
+
+// refuse fails the case when err is nil.
+func refuse(t *testing.T, err error, what string) {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("expected %s to be refused", what)
+	}
+}
+
+// minutes returns the time n minutes after at.
+func minutes(n int) time.Time {
+	return at.Add(time.Duration(n) * time.Minute)
+}
+
+// ids lists the IDs of items in order.
+func ids[T any, ID ~string](items []T, id func(T) ID) []string {
+	out := make([]string, len(items))
+	for i, item := range items {
+		out[i] = string(id(item))
+	}
+	return out
+}
+
+// sameIDs fails the case unless got lists exactly want, in order.
+func sameIDs(t *testing.T, what string, got []string, want ...string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: expected %v, got %v", what, want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("%s: expected %v, got %v", what, want, got)
+		}
+	}
+}
+
+// pages reads every page of a list two items at a time and checks that
+// only the last page has no cursor.
+func pages[T any](t *testing.T, what string, list func(repository.PageRequest) (repository.Page[T], error)) []T {
+	t.Helper()
+	var all []T
+	req := repository.PageRequest{Limit: 2}
+	for {
+		page, err := list(req)
+		check(t, err, what)
+		if len(page.Items) > req.Limit {
+			t.Fatalf("%s: page of %d items exceeds the limit of %d", what, len(page.Items), req.Limit)
+		}
+		all = append(all, page.Items...)
+		if page.NextCursor == "" {
+			return all
+		}
+		if len(page.Items) == 0 {
+			t.Fatalf("%s: empty page with a cursor", what)
+		}
+		req.Cursor = page.NextCursor
+	}
+}
+
+// createTest stores a test of the teacher created at the given minute with
+// two questions, assigned to students.
+func createTest(t *testing.T, e env, id domain.TestID, teacherID domain.TeacherID, minute int, students ...domain.StudentID) (domain.Test, []domain.Question) {
+	t.Helper()
+	test := domain.Test{
+		ID:        id,
+		TeacherID: teacherID,
+		Title:     "Test " + string(id),
+		Version:   1,
+		CreatedAt: minutes(minute),
+		UpdatedAt: minutes(minute),
+	}
+	questions := []domain.Question{
+		{ID: domain.QuestionID(id + "-q2"), TestID: id, Sequence: 2, Prompt: "Second", Points: 5, CreatedAt: minutes(minute)},
+		{ID: domain.QuestionID(id + "-q1"), TestID: id, Sequence: 1, Prompt: "First", Points: 10, CreatedAt: minutes(minute)},
+	}
+	check(t, e.store.CreateTest(e.ctx, &test, questions, students), "CreateTest")
+	test.AssignedTo = students
+	return test, questions
+}
+
+// answer stores a student's answer to a question at the given minute.
+func answer(t *testing.T, e env, id domain.AnswerID, q domain.Question, studentID domain.StudentID, minute int) domain.Answer {
+	t.Helper()
+	a := domain.Answer{
+		ID:         id,
+		TestID:     q.TestID,
+		QuestionID: q.ID,
+		StudentID:  studentID,
+		Response:   "response " + string(id),
+		CreatedAt:  minutes(minute),
+		UpdatedAt:  minutes(minute),
+	}
+	check(t, e.store.UpsertAnswer(e.ctx, &a), "UpsertAnswer")
+	return a
+}
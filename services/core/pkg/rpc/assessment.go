@@ -0,0 +1,314 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+const assessmentService = "/gowork.assessment.v1.Assessment/"
+
+// GetTestRequest mirrors the message of the same name in assessment.proto.
+type GetTestRequest struct {
+	TeacherID string
+	TestID    string
+}
+
+func (m *GetTestRequest) MarshalProto() []byte {
+	var e encoder
+	e.string(1, m.TeacherID)
+	e.string(2, m.TestID)
+	return e.buf
+}
+
+func (m *GetTestRequest) UnmarshalProto(data []byte) error {
+	*m = GetTestRequest{}
+	d := decoder{data: data}
+	for d.next() {
+		switch d.field {
+		case 1:
+			m.TeacherID = d.string()
+		case 2:
+			m.TestID = d.string()
+		}
+	}
+	return d.err
+}
+
+// Test mirrors the message of the same name in assessment.proto.
+type Test struct {
+	TestID       string
+	TeacherID    string
+	Title        string
+	Instructions string
+	Published    bool
+	OpensAt      time.Time
+	ClosesAt     time.Time
+	AssignedTo   []string
+	Questions    []Question
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// NewTest converts a test and its questions to a message.
+func NewTest(t *domain.Test, questions []domain.Question) *Test {
+	m := &Test{
+		TestID:       string(t.ID),
+		TeacherID:    string(t.TeacherID),
+		Title:        t.Title,
+		Instructions: t.Instructions,
+		Published:    t.Published,
+		OpensAt:      timeValue(t.OpensAt),
+		ClosesAt:     timeValue(t.ClosesAt),
+		CreatedAt:    t.CreatedAt,
+		UpdatedAt:    t.UpdatedAt,
+	}
+	for _, id := range t.AssignedTo {
+		m.AssignedTo = append(m.AssignedTo, string(id))
+	}
+	for _, q := range questions {
+		qm := Question{
+			QuestionID:       string(q.ID),
+			Sequence:         int64(q.Sequence),
+			Prompt:           q.Prompt,
+			Points:           int64(q.Points),
+			Type:             string(q.Type),
+			ExpectedResponse: q.ExpectedResponse,
+		}
+		for _, c := range q.Choices {
+			qm.Choices = append(qm.Choices, Choice{Key: c.Key, Label: c.Label})
+		}
+		m.Questions = append(m.Questions, qm)
+	}
+	return m
+}
+
+// Domain converts the message back to a test and its questions. Fields
+// the contract does not carry, such as sections and curves, are left
+// empty.
+func (m *Test) Domain() (*domain.Test, []domain.Question) {
+	t := &domain.Test{
+		ID:           domain.TestID(m.TestID),
+		TeacherID:    domain.TeacherID(m.TeacherID),
+		Title:        m.Title,
+		Instructions: m.Instructions,
+		Published:    m.Published,
+		OpensAt:      timePtr(m.OpensAt),
+		ClosesAt:     timePtr(m.ClosesAt),
+		CreatedAt:    m.CreatedAt,
+		UpdatedAt:    m.UpdatedAt,
+	}
+	for _, id := range m.AssignedTo {
+		t.AssignedTo = append(t.AssignedTo, domain.StudentID(id))
+	}
+	questions := make([]domain.Question, 0, len(m.Questions))
+	for _, qm := range m.Questions {
+		q := domain.Question{
+			ID:               domain.QuestionID(qm.QuestionID),
+			TestID:           t.ID,
+			Sequence:         int(qm.Sequence),
+			Prompt:           qm.Prompt,
+			Points:           domain.Points(qm.Points),
+			Type:             domain.QuestionType(qm.Type),
+			ExpectedResponse: qm.ExpectedResponse,
+		}
+		for _, c := range qm.Choices {
+			q.Choices = append(q.Choices, domain.Choice{Key: c.Key, Label: c.Label})
+		}
+		questions = append(questions, q)
+	}
+	return t, questions
+}
+
+func (m *Test) MarshalProto() []byte {
+	var e encoder
+	e.string(1, m.TestID)
+	e.string(2, m.TeacherID)
+	e.string(3, m.Title)
+	e.string(4, m.Instructions)
+	e.bool(5, m.Published)
+	e.time(6, m.OpensAt)
+	e.time(7, m.ClosesAt)
+	e.strings(8, m.AssignedTo)
+	for i := range m.Questions {
+		e.message(9, &m.Questions[i])
+	}
+	e.time(10, m.CreatedAt)
+	e.time(11, m.UpdatedAt)
+	return e.buf
+}
+
+func (m *Test) UnmarshalProto(data []byte) error {
+	*m = Test{}
+	d := decoder{data: data}
+	for d.next() {
+		switch d.field {
+		case 1:
+			m.TestID = d.string()
+		case 2:
+			m.TeacherID = d.string()
+		case 3:
+			m.Title = d.string()
+		case 4:
+			m.Instructions = d.string()
+		case 5:
+			m.Published = d.bool()
+		case 6:
+			m.OpensAt = d.time()
+		case 7:
+			m.ClosesAt = d.time()
+		case 8:
+			m.AssignedTo = append(m.AssignedTo, d.string())
+		case 9:
+			var q Question
+			d.message(&q)
+			m.Questions = append(m.Questions, q)
+		case 10:
+			m.CreatedAt = d.time()
+		case 11:
+			m.UpdatedAt = d.time()
+		}
+	}
+	return d.err
+}
+
+// Question mirrors the message of the same name in assessment.proto.
+type Question struct {
+	QuestionID       string
+	Sequence         int64
+	Prompt           string
+	Points           int64
+	Type             string
+	Choices          []Choice
+	ExpectedResponse string
+}
+
+func (m *Question) MarshalProto() []byte {
+	var e encoder
+	e.string(1, m.QuestionID)
+	e.int64(2, m.Sequence)
+	e.string(3, m.Prompt)
+	e.int64(4, m.Points)
+	e.string(5, m.Type)
+	for i := range m.Choices {
+		e.message(6, &m.Choices[i])
+	}
+	e.string(7, m.ExpectedResponse)
+	return e.buf
+}
+
+func (m *Question) UnmarshalProto(data []byte) error {
+	*m = Question{}
+	d := decoder{data: data}
+	for d.next() {
+		switch d.field {
+		case 1:
+			m.QuestionID = d.string()
+		case 2:
+			m.Sequence = d.int64()
+		case 3:
+			m.Prompt = d.string()
+		case 4:
+			m.Points = d.int64()
+		case 5:
+			m.Type = d.string()
+		case 6:
+			var c Choice
+			d.message(&c)
+			m.Choices = append(m.Choices, c)
+		case 7:
+			m.ExpectedResponse = d.string()
+		}
+	}
+	return d.err
+}
+
+// Choice mirrors the message of the same name in assessment.proto.
+type Choice struct {
+	Key   string
+	Label string
+}
+
+func (m *Choice) MarshalProto() []byte {
+	var e encoder
+	e.string(1, m.Key)
+	e.string(2, m.Label)
+	return e.buf
+}
+
+func (m *Choice) UnmarshalProto(data []byte) error {
+	*m = Choice{}
+	d := decoder{data: data}
+	for d.next() {
+		switch d.field {
+		case 1:
+			m.Key = d.string()
+		case 2:
+			m.Label = d.string()
+		}
+	}
+	return d.err
+}
+
+// AssessmentServer implements the Assessment service of assessment.proto.
+type AssessmentServer interface {
+	GetTest(ctx context.Context, req *GetTestRequest) (*Test, error)
+}
+
+// RegisterAssessment adds the Assessment methods to s.
+func RegisterAssessment(s *Server, impl AssessmentServer) {
+	s.handle(assessmentService+"GetTest", func(ctx context.Context, body []byte) (Message, error) {
+		var req GetTestRequest
+		if err := req.UnmarshalProto(body); err != nil {
+			return nil, Errorf(InvalidArgument, "%v", err)
+		}
+		return unary(impl.GetTest(ctx, &req))
+	})
+}
+
+// NewAssessmentServer serves the Assessment service from assessments. The
+// caller must be authenticated as the teacher named in the request.
+func NewAssessmentServer(assessments *usecase.AssessmentService) AssessmentServer {
+	return assessmentServer{assessments: assessments}
+}
+
+type assessmentServer struct {
+	assessments *usecase.AssessmentService
+}
+
+func (s assessmentServer) GetTest(ctx context.Context, req *GetTestRequest) (*Test, error) {
+	teacherID := domain.TeacherID(req.TeacherID)
+	if !auth.IsTeacher(ctx, teacherID) {
+		return nil, Errorf(PermissionDenied, "caller is not teacher %s", req.TeacherID)
+	}
+	test, err := s.assessments.GetTestForTeacher(ctx, teacherID, domain.TestID(req.TestID))
+	if err != nil {
+		return nil, err
+	}
+	questions, err := s.assessments.GetQuestionsForTeacher(ctx, teacherID, test.ID)
+	if err != nil {
+		return nil, err
+	}
+	return NewTest(test, questions), nil
+}
+
+// AssessmentClient calls a remote Assessment service.
+type AssessmentClient struct {
+	conn *Client
+}
+
+// NewAssessmentClient returns an Assessment client using conn.
+func NewAssessmentClient(conn *Client) *AssessmentClient {
+	return &AssessmentClient{conn: conn}
+}
+
+func (c *AssessmentClient) GetTest(ctx context.Context, req *GetTestRequest) (*Test, error) {
+	var resp Test
+	if err := c.conn.invoke(ctx, assessmentService+"GetTest", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
@@ -0,0 +1,311 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+const gradingService = "/gowork.grading.v1.Grading/"
+
+// GradeAnswerRequest mirrors the message of the same name in grading.proto.
+type GradeAnswerRequest struct {
+	TeacherID  string
+	TestID     string
+	QuestionID string
+	StudentID  string
+	Score      int64
+	Feedback   string
+	Completed  bool
+	// ExpectedVersion is optional.
+	ExpectedVersion *int64
+}
+
+// NewGradeAnswerRequest converts a grading payload to its message.
+func NewGradeAnswerRequest(input usecase.GradeInput) *GradeAnswerRequest {
+	m := &GradeAnswerRequest{
+		TeacherID:  string(input.TeacherID),
+		TestID:     string(input.TestID),
+		QuestionID: string(input.QuestionID),
+		StudentID:  string(input.StudentID),
+		Score:      int64(input.Score),
+		Feedback:   input.Feedback,
+		Completed:  input.Completed,
+	}
+	if input.ExpectedVersion != nil {
+		version := int64(*input.ExpectedVersion)
+		m.ExpectedVersion = &version
+	}
+	return m
+}
+
+// Input converts the message back to a grading payload.
+func (m *GradeAnswerRequest) Input() usecase.GradeInput {
+	input := usecase.GradeInput{
+		TeacherID:  domain.TeacherID(m.TeacherID),
+		TestID:     domain.TestID(m.TestID),
+		QuestionID: domain.QuestionID(m.QuestionID),
+		StudentID:  domain.StudentID(m.StudentID),
+		Score:      domain.Score(m.Score),
+		Feedback:   m.Feedback,
+		Completed:  m.Completed,
+	}
+	if m.ExpectedVersion != nil {
+		version := int(*m.ExpectedVersion)
+		input.ExpectedVersion = &version
+	}
+	return input
+}
+
+func (m *GradeAnswerRequest) MarshalProto() []byte {
+	var e encoder
+	e.string(1, m.TeacherID)
+	e.string(2, m.TestID)
+	e.string(3, m.QuestionID)
+	e.string(4, m.StudentID)
+	e.int64(5, m.Score)
+	e.string(6, m.Feedback)
+	e.bool(7, m.Completed)
+	e.optionalInt64(8, m.ExpectedVersion)
+	return e.buf
+}
+
+func (m *GradeAnswerRequest) UnmarshalProto(data []byte) error {
+	*m = GradeAnswerRequest{}
+	d := decoder{data: data}
+	for d.next() {
+		switch d.field {
+		case 1:
+			m.TeacherID = d.string()
+		case 2:
+			m.TestID = d.string()
+		case 3:
+			m.QuestionID = d.string()
+		case 4:
+			m.StudentID = d.string()
+		case 5:
+			m.Score = d.int64()
+		case 6:
+			m.Feedback = d.string()
+		case 7:
+			m.Completed = d.bool()
+		case 8:
+			version := d.int64()
+			m.ExpectedVersion = &version
+		}
+	}
+	return d.err
+}
+
+// Result mirrors the message of the same name in grading.proto.
+type Result struct {
+	ResultID  string
+	AnswerID  string
+	Score     int64
+	RawScore  *int64
+	Feedback  string
+	Completed bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Version   int64
+}
+
+// NewResult converts a result to its message.
+func NewResult(r *domain.Result) *Result {
+	m := &Result{
+		ResultID:  string(r.ID),
+		AnswerID:  string(r.AnswerID),
+		Score:     int64(r.Score),
+		Feedback:  r.Feedback,
+		Completed: r.Completed,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+		Version:   int64(r.Version),
+	}
+	if r.RawScore != nil {
+		raw := int64(*r.RawScore)
+		m.RawScore = &raw
+	}
+	return m
+}
+
+// Domain converts the message back to a result.
+func (m *Result) Domain() *domain.Result {
+	r := &domain.Result{
+		ID:        domain.ResultID(m.ResultID),
+		AnswerID:  domain.AnswerID(m.AnswerID),
+		Score:     domain.Score(m.Score),
+		Feedback:  m.Feedback,
+		Completed: m.Completed,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+		Version:   int(m.Version),
+	}
+	if m.RawScore != nil {
+		raw := domain.Score(*m.RawScore)
+		r.RawScore = &raw
+	}
+	return r
+}
+
+func (m *Result) MarshalProto() []byte {
+	var e encoder
+	e.string(1, m.ResultID)
+	e.string(2, m.AnswerID)
+	e.int64(3, m.Score)
+	e.optionalInt64(4, m.RawScore)
+	e.string(5, m.Feedback)
+	e.bool(6, m.Completed)
+	e.time(7, m.CreatedAt)
+	e.time(8, m.UpdatedAt)
+	e.int64(9, m.Version)
+	return e.buf
+}
+
+func (m *Result) UnmarshalProto(data []byte) error {
+	*m = Result{}
+	d := decoder{data: data}
+	for d.next() {
+		switch d.field {
+		case 1:
+			m.ResultID = d.string()
+		case 2:
+			m.AnswerID = d.string()
+		case 3:
+			m.Score = d.int64()
+		case 4:
+			raw := d.int64()
+			m.RawScore = &raw
+		case 5:
+			m.Feedback = d.string()
+		case 6:
+			m.Completed = d.bool()
+		case 7:
+			m.CreatedAt = d.time()
+		case 8:
+			m.UpdatedAt = d.time()
+		case 9:
+			m.Version = d.int64()
+		}
+	}
+	return d.err
+}
+
+// AutogradeTestRequest mirrors the message of the same name in
+// grading.proto.
+type AutogradeTestRequest struct {
+	TeacherID string
+	TestID    string
+}
+
+func (m *AutogradeTestRequest) MarshalProto() []byte {
+	var e encoder
+	e.string(1, m.TeacherID)
+	e.string(2, m.TestID)
+	return e.buf
+}
+
+func (m *AutogradeTestRequest) UnmarshalProto(data []byte) error {
+	*m = AutogradeTestRequest{}
+	d := decoder{data: data}
+	for d.next() {
+		switch d.field {
+		case 1:
+			m.TeacherID = d.string()
+		case 2:
+			m.TestID = d.string()
+		}
+	}
+	return d.err
+}
+
+// AutogradeSummary mirrors the message of the same name in grading.proto.
+type AutogradeSummary struct {
+	Graded int64
+	Kept   int64
+	Manual int64
+}
+
+func (m *AutogradeSummary) MarshalProto() []byte {
+	var e encoder
+	e.int64(1, m.Graded)
+	e.int64(2, m.Kept)
+	e.int64(3, m.Manual)
+	return e.buf
+}
+
+func (m *AutogradeSummary) UnmarshalProto(data []byte) error {
+	*m = AutogradeSummary{}
+	d := decoder{data: data}
+	for d.next() {
+		switch d.field {
+		case 1:
+			m.Graded = d.int64()
+		case 2:
+			m.Kept = d.int64()
+		case 3:
+			m.Manual = d.int64()
+		}
+	}
+	return d.err
+}
+
+// GradingServer implements the Grading service of grading.proto.
+type GradingServer interface {
+	GradeAnswer(ctx context.Context, req *GradeAnswerRequest) (*Result, error)
+	AutogradeTest(ctx context.Context, req *AutogradeTestRequest) (*AutogradeSummary, error)
+}
+
+// RegisterGrading adds the Grading methods to s.
+func RegisterGrading(s *Server, impl GradingServer) {
+	s.handle(gradingService+"GradeAnswer", func(ctx context.Context, body []byte) (Message, error) {
+		var req GradeAnswerRequest
+		if err := req.UnmarshalProto(body); err != nil {
+			return nil, Errorf(InvalidArgument, "%v", err)
+		}
+		return unary(impl.GradeAnswer(ctx, &req))
+	})
+	s.handle(gradingService+"AutogradeTest", func(ctx context.Context, body []byte) (Message, error) {
+		var req AutogradeTestRequest
+		if err := req.UnmarshalProto(body); err != nil {
+			return nil, Errorf(InvalidArgument, "%v", err)
+		}
+		return unary(impl.AutogradeTest(ctx, &req))
+	})
+}
+
+// GradingClient calls a remote Grading service.
+type GradingClient struct {
+	conn *Client
+}
+
+// NewGradingClient returns a Grading client using conn.
+func NewGradingClient(conn *Client) *GradingClient {
+	return &GradingClient{conn: conn}
+}
+
+func (c *GradingClient) GradeAnswer(ctx context.Context, req *GradeAnswerRequest) (*Result, error) {
+	var resp Result
+	if err := c.conn.invoke(ctx, gradingService+"GradeAnswer", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *GradingClient) AutogradeTest(ctx context.Context, req *AutogradeTestRequest) (*AutogradeSummary, error) {
+	var resp AutogradeSummary
+	if err := c.conn.invoke(ctx, gradingService+"AutogradeTest", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// unary keeps a typed nil reply from turning into a non-nil Message.
+func unary[M Message](resp M, err error) (Message, error) {
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
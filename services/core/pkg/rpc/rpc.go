@@ -0,0 +1,495 @@
+// Package rpc carries service-to-service calls as unary gRPC over
+// unencrypted HTTP/2. The contracts live in core/proto; this package holds
+// the wire encoding, a server that mounts like any other http.Handler and a
+// client, all built on the standard library.
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/httpmw"
+	"github.com/sky0621/go_work_sample/core/pkg/tracing"
+)
+
+// ContentType is the media type of gRPC requests and responses.
+const ContentType = "application/grpc"
+
+// MaxMessageSize bounds a single request or response message.
+const MaxMessageSize = 4 << 20
+
+// Code is a gRPC status code.
+type Code uint32
+
+const (
+	OK                 Code = 0
+	Canceled           Code = 1
+	Unknown            Code = 2
+	InvalidArgument    Code = 3
+	DeadlineExceeded   Code = 4
+	NotFound           Code = 5
+	AlreadyExists      Code = 6
+	PermissionDenied   Code = 7
+	ResourceExhausted  Code = 8
+	FailedPrecondition Code = 9
+	Aborted            Code = 10
+	OutOfRange         Code = 11
+	Unimplemented      Code = 12
+	Internal           Code = 13
+	Unavailable        Code = 14
+	DataLoss           Code = 15
+	Unauthenticated    Code = 16
+)
+
+// Status is the error returned for a call that did not end with OK.
+type Status struct {
+	Code    Code
+	Message string
+}
+
+func (s *Status) Error() string {
+	return fmt.Sprintf("rpc error: code = %d desc = %s", s.Code, s.Message)
+}
+
+// Errorf returns a Status error with the given code.
+func Errorf(code Code, format string, args ...any) error {
+	return &Status{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// CodeOf returns the status code carried by err: OK for nil and Unknown
+// for errors that are not a Status.
+func CodeOf(err error) Code {
+	if err == nil {
+		return OK
+	}
+	var st *Status
+	if errors.As(err, &st) {
+		return st.Code
+	}
+	return Unknown
+}
+
+// statusOf converts a service error to the status sent to the caller.
+func statusOf(err error) *Status {
+	var st *Status
+	if errors.As(err, &st) {
+		return st
+	}
+	var invariant *domain.InvariantError
+	if errors.As(err, &invariant) {
+		return &Status{Code: InvalidArgument, Message: err.Error()}
+	}
+	code := Internal
+	switch {
+	case errors.Is(err, context.Canceled):
+		code = Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		code = DeadlineExceeded
+	case errors.Is(err, errs.ErrTeacherNotFound), errors.Is(err, errs.ErrTestNotFound),
+		errors.Is(err, errs.ErrQuestionNotFound), errors.Is(err, errs.ErrAnswerNotFound),
+		errors.Is(err, errs.ErrResultNotFound):
+		code = NotFound
+	case errors.Is(err, errs.ErrStudentNotFound), errors.Is(err, errs.ErrStudentNotAssigned),
+		errors.Is(err, errs.ErrResponseTooLong), errors.Is(err, errs.ErrScoreOutOfRange):
+		code = InvalidArgument
+	case errors.Is(err, errs.ErrForbiddenTeacher), errors.Is(err, errs.ErrForbiddenStudent):
+		code = PermissionDenied
+	case errors.Is(err, errs.ErrQuotaExceeded), errors.Is(err, errs.ErrTooManyRequests),
+		errors.Is(err, errs.ErrAnswerThrottled), errors.Is(err, errs.ErrDuplicateAnswer):
+		code = ResourceExhausted
+	case errors.Is(err, errs.ErrTestClosed), errors.Is(err, errs.ErrTestSubmitted),
+		errors.Is(err, errs.ErrTestNotStarted), errors.Is(err, errs.ErrTimeExpired):
+		code = FailedPrecondition
+	case errors.Is(err, errs.ErrSubmitUnavailable), errors.Is(err, errs.ErrTimerUnavailable):
+		code = Unimplemented
+	case errors.Is(err, errs.ErrConflict):
+		code = Aborted
+	}
+	return &Status{Code: code, Message: err.Error()}
+}
+
+// knownErrors are the service errors a remote call can return. ServiceError
+// turns a status back into one of them so callers keep matching the errs
+// sentinels they know.
+var knownErrors = []error{
+	errs.ErrTeacherNotFound, errs.ErrStudentNotFound, errs.ErrTestNotFound,
+	errs.ErrQuestionNotFound, errs.ErrAnswerNotFound, errs.ErrResultNotFound,
+	errs.ErrStudentNotAssigned, errs.ErrForbiddenTeacher, errs.ErrForbiddenStudent,
+	errs.ErrInvalidTest, errs.ErrInvalidQuestion, errs.ErrInvalidAnswer,
+	errs.ErrQuotaExceeded, errs.ErrTooManyRequests, errs.ErrTestClosed,
+	errs.ErrResponseTooLong, errs.ErrAnswerThrottled, errs.ErrDuplicateAnswer,
+	errs.ErrTestSubmitted, errs.ErrSubmitUnavailable, errs.ErrScoreOutOfRange,
+	errs.ErrTestNotStarted, errs.ErrTimeExpired, errs.ErrTimerUnavailable,
+	errs.ErrConflict,
+}
+
+// ServiceError returns the service error a remote call failed with: the
+// errs sentinel or domain.InvariantError the server reported, or err
+// itself when the status names neither.
+func ServiceError(err error) error {
+	var st *Status
+	if !errors.As(err, &st) {
+		return err
+	}
+	for _, known := range knownErrors {
+		if st.Message == known.Error() {
+			return known
+		}
+		if st.Code != InvalidArgument {
+			continue
+		}
+		detail, ok := strings.CutPrefix(st.Message, known.Error()+": ")
+		if !ok {
+			continue
+		}
+		field, reason, _ := strings.Cut(detail, " ")
+		return &domain.InvariantError{Field: field, Reason: reason, Err: known}
+	}
+	return err
+}
+
+type method func(ctx context.Context, body []byte) (Message, error)
+
+// Server dispatches unary gRPC calls to registered methods. It is an
+// http.Handler, so the authentication middleware of the HTTP API applies
+// unchanged; serve it over HTTP/2 with NewHTTPServer.
+type Server struct {
+	methods map[string]method
+}
+
+// NewServer returns a server without methods. Use the Register functions
+// of each service to add them.
+func NewServer() *Server {
+	return &Server{methods: make(map[string]method)}
+}
+
+func (s *Server) handle(path string, fn method) {
+	s.methods[path] = fn
+}
+
+// ServeHTTP answers a single unary call. Failures are reported in the
+// grpc-status trailer, as gRPC clients expect.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if ct := r.Header.Get("Content-Type"); ct != ContentType && !strings.HasPrefix(ct, ContentType+"+") && !strings.HasPrefix(ct, ContentType+";") {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+	w.WriteHeader(http.StatusOK)
+
+	resp, err := s.call(r)
+	if err == nil {
+		_, err = w.Write(frame(resp.MarshalProto()))
+	}
+	st := &Status{Code: OK}
+	if err != nil {
+		st = statusOf(err)
+	}
+	w.Header().Set("Grpc-Status", strconv.Itoa(int(st.Code)))
+	if st.Message != "" {
+		w.Header().Set("Grpc-Message", encodeMessage(st.Message))
+	}
+	// The HTTP status is always 200, so the server span started by
+	// httpmw.Tracing learns the outcome here.
+	span := tracing.SpanFromContext(r.Context())
+	span.SetAttributes(tracing.String("rpc.system", "grpc"), tracing.Int("rpc.grpc.status_code", int(st.Code)))
+	if serverFault(st.Code) {
+		span.SetError(st)
+	}
+}
+
+// serverFault reports whether code blames the server rather than the call.
+func serverFault(code Code) bool {
+	switch code {
+	case Unknown, DeadlineExceeded, Unimplemented, Internal, Unavailable, DataLoss:
+		return true
+	}
+	return false
+}
+
+func (s *Server) call(r *http.Request) (Message, error) {
+	fn, ok := s.methods[r.URL.Path]
+	if !ok {
+		return nil, Errorf(Unimplemented, "unknown method %s", r.URL.Path)
+	}
+	ctx := r.Context()
+	if timeout := r.Header.Get("Grpc-Timeout"); timeout != "" {
+		d, err := parseTimeout(timeout)
+		if err != nil {
+			return nil, Errorf(InvalidArgument, "%v", err)
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+	body, err := readFrame(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	return fn(ctx, body)
+}
+
+// NewHTTPServer returns a server speaking HTTP/2 without TLS, which gRPC
+// clients use inside the cluster network.
+func NewHTTPServer(addr string, handler http.Handler) *http.Server {
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		Protocols:         &protocols,
+		ReadHeaderTimeout: 5 * time.Second,
+		IdleTimeout:       120 * time.Second,
+	}
+}
+
+// ClientOptions configures a Client.
+type ClientOptions struct {
+	// Token returns the bearer token sent with a call. Nil sends none.
+	Token func(ctx context.Context) (string, error)
+	// Timeout bounds calls whose context has no deadline. Zero means no
+	// bound.
+	Timeout time.Duration
+}
+
+// ForwardPrincipal returns a ClientOptions.Token func that signs a token
+// for the principal of the incoming request, so the remote service checks
+// access against the same user. Tokens live for ttl only.
+func ForwardPrincipal(signer *auth.Signer, ttl time.Duration) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		principal, ok := auth.PrincipalFrom(ctx)
+		if !ok {
+			return "", errors.New("no authenticated principal to forward")
+		}
+		token, _, err := signer.Issue(principal, ttl)
+		return token, err
+	}
+}
+
+// Client calls unary methods of a remote Server.
+type Client struct {
+	target string
+	http   *http.Client
+	opts   ClientOptions
+}
+
+// NewClient returns a client for the server listening on target, a
+// host:port address reached over unencrypted HTTP/2.
+func NewClient(target string, opts ClientOptions) *Client {
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+	return &Client{
+		target: strings.TrimSuffix(target, "/"),
+		http:   &http.Client{Transport: &http.Transport{Protocols: &protocols}},
+		opts:   opts,
+	}
+}
+
+// invoke calls the method at path and decodes the reply into resp.
+func (c *Client) invoke(ctx context.Context, path string, req, resp Message) (err error) {
+	ctx, span := tracing.Start(ctx, strings.TrimPrefix(path, "/"),
+		tracing.WithKind(tracing.KindClient),
+		tracing.WithAttributes(tracing.String("rpc.system", "grpc"), tracing.String("server.address", c.target)),
+	)
+	defer func() {
+		span.SetAttributes(tracing.Int("rpc.grpc.status_code", int(CodeOf(err))))
+		span.SetError(err)
+		span.End()
+	}()
+	if _, ok := ctx.Deadline(); !ok && c.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.opts.Timeout)
+		defer cancel()
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://"+c.target+path, bytes.NewReader(frame(req.MarshalProto())))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", ContentType)
+	httpReq.Header.Set("Te", "trailers")
+	tracing.Inject(ctx, httpReq.Header)
+	if id := httpmw.RequestIDFrom(ctx); id != "" {
+		httpReq.Header.Set(httpmw.RequestIDHeader, id)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		httpReq.Header.Set("Grpc-Timeout", formatTimeout(time.Until(deadline)))
+	}
+	if c.opts.Token != nil {
+		token, err := c.opts.Token(ctx)
+		if err != nil {
+			return Errorf(Unauthenticated, "%v", err)
+		}
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	httpResp, err := c.http.Do(httpReq)
+	if err != nil {
+		if ctx.Err() != nil {
+			return Errorf(DeadlineExceeded, "%v", err)
+		}
+		return Errorf(Unavailable, "%v", err)
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return Errorf(codeForHTTP(httpResp.StatusCode), "unexpected HTTP status %s", httpResp.Status)
+	}
+
+	body, readErr := io.ReadAll(io.LimitReader(httpResp.Body, MaxMessageSize+5+1))
+	if readErr != nil {
+		return Errorf(Unavailable, "%v", readErr)
+	}
+	// A call that fails before any message is sent may carry its status in
+	// the headers alone.
+	status := httpResp.Trailer.Get("Grpc-Status")
+	message := httpResp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		status = httpResp.Header.Get("Grpc-Status")
+		message = httpResp.Header.Get("Grpc-Message")
+	}
+	code, err := strconv.Atoi(status)
+	if err != nil {
+		return Errorf(Internal, "missing grpc-status in reply")
+	}
+	if Code(code) != OK {
+		return &Status{Code: Code(code), Message: decodeMessage(message)}
+	}
+	payload, err := readFrame(bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if err := resp.UnmarshalProto(payload); err != nil {
+		return Errorf(Internal, "%v", err)
+	}
+	return nil
+}
+
+// codeForHTTP maps an HTTP status of a reply that is not gRPC, such as an
+// authentication failure, to a status code as gRPC clients do.
+func codeForHTTP(status int) Code {
+	switch status {
+	case http.StatusBadRequest:
+		return Internal
+	case http.StatusUnauthorized:
+		return Unauthenticated
+	case http.StatusForbidden:
+		return PermissionDenied
+	case http.StatusNotFound:
+		return Unimplemented
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return Unavailable
+	default:
+		return Unknown
+	}
+}
+
+// frame prefixes a message with the uncompressed flag and its length.
+func frame(msg []byte) []byte {
+	out := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(out[1:], uint32(len(msg)))
+	return append(out, msg...)
+}
+
+func readFrame(r io.Reader) ([]byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, Errorf(Internal, "reading message header: %v", err)
+	}
+	if header[0] != 0 {
+		return nil, Errorf(Unimplemented, "compressed messages are not supported")
+	}
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > MaxMessageSize {
+		return nil, Errorf(ResourceExhausted, "message of %d bytes exceeds the limit of %d", size, MaxMessageSize)
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, Errorf(Internal, "reading message: %v", err)
+	}
+	return msg, nil
+}
+
+var timeoutUnits = []struct {
+	suffix byte
+	unit   time.Duration
+}{
+	{'H', time.Hour}, {'M', time.Minute}, {'S', time.Second},
+	{'m', time.Millisecond}, {'u', time.Microsecond}, {'n', time.Nanosecond},
+}
+
+// formatTimeout writes a grpc-timeout value, which allows at most eight
+// digits, in the finest unit that fits.
+func formatTimeout(d time.Duration) string {
+	if d <= 0 {
+		return "1n"
+	}
+	for i := len(timeoutUnits) - 1; i >= 0; i-- {
+		u := timeoutUnits[i]
+		if v := (d + u.unit - 1) / u.unit; v < 1e8 {
+			return strconv.FormatInt(int64(v), 10) + string(u.suffix)
+		}
+	}
+	return "99999999H"
+}
+
+func parseTimeout(s string) (time.Duration, error) {
+	if len(s) < 2 {
+		return 0, fmt.Errorf("invalid grpc-timeout %q", s)
+	}
+	v, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+	if err != nil || v < 0 || len(s) > 9 {
+		return 0, fmt.Errorf("invalid grpc-timeout %q", s)
+	}
+	for _, u := range timeoutUnits {
+		if u.suffix == s[len(s)-1] {
+			return time.Duration(v) * u.unit, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid grpc-timeout %q", s)
+}
+
+// encodeMessage percent-encodes a grpc-message value as the protocol asks.
+func encodeMessage(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 0x20 && c <= 0x7e && c != '%' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func decodeMessage(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+3], 16, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,164 @@
+package rpc_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/httpmw"
+	"github.com/sky0621/go_work_sample/core/pkg/rpc"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+// serve starts h on a loopback HTTP/2 listener and returns its address.
+func serve(t *testing.T, h http.Handler) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	server := rpc.NewHTTPServer("", h)
+	go func() { _ = server.Serve(ln) }()
+	t.Cleanup(func() { _ = server.Close() })
+	return ln.Addr().String()
+}
+
+func TestAssessment_GetTestOverTheNetwork(t *testing.T) {
+	fx := fixtures.NewSchool().WithTeachers(2).WithStudents(1).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	opensAt := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	closesAt := opensAt.Add(time.Hour)
+	test, _, err := service.CreateTest(context.Background(), usecase.CreateTestInput{
+		Title:     "Quiz",
+		TeacherID: fx.Teacher(0),
+		OpensAt:   &opensAt,
+		ClosesAt:  &closesAt,
+		Questions: []usecase.QuestionDraft{
+			{Prompt: "2 + 2", Points: 1},
+			{Prompt: "Pick one", Points: 2, Type: domain.QuestionMultipleChoice, Choices: []domain.Choice{{Key: "a", Label: "A"}, {Key: "b", Label: "B"}}, ExpectedResponse: "b"},
+		},
+		StudentIDs: []domain.StudentID{fx.Student(0)},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+
+	signer := auth.NewSigner("secret")
+	server := rpc.NewServer()
+	rpc.RegisterAssessment(server, rpc.NewAssessmentServer(service))
+	addr := serve(t, httpmw.JWT(httpmw.JWTConfig{Signer: signer, Roles: []domain.Role{domain.RoleTeacher}})(server))
+	client := rpc.NewAssessmentClient(rpc.NewClient(addr, rpc.ClientOptions{
+		Token:   rpc.ForwardPrincipal(signer, time.Minute),
+		Timeout: 5 * time.Second,
+	}))
+
+	owner := auth.WithPrincipal(context.Background(), auth.Principal{Role: domain.RoleTeacher, ID: string(fx.Teacher(0))})
+	got, err := client.GetTest(owner, &rpc.GetTestRequest{TeacherID: string(fx.Teacher(0)), TestID: string(test.ID)})
+	if err != nil {
+		t.Fatalf("GetTest failed: %v", err)
+	}
+	decoded, questions := got.Domain()
+	if decoded.ID != test.ID || decoded.Title != "Quiz" || decoded.OpensAt == nil || !decoded.OpensAt.Equal(opensAt) {
+		t.Fatalf("unexpected test %+v", decoded)
+	}
+	if len(decoded.AssignedTo) != 1 || decoded.AssignedTo[0] != fx.Student(0) {
+		t.Fatalf("unexpected assignments %v", decoded.AssignedTo)
+	}
+	if len(questions) != 2 || len(questions[1].Choices) != 2 || questions[1].ExpectedResponse != "b" || questions[1].Points != 2 {
+		t.Fatalf("unexpected questions %+v", questions)
+	}
+
+	other := auth.WithPrincipal(context.Background(), auth.Principal{Role: domain.RoleTeacher, ID: string(fx.Teacher(1))})
+	_, err = client.GetTest(other, &rpc.GetTestRequest{TeacherID: string(fx.Teacher(1)), TestID: string(test.ID)})
+	if rpc.CodeOf(err) != rpc.PermissionDenied || rpc.ServiceError(err) != errs.ErrForbiddenTeacher {
+		t.Fatalf("expected the owner check to fail, got %v", err)
+	}
+	_, err = client.GetTest(other, &rpc.GetTestRequest{TeacherID: string(fx.Teacher(0)), TestID: string(test.ID)})
+	if rpc.CodeOf(err) != rpc.PermissionDenied {
+		t.Fatalf("expected impersonation to be refused, got %v", err)
+	}
+	_, err = client.GetTest(owner, &rpc.GetTestRequest{TeacherID: string(fx.Teacher(0)), TestID: "missing"})
+	if rpc.CodeOf(err) != rpc.NotFound || rpc.ServiceError(err) != errs.ErrTestNotFound {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	anonymous := rpc.NewAssessmentClient(rpc.NewClient(addr, rpc.ClientOptions{}))
+	_, err = anonymous.GetTest(context.Background(), &rpc.GetTestRequest{TeacherID: string(fx.Teacher(0)), TestID: string(test.ID)})
+	if rpc.CodeOf(err) != rpc.Unauthenticated {
+		t.Fatalf("expected a call without a token to be unauthenticated, got %v", err)
+	}
+}
+
+func TestServer_UnknownMethod(t *testing.T) {
+	addr := serve(t, rpc.NewServer())
+	client := rpc.NewGradingClient(rpc.NewClient(addr, rpc.ClientOptions{}))
+	_, err := client.AutogradeTest(context.Background(), &rpc.AutogradeTestRequest{TestID: "t"})
+	if rpc.CodeOf(err) != rpc.Unimplemented {
+		t.Fatalf("expected unimplemented, got %v", err)
+	}
+}
+
+func TestClient_Unavailable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	client := rpc.NewGradingClient(rpc.NewClient(addr, rpc.ClientOptions{Timeout: time.Second}))
+	_, err = client.AutogradeTest(context.Background(), &rpc.AutogradeTestRequest{TestID: "t"})
+	if rpc.CodeOf(err) != rpc.Unavailable {
+		t.Fatalf("expected unavailable, got %v", err)
+	}
+}
+
+func TestMessages_RoundTrip(t *testing.T) {
+	raw := int64(0)
+	in := &rpc.Result{
+		ResultID:  "r1",
+		AnswerID:  "a1",
+		Score:     -3,
+		RawScore:  &raw,
+		Feedback:  "100% right",
+		Completed: true,
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+	}
+	var out rpc.Result
+	if err := out.UnmarshalProto(in.MarshalProto()); err != nil {
+		t.Fatalf("UnmarshalProto failed: %v", err)
+	}
+	if out.Score != -3 || out.RawScore == nil || *out.RawScore != 0 || out.Feedback != in.Feedback || !out.Completed || !out.CreatedAt.Equal(in.CreatedAt) || !out.UpdatedAt.IsZero() {
+		t.Fatalf("unexpected round trip %+v", out)
+	}
+
+	// Fields a newer peer adds are skipped.
+	extended := append((&rpc.AutogradeSummary{Graded: 2}).MarshalProto(), 0x7a, 0x02, 'h', 'i', 0x80, 0x01, 0x05)
+	var summary rpc.AutogradeSummary
+	if err := summary.UnmarshalProto(extended); err != nil || summary.Graded != 2 {
+		t.Fatalf("expected unknown fields to be skipped, got %+v, %v", summary, err)
+	}
+	if err := summary.UnmarshalProto([]byte{0x0a, 0x05, 'x'}); err == nil {
+		t.Fatalf("expected a truncated message to fail")
+	}
+}
+
+func TestServiceError_RestoresInvariants(t *testing.T) {
+	invariant := &domain.InvariantError{Field: "score", Reason: "must not be negative", Err: errs.ErrInvalidAnswer}
+	err := rpc.ServiceError(rpc.Errorf(rpc.InvalidArgument, "%s", invariant.Error()))
+	var got *domain.InvariantError
+	if !errors.As(err, &got) || got.Error() != invariant.Error() || !errors.Is(err, errs.ErrInvalidAnswer) {
+		t.Fatalf("expected the invariant error back, got %v", err)
+	}
+	unknown := rpc.Errorf(rpc.Internal, "disk full")
+	if rpc.ServiceError(unknown) != unknown {
+		t.Fatalf("expected unknown statuses to pass through")
+	}
+}
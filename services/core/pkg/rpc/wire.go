@@ -0,0 +1,234 @@
+package rpc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Message is a protobuf message. Messages are hand-written against the
+// contracts under core/proto so that no code generator or third-party
+// runtime is needed.
+type Message interface {
+	MarshalProto() []byte
+	UnmarshalProto(data []byte) error
+}
+
+// Wire types of the protobuf encoding.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errTruncated = errors.New("rpc: truncated message")
+
+// encoder appends fields in the protobuf wire format. As in proto3, fields
+// holding their zero value are left out.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) tag(field, wireType int) {
+	e.buf = binary.AppendUvarint(e.buf, uint64(field)<<3|uint64(wireType))
+}
+
+func (e *encoder) bytes(field int, v []byte) {
+	e.tag(field, wireBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(v)))
+	e.buf = append(e.buf, v...)
+}
+
+func (e *encoder) string(field int, v string) {
+	if v == "" {
+		return
+	}
+	e.bytes(field, []byte(v))
+}
+
+// strings writes a repeated string field, keeping empty elements.
+func (e *encoder) strings(field int, vs []string) {
+	for _, v := range vs {
+		e.bytes(field, []byte(v))
+	}
+}
+
+func (e *encoder) int64(field int, v int64) {
+	if v == 0 {
+		return
+	}
+	e.tag(field, wireVarint)
+	e.buf = binary.AppendUvarint(e.buf, uint64(v))
+}
+
+// optionalInt64 writes a proto3 optional field, which is sent whenever it
+// is set, even to zero.
+func (e *encoder) optionalInt64(field int, v *int64) {
+	if v == nil {
+		return
+	}
+	e.tag(field, wireVarint)
+	e.buf = binary.AppendUvarint(e.buf, uint64(*v))
+}
+
+func (e *encoder) bool(field int, v bool) {
+	if !v {
+		return
+	}
+	e.tag(field, wireVarint)
+	e.buf = append(e.buf, 1)
+}
+
+func (e *encoder) message(field int, m Message) {
+	e.bytes(field, m.MarshalProto())
+}
+
+// time writes a google.protobuf.Timestamp. The zero time is left out.
+func (e *encoder) time(field int, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	var ts encoder
+	ts.int64(1, t.Unix())
+	ts.int64(2, int64(t.Nanosecond()))
+	e.bytes(field, ts.buf)
+}
+
+// decoder walks the fields of an encoded message. Call next until it
+// returns false, then check err. Fields the caller does not read are
+// skipped, so messages from newer peers decode cleanly.
+type decoder struct {
+	data []byte
+	err  error
+
+	field    int
+	wireType int
+	varint   uint64
+	value    []byte
+}
+
+func (d *decoder) next() bool {
+	if d.err != nil || len(d.data) == 0 {
+		return false
+	}
+	key, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.err = errTruncated
+		return false
+	}
+	d.data = d.data[n:]
+	d.field, d.wireType = int(key>>3), int(key&7)
+	if d.field == 0 {
+		d.err = errors.New("rpc: invalid field number 0")
+		return false
+	}
+
+	switch d.wireType {
+	case wireVarint:
+		v, n := binary.Uvarint(d.data)
+		if n <= 0 {
+			d.err = errTruncated
+			return false
+		}
+		d.varint, d.data = v, d.data[n:]
+	case wireBytes:
+		size, n := binary.Uvarint(d.data)
+		if n <= 0 || size > uint64(len(d.data)-n) {
+			d.err = errTruncated
+			return false
+		}
+		d.value, d.data = d.data[n:n+int(size)], d.data[n+int(size):]
+	case wireFixed64, wireFixed32:
+		width := 8
+		if d.wireType == wireFixed32 {
+			width = 4
+		}
+		if len(d.data) < width {
+			d.err = errTruncated
+			return false
+		}
+		d.data = d.data[width:]
+	default:
+		d.err = fmt.Errorf("rpc: unsupported wire type %d for field %d", d.wireType, d.field)
+		return false
+	}
+	return true
+}
+
+func (d *decoder) expect(wireType int) bool {
+	if d.wireType != wireType {
+		if d.err == nil {
+			d.err = fmt.Errorf("rpc: field %d has wire type %d, want %d", d.field, d.wireType, wireType)
+		}
+		return false
+	}
+	return true
+}
+
+func (d *decoder) string() string {
+	if !d.expect(wireBytes) {
+		return ""
+	}
+	return string(d.value)
+}
+
+func (d *decoder) int64() int64 {
+	if !d.expect(wireVarint) {
+		return 0
+	}
+	return int64(d.varint)
+}
+
+func (d *decoder) bool() bool {
+	if !d.expect(wireVarint) {
+		return false
+	}
+	return d.varint != 0
+}
+
+func (d *decoder) message(m Message) {
+	if !d.expect(wireBytes) {
+		return
+	}
+	if err := m.UnmarshalProto(d.value); err != nil {
+		d.err = err
+	}
+}
+
+func (d *decoder) time() time.Time {
+	if !d.expect(wireBytes) {
+		return time.Time{}
+	}
+	var seconds, nanos int64
+	ts := decoder{data: d.value}
+	for ts.next() {
+		switch ts.field {
+		case 1:
+			seconds = ts.int64()
+		case 2:
+			nanos = ts.int64()
+		}
+	}
+	if ts.err != nil {
+		d.err = ts.err
+	}
+	return time.Unix(seconds, nanos).UTC()
+}
+
+// timePtr converts between optional timestamps and the zero time used on
+// the wire for "not set".
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func timeValue(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
@@ -0,0 +1,163 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+// MemoryIndex is an inverted index held in the process. It is rebuilt from
+// storage after a restart, and every process keeps its own.
+type MemoryIndex struct {
+	mu       sync.RWMutex
+	docs     map[string]Document
+	postings map[string]map[string]int
+	byTest   map[domain.TestID]map[string]struct{}
+}
+
+var _ Index = (*MemoryIndex)(nil)
+
+// NewMemoryIndex creates an empty index.
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{
+		docs:     make(map[string]Document),
+		postings: make(map[string]map[string]int),
+		byTest:   make(map[domain.TestID]map[string]struct{}),
+	}
+}
+
+// Put adds the documents, replacing those with the same IDs.
+func (x *MemoryIndex) Put(docs ...Document) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for _, doc := range docs {
+		x.remove(doc.ID)
+		x.docs[doc.ID] = doc
+		for term, n := range termCounts(doc.Text) {
+			if x.postings[term] == nil {
+				x.postings[term] = make(map[string]int)
+			}
+			x.postings[term][doc.ID] = n
+		}
+		if x.byTest[doc.TestID] == nil {
+			x.byTest[doc.TestID] = make(map[string]struct{})
+		}
+		x.byTest[doc.TestID][doc.ID] = struct{}{}
+	}
+	return nil
+}
+
+// Delete removes the documents with the IDs. Unknown IDs are ignored.
+func (x *MemoryIndex) Delete(ids ...string) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for _, id := range ids {
+		x.remove(id)
+	}
+	return nil
+}
+
+// DeleteTest removes every document of the test.
+func (x *MemoryIndex) DeleteTest(testID domain.TestID) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for id := range x.byTest[testID] {
+		x.remove(id)
+	}
+	return nil
+}
+
+// Search ranks the documents containing every term of the query by the
+// frequency of the terms, weighting rare terms higher.
+func (x *MemoryIndex) Search(q Query) ([]Hit, error) {
+	terms := Tokenize(q.Text)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	tests := make(map[domain.TestID]bool, len(q.TestIDs))
+	for _, id := range q.TestIDs {
+		tests[id] = true
+	}
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	var hits []Hit
+	total := float64(len(x.docs))
+	for id, n := range x.postings[terms[0]] {
+		doc := x.docs[id]
+		if !tests[doc.TestID] || (q.Kind != "" && doc.Kind != q.Kind) {
+			continue
+		}
+		score := float64(n) * x.idf(terms[0], total)
+		matched := true
+		for _, term := range terms[1:] {
+			n, ok := x.postings[term][id]
+			if !ok {
+				matched = false
+				break
+			}
+			score += float64(n) * x.idf(term, total)
+		}
+		if matched {
+			hits = append(hits, Hit{Document: doc, Score: score})
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if !hits[i].UpdatedAt.Equal(hits[j].UpdatedAt) {
+			return hits[i].UpdatedAt.After(hits[j].UpdatedAt)
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	if q.Limit > 0 && len(hits) > q.Limit {
+		hits = hits[:q.Limit]
+	}
+	return hits, nil
+}
+
+func (x *MemoryIndex) idf(term string, total float64) float64 {
+	return math.Log(1 + total/float64(len(x.postings[term])))
+}
+
+// remove drops a document and its postings. The caller holds mu.
+func (x *MemoryIndex) remove(id string) {
+	doc, ok := x.docs[id]
+	if !ok {
+		return
+	}
+	for term := range termCounts(doc.Text) {
+		delete(x.postings[term], id)
+		if len(x.postings[term]) == 0 {
+			delete(x.postings, term)
+		}
+	}
+	delete(x.byTest[doc.TestID], id)
+	if len(x.byTest[doc.TestID]) == 0 {
+		delete(x.byTest, doc.TestID)
+	}
+	delete(x.docs, id)
+}
+
+// Tokenize splits text into lower-case terms at every character that is
+// neither a letter nor a digit.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func termCounts(text string) map[string]int {
+	counts := make(map[string]int)
+	for _, term := range Tokenize(text) {
+		counts[term]++
+	}
+	return counts
+}
@@ -0,0 +1,297 @@
+// Package search lets teachers find their tests' questions, the feedback
+// they gave and their students' answers by keyword. Documents are kept in an
+// Index: MemoryIndex serves a single process today, and engines such as
+// Bleve or Elasticsearch can implement Index without changing callers.
+package search
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+	"github.com/sky0621/go_work_sample/core/pkg/tracing"
+)
+
+// Kind is what a document holds.
+type Kind string
+
+const (
+	KindQuestion Kind = "question"
+	KindAnswer   Kind = "answer"
+	KindFeedback Kind = "feedback"
+)
+
+// Search limits.
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// Document is one searchable text. Answers and feedback name the question
+// and student they belong to; questions leave StudentID empty.
+type Document struct {
+	ID         string
+	Kind       Kind
+	TeacherID  domain.TeacherID
+	TestID     domain.TestID
+	TestTitle  string
+	QuestionID domain.QuestionID
+	StudentID  domain.StudentID
+	Text       string
+	UpdatedAt  time.Time
+}
+
+// Hit is a document matching a query with its relevance.
+type Hit struct {
+	Document
+	Score float64
+}
+
+// Query selects documents containing every word of Text among the tests
+// listed in TestIDs, optionally of one Kind. A zero Limit returns every hit.
+type Query struct {
+	Text    string
+	TestIDs []domain.TestID
+	Kind    Kind
+	Limit   int
+}
+
+// Index stores documents and answers queries, best match first.
+type Index interface {
+	Put(docs ...Document) error
+	Delete(ids ...string) error
+	DeleteTest(testID domain.TestID) error
+	Search(q Query) ([]Hit, error)
+}
+
+// Source is the storage the documents of a test are built from.
+type Source interface {
+	GetTeacher(ctx context.Context, id domain.TeacherID) (*domain.Teacher, error)
+	ListTestsByTeacher(ctx context.Context, teacherID domain.TeacherID, page repository.PageRequest) (repository.Page[domain.Test], error)
+	ListQuestions(ctx context.Context, testID domain.TestID) ([]domain.Question, error)
+	SnapshotGrading(ctx context.Context, testID domain.TestID) (repository.GradingSnapshot, error)
+}
+
+// Service indexes tests from the source the first time they are searched
+// and keeps them current with the domain events applied to it. Writes made
+// by other processes, such as answers submitted through the student
+// service, produce no events here, so a test indexed longer than maxAge ago
+// is indexed again before the next search; a zero maxAge keeps it until the
+// test changes.
+type Service struct {
+	index  Index
+	source Source
+	maxAge time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	indexed map[domain.TestID]indexedTest
+}
+
+type indexedTest struct {
+	test domain.Test
+	at   time.Time
+}
+
+// NewService creates a service keeping documents in index.
+func NewService(index Index, source Source, maxAge time.Duration) *Service {
+	return &Service{
+		index:   index,
+		source:  source,
+		maxAge:  maxAge,
+		now:     time.Now,
+		indexed: make(map[domain.TestID]indexedTest),
+	}
+}
+
+// Search returns the teacher's documents containing every word of text,
+// best match first. An empty kind searches every kind; limit is clamped to
+// MaxLimit and defaults to DefaultLimit.
+func (s *Service) Search(ctx context.Context, teacherID domain.TeacherID, text string, kind Kind, limit int) ([]Hit, error) {
+	_, span := tracing.Start(ctx, "search.Service.Search")
+	defer span.End()
+
+	if len(Tokenize(text)) == 0 {
+		return nil, errs.ErrInvalidSearch
+	}
+	teacher, err := s.source.GetTeacher(ctx, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	if teacher == nil {
+		return nil, errs.ErrTeacherNotFound
+	}
+	tests, err := repository.Collect(s.source.ListTestsByTeacher(ctx, teacherID, repository.All))
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]domain.TestID, len(tests))
+	for i, test := range tests {
+		ids[i] = test.ID
+		if err := s.ensureIndexed(ctx, test); err != nil {
+			return nil, err
+		}
+	}
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+	return s.index.Search(Query{Text: text, TestIDs: ids, Kind: kind, Limit: limit})
+}
+
+// Apply updates the documents of the event's test. Events of tests that are
+// not indexed yet are dropped: they will be indexed from storage, which
+// already holds the change.
+func (s *Service) Apply(event domain.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	testID := event.Aggregate()
+	var err error
+	switch e := event.(type) {
+	case domain.TestCreated:
+		err = s.indexTest(e.Test, e.Questions, repository.GradingSnapshot{})
+	case domain.TestChanged:
+		delete(s.indexed, testID)
+	case domain.AnswerSaved:
+		if t, ok := s.indexed[testID]; ok {
+			err = s.index.Put(answerDocument(t.test, e.Answer))
+		}
+	case domain.AnswerDeleted:
+		if _, ok := s.indexed[testID]; ok {
+			key := documentKey(e.TestID, e.QuestionID, e.StudentID)
+			err = s.index.Delete(string(KindAnswer)+":"+key, string(KindFeedback)+":"+key)
+		}
+	case domain.ResultSaved:
+		if t, ok := s.indexed[testID]; ok {
+			err = s.index.Put(feedbackDocument(t.test, e.QuestionID, e.StudentID, e.Result))
+		}
+	}
+	if err != nil {
+		log.Printf("search: index test %s: %v", testID, err)
+		delete(s.indexed, testID)
+	}
+}
+
+// Reset drops the documents of every indexed test, so each is indexed
+// from storage again before its next search.
+func (s *Service) Reset() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for testID := range s.indexed {
+		if err := s.index.DeleteTest(testID); err != nil {
+			return err
+		}
+		delete(s.indexed, testID)
+	}
+	return nil
+}
+
+// ensureIndexed indexes the test from the source unless it was indexed
+// within maxAge.
+func (s *Service) ensureIndexed(ctx context.Context, test domain.Test) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t, ok := s.indexed[test.ID]; ok && (s.maxAge <= 0 || s.now().Sub(t.at) < s.maxAge) {
+		return nil
+	}
+	questions, err := s.source.ListQuestions(ctx, test.ID)
+	if err != nil {
+		return err
+	}
+	snapshot, err := s.source.SnapshotGrading(ctx, test.ID)
+	if err != nil {
+		return err
+	}
+	return s.indexTest(test, questions, snapshot)
+}
+
+// indexTest replaces the documents of a test. The caller holds mu.
+func (s *Service) indexTest(test domain.Test, questions []domain.Question, snapshot repository.GradingSnapshot) error {
+	if err := s.index.DeleteTest(test.ID); err != nil {
+		return err
+	}
+	docs := make([]Document, 0, len(questions)+2*len(snapshot.Answers))
+	for _, q := range questions {
+		docs = append(docs, questionDocument(test, q))
+	}
+	answers := make(map[domain.AnswerID]domain.Answer, len(snapshot.Answers))
+	for _, a := range snapshot.Answers {
+		answers[a.ID] = a
+		docs = append(docs, answerDocument(test, a))
+	}
+	for _, res := range snapshot.Results {
+		if a, ok := answers[res.AnswerID]; ok {
+			docs = append(docs, feedbackDocument(test, a.QuestionID, a.StudentID, res))
+		}
+	}
+	if err := s.index.Put(docs...); err != nil {
+		return err
+	}
+	s.indexed[test.ID] = indexedTest{test: test, at: s.now()}
+	return nil
+}
+
+func questionDocument(test domain.Test, q domain.Question) Document {
+	text := []string{q.Prompt}
+	for _, c := range q.Choices {
+		text = append(text, c.Label)
+	}
+	return Document{
+		ID:         string(KindQuestion) + ":" + string(q.ID),
+		Kind:       KindQuestion,
+		TeacherID:  test.TeacherID,
+		TestID:     test.ID,
+		TestTitle:  test.Title,
+		QuestionID: q.ID,
+		Text:       strings.Join(text, "\n"),
+		UpdatedAt:  q.CreatedAt,
+	}
+}
+
+func answerDocument(test domain.Test, a domain.Answer) Document {
+	text := a.Response
+	if a.Note != "" {
+		text += "\n" + a.Note
+	}
+	return Document{
+		ID:         string(KindAnswer) + ":" + documentKey(a.TestID, a.QuestionID, a.StudentID),
+		Kind:       KindAnswer,
+		TeacherID:  test.TeacherID,
+		TestID:     test.ID,
+		TestTitle:  test.Title,
+		QuestionID: a.QuestionID,
+		StudentID:  a.StudentID,
+		Text:       text,
+		UpdatedAt:  a.UpdatedAt,
+	}
+}
+
+func feedbackDocument(test domain.Test, questionID domain.QuestionID, studentID domain.StudentID, res domain.Result) Document {
+	return Document{
+		ID:         string(KindFeedback) + ":" + documentKey(test.ID, questionID, studentID),
+		Kind:       KindFeedback,
+		TeacherID:  test.TeacherID,
+		TestID:     test.ID,
+		TestTitle:  test.Title,
+		QuestionID: questionID,
+		StudentID:  studentID,
+		Text:       res.Feedback,
+		UpdatedAt:  res.UpdatedAt,
+	}
+}
+
+// documentKey identifies the answer of a student to a question, which
+// stays the same when the answer is deleted and given again.
+func documentKey(testID domain.TestID, questionID domain.QuestionID, studentID domain.StudentID) string {
+	return string(testID) + "|" + string(questionID) + "|" + string(studentID)
+}
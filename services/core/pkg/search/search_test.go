@@ -0,0 +1,68 @@
+package search_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/search"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestServiceSearch(t *testing.T) {
+	fx := fixtures.NewSchool().WithTeachers(2).Build()
+	assessments := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	service := search.NewService(search.NewMemoryIndex(), fx.Repo, 0)
+	assessments.SetSearch(service)
+	ctx := context.Background()
+
+	create := func(teacher int, prompt string) (*domain.Test, []domain.Question) {
+		t.Helper()
+		test, questions, err := assessments.CreateTest(ctx, usecase.CreateTestInput{
+			Title:      "Biology",
+			TeacherID:  fx.Teacher(teacher),
+			Questions:  []usecase.QuestionDraft{{Prompt: prompt, Points: 10}},
+			StudentIDs: []domain.StudentID{fx.Student(0)},
+		})
+		if err != nil {
+			t.Fatalf("CreateTest failed: %v", err)
+		}
+		return test, questions
+	}
+	test, questions := create(0, "How do plants use light?")
+	create(1, "Which pigment makes plants green?")
+
+	if hits, err := service.Search(ctx, fx.Teacher(0), "plants", "", 0); err != nil || len(hits) != 1 || hits[0].Kind != search.KindQuestion || hits[0].TestID != test.ID {
+		t.Fatalf("expected only the teacher's own question, got %+v, %v", hits, err)
+	}
+
+	if _, err := assessments.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: fx.Student(0), Response: "Chlorophyll absorbs the light"}); err != nil {
+		t.Fatalf("SubmitAnswer failed: %v", err)
+	}
+	if _, err := assessments.GradeAnswer(ctx, usecase.GradeInput{TeacherID: fx.Teacher(0), TestID: test.ID, QuestionID: questions[0].ID, StudentID: fx.Student(0), Score: 8, Feedback: "Good, now explain what chlorophyll makes"}); err != nil {
+		t.Fatalf("GradeAnswer failed: %v", err)
+	}
+
+	hits, err := service.Search(ctx, fx.Teacher(0), "CHLOROPHYLL", "", 0)
+	if err != nil || len(hits) != 2 {
+		t.Fatalf("expected the answer and the feedback, got %+v, %v", hits, err)
+	}
+	if hits, _ := service.Search(ctx, fx.Teacher(0), "chlorophyll light", search.KindAnswer, 0); len(hits) != 1 || hits[0].StudentID != fx.Student(0) {
+		t.Fatalf("expected the answer alone, got %+v", hits)
+	}
+
+	// A new service indexes what is already stored.
+	fresh := search.NewService(search.NewMemoryIndex(), fx.Repo, 0)
+	if hits, err := fresh.Search(ctx, fx.Teacher(0), "explain", search.KindFeedback, 0); err != nil || len(hits) != 1 {
+		t.Fatalf("expected the stored feedback, got %+v, %v", hits, err)
+	}
+
+	if _, err := service.Search(ctx, fx.Teacher(0), " ?! ", "", 0); err != errs.ErrInvalidSearch {
+		t.Fatalf("expected ErrInvalidSearch, got %v", err)
+	}
+	if _, err := service.Search(ctx, "missing", "plants", "", 0); err != errs.ErrTeacherNotFound {
+		t.Fatalf("expected ErrTeacherNotFound, got %v", err)
+	}
+}
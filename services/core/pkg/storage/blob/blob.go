@@ -0,0 +1,132 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+)
+
+// Store keeps opaque binary objects addressed by slash-separated keys.
+type Store interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// FileStore stores objects as files below a root directory.
+type FileStore struct {
+	root string
+}
+
+// NewFileStore creates a store rooted at dir, creating the directory if
+// needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileStore{root: dir}, nil
+}
+
+// Put writes the object atomically so readers never observe partial content.
+func (s *FileStore) Put(_ context.Context, key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".blob-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Open returns a reader for the object stored under key.
+func (s *FileStore) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errs.ErrBlobNotFound
+	}
+	return f, err
+}
+
+// Delete removes the object; deleting a missing object is not an error.
+func (s *FileStore) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path maps key below the root, rejecting keys that would escape it.
+func (s *FileStore) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return "", fmt.Errorf("blob: invalid key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return "", fmt.Errorf("blob: invalid key %q", key)
+		}
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// Prefixed keeps its objects in another store under a key prefix, giving
+// them a namespace of their own. Keys are checked by the underlying store
+// once prefixed, so they cannot escape the namespace.
+type Prefixed struct {
+	store  Store
+	prefix string
+}
+
+// NewPrefixed creates a store keeping its objects in store under prefix,
+// which is a slash-separated key without a trailing slash.
+func NewPrefixed(store Store, prefix string) *Prefixed {
+	return &Prefixed{store: store, prefix: prefix + "/"}
+}
+
+// Put writes the object to the underlying store.
+func (s *Prefixed) Put(ctx context.Context, key string, r io.Reader) error {
+	return s.store.Put(ctx, s.prefix+key, r)
+}
+
+// Open returns a reader for the object stored under key.
+func (s *Prefixed) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	return s.store.Open(ctx, s.prefix+key)
+}
+
+// Delete removes the object from the underlying store.
+func (s *Prefixed) Delete(ctx context.Context, key string) error {
+	return s.store.Delete(ctx, s.prefix+key)
+}
+
+var (
+	_ Store = (*FileStore)(nil)
+	_ Store = (*Prefixed)(nil)
+)
@@ -0,0 +1,81 @@
+package blob_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/storage/blob"
+)
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store, err := blob.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+
+	if err := store.Put(ctx, "exports/teacher-001/out.parquet", strings.NewReader("payload")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	rc, err := store.Open(ctx, "exports/teacher-001/out.parquet")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	data, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(data) != "payload" {
+		t.Fatalf("unexpected content %q", data)
+	}
+
+	if err := store.Delete(ctx, "exports/teacher-001/out.parquet"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.Open(ctx, "exports/teacher-001/out.parquet"); !errors.Is(err, errs.ErrBlobNotFound) {
+		t.Fatalf("expected ErrBlobNotFound, got %v", err)
+	}
+}
+
+func TestFileStoreRejectsEscapingKeys(t *testing.T) {
+	store, err := blob.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+	for _, key := range []string{"", "/etc/passwd", "../outside", "a//b", "a/./b"} {
+		if err := store.Put(context.Background(), key, strings.NewReader("x")); err == nil {
+			t.Fatalf("expected key %q to be rejected", key)
+		}
+	}
+}
+
+func TestPrefixedKeepsObjectsApart(t *testing.T) {
+	ctx := context.Background()
+	store, err := blob.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+	sandbox := blob.NewPrefixed(store, "sandbox")
+
+	if err := sandbox.Put(ctx, "exports/teacher-001/out.csv", strings.NewReader("sandbox")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if _, err := store.Open(ctx, "exports/teacher-001/out.csv"); !errors.Is(err, errs.ErrBlobNotFound) {
+		t.Fatalf("expected the live key to stay empty, got %v", err)
+	}
+	rc, err := store.Open(ctx, "sandbox/exports/teacher-001/out.csv")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	data, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(data) != "sandbox" {
+		t.Fatalf("unexpected content %q", data)
+	}
+
+	if err := sandbox.Put(ctx, "../escape", strings.NewReader("x")); err == nil {
+		t.Fatal("expected a key escaping the prefix to be rejected")
+	}
+}
@@ -0,0 +1,20 @@
+package filedb_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/memory"
+	"github.com/sky0621/go_work_sample/core/pkg/repository/repotest"
+	"github.com/sky0621/go_work_sample/core/pkg/storage/filedb"
+)
+
+func TestConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T, seed memory.SeedData) repotest.Store {
+		repo, err := filedb.NewRepository(filepath.Join(t.TempDir(), "state.json"), seed)
+		if err != nil {
+			t.Fatalf("NewRepository failed: %v", err)
+		}
+		return repo
+	})
+}
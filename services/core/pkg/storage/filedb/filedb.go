@@ -1,11 +1,15 @@
 package filedb
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/sky0621/go_work_sample/core/pkg/domain"
 	"github.com/sky0621/go_work_sample/core/pkg/memory"
@@ -16,186 +20,975 @@ import (
 type Repository struct {
 	mu       sync.Mutex
 	path     string
-	delegate *memory.Repository
+	delegate atomic.Pointer[memory.Repository]
+
+	staged *Candidate
+	// writes counts the writes persisted since the store was opened, so
+	// Promote can tell whether live data changed after staging.
+	writes uint64
+	// segments is set when answers and results are loaded per test.
+	segments *segments
+	// journal is set when answer and result writes are journaled.
+	journal *journal
 }
 
 // Ensure interface compliance.
 var (
-	_ repository.OrganizationRepository = (*Repository)(nil)
-	_ repository.TestRepository         = (*Repository)(nil)
-	_ repository.AnswerRepository       = (*Repository)(nil)
-	_ repository.ResultRepository       = (*Repository)(nil)
+	_ repository.OrganizationRepository    = (*Repository)(nil)
+	_ repository.TestRepository            = (*Repository)(nil)
+	_ repository.AnswerRepository          = (*Repository)(nil)
+	_ repository.ResultRepository          = (*Repository)(nil)
+	_ repository.NotificationRepository    = (*Repository)(nil)
+	_ repository.DigestRepository          = (*Repository)(nil)
+	_ repository.QuestionCommentRepository = (*Repository)(nil)
+	_ repository.AnswerCommentRepository   = (*Repository)(nil)
+	_ repository.TestSessionRepository     = (*Repository)(nil)
+	_ repository.SubmissionRepository      = (*Repository)(nil)
+	_ repository.RubricRepository          = (*Repository)(nil)
+	_ repository.DelegationRepository      = (*Repository)(nil)
+	_ repository.DeviceSessionRepository   = (*Repository)(nil)
+	_ repository.QuestionBankRepository    = (*Repository)(nil)
+	_ repository.DistrictRepository        = (*Repository)(nil)
+	_ repository.AuditRepository           = (*Repository)(nil)
 )
 
-// NewRepository loads state from the provided path or seeds a new one.
+// Sandbox returns an in-memory copy of the live data. Writes to the copy are
+// never persisted and never reach the live repository.
+func (r *Repository) Sandbox() (*memory.Repository, error) {
+	state, err := r.ExportState()
+	if err != nil {
+		return nil, err
+	}
+	return memory.NewRepositoryFromState(state), nil
+}
+
+// ExportState returns a snapshot of the live data. In lazy mode the answers
+// and results are read from their files; the error of a file that cannot be
+// read is returned with the rest of the data.
+func (r *Repository) ExportState() (memory.State, error) {
+	if r.segments == nil {
+		return r.current().ExportState(), nil
+	}
+	state := r.current().ExportStateWithoutAnswers()
+	answers, results, err := readAllSegments(r.segments.dir)
+	state.Answers = append(state.Answers, answers...)
+	state.Results = append(state.Results, results...)
+	return state, err
+}
+
+// NewRepository loads state from the provided path or seeds a new one,
+// keeping all of it in memory.
 func NewRepository(path string, seed memory.SeedData) (*Repository, error) {
+	return Open(path, seed, Options{})
+}
+
+// Open loads state from the provided path or seeds a new one. A store
+// written in lazy mode and opened eagerly, or the other way around, is
+// converted on open. A journal left by an earlier run is replayed and
+// compacted into the state file.
+func Open(path string, seed memory.SeedData, opts Options) (*Repository, error) {
 	if path == "" {
 		return nil, errors.New("filedb: path must be provided")
 	}
+	if opts.Lazy && opts.Journal {
+		return nil, errors.New("filedb: journal mode cannot be combined with lazy mode")
+	}
 
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 		return nil, err
 	}
 
-	var delegate *memory.Repository
-	if _, err := os.Stat(path); err == nil {
-		state, loadErr := loadState(path)
-		if loadErr != nil {
-			return nil, loadErr
+	var state memory.State
+	_, statErr := os.Stat(path)
+	exists := statErr == nil
+	if exists {
+		loaded, err := loadState(path)
+		if err != nil {
+			return nil, err
 		}
-		delegate = memory.NewRepositoryFromState(state)
+		state = loaded
 	} else {
-		delegate = memory.NewRepository(seed)
+		state = memory.NewRepository(seed).ExportState()
+	}
+	state, replayed, err := replayJournal(path, state)
+	if err != nil {
+		return nil, err
 	}
 
-	repo := &Repository{path: path, delegate: delegate}
+	repo := &Repository{path: path}
+	dir := segmentDir(path)
+	_, dirErr := os.Stat(dir)
+	hasSegments := dirErr == nil
 
-	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+	if !opts.Lazy {
+		if hasSegments {
+			answers, results, err := readAllSegments(dir)
+			if err != nil {
+				return nil, err
+			}
+			state.Answers = append(state.Answers, answers...)
+			state.Results = append(state.Results, results...)
+		}
+		repo.delegate.Store(memory.NewRepositoryFromState(state))
+		if !exists || hasSegments || replayed {
+			if err := repo.persist(); err != nil {
+				return nil, err
+			}
+		}
+		if replayed {
+			if err := os.Remove(journalPath(path)); err != nil {
+				return nil, err
+			}
+		}
+		if opts.Journal {
+			repo.journal = newJournal(path, opts.CompactAfter)
+		}
+		if hasSegments {
+			if err := os.RemoveAll(dir); err != nil {
+				return nil, err
+			}
+		}
+		return repo, nil
+	}
+
+	// Answers still in the state file were written eagerly; they replace
+	// whatever a conversion interrupted earlier left behind.
+	migrate := len(state.Answers) > 0 || len(state.Results) > 0
+	if migrate {
+		if err := writeSegments(dir, state.Answers, state.Results); err != nil {
+			return nil, err
+		}
+		state.Answers, state.Results = []domain.Answer{}, []domain.Result{}
+	}
+	segs, err := openSegments(dir, opts.MaxLoadedTests)
+	if err != nil {
+		return nil, err
+	}
+	repo.segments = segs
+	repo.delegate.Store(memory.NewRepositoryFromState(state))
+	if !exists || migrate {
 		if err := repo.persist(); err != nil {
 			return nil, err
 		}
 	}
-
+	if replayed {
+		if err := os.Remove(journalPath(path)); err != nil {
+			return nil, err
+		}
+	}
 	return repo, nil
 }
 
 // OrganizationRepository delegation.
 
-func (r *Repository) ListSchools() ([]domain.School, error) {
-	return r.delegate.ListSchools()
+func (r *Repository) ListSchools(ctx context.Context, page repository.PageRequest) (repository.Page[domain.School], error) {
+	return r.current().ListSchools(ctx, page)
+}
+
+func (r *Repository) GetSchool(ctx context.Context, id domain.SchoolID) (*domain.School, error) {
+	return r.current().GetSchool(ctx, id)
+}
+
+func (r *Repository) GetGrade(ctx context.Context, id domain.GradeID) (*domain.Grade, error) {
+	return r.current().GetGrade(ctx, id)
+}
+
+func (r *Repository) GetClass(ctx context.Context, id domain.ClassID) (*domain.Class, error) {
+	return r.current().GetClass(ctx, id)
 }
 
-func (r *Repository) GetSchool(id domain.SchoolID) (*domain.School, error) {
-	return r.delegate.GetSchool(id)
+func (r *Repository) GetTeacher(ctx context.Context, id domain.TeacherID) (*domain.Teacher, error) {
+	return r.current().GetTeacher(ctx, id)
 }
 
-func (r *Repository) GetGrade(id domain.GradeID) (*domain.Grade, error) {
-	return r.delegate.GetGrade(id)
+func (r *Repository) GetStudent(ctx context.Context, id domain.StudentID) (*domain.Student, error) {
+	return r.current().GetStudent(ctx, id)
 }
 
-func (r *Repository) GetClass(id domain.ClassID) (*domain.Class, error) {
-	return r.delegate.GetClass(id)
+func (r *Repository) FindStudentsByEmail(ctx context.Context, email string) ([]domain.Student, error) {
+	return r.current().FindStudentsByEmail(ctx, email)
 }
 
-func (r *Repository) GetTeacher(id domain.TeacherID) (*domain.Teacher, error) {
-	return r.delegate.GetTeacher(id)
+func (r *Repository) ListGrades(ctx context.Context, schoolID domain.SchoolID, page repository.PageRequest) (repository.Page[domain.Grade], error) {
+	return r.current().ListGrades(ctx, schoolID, page)
 }
 
-func (r *Repository) GetStudent(id domain.StudentID) (*domain.Student, error) {
-	return r.delegate.GetStudent(id)
+func (r *Repository) ListClasses(ctx context.Context, gradeID domain.GradeID, page repository.PageRequest) (repository.Page[domain.Class], error) {
+	return r.current().ListClasses(ctx, gradeID, page)
 }
 
-func (r *Repository) ListGrades(schoolID domain.SchoolID) ([]domain.Grade, error) {
-	return r.delegate.ListGrades(schoolID)
+func (r *Repository) ListStudents(ctx context.Context, classID domain.ClassID, page repository.PageRequest) (repository.Page[domain.Student], error) {
+	return r.current().ListStudents(ctx, classID, page)
 }
 
-func (r *Repository) ListClasses(gradeID domain.GradeID) ([]domain.Class, error) {
-	return r.delegate.ListClasses(gradeID)
+func (r *Repository) ListTeachers(ctx context.Context, schoolID domain.SchoolID, page repository.PageRequest) (repository.Page[domain.Teacher], error) {
+	return r.current().ListTeachers(ctx, schoolID, page)
+}
+
+func (r *Repository) UpdateSchool(ctx context.Context, school *domain.School) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
+	defer r.mu.Unlock()
+
+	if err := r.current().UpdateSchool(ctx, school); err != nil {
+		return err
+	}
+	return r.persist()
+}
+
+func (r *Repository) UpdateTeacher(ctx context.Context, teacher *domain.Teacher) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
+	defer r.mu.Unlock()
+
+	if err := r.current().UpdateTeacher(ctx, teacher); err != nil {
+		return err
+	}
+	return r.persist()
 }
 
-func (r *Repository) ListStudents(classID domain.ClassID) ([]domain.Student, error) {
-	return r.delegate.ListStudents(classID)
+func (r *Repository) UpdateStudent(ctx context.Context, student *domain.Student) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
+	defer r.mu.Unlock()
+
+	if err := r.current().UpdateStudent(ctx, student); err != nil {
+		return err
+	}
+	return r.persist()
 }
 
-func (r *Repository) ListTeachers(schoolID domain.SchoolID) ([]domain.Teacher, error) {
-	return r.delegate.ListTeachers(schoolID)
+func (r *Repository) CreateStudents(ctx context.Context, classID domain.ClassID, students []domain.Student) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
+	defer r.mu.Unlock()
+
+	if err := r.current().CreateStudents(ctx, classID, students); err != nil {
+		return err
+	}
+	return r.persist()
 }
 
 // TestRepository delegation with persistence on mutations.
 
-func (r *Repository) CreateTest(test *domain.Test, questions []domain.Question, studentIDs []domain.StudentID) error {
-	r.mu.Lock()
+func (r *Repository) CreateTest(ctx context.Context, test *domain.Test, questions []domain.Question, studentIDs []domain.StudentID) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
 	defer r.mu.Unlock()
 
-	if err := r.delegate.CreateTest(test, questions, studentIDs); err != nil {
+	if err := r.current().CreateTest(ctx, test, questions, studentIDs); err != nil {
 		return err
 	}
 	return r.persist()
 }
 
-func (r *Repository) UpdateTest(test *domain.Test) error {
-	r.mu.Lock()
+func (r *Repository) UpdateTest(ctx context.Context, test *domain.Test) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
 	defer r.mu.Unlock()
 
-	if err := r.delegate.UpdateTest(test); err != nil {
+	if err := r.current().UpdateTest(ctx, test); err != nil {
 		return err
 	}
 	return r.persist()
 }
 
-func (r *Repository) GetTest(id domain.TestID) (*domain.Test, error) {
-	return r.delegate.GetTest(id)
+func (r *Repository) UpdateAssignments(ctx context.Context, testID domain.TestID, add, remove []domain.StudentID) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
+	defer r.mu.Unlock()
+
+	if err := r.current().UpdateAssignments(ctx, testID, add, remove); err != nil {
+		return err
+	}
+	return r.persist()
 }
 
-func (r *Repository) ListTestsByTeacher(teacherID domain.TeacherID) ([]domain.Test, error) {
-	return r.delegate.ListTestsByTeacher(teacherID)
+func (r *Repository) GetTest(ctx context.Context, id domain.TestID) (*domain.Test, error) {
+	return r.current().GetTest(ctx, id)
 }
 
-func (r *Repository) ListTestsForStudent(studentID domain.StudentID) ([]domain.Test, error) {
-	return r.delegate.ListTestsForStudent(studentID)
+func (r *Repository) ListTestsByTeacher(ctx context.Context, teacherID domain.TeacherID, page repository.PageRequest) (repository.Page[domain.Test], error) {
+	return r.current().ListTestsByTeacher(ctx, teacherID, page)
 }
 
-func (r *Repository) ListQuestions(testID domain.TestID) ([]domain.Question, error) {
-	return r.delegate.ListQuestions(testID)
+func (r *Repository) ListTestsForStudent(ctx context.Context, studentID domain.StudentID, page repository.PageRequest) (repository.Page[domain.Test], error) {
+	return r.current().ListTestsForStudent(ctx, studentID, page)
 }
 
-func (r *Repository) IsStudentAssigned(testID domain.TestID, studentID domain.StudentID) (bool, error) {
-	return r.delegate.IsStudentAssigned(testID, studentID)
+func (r *Repository) ListTestsInWindow(ctx context.Context, from, to time.Time) ([]domain.Test, error) {
+	return r.current().ListTestsInWindow(ctx, from, to)
 }
 
-// AnswerRepository delegation with persistence.
+func (r *Repository) ListQuestions(ctx context.Context, testID domain.TestID) ([]domain.Question, error) {
+	return r.current().ListQuestions(ctx, testID)
+}
 
-func (r *Repository) UpsertAnswer(answer *domain.Answer) error {
-	r.mu.Lock()
+func (r *Repository) UpdateQuestion(ctx context.Context, question *domain.Question) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
+	defer r.mu.Unlock()
+
+	if err := r.current().UpdateQuestion(ctx, question); err != nil {
+		return err
+	}
+	return r.persist()
+}
+
+func (r *Repository) ReorderQuestions(ctx context.Context, testID domain.TestID, sequences map[domain.QuestionID]int) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
 	defer r.mu.Unlock()
 
-	if err := r.delegate.UpsertAnswer(answer); err != nil {
+	if err := r.current().ReorderQuestions(ctx, testID, sequences); err != nil {
 		return err
 	}
 	return r.persist()
 }
 
-func (r *Repository) GetAnswer(testID domain.TestID, questionID domain.QuestionID, studentID domain.StudentID) (*domain.Answer, error) {
-	return r.delegate.GetAnswer(testID, questionID, studentID)
+func (r *Repository) GetQuestion(ctx context.Context, testID domain.TestID, questionID domain.QuestionID) (*domain.Question, error) {
+	return r.current().GetQuestion(ctx, testID, questionID)
 }
 
-func (r *Repository) ListAnswers(testID domain.TestID, studentID domain.StudentID) ([]domain.Answer, error) {
-	return r.delegate.ListAnswers(testID, studentID)
+func (r *Repository) HasQuestion(ctx context.Context, testID domain.TestID, questionID domain.QuestionID) (bool, error) {
+	return r.current().HasQuestion(ctx, testID, questionID)
 }
 
-func (r *Repository) ListAnswersByTest(testID domain.TestID) ([]domain.Answer, error) {
-	return r.delegate.ListAnswersByTest(testID)
+func (r *Repository) IsStudentAssigned(ctx context.Context, testID domain.TestID, studentID domain.StudentID) (bool, error) {
+	return r.current().IsStudentAssigned(ctx, testID, studentID)
+}
+
+// AnswerRepository delegation with persistence.
+
+func (r *Repository) UpsertAnswer(ctx context.Context, answer *domain.Answer) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
+	defer r.mu.Unlock()
+
+	entry := &journalEntry{Stored: true}
+	return r.updateAnswers(answer.TestID, entry, func(m *memory.Repository) error {
+		if err := m.UpsertAnswer(ctx, answer); err != nil {
+			return err
+		}
+		stored, err := m.GetAnswer(ctx, answer.TestID, answer.QuestionID, answer.StudentID)
+		entry.Answer = stored
+		return err
+	})
+}
+
+func (r *Repository) DeleteAnswer(ctx context.Context, testID domain.TestID, questionID domain.QuestionID, studentID domain.StudentID) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
+	defer r.mu.Unlock()
+
+	deleted := &deletedAnswer{TestID: testID, QuestionID: questionID, StudentID: studentID}
+	return r.updateAnswers(testID, &journalEntry{Deleted: deleted}, func(m *memory.Repository) error {
+		return m.DeleteAnswer(ctx, testID, questionID, studentID)
+	})
+}
+
+func (r *Repository) GetAnswer(ctx context.Context, testID domain.TestID, questionID domain.QuestionID, studentID domain.StudentID) (*domain.Answer, error) {
+	return withAnswers(r, testID, func(m *memory.Repository) (*domain.Answer, error) {
+		return m.GetAnswer(ctx, testID, questionID, studentID)
+	})
+}
+
+func (r *Repository) HasAnswer(ctx context.Context, id domain.AnswerID) (bool, error) {
+	return withAnswers(r, r.testOf(id), func(m *memory.Repository) (bool, error) {
+		return m.HasAnswer(ctx, id)
+	})
+}
+
+func (r *Repository) ListAnswers(ctx context.Context, testID domain.TestID, studentID domain.StudentID) ([]domain.Answer, error) {
+	return withAnswers(r, testID, func(m *memory.Repository) ([]domain.Answer, error) {
+		return m.ListAnswers(ctx, testID, studentID)
+	})
+}
+
+func (r *Repository) ListAnswersByTest(ctx context.Context, testID domain.TestID, page repository.PageRequest) (repository.Page[domain.Answer], error) {
+	return withAnswers(r, testID, func(m *memory.Repository) (repository.Page[domain.Answer], error) {
+		return m.ListAnswersByTest(ctx, testID, page)
+	})
 }
 
 // ResultRepository delegation with persistence.
 
-func (r *Repository) SaveResult(result *domain.Result) error {
-	r.mu.Lock()
+func (r *Repository) SaveResult(ctx context.Context, result *domain.Result) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
 	defer r.mu.Unlock()
 
-	if err := r.delegate.SaveResult(result); err != nil {
+	testID := r.testOf(result.AnswerID)
+	if r.segments != nil && testID == "" {
+		return errors.New("answer not found")
+	}
+	return r.updateAnswers(testID, &journalEntry{Results: []domain.Result{*result}}, func(m *memory.Repository) error {
+		return m.SaveResult(ctx, result)
+	})
+}
+
+func (r *Repository) SaveResults(ctx context.Context, results []domain.Result) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
+	defer r.mu.Unlock()
+
+	if r.segments == nil {
+		if err := r.current().SaveResults(ctx, results); err != nil {
+			return err
+		}
+		return r.record(journalEntry{Results: results})
+	}
+
+	// Results are saved test by test; checking every answer first keeps a
+	// batch with an unknown answer from being saved in part.
+	var order []domain.TestID
+	byTest := make(map[domain.TestID][]domain.Result)
+	for _, res := range results {
+		testID := r.testOf(res.AnswerID)
+		if testID == "" {
+			return errors.New("answer not found")
+		}
+		if _, ok := byTest[testID]; !ok {
+			order = append(order, testID)
+		}
+		byTest[testID] = append(byTest[testID], res)
+	}
+	for _, testID := range order {
+		err := r.updateAnswers(testID, &journalEntry{}, func(m *memory.Repository) error {
+			return m.SaveResults(ctx, byTest[testID])
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Repository) GetResult(ctx context.Context, answerID domain.AnswerID) (*domain.Result, error) {
+	return withAnswers(r, r.testOf(answerID), func(m *memory.Repository) (*domain.Result, error) {
+		return m.GetResult(ctx, answerID)
+	})
+}
+
+func (r *Repository) ListResultsByTest(ctx context.Context, testID domain.TestID, page repository.PageRequest) (repository.Page[domain.Result], error) {
+	return withAnswers(r, testID, func(m *memory.Repository) (repository.Page[domain.Result], error) {
+		return m.ListResultsByTest(ctx, testID, page)
+	})
+}
+
+func (r *Repository) ListResultsByStudent(ctx context.Context, testID domain.TestID, studentID domain.StudentID) ([]domain.Result, error) {
+	return withAnswers(r, testID, func(m *memory.Repository) ([]domain.Result, error) {
+		return m.ListResultsByStudent(ctx, testID, studentID)
+	})
+}
+
+func (r *Repository) SnapshotGrading(ctx context.Context, testID domain.TestID) (repository.GradingSnapshot, error) {
+	return withAnswers(r, testID, func(m *memory.Repository) (repository.GradingSnapshot, error) {
+		return m.SnapshotGrading(ctx, testID)
+	})
+}
+
+// NotificationRepository delegation with persistence.
+
+func (r *Repository) SaveNotification(ctx context.Context, notification *domain.Notification) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
+	defer r.mu.Unlock()
+
+	if err := r.current().SaveNotification(ctx, notification); err != nil {
 		return err
 	}
 	return r.persist()
 }
 
-func (r *Repository) GetResult(answerID domain.AnswerID) (*domain.Result, error) {
-	return r.delegate.GetResult(answerID)
+func (r *Repository) ListNotifications(ctx context.Context, role domain.Role, recipientID string, page repository.PageRequest) (repository.Page[domain.Notification], error) {
+	return r.current().ListNotifications(ctx, role, recipientID, page)
 }
 
-func (r *Repository) ListResultsByTest(testID domain.TestID) ([]domain.Result, error) {
-	return r.delegate.ListResultsByTest(testID)
+func (r *Repository) MarkNotificationsRead(ctx context.Context, role domain.Role, recipientID string, ids []domain.NotificationID, at time.Time) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
+	defer r.mu.Unlock()
+
+	if err := r.current().MarkNotificationsRead(ctx, role, recipientID, ids, at); err != nil {
+		return err
+	}
+	return r.persist()
+}
+
+// DigestRepository delegation with persistence.
+
+func (r *Repository) QueueDigestItem(ctx context.Context, item *domain.DigestItem) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
+	defer r.mu.Unlock()
+
+	if err := r.current().QueueDigestItem(ctx, item); err != nil {
+		return err
+	}
+	return r.persist()
+}
+
+func (r *Repository) ListDigestItems(ctx context.Context) ([]domain.DigestItem, error) {
+	return r.current().ListDigestItems(ctx)
+}
+
+func (r *Repository) DeleteDigestItems(ctx context.Context, ids []domain.DigestItemID) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
+	defer r.mu.Unlock()
+
+	if err := r.current().DeleteDigestItems(ctx, ids); err != nil {
+		return err
+	}
+	return r.persist()
+}
+
+// QuestionCommentRepository delegation with persistence.
+
+func (r *Repository) SaveQuestionComment(ctx context.Context, comment *domain.QuestionComment) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
+	defer r.mu.Unlock()
+
+	if err := r.current().SaveQuestionComment(ctx, comment); err != nil {
+		return err
+	}
+	return r.persist()
+}
+
+func (r *Repository) GetQuestionComment(ctx context.Context, id domain.QuestionCommentID) (*domain.QuestionComment, error) {
+	return r.current().GetQuestionComment(ctx, id)
+}
+
+func (r *Repository) ListQuestionComments(ctx context.Context, testID domain.TestID, questionID domain.QuestionID) ([]domain.QuestionComment, error) {
+	return r.current().ListQuestionComments(ctx, testID, questionID)
 }
 
-func (r *Repository) ListResultsByStudent(testID domain.TestID, studentID domain.StudentID) ([]domain.Result, error) {
-	return r.delegate.ListResultsByStudent(testID, studentID)
+// AnswerCommentRepository delegation with persistence.
+
+func (r *Repository) SaveAnswerComment(ctx context.Context, comment *domain.AnswerComment) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
+	defer r.mu.Unlock()
+
+	if err := r.current().SaveAnswerComment(ctx, comment); err != nil {
+		return err
+	}
+	return r.persist()
+}
+
+func (r *Repository) GetAnswerComment(ctx context.Context, id domain.AnswerCommentID) (*domain.AnswerComment, error) {
+	return r.current().GetAnswerComment(ctx, id)
+}
+
+func (r *Repository) ListAnswerComments(ctx context.Context, testID domain.TestID, answerID domain.AnswerID) ([]domain.AnswerComment, error) {
+	return r.current().ListAnswerComments(ctx, testID, answerID)
+}
+
+func (r *Repository) ListAnswerCommentsByStudent(ctx context.Context, studentID domain.StudentID) ([]domain.AnswerComment, error) {
+	return r.current().ListAnswerCommentsByStudent(ctx, studentID)
+}
+
+// TestSessionRepository delegation with persistence.
+
+func (r *Repository) GetTestSession(ctx context.Context, testID domain.TestID, studentID domain.StudentID) (*domain.TestSession, error) {
+	return r.current().GetTestSession(ctx, testID, studentID)
+}
+
+func (r *Repository) SaveTestSession(ctx context.Context, session *domain.TestSession) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
+	defer r.mu.Unlock()
+
+	if err := r.current().SaveTestSession(ctx, session); err != nil {
+		return err
+	}
+	return r.persist()
+}
+
+// SubmissionRepository delegation with persistence.
+
+func (r *Repository) GetSubmission(ctx context.Context, testID domain.TestID, studentID domain.StudentID) (*domain.Submission, error) {
+	return r.current().GetSubmission(ctx, testID, studentID)
+}
+
+func (r *Repository) ListSubmissionsByTest(ctx context.Context, testID domain.TestID) ([]domain.Submission, error) {
+	return r.current().ListSubmissionsByTest(ctx, testID)
+}
+
+func (r *Repository) SaveSubmission(ctx context.Context, submission *domain.Submission) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
+	defer r.mu.Unlock()
+
+	if err := r.current().SaveSubmission(ctx, submission); err != nil {
+		return err
+	}
+	return r.persist()
+}
+
+// RubricRepository delegation with persistence.
+
+func (r *Repository) SaveRubrics(ctx context.Context, rubrics []domain.Rubric, templates []domain.FeedbackTemplate) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
+	defer r.mu.Unlock()
+
+	if err := r.current().SaveRubrics(ctx, rubrics, templates); err != nil {
+		return err
+	}
+	return r.persist()
+}
+
+func (r *Repository) GetRubric(ctx context.Context, id domain.RubricID) (*domain.Rubric, error) {
+	return r.current().GetRubric(ctx, id)
+}
+
+func (r *Repository) ListRubrics(ctx context.Context, teacherID domain.TeacherID, page repository.PageRequest) (repository.Page[domain.Rubric], error) {
+	return r.current().ListRubrics(ctx, teacherID, page)
+}
+
+func (r *Repository) ListFeedbackTemplates(ctx context.Context, teacherID domain.TeacherID, page repository.PageRequest) (repository.Page[domain.FeedbackTemplate], error) {
+	return r.current().ListFeedbackTemplates(ctx, teacherID, page)
+}
+
+// DelegationRepository delegation with persistence.
+
+func (r *Repository) SaveDelegation(ctx context.Context, delegation *domain.Delegation) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
+	defer r.mu.Unlock()
+
+	if err := r.current().SaveDelegation(ctx, delegation); err != nil {
+		return err
+	}
+	return r.persist()
+}
+
+func (r *Repository) GetDelegation(ctx context.Context, id domain.DelegationID) (*domain.Delegation, error) {
+	return r.current().GetDelegation(ctx, id)
+}
+
+func (r *Repository) ListDelegationsByTeacher(ctx context.Context, teacherID domain.TeacherID, page repository.PageRequest) (repository.Page[domain.Delegation], error) {
+	return r.current().ListDelegationsByTeacher(ctx, teacherID, page)
+}
+
+func (r *Repository) ListDelegationsForDelegate(ctx context.Context, delegateID domain.TeacherID) ([]domain.Delegation, error) {
+	return r.current().ListDelegationsForDelegate(ctx, delegateID)
+}
+
+func (r *Repository) DeleteDelegation(ctx context.Context, id domain.DelegationID) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
+	defer r.mu.Unlock()
+
+	if err := r.current().DeleteDelegation(ctx, id); err != nil {
+		return err
+	}
+	return r.persist()
+}
+
+func (r *Repository) DeleteExpiredDelegations(ctx context.Context, now time.Time) ([]domain.Delegation, error) {
+	if err := r.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.mu.Unlock()
+
+	expired, err := r.current().DeleteExpiredDelegations(ctx, now)
+	if err != nil || len(expired) == 0 {
+		return expired, err
+	}
+	return expired, r.persist()
+}
+
+// DeviceSessionRepository delegation with persistence.
+
+func (r *Repository) SaveDeviceSession(ctx context.Context, session *domain.DeviceSession) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
+	defer r.mu.Unlock()
+
+	if err := r.current().SaveDeviceSession(ctx, session); err != nil {
+		return err
+	}
+	return r.persist()
+}
+
+func (r *Repository) GetDeviceSession(ctx context.Context, id domain.DeviceSessionID) (*domain.DeviceSession, error) {
+	return r.current().GetDeviceSession(ctx, id)
+}
+
+func (r *Repository) ListDeviceSessions(ctx context.Context, studentID domain.StudentID) ([]domain.DeviceSession, error) {
+	return r.current().ListDeviceSessions(ctx, studentID)
+}
+
+func (r *Repository) DeleteExpiredDeviceSessions(ctx context.Context, now time.Time) ([]domain.DeviceSession, error) {
+	if err := r.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.mu.Unlock()
+
+	expired, err := r.current().DeleteExpiredDeviceSessions(ctx, now)
+	if err != nil || len(expired) == 0 {
+		return expired, err
+	}
+	return expired, r.persist()
+}
+
+// QuestionBankRepository delegation with persistence.
+
+func (r *Repository) SaveBankQuestion(ctx context.Context, question *domain.BankQuestion) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
+	defer r.mu.Unlock()
+
+	if err := r.current().SaveBankQuestion(ctx, question); err != nil {
+		return err
+	}
+	return r.persist()
+}
+
+func (r *Repository) GetBankQuestion(ctx context.Context, id domain.BankQuestionID) (*domain.BankQuestion, error) {
+	return r.current().GetBankQuestion(ctx, id)
+}
+
+func (r *Repository) ListBankQuestions(ctx context.Context, teacherID domain.TeacherID, page repository.PageRequest) (repository.Page[domain.BankQuestion], error) {
+	return r.current().ListBankQuestions(ctx, teacherID, page)
+}
+
+func (r *Repository) DeleteBankQuestion(ctx context.Context, id domain.BankQuestionID) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
+	defer r.mu.Unlock()
+
+	if err := r.current().DeleteBankQuestion(ctx, id); err != nil {
+		return err
+	}
+	return r.persist()
+}
+
+// DistrictRepository delegation with persistence.
+
+func (r *Repository) SaveDistrict(ctx context.Context, district *domain.District) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
+	defer r.mu.Unlock()
+
+	if err := r.current().SaveDistrict(ctx, district); err != nil {
+		return err
+	}
+	return r.persist()
+}
+
+func (r *Repository) GetDistrict(ctx context.Context, id domain.DistrictID) (*domain.District, error) {
+	return r.current().GetDistrict(ctx, id)
+}
+
+func (r *Repository) ListDistricts(ctx context.Context, page repository.PageRequest) (repository.Page[domain.District], error) {
+	return r.current().ListDistricts(ctx, page)
+}
+
+func (r *Repository) ListSchoolsByDistrict(ctx context.Context, districtID domain.DistrictID, page repository.PageRequest) (repository.Page[domain.School], error) {
+	return r.current().ListSchoolsByDistrict(ctx, districtID, page)
+}
+
+func (r *Repository) SaveDistrictStaff(ctx context.Context, staff *domain.DistrictStaff) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
+	defer r.mu.Unlock()
+
+	if err := r.current().SaveDistrictStaff(ctx, staff); err != nil {
+		return err
+	}
+	return r.persist()
+}
+
+func (r *Repository) GetDistrictStaff(ctx context.Context, id domain.DistrictStaffID) (*domain.DistrictStaff, error) {
+	return r.current().GetDistrictStaff(ctx, id)
+}
+
+// AuditRepository delegation with persistence.
+
+func (r *Repository) SaveAuditEntry(ctx context.Context, entry *domain.AuditEntry) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
+	defer r.mu.Unlock()
+
+	if err := r.current().SaveAuditEntry(ctx, entry); err != nil {
+		return err
+	}
+	return r.persist()
+}
+
+func (r *Repository) ListAuditEntries(ctx context.Context, filter repository.AuditFilter, page repository.PageRequest) (repository.Page[domain.AuditEntry], error) {
+	return r.current().ListAuditEntries(ctx, filter, page)
+}
+
+// Snapshot writes the current state as JSON, suitable for backups.
+func (r *Repository) Snapshot(ctx context.Context, w io.Writer) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
+	state, err := r.ExportState()
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(state)
 }
 
 // Helpers.
 
+// lock takes the lock unless ctx is done by the time it is held, so that
+// work abandoned by its caller while waiting is not done anyway.
+func (r *Repository) lock(ctx context.Context) error {
+	r.mu.Lock()
+	if err := ctx.Err(); err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
 func (r *Repository) persist() error {
-	state := r.delegate.ExportState()
-	tmp := r.path + ".tmp"
+	r.writes++
+	if r.segments != nil {
+		return writeState(r.path, r.current().ExportStateWithoutAnswers())
+	}
+	if err := writeState(r.path, r.current().ExportState()); err != nil {
+		return err
+	}
+	if r.journal != nil {
+		return r.journal.reset()
+	}
+	return nil
+}
+
+// record persists a write of answers or results to an eager store: appended
+// to the journal in journal mode, which is compacted once full, and by
+// rewriting the state file otherwise. The caller holds r.mu.
+func (r *Repository) record(entry journalEntry) error {
+	if r.journal == nil {
+		return r.persist()
+	}
+	if err := r.journal.append(entry); err != nil {
+		return err
+	}
+	r.writes++
+	if r.journal.full() {
+		return r.persist()
+	}
+	return nil
+}
+
+// withAnswers runs fn once the answers of the test are in memory. An empty
+// testID, such as an answer ID that is not stored, runs fn as it is.
+func withAnswers[T any](r *Repository, testID domain.TestID, fn func(*memory.Repository) (T, error)) (T, error) {
+	if r.segments == nil || testID == "" {
+		return fn(r.current())
+	}
+	var out T
+	err := r.segments.use(r.current, testID, func(m *memory.Repository) error {
+		var err error
+		out, err = fn(m)
+		return err
+	})
+	return out, err
+}
+
+// updateAnswers applies fn to the answers of the test and persists them. In
+// lazy mode only the test's file is written; in eager mode entry, as fn
+// leaves it, records the write. The caller holds r.mu.
+func (r *Repository) updateAnswers(testID domain.TestID, entry *journalEntry, fn func(*memory.Repository) error) error {
+	_, err := withAnswers(r, testID, func(m *memory.Repository) (struct{}, error) {
+		if err := fn(m); err != nil {
+			return struct{}{}, err
+		}
+		if r.segments == nil {
+			return struct{}{}, r.record(*entry)
+		}
+		r.writes++
+		return struct{}{}, r.segments.save(m, testID)
+	})
+	return err
+}
+
+// Reindex rebuilds the index locating answers in lazy mode from the files
+// of every test. An eager store keeps no index.
+func (r *Repository) Reindex(ctx context.Context) error {
+	if r.segments == nil {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return r.segments.reindex()
+}
+
+// testOf returns the test of a stored answer in lazy mode, or "" when the
+// answer is unknown or the store is not lazy.
+func (r *Repository) testOf(id domain.AnswerID) domain.TestID {
+	if r.segments == nil {
+		return ""
+	}
+	testID, _ := r.segments.testOf(id)
+	return testID
+}
+
+// storeLazy replaces the files of a lazy store with state and makes it the
+// live data. The caller holds r.mu.
+func (r *Repository) storeLazy(state memory.State) error {
+	if err := writeSegments(r.segments.dir, state.Answers, state.Results); err != nil {
+		return err
+	}
+	state.Answers, state.Results = []domain.Answer{}, []domain.Result{}
+	if err := writeState(r.path, state); err != nil {
+		return err
+	}
+	repo := memory.NewRepositoryFromState(state)
+	return r.segments.replace(func() { r.delegate.Store(repo) })
+}
+
+func writeState(path string, state memory.State) error {
+	return writeJSON(path, state)
+}
+
+func writeJSON(path string, v any) error {
+	tmp := path + ".tmp"
 
 	file, err := os.Create(tmp)
 	if err != nil {
@@ -203,14 +996,14 @@ func (r *Repository) persist() error {
 	}
 	encoder := json.NewEncoder(file)
 	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(state); err != nil {
+	if err := encoder.Encode(v); err != nil {
 		file.Close()
 		return err
 	}
 	if err := file.Close(); err != nil {
 		return err
 	}
-	return os.Rename(tmp, r.path)
+	return os.Rename(tmp, path)
 }
 
 func loadState(path string) (memory.State, error) {
@@ -229,5 +1022,9 @@ func loadState(path string) (memory.State, error) {
 
 // Delegate exposes the underlying memory repository for testing purposes.
 func (r *Repository) Delegate() *memory.Repository {
-	return r.delegate
+	return r.current()
+}
+
+func (r *Repository) current() *memory.Repository {
+	return r.delegate.Load()
 }
@@ -1,20 +1,25 @@
 package filedb_test
 
 import (
+	"context"
+	"errors"
 	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
 	"github.com/sky0621/go_work_sample/core/pkg/memory"
 	"github.com/sky0621/go_work_sample/core/pkg/storage/filedb"
 )
 
 func TestRepositoryPersistence(t *testing.T) {
+	ctx := context.Background()
 	dir := t.TempDir()
 	path := filepath.Join(dir, "state.json")
 
-	repo, err := filedb.NewRepository(path, memory.SampleSeed())
+	repo, err := filedb.NewRepository(path, fixtures.NewSchool().Seed())
 	if err != nil {
 		t.Fatalf("NewRepository failed: %v", err)
 	}
@@ -35,20 +40,174 @@ func TestRepositoryPersistence(t *testing.T) {
 		CreatedAt: time.Now().UTC(),
 	}}
 
-	if err := repo.CreateTest(test, questions, []domain.StudentID{"student-001"}); err != nil {
+	if err := repo.CreateTest(ctx, test, questions, []domain.StudentID{"student-001"}); err != nil {
 		t.Fatalf("CreateTest failed: %v", err)
 	}
 
-	repo2, err := filedb.NewRepository(path, memory.SampleSeed())
+	repo2, err := filedb.NewRepository(path, memory.SeedData{})
 	if err != nil {
 		t.Fatalf("reloading repository failed: %v", err)
 	}
 
-	loaded, err := repo2.GetTest(test.ID)
+	loaded, err := repo2.GetTest(ctx, test.ID)
 	if err != nil {
 		t.Fatalf("GetTest failed: %v", err)
 	}
 	if loaded == nil || loaded.ID != test.ID {
 		t.Fatalf("expected test to persist, got %+v", loaded)
 	}
+
+	has, err := repo2.HasQuestion(ctx, test.ID, questions[0].ID)
+	if err != nil || !has {
+		t.Fatalf("expected question membership to persist, got %v %v", has, err)
+	}
+	if has, _ := repo2.HasQuestion(ctx, "other-test", questions[0].ID); has {
+		t.Fatal("expected question to belong only to its own test")
+	}
+}
+
+func TestRepositoryRefusesWritesOfCanceledContexts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	repo, err := filedb.NewRepository(path, fixtures.NewSchool().Seed())
+	if err != nil {
+		t.Fatalf("NewRepository failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	test := &domain.Test{ID: "test-001", TeacherID: "teacher-001", Title: "History Quiz"}
+	if err := repo.CreateTest(ctx, test, nil, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the write to be canceled, got %v", err)
+	}
+
+	reloaded, err := filedb.NewRepository(path, memory.SeedData{})
+	if err != nil {
+		t.Fatalf("reloading repository failed: %v", err)
+	}
+	if got, _ := reloaded.GetTest(context.Background(), test.ID); got != nil {
+		t.Fatalf("expected a canceled write to store nothing, found %+v", got)
+	}
+}
+
+func TestRepositoryStageAndPromote(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	live, err := filedb.NewRepository(filepath.Join(dir, "state.json"), fixtures.NewSchool().WithStudents(3).Seed())
+	if err != nil {
+		t.Fatalf("NewRepository failed: %v", err)
+	}
+
+	seed := fixtures.NewSchool().WithStudents(4).Seed()
+	candidatePath := filepath.Join(dir, "candidate.json")
+	if _, err := filedb.NewRepository(candidatePath, seed); err != nil {
+		t.Fatalf("writing candidate failed: %v", err)
+	}
+
+	if _, err := live.Promote(); !errors.Is(err, errs.ErrNoCandidate) {
+		t.Fatalf("expected promote without candidate to fail, got %v", err)
+	}
+
+	candidate, err := live.Stage(candidatePath)
+	if err != nil {
+		t.Fatalf("Stage failed: %v", err)
+	}
+	if candidate.Counts["students"] != 4 {
+		t.Fatalf("expected candidate to hold 4 students, got %d", candidate.Counts["students"])
+	}
+
+	if student, _ := live.GetStudent(ctx, "student-004"); student != nil {
+		t.Fatal("expected staged data to stay invisible before promotion")
+	}
+
+	if _, err := live.Promote(); err != nil {
+		t.Fatalf("Promote failed: %v", err)
+	}
+	if student, _ := live.GetStudent(ctx, "student-004"); student == nil {
+		t.Fatal("expected promoted data to be live")
+	}
+
+	reloaded, err := filedb.NewRepository(filepath.Join(dir, "state.json"), memory.SeedData{})
+	if err != nil {
+		t.Fatalf("reloading repository failed: %v", err)
+	}
+	if student, _ := reloaded.GetStudent(ctx, "student-004"); student == nil {
+		t.Fatal("expected promoted data to be persisted")
+	}
+}
+
+func TestRepositoryPromoteRefusesStaleCandidate(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+
+	live, err := filedb.Open(path, fixtures.NewSchool().WithStudents(3).Seed(), filedb.Options{Journal: true})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	candidatePath := filepath.Join(dir, "candidate.json")
+	if _, err := filedb.NewRepository(candidatePath, fixtures.NewSchool().WithStudents(4).Seed()); err != nil {
+		t.Fatalf("writing candidate failed: %v", err)
+	}
+	if _, err := live.Stage(candidatePath); err != nil {
+		t.Fatalf("Stage failed: %v", err)
+	}
+
+	now := time.Now().UTC()
+	test := &domain.Test{ID: "test-001", TeacherID: "teacher-001", Title: "Quiz", CreatedAt: now, UpdatedAt: now}
+	questions := []domain.Question{{ID: "question-001", TestID: test.ID, Sequence: 1, Prompt: "?", Points: 10, CreatedAt: now}}
+	if err := live.CreateTest(ctx, test, questions, []domain.StudentID{"student-001"}); err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	if _, err := live.Promote(); !errors.Is(err, errs.ErrStaleCandidate) {
+		t.Fatalf("expected a write after staging to refuse promotion, got %v", err)
+	}
+	if got, _ := live.GetTest(ctx, test.ID); got == nil {
+		t.Fatal("expected the refused promotion to keep the live write")
+	}
+
+	// A journaled write before staging is not replayed onto the candidate.
+	answer := &domain.Answer{ID: "answer-001", TestID: test.ID, QuestionID: "question-001", StudentID: "student-001", Response: "42", CreatedAt: now, UpdatedAt: now}
+	if err := live.UpsertAnswer(ctx, answer); err != nil {
+		t.Fatalf("UpsertAnswer failed: %v", err)
+	}
+	if _, err := live.Stage(candidatePath); err != nil {
+		t.Fatalf("Stage failed: %v", err)
+	}
+	if _, err := live.Promote(); err != nil {
+		t.Fatalf("Promote failed: %v", err)
+	}
+	reloaded, err := filedb.Open(path, memory.SeedData{}, filedb.Options{Journal: true})
+	if err != nil {
+		t.Fatalf("reopening failed: %v", err)
+	}
+	if student, _ := reloaded.GetStudent(ctx, "student-004"); student == nil {
+		t.Fatal("expected promoted data to be persisted")
+	}
+	if got, _ := reloaded.GetAnswer(ctx, test.ID, "question-001", "student-001"); got != nil {
+		t.Fatalf("expected the live journal to be gone after promotion, got %+v", got)
+	}
+}
+
+func TestRepositoryStageRejectsBrokenSnapshot(t *testing.T) {
+	dir := t.TempDir()
+
+	live, err := filedb.NewRepository(filepath.Join(dir, "state.json"), fixtures.NewSchool().Seed())
+	if err != nil {
+		t.Fatalf("NewRepository failed: %v", err)
+	}
+
+	seed := fixtures.NewSchool().Seed()
+	seed.Students[0].ClassID = domain.ClassID("class-missing")
+	candidatePath := filepath.Join(dir, "candidate.json")
+	if _, err := filedb.NewRepository(candidatePath, seed); err != nil {
+		t.Fatalf("writing candidate failed: %v", err)
+	}
+
+	if _, err := live.Stage(candidatePath); !errors.Is(err, errs.ErrInvalidSnapshot) {
+		t.Fatalf("expected integrity failure, got %v", err)
+	}
+	if live.Staged() != nil {
+		t.Fatal("expected broken snapshot not to be staged")
+	}
 }
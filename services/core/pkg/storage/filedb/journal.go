@@ -0,0 +1,154 @@
+package filedb
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/memory"
+)
+
+const defaultCompactAfter = 1000
+
+// journal is the write-ahead log of an eager store in journal mode. Answer
+// and result writes are appended to it instead of rewriting the state file;
+// any other write rewrites the state file and empties the journal, and so
+// does reaching compactAfter entries. Writers hold the repository's lock.
+type journal struct {
+	path         string
+	size         int64
+	entries      int
+	compactAfter int
+}
+
+// journalEntry is one line of the journal. Answer is the answer as stored,
+// with its revisions, and Stored marks it so; journals written before answers
+// were recorded that way hold the answer as it was written instead.
+type journalEntry struct {
+	Answer  *domain.Answer  `json:"answer,omitempty"`
+	Stored  bool            `json:"stored,omitempty"`
+	Results []domain.Result `json:"results,omitempty"`
+	Deleted *deletedAnswer  `json:"deleted,omitempty"`
+}
+
+// deletedAnswer identifies an answer removed with its result.
+type deletedAnswer struct {
+	TestID     domain.TestID     `json:"test_id"`
+	QuestionID domain.QuestionID `json:"question_id"`
+	StudentID  domain.StudentID  `json:"student_id"`
+}
+
+func journalPath(path string) string {
+	return path + ".wal"
+}
+
+func newJournal(path string, compactAfter int) *journal {
+	if compactAfter <= 0 {
+		compactAfter = defaultCompactAfter
+	}
+	return &journal{path: journalPath(path), compactAfter: compactAfter}
+}
+
+// append writes the entry and syncs it to disk. A failed append is cut off
+// again so the next one does not follow a partial line.
+func (j *journal) append(entry journalEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	file, err := os.OpenFile(j.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(line); err != nil {
+		file.Truncate(j.size)
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	j.size += int64(len(line))
+	j.entries++
+	return nil
+}
+
+// full reports whether the journal is due for compaction.
+func (j *journal) full() bool {
+	return j.entries >= j.compactAfter
+}
+
+// reset empties the journal once its entries are in the state file.
+func (j *journal) reset() error {
+	if err := os.Remove(j.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	j.size, j.entries = 0, 0
+	return nil
+}
+
+// replayJournal applies the journal next to the state file at path to state
+// and reports whether there was one. Replaying is idempotent, so entries
+// already compacted into the state before a crash are harmless: stored
+// answers are restored as they are rather than revised again. A partial
+// final line, left by a crash during an append, is ignored.
+func replayJournal(path string, state memory.State) (memory.State, bool, error) {
+	file, err := os.Open(journalPath(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return state, false, nil
+	}
+	if err != nil {
+		return state, false, err
+	}
+	defer file.Close()
+
+	ctx := context.Background()
+	repo := memory.NewRepositoryFromState(state)
+	reader := bufio.NewReader(file)
+	for n := 1; ; n++ {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return state, false, err
+		}
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var entry journalEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return state, false, fmt.Errorf("filedb: journal entry %d: %w", n, err)
+		}
+		if entry.Answer != nil && entry.Stored {
+			repo.LoadAnswers([]domain.Answer{*entry.Answer}, nil)
+		} else if entry.Answer != nil {
+			if err := repo.UpsertAnswer(ctx, entry.Answer); err != nil {
+				return state, false, err
+			}
+		}
+		if len(entry.Results) > 0 {
+			if err := repo.SaveResults(ctx, entry.Results); err != nil {
+				return state, false, fmt.Errorf("filedb: journal entry %d: %w", n, err)
+			}
+		}
+		if d := entry.Deleted; d != nil {
+			if err := repo.DeleteAnswer(ctx, d.TestID, d.QuestionID, d.StudentID); err != nil {
+				return state, false, err
+			}
+		}
+	}
+	return repo.ExportState(), true, nil
+}
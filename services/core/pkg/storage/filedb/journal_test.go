@@ -0,0 +1,164 @@
+package filedb_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/memory"
+	"github.com/sky0621/go_work_sample/core/pkg/storage/filedb"
+)
+
+func TestJournalReplaysAnswersAfterCrash(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state.json")
+	opts := filedb.Options{Journal: true, CompactAfter: 3}
+
+	repo, err := filedb.Open(path, fixtures.NewSchool().WithStudents(3).Seed(), opts)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	now := time.Now().UTC()
+	test := &domain.Test{ID: "test-001", TeacherID: "teacher-001", Title: "Quiz", CreatedAt: now, UpdatedAt: now}
+	questions := []domain.Question{{ID: "question-001", TestID: test.ID, Sequence: 1, Prompt: "?", Points: 10, CreatedAt: now}}
+	if err := repo.CreateTest(ctx, test, questions, []domain.StudentID{"student-001", "student-002", "student-003"}); err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	answer := &domain.Answer{ID: "answer-001", TestID: test.ID, QuestionID: "question-001", StudentID: "student-001", Response: "42", CreatedAt: now, UpdatedAt: now}
+	if err := repo.UpsertAnswer(ctx, answer); err != nil {
+		t.Fatalf("UpsertAnswer failed: %v", err)
+	}
+	if err := repo.SaveResult(ctx, &domain.Result{ID: "result-001", AnswerID: answer.ID, Score: 7, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("SaveResult failed: %v", err)
+	}
+	if stored := readState(t, path); len(stored.Answers) != 0 || len(stored.Results) != 0 {
+		t.Fatalf("expected journaled writes to leave the state file alone, got %+v", stored)
+	}
+
+	// A crash in the middle of an append leaves a partial line behind.
+	file, err := os.OpenFile(path+".wal", os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("opening journal failed: %v", err)
+	}
+	file.WriteString(`{"answer":{"ID":"answer-0`)
+	file.Close()
+
+	reopened, err := filedb.Open(path, memory.SeedData{}, opts)
+	if err != nil {
+		t.Fatalf("reopening failed: %v", err)
+	}
+	if got, _ := reopened.GetAnswer(ctx, test.ID, "question-001", "student-001"); got == nil || got.Response != "42" {
+		t.Fatalf("expected the journaled answer to be replayed, got %+v", got)
+	}
+	if got, _ := reopened.GetResult(ctx, answer.ID); got == nil || got.Score != 7 {
+		t.Fatalf("expected the journaled result to be replayed, got %+v", got)
+	}
+	if stored := readState(t, path); len(stored.Answers) != 1 || len(stored.Results) != 1 {
+		t.Fatalf("expected the replayed journal to be compacted, got %+v", stored)
+	}
+	if _, err := os.Stat(path + ".wal"); !os.IsNotExist(err) {
+		t.Fatalf("expected the journal to be removed after compaction, got %v", err)
+	}
+
+	for _, studentID := range []domain.StudentID{"student-002", "student-003"} {
+		next := &domain.Answer{ID: domain.AnswerID("answer-" + studentID), TestID: test.ID, QuestionID: "question-001", StudentID: studentID, Response: "1", CreatedAt: now, UpdatedAt: now}
+		if err := reopened.UpsertAnswer(ctx, next); err != nil {
+			t.Fatalf("UpsertAnswer failed: %v", err)
+		}
+	}
+	if err := reopened.SaveResults(ctx, []domain.Result{{ID: "result-002", AnswerID: "answer-student-002", Score: 3}}); err != nil {
+		t.Fatalf("SaveResults failed: %v", err)
+	}
+	if stored := readState(t, path); len(stored.Answers) != 3 || len(stored.Results) != 2 {
+		t.Fatalf("expected a full journal to be compacted, got %d answers and %d results", len(stored.Answers), len(stored.Results))
+	}
+
+	if err := reopened.DeleteAnswer(ctx, test.ID, "question-001", "student-002"); err != nil {
+		t.Fatalf("DeleteAnswer failed: %v", err)
+	}
+	replayed, err := filedb.Open(path, memory.SeedData{}, opts)
+	if err != nil {
+		t.Fatalf("reopening failed: %v", err)
+	}
+	if got, _ := replayed.GetAnswer(ctx, test.ID, "question-001", "student-002"); got != nil {
+		t.Fatalf("expected the journaled deletion to be replayed, got %+v", got)
+	}
+	if got, _ := replayed.GetResult(ctx, "answer-student-002"); got != nil {
+		t.Fatalf("expected the deleted answer's result to be gone, got %+v", got)
+	}
+}
+
+func TestJournalReplayAfterCompactionKeepsRevisions(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state.json")
+	opts := filedb.Options{Journal: true}
+
+	repo, err := filedb.Open(path, fixtures.NewSchool().WithStudents(1).Seed(), opts)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	now := time.Now().UTC()
+	test := &domain.Test{ID: "test-001", TeacherID: "teacher-001", Title: "Quiz", CreatedAt: now, UpdatedAt: now}
+	questions := []domain.Question{{ID: "question-001", TestID: test.ID, Sequence: 1, Prompt: "?", Points: 10, CreatedAt: now}}
+	if err := repo.CreateTest(ctx, test, questions, []domain.StudentID{"student-001"}); err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	for i, response := range []string{"1", "2", "3"} {
+		at := now.Add(time.Duration(i) * time.Minute)
+		answer := &domain.Answer{ID: "answer-001", TestID: test.ID, QuestionID: "question-001", StudentID: "student-001", Response: response, CreatedAt: now, UpdatedAt: at}
+		if err := repo.UpsertAnswer(ctx, answer); err != nil {
+			t.Fatalf("UpsertAnswer failed: %v", err)
+		}
+	}
+
+	// A crash after compaction wrote the state file but before it removed
+	// the journal leaves entries behind that the state already holds.
+	journal, err := os.ReadFile(path + ".wal")
+	if err != nil {
+		t.Fatalf("reading journal failed: %v", err)
+	}
+	test.Title = "Renamed"
+	if err := repo.UpdateTest(ctx, test); err != nil {
+		t.Fatalf("UpdateTest failed: %v", err)
+	}
+	if err := os.WriteFile(path+".wal", journal, 0o644); err != nil {
+		t.Fatalf("restoring journal failed: %v", err)
+	}
+
+	reopened, err := filedb.Open(path, memory.SeedData{}, opts)
+	if err != nil {
+		t.Fatalf("reopening failed: %v", err)
+	}
+	got, err := reopened.GetAnswer(ctx, test.ID, "question-001", "student-001")
+	if err != nil || got == nil {
+		t.Fatalf("GetAnswer failed: %v", err)
+	}
+	if got.Response != "3" || len(got.Revisions) != 2 {
+		t.Fatalf("expected response 3 with 2 revisions after replay, got %q with %d", got.Response, len(got.Revisions))
+	}
+}
+
+func TestJournalRequiresEagerLoading(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if _, err := filedb.Open(path, memory.SeedData{}, filedb.Options{Lazy: true, Journal: true}); err == nil {
+		t.Fatalf("expected journal and lazy mode to be rejected together")
+	}
+}
+
+func readState(t *testing.T, path string) memory.State {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading state failed: %v", err)
+	}
+	var state memory.State
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatalf("decoding state failed: %v", err)
+	}
+	return state
+}
@@ -0,0 +1,133 @@
+package filedb_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/memory"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+	"github.com/sky0621/go_work_sample/core/pkg/storage/filedb"
+)
+
+func TestLazyRepositoryLoadsAnswersPerTest(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state.json")
+	opts := filedb.Options{Lazy: true, MaxLoadedTests: 1}
+
+	repo, err := filedb.Open(path, fixtures.NewSchool().Seed(), opts)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	now := time.Now().UTC()
+	for _, testID := range []domain.TestID{"test-a", "test-b"} {
+		test := &domain.Test{ID: testID, TeacherID: "teacher-001", Title: string(testID), CreatedAt: now, UpdatedAt: now}
+		questions := []domain.Question{{ID: domain.QuestionID(testID + "-q1"), TestID: testID, Sequence: 1, Prompt: "?", Points: 10, CreatedAt: now}}
+		if err := repo.CreateTest(ctx, test, questions, []domain.StudentID{"student-001"}); err != nil {
+			t.Fatalf("CreateTest failed: %v", err)
+		}
+		answer := &domain.Answer{ID: domain.AnswerID(testID + "-a1"), TestID: testID, QuestionID: questions[0].ID, StudentID: "student-001", Response: "42", CreatedAt: now, UpdatedAt: now}
+		if err := repo.UpsertAnswer(ctx, answer); err != nil {
+			t.Fatalf("UpsertAnswer failed: %v", err)
+		}
+		if err := repo.SaveResult(ctx, &domain.Result{ID: domain.ResultID(testID + "-r1"), AnswerID: answer.ID, Score: 7, CreatedAt: now, UpdatedAt: now}); err != nil {
+			t.Fatalf("SaveResult failed: %v", err)
+		}
+	}
+	if loaded := len(repo.Delegate().ExportState().Answers); loaded != 1 {
+		t.Fatalf("expected only the most recent test in memory, got %d answers", loaded)
+	}
+	if err := repo.SaveResult(ctx, &domain.Result{ID: "r-x", AnswerID: "missing"}); err == nil {
+		t.Fatalf("expected a result for an unknown answer to be rejected")
+	}
+	if state, err := repo.ExportState(); err != nil || len(state.Answers) != 2 || len(state.Results) != 2 {
+		t.Fatalf("expected the export to hold every answer, got %d answers and %d results (%v)", len(state.Answers), len(state.Results), err)
+	}
+
+	reopened, err := filedb.Open(path, memory.SeedData{}, opts)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	if loaded := len(reopened.Delegate().ExportState().Answers); loaded != 0 {
+		t.Fatalf("expected no answers loaded on open, got %d", loaded)
+	}
+	result, err := reopened.GetResult(ctx, "test-a-a1")
+	if err != nil || result == nil || result.Score != 7 {
+		t.Fatalf("expected the result to load through the answer index, got %+v, %v", result, err)
+	}
+	page, err := reopened.ListAnswersByTest(ctx, "test-b", repository.All)
+	if err != nil || len(page.Items) != 1 {
+		t.Fatalf("expected test-b's answer, got %+v, %v", page.Items, err)
+	}
+	if has, _ := reopened.HasAnswer(ctx, "test-a-a1"); !has {
+		t.Fatalf("expected an unloaded test's answer to be found")
+	}
+
+	eager, err := filedb.NewRepository(path, memory.SeedData{})
+	if err != nil {
+		t.Fatalf("eager reopen failed: %v", err)
+	}
+	if state := eager.Delegate().ExportState(); len(state.Answers) != 2 || len(state.Results) != 2 {
+		t.Fatalf("expected an eager open to merge the answer files back, got %d answers", len(state.Answers))
+	}
+
+	lazyAgain, err := filedb.Open(path, memory.SeedData{}, opts)
+	if err != nil {
+		t.Fatalf("lazy reopen failed: %v", err)
+	}
+	if loaded := len(lazyAgain.Delegate().ExportState().Answers); loaded != 0 {
+		t.Fatalf("expected a lazy open to split the answers out again, got %d loaded", loaded)
+	}
+	if answers, err := lazyAgain.ListAnswers(ctx, "test-a", "student-001"); err != nil || len(answers) != 1 {
+		t.Fatalf("expected test-a's answer after the round trip, got %+v, %v", answers, err)
+	}
+}
+
+func TestLazyRepositoryReindexRestoresTheAnswerIndex(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+	opts := filedb.Options{Lazy: true}
+
+	repo, err := filedb.Open(path, fixtures.NewSchool().Seed(), opts)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	now := time.Now().UTC()
+	test := &domain.Test{ID: "test-a", TeacherID: "teacher-001", Title: "A", CreatedAt: now, UpdatedAt: now}
+	questions := []domain.Question{{ID: "q1", TestID: test.ID, Sequence: 1, Prompt: "?", Points: 10, CreatedAt: now}}
+	if err := repo.CreateTest(ctx, test, questions, []domain.StudentID{"student-001"}); err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	if err := repo.UpsertAnswer(ctx, &domain.Answer{ID: "a1", TestID: test.ID, QuestionID: "q1", StudentID: "student-001", Response: "42", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("UpsertAnswer failed: %v", err)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "state.answers", "index.log")); err != nil {
+		t.Fatalf("remove index failed: %v", err)
+	}
+	damaged, err := filedb.Open(path, memory.SeedData{}, opts)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	if has, _ := damaged.HasAnswer(ctx, "a1"); has {
+		t.Fatalf("expected the answer to be unreachable without its index")
+	}
+	if err := damaged.Reindex(ctx); err != nil {
+		t.Fatalf("Reindex failed: %v", err)
+	}
+	if has, _ := damaged.HasAnswer(ctx, "a1"); !has {
+		t.Fatalf("expected the answer to be found after reindexing")
+	}
+	reopened, err := filedb.Open(path, memory.SeedData{}, opts)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	if has, _ := reopened.HasAnswer(ctx, "a1"); !has {
+		t.Fatalf("expected the rebuilt index to be written to disk")
+	}
+}
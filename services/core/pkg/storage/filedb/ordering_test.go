@@ -0,0 +1,96 @@
+package filedb_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/memory"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+	"github.com/sky0621/go_work_sample/core/pkg/storage/filedb"
+)
+
+// orderingRepository is what the ordering checks need from a backend.
+type orderingRepository interface {
+	repository.OrganizationReader
+	repository.TestRepository
+	repository.AnswerRepository
+}
+
+// TestListOrderingBreaksTiesOnID checks every backend returns records that
+// share a timestamp ordered by ID, so cursors over them stay stable.
+func TestListOrderingBreaksTiesOnID(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	seed := memory.SeedData{
+		Schools: []domain.School{{ID: "school-1", Name: "School", CreatedAt: now}},
+		Grades:  []domain.Grade{{ID: "grade-1", SchoolID: "school-1", Name: "Grade", CreatedAt: now}},
+		Classes: []domain.Class{{ID: "class-1", GradeID: "grade-1", Name: "Class", CreatedAt: now}},
+		Teachers: []domain.Teacher{
+			{ID: "teacher-c", SchoolID: "school-1", Name: "C", CreatedAt: now},
+			{ID: "teacher-a", SchoolID: "school-1", Name: "A", CreatedAt: now},
+			{ID: "teacher-b", SchoolID: "school-1", Name: "B", CreatedAt: now},
+		},
+	}
+	for _, id := range []string{"student-5", "student-1", "student-4", "student-2", "student-3"} {
+		seed.Students = append(seed.Students, domain.Student{ID: domain.StudentID(id), ClassID: "class-1", Name: id, CreatedAt: now})
+	}
+
+	fileRepo, err := filedb.NewRepository(filepath.Join(t.TempDir(), "state.json"), seed)
+	if err != nil {
+		t.Fatalf("NewRepository failed: %v", err)
+	}
+	backends := map[string]orderingRepository{
+		"memory": memory.NewRepository(seed),
+		"filedb": fileRepo,
+	}
+
+	for name, repo := range backends {
+		t.Run(name, func(t *testing.T) {
+			for _, id := range []domain.TestID{"test-z", "test-x", "test-y"} {
+				test := &domain.Test{ID: id, TeacherID: "teacher-a", Title: string(id), CreatedAt: now, UpdatedAt: now}
+				question := domain.Question{ID: domain.QuestionID("q-" + id), TestID: id, Sequence: 1, Prompt: "Q", Points: 1, CreatedAt: now}
+				if err := repo.CreateTest(ctx, test, []domain.Question{question}, []domain.StudentID{"student-1", "student-2"}); err != nil {
+					t.Fatalf("CreateTest failed: %v", err)
+				}
+			}
+			for _, id := range []domain.AnswerID{"answer-2", "answer-1"} {
+				student := domain.StudentID("student-" + string(id)[len("answer-"):])
+				if err := repo.UpsertAnswer(ctx, &domain.Answer{ID: id, TestID: "test-x", QuestionID: "q-test-x", StudentID: student, CreatedAt: now, UpdatedAt: now}); err != nil {
+					t.Fatalf("UpsertAnswer failed: %v", err)
+				}
+			}
+
+			for attempt := 0; attempt < 5; attempt++ {
+				teachers, _ := repository.Collect(repo.ListTeachers(ctx, "school-1", repository.All))
+				expectOrder(t, "teachers", ids(teachers, func(v domain.Teacher) string { return string(v.ID) }), "teacher-a", "teacher-b", "teacher-c")
+				students, _ := repository.Collect(repo.ListStudents(ctx, "class-1", repository.All))
+				expectOrder(t, "students", ids(students, func(v domain.Student) string { return string(v.ID) }), "student-1", "student-2", "student-3", "student-4", "student-5")
+				tests, _ := repository.Collect(repo.ListTestsByTeacher(ctx, "teacher-a", repository.All))
+				expectOrder(t, "tests by teacher", ids(tests, func(v domain.Test) string { return string(v.ID) }), "test-x", "test-y", "test-z")
+				assigned, _ := repository.Collect(repo.ListTestsForStudent(ctx, "student-1", repository.All))
+				expectOrder(t, "tests for student", ids(assigned, func(v domain.Test) string { return string(v.ID) }), "test-x", "test-y", "test-z")
+				answers, _ := repository.Collect(repo.ListAnswersByTest(ctx, "test-x", repository.All))
+				expectOrder(t, "answers", ids(answers, func(v domain.Answer) string { return string(v.ID) }), "answer-1", "answer-2")
+			}
+		})
+	}
+}
+
+func ids[T any](items []T, id func(T) string) []string {
+	out := make([]string, len(items))
+	for i, item := range items {
+		out[i] = id(item)
+	}
+	return out
+}
+
+func expectOrder(t *testing.T, what string, got []string, want ...string) {
+	t.Helper()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("%s: got order %v, want %v", what, got, want)
+	}
+}
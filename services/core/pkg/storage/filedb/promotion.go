@@ -0,0 +1,203 @@
+package filedb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/memory"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// Candidate is a state snapshot loaded side-by-side with the live data,
+// waiting to be promoted.
+type Candidate struct {
+	Path     string
+	StagedAt time.Time
+	Counts   map[string]int
+
+	repo *memory.Repository
+	// writes is the live store's write count when the candidate was staged.
+	writes uint64
+}
+
+// Stage loads the snapshot at path next to the live repository, verifies its
+// referential integrity and runs smoke queries against it. A successfully
+// staged candidate replaces any previously staged one.
+func (r *Repository) Stage(path string) (*Candidate, error) {
+	state, err := loadState(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errs.ErrInvalidSnapshot, err)
+	}
+	if err := memory.CheckIntegrity(state); err != nil {
+		return nil, fmt.Errorf("%w: %w", errs.ErrInvalidSnapshot, err)
+	}
+
+	repo := memory.NewRepositoryFromState(state)
+	if err := smokeTest(repo, state); err != nil {
+		return nil, fmt.Errorf("%w: %w", errs.ErrInvalidSnapshot, err)
+	}
+
+	candidate := &Candidate{
+		Path:     path,
+		StagedAt: time.Now().UTC(),
+		Counts: map[string]int{
+			"districts": len(state.Districts),
+			"schools":   len(state.Schools),
+			"grades":    len(state.Grades),
+			"classes":   len(state.Classes),
+			"teachers":  len(state.Teachers),
+			"students":  len(state.Students),
+			"tests":     len(state.Tests),
+			"questions": len(state.Questions),
+			"answers":   len(state.Answers),
+			"results":   len(state.Results),
+		},
+		repo: repo,
+	}
+
+	r.mu.Lock()
+	candidate.writes = r.writes
+	r.staged = candidate
+	r.mu.Unlock()
+
+	return candidate, nil
+}
+
+// Staged returns the currently staged candidate, if any.
+func (r *Repository) Staged() *Candidate {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.staged
+}
+
+// Discard drops the staged candidate without touching live data.
+func (r *Repository) Discard() {
+	r.mu.Lock()
+	r.staged = nil
+	r.mu.Unlock()
+}
+
+// Promote persists the staged candidate and swaps it in as the live
+// repository. Readers observe either the old or the new state, never a mix;
+// in lazy mode, answers of a test first read while the files are replaced
+// may come from the new state a moment early. Promotion is refused with
+// ErrStaleCandidate once the live repository was written after staging, as
+// the candidate would silently discard those writes; the candidate stays
+// staged until it is staged again or discarded.
+func (r *Repository) Promote() (*Candidate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	candidate := r.staged
+	if candidate == nil {
+		return nil, errs.ErrNoCandidate
+	}
+	if r.writes != candidate.writes {
+		return nil, errs.ErrStaleCandidate
+	}
+
+	if r.segments == nil {
+		if r.journal != nil {
+			// Fold the journal into the live state file before emptying it,
+			// so a crash before the candidate is written loses nothing and
+			// one after it leaves no journal to replay onto the candidate.
+			if err := writeState(r.path, r.current().ExportState()); err != nil {
+				return nil, err
+			}
+			if err := r.journal.reset(); err != nil {
+				return nil, err
+			}
+		}
+		if err := writeState(r.path, candidate.repo.ExportState()); err != nil {
+			return nil, err
+		}
+		r.delegate.Store(candidate.repo)
+	} else if err := r.storeLazy(candidate.repo.ExportState()); err != nil {
+		return nil, err
+	}
+	r.staged = nil
+
+	return candidate, nil
+}
+
+// smokeTest walks the repository the same way the services do and checks that
+// every stored entity is reachable through the read paths.
+func smokeTest(repo *memory.Repository, state memory.State) error {
+	ctx := context.Background()
+	schools, err := repository.Collect(repo.ListSchools(ctx, repository.All))
+	if err != nil {
+		return err
+	}
+
+	var students, tests, questions, answers, results int
+	for _, school := range schools {
+		grades, err := repository.Collect(repo.ListGrades(ctx, school.ID, repository.All))
+		if err != nil {
+			return err
+		}
+		for _, grade := range grades {
+			classes, err := repository.Collect(repo.ListClasses(ctx, grade.ID, repository.All))
+			if err != nil {
+				return err
+			}
+			for _, class := range classes {
+				list, err := repository.Collect(repo.ListStudents(ctx, class.ID, repository.All))
+				if err != nil {
+					return err
+				}
+				students += len(list)
+			}
+		}
+
+		teachers, err := repository.Collect(repo.ListTeachers(ctx, school.ID, repository.All))
+		if err != nil {
+			return err
+		}
+		for _, teacher := range teachers {
+			list, err := repository.Collect(repo.ListTestsByTeacher(ctx, teacher.ID, repository.All))
+			if err != nil {
+				return err
+			}
+			tests += len(list)
+			for _, test := range list {
+				qs, err := repo.ListQuestions(ctx, test.ID)
+				if err != nil {
+					return err
+				}
+				questions += len(qs)
+
+				as, err := repository.Collect(repo.ListAnswersByTest(ctx, test.ID, repository.All))
+				if err != nil {
+					return err
+				}
+				answers += len(as)
+
+				rs, err := repository.Collect(repo.ListResultsByTest(ctx, test.ID, repository.All))
+				if err != nil {
+					return err
+				}
+				results += len(rs)
+			}
+		}
+	}
+
+	checks := []struct {
+		name      string
+		reachable int
+		stored    int
+	}{
+		{"students", students, len(state.Students)},
+		{"tests", tests, len(state.Tests)},
+		{"questions", questions, len(state.Questions)},
+		{"answers", answers, len(state.Answers)},
+		{"results", results, len(state.Results)},
+	}
+	for _, c := range checks {
+		if c.reachable != c.stored {
+			return fmt.Errorf("smoke query reached %d of %d %s", c.reachable, c.stored, c.name)
+		}
+	}
+	return nil
+}
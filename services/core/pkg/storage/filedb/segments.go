@@ -0,0 +1,352 @@
+package filedb
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/memory"
+)
+
+// Options tune how a store keeps its data in memory.
+type Options struct {
+	// Lazy keeps answers and results in one file per test next to the
+	// state file and loads them when a test is first used. Organization
+	// data, tests and everything else stay loaded. Opening a store in the
+	// other mode than it was written in converts the files.
+	Lazy bool
+	// MaxLoadedTests bounds the tests whose answers stay in memory in lazy
+	// mode; the least recently used are unloaded first. Defaults to 64.
+	MaxLoadedTests int
+	// Journal appends answer and result writes to a write-ahead log next to
+	// the state file instead of rewriting the whole state on each of them.
+	// The log is replayed on open. It needs eager loading.
+	Journal bool
+	// CompactAfter is how many journal entries are written before they are
+	// compacted into the state file. Defaults to 1000.
+	CompactAfter int
+}
+
+const defaultMaxLoadedTests = 64
+
+// segment is the file holding one test's answers and results.
+type segment struct {
+	Answers []domain.Answer `json:"answers"`
+	Results []domain.Result `json:"results"`
+}
+
+// segments tracks the per-test answer files of a lazy store. Loading and
+// unloading take mu exclusively; work on loaded answers shares it, so a
+// test cannot be unloaded while it is in use.
+type segments struct {
+	dir string
+	max int
+
+	mu     sync.RWMutex
+	loaded map[domain.TestID]*atomic.Int64
+
+	// index maps every answer to its test, so lookups by answer ID know
+	// which file to load. It is kept in an append-only file.
+	indexMu sync.RWMutex
+	index   map[domain.AnswerID]domain.TestID
+}
+
+func segmentDir(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".answers"
+}
+
+func (s *segments) file(testID domain.TestID) string {
+	return filepath.Join(s.dir, url.PathEscape(string(testID))+".json")
+}
+
+func (s *segments) indexFile() string {
+	return filepath.Join(s.dir, "index.log")
+}
+
+func openSegments(dir string, max int) (*segments, error) {
+	if max <= 0 {
+		max = defaultMaxLoadedTests
+	}
+	s := &segments{dir: dir, max: max, loaded: make(map[domain.TestID]*atomic.Int64), index: make(map[domain.AnswerID]domain.TestID)}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	file, err := os.Open(s.indexFile())
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		answerID, testID, ok := strings.Cut(scanner.Text(), "\t")
+		if ok {
+			s.index[domain.AnswerID(answerID)] = domain.TestID(testID)
+		}
+	}
+	return s, scanner.Err()
+}
+
+// testOf returns the test of a stored answer.
+func (s *segments) testOf(id domain.AnswerID) (domain.TestID, bool) {
+	s.indexMu.RLock()
+	defer s.indexMu.RUnlock()
+	testID, ok := s.index[id]
+	return testID, ok
+}
+
+// addToIndex records answers that are not indexed yet.
+func (s *segments) addToIndex(answers []domain.Answer) error {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	var lines strings.Builder
+	for _, ans := range answers {
+		if _, ok := s.index[ans.ID]; ok {
+			continue
+		}
+		s.index[ans.ID] = ans.TestID
+		fmt.Fprintf(&lines, "%s\t%s\n", ans.ID, ans.TestID)
+	}
+	if lines.Len() == 0 {
+		return nil
+	}
+	file, err := os.OpenFile(s.indexFile(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(lines.String()); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// use runs fn once the answers of the test are loaded into the live
+// repository, loading them first when needed. current is resolved under the
+// lock, so a repository being replaced is never loaded into.
+func (s *segments) use(current func() *memory.Repository, testID domain.TestID, fn func(*memory.Repository) error) error {
+	s.mu.RLock()
+	if lastUsed, ok := s.loaded[testID]; ok {
+		lastUsed.Store(time.Now().UnixNano())
+		defer s.mu.RUnlock()
+		return fn(current())
+	}
+	s.mu.RUnlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	repo := current()
+	if err := s.load(repo, testID); err != nil {
+		return err
+	}
+	return fn(repo)
+}
+
+// load reads the test's file into repo and unloads the least recently used
+// tests beyond the limit. The caller holds mu exclusively.
+func (s *segments) load(repo *memory.Repository, testID domain.TestID) error {
+	if lastUsed, ok := s.loaded[testID]; ok {
+		lastUsed.Store(time.Now().UnixNano())
+		return nil
+	}
+	seg, err := readSegment(s.file(testID))
+	if err != nil {
+		return fmt.Errorf("filedb: answers of test %s: %w", testID, err)
+	}
+	// Answers written just before a crash may have missed the index.
+	if err := s.addToIndex(seg.Answers); err != nil {
+		return err
+	}
+	repo.LoadAnswers(seg.Answers, seg.Results)
+	lastUsed := new(atomic.Int64)
+	lastUsed.Store(time.Now().UnixNano())
+	s.loaded[testID] = lastUsed
+
+	for len(s.loaded) > s.max {
+		var oldest domain.TestID
+		var oldestAt int64
+		for id, at := range s.loaded {
+			if id == testID {
+				continue
+			}
+			if v := at.Load(); oldest == "" || v < oldestAt {
+				oldest, oldestAt = id, v
+			}
+		}
+		repo.UnloadAnswers(oldest)
+		delete(s.loaded, oldest)
+	}
+	return nil
+}
+
+// save writes the test's answers and results as they are in repo. The
+// caller holds mu, so the test stays loaded.
+func (s *segments) save(repo *memory.Repository, testID domain.TestID) error {
+	answers, results := repo.ExportAnswers(testID)
+	if err := writeJSON(s.file(testID), segment{Answers: answers, Results: results}); err != nil {
+		return err
+	}
+	return s.addToIndex(answers)
+}
+
+// replace runs swap, which installs a repository without answers, once no
+// test is in use, then forgets the loaded tests and reads the index again.
+// The caller has already replaced the files.
+func (s *segments) replace(swap func()) error {
+	fresh, err := openSegments(s.dir, s.max)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	swap()
+	s.loaded = fresh.loaded
+	s.indexMu.Lock()
+	s.index = fresh.index
+	s.indexMu.Unlock()
+	return nil
+}
+
+// reindex rebuilds the answer index from the files of every test, dropping
+// entries of answers that are gone and adding ones that were missed.
+func (s *segments) reindex() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	answers, _, err := readAllSegments(s.dir)
+	if err != nil {
+		return err
+	}
+	index := make(map[domain.AnswerID]domain.TestID, len(answers))
+	var lines strings.Builder
+	for _, ans := range answers {
+		index[ans.ID] = ans.TestID
+		fmt.Fprintf(&lines, "%s\t%s\n", ans.ID, ans.TestID)
+	}
+	staging := s.indexFile() + ".tmp"
+	if err := os.WriteFile(staging, []byte(lines.String()), 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(staging, s.indexFile()); err != nil {
+		return err
+	}
+	s.indexMu.Lock()
+	s.index = index
+	s.indexMu.Unlock()
+	return nil
+}
+
+// readAllSegments returns every answer and result stored in dir.
+func readAllSegments(dir string) ([]domain.Answer, []domain.Result, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	var answers []domain.Answer
+	var results []domain.Result
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		seg, err := readSegment(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, nil, fmt.Errorf("filedb: %s: %w", entry.Name(), err)
+		}
+		answers = append(answers, seg.Answers...)
+		results = append(results, seg.Results...)
+	}
+	return answers, results, nil
+}
+
+// writeSegments replaces dir with one file per test holding the given
+// answers and results, plus their index. The files are written to a new
+// directory first, so a failure leaves dir as it was.
+func writeSegments(dir string, answers []domain.Answer, results []domain.Result) error {
+	byTest := make(map[domain.TestID]*segment)
+	testOf := make(map[domain.AnswerID]domain.TestID, len(answers))
+	for _, ans := range answers {
+		seg, ok := byTest[ans.TestID]
+		if !ok {
+			seg = &segment{Results: []domain.Result{}}
+			byTest[ans.TestID] = seg
+		}
+		seg.Answers = append(seg.Answers, ans)
+		testOf[ans.ID] = ans.TestID
+	}
+	for _, res := range results {
+		testID, ok := testOf[res.AnswerID]
+		if !ok {
+			return fmt.Errorf("filedb: result %s references unknown answer %s", res.ID, res.AnswerID)
+		}
+		byTest[testID].Results = append(byTest[testID].Results, res)
+	}
+
+	tmp := dir + ".tmp"
+	if err := os.RemoveAll(tmp); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(tmp, 0o755); err != nil {
+		return err
+	}
+	staging := &segments{dir: tmp}
+	for testID, seg := range byTest {
+		if err := writeJSON(staging.file(testID), seg); err != nil {
+			return err
+		}
+	}
+	ids := make([]string, 0, len(testOf))
+	for id := range testOf {
+		ids = append(ids, string(id))
+	}
+	sort.Strings(ids)
+	var index strings.Builder
+	for _, id := range ids {
+		fmt.Fprintf(&index, "%s\t%s\n", id, testOf[domain.AnswerID(id)])
+	}
+	if err := os.WriteFile(staging.indexFile(), []byte(index.String()), 0o644); err != nil {
+		return err
+	}
+
+	old := dir + ".old"
+	if err := os.RemoveAll(old); err != nil {
+		return err
+	}
+	if err := os.Rename(dir, old); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		return err
+	}
+	return os.RemoveAll(old)
+}
+
+func readSegment(path string) (segment, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return segment{}, nil
+	}
+	if err != nil {
+		return segment{}, err
+	}
+	var seg segment
+	if err := json.Unmarshal(data, &seg); err != nil {
+		return segment{}, err
+	}
+	return seg, nil
+}
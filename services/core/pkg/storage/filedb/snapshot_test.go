@@ -0,0 +1,72 @@
+package filedb_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/storage/filedb"
+)
+
+func TestSnapshotGradingIsConsistentDuringGrading(t *testing.T) {
+	ctx := context.Background()
+	for name, opts := range map[string]filedb.Options{"eager": {}, "lazy": {Lazy: true, MaxLoadedTests: 1}} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "state.json")
+			repo, err := filedb.Open(path, fixtures.NewSchool().WithStudents(1).Seed(), opts)
+			if err != nil {
+				t.Fatalf("Open failed: %v", err)
+			}
+			now := time.Now().UTC()
+			test := &domain.Test{ID: "test-001", TeacherID: "teacher-001", Title: "Quiz", CreatedAt: now, UpdatedAt: now}
+			var questions []domain.Question
+			for i := 1; i <= 20; i++ {
+				questions = append(questions, domain.Question{ID: domain.QuestionID(fmt.Sprintf("question-%03d", i)), TestID: test.ID, Sequence: i, Prompt: "?", Points: 1, CreatedAt: now})
+			}
+			if err := repo.CreateTest(ctx, test, questions, []domain.StudentID{"student-001"}); err != nil {
+				t.Fatalf("CreateTest failed: %v", err)
+			}
+
+			grading := make(chan struct{})
+			go func() {
+				defer close(grading)
+				for i, q := range questions {
+					answer := &domain.Answer{ID: domain.AnswerID(fmt.Sprintf("answer-%03d", i)), TestID: test.ID, QuestionID: q.ID, StudentID: "student-001", Response: "x", CreatedAt: now}
+					if err := repo.UpsertAnswer(ctx, answer); err != nil {
+						t.Errorf("UpsertAnswer failed: %v", err)
+						return
+					}
+					if err := repo.SaveResult(ctx, &domain.Result{ID: domain.ResultID(fmt.Sprintf("result-%03d", i)), AnswerID: answer.ID, Score: 1, CreatedAt: now}); err != nil {
+						t.Errorf("SaveResult failed: %v", err)
+						return
+					}
+				}
+			}()
+
+			for {
+				snapshot, err := repo.SnapshotGrading(ctx, test.ID)
+				if err != nil {
+					t.Fatalf("SnapshotGrading failed: %v", err)
+				}
+				answered := make(map[domain.AnswerID]bool, len(snapshot.Answers))
+				for _, ans := range snapshot.Answers {
+					answered[ans.ID] = true
+				}
+				for _, res := range snapshot.Results {
+					if !answered[res.AnswerID] {
+						t.Fatalf("snapshot holds result %s without its answer", res.ID)
+					}
+				}
+				select {
+				case <-grading:
+					return
+				default:
+				}
+			}
+		})
+	}
+}
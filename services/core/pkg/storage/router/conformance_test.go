@@ -0,0 +1,29 @@
+package router_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/memory"
+	"github.com/sky0621/go_work_sample/core/pkg/repository/repotest"
+	"github.com/sky0621/go_work_sample/core/pkg/storage/filedb"
+	"github.com/sky0621/go_work_sample/core/pkg/storage/router"
+)
+
+// TestConformance keeps every seeded school in a file of its own, so each
+// case crosses from the shared store to a school's.
+func TestConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T, seed memory.SeedData) repotest.Store {
+		dir := t.TempDir()
+		schools := make(map[domain.SchoolID]string, len(seed.Schools))
+		for _, school := range seed.Schools {
+			schools[school.ID] = filepath.Join(dir, string(school.ID)+".json")
+		}
+		repo, _, err := router.OpenFiles(filepath.Join(dir, "shared.json"), schools, seed, filedb.Options{})
+		if err != nil {
+			t.Fatalf("OpenFiles failed: %v", err)
+		}
+		return repo
+	})
+}
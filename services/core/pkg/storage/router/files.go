@@ -0,0 +1,95 @@
+package router
+
+import (
+	"fmt"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/memory"
+	"github.com/sky0621/go_work_sample/core/pkg/storage/filedb"
+)
+
+// OpenFiles opens a JSON file store at sharedPath and one per school listed
+// in schoolPaths, and routes between them. New files are seeded with their
+// part of seed: a school's grades, classes, teachers and students go to its
+// own file and the rest to the shared one. Records already in the shared
+// file are not moved when a school later gets a file of its own.
+//
+// Every file is opened with opts.
+//
+// The shared store is returned as well for features that work on one file,
+// such as backups and snapshot promotion.
+func OpenFiles(sharedPath string, schoolPaths map[domain.SchoolID]string, seed memory.SeedData, opts filedb.Options) (*Router, *filedb.Repository, error) {
+	schools := make(map[domain.SchoolID]bool, len(schoolPaths))
+	for id := range schoolPaths {
+		schools[id] = true
+	}
+	sharedSeed, schoolSeeds := splitSeed(seed, schools)
+
+	shared, err := filedb.Open(sharedPath, sharedSeed, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	stores := make(map[domain.SchoolID]Store, len(schoolPaths))
+	for id, path := range schoolPaths {
+		if path == sharedPath {
+			return nil, nil, fmt.Errorf("router: school %s must not use the shared store path", id)
+		}
+		store, err := filedb.Open(path, schoolSeeds[id], opts)
+		if err != nil {
+			return nil, nil, fmt.Errorf("router: school %s: %w", id, err)
+		}
+		stores[id] = store
+	}
+	return New(shared, stores), shared, nil
+}
+
+// splitSeed separates the organization data of the given schools from the
+// rest. School records stay in the shared seed, which is the directory.
+func splitSeed(seed memory.SeedData, schools map[domain.SchoolID]bool) (memory.SeedData, map[domain.SchoolID]memory.SeedData) {
+	shared := memory.SeedData{Schools: seed.Schools}
+	perSchool := make(map[domain.SchoolID]memory.SeedData, len(schools))
+
+	gradeSchool := make(map[domain.GradeID]domain.SchoolID, len(seed.Grades))
+	for _, g := range seed.Grades {
+		gradeSchool[g.ID] = g.SchoolID
+		if schools[g.SchoolID] {
+			s := perSchool[g.SchoolID]
+			s.Grades = append(s.Grades, g)
+			perSchool[g.SchoolID] = s
+		} else {
+			shared.Grades = append(shared.Grades, g)
+		}
+	}
+	classSchool := make(map[domain.ClassID]domain.SchoolID, len(seed.Classes))
+	for _, c := range seed.Classes {
+		id := gradeSchool[c.GradeID]
+		classSchool[c.ID] = id
+		if schools[id] {
+			s := perSchool[id]
+			s.Classes = append(s.Classes, c)
+			perSchool[id] = s
+		} else {
+			shared.Classes = append(shared.Classes, c)
+		}
+	}
+	for _, t := range seed.Teachers {
+		if schools[t.SchoolID] {
+			s := perSchool[t.SchoolID]
+			s.Teachers = append(s.Teachers, t)
+			perSchool[t.SchoolID] = s
+		} else {
+			shared.Teachers = append(shared.Teachers, t)
+		}
+	}
+	for _, st := range seed.Students {
+		id := classSchool[st.ClassID]
+		if schools[id] {
+			s := perSchool[id]
+			s.Students = append(s.Students, st)
+			perSchool[id] = s
+		} else {
+			shared.Students = append(shared.Students, st)
+		}
+	}
+	return shared, perSchool
+}
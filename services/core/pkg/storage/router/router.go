@@ -0,0 +1,965 @@
+// Package router spreads data across backing stores by school, so schools
+// with data residency requirements keep their records physically apart while
+// sharing one deployment.
+package router
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/memory"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// Store is a backing store the router routes to. Every store has the same
+// schema; the router decides which records each one holds.
+type Store interface {
+	repository.OrganizationRepository
+	repository.TestRepository
+	repository.AnswerRepository
+	repository.ResultRepository
+	repository.NotificationRepository
+	repository.DigestRepository
+	repository.QuestionCommentRepository
+	repository.AnswerCommentRepository
+	repository.TestSessionRepository
+	repository.SubmissionRepository
+	repository.RubricRepository
+	repository.DelegationRepository
+	repository.DeviceSessionRepository
+	repository.QuestionBankRepository
+	repository.DistrictRepository
+	repository.AuditRepository
+
+	// HasAnswer reports whether the answer is stored here. Results are kept
+	// with their answer.
+	HasAnswer(ctx context.Context, id domain.AnswerID) (bool, error)
+	// ExportState returns a snapshot of the store's data, with the error of
+	// any part that could not be read.
+	ExportState() (memory.State, error)
+}
+
+// SnapshotStore is a Store that streams a backup of its data, as both the
+// file and the SQLite stores do.
+type SnapshotStore interface {
+	Store
+	Snapshot(ctx context.Context, w io.Writer) error
+}
+
+// Reindexer is a store that rebuilds its lookup indexes on demand, as both
+// the file and the SQLite stores do.
+type Reindexer interface {
+	Reindex(ctx context.Context) error
+}
+
+// Router implements the repository interfaces over a shared store and
+// dedicated per-school stores. The shared store holds the school directory,
+// districts and their staff, and everything of schools without a dedicated
+// store. A dedicated store holds its school's grades, classes, teachers and
+// students and every record hanging off them: tests, answers, results,
+// sessions, comments, rubrics, bank questions, delegations, device sessions,
+// notifications, queued digest items and audit entries.
+//
+// Records looked up by ID are found by asking each store in turn, which
+// relies on IDs being unique across stores as generated IDs are. Writes
+// follow the record's owner: a test goes to its teacher's store, an answer
+// to its test's store and so on. Lists spanning stores are merged in
+// creation order.
+type Router struct {
+	shared  Store
+	schools map[domain.SchoolID]Store
+	// stores lists every distinct store, dedicated ones first.
+	stores []Store
+}
+
+// Ensure interface compliance.
+var (
+	_ repository.OrganizationRepository    = (*Router)(nil)
+	_ repository.TestRepository            = (*Router)(nil)
+	_ repository.AnswerRepository          = (*Router)(nil)
+	_ repository.ResultRepository          = (*Router)(nil)
+	_ repository.NotificationRepository    = (*Router)(nil)
+	_ repository.DigestRepository          = (*Router)(nil)
+	_ repository.QuestionCommentRepository = (*Router)(nil)
+	_ repository.AnswerCommentRepository   = (*Router)(nil)
+	_ repository.TestSessionRepository     = (*Router)(nil)
+	_ repository.SubmissionRepository      = (*Router)(nil)
+	_ repository.RubricRepository          = (*Router)(nil)
+	_ repository.DelegationRepository      = (*Router)(nil)
+	_ repository.DeviceSessionRepository   = (*Router)(nil)
+	_ repository.QuestionBankRepository    = (*Router)(nil)
+	_ repository.DistrictRepository        = (*Router)(nil)
+	_ repository.AuditRepository           = (*Router)(nil)
+)
+
+// New routes the schools listed in schools to their own store and every
+// other school to shared.
+func New(shared Store, schools map[domain.SchoolID]Store) *Router {
+	r := &Router{shared: shared, schools: make(map[domain.SchoolID]Store, len(schools))}
+
+	ids := make([]domain.SchoolID, 0, len(schools))
+	for id := range schools {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	seen := map[Store]bool{shared: true}
+	for _, id := range ids {
+		store := schools[id]
+		r.schools[id] = store
+		if !seen[store] {
+			seen[store] = true
+			r.stores = append(r.stores, store)
+		}
+	}
+	r.stores = append(r.stores, shared)
+	return r
+}
+
+// Reindex rebuilds the indexes of every store that keeps any.
+func (r *Router) Reindex(ctx context.Context) error {
+	var failed []error
+	for _, s := range r.stores {
+		if reindexer, ok := s.(Reindexer); ok {
+			if err := reindexer.Reindex(ctx); err != nil {
+				failed = append(failed, err)
+			}
+		}
+	}
+	return errors.Join(failed...)
+}
+
+// Sandbox returns an in-memory copy of the data of every store. Writes to
+// the copy never reach the stores.
+func (r *Router) Sandbox() (*memory.Repository, error) {
+	var merged memory.State
+	merged.Assignments = make(map[string][]domain.StudentID)
+	for _, s := range r.stores {
+		state, err := s.ExportState()
+		if err != nil {
+			return nil, err
+		}
+		merged.Schools = append(merged.Schools, state.Schools...)
+		merged.Grades = append(merged.Grades, state.Grades...)
+		merged.Classes = append(merged.Classes, state.Classes...)
+		merged.Teachers = append(merged.Teachers, state.Teachers...)
+		merged.Students = append(merged.Students, state.Students...)
+		merged.Tests = append(merged.Tests, state.Tests...)
+		merged.Questions = append(merged.Questions, state.Questions...)
+		for testID, students := range state.Assignments {
+			merged.Assignments[testID] = append(merged.Assignments[testID], students...)
+		}
+		merged.Answers = append(merged.Answers, state.Answers...)
+		merged.Results = append(merged.Results, state.Results...)
+		merged.Notifications = append(merged.Notifications, state.Notifications...)
+		merged.Digests = append(merged.Digests, state.Digests...)
+		merged.Comments = append(merged.Comments, state.Comments...)
+		merged.Feedback = append(merged.Feedback, state.Feedback...)
+		merged.Sessions = append(merged.Sessions, state.Sessions...)
+		merged.Submissions = append(merged.Submissions, state.Submissions...)
+		merged.Rubrics = append(merged.Rubrics, state.Rubrics...)
+		merged.Templates = append(merged.Templates, state.Templates...)
+		merged.Delegations = append(merged.Delegations, state.Delegations...)
+		merged.Devices = append(merged.Devices, state.Devices...)
+		merged.BankQuestions = append(merged.BankQuestions, state.BankQuestions...)
+		merged.Districts = append(merged.Districts, state.Districts...)
+		merged.DistrictStaff = append(merged.DistrictStaff, state.DistrictStaff...)
+		merged.Audit = append(merged.Audit, state.Audit...)
+	}
+	return memory.NewRepositoryFromState(merged), nil
+}
+
+// probe asks each store for a record and returns the first store that has
+// it. When none does it returns the shared store and a nil record, so the
+// caller's usual not-found handling applies.
+func probe[T any](r *Router, get func(Store) (*T, error)) (Store, *T, error) {
+	for _, s := range r.stores {
+		v, err := get(s)
+		if err != nil {
+			return nil, nil, err
+		}
+		if v != nil {
+			return s, v, nil
+		}
+	}
+	return r.shared, nil, nil
+}
+
+// gather concatenates a list from every store in creation order.
+func gather[T any, ID ~string](r *Router, list func(Store) ([]T, error), key func(T) (time.Time, ID)) ([]T, error) {
+	out := make([]T, 0)
+	for _, s := range r.stores {
+		items, err := list(s)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, items...)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		ti, idi := key(out[i])
+		tj, idj := key(out[j])
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return idi < idj
+	})
+	return out, nil
+}
+
+func (r *Router) forSchool(id domain.SchoolID) Store {
+	if s, ok := r.schools[id]; ok {
+		return s
+	}
+	return r.shared
+}
+
+func (r *Router) forGrade(ctx context.Context, id domain.GradeID) (Store, error) {
+	s, _, err := probe(r, func(s Store) (*domain.Grade, error) { return s.GetGrade(ctx, id) })
+	return s, err
+}
+
+func (r *Router) forClass(ctx context.Context, id domain.ClassID) (Store, error) {
+	s, _, err := probe(r, func(s Store) (*domain.Class, error) { return s.GetClass(ctx, id) })
+	return s, err
+}
+
+func (r *Router) forTeacher(ctx context.Context, id domain.TeacherID) (Store, error) {
+	s, _, err := probe(r, func(s Store) (*domain.Teacher, error) { return s.GetTeacher(ctx, id) })
+	return s, err
+}
+
+func (r *Router) forStudent(ctx context.Context, id domain.StudentID) (Store, error) {
+	s, _, err := probe(r, func(s Store) (*domain.Student, error) { return s.GetStudent(ctx, id) })
+	return s, err
+}
+
+func (r *Router) forTest(ctx context.Context, id domain.TestID) (Store, error) {
+	s, _, err := probe(r, func(s Store) (*domain.Test, error) { return s.GetTest(ctx, id) })
+	return s, err
+}
+
+func (r *Router) forAnswer(ctx context.Context, id domain.AnswerID) (Store, error) {
+	for _, s := range r.stores {
+		ok, err := s.HasAnswer(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return s, nil
+		}
+	}
+	return r.shared, nil
+}
+
+// forRecipient returns the store of a notification recipient. Guardians'
+// notifications are addressed to their student.
+func (r *Router) forRecipient(ctx context.Context, role domain.Role, recipientID string) (Store, error) {
+	switch role {
+	case domain.RoleTeacher:
+		return r.forTeacher(ctx, domain.TeacherID(recipientID))
+	case domain.RoleStudent, domain.RoleGuardian:
+		return r.forStudent(ctx, domain.StudentID(recipientID))
+	default:
+		return r.shared, nil
+	}
+}
+
+// OrganizationRepository routing. Schools themselves live in the shared
+// directory.
+
+func (r *Router) ListSchools(ctx context.Context, page repository.PageRequest) (repository.Page[domain.School], error) {
+	return r.shared.ListSchools(ctx, page)
+}
+
+func (r *Router) GetSchool(ctx context.Context, id domain.SchoolID) (*domain.School, error) {
+	return r.shared.GetSchool(ctx, id)
+}
+
+func (r *Router) GetGrade(ctx context.Context, id domain.GradeID) (*domain.Grade, error) {
+	_, g, err := probe(r, func(s Store) (*domain.Grade, error) { return s.GetGrade(ctx, id) })
+	return g, err
+}
+
+func (r *Router) GetClass(ctx context.Context, id domain.ClassID) (*domain.Class, error) {
+	_, c, err := probe(r, func(s Store) (*domain.Class, error) { return s.GetClass(ctx, id) })
+	return c, err
+}
+
+func (r *Router) GetTeacher(ctx context.Context, id domain.TeacherID) (*domain.Teacher, error) {
+	_, t, err := probe(r, func(s Store) (*domain.Teacher, error) { return s.GetTeacher(ctx, id) })
+	return t, err
+}
+
+func (r *Router) GetStudent(ctx context.Context, id domain.StudentID) (*domain.Student, error) {
+	_, st, err := probe(r, func(s Store) (*domain.Student, error) { return s.GetStudent(ctx, id) })
+	return st, err
+}
+
+func (r *Router) FindStudentsByEmail(ctx context.Context, email string) ([]domain.Student, error) {
+	return gather(r, func(s Store) ([]domain.Student, error) { return s.FindStudentsByEmail(ctx, email) },
+		func(st domain.Student) (time.Time, domain.StudentID) { return st.CreatedAt, st.ID })
+}
+
+func (r *Router) ListGrades(ctx context.Context, schoolID domain.SchoolID, page repository.PageRequest) (repository.Page[domain.Grade], error) {
+	return r.forSchool(schoolID).ListGrades(ctx, schoolID, page)
+}
+
+func (r *Router) ListClasses(ctx context.Context, gradeID domain.GradeID, page repository.PageRequest) (repository.Page[domain.Class], error) {
+	s, err := r.forGrade(ctx, gradeID)
+	if err != nil {
+		return repository.Page[domain.Class]{}, err
+	}
+	return s.ListClasses(ctx, gradeID, page)
+}
+
+func (r *Router) ListStudents(ctx context.Context, classID domain.ClassID, page repository.PageRequest) (repository.Page[domain.Student], error) {
+	s, err := r.forClass(ctx, classID)
+	if err != nil {
+		return repository.Page[domain.Student]{}, err
+	}
+	return s.ListStudents(ctx, classID, page)
+}
+
+func (r *Router) ListTeachers(ctx context.Context, schoolID domain.SchoolID, page repository.PageRequest) (repository.Page[domain.Teacher], error) {
+	return r.forSchool(schoolID).ListTeachers(ctx, schoolID, page)
+}
+
+func (r *Router) UpdateSchool(ctx context.Context, school *domain.School) error {
+	return r.shared.UpdateSchool(ctx, school)
+}
+
+func (r *Router) UpdateTeacher(ctx context.Context, teacher *domain.Teacher) error {
+	s, err := r.forTeacher(ctx, teacher.ID)
+	if err != nil {
+		return err
+	}
+	return s.UpdateTeacher(ctx, teacher)
+}
+
+func (r *Router) UpdateStudent(ctx context.Context, student *domain.Student) error {
+	s, err := r.forStudent(ctx, student.ID)
+	if err != nil {
+		return err
+	}
+	return s.UpdateStudent(ctx, student)
+}
+
+func (r *Router) CreateStudents(ctx context.Context, classID domain.ClassID, students []domain.Student) error {
+	s, err := r.forClass(ctx, classID)
+	if err != nil {
+		return err
+	}
+	return s.CreateStudents(ctx, classID, students)
+}
+
+// TestRepository routing. Tests live with their teacher.
+
+func (r *Router) CreateTest(ctx context.Context, test *domain.Test, questions []domain.Question, studentIDs []domain.StudentID) error {
+	s, err := r.forTeacher(ctx, test.TeacherID)
+	if err != nil {
+		return err
+	}
+	return s.CreateTest(ctx, test, questions, studentIDs)
+}
+
+func (r *Router) UpdateTest(ctx context.Context, test *domain.Test) error {
+	s, err := r.forTest(ctx, test.ID)
+	if err != nil {
+		return err
+	}
+	return s.UpdateTest(ctx, test)
+}
+
+func (r *Router) UpdateAssignments(ctx context.Context, testID domain.TestID, add, remove []domain.StudentID) error {
+	s, err := r.forTest(ctx, testID)
+	if err != nil {
+		return err
+	}
+	return s.UpdateAssignments(ctx, testID, add, remove)
+}
+
+func (r *Router) UpdateQuestion(ctx context.Context, question *domain.Question) error {
+	s, err := r.forTest(ctx, question.TestID)
+	if err != nil {
+		return err
+	}
+	return s.UpdateQuestion(ctx, question)
+}
+
+func (r *Router) ReorderQuestions(ctx context.Context, testID domain.TestID, sequences map[domain.QuestionID]int) error {
+	s, err := r.forTest(ctx, testID)
+	if err != nil {
+		return err
+	}
+	return s.ReorderQuestions(ctx, testID, sequences)
+}
+
+func (r *Router) GetTest(ctx context.Context, id domain.TestID) (*domain.Test, error) {
+	_, t, err := probe(r, func(s Store) (*domain.Test, error) { return s.GetTest(ctx, id) })
+	return t, err
+}
+
+func (r *Router) ListTestsByTeacher(ctx context.Context, teacherID domain.TeacherID, page repository.PageRequest) (repository.Page[domain.Test], error) {
+	s, err := r.forTeacher(ctx, teacherID)
+	if err != nil {
+		return repository.Page[domain.Test]{}, err
+	}
+	return s.ListTestsByTeacher(ctx, teacherID, page)
+}
+
+func (r *Router) ListTestsForStudent(ctx context.Context, studentID domain.StudentID, page repository.PageRequest) (repository.Page[domain.Test], error) {
+	s, err := r.forStudent(ctx, studentID)
+	if err != nil {
+		return repository.Page[domain.Test]{}, err
+	}
+	return s.ListTestsForStudent(ctx, studentID, page)
+}
+
+func (r *Router) ListTestsInWindow(ctx context.Context, from, to time.Time) ([]domain.Test, error) {
+	return gather(r, func(s Store) ([]domain.Test, error) { return s.ListTestsInWindow(ctx, from, to) },
+		func(t domain.Test) (time.Time, domain.TestID) { return t.CreatedAt, t.ID })
+}
+
+func (r *Router) ListQuestions(ctx context.Context, testID domain.TestID) ([]domain.Question, error) {
+	s, err := r.forTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	return s.ListQuestions(ctx, testID)
+}
+
+func (r *Router) GetQuestion(ctx context.Context, testID domain.TestID, questionID domain.QuestionID) (*domain.Question, error) {
+	s, err := r.forTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetQuestion(ctx, testID, questionID)
+}
+
+func (r *Router) HasQuestion(ctx context.Context, testID domain.TestID, questionID domain.QuestionID) (bool, error) {
+	s, err := r.forTest(ctx, testID)
+	if err != nil {
+		return false, err
+	}
+	return s.HasQuestion(ctx, testID, questionID)
+}
+
+func (r *Router) IsStudentAssigned(ctx context.Context, testID domain.TestID, studentID domain.StudentID) (bool, error) {
+	s, err := r.forTest(ctx, testID)
+	if err != nil {
+		return false, err
+	}
+	return s.IsStudentAssigned(ctx, testID, studentID)
+}
+
+// AnswerRepository routing. Answers live with their test.
+
+func (r *Router) UpsertAnswer(ctx context.Context, answer *domain.Answer) error {
+	s, err := r.forTest(ctx, answer.TestID)
+	if err != nil {
+		return err
+	}
+	return s.UpsertAnswer(ctx, answer)
+}
+
+func (r *Router) DeleteAnswer(ctx context.Context, testID domain.TestID, questionID domain.QuestionID, studentID domain.StudentID) error {
+	s, err := r.forTest(ctx, testID)
+	if err != nil {
+		return err
+	}
+	return s.DeleteAnswer(ctx, testID, questionID, studentID)
+}
+
+func (r *Router) GetAnswer(ctx context.Context, testID domain.TestID, questionID domain.QuestionID, studentID domain.StudentID) (*domain.Answer, error) {
+	s, err := r.forTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetAnswer(ctx, testID, questionID, studentID)
+}
+
+func (r *Router) ListAnswers(ctx context.Context, testID domain.TestID, studentID domain.StudentID) ([]domain.Answer, error) {
+	s, err := r.forTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	return s.ListAnswers(ctx, testID, studentID)
+}
+
+func (r *Router) ListAnswersByTest(ctx context.Context, testID domain.TestID, page repository.PageRequest) (repository.Page[domain.Answer], error) {
+	s, err := r.forTest(ctx, testID)
+	if err != nil {
+		return repository.Page[domain.Answer]{}, err
+	}
+	return s.ListAnswersByTest(ctx, testID, page)
+}
+
+func (r *Router) HasAnswer(ctx context.Context, id domain.AnswerID) (bool, error) {
+	for _, s := range r.stores {
+		if ok, err := s.HasAnswer(ctx, id); err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// ResultRepository routing. Results live with their answer.
+
+func (r *Router) SaveResult(ctx context.Context, result *domain.Result) error {
+	s, err := r.forAnswer(ctx, result.AnswerID)
+	if err != nil {
+		return err
+	}
+	return s.SaveResult(ctx, result)
+}
+
+// SaveResults saves the results of each store together. Results of answers
+// in different stores are not saved atomically across stores, but a batch
+// with a result of an unknown answer is refused before any store is written.
+func (r *Router) SaveResults(ctx context.Context, results []domain.Result) error {
+	var order []Store
+	byStore := make(map[Store][]domain.Result)
+	for _, res := range results {
+		s, err := r.forAnswer(ctx, res.AnswerID)
+		if err != nil {
+			return err
+		}
+		if s == r.shared {
+			ok, err := s.HasAnswer(ctx, res.AnswerID)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return errors.New("router: answer not found")
+			}
+		}
+		if _, ok := byStore[s]; !ok {
+			order = append(order, s)
+		}
+		byStore[s] = append(byStore[s], res)
+	}
+	for _, s := range order {
+		if err := s.SaveResults(ctx, byStore[s]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Router) GetResult(ctx context.Context, answerID domain.AnswerID) (*domain.Result, error) {
+	_, res, err := probe(r, func(s Store) (*domain.Result, error) { return s.GetResult(ctx, answerID) })
+	return res, err
+}
+
+func (r *Router) ListResultsByTest(ctx context.Context, testID domain.TestID, page repository.PageRequest) (repository.Page[domain.Result], error) {
+	s, err := r.forTest(ctx, testID)
+	if err != nil {
+		return repository.Page[domain.Result]{}, err
+	}
+	return s.ListResultsByTest(ctx, testID, page)
+}
+
+func (r *Router) ListResultsByStudent(ctx context.Context, testID domain.TestID, studentID domain.StudentID) ([]domain.Result, error) {
+	s, err := r.forTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	return s.ListResultsByStudent(ctx, testID, studentID)
+}
+
+func (r *Router) SnapshotGrading(ctx context.Context, testID domain.TestID) (repository.GradingSnapshot, error) {
+	s, err := r.forTest(ctx, testID)
+	if err != nil {
+		return repository.GradingSnapshot{}, err
+	}
+	return s.SnapshotGrading(ctx, testID)
+}
+
+// NotificationRepository routing. Notifications live with their recipient.
+
+func (r *Router) SaveNotification(ctx context.Context, notification *domain.Notification) error {
+	s, err := r.forRecipient(ctx, notification.Role, notification.RecipientID)
+	if err != nil {
+		return err
+	}
+	return s.SaveNotification(ctx, notification)
+}
+
+func (r *Router) ListNotifications(ctx context.Context, role domain.Role, recipientID string, page repository.PageRequest) (repository.Page[domain.Notification], error) {
+	s, err := r.forRecipient(ctx, role, recipientID)
+	if err != nil {
+		return repository.Page[domain.Notification]{}, err
+	}
+	return s.ListNotifications(ctx, role, recipientID, page)
+}
+
+func (r *Router) MarkNotificationsRead(ctx context.Context, role domain.Role, recipientID string, ids []domain.NotificationID, at time.Time) error {
+	s, err := r.forRecipient(ctx, role, recipientID)
+	if err != nil {
+		return err
+	}
+	return s.MarkNotificationsRead(ctx, role, recipientID, ids, at)
+}
+
+// DigestRepository routing. Queued digest items live with their recipient.
+
+func (r *Router) QueueDigestItem(ctx context.Context, item *domain.DigestItem) error {
+	s, err := r.forRecipient(ctx, item.Role, item.RecipientID)
+	if err != nil {
+		return err
+	}
+	return s.QueueDigestItem(ctx, item)
+}
+
+func (r *Router) ListDigestItems(ctx context.Context) ([]domain.DigestItem, error) {
+	return gather(r, func(s Store) ([]domain.DigestItem, error) { return s.ListDigestItems(ctx) },
+		func(item domain.DigestItem) (time.Time, domain.DigestItemID) { return item.OccurredAt, item.ID })
+}
+
+// DeleteDigestItems removes the items from every store, which ignore the IDs
+// they do not hold.
+func (r *Router) DeleteDigestItems(ctx context.Context, ids []domain.DigestItemID) error {
+	for _, s := range r.stores {
+		if err := s.DeleteDigestItems(ctx, ids); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// QuestionCommentRepository routing. Comments live with their test.
+
+func (r *Router) SaveQuestionComment(ctx context.Context, comment *domain.QuestionComment) error {
+	s, err := r.forTest(ctx, comment.TestID)
+	if err != nil {
+		return err
+	}
+	return s.SaveQuestionComment(ctx, comment)
+}
+
+func (r *Router) GetQuestionComment(ctx context.Context, id domain.QuestionCommentID) (*domain.QuestionComment, error) {
+	_, c, err := probe(r, func(s Store) (*domain.QuestionComment, error) { return s.GetQuestionComment(ctx, id) })
+	return c, err
+}
+
+func (r *Router) ListQuestionComments(ctx context.Context, testID domain.TestID, questionID domain.QuestionID) ([]domain.QuestionComment, error) {
+	s, err := r.forTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	return s.ListQuestionComments(ctx, testID, questionID)
+}
+
+// AnswerCommentRepository routing. Comments live with their test.
+
+func (r *Router) SaveAnswerComment(ctx context.Context, comment *domain.AnswerComment) error {
+	s, err := r.forTest(ctx, comment.TestID)
+	if err != nil {
+		return err
+	}
+	return s.SaveAnswerComment(ctx, comment)
+}
+
+func (r *Router) GetAnswerComment(ctx context.Context, id domain.AnswerCommentID) (*domain.AnswerComment, error) {
+	_, c, err := probe(r, func(s Store) (*domain.AnswerComment, error) { return s.GetAnswerComment(ctx, id) })
+	return c, err
+}
+
+func (r *Router) ListAnswerComments(ctx context.Context, testID domain.TestID, answerID domain.AnswerID) ([]domain.AnswerComment, error) {
+	s, err := r.forTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	return s.ListAnswerComments(ctx, testID, answerID)
+}
+
+func (r *Router) ListAnswerCommentsByStudent(ctx context.Context, studentID domain.StudentID) ([]domain.AnswerComment, error) {
+	return gather(r, func(s Store) ([]domain.AnswerComment, error) { return s.ListAnswerCommentsByStudent(ctx, studentID) },
+		func(c domain.AnswerComment) (time.Time, domain.AnswerCommentID) { return c.CreatedAt, c.ID })
+}
+
+// TestSessionRepository routing. Sessions live with their test.
+
+func (r *Router) GetTestSession(ctx context.Context, testID domain.TestID, studentID domain.StudentID) (*domain.TestSession, error) {
+	s, err := r.forTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetTestSession(ctx, testID, studentID)
+}
+
+func (r *Router) SaveTestSession(ctx context.Context, session *domain.TestSession) error {
+	s, err := r.forTest(ctx, session.TestID)
+	if err != nil {
+		return err
+	}
+	return s.SaveTestSession(ctx, session)
+}
+
+// SubmissionRepository routing. Submissions live with their test.
+
+func (r *Router) GetSubmission(ctx context.Context, testID domain.TestID, studentID domain.StudentID) (*domain.Submission, error) {
+	s, err := r.forTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetSubmission(ctx, testID, studentID)
+}
+
+func (r *Router) ListSubmissionsByTest(ctx context.Context, testID domain.TestID) ([]domain.Submission, error) {
+	s, err := r.forTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	return s.ListSubmissionsByTest(ctx, testID)
+}
+
+func (r *Router) SaveSubmission(ctx context.Context, submission *domain.Submission) error {
+	s, err := r.forTest(ctx, submission.TestID)
+	if err != nil {
+		return err
+	}
+	return s.SaveSubmission(ctx, submission)
+}
+
+// RubricRepository routing. Rubrics and templates live with their teacher.
+
+// SaveRubrics saves rubrics and templates belonging to one teacher.
+func (r *Router) SaveRubrics(ctx context.Context, rubrics []domain.Rubric, templates []domain.FeedbackTemplate) error {
+	var owners []domain.TeacherID
+	for _, rb := range rubrics {
+		owners = append(owners, rb.TeacherID)
+	}
+	for _, t := range templates {
+		owners = append(owners, t.TeacherID)
+	}
+	if len(owners) == 0 {
+		return nil
+	}
+	for _, owner := range owners[1:] {
+		if owner != owners[0] {
+			return errors.New("router: rubrics of several teachers cannot be saved together")
+		}
+	}
+	s, err := r.forTeacher(ctx, owners[0])
+	if err != nil {
+		return err
+	}
+	return s.SaveRubrics(ctx, rubrics, templates)
+}
+
+func (r *Router) GetRubric(ctx context.Context, id domain.RubricID) (*domain.Rubric, error) {
+	_, rb, err := probe(r, func(s Store) (*domain.Rubric, error) { return s.GetRubric(ctx, id) })
+	return rb, err
+}
+
+func (r *Router) ListRubrics(ctx context.Context, teacherID domain.TeacherID, page repository.PageRequest) (repository.Page[domain.Rubric], error) {
+	s, err := r.forTeacher(ctx, teacherID)
+	if err != nil {
+		return repository.Page[domain.Rubric]{}, err
+	}
+	return s.ListRubrics(ctx, teacherID, page)
+}
+
+func (r *Router) ListFeedbackTemplates(ctx context.Context, teacherID domain.TeacherID, page repository.PageRequest) (repository.Page[domain.FeedbackTemplate], error) {
+	s, err := r.forTeacher(ctx, teacherID)
+	if err != nil {
+		return repository.Page[domain.FeedbackTemplate]{}, err
+	}
+	return s.ListFeedbackTemplates(ctx, teacherID, page)
+}
+
+// DelegationRepository routing. Delegations live with the delegating
+// teacher; a delegate may belong to another school.
+
+func (r *Router) SaveDelegation(ctx context.Context, delegation *domain.Delegation) error {
+	s, err := r.forTeacher(ctx, delegation.TeacherID)
+	if err != nil {
+		return err
+	}
+	return s.SaveDelegation(ctx, delegation)
+}
+
+func (r *Router) GetDelegation(ctx context.Context, id domain.DelegationID) (*domain.Delegation, error) {
+	_, d, err := probe(r, func(s Store) (*domain.Delegation, error) { return s.GetDelegation(ctx, id) })
+	return d, err
+}
+
+func (r *Router) ListDelegationsByTeacher(ctx context.Context, teacherID domain.TeacherID, page repository.PageRequest) (repository.Page[domain.Delegation], error) {
+	s, err := r.forTeacher(ctx, teacherID)
+	if err != nil {
+		return repository.Page[domain.Delegation]{}, err
+	}
+	return s.ListDelegationsByTeacher(ctx, teacherID, page)
+}
+
+func (r *Router) ListDelegationsForDelegate(ctx context.Context, delegateID domain.TeacherID) ([]domain.Delegation, error) {
+	return gather(r, func(s Store) ([]domain.Delegation, error) { return s.ListDelegationsForDelegate(ctx, delegateID) },
+		func(d domain.Delegation) (time.Time, domain.DelegationID) { return d.CreatedAt, d.ID })
+}
+
+func (r *Router) DeleteDelegation(ctx context.Context, id domain.DelegationID) error {
+	s, _, err := probe(r, func(s Store) (*domain.Delegation, error) { return s.GetDelegation(ctx, id) })
+	if err != nil {
+		return err
+	}
+	return s.DeleteDelegation(ctx, id)
+}
+
+func (r *Router) DeleteExpiredDelegations(ctx context.Context, now time.Time) ([]domain.Delegation, error) {
+	return gather(r, func(s Store) ([]domain.Delegation, error) { return s.DeleteExpiredDelegations(ctx, now) },
+		func(d domain.Delegation) (time.Time, domain.DelegationID) { return d.CreatedAt, d.ID })
+}
+
+// DeviceSessionRepository routing. Device sessions live with their student.
+
+func (r *Router) SaveDeviceSession(ctx context.Context, session *domain.DeviceSession) error {
+	s, err := r.forStudent(ctx, session.StudentID)
+	if err != nil {
+		return err
+	}
+	return s.SaveDeviceSession(ctx, session)
+}
+
+func (r *Router) GetDeviceSession(ctx context.Context, id domain.DeviceSessionID) (*domain.DeviceSession, error) {
+	_, d, err := probe(r, func(s Store) (*domain.DeviceSession, error) { return s.GetDeviceSession(ctx, id) })
+	return d, err
+}
+
+func (r *Router) ListDeviceSessions(ctx context.Context, studentID domain.StudentID) ([]domain.DeviceSession, error) {
+	s, err := r.forStudent(ctx, studentID)
+	if err != nil {
+		return nil, err
+	}
+	return s.ListDeviceSessions(ctx, studentID)
+}
+
+func (r *Router) DeleteExpiredDeviceSessions(ctx context.Context, now time.Time) ([]domain.DeviceSession, error) {
+	return gather(r, func(s Store) ([]domain.DeviceSession, error) { return s.DeleteExpiredDeviceSessions(ctx, now) },
+		func(d domain.DeviceSession) (time.Time, domain.DeviceSessionID) { return d.CreatedAt, d.ID })
+}
+
+// QuestionBankRepository routing. Bank questions live with their teacher.
+
+func (r *Router) SaveBankQuestion(ctx context.Context, question *domain.BankQuestion) error {
+	s, err := r.forTeacher(ctx, question.TeacherID)
+	if err != nil {
+		return err
+	}
+	return s.SaveBankQuestion(ctx, question)
+}
+
+func (r *Router) GetBankQuestion(ctx context.Context, id domain.BankQuestionID) (*domain.BankQuestion, error) {
+	_, q, err := probe(r, func(s Store) (*domain.BankQuestion, error) { return s.GetBankQuestion(ctx, id) })
+	return q, err
+}
+
+func (r *Router) ListBankQuestions(ctx context.Context, teacherID domain.TeacherID, page repository.PageRequest) (repository.Page[domain.BankQuestion], error) {
+	s, err := r.forTeacher(ctx, teacherID)
+	if err != nil {
+		return repository.Page[domain.BankQuestion]{}, err
+	}
+	return s.ListBankQuestions(ctx, teacherID, page)
+}
+
+func (r *Router) DeleteBankQuestion(ctx context.Context, id domain.BankQuestionID) error {
+	s, q, err := probe(r, func(s Store) (*domain.BankQuestion, error) { return s.GetBankQuestion(ctx, id) })
+	if err != nil || q == nil {
+		return err
+	}
+	return s.DeleteBankQuestion(ctx, id)
+}
+
+// DistrictRepository routing. Districts and their staff live in the shared
+// store.
+
+func (r *Router) SaveDistrict(ctx context.Context, district *domain.District) error {
+	return r.shared.SaveDistrict(ctx, district)
+}
+
+func (r *Router) GetDistrict(ctx context.Context, id domain.DistrictID) (*domain.District, error) {
+	return r.shared.GetDistrict(ctx, id)
+}
+
+func (r *Router) ListDistricts(ctx context.Context, page repository.PageRequest) (repository.Page[domain.District], error) {
+	return r.shared.ListDistricts(ctx, page)
+}
+
+func (r *Router) ListSchoolsByDistrict(ctx context.Context, districtID domain.DistrictID, page repository.PageRequest) (repository.Page[domain.School], error) {
+	return r.shared.ListSchoolsByDistrict(ctx, districtID, page)
+}
+
+func (r *Router) SaveDistrictStaff(ctx context.Context, staff *domain.DistrictStaff) error {
+	return r.shared.SaveDistrictStaff(ctx, staff)
+}
+
+func (r *Router) GetDistrictStaff(ctx context.Context, id domain.DistrictStaffID) (*domain.DistrictStaff, error) {
+	return r.shared.GetDistrictStaff(ctx, id)
+}
+
+// AuditRepository routing. Audit entries live with the test, student or
+// teacher they concern, so a school's trail stays in its store; entries
+// concerning none of them go to the shared store.
+
+func (r *Router) SaveAuditEntry(ctx context.Context, entry *domain.AuditEntry) error {
+	s, err := r.forAudit(ctx, entry)
+	if err != nil {
+		return err
+	}
+	return s.SaveAuditEntry(ctx, entry)
+}
+
+// ListAuditEntries merges the pages of every store. Each store is asked for
+// a page after the same cursor, so the newest entries of all of them are
+// among the entries fetched.
+func (r *Router) ListAuditEntries(ctx context.Context, filter repository.AuditFilter, page repository.PageRequest) (repository.Page[domain.AuditEntry], error) {
+	merged := make([]domain.AuditEntry, 0)
+	more := false
+	for _, s := range r.stores {
+		p, err := s.ListAuditEntries(ctx, filter, page)
+		if err != nil {
+			return repository.Page[domain.AuditEntry]{}, err
+		}
+		merged = append(merged, p.Items...)
+		more = more || p.NextCursor != ""
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		if !merged[i].CreatedAt.Equal(merged[j].CreatedAt) {
+			return merged[i].CreatedAt.After(merged[j].CreatedAt)
+		}
+		return merged[i].ID > merged[j].ID
+	})
+	key := func(e domain.AuditEntry) (time.Time, string) { return e.CreatedAt, string(e.ID) }
+	out, err := repository.Paginate(merged, repository.PageRequest{Limit: page.Limit}, true, key)
+	if err != nil {
+		return repository.Page[domain.AuditEntry]{}, err
+	}
+	if out.NextCursor == "" && more && len(out.Items) > 0 {
+		out.NextCursor = repository.EncodeCursor(key(out.Items[len(out.Items)-1]))
+	}
+	return out, nil
+}
+
+// forAudit returns the store of the test, student or teacher an audit entry
+// concerns, in that order.
+func (r *Router) forAudit(ctx context.Context, entry *domain.AuditEntry) (Store, error) {
+	if entry.TestID != "" {
+		s, t, err := probe(r, func(s Store) (*domain.Test, error) { return s.GetTest(ctx, entry.TestID) })
+		if err != nil || t != nil {
+			return s, err
+		}
+	}
+	if entry.StudentID != "" {
+		s, st, err := probe(r, func(s Store) (*domain.Student, error) { return s.GetStudent(ctx, entry.StudentID) })
+		if err != nil || st != nil {
+			return s, err
+		}
+	}
+	if entry.PrincipalRole == domain.RoleTeacher {
+		return r.forTeacher(ctx, domain.TeacherID(entry.PrincipalID))
+	}
+	return r.shared, nil
+}
@@ -0,0 +1,142 @@
+package router_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/audit"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/memory"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+	"github.com/sky0621/go_work_sample/core/pkg/storage/filedb"
+	"github.com/sky0621/go_work_sample/core/pkg/storage/router"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+var created = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// schoolSeed returns one school with a grade, a class, a teacher and a
+// student, all named after the school.
+func schoolSeed(id string) memory.SeedData {
+	return memory.SeedData{
+		Schools:  []domain.School{{ID: domain.SchoolID(id), Name: id, CreatedAt: created}},
+		Grades:   []domain.Grade{{ID: domain.GradeID(id + "-grade"), SchoolID: domain.SchoolID(id), CreatedAt: created}},
+		Classes:  []domain.Class{{ID: domain.ClassID(id + "-class"), GradeID: domain.GradeID(id + "-grade"), CreatedAt: created}},
+		Teachers: []domain.Teacher{{ID: domain.TeacherID(id + "-teacher"), SchoolID: domain.SchoolID(id), CreatedAt: created}},
+		Students: []domain.Student{{ID: domain.StudentID(id + "-student"), ClassID: domain.ClassID(id + "-class"), Email: "kid@example.com", CreatedAt: created}},
+	}
+}
+
+// memoryStore is a Store kept in memory, whose data can always be exported.
+type memoryStore struct {
+	*memory.Repository
+}
+
+func (s memoryStore) ExportState() (memory.State, error) {
+	return s.Repository.ExportState(), nil
+}
+
+func TestRouter_KeepsSchoolDataInItsStore(t *testing.T) {
+	shared := memory.NewRepository(schoolSeed("north"))
+	south := memory.NewRepository(schoolSeed("south"))
+	repo := router.New(memoryStore{shared}, map[domain.SchoolID]router.Store{"south": memoryStore{south}})
+	service := usecase.NewAssessmentService(repo, repo, repo, repo)
+	service.SetAuditLog(audit.NewLog(repo))
+	ctx := context.Background()
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Quiz",
+		TeacherID:  "south-teacher",
+		Questions:  []usecase.QuestionDraft{{Prompt: "2 + 2", Points: 1}},
+		StudentIDs: []domain.StudentID{"south-student"},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	answer, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: "south-student", Response: "4"})
+	if err != nil {
+		t.Fatalf("SubmitAnswer failed: %v", err)
+	}
+	if _, err := service.GradeAnswer(ctx, usecase.GradeInput{TeacherID: "south-teacher", TestID: test.ID, QuestionID: questions[0].ID, StudentID: "south-student", Score: 1, Completed: true}); err != nil {
+		t.Fatalf("GradeAnswer failed: %v", err)
+	}
+
+	if got, _ := south.GetTest(ctx, test.ID); got == nil {
+		t.Fatalf("expected the test in the school's store")
+	}
+	if got, _ := shared.GetTest(ctx, test.ID); got != nil {
+		t.Fatalf("expected no test in the shared store")
+	}
+	if got, _ := south.GetResult(ctx, answer.ID); got == nil || got.Score != 1 {
+		t.Fatalf("expected the result next to its answer, got %+v", got)
+	}
+	if ok, _ := shared.HasAnswer(ctx, answer.ID); ok {
+		t.Fatalf("expected no answer in the shared store")
+	}
+
+	if trail, _ := shared.ListAuditEntries(ctx, repository.AuditFilter{}, repository.All); len(trail.Items) != 0 {
+		t.Fatalf("expected no audit entries of the school in the shared store, got %+v", trail.Items)
+	}
+	trail, err := repo.ListAuditEntries(ctx, repository.AuditFilter{}, repository.PageRequest{Limit: 2})
+	if err != nil || len(trail.Items) != 2 || trail.NextCursor == "" {
+		t.Fatalf("expected a first page of the school's audit trail, got %+v, %v", trail, err)
+	}
+	rest, err := repo.ListAuditEntries(ctx, repository.AuditFilter{}, repository.PageRequest{Limit: 2, Cursor: trail.NextCursor})
+	if err != nil || len(rest.Items) != 1 || rest.NextCursor != "" || rest.Items[0].ID == trail.Items[0].ID || rest.Items[0].ID == trail.Items[1].ID {
+		t.Fatalf("expected the rest of the school's audit trail, got %+v, %v", rest, err)
+	}
+
+	page, err := repo.ListTestsForStudent(ctx, "south-student", repository.All)
+	if err != nil || len(page.Items) != 1 {
+		t.Fatalf("expected the student's test through the router, got %+v, %v", page.Items, err)
+	}
+	if school, _ := repo.GetSchool(ctx, "south"); school != nil {
+		t.Fatalf("expected schools to come from the shared directory only, got %+v", school)
+	}
+	students, err := repo.FindStudentsByEmail(ctx, "kid@example.com")
+	if err != nil || len(students) != 2 {
+		t.Fatalf("expected students of both stores, got %+v, %v", students, err)
+	}
+}
+
+func TestOpenFiles_SplitsSeedBySchool(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	north, south := schoolSeed("north"), schoolSeed("south")
+	seed := memory.SeedData{
+		Schools:  append(north.Schools, south.Schools...),
+		Grades:   append(north.Grades, south.Grades...),
+		Classes:  append(north.Classes, south.Classes...),
+		Teachers: append(north.Teachers, south.Teachers...),
+		Students: append(north.Students, south.Students...),
+	}
+	southPath := filepath.Join(dir, "south.json")
+
+	repo, shared, err := router.OpenFiles(filepath.Join(dir, "state.json"), map[domain.SchoolID]string{"south": southPath}, seed, filedb.Options{})
+	if err != nil {
+		t.Fatalf("OpenFiles failed: %v", err)
+	}
+	if teacher, _ := shared.GetTeacher(ctx, "south-teacher"); teacher != nil {
+		t.Fatalf("expected the school's teacher outside the shared file")
+	}
+	if school, _ := shared.GetSchool(ctx, "south"); school == nil {
+		t.Fatalf("expected every school in the shared directory")
+	}
+	if student, _ := repo.GetStudent(ctx, "south-student"); student == nil {
+		t.Fatalf("expected the router to find the school's student")
+	}
+
+	reopened, err := filedb.NewRepository(southPath, memory.SeedData{})
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	state, err := reopened.ExportState()
+	if err != nil {
+		t.Fatalf("ExportState failed: %v", err)
+	}
+	if len(state.Teachers) != 1 || len(state.Students) != 1 || len(state.Schools) != 0 || state.Teachers[0].ID != "south-teacher" {
+		t.Fatalf("unexpected school file contents %+v", state)
+	}
+}
@@ -0,0 +1,37 @@
+package router
+
+import (
+	"fmt"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/memory"
+	"github.com/sky0621/go_work_sample/core/pkg/storage/sqlite"
+)
+
+// OpenSQLite is OpenFiles for SQLite databases opened with the registered
+// driver: a shared database at sharedPath and one per school listed in
+// schoolPaths, each seeded with its part of seed when created.
+func OpenSQLite(sharedPath string, schoolPaths map[domain.SchoolID]string, seed memory.SeedData, driver string) (*Router, *sqlite.Repository, error) {
+	schools := make(map[domain.SchoolID]bool, len(schoolPaths))
+	for id := range schoolPaths {
+		schools[id] = true
+	}
+	sharedSeed, schoolSeeds := splitSeed(seed, schools)
+
+	shared, err := sqlite.Open(driver, sharedPath, sharedSeed)
+	if err != nil {
+		return nil, nil, err
+	}
+	stores := make(map[domain.SchoolID]Store, len(schoolPaths))
+	for id, path := range schoolPaths {
+		if path == sharedPath {
+			return nil, nil, fmt.Errorf("router: school %s must not use the shared store path", id)
+		}
+		store, err := sqlite.Open(driver, path, schoolSeeds[id])
+		if err != nil {
+			return nil, nil, fmt.Errorf("router: school %s: %w", id, err)
+		}
+		stores[id] = store
+	}
+	return New(shared, stores), shared, nil
+}
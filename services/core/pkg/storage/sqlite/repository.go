@@ -0,0 +1,910 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// OrganizationRepository implementation.
+
+func (r *Repository) GetSchool(ctx context.Context, id domain.SchoolID) (*domain.School, error) {
+	return get[domain.School](ctx, r.db, "SELECT body FROM schools WHERE id = ?", string(id))
+}
+
+func (r *Repository) ListSchools(ctx context.Context, req repository.PageRequest) (repository.Page[domain.School], error) {
+	return page(ctx, r.db, "schools", "1 = 1", nil, req, false, schoolPageKey)
+}
+
+func (r *Repository) GetGrade(ctx context.Context, id domain.GradeID) (*domain.Grade, error) {
+	return get[domain.Grade](ctx, r.db, "SELECT body FROM grades WHERE id = ?", string(id))
+}
+
+func (r *Repository) GetClass(ctx context.Context, id domain.ClassID) (*domain.Class, error) {
+	return get[domain.Class](ctx, r.db, "SELECT body FROM classes WHERE id = ?", string(id))
+}
+
+func (r *Repository) GetTeacher(ctx context.Context, id domain.TeacherID) (*domain.Teacher, error) {
+	return get[domain.Teacher](ctx, r.db, "SELECT body FROM teachers WHERE id = ?", string(id))
+}
+
+func (r *Repository) GetStudent(ctx context.Context, id domain.StudentID) (*domain.Student, error) {
+	return get[domain.Student](ctx, r.db, "SELECT body FROM students WHERE id = ?", string(id))
+}
+
+func (r *Repository) FindStudentsByEmail(ctx context.Context, email string) ([]domain.Student, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return []domain.Student{}, nil
+	}
+	return list[domain.Student](ctx, r.db,
+		"SELECT body FROM students WHERE email = ? OR guardian_email = ? ORDER BY created_at, id", email, email)
+}
+
+func (r *Repository) ListGrades(ctx context.Context, schoolID domain.SchoolID, req repository.PageRequest) (repository.Page[domain.Grade], error) {
+	return page(ctx, r.db, "grades", "school_id = ?", []any{string(schoolID)}, req, false, gradePageKey)
+}
+
+func (r *Repository) ListClasses(ctx context.Context, gradeID domain.GradeID, req repository.PageRequest) (repository.Page[domain.Class], error) {
+	return page(ctx, r.db, "classes", "grade_id = ?", []any{string(gradeID)}, req, false, classPageKey)
+}
+
+func (r *Repository) ListStudents(ctx context.Context, classID domain.ClassID, req repository.PageRequest) (repository.Page[domain.Student], error) {
+	return page(ctx, r.db, "students", "class_id = ?", []any{string(classID)}, req, false, studentPageKey)
+}
+
+func (r *Repository) ListTeachers(ctx context.Context, schoolID domain.SchoolID, req repository.PageRequest) (repository.Page[domain.Teacher], error) {
+	return page(ctx, r.db, "teachers", "school_id = ?", []any{string(schoolID)}, req, false, teacherPageKey)
+}
+
+func (r *Repository) UpdateSchool(ctx context.Context, school *domain.School) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		if err := mustExist(ctx, tx, "school not found", "SELECT 1 FROM schools WHERE id = ?", string(school.ID)); err != nil {
+			return err
+		}
+		if school.DistrictID != "" {
+			if err := mustExist(ctx, tx, "district not found", "SELECT 1 FROM districts WHERE id = ?", string(school.DistrictID)); err != nil {
+				return err
+			}
+		}
+		return putSchool(ctx, tx, *school)
+	})
+}
+
+func (r *Repository) UpdateTeacher(ctx context.Context, teacher *domain.Teacher) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		if err := mustExist(ctx, tx, "teacher not found", "SELECT 1 FROM teachers WHERE id = ?", string(teacher.ID)); err != nil {
+			return err
+		}
+		return putTeacher(ctx, tx, *teacher)
+	})
+}
+
+func (r *Repository) UpdateStudent(ctx context.Context, student *domain.Student) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		if err := mustExist(ctx, tx, "student not found", "SELECT 1 FROM students WHERE id = ?", string(student.ID)); err != nil {
+			return err
+		}
+		return putStudent(ctx, tx, *student)
+	})
+}
+
+func (r *Repository) CreateStudents(ctx context.Context, classID domain.ClassID, students []domain.Student) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		if err := mustExist(ctx, tx, "class not found", "SELECT 1 FROM classes WHERE id = ?", string(classID)); err != nil {
+			return err
+		}
+		for _, st := range students {
+			taken, err := exists(ctx, tx, "SELECT 1 FROM students WHERE id = ?", string(st.ID))
+			if err != nil {
+				return err
+			}
+			if taken {
+				return errors.New("student already exists")
+			}
+			st.ClassID = classID
+			if err := putStudent(ctx, tx, st); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// TestRepository implementation.
+
+func (r *Repository) CreateTest(ctx context.Context, test *domain.Test, questions []domain.Question, studentIDs []domain.StudentID) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		taken, err := exists(ctx, tx, "SELECT 1 FROM tests WHERE id = ?", string(test.ID))
+		if err != nil {
+			return err
+		}
+		if taken {
+			return errors.New("test already exists")
+		}
+		if err := mustExist(ctx, tx, "teacher not found", "SELECT 1 FROM teachers WHERE id = ?", string(test.TeacherID)); err != nil {
+			return err
+		}
+		for _, studentID := range studentIDs {
+			if err := mustExist(ctx, tx, "student not found", "SELECT 1 FROM students WHERE id = ?", string(studentID)); err != nil {
+				return err
+			}
+		}
+
+		stored := *test
+		stored.AssignedTo = append([]domain.StudentID(nil), studentIDs...)
+		if err := putTest(ctx, tx, stored); err != nil {
+			return err
+		}
+		for _, q := range questions {
+			if err := putQuestion(ctx, tx, q); err != nil {
+				return err
+			}
+		}
+		for _, studentID := range studentIDs {
+			if err := assign(ctx, tx, test.ID, studentID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *Repository) UpdateTest(ctx context.Context, test *domain.Test) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		if err := mustExist(ctx, tx, "test not found", "SELECT 1 FROM tests WHERE id = ?", string(test.ID)); err != nil {
+			return err
+		}
+		return putTest(ctx, tx, *test)
+	})
+}
+
+func (r *Repository) UpdateAssignments(ctx context.Context, testID domain.TestID, add, remove []domain.StudentID) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		test, err := get[domain.Test](ctx, tx, "SELECT body FROM tests WHERE id = ?", string(testID))
+		if err != nil {
+			return err
+		}
+		if test == nil {
+			return errors.New("test not found")
+		}
+		for _, studentID := range add {
+			if err := mustExist(ctx, tx, "student not found", "SELECT 1 FROM students WHERE id = ?", string(studentID)); err != nil {
+				return err
+			}
+		}
+		for _, studentID := range remove {
+			if _, err := tx.ExecContext(ctx, "DELETE FROM assignments WHERE test_id = ? AND student_id = ?", string(testID), string(studentID)); err != nil {
+				return err
+			}
+		}
+		for _, studentID := range add {
+			if err := assign(ctx, tx, testID, studentID); err != nil {
+				return err
+			}
+		}
+		test.Reassign(add, remove)
+		return putTest(ctx, tx, *test)
+	})
+}
+
+func (r *Repository) GetTest(ctx context.Context, id domain.TestID) (*domain.Test, error) {
+	return get[domain.Test](ctx, r.db, "SELECT body FROM tests WHERE id = ?", string(id))
+}
+
+func (r *Repository) ListTestsByTeacher(ctx context.Context, teacherID domain.TeacherID, req repository.PageRequest) (repository.Page[domain.Test], error) {
+	return page(ctx, r.db, "tests", "teacher_id = ?", []any{string(teacherID)}, req, false, testPageKey)
+}
+
+func (r *Repository) ListTestsForStudent(ctx context.Context, studentID domain.StudentID, req repository.PageRequest) (repository.Page[domain.Test], error) {
+	return page(ctx, r.db, "tests", "id IN (SELECT test_id FROM assignments WHERE student_id = ?)", []any{string(studentID)}, req, false, testPageKey)
+}
+
+func (r *Repository) ListTestsInWindow(ctx context.Context, from, to time.Time) ([]domain.Test, error) {
+	return list[domain.Test](ctx, r.db,
+		"SELECT body FROM tests WHERE opens_at IS NOT NULL AND closes_at IS NOT NULL AND opens_at < ? AND closes_at > ? ORDER BY created_at, id",
+		stamp(to), stamp(from))
+}
+
+func (r *Repository) ListQuestions(ctx context.Context, testID domain.TestID) ([]domain.Question, error) {
+	return list[domain.Question](ctx, r.db, "SELECT body FROM questions WHERE test_id = ? ORDER BY sequence, id", string(testID))
+}
+
+func (r *Repository) UpdateQuestion(ctx context.Context, question *domain.Question) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		if err := mustExist(ctx, tx, "question not found", "SELECT 1 FROM questions WHERE id = ? AND test_id = ?", string(question.ID), string(question.TestID)); err != nil {
+			return err
+		}
+		return putQuestion(ctx, tx, *question)
+	})
+}
+
+func (r *Repository) ReorderQuestions(ctx context.Context, testID domain.TestID, sequences map[domain.QuestionID]int) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		for questionID, sequence := range sequences {
+			question, err := get[domain.Question](ctx, tx, "SELECT body FROM questions WHERE id = ? AND test_id = ?", string(questionID), string(testID))
+			if err != nil {
+				return err
+			}
+			if question == nil {
+				return errors.New("question not found")
+			}
+			question.Sequence = sequence
+			if err := putQuestion(ctx, tx, *question); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *Repository) GetQuestion(ctx context.Context, testID domain.TestID, questionID domain.QuestionID) (*domain.Question, error) {
+	return get[domain.Question](ctx, r.db, "SELECT body FROM questions WHERE id = ? AND test_id = ?", string(questionID), string(testID))
+}
+
+func (r *Repository) HasQuestion(ctx context.Context, testID domain.TestID, questionID domain.QuestionID) (bool, error) {
+	return exists(ctx, r.db, "SELECT 1 FROM questions WHERE id = ? AND test_id = ?", string(questionID), string(testID))
+}
+
+func (r *Repository) IsStudentAssigned(ctx context.Context, testID domain.TestID, studentID domain.StudentID) (bool, error) {
+	return exists(ctx, r.db, "SELECT 1 FROM assignments WHERE test_id = ? AND student_id = ?", string(testID), string(studentID))
+}
+
+// AnswerRepository implementation.
+
+func (r *Repository) UpsertAnswer(ctx context.Context, answer *domain.Answer) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		prev, err := get[domain.Answer](ctx, tx, "SELECT body FROM answers WHERE id = ?", string(answer.ID))
+		if err != nil {
+			return err
+		}
+		return putAnswer(ctx, tx, answer.Revise(prev))
+	})
+}
+
+func (r *Repository) GetAnswer(ctx context.Context, testID domain.TestID, questionID domain.QuestionID, studentID domain.StudentID) (*domain.Answer, error) {
+	return get[domain.Answer](ctx, r.db,
+		"SELECT body FROM answers WHERE test_id = ? AND question_id = ? AND student_id = ?",
+		string(testID), string(questionID), string(studentID))
+}
+
+func (r *Repository) DeleteAnswer(ctx context.Context, testID domain.TestID, questionID domain.QuestionID, studentID domain.StudentID) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		args := []any{string(testID), string(questionID), string(studentID)}
+		_, err := tx.ExecContext(ctx, "DELETE FROM results WHERE answer_id IN (SELECT id FROM answers WHERE test_id = ? AND question_id = ? AND student_id = ?)", args...)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, "DELETE FROM answers WHERE test_id = ? AND question_id = ? AND student_id = ?", args...)
+		return err
+	})
+}
+
+// HasAnswer reports whether the answer is stored here.
+func (r *Repository) HasAnswer(ctx context.Context, id domain.AnswerID) (bool, error) {
+	return exists(ctx, r.db, "SELECT 1 FROM answers WHERE id = ?", string(id))
+}
+
+func (r *Repository) ListAnswers(ctx context.Context, testID domain.TestID, studentID domain.StudentID) ([]domain.Answer, error) {
+	return list[domain.Answer](ctx, r.db,
+		"SELECT body FROM answers WHERE test_id = ? AND student_id = ? ORDER BY created_at, id",
+		string(testID), string(studentID))
+}
+
+func (r *Repository) ListAnswersByTest(ctx context.Context, testID domain.TestID, req repository.PageRequest) (repository.Page[domain.Answer], error) {
+	return page(ctx, r.db, "answers", "test_id = ?", []any{string(testID)}, req, false, answerPageKey)
+}
+
+// ResultRepository implementation.
+
+// SaveResult stores a result for an answer kept here; results of unknown
+// answers are rejected because they could never be listed.
+func (r *Repository) SaveResult(ctx context.Context, result *domain.Result) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		return putResult(ctx, tx, *result)
+	})
+}
+
+func (r *Repository) SaveResults(ctx context.Context, results []domain.Result) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		for _, result := range results {
+			if err := putResult(ctx, tx, result); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *Repository) GetResult(ctx context.Context, answerID domain.AnswerID) (*domain.Result, error) {
+	return get[domain.Result](ctx, r.db, "SELECT body FROM results WHERE answer_id = ?", string(answerID))
+}
+
+func (r *Repository) ListResultsByTest(ctx context.Context, testID domain.TestID, req repository.PageRequest) (repository.Page[domain.Result], error) {
+	return page(ctx, r.db, "results", "test_id = ?", []any{string(testID)}, req, false, resultPageKey)
+}
+
+func (r *Repository) ListResultsByStudent(ctx context.Context, testID domain.TestID, studentID domain.StudentID) ([]domain.Result, error) {
+	return list[domain.Result](ctx, r.db,
+		"SELECT body FROM results WHERE test_id = ? AND student_id = ? ORDER BY created_at, id",
+		string(testID), string(studentID))
+}
+
+// SnapshotGrading reads the test's answers and results in one read
+// transaction.
+func (r *Repository) SnapshotGrading(ctx context.Context, testID domain.TestID) (repository.GradingSnapshot, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return repository.GradingSnapshot{}, err
+	}
+	defer tx.Rollback()
+
+	answers, err := list[domain.Answer](ctx, tx, "SELECT body FROM answers WHERE test_id = ? ORDER BY created_at, id", string(testID))
+	if err != nil {
+		return repository.GradingSnapshot{}, err
+	}
+	results, err := list[domain.Result](ctx, tx, "SELECT body FROM results WHERE test_id = ? ORDER BY created_at, id", string(testID))
+	if err != nil {
+		return repository.GradingSnapshot{}, err
+	}
+	return repository.GradingSnapshot{Answers: answers, Results: results}, nil
+}
+
+// NotificationRepository implementation.
+
+func (r *Repository) SaveNotification(ctx context.Context, notification *domain.Notification) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		return putNotification(ctx, tx, *notification)
+	})
+}
+
+func (r *Repository) ListNotifications(ctx context.Context, role domain.Role, recipientID string, req repository.PageRequest) (repository.Page[domain.Notification], error) {
+	return page(ctx, r.db, "notifications", "role = ? AND recipient_id = ?", []any{string(role), recipientID}, req, true, notificationPageKey)
+}
+
+func (r *Repository) MarkNotificationsRead(ctx context.Context, role domain.Role, recipientID string, ids []domain.NotificationID, at time.Time) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		query := "SELECT body FROM notifications WHERE role = ? AND recipient_id = ?"
+		args := []any{string(role), recipientID}
+		if len(ids) > 0 {
+			query += " AND id IN (" + strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ") + ")"
+			for _, id := range ids {
+				args = append(args, string(id))
+			}
+		}
+		notifications, err := list[domain.Notification](ctx, tx, query, args...)
+		if err != nil {
+			return err
+		}
+		for _, n := range notifications {
+			if n.ReadAt != nil {
+				continue
+			}
+			readAt := at
+			n.ReadAt = &readAt
+			if err := putNotification(ctx, tx, n); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DigestRepository implementation.
+
+func (r *Repository) QueueDigestItem(ctx context.Context, item *domain.DigestItem) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		return putDigestItem(ctx, tx, *item)
+	})
+}
+
+func (r *Repository) ListDigestItems(ctx context.Context) ([]domain.DigestItem, error) {
+	return list[domain.DigestItem](ctx, r.db, "SELECT body FROM digest_items ORDER BY occurred_at, id")
+}
+
+func (r *Repository) DeleteDigestItems(ctx context.Context, ids []domain.DigestItemID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.write(ctx, func(tx *sql.Tx) error {
+		args := make([]any, len(ids))
+		for i, id := range ids {
+			args[i] = string(id)
+		}
+		_, err := tx.ExecContext(ctx, "DELETE FROM digest_items WHERE id IN ("+strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")+")", args...)
+		return err
+	})
+}
+
+// QuestionCommentRepository implementation.
+
+func (r *Repository) SaveQuestionComment(ctx context.Context, comment *domain.QuestionComment) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		if err := mustExist(ctx, tx, "question not found", "SELECT 1 FROM questions WHERE id = ?", string(comment.QuestionID)); err != nil {
+			return err
+		}
+		return putQuestionComment(ctx, tx, *comment)
+	})
+}
+
+func (r *Repository) GetQuestionComment(ctx context.Context, id domain.QuestionCommentID) (*domain.QuestionComment, error) {
+	return get[domain.QuestionComment](ctx, r.db, "SELECT body FROM question_comments WHERE id = ?", string(id))
+}
+
+func (r *Repository) ListQuestionComments(ctx context.Context, testID domain.TestID, questionID domain.QuestionID) ([]domain.QuestionComment, error) {
+	return list[domain.QuestionComment](ctx, r.db,
+		"SELECT body FROM question_comments WHERE test_id = ? AND question_id = ? ORDER BY created_at, id",
+		string(testID), string(questionID))
+}
+
+// AnswerCommentRepository implementation.
+
+func (r *Repository) SaveAnswerComment(ctx context.Context, comment *domain.AnswerComment) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		if err := mustExist(ctx, tx, "test not found", "SELECT 1 FROM tests WHERE id = ?", string(comment.TestID)); err != nil {
+			return err
+		}
+		return putAnswerComment(ctx, tx, *comment)
+	})
+}
+
+func (r *Repository) GetAnswerComment(ctx context.Context, id domain.AnswerCommentID) (*domain.AnswerComment, error) {
+	return get[domain.AnswerComment](ctx, r.db, "SELECT body FROM answer_comments WHERE id = ?", string(id))
+}
+
+func (r *Repository) ListAnswerComments(ctx context.Context, testID domain.TestID, answerID domain.AnswerID) ([]domain.AnswerComment, error) {
+	return list[domain.AnswerComment](ctx, r.db,
+		"SELECT body FROM answer_comments WHERE test_id = ? AND answer_id = ? ORDER BY created_at, id",
+		string(testID), string(answerID))
+}
+
+func (r *Repository) ListAnswerCommentsByStudent(ctx context.Context, studentID domain.StudentID) ([]domain.AnswerComment, error) {
+	return list[domain.AnswerComment](ctx, r.db,
+		"SELECT body FROM answer_comments WHERE student_id = ? ORDER BY created_at, id", string(studentID))
+}
+
+// TestSessionRepository implementation.
+
+func (r *Repository) GetTestSession(ctx context.Context, testID domain.TestID, studentID domain.StudentID) (*domain.TestSession, error) {
+	return get[domain.TestSession](ctx, r.db,
+		"SELECT body FROM test_sessions WHERE test_id = ? AND student_id = ?", string(testID), string(studentID))
+}
+
+func (r *Repository) SaveTestSession(ctx context.Context, session *domain.TestSession) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		if err := mustExist(ctx, tx, "test not found", "SELECT 1 FROM tests WHERE id = ?", string(session.TestID)); err != nil {
+			return err
+		}
+		return putTestSession(ctx, tx, *session)
+	})
+}
+
+// SubmissionRepository implementation.
+
+func (r *Repository) GetSubmission(ctx context.Context, testID domain.TestID, studentID domain.StudentID) (*domain.Submission, error) {
+	return get[domain.Submission](ctx, r.db,
+		"SELECT body FROM submissions WHERE test_id = ? AND student_id = ?", string(testID), string(studentID))
+}
+
+func (r *Repository) ListSubmissionsByTest(ctx context.Context, testID domain.TestID) ([]domain.Submission, error) {
+	return list[domain.Submission](ctx, r.db,
+		"SELECT body FROM submissions WHERE test_id = ? ORDER BY submitted_at, student_id", string(testID))
+}
+
+func (r *Repository) SaveSubmission(ctx context.Context, submission *domain.Submission) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		if err := mustExist(ctx, tx, "test not found", "SELECT 1 FROM tests WHERE id = ?", string(submission.TestID)); err != nil {
+			return err
+		}
+		return putSubmission(ctx, tx, *submission)
+	})
+}
+
+// RubricRepository implementation.
+
+func (r *Repository) SaveRubrics(ctx context.Context, rubrics []domain.Rubric, templates []domain.FeedbackTemplate) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		for _, rubric := range rubrics {
+			if err := mustExist(ctx, tx, "teacher not found", "SELECT 1 FROM teachers WHERE id = ?", string(rubric.TeacherID)); err != nil {
+				return err
+			}
+			if err := putRubric(ctx, tx, rubric); err != nil {
+				return err
+			}
+		}
+		for _, tmpl := range templates {
+			if err := mustExist(ctx, tx, "teacher not found", "SELECT 1 FROM teachers WHERE id = ?", string(tmpl.TeacherID)); err != nil {
+				return err
+			}
+			if err := putFeedbackTemplate(ctx, tx, tmpl); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *Repository) GetRubric(ctx context.Context, id domain.RubricID) (*domain.Rubric, error) {
+	return get[domain.Rubric](ctx, r.db, "SELECT body FROM rubrics WHERE id = ?", string(id))
+}
+
+func (r *Repository) ListRubrics(ctx context.Context, teacherID domain.TeacherID, req repository.PageRequest) (repository.Page[domain.Rubric], error) {
+	return page(ctx, r.db, "rubrics", "teacher_id = ?", []any{string(teacherID)}, req, false, rubricPageKey)
+}
+
+func (r *Repository) ListFeedbackTemplates(ctx context.Context, teacherID domain.TeacherID, req repository.PageRequest) (repository.Page[domain.FeedbackTemplate], error) {
+	return page(ctx, r.db, "feedback_templates", "teacher_id = ?", []any{string(teacherID)}, req, false, feedbackTemplatePageKey)
+}
+
+// DelegationRepository implementation.
+
+func (r *Repository) SaveDelegation(ctx context.Context, delegation *domain.Delegation) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		for _, id := range []domain.TeacherID{delegation.TeacherID, delegation.DelegateID} {
+			if err := mustExist(ctx, tx, "teacher not found", "SELECT 1 FROM teachers WHERE id = ?", string(id)); err != nil {
+				return err
+			}
+		}
+		return putDelegation(ctx, tx, *delegation)
+	})
+}
+
+func (r *Repository) GetDelegation(ctx context.Context, id domain.DelegationID) (*domain.Delegation, error) {
+	return get[domain.Delegation](ctx, r.db, "SELECT body FROM delegations WHERE id = ?", string(id))
+}
+
+func (r *Repository) ListDelegationsByTeacher(ctx context.Context, teacherID domain.TeacherID, req repository.PageRequest) (repository.Page[domain.Delegation], error) {
+	return page(ctx, r.db, "delegations", "teacher_id = ?", []any{string(teacherID)}, req, false, delegationPageKey)
+}
+
+func (r *Repository) ListDelegationsForDelegate(ctx context.Context, delegateID domain.TeacherID) ([]domain.Delegation, error) {
+	return list[domain.Delegation](ctx, r.db,
+		"SELECT body FROM delegations WHERE delegate_id = ? ORDER BY created_at, id", string(delegateID))
+}
+
+func (r *Repository) DeleteDelegation(ctx context.Context, id domain.DelegationID) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, "DELETE FROM delegations WHERE id = ?", string(id))
+		return err
+	})
+}
+
+func (r *Repository) DeleteExpiredDelegations(ctx context.Context, now time.Time) ([]domain.Delegation, error) {
+	var expired []domain.Delegation
+	err := r.write(ctx, func(tx *sql.Tx) error {
+		var err error
+		expired, err = list[domain.Delegation](ctx, tx,
+			"SELECT body FROM delegations WHERE expires_at <= ? ORDER BY created_at, id", stamp(now))
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, "DELETE FROM delegations WHERE expires_at <= ?", stamp(now))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expired, nil
+}
+
+// DeviceSessionRepository implementation.
+
+func (r *Repository) SaveDeviceSession(ctx context.Context, session *domain.DeviceSession) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		if err := mustExist(ctx, tx, "student not found", "SELECT 1 FROM students WHERE id = ?", string(session.StudentID)); err != nil {
+			return err
+		}
+		return putDeviceSession(ctx, tx, *session)
+	})
+}
+
+func (r *Repository) GetDeviceSession(ctx context.Context, id domain.DeviceSessionID) (*domain.DeviceSession, error) {
+	return get[domain.DeviceSession](ctx, r.db, "SELECT body FROM device_sessions WHERE id = ?", string(id))
+}
+
+func (r *Repository) ListDeviceSessions(ctx context.Context, studentID domain.StudentID) ([]domain.DeviceSession, error) {
+	return list[domain.DeviceSession](ctx, r.db,
+		"SELECT body FROM device_sessions WHERE student_id = ? ORDER BY created_at, id", string(studentID))
+}
+
+func (r *Repository) DeleteExpiredDeviceSessions(ctx context.Context, now time.Time) ([]domain.DeviceSession, error) {
+	var expired []domain.DeviceSession
+	err := r.write(ctx, func(tx *sql.Tx) error {
+		var err error
+		expired, err = list[domain.DeviceSession](ctx, tx,
+			"SELECT body FROM device_sessions WHERE expires_at <= ? ORDER BY created_at, id", stamp(now))
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, "DELETE FROM device_sessions WHERE expires_at <= ?", stamp(now))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expired, nil
+}
+
+// QuestionBankRepository implementation.
+
+func (r *Repository) SaveBankQuestion(ctx context.Context, question *domain.BankQuestion) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		if err := mustExist(ctx, tx, "teacher not found", "SELECT 1 FROM teachers WHERE id = ?", string(question.TeacherID)); err != nil {
+			return err
+		}
+		return putBankQuestion(ctx, tx, *question)
+	})
+}
+
+func (r *Repository) GetBankQuestion(ctx context.Context, id domain.BankQuestionID) (*domain.BankQuestion, error) {
+	return get[domain.BankQuestion](ctx, r.db, "SELECT body FROM bank_questions WHERE id = ?", string(id))
+}
+
+func (r *Repository) ListBankQuestions(ctx context.Context, teacherID domain.TeacherID, req repository.PageRequest) (repository.Page[domain.BankQuestion], error) {
+	return page(ctx, r.db, "bank_questions", "teacher_id = ?", []any{string(teacherID)}, req, false, bankQuestionPageKey)
+}
+
+func (r *Repository) DeleteBankQuestion(ctx context.Context, id domain.BankQuestionID) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, "DELETE FROM bank_questions WHERE id = ?", string(id))
+		return err
+	})
+}
+
+// DistrictRepository implementation.
+
+func (r *Repository) SaveDistrict(ctx context.Context, district *domain.District) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		return putDistrict(ctx, tx, *district)
+	})
+}
+
+func (r *Repository) GetDistrict(ctx context.Context, id domain.DistrictID) (*domain.District, error) {
+	return get[domain.District](ctx, r.db, "SELECT body FROM districts WHERE id = ?", string(id))
+}
+
+func (r *Repository) ListDistricts(ctx context.Context, req repository.PageRequest) (repository.Page[domain.District], error) {
+	return page(ctx, r.db, "districts", "1 = 1", nil, req, false, districtPageKey)
+}
+
+func (r *Repository) ListSchoolsByDistrict(ctx context.Context, districtID domain.DistrictID, req repository.PageRequest) (repository.Page[domain.School], error) {
+	return page(ctx, r.db, "schools", "district_id = ?", []any{string(districtID)}, req, false, schoolPageKey)
+}
+
+func (r *Repository) SaveDistrictStaff(ctx context.Context, staff *domain.DistrictStaff) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		if err := mustExist(ctx, tx, "district not found", "SELECT 1 FROM districts WHERE id = ?", string(staff.DistrictID)); err != nil {
+			return err
+		}
+		return putDistrictStaff(ctx, tx, *staff)
+	})
+}
+
+func (r *Repository) GetDistrictStaff(ctx context.Context, id domain.DistrictStaffID) (*domain.DistrictStaff, error) {
+	return get[domain.DistrictStaff](ctx, r.db, "SELECT body FROM district_staff WHERE id = ?", string(id))
+}
+
+// AuditRepository implementation.
+
+func (r *Repository) SaveAuditEntry(ctx context.Context, entry *domain.AuditEntry) error {
+	return r.write(ctx, func(tx *sql.Tx) error {
+		return putAuditEntry(ctx, tx, *entry)
+	})
+}
+
+func (r *Repository) ListAuditEntries(ctx context.Context, filter repository.AuditFilter, req repository.PageRequest) (repository.Page[domain.AuditEntry], error) {
+	where, args := auditWhere(filter)
+	return page(ctx, r.db, "audit_entries", where, args, req, true, auditEntryPageKey)
+}
+
+// auditWhere translates filter into a condition on the audit_entries
+// columns.
+func auditWhere(filter repository.AuditFilter) (string, []any) {
+	conds := []string{"1 = 1"}
+	var args []any
+	if filter.Action != "" {
+		conds = append(conds, "action = ?")
+		args = append(args, string(filter.Action))
+	}
+	if filter.PrincipalID != "" {
+		conds = append(conds, "principal_id = ?")
+		args = append(args, filter.PrincipalID)
+	}
+	if filter.TestID != "" {
+		conds = append(conds, "test_id = ?")
+		args = append(args, string(filter.TestID))
+	}
+	if !filter.Since.IsZero() {
+		conds = append(conds, "created_at >= ?")
+		args = append(args, stamp(filter.Since))
+	}
+	if !filter.Until.IsZero() {
+		conds = append(conds, "created_at < ?")
+		args = append(args, stamp(filter.Until))
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// Rows.
+
+func putSchool(ctx context.Context, q queryer, s domain.School) error {
+	return put(ctx, q, "schools", []string{"id", "district_id", "created_at"},
+		[]any{string(s.ID), string(s.DistrictID), stamp(s.CreatedAt)}, s)
+}
+
+func putGrade(ctx context.Context, q queryer, g domain.Grade) error {
+	return put(ctx, q, "grades", []string{"id", "school_id", "created_at"},
+		[]any{string(g.ID), string(g.SchoolID), stamp(g.CreatedAt)}, g)
+}
+
+func putClass(ctx context.Context, q queryer, c domain.Class) error {
+	return put(ctx, q, "classes", []string{"id", "grade_id", "created_at"},
+		[]any{string(c.ID), string(c.GradeID), stamp(c.CreatedAt)}, c)
+}
+
+func putTeacher(ctx context.Context, q queryer, t domain.Teacher) error {
+	return put(ctx, q, "teachers", []string{"id", "school_id", "created_at"},
+		[]any{string(t.ID), string(t.SchoolID), stamp(t.CreatedAt)}, t)
+}
+
+func putStudent(ctx context.Context, q queryer, s domain.Student) error {
+	return put(ctx, q, "students", []string{"id", "class_id", "email", "guardian_email", "created_at"},
+		[]any{string(s.ID), string(s.ClassID), strings.ToLower(s.Email), strings.ToLower(s.GuardianEmail), stamp(s.CreatedAt)}, s)
+}
+
+func putTest(ctx context.Context, q queryer, t domain.Test) error {
+	return put(ctx, q, "tests", []string{"id", "teacher_id", "opens_at", "closes_at", "created_at"},
+		[]any{string(t.ID), string(t.TeacherID), nullStamp(t.OpensAt), nullStamp(t.ClosesAt), stamp(t.CreatedAt)}, t)
+}
+
+func putQuestion(ctx context.Context, q queryer, question domain.Question) error {
+	return put(ctx, q, "questions", []string{"id", "test_id", "sequence"},
+		[]any{string(question.ID), string(question.TestID), question.Sequence}, question)
+}
+
+func assign(ctx context.Context, q queryer, testID domain.TestID, studentID domain.StudentID) error {
+	_, err := q.ExecContext(ctx, "INSERT OR IGNORE INTO assignments (test_id, student_id) VALUES (?, ?)", string(testID), string(studentID))
+	return err
+}
+
+func putAnswer(ctx context.Context, q queryer, a domain.Answer) error {
+	return put(ctx, q, "answers", []string{"id", "test_id", "question_id", "student_id", "created_at"},
+		[]any{string(a.ID), string(a.TestID), string(a.QuestionID), string(a.StudentID), stamp(a.CreatedAt)}, a)
+}
+
+// putResult files the result under its answer's test and student.
+func putResult(ctx context.Context, q queryer, res domain.Result) error {
+	var testID, studentID string
+	err := q.QueryRowContext(ctx, "SELECT test_id, student_id FROM answers WHERE id = ?", string(res.AnswerID)).Scan(&testID, &studentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("answer not found")
+	}
+	if err != nil {
+		return err
+	}
+	return put(ctx, q, "results", []string{"id", "answer_id", "test_id", "student_id", "created_at"},
+		[]any{string(res.ID), string(res.AnswerID), testID, studentID, stamp(res.CreatedAt)}, res)
+}
+
+func putNotification(ctx context.Context, q queryer, n domain.Notification) error {
+	return put(ctx, q, "notifications", []string{"id", "role", "recipient_id", "created_at"},
+		[]any{string(n.ID), string(n.Role), n.RecipientID, stamp(n.CreatedAt)}, n)
+}
+
+func putDigestItem(ctx context.Context, q queryer, item domain.DigestItem) error {
+	return put(ctx, q, "digest_items", []string{"id", "occurred_at"},
+		[]any{string(item.ID), stamp(item.OccurredAt)}, item)
+}
+
+func putQuestionComment(ctx context.Context, q queryer, c domain.QuestionComment) error {
+	return put(ctx, q, "question_comments", []string{"id", "test_id", "question_id", "created_at"},
+		[]any{string(c.ID), string(c.TestID), string(c.QuestionID), stamp(c.CreatedAt)}, c)
+}
+
+func putAnswerComment(ctx context.Context, q queryer, c domain.AnswerComment) error {
+	return put(ctx, q, "answer_comments", []string{"id", "test_id", "answer_id", "student_id", "created_at"},
+		[]any{string(c.ID), string(c.TestID), string(c.AnswerID), string(c.StudentID), stamp(c.CreatedAt)}, c)
+}
+
+func putTestSession(ctx context.Context, q queryer, s domain.TestSession) error {
+	return put(ctx, q, "test_sessions", []string{"test_id", "student_id"},
+		[]any{string(s.TestID), string(s.StudentID)}, s)
+}
+
+func putSubmission(ctx context.Context, q queryer, s domain.Submission) error {
+	return put(ctx, q, "submissions", []string{"test_id", "student_id", "submitted_at"},
+		[]any{string(s.TestID), string(s.StudentID), stamp(s.SubmittedAt)}, s)
+}
+
+func putRubric(ctx context.Context, q queryer, rub domain.Rubric) error {
+	return put(ctx, q, "rubrics", []string{"id", "teacher_id", "created_at"},
+		[]any{string(rub.ID), string(rub.TeacherID), stamp(rub.CreatedAt)}, rub)
+}
+
+func putFeedbackTemplate(ctx context.Context, q queryer, t domain.FeedbackTemplate) error {
+	return put(ctx, q, "feedback_templates", []string{"id", "teacher_id", "created_at"},
+		[]any{string(t.ID), string(t.TeacherID), stamp(t.CreatedAt)}, t)
+}
+
+func putDelegation(ctx context.Context, q queryer, d domain.Delegation) error {
+	return put(ctx, q, "delegations", []string{"id", "teacher_id", "delegate_id", "expires_at", "created_at"},
+		[]any{string(d.ID), string(d.TeacherID), string(d.DelegateID), stamp(d.ExpiresAt), stamp(d.CreatedAt)}, d)
+}
+
+func putDeviceSession(ctx context.Context, q queryer, d domain.DeviceSession) error {
+	return put(ctx, q, "device_sessions", []string{"id", "student_id", "expires_at", "created_at"},
+		[]any{string(d.ID), string(d.StudentID), stamp(d.ExpiresAt), stamp(d.CreatedAt)}, d)
+}
+
+func putBankQuestion(ctx context.Context, q queryer, b domain.BankQuestion) error {
+	return put(ctx, q, "bank_questions", []string{"id", "teacher_id", "created_at"},
+		[]any{string(b.ID), string(b.TeacherID), stamp(b.CreatedAt)}, b)
+}
+
+func putDistrict(ctx context.Context, q queryer, d domain.District) error {
+	return put(ctx, q, "districts", []string{"id", "created_at"},
+		[]any{string(d.ID), stamp(d.CreatedAt)}, d)
+}
+
+func putDistrictStaff(ctx context.Context, q queryer, s domain.DistrictStaff) error {
+	return put(ctx, q, "district_staff", []string{"id", "district_id", "created_at"},
+		[]any{string(s.ID), string(s.DistrictID), stamp(s.CreatedAt)}, s)
+}
+
+func putAuditEntry(ctx context.Context, q queryer, e domain.AuditEntry) error {
+	return put(ctx, q, "audit_entries", []string{"id", "action", "principal_id", "test_id", "created_at"},
+		[]any{string(e.ID), string(e.Action), e.PrincipalID, string(e.TestID), stamp(e.CreatedAt)}, e)
+}
+
+// mustExist fails with message when query selects no row.
+func mustExist(ctx context.Context, q queryer, message, query string, args ...any) error {
+	found, err := exists(ctx, q, query, args...)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.New(message)
+	}
+	return nil
+}
+
+// Page keys.
+
+func schoolPageKey(v domain.School) (time.Time, string)   { return v.CreatedAt, string(v.ID) }
+func gradePageKey(v domain.Grade) (time.Time, string)     { return v.CreatedAt, string(v.ID) }
+func classPageKey(v domain.Class) (time.Time, string)     { return v.CreatedAt, string(v.ID) }
+func teacherPageKey(v domain.Teacher) (time.Time, string) { return v.CreatedAt, string(v.ID) }
+func studentPageKey(v domain.Student) (time.Time, string) { return v.CreatedAt, string(v.ID) }
+func testPageKey(v domain.Test) (time.Time, string)       { return v.CreatedAt, string(v.ID) }
+func answerPageKey(v domain.Answer) (time.Time, string)   { return v.CreatedAt, string(v.ID) }
+func resultPageKey(v domain.Result) (time.Time, string)   { return v.CreatedAt, string(v.ID) }
+func rubricPageKey(v domain.Rubric) (time.Time, string)   { return v.CreatedAt, string(v.ID) }
+
+func notificationPageKey(v domain.Notification) (time.Time, string) {
+	return v.CreatedAt, string(v.ID)
+}
+
+func feedbackTemplatePageKey(v domain.FeedbackTemplate) (time.Time, string) {
+	return v.CreatedAt, string(v.ID)
+}
+
+func delegationPageKey(v domain.Delegation) (time.Time, string) {
+	return v.CreatedAt, string(v.ID)
+}
+
+func bankQuestionPageKey(v domain.BankQuestion) (time.Time, string) {
+	return v.CreatedAt, string(v.ID)
+}
+
+func districtPageKey(v domain.District) (time.Time, string) {
+	return v.CreatedAt, string(v.ID)
+}
+
+func auditEntryPageKey(v domain.AuditEntry) (time.Time, string) {
+	return v.CreatedAt, string(v.ID)
+}
@@ -0,0 +1,196 @@
+package sqlite
+
+// schema creates the tables on first open. Every table keeps the record as
+// JSON in body; the other columns mirror the fields lookups and lists use.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS schools (
+		id TEXT PRIMARY KEY,
+		district_id TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		body TEXT NOT NULL)`,
+	`CREATE INDEX IF NOT EXISTS schools_by_district ON schools (district_id, created_at, id)`,
+
+	`CREATE TABLE IF NOT EXISTS grades (
+		id TEXT PRIMARY KEY,
+		school_id TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		body TEXT NOT NULL)`,
+	`CREATE INDEX IF NOT EXISTS grades_by_school ON grades (school_id, created_at, id)`,
+
+	`CREATE TABLE IF NOT EXISTS classes (
+		id TEXT PRIMARY KEY,
+		grade_id TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		body TEXT NOT NULL)`,
+	`CREATE INDEX IF NOT EXISTS classes_by_grade ON classes (grade_id, created_at, id)`,
+
+	`CREATE TABLE IF NOT EXISTS teachers (
+		id TEXT PRIMARY KEY,
+		school_id TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		body TEXT NOT NULL)`,
+	`CREATE INDEX IF NOT EXISTS teachers_by_school ON teachers (school_id, created_at, id)`,
+
+	// Emails are stored lower-cased for case-insensitive lookups.
+	`CREATE TABLE IF NOT EXISTS students (
+		id TEXT PRIMARY KEY,
+		class_id TEXT NOT NULL,
+		email TEXT NOT NULL,
+		guardian_email TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		body TEXT NOT NULL)`,
+	`CREATE INDEX IF NOT EXISTS students_by_class ON students (class_id, created_at, id)`,
+	`CREATE INDEX IF NOT EXISTS students_by_email ON students (email)`,
+	`CREATE INDEX IF NOT EXISTS students_by_guardian_email ON students (guardian_email)`,
+
+	`CREATE TABLE IF NOT EXISTS tests (
+		id TEXT PRIMARY KEY,
+		teacher_id TEXT NOT NULL,
+		opens_at TEXT,
+		closes_at TEXT,
+		created_at TEXT NOT NULL,
+		body TEXT NOT NULL)`,
+	`CREATE INDEX IF NOT EXISTS tests_by_teacher ON tests (teacher_id, created_at, id)`,
+	`CREATE INDEX IF NOT EXISTS tests_by_window ON tests (opens_at, closes_at)`,
+
+	`CREATE TABLE IF NOT EXISTS questions (
+		id TEXT PRIMARY KEY,
+		test_id TEXT NOT NULL,
+		sequence INTEGER NOT NULL,
+		body TEXT NOT NULL)`,
+	`CREATE INDEX IF NOT EXISTS questions_by_test ON questions (test_id, sequence, id)`,
+
+	`CREATE TABLE IF NOT EXISTS assignments (
+		test_id TEXT NOT NULL,
+		student_id TEXT NOT NULL,
+		PRIMARY KEY (test_id, student_id))`,
+	`CREATE INDEX IF NOT EXISTS assignments_by_student ON assignments (student_id, test_id)`,
+
+	`CREATE TABLE IF NOT EXISTS answers (
+		id TEXT PRIMARY KEY,
+		test_id TEXT NOT NULL,
+		question_id TEXT NOT NULL,
+		student_id TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		body TEXT NOT NULL,
+		UNIQUE (test_id, question_id, student_id))`,
+	`CREATE INDEX IF NOT EXISTS answers_by_test ON answers (test_id, created_at, id)`,
+
+	// Results copy their answer's test and student to list without a join.
+	`CREATE TABLE IF NOT EXISTS results (
+		id TEXT PRIMARY KEY,
+		answer_id TEXT NOT NULL UNIQUE,
+		test_id TEXT NOT NULL,
+		student_id TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		body TEXT NOT NULL)`,
+	`CREATE INDEX IF NOT EXISTS results_by_test ON results (test_id, created_at, id)`,
+
+	`CREATE TABLE IF NOT EXISTS notifications (
+		id TEXT PRIMARY KEY,
+		role TEXT NOT NULL,
+		recipient_id TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		body TEXT NOT NULL)`,
+	`CREATE INDEX IF NOT EXISTS notifications_by_recipient ON notifications (role, recipient_id, created_at, id)`,
+
+	`CREATE TABLE IF NOT EXISTS digest_items (
+		id TEXT PRIMARY KEY,
+		occurred_at TEXT NOT NULL,
+		body TEXT NOT NULL)`,
+
+	`CREATE TABLE IF NOT EXISTS question_comments (
+		id TEXT PRIMARY KEY,
+		test_id TEXT NOT NULL,
+		question_id TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		body TEXT NOT NULL)`,
+	`CREATE INDEX IF NOT EXISTS question_comments_by_question ON question_comments (test_id, question_id, created_at, id)`,
+
+	`CREATE TABLE IF NOT EXISTS answer_comments (
+		id TEXT PRIMARY KEY,
+		test_id TEXT NOT NULL,
+		answer_id TEXT NOT NULL,
+		student_id TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		body TEXT NOT NULL)`,
+	`CREATE INDEX IF NOT EXISTS answer_comments_by_answer ON answer_comments (test_id, answer_id, created_at, id)`,
+	`CREATE INDEX IF NOT EXISTS answer_comments_by_student ON answer_comments (student_id, created_at, id)`,
+
+	`CREATE TABLE IF NOT EXISTS test_sessions (
+		test_id TEXT NOT NULL,
+		student_id TEXT NOT NULL,
+		body TEXT NOT NULL,
+		PRIMARY KEY (test_id, student_id))`,
+
+	`CREATE TABLE IF NOT EXISTS submissions (
+		test_id TEXT NOT NULL,
+		student_id TEXT NOT NULL,
+		submitted_at TEXT NOT NULL,
+		body TEXT NOT NULL,
+		PRIMARY KEY (test_id, student_id))`,
+	`CREATE INDEX IF NOT EXISTS submissions_by_test ON submissions (test_id, submitted_at, student_id)`,
+
+	`CREATE TABLE IF NOT EXISTS rubrics (
+		id TEXT PRIMARY KEY,
+		teacher_id TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		body TEXT NOT NULL)`,
+	`CREATE INDEX IF NOT EXISTS rubrics_by_teacher ON rubrics (teacher_id, created_at, id)`,
+
+	`CREATE TABLE IF NOT EXISTS feedback_templates (
+		id TEXT PRIMARY KEY,
+		teacher_id TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		body TEXT NOT NULL)`,
+	`CREATE INDEX IF NOT EXISTS feedback_templates_by_teacher ON feedback_templates (teacher_id, created_at, id)`,
+
+	`CREATE TABLE IF NOT EXISTS delegations (
+		id TEXT PRIMARY KEY,
+		teacher_id TEXT NOT NULL,
+		delegate_id TEXT NOT NULL,
+		expires_at TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		body TEXT NOT NULL)`,
+	`CREATE INDEX IF NOT EXISTS delegations_by_teacher ON delegations (teacher_id, created_at, id)`,
+	`CREATE INDEX IF NOT EXISTS delegations_by_delegate ON delegations (delegate_id, created_at, id)`,
+	`CREATE INDEX IF NOT EXISTS delegations_by_expiry ON delegations (expires_at)`,
+
+	`CREATE TABLE IF NOT EXISTS device_sessions (
+		id TEXT PRIMARY KEY,
+		student_id TEXT NOT NULL,
+		expires_at TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		body TEXT NOT NULL)`,
+	`CREATE INDEX IF NOT EXISTS device_sessions_by_student ON device_sessions (student_id, created_at, id)`,
+	`CREATE INDEX IF NOT EXISTS device_sessions_by_expiry ON device_sessions (expires_at)`,
+
+	`CREATE TABLE IF NOT EXISTS bank_questions (
+		id TEXT PRIMARY KEY,
+		teacher_id TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		body TEXT NOT NULL)`,
+	`CREATE INDEX IF NOT EXISTS bank_questions_by_teacher ON bank_questions (teacher_id, created_at, id)`,
+
+	`CREATE TABLE IF NOT EXISTS districts (
+		id TEXT PRIMARY KEY,
+		created_at TEXT NOT NULL,
+		body TEXT NOT NULL)`,
+
+	`CREATE TABLE IF NOT EXISTS district_staff (
+		id TEXT PRIMARY KEY,
+		district_id TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		body TEXT NOT NULL)`,
+
+	`CREATE TABLE IF NOT EXISTS audit_entries (
+		id TEXT PRIMARY KEY,
+		action TEXT NOT NULL,
+		principal_id TEXT NOT NULL,
+		test_id TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		body TEXT NOT NULL)`,
+	`CREATE INDEX IF NOT EXISTS audit_entries_by_time ON audit_entries (created_at, id)`,
+	`CREATE INDEX IF NOT EXISTS audit_entries_by_principal ON audit_entries (principal_id, created_at, id)`,
+	`CREATE INDEX IF NOT EXISTS audit_entries_by_test ON audit_entries (test_id, created_at, id)`,
+}
@@ -0,0 +1,448 @@
+// Package sqlite stores the repositories in an embedded SQLite database, for
+// single-node deployments that outgrow the JSON file store without needing a
+// database server.
+//
+// The package uses database/sql and links github.com/mattn/go-sqlite3,
+// registered as Driver, so binaries need cgo. Open takes the driver name so
+// that a binary may register another SQLite driver instead.
+//
+// Every record is kept as JSON next to the columns queries filter and sort
+// on, so the schema follows the domain types without a migration per field.
+// The database runs in WAL mode so reads proceed while a write commits.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/memory"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// Driver is the name of the database/sql driver the package links.
+const Driver = "sqlite3"
+
+// Repository provides a SQLite backed implementation of repository interfaces.
+type Repository struct {
+	db *sql.DB
+	// mu serialises writes, so transactions of this process never wait on
+	// each other for the database lock.
+	mu sync.Mutex
+}
+
+// Ensure interface compliance.
+var (
+	_ repository.OrganizationRepository    = (*Repository)(nil)
+	_ repository.TestRepository            = (*Repository)(nil)
+	_ repository.AnswerRepository          = (*Repository)(nil)
+	_ repository.ResultRepository          = (*Repository)(nil)
+	_ repository.NotificationRepository    = (*Repository)(nil)
+	_ repository.DigestRepository          = (*Repository)(nil)
+	_ repository.QuestionCommentRepository = (*Repository)(nil)
+	_ repository.AnswerCommentRepository   = (*Repository)(nil)
+	_ repository.TestSessionRepository     = (*Repository)(nil)
+	_ repository.SubmissionRepository      = (*Repository)(nil)
+	_ repository.RubricRepository          = (*Repository)(nil)
+	_ repository.DelegationRepository      = (*Repository)(nil)
+	_ repository.DeviceSessionRepository   = (*Repository)(nil)
+	_ repository.QuestionBankRepository    = (*Repository)(nil)
+	_ repository.DistrictRepository        = (*Repository)(nil)
+	_ repository.AuditRepository           = (*Repository)(nil)
+)
+
+// Open opens the database at path with the registered driver, creating and
+// seeding it when the file does not exist yet.
+func Open(driver, path string, seed memory.SeedData) (*Repository, error) {
+	if path == "" {
+		return nil, errors.New("sqlite: path must be provided")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	_, statErr := os.Stat(path)
+	exists := statErr == nil
+
+	ctx := context.Background()
+	db, err := sql.Open(driver, path)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: %w", err)
+	}
+	repo := &Repository{db: db}
+	if err := repo.migrate(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if !exists {
+		state := memory.NewRepository(seed).ExportState()
+		if err := repo.write(ctx, func(tx *sql.Tx) error { return insertState(ctx, tx, state) }); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	return repo, nil
+}
+
+// Close closes the database.
+func (r *Repository) Close() error {
+	return r.db.Close()
+}
+
+func (r *Repository) migrate(ctx context.Context) error {
+	// The journal mode is stored in the database file, so setting it once
+	// covers every pooled connection.
+	var mode string
+	if err := r.db.QueryRowContext(ctx, "PRAGMA journal_mode=WAL").Scan(&mode); err != nil {
+		return fmt.Errorf("sqlite: enable WAL: %w", err)
+	}
+	if !strings.EqualFold(mode, "wal") {
+		return fmt.Errorf("sqlite: enable WAL: journal mode is %s", mode)
+	}
+	return r.write(ctx, func(tx *sql.Tx) error {
+		for _, stmt := range schema {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				return fmt.Errorf("sqlite: migrate: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// Sandbox returns an in-memory copy of the live data. Writes to the copy are
+// never persisted and never reach the live repository.
+func (r *Repository) Sandbox() (*memory.Repository, error) {
+	state, err := r.ExportState()
+	if err != nil {
+		return nil, err
+	}
+	return memory.NewRepositoryFromState(state), nil
+}
+
+// ExportState returns a snapshot of the live data. The errors of tables that
+// cannot be read are returned with the rest of the data.
+func (r *Repository) ExportState() (memory.State, error) {
+	return r.exportState(context.Background())
+}
+
+// Reindex rebuilds the indexes of every table.
+func (r *Repository) Reindex(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.db.ExecContext(ctx, "REINDEX"); err != nil {
+		return fmt.Errorf("sqlite: reindex: %w", err)
+	}
+	return nil
+}
+
+// Snapshot streams the data as the JSON state the file store writes, so a
+// backup can be restored into either backend.
+func (r *Repository) Snapshot(ctx context.Context, w io.Writer) error {
+	state, err := r.exportState(ctx)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(state)
+}
+
+func (r *Repository) exportState(ctx context.Context) (memory.State, error) {
+	// One read transaction sees a single point in time across tables.
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return memory.State{}, err
+	}
+	defer tx.Rollback()
+
+	state := memory.State{Assignments: make(map[string][]domain.StudentID)}
+	var errs []error
+	collect := func(err error) { errs = append(errs, err) }
+	state.Schools, err = list[domain.School](ctx, tx, "SELECT body FROM schools ORDER BY created_at, id")
+	collect(err)
+	state.Grades, err = list[domain.Grade](ctx, tx, "SELECT body FROM grades ORDER BY created_at, id")
+	collect(err)
+	state.Classes, err = list[domain.Class](ctx, tx, "SELECT body FROM classes ORDER BY created_at, id")
+	collect(err)
+	state.Teachers, err = list[domain.Teacher](ctx, tx, "SELECT body FROM teachers ORDER BY created_at, id")
+	collect(err)
+	state.Students, err = list[domain.Student](ctx, tx, "SELECT body FROM students ORDER BY created_at, id")
+	collect(err)
+	state.Tests, err = list[domain.Test](ctx, tx, "SELECT body FROM tests ORDER BY created_at, id")
+	collect(err)
+	state.Questions, err = list[domain.Question](ctx, tx, "SELECT body FROM questions ORDER BY test_id, sequence, id")
+	collect(err)
+	collect(exportAssignments(ctx, tx, state.Assignments))
+	state.Answers, err = list[domain.Answer](ctx, tx, "SELECT body FROM answers ORDER BY created_at, id")
+	collect(err)
+	state.Results, err = list[domain.Result](ctx, tx, "SELECT body FROM results ORDER BY created_at, id")
+	collect(err)
+	state.Notifications, err = list[domain.Notification](ctx, tx, "SELECT body FROM notifications ORDER BY created_at, id")
+	collect(err)
+	state.Digests, err = list[domain.DigestItem](ctx, tx, "SELECT body FROM digest_items ORDER BY occurred_at, id")
+	collect(err)
+	state.Comments, err = list[domain.QuestionComment](ctx, tx, "SELECT body FROM question_comments ORDER BY created_at, id")
+	collect(err)
+	state.Feedback, err = list[domain.AnswerComment](ctx, tx, "SELECT body FROM answer_comments ORDER BY created_at, id")
+	collect(err)
+	state.Sessions, err = list[domain.TestSession](ctx, tx, "SELECT body FROM test_sessions ORDER BY test_id, student_id")
+	collect(err)
+	state.Submissions, err = list[domain.Submission](ctx, tx, "SELECT body FROM submissions ORDER BY submitted_at, test_id, student_id")
+	collect(err)
+	state.Rubrics, err = list[domain.Rubric](ctx, tx, "SELECT body FROM rubrics ORDER BY created_at, id")
+	collect(err)
+	state.Templates, err = list[domain.FeedbackTemplate](ctx, tx, "SELECT body FROM feedback_templates ORDER BY created_at, id")
+	collect(err)
+	state.Delegations, err = list[domain.Delegation](ctx, tx, "SELECT body FROM delegations ORDER BY created_at, id")
+	collect(err)
+	state.Devices, err = list[domain.DeviceSession](ctx, tx, "SELECT body FROM device_sessions ORDER BY created_at, id")
+	collect(err)
+	state.BankQuestions, err = list[domain.BankQuestion](ctx, tx, "SELECT body FROM bank_questions ORDER BY created_at, id")
+	collect(err)
+	state.Districts, err = list[domain.District](ctx, tx, "SELECT body FROM districts ORDER BY created_at, id")
+	collect(err)
+	state.DistrictStaff, err = list[domain.DistrictStaff](ctx, tx, "SELECT body FROM district_staff ORDER BY created_at, id")
+	collect(err)
+	state.Audit, err = list[domain.AuditEntry](ctx, tx, "SELECT body FROM audit_entries ORDER BY created_at, id")
+	collect(err)
+	return state, errors.Join(errs...)
+}
+
+func exportAssignments(ctx context.Context, q queryer, into map[string][]domain.StudentID) error {
+	rows, err := q.QueryContext(ctx, "SELECT test_id, student_id FROM assignments ORDER BY test_id, student_id")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var testID, studentID string
+		if err := rows.Scan(&testID, &studentID); err != nil {
+			return err
+		}
+		into[testID] = append(into[testID], domain.StudentID(studentID))
+	}
+	return rows.Err()
+}
+
+func insertState(ctx context.Context, tx *sql.Tx, state memory.State) error {
+	var errs []error
+	for _, s := range state.Schools {
+		errs = append(errs, putSchool(ctx, tx, s))
+	}
+	for _, g := range state.Grades {
+		errs = append(errs, putGrade(ctx, tx, g))
+	}
+	for _, c := range state.Classes {
+		errs = append(errs, putClass(ctx, tx, c))
+	}
+	for _, t := range state.Teachers {
+		errs = append(errs, putTeacher(ctx, tx, t))
+	}
+	for _, s := range state.Students {
+		errs = append(errs, putStudent(ctx, tx, s))
+	}
+	for _, t := range state.Tests {
+		errs = append(errs, putTest(ctx, tx, t))
+	}
+	for _, q := range state.Questions {
+		errs = append(errs, putQuestion(ctx, tx, q))
+	}
+	for testID, students := range state.Assignments {
+		for _, studentID := range students {
+			errs = append(errs, assign(ctx, tx, domain.TestID(testID), studentID))
+		}
+	}
+	for _, a := range state.Answers {
+		errs = append(errs, putAnswer(ctx, tx, a))
+	}
+	for _, res := range state.Results {
+		errs = append(errs, putResult(ctx, tx, res))
+	}
+	for _, n := range state.Notifications {
+		errs = append(errs, putNotification(ctx, tx, n))
+	}
+	for _, item := range state.Digests {
+		errs = append(errs, putDigestItem(ctx, tx, item))
+	}
+	for _, c := range state.Comments {
+		errs = append(errs, putQuestionComment(ctx, tx, c))
+	}
+	for _, c := range state.Feedback {
+		errs = append(errs, putAnswerComment(ctx, tx, c))
+	}
+	for _, s := range state.Sessions {
+		errs = append(errs, putTestSession(ctx, tx, s))
+	}
+	for _, s := range state.Submissions {
+		errs = append(errs, putSubmission(ctx, tx, s))
+	}
+	for _, rub := range state.Rubrics {
+		errs = append(errs, putRubric(ctx, tx, rub))
+	}
+	for _, t := range state.Templates {
+		errs = append(errs, putFeedbackTemplate(ctx, tx, t))
+	}
+	for _, d := range state.Delegations {
+		errs = append(errs, putDelegation(ctx, tx, d))
+	}
+	for _, d := range state.Devices {
+		errs = append(errs, putDeviceSession(ctx, tx, d))
+	}
+	for _, q := range state.BankQuestions {
+		errs = append(errs, putBankQuestion(ctx, tx, q))
+	}
+	for _, d := range state.Districts {
+		errs = append(errs, putDistrict(ctx, tx, d))
+	}
+	for _, s := range state.DistrictStaff {
+		errs = append(errs, putDistrictStaff(ctx, tx, s))
+	}
+	for _, e := range state.Audit {
+		errs = append(errs, putAuditEntry(ctx, tx, e))
+	}
+	return errors.Join(errs...)
+}
+
+// Helpers.
+
+// queryer is satisfied by both *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// write runs fn in a transaction, committing when it succeeds.
+func (r *Repository) write(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// timeLayout has fixed-width fractions so stored times sort as text.
+const timeLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+func stamp(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// nullStamp stores a missing time as NULL.
+func nullStamp(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return stamp(*t)
+}
+
+// put inserts or replaces the row of v, keyed and indexed by columns.
+func put(ctx context.Context, q queryer, table string, columns []string, values []any, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	placeholders := strings.Repeat("?, ", len(columns)) + "?"
+	query := "INSERT OR REPLACE INTO " + table + " (" + strings.Join(columns, ", ") + ", body) VALUES (" + placeholders + ")"
+	_, err = q.ExecContext(ctx, query, append(values, string(body))...)
+	return err
+}
+
+// get returns the record selected by query, or nil when there is none.
+func get[T any](ctx context.Context, q queryer, query string, args ...any) (*T, error) {
+	var body string
+	err := q.QueryRowContext(ctx, query, args...).Scan(&body)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var v T
+	if err := json.Unmarshal([]byte(body), &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// list returns the records selected by query, never nil.
+func list[T any](ctx context.Context, q queryer, query string, args ...any) ([]T, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]T, 0)
+	for rows.Next() {
+		var body string
+		if err := rows.Scan(&body); err != nil {
+			return nil, err
+		}
+		var v T
+		if err := json.Unmarshal([]byte(body), &v); err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+	}
+	return items, rows.Err()
+}
+
+func exists(ctx context.Context, q queryer, query string, args ...any) (bool, error) {
+	var found bool
+	err := q.QueryRowContext(ctx, "SELECT EXISTS ("+query+")", args...).Scan(&found)
+	return found, err
+}
+
+// page returns one page of the rows of table matching filter, in creation
+// order or newest first. Tables listed this way have id and created_at
+// columns.
+func page[T any](ctx context.Context, q queryer, table, filter string, args []any, req repository.PageRequest, newestFirst bool, key func(T) (time.Time, string)) (repository.Page[T], error) {
+	cmp, order := ">", "ASC"
+	if newestFirst {
+		cmp, order = "<", "DESC"
+	}
+	query := "SELECT body FROM " + table + " WHERE " + filter
+	if req.Cursor != "" {
+		afterAt, afterID, err := repository.DecodeCursor(req.Cursor)
+		if err != nil {
+			return repository.Page[T]{}, err
+		}
+		query += " AND (created_at " + cmp + " ? OR (created_at = ? AND id " + cmp + " ?))"
+		args = append(args, stamp(afterAt), stamp(afterAt), afterID)
+	}
+	query += " ORDER BY created_at " + order + ", id " + order
+	if req.Limit > 0 {
+		// One extra row tells whether another page follows.
+		query += " LIMIT ?"
+		args = append(args, req.Limit+1)
+	}
+
+	items, err := list[T](ctx, q, query, args...)
+	if err != nil {
+		return repository.Page[T]{}, err
+	}
+	result := repository.Page[T]{Items: items}
+	if req.Limit > 0 && len(items) > req.Limit {
+		result.Items = items[:req.Limit]
+		result.NextCursor = repository.EncodeCursor(key(result.Items[req.Limit-1]))
+	}
+	return result, nil
+}
@@ -0,0 +1,154 @@
+package sqlite_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/memory"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+	"github.com/sky0621/go_work_sample/core/pkg/storage/sqlite"
+)
+
+func open(t *testing.T, path string, seed memory.SeedData) *sqlite.Repository {
+	t.Helper()
+	repo, err := sqlite.Open(sqlite.Driver, path, seed)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+func TestRepositoryStoresAndPagesRecords(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state.db")
+	school := fixtures.NewSchool().WithStudents(2)
+	fx := school.Build()
+	repo := open(t, path, school.Seed())
+
+	now := time.Now().UTC()
+	opens, closes := now.Add(time.Hour), now.Add(2*time.Hour)
+	for i, testID := range []domain.TestID{"test-a", "test-b", "test-c"} {
+		test := &domain.Test{ID: testID, TeacherID: fx.Teacher(0), Title: string(testID), OpensAt: &opens, ClosesAt: &closes, CreatedAt: now.Add(time.Duration(i) * time.Second)}
+		questions := []domain.Question{{ID: domain.QuestionID(testID + "-q1"), TestID: testID, Sequence: 1, Prompt: "?", Points: 10}}
+		if err := repo.CreateTest(ctx, test, questions, []domain.StudentID{fx.Student(0)}); err != nil {
+			t.Fatalf("CreateTest failed: %v", err)
+		}
+	}
+	if err := repo.CreateTest(ctx, &domain.Test{ID: "test-a", TeacherID: fx.Teacher(0)}, nil, nil); err == nil {
+		t.Fatalf("expected a duplicate test to be rejected")
+	}
+	if err := repo.CreateTest(ctx, &domain.Test{ID: "test-x", TeacherID: "nobody"}, nil, nil); err == nil {
+		t.Fatalf("expected a test of an unknown teacher to be rejected")
+	}
+
+	first, err := repo.ListTestsForStudent(ctx, fx.Student(0), repository.PageRequest{Limit: 2})
+	if err != nil || len(first.Items) != 2 || first.NextCursor == "" {
+		t.Fatalf("expected a first page of two tests, got %+v, %v", first, err)
+	}
+	rest, err := repo.ListTestsForStudent(ctx, fx.Student(0), repository.PageRequest{Limit: 2, Cursor: first.NextCursor})
+	if err != nil || len(rest.Items) != 1 || rest.Items[0].ID != "test-c" || rest.NextCursor != "" {
+		t.Fatalf("expected the last test on the second page, got %+v, %v", rest, err)
+	}
+	if window, err := repo.ListTestsInWindow(ctx, now, now.Add(90*time.Minute)); err != nil || len(window) != 3 {
+		t.Fatalf("expected every test in the window, got %d, %v", len(window), err)
+	}
+	if assigned, _ := repo.IsStudentAssigned(ctx, "test-a", fx.Student(1)); assigned {
+		t.Fatalf("expected the second student not to be assigned")
+	}
+
+	answer := &domain.Answer{ID: "answer-1", TestID: "test-a", QuestionID: "test-a-q1", StudentID: fx.Student(0), Response: "42", CreatedAt: now}
+	if err := repo.UpsertAnswer(ctx, answer); err != nil {
+		t.Fatalf("UpsertAnswer failed: %v", err)
+	}
+	answer.Response = "43"
+	if err := repo.UpsertAnswer(ctx, answer); err != nil {
+		t.Fatalf("second UpsertAnswer failed: %v", err)
+	}
+	if got, err := repo.GetAnswer(ctx, "test-a", "test-a-q1", fx.Student(0)); err != nil || got == nil || got.Response != "43" {
+		t.Fatalf("expected the updated answer, got %+v, %v", got, err)
+	}
+	if err := repo.SaveResults(ctx, []domain.Result{{ID: "r-1", AnswerID: "answer-1", Score: 7}, {ID: "r-2", AnswerID: "missing"}}); err == nil {
+		t.Fatalf("expected a result for an unknown answer to fail the batch")
+	}
+	if got, _ := repo.GetResult(ctx, "answer-1"); got != nil {
+		t.Fatalf("expected the failed batch to save nothing, got %+v", got)
+	}
+	if err := repo.SaveResult(ctx, &domain.Result{ID: "r-1", AnswerID: "answer-1", Score: 7, CreatedAt: now}); err != nil {
+		t.Fatalf("SaveResult failed: %v", err)
+	}
+	if results, err := repo.ListResultsByStudent(ctx, "test-a", fx.Student(0)); err != nil || len(results) != 1 || results[0].Score != 7 {
+		t.Fatalf("expected the student's result, got %+v, %v", results, err)
+	}
+	if snapshot, err := repo.SnapshotGrading(ctx, "test-a"); err != nil || len(snapshot.Answers) != 1 || len(snapshot.Results) != 1 || snapshot.Results[0].AnswerID != "answer-1" {
+		t.Fatalf("expected the answer and its result in the snapshot, got %+v, %v", snapshot, err)
+	}
+
+	student, _ := repo.GetStudent(ctx, fx.Student(1))
+	student.GuardianEmail = "Parent@Example.com"
+	if err := repo.UpdateStudent(ctx, student); err != nil {
+		t.Fatalf("UpdateStudent failed: %v", err)
+	}
+	if found, err := repo.FindStudentsByEmail(ctx, " parent@example.COM "); err != nil || len(found) != 1 || found[0].ID != fx.Student(1) {
+		t.Fatalf("expected a case-insensitive guardian match, got %+v, %v", found, err)
+	}
+
+	for i, id := range []domain.NotificationID{"n-1", "n-2"} {
+		n := &domain.Notification{ID: id, Role: domain.RoleStudent, RecipientID: string(fx.Student(0)), CreatedAt: now.Add(time.Duration(i) * time.Second)}
+		if err := repo.SaveNotification(ctx, n); err != nil {
+			t.Fatalf("SaveNotification failed: %v", err)
+		}
+	}
+	if err := repo.MarkNotificationsRead(ctx, domain.RoleStudent, string(fx.Student(0)), []domain.NotificationID{"n-1"}, now); err != nil {
+		t.Fatalf("MarkNotificationsRead failed: %v", err)
+	}
+	inbox, err := repo.ListNotifications(ctx, domain.RoleStudent, string(fx.Student(0)), repository.All)
+	if err != nil || len(inbox.Items) != 2 || inbox.Items[0].ID != "n-2" || inbox.Items[0].ReadAt != nil || inbox.Items[1].ReadAt == nil {
+		t.Fatalf("expected the newest notification first and only n-1 read, got %+v, %v", inbox.Items, err)
+	}
+
+	delegation := &domain.Delegation{ID: "d-1", TeacherID: fx.Teacher(0), DelegateID: fx.Teacher(0), ExpiresAt: now, CreatedAt: now}
+	if err := repo.SaveDelegation(ctx, delegation); err != nil {
+		t.Fatalf("SaveDelegation failed: %v", err)
+	}
+	if expired, err := repo.DeleteExpiredDelegations(ctx, now); err != nil || len(expired) != 1 {
+		t.Fatalf("expected the delegation to expire, got %+v, %v", expired, err)
+	}
+
+	var snapshot bytes.Buffer
+	if err := repo.Snapshot(ctx, &snapshot); err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	var state memory.State
+	if err := json.Unmarshal(snapshot.Bytes(), &state); err != nil {
+		t.Fatalf("snapshot is not a JSON state: %v", err)
+	}
+	if len(state.Tests) != 3 || len(state.Answers) != 1 || len(state.Results) != 1 || len(state.Assignments["test-a"]) != 1 {
+		t.Fatalf("unexpected snapshot %+v", state)
+	}
+}
+
+func TestRepositorySeedsOnlyNewDatabases(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state.db")
+	seed := fixtures.NewSchool().Seed()
+	repo := open(t, path, seed)
+	school := seed.Schools[0]
+	school.Name = "Renamed"
+	if err := repo.UpdateSchool(ctx, &school); err != nil {
+		t.Fatalf("UpdateSchool failed: %v", err)
+	}
+	repo.Close()
+
+	reopened := open(t, path, seed)
+	got, err := reopened.GetSchool(ctx, school.ID)
+	if err != nil || got == nil || got.Name != "Renamed" {
+		t.Fatalf("expected the stored school to survive reopening, got %+v, %v", got, err)
+	}
+}
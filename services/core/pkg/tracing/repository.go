@@ -0,0 +1,360 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// Repository calls are traced through wrappers. Each call records a span
+// named after the interface and method, as a child of the span in the
+// context it was made with, and passes the span on to the repository.
+
+func call[T any](ctx context.Context, name string, fn func(ctx context.Context) (T, error)) (T, error) {
+	ctx, span := Start(ctx, name, WithKind(KindClient), WithAttributes(String("db.operation.name", name)))
+	out, err := fn(ctx)
+	span.SetError(err)
+	span.End()
+	return out, err
+}
+
+func exec(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	_, err := call(ctx, name, func(ctx context.Context) (struct{}, error) { return struct{}{}, fn(ctx) })
+	return err
+}
+
+// OrganizationReader traces the calls made to repo.
+func OrganizationReader(repo repository.OrganizationReader) repository.OrganizationReader {
+	return organizationReader{repo: repo}
+}
+
+type organizationReader struct {
+	repo repository.OrganizationReader
+}
+
+func (r organizationReader) ListSchools(ctx context.Context, page repository.PageRequest) (repository.Page[domain.School], error) {
+	return call(ctx, "OrganizationReader.ListSchools", func(ctx context.Context) (repository.Page[domain.School], error) {
+		return r.repo.ListSchools(ctx, page)
+	})
+}
+
+func (r organizationReader) GetSchool(ctx context.Context, id domain.SchoolID) (*domain.School, error) {
+	return call(ctx, "OrganizationReader.GetSchool", func(ctx context.Context) (*domain.School, error) { return r.repo.GetSchool(ctx, id) })
+}
+
+func (r organizationReader) GetGrade(ctx context.Context, id domain.GradeID) (*domain.Grade, error) {
+	return call(ctx, "OrganizationReader.GetGrade", func(ctx context.Context) (*domain.Grade, error) { return r.repo.GetGrade(ctx, id) })
+}
+
+func (r organizationReader) GetClass(ctx context.Context, id domain.ClassID) (*domain.Class, error) {
+	return call(ctx, "OrganizationReader.GetClass", func(ctx context.Context) (*domain.Class, error) { return r.repo.GetClass(ctx, id) })
+}
+
+func (r organizationReader) GetTeacher(ctx context.Context, id domain.TeacherID) (*domain.Teacher, error) {
+	return call(ctx, "OrganizationReader.GetTeacher", func(ctx context.Context) (*domain.Teacher, error) { return r.repo.GetTeacher(ctx, id) })
+}
+
+func (r organizationReader) GetStudent(ctx context.Context, id domain.StudentID) (*domain.Student, error) {
+	return call(ctx, "OrganizationReader.GetStudent", func(ctx context.Context) (*domain.Student, error) { return r.repo.GetStudent(ctx, id) })
+}
+
+func (r organizationReader) ListGrades(ctx context.Context, schoolID domain.SchoolID, page repository.PageRequest) (repository.Page[domain.Grade], error) {
+	return call(ctx, "OrganizationReader.ListGrades", func(ctx context.Context) (repository.Page[domain.Grade], error) {
+		return r.repo.ListGrades(ctx, schoolID, page)
+	})
+}
+
+func (r organizationReader) ListClasses(ctx context.Context, gradeID domain.GradeID, page repository.PageRequest) (repository.Page[domain.Class], error) {
+	return call(ctx, "OrganizationReader.ListClasses", func(ctx context.Context) (repository.Page[domain.Class], error) {
+		return r.repo.ListClasses(ctx, gradeID, page)
+	})
+}
+
+func (r organizationReader) ListStudents(ctx context.Context, classID domain.ClassID, page repository.PageRequest) (repository.Page[domain.Student], error) {
+	return call(ctx, "OrganizationReader.ListStudents", func(ctx context.Context) (repository.Page[domain.Student], error) {
+		return r.repo.ListStudents(ctx, classID, page)
+	})
+}
+
+func (r organizationReader) ListTeachers(ctx context.Context, schoolID domain.SchoolID, page repository.PageRequest) (repository.Page[domain.Teacher], error) {
+	return call(ctx, "OrganizationReader.ListTeachers", func(ctx context.Context) (repository.Page[domain.Teacher], error) {
+		return r.repo.ListTeachers(ctx, schoolID, page)
+	})
+}
+
+func (r organizationReader) FindStudentsByEmail(ctx context.Context, email string) ([]domain.Student, error) {
+	return call(ctx, "OrganizationReader.FindStudentsByEmail", func(ctx context.Context) ([]domain.Student, error) { return r.repo.FindStudentsByEmail(ctx, email) })
+}
+
+// TestRepository traces the calls made to repo.
+func TestRepository(repo repository.TestRepository) repository.TestRepository {
+	return testRepository{repo: repo}
+}
+
+type testRepository struct {
+	repo repository.TestRepository
+}
+
+func (r testRepository) GetTest(ctx context.Context, id domain.TestID) (*domain.Test, error) {
+	return call(ctx, "TestRepository.GetTest", func(ctx context.Context) (*domain.Test, error) { return r.repo.GetTest(ctx, id) })
+}
+
+func (r testRepository) ListTestsByTeacher(ctx context.Context, teacherID domain.TeacherID, page repository.PageRequest) (repository.Page[domain.Test], error) {
+	return call(ctx, "TestRepository.ListTestsByTeacher", func(ctx context.Context) (repository.Page[domain.Test], error) {
+		return r.repo.ListTestsByTeacher(ctx, teacherID, page)
+	})
+}
+
+func (r testRepository) ListTestsForStudent(ctx context.Context, studentID domain.StudentID, page repository.PageRequest) (repository.Page[domain.Test], error) {
+	return call(ctx, "TestRepository.ListTestsForStudent", func(ctx context.Context) (repository.Page[domain.Test], error) {
+		return r.repo.ListTestsForStudent(ctx, studentID, page)
+	})
+}
+
+func (r testRepository) ListTestsInWindow(ctx context.Context, from, to time.Time) ([]domain.Test, error) {
+	return call(ctx, "TestRepository.ListTestsInWindow", func(ctx context.Context) ([]domain.Test, error) { return r.repo.ListTestsInWindow(ctx, from, to) })
+}
+
+func (r testRepository) ListQuestions(ctx context.Context, testID domain.TestID) ([]domain.Question, error) {
+	return call(ctx, "TestRepository.ListQuestions", func(ctx context.Context) ([]domain.Question, error) { return r.repo.ListQuestions(ctx, testID) })
+}
+
+func (r testRepository) GetQuestion(ctx context.Context, testID domain.TestID, questionID domain.QuestionID) (*domain.Question, error) {
+	return call(ctx, "TestRepository.GetQuestion", func(ctx context.Context) (*domain.Question, error) {
+		return r.repo.GetQuestion(ctx, testID, questionID)
+	})
+}
+
+func (r testRepository) HasQuestion(ctx context.Context, testID domain.TestID, questionID domain.QuestionID) (bool, error) {
+	return call(ctx, "TestRepository.HasQuestion", func(ctx context.Context) (bool, error) { return r.repo.HasQuestion(ctx, testID, questionID) })
+}
+
+func (r testRepository) IsStudentAssigned(ctx context.Context, testID domain.TestID, studentID domain.StudentID) (bool, error) {
+	return call(ctx, "TestRepository.IsStudentAssigned", func(ctx context.Context) (bool, error) { return r.repo.IsStudentAssigned(ctx, testID, studentID) })
+}
+
+func (r testRepository) CreateTest(ctx context.Context, test *domain.Test, questions []domain.Question, studentIDs []domain.StudentID) error {
+	return exec(ctx, "TestRepository.CreateTest", func(ctx context.Context) error { return r.repo.CreateTest(ctx, test, questions, studentIDs) })
+}
+
+func (r testRepository) UpdateTest(ctx context.Context, test *domain.Test) error {
+	return exec(ctx, "TestRepository.UpdateTest", func(ctx context.Context) error { return r.repo.UpdateTest(ctx, test) })
+}
+
+func (r testRepository) UpdateAssignments(ctx context.Context, testID domain.TestID, add, remove []domain.StudentID) error {
+	return exec(ctx, "TestRepository.UpdateAssignments", func(ctx context.Context) error { return r.repo.UpdateAssignments(ctx, testID, add, remove) })
+}
+
+func (r testRepository) UpdateQuestion(ctx context.Context, question *domain.Question) error {
+	return exec(ctx, "TestRepository.UpdateQuestion", func(ctx context.Context) error { return r.repo.UpdateQuestion(ctx, question) })
+}
+
+func (r testRepository) ReorderQuestions(ctx context.Context, testID domain.TestID, sequences map[domain.QuestionID]int) error {
+	return exec(ctx, "TestRepository.ReorderQuestions", func(ctx context.Context) error { return r.repo.ReorderQuestions(ctx, testID, sequences) })
+}
+
+// AnswerRepository traces the calls made to repo.
+func AnswerRepository(repo repository.AnswerRepository) repository.AnswerRepository {
+	return answerRepository{repo: repo}
+}
+
+type answerRepository struct {
+	repo repository.AnswerRepository
+}
+
+func (r answerRepository) GetAnswer(ctx context.Context, testID domain.TestID, questionID domain.QuestionID, studentID domain.StudentID) (*domain.Answer, error) {
+	return call(ctx, "AnswerRepository.GetAnswer", func(ctx context.Context) (*domain.Answer, error) {
+		return r.repo.GetAnswer(ctx, testID, questionID, studentID)
+	})
+}
+
+func (r answerRepository) ListAnswers(ctx context.Context, testID domain.TestID, studentID domain.StudentID) ([]domain.Answer, error) {
+	return call(ctx, "AnswerRepository.ListAnswers", func(ctx context.Context) ([]domain.Answer, error) { return r.repo.ListAnswers(ctx, testID, studentID) })
+}
+
+func (r answerRepository) ListAnswersByTest(ctx context.Context, testID domain.TestID, page repository.PageRequest) (repository.Page[domain.Answer], error) {
+	return call(ctx, "AnswerRepository.ListAnswersByTest", func(ctx context.Context) (repository.Page[domain.Answer], error) {
+		return r.repo.ListAnswersByTest(ctx, testID, page)
+	})
+}
+
+func (r answerRepository) UpsertAnswer(ctx context.Context, answer *domain.Answer) error {
+	return exec(ctx, "AnswerRepository.UpsertAnswer", func(ctx context.Context) error { return r.repo.UpsertAnswer(ctx, answer) })
+}
+
+func (r answerRepository) DeleteAnswer(ctx context.Context, testID domain.TestID, questionID domain.QuestionID, studentID domain.StudentID) error {
+	return exec(ctx, "AnswerRepository.DeleteAnswer", func(ctx context.Context) error { return r.repo.DeleteAnswer(ctx, testID, questionID, studentID) })
+}
+
+// ResultRepository traces the calls made to repo.
+func ResultRepository(repo repository.ResultRepository) repository.ResultRepository {
+	return resultRepository{repo: repo}
+}
+
+type resultRepository struct {
+	repo repository.ResultRepository
+}
+
+func (r resultRepository) GetResult(ctx context.Context, answerID domain.AnswerID) (*domain.Result, error) {
+	return call(ctx, "ResultRepository.GetResult", func(ctx context.Context) (*domain.Result, error) { return r.repo.GetResult(ctx, answerID) })
+}
+
+func (r resultRepository) ListResultsByTest(ctx context.Context, testID domain.TestID, page repository.PageRequest) (repository.Page[domain.Result], error) {
+	return call(ctx, "ResultRepository.ListResultsByTest", func(ctx context.Context) (repository.Page[domain.Result], error) {
+		return r.repo.ListResultsByTest(ctx, testID, page)
+	})
+}
+
+func (r resultRepository) ListResultsByStudent(ctx context.Context, testID domain.TestID, studentID domain.StudentID) ([]domain.Result, error) {
+	return call(ctx, "ResultRepository.ListResultsByStudent", func(ctx context.Context) ([]domain.Result, error) {
+		return r.repo.ListResultsByStudent(ctx, testID, studentID)
+	})
+}
+
+func (r resultRepository) SnapshotGrading(ctx context.Context, testID domain.TestID) (repository.GradingSnapshot, error) {
+	return call(ctx, "ResultRepository.SnapshotGrading", func(ctx context.Context) (repository.GradingSnapshot, error) {
+		return r.repo.SnapshotGrading(ctx, testID)
+	})
+}
+
+func (r resultRepository) SaveResult(ctx context.Context, result *domain.Result) error {
+	return exec(ctx, "ResultRepository.SaveResult", func(ctx context.Context) error { return r.repo.SaveResult(ctx, result) })
+}
+
+func (r resultRepository) SaveResults(ctx context.Context, results []domain.Result) error {
+	return exec(ctx, "ResultRepository.SaveResults", func(ctx context.Context) error { return r.repo.SaveResults(ctx, results) })
+}
+
+// DelegationReader traces the calls made to repo. A nil repo stays nil.
+func DelegationReader(repo repository.DelegationReader) repository.DelegationReader {
+	if repo == nil {
+		return nil
+	}
+	return delegationReader{repo: repo}
+}
+
+type delegationReader struct {
+	repo repository.DelegationReader
+}
+
+func (r delegationReader) GetDelegation(ctx context.Context, id domain.DelegationID) (*domain.Delegation, error) {
+	return call(ctx, "DelegationReader.GetDelegation", func(ctx context.Context) (*domain.Delegation, error) { return r.repo.GetDelegation(ctx, id) })
+}
+
+func (r delegationReader) ListDelegationsByTeacher(ctx context.Context, teacherID domain.TeacherID, page repository.PageRequest) (repository.Page[domain.Delegation], error) {
+	return call(ctx, "DelegationReader.ListDelegationsByTeacher", func(ctx context.Context) (repository.Page[domain.Delegation], error) {
+		return r.repo.ListDelegationsByTeacher(ctx, teacherID, page)
+	})
+}
+
+func (r delegationReader) ListDelegationsForDelegate(ctx context.Context, delegateID domain.TeacherID) ([]domain.Delegation, error) {
+	return call(ctx, "DelegationReader.ListDelegationsForDelegate", func(ctx context.Context) ([]domain.Delegation, error) {
+		return r.repo.ListDelegationsForDelegate(ctx, delegateID)
+	})
+}
+
+// QuestionBankReader traces the calls made to repo. A nil repo stays nil.
+func QuestionBankReader(repo repository.QuestionBankReader) repository.QuestionBankReader {
+	if repo == nil {
+		return nil
+	}
+	return questionBankReader{repo: repo}
+}
+
+type questionBankReader struct {
+	repo repository.QuestionBankReader
+}
+
+func (r questionBankReader) GetBankQuestion(ctx context.Context, id domain.BankQuestionID) (*domain.BankQuestion, error) {
+	return call(ctx, "QuestionBankReader.GetBankQuestion", func(ctx context.Context) (*domain.BankQuestion, error) { return r.repo.GetBankQuestion(ctx, id) })
+}
+
+func (r questionBankReader) ListBankQuestions(ctx context.Context, teacherID domain.TeacherID, page repository.PageRequest) (repository.Page[domain.BankQuestion], error) {
+	return call(ctx, "QuestionBankReader.ListBankQuestions", func(ctx context.Context) (repository.Page[domain.BankQuestion], error) {
+		return r.repo.ListBankQuestions(ctx, teacherID, page)
+	})
+}
+
+// SubmissionRepository traces the calls made to repo. A nil repo stays nil.
+func SubmissionRepository(repo repository.SubmissionRepository) repository.SubmissionRepository {
+	if repo == nil {
+		return nil
+	}
+	return submissionRepository{repo: repo}
+}
+
+type submissionRepository struct {
+	repo repository.SubmissionRepository
+}
+
+func (r submissionRepository) GetSubmission(ctx context.Context, testID domain.TestID, studentID domain.StudentID) (*domain.Submission, error) {
+	return call(ctx, "SubmissionRepository.GetSubmission", func(ctx context.Context) (*domain.Submission, error) {
+		return r.repo.GetSubmission(ctx, testID, studentID)
+	})
+}
+
+func (r submissionRepository) ListSubmissionsByTest(ctx context.Context, testID domain.TestID) ([]domain.Submission, error) {
+	return call(ctx, "SubmissionRepository.ListSubmissionsByTest", func(ctx context.Context) ([]domain.Submission, error) {
+		return r.repo.ListSubmissionsByTest(ctx, testID)
+	})
+}
+
+func (r submissionRepository) SaveSubmission(ctx context.Context, submission *domain.Submission) error {
+	return exec(ctx, "SubmissionRepository.SaveSubmission", func(ctx context.Context) error { return r.repo.SaveSubmission(ctx, submission) })
+}
+
+// TestSessionRepository traces the calls made to repo. A nil repo stays nil.
+func TestSessionRepository(repo repository.TestSessionRepository) repository.TestSessionRepository {
+	if repo == nil {
+		return nil
+	}
+	return testSessionRepository{repo: repo}
+}
+
+type testSessionRepository struct {
+	repo repository.TestSessionRepository
+}
+
+func (r testSessionRepository) GetTestSession(ctx context.Context, testID domain.TestID, studentID domain.StudentID) (*domain.TestSession, error) {
+	return call(ctx, "TestSessionRepository.GetTestSession", func(ctx context.Context) (*domain.TestSession, error) {
+		return r.repo.GetTestSession(ctx, testID, studentID)
+	})
+}
+
+func (r testSessionRepository) SaveTestSession(ctx context.Context, session *domain.TestSession) error {
+	return exec(ctx, "TestSessionRepository.SaveTestSession", func(ctx context.Context) error { return r.repo.SaveTestSession(ctx, session) })
+}
+
+// AnswerCommentRepository traces the calls made to repo. A nil repo stays nil.
+func AnswerCommentRepository(repo repository.AnswerCommentRepository) repository.AnswerCommentRepository {
+	if repo == nil {
+		return nil
+	}
+	return answerCommentRepository{repo: repo}
+}
+
+type answerCommentRepository struct {
+	repo repository.AnswerCommentRepository
+}
+
+func (r answerCommentRepository) GetAnswerComment(ctx context.Context, id domain.AnswerCommentID) (*domain.AnswerComment, error) {
+	return call(ctx, "AnswerCommentRepository.GetAnswerComment", func(ctx context.Context) (*domain.AnswerComment, error) { return r.repo.GetAnswerComment(ctx, id) })
+}
+
+func (r answerCommentRepository) ListAnswerComments(ctx context.Context, testID domain.TestID, answerID domain.AnswerID) ([]domain.AnswerComment, error) {
+	return call(ctx, "AnswerCommentRepository.ListAnswerComments", func(ctx context.Context) ([]domain.AnswerComment, error) {
+		return r.repo.ListAnswerComments(ctx, testID, answerID)
+	})
+}
+
+func (r answerCommentRepository) ListAnswerCommentsByStudent(ctx context.Context, studentID domain.StudentID) ([]domain.AnswerComment, error) {
+	return call(ctx, "AnswerCommentRepository.ListAnswerCommentsByStudent", func(ctx context.Context) ([]domain.AnswerComment, error) {
+		return r.repo.ListAnswerCommentsByStudent(ctx, studentID)
+	})
+}
+
+func (r answerCommentRepository) SaveAnswerComment(ctx context.Context, comment *domain.AnswerComment) error {
+	return exec(ctx, "AnswerCommentRepository.SaveAnswerComment", func(ctx context.Context) error { return r.repo.SaveAnswerComment(ctx, comment) })
+}
@@ -0,0 +1,308 @@
+// Package tracing records spans in the OpenTelemetry data model and exports
+// them over OTLP, so a request can be followed from the API that received it
+// through other services down to storage.
+//
+// Spans are started with Start and carried in contexts. Nothing is recorded
+// until SetProvider installs a Provider; until then Start returns a nil span,
+// whose methods do nothing.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/health"
+)
+
+// TraceID identifies a trace, the tree of spans of one request.
+type TraceID [16]byte
+
+// IsValid reports whether the ID is not all zeros.
+func (id TraceID) IsValid() bool { return id != TraceID{} }
+
+func (id TraceID) String() string { return hex.EncodeToString(id[:]) }
+
+// SpanID identifies a span within its trace.
+type SpanID [8]byte
+
+// IsValid reports whether the ID is not all zeros.
+func (id SpanID) IsValid() bool { return id != SpanID{} }
+
+func (id SpanID) String() string { return hex.EncodeToString(id[:]) }
+
+// SpanContext is the part of a span that crosses process boundaries.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid reports whether both IDs are set.
+func (sc SpanContext) IsValid() bool { return sc.TraceID.IsValid() && sc.SpanID.IsValid() }
+
+// Kind describes a span's role in a call, with the OTLP numbering.
+type Kind int
+
+const (
+	KindInternal Kind = 1
+	KindServer   Kind = 2
+	KindClient   Kind = 3
+)
+
+// Attribute is a key-value pair describing a span. Values are strings,
+// int64s or bools.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+func String(key, value string) Attribute { return Attribute{Key: key, Value: value} }
+
+func Int(key string, value int) Attribute { return Attribute{Key: key, Value: int64(value)} }
+
+func Bool(key string, value bool) Attribute { return Attribute{Key: key, Value: value} }
+
+// SpanData is a finished span as handed to an Exporter. Error is empty for
+// spans that succeeded.
+type SpanData struct {
+	Name       string
+	Kind       Kind
+	TraceID    TraceID
+	SpanID     SpanID
+	ParentID   SpanID
+	Start      time.Time
+	End        time.Time
+	Attributes []Attribute
+	Error      string
+}
+
+// Span is one timed operation. All methods are safe on a nil span.
+type Span struct {
+	provider *Provider
+	sc       SpanContext
+
+	mu     sync.Mutex
+	data   SpanData
+	ended  bool
+	failed bool
+}
+
+// SpanContext returns the IDs to propagate to callees.
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.sc
+}
+
+// Recording reports whether the span will be exported.
+func (s *Span) Recording() bool {
+	return s != nil && s.sc.Sampled
+}
+
+// SetAttributes adds attributes to the span.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if !s.Recording() {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Attributes = append(s.data.Attributes, attrs...)
+}
+
+// SetError marks the span as failed with err. A nil err does nothing.
+func (s *Span) SetError(err error) {
+	if err == nil || !s.Recording() {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Error = err.Error()
+	s.failed = true
+}
+
+// End finishes the span and queues it for export. Later calls do nothing.
+func (s *Span) End() {
+	if !s.Recording() {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+	s.provider.enqueue(data)
+}
+
+// SpanOption configures a span in Start.
+type SpanOption func(*SpanData)
+
+// WithKind sets the span's kind; spans are internal by default.
+func WithKind(kind Kind) SpanOption {
+	return func(d *SpanData) { d.Kind = kind }
+}
+
+// WithAttributes sets attributes when the span starts.
+func WithAttributes(attrs ...Attribute) SpanOption {
+	return func(d *SpanData) { d.Attributes = append(d.Attributes, attrs...) }
+}
+
+type spanKey struct{}
+type remoteKey struct{}
+
+// SpanFromContext returns the span running in ctx, or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// parentOf returns the span context new spans in ctx descend from: the
+// running span, or one received from a caller.
+func parentOf(ctx context.Context) SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.sc
+	}
+	sc, _ := ctx.Value(remoteKey{}).(SpanContext)
+	return sc
+}
+
+// Start begins a span as a child of the span in ctx, or of the caller's span
+// extracted from a request, and returns a context carrying it. Without a
+// provider it returns ctx and a nil span.
+func Start(ctx context.Context, name string, opts ...SpanOption) (context.Context, *Span) {
+	p := defaultProvider.Load()
+	if p == nil {
+		return ctx, nil
+	}
+	parent := parentOf(ctx)
+	span := &Span{provider: p, data: SpanData{Name: name, Kind: KindInternal, Start: time.Now()}}
+	if parent.IsValid() {
+		span.sc = SpanContext{TraceID: parent.TraceID, Sampled: parent.Sampled}
+		span.data.ParentID = parent.SpanID
+	} else {
+		_, _ = rand.Read(span.sc.TraceID[:])
+		span.sc.Sampled = p.sample(span.sc.TraceID)
+	}
+	_, _ = rand.Read(span.sc.SpanID[:])
+	span.data.TraceID, span.data.SpanID = span.sc.TraceID, span.sc.SpanID
+	for _, opt := range opts {
+		opt(&span.data)
+	}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// Exporter sends finished spans to a tracing backend.
+type Exporter interface {
+	Export(ctx context.Context, spans []SpanData) error
+}
+
+// Options configure a Provider.
+type Options struct {
+	Exporter Exporter
+	// SampleRatio is the share of new traces recorded, from 0 to 1. Traces
+	// started by a caller follow the caller's decision.
+	SampleRatio float64
+	// MaxQueue bounds the spans waiting for export; more are dropped.
+	// Defaults to 2048.
+	MaxQueue int
+}
+
+// Provider collects finished spans and exports them in batches. A nil
+// Provider records nothing.
+type Provider struct {
+	opts Options
+
+	mu      sync.Mutex
+	queue   []SpanData
+	dropped int
+}
+
+// NewProvider returns a provider exporting through opts.Exporter.
+func NewProvider(opts Options) *Provider {
+	if opts.MaxQueue <= 0 {
+		opts.MaxQueue = 2048
+	}
+	return &Provider{opts: opts}
+}
+
+var defaultProvider atomic.Pointer[Provider]
+
+// SetProvider makes p record the spans started from now on. A nil p turns
+// tracing off.
+func SetProvider(p *Provider) {
+	defaultProvider.Store(p)
+}
+
+func (p *Provider) sample(id TraceID) bool {
+	switch {
+	case p.opts.SampleRatio >= 1:
+		return true
+	case p.opts.SampleRatio <= 0:
+		return false
+	}
+	// The low half of a random trace ID is uniformly distributed, so every
+	// service deciding on the same ID agrees.
+	return float64(binary.BigEndian.Uint64(id[8:])>>11)/(1<<53) < p.opts.SampleRatio
+}
+
+func (p *Provider) enqueue(data SpanData) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.queue) >= p.opts.MaxQueue {
+		p.dropped++
+		return
+	}
+	p.queue = append(p.queue, data)
+}
+
+// Flush exports the queued spans.
+func (p *Provider) Flush(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	batch, dropped := p.queue, p.dropped
+	p.queue, p.dropped = nil, 0
+	p.mu.Unlock()
+	if dropped > 0 {
+		slog.Warn("tracing queue full, spans dropped", "dropped", dropped)
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+	return p.opts.Exporter.Export(ctx, batch)
+}
+
+// Run exports queued spans every interval until ctx is done, reporting each
+// export to the worker's health.
+func (p *Provider) Run(ctx context.Context, interval time.Duration) {
+	if p == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			exportCtx, cancel := context.WithTimeout(ctx, interval)
+			err := p.Flush(exportCtx)
+			cancel()
+			if err != nil {
+				slog.Error("span export failed", "error", err)
+			}
+			health.Report(ctx, err)
+		}
+	}
+}
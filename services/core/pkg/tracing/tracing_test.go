@@ -0,0 +1,151 @@
+package tracing_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+	"github.com/sky0621/go_work_sample/core/pkg/tracing"
+)
+
+type recorder struct {
+	mu    sync.Mutex
+	spans []tracing.SpanData
+}
+
+func (r *recorder) Export(_ context.Context, spans []tracing.SpanData) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, spans...)
+	return nil
+}
+
+// brokenOrg fails every teacher lookup.
+type brokenOrg struct {
+	repository.OrganizationReader
+}
+
+func (brokenOrg) GetTeacher(context.Context, domain.TeacherID) (*domain.Teacher, error) {
+	return nil, errors.New("store offline")
+}
+
+func install(t *testing.T, ratio float64) (*tracing.Provider, *recorder) {
+	t.Helper()
+	rec := &recorder{}
+	provider := tracing.NewProvider(tracing.Options{Exporter: rec, SampleRatio: ratio})
+	tracing.SetProvider(provider)
+	t.Cleanup(func() { tracing.SetProvider(nil) })
+	return provider, rec
+}
+
+func TestSpansJoinTheCallersTraceAcrossProcesses(t *testing.T) {
+	provider, rec := install(t, 1)
+
+	// The caller's side: a client span whose context travels in headers.
+	ctx, client := tracing.Start(context.Background(), "client", tracing.WithKind(tracing.KindClient))
+	header := http.Header{}
+	tracing.Inject(ctx, header)
+	client.End()
+
+	// The callee's side: the server span and a repository call under it.
+	serverCtx, server := tracing.Start(tracing.Extract(context.Background(), header), "server")
+	teachers := tracing.OrganizationReader(brokenOrg{})
+	if _, err := teachers.GetTeacher(serverCtx, "teacher-001"); err == nil {
+		t.Fatalf("expected the wrapped repository's error to come through")
+	}
+	server.End()
+
+	if err := provider.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if len(rec.spans) != 3 {
+		t.Fatalf("expected 3 spans, got %+v", rec.spans)
+	}
+	clientSpan, storage, serverSpan := rec.spans[0], rec.spans[1], rec.spans[2]
+	if serverSpan.TraceID != clientSpan.TraceID || serverSpan.ParentID != clientSpan.SpanID {
+		t.Fatalf("expected the server span to continue the client's trace")
+	}
+	if storage.Name != "OrganizationReader.GetTeacher" || storage.ParentID != serverSpan.SpanID || storage.Error == "" {
+		t.Fatalf("unexpected repository span %+v", storage)
+	}
+}
+
+func TestUnsampledTracesPropagateWithoutRecording(t *testing.T) {
+	provider, rec := install(t, 0)
+
+	ctx, span := tracing.Start(context.Background(), "root")
+	if span.Recording() {
+		t.Fatalf("expected a zero sample ratio to skip new traces")
+	}
+	header := http.Header{}
+	tracing.Inject(ctx, header)
+	if got := header.Get(tracing.TraceparentHeader); len(got) != 55 || got[53:] != "00" {
+		t.Fatalf("expected an unsampled traceparent, got %q", got)
+	}
+	span.End()
+
+	header.Set(tracing.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, child := tracing.Start(tracing.Extract(context.Background(), header), "child")
+	child.End()
+	if err := provider.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if len(rec.spans) != 1 || rec.spans[0].TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("expected a sampled caller to be followed, got %+v", rec.spans)
+	}
+
+	for _, bad := range []string{"", "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "00-xyz"} {
+		header.Set(tracing.TraceparentHeader, bad)
+		if ctx := tracing.Extract(context.Background(), header); ctx != context.Background() {
+			t.Fatalf("expected %q to be ignored", bad)
+		}
+	}
+}
+
+func TestOTLPExporterPostsJSON(t *testing.T) {
+	var body map[string]any
+	var auth string
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &body)
+	}))
+	defer collector.Close()
+
+	exporter := tracing.NewOTLPExporter(tracing.OTLPOptions{
+		Endpoint:    collector.URL + "/v1/traces",
+		Headers:     map[string]string{"Authorization": "Bearer key"},
+		ServiceName: "teacher-api",
+	})
+	provider := tracing.NewProvider(tracing.Options{Exporter: exporter, SampleRatio: 1})
+	tracing.SetProvider(provider)
+	t.Cleanup(func() { tracing.SetProvider(nil) })
+
+	_, span := tracing.Start(context.Background(), "GradeAnswer", tracing.WithAttributes(tracing.Int("answers", 3)))
+	span.SetError(errors.New("boom"))
+	span.End()
+	if err := provider.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	if auth != "Bearer key" {
+		t.Fatalf("expected the configured headers, got %q", auth)
+	}
+	resource := body["resourceSpans"].([]any)[0].(map[string]any)
+	service := resource["resource"].(map[string]any)["attributes"].([]any)[0].(map[string]any)
+	if service["value"].(map[string]any)["stringValue"] != "teacher-api" {
+		t.Fatalf("unexpected resource %+v", resource["resource"])
+	}
+	sent := resource["scopeSpans"].([]any)[0].(map[string]any)["spans"].([]any)[0].(map[string]any)
+	attr := sent["attributes"].([]any)[0].(map[string]any)["value"].(map[string]any)
+	if sent["name"] != "GradeAnswer" || len(sent["traceId"].(string)) != 32 || attr["intValue"] != "3" || sent["status"].(map[string]any)["code"] != float64(2) {
+		t.Fatalf("unexpected span %+v", sent)
+	}
+}
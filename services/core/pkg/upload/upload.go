@@ -0,0 +1,413 @@
+// Package upload assembles large attachments from parts sent in separate
+// requests, so a dropped connection costs one part rather than the whole
+// file. A session is started with the size and SHA-256 of the file, parts
+// are sent in any order and retried as often as needed, and completing the
+// session joins them and checks the result against the checksum.
+package upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/id"
+	"github.com/sky0621/go_work_sample/core/pkg/storage/blob"
+	"github.com/sky0621/go_work_sample/core/pkg/tracing"
+)
+
+// Defaults used for zero Options.
+const (
+	DefaultPartSize int64 = 5 << 20
+	DefaultMaxSize  int64 = 1 << 30
+	DefaultTTL            = 24 * time.Hour
+)
+
+// Options tune a Manager. Every part but the last is PartSize bytes.
+type Options struct {
+	PartSize int64
+	MaxSize  int64
+	TTL      time.Duration
+}
+
+// Session is the state of one upload. Parts maps the numbers of the parts
+// received so far, starting at 1, to their SHA-256.
+type Session struct {
+	ID          string         `json:"id"`
+	Owner       string         `json:"owner"`
+	Filename    string         `json:"filename"`
+	ContentType string         `json:"content_type"`
+	Size        int64          `json:"size"`
+	SHA256      string         `json:"sha256"`
+	PartSize    int64          `json:"part_size"`
+	Parts       map[int]string `json:"parts"`
+	CreatedAt   time.Time      `json:"created_at"`
+	ExpiresAt   time.Time      `json:"expires_at"`
+	CompletedAt *time.Time     `json:"completed_at,omitempty"`
+}
+
+// PartCount is the number of parts the file is split into.
+func (s Session) PartCount() int {
+	if s.Size == 0 {
+		return 1
+	}
+	return int((s.Size + s.PartSize - 1) / s.PartSize)
+}
+
+// PartLength is the size in bytes of part n.
+func (s Session) PartLength(n int) int64 {
+	if n < s.PartCount() {
+		return s.PartSize
+	}
+	return s.Size - int64(s.PartCount()-1)*s.PartSize
+}
+
+// Missing lists the numbers of the parts not received yet.
+func (s Session) Missing() []int {
+	var missing []int
+	for n := 1; n <= s.PartCount(); n++ {
+		if _, ok := s.Parts[n]; !ok {
+			missing = append(missing, n)
+		}
+	}
+	return missing
+}
+
+// Completed reports whether the file has been assembled.
+func (s Session) Completed() bool {
+	return s.CompletedAt != nil
+}
+
+// InitInput describes the file to upload.
+type InitInput struct {
+	Filename    string
+	ContentType string
+	Size        int64
+	SHA256      string
+}
+
+// Manager keeps sessions, parts and assembled files in a blob store:
+// uploads/{id}/session.json, uploads/{id}/parts/{n} and uploads/{id}/file.
+// The store offers no listing, so parts of sessions that expire without
+// being aborted stay until the store is cleaned outside the service.
+type Manager struct {
+	blobs blob.Store
+	opts  Options
+	now   func() time.Time
+
+	mu sync.Mutex
+}
+
+// NewManager creates a manager storing uploads in blobs.
+func NewManager(blobs blob.Store, opts Options) *Manager {
+	if opts.PartSize <= 0 {
+		opts.PartSize = DefaultPartSize
+	}
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = DefaultMaxSize
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultTTL
+	}
+	return &Manager{blobs: blobs, opts: opts, now: time.Now}
+}
+
+// Init starts a session for owner.
+func (m *Manager) Init(ctx context.Context, owner string, in InitInput) (*Session, error) {
+	ctx, span := tracing.Start(ctx, "upload.Manager.Init")
+	defer span.End()
+
+	filename := strings.TrimSpace(in.Filename)
+	if filename == "" || strings.ContainsAny(filename, "/\\\"\r\n") {
+		return nil, fmt.Errorf("%w: filename is required and must not contain slashes, quotes or line breaks", errs.ErrInvalidUpload)
+	}
+	if in.Size < 0 || in.Size > m.opts.MaxSize {
+		return nil, fmt.Errorf("%w: size must be between 0 and %d bytes", errs.ErrInvalidUpload, m.opts.MaxSize)
+	}
+	sum := strings.ToLower(in.SHA256)
+	if !validChecksum(sum) {
+		return nil, fmt.Errorf("%w: sha256 must be 64 hexadecimal characters", errs.ErrInvalidUpload)
+	}
+	contentType := in.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	now := m.now().UTC()
+	session := &Session{
+		ID:          id.New(),
+		Owner:       owner,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        in.Size,
+		SHA256:      sum,
+		PartSize:    m.opts.PartSize,
+		Parts:       map[int]string{},
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(m.opts.TTL),
+	}
+	if err := m.save(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Get returns owner's session.
+func (m *Manager) Get(ctx context.Context, owner, uploadID string) (*Session, error) {
+	ctx, span := tracing.Start(ctx, "upload.Manager.Get")
+	defer span.End()
+	return m.load(ctx, owner, uploadID)
+}
+
+// PutPart stores part n of the session read from r. A non-empty sum must
+// match the SHA-256 of the part. Sending a part again replaces it.
+func (m *Manager) PutPart(ctx context.Context, owner, uploadID string, n int, r io.Reader, sum string) (*Session, error) {
+	ctx, span := tracing.Start(ctx, "upload.Manager.PutPart")
+	defer span.End()
+
+	session, err := m.load(ctx, owner, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Completed() {
+		return nil, fmt.Errorf("%w: upload is already complete", errs.ErrInvalidUpload)
+	}
+	if n < 1 || n > session.PartCount() {
+		return nil, fmt.Errorf("%w: part must be between 1 and %d", errs.ErrInvalidUpload, session.PartCount())
+	}
+	sum = strings.ToLower(sum)
+	if sum != "" && !validChecksum(sum) {
+		return nil, fmt.Errorf("%w: part sha256 must be 64 hexadecimal characters", errs.ErrInvalidUpload)
+	}
+
+	// Read one byte past the expected length to notice oversized parts.
+	want := session.PartLength(n)
+	body := &digestReader{r: io.LimitReader(r, want+1), h: sha256.New()}
+	key := partKey(uploadID, n)
+	if err := m.blobs.Put(ctx, key, body); err != nil {
+		return nil, err
+	}
+	got := hex.EncodeToString(body.h.Sum(nil))
+	switch {
+	case body.n != want:
+		_ = m.blobs.Delete(ctx, key)
+		return nil, fmt.Errorf("%w: part %d must be %d bytes", errs.ErrInvalidUpload, n, want)
+	case sum != "" && sum != got:
+		_ = m.blobs.Delete(ctx, key)
+		return nil, fmt.Errorf("%w: part %d", errs.ErrChecksumMismatch, n)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// Reload so parts stored concurrently are kept.
+	session, err = m.load(ctx, owner, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Completed() {
+		return nil, fmt.Errorf("%w: upload is already complete", errs.ErrInvalidUpload)
+	}
+	session.Parts[n] = got
+	if err := m.save(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Complete joins the parts into the file and checks it against the
+// session's size and checksum. On a mismatch the parts are kept so the
+// wrong ones can be sent again. Completing a completed session is a no-op.
+func (m *Manager) Complete(ctx context.Context, owner, uploadID string) (*Session, error) {
+	ctx, span := tracing.Start(ctx, "upload.Manager.Complete")
+	defer span.End()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, err := m.load(ctx, owner, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Completed() {
+		return session, nil
+	}
+	if missing := session.Missing(); len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %v", errs.ErrUploadIncomplete, missing)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(m.joinParts(ctx, session, pw))
+	}()
+	body := &digestReader{r: pr, h: sha256.New()}
+	err = m.blobs.Put(ctx, fileKey(uploadID), body)
+	pr.CloseWithError(err)
+	if err != nil {
+		return nil, err
+	}
+	if body.n != session.Size || hex.EncodeToString(body.h.Sum(nil)) != session.SHA256 {
+		_ = m.blobs.Delete(ctx, fileKey(uploadID))
+		return nil, fmt.Errorf("%w: assembled file", errs.ErrChecksumMismatch)
+	}
+
+	completedAt := m.now().UTC()
+	session.CompletedAt = &completedAt
+	if err := m.save(ctx, session); err != nil {
+		return nil, err
+	}
+	m.deleteParts(ctx, session)
+	return session, nil
+}
+
+// Abort discards the session with its parts and file.
+func (m *Manager) Abort(ctx context.Context, owner, uploadID string) error {
+	ctx, span := tracing.Start(ctx, "upload.Manager.Abort")
+	defer span.End()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, err := m.load(ctx, owner, uploadID)
+	if err != nil {
+		return err
+	}
+	m.deleteParts(ctx, session)
+	if err := m.blobs.Delete(ctx, fileKey(uploadID)); err != nil {
+		return err
+	}
+	return m.blobs.Delete(ctx, sessionKey(uploadID))
+}
+
+// Open returns the session and a reader for its assembled file.
+func (m *Manager) Open(ctx context.Context, owner, uploadID string) (*Session, io.ReadCloser, error) {
+	ctx, span := tracing.Start(ctx, "upload.Manager.Open")
+	defer span.End()
+
+	session, err := m.load(ctx, owner, uploadID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !session.Completed() {
+		return nil, nil, fmt.Errorf("%w: %v", errs.ErrUploadIncomplete, session.Missing())
+	}
+	body, err := m.blobs.Open(ctx, fileKey(uploadID))
+	if errors.Is(err, errs.ErrBlobNotFound) {
+		return nil, nil, errs.ErrUploadNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return session, body, nil
+}
+
+// load reads owner's session. Sessions of other owners and incomplete
+// sessions past their expiry are reported as not found.
+func (m *Manager) load(ctx context.Context, owner, uploadID string) (*Session, error) {
+	if !validID(uploadID) {
+		return nil, errs.ErrUploadNotFound
+	}
+	body, err := m.blobs.Open(ctx, sessionKey(uploadID))
+	if errors.Is(err, errs.ErrBlobNotFound) {
+		return nil, errs.ErrUploadNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var session Session
+	if err := json.NewDecoder(body).Decode(&session); err != nil {
+		return nil, fmt.Errorf("upload: decode session %s: %w", uploadID, err)
+	}
+	if session.Owner != owner || (!session.Completed() && !m.now().Before(session.ExpiresAt)) {
+		return nil, errs.ErrUploadNotFound
+	}
+	if session.Parts == nil {
+		session.Parts = map[int]string{}
+	}
+	return &session, nil
+}
+
+func (m *Manager) save(ctx context.Context, session *Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	return m.blobs.Put(ctx, sessionKey(session.ID), bytes.NewReader(data))
+}
+
+// joinParts copies the parts in order to w.
+func (m *Manager) joinParts(ctx context.Context, session *Session, w io.Writer) error {
+	for n := 1; n <= session.PartCount(); n++ {
+		part, err := m.blobs.Open(ctx, partKey(session.ID, n))
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(w, part)
+		part.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteParts removes the stored parts. Failures are ignored: a part left
+// behind only wastes space.
+func (m *Manager) deleteParts(ctx context.Context, session *Session) {
+	for n := range session.Parts {
+		_ = m.blobs.Delete(ctx, partKey(session.ID, n))
+	}
+}
+
+func sessionKey(uploadID string) string { return "uploads/" + uploadID + "/session.json" }
+func fileKey(uploadID string) string    { return "uploads/" + uploadID + "/file" }
+func partKey(uploadID string, n int) string {
+	return "uploads/" + uploadID + "/parts/" + strconv.Itoa(n)
+}
+
+// validID accepts the identifiers id.New produces, which keeps client
+// supplied IDs from reaching blob keys unchecked.
+func validID(uploadID string) bool {
+	if uploadID == "" || len(uploadID) > 64 {
+		return false
+	}
+	for _, r := range uploadID {
+		if !('0' <= r && r <= '9') && !('a' <= r && r <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func validChecksum(sum string) bool {
+	if len(sum) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(sum)
+	return err == nil
+}
+
+// digestReader hashes and counts the bytes read through it.
+type digestReader struct {
+	r io.Reader
+	h hash.Hash
+	n int64
+}
+
+func (d *digestReader) Read(p []byte) (int, error) {
+	n, err := d.r.Read(p)
+	d.h.Write(p[:n])
+	d.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,109 @@
+package upload_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/storage/blob"
+	"github.com/sky0621/go_work_sample/core/pkg/upload"
+)
+
+func checksum(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestManagerUploadsInParts(t *testing.T) {
+	ctx := context.Background()
+	store, err := blob.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+	manager := upload.NewManager(store, upload.Options{PartSize: 4})
+	content := "hello, world"
+
+	session, err := manager.Init(ctx, "teacher-001", upload.InitInput{Filename: "notes.txt", Size: int64(len(content)), SHA256: checksum(content)})
+	if err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	if session.PartCount() != 3 {
+		t.Fatalf("expected 3 parts, got %d", session.PartCount())
+	}
+
+	// Parts arrive out of order; a wrong checksum or length is rejected.
+	if _, err := manager.PutPart(ctx, "teacher-001", session.ID, 3, strings.NewReader("orld"), checksum("orld")); err != nil {
+		t.Fatalf("PutPart 3 failed: %v", err)
+	}
+	if _, err := manager.PutPart(ctx, "teacher-001", session.ID, 1, strings.NewReader("hell"), checksum("HELL")); !errors.Is(err, errs.ErrChecksumMismatch) {
+		t.Fatalf("expected ErrChecksumMismatch, got %v", err)
+	}
+	if _, err := manager.PutPart(ctx, "teacher-001", session.ID, 1, strings.NewReader("hello"), ""); !errors.Is(err, errs.ErrInvalidUpload) {
+		t.Fatalf("expected ErrInvalidUpload for an oversized part, got %v", err)
+	}
+	if _, err := manager.PutPart(ctx, "teacher-001", session.ID, 1, strings.NewReader("hell"), ""); err != nil {
+		t.Fatalf("PutPart 1 failed: %v", err)
+	}
+	if _, err := manager.Complete(ctx, "teacher-001", session.ID); !errors.Is(err, errs.ErrUploadIncomplete) {
+		t.Fatalf("expected ErrUploadIncomplete, got %v", err)
+	}
+	if got, _ := manager.Get(ctx, "teacher-001", session.ID); len(got.Missing()) != 1 || got.Missing()[0] != 2 {
+		t.Fatalf("expected part 2 missing, got %v", got.Missing())
+	}
+
+	// A corrupted part fails the final checksum and can be sent again.
+	if _, err := manager.PutPart(ctx, "teacher-001", session.ID, 2, strings.NewReader("o, X"), ""); err != nil {
+		t.Fatalf("PutPart 2 failed: %v", err)
+	}
+	if _, err := manager.Complete(ctx, "teacher-001", session.ID); !errors.Is(err, errs.ErrChecksumMismatch) {
+		t.Fatalf("expected ErrChecksumMismatch, got %v", err)
+	}
+	if _, err := manager.PutPart(ctx, "teacher-001", session.ID, 2, strings.NewReader("o, w"), ""); err != nil {
+		t.Fatalf("PutPart 2 retry failed: %v", err)
+	}
+	if session, err = manager.Complete(ctx, "teacher-001", session.ID); err != nil || !session.Completed() {
+		t.Fatalf("Complete failed: %+v, %v", session, err)
+	}
+
+	_, body, err := manager.Open(ctx, "teacher-001", session.ID)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != content {
+		t.Fatalf("unexpected content %q", data)
+	}
+
+	if _, err := manager.Get(ctx, "teacher-002", session.ID); !errors.Is(err, errs.ErrUploadNotFound) {
+		t.Fatalf("expected ErrUploadNotFound for another owner, got %v", err)
+	}
+	if err := manager.Abort(ctx, "teacher-001", session.ID); err != nil {
+		t.Fatalf("Abort failed: %v", err)
+	}
+	if _, err := manager.Get(ctx, "teacher-001", session.ID); !errors.Is(err, errs.ErrUploadNotFound) {
+		t.Fatalf("expected ErrUploadNotFound after Abort, got %v", err)
+	}
+}
+
+func TestManagerInitValidates(t *testing.T) {
+	store, err := blob.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+	manager := upload.NewManager(store, upload.Options{MaxSize: 10})
+	for name, in := range map[string]upload.InitInput{
+		"filename": {Filename: "../x", Size: 1, SHA256: checksum("x")},
+		"size":     {Filename: "x", Size: 11, SHA256: checksum("x")},
+		"checksum": {Filename: "x", Size: 1, SHA256: "abc"},
+	} {
+		if _, err := manager.Init(context.Background(), "teacher-001", in); !errors.Is(err, errs.ErrInvalidUpload) {
+			t.Errorf("%s: expected ErrInvalidUpload, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/id"
+	"github.com/sky0621/go_work_sample/core/pkg/notify"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+	"github.com/sky0621/go_work_sample/core/pkg/tracing"
+)
+
+// SetAnswerComments lets teachers and students discuss answers in comment
+// threads. Without a repository answers only carry the result's feedback.
+func (s *AssessmentService) SetAnswerComments(comments repository.AnswerCommentRepository) {
+	s.commentRepo = tracing.AnswerCommentRepository(comments)
+}
+
+// AnswerCommentInput describes a new comment or reply on an answer. Role is
+// RoleTeacher for a teacher of the test or RoleStudent for the student who
+// gave the answer, identified by AuthorID.
+type AnswerCommentInput struct {
+	Role     domain.Role
+	AuthorID string
+	TestID   domain.TestID
+	AnswerID domain.AnswerID
+	ParentID domain.AnswerCommentID
+	Body     string
+}
+
+// AnswerCommentThread is a comment on an answer with its replies, oldest
+// first.
+type AnswerCommentThread struct {
+	Comment domain.AnswerComment
+	Replies []AnswerCommentThread
+}
+
+// AddAnswerComment adds a comment to the thread on an answer. The student is
+// notified of comments from teachers.
+func (s *AssessmentService) AddAnswerComment(ctx context.Context, input AnswerCommentInput) (*domain.AnswerComment, error) {
+	ctx, span := s.trace(ctx, "AddAnswerComment")
+	defer span.End()
+
+	body := strings.TrimSpace(input.Body)
+	if body == "" || len(body) > maxCommentLength {
+		return nil, errs.ErrInvalidComment
+	}
+	answer, err := s.commentableAnswer(ctx, input.Role, input.AuthorID, input.TestID, input.AnswerID)
+	if err != nil {
+		return nil, err
+	}
+	if input.ParentID != "" {
+		parent, err := s.commentRepo.GetAnswerComment(ctx, input.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil || parent.AnswerID != answer.ID {
+			return nil, errs.ErrCommentNotFound
+		}
+	}
+
+	comment := &domain.AnswerComment{
+		ID:         domain.AnswerCommentID(id.New()),
+		TestID:     answer.TestID,
+		AnswerID:   answer.ID,
+		StudentID:  answer.StudentID,
+		ParentID:   input.ParentID,
+		AuthorRole: input.Role,
+		AuthorID:   input.AuthorID,
+		Body:       body,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if err := s.commentRepo.SaveAnswerComment(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	if comment.AuthorRole == domain.RoleTeacher {
+		title := string(answer.TestID)
+		if test, err := s.testRepo.GetTest(ctx, answer.TestID); err == nil && test != nil {
+			title = test.Title
+		}
+		s.notifyStudent(ctx, answer.StudentID, notify.Notification{
+			Kind:       notify.KindFeedback,
+			Subject:    "New feedback on your answer: " + title,
+			Body:       comment.Body,
+			OccurredAt: comment.CreatedAt,
+		})
+	}
+	return comment, nil
+}
+
+// ListAnswerComments returns the comment threads on an answer for a teacher
+// of the test or the student who gave the answer.
+func (s *AssessmentService) ListAnswerComments(ctx context.Context, role domain.Role, userID string, testID domain.TestID, answerID domain.AnswerID) ([]AnswerCommentThread, error) {
+	ctx, span := s.trace(ctx, "ListAnswerComments")
+	defer span.End()
+
+	if _, err := s.commentableAnswer(ctx, role, userID, testID, answerID); err != nil {
+		return nil, err
+	}
+	comments, err := s.commentRepo.ListAnswerComments(ctx, testID, answerID)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[domain.AnswerCommentID][]domain.AnswerComment)
+	for _, c := range comments {
+		children[c.ParentID] = append(children[c.ParentID], c)
+	}
+	var build func(parent domain.AnswerCommentID) []AnswerCommentThread
+	build = func(parent domain.AnswerCommentID) []AnswerCommentThread {
+		threads := make([]AnswerCommentThread, 0, len(children[parent]))
+		for _, c := range children[parent] {
+			threads = append(threads, AnswerCommentThread{Comment: c, Replies: build(c.ID)})
+		}
+		return threads
+	}
+	return build(""), nil
+}
+
+// MarkAnswerCommentsRead marks the comments the other side left on an answer
+// as read by role and returns how many were unread.
+func (s *AssessmentService) MarkAnswerCommentsRead(ctx context.Context, role domain.Role, userID string, testID domain.TestID, answerID domain.AnswerID) (int, error) {
+	ctx, span := s.trace(ctx, "MarkAnswerCommentsRead")
+	defer span.End()
+
+	if _, err := s.commentableAnswer(ctx, role, userID, testID, answerID); err != nil {
+		return 0, err
+	}
+	comments, err := s.commentRepo.ListAnswerComments(ctx, testID, answerID)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	marked := 0
+	for _, c := range comments {
+		if c.AuthorRole == role || c.ReadAt != nil {
+			continue
+		}
+		c.ReadAt = &now
+		if err := s.commentRepo.SaveAnswerComment(ctx, &c); err != nil {
+			return marked, err
+		}
+		marked++
+	}
+	return marked, nil
+}
+
+// UnreadAnswerComments returns the teachers' comments on the student's
+// answers that the student has not read yet, oldest first. Comments on tests
+// the student can no longer see are left out.
+func (s *AssessmentService) UnreadAnswerComments(ctx context.Context, studentID domain.StudentID) ([]domain.AnswerComment, error) {
+	ctx, span := s.trace(ctx, "UnreadAnswerComments")
+	defer span.End()
+
+	if s.commentRepo == nil {
+		return nil, errs.ErrCommentsUnavailable
+	}
+	if err := s.ensureStudentExists(ctx, studentID); err != nil {
+		return nil, err
+	}
+	comments, err := s.commentRepo.ListAnswerCommentsByStudent(ctx, studentID)
+	if err != nil {
+		return nil, err
+	}
+	visible := make(map[domain.TestID]bool)
+	unread := make([]domain.AnswerComment, 0)
+	for _, c := range comments {
+		if c.AuthorRole == domain.RoleStudent || c.ReadAt != nil {
+			continue
+		}
+		seen, ok := visible[c.TestID]
+		if !ok {
+			_, err := s.publishedTestFor(ctx, studentID, c.TestID)
+			seen = err == nil
+			visible[c.TestID] = seen
+		}
+		if seen {
+			unread = append(unread, c)
+		}
+	}
+	return unread, nil
+}
+
+// commentableAnswer returns the answer when userID may take part in its
+// comment thread as role.
+func (s *AssessmentService) commentableAnswer(ctx context.Context, role domain.Role, userID string, testID domain.TestID, answerID domain.AnswerID) (*domain.Answer, error) {
+	if s.commentRepo == nil {
+		return nil, errs.ErrCommentsUnavailable
+	}
+	var answers []domain.Answer
+	switch role {
+	case domain.RoleTeacher:
+		if err := s.ensureTeacherOwnsTest(ctx, domain.TeacherID(userID), testID); err != nil {
+			return nil, err
+		}
+		all, err := repository.Collect(s.answerRepo.ListAnswersByTest(ctx, testID, repository.All))
+		if err != nil {
+			return nil, err
+		}
+		answers = all
+	case domain.RoleStudent:
+		studentID := domain.StudentID(userID)
+		if err := s.ensureStudentExists(ctx, studentID); err != nil {
+			return nil, err
+		}
+		if _, err := s.publishedTestFor(ctx, studentID, testID); err != nil {
+			return nil, err
+		}
+		own, err := s.answerRepo.ListAnswers(ctx, testID, studentID)
+		if err != nil {
+			return nil, err
+		}
+		answers = own
+	default:
+		return nil, errs.ErrInvalidComment
+	}
+	for _, a := range answers {
+		if a.ID == answerID {
+			return &a, nil
+		}
+	}
+	return nil, errs.ErrAnswerNotFound
+}
@@ -0,0 +1,83 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_AnswerComments(t *testing.T) {
+	fx := fixtures.NewSchool().WithStudents(2).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Essay",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "Q1", Points: 10}},
+		StudentIDs: []domain.StudentID{fx.Student(0), fx.Student(1)},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	answer, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: fx.Student(0), Response: "x"})
+	if err != nil {
+		t.Fatalf("SubmitAnswer failed: %v", err)
+	}
+	teacherInput := usecase.AnswerCommentInput{
+		Role:     domain.RoleTeacher,
+		AuthorID: string(fx.Teacher(0)),
+		TestID:   test.ID,
+		AnswerID: answer.ID,
+		Body:     "Cite your sources.",
+	}
+	if _, err := service.AddAnswerComment(ctx, teacherInput); err != errs.ErrCommentsUnavailable {
+		t.Fatalf("expected comments to need a repository, got %v", err)
+	}
+	service.SetAnswerComments(fx.Repo)
+
+	feedback, err := service.AddAnswerComment(ctx, teacherInput)
+	if err != nil {
+		t.Fatalf("AddAnswerComment failed: %v", err)
+	}
+	reply, err := service.AddAnswerComment(ctx, usecase.AnswerCommentInput{
+		Role:     domain.RoleStudent,
+		AuthorID: string(fx.Student(0)),
+		TestID:   test.ID,
+		AnswerID: answer.ID,
+		ParentID: feedback.ID,
+		Body:     "Added them, thanks.",
+	})
+	if err != nil {
+		t.Fatalf("student reply failed: %v", err)
+	}
+	if _, err := service.ListAnswerComments(ctx, domain.RoleStudent, string(fx.Student(1)), test.ID, answer.ID); err != errs.ErrAnswerNotFound {
+		t.Fatalf("expected another student to be kept out of the thread, got %v", err)
+	}
+
+	threads, err := service.ListAnswerComments(ctx, domain.RoleTeacher, string(fx.Teacher(0)), test.ID, answer.ID)
+	if err != nil {
+		t.Fatalf("ListAnswerComments failed: %v", err)
+	}
+	if len(threads) != 1 || len(threads[0].Replies) != 1 || threads[0].Replies[0].Comment.ID != reply.ID {
+		t.Fatalf("expected the reply nested under the feedback, got %+v", threads)
+	}
+
+	unread, err := service.UnreadAnswerComments(ctx, fx.Student(0))
+	if err != nil {
+		t.Fatalf("UnreadAnswerComments failed: %v", err)
+	}
+	if len(unread) != 1 || unread[0].ID != feedback.ID {
+		t.Fatalf("expected the teacher's feedback to be unread, got %+v", unread)
+	}
+	if marked, err := service.MarkAnswerCommentsRead(ctx, domain.RoleStudent, string(fx.Student(0)), test.ID, answer.ID); err != nil || marked != 1 {
+		t.Fatalf("MarkAnswerCommentsRead: marked %d, %v", marked, err)
+	}
+	if unread, _ := service.UnreadAnswerComments(ctx, fx.Student(0)); len(unread) != 0 {
+		t.Fatalf("expected no unread feedback after reading, got %+v", unread)
+	}
+}
@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/sky0621/go_work_sample/core/pkg/audit"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+)
+
+// DeleteAnswer clears a student's answer to a question, such as an accidental
+// or corrupted submission, together with its result, so the student can
+// answer again. Every deletion is written to the audit log.
+func (s *AssessmentService) DeleteAnswer(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID, questionID domain.QuestionID, studentID domain.StudentID) error {
+	ctx, span := s.trace(ctx, "DeleteAnswer")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return err
+	}
+	answer, err := s.answerRepo.GetAnswer(ctx, testID, questionID, studentID)
+	if err != nil {
+		return err
+	}
+	if answer == nil {
+		return errs.ErrAnswerNotFound
+	}
+	if err := s.answerRepo.DeleteAnswer(ctx, testID, questionID, studentID); err != nil {
+		return err
+	}
+	s.stats.entries.Invalidate(testID)
+	s.record(domain.AnswerDeleted{TestID: testID, QuestionID: questionID, StudentID: studentID})
+	s.audit(ctx, audit.AnswerDeleted(teacherID, *answer))
+	return nil
+}
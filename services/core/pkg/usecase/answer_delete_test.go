@@ -0,0 +1,54 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_DeleteAnswer(t *testing.T) {
+	fx := fixtures.NewSchool().WithTeachers(2).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Oops",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "Q1", Points: 5}},
+		StudentIDs: []domain.StudentID{fx.Student(0)},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	q := questions[0]
+	answer, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: q.ID, StudentID: fx.Student(0), Response: "garbled"})
+	if err != nil {
+		t.Fatalf("SubmitAnswer failed: %v", err)
+	}
+	if _, err := service.GradeAnswer(ctx, usecase.GradeInput{TeacherID: fx.Teacher(0), TestID: test.ID, QuestionID: q.ID, StudentID: fx.Student(0), Score: 2, Completed: true}); err != nil {
+		t.Fatalf("GradeAnswer failed: %v", err)
+	}
+
+	if err := service.DeleteAnswer(ctx, fx.Teacher(1), test.ID, q.ID, fx.Student(0)); err != errs.ErrForbiddenTeacher {
+		t.Fatalf("expected another teacher to be refused, got %v", err)
+	}
+	if err := service.DeleteAnswer(ctx, fx.Teacher(0), test.ID, q.ID, fx.Student(0)); err != nil {
+		t.Fatalf("DeleteAnswer failed: %v", err)
+	}
+	if got, _ := fx.Repo.GetAnswer(ctx, test.ID, q.ID, fx.Student(0)); got != nil {
+		t.Fatalf("expected the answer to be gone, got %+v", got)
+	}
+	if got, _ := fx.Repo.GetResult(ctx, answer.ID); got != nil {
+		t.Fatalf("expected the linked result to be gone, got %+v", got)
+	}
+	if err := service.DeleteAnswer(ctx, fx.Teacher(0), test.ID, q.ID, fx.Student(0)); err != errs.ErrAnswerNotFound {
+		t.Fatalf("expected ErrAnswerNotFound, got %v", err)
+	}
+	if _, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: q.ID, StudentID: fx.Student(0), Response: "42"}); err != nil {
+		t.Fatalf("expected the student to answer again, got %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/sky0621/go_work_sample/core/pkg/audit"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+)
+
+// AnswerHistory returns a student's answer to a question of the teacher's
+// test with the earlier versions of its response.
+func (s *AssessmentService) AnswerHistory(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID, questionID domain.QuestionID, studentID domain.StudentID) (*domain.Answer, error) {
+	ctx, span := s.trace(ctx, "AnswerHistory")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	return s.answerWithHistory(ctx, testID, questionID, studentID)
+}
+
+// StudentAnswerHistory returns the student's own answer to a question with
+// the earlier versions of its response.
+func (s *AssessmentService) StudentAnswerHistory(ctx context.Context, studentID domain.StudentID, testID domain.TestID, questionID domain.QuestionID) (*domain.Answer, error) {
+	ctx, span := s.trace(ctx, "StudentAnswerHistory")
+	defer span.End()
+
+	if err := s.ensureStudentExists(ctx, studentID); err != nil {
+		return nil, err
+	}
+	if _, err := s.publishedTestFor(ctx, studentID, testID); err != nil {
+		return nil, err
+	}
+	return s.answerWithHistory(ctx, testID, questionID, studentID)
+}
+
+// RestoreAnswerRevision saves an earlier version of the student's answer as
+// its current response. The restore is an ordinary submission, so it is
+// refused once the test is closed or submitted, and the replaced response
+// becomes a revision in turn.
+func (s *AssessmentService) RestoreAnswerRevision(ctx context.Context, studentID domain.StudentID, testID domain.TestID, questionID domain.QuestionID, revision int) (*domain.Answer, error) {
+	ctx, span := s.trace(ctx, "RestoreAnswerRevision")
+	defer span.End()
+
+	answer, err := s.StudentAnswerHistory(ctx, studentID, testID, questionID)
+	if err != nil {
+		return nil, err
+	}
+	rev, ok := answer.Revision(revision)
+	if !ok {
+		return nil, errs.ErrRevisionNotFound
+	}
+	restored, err := s.SubmitAnswer(ctx, &domain.Answer{
+		TestID:     testID,
+		QuestionID: questionID,
+		StudentID:  studentID,
+		Response:   rev.Response,
+		Note:       rev.Note,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.audit(ctx, audit.AnswerRestored(*restored, revision))
+	return restored, nil
+}
+
+func (s *AssessmentService) answerWithHistory(ctx context.Context, testID domain.TestID, questionID domain.QuestionID, studentID domain.StudentID) (*domain.Answer, error) {
+	answer, err := s.answerRepo.GetAnswer(ctx, testID, questionID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	if answer == nil {
+		return nil, errs.ErrAnswerNotFound
+	}
+	return answer, nil
+}
@@ -0,0 +1,68 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_AnswerHistory(t *testing.T) {
+	fx := fixtures.NewSchool().WithTeachers(2).WithStudents(2).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Drafts",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "Describe photosynthesis", Points: 5}},
+		StudentIDs: []domain.StudentID{fx.Student(0), fx.Student(1)},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	q := questions[0]
+	for _, response := range []string{"Plants", "Plants make sugar", "Plants make sugar", "Plants make sugar from light"} {
+		if _, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: q.ID, StudentID: fx.Student(0), Response: response}); err != nil {
+			t.Fatalf("SubmitAnswer failed: %v", err)
+		}
+	}
+
+	answer, err := service.AnswerHistory(ctx, fx.Teacher(0), test.ID, q.ID, fx.Student(0))
+	if err != nil {
+		t.Fatalf("AnswerHistory failed: %v", err)
+	}
+	if answer.Response != "Plants make sugar from light" || len(answer.Revisions) != 2 {
+		t.Fatalf("expected two revisions behind the latest response, got %+v", answer)
+	}
+	if answer.Revisions[0].Revision != 1 || answer.Revisions[0].Response != "Plants" || answer.Revisions[1].Response != "Plants make sugar" {
+		t.Fatalf("unexpected revisions %+v", answer.Revisions)
+	}
+	if _, err := service.AnswerHistory(ctx, fx.Teacher(1), test.ID, q.ID, fx.Student(0)); err != errs.ErrForbiddenTeacher {
+		t.Fatalf("expected another teacher to be refused, got %v", err)
+	}
+	if _, err := service.StudentAnswerHistory(ctx, fx.Student(1), test.ID, q.ID); err != errs.ErrAnswerNotFound {
+		t.Fatalf("expected ErrAnswerNotFound for a student without an answer, got %v", err)
+	}
+
+	restored, err := service.RestoreAnswerRevision(ctx, fx.Student(0), test.ID, q.ID, 1)
+	if err != nil {
+		t.Fatalf("RestoreAnswerRevision failed: %v", err)
+	}
+	if restored.Response != "Plants" {
+		t.Fatalf("expected the first draft back, got %q", restored.Response)
+	}
+	answer, err = service.StudentAnswerHistory(ctx, fx.Student(0), test.ID, q.ID)
+	if err != nil {
+		t.Fatalf("StudentAnswerHistory failed: %v", err)
+	}
+	if len(answer.Revisions) != 3 || answer.Revisions[2].Response != "Plants make sugar from light" || answer.CurrentRevision() != 4 {
+		t.Fatalf("expected the replaced response kept as revision 3, got %+v", answer.Revisions)
+	}
+	if _, err := service.RestoreAnswerRevision(ctx, fx.Student(0), test.ID, q.ID, 9); err != errs.ErrRevisionNotFound {
+		t.Fatalf("expected ErrRevisionNotFound, got %v", err)
+	}
+}
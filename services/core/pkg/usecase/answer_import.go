@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/audit"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/export"
+	"github.com/sky0621/go_work_sample/core/pkg/id"
+)
+
+// AnswerImportError explains why one row of an answer upload was skipped.
+type AnswerImportError struct {
+	Line   int
+	Reason string
+}
+
+// AnswerImport reports the outcome of an answer upload.
+type AnswerImport struct {
+	Created int
+	Updated int
+	Errors  []AnswerImportError
+}
+
+// ImportAnswers records answers collected on paper. Valid rows are stored as
+// if the students had submitted them, replacing any earlier answer, and are
+// autograded like online answers; invalid rows are skipped and reported by
+// line. The test window and submission quotas do not apply, as the teacher
+// enters the answers after the fact.
+func (s *AssessmentService) ImportAnswers(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID, rows []export.AnswerCSVRow) (*AnswerImport, error) {
+	ctx, span := s.trace(ctx, "ImportAnswers")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	questions, err := s.listQuestions(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]domain.Question, 2*len(questions))
+	for _, q := range questions {
+		byKey[string(q.ID)] = q
+		byKey[strconv.Itoa(q.Sequence)] = q
+	}
+	assigned := make(map[domain.StudentID]bool, len(test.AssignedTo))
+	for _, studentID := range test.AssignedTo {
+		assigned[studentID] = true
+	}
+
+	report := &AnswerImport{Errors: make([]AnswerImportError, 0)}
+	reject := func(row export.AnswerCSVRow, reason string) {
+		report.Errors = append(report.Errors, AnswerImportError{Line: row.Line, Reason: reason})
+	}
+	type answerKey struct {
+		studentID  domain.StudentID
+		questionID domain.QuestionID
+	}
+	seen := make(map[answerKey]int, len(rows))
+	for _, row := range rows {
+		studentID := domain.StudentID(row.StudentID)
+		if !assigned[studentID] {
+			reject(row, "student is not assigned to the test")
+			continue
+		}
+		question, ok := byKey[row.Question]
+		if !ok {
+			reject(row, "unknown question")
+			continue
+		}
+		key := answerKey{studentID, question.ID}
+		if line, dup := seen[key]; dup {
+			reject(row, fmt.Sprintf("duplicates the answer on line %d", line))
+			continue
+		}
+		seen[key] = row.Line
+		if err := question.ValidateResponse(row.Response); err != nil {
+			reject(row, err.Error())
+			continue
+		}
+
+		created, err := s.importAnswer(ctx, teacherID, question, studentID, row.Response)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			report.Created++
+		} else {
+			report.Updated++
+		}
+	}
+	return report, nil
+}
+
+// importAnswer upserts one imported answer and reports whether it is new.
+func (s *AssessmentService) importAnswer(ctx context.Context, teacherID domain.TeacherID, question domain.Question, studentID domain.StudentID, response string) (bool, error) {
+	now := time.Now().UTC()
+	existing, err := s.answerRepo.GetAnswer(ctx, question.TestID, question.ID, studentID)
+	if err != nil {
+		return false, err
+	}
+	answer := &domain.Answer{
+		ID:         domain.AnswerID(id.New()),
+		TestID:     question.TestID,
+		QuestionID: question.ID,
+		StudentID:  studentID,
+		Response:   response,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if existing != nil {
+		answer.ID = existing.ID
+		answer.Note = existing.Note
+		answer.CreatedAt = existing.CreatedAt
+	}
+	if err := s.answerRepo.UpsertAnswer(ctx, answer); err != nil {
+		return false, err
+	}
+	s.record(domain.AnswerSaved{Answer: *answer})
+	s.audit(ctx, audit.AnswerImported(teacherID, *answer))
+
+	s.publish(EventAnswerSubmitted, answerEvent{
+		AnswerID:   string(answer.ID),
+		TestID:     string(answer.TestID),
+		QuestionID: string(answer.QuestionID),
+		StudentID:  string(answer.StudentID),
+	})
+	if _, err := s.autogradeAnswer(ctx, question, *answer); err != nil {
+		log.Printf("autograde answer %s: %v", answer.ID, err)
+	}
+	return existing == nil, nil
+}
@@ -0,0 +1,88 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/export"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_ImportAnswers(t *testing.T) {
+	fx := fixtures.NewSchool().WithTeachers(2).WithStudents(3).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	service.SetAutograder(exactGrader{})
+	ctx := context.Background()
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:     "Paper quiz",
+		TeacherID: fx.Teacher(0),
+		Questions: []usecase.QuestionDraft{
+			{Prompt: "The sky is blue", Points: 2, Type: domain.QuestionTrueFalse, ExpectedResponse: "true"},
+			{Prompt: "Explain why", Points: 3},
+		},
+		StudentIDs: []domain.StudentID{fx.Student(0), fx.Student(1)},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	if _, err := service.SubmitAnswer(ctx, &domain.Answer{
+		TestID: test.ID, QuestionID: questions[1].ID, StudentID: fx.Student(0), Response: "online",
+	}); err != nil {
+		t.Fatalf("SubmitAnswer failed: %v", err)
+	}
+
+	rows := []export.AnswerCSVRow{
+		{Line: 2, StudentID: string(fx.Student(0)), Question: "1", Response: "true"},
+		{Line: 3, StudentID: string(fx.Student(0)), Question: string(questions[1].ID), Response: "Rayleigh scattering"},
+		{Line: 4, StudentID: string(fx.Student(1)), Question: "1", Response: "maybe"},
+		{Line: 5, StudentID: string(fx.Student(1)), Question: "9", Response: "x"},
+		{Line: 6, StudentID: string(fx.Student(2)), Question: "1", Response: "true"},
+		{Line: 7, StudentID: string(fx.Student(0)), Question: "1", Response: "false"},
+		{Line: 8, StudentID: string(fx.Student(1)), Question: "2", Response: "light"},
+	}
+
+	if _, err := service.ImportAnswers(ctx, fx.Teacher(1), test.ID, rows); err != errs.ErrForbiddenTeacher {
+		t.Fatalf("expected ErrForbiddenTeacher, got %v", err)
+	}
+	report, err := service.ImportAnswers(ctx, fx.Teacher(0), test.ID, rows)
+	if err != nil {
+		t.Fatalf("ImportAnswers failed: %v", err)
+	}
+	if report.Created != 2 || report.Updated != 1 {
+		t.Fatalf("expected 2 created and 1 updated, got %+v", report)
+	}
+	wantLines := []int{4, 5, 6, 7}
+	if len(report.Errors) != len(wantLines) {
+		t.Fatalf("expected errors on lines %v, got %+v", wantLines, report.Errors)
+	}
+	for i, line := range wantLines {
+		if report.Errors[i].Line != line || report.Errors[i].Reason == "" {
+			t.Fatalf("expected an error on line %d, got %+v", line, report.Errors[i])
+		}
+	}
+
+	answers, err := repository.Collect(fx.Repo.ListAnswersByTest(ctx, test.ID, repository.All))
+	if err != nil {
+		t.Fatalf("ListAnswersByTest failed: %v", err)
+	}
+	if len(answers) != 3 {
+		t.Fatalf("expected 3 answers, got %d", len(answers))
+	}
+	for _, answer := range answers {
+		if answer.QuestionID == questions[1].ID && answer.StudentID == fx.Student(0) && answer.Response != "Rayleigh scattering" {
+			t.Fatalf("expected the paper answer to replace the online one, got %q", answer.Response)
+		}
+		if answer.QuestionID != questions[0].ID {
+			continue
+		}
+		result, err := fx.Repo.GetResult(ctx, answer.ID)
+		if err != nil || result == nil || result.Score != 2 {
+			t.Fatalf("expected the imported answer to be autograded, got %+v (%v)", result, err)
+		}
+	}
+}
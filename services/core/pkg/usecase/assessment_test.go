@@ -2,19 +2,24 @@ package usecase_test
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/sky0621/go_work_sample/core/pkg/domain"
-	"github.com/sky0621/go_work_sample/core/pkg/memory"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/notify"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
 	"github.com/sky0621/go_work_sample/core/pkg/usecase"
 )
 
 func TestAssessmentService_Workflow(t *testing.T) {
-	repo := memory.NewRepository(memory.SampleSeed())
-	service := usecase.NewAssessmentService(repo, repo, repo, repo)
+	fx := fixtures.NewSchool().WithStudents(2).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
 
-	teacherID := domain.TeacherID("teacher-001")
-	studentIDs := []domain.StudentID{"student-001", "student-002"}
+	teacherID := fx.Teacher(0)
+	studentIDs := []domain.StudentID{fx.Student(0), fx.Student(1)}
 
 	test, questions, err := service.CreateTest(context.Background(), usecase.CreateTestInput{
 		Title:      "Math Quiz",
@@ -29,7 +34,7 @@ func TestAssessmentService_Workflow(t *testing.T) {
 		t.Fatalf("expected one question, got %d", len(questions))
 	}
 
-	tests, err := service.ListTestsByTeacher(context.Background(), teacherID)
+	tests, err := repository.Collect(service.ListTestsByTeacher(context.Background(), teacherID, repository.All))
 	if err != nil {
 		t.Fatalf("ListTestsByTeacher failed: %v", err)
 	}
@@ -37,7 +42,7 @@ func TestAssessmentService_Workflow(t *testing.T) {
 		t.Fatalf("expected one test, got %d", len(tests))
 	}
 
-	studentTests, err := service.ListTestsForStudent(context.Background(), studentIDs[0])
+	studentTests, err := repository.Collect(service.ListTestsForStudent(context.Background(), studentIDs[0], repository.All))
 	if err != nil {
 		t.Fatalf("ListTestsForStudent failed: %v", err)
 	}
@@ -82,4 +87,205 @@ func TestAssessmentService_Workflow(t *testing.T) {
 	if len(results) != 1 {
 		t.Fatalf("expected one result, got %d", len(results))
 	}
+
+	answers, err := service.ListAnswersForStudent(context.Background(), studentIDs[0], test.ID)
+	if err != nil {
+		t.Fatalf("ListAnswersForStudent failed: %v", err)
+	}
+	if len(answers) != 1 || answers[0].ID != savedAnswer.ID {
+		t.Fatalf("expected the student's answer, got %+v", answers)
+	}
+}
+
+func TestAssessmentService_AnswerNote(t *testing.T) {
+	fx := fixtures.NewSchool().Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Notes",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "Describe a cell", Points: 5}},
+		StudentIDs: []domain.StudentID{fx.Student(0)},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+
+	tooLong := &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: fx.Student(0), Note: strings.Repeat("x", usecase.MaxAnswerNoteLength+1)}
+	if _, err := service.SubmitAnswer(ctx, tooLong); err != errs.ErrInvalidAnswer {
+		t.Fatalf("expected ErrInvalidAnswer for an oversized note, got %v", err)
+	}
+
+	note := "I interpreted the question as an animal cell"
+	if _, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: fx.Student(0), Response: "nucleus", Note: note}); err != nil {
+		t.Fatalf("SubmitAnswer failed: %v", err)
+	}
+	answers, err := repository.Collect(service.ListAnswersByTest(ctx, fx.Teacher(0), test.ID, repository.All))
+	if err != nil {
+		t.Fatalf("ListAnswersByTest failed: %v", err)
+	}
+	if len(answers) != 1 || answers[0].Note != note {
+		t.Fatalf("expected the note to be shown to the teacher, got %+v", answers)
+	}
+}
+
+func TestAssessmentService_QuestionTypes(t *testing.T) {
+	fx := fixtures.NewSchool().Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:     "Geography",
+		TeacherID: fx.Teacher(0),
+		Questions: []usecase.QuestionDraft{
+			{Prompt: "Capital of Italy?", Points: 1, Type: domain.QuestionMultipleChoice, Choices: []domain.Choice{{Key: "a", Label: "Milan"}, {Key: "b", Label: "Rome"}}},
+			{Prompt: "The Nile is in Africa", Points: 1, Type: domain.QuestionTrueFalse},
+			{Prompt: "Describe a delta", Points: 3},
+		},
+		StudentIDs: []domain.StudentID{fx.Student(0)},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	if questions[2].Type != domain.QuestionFreeText {
+		t.Fatalf("expected an untyped draft to become free text, got %q", questions[2].Type)
+	}
+
+	submit := func(q domain.Question, response string) error {
+		_, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: q.ID, StudentID: fx.Student(0), Response: response})
+		return err
+	}
+	if err := submit(questions[0], "Rome"); !errors.Is(err, errs.ErrInvalidAnswer) {
+		t.Fatalf("expected ErrInvalidAnswer for a response that is not a choice key, got %v", err)
+	}
+	if err := submit(questions[0], "b"); err != nil {
+		t.Fatalf("SubmitAnswer with a choice key failed: %v", err)
+	}
+	if err := submit(questions[1], "yes"); !errors.Is(err, errs.ErrInvalidAnswer) {
+		t.Fatalf("expected ErrInvalidAnswer for a non-boolean response, got %v", err)
+	}
+	if err := submit(questions[1], "true"); err != nil {
+		t.Fatalf("SubmitAnswer for a true/false question failed: %v", err)
+	}
+	if err := submit(questions[2], "Where a river meets the sea"); err != nil {
+		t.Fatalf("SubmitAnswer for a free-text question failed: %v", err)
+	}
+
+	if _, _, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:     "Broken",
+		TeacherID: fx.Teacher(0),
+		Questions: []usecase.QuestionDraft{{Prompt: "Pick one", Points: 1, Type: domain.QuestionMultipleChoice}},
+	}); !errors.Is(err, errs.ErrInvalidQuestion) {
+		t.Fatalf("expected ErrInvalidQuestion for a multiple-choice question without choices, got %v", err)
+	}
+}
+
+func TestAssessmentService_Instructions(t *testing.T) {
+	fx := fixtures.NewSchool().Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:        "Final",
+		Instructions: "Answer **all** questions.",
+		TeacherID:    fx.Teacher(0),
+		Sections:     []usecase.SectionDraft{{Title: "Part A", Instructions: "No calculators."}},
+		Questions: []usecase.QuestionDraft{
+			{Prompt: "Warm-up", Points: 1},
+			{Prompt: "2x3?", Points: 2, Section: 1},
+		},
+		StudentIDs: []domain.StudentID{fx.Student(0)},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	if questions[0].SectionID != "" || questions[1].SectionID != test.Sections[0].ID {
+		t.Fatalf("unexpected question sections: %q, %q", questions[0].SectionID, questions[1].SectionID)
+	}
+
+	if _, _, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:     "Broken",
+		TeacherID: fx.Teacher(0),
+		Questions: []usecase.QuestionDraft{{Prompt: "Q", Points: 1, Section: 1}},
+	}); err != errs.ErrInvalidQuestion {
+		t.Fatalf("expected ErrInvalidQuestion for an unknown section, got %v", err)
+	}
+
+	instructions := "Calculators allowed."
+	if _, err := service.UpdateTestDetails(ctx, fx.Teacher(0), test.ID, usecase.TestDetailsInput{
+		Sections: []usecase.SectionEdit{{ID: test.Sections[0].ID, Instructions: &instructions}},
+	}); err != nil {
+		t.Fatalf("UpdateTestDetails failed: %v", err)
+	}
+	if _, err := service.UpdateTestDetails(ctx, fx.Teacher(0), test.ID, usecase.TestDetailsInput{
+		Sections: []usecase.SectionEdit{{ID: "missing", Instructions: &instructions}},
+	}); err != errs.ErrSectionNotFound {
+		t.Fatalf("expected ErrSectionNotFound, got %v", err)
+	}
+
+	got, err := service.GetTestForStudent(ctx, fx.Student(0), test.ID)
+	if err != nil {
+		t.Fatalf("GetTestForStudent failed: %v", err)
+	}
+	if got.Instructions != "Answer **all** questions." || got.Sections[0].Instructions != instructions {
+		t.Fatalf("unexpected instructions: %q, %+v", got.Instructions, got.Sections)
+	}
+}
+
+func TestAssessmentService_GradeAnswerNotifiesOnRelease(t *testing.T) {
+	fx := fixtures.NewSchool().WithStudents(1).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	service.SetNotifier(notify.NewService(discardMailer{}, fx.Repo, fx.Repo))
+	ctx := context.Background()
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Essay",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "Why?", Points: 10}},
+		StudentIDs: []domain.StudentID{fx.Student(0)},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	if _, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: fx.Student(0), Response: "because"}); err != nil {
+		t.Fatalf("SubmitAnswer failed: %v", err)
+	}
+	released := func() int {
+		inbox, err := fx.Repo.ListNotifications(ctx, domain.RoleStudent, string(fx.Student(0)), repository.All)
+		if err != nil {
+			t.Fatalf("ListNotifications failed: %v", err)
+		}
+		count := 0
+		for _, n := range inbox.Items {
+			if n.Kind == string(notify.KindResultReleased) {
+				count++
+			}
+		}
+		return count
+	}
+
+	grade := usecase.GradeInput{TeacherID: fx.Teacher(0), TestID: test.ID, QuestionID: questions[0].ID, StudentID: fx.Student(0), Score: 4}
+	if _, err := service.GradeAnswer(ctx, grade); err != nil {
+		t.Fatalf("GradeAnswer failed: %v", err)
+	}
+	if got := released(); got != 0 {
+		t.Fatalf("expected no notification for an incomplete grade, got %d", got)
+	}
+
+	grade.Score, grade.Completed = 7, true
+	if _, err := service.GradeAnswer(ctx, grade); err != nil {
+		t.Fatalf("GradeAnswer failed: %v", err)
+	}
+	if got := released(); got != 1 {
+		t.Fatalf("expected one notification once the regrade completes the result, got %d", got)
+	}
+
+	grade.Score = 8
+	if _, err := service.GradeAnswer(ctx, grade); err != nil {
+		t.Fatalf("GradeAnswer failed: %v", err)
+	}
+	if got := released(); got != 1 {
+		t.Fatalf("expected no further notification for a released result, got %d", got)
+	}
 }
@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/audit"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// AssigneeChange adds students to a test and removes others. Students are
+// added one by one or by every student of a class or grade.
+type AssigneeChange struct {
+	AddStudentIDs    []domain.StudentID
+	AddClassIDs      []domain.ClassID
+	AddGradeIDs      []domain.GradeID
+	RemoveStudentIDs []domain.StudentID
+}
+
+// UpdateAssignees changes who a test is assigned to. Students who already
+// answered the test cannot be removed. When the test is published, added
+// students are notified as on publication.
+func (s *AssessmentService) UpdateAssignees(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID, change AssigneeChange) (*domain.Test, error) {
+	ctx, span := s.trace(ctx, "UpdateAssignees")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureDraftUnlocked(test, teacherID); err != nil {
+		return nil, err
+	}
+	owner, err := s.orgRepo.GetTeacher(ctx, test.TeacherID)
+	if err != nil {
+		return nil, err
+	}
+	if owner == nil {
+		return nil, errs.ErrTeacherNotFound
+	}
+
+	requested, err := s.expandAssignees(ctx, owner.SchoolID, change.AddStudentIDs, change.AddClassIDs, change.AddGradeIDs)
+	if err != nil {
+		return nil, err
+	}
+	assigned := make(map[domain.StudentID]bool, len(test.AssignedTo))
+	for _, studentID := range test.AssignedTo {
+		assigned[studentID] = true
+	}
+	removing := make(map[domain.StudentID]bool, len(change.RemoveStudentIDs))
+	var removed []domain.StudentID
+	for _, studentID := range change.RemoveStudentIDs {
+		if !assigned[studentID] || removing[studentID] {
+			continue
+		}
+		answers, err := s.answerRepo.ListAnswers(ctx, testID, studentID)
+		if err != nil {
+			return nil, err
+		}
+		if len(answers) > 0 {
+			return nil, errs.ErrStudentAnswered
+		}
+		removing[studentID] = true
+		removed = append(removed, studentID)
+	}
+	var added []domain.StudentID
+	for _, studentID := range requested {
+		if !assigned[studentID] && !removing[studentID] {
+			added = append(added, studentID)
+		}
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return test, nil
+	}
+
+	if err := s.testRepo.UpdateAssignments(ctx, testID, added, removed); err != nil {
+		return nil, err
+	}
+	test.Reassign(added, removed)
+	// Bump the test so offline clients syncing since before the change
+	// pick it up (see SyncForStudent).
+	test.UpdatedAt = time.Now().UTC()
+	if err := s.testRepo.UpdateTest(ctx, test); err != nil {
+		return nil, err
+	}
+	s.record(domain.AssigneesChanged{Test: *test, Added: added, Removed: removed})
+	for _, studentID := range added {
+		s.audit(ctx, audit.AssigneeAdded(teacherID, testID, studentID))
+	}
+	for _, studentID := range removed {
+		s.audit(ctx, audit.AssigneeRemoved(teacherID, testID, studentID))
+	}
+
+	if test.Published && len(added) > 0 {
+		notified := *test
+		notified.AssignedTo = added
+		s.notifyAssigned(ctx, &notified, time.Now().UTC())
+		s.publish(EventTestAssigned, testEvent{
+			TestID:     string(test.ID),
+			TeacherID:  string(test.TeacherID),
+			Title:      test.Title,
+			StudentIDs: studentIDStrings(added),
+			Published:  true,
+		})
+	}
+	return test, nil
+}
+
+// expandAssignees returns the students listed, followed by the students of
+// the classes and grades listed, each once. Classes and grades must belong to
+// the school.
+func (s *AssessmentService) expandAssignees(ctx context.Context, schoolID domain.SchoolID, studentIDs []domain.StudentID, classIDs []domain.ClassID, gradeIDs []domain.GradeID) ([]domain.StudentID, error) {
+	classIDs = append([]domain.ClassID(nil), classIDs...)
+	var out []domain.StudentID
+	seen := make(map[domain.StudentID]bool)
+	add := func(studentID domain.StudentID) {
+		if !seen[studentID] {
+			seen[studentID] = true
+			out = append(out, studentID)
+		}
+	}
+
+	for _, studentID := range studentIDs {
+		student, err := s.orgRepo.GetStudent(ctx, studentID)
+		if err != nil {
+			return nil, err
+		}
+		if student == nil {
+			return nil, errs.ErrStudentNotFound
+		}
+		add(studentID)
+	}
+
+	for _, gradeID := range gradeIDs {
+		grade, err := s.orgRepo.GetGrade(ctx, gradeID)
+		if err != nil {
+			return nil, err
+		}
+		if grade == nil || grade.SchoolID != schoolID {
+			return nil, errs.ErrGradeNotFound
+		}
+		classes, err := s.orgRepo.ListClasses(ctx, gradeID, repository.All)
+		if err != nil {
+			return nil, err
+		}
+		for _, class := range classes.Items {
+			classIDs = append(classIDs, class.ID)
+		}
+	}
+
+	for _, classID := range classIDs {
+		class, err := s.orgRepo.GetClass(ctx, classID)
+		if err != nil {
+			return nil, err
+		}
+		if class == nil {
+			return nil, errs.ErrClassNotFound
+		}
+		grade, err := s.orgRepo.GetGrade(ctx, class.GradeID)
+		if err != nil {
+			return nil, err
+		}
+		if grade == nil || grade.SchoolID != schoolID {
+			return nil, errs.ErrClassNotFound
+		}
+		students, err := s.orgRepo.ListStudents(ctx, classID, repository.All)
+		if err != nil {
+			return nil, err
+		}
+		for _, student := range students.Items {
+			add(student.ID)
+		}
+	}
+	return out, nil
+}
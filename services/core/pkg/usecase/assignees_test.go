@@ -0,0 +1,106 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_AssignsByClassAndGrade(t *testing.T) {
+	fx := fixtures.NewSchool().WithGrades(2).WithClasses(2).WithStudents(3).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	first := fx.Classes[0]
+	var otherGrade []domain.StudentID
+	for _, class := range fx.Classes {
+		if class.GradeID == fx.Grades[1].ID {
+			otherGrade = append(otherGrade, fx.StudentsOf(class.ID)...)
+		}
+	}
+	lone := fx.StudentsOf(first.ID)[0]
+
+	test, _, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Fractions",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "1/2 + 1/4?", Points: 2}},
+		StudentIDs: []domain.StudentID{lone},
+		ClassIDs:   []domain.ClassID{first.ID},
+		GradeIDs:   []domain.GradeID{fx.Grades[1].ID},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	if want := len(fx.StudentsOf(first.ID)) + len(otherGrade); len(test.AssignedTo) != want {
+		t.Fatalf("expected %d assignees without duplicates, got %v", want, test.AssignedTo)
+	}
+	if test.AssignedTo[0] != lone {
+		t.Fatalf("expected listed students first, got %v", test.AssignedTo)
+	}
+
+	if _, _, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:     "Broken",
+		TeacherID: fx.Teacher(0),
+		Questions: []usecase.QuestionDraft{{Prompt: "Q", Points: 1}},
+		ClassIDs:  []domain.ClassID{"missing"},
+	}); err != errs.ErrClassNotFound {
+		t.Fatalf("expected ErrClassNotFound, got %v", err)
+	}
+}
+
+func TestAssessmentService_UpdateAssignees(t *testing.T) {
+	fx := fixtures.NewSchool().WithClasses(2).WithStudents(2).WithTeachers(2).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	first, second := fx.StudentsOf(fx.Classes[0].ID), fx.StudentsOf(fx.Classes[1].ID)
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Reading",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "Summarise chapter one", Points: 5}},
+		StudentIDs: first,
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	if _, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: first[0], Response: "A storm"}); err != nil {
+		t.Fatalf("SubmitAnswer failed: %v", err)
+	}
+
+	if _, err := service.UpdateAssignees(ctx, fx.Teacher(1), test.ID, usecase.AssigneeChange{AddClassIDs: []domain.ClassID{fx.Classes[1].ID}}); err != errs.ErrForbiddenTeacher {
+		t.Fatalf("expected another teacher to be refused, got %v", err)
+	}
+	if _, err := service.UpdateAssignees(ctx, fx.Teacher(0), test.ID, usecase.AssigneeChange{RemoveStudentIDs: []domain.StudentID{first[0]}}); err != errs.ErrStudentAnswered {
+		t.Fatalf("expected ErrStudentAnswered, got %v", err)
+	}
+
+	updated, err := service.UpdateAssignees(ctx, fx.Teacher(0), test.ID, usecase.AssigneeChange{
+		AddClassIDs:      []domain.ClassID{fx.Classes[1].ID},
+		RemoveStudentIDs: []domain.StudentID{first[1]},
+	})
+	if err != nil {
+		t.Fatalf("UpdateAssignees failed: %v", err)
+	}
+	want := append([]domain.StudentID{first[0]}, second...)
+	if len(updated.AssignedTo) != len(want) {
+		t.Fatalf("expected assignees %v, got %v", want, updated.AssignedTo)
+	}
+	for i := range want {
+		if updated.AssignedTo[i] != want[i] {
+			t.Fatalf("expected assignees %v, got %v", want, updated.AssignedTo)
+		}
+	}
+
+	stored, err := fx.Repo.GetTest(ctx, test.ID)
+	if err != nil || len(stored.AssignedTo) != len(want) {
+		t.Fatalf("expected the change to be stored, got %+v (%v)", stored, err)
+	}
+	if tests, _ := service.ListTestsForStudent(ctx, first[1], repository.All); len(tests.Items) != 0 {
+		t.Fatalf("expected the removed student to no longer see the test, got %d tests", len(tests.Items))
+	}
+}
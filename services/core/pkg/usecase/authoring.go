@@ -0,0 +1,200 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/id"
+	"github.com/sky0621/go_work_sample/core/pkg/notify"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+const maxCommentLength = 4000
+
+var mentionPattern = regexp.MustCompile(`@([A-Za-z0-9_-]+)`)
+
+// AuthoringService supports collaboration between teachers while a test is
+// being written.
+type AuthoringService struct {
+	orgRepo        repository.OrganizationReader
+	testRepo       repository.TestReader
+	commentRepo    repository.QuestionCommentRepository
+	notifier       *notify.Service
+	delegationRepo repository.DelegationReader
+}
+
+// NewAuthoringService constructs an authoring service. notifier may be nil.
+func NewAuthoringService(
+	org repository.OrganizationReader,
+	test repository.TestReader,
+	comments repository.QuestionCommentRepository,
+	notifier *notify.Service,
+) *AuthoringService {
+	return &AuthoringService{
+		orgRepo:     org,
+		testRepo:    test,
+		commentRepo: comments,
+		notifier:    notifier,
+	}
+}
+
+// SetDelegations lets delegates of a test's owner comment on it and be
+// mentioned. Without a repository only owners can access their tests.
+func (s *AuthoringService) SetDelegations(delegations repository.DelegationReader) {
+	s.delegationRepo = delegations
+}
+
+// CommentInput describes a new comment or reply on a question.
+type CommentInput struct {
+	TeacherID  domain.TeacherID
+	TestID     domain.TestID
+	QuestionID domain.QuestionID
+	ParentID   domain.QuestionCommentID
+	Body       string
+}
+
+// CommentThread is a comment with its replies, oldest first.
+type CommentThread struct {
+	Comment domain.QuestionComment
+	Replies []CommentThread
+}
+
+// AddComment stores a comment and notifies mentioned teachers that can access
+// the test. Mentions use the "@teacher-id" form.
+func (s *AuthoringService) AddComment(ctx context.Context, input CommentInput) (*domain.QuestionComment, error) {
+	body := strings.TrimSpace(input.Body)
+	if body == "" || len(body) > maxCommentLength {
+		return nil, errs.ErrInvalidComment
+	}
+	if err := ensureTeacherAccess(ctx, s.testRepo, s.delegationRepo, input.TeacherID, input.TestID); err != nil {
+		return nil, err
+	}
+	if err := s.ensureQuestionInTest(ctx, input.TestID, input.QuestionID); err != nil {
+		return nil, err
+	}
+
+	if input.ParentID != "" {
+		parent, err := s.commentRepo.GetQuestionComment(ctx, input.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil || parent.TestID != input.TestID || parent.QuestionID != input.QuestionID {
+			return nil, errs.ErrCommentNotFound
+		}
+	}
+
+	comment := &domain.QuestionComment{
+		ID:         domain.QuestionCommentID(id.New()),
+		TestID:     input.TestID,
+		QuestionID: input.QuestionID,
+		ParentID:   input.ParentID,
+		AuthorID:   input.TeacherID,
+		Body:       body,
+		Mentions:   s.resolveMentions(ctx, input.TestID, input.TeacherID, body),
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	if err := s.commentRepo.SaveQuestionComment(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	s.notifyMentions(ctx, comment)
+	return comment, nil
+}
+
+// ListComments returns the comment threads of a question.
+func (s *AuthoringService) ListComments(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID, questionID domain.QuestionID) ([]CommentThread, error) {
+	if err := ensureTeacherAccess(ctx, s.testRepo, s.delegationRepo, teacherID, testID); err != nil {
+		return nil, err
+	}
+	if err := s.ensureQuestionInTest(ctx, testID, questionID); err != nil {
+		return nil, err
+	}
+
+	comments, err := s.commentRepo.ListQuestionComments(ctx, testID, questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[domain.QuestionCommentID][]domain.QuestionComment)
+	for _, c := range comments {
+		children[c.ParentID] = append(children[c.ParentID], c)
+	}
+
+	var build func(parent domain.QuestionCommentID) []CommentThread
+	build = func(parent domain.QuestionCommentID) []CommentThread {
+		threads := make([]CommentThread, 0, len(children[parent]))
+		for _, c := range children[parent] {
+			threads = append(threads, CommentThread{Comment: c, Replies: build(c.ID)})
+		}
+		return threads
+	}
+
+	return build(""), nil
+}
+
+func (s *AuthoringService) ensureQuestionInTest(ctx context.Context, testID domain.TestID, questionID domain.QuestionID) error {
+	found, err := s.testRepo.HasQuestion(ctx, testID, questionID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errs.ErrQuestionNotFound
+	}
+	return nil
+}
+
+// resolveMentions keeps mentions of teachers other than the author who can
+// access the test, so comments never leak to teachers without access.
+func (s *AuthoringService) resolveMentions(ctx context.Context, testID domain.TestID, author domain.TeacherID, body string) []domain.TeacherID {
+	seen := make(map[domain.TeacherID]struct{})
+	var mentions []domain.TeacherID
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		teacherID := domain.TeacherID(match[1])
+		if teacherID == author {
+			continue
+		}
+		if _, dup := seen[teacherID]; dup {
+			continue
+		}
+		seen[teacherID] = struct{}{}
+		if ensureTeacherAccess(ctx, s.testRepo, s.delegationRepo, teacherID, testID) != nil {
+			continue
+		}
+		mentions = append(mentions, teacherID)
+	}
+	return mentions
+}
+
+func (s *AuthoringService) notifyMentions(ctx context.Context, comment *domain.QuestionComment) {
+	if s.notifier == nil {
+		return
+	}
+	for _, teacherID := range comment.Mentions {
+		teacher, err := s.orgRepo.GetTeacher(ctx, teacherID)
+		if err != nil || teacher == nil {
+			continue
+		}
+		to := notify.Recipient{
+			Role:        domain.RoleTeacher,
+			ID:          string(teacher.ID),
+			Name:        teacher.Name,
+			Email:       teacher.Email,
+			Preferences: teacher.Notifications,
+		}
+		n := notify.Notification{
+			Kind:       notify.KindMention,
+			Subject:    "You were mentioned on a question by " + string(comment.AuthorID),
+			Body:       comment.Body,
+			OccurredAt: comment.CreatedAt,
+		}
+		if err := s.notifier.Deliver(ctx, to, n); err != nil {
+			log.Printf("notify teacher %s: %v", teacherID, err)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/audit"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/id"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// Autograder scores answers to objective questions. Grade reports false for
+// questions it cannot grade, which are left to the teacher.
+type Autograder interface {
+	Grade(question domain.Question, response string) (domain.Score, bool)
+}
+
+// AutogradeSummary counts the answers of one autograde pass.
+type AutogradeSummary struct {
+	// Graded answers received a result from the autograder.
+	Graded int
+	// Kept answers already had a result, which was left untouched.
+	Kept int
+	// Manual answers belong to questions the autograder cannot grade.
+	Manual int
+}
+
+// SetAutograder grades answers to objective questions as students submit
+// them. Without an autograder every answer is graded by hand.
+func (s *AssessmentService) SetAutograder(grader Autograder) {
+	s.autograder = grader
+}
+
+// AutogradeTest grades every answer of the test the autograder can grade and
+// that has no result yet, so grades a teacher already gave are kept. Results
+// are stored unreleased for the teacher to review.
+func (s *AssessmentService) AutogradeTest(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID) (*AutogradeSummary, error) {
+	ctx, span := s.trace(ctx, "AutogradeTest")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	questions, err := s.testRepo.ListQuestions(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[domain.QuestionID]domain.Question, len(questions))
+	for _, q := range questions {
+		byID[q.ID] = q
+	}
+	answers, err := repository.Collect(s.answerRepo.ListAnswersByTest(ctx, testID, repository.All))
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &AutogradeSummary{}
+	for _, answer := range answers {
+		existing, err := s.resultRepo.GetResult(ctx, answer.ID)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			summary.Kept++
+			continue
+		}
+		graded, err := s.autogradeAnswer(ctx, byID[answer.QuestionID], answer)
+		if err != nil {
+			return nil, err
+		}
+		if graded {
+			summary.Graded++
+		} else {
+			summary.Manual++
+		}
+	}
+	return summary, nil
+}
+
+// autogradeAnswer stores the autograder's score for the answer and reports
+// whether it could grade it. A released result keeps its release state.
+func (s *AssessmentService) autogradeAnswer(ctx context.Context, question domain.Question, answer domain.Answer) (bool, error) {
+	if s.autograder == nil {
+		return false, nil
+	}
+	score, ok := s.autograder.Grade(question, answer.Response)
+	if !ok {
+		return false, nil
+	}
+
+	now := time.Now().UTC()
+	result, err := s.resultRepo.GetResult(ctx, answer.ID)
+	if err != nil {
+		return false, err
+	}
+	var previous *domain.Score
+	if result != nil {
+		old := result.Score
+		previous = &old
+	} else {
+		result = &domain.Result{
+			ID:        domain.ResultID(id.New()),
+			AnswerID:  answer.ID,
+			CreatedAt: now,
+		}
+	}
+	result.Score = score
+	result.RawScore = nil
+	result.GradedBy = ""
+	result.UpdatedAt = now
+	if err := s.resultRepo.SaveResult(ctx, result); err != nil {
+		return false, err
+	}
+
+	s.stats.entries.Invalidate(answer.TestID)
+	s.recordGraded(answer.TestID, answer.QuestionID, answer.StudentID, result)
+	s.audit(ctx, audit.AnswerAutograded(answer, previous, score))
+	s.publishGraded(GradeInput{
+		TestID:     answer.TestID,
+		QuestionID: answer.QuestionID,
+		StudentID:  answer.StudentID,
+	}, result)
+	return true, nil
+}
@@ -0,0 +1,81 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+// exactGrader awards full points when the response equals the expected one.
+type exactGrader struct{}
+
+func (exactGrader) Grade(q domain.Question, response string) (domain.Score, bool) {
+	if q.ExpectedResponse == "" {
+		return 0, false
+	}
+	if response != q.ExpectedResponse {
+		return 0, true
+	}
+	return domain.Score(q.Points), true
+}
+
+func TestAssessmentService_Autograde(t *testing.T) {
+	fx := fixtures.NewSchool().WithStudents(2).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+	teacher := fx.Teacher(0)
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:     "Quiz",
+		TeacherID: teacher,
+		Questions: []usecase.QuestionDraft{
+			{Prompt: "Water boils at 100C at sea level", Points: 2, Type: domain.QuestionTrueFalse, ExpectedResponse: "true"},
+			{Prompt: "Explain evaporation", Points: 5},
+		},
+		StudentIDs: []domain.StudentID{fx.Student(0), fx.Student(1)},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	submit := func(student domain.StudentID, q domain.Question, response string) *domain.Answer {
+		answer, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: q.ID, StudentID: student, Response: response})
+		if err != nil {
+			t.Fatalf("SubmitAnswer failed: %v", err)
+		}
+		return answer
+	}
+
+	// Answers submitted before an autograder is configured wait for the
+	// batch pass.
+	early := submit(fx.Student(0), questions[0], "true")
+	submit(fx.Student(0), questions[1], "Molecules escape the liquid")
+
+	service.SetAutograder(exactGrader{})
+	onSubmit := submit(fx.Student(1), questions[0], "false")
+	result, err := fx.Repo.GetResult(ctx, onSubmit.ID)
+	if err != nil || result == nil {
+		t.Fatalf("expected the answer to be graded on submission, got %+v, %v", result, err)
+	}
+	if result.Score != 0 || result.Completed {
+		t.Fatalf("expected an unreleased zero score for a wrong answer, got %+v", result)
+	}
+
+	summary, err := service.AutogradeTest(ctx, teacher, test.ID)
+	if err != nil {
+		t.Fatalf("AutogradeTest failed: %v", err)
+	}
+	if summary.Graded != 1 || summary.Kept != 1 || summary.Manual != 1 {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+	result, err = fx.Repo.GetResult(ctx, early.ID)
+	if err != nil || result == nil || result.Score != 2 {
+		t.Fatalf("expected full points for the correct early answer, got %+v, %v", result, err)
+	}
+
+	if _, err := service.AutogradeTest(ctx, fx.Teacher(0)+"-other", test.ID); err == nil {
+		t.Fatalf("expected another teacher to be refused")
+	}
+}
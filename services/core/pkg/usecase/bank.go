@@ -0,0 +1,233 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/id"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// QuestionBankService manages teachers' question banks: standalone, tagged
+// questions that tests reuse by copying them (see QuestionDraft). Tests and
+// results are read to report how often pooled questions were used.
+type QuestionBankService struct {
+	orgRepo    repository.OrganizationReader
+	bankRepo   repository.QuestionBankRepository
+	testRepo   repository.TestReader
+	resultRepo repository.ResultReader
+}
+
+// NewQuestionBankService constructs a question bank service.
+func NewQuestionBankService(org repository.OrganizationReader, bank repository.QuestionBankRepository, tests repository.TestReader, results repository.ResultReader) *QuestionBankService {
+	return &QuestionBankService{orgRepo: org, bankRepo: bank, testRepo: tests, resultRepo: results}
+}
+
+// BankQuestionInput describes a bank question to create or the new content of
+// one to update.
+type BankQuestionInput struct {
+	TeacherID        domain.TeacherID
+	Prompt           string
+	Points           domain.Points
+	Difficulty       domain.Difficulty
+	Type             domain.QuestionType
+	Choices          []domain.Choice
+	ExpectedResponse string
+	Tags             []string
+	// Shared puts the question in the school's pool.
+	Shared bool
+}
+
+// BankQuery filters a question bank. Text matches the prompt ignoring case;
+// every tag listed must be present. Empty fields do not filter.
+type BankQuery struct {
+	Text       string
+	Tags       []string
+	Difficulty domain.Difficulty
+	Type       domain.QuestionType
+}
+
+// CreateBankQuestion adds a question to the teacher's bank.
+func (s *QuestionBankService) CreateBankQuestion(ctx context.Context, input BankQuestionInput) (*domain.BankQuestion, error) {
+	if err := s.ensureTeacherExists(ctx, input.TeacherID); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	question := &domain.BankQuestion{
+		ID:        domain.BankQuestionID(id.New()),
+		TeacherID: input.TeacherID,
+		CreatedAt: now,
+	}
+	if err := applyBankInput(question, input, now); err != nil {
+		return nil, err
+	}
+	if err := s.bankRepo.SaveBankQuestion(ctx, question); err != nil {
+		return nil, err
+	}
+	return question, nil
+}
+
+// GetBankQuestion returns a question of the teacher's bank.
+func (s *QuestionBankService) GetBankQuestion(ctx context.Context, teacherID domain.TeacherID, questionID domain.BankQuestionID) (*domain.BankQuestion, error) {
+	return ownedBankQuestion(ctx, s.bankRepo, teacherID, questionID)
+}
+
+// UpdateBankQuestion replaces the content of a bank question. Tests that
+// already copied the question keep their copy unchanged.
+func (s *QuestionBankService) UpdateBankQuestion(ctx context.Context, questionID domain.BankQuestionID, input BankQuestionInput) (*domain.BankQuestion, error) {
+	question, err := ownedBankQuestion(ctx, s.bankRepo, input.TeacherID, questionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyBankInput(question, input, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	if err := s.bankRepo.SaveBankQuestion(ctx, question); err != nil {
+		return nil, err
+	}
+	return question, nil
+}
+
+// DeleteBankQuestion removes a question from the teacher's bank. Tests that
+// copied it are unaffected.
+func (s *QuestionBankService) DeleteBankQuestion(ctx context.Context, teacherID domain.TeacherID, questionID domain.BankQuestionID) error {
+	if _, err := ownedBankQuestion(ctx, s.bankRepo, teacherID, questionID); err != nil {
+		return err
+	}
+	return s.bankRepo.DeleteBankQuestion(ctx, questionID)
+}
+
+// SearchBankQuestions lists a page of the teacher's bank questions matching
+// query, oldest first.
+func (s *QuestionBankService) SearchBankQuestions(ctx context.Context, teacherID domain.TeacherID, query BankQuery, page repository.PageRequest) (repository.Page[domain.BankQuestion], error) {
+	if err := s.ensureTeacherExists(ctx, teacherID); err != nil {
+		return repository.Page[domain.BankQuestion]{}, err
+	}
+	questions, err := repository.Collect(s.bankRepo.ListBankQuestions(ctx, teacherID, repository.All))
+	if err != nil {
+		return repository.Page[domain.BankQuestion]{}, err
+	}
+
+	return paginateBankQuestions(questions, query, page)
+}
+
+// paginateBankQuestions returns a page of the questions matching query,
+// oldest first.
+func paginateBankQuestions(questions []domain.BankQuestion, query BankQuery, page repository.PageRequest) (repository.Page[domain.BankQuestion], error) {
+	text := strings.ToLower(strings.TrimSpace(query.Text))
+	tags := normalizeTags(query.Tags)
+	matched := make([]domain.BankQuestion, 0, len(questions))
+	for _, q := range questions {
+		if text != "" && !strings.Contains(strings.ToLower(q.Prompt), text) {
+			continue
+		}
+		if query.Difficulty != "" && q.Difficulty != query.Difficulty {
+			continue
+		}
+		if query.Type != "" && q.Type != query.Type {
+			continue
+		}
+		if !hasTags(q.Tags, tags) {
+			continue
+		}
+		matched = append(matched, q)
+	}
+	return repository.Paginate(matched, page, false, func(q domain.BankQuestion) (time.Time, string) {
+		return q.CreatedAt, string(q.ID)
+	})
+}
+
+func (s *QuestionBankService) ensureTeacherExists(ctx context.Context, teacherID domain.TeacherID) error {
+	teacher, err := s.orgRepo.GetTeacher(ctx, teacherID)
+	if err != nil {
+		return err
+	}
+	if teacher == nil {
+		return errs.ErrTeacherNotFound
+	}
+	return nil
+}
+
+// ownedBankQuestion returns a bank question of the teacher; questions of
+// other teachers are not found.
+func ownedBankQuestion(ctx context.Context, bank repository.QuestionBankReader, teacherID domain.TeacherID, questionID domain.BankQuestionID) (*domain.BankQuestion, error) {
+	question, err := bank.GetBankQuestion(ctx, questionID)
+	if err != nil {
+		return nil, err
+	}
+	if question == nil || question.TeacherID != teacherID {
+		return nil, errs.ErrBankQuestionNotFound
+	}
+	return question, nil
+}
+
+// applyBankInput validates input and copies it onto question.
+func applyBankInput(question *domain.BankQuestion, input BankQuestionInput, now time.Time) error {
+	question.Prompt = strings.TrimSpace(input.Prompt)
+	question.Points = input.Points
+	question.Difficulty = input.Difficulty
+	question.Type = input.Type
+	if question.Type == "" {
+		question.Type = domain.QuestionFreeText
+	}
+	question.Choices = append([]domain.Choice(nil), input.Choices...)
+	question.ExpectedResponse = input.ExpectedResponse
+	question.Tags = normalizeTags(input.Tags)
+	question.Shared = input.Shared
+	question.UpdatedAt = now
+
+	q := question.Question()
+	return q.Validate()
+}
+
+// normalizeTags lower-cases and trims tags, dropping empty and repeated ones.
+func normalizeTags(tags []string) []string {
+	var out []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !slices.Contains(out, tag) {
+			out = append(out, tag)
+		}
+	}
+	return out
+}
+
+func hasTags(tags, required []string) bool {
+	for _, tag := range required {
+		if !slices.Contains(tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// bankDraft fills draft in from the bank question it references, which must
+// belong to the teacher or be pooled in the teacher's school.
+func (s *AssessmentService) bankDraft(ctx context.Context, teacherID domain.TeacherID, draft QuestionDraft) (QuestionDraft, error) {
+	if s.bankRepo == nil {
+		return draft, errs.ErrBankQuestionNotFound
+	}
+	question, err := usableBankQuestion(ctx, s.orgRepo, s.bankRepo, teacherID, draft.BankQuestionID)
+	if err != nil {
+		return draft, err
+	}
+	points := draft.Points
+	if points == 0 {
+		points = question.Points
+	}
+	return QuestionDraft{
+		Prompt:           question.Prompt,
+		Points:           points,
+		Section:          draft.Section,
+		Sequence:         draft.Sequence,
+		Difficulty:       question.Difficulty,
+		Type:             question.Type,
+		Choices:          append([]domain.Choice(nil), question.Choices...),
+		ExpectedResponse: question.ExpectedResponse,
+		BankQuestionID:   question.ID,
+	}, nil
+}
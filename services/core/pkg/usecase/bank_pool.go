@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// BankQuestionUsage tells how exposed a bank question is: the tests that
+// copied it, the students those tests were assigned to, and how the graded
+// answers to the copies went.
+type BankQuestionUsage struct {
+	Question domain.BankQuestion
+	Tests    int
+	Students int
+	Graded   int
+	// Facility is the mean share of the points scored by graded answers,
+	// from 0 for a question nobody got right to 1 for one everybody did.
+	// It is zero while nothing is graded.
+	Facility float64
+}
+
+// SearchQuestionPool lists a page of the shared questions of the teacher's
+// school matching query, oldest first. The teacher's own shared questions
+// are included.
+func (s *QuestionBankService) SearchQuestionPool(ctx context.Context, teacherID domain.TeacherID, query BankQuery, page repository.PageRequest) (repository.Page[domain.BankQuestion], error) {
+	colleagues, err := s.colleagues(ctx, teacherID)
+	if err != nil {
+		return repository.Page[domain.BankQuestion]{}, err
+	}
+	pool, err := s.pool(ctx, colleagues)
+	if err != nil {
+		return repository.Page[domain.BankQuestion]{}, err
+	}
+	return paginateBankQuestions(pool, query, page)
+}
+
+// QuestionUsage reports the usage of every question the teacher can copy,
+// their own bank and the school's pool, most exposed first. Usage counts the
+// tests of every teacher of the school.
+func (s *QuestionBankService) QuestionUsage(ctx context.Context, teacherID domain.TeacherID) ([]BankQuestionUsage, error) {
+	colleagues, err := s.colleagues(ctx, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := s.pool(ctx, colleagues)
+	if err != nil {
+		return nil, err
+	}
+	own, err := repository.Collect(s.bankRepo.ListBankQuestions(ctx, teacherID, repository.All))
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make(map[domain.BankQuestionID]*BankQuestionUsage)
+	var order []domain.BankQuestionID
+	for _, q := range append(own, pool...) {
+		if _, ok := usage[q.ID]; !ok {
+			usage[q.ID] = &BankQuestionUsage{Question: q}
+			order = append(order, q.ID)
+		}
+	}
+	if err := s.countUsage(ctx, colleagues, usage); err != nil {
+		return nil, err
+	}
+
+	report := make([]BankQuestionUsage, 0, len(order))
+	for _, id := range order {
+		report = append(report, *usage[id])
+	}
+	slices.SortStableFunc(report, func(a, b BankQuestionUsage) int {
+		if a.Students != b.Students {
+			return b.Students - a.Students
+		}
+		return b.Tests - a.Tests
+	})
+	return report, nil
+}
+
+// pool returns the questions the colleagues shared.
+func (s *QuestionBankService) pool(ctx context.Context, colleagues []domain.Teacher) ([]domain.BankQuestion, error) {
+	var pool []domain.BankQuestion
+	for _, colleague := range colleagues {
+		questions, err := repository.Collect(s.bankRepo.ListBankQuestions(ctx, colleague.ID, repository.All))
+		if err != nil {
+			return nil, err
+		}
+		for _, q := range questions {
+			if q.Shared {
+				pool = append(pool, q)
+			}
+		}
+	}
+	return pool, nil
+}
+
+// colleagues returns every teacher of the teacher's school, the teacher
+// included.
+func (s *QuestionBankService) colleagues(ctx context.Context, teacherID domain.TeacherID) ([]domain.Teacher, error) {
+	teacher, err := s.orgRepo.GetTeacher(ctx, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	if teacher == nil {
+		return nil, errs.ErrTeacherNotFound
+	}
+	return repository.Collect(s.orgRepo.ListTeachers(ctx, teacher.SchoolID, repository.All))
+}
+
+// countUsage fills usage in from the colleagues' tests that copied the
+// questions.
+func (s *QuestionBankService) countUsage(ctx context.Context, colleagues []domain.Teacher, usage map[domain.BankQuestionID]*BankQuestionUsage) error {
+	students := make(map[domain.BankQuestionID]map[domain.StudentID]struct{})
+	shares := make(map[domain.BankQuestionID]float64)
+	for _, colleague := range colleagues {
+		tests, err := repository.Collect(s.testRepo.ListTestsByTeacher(ctx, colleague.ID, repository.All))
+		if err != nil {
+			return err
+		}
+		for _, test := range tests {
+			questions, err := s.testRepo.ListQuestions(ctx, test.ID)
+			if err != nil {
+				return err
+			}
+			copies := make(map[domain.QuestionID]domain.Question)
+			for _, q := range questions {
+				if _, ok := usage[q.BankQuestionID]; ok {
+					copies[q.ID] = q
+				}
+			}
+			if len(copies) == 0 {
+				continue
+			}
+
+			counted := make(map[domain.BankQuestionID]bool)
+			for _, q := range copies {
+				if counted[q.BankQuestionID] {
+					continue
+				}
+				counted[q.BankQuestionID] = true
+				usage[q.BankQuestionID].Tests++
+				if students[q.BankQuestionID] == nil {
+					students[q.BankQuestionID] = make(map[domain.StudentID]struct{})
+				}
+				for _, sid := range test.AssignedTo {
+					students[q.BankQuestionID][sid] = struct{}{}
+				}
+			}
+
+			snapshot, err := s.resultRepo.SnapshotGrading(ctx, test.ID)
+			if err != nil {
+				return err
+			}
+			results := make(map[domain.AnswerID]domain.Result, len(snapshot.Results))
+			for _, res := range snapshot.Results {
+				results[res.AnswerID] = res
+			}
+			for _, ans := range snapshot.Answers {
+				q, ok := copies[ans.QuestionID]
+				res, graded := results[ans.ID]
+				if !ok || !graded || q.Points <= 0 {
+					continue
+				}
+				usage[q.BankQuestionID].Graded++
+				shares[q.BankQuestionID] += float64(res.Score) / float64(q.Points)
+			}
+		}
+	}
+	for id, u := range usage {
+		u.Students = len(students[id])
+		if u.Graded > 0 {
+			u.Facility = shares[id] / float64(u.Graded)
+		}
+	}
+	return nil
+}
+
+// usableBankQuestion returns a bank question the teacher may copy into a
+// test: one of their own, or one shared by a teacher of the same school.
+func usableBankQuestion(ctx context.Context, org repository.OrganizationReader, bank repository.QuestionBankReader, teacherID domain.TeacherID, questionID domain.BankQuestionID) (*domain.BankQuestion, error) {
+	question, err := bank.GetBankQuestion(ctx, questionID)
+	if err != nil {
+		return nil, err
+	}
+	if question == nil {
+		return nil, errs.ErrBankQuestionNotFound
+	}
+	if question.TeacherID == teacherID {
+		return question, nil
+	}
+	if !question.Shared {
+		return nil, errs.ErrBankQuestionNotFound
+	}
+	teacher, err := org.GetTeacher(ctx, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := org.GetTeacher(ctx, question.TeacherID)
+	if err != nil {
+		return nil, err
+	}
+	if teacher == nil || owner == nil || teacher.SchoolID != owner.SchoolID {
+		return nil, errs.ErrBankQuestionNotFound
+	}
+	return question, nil
+}
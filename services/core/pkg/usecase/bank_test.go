@@ -0,0 +1,178 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestQuestionBankService_SearchesTaggedQuestions(t *testing.T) {
+	fx := fixtures.NewSchool().WithTeachers(2).Build()
+	bank := usecase.NewQuestionBankService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	create := func(teacher domain.TeacherID, prompt string, tags ...string) *domain.BankQuestion {
+		t.Helper()
+		q, err := bank.CreateBankQuestion(ctx, usecase.BankQuestionInput{TeacherID: teacher, Prompt: prompt, Points: 2, Tags: tags})
+		if err != nil {
+			t.Fatalf("CreateBankQuestion failed: %v", err)
+		}
+		return q
+	}
+	photosynthesis := create(fx.Teacher(0), "Explain photosynthesis", " Biology ", "plants", "biology")
+	create(fx.Teacher(0), "Name the parts of a cell", "biology")
+	create(fx.Teacher(0), "Solve 2x = 4", "algebra")
+	create(fx.Teacher(1), "Explain osmosis", "biology")
+
+	if len(photosynthesis.Tags) != 2 || photosynthesis.Tags[0] != "biology" || photosynthesis.Type != domain.QuestionFreeText {
+		t.Fatalf("expected normalized tags and a free-text type, got %+v", photosynthesis)
+	}
+
+	found, err := bank.SearchBankQuestions(ctx, fx.Teacher(0), usecase.BankQuery{Tags: []string{"Biology"}}, repository.All)
+	if err != nil || len(found.Items) != 2 {
+		t.Fatalf("expected the teacher's two biology questions, got %+v (%v)", found.Items, err)
+	}
+	found, _ = bank.SearchBankQuestions(ctx, fx.Teacher(0), usecase.BankQuery{Text: "PHOTO", Tags: []string{"plants"}}, repository.All)
+	if len(found.Items) != 1 || found.Items[0].ID != photosynthesis.ID {
+		t.Fatalf("expected the photosynthesis question, got %+v", found.Items)
+	}
+
+	if _, err := bank.CreateBankQuestion(ctx, usecase.BankQuestionInput{TeacherID: fx.Teacher(0), Prompt: "Pick one", Points: 1, Type: domain.QuestionMultipleChoice}); !errors.Is(err, errs.ErrInvalidQuestion) {
+		t.Fatalf("expected ErrInvalidQuestion for a multiple-choice question without choices, got %v", err)
+	}
+	if _, err := bank.GetBankQuestion(ctx, fx.Teacher(1), photosynthesis.ID); err != errs.ErrBankQuestionNotFound {
+		t.Fatalf("expected another teacher's question not to be found, got %v", err)
+	}
+	if err := bank.DeleteBankQuestion(ctx, fx.Teacher(0), photosynthesis.ID); err != nil {
+		t.Fatalf("DeleteBankQuestion failed: %v", err)
+	}
+	if _, err := bank.GetBankQuestion(ctx, fx.Teacher(0), photosynthesis.ID); err != errs.ErrBankQuestionNotFound {
+		t.Fatalf("expected the deleted question not to be found, got %v", err)
+	}
+}
+
+func TestAssessmentService_CreatesTestsFromBankQuestions(t *testing.T) {
+	fx := fixtures.NewSchool().WithTeachers(2).Build()
+	bank := usecase.NewQuestionBankService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	service.SetQuestionBank(fx.Repo)
+	ctx := context.Background()
+
+	banked, err := bank.CreateBankQuestion(ctx, usecase.BankQuestionInput{
+		TeacherID:        fx.Teacher(0),
+		Prompt:           "The Earth orbits the Sun",
+		Points:           2,
+		Type:             domain.QuestionTrueFalse,
+		ExpectedResponse: "true",
+		Tags:             []string{"astronomy"},
+	})
+	if err != nil {
+		t.Fatalf("CreateBankQuestion failed: %v", err)
+	}
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:     "Space",
+		TeacherID: fx.Teacher(0),
+		Questions: []usecase.QuestionDraft{
+			{BankQuestionID: banked.ID, Points: 4},
+			{Prompt: "Describe a comet", Points: 3},
+			{BankQuestionID: banked.ID},
+		},
+		StudentIDs: []domain.StudentID{fx.Student(0)},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	first := questions[0]
+	if first.Prompt != banked.Prompt || first.Type != domain.QuestionTrueFalse || first.ExpectedResponse != "true" || first.Points != 4 || first.BankQuestionID != banked.ID {
+		t.Fatalf("expected a copy of the bank question worth 4 points, got %+v", first)
+	}
+	if questions[1].BankQuestionID != "" || questions[2].Points != 2 {
+		t.Fatalf("expected the bank points unless overridden, got %+v", questions)
+	}
+
+	if _, err := bank.UpdateBankQuestion(ctx, banked.ID, usecase.BankQuestionInput{TeacherID: fx.Teacher(0), Prompt: "Rewritten", Points: 1}); err != nil {
+		t.Fatalf("UpdateBankQuestion failed: %v", err)
+	}
+	stored, err := fx.Repo.ListQuestions(ctx, test.ID)
+	if err != nil || stored[0].Prompt != banked.Prompt || stored[0].Points != 4 {
+		t.Fatalf("expected the test to keep its copy, got %+v (%v)", stored, err)
+	}
+
+	if _, _, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:     "Borrowed",
+		TeacherID: fx.Teacher(1),
+		Questions: []usecase.QuestionDraft{{BankQuestionID: banked.ID}},
+	}); err != errs.ErrBankQuestionNotFound {
+		t.Fatalf("expected another teacher's bank question not to be found, got %v", err)
+	}
+}
+
+func TestQuestionBankService_PoolAndUsage(t *testing.T) {
+	fx := fixtures.NewSchool().WithTeachers(2).WithStudents(2).Build()
+	bank := usecase.NewQuestionBankService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	service.SetQuestionBank(fx.Repo)
+	ctx := context.Background()
+
+	shared, err := bank.CreateBankQuestion(ctx, usecase.BankQuestionInput{TeacherID: fx.Teacher(0), Prompt: "Name a noble gas", Points: 4, Tags: []string{"chemistry"}, Shared: true})
+	if err != nil {
+		t.Fatalf("CreateBankQuestion failed: %v", err)
+	}
+	private, err := bank.CreateBankQuestion(ctx, usecase.BankQuestionInput{TeacherID: fx.Teacher(0), Prompt: "Name an alkali metal", Points: 2})
+	if err != nil {
+		t.Fatalf("CreateBankQuestion failed: %v", err)
+	}
+
+	pool, err := bank.SearchQuestionPool(ctx, fx.Teacher(1), usecase.BankQuery{Tags: []string{"chemistry"}}, repository.All)
+	if err != nil || len(pool.Items) != 1 || pool.Items[0].ID != shared.ID {
+		t.Fatalf("expected the shared question in the pool, got %+v (%v)", pool.Items, err)
+	}
+
+	students := []domain.StudentID{fx.Student(0), fx.Student(1)}
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Borrowed",
+		TeacherID:  fx.Teacher(1),
+		Questions:  []usecase.QuestionDraft{{BankQuestionID: shared.ID}},
+		StudentIDs: students,
+	})
+	if err != nil {
+		t.Fatalf("expected a colleague to use a pooled question, got %v", err)
+	}
+	if _, _, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:     "Private",
+		TeacherID: fx.Teacher(1),
+		Questions: []usecase.QuestionDraft{{BankQuestionID: private.ID}},
+	}); err != errs.ErrBankQuestionNotFound {
+		t.Fatalf("expected an unshared question not to be found, got %v", err)
+	}
+
+	for i, sid := range students {
+		if _, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: sid, Response: "neon"}); err != nil {
+			t.Fatalf("SubmitAnswer failed: %v", err)
+		}
+		if _, err := service.GradeAnswer(ctx, usecase.GradeInput{TeacherID: fx.Teacher(1), TestID: test.ID, QuestionID: questions[0].ID, StudentID: sid, Score: domain.Score(4 - 2*i)}); err != nil {
+			t.Fatalf("GradeAnswer failed: %v", err)
+		}
+	}
+
+	report, err := bank.QuestionUsage(ctx, fx.Teacher(0))
+	if err != nil {
+		t.Fatalf("QuestionUsage failed: %v", err)
+	}
+	if len(report) != 2 || report[0].Question.ID != shared.ID || report[1].Question.ID != private.ID {
+		t.Fatalf("expected the used question first, got %+v", report)
+	}
+	if got := report[0]; got.Tests != 1 || got.Students != 2 || got.Graded != 2 || got.Facility != 0.75 {
+		t.Fatalf("unexpected usage %+v", got)
+	}
+	if got := report[1]; got.Tests != 0 || got.Students != 0 || got.Graded != 0 {
+		t.Fatalf("expected the unused question to have no usage, got %+v", got)
+	}
+}
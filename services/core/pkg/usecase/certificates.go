@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/certificate"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/id"
+)
+
+// SetCertificateSigner enables issuing completion certificates, signed with
+// signer.
+func (s *AssessmentService) SetCertificateSigner(signer *certificate.Signer) {
+	s.certificates = signer
+}
+
+// SetCertificates lets students who pass the test download a completion
+// certificate, or stops issuing them. Certificates need a passing score.
+func (s *AssessmentService) SetCertificates(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID, enabled bool) (*domain.Test, error) {
+	ctx, span := s.trace(ctx, "SetCertificates")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled && test.PassingScore == nil {
+		return nil, errs.ErrInvalidTest
+	}
+
+	test.Certificates = enabled
+	test.UpdatedAt = time.Now().UTC()
+	if err := s.testRepo.UpdateTest(ctx, test); err != nil {
+		return nil, err
+	}
+	return test, nil
+}
+
+// IssueCertificate certifies that the student passed the test and returns
+// the certificate with its signed code. The test must award certificates and
+// every answer of the student must be graded.
+func (s *AssessmentService) IssueCertificate(ctx context.Context, studentID domain.StudentID, testID domain.TestID) (*certificate.Certificate, string, error) {
+	ctx, span := s.trace(ctx, "IssueCertificate")
+	defer span.End()
+
+	if s.certificates == nil {
+		return nil, "", errs.ErrSigningUnavailable
+	}
+	test, err := s.GetTestForStudent(ctx, studentID, testID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !test.Certificates || test.PassingScore == nil {
+		return nil, "", errs.ErrNoCertificate
+	}
+	outcome, err := s.StudentTestOutcome(ctx, studentID, testID)
+	if err != nil {
+		return nil, "", err
+	}
+	if outcome.Passed == nil || !*outcome.Passed {
+		return nil, "", errs.ErrTestNotPassed
+	}
+
+	student, err := s.orgRepo.GetStudent(ctx, studentID)
+	if err != nil {
+		return nil, "", err
+	}
+	school, err := s.schoolOfStudent(ctx, studentID)
+	if err != nil {
+		return nil, "", err
+	}
+	cert := &certificate.Certificate{
+		ID:          id.New(),
+		StudentID:   string(studentID),
+		StudentName: student.Name,
+		TestID:      string(testID),
+		TestTitle:   test.Title,
+		Score:       int(outcome.Score),
+		MaxScore:    int(outcome.MaxScore),
+		IssuedAt:    time.Now().UTC().Truncate(time.Second),
+	}
+	if school != nil {
+		cert.SchoolName = school.Name
+	}
+	code, err := s.certificates.Sign(*cert)
+	if err != nil {
+		return nil, "", err
+	}
+	return cert, code, nil
+}
@@ -0,0 +1,70 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/certificate"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_IssueCertificate(t *testing.T) {
+	fx := fixtures.NewSchool().WithStudents(2).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Certified",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "Q1", Points: 10}},
+		StudentIDs: []domain.StudentID{fx.Student(0), fx.Student(1)},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	for i, score := range []domain.Score{8, 3} {
+		if _, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: fx.Student(i), Response: "x"}); err != nil {
+			t.Fatalf("SubmitAnswer failed: %v", err)
+		}
+		if _, err := service.GradeAnswer(ctx, usecase.GradeInput{TeacherID: fx.Teacher(0), TestID: test.ID, QuestionID: questions[0].ID, StudentID: fx.Student(i), Score: score, Completed: true}); err != nil {
+			t.Fatalf("GradeAnswer failed: %v", err)
+		}
+	}
+
+	if _, _, err := service.IssueCertificate(ctx, fx.Student(0), test.ID); err != errs.ErrSigningUnavailable {
+		t.Fatalf("expected ErrSigningUnavailable without a signer, got %v", err)
+	}
+	signer := certificate.NewSigner("secret")
+	service.SetCertificateSigner(signer)
+	if _, _, err := service.IssueCertificate(ctx, fx.Student(0), test.ID); err != errs.ErrNoCertificate {
+		t.Fatalf("expected ErrNoCertificate before the test awards certificates, got %v", err)
+	}
+	if _, err := service.SetCertificates(ctx, fx.Teacher(0), test.ID, true); err != errs.ErrInvalidTest {
+		t.Fatalf("expected certificates to need a passing score, got %v", err)
+	}
+	passing := domain.Score(6)
+	if _, err := service.SetPassingScore(ctx, fx.Teacher(0), test.ID, &passing); err != nil {
+		t.Fatalf("SetPassingScore failed: %v", err)
+	}
+	if updated, err := service.SetCertificates(ctx, fx.Teacher(0), test.ID, true); err != nil || !updated.Certificates {
+		t.Fatalf("SetCertificates failed: %+v, %v", updated, err)
+	}
+
+	if _, _, err := service.IssueCertificate(ctx, fx.Student(1), test.ID); err != errs.ErrTestNotPassed {
+		t.Fatalf("expected ErrTestNotPassed for a failing student, got %v", err)
+	}
+	cert, code, err := service.IssueCertificate(ctx, fx.Student(0), test.ID)
+	if err != nil {
+		t.Fatalf("IssueCertificate failed: %v", err)
+	}
+	if cert.Score != 8 || cert.MaxScore != 10 || cert.TestTitle != "Certified" || cert.StudentName == "" {
+		t.Fatalf("unexpected certificate: %+v", cert)
+	}
+	verified, err := signer.Verify(code)
+	if err != nil || verified.ID != cert.ID || verified.StudentID != string(fx.Student(0)) {
+		t.Fatalf("expected the code to verify as the certificate, got %+v, %v", verified, err)
+	}
+}
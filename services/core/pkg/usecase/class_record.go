@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// ClassRecord is the work a class did on a teacher's published tests, the
+// raw material of class analytics. Tests only carry the answers of the
+// class's students and their results.
+type ClassRecord struct {
+	Class    domain.Class
+	Students []domain.Student
+	Tests    []ClassTestRecord
+	ReadAt   time.Time
+}
+
+// ClassTestRecord is one test of a ClassRecord. Assigned lists the class's
+// students the test is assigned to.
+type ClassTestRecord struct {
+	Test      domain.Test
+	Questions []domain.Question
+	Assigned  []domain.StudentID
+	Answers   []domain.Answer
+	Results   []domain.Result
+}
+
+// ClassRecord returns the work of a class of the teacher's school on the
+// teacher's published tests, oldest test first. Classes of other schools
+// are reported as not found.
+func (s *AssessmentService) ClassRecord(ctx context.Context, teacherID domain.TeacherID, classID domain.ClassID) (*ClassRecord, error) {
+	ctx, span := s.trace(ctx, "ClassRecord")
+	defer span.End()
+
+	teacher, err := s.orgRepo.GetTeacher(ctx, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	if teacher == nil {
+		return nil, errs.ErrTeacherNotFound
+	}
+	class, err := s.orgRepo.GetClass(ctx, classID)
+	if err != nil {
+		return nil, err
+	}
+	if class == nil {
+		return nil, errs.ErrClassNotFound
+	}
+	grade, err := s.orgRepo.GetGrade(ctx, class.GradeID)
+	if err != nil {
+		return nil, err
+	}
+	if grade == nil || grade.SchoolID != teacher.SchoolID {
+		return nil, errs.ErrClassNotFound
+	}
+
+	students, err := s.orgRepo.ListStudents(ctx, classID, repository.All)
+	if err != nil {
+		return nil, err
+	}
+	record := &ClassRecord{Class: *class, Students: students.Items, ReadAt: time.Now().UTC()}
+	inClass := make(map[domain.StudentID]bool, len(students.Items))
+	for _, st := range students.Items {
+		inClass[st.ID] = true
+	}
+
+	tests, err := repository.Collect(s.testRepo.ListTestsByTeacher(ctx, teacherID, repository.All))
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(tests, func(i, j int) bool {
+		return tests[i].CreatedAt.Before(tests[j].CreatedAt)
+	})
+	for _, test := range tests {
+		if !test.Published {
+			continue
+		}
+		var assigned []domain.StudentID
+		for _, sid := range test.AssignedTo {
+			if inClass[sid] {
+				assigned = append(assigned, sid)
+			}
+		}
+		if len(assigned) == 0 {
+			continue
+		}
+
+		questions, err := s.testRepo.ListQuestions(ctx, test.ID)
+		if err != nil {
+			return nil, err
+		}
+		snapshot, err := s.resultRepo.SnapshotGrading(ctx, test.ID)
+		if err != nil {
+			return nil, err
+		}
+		entry := ClassTestRecord{Test: test, Questions: questions, Assigned: assigned}
+		answered := make(map[domain.AnswerID]bool)
+		for _, ans := range snapshot.Answers {
+			if inClass[ans.StudentID] {
+				entry.Answers = append(entry.Answers, ans)
+				answered[ans.ID] = true
+			}
+		}
+		for _, res := range snapshot.Results {
+			if answered[res.AnswerID] {
+				entry.Results = append(entry.Results, res)
+			}
+		}
+		record.Tests = append(record.Tests, entry)
+	}
+	return record, nil
+}
@@ -0,0 +1,263 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+)
+
+const (
+	// histogramBands splits percentage scores into bands of ten points; a
+	// full score falls into the last band.
+	histogramBands = 10
+	// significanceLevel is the p-value below which a difference is hinted
+	// as significant.
+	significanceLevel = 0.05
+)
+
+// CompareInput selects the scores to compare. Tests lists the test and any
+// equivalent tests, such as the same test given in other terms; each of them
+// is a group of its own, split further by class when ByClass is set.
+type CompareInput struct {
+	TeacherID domain.TeacherID
+	Tests     []domain.TestID
+	ByClass   bool
+}
+
+// ScoreComparison holds the score distribution of each group and how every
+// group differs from the first.
+type ScoreComparison struct {
+	Groups []ComparisonGroup
+	Hints  []SignificanceHint
+}
+
+// ComparisonGroup is the distribution of the percentage scores of students
+// whose answers to a test are all graded. ClassID is empty unless the
+// comparison is by class.
+type ComparisonGroup struct {
+	TestID    domain.TestID
+	ClassID   domain.ClassID
+	Students  int
+	Mean      float64
+	StdDev    float64
+	Histogram []int
+
+	scores []float64
+}
+
+// SignificanceHint compares a group with the baseline, the first group, using
+// Welch's t-test. It is a hint for curriculum evaluation rather than a
+// verdict: small groups rarely differ significantly. Groups with fewer than
+// two students get no hint.
+type SignificanceHint struct {
+	Group       int
+	Difference  float64
+	EffectSize  float64
+	PValue      float64
+	Significant bool
+}
+
+// CompareScores compares the score distributions of tests, or of the classes
+// sitting them, ensuring the teacher may access every test.
+func (s *AssessmentService) CompareScores(ctx context.Context, input CompareInput) (*ScoreComparison, error) {
+	ctx, span := s.trace(ctx, "CompareScores")
+	defer span.End()
+
+	if len(input.Tests) == 0 {
+		return nil, errs.ErrInvalidTest
+	}
+	seen := make(map[domain.TestID]bool, len(input.Tests))
+	var groups []ComparisonGroup
+	for _, testID := range input.Tests {
+		if seen[testID] {
+			continue
+		}
+		seen[testID] = true
+		if err := s.ensureTeacherOwnsTest(ctx, input.TeacherID, testID); err != nil {
+			return nil, err
+		}
+		test, err := s.testRepo.GetTest(ctx, testID)
+		if err != nil {
+			return nil, err
+		}
+		testGroups, err := s.comparisonGroups(ctx, test, input.ByClass)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, testGroups...)
+	}
+
+	comparison := &ScoreComparison{Groups: groups}
+	for i := 1; i < len(groups); i++ {
+		if hint, ok := compareGroups(groups[0], groups[i]); ok {
+			hint.Group = i
+			comparison.Hints = append(comparison.Hints, hint)
+		}
+	}
+	return comparison, nil
+}
+
+// comparisonGroups collects the decided scores of a test, split by class when
+// byClass is set.
+func (s *AssessmentService) comparisonGroups(ctx context.Context, test *domain.Test, byClass bool) ([]ComparisonGroup, error) {
+	outcomes, err := s.studentOutcomes(ctx, test)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[domain.ClassID]*ComparisonGroup)
+	var keys []domain.ClassID
+	for _, o := range outcomes {
+		if o.Answered == 0 || o.Graded < o.Answered {
+			continue
+		}
+		var classID domain.ClassID
+		if byClass {
+			student, err := s.orgRepo.GetStudent(ctx, o.StudentID)
+			if err != nil {
+				return nil, err
+			}
+			if student == nil {
+				continue
+			}
+			classID = student.ClassID
+		}
+		group, ok := byKey[classID]
+		if !ok {
+			group = &ComparisonGroup{TestID: test.ID, ClassID: classID}
+			byKey[classID] = group
+			keys = append(keys, classID)
+		}
+		group.scores = append(group.scores, o.Percent())
+	}
+	if !byClass && len(keys) == 0 {
+		keys = append(keys, "")
+		byKey[""] = &ComparisonGroup{TestID: test.ID}
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	groups := make([]ComparisonGroup, len(keys))
+	for i, key := range keys {
+		group := byKey[key]
+		group.describe()
+		groups[i] = *group
+	}
+	return groups, nil
+}
+
+// describe fills in the summary of the group's scores.
+func (g *ComparisonGroup) describe() {
+	g.Students = len(g.scores)
+	g.Histogram = make([]int, histogramBands)
+	for _, score := range g.scores {
+		band := int(score / (100 / histogramBands))
+		g.Histogram[max(0, min(band, histogramBands-1))]++
+	}
+	g.Mean, g.StdDev = meanAndStdDev(g.scores)
+}
+
+func compareGroups(baseline, group ComparisonGroup) (SignificanceHint, bool) {
+	n1, n2 := float64(len(baseline.scores)), float64(len(group.scores))
+	if n1 < 2 || n2 < 2 {
+		return SignificanceHint{}, false
+	}
+	hint := SignificanceHint{Difference: group.Mean - baseline.Mean}
+
+	v1, v2 := baseline.StdDev*baseline.StdDev, group.StdDev*group.StdDev
+	if pooled := math.Sqrt(((n1-1)*v1 + (n2-1)*v2) / (n1 + n2 - 2)); pooled > 0 {
+		hint.EffectSize = hint.Difference / pooled
+	}
+
+	se1, se2 := v1/n1, v2/n2
+	switch {
+	case se1+se2 > 0:
+		t := hint.Difference / math.Sqrt(se1+se2)
+		df := (se1 + se2) * (se1 + se2) / (se1*se1/(n1-1) + se2*se2/(n2-1))
+		hint.PValue = studentTwoSidedP(t, df)
+	case hint.Difference == 0:
+		hint.PValue = 1
+	}
+	hint.Significant = hint.PValue < significanceLevel
+	return hint, true
+}
+
+// meanAndStdDev returns the mean and sample standard deviation of values.
+func meanAndStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	if len(values) < 2 {
+		return mean, 0
+	}
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(squares / float64(len(values)-1))
+}
+
+// studentTwoSidedP returns the probability of a t statistic at least as
+// extreme as t under Student's t distribution with df degrees of freedom.
+func studentTwoSidedP(t, df float64) float64 {
+	return regularizedBeta(df/2, 0.5, df/(df+t*t))
+}
+
+// regularizedBeta evaluates the regularized incomplete beta function
+// I_x(a, b) by its continued fraction.
+func regularizedBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	lab, _ := math.Lgamma(a + b)
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+	if x < (a+1)/(a+b+2) {
+		return front * betaFraction(a, b, x) / a
+	}
+	return 1 - front*betaFraction(b, a, 1-x)/b
+}
+
+// betaFraction evaluates the continued fraction of the incomplete beta
+// function with Lentz's method.
+func betaFraction(a, b, x float64) float64 {
+	const (
+		epsilon    = 1e-12
+		tiny       = 1e-300
+		iterations = 300
+	)
+	clamp := func(v float64) float64 {
+		if math.Abs(v) < tiny {
+			return tiny
+		}
+		return v
+	}
+	c, d := 1.0, 1/clamp(1-(a+b)*x/(a+1))
+	h := d
+	for m := 1.0; m <= iterations; m++ {
+		even := m * (b - m) * x / ((a + 2*m - 1) * (a + 2*m))
+		d = 1 / clamp(1+even*d)
+		c = clamp(1 + even/c)
+		h *= d * c
+
+		odd := -(a + m) * (a + b + m) * x / ((a + 2*m) * (a + 2*m + 1))
+		d = 1 / clamp(1+odd*d)
+		c = clamp(1 + odd/c)
+		step := d * c
+		h *= step
+		if math.Abs(step-1) < epsilon {
+			break
+		}
+	}
+	return h
+}
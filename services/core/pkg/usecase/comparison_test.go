@@ -0,0 +1,72 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_CompareScores(t *testing.T) {
+	fx := fixtures.NewSchool().WithClasses(2).WithStudents(3).WithTeachers(2).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+	strong, weak := fx.StudentsOf(fx.Classes[0].ID), fx.StudentsOf(fx.Classes[1].ID)
+	students := append(append([]domain.StudentID{}, strong...), weak...)
+
+	sit := func(title string, scores []domain.Score) domain.TestID {
+		test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+			Title:      title,
+			TeacherID:  fx.Teacher(0),
+			Questions:  []usecase.QuestionDraft{{Prompt: "Q1", Points: 10}},
+			StudentIDs: students,
+		})
+		if err != nil {
+			t.Fatalf("CreateTest failed: %v", err)
+		}
+		for i, sid := range students {
+			if _, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: sid, Response: "x"}); err != nil {
+				t.Fatalf("SubmitAnswer failed: %v", err)
+			}
+			input := usecase.GradeInput{TeacherID: fx.Teacher(0), TestID: test.ID, QuestionID: questions[0].ID, StudentID: sid, Score: scores[i], Completed: true}
+			if _, err := service.GradeAnswer(ctx, input); err != nil {
+				t.Fatalf("GradeAnswer failed: %v", err)
+			}
+		}
+		return test.ID
+	}
+	spring := sit("Spring", []domain.Score{7, 10, 10, 1, 2, 1})
+	autumn := sit("Autumn", []domain.Score{7, 10, 10, 1, 2, 1})
+
+	byClass, err := service.CompareScores(ctx, usecase.CompareInput{TeacherID: fx.Teacher(0), Tests: []domain.TestID{spring}, ByClass: true})
+	if err != nil {
+		t.Fatalf("CompareScores failed: %v", err)
+	}
+	if len(byClass.Groups) != 2 || byClass.Groups[0].ClassID != fx.Classes[0].ID || byClass.Groups[0].Students != 3 {
+		t.Fatalf("expected one group per class, got %+v", byClass.Groups)
+	}
+	if h := byClass.Groups[0].Histogram; h[9] != 2 || h[7] != 1 {
+		t.Fatalf("expected the strong class in the top bands, got %v", h)
+	}
+	if len(byClass.Hints) != 1 || !byClass.Hints[0].Significant || byClass.Hints[0].Difference >= 0 || byClass.Hints[0].EffectSize >= 0 {
+		t.Fatalf("expected the weak class to differ significantly, got %+v", byClass.Hints)
+	}
+
+	terms, err := service.CompareScores(ctx, usecase.CompareInput{TeacherID: fx.Teacher(0), Tests: []domain.TestID{spring, autumn}})
+	if err != nil {
+		t.Fatalf("CompareScores failed: %v", err)
+	}
+	if len(terms.Groups) != 2 || terms.Groups[1].TestID != autumn || terms.Groups[1].Students != 6 {
+		t.Fatalf("expected one group per test, got %+v", terms.Groups)
+	}
+	if len(terms.Hints) != 1 || terms.Hints[0].Significant || terms.Hints[0].PValue != 1 {
+		t.Fatalf("expected identical terms not to differ, got %+v", terms.Hints)
+	}
+
+	if _, err := service.CompareScores(ctx, usecase.CompareInput{TeacherID: fx.Teacher(1), Tests: []domain.TestID{spring}}); err != errs.ErrForbiddenTeacher {
+		t.Fatalf("expected another teacher to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// difficulties lists the difficulty levels in the order composed tests
+// present them.
+var difficulties = []domain.Difficulty{domain.DifficultyEasy, domain.DifficultyMedium, domain.DifficultyHard}
+
+// ComposeInput asks for a new test assembled from the teacher's existing
+// questions. Distribution gives the percentage of TotalPoints each difficulty
+// should carry; percentages must add up to 100.
+type ComposeInput struct {
+	TeacherID    domain.TeacherID
+	Title        string
+	TotalPoints  domain.Points
+	Distribution map[domain.Difficulty]int
+	StudentIDs   []domain.StudentID
+	Draft        bool
+}
+
+// SetQuestionDifficulty tags a question with a difficulty, or clears the tag
+// with an empty difficulty.
+func (s *AssessmentService) SetQuestionDifficulty(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID, questionID domain.QuestionID, difficulty domain.Difficulty) (*domain.Question, error) {
+	ctx, span := s.trace(ctx, "SetQuestionDifficulty")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+
+	questions, err := s.testRepo.ListQuestions(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	for _, q := range questions {
+		if q.ID != questionID {
+			continue
+		}
+		q.Difficulty = difficulty
+		if err := q.Validate(); err != nil {
+			return nil, err
+		}
+		if err := s.testRepo.UpdateQuestion(ctx, &q); err != nil {
+			return nil, err
+		}
+		return &q, nil
+	}
+	return nil, errs.ErrQuestionNotFound
+}
+
+// ComposeTest creates a test from questions the teacher wrote for earlier
+// tests, picking per difficulty a set of questions whose points add up exactly
+// to that difficulty's share of the total. Questions repeated across tests are
+// considered once, and earlier questions are preferred, so the same input
+// composes the same test.
+func (s *AssessmentService) ComposeTest(ctx context.Context, input ComposeInput) (*domain.Test, []domain.Question, error) {
+	ctx, span := s.trace(ctx, "ComposeTest")
+	defer span.End()
+
+	targets, err := compositionTargets(input.TotalPoints, input.Distribution)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.ensureTeacherExists(ctx, input.TeacherID); err != nil {
+		return nil, nil, err
+	}
+
+	pool, err := s.questionPool(ctx, input.TeacherID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var drafts []QuestionDraft
+	for _, d := range difficulties {
+		picked, ok := pickPoints(pool[d], targets[d])
+		if !ok {
+			return nil, nil, errs.ErrNotEnoughQuestions
+		}
+		for _, q := range picked {
+			drafts = append(drafts, QuestionDraft{Prompt: q.Prompt, Points: q.Points, Difficulty: q.Difficulty, Type: q.Type, Choices: q.Choices, ExpectedResponse: q.ExpectedResponse, Translations: q.Translations})
+		}
+	}
+
+	return s.CreateTest(ctx, CreateTestInput{
+		Title:      input.Title,
+		TeacherID:  input.TeacherID,
+		Questions:  drafts,
+		StudentIDs: input.StudentIDs,
+		Draft:      input.Draft,
+	})
+}
+
+// questionPool groups the teacher's tagged questions by difficulty, oldest
+// first and without duplicates.
+func (s *AssessmentService) questionPool(ctx context.Context, teacherID domain.TeacherID) (map[domain.Difficulty][]domain.Question, error) {
+	tests, err := repository.Collect(s.testRepo.ListTestsByTeacher(ctx, teacherID, repository.All))
+	if err != nil {
+		return nil, err
+	}
+
+	var all []domain.Question
+	for _, test := range tests {
+		questions, err := s.testRepo.ListQuestions(ctx, test.ID)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, questions...)
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].CreatedAt.Before(all[j].CreatedAt)
+	})
+
+	type key struct {
+		prompt     string
+		points     domain.Points
+		difficulty domain.Difficulty
+	}
+	seen := make(map[key]struct{})
+	pool := make(map[domain.Difficulty][]domain.Question)
+	for _, q := range all {
+		if q.Difficulty == "" || !q.Points.Valid() {
+			continue
+		}
+		k := key{q.Prompt, q.Points, q.Difficulty}
+		if _, dup := seen[k]; dup {
+			continue
+		}
+		seen[k] = struct{}{}
+		pool[q.Difficulty] = append(pool[q.Difficulty], q)
+	}
+	return pool, nil
+}
+
+// compositionTargets splits total into points per difficulty, handing the
+// points lost to rounding to the largest remainders.
+func compositionTargets(total domain.Points, distribution map[domain.Difficulty]int) (map[domain.Difficulty]domain.Points, error) {
+	if total <= 0 || len(distribution) == 0 {
+		return nil, errs.ErrInvalidComposition
+	}
+	sum := 0
+	for d, pct := range distribution {
+		if d == "" || !d.Valid() || pct < 0 {
+			return nil, errs.ErrInvalidComposition
+		}
+		sum += pct
+	}
+	if sum != 100 {
+		return nil, errs.ErrInvalidComposition
+	}
+
+	targets := make(map[domain.Difficulty]domain.Points, len(difficulties))
+	remainders := make([]domain.Difficulty, 0, len(difficulties))
+	var assigned domain.Points
+	for _, d := range difficulties {
+		targets[d] = total * domain.Points(distribution[d]) / 100
+		assigned += targets[d]
+		remainders = append(remainders, d)
+	}
+	sort.SliceStable(remainders, func(i, j int) bool {
+		return int(total)*distribution[remainders[i]]%100 > int(total)*distribution[remainders[j]]%100
+	})
+	for i := 0; assigned < total; i++ {
+		targets[remainders[i]]++
+		assigned++
+	}
+	return targets, nil
+}
+
+// pickPoints finds questions whose points add up to exactly target, preferring
+// questions earlier in the list.
+func pickPoints(questions []domain.Question, target domain.Points) ([]domain.Question, bool) {
+	if target == 0 {
+		return nil, true
+	}
+
+	// from[s] is the question completing sum s; it is set once, by the
+	// earliest question that can reach s.
+	from := make([]int, target+1)
+	for i := range from {
+		from[i] = -1
+	}
+	reached := make([]bool, target+1)
+	reached[0] = true
+	for i, q := range questions {
+		for sum := target; sum >= q.Points; sum-- {
+			if reached[sum-q.Points] && !reached[sum] {
+				reached[sum] = true
+				from[sum] = i
+			}
+		}
+	}
+	if !reached[target] {
+		return nil, false
+	}
+
+	var picked []int
+	for sum := target; sum > 0; sum -= questions[from[sum]].Points {
+		picked = append(picked, from[sum])
+	}
+	sort.Ints(picked)
+
+	out := make([]domain.Question, len(picked))
+	for i, idx := range picked {
+		out[i] = questions[idx]
+	}
+	return out, true
+}
@@ -0,0 +1,81 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_ComposeTest(t *testing.T) {
+	fx := fixtures.NewSchool().Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:     "Bank",
+		TeacherID: fx.Teacher(0),
+		Questions: []usecase.QuestionDraft{
+			{Prompt: "e1", Points: 2, Difficulty: domain.DifficultyEasy},
+			{Prompt: "e2", Points: 3, Difficulty: domain.DifficultyEasy},
+			{Prompt: "m1", Points: 5},
+			{Prompt: "m2", Points: 4, Difficulty: domain.DifficultyMedium},
+			{Prompt: "h1", Points: 1, Difficulty: domain.DifficultyHard},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	if _, err := service.SetQuestionDifficulty(ctx, fx.Teacher(0), test.ID, questions[2].ID, domain.DifficultyMedium); err != nil {
+		t.Fatalf("SetQuestionDifficulty failed: %v", err)
+	}
+	if _, err := service.SetQuestionDifficulty(ctx, fx.Teacher(0), test.ID, questions[2].ID, "impossible"); !errors.Is(err, errs.ErrInvalidQuestion) {
+		t.Fatalf("expected ErrInvalidQuestion, got %v", err)
+	}
+
+	// 10 points: 5 easy, 4 medium, 1 hard after handing out the remainder.
+	_, composed, err := service.ComposeTest(ctx, usecase.ComposeInput{
+		TeacherID:   fx.Teacher(0),
+		Title:       "Composed",
+		TotalPoints: 10,
+		Distribution: map[domain.Difficulty]int{
+			domain.DifficultyEasy:   45,
+			domain.DifficultyMedium: 45,
+			domain.DifficultyHard:   10,
+		},
+		StudentIDs: []domain.StudentID{fx.Student(0)},
+	})
+	if err != nil {
+		t.Fatalf("ComposeTest failed: %v", err)
+	}
+	var prompts []string
+	var total domain.Points
+	for _, q := range composed {
+		prompts = append(prompts, q.Prompt)
+		total += q.Points
+	}
+	if total != 10 || len(prompts) != 4 || prompts[0] != "e1" || prompts[1] != "e2" || prompts[2] != "m2" || prompts[3] != "h1" {
+		t.Fatalf("unexpected composition %v totalling %d", prompts, total)
+	}
+
+	if _, _, err := service.ComposeTest(ctx, usecase.ComposeInput{
+		TeacherID:    fx.Teacher(0),
+		Title:        "Too hard",
+		TotalPoints:  10,
+		Distribution: map[domain.Difficulty]int{domain.DifficultyHard: 100},
+	}); err != errs.ErrNotEnoughQuestions {
+		t.Fatalf("expected ErrNotEnoughQuestions, got %v", err)
+	}
+	if _, _, err := service.ComposeTest(ctx, usecase.ComposeInput{
+		TeacherID:    fx.Teacher(0),
+		Title:        "Bad",
+		TotalPoints:  10,
+		Distribution: map[domain.Difficulty]int{domain.DifficultyEasy: 60},
+	}); err != errs.ErrInvalidComposition {
+		t.Fatalf("expected ErrInvalidComposition, got %v", err)
+	}
+}
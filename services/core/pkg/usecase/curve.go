@@ -0,0 +1,260 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/audit"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+)
+
+// CurveInput describes a curve to apply to a test.
+type CurveInput struct {
+	TeacherID domain.TeacherID
+	TestID    domain.TestID
+	Kind      domain.CurveKind
+	Points    domain.Score
+	Mapping   map[domain.Score]domain.Score
+}
+
+// ApplyCurve adjusts every result of a test in one batch and records the
+// curve on the test. Scores are always derived from the graded (raw) scores,
+// so applying a new curve replaces the previous one instead of stacking.
+func (s *AssessmentService) ApplyCurve(ctx context.Context, input CurveInput) (*domain.Test, error) {
+	ctx, span := s.trace(ctx, "ApplyCurve")
+	defer span.End()
+
+	if err := validCurve(input); err != nil {
+		return nil, err
+	}
+	if err := s.ensureTeacherOwnsTest(ctx, input.TeacherID, input.TestID); err != nil {
+		return nil, err
+	}
+
+	s.curveMu.Lock()
+	defer s.curveMu.Unlock()
+
+	test, results, points, err := s.curveTargets(ctx, input.TestID)
+	if err != nil {
+		return nil, err
+	}
+
+	top := make(map[domain.QuestionID]domain.Score)
+	for _, cr := range results {
+		if raw := cr.raw(); raw > top[cr.questionID] {
+			top[cr.questionID] = raw
+		}
+	}
+
+	now := time.Now().UTC()
+	curve := &domain.Curve{
+		Kind:      input.Kind,
+		Points:    input.Points,
+		Mapping:   input.Mapping,
+		AppliedBy: input.TeacherID,
+		AppliedAt: now,
+	}
+	curved := make([]domain.Result, len(results))
+	for i, cr := range results {
+		raw := cr.raw()
+		res := cr.result
+		res.RawScore = &raw
+		res.Score = curvedScore(curve, raw, top[cr.questionID], points[cr.questionID])
+		res.UpdatedAt = now
+		curved[i] = res
+	}
+
+	previous := test.Curve
+	test.Curve = curve
+	test.UpdatedAt = now
+	if err := s.saveCurve(ctx, test, results, curved, previous); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, audit.CurveApplied(input.TeacherID, *test))
+	return test, nil
+}
+
+// RevertCurve restores the graded scores of a test and removes its curve.
+func (s *AssessmentService) RevertCurve(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID) (*domain.Test, error) {
+	ctx, span := s.trace(ctx, "RevertCurve")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+
+	s.curveMu.Lock()
+	defer s.curveMu.Unlock()
+
+	test, results, _, err := s.curveTargets(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	if test.Curve == nil {
+		return nil, errs.ErrNoCurve
+	}
+
+	now := time.Now().UTC()
+	restored := make([]domain.Result, 0, len(results))
+	for _, cr := range results {
+		if cr.result.RawScore == nil {
+			continue
+		}
+		res := cr.result
+		res.Score = *res.RawScore
+		res.RawScore = nil
+		res.UpdatedAt = now
+		restored = append(restored, res)
+	}
+
+	previous := test.Curve
+	test.Curve = nil
+	test.UpdatedAt = now
+	if err := s.saveCurve(ctx, test, results, restored, previous); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, audit.CurveReverted(teacherID, *test))
+	return test, nil
+}
+
+// regradeScore returns the score to store for raw, graded anew on an
+// answer of a curved test. Scaling to the top takes the regrade into
+// account; the other results keep their scores until the curve is applied
+// again.
+func (s *AssessmentService) regradeScore(ctx context.Context, test *domain.Test, answer *domain.Answer, raw domain.Score) (domain.Score, error) {
+	question, err := s.findQuestion(ctx, test.ID, answer.QuestionID)
+	if err != nil {
+		return 0, err
+	}
+	top := raw
+	if test.Curve.Kind == domain.CurveScaleToTop {
+		_, results, _, err := s.curveTargets(ctx, test.ID)
+		if err != nil {
+			return 0, err
+		}
+		for _, cr := range results {
+			if cr.questionID == answer.QuestionID && cr.result.AnswerID != answer.ID && cr.raw() > top {
+				top = cr.raw()
+			}
+		}
+	}
+	return curvedScore(test.Curve, raw, top, question.Points), nil
+}
+
+// curvedScore adjusts raw by the curve. top is the highest raw score of the
+// question and full its points.
+func curvedScore(curve *domain.Curve, raw, top domain.Score, full domain.Points) domain.Score {
+	score := raw
+	switch curve.Kind {
+	case domain.CurveAdd:
+		score = raw + curve.Points
+	case domain.CurveScaleToTop:
+		score = raw.Rescale(top, full)
+	case domain.CurveMapping:
+		if mapped, ok := curve.Mapping[raw]; ok {
+			score = mapped
+		}
+	}
+	return score.Clamp(full)
+}
+
+type curveResult struct {
+	result     domain.Result
+	questionID domain.QuestionID
+}
+
+// raw is the score as graded, before any curve.
+func (c curveResult) raw() domain.Score {
+	if c.result.RawScore != nil {
+		return *c.result.RawScore
+	}
+	return c.result.Score
+}
+
+func (s *AssessmentService) curveTargets(ctx context.Context, testID domain.TestID) (*domain.Test, []curveResult, map[domain.QuestionID]domain.Points, error) {
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	questions, err := s.testRepo.ListQuestions(ctx, testID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	snapshot, err := s.resultRepo.SnapshotGrading(ctx, testID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	answers, results := snapshot.Answers, snapshot.Results
+
+	points := make(map[domain.QuestionID]domain.Points, len(questions))
+	for _, q := range questions {
+		points[q.ID] = q.Points
+	}
+	questionOf := make(map[domain.AnswerID]domain.QuestionID, len(answers))
+	for _, ans := range answers {
+		questionOf[ans.ID] = ans.QuestionID
+	}
+
+	targets := make([]curveResult, 0, len(results))
+	for _, res := range results {
+		targets = append(targets, curveResult{result: res, questionID: questionOf[res.AnswerID]})
+	}
+	return test, targets, points, nil
+}
+
+// saveCurve writes the adjusted results and the test together. If the test
+// cannot be saved the original results are written back, so a failed curve
+// leaves no partially adjusted scores behind; should that fail too, both
+// errors are returned. Cached statistics are dropped either way, as the
+// stored results may have changed.
+func (s *AssessmentService) saveCurve(ctx context.Context, test *domain.Test, original []curveResult, adjusted []domain.Result, previous *domain.Curve) error {
+	if err := s.resultRepo.SaveResults(ctx, adjusted); err != nil {
+		return err
+	}
+	err := s.testRepo.UpdateTest(ctx, test)
+	if err != nil {
+		// The rollback is saved over the adjusted results, so it takes
+		// their versions.
+		saved := make(map[domain.ResultID]int, len(adjusted))
+		for _, res := range adjusted {
+			saved[res.ID] = res.Version
+		}
+		rollback := make([]domain.Result, 0, len(adjusted))
+		for _, cr := range original {
+			if version, ok := saved[cr.result.ID]; ok {
+				res := cr.result
+				res.Version = version
+				rollback = append(rollback, res)
+			}
+		}
+		err = errors.Join(err, s.resultRepo.SaveResults(ctx, rollback))
+		test.Curve = previous
+	}
+	s.stats.entries.Invalidate(test.ID)
+	s.record(domain.TestChanged{TestID: test.ID})
+	return err
+}
+
+func validCurve(input CurveInput) error {
+	switch input.Kind {
+	case domain.CurveAdd:
+		if input.Points == 0 {
+			return errs.ErrInvalidCurve
+		}
+	case domain.CurveScaleToTop:
+	case domain.CurveMapping:
+		if len(input.Mapping) == 0 {
+			return errs.ErrInvalidCurve
+		}
+		for from, to := range input.Mapping {
+			if from < 0 || to < 0 {
+				return errs.ErrInvalidCurve
+			}
+		}
+	default:
+		return errs.ErrInvalidCurve
+	}
+	return nil
+}
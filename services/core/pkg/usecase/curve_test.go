@@ -0,0 +1,234 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_Curve(t *testing.T) {
+	fx := fixtures.NewSchool().WithStudents(3).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	students := []domain.StudentID{fx.Student(0), fx.Student(1), fx.Student(2)}
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Hard test",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "Q", Points: 10}},
+		StudentIDs: students,
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	for i, sid := range students {
+		if _, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: sid, Response: "x"}); err != nil {
+			t.Fatalf("SubmitAnswer failed: %v", err)
+		}
+		if _, err := service.GradeAnswer(ctx, usecase.GradeInput{TeacherID: fx.Teacher(0), TestID: test.ID, QuestionID: questions[0].ID, StudentID: sid, Score: []domain.Score{2, 5, 8}[i]}); err != nil {
+			t.Fatalf("GradeAnswer failed: %v", err)
+		}
+	}
+
+	scores := func() []domain.Score {
+		var out []domain.Score
+		for _, sid := range students {
+			results, err := service.ListResultsForStudent(ctx, sid, test.ID)
+			if err != nil {
+				t.Fatalf("ListResultsForStudent failed: %v", err)
+			}
+			out = append(out, results[0].Score)
+		}
+		return out
+	}
+	expect := func(label string, want ...domain.Score) {
+		t.Helper()
+		got := scores()
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("%s: expected scores %v, got %v", label, want, got)
+			}
+		}
+	}
+
+	if _, err := service.RevertCurve(ctx, fx.Teacher(0), test.ID); err != errs.ErrNoCurve {
+		t.Fatalf("expected ErrNoCurve, got %v", err)
+	}
+	if _, err := service.ApplyCurve(ctx, usecase.CurveInput{TeacherID: fx.Teacher(0), TestID: test.ID, Kind: "bell"}); err != errs.ErrInvalidCurve {
+		t.Fatalf("expected ErrInvalidCurve, got %v", err)
+	}
+
+	if _, err := service.ApplyCurve(ctx, usecase.CurveInput{TeacherID: fx.Teacher(0), TestID: test.ID, Kind: domain.CurveAdd, Points: 3}); err != nil {
+		t.Fatalf("ApplyCurve add failed: %v", err)
+	}
+	expect("add", 5, 8, 10)
+
+	// A new curve replaces the previous one rather than stacking on it.
+	curved, err := service.ApplyCurve(ctx, usecase.CurveInput{TeacherID: fx.Teacher(0), TestID: test.ID, Kind: domain.CurveScaleToTop})
+	if err != nil {
+		t.Fatalf("ApplyCurve scale failed: %v", err)
+	}
+	if curved.Curve == nil || curved.Curve.Kind != domain.CurveScaleToTop {
+		t.Fatalf("expected the curve to be recorded, got %+v", curved.Curve)
+	}
+	expect("scale to top", 3, 6, 10)
+
+	if _, err := service.ApplyCurve(ctx, usecase.CurveInput{TeacherID: fx.Teacher(0), TestID: test.ID, Kind: domain.CurveMapping, Mapping: map[domain.Score]domain.Score{2: 4}}); err != nil {
+		t.Fatalf("ApplyCurve mapping failed: %v", err)
+	}
+	expect("mapping", 4, 5, 8)
+
+	reverted, err := service.RevertCurve(ctx, fx.Teacher(0), test.ID)
+	if err != nil {
+		t.Fatalf("RevertCurve failed: %v", err)
+	}
+	if reverted.Curve != nil {
+		t.Fatalf("expected the curve to be removed, got %+v", reverted.Curve)
+	}
+	expect("revert", 2, 5, 8)
+}
+
+func TestAssessmentService_RegradeAfterCurve(t *testing.T) {
+	fx := fixtures.NewSchool().WithStudents(2).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	students := []domain.StudentID{fx.Student(0), fx.Student(1)}
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Hard test",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "Q", Points: 10}},
+		StudentIDs: students,
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	grade := func(sid domain.StudentID, score domain.Score) *domain.Result {
+		t.Helper()
+		result, err := service.GradeAnswer(ctx, usecase.GradeInput{TeacherID: fx.Teacher(0), TestID: test.ID, QuestionID: questions[0].ID, StudentID: sid, Score: score})
+		if err != nil {
+			t.Fatalf("GradeAnswer failed: %v", err)
+		}
+		return result
+	}
+	for i, sid := range students {
+		if _, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: sid, Response: "x"}); err != nil {
+			t.Fatalf("SubmitAnswer failed: %v", err)
+		}
+		grade(sid, []domain.Score{2, 5}[i])
+	}
+	if _, err := service.ApplyCurve(ctx, usecase.CurveInput{TeacherID: fx.Teacher(0), TestID: test.ID, Kind: domain.CurveAdd, Points: 3}); err != nil {
+		t.Fatalf("ApplyCurve failed: %v", err)
+	}
+
+	regraded := grade(fx.Student(0), 4)
+	if regraded.Score != 7 || regraded.RawScore == nil || *regraded.RawScore != 4 {
+		t.Fatalf("expected the regrade to keep the curve over raw score 4, got %v (raw %v)", regraded.Score, regraded.RawScore)
+	}
+
+	if _, err := service.RevertCurve(ctx, fx.Teacher(0), test.ID); err != nil {
+		t.Fatalf("RevertCurve failed: %v", err)
+	}
+	results, err := service.ListResultsForStudent(ctx, fx.Student(0), test.ID)
+	if err != nil {
+		t.Fatalf("ListResultsForStudent failed: %v", err)
+	}
+	if results[0].Score != 4 {
+		t.Fatalf("expected reverting to restore the regraded score, got %v", results[0].Score)
+	}
+}
+
+// failingTests refuses test updates once updateErr is set.
+type failingTests struct {
+	repository.TestRepository
+	updateErr error
+}
+
+func (f *failingTests) UpdateTest(ctx context.Context, test *domain.Test) error {
+	if f.updateErr != nil {
+		return f.updateErr
+	}
+	return f.TestRepository.UpdateTest(ctx, test)
+}
+
+// failingResults refuses batch result writes after the first allowed ones.
+type failingResults struct {
+	repository.ResultRepository
+	allowed int
+	saveErr error
+}
+
+func (f *failingResults) SaveResults(ctx context.Context, results []domain.Result) error {
+	if f.saveErr != nil {
+		if f.allowed == 0 {
+			return f.saveErr
+		}
+		f.allowed--
+	}
+	return f.ResultRepository.SaveResults(ctx, results)
+}
+
+func TestAssessmentService_CurveRollsBackWhenTheTestCannotBeSaved(t *testing.T) {
+	fx := fixtures.NewSchool().WithStudents(2).Build()
+	tests := &failingTests{TestRepository: fx.Repo}
+	results := &failingResults{ResultRepository: fx.Repo}
+	service := usecase.NewAssessmentService(fx.Repo, tests, fx.Repo, results)
+	ctx := context.Background()
+
+	students := []domain.StudentID{fx.Student(0), fx.Student(1)}
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Hard test",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "Q", Points: 10}},
+		StudentIDs: students,
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	for i, sid := range students {
+		if _, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: sid, Response: "x"}); err != nil {
+			t.Fatalf("SubmitAnswer failed: %v", err)
+		}
+		if _, err := service.GradeAnswer(ctx, usecase.GradeInput{TeacherID: fx.Teacher(0), TestID: test.ID, QuestionID: questions[0].ID, StudentID: sid, Score: []domain.Score{2, 6}[i]}); err != nil {
+			t.Fatalf("GradeAnswer failed: %v", err)
+		}
+	}
+	maxScore := func() domain.Score {
+		t.Helper()
+		stats, err := service.TestStatistics(ctx, fx.Teacher(0), test.ID)
+		if err != nil {
+			t.Fatalf("TestStatistics failed: %v", err)
+		}
+		return stats.MaxScore
+	}
+	if got := maxScore(); got != 6 {
+		t.Fatalf("expected a top score of 6, got %d", got)
+	}
+
+	tests.updateErr = errors.New("disk full")
+	if _, err := service.ApplyCurve(ctx, usecase.CurveInput{TeacherID: fx.Teacher(0), TestID: test.ID, Kind: domain.CurveAdd, Points: 3}); !errors.Is(err, tests.updateErr) {
+		t.Fatalf("expected the update error, got %v", err)
+	}
+	if got := maxScore(); got != 6 {
+		t.Fatalf("expected the rollback to restore the top score of 6, got %d", got)
+	}
+
+	results.allowed, results.saveErr = 1, errors.New("connection lost")
+	_, err = service.ApplyCurve(ctx, usecase.CurveInput{TeacherID: fx.Teacher(0), TestID: test.ID, Kind: domain.CurveAdd, Points: 3})
+	if !errors.Is(err, tests.updateErr) || !errors.Is(err, results.saveErr) {
+		t.Fatalf("expected both the update and the rollback error, got %v", err)
+	}
+	if got := maxScore(); got != 9 {
+		t.Fatalf("expected the statistics to show the results left curved, got a top score of %d", got)
+	}
+	stored, err := fx.Repo.GetTest(ctx, test.ID)
+	if err != nil || stored.Curve != nil {
+		t.Fatalf("expected the test to keep no curve, got %+v, %v", stored, err)
+	}
+}
@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/export"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// DatasetService builds anonymized datasets of graded answers for training
+// auto-grading models.
+type DatasetService struct {
+	orgRepo    repository.OrganizationReader
+	testRepo   repository.TestReader
+	answerRepo repository.AnswerReader
+	resultRepo repository.ResultReader
+	salt       []byte
+	minK       int
+}
+
+// NewDatasetService constructs a dataset service. Student IDs are hashed with
+// salt, and no export may use a k below minK.
+func NewDatasetService(
+	org repository.OrganizationReader,
+	tests repository.TestReader,
+	answers repository.AnswerReader,
+	results repository.ResultReader,
+	salt string,
+	minK int,
+) *DatasetService {
+	return &DatasetService{
+		orgRepo:    org,
+		testRepo:   tests,
+		answerRepo: answers,
+		resultRepo: results,
+		salt:       []byte(salt),
+		minK:       minK,
+	}
+}
+
+// Dataset is an anonymized export ready for release.
+type Dataset struct {
+	K    int
+	Rows []export.DatasetRow
+	// Tests counts the tests released; SuppressedTests counts the tests left
+	// out because fewer than K students had graded answers in them.
+	Tests           int
+	SuppressedTests int
+}
+
+// BuildDataset exports every graded answer with the student replaced by a
+// keyed hash. A test is released only when at least k distinct students
+// appear in it, so no row can be narrowed down to fewer than k students by
+// test alone. A zero k uses the configured minimum.
+func (s *DatasetService) BuildDataset(ctx context.Context, k int) (*Dataset, error) {
+	if k == 0 {
+		k = s.minK
+	}
+	if k < s.minK {
+		return nil, errs.ErrInvalidAnonymity
+	}
+
+	tests, err := s.allTests(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dataset := &Dataset{K: k, Rows: make([]export.DatasetRow, 0)}
+	for _, test := range tests {
+		rows, students, err := s.testRows(ctx, test.ID)
+		if err != nil {
+			return nil, err
+		}
+		if students == 0 {
+			continue
+		}
+		if students < k {
+			dataset.SuppressedTests++
+			continue
+		}
+		dataset.Tests++
+		dataset.Rows = append(dataset.Rows, rows...)
+	}
+	if dataset.Tests == 0 {
+		return nil, errs.ErrDatasetTooSmall
+	}
+	return dataset, nil
+}
+
+func (s *DatasetService) allTests(ctx context.Context) ([]domain.Test, error) {
+	schools, err := repository.Collect(s.orgRepo.ListSchools(ctx, repository.All))
+	if err != nil {
+		return nil, err
+	}
+	var tests []domain.Test
+	for _, school := range schools {
+		teachers, err := repository.Collect(s.orgRepo.ListTeachers(ctx, school.ID, repository.All))
+		if err != nil {
+			return nil, err
+		}
+		for _, teacher := range teachers {
+			owned, err := repository.Collect(s.testRepo.ListTestsByTeacher(ctx, teacher.ID, repository.All))
+			if err != nil {
+				return nil, err
+			}
+			tests = append(tests, owned...)
+		}
+	}
+	return tests, nil
+}
+
+// testRows returns the graded answers of a test ordered by student hash and
+// question, together with the number of distinct students among them.
+func (s *DatasetService) testRows(ctx context.Context, testID domain.TestID) ([]export.DatasetRow, int, error) {
+	questions, err := s.testRepo.ListQuestions(ctx, testID)
+	if err != nil {
+		return nil, 0, err
+	}
+	snapshot, err := s.resultRepo.SnapshotGrading(ctx, testID)
+	if err != nil {
+		return nil, 0, err
+	}
+	answers, results := snapshot.Answers, snapshot.Results
+
+	byID := make(map[domain.QuestionID]domain.Question, len(questions))
+	for _, q := range questions {
+		byID[q.ID] = q
+	}
+	scores := make(map[domain.AnswerID]domain.Score, len(results))
+	for _, res := range results {
+		scores[res.AnswerID] = res.Score
+	}
+
+	type keyed struct {
+		row      export.DatasetRow
+		sequence int
+	}
+	var graded []keyed
+	students := make(map[domain.StudentID]struct{})
+	for _, ans := range answers {
+		score, ok := scores[ans.ID]
+		if !ok {
+			continue
+		}
+		q, ok := byID[ans.QuestionID]
+		if !ok {
+			continue
+		}
+		students[ans.StudentID] = struct{}{}
+		graded = append(graded, keyed{
+			row: export.DatasetRow{
+				StudentHash: s.hashStudent(ans.StudentID),
+				TestID:      string(testID),
+				QuestionID:  string(q.ID),
+				Question:    q.Prompt,
+				MaxPoints:   int(q.Points),
+				Answer:      ans.Response,
+				Score:       int(score),
+			},
+			sequence: q.Sequence,
+		})
+	}
+
+	// Ordering by hash rather than by submission keeps the row order from
+	// hinting at who answered first.
+	sort.Slice(graded, func(i, j int) bool {
+		if graded[i].row.StudentHash != graded[j].row.StudentHash {
+			return graded[i].row.StudentHash < graded[j].row.StudentHash
+		}
+		return graded[i].sequence < graded[j].sequence
+	})
+	rows := make([]export.DatasetRow, len(graded))
+	for i, g := range graded {
+		rows[i] = g.row
+	}
+	return rows, len(students), nil
+}
+
+func (s *DatasetService) hashStudent(studentID domain.StudentID) string {
+	mac := hmac.New(sha256.New, s.salt)
+	mac.Write([]byte(studentID))
+	return hex.EncodeToString(mac.Sum(nil))
+}
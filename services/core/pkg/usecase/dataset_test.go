@@ -0,0 +1,63 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestDatasetService_BuildDataset(t *testing.T) {
+	fx := fixtures.NewSchool().WithStudents(3).Build()
+	assessments := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	datasets := usecase.NewDatasetService(fx.Repo, fx.Repo, fx.Repo, fx.Repo, "salt", 2)
+	ctx := context.Background()
+
+	students := []domain.StudentID{fx.Student(0), fx.Student(1), fx.Student(2)}
+	test, questions, err := assessments.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Essay",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "Why is the sky blue?", Points: 5}},
+		StudentIDs: students,
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	for i, sid := range students {
+		if _, err := assessments.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: sid, Response: "scattering"}); err != nil {
+			t.Fatalf("SubmitAnswer failed: %v", err)
+		}
+		if _, err := assessments.GradeAnswer(ctx, usecase.GradeInput{TeacherID: fx.Teacher(0), TestID: test.ID, QuestionID: questions[0].ID, StudentID: sid, Score: domain.Score(i + 2), Completed: true}); err != nil {
+			t.Fatalf("GradeAnswer failed: %v", err)
+		}
+	}
+
+	if _, err := datasets.BuildDataset(ctx, 1); err != errs.ErrInvalidAnonymity {
+		t.Fatalf("expected ErrInvalidAnonymity below the minimum k, got %v", err)
+	}
+	if _, err := datasets.BuildDataset(ctx, 4); err != errs.ErrDatasetTooSmall {
+		t.Fatalf("expected ErrDatasetTooSmall, got %v", err)
+	}
+
+	dataset, err := datasets.BuildDataset(ctx, 3)
+	if err != nil {
+		t.Fatalf("BuildDataset failed: %v", err)
+	}
+	if dataset.Tests != 1 || len(dataset.Rows) != 3 {
+		t.Fatalf("expected three rows from one test, got %+v", dataset)
+	}
+	for _, row := range dataset.Rows {
+		for _, sid := range students {
+			if strings.Contains(row.StudentHash, string(sid)) {
+				t.Fatalf("student id leaked into %+v", row)
+			}
+		}
+		if row.Question != "Why is the sky blue?" || row.MaxPoints != 5 {
+			t.Fatalf("unexpected row %+v", row)
+		}
+	}
+}
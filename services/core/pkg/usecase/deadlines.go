@@ -0,0 +1,228 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/audit"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/health"
+	"github.com/sky0621/go_work_sample/core/pkg/notify"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// GradingBacklogItem is a test with answers still waiting for a grade.
+type GradingBacklogItem struct {
+	Test     domain.Test
+	Ungraded int
+	Overdue  bool
+}
+
+// SetGradingDeadline sets or, with a nil deadline, clears the date by which
+// a test's answers must be graded.
+func (s *AssessmentService) SetGradingDeadline(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID, deadline *time.Time) (*domain.Test, error) {
+	ctx, span := s.trace(ctx, "SetGradingDeadline")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+
+	test.GradingDeadline = nil
+	if deadline != nil {
+		d := deadline.UTC()
+		test.GradingDeadline = &d
+	}
+	test.UpdatedAt = time.Now().UTC()
+	if err := s.testRepo.UpdateTest(ctx, test); err != nil {
+		return nil, err
+	}
+	s.reminders.forget(testID)
+	s.audit(ctx, audit.TestUpdated(teacherID, *test, "grading deadline"))
+	return test, nil
+}
+
+// ListGradingBacklog lists the teacher's tests that still have ungraded
+// answers and whose grading deadline has passed or falls within the given
+// window, most urgent first.
+func (s *AssessmentService) ListGradingBacklog(ctx context.Context, teacherID domain.TeacherID, within time.Duration) ([]GradingBacklogItem, error) {
+	ctx, span := s.trace(ctx, "ListGradingBacklog")
+	defer span.End()
+
+	if err := s.ensureTeacherExists(ctx, teacherID); err != nil {
+		return nil, err
+	}
+	tests, err := repository.Collect(s.testRepo.ListTestsByTeacher(ctx, teacherID, repository.All))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	items := make([]GradingBacklogItem, 0)
+	for _, test := range tests {
+		if test.GradingDeadline == nil || test.GradingDeadline.After(now.Add(within)) {
+			continue
+		}
+		ungraded, err := s.countUngraded(ctx, test.ID)
+		if err != nil {
+			return nil, err
+		}
+		if ungraded == 0 {
+			continue
+		}
+		items = append(items, GradingBacklogItem{
+			Test:     test,
+			Ungraded: ungraded,
+			Overdue:  test.GradingDeadline.Before(now),
+		})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Test.GradingDeadline.Before(*items[j].Test.GradingDeadline)
+	})
+	return items, nil
+}
+
+// RunGradingReminders notifies teachers every interval about tests whose
+// grading deadline is within lead or already passed. Each test is reminded
+// once when the deadline approaches and once more when it is missed.
+func (s *AssessmentService) RunGradingReminders(ctx context.Context, interval, lead time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := s.SendGradingReminders(ctx, lead)
+			if err != nil {
+				log.Printf("grading reminders failed: %v", err)
+			}
+			health.Report(ctx, err)
+		}
+	}
+}
+
+// SendGradingReminders performs one reminder pass over every teacher.
+func (s *AssessmentService) SendGradingReminders(ctx context.Context, lead time.Duration) error {
+	ctx, span := s.trace(ctx, "SendGradingReminders")
+	defer span.End()
+
+	if s.notifier == nil {
+		return nil
+	}
+	schools, err := repository.Collect(s.orgRepo.ListSchools(ctx, repository.All))
+	if err != nil {
+		return err
+	}
+	for _, school := range schools {
+		teachers, err := repository.Collect(s.orgRepo.ListTeachers(ctx, school.ID, repository.All))
+		if err != nil {
+			return err
+		}
+		for _, teacher := range teachers {
+			backlog, err := s.ListGradingBacklog(ctx, teacher.ID, lead)
+			if err != nil {
+				return err
+			}
+			for _, item := range backlog {
+				s.remindTeacher(ctx, teacher, item)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *AssessmentService) remindTeacher(ctx context.Context, teacher domain.Teacher, item GradingBacklogItem) {
+	stage, subject := "approaching", "Grading due soon: "+item.Test.Title
+	if item.Overdue {
+		stage, subject = "overdue", "Grading overdue: "+item.Test.Title
+	}
+	if !s.reminders.mark(item.Test.ID, stage) {
+		return
+	}
+
+	to := notify.Recipient{
+		Role:        domain.RoleTeacher,
+		ID:          string(teacher.ID),
+		Name:        teacher.Name,
+		Email:       teacher.Email,
+		Preferences: teacher.Notifications,
+	}
+	n := notify.Notification{
+		Kind:       notify.KindGradingDue,
+		Subject:    subject,
+		Body:       "Answers waiting for a grade. Deadline: " + item.Test.GradingDeadline.Format(time.RFC3339),
+		OccurredAt: time.Now().UTC(),
+	}
+	if err := s.notifier.Deliver(ctx, to, n); err != nil {
+		log.Printf("notify teacher %s: %v", teacher.ID, err)
+	}
+}
+
+func (s *AssessmentService) countUngraded(ctx context.Context, testID domain.TestID) (int, error) {
+	if s.readModel != nil {
+		counters, err := s.readModel.Counters(ctx, testID)
+		if err != nil {
+			return 0, err
+		}
+		return counters.Ungraded(), nil
+	}
+	answers, err := repository.Collect(s.answerRepo.ListAnswersByTest(ctx, testID, repository.All))
+	if err != nil {
+		return 0, err
+	}
+	ungraded := 0
+	for _, ans := range answers {
+		res, err := s.resultRepo.GetResult(ctx, ans.ID)
+		if err != nil {
+			return 0, err
+		}
+		if res == nil {
+			ungraded++
+		}
+	}
+	return ungraded, nil
+}
+
+// deadlineReminders remembers which reminder stage was already sent per test.
+type deadlineReminders struct {
+	mu   sync.Mutex
+	sent map[domain.TestID]map[string]struct{}
+}
+
+func newDeadlineReminders() *deadlineReminders {
+	return &deadlineReminders{sent: make(map[domain.TestID]map[string]struct{})}
+}
+
+// mark records stage for testID and reports whether it was not sent before.
+func (r *deadlineReminders) mark(testID domain.TestID, stage string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stages, ok := r.sent[testID]
+	if !ok {
+		stages = make(map[string]struct{})
+		r.sent[testID] = stages
+	}
+	if _, done := stages[stage]; done {
+		return false
+	}
+	stages[stage] = struct{}{}
+	return true
+}
+
+// forget resets reminders of a test, e.g. after its deadline moved.
+func (r *deadlineReminders) forget(testID domain.TestID) {
+	r.mu.Lock()
+	delete(r.sent, testID)
+	r.mu.Unlock()
+}
@@ -0,0 +1,78 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/notify"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+type discardMailer struct{}
+
+func (discardMailer) Send(context.Context, string, string, string) error { return nil }
+
+func TestAssessmentService_GradingDeadlines(t *testing.T) {
+	fx := fixtures.NewSchool().WithStudents(2).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	service.SetNotifier(notify.NewService(discardMailer{}, fx.Repo, fx.Repo))
+	ctx := context.Background()
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Essay",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "Why?", Points: 10}},
+		StudentIDs: []domain.StudentID{fx.Student(0), fx.Student(1)},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	for _, sid := range []domain.StudentID{fx.Student(0), fx.Student(1)} {
+		if _, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: sid, Response: "because"}); err != nil {
+			t.Fatalf("SubmitAnswer failed: %v", err)
+		}
+	}
+
+	backlog, err := service.ListGradingBacklog(ctx, fx.Teacher(0), 48*time.Hour)
+	if err != nil {
+		t.Fatalf("ListGradingBacklog failed: %v", err)
+	}
+	if len(backlog) != 0 {
+		t.Fatalf("expected no backlog without a deadline, got %d", len(backlog))
+	}
+
+	deadline := time.Now().Add(-time.Hour)
+	if _, err := service.SetGradingDeadline(ctx, fx.Teacher(0), test.ID, &deadline); err != nil {
+		t.Fatalf("SetGradingDeadline failed: %v", err)
+	}
+	if _, err := service.GradeAnswer(ctx, usecase.GradeInput{
+		TeacherID: fx.Teacher(0), TestID: test.ID, QuestionID: questions[0].ID, StudentID: fx.Student(0), Score: 8, Completed: true,
+	}); err != nil {
+		t.Fatalf("GradeAnswer failed: %v", err)
+	}
+
+	backlog, err = service.ListGradingBacklog(ctx, fx.Teacher(0), 0)
+	if err != nil {
+		t.Fatalf("ListGradingBacklog failed: %v", err)
+	}
+	if len(backlog) != 1 || backlog[0].Ungraded != 1 || !backlog[0].Overdue {
+		t.Fatalf("expected one overdue test with one ungraded answer, got %+v", backlog)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := service.SendGradingReminders(ctx, 48*time.Hour); err != nil {
+			t.Fatalf("SendGradingReminders failed: %v", err)
+		}
+	}
+	inbox, err := repository.Collect(fx.Repo.ListNotifications(ctx, domain.RoleTeacher, string(fx.Teacher(0)), repository.All))
+	if err != nil {
+		t.Fatalf("ListNotifications failed: %v", err)
+	}
+	if len(inbox) != 1 || inbox[0].Kind != string(notify.KindGradingDue) {
+		t.Fatalf("expected a single grading reminder, got %+v", inbox)
+	}
+}
@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/audit"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/health"
+	"github.com/sky0621/go_work_sample/core/pkg/id"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// DelegationService lets a teacher hand their tests and grading to a
+// substitute from the same school for a limited time.
+type DelegationService struct {
+	orgRepo        repository.OrganizationReader
+	testRepo       repository.TestReader
+	delegationRepo repository.DelegationRepository
+	auditLog       *audit.Log
+}
+
+// NewDelegationService constructs a delegation service.
+func NewDelegationService(org repository.OrganizationReader, tests repository.TestReader, delegations repository.DelegationRepository) *DelegationService {
+	return &DelegationService{orgRepo: org, testRepo: tests, delegationRepo: delegations}
+}
+
+// SetAuditLog records granted and revoked delegations to log.
+func (s *DelegationService) SetAuditLog(log *audit.Log) {
+	s.auditLog = log
+}
+
+// DelegationInput describes a new delegation from TeacherID to DelegateID.
+type DelegationInput struct {
+	TeacherID  domain.TeacherID
+	DelegateID domain.TeacherID
+	ExpiresAt  time.Time
+}
+
+// Grant gives the delegate access to every test of the teacher until the
+// expiry, which must lie in the future.
+func (s *DelegationService) Grant(ctx context.Context, input DelegationInput) (*domain.Delegation, error) {
+	teacher, err := s.getTeacher(ctx, input.TeacherID)
+	if err != nil {
+		return nil, err
+	}
+	if input.DelegateID == "" || input.DelegateID == input.TeacherID {
+		return nil, errs.ErrInvalidDelegation
+	}
+	delegate, err := s.getTeacher(ctx, input.DelegateID)
+	if err != nil {
+		return nil, err
+	}
+	if delegate.SchoolID != teacher.SchoolID {
+		return nil, errs.ErrForbiddenTeacher
+	}
+
+	now := time.Now().UTC()
+	if !input.ExpiresAt.After(now) {
+		return nil, errs.ErrInvalidDelegation
+	}
+	delegation := &domain.Delegation{
+		ID:         domain.DelegationID(id.New()),
+		TeacherID:  input.TeacherID,
+		DelegateID: input.DelegateID,
+		ExpiresAt:  input.ExpiresAt.UTC(),
+		CreatedAt:  now,
+	}
+	if err := s.delegationRepo.SaveDelegation(ctx, delegation); err != nil {
+		return nil, err
+	}
+	recordAudit(ctx, s.auditLog, audit.DelegationGranted(*delegation))
+	return delegation, nil
+}
+
+// Revoke ends a delegation early. Only the delegating teacher may revoke it.
+func (s *DelegationService) Revoke(ctx context.Context, teacherID domain.TeacherID, delegationID domain.DelegationID) error {
+	delegation, err := s.delegationRepo.GetDelegation(ctx, delegationID)
+	if err != nil {
+		return err
+	}
+	if delegation == nil {
+		return errs.ErrDelegationNotFound
+	}
+	if delegation.TeacherID != teacherID {
+		return errs.ErrForbiddenTeacher
+	}
+	if err := s.delegationRepo.DeleteDelegation(ctx, delegationID); err != nil {
+		return err
+	}
+	recordAudit(ctx, s.auditLog, audit.DelegationRevoked(*delegation))
+	return nil
+}
+
+// ListGranted lists a page of the delegations the teacher has granted,
+// oldest first. Expired delegations are listed until the expiry job removes
+// them.
+func (s *DelegationService) ListGranted(ctx context.Context, teacherID domain.TeacherID, page repository.PageRequest) (repository.Page[domain.Delegation], error) {
+	if _, err := s.getTeacher(ctx, teacherID); err != nil {
+		return repository.Page[domain.Delegation]{}, err
+	}
+	return s.delegationRepo.ListDelegationsByTeacher(ctx, teacherID, page)
+}
+
+// ListReceived lists the active delegations the teacher holds.
+func (s *DelegationService) ListReceived(ctx context.Context, delegateID domain.TeacherID) ([]domain.Delegation, error) {
+	if _, err := s.getTeacher(ctx, delegateID); err != nil {
+		return nil, err
+	}
+	return activeDelegations(ctx, s.delegationRepo, delegateID, time.Now().UTC())
+}
+
+// ListDelegatedTests lists a page of the tests the teacher may work on as a
+// delegate, oldest first.
+func (s *DelegationService) ListDelegatedTests(ctx context.Context, delegateID domain.TeacherID, page repository.PageRequest) (repository.Page[domain.Test], error) {
+	delegations, err := s.ListReceived(ctx, delegateID)
+	if err != nil {
+		return repository.Page[domain.Test]{}, err
+	}
+
+	owners := make(map[domain.TeacherID]struct{}, len(delegations))
+	tests := make([]domain.Test, 0)
+	for _, d := range delegations {
+		if _, dup := owners[d.TeacherID]; dup {
+			continue
+		}
+		owners[d.TeacherID] = struct{}{}
+		owned, err := repository.Collect(s.testRepo.ListTestsByTeacher(ctx, d.TeacherID, repository.All))
+		if err != nil {
+			return repository.Page[domain.Test]{}, err
+		}
+		tests = append(tests, owned...)
+	}
+
+	sort.Slice(tests, func(i, j int) bool {
+		if !tests[i].CreatedAt.Equal(tests[j].CreatedAt) {
+			return tests[i].CreatedAt.Before(tests[j].CreatedAt)
+		}
+		return tests[i].ID < tests[j].ID
+	})
+	return repository.Paginate(tests, page, false, testPageKey)
+}
+
+// RunExpiry removes expired delegations every interval.
+func (s *DelegationService) RunExpiry(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := s.ExpireDelegations(ctx)
+			if err != nil {
+				log.Printf("delegation expiry failed: %v", err)
+			}
+			health.Report(ctx, err)
+		}
+	}
+}
+
+// ExpireDelegations performs one expiry pass and returns the removed
+// delegations. Access checks ignore expired delegations on their own; the
+// pass only keeps the store from accumulating them.
+func (s *DelegationService) ExpireDelegations(ctx context.Context) ([]domain.Delegation, error) {
+	expired, err := s.delegationRepo.DeleteExpiredDelegations(ctx, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range expired {
+		log.Printf("delegation %s from teacher %s to %s expired", d.ID, d.TeacherID, d.DelegateID)
+	}
+	return expired, nil
+}
+
+func (s *DelegationService) getTeacher(ctx context.Context, teacherID domain.TeacherID) (*domain.Teacher, error) {
+	teacher, err := s.orgRepo.GetTeacher(ctx, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	if teacher == nil {
+		return nil, errs.ErrTeacherNotFound
+	}
+	return teacher, nil
+}
+
+// hasActiveDelegation reports whether owner has delegated to delegate and the
+// delegation is still active at now.
+func hasActiveDelegation(ctx context.Context, delegations repository.DelegationReader, owner, delegate domain.TeacherID, now time.Time) (bool, error) {
+	active, err := activeDelegations(ctx, delegations, delegate, now)
+	if err != nil {
+		return false, err
+	}
+	for _, d := range active {
+		if d.TeacherID == owner {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func activeDelegations(ctx context.Context, delegations repository.DelegationReader, delegate domain.TeacherID, now time.Time) ([]domain.Delegation, error) {
+	all, err := delegations.ListDelegationsForDelegate(ctx, delegate)
+	if err != nil {
+		return nil, err
+	}
+	active := make([]domain.Delegation, 0, len(all))
+	for _, d := range all {
+		if d.Active(now) {
+			active = append(active, d)
+		}
+	}
+	return active, nil
+}
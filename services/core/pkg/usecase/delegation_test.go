@@ -0,0 +1,115 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/memory"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestDelegationService_GrantsAccessUntilExpiry(t *testing.T) {
+	fx := fixtures.NewSchool().WithTeachers(2).WithStudents(1).Build()
+	assessment := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	assessment.SetDelegations(fx.Repo)
+	delegations := usecase.NewDelegationService(fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+	owner, substitute := fx.Teacher(0), fx.Teacher(1)
+
+	test, _, err := assessment.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Biology",
+		TeacherID:  owner,
+		Questions:  []usecase.QuestionDraft{{Prompt: "Q", Points: 5}},
+		StudentIDs: []domain.StudentID{fx.Student(0)},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+
+	if _, err := assessment.GetQuestionsForTeacher(ctx, substitute, test.ID); !errors.Is(err, errs.ErrForbiddenTeacher) {
+		t.Fatalf("expected ErrForbiddenTeacher before delegating, got %v", err)
+	}
+
+	delegation, err := delegations.Grant(ctx, usecase.DelegationInput{
+		TeacherID:  owner,
+		DelegateID: substitute,
+		ExpiresAt:  time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Grant failed: %v", err)
+	}
+	if _, err := assessment.GetQuestionsForTeacher(ctx, substitute, test.ID); err != nil {
+		t.Fatalf("expected the delegate to access the test, got %v", err)
+	}
+	delegated, err := delegations.ListDelegatedTests(ctx, substitute, repository.All)
+	if err != nil {
+		t.Fatalf("ListDelegatedTests failed: %v", err)
+	}
+	if len(delegated.Items) != 1 || delegated.Items[0].ID != test.ID {
+		t.Fatalf("expected the owner's test to be listed, got %+v", delegated.Items)
+	}
+
+	if err := delegations.Revoke(ctx, substitute, delegation.ID); !errors.Is(err, errs.ErrForbiddenTeacher) {
+		t.Fatalf("expected ErrForbiddenTeacher when the delegate revokes, got %v", err)
+	}
+	if err := delegations.Revoke(ctx, owner, delegation.ID); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if _, err := assessment.GetQuestionsForTeacher(ctx, substitute, test.ID); !errors.Is(err, errs.ErrForbiddenTeacher) {
+		t.Fatalf("expected ErrForbiddenTeacher after revoking, got %v", err)
+	}
+
+	// An expired delegation grants nothing even before the expiry job
+	// removes it.
+	expired := &domain.Delegation{
+		ID:         "delegation-expired",
+		TeacherID:  owner,
+		DelegateID: substitute,
+		ExpiresAt:  time.Now().Add(-time.Minute).UTC(),
+		CreatedAt:  time.Now().Add(-time.Hour).UTC(),
+	}
+	if err := fx.Repo.SaveDelegation(ctx, expired); err != nil {
+		t.Fatalf("SaveDelegation failed: %v", err)
+	}
+	if _, err := assessment.GetQuestionsForTeacher(ctx, substitute, test.ID); !errors.Is(err, errs.ErrForbiddenTeacher) {
+		t.Fatalf("expected ErrForbiddenTeacher with an expired delegation, got %v", err)
+	}
+	removed, err := delegations.ExpireDelegations(ctx)
+	if err != nil {
+		t.Fatalf("ExpireDelegations failed: %v", err)
+	}
+	if len(removed) != 1 || removed[0].ID != expired.ID {
+		t.Fatalf("expected the expired delegation to be removed, got %+v", removed)
+	}
+	if got, _ := fx.Repo.GetDelegation(ctx, expired.ID); got != nil {
+		t.Fatalf("expected the expired delegation to be gone, got %+v", got)
+	}
+}
+
+func TestDelegationService_GrantValidation(t *testing.T) {
+	seed := fixtures.Merge(
+		fixtures.NewSchool().Seed(),
+		fixtures.NewSchool().WithPrefix("b-").Seed(),
+	)
+	repo := memory.NewRepository(seed)
+	delegations := usecase.NewDelegationService(repo, repo, repo)
+	ctx := context.Background()
+	teacher, otherSchool := seed.Teachers[0].ID, seed.Teachers[1].ID
+	tomorrow := time.Now().Add(24 * time.Hour)
+
+	if _, err := delegations.Grant(ctx, usecase.DelegationInput{TeacherID: teacher, DelegateID: teacher, ExpiresAt: tomorrow}); !errors.Is(err, errs.ErrInvalidDelegation) {
+		t.Fatalf("expected ErrInvalidDelegation for self delegation, got %v", err)
+	}
+	if _, err := delegations.Grant(ctx, usecase.DelegationInput{TeacherID: teacher, DelegateID: "teacher-unknown", ExpiresAt: tomorrow}); !errors.Is(err, errs.ErrTeacherNotFound) {
+		t.Fatalf("expected ErrTeacherNotFound for an unknown delegate, got %v", err)
+	}
+	if _, err := delegations.Grant(ctx, usecase.DelegationInput{TeacherID: teacher, DelegateID: otherSchool, ExpiresAt: tomorrow}); !errors.Is(err, errs.ErrForbiddenTeacher) {
+		t.Fatalf("expected ErrForbiddenTeacher for a teacher of another school, got %v", err)
+	}
+}
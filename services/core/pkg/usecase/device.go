@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/health"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// DeviceService tracks the devices students and guardians signed in on and
+// signs them out remotely, for example when a shared lab computer was left
+// signed in. Only sign-ins recorded through Record can be revoked.
+type DeviceService struct {
+	orgRepo    repository.OrganizationReader
+	deviceRepo repository.DeviceSessionRepository
+}
+
+// NewDeviceService constructs a device service.
+func NewDeviceService(org repository.OrganizationReader, devices repository.DeviceSessionRepository) *DeviceService {
+	return &DeviceService{orgRepo: org, deviceRepo: devices}
+}
+
+// Record remembers the sign-in the access token of claims was issued for.
+// device is the user agent and client the IP address that signed in.
+func (s *DeviceService) Record(ctx context.Context, claims auth.Claims, device, client string) error {
+	session := &domain.DeviceSession{
+		ID:        domain.DeviceSessionID(claims.TokenID),
+		StudentID: domain.StudentID(claims.Subject),
+		Role:      claims.Role,
+		Device:    device,
+		Client:    client,
+		CreatedAt: time.Unix(claims.IssuedAt, 0).UTC(),
+		ExpiresAt: claims.Expiry(),
+	}
+	return s.deviceRepo.SaveDeviceSession(ctx, session)
+}
+
+// ListDevices returns the active sessions the student, or their guardian,
+// is signed in with as role, oldest first. An empty role lists the sessions
+// of both.
+func (s *DeviceService) ListDevices(ctx context.Context, studentID domain.StudentID, role domain.Role) ([]domain.DeviceSession, error) {
+	if err := s.ensureStudent(ctx, studentID); err != nil {
+		return nil, err
+	}
+	sessions, err := s.deviceRepo.ListDeviceSessions(ctx, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	active := make([]domain.DeviceSession, 0, len(sessions))
+	for _, session := range sessions {
+		if session.Active(now) && (role == "" || session.Role == role) {
+			active = append(active, session)
+		}
+	}
+	return active, nil
+}
+
+// Revoke signs one session out. Revoking a session that already ended is
+// not an error; a session of another student or role is not found.
+func (s *DeviceService) Revoke(ctx context.Context, studentID domain.StudentID, role domain.Role, id domain.DeviceSessionID) error {
+	session, err := s.deviceRepo.GetDeviceSession(ctx, id)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.StudentID != studentID || (role != "" && session.Role != role) {
+		return errs.ErrDeviceNotFound
+	}
+	now := time.Now().UTC()
+	if !session.Active(now) {
+		return nil
+	}
+	session.RevokedAt = &now
+	return s.deviceRepo.SaveDeviceSession(ctx, session)
+}
+
+// RevokeAll signs out every active session of the student as role except
+// keep, usually the caller's own, and returns how many it ended. An empty
+// role revokes the sessions of both the student and their guardian.
+func (s *DeviceService) RevokeAll(ctx context.Context, studentID domain.StudentID, role domain.Role, keep domain.DeviceSessionID) (int, error) {
+	sessions, err := s.ListDevices(ctx, studentID, role)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	revoked := 0
+	for _, session := range sessions {
+		if session.ID == keep {
+			continue
+		}
+		session.RevokedAt = &now
+		if err := s.deviceRepo.SaveDeviceSession(ctx, &session); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+	return revoked, nil
+}
+
+// Revoked reports whether the access token with tokenID was signed out
+// before it expired. Tokens of unrecorded sign-ins are never revoked.
+func (s *DeviceService) Revoked(ctx context.Context, tokenID string) (bool, error) {
+	session, err := s.deviceRepo.GetDeviceSession(ctx, domain.DeviceSessionID(tokenID))
+	if err != nil {
+		return false, err
+	}
+	return session != nil && session.RevokedAt != nil, nil
+}
+
+// RunExpiry removes the sessions of expired tokens every interval.
+func (s *DeviceService) RunExpiry(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := s.ExpireDevices(ctx)
+			if err != nil {
+				log.Printf("device session expiry failed: %v", err)
+			}
+			health.Report(ctx, err)
+		}
+	}
+}
+
+// ExpireDevices performs one expiry pass and returns the removed sessions.
+// Their tokens are rejected on expiry anyway, revoked or not.
+func (s *DeviceService) ExpireDevices(ctx context.Context) ([]domain.DeviceSession, error) {
+	return s.deviceRepo.DeleteExpiredDeviceSessions(ctx, time.Now().UTC())
+}
+
+func (s *DeviceService) ensureStudent(ctx context.Context, studentID domain.StudentID) error {
+	student, err := s.orgRepo.GetStudent(ctx, studentID)
+	if err != nil {
+		return err
+	}
+	if student == nil {
+		return errs.ErrStudentNotFound
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestDeviceService_ListsAndRevokesSignIns(t *testing.T) {
+	fx := fixtures.NewSchool().WithStudents(2).Build()
+	ctx := context.Background()
+	devices := usecase.NewDeviceService(fx.Repo, fx.Repo)
+	signer := auth.NewSigner("session-secret")
+
+	signIn := func(role domain.Role, studentID domain.StudentID, device string) auth.Claims {
+		t.Helper()
+		_, claims, err := signer.Issue(auth.Principal{Role: role, ID: string(studentID)}, time.Hour)
+		if err != nil {
+			t.Fatalf("Issue failed: %v", err)
+		}
+		if err := devices.Record(ctx, claims, device, "10.0.0.1"); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+		return claims
+	}
+	lab := signIn(domain.RoleStudent, fx.Student(0), "lab-pc")
+	home := signIn(domain.RoleStudent, fx.Student(0), "home-laptop")
+	phone := signIn(domain.RoleStudent, fx.Student(0), "phone")
+	guardian := signIn(domain.RoleGuardian, fx.Student(0), "parent-phone")
+	other := signIn(domain.RoleStudent, fx.Student(1), "other")
+
+	listed, err := devices.ListDevices(ctx, fx.Student(0), domain.RoleStudent)
+	if err != nil {
+		t.Fatalf("ListDevices failed: %v", err)
+	}
+	if len(listed) != 3 || listed[0].Role != domain.RoleStudent || listed[0].Client != "10.0.0.1" {
+		t.Fatalf("expected the three student sessions, got %+v", listed)
+	}
+	if all, _ := devices.ListDevices(ctx, fx.Student(0), ""); len(all) != 4 {
+		t.Fatalf("expected the guardian session in the unfiltered list, got %d sessions", len(all))
+	}
+
+	if err := devices.Revoke(ctx, fx.Student(0), domain.RoleStudent, domain.DeviceSessionID(other.TokenID)); err != errs.ErrDeviceNotFound {
+		t.Fatalf("expected ErrDeviceNotFound for another student's session, got %v", err)
+	}
+	if err := devices.Revoke(ctx, fx.Student(0), domain.RoleStudent, domain.DeviceSessionID(guardian.TokenID)); err != errs.ErrDeviceNotFound {
+		t.Fatalf("expected ErrDeviceNotFound for the guardian's session, got %v", err)
+	}
+	if err := devices.Revoke(ctx, fx.Student(0), domain.RoleStudent, domain.DeviceSessionID(lab.TokenID)); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if revoked, err := devices.Revoked(ctx, lab.TokenID); err != nil || !revoked {
+		t.Fatalf("expected the lab session to be revoked, got %v (%v)", revoked, err)
+	}
+
+	count, err := devices.RevokeAll(ctx, fx.Student(0), domain.RoleStudent, domain.DeviceSessionID(home.TokenID))
+	if err != nil || count != 1 {
+		t.Fatalf("expected RevokeAll to end one session, got %d (%v)", count, err)
+	}
+	for tokenID, want := range map[string]bool{home.TokenID: false, phone.TokenID: true, guardian.TokenID: false, other.TokenID: false, "unrecorded": false} {
+		if revoked, _ := devices.Revoked(ctx, tokenID); revoked != want {
+			t.Fatalf("expected Revoked(%s) = %v", tokenID, want)
+		}
+	}
+	if listed, _ = devices.ListDevices(ctx, fx.Student(0), domain.RoleStudent); len(listed) != 1 || listed[0].Device != "home-laptop" {
+		t.Fatalf("expected only the kept session, got %+v", listed)
+	}
+
+	if _, err := devices.ListDevices(ctx, "missing", domain.RoleStudent); err != errs.ErrStudentNotFound {
+		t.Fatalf("expected ErrStudentNotFound, got %v", err)
+	}
+}
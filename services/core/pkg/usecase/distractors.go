@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// ChoiceSelection counts the students who picked one choice of a question.
+// Share is the fraction of the question's answers that picked it.
+type ChoiceSelection struct {
+	Key     string
+	Label   string
+	Correct bool
+	Count   int
+	Share   float64
+}
+
+// DistractorAnalysis shows which choices students picked for a
+// multiple-choice question. Choices are in the question's order; Distractors
+// holds the picked wrong choices, most popular first, and is only filled in
+// when the question has an expected response. Unrecognized counts stored
+// responses that match no current choice key.
+type DistractorAnalysis struct {
+	TestID       domain.TestID
+	QuestionID   domain.QuestionID
+	Answers      int
+	Choices      []ChoiceSelection
+	Distractors  []ChoiceSelection
+	Unrecognized int
+	ComputedAt   time.Time
+}
+
+// QuestionDistractors analyses the choices picked for a multiple-choice
+// question of a test, ensuring teacher access.
+func (s *AssessmentService) QuestionDistractors(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID, questionID domain.QuestionID) (*DistractorAnalysis, error) {
+	ctx, span := s.trace(ctx, "QuestionDistractors")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	question, err := s.findQuestion(ctx, testID, questionID)
+	if err != nil {
+		return nil, err
+	}
+	if question.AnswerType() != domain.QuestionMultipleChoice {
+		return nil, errs.ErrNotMultipleChoice
+	}
+	answers, err := repository.Collect(s.answerRepo.ListAnswersByTest(ctx, testID, repository.All))
+	if err != nil {
+		return nil, err
+	}
+	return analyseChoices(*question, answers), nil
+}
+
+func analyseChoices(q domain.Question, answers []domain.Answer) *DistractorAnalysis {
+	analysis := &DistractorAnalysis{
+		TestID:     q.TestID,
+		QuestionID: q.ID,
+		Choices:    make([]ChoiceSelection, len(q.Choices)),
+		ComputedAt: time.Now().UTC(),
+	}
+	index := make(map[string]int, len(q.Choices))
+	for i, c := range q.Choices {
+		index[c.Key] = i
+		analysis.Choices[i] = ChoiceSelection{Key: c.Key, Label: c.Label, Correct: c.Key == q.ExpectedResponse}
+	}
+	for _, a := range answers {
+		if a.QuestionID != q.ID {
+			continue
+		}
+		analysis.Answers++
+		if i, ok := index[a.Response]; ok {
+			analysis.Choices[i].Count++
+		} else {
+			analysis.Unrecognized++
+		}
+	}
+
+	for i := range analysis.Choices {
+		c := &analysis.Choices[i]
+		if analysis.Answers > 0 {
+			c.Share = float64(c.Count) / float64(analysis.Answers)
+		}
+		if q.ExpectedResponse != "" && !c.Correct && c.Count > 0 {
+			analysis.Distractors = append(analysis.Distractors, *c)
+		}
+	}
+	sort.SliceStable(analysis.Distractors, func(i, j int) bool {
+		return analysis.Distractors[i].Count > analysis.Distractors[j].Count
+	})
+	return analysis
+}
@@ -0,0 +1,78 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_QuestionDistractors(t *testing.T) {
+	fx := fixtures.NewSchool().WithStudents(5).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+	teacher := fx.Teacher(0)
+
+	var students []domain.StudentID
+	for i := 0; i < 5; i++ {
+		students = append(students, fx.Student(i))
+	}
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:     "Capitals",
+		TeacherID: teacher,
+		Questions: []usecase.QuestionDraft{
+			{
+				Prompt: "Capital of Australia",
+				Points: 1,
+				Type:   domain.QuestionMultipleChoice,
+				Choices: []domain.Choice{
+					{Key: "a", Label: "Sydney"},
+					{Key: "b", Label: "Canberra"},
+					{Key: "c", Label: "Melbourne"},
+					{Key: "d", Label: "Perth"},
+				},
+				ExpectedResponse: "b",
+			},
+			{Prompt: "Why is it the capital?", Points: 3},
+		},
+		StudentIDs: students,
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	mcq := questions[0]
+	for i, response := range []string{"a", "b", "a", "c"} {
+		if _, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: mcq.ID, StudentID: students[i], Response: response}); err != nil {
+			t.Fatalf("SubmitAnswer failed: %v", err)
+		}
+	}
+	// An answer stored before the question had choices matches no key.
+	if err := fx.Repo.UpsertAnswer(ctx, &domain.Answer{ID: "legacy", TestID: test.ID, QuestionID: mcq.ID, StudentID: students[4], Response: "Sydney"}); err != nil {
+		t.Fatalf("UpsertAnswer failed: %v", err)
+	}
+
+	analysis, err := service.QuestionDistractors(ctx, teacher, test.ID, mcq.ID)
+	if err != nil {
+		t.Fatalf("QuestionDistractors failed: %v", err)
+	}
+	if analysis.Answers != 5 || analysis.Unrecognized != 1 || len(analysis.Choices) != 4 {
+		t.Fatalf("unexpected analysis %+v", analysis)
+	}
+	if b := analysis.Choices[1]; !b.Correct || b.Count != 1 || b.Share != 0.2 {
+		t.Fatalf("unexpected correct choice %+v", b)
+	}
+	if len(analysis.Distractors) != 2 || analysis.Distractors[0].Key != "a" || analysis.Distractors[0].Count != 2 || analysis.Distractors[1].Key != "c" {
+		t.Fatalf("expected a then c as distractors, got %+v", analysis.Distractors)
+	}
+
+	if _, err := service.QuestionDistractors(ctx, teacher, test.ID, questions[1].ID); !errors.Is(err, errs.ErrNotMultipleChoice) {
+		t.Fatalf("expected ErrNotMultipleChoice for free text, got %v", err)
+	}
+	if _, err := service.QuestionDistractors(ctx, teacher+"-other", test.ID, mcq.ID); err == nil {
+		t.Fatalf("expected another teacher to be refused")
+	}
+}
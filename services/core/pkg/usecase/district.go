@@ -0,0 +1,375 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/id"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// DistrictService manages districts above schools and gives district staff a
+// read-only view across the schools of their district. Staff may modify a
+// school only when an administrator granted it to them.
+type DistrictService struct {
+	orgRepo      repository.OrganizationRepository
+	districtRepo repository.DistrictRepository
+	testRepo     repository.TestReader
+	answerRepo   repository.AnswerReader
+	resultRepo   repository.ResultReader
+}
+
+// NewDistrictService constructs a district service.
+func NewDistrictService(
+	org repository.OrganizationRepository,
+	districts repository.DistrictRepository,
+	tests repository.TestReader,
+	answers repository.AnswerReader,
+	results repository.ResultReader,
+) *DistrictService {
+	return &DistrictService{
+		orgRepo:      org,
+		districtRepo: districts,
+		testRepo:     tests,
+		answerRepo:   answers,
+		resultRepo:   results,
+	}
+}
+
+// StaffInput describes a new district staff member.
+type StaffInput struct {
+	DistrictID     domain.DistrictID
+	Name           string
+	Email          string
+	ManagedSchools []domain.SchoolID
+}
+
+// SchoolAnalytics aggregates activity of one school of a district.
+type SchoolAnalytics struct {
+	School    domain.School
+	Teachers  int
+	Students  int
+	Tests     int
+	Answers   int
+	Graded    int
+	MeanScore float64
+}
+
+// DistrictAnalytics aggregates activity across the schools of a district.
+// Totals sum the schools; MeanScore is over every graded answer.
+type DistrictAnalytics struct {
+	District   domain.District
+	Schools    []SchoolAnalytics
+	Totals     SchoolAnalytics
+	ComputedAt time.Time
+}
+
+// CreateDistrict registers a new district.
+func (s *DistrictService) CreateDistrict(ctx context.Context, name string) (*domain.District, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errs.ErrInvalidDistrict
+	}
+	district := &domain.District{
+		ID:        domain.DistrictID(id.New()),
+		Name:      name,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.districtRepo.SaveDistrict(ctx, district); err != nil {
+		return nil, err
+	}
+	return district, nil
+}
+
+// ListDistricts lists a page of every district, oldest first.
+func (s *DistrictService) ListDistricts(ctx context.Context, page repository.PageRequest) (repository.Page[domain.District], error) {
+	return s.districtRepo.ListDistricts(ctx, page)
+}
+
+// AssignSchool moves a school into the district or, with an empty district,
+// out of any district. Staff of the previous district keep no access to it.
+func (s *DistrictService) AssignSchool(ctx context.Context, schoolID domain.SchoolID, districtID domain.DistrictID) (*domain.School, error) {
+	if districtID != "" {
+		if _, err := s.getDistrict(ctx, districtID); err != nil {
+			return nil, err
+		}
+	}
+	school, err := s.orgRepo.GetSchool(ctx, schoolID)
+	if err != nil {
+		return nil, err
+	}
+	if school == nil {
+		return nil, errs.ErrSchoolNotFound
+	}
+
+	school.DistrictID = districtID
+	if err := s.orgRepo.UpdateSchool(ctx, school); err != nil {
+		return nil, err
+	}
+	return school, nil
+}
+
+// CreateStaff registers a staff member of a district. Managed schools must
+// belong to the district.
+func (s *DistrictService) CreateStaff(ctx context.Context, input StaffInput) (*domain.DistrictStaff, error) {
+	if _, err := s.getDistrict(ctx, input.DistrictID); err != nil {
+		return nil, err
+	}
+	name, email := strings.TrimSpace(input.Name), strings.TrimSpace(input.Email)
+	if name == "" {
+		return nil, errs.ErrInvalidDistrict
+	}
+	if err := s.ensureSchoolsInDistrict(ctx, input.DistrictID, input.ManagedSchools); err != nil {
+		return nil, err
+	}
+
+	staff := &domain.DistrictStaff{
+		ID:             domain.DistrictStaffID(id.New()),
+		DistrictID:     input.DistrictID,
+		Name:           name,
+		Email:          email,
+		ManagedSchools: append([]domain.SchoolID(nil), input.ManagedSchools...),
+		CreatedAt:      time.Now().UTC(),
+	}
+	if err := s.districtRepo.SaveDistrictStaff(ctx, staff); err != nil {
+		return nil, err
+	}
+	return staff, nil
+}
+
+// GrantSchools replaces the schools a staff member may modify.
+func (s *DistrictService) GrantSchools(ctx context.Context, staffID domain.DistrictStaffID, schoolIDs []domain.SchoolID) (*domain.DistrictStaff, error) {
+	staff, err := s.GetStaff(ctx, staffID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureSchoolsInDistrict(ctx, staff.DistrictID, schoolIDs); err != nil {
+		return nil, err
+	}
+
+	staff.ManagedSchools = append([]domain.SchoolID(nil), schoolIDs...)
+	if err := s.districtRepo.SaveDistrictStaff(ctx, staff); err != nil {
+		return nil, err
+	}
+	return staff, nil
+}
+
+// GetStaff returns a district staff member.
+func (s *DistrictService) GetStaff(ctx context.Context, staffID domain.DistrictStaffID) (*domain.DistrictStaff, error) {
+	staff, err := s.districtRepo.GetDistrictStaff(ctx, staffID)
+	if err != nil {
+		return nil, err
+	}
+	if staff == nil {
+		return nil, errs.ErrStaffNotFound
+	}
+	return staff, nil
+}
+
+// GetDistrict returns the district of the staff member.
+func (s *DistrictService) GetDistrict(ctx context.Context, staffID domain.DistrictStaffID, districtID domain.DistrictID) (*domain.District, error) {
+	if _, err := s.ensureStaffOf(ctx, staffID, districtID); err != nil {
+		return nil, err
+	}
+	return s.getDistrict(ctx, districtID)
+}
+
+// ListSchools lists a page of the schools of the staff member's district.
+func (s *DistrictService) ListSchools(ctx context.Context, staffID domain.DistrictStaffID, districtID domain.DistrictID, page repository.PageRequest) (repository.Page[domain.School], error) {
+	if _, err := s.ensureStaffOf(ctx, staffID, districtID); err != nil {
+		return repository.Page[domain.School]{}, err
+	}
+	return s.districtRepo.ListSchoolsByDistrict(ctx, districtID, page)
+}
+
+// ListTeachers lists a page of the teachers across every school of the staff
+// member's district, oldest first.
+func (s *DistrictService) ListTeachers(ctx context.Context, staffID domain.DistrictStaffID, districtID domain.DistrictID, page repository.PageRequest) (repository.Page[domain.Teacher], error) {
+	if _, err := s.ensureStaffOf(ctx, staffID, districtID); err != nil {
+		return repository.Page[domain.Teacher]{}, err
+	}
+	schools, err := repository.Collect(s.districtRepo.ListSchoolsByDistrict(ctx, districtID, repository.All))
+	if err != nil {
+		return repository.Page[domain.Teacher]{}, err
+	}
+
+	var teachers []domain.Teacher
+	for _, school := range schools {
+		list, err := repository.Collect(s.orgRepo.ListTeachers(ctx, school.ID, repository.All))
+		if err != nil {
+			return repository.Page[domain.Teacher]{}, err
+		}
+		teachers = append(teachers, list...)
+	}
+
+	sort.Slice(teachers, func(i, j int) bool {
+		if !teachers[i].CreatedAt.Equal(teachers[j].CreatedAt) {
+			return teachers[i].CreatedAt.Before(teachers[j].CreatedAt)
+		}
+		return teachers[i].ID < teachers[j].ID
+	})
+	return repository.Paginate(teachers, page, false, func(t domain.Teacher) (time.Time, string) {
+		return t.CreatedAt, string(t.ID)
+	})
+}
+
+// Analytics aggregates teachers, students, tests and grading across the
+// schools of the staff member's district.
+func (s *DistrictService) Analytics(ctx context.Context, staffID domain.DistrictStaffID, districtID domain.DistrictID) (*DistrictAnalytics, error) {
+	if _, err := s.ensureStaffOf(ctx, staffID, districtID); err != nil {
+		return nil, err
+	}
+	district, err := s.getDistrict(ctx, districtID)
+	if err != nil {
+		return nil, err
+	}
+	schools, err := repository.Collect(s.districtRepo.ListSchoolsByDistrict(ctx, districtID, repository.All))
+	if err != nil {
+		return nil, err
+	}
+
+	analytics := &DistrictAnalytics{
+		District:   *district,
+		Schools:    make([]SchoolAnalytics, 0, len(schools)),
+		ComputedAt: time.Now().UTC(),
+	}
+	var allResults []domain.Result
+	for _, school := range schools {
+		row, results, err := s.schoolAnalytics(ctx, school)
+		if err != nil {
+			return nil, err
+		}
+		analytics.Schools = append(analytics.Schools, row)
+		analytics.Totals.Teachers += row.Teachers
+		analytics.Totals.Students += row.Students
+		analytics.Totals.Tests += row.Tests
+		analytics.Totals.Answers += row.Answers
+		analytics.Totals.Graded += row.Graded
+		allResults = append(allResults, results...)
+	}
+	analytics.Totals.MeanScore = domain.MeanScore(allResults)
+	return analytics, nil
+}
+
+// UpdateSchoolQuotas changes the quotas of a school of the district the
+// staff member was granted.
+func (s *DistrictService) UpdateSchoolQuotas(ctx context.Context, staffID domain.DistrictStaffID, districtID domain.DistrictID, schoolID domain.SchoolID, quotas domain.SchoolQuotas) (*domain.School, error) {
+	staff, err := s.ensureStaffOf(ctx, staffID, districtID)
+	if err != nil {
+		return nil, err
+	}
+	school, err := s.orgRepo.GetSchool(ctx, schoolID)
+	if err != nil {
+		return nil, err
+	}
+	if school == nil {
+		return nil, errs.ErrSchoolNotFound
+	}
+	if school.DistrictID != staff.DistrictID || !staff.Manages(schoolID) {
+		return nil, errs.ErrForbiddenDistrict
+	}
+	if !quotas.Valid() {
+		return nil, errs.ErrInvalidQuota
+	}
+
+	school.Settings.Quotas = quotas
+	if err := s.orgRepo.UpdateSchool(ctx, school); err != nil {
+		return nil, err
+	}
+	return school, nil
+}
+
+func (s *DistrictService) schoolAnalytics(ctx context.Context, school domain.School) (SchoolAnalytics, []domain.Result, error) {
+	row := SchoolAnalytics{School: school}
+
+	teachers, err := repository.Collect(s.orgRepo.ListTeachers(ctx, school.ID, repository.All))
+	if err != nil {
+		return row, nil, err
+	}
+	row.Teachers = len(teachers)
+
+	grades, err := repository.Collect(s.orgRepo.ListGrades(ctx, school.ID, repository.All))
+	if err != nil {
+		return row, nil, err
+	}
+	for _, grade := range grades {
+		classes, err := repository.Collect(s.orgRepo.ListClasses(ctx, grade.ID, repository.All))
+		if err != nil {
+			return row, nil, err
+		}
+		for _, class := range classes {
+			students, err := repository.Collect(s.orgRepo.ListStudents(ctx, class.ID, repository.All))
+			if err != nil {
+				return row, nil, err
+			}
+			row.Students += len(students)
+		}
+	}
+
+	var results []domain.Result
+	for _, teacher := range teachers {
+		tests, err := repository.Collect(s.testRepo.ListTestsByTeacher(ctx, teacher.ID, repository.All))
+		if err != nil {
+			return row, nil, err
+		}
+		row.Tests += len(tests)
+		for _, test := range tests {
+			snapshot, err := s.resultRepo.SnapshotGrading(ctx, test.ID)
+			if err != nil {
+				return row, nil, err
+			}
+			row.Answers += len(snapshot.Answers)
+			results = append(results, snapshot.Results...)
+		}
+	}
+	row.Graded = len(results)
+	row.MeanScore = domain.MeanScore(results)
+	return row, results, nil
+}
+
+// ensureStaffOf returns the staff member after checking they work for the
+// district.
+func (s *DistrictService) ensureStaffOf(ctx context.Context, staffID domain.DistrictStaffID, districtID domain.DistrictID) (*domain.DistrictStaff, error) {
+	staff, err := s.districtRepo.GetDistrictStaff(ctx, staffID)
+	if err != nil {
+		return nil, err
+	}
+	if staff == nil {
+		return nil, errs.ErrStaffNotFound
+	}
+	if staff.DistrictID != districtID {
+		return nil, errs.ErrForbiddenDistrict
+	}
+	return staff, nil
+}
+
+func (s *DistrictService) ensureSchoolsInDistrict(ctx context.Context, districtID domain.DistrictID, schoolIDs []domain.SchoolID) error {
+	for _, schoolID := range schoolIDs {
+		school, err := s.orgRepo.GetSchool(ctx, schoolID)
+		if err != nil {
+			return err
+		}
+		if school == nil {
+			return errs.ErrSchoolNotFound
+		}
+		if school.DistrictID != districtID {
+			return errs.ErrInvalidDistrict
+		}
+	}
+	return nil
+}
+
+func (s *DistrictService) getDistrict(ctx context.Context, districtID domain.DistrictID) (*domain.District, error) {
+	district, err := s.districtRepo.GetDistrict(ctx, districtID)
+	if err != nil {
+		return nil, err
+	}
+	if district == nil {
+		return nil, errs.ErrDistrictNotFound
+	}
+	return district, nil
+}
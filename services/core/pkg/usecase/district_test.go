@@ -0,0 +1,127 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/memory"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestDistrictService_StaffViewButModifyOnlyGrantedSchools(t *testing.T) {
+	seed := fixtures.Merge(
+		fixtures.NewSchool().Seed(),
+		fixtures.NewSchool().WithPrefix("b-").Seed(),
+	)
+	repo := memory.NewRepository(seed)
+	districts := usecase.NewDistrictService(repo, repo, repo, repo, repo)
+	ctx := context.Background()
+	schoolA, schoolB := seed.Schools[0].ID, seed.Schools[1].ID
+
+	district, err := districts.CreateDistrict(ctx, "North")
+	if err != nil {
+		t.Fatalf("CreateDistrict failed: %v", err)
+	}
+	other, err := districts.CreateDistrict(ctx, "South")
+	if err != nil {
+		t.Fatalf("CreateDistrict failed: %v", err)
+	}
+	for _, schoolID := range []domain.SchoolID{schoolA, schoolB} {
+		if _, err := districts.AssignSchool(ctx, schoolID, district.ID); err != nil {
+			t.Fatalf("AssignSchool failed: %v", err)
+		}
+	}
+
+	staff, err := districts.CreateStaff(ctx, usecase.StaffInput{
+		DistrictID:     district.ID,
+		Name:           "Officer",
+		ManagedSchools: []domain.SchoolID{schoolA},
+	})
+	if err != nil {
+		t.Fatalf("CreateStaff failed: %v", err)
+	}
+
+	schools, err := districts.ListSchools(ctx, staff.ID, district.ID, repository.All)
+	if err != nil {
+		t.Fatalf("ListSchools failed: %v", err)
+	}
+	if len(schools.Items) != 2 {
+		t.Fatalf("expected both schools of the district, got %+v", schools.Items)
+	}
+	teachers, err := districts.ListTeachers(ctx, staff.ID, district.ID, repository.All)
+	if err != nil {
+		t.Fatalf("ListTeachers failed: %v", err)
+	}
+	if len(teachers.Items) != len(seed.Teachers) {
+		t.Fatalf("expected %d teachers across schools, got %d", len(seed.Teachers), len(teachers.Items))
+	}
+
+	quotas := domain.SchoolQuotas{SubmissionsPerMinute: 30}
+	if _, err := districts.UpdateSchoolQuotas(ctx, staff.ID, district.ID, schoolA, quotas); err != nil {
+		t.Fatalf("UpdateSchoolQuotas on a granted school failed: %v", err)
+	}
+	if _, err := districts.UpdateSchoolQuotas(ctx, staff.ID, district.ID, schoolB, quotas); !errors.Is(err, errs.ErrForbiddenDistrict) {
+		t.Fatalf("expected ErrForbiddenDistrict for a school not granted, got %v", err)
+	}
+	if _, err := districts.ListSchools(ctx, staff.ID, other.ID, repository.All); !errors.Is(err, errs.ErrForbiddenDistrict) {
+		t.Fatalf("expected ErrForbiddenDistrict for another district, got %v", err)
+	}
+
+	// Grants are limited to schools of the staff member's own district.
+	if _, err := districts.AssignSchool(ctx, schoolB, other.ID); err != nil {
+		t.Fatalf("AssignSchool failed: %v", err)
+	}
+	if _, err := districts.GrantSchools(ctx, staff.ID, []domain.SchoolID{schoolB}); !errors.Is(err, errs.ErrInvalidDistrict) {
+		t.Fatalf("expected ErrInvalidDistrict when granting a school of another district, got %v", err)
+	}
+}
+
+func TestDistrictService_AnalyticsAggregatesSchools(t *testing.T) {
+	fx := fixtures.NewSchool().WithTeachers(2).WithStudents(3).Build()
+	districts := usecase.NewDistrictService(fx.Repo, fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	assessment := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	district, err := districts.CreateDistrict(ctx, "Central")
+	if err != nil {
+		t.Fatalf("CreateDistrict failed: %v", err)
+	}
+	if _, err := districts.AssignSchool(ctx, fx.School.ID, district.ID); err != nil {
+		t.Fatalf("AssignSchool failed: %v", err)
+	}
+	staff, err := districts.CreateStaff(ctx, usecase.StaffInput{DistrictID: district.ID, Name: "Analyst"})
+	if err != nil {
+		t.Fatalf("CreateStaff failed: %v", err)
+	}
+
+	test, questions, err := assessment.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Geometry",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "Q", Points: 10}},
+		StudentIDs: []domain.StudentID{fx.Student(0)},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	answer := &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: fx.Student(0), Response: "90"}
+	if _, err := assessment.SubmitAnswer(ctx, answer); err != nil {
+		t.Fatalf("SubmitAnswer failed: %v", err)
+	}
+
+	analytics, err := districts.Analytics(ctx, staff.ID, district.ID)
+	if err != nil {
+		t.Fatalf("Analytics failed: %v", err)
+	}
+	if len(analytics.Schools) != 1 {
+		t.Fatalf("expected one school, got %+v", analytics.Schools)
+	}
+	totals := analytics.Totals
+	if totals.Teachers != 2 || totals.Students != 3 || totals.Tests != 1 || totals.Answers != 1 || totals.Graded != 0 {
+		t.Fatalf("unexpected totals %+v", totals)
+	}
+}
@@ -0,0 +1,187 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+)
+
+const defaultDraftLockTTL = 5 * time.Minute
+
+// DraftLock is a teacher's claim to edit a draft test alone until ExpiresAt.
+// Editors renew it while the draft is open.
+type DraftLock struct {
+	TestID     domain.TestID
+	TeacherID  domain.TeacherID
+	AcquiredAt time.Time
+	ExpiresAt  time.Time
+}
+
+// DraftLockedError reports a draft held by another teacher. It matches
+// errs.ErrDraftLocked.
+type DraftLockedError struct {
+	Lock DraftLock
+}
+
+func (e *DraftLockedError) Error() string {
+	return fmt.Sprintf("%s by %s until %s", errs.ErrDraftLocked, e.Lock.TeacherID, e.Lock.ExpiresAt.Format(time.RFC3339))
+}
+
+func (e *DraftLockedError) Unwrap() error {
+	return errs.ErrDraftLocked
+}
+
+// SetDraftLockTTL sets how long a draft lock lasts without renewal.
+func (s *AssessmentService) SetDraftLockTTL(ttl time.Duration) {
+	s.draftLocks.setTTL(ttl)
+}
+
+// LockDraft locks a draft test for the teacher, or renews the teacher's own
+// lock. A lock held by another teacher is only taken over with steal, so
+// co-teachers never overwrite each other's edits by accident.
+func (s *AssessmentService) LockDraft(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID, steal bool) (*DraftLock, error) {
+	ctx, span := s.trace(ctx, "LockDraft")
+	defer span.End()
+
+	test, err := s.draftForTeacher(ctx, teacherID, testID)
+	if err != nil {
+		return nil, err
+	}
+	lock, err := s.draftLocks.acquire(test.ID, teacherID, steal, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return &lock, nil
+}
+
+// UnlockDraft releases the teacher's lock on a draft. Releasing a draft
+// nobody holds is not an error.
+func (s *AssessmentService) UnlockDraft(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID) error {
+	ctx, span := s.trace(ctx, "UnlockDraft")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return err
+	}
+	return s.draftLocks.release(testID, teacherID, time.Now().UTC())
+}
+
+// GetDraftLock returns the current lock on a test, or nil when it is free.
+func (s *AssessmentService) GetDraftLock(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID) (*DraftLock, error) {
+	ctx, span := s.trace(ctx, "GetDraftLock")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	lock, ok := s.draftLocks.current(testID, time.Now().UTC())
+	if !ok {
+		return nil, nil
+	}
+	return &lock, nil
+}
+
+func (s *AssessmentService) draftForTeacher(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID) (*domain.Test, error) {
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	if test.Published {
+		return nil, errs.ErrTestPublished
+	}
+	return test, nil
+}
+
+// ensureDraftUnlocked stops a teacher from editing a draft another teacher
+// holds the lock of. Editing a draft nobody locked is allowed.
+func (s *AssessmentService) ensureDraftUnlocked(test *domain.Test, teacherID domain.TeacherID) error {
+	if test.Published {
+		return nil
+	}
+	lock, ok := s.draftLocks.current(test.ID, time.Now().UTC())
+	if ok && lock.TeacherID != teacherID {
+		return &DraftLockedError{Lock: lock}
+	}
+	return nil
+}
+
+// draftLocks holds the draft locks of the process. Locks are short-lived, so
+// losing them on restart only frees drafts a little early.
+type draftLocks struct {
+	mu   sync.Mutex
+	ttl  time.Duration
+	held map[domain.TestID]DraftLock
+}
+
+func newDraftLocks() *draftLocks {
+	return &draftLocks{ttl: defaultDraftLockTTL, held: make(map[domain.TestID]DraftLock)}
+}
+
+func (l *draftLocks) setTTL(ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.ttl = ttl
+}
+
+func (l *draftLocks) acquire(testID domain.TestID, teacherID domain.TeacherID, steal bool, now time.Time) (DraftLock, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lock, ok := l.held[testID]
+	switch {
+	case !ok || !now.Before(lock.ExpiresAt):
+		lock = DraftLock{TestID: testID, TeacherID: teacherID, AcquiredAt: now}
+	case lock.TeacherID == teacherID:
+	case steal:
+		lock = DraftLock{TestID: testID, TeacherID: teacherID, AcquiredAt: now}
+	default:
+		return DraftLock{}, &DraftLockedError{Lock: lock}
+	}
+	lock.ExpiresAt = now.Add(l.ttl)
+	l.held[testID] = lock
+	return lock, nil
+}
+
+func (l *draftLocks) release(testID domain.TestID, teacherID domain.TeacherID, now time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lock, ok := l.held[testID]
+	if !ok {
+		return nil
+	}
+	if now.Before(lock.ExpiresAt) && lock.TeacherID != teacherID {
+		return &DraftLockedError{Lock: lock}
+	}
+	delete(l.held, testID)
+	return nil
+}
+
+// drop forgets the lock of a test that is no longer a draft.
+func (l *draftLocks) drop(testID domain.TestID) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.held, testID)
+}
+
+func (l *draftLocks) current(testID domain.TestID, now time.Time) (DraftLock, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lock, ok := l.held[testID]
+	if !ok {
+		return DraftLock{}, false
+	}
+	if !now.Before(lock.ExpiresAt) {
+		delete(l.held, testID)
+		return DraftLock{}, false
+	}
+	return lock, true
+}
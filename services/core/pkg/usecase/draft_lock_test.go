@@ -0,0 +1,96 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_DraftLocking(t *testing.T) {
+	fx := fixtures.NewSchool().WithTeachers(2).WithStudents(1).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	service.SetDelegations(fx.Repo)
+	ctx := context.Background()
+	owner, coTeacher := fx.Teacher(0), fx.Teacher(1)
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Geometry",
+		TeacherID:  owner,
+		Questions:  []usecase.QuestionDraft{{Prompt: "Q1", Points: 5}},
+		StudentIDs: []domain.StudentID{fx.Student(0)},
+		Draft:      true,
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	if _, err := usecase.NewDelegationService(fx.Repo, fx.Repo, fx.Repo).Grant(ctx, usecase.DelegationInput{
+		TeacherID:  owner,
+		DelegateID: coTeacher,
+		ExpiresAt:  time.Now().Add(time.Hour),
+	}); err != nil {
+		t.Fatalf("Grant failed: %v", err)
+	}
+
+	lock, err := service.LockDraft(ctx, owner, test.ID, false)
+	if err != nil {
+		t.Fatalf("LockDraft failed: %v", err)
+	}
+	if lock.TeacherID != owner || !lock.ExpiresAt.After(lock.AcquiredAt) {
+		t.Fatalf("unexpected lock %+v", lock)
+	}
+
+	// The co-teacher sees who holds the draft and cannot edit it.
+	_, err = service.LockDraft(ctx, coTeacher, test.ID, false)
+	var locked *usecase.DraftLockedError
+	if !errors.As(err, &locked) || locked.Lock.TeacherID != owner || !errors.Is(err, errs.ErrDraftLocked) {
+		t.Fatalf("expected the owner's lock, got %v", err)
+	}
+	title := "Geometry II"
+	if _, err := service.UpdateTestDetails(ctx, coTeacher, test.ID, usecase.TestDetailsInput{Title: &title}); !errors.Is(err, errs.ErrDraftLocked) {
+		t.Fatalf("expected ErrDraftLocked on edit, got %v", err)
+	}
+	points := domain.Points(8)
+	if _, err := service.UpdateQuestion(ctx, coTeacher, test.ID, questions[0].ID, usecase.QuestionEdit{Points: &points}); !errors.Is(err, errs.ErrDraftLocked) {
+		t.Fatalf("expected ErrDraftLocked on question edit, got %v", err)
+	}
+	if err := service.UnlockDraft(ctx, coTeacher, test.ID); !errors.Is(err, errs.ErrDraftLocked) {
+		t.Fatalf("expected only the holder to unlock, got %v", err)
+	}
+	if _, err := service.UpdateTestDetails(ctx, owner, test.ID, usecase.TestDetailsInput{Title: &title}); err != nil {
+		t.Fatalf("expected the holder to edit, got %v", err)
+	}
+
+	// Stealing hands the draft over explicitly.
+	stolen, err := service.LockDraft(ctx, coTeacher, test.ID, true)
+	if err != nil || stolen.TeacherID != coTeacher {
+		t.Fatalf("expected the co-teacher to steal the lock, got %+v, %v", stolen, err)
+	}
+	if _, err := service.UpdateQuestion(ctx, owner, test.ID, questions[0].ID, usecase.QuestionEdit{Points: &points}); !errors.Is(err, errs.ErrDraftLocked) {
+		t.Fatalf("expected the previous holder to be locked out, got %v", err)
+	}
+	if err := service.UnlockDraft(ctx, coTeacher, test.ID); err != nil {
+		t.Fatalf("UnlockDraft failed: %v", err)
+	}
+	if current, err := service.GetDraftLock(ctx, owner, test.ID); err != nil || current != nil {
+		t.Fatalf("expected a free draft, got %+v, %v", current, err)
+	}
+
+	// Locks expire without renewal.
+	service.SetDraftLockTTL(time.Millisecond)
+	if _, err := service.LockDraft(ctx, coTeacher, test.ID, false); err != nil {
+		t.Fatalf("LockDraft failed: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, err := service.PublishTest(ctx, owner, test.ID); err != nil {
+		t.Fatalf("expected an expired lock to be ignored, got %v", err)
+	}
+	if _, err := service.LockDraft(ctx, owner, test.ID, false); !errors.Is(err, errs.ErrTestPublished) {
+		t.Fatalf("expected published tests to have no lock, got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"log"
+
+	"github.com/sky0621/go_work_sample/core/pkg/audit"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+// Webhook events published by the assessment workflow.
+const (
+	EventTestCreated     = "test.created"
+	EventTestPublished   = "test.published"
+	EventTestAssigned    = "test.assigned"
+	EventAnswerSubmitted = "answer.submitted"
+	EventTestSubmitted   = "test.submitted"
+	EventResultGraded    = "result.graded"
+)
+
+type testEvent struct {
+	TestID     string   `json:"test_id"`
+	TeacherID  string   `json:"teacher_id"`
+	Title      string   `json:"title"`
+	StudentIDs []string `json:"student_ids"`
+	Published  bool     `json:"published"`
+}
+
+type answerEvent struct {
+	AnswerID   string `json:"answer_id"`
+	TestID     string `json:"test_id"`
+	QuestionID string `json:"question_id"`
+	StudentID  string `json:"student_id"`
+}
+
+type resultEvent struct {
+	ResultID   string `json:"result_id"`
+	AnswerID   string `json:"answer_id"`
+	TestID     string `json:"test_id"`
+	QuestionID string `json:"question_id"`
+	StudentID  string `json:"student_id"`
+	Score      int    `json:"score"`
+	Completed  bool   `json:"completed"`
+}
+
+// publish queues a webhook event on a best-effort basis; failures never fail
+// the use case that raised them.
+func (s *AssessmentService) publish(event string, data any) {
+	if s.webhooks == nil {
+		return
+	}
+	if err := s.webhooks.Publish(event, data); err != nil {
+		log.Printf("publish %s: %v", event, err)
+	}
+}
+
+func (s *AssessmentService) publishGraded(input GradeInput, result *domain.Result) {
+	s.publish(EventResultGraded, resultEvent{
+		ResultID:   string(result.ID),
+		AnswerID:   string(result.AnswerID),
+		TestID:     string(input.TestID),
+		QuestionID: string(input.QuestionID),
+		StudentID:  string(input.StudentID),
+		Score:      int(result.Score),
+		Completed:  result.Completed,
+	})
+}
+
+// audit appends entry to the audit log, if there is one. A failed write is
+// logged and never fails the use case.
+func (s *AssessmentService) audit(ctx context.Context, entry domain.AuditEntry) {
+	recordAudit(ctx, s.auditLog, entry)
+}
+
+func recordAudit(ctx context.Context, trail *audit.Log, entry domain.AuditEntry) {
+	if trail == nil {
+		return
+	}
+	if err := trail.Record(ctx, entry); err != nil {
+		log.Printf("audit %s: %v", entry.Action, err)
+	}
+}
+
+// record applies a domain event to the read model, the search index and the
+// student event bus and queues it for the broker, if there are any. Like webhooks, a full
+// broker queue never fails the use case.
+func (s *AssessmentService) record(event domain.Event) {
+	if s.readModel != nil {
+		s.readModel.Apply(event)
+	}
+	if s.search != nil {
+		s.search.Apply(event)
+	}
+	if s.events != nil {
+		s.events.Apply(event)
+	}
+	if s.broker != nil {
+		if err := s.broker.Enqueue(event); err != nil {
+			log.Printf("queue event %T: %v", event, err)
+		}
+	}
+}
+
+func (s *AssessmentService) recordGraded(testID domain.TestID, questionID domain.QuestionID, studentID domain.StudentID, result *domain.Result) {
+	s.record(domain.ResultSaved{TestID: testID, QuestionID: questionID, StudentID: studentID, Result: *result})
+}
+
+func studentIDStrings(ids []domain.StudentID) []string {
+	out := make([]string, len(ids))
+	for i, sid := range ids {
+		out[i] = string(sid)
+	}
+	return out
+}
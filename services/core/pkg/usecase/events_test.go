@@ -0,0 +1,85 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/broker"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/events"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_PushesAssignmentsAndGrades(t *testing.T) {
+	fx := fixtures.NewSchool().Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	bus := events.NewBus()
+	service.SetEvents(bus)
+	ctx := context.Background()
+	teacher, student := fx.Teacher(0), fx.Student(0)
+	sub := bus.Subscribe(student)
+	defer sub.Close()
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Physics",
+		TeacherID:  teacher,
+		Questions:  []usecase.QuestionDraft{{Prompt: "Q1", Points: 5}},
+		StudentIDs: []domain.StudentID{student},
+		Draft:      true,
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	if len(sub.C) != 0 {
+		t.Fatalf("expected no event for a draft")
+	}
+	if _, err := service.PublishTest(ctx, teacher, test.ID); err != nil {
+		t.Fatalf("PublishTest failed: %v", err)
+	}
+	if e := <-sub.C; e.Kind != events.KindTestAssigned || e.TestID != test.ID || e.Title != "Physics" {
+		t.Fatalf("unexpected assignment event %+v", e)
+	}
+
+	if _, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: student, Response: "x"}); err != nil {
+		t.Fatalf("SubmitAnswer failed: %v", err)
+	}
+	if _, err := service.GradeAnswer(ctx, usecase.GradeInput{TeacherID: teacher, TestID: test.ID, QuestionID: questions[0].ID, StudentID: student, Score: 3}); err != nil {
+		t.Fatalf("GradeAnswer failed: %v", err)
+	}
+	if e := <-sub.C; e.Kind != events.KindResultSaved || e.QuestionID != questions[0].ID || e.Score != 3 {
+		t.Fatalf("unexpected grade event %+v", e)
+	}
+}
+
+func TestAssessmentService_PublishesDomainEventsToBroker(t *testing.T) {
+	fx := fixtures.NewSchool().Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	inProcess := broker.NewInProcess()
+	received := make(chan broker.Message, 4)
+	inProcess.Subscribe(func(msg broker.Message) { received <- msg })
+	queue := broker.NewQueue(inProcess, 8)
+	service.SetBroker(queue)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go queue.Run(ctx)
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Biology",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "Q1", Points: 5}},
+		StudentIDs: []domain.StudentID{fx.Student(0)},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	if _, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: fx.Student(0), Response: "x"}); err != nil {
+		t.Fatalf("SubmitAnswer failed: %v", err)
+	}
+
+	for _, want := range []string{broker.TypeTestCreated, broker.TypeAnswerSaved} {
+		if msg := <-received; msg.Type != want || msg.TestID != test.ID {
+			t.Fatalf("expected %s for %s, got %+v", want, test.ID, msg)
+		}
+	}
+}
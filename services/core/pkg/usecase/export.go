@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/export"
+)
+
+// CollectGradingPackage gathers questions, answers and results of a test
+// grouped per assigned student, ensuring teacher ownership.
+func (s *AssessmentService) CollectGradingPackage(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID) (*export.GradingPackage, error) {
+	ctx, span := s.trace(ctx, "CollectGradingPackage")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	if test == nil {
+		return nil, errs.ErrTestNotFound
+	}
+
+	questions, err := s.listQuestions(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := &export.GradingPackage{
+		Test:      *test,
+		Questions: questions,
+		Students:  make([]export.StudentPackage, 0, len(test.AssignedTo)),
+	}
+
+	snapshot, err := s.resultRepo.SnapshotGrading(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	answersOf := make(map[domain.StudentID][]domain.Answer, len(test.AssignedTo))
+	for _, ans := range snapshot.Answers {
+		answersOf[ans.StudentID] = append(answersOf[ans.StudentID], ans)
+	}
+	resultOf := make(map[domain.AnswerID]domain.Result, len(snapshot.Results))
+	for _, res := range snapshot.Results {
+		resultOf[res.AnswerID] = res
+	}
+
+	for _, studentID := range test.AssignedTo {
+		student, err := s.orgRepo.GetStudent(ctx, studentID)
+		if err != nil {
+			return nil, err
+		}
+		if student == nil {
+			return nil, errs.ErrStudentNotFound
+		}
+
+		answers := answersOf[studentID]
+		if answers == nil {
+			answers = []domain.Answer{}
+		}
+		results := make(map[domain.AnswerID]domain.Result, len(answers))
+		for _, ans := range answers {
+			if res, ok := resultOf[ans.ID]; ok {
+				results[ans.ID] = res
+			}
+		}
+
+		pkg.Students = append(pkg.Students, export.StudentPackage{
+			Student: *student,
+			Answers: answers,
+			Results: results,
+		})
+	}
+
+	return pkg, nil
+}
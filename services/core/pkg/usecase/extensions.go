@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sky0621/go_work_sample/core/pkg/audit"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+)
+
+// MaxExtensionReasonLength caps the characters of the reason given for a
+// deadline extension.
+const MaxExtensionReasonLength = 500
+
+// ExtensionInput grants each of StudentIDs until ClosesAt to sit a test,
+// for the given Reason.
+type ExtensionInput struct {
+	StudentIDs []domain.StudentID
+	ClosesAt   time.Time
+	Reason     string
+}
+
+// ExtendTest moves the close time of a test later for every student, for
+// example when a fire drill cut the exam short. Only closing later is an
+// extension; SetTestWindow reschedules freely but leaves no reason in the
+// audit trail.
+func (s *AssessmentService) ExtendTest(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID, closesAt time.Time, reason string) (*domain.Test, error) {
+	ctx, span := s.trace(ctx, "ExtendTest")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	reason, closesAt, err = checkExtension(*test, closesAt, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := *test.ClosesAt
+	test.ClosesAt = &closesAt
+	test.UpdatedAt = time.Now().UTC()
+	if err := s.testRepo.UpdateTest(ctx, test); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, audit.TestExtended(teacherID, testID, previous, closesAt, reason))
+	return test, nil
+}
+
+// GrantExtensions keeps a test open longer for individual students. An
+// extension replaces the one the student already had. Once the test itself
+// is extended past an extension, the later close time wins.
+func (s *AssessmentService) GrantExtensions(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID, input ExtensionInput) (*domain.Test, error) {
+	ctx, span := s.trace(ctx, "GrantExtensions")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	if len(input.StudentIDs) == 0 {
+		return nil, errs.ErrInvalidExtension
+	}
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	reason, closesAt, err := checkExtension(*test, input.ClosesAt, input.Reason)
+	if err != nil {
+		return nil, err
+	}
+	assigned := make(map[domain.StudentID]bool, len(test.AssignedTo))
+	for _, studentID := range test.AssignedTo {
+		assigned[studentID] = true
+	}
+	for _, studentID := range input.StudentIDs {
+		if !assigned[studentID] {
+			return nil, errs.ErrStudentNotAssigned
+		}
+	}
+
+	now := time.Now().UTC()
+	granted := make([]domain.DeadlineExtension, 0, len(input.StudentIDs))
+	previous := make(map[domain.StudentID]domain.DeadlineExtension)
+	extensions := make([]domain.DeadlineExtension, 0, len(test.Extensions)+len(input.StudentIDs))
+	for _, studentID := range input.StudentIDs {
+		if _, ok := previous[studentID]; ok {
+			continue
+		}
+		old, _ := test.Extension(studentID)
+		previous[studentID] = old
+		granted = append(granted, domain.DeadlineExtension{
+			StudentID: studentID,
+			ClosesAt:  closesAt,
+			Reason:    reason,
+			GrantedBy: teacherID,
+			GrantedAt: now,
+		})
+	}
+	for _, ext := range test.Extensions {
+		if _, replaced := previous[ext.StudentID]; !replaced {
+			extensions = append(extensions, ext)
+		}
+	}
+	test.Extensions = append(extensions, granted...)
+	test.UpdatedAt = now
+	if err := s.testRepo.UpdateTest(ctx, test); err != nil {
+		return nil, err
+	}
+	for _, ext := range granted {
+		var replaced *domain.DeadlineExtension
+		if old := previous[ext.StudentID]; old.StudentID != "" {
+			replaced = &old
+		}
+		s.audit(ctx, audit.ExtensionGranted(*test, ext, replaced))
+	}
+	return test, nil
+}
+
+// RevokeExtension withdraws a student's extension, so the test closes for
+// them with everyone else's.
+func (s *AssessmentService) RevokeExtension(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID, studentID domain.StudentID) (*domain.Test, error) {
+	ctx, span := s.trace(ctx, "RevokeExtension")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	ext, ok := test.Extension(studentID)
+	if !ok {
+		return nil, errs.ErrExtensionNotFound
+	}
+
+	extensions := make([]domain.DeadlineExtension, 0, len(test.Extensions)-1)
+	for _, other := range test.Extensions {
+		if other.StudentID != studentID {
+			extensions = append(extensions, other)
+		}
+	}
+	test.Extensions = extensions
+	test.UpdatedAt = time.Now().UTC()
+	if err := s.testRepo.UpdateTest(ctx, test); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, audit.ExtensionRevoked(teacherID, *test, ext))
+	return test, nil
+}
+
+// checkExtension validates a new close time and reason for a test and
+// returns them normalized. Only a test with a close time can be extended,
+// and only to a later one.
+func checkExtension(test domain.Test, closesAt time.Time, reason string) (string, time.Time, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || utf8.RuneCountInString(reason) > MaxExtensionReasonLength {
+		return "", time.Time{}, errs.ErrInvalidExtension
+	}
+	closesAt = closesAt.UTC()
+	if test.ClosesAt == nil || !closesAt.After(*test.ClosesAt) {
+		return "", time.Time{}, errs.ErrInvalidExtension
+	}
+	return reason, closesAt, nil
+}
@@ -0,0 +1,108 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/audit"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_DeadlineExtensions(t *testing.T) {
+	fx := fixtures.NewSchool().WithStudents(2).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	log := audit.NewLog(fx.Repo)
+	service.SetAuditLog(log)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	opens, closes := now.Add(-2*time.Hour), now.Add(-time.Hour)
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Quiz",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "Q1", Points: 10}},
+		StudentIDs: []domain.StudentID{fx.Student(0), fx.Student(1)},
+		OpensAt:    &opens,
+		ClosesAt:   &closes,
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	answer := func(student int) error {
+		_, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: fx.Student(student), Response: "x"})
+		return err
+	}
+	if err := answer(0); err != errs.ErrTestClosed {
+		t.Fatalf("expected ErrTestClosed before any extension, got %v", err)
+	}
+
+	for name, in := range map[string]usecase.ExtensionInput{
+		"no reason":    {StudentIDs: []domain.StudentID{fx.Student(0)}, ClosesAt: now.Add(time.Hour)},
+		"earlier":      {StudentIDs: []domain.StudentID{fx.Student(0)}, ClosesAt: closes.Add(-time.Minute), Reason: "ill"},
+		"no students":  {ClosesAt: now.Add(time.Hour), Reason: "ill"},
+		"not assigned": {StudentIDs: []domain.StudentID{"student-999"}, ClosesAt: now.Add(time.Hour), Reason: "ill"},
+	} {
+		if _, err := service.GrantExtensions(ctx, fx.Teacher(0), test.ID, in); err != errs.ErrInvalidExtension && err != errs.ErrStudentNotAssigned {
+			t.Errorf("%s: expected the extension to be refused, got %v", name, err)
+		}
+	}
+
+	extended, err := service.GrantExtensions(ctx, fx.Teacher(0), test.ID, usecase.ExtensionInput{
+		StudentIDs: []domain.StudentID{fx.Student(0)},
+		ClosesAt:   now.Add(time.Hour),
+		Reason:     " Hospital appointment ",
+	})
+	if err != nil {
+		t.Fatalf("GrantExtensions failed: %v", err)
+	}
+	if ext, ok := extended.Extension(fx.Student(0)); !ok || ext.Reason != "Hospital appointment" || ext.GrantedBy != fx.Teacher(0) {
+		t.Fatalf("expected the extension on the test, got %+v", extended.Extensions)
+	}
+	if err := answer(0); err != nil {
+		t.Fatalf("expected the extended student to answer, got %v", err)
+	}
+	if err := answer(1); err != errs.ErrTestClosed {
+		t.Fatalf("expected ErrTestClosed for the other student, got %v", err)
+	}
+
+	if _, err := service.ExtendTest(ctx, fx.Teacher(0), test.ID, closes, "drill"); err != errs.ErrInvalidExtension {
+		t.Fatalf("expected ErrInvalidExtension for an unchanged close time, got %v", err)
+	}
+	if _, err := service.ExtendTest(ctx, fx.Teacher(0), test.ID, now.Add(30*time.Minute), "Fire drill"); err != nil {
+		t.Fatalf("ExtendTest failed: %v", err)
+	}
+	if err := answer(1); err != nil {
+		t.Fatalf("expected every student to answer after ExtendTest, got %v", err)
+	}
+
+	if _, err := service.RevokeExtension(ctx, fx.Teacher(0), test.ID, fx.Student(1)); err != errs.ErrExtensionNotFound {
+		t.Fatalf("expected ErrExtensionNotFound, got %v", err)
+	}
+	revoked, err := service.RevokeExtension(ctx, fx.Teacher(0), test.ID, fx.Student(0))
+	if err != nil || len(revoked.Extensions) != 0 {
+		t.Fatalf("RevokeExtension failed: %+v, %v", revoked, err)
+	}
+
+	entries, err := log.Query(ctx, repository.AuditFilter{TestID: test.ID}, repository.PageRequest{Limit: 50})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	actions := make(map[domain.AuditAction]domain.AuditEntry)
+	for _, e := range entries.Items {
+		actions[e.Action] = e
+	}
+	if e := actions[domain.AuditExtensionGranted]; e.StudentID != fx.Student(0) || e.Reason != "Hospital appointment" || e.NewClosesAt == nil {
+		t.Fatalf("expected the grant in the audit trail, got %+v", e)
+	}
+	if e := actions[domain.AuditTestExtended]; e.Reason != "Fire drill" || e.OldClosesAt == nil || !e.OldClosesAt.Equal(closes) {
+		t.Fatalf("expected the test extension in the audit trail, got %+v", e)
+	}
+	if _, ok := actions[domain.AuditExtensionRevoked]; !ok {
+		t.Fatalf("expected the revocation in the audit trail, got %+v", entries.Items)
+	}
+}
@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/export"
+)
+
+// ExportGradeSheet writes the grade sheet of a test to w as CSV: a row per
+// assigned student, ordered by name, with the score of every question, the
+// total and the feedback given. Answers and results are loaded one student at
+// a time, so the sheet is streamed rather than built in memory. Nothing is
+// written to w when the teacher may not access the test.
+func (s *AssessmentService) ExportGradeSheet(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID, w io.Writer) error {
+	ctx, span := s.trace(ctx, "ExportGradeSheet")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return err
+	}
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return err
+	}
+	questions, err := s.listQuestions(ctx, testID)
+	if err != nil {
+		return err
+	}
+	students := make([]domain.Student, 0, len(test.AssignedTo))
+	for _, studentID := range test.AssignedTo {
+		student, err := s.orgRepo.GetStudent(ctx, studentID)
+		if err != nil {
+			return err
+		}
+		if student == nil {
+			student = &domain.Student{ID: studentID}
+		}
+		students = append(students, *student)
+	}
+	sort.SliceStable(students, func(i, j int) bool {
+		if students[i].Name != students[j].Name {
+			return students[i].Name < students[j].Name
+		}
+		return students[i].ID < students[j].ID
+	})
+
+	sheet, err := export.NewGradeSheetWriter(w, questions)
+	if err != nil {
+		return err
+	}
+	for _, student := range students {
+		row, err := s.gradeSheetRow(ctx, testID, questions, student)
+		if err != nil {
+			return err
+		}
+		if err := sheet.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gradeSheetRow collects one student's scores and feedback. Feedback on
+// several questions is joined line by line, each prefixed with its question.
+func (s *AssessmentService) gradeSheetRow(ctx context.Context, testID domain.TestID, questions []domain.Question, student domain.Student) (export.GradeSheetRow, error) {
+	answers, err := s.answerRepo.ListAnswers(ctx, testID, student.ID)
+	if err != nil {
+		return export.GradeSheetRow{}, err
+	}
+	results, err := s.resultRepo.ListResultsByStudent(ctx, testID, student.ID)
+	if err != nil {
+		return export.GradeSheetRow{}, err
+	}
+	byAnswer := make(map[domain.AnswerID]domain.Result, len(results))
+	for _, res := range results {
+		byAnswer[res.AnswerID] = res
+	}
+	byQuestion := make(map[domain.QuestionID]domain.Result, len(answers))
+	for _, ans := range answers {
+		if res, ok := byAnswer[ans.ID]; ok {
+			byQuestion[ans.QuestionID] = res
+		}
+	}
+
+	row := export.GradeSheetRow{
+		StudentID:   string(student.ID),
+		StudentName: student.Name,
+		Scores:      make([]*int, len(questions)),
+	}
+	var feedback []string
+	for i, q := range questions {
+		res, ok := byQuestion[q.ID]
+		if !ok {
+			continue
+		}
+		score := int(res.Score)
+		row.Scores[i] = &score
+		row.Total += score
+		if text := strings.TrimSpace(res.Feedback); text != "" {
+			feedback = append(feedback, "q"+strconv.Itoa(q.Sequence)+": "+text)
+		}
+	}
+	row.Feedback = strings.Join(feedback, "\n")
+	return row, nil
+}
@@ -0,0 +1,78 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_ExportGradeSheet(t *testing.T) {
+	fx := fixtures.NewSchool().WithStudents(3).WithTeachers(2).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Algebra",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "Q1", Points: 5}, {Prompt: "Q2", Points: 5}},
+		StudentIDs: []domain.StudentID{fx.Student(2), fx.Student(1), fx.Student(0)},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	for _, q := range questions {
+		if _, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: q.ID, StudentID: fx.Student(0), Response: "x"}); err != nil {
+			t.Fatalf("SubmitAnswer failed: %v", err)
+		}
+	}
+	if _, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[1].ID, StudentID: fx.Student(1), Response: "y"}); err != nil {
+		t.Fatalf("SubmitAnswer failed: %v", err)
+	}
+	grades := []usecase.GradeInput{
+		{QuestionID: questions[0].ID, StudentID: fx.Student(0), Score: 4, Feedback: "Good", Completed: true},
+		{QuestionID: questions[1].ID, StudentID: fx.Student(0), Score: 3, Feedback: "Check the sign", Completed: true},
+	}
+	for _, g := range grades {
+		g.TeacherID, g.TestID = fx.Teacher(0), test.ID
+		if _, err := service.GradeAnswer(ctx, g); err != nil {
+			t.Fatalf("GradeAnswer failed: %v", err)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := service.ExportGradeSheet(ctx, fx.Teacher(1), test.ID, &out); err != errs.ErrForbiddenTeacher {
+		t.Fatalf("expected another teacher to be refused, got %v", err)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("expected nothing written for a refused export, got %q", out.String())
+	}
+	if err := service.ExportGradeSheet(ctx, fx.Teacher(0), test.ID, &out); err != nil {
+		t.Fatalf("ExportGradeSheet failed: %v", err)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(out.String(), "\ufeff"))).ReadAll()
+	if err != nil {
+		t.Fatalf("grade sheet is not valid CSV: %v", err)
+	}
+	want := [][]string{
+		{"student_id", "student_name", "q1", "q2", "total", "feedback"},
+		{string(fx.Student(0)), "Student 1", "4", "3", "7", "q1: Good\nq2: Check the sign"},
+		{string(fx.Student(1)), "Student 2", "", "", "0", ""},
+		{string(fx.Student(2)), "Student 3", "", "", "0", ""},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("expected %d records, got %q", len(want), records)
+	}
+	for i := range want {
+		if strings.Join(records[i], "|") != strings.Join(want[i], "|") {
+			t.Fatalf("record %d: expected %q, got %q", i, want[i], records[i])
+		}
+	}
+}
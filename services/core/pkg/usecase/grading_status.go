@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// TestGradingStatus tells how far the grading of one test has got.
+type TestGradingStatus struct {
+	Test    domain.Test
+	Answers int
+	Graded  int
+}
+
+// Ungraded returns the number of answers still waiting for a result.
+func (s TestGradingStatus) Ungraded() int {
+	return s.Answers - s.Graded
+}
+
+// GradingStatusService gives operators a read-only view of the tests of any
+// teacher with their grading progress, without teacher ownership checks.
+type GradingStatusService struct {
+	orgRepo    repository.OrganizationReader
+	testRepo   repository.TestReader
+	resultRepo repository.ResultReader
+}
+
+// NewGradingStatusService constructs a grading status service.
+func NewGradingStatusService(org repository.OrganizationReader, tests repository.TestReader, results repository.ResultReader) *GradingStatusService {
+	return &GradingStatusService{orgRepo: org, testRepo: tests, resultRepo: results}
+}
+
+// ListTeacherTests returns a page of the teacher's tests, each with the
+// number of answers received and graded so far.
+func (s *GradingStatusService) ListTeacherTests(ctx context.Context, teacherID domain.TeacherID, page repository.PageRequest) (repository.Page[TestGradingStatus], error) {
+	teacher, err := s.orgRepo.GetTeacher(ctx, teacherID)
+	if err != nil {
+		return repository.Page[TestGradingStatus]{}, err
+	}
+	if teacher == nil {
+		return repository.Page[TestGradingStatus]{}, errs.ErrTeacherNotFound
+	}
+
+	tests, err := s.testRepo.ListTestsByTeacher(ctx, teacherID, page)
+	if err != nil {
+		return repository.Page[TestGradingStatus]{}, err
+	}
+	items := make([]TestGradingStatus, 0, len(tests.Items))
+	for _, test := range tests.Items {
+		status, err := s.status(ctx, test)
+		if err != nil {
+			return repository.Page[TestGradingStatus]{}, err
+		}
+		items = append(items, status)
+	}
+	return repository.Page[TestGradingStatus]{Items: items, NextCursor: tests.NextCursor}, nil
+}
+
+func (s *GradingStatusService) status(ctx context.Context, test domain.Test) (TestGradingStatus, error) {
+	snapshot, err := s.resultRepo.SnapshotGrading(ctx, test.ID)
+	if err != nil {
+		return TestGradingStatus{}, err
+	}
+	graded := make(map[domain.AnswerID]struct{}, len(snapshot.Results))
+	for _, res := range snapshot.Results {
+		graded[res.AnswerID] = struct{}{}
+	}
+	status := TestGradingStatus{Test: test, Answers: len(snapshot.Answers)}
+	for _, ans := range snapshot.Answers {
+		if _, ok := graded[ans.ID]; ok {
+			status.Graded++
+		}
+	}
+	return status, nil
+}
@@ -0,0 +1,54 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestGradingStatusService_ListTeacherTests(t *testing.T) {
+	fx := fixtures.NewSchool().WithStudents(2).Build()
+	assessments := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	statuses := usecase.NewGradingStatusService(fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	students := []domain.StudentID{fx.Student(0), fx.Student(1)}
+	test, questions, err := assessments.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Essay",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "Why is the sky blue?", Points: 5}},
+		StudentIDs: students,
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	for _, sid := range students {
+		if _, err := assessments.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: sid, Response: "scattering"}); err != nil {
+			t.Fatalf("SubmitAnswer failed: %v", err)
+		}
+	}
+	if _, err := assessments.GradeAnswer(ctx, usecase.GradeInput{TeacherID: fx.Teacher(0), TestID: test.ID, QuestionID: questions[0].ID, StudentID: students[0], Score: 4, Completed: true}); err != nil {
+		t.Fatalf("GradeAnswer failed: %v", err)
+	}
+
+	page, err := statuses.ListTeacherTests(ctx, fx.Teacher(0), repository.All)
+	if err != nil {
+		t.Fatalf("ListTeacherTests failed: %v", err)
+	}
+	if len(page.Items) != 1 {
+		t.Fatalf("expected one test, got %+v", page.Items)
+	}
+	status := page.Items[0]
+	if status.Test.ID != test.ID || status.Answers != 2 || status.Graded != 1 || status.Ungraded() != 1 {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	if _, err := statuses.ListTeacherTests(ctx, "missing", repository.All); err != errs.ErrTeacherNotFound {
+		t.Fatalf("expected ErrTeacherNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// InboxService exposes a user's in-app notifications.
+type InboxService struct {
+	notificationRepo repository.NotificationRepository
+}
+
+// NewInboxService constructs an inbox service.
+func NewInboxService(notifications repository.NotificationRepository) *InboxService {
+	return &InboxService{notificationRepo: notifications}
+}
+
+// Inbox is a page of a user's notifications, newest first, with the unread
+// count across the whole inbox.
+type Inbox struct {
+	Notifications []domain.Notification
+	NextCursor    string
+	Unread        int
+}
+
+// List returns a page of the inbox of the given user.
+func (s *InboxService) List(ctx context.Context, role domain.Role, recipientID string, page repository.PageRequest) (*Inbox, error) {
+	notifications, err := s.notificationRepo.ListNotifications(ctx, role, recipientID, page)
+	if err != nil {
+		return nil, err
+	}
+	all, err := repository.Collect(s.notificationRepo.ListNotifications(ctx, role, recipientID, repository.All))
+	if err != nil {
+		return nil, err
+	}
+
+	inbox := &Inbox{Notifications: notifications.Items, NextCursor: notifications.NextCursor}
+	for _, n := range all {
+		if n.ReadAt == nil {
+			inbox.Unread++
+		}
+	}
+	return inbox, nil
+}
+
+// MarkRead marks the given notifications as read, or every notification when
+// ids is empty.
+func (s *InboxService) MarkRead(ctx context.Context, role domain.Role, recipientID string, ids []domain.NotificationID) error {
+	return s.notificationRepo.MarkNotificationsRead(ctx, role, recipientID, ids, time.Now().UTC())
+}
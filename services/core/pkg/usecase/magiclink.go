@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/magiclink"
+	"github.com/sky0621/go_work_sample/core/pkg/notify"
+	"github.com/sky0621/go_work_sample/core/pkg/ratelimit"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// MagicLinkSettings configures passwordless sign-in.
+type MagicLinkSettings struct {
+	// BaseURL is the sign-in page the emailed link opens.
+	BaseURL    string
+	LinkTTL    time.Duration
+	SessionTTL time.Duration
+	// PerEmail and PerClient cap requests per hour for one address and for
+	// one client.
+	PerEmail  int
+	PerClient int
+}
+
+// MagicLinkService signs students and guardians in without a password: it
+// emails short-lived links and exchanges each link once for an access token.
+// Used links are remembered in memory only; a restart forgets them, but
+// links expire within the hour. Invitations, which live longer, are
+// remembered with the student instead.
+type MagicLinkService struct {
+	orgRepo  repository.OrganizationRepository
+	links    *magiclink.Signer
+	sessions *auth.Signer
+	mailer   notify.Mailer
+	limiter  *ratelimit.Limiter
+	settings MagicLinkSettings
+	devices  *DeviceService
+
+	// mu guards the rate limits in settings and redeemed.
+	mu sync.Mutex
+	// redeemed maps the IDs of used link tokens to their expiry, after which
+	// the signature check rejects them anyway.
+	redeemed map[string]time.Time
+}
+
+// NewMagicLinkService constructs a magic-link service.
+func NewMagicLinkService(org repository.OrganizationRepository, links *magiclink.Signer, sessions *auth.Signer, mailer notify.Mailer, settings MagicLinkSettings) *MagicLinkService {
+	return &MagicLinkService{
+		orgRepo:  org,
+		links:    links,
+		sessions: sessions,
+		mailer:   mailer,
+		limiter:  ratelimit.NewLimiter(),
+		settings: settings,
+		redeemed: make(map[string]time.Time),
+	}
+}
+
+// SetRateLimits changes the hourly caps per address and per client, for
+// example when the runtime configuration is reloaded.
+func (s *MagicLinkService) SetRateLimits(perEmail, perClient int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settings.PerEmail, s.settings.PerClient = perEmail, perClient
+}
+
+// SetDevices records every sign-in with devices so it can be listed and
+// revoked later.
+func (s *MagicLinkService) SetDevices(devices *DeviceService) {
+	s.devices = devices
+}
+
+func (s *MagicLinkService) rateLimits() (perEmail, perClient int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.settings.PerEmail, s.settings.PerClient
+}
+
+// RequestLink emails a sign-in link for every student whose own or guardian
+// email is email, one link per student and role. Unknown addresses are
+// accepted silently so the endpoint does not reveal who is registered.
+// client identifies the caller, such as its IP address, for throttling.
+func (s *MagicLinkService) RequestLink(ctx context.Context, email, client string) error {
+	email = strings.TrimSpace(email)
+	if !domain.ValidEmail(email) {
+		return errs.ErrInvalidEmail
+	}
+	perEmail, perClient := s.rateLimits()
+	if !s.limiter.Allow("request-client:"+client, perClient, time.Hour) ||
+		!s.limiter.Allow("request-email:"+strings.ToLower(email), perEmail, time.Hour) {
+		return errs.ErrTooManyRequests
+	}
+
+	students, err := s.orgRepo.FindStudentsByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	var lines []string
+	for _, st := range students {
+		if strings.EqualFold(st.Email, email) {
+			link, err := s.link(domain.RoleStudent, st.ID)
+			if err != nil {
+				return err
+			}
+			lines = append(lines, fmt.Sprintf("Sign in as %s: %s", studentName(st), link))
+		}
+		if strings.EqualFold(st.GuardianEmail, email) {
+			link, err := s.link(domain.RoleGuardian, st.ID)
+			if err != nil {
+				return err
+			}
+			lines = append(lines, fmt.Sprintf("Follow %s as their guardian: %s", studentName(st), link))
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	body := strings.Join(lines, "\n") + fmt.Sprintf("\n\nLinks work once and expire in %s. If you did not ask to sign in, ignore this email.", s.settings.LinkTTL)
+	return s.mailer.Send(ctx, email, "Your sign-in link", body)
+}
+
+// Redeem exchanges a link token for an access token. Each link works once
+// and only until it expires; the student it names must still exist, and an
+// invitation must still be the student's outstanding one. device is the user
+// agent signing in.
+func (s *MagicLinkService) Redeem(ctx context.Context, token, client, device string) (string, auth.Claims, error) {
+	_, perClient := s.rateLimits()
+	if !s.limiter.Allow("redeem-client:"+client, perClient, time.Hour) {
+		return "", auth.Claims{}, errs.ErrTooManyRequests
+	}
+	claims, err := s.links.Verify(strings.TrimSpace(token))
+	if err != nil || (claims.Role != domain.RoleStudent && claims.Role != domain.RoleGuardian) {
+		return "", auth.Claims{}, errs.ErrInvalidMagicLink
+	}
+	if !s.markRedeemed(claims) {
+		return "", auth.Claims{}, errs.ErrInvalidMagicLink
+	}
+
+	student, err := s.orgRepo.GetStudent(ctx, domain.StudentID(claims.Subject))
+	if err != nil {
+		return "", auth.Claims{}, err
+	}
+	if student == nil {
+		return "", auth.Claims{}, errs.ErrInvalidMagicLink
+	}
+	if claims.Invitation {
+		if claims.Role != domain.RoleStudent || student.InvitationID != claims.TokenID {
+			return "", auth.Claims{}, errs.ErrInvalidMagicLink
+		}
+		student.InvitationID = ""
+		if err := s.orgRepo.UpdateStudent(ctx, student); err != nil {
+			return "", auth.Claims{}, err
+		}
+	}
+	access, issued, err := s.sessions.Issue(auth.Principal{Role: claims.Role, ID: claims.Subject}, s.settings.SessionTTL)
+	if err != nil {
+		return "", auth.Claims{}, err
+	}
+	if s.devices != nil {
+		if err := s.devices.Record(ctx, issued, device, client); err != nil {
+			return "", auth.Claims{}, err
+		}
+	}
+	return access, issued, nil
+}
+
+func (s *MagicLinkService) link(role domain.Role, studentID domain.StudentID) (string, error) {
+	token, _, err := s.links.Issue(role, string(studentID), s.settings.LinkTTL)
+	if err != nil {
+		return "", err
+	}
+	return signInURL(s.settings.BaseURL, token)
+}
+
+// signInURL is the sign-in page at baseURL carrying a link token.
+func signInURL(baseURL, token string) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// markRedeemed records the token as used and reports whether it was unused.
+func (s *MagicLinkService) markRedeemed(claims magiclink.Claims) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	for tokenID, expiresAt := range s.redeemed {
+		if !now.Before(expiresAt) {
+			delete(s.redeemed, tokenID)
+		}
+	}
+	if _, used := s.redeemed[claims.TokenID]; used {
+		return false
+	}
+	s.redeemed[claims.TokenID] = claims.ExpiresAt
+	return true
+}
+
+func studentName(st domain.Student) string {
+	if st.DisplayName != "" {
+		return st.DisplayName
+	}
+	return st.Name
+}
@@ -0,0 +1,126 @@
+package usecase_test
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/magiclink"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+type sentMail struct {
+	to, body string
+}
+
+type capturingMailer struct {
+	mu   sync.Mutex
+	sent []sentMail
+}
+
+func (m *capturingMailer) Send(_ context.Context, to, _, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, sentMail{to: to, body: body})
+	return nil
+}
+
+var linkPattern = regexp.MustCompile(`https?://\S+`)
+
+// tokensIn returns the link tokens in the email body, in order.
+func tokensIn(t *testing.T, body string) []string {
+	t.Helper()
+	var tokens []string
+	for _, raw := range linkPattern.FindAllString(body, -1) {
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatalf("invalid link %q: %v", raw, err)
+		}
+		tokens = append(tokens, u.Query().Get("token"))
+	}
+	return tokens
+}
+
+func TestMagicLinkService(t *testing.T) {
+	fx := fixtures.NewSchool().WithStudents(2).Build()
+	ctx := context.Background()
+
+	student, err := fx.Repo.GetStudent(ctx, fx.Student(0))
+	if err != nil || student == nil {
+		t.Fatalf("GetStudent failed: %v", err)
+	}
+	student.GuardianEmail = "parent@example.com"
+	if err := fx.Repo.UpdateStudent(ctx, student); err != nil {
+		t.Fatalf("UpdateStudent failed: %v", err)
+	}
+
+	mailer := &capturingMailer{}
+	sessions := auth.NewSigner("session-secret")
+	service := usecase.NewMagicLinkService(fx.Repo, magiclink.NewSigner("link-secret"), sessions, mailer, usecase.MagicLinkSettings{
+		BaseURL:    "https://example.com/sign-in",
+		LinkTTL:    15 * time.Minute,
+		SessionTTL: time.Hour,
+		PerEmail:   2,
+		PerClient:  10,
+	})
+
+	if err := service.RequestLink(ctx, "not-an-email", "client"); err != errs.ErrInvalidEmail {
+		t.Fatalf("expected ErrInvalidEmail, got %v", err)
+	}
+	if err := service.RequestLink(ctx, "nobody@example.com", "client"); err != nil {
+		t.Fatalf("RequestLink for unknown address failed: %v", err)
+	}
+	if len(mailer.sent) != 0 {
+		t.Fatalf("expected no email for unknown address, got %d", len(mailer.sent))
+	}
+
+	if err := service.RequestLink(ctx, "Parent@Example.com", "client"); err != nil {
+		t.Fatalf("RequestLink failed: %v", err)
+	}
+	if len(mailer.sent) != 1 {
+		t.Fatalf("expected one email, got %d", len(mailer.sent))
+	}
+	tokens := tokensIn(t, mailer.sent[0].body)
+	if len(tokens) != 1 {
+		t.Fatalf("expected one link, got %d", len(tokens))
+	}
+
+	access, claims, err := service.Redeem(ctx, tokens[0], "client", "test-agent")
+	if err != nil {
+		t.Fatalf("Redeem failed: %v", err)
+	}
+	if claims.Role != domain.RoleGuardian || claims.Subject != string(fx.Student(0)) {
+		t.Fatalf("unexpected claims %+v", claims)
+	}
+	if verified, err := sessions.Verify(access); err != nil || verified.Role != domain.RoleGuardian {
+		t.Fatalf("expected a guardian access token, got %+v (%v)", verified, err)
+	}
+	if _, _, err := service.Redeem(ctx, tokens[0], "client", "test-agent"); err != errs.ErrInvalidMagicLink {
+		t.Fatalf("expected ErrInvalidMagicLink for a used link, got %v", err)
+	}
+	if _, _, err := service.Redeem(ctx, tokens[0]+"x", "client", "test-agent"); err != errs.ErrInvalidMagicLink {
+		t.Fatalf("expected ErrInvalidMagicLink for a tampered link, got %v", err)
+	}
+
+	if err := service.RequestLink(ctx, student.Email, "client"); err != nil {
+		t.Fatalf("RequestLink failed: %v", err)
+	}
+	tokens = tokensIn(t, mailer.sent[len(mailer.sent)-1].body)
+	if _, claims, err = service.Redeem(ctx, tokens[0], "client", "test-agent"); err != nil || claims.Role != domain.RoleStudent {
+		t.Fatalf("expected a student session, got %+v (%v)", claims, err)
+	}
+
+	if err := service.RequestLink(ctx, "parent@example.com", "client"); err != nil {
+		t.Fatalf("RequestLink failed: %v", err)
+	}
+	if err := service.RequestLink(ctx, "parent@example.com", "client"); err != errs.ErrTooManyRequests {
+		t.Fatalf("expected ErrTooManyRequests, got %v", err)
+	}
+}
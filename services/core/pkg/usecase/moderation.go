@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/audit"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// moderationMinHistory is the fewest earlier tests a student needs before a
+// score can stand out against their average.
+const moderationMinHistory = 2
+
+// HeldResult is a result held for review, with the answer it grades.
+type HeldResult struct {
+	Result     domain.Result
+	QuestionID domain.QuestionID
+	StudentID  domain.StudentID
+}
+
+// ListHeldResults lists the results of the test held for review, oldest
+// first, ensuring teacher ownership.
+func (s *AssessmentService) ListHeldResults(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID) ([]HeldResult, error) {
+	ctx, span := s.trace(ctx, "ListHeldResults")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	snapshot, err := s.resultRepo.SnapshotGrading(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	answers := make(map[domain.AnswerID]domain.Answer, len(snapshot.Answers))
+	for _, a := range snapshot.Answers {
+		answers[a.ID] = a
+	}
+	held := make([]HeldResult, 0)
+	for _, res := range snapshot.Results {
+		if res.Hold == nil {
+			continue
+		}
+		a := answers[res.AnswerID]
+		held = append(held, HeldResult{Result: res, QuestionID: a.QuestionID, StudentID: a.StudentID})
+	}
+	sort.SliceStable(held, func(i, j int) bool {
+		return held[i].Result.Hold.FlaggedAt.Before(held[j].Result.Hold.FlaggedAt)
+	})
+	return held, nil
+}
+
+// ReleaseHeldResult releases a result held for review to the student as
+// graded, ensuring teacher ownership.
+func (s *AssessmentService) ReleaseHeldResult(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID, resultID domain.ResultID) (*HeldResult, error) {
+	ctx, span := s.trace(ctx, "ReleaseHeldResult")
+	defer span.End()
+
+	held, err := s.ListHeldResults(ctx, teacherID, testID)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range held {
+		if h.Result.ID != resultID {
+			continue
+		}
+		result := h.Result
+		result.Hold = nil
+		result.Completed = true
+		result.UpdatedAt = time.Now().UTC()
+		if err := s.resultRepo.SaveResult(ctx, &result); err != nil {
+			return nil, err
+		}
+		input := GradeInput{TeacherID: teacherID, TestID: testID, QuestionID: h.QuestionID, StudentID: h.StudentID, Score: result.Score, Completed: true}
+		s.stats.entries.Invalidate(testID)
+		s.recordGraded(testID, h.QuestionID, h.StudentID, &result)
+		s.audit(ctx, audit.ResultReleased(teacherID, testID, h.StudentID, result))
+		s.notifyResultReleased(ctx, input)
+		s.publishGraded(input, &result)
+		h.Result = result
+		return &h, nil
+	}
+	return nil, errs.ErrResultNotHeld
+}
+
+// moderate decides whether releasing result, the grade of answer, needs
+// review under the moderation settings of the student's school, returning
+// nil when it does not. The checks only run once the student's answers to
+// the test are all graded.
+func (s *AssessmentService) moderate(ctx context.Context, answer *domain.Answer, result domain.Result, now time.Time) (*domain.ResultHold, error) {
+	school, err := s.schoolOfStudent(ctx, answer.StudentID)
+	if err != nil || school == nil {
+		return nil, err
+	}
+	settings := school.Settings.Moderation
+	if settings.ScoreJump == 0 && settings.PerfectClass == 0 {
+		return nil, nil
+	}
+
+	test, err := s.testRepo.GetTest(ctx, answer.TestID)
+	if err != nil || test == nil {
+		return nil, err
+	}
+	questions, err := s.testRepo.ListQuestions(ctx, test.ID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := s.resultRepo.SnapshotGrading(ctx, test.ID)
+	if err != nil {
+		return nil, err
+	}
+	scores := make(map[domain.AnswerID]domain.Score, len(snapshot.Results)+1)
+	for _, res := range snapshot.Results {
+		scores[res.AnswerID] = res.Score
+	}
+	scores[answer.ID] = result.Score
+	totals := gradedTotals(snapshot.Answers, scores)
+	total, graded := totals[answer.StudentID]
+	if !graded {
+		return nil, nil
+	}
+	maxScore := domain.TotalPoints(questions)
+
+	var reasons []domain.HoldReason
+	if settings.ScoreJump > 0 {
+		history, err := s.scoreHistory(ctx, answer.StudentID, test.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(history) >= moderationMinHistory && total.Percent(maxScore)-mean(history) >= float64(settings.ScoreJump) {
+			reasons = append(reasons, domain.HoldScoreJump)
+		}
+	}
+	if settings.PerfectClass > 0 && len(totals) >= settings.PerfectClass && perfect(totals, snapshot.Answers, maxScore) {
+		reasons = append(reasons, domain.HoldPerfectClass)
+	}
+	if len(reasons) == 0 {
+		return nil, nil
+	}
+	return &domain.ResultHold{Reasons: reasons, FlaggedAt: now}, nil
+}
+
+// scoreHistory returns the student's percentage scores on their other fully
+// graded tests.
+func (s *AssessmentService) scoreHistory(ctx context.Context, studentID domain.StudentID, except domain.TestID) ([]float64, error) {
+	tests, err := repository.Collect(s.testRepo.ListTestsForStudent(ctx, studentID, repository.All))
+	if err != nil {
+		return nil, err
+	}
+	var history []float64
+	for _, test := range tests {
+		if test.ID == except {
+			continue
+		}
+		answers, err := s.answerRepo.ListAnswers(ctx, test.ID, studentID)
+		if err != nil {
+			return nil, err
+		}
+		results, err := s.resultRepo.ListResultsByStudent(ctx, test.ID, studentID)
+		if err != nil {
+			return nil, err
+		}
+		scores := make(map[domain.AnswerID]domain.Score, len(results))
+		for _, res := range results {
+			scores[res.AnswerID] = res.Score
+		}
+		total, graded := gradedTotals(answers, scores)[studentID]
+		if !graded {
+			continue
+		}
+		questions, err := s.testRepo.ListQuestions(ctx, test.ID)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, total.Percent(domain.TotalPoints(questions)))
+	}
+	return history, nil
+}
+
+// gradedTotals totals the scores of the students whose answers all have a
+// score.
+func gradedTotals(answers []domain.Answer, scores map[domain.AnswerID]domain.Score) map[domain.StudentID]domain.Score {
+	totals := make(map[domain.StudentID]domain.Score)
+	pending := make(map[domain.StudentID]bool)
+	for _, a := range answers {
+		score, ok := scores[a.ID]
+		if !ok {
+			pending[a.StudentID] = true
+			continue
+		}
+		totals[a.StudentID] += score
+	}
+	for sid := range pending {
+		delete(totals, sid)
+	}
+	return totals
+}
+
+// perfect reports whether every student who answered scored full marks.
+func perfect(totals map[domain.StudentID]domain.Score, answers []domain.Answer, maxScore domain.Points) bool {
+	for _, a := range answers {
+		total, ok := totals[a.StudentID]
+		if !ok || total < domain.Score(maxScore) {
+			return false
+		}
+	}
+	return true
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
@@ -0,0 +1,80 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_ModerationHold(t *testing.T) {
+	fx := fixtures.NewSchool().WithStudents(2).WithSettings(domain.SchoolSettings{
+		Moderation: domain.ModerationSettings{ScoreJump: 50, PerfectClass: 2},
+	}).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	sit := func(score domain.Score, students ...int) (domain.TestID, []*domain.Result) {
+		t.Helper()
+		var assigned []domain.StudentID
+		for _, i := range students {
+			assigned = append(assigned, fx.Student(i))
+		}
+		test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+			Title:      "Quiz",
+			TeacherID:  fx.Teacher(0),
+			Questions:  []usecase.QuestionDraft{{Prompt: "Q1", Points: 10}},
+			StudentIDs: assigned,
+		})
+		if err != nil {
+			t.Fatalf("CreateTest failed: %v", err)
+		}
+		var results []*domain.Result
+		for _, sid := range assigned {
+			if _, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: sid, Response: "x"}); err != nil {
+				t.Fatalf("SubmitAnswer failed: %v", err)
+			}
+			res, err := service.GradeAnswer(ctx, usecase.GradeInput{TeacherID: fx.Teacher(0), TestID: test.ID, QuestionID: questions[0].ID, StudentID: sid, Score: score, Completed: true})
+			if err != nil {
+				t.Fatalf("GradeAnswer failed: %v", err)
+			}
+			results = append(results, res)
+		}
+		return test.ID, results
+	}
+
+	sit(2, 0)
+	if _, results := sit(3, 0); results[0].Hold != nil || !results[0].Completed {
+		t.Fatalf("expected an ordinary score to be released, got %+v", results[0])
+	}
+	jumpTest, results := sit(10, 0)
+	if results[0].Hold == nil || results[0].Completed || results[0].Hold.Reasons[0] != domain.HoldScoreJump {
+		t.Fatalf("expected a jump from 25%% to 100%% to be held, got %+v", results[0])
+	}
+	if visible, _ := service.ListResultsForStudent(ctx, fx.Student(0), jumpTest); len(visible) != 0 {
+		t.Fatalf("expected the held result to stay hidden, got %+v", visible)
+	}
+
+	held, err := service.ListHeldResults(ctx, fx.Teacher(0), jumpTest)
+	if err != nil || len(held) != 1 || held[0].StudentID != fx.Student(0) {
+		t.Fatalf("ListHeldResults: %+v, %v", held, err)
+	}
+	released, err := service.ReleaseHeldResult(ctx, fx.Teacher(0), jumpTest, held[0].Result.ID)
+	if err != nil || released.Result.Hold != nil || !released.Result.Completed {
+		t.Fatalf("ReleaseHeldResult: %+v, %v", released, err)
+	}
+	if visible, _ := service.ListResultsForStudent(ctx, fx.Student(0), jumpTest); len(visible) != 1 {
+		t.Fatalf("expected the released result to be visible, got %+v", visible)
+	}
+	if _, err := service.ReleaseHeldResult(ctx, fx.Teacher(0), jumpTest, held[0].Result.ID); err != errs.ErrResultNotHeld {
+		t.Fatalf("expected ErrResultNotHeld, got %v", err)
+	}
+
+	_, results = sit(10, 0, 1)
+	if last := results[1]; last.Hold == nil || last.Hold.Reasons[0] != domain.HoldPerfectClass {
+		t.Fatalf("expected an all-perfect class to hold the last result, got %+v", last)
+	}
+}
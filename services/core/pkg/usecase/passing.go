@@ -0,0 +1,244 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/audit"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/readmodel"
+)
+
+// StudentOutcome is a student's total on a test. Passed is only set once the
+// test has a passing score and every submitted answer has been graded.
+type StudentOutcome struct {
+	StudentID domain.StudentID
+	Score     domain.Score
+	MaxScore  domain.Points
+	Answered  int
+	Graded    int
+	Passed    *bool
+}
+
+// Percent returns the student's score as a percentage of the test's points.
+func (o StudentOutcome) Percent() float64 {
+	return o.Score.Percent(o.MaxScore)
+}
+
+// ClassPassRate summarises pass/fail outcomes of one class on a test.
+type ClassPassRate struct {
+	ClassID  domain.ClassID
+	Decided  int
+	Passed   int
+	PassRate float64
+}
+
+// SetPassingScore sets or, with a nil score, clears the total a student needs
+// to pass a test. The score may not exceed the test's total points.
+func (s *AssessmentService) SetPassingScore(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID, score *domain.Score) (*domain.Test, error) {
+	ctx, span := s.trace(ctx, "SetPassingScore")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+
+	test.PassingScore = nil
+	if score != nil {
+		questions, err := s.testRepo.ListQuestions(ctx, testID)
+		if err != nil {
+			return nil, err
+		}
+		if !validPassingScore(*score, domain.TotalPoints(questions)) {
+			return nil, errs.ErrInvalidTest
+		}
+		v := *score
+		test.PassingScore = &v
+	}
+	test.UpdatedAt = time.Now().UTC()
+	if err := s.testRepo.UpdateTest(ctx, test); err != nil {
+		return nil, err
+	}
+	s.stats.entries.Invalidate(testID)
+	s.audit(ctx, audit.TestUpdated(teacherID, *test, "passing score"))
+	return test, nil
+}
+
+// TestOutcomes lists the total and pass/fail outcome of every student assigned
+// to a test, ensuring teacher ownership.
+func (s *AssessmentService) TestOutcomes(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID) ([]StudentOutcome, error) {
+	ctx, span := s.trace(ctx, "TestOutcomes")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	return s.studentOutcomes(ctx, test)
+}
+
+// StudentTestOutcome returns a student's own total and pass/fail outcome.
+func (s *AssessmentService) StudentTestOutcome(ctx context.Context, studentID domain.StudentID, testID domain.TestID) (*StudentOutcome, error) {
+	ctx, span := s.trace(ctx, "StudentTestOutcome")
+	defer span.End()
+
+	test, err := s.GetTestForStudent(ctx, studentID, testID)
+	if err != nil {
+		return nil, err
+	}
+	outcomes, err := s.studentOutcomes(ctx, test)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range outcomes {
+		if o.StudentID == studentID {
+			return &o, nil
+		}
+	}
+	return nil, errs.ErrStudentNotAssigned
+}
+
+func (s *AssessmentService) studentOutcomes(ctx context.Context, test *domain.Test) ([]StudentOutcome, error) {
+	var (
+		outcomes []StudentOutcome
+		err      error
+	)
+	if s.readModel != nil {
+		outcomes, err = s.gradebookOutcomes(ctx, test)
+	} else {
+		outcomes, err = s.repositoryOutcomes(ctx, test)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if test.PassingScore != nil {
+		for i := range outcomes {
+			o := &outcomes[i]
+			if o.Answered > 0 && o.Graded == o.Answered {
+				passed := o.Score >= *test.PassingScore
+				o.Passed = &passed
+			}
+		}
+	}
+
+	sort.Slice(outcomes, func(i, j int) bool {
+		return outcomes[i].StudentID < outcomes[j].StudentID
+	})
+	return outcomes, nil
+}
+
+// repositoryOutcomes totals the assigned students' answers as stored.
+func (s *AssessmentService) repositoryOutcomes(ctx context.Context, test *domain.Test) ([]StudentOutcome, error) {
+	questions, err := s.testRepo.ListQuestions(ctx, test.ID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := s.resultRepo.SnapshotGrading(ctx, test.ID)
+	if err != nil {
+		return nil, err
+	}
+	answers, results := snapshot.Answers, snapshot.Results
+
+	scores := make(map[domain.AnswerID]domain.Score, len(results))
+	for _, res := range results {
+		scores[res.AnswerID] = res.Score
+	}
+
+	maxScore := domain.TotalPoints(questions)
+	byStudent := make(map[domain.StudentID]*StudentOutcome, len(test.AssignedTo))
+	outcomes := make([]StudentOutcome, len(test.AssignedTo))
+	for i, sid := range test.AssignedTo {
+		outcomes[i] = StudentOutcome{StudentID: sid, MaxScore: maxScore}
+		byStudent[sid] = &outcomes[i]
+	}
+	for _, ans := range answers {
+		o, ok := byStudent[ans.StudentID]
+		if !ok {
+			continue
+		}
+		o.Answered++
+		if score, graded := scores[ans.ID]; graded {
+			o.Graded++
+			o.Score += score
+		}
+	}
+	return outcomes, nil
+}
+
+// gradebookOutcomes totals the assigned students' answers from the read
+// model.
+func (s *AssessmentService) gradebookOutcomes(ctx context.Context, test *domain.Test) ([]StudentOutcome, error) {
+	gradebook, err := s.readModel.Gradebook(ctx, test.ID)
+	if err != nil {
+		return nil, err
+	}
+	rows := make(map[domain.StudentID]readmodel.GradebookRow, len(gradebook.Rows))
+	for _, row := range gradebook.Rows {
+		rows[row.StudentID] = row
+	}
+	outcomes := make([]StudentOutcome, len(test.AssignedTo))
+	for i, sid := range test.AssignedTo {
+		o := StudentOutcome{StudentID: sid, MaxScore: gradebook.MaxScore}
+		for _, cell := range rows[sid].Cells {
+			if !cell.Answered() {
+				continue
+			}
+			o.Answered++
+			if cell.Graded {
+				o.Graded++
+				o.Score += cell.Score
+			}
+		}
+		outcomes[i] = o
+	}
+	return outcomes, nil
+}
+
+// classPassRates groups decided outcomes by the students' classes.
+func (s *AssessmentService) classPassRates(ctx context.Context, outcomes []StudentOutcome) ([]ClassPassRate, error) {
+	byClass := make(map[domain.ClassID]*ClassPassRate)
+	for _, o := range outcomes {
+		if o.Passed == nil {
+			continue
+		}
+		student, err := s.orgRepo.GetStudent(ctx, o.StudentID)
+		if err != nil {
+			return nil, err
+		}
+		if student == nil {
+			continue
+		}
+		rate, ok := byClass[student.ClassID]
+		if !ok {
+			rate = &ClassPassRate{ClassID: student.ClassID}
+			byClass[student.ClassID] = rate
+		}
+		rate.Decided++
+		if *o.Passed {
+			rate.Passed++
+		}
+	}
+
+	rates := make([]ClassPassRate, 0, len(byClass))
+	for _, rate := range byClass {
+		rate.PassRate = float64(rate.Passed) / float64(rate.Decided)
+		rates = append(rates, *rate)
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		return rates[i].ClassID < rates[j].ClassID
+	})
+	return rates, nil
+}
+
+func validPassingScore(score domain.Score, total domain.Points) bool {
+	return score.Within(total)
+}
@@ -0,0 +1,77 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_PassingScore(t *testing.T) {
+	fx := fixtures.NewSchool().WithClasses(2).WithStudents(2).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	students := []domain.StudentID{fx.Student(0), fx.Student(1), fx.Student(2), fx.Student(3)}
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Threshold",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "Q1", Points: 5}, {Prompt: "Q2", Points: 5}},
+		StudentIDs: students,
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+
+	tooHigh := domain.Score(11)
+	if _, err := service.SetPassingScore(ctx, fx.Teacher(0), test.ID, &tooHigh); err != errs.ErrInvalidTest {
+		t.Fatalf("expected ErrInvalidTest above total points, got %v", err)
+	}
+	passing := domain.Score(6)
+	if _, err := service.SetPassingScore(ctx, fx.Teacher(0), test.ID, &passing); err != nil {
+		t.Fatalf("SetPassingScore failed: %v", err)
+	}
+
+	// Students 0 and 1 sit in the first class, 2 and 3 in the second.
+	scores := map[domain.StudentID][2]domain.Score{
+		fx.Student(0): {5, 5},
+		fx.Student(1): {2, 3},
+		fx.Student(2): {4, 4},
+	}
+	for sid, pair := range scores {
+		for i, q := range questions {
+			if _, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: q.ID, StudentID: sid, Response: "x"}); err != nil {
+				t.Fatalf("SubmitAnswer failed: %v", err)
+			}
+			if _, err := service.GradeAnswer(ctx, usecase.GradeInput{TeacherID: fx.Teacher(0), TestID: test.ID, QuestionID: q.ID, StudentID: sid, Score: pair[i], Completed: true}); err != nil {
+				t.Fatalf("GradeAnswer failed: %v", err)
+			}
+		}
+	}
+	// Student 3 answered but is not graded yet, so is still undecided.
+	if _, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: fx.Student(3), Response: "x"}); err != nil {
+		t.Fatalf("SubmitAnswer failed: %v", err)
+	}
+
+	outcome, err := service.StudentTestOutcome(ctx, fx.Student(1), test.ID)
+	if err != nil {
+		t.Fatalf("StudentTestOutcome failed: %v", err)
+	}
+	if outcome.Score != 5 || outcome.MaxScore != 10 || outcome.Passed == nil || *outcome.Passed {
+		t.Fatalf("expected student 1 to fail with 5/10, got %+v", outcome)
+	}
+
+	stats, err := service.TestStatistics(ctx, fx.Teacher(0), test.ID)
+	if err != nil {
+		t.Fatalf("TestStatistics failed: %v", err)
+	}
+	if stats.Decided != 3 || stats.Passed != 2 {
+		t.Fatalf("expected 2 of 3 decided students to pass, got %d of %d", stats.Passed, stats.Decided)
+	}
+	if len(stats.Classes) != 2 || stats.Classes[0].PassRate != 0.5 || stats.Classes[1].PassRate != 1 {
+		t.Fatalf("unexpected class pass rates: %+v", stats.Classes)
+	}
+}
@@ -0,0 +1,226 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+const maxDisplayNameLength = 64
+
+// ProfileService lets students and teachers manage their own profile.
+type ProfileService struct {
+	orgRepo repository.OrganizationRepository
+}
+
+// NewProfileService constructs a profile service.
+func NewProfileService(org repository.OrganizationRepository) *ProfileService {
+	return &ProfileService{orgRepo: org}
+}
+
+// ProfileUpdate lists the self-service fields; nil fields are left unchanged.
+type ProfileUpdate struct {
+	DisplayName   *string
+	Notifications *domain.NotificationPreferences
+	// Locale sets the preferred language of translated tests; an empty
+	// locale clears it. Teachers have no locale.
+	Locale *string
+	// Timezone sets the IANA zone timestamps are shown in; an empty zone
+	// falls back to the school's.
+	Timezone *string
+}
+
+// GetStudentProfile returns the student record.
+func (s *ProfileService) GetStudentProfile(ctx context.Context, studentID domain.StudentID) (*domain.Student, error) {
+	student, err := s.orgRepo.GetStudent(ctx, studentID)
+	if err != nil {
+		return nil, err
+	}
+	if student == nil {
+		return nil, errs.ErrStudentNotFound
+	}
+	return student, nil
+}
+
+// UpdateStudentProfile applies a validated profile update to a student.
+func (s *ProfileService) UpdateStudentProfile(ctx context.Context, studentID domain.StudentID, update ProfileUpdate) (*domain.Student, error) {
+	student, err := s.GetStudentProfile(ctx, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.DisplayName != nil {
+		name, err := normalizeDisplayName(*update.DisplayName)
+		if err != nil {
+			return nil, err
+		}
+		student.DisplayName = name
+	}
+	if update.Notifications != nil {
+		if !validDelivery(update.Notifications.Delivery) {
+			return nil, errs.ErrInvalidProfile
+		}
+		student.Notifications = *update.Notifications
+	}
+	if update.Locale != nil {
+		locale := ""
+		if strings.TrimSpace(*update.Locale) != "" {
+			var ok bool
+			if locale, ok = domain.CanonicalLocale(*update.Locale); !ok {
+				return nil, errs.ErrInvalidProfile
+			}
+		}
+		student.Locale = locale
+	}
+	if update.Timezone != nil {
+		timezone, err := normalizeTimezone(*update.Timezone)
+		if err != nil {
+			return nil, err
+		}
+		student.Timezone = timezone
+	}
+
+	if err := s.orgRepo.UpdateStudent(ctx, student); err != nil {
+		return nil, err
+	}
+	return student, nil
+}
+
+// GetTeacherProfile returns the teacher record.
+func (s *ProfileService) GetTeacherProfile(ctx context.Context, teacherID domain.TeacherID) (*domain.Teacher, error) {
+	teacher, err := s.orgRepo.GetTeacher(ctx, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	if teacher == nil {
+		return nil, errs.ErrTeacherNotFound
+	}
+	return teacher, nil
+}
+
+// UpdateTeacherProfile applies a validated profile update to a teacher.
+func (s *ProfileService) UpdateTeacherProfile(ctx context.Context, teacherID domain.TeacherID, update ProfileUpdate) (*domain.Teacher, error) {
+	teacher, err := s.GetTeacherProfile(ctx, teacherID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.DisplayName != nil {
+		name, err := normalizeDisplayName(*update.DisplayName)
+		if err != nil {
+			return nil, err
+		}
+		teacher.DisplayName = name
+	}
+	if update.Notifications != nil {
+		if !validDelivery(update.Notifications.Delivery) {
+			return nil, errs.ErrInvalidProfile
+		}
+		teacher.Notifications = *update.Notifications
+	}
+	if update.Timezone != nil {
+		timezone, err := normalizeTimezone(*update.Timezone)
+		if err != nil {
+			return nil, err
+		}
+		teacher.Timezone = timezone
+	}
+
+	if err := s.orgRepo.UpdateTeacher(ctx, teacher); err != nil {
+		return nil, err
+	}
+	return teacher, nil
+}
+
+// TeacherLocation returns the zone the teacher's timestamps are shown in:
+// their own, else their school's, else UTC.
+func (s *ProfileService) TeacherLocation(ctx context.Context, teacherID domain.TeacherID) (*time.Location, error) {
+	teacher, err := s.GetTeacherProfile(ctx, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	school, err := s.orgRepo.GetSchool(ctx, teacher.SchoolID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.ResolveTimezone(teacher.Timezone, schoolTimezone(school)), nil
+}
+
+// StudentLocation returns the zone the student's timestamps are shown in:
+// their own, else their school's, else UTC.
+func (s *ProfileService) StudentLocation(ctx context.Context, studentID domain.StudentID) (*time.Location, error) {
+	student, err := s.GetStudentProfile(ctx, studentID)
+	if err != nil {
+		return nil, err
+	}
+	if student.Timezone != "" {
+		return domain.ResolveTimezone(student.Timezone), nil
+	}
+	school, err := s.schoolOfClass(ctx, student.ClassID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.ResolveTimezone(schoolTimezone(school)), nil
+}
+
+func (s *ProfileService) schoolOfClass(ctx context.Context, classID domain.ClassID) (*domain.School, error) {
+	class, err := s.orgRepo.GetClass(ctx, classID)
+	if err != nil || class == nil {
+		return nil, err
+	}
+	grade, err := s.orgRepo.GetGrade(ctx, class.GradeID)
+	if err != nil || grade == nil {
+		return nil, err
+	}
+	return s.orgRepo.GetSchool(ctx, grade.SchoolID)
+}
+
+func schoolTimezone(school *domain.School) string {
+	if school == nil {
+		return ""
+	}
+	return school.Settings.Timezone
+}
+
+// normalizeTimezone trims the zone name and rejects unknown zones. An empty
+// name clears the setting.
+func normalizeTimezone(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil
+	}
+	if _, ok := domain.LoadTimezone(name); !ok {
+		return "", errs.ErrInvalidProfile
+	}
+	return name, nil
+}
+
+// normalizeDisplayName trims the name and rejects control characters and
+// overly long values. An empty name clears the display name.
+func normalizeDisplayName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if utf8.RuneCountInString(name) > maxDisplayNameLength {
+		return "", errs.ErrInvalidProfile
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return "", errs.ErrInvalidProfile
+		}
+	}
+	return name, nil
+}
+
+func validDelivery(d domain.NotificationDelivery) bool {
+	switch d {
+	case "", domain.DeliveryImmediate, domain.DeliveryDigest, domain.DeliveryOff:
+		return true
+	default:
+		return false
+	}
+}
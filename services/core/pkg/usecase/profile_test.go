@@ -0,0 +1,74 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestProfileService_UpdateStudentProfile(t *testing.T) {
+	fx := fixtures.NewSchool().Build()
+	service := usecase.NewProfileService(fx.Repo)
+	studentID := fx.Student(0)
+	ctx := context.Background()
+
+	name := "  Ali  "
+	updated, err := service.UpdateStudentProfile(ctx, studentID, usecase.ProfileUpdate{
+		DisplayName:   &name,
+		Notifications: &domain.NotificationPreferences{MuteTestAssigned: true},
+	})
+	if err != nil {
+		t.Fatalf("UpdateStudentProfile failed: %v", err)
+	}
+	if updated.DisplayName != "Ali" || !updated.Notifications.MuteTestAssigned {
+		t.Fatalf("unexpected profile %+v", updated)
+	}
+
+	stored, err := service.GetStudentProfile(ctx, "student-001")
+	if err != nil {
+		t.Fatalf("GetStudentProfile failed: %v", err)
+	}
+	if stored.DisplayName != "Ali" || stored.Name != fx.Students[0].Name {
+		t.Fatalf("expected display name to persist without touching name, got %+v", stored)
+	}
+
+	tooLong := strings.Repeat("x", 65)
+	if _, err := service.UpdateStudentProfile(ctx, studentID, usecase.ProfileUpdate{DisplayName: &tooLong}); err != errs.ErrInvalidProfile {
+		t.Fatalf("expected ErrInvalidProfile, got %v", err)
+	}
+	if _, err := service.UpdateStudentProfile(ctx, "student-999", usecase.ProfileUpdate{}); err != errs.ErrStudentNotFound {
+		t.Fatalf("expected ErrStudentNotFound, got %v", err)
+	}
+}
+
+func TestProfileService_Locations(t *testing.T) {
+	fx := fixtures.NewSchool().WithSettings(domain.SchoolSettings{Timezone: "Asia/Tokyo"}).Build()
+	service := usecase.NewProfileService(fx.Repo)
+	ctx := context.Background()
+
+	loc, err := service.StudentLocation(ctx, fx.Student(0))
+	if err != nil {
+		t.Fatalf("StudentLocation failed: %v", err)
+	}
+	if loc.String() != "Asia/Tokyo" {
+		t.Fatalf("expected the school's zone, got %v", loc)
+	}
+
+	paris := "Europe/Paris"
+	if _, err := service.UpdateTeacherProfile(ctx, fx.Teacher(0), usecase.ProfileUpdate{Timezone: &paris}); err != nil {
+		t.Fatalf("UpdateTeacherProfile failed: %v", err)
+	}
+	if loc, err := service.TeacherLocation(ctx, fx.Teacher(0)); err != nil || loc.String() != "Europe/Paris" {
+		t.Fatalf("expected the teacher's own zone, got %v, %v", loc, err)
+	}
+
+	unknown := "Mars/Olympus"
+	if _, err := service.UpdateStudentProfile(ctx, fx.Student(0), usecase.ProfileUpdate{Timezone: &unknown}); err != errs.ErrInvalidProfile {
+		t.Fatalf("expected ErrInvalidProfile for an unknown zone, got %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/id"
+	"github.com/sky0621/go_work_sample/core/pkg/magiclink"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// MaxRosterSize caps the students provisioned in one request.
+const MaxRosterSize = 500
+
+// ProvisioningSettings configures the invitations of new accounts.
+type ProvisioningSettings struct {
+	// BaseURL is the sign-in page the invitation link opens, the same page
+	// as for emailed magic links.
+	BaseURL       string
+	InvitationTTL time.Duration
+}
+
+// ProvisioningService creates student accounts in bulk and hands out their
+// first sign-in as invitation links. An invitation is a magic link that is
+// redeemed through the same endpoint as emailed ones; it works once, and
+// only until a newer invitation replaces it.
+type ProvisioningService struct {
+	orgRepo  repository.OrganizationRepository
+	links    *magiclink.Signer
+	settings ProvisioningSettings
+}
+
+// NewProvisioningService constructs a provisioning service. links must share
+// its secret with the magic-link service that redeems the invitations.
+func NewProvisioningService(org repository.OrganizationRepository, links *magiclink.Signer, settings ProvisioningSettings) *ProvisioningService {
+	return &ProvisioningService{orgRepo: org, links: links, settings: settings}
+}
+
+// StudentDraft is one line of a roster to provision.
+type StudentDraft struct {
+	Name          string
+	DisplayName   string
+	Email         string
+	GuardianEmail string
+}
+
+// Invitation is a student's credential: the link that signs them in for the
+// first time.
+type Invitation struct {
+	Student   domain.Student
+	Link      string
+	ExpiresAt time.Time
+}
+
+// ProvisionStudents creates an account for every student of roster in the
+// class and returns their invitations in roster order. Either every student
+// is created or, when any line is invalid or uses the email of an existing
+// student, none is.
+func (s *ProvisioningService) ProvisionStudents(ctx context.Context, classID domain.ClassID, roster []StudentDraft) ([]Invitation, error) {
+	if err := s.ensureClass(ctx, classID); err != nil {
+		return nil, err
+	}
+	if len(roster) == 0 || len(roster) > MaxRosterSize {
+		return nil, errs.ErrInvalidRoster
+	}
+
+	now := time.Now().UTC()
+	seen := make(map[string]bool, len(roster))
+	students := make([]domain.Student, len(roster))
+	for i, draft := range roster {
+		student, err := domain.NewStudent(domain.StudentID(id.New()), classID, strings.TrimSpace(draft.Name), strings.TrimSpace(draft.Email), now)
+		if err != nil {
+			return nil, fmt.Errorf("student %d: %w", i+1, err)
+		}
+		student.DisplayName = strings.TrimSpace(draft.DisplayName)
+		student.GuardianEmail = strings.TrimSpace(draft.GuardianEmail)
+		if student.GuardianEmail != "" && !domain.ValidEmail(student.GuardianEmail) {
+			return nil, fmt.Errorf("student %d: %w", i+1, errs.ErrInvalidEmail)
+		}
+		key := strings.ToLower(student.Email)
+		if seen[key] {
+			return nil, errs.ErrInvalidRoster
+		}
+		seen[key] = true
+		if err := s.ensureEmailFree(ctx, student.Email); err != nil {
+			return nil, fmt.Errorf("student %d: %w", i+1, err)
+		}
+		students[i] = *student
+	}
+
+	invitations := make([]Invitation, len(students))
+	for i := range students {
+		invitation, err := s.invite(&students[i])
+		if err != nil {
+			return nil, err
+		}
+		invitations[i] = invitation
+	}
+	if err := s.orgRepo.CreateStudents(ctx, classID, students); err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// ClassInvitations issues a new invitation to every student of the class,
+// for example when the credential sheet was lost, oldest student first.
+// Earlier invitations stop working.
+func (s *ProvisioningService) ClassInvitations(ctx context.Context, classID domain.ClassID) ([]Invitation, error) {
+	if err := s.ensureClass(ctx, classID); err != nil {
+		return nil, err
+	}
+	students, err := repository.Collect(s.orgRepo.ListStudents(ctx, classID, repository.All))
+	if err != nil {
+		return nil, err
+	}
+
+	invitations := make([]Invitation, len(students))
+	for i := range students {
+		invitation, err := s.invite(&students[i])
+		if err != nil {
+			return nil, err
+		}
+		if err := s.orgRepo.UpdateStudent(ctx, &students[i]); err != nil {
+			return nil, err
+		}
+		invitations[i] = invitation
+	}
+	return invitations, nil
+}
+
+// invite issues an invitation for the student and records it as their
+// outstanding one; the caller stores the student.
+func (s *ProvisioningService) invite(student *domain.Student) (Invitation, error) {
+	token, claims, err := s.links.IssueInvitation(domain.RoleStudent, string(student.ID), s.settings.InvitationTTL)
+	if err != nil {
+		return Invitation{}, err
+	}
+	link, err := signInURL(s.settings.BaseURL, token)
+	if err != nil {
+		return Invitation{}, err
+	}
+	student.InvitationID = claims.TokenID
+	return Invitation{Student: *student, Link: link, ExpiresAt: claims.ExpiresAt}, nil
+}
+
+// ensureEmailFree refuses an email an existing student signs in with.
+// Guardians may share theirs with any number of students.
+func (s *ProvisioningService) ensureEmailFree(ctx context.Context, email string) error {
+	found, err := s.orgRepo.FindStudentsByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	for _, student := range found {
+		if strings.EqualFold(student.Email, email) {
+			return errs.ErrEmailTaken
+		}
+	}
+	return nil
+}
+
+func (s *ProvisioningService) ensureClass(ctx context.Context, classID domain.ClassID) error {
+	class, err := s.orgRepo.GetClass(ctx, classID)
+	if err != nil {
+		return err
+	}
+	if class == nil {
+		return errs.ErrClassNotFound
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/magiclink"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestProvisioningService(t *testing.T) {
+	fx := fixtures.NewSchool().Build()
+	ctx := context.Background()
+	classID := fx.Classes[0].ID
+
+	links := magiclink.NewSigner("link-secret")
+	provisioning := usecase.NewProvisioningService(fx.Repo, links, usecase.ProvisioningSettings{
+		BaseURL:       "https://example.com/sign-in",
+		InvitationTTL: 7 * 24 * time.Hour,
+	})
+	signIn := usecase.NewMagicLinkService(fx.Repo, links, auth.NewSigner("session-secret"), &capturingMailer{}, usecase.MagicLinkSettings{
+		LinkTTL:    15 * time.Minute,
+		SessionTTL: time.Hour,
+		PerEmail:   10,
+		PerClient:  10,
+	})
+
+	if _, err := provisioning.ProvisionStudents(ctx, "missing", []usecase.StudentDraft{{Name: "Ann", Email: "ann@example.com"}}); err != errs.ErrClassNotFound {
+		t.Fatalf("expected ErrClassNotFound, got %v", err)
+	}
+	if _, err := provisioning.ProvisionStudents(ctx, classID, []usecase.StudentDraft{
+		{Name: "Ann", Email: "ann@example.com"},
+		{Name: "Ann again", Email: "ANN@example.com"},
+	}); err != errs.ErrInvalidRoster {
+		t.Fatalf("expected ErrInvalidRoster for a repeated email, got %v", err)
+	}
+	if _, err := provisioning.ProvisionStudents(ctx, classID, []usecase.StudentDraft{
+		{Name: "Ann", Email: "ann@example.com"},
+		{Name: "", Email: "bob@example.com"},
+	}); !errors.Is(err, errs.ErrInvalidProfile) {
+		t.Fatalf("expected ErrInvalidProfile for a nameless student, got %v", err)
+	}
+	if _, err := provisioning.ProvisionStudents(ctx, classID, []usecase.StudentDraft{
+		{Name: "Ann", Email: "ann@example.com"},
+		{Name: "Copy", Email: strings.ToUpper(fx.Students[0].Email)},
+	}); !errors.Is(err, errs.ErrEmailTaken) {
+		t.Fatalf("expected ErrEmailTaken for an existing student's email, got %v", err)
+	}
+	if students, _ := repository.Collect(fx.Repo.ListStudents(ctx, classID, repository.All)); len(students) != 1 {
+		t.Fatalf("expected a rejected roster to create nobody, got %d students", len(students))
+	}
+
+	invitations, err := provisioning.ProvisionStudents(ctx, classID, []usecase.StudentDraft{
+		{Name: "Ann", Email: " ann@example.com ", GuardianEmail: "parent@example.com"},
+		{Name: "Bob", DisplayName: "Bobby", Email: "bob@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("ProvisionStudents failed: %v", err)
+	}
+	if len(invitations) != 2 || invitations[0].Student.Email != "ann@example.com" || invitations[1].Student.DisplayName != "Bobby" {
+		t.Fatalf("unexpected invitations %+v", invitations)
+	}
+	stored, err := fx.Repo.GetStudent(ctx, invitations[0].Student.ID)
+	if err != nil || stored == nil || stored.ClassID != classID || stored.GuardianEmail != "parent@example.com" {
+		t.Fatalf("expected the student stored in the class, got %+v (%v)", stored, err)
+	}
+
+	token := tokenOf(t, invitations[0].Link)
+	_, claims, err := signIn.Redeem(ctx, token, "client", "test-agent")
+	if err != nil {
+		t.Fatalf("Redeem failed: %v", err)
+	}
+	if claims.Role != domain.RoleStudent || claims.Subject != string(invitations[0].Student.ID) {
+		t.Fatalf("unexpected claims %+v", claims)
+	}
+	// A restart forgets the links redeemed in memory, but not invitations.
+	restarted := usecase.NewMagicLinkService(fx.Repo, links, auth.NewSigner("session-secret"), &capturingMailer{}, usecase.MagicLinkSettings{SessionTTL: time.Hour, PerClient: 10})
+	if _, _, err := restarted.Redeem(ctx, token, "client", "test-agent"); err != errs.ErrInvalidMagicLink {
+		t.Fatalf("expected ErrInvalidMagicLink for a used invitation, got %v", err)
+	}
+
+	reissued, err := provisioning.ClassInvitations(ctx, classID)
+	if err != nil {
+		t.Fatalf("ClassInvitations failed: %v", err)
+	}
+	if len(reissued) != 3 {
+		t.Fatalf("expected an invitation per student of the class, got %d", len(reissued))
+	}
+	if _, _, err := signIn.Redeem(ctx, tokenOf(t, invitations[1].Link), "client", "test-agent"); err != errs.ErrInvalidMagicLink {
+		t.Fatalf("expected ErrInvalidMagicLink for a replaced invitation, got %v", err)
+	}
+	if _, _, err := signIn.Redeem(ctx, tokenOf(t, reissued[2].Link), "client", "test-agent"); err != nil {
+		t.Fatalf("Redeem of a reissued invitation failed: %v", err)
+	}
+}
+
+func tokenOf(t *testing.T, link string) string {
+	t.Helper()
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("invalid link %q: %v", link, err)
+	}
+	return u.Query().Get("token")
+}
@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/audit"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/notify"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// QuestionEdit changes the prompt, points or translations of a question. Nil
+// fields are left unchanged; a non-nil Translations replaces them all, so an
+// empty map removes them.
+type QuestionEdit struct {
+	Prompt       *string
+	Points       *domain.Points
+	Translations map[string]domain.Translation
+}
+
+// PublishTest makes a draft test visible to its assigned students and
+// notifies them. Publishing a published test changes nothing.
+func (s *AssessmentService) PublishTest(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID) (*domain.Test, error) {
+	ctx, span := s.trace(ctx, "PublishTest")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	if test.Published {
+		return test, nil
+	}
+	if err := s.ensureDraftUnlocked(test, teacherID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	test.Published = true
+	test.UpdatedAt = now
+	if err := s.testRepo.UpdateTest(ctx, test); err != nil {
+		return nil, err
+	}
+	s.draftLocks.drop(testID)
+
+	s.record(domain.TestPublished{Test: *test})
+	s.audit(ctx, audit.TestPublished(teacherID, *test))
+	s.notifyAssigned(ctx, test, now)
+	s.publish(EventTestPublished, testEvent{
+		TestID:     string(test.ID),
+		TeacherID:  string(test.TeacherID),
+		Title:      test.Title,
+		StudentIDs: studentIDStrings(test.AssignedTo),
+		Published:  test.Published,
+	})
+	return test, nil
+}
+
+// UnpublishTest returns a test to draft, hiding it from students so its
+// questions can be edited. Once a student has answered, the test stays
+// published.
+func (s *AssessmentService) UnpublishTest(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID) (*domain.Test, error) {
+	ctx, span := s.trace(ctx, "UnpublishTest")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	if !test.Published {
+		return test, nil
+	}
+
+	answers, err := s.answerRepo.ListAnswersByTest(ctx, testID, repository.PageRequest{Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(answers.Items) > 0 {
+		return nil, errs.ErrTestAnswered
+	}
+
+	test.Published = false
+	test.UpdatedAt = time.Now().UTC()
+	if err := s.testRepo.UpdateTest(ctx, test); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, audit.TestUnpublished(teacherID, *test))
+	return test, nil
+}
+
+// UpdateQuestion edits a question of a draft test. A passing score must stay
+// reachable with the new points.
+func (s *AssessmentService) UpdateQuestion(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID, questionID domain.QuestionID, edit QuestionEdit) (*domain.Question, error) {
+	ctx, span := s.trace(ctx, "UpdateQuestion")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	if test.Published {
+		return nil, errs.ErrTestPublished
+	}
+	if err := s.ensureDraftUnlocked(test, teacherID); err != nil {
+		return nil, err
+	}
+
+	questions, err := s.testRepo.ListQuestions(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	var question *domain.Question
+	var totalPoints domain.Points
+	for i := range questions {
+		if questions[i].ID == questionID {
+			question = &questions[i]
+			continue
+		}
+		totalPoints += questions[i].Points
+	}
+	if question == nil {
+		return nil, errs.ErrQuestionNotFound
+	}
+
+	if edit.Prompt != nil {
+		question.Prompt = *edit.Prompt
+	}
+	if edit.Points != nil {
+		question.Points = *edit.Points
+	}
+	if edit.Translations != nil {
+		question.Translations = copyTranslations(edit.Translations)
+	}
+	if err := question.Validate(); err != nil {
+		return nil, err
+	}
+	if test.PassingScore != nil && !validPassingScore(*test.PassingScore, totalPoints+question.Points) {
+		return nil, errs.ErrInvalidQuestion
+	}
+
+	if err := s.testRepo.UpdateQuestion(ctx, question); err != nil {
+		return nil, err
+	}
+	s.record(domain.TestChanged{TestID: testID})
+	s.audit(ctx, audit.QuestionUpdated(teacherID, *question))
+	return question, nil
+}
+
+// publishedTestFor returns a test assigned to the student. Drafts are hidden
+// from students as if they did not exist.
+func (s *AssessmentService) publishedTestFor(ctx context.Context, studentID domain.StudentID, testID domain.TestID) (*domain.Test, error) {
+	assigned, err := s.testRepo.IsStudentAssigned(ctx, testID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	if !assigned {
+		return nil, errs.ErrStudentNotAssigned
+	}
+
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	if test == nil || !test.Published {
+		return nil, errs.ErrTestNotFound
+	}
+	return test, nil
+}
+
+func (s *AssessmentService) notifyAssigned(ctx context.Context, test *domain.Test, now time.Time) {
+	for _, studentID := range test.AssignedTo {
+		s.notifyStudent(ctx, studentID, notify.Notification{
+			Kind:       notify.KindTestAssigned,
+			Subject:    "New test assigned: " + test.Title,
+			OccurredAt: now,
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_PublishingLifecycle(t *testing.T) {
+	fx := fixtures.NewSchool().Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+	teacher, student := fx.Teacher(0), fx.Student(0)
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Chemistry",
+		TeacherID:  teacher,
+		Questions:  []usecase.QuestionDraft{{Prompt: "Q1", Points: 5}},
+		StudentIDs: []domain.StudentID{student},
+		Draft:      true,
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	if test.Published {
+		t.Fatalf("expected a draft test")
+	}
+
+	// Drafts are invisible to students.
+	listed, err := service.ListTestsForStudent(ctx, student, repository.All)
+	if err != nil {
+		t.Fatalf("ListTestsForStudent failed: %v", err)
+	}
+	if len(listed.Items) != 0 {
+		t.Fatalf("expected no tests for the student, got %+v", listed.Items)
+	}
+	if _, err := service.GetTestForStudent(ctx, student, test.ID); !errors.Is(err, errs.ErrTestNotFound) {
+		t.Fatalf("expected ErrTestNotFound for a draft, got %v", err)
+	}
+	answer := &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: student, Response: "H2O"}
+	if _, err := service.SubmitAnswer(ctx, answer); !errors.Is(err, errs.ErrTestNotFound) {
+		t.Fatalf("expected ErrTestNotFound when answering a draft, got %v", err)
+	}
+
+	prompt, points := "Name the formula of water", domain.Points(10)
+	edited, err := service.UpdateQuestion(ctx, teacher, test.ID, questions[0].ID, usecase.QuestionEdit{Prompt: &prompt, Points: &points})
+	if err != nil {
+		t.Fatalf("UpdateQuestion failed: %v", err)
+	}
+	if edited.Prompt != prompt || edited.Points != points {
+		t.Fatalf("expected the edit to apply, got %+v", edited)
+	}
+
+	if _, err := service.PublishTest(ctx, teacher, test.ID); err != nil {
+		t.Fatalf("PublishTest failed: %v", err)
+	}
+	if _, err := service.UpdateQuestion(ctx, teacher, test.ID, questions[0].ID, usecase.QuestionEdit{Prompt: &prompt}); !errors.Is(err, errs.ErrTestPublished) {
+		t.Fatalf("expected ErrTestPublished when editing a published test, got %v", err)
+	}
+	listed, err = service.ListTestsForStudent(ctx, student, repository.All)
+	if err != nil {
+		t.Fatalf("ListTestsForStudent failed: %v", err)
+	}
+	if len(listed.Items) != 1 || listed.Items[0].ID != test.ID {
+		t.Fatalf("expected the published test to be listed, got %+v", listed.Items)
+	}
+	if _, err := service.SubmitAnswer(ctx, answer); err != nil {
+		t.Fatalf("SubmitAnswer failed: %v", err)
+	}
+
+	if _, err := service.UnpublishTest(ctx, teacher, test.ID); !errors.Is(err, errs.ErrTestAnswered) {
+		t.Fatalf("expected ErrTestAnswered when unpublishing an answered test, got %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sky0621/go_work_sample/core/pkg/audit"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+)
+
+// ReorderQuestions puts the questions of a draft test in the given order. The
+// order must name every question of the test exactly once.
+func (s *AssessmentService) ReorderQuestions(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID, order []domain.QuestionID) ([]domain.Question, error) {
+	ctx, span := s.trace(ctx, "ReorderQuestions")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	if test.Published {
+		return nil, errs.ErrTestPublished
+	}
+	if err := s.ensureDraftUnlocked(test, teacherID); err != nil {
+		return nil, err
+	}
+
+	questions, err := s.testRepo.ListQuestions(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	if len(order) != len(questions) {
+		return nil, &domain.InvariantError{Field: "order", Reason: "must name every question of the test", Err: errs.ErrInvalidQuestion}
+	}
+	sequences := make(map[domain.QuestionID]int, len(order))
+	for i, questionID := range order {
+		if _, dup := sequences[questionID]; dup {
+			return nil, &domain.InvariantError{Field: "order", Reason: fmt.Sprintf("names question %s more than once", questionID), Err: errs.ErrInvalidQuestion}
+		}
+		sequences[questionID] = i + 1
+	}
+	previous := make(map[domain.QuestionID]int, len(questions))
+	for i := range questions {
+		sequence, ok := sequences[questions[i].ID]
+		if !ok {
+			return nil, errs.ErrQuestionNotFound
+		}
+		previous[questions[i].ID] = questions[i].Sequence
+		questions[i].Sequence = sequence
+	}
+	if err := domain.NormalizeSequences(questions); err != nil {
+		return nil, err
+	}
+
+	changed := make(map[domain.QuestionID]int, len(questions))
+	for _, q := range questions {
+		if q.Sequence != previous[q.ID] {
+			changed[q.ID] = q.Sequence
+		}
+	}
+	if err := s.testRepo.ReorderQuestions(ctx, testID, changed); err != nil {
+		return nil, err
+	}
+	s.record(domain.TestChanged{TestID: testID})
+	s.audit(ctx, audit.QuestionsReordered(teacherID, testID))
+	return questions, nil
+}
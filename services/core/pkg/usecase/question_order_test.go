@@ -0,0 +1,119 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_CreateTestNormalizesSequences(t *testing.T) {
+	fx := fixtures.NewSchool().Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	_, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:     "Gaps",
+		TeacherID: fx.Teacher(0),
+		Questions: []usecase.QuestionDraft{
+			{Prompt: "third", Points: 1, Sequence: 30},
+			{Prompt: "first", Points: 1, Sequence: 2},
+			{Prompt: "second", Points: 1, Sequence: 7},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	for i, want := range []string{"first", "second", "third"} {
+		if questions[i].Prompt != want || questions[i].Sequence != i+1 {
+			t.Fatalf("expected %q at sequence %d, got %+v", want, i+1, questions[i])
+		}
+	}
+	stored, err := service.GetQuestionsForTeacher(ctx, fx.Teacher(0), questions[0].TestID)
+	if err != nil || len(stored) != 3 || stored[0].Prompt != "first" || stored[2].Sequence != 3 {
+		t.Fatalf("expected the stored questions in normalized order, got %+v, %v", stored, err)
+	}
+
+	_, implicit, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:     "Slice order",
+		TeacherID: fx.Teacher(0),
+		Questions: []usecase.QuestionDraft{{Prompt: "a", Points: 1}, {Prompt: "b", Points: 1}},
+	})
+	if err != nil || implicit[0].Prompt != "a" || implicit[1].Sequence != 2 {
+		t.Fatalf("expected drafts without sequences to keep their order, got %+v, %v", implicit, err)
+	}
+
+	cases := map[string][]usecase.QuestionDraft{
+		"duplicate": {{Prompt: "a", Points: 1, Sequence: 1}, {Prompt: "b", Points: 1, Sequence: 1}},
+		"negative":  {{Prompt: "a", Points: 1, Sequence: -1}},
+		"mixed":     {{Prompt: "a", Points: 1}, {Prompt: "b", Points: 1, Sequence: 2}},
+		"partial":   {{Prompt: "a", Points: 1, Sequence: 1}, {Prompt: "b", Points: 1}},
+	}
+	for name, drafts := range cases {
+		_, _, err := service.CreateTest(ctx, usecase.CreateTestInput{Title: name, TeacherID: fx.Teacher(0), Questions: drafts})
+		var invariant *domain.InvariantError
+		if !errors.As(err, &invariant) || invariant.Field != "sequence" || !errors.Is(err, errs.ErrInvalidQuestion) {
+			t.Fatalf("%s: expected an invariant error on sequence, got %v", name, err)
+		}
+	}
+}
+
+func TestAssessmentService_ReorderQuestions(t *testing.T) {
+	fx := fixtures.NewSchool().WithTeachers(2).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+	teacher := fx.Teacher(0)
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:     "Reorder",
+		TeacherID: teacher,
+		Questions: []usecase.QuestionDraft{{Prompt: "a", Points: 1}, {Prompt: "b", Points: 1}, {Prompt: "c", Points: 1}},
+		Draft:     true,
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	a, b, c := questions[0].ID, questions[1].ID, questions[2].ID
+
+	reordered, err := service.ReorderQuestions(ctx, teacher, test.ID, []domain.QuestionID{c, a, b})
+	if err != nil {
+		t.Fatalf("ReorderQuestions failed: %v", err)
+	}
+	for i, want := range []domain.QuestionID{c, a, b} {
+		if reordered[i].ID != want || reordered[i].Sequence != i+1 {
+			t.Fatalf("expected %s at sequence %d, got %+v", want, i+1, reordered[i])
+		}
+	}
+	stored, err := service.GetQuestionsForTeacher(ctx, teacher, test.ID)
+	if err != nil || stored[0].ID != c || stored[1].ID != a || stored[2].ID != b {
+		t.Fatalf("expected the new order to be stored, got %+v, %v", stored, err)
+	}
+
+	invalid := map[string][]domain.QuestionID{
+		"missing":   {a, b},
+		"duplicate": {a, a, b},
+		"empty":     nil,
+	}
+	for name, order := range invalid {
+		if _, err := service.ReorderQuestions(ctx, teacher, test.ID, order); !errors.Is(err, errs.ErrInvalidQuestion) {
+			t.Fatalf("%s: expected ErrInvalidQuestion, got %v", name, err)
+		}
+	}
+	if _, err := service.ReorderQuestions(ctx, teacher, test.ID, []domain.QuestionID{a, b, "unknown"}); !errors.Is(err, errs.ErrQuestionNotFound) {
+		t.Fatalf("expected ErrQuestionNotFound for an unknown question, got %v", err)
+	}
+	if _, err := service.ReorderQuestions(ctx, fx.Teacher(1), test.ID, []domain.QuestionID{a, b, c}); err == nil {
+		t.Fatalf("expected another teacher to be refused")
+	}
+
+	if _, err := service.PublishTest(ctx, teacher, test.ID); err != nil {
+		t.Fatalf("PublishTest failed: %v", err)
+	}
+	if _, err := service.ReorderQuestions(ctx, teacher, test.ID, []domain.QuestionID{a, b, c}); !errors.Is(err, errs.ErrTestPublished) {
+		t.Fatalf("expected ErrTestPublished, got %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+)
+
+// MaxQuickImportQuestions caps the questions of one quick import.
+const MaxQuickImportQuestions = 200
+
+var (
+	// quickNumbering matches what teachers put before a prompt: a Markdown
+	// heading or list number such as "## ", "3." or "Q4:".
+	quickNumbering = regexp.MustCompile(`^(?:#{1,6}\s+|\d+[.)]\s+|[Qq]\d*[.:)]\s+)`)
+	quickPoints    = regexp.MustCompile(`(?i)\s*[(\[](\d+)\s*(?:points?|pts?)[)\]]$`)
+	quickChoice    = regexp.MustCompile(`^(?:[-+]\s+)?(\*)?\s*([A-Za-z])[.)]\s+(.+)$`)
+	quickAnswer    = regexp.MustCompile(`^(?:=|(?i:answer:))\s*(.+)$`)
+)
+
+// QuickImport is a test written in the quick import format.
+type QuickImport struct {
+	// Title is the text of a leading "# " heading, or empty without one.
+	Title     string
+	Questions []QuestionDraft
+}
+
+// QuickImportError reports the line of a quick import that could not be
+// read. It matches errs.ErrInvalidQuickImport.
+type QuickImportError struct {
+	Line   int
+	Reason string
+}
+
+func (e *QuickImportError) Error() string {
+	return fmt.Sprintf("%s: line %d: %s", errs.ErrInvalidQuickImport, e.Line, e.Reason)
+}
+
+func (e *QuickImportError) Unwrap() error {
+	return errs.ErrInvalidQuickImport
+}
+
+// ParseQuickImport reads questions from plain text or Markdown. Questions
+// are separated by blank lines; a document may open with a "# Title" line.
+// Each question starts with its prompt, which may span lines, be numbered
+// ("1.", "Q1:", "## ") and end in its worth, as in "(2 points)"; questions
+// are worth one point otherwise. Lines such as "a) Paris" or "- B. Rome" that
+// follow the prompt are choices, and a "*" before the letter marks the
+// correct one. Two choices labelled True and False make a true/false
+// question. A last line "= text" or "Answer: text" gives the expected
+// response of a free-text question, or the correct letter of a
+// multiple-choice one. Questions without choices are free text.
+func ParseQuickImport(text string) (*QuickImport, error) {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	result := &QuickImport{}
+	for start := 0; start < len(lines); {
+		if strings.TrimSpace(lines[start]) == "" {
+			start++
+			continue
+		}
+		end := start
+		for end < len(lines) && strings.TrimSpace(lines[end]) != "" {
+			end++
+		}
+		block := lines[start:end]
+		first := strings.TrimSpace(block[0])
+		if len(result.Questions) == 0 && result.Title == "" && len(block) == 1 && strings.HasPrefix(first, "# ") {
+			result.Title = strings.TrimSpace(strings.TrimPrefix(first, "# "))
+		} else {
+			if len(result.Questions) == MaxQuickImportQuestions {
+				return nil, &QuickImportError{Line: start + 1, Reason: fmt.Sprintf("a quick import holds at most %d questions", MaxQuickImportQuestions)}
+			}
+			draft, err := parseQuickQuestion(block, start+1)
+			if err != nil {
+				return nil, err
+			}
+			result.Questions = append(result.Questions, draft)
+		}
+		start = end
+	}
+	if len(result.Questions) == 0 {
+		return nil, errs.ErrNoQuestions
+	}
+	return result, nil
+}
+
+// parseQuickQuestion reads one blank-line separated block whose first line
+// is line number firstLine of the document.
+func parseQuickQuestion(block []string, firstLine int) (QuestionDraft, error) {
+	draft := QuestionDraft{Points: 1}
+	prompt := []string{quickNumbering.ReplaceAllString(strings.TrimSpace(block[0]), "")}
+	correct, answer := "", ""
+	for i, raw := range block[1:] {
+		line := strings.TrimSpace(raw)
+		lineNo := firstLine + i + 1
+		fail := func(reason string) (QuestionDraft, error) {
+			return QuestionDraft{}, &QuickImportError{Line: lineNo, Reason: reason}
+		}
+		if answer != "" {
+			return fail("nothing may follow the answer line")
+		}
+		if m := quickAnswer.FindStringSubmatch(line); m != nil {
+			answer = strings.TrimSpace(m[1])
+			continue
+		}
+		if m := quickChoice.FindStringSubmatch(line); m != nil {
+			key := strings.ToLower(m[2])
+			for _, c := range draft.Choices {
+				if c.Key == key {
+					return fail(fmt.Sprintf("choice %s is listed twice", key))
+				}
+			}
+			if m[1] != "" {
+				if correct != "" {
+					return fail("only one choice may be marked correct")
+				}
+				correct = key
+			}
+			draft.Choices = append(draft.Choices, domain.Choice{Key: key, Label: strings.TrimSpace(m[3])})
+			continue
+		}
+		if len(draft.Choices) > 0 {
+			return fail("the prompt must come before the choices")
+		}
+		prompt = append(prompt, line)
+	}
+
+	fail := func(reason string) (QuestionDraft, error) {
+		return QuestionDraft{}, &QuickImportError{Line: firstLine, Reason: reason}
+	}
+	if m := quickPoints.FindStringSubmatchIndex(prompt[0]); m != nil {
+		points, err := strconv.Atoi(prompt[0][m[2]:m[3]])
+		if err != nil || points <= 0 {
+			return fail("points must be a positive number")
+		}
+		draft.Points = domain.Points(points)
+		prompt[0] = prompt[0][:m[0]]
+	}
+	draft.Prompt = strings.TrimSpace(strings.Join(prompt, "\n"))
+	if draft.Prompt == "" {
+		return fail("the question has no prompt")
+	}
+
+	switch {
+	case len(draft.Choices) == 0:
+		draft.Type = domain.QuestionFreeText
+		draft.ExpectedResponse = answer
+		return draft, nil
+	case len(draft.Choices) == 1:
+		return fail("a multiple-choice question needs at least two choices")
+	}
+	if answer != "" {
+		if correct != "" && !strings.EqualFold(answer, correct) {
+			return fail("the answer line and the marked choice disagree")
+		}
+		correct = strings.ToLower(answer)
+	}
+	if correct != "" && !hasChoice(draft.Choices, correct) {
+		return fail(fmt.Sprintf("the answer %s is not one of the choices", correct))
+	}
+	if trueFalse(draft.Choices) {
+		draft.Type = domain.QuestionTrueFalse
+		for _, c := range draft.Choices {
+			if c.Key == correct {
+				draft.ExpectedResponse = strings.ToLower(c.Label)
+			}
+		}
+		draft.Choices = nil
+		return draft, nil
+	}
+	draft.Type = domain.QuestionMultipleChoice
+	draft.ExpectedResponse = correct
+	return draft, nil
+}
+
+func hasChoice(choices []domain.Choice, key string) bool {
+	for _, c := range choices {
+		if c.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// trueFalse reports whether the choices are exactly True and False.
+func trueFalse(choices []domain.Choice) bool {
+	if len(choices) != 2 {
+		return false
+	}
+	a, b := strings.ToLower(choices[0].Label), strings.ToLower(choices[1].Label)
+	return (a == "true" && b == "false") || (a == "false" && b == "true")
+}
+
+// QuickImportTest creates a draft test from a document in the quick import
+// format for the teacher to review, assign and publish. A non-empty title
+// replaces the document's own.
+func (s *AssessmentService) QuickImportTest(ctx context.Context, teacherID domain.TeacherID, title, text string) (*domain.Test, []domain.Question, error) {
+	ctx, span := s.trace(ctx, "QuickImportTest")
+	defer span.End()
+
+	parsed, err := ParseQuickImport(text)
+	if err != nil {
+		return nil, nil, err
+	}
+	if title = strings.TrimSpace(title); title == "" {
+		title = parsed.Title
+	}
+	return s.CreateTest(ctx, CreateTestInput{
+		Title:     title,
+		TeacherID: teacherID,
+		Questions: parsed.Questions,
+		Draft:     true,
+	})
+}
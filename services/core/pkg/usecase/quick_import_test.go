@@ -0,0 +1,93 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+const quickImportSample = `# Geography quiz
+
+1. What is the capital of France? (2 points)
+a) Berlin
+*b) Paris
+c) Rome
+
+Q2: The Nile flows into the
+Mediterranean Sea.
+- A. True
+- B. False
+Answer: a
+
+## Name a river in Asia.
+= Mekong
+
+Describe the water cycle. [5 pts]
+`
+
+func TestParseQuickImport(t *testing.T) {
+	parsed, err := usecase.ParseQuickImport(quickImportSample)
+	if err != nil {
+		t.Fatalf("ParseQuickImport failed: %v", err)
+	}
+	if parsed.Title != "Geography quiz" || len(parsed.Questions) != 4 {
+		t.Fatalf("unexpected import: %+v", parsed)
+	}
+
+	mc := parsed.Questions[0]
+	if mc.Prompt != "What is the capital of France?" || mc.Points != 2 || mc.Type != domain.QuestionMultipleChoice ||
+		len(mc.Choices) != 3 || mc.Choices[1] != (domain.Choice{Key: "b", Label: "Paris"}) || mc.ExpectedResponse != "b" {
+		t.Fatalf("unexpected multiple-choice question: %+v", mc)
+	}
+	tf := parsed.Questions[1]
+	if tf.Prompt != "The Nile flows into the\nMediterranean Sea." || tf.Type != domain.QuestionTrueFalse || tf.Choices != nil || tf.ExpectedResponse != "true" {
+		t.Fatalf("unexpected true/false question: %+v", tf)
+	}
+	if free := parsed.Questions[2]; free.Prompt != "Name a river in Asia." || free.Type != domain.QuestionFreeText || free.ExpectedResponse != "Mekong" || free.Points != 1 {
+		t.Fatalf("unexpected free-text question: %+v", free)
+	}
+	if essay := parsed.Questions[3]; essay.Prompt != "Describe the water cycle." || essay.Points != 5 || essay.ExpectedResponse != "" {
+		t.Fatalf("unexpected essay question: %+v", essay)
+	}
+}
+
+func TestParseQuickImport_Errors(t *testing.T) {
+	cases := map[string]struct {
+		text string
+		line int
+	}{
+		"two correct choices":  {text: "Pick one\n*a) x\n*b) y", line: 3},
+		"prompt after choices": {text: "Pick one\na) x\nb) y\nmore prompt", line: 4},
+		"unknown answer":       {text: "\n\nPick one\na) x\nb) y\nAnswer: c", line: 3},
+		"single choice":        {text: "Pick one\na) x", line: 1},
+		"after the answer":     {text: "Name one\n= x\n= y", line: 3},
+	}
+	for name, tc := range cases {
+		_, err := usecase.ParseQuickImport(tc.text)
+		var importErr *usecase.QuickImportError
+		if !errors.As(err, &importErr) || !errors.Is(err, errs.ErrInvalidQuickImport) || importErr.Line != tc.line {
+			t.Errorf("%s: expected an error on line %d, got %v", name, tc.line, err)
+		}
+	}
+	if _, err := usecase.ParseQuickImport("# Only a title\n"); err != errs.ErrNoQuestions {
+		t.Errorf("expected ErrNoQuestions, got %v", err)
+	}
+}
+
+func TestAssessmentService_QuickImportTest(t *testing.T) {
+	fx := fixtures.NewSchool().Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+
+	test, questions, err := service.QuickImportTest(context.Background(), fx.Teacher(0), "", quickImportSample)
+	if err != nil {
+		t.Fatalf("QuickImportTest failed: %v", err)
+	}
+	if test.Title != "Geography quiz" || test.Published || len(questions) != 4 || questions[3].Sequence != 4 {
+		t.Fatalf("unexpected imported test: %+v %+v", test, questions)
+	}
+}
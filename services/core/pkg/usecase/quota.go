@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/ratelimit"
+)
+
+// SetQuotas enforces the per-school quotas configured in school settings
+// using limiter. Without a limiter no quotas are enforced.
+func (s *AssessmentService) SetQuotas(limiter *ratelimit.Limiter) {
+	s.quotas = limiter
+}
+
+// ReserveExportJob counts an export job against the teacher's school daily
+// quota, returning ErrQuotaExceeded once it is used up.
+func (s *AssessmentService) ReserveExportJob(ctx context.Context, teacherID domain.TeacherID) error {
+	ctx, span := s.trace(ctx, "ReserveExportJob")
+	defer span.End()
+
+	if s.quotas == nil {
+		return nil
+	}
+	teacher, err := s.orgRepo.GetTeacher(ctx, teacherID)
+	if err != nil {
+		return err
+	}
+	if teacher == nil {
+		return errs.ErrTeacherNotFound
+	}
+	school, err := s.orgRepo.GetSchool(ctx, teacher.SchoolID)
+	if err != nil || school == nil {
+		return err
+	}
+	if !s.quotas.Allow("exports:"+string(school.ID), school.Settings.Quotas.ExportJobsPerDay, 24*time.Hour) {
+		return errs.ErrQuotaExceeded
+	}
+	return nil
+}
+
+// reserveSubmission counts an answer submission against the student's
+// school per-minute quota.
+func (s *AssessmentService) reserveSubmission(ctx context.Context, studentID domain.StudentID) error {
+	if s.quotas == nil {
+		return nil
+	}
+	school, err := s.schoolOfStudent(ctx, studentID)
+	if err != nil || school == nil {
+		return err
+	}
+	if !s.quotas.Allow("submissions:"+string(school.ID), school.Settings.Quotas.SubmissionsPerMinute, time.Minute) {
+		return errs.ErrQuotaExceeded
+	}
+	return nil
+}
+
+// limitResponse applies the cap of the student's school on response length,
+// rejecting or truncating longer responses as the school chose.
+func (s *AssessmentService) limitResponse(ctx context.Context, answer *domain.Answer) error {
+	answer.Truncated = false
+	school, err := s.schoolOfStudent(ctx, answer.StudentID)
+	if err != nil || school == nil {
+		return err
+	}
+	quotas := school.Settings.Quotas
+	if quotas.MaxResponseLength == 0 || utf8.RuneCountInString(answer.Response) <= quotas.MaxResponseLength {
+		return nil
+	}
+	if quotas.ResponseLengthPolicy != domain.LengthPolicyTruncate {
+		return errs.ErrResponseTooLong
+	}
+	runes := 0
+	for i := range answer.Response {
+		if runes == quotas.MaxResponseLength {
+			answer.Response = answer.Response[:i]
+			break
+		}
+		runes++
+	}
+	answer.Truncated = true
+	return nil
+}
+
+func (s *AssessmentService) schoolOfStudent(ctx context.Context, studentID domain.StudentID) (*domain.School, error) {
+	student, err := s.orgRepo.GetStudent(ctx, studentID)
+	if err != nil || student == nil {
+		return nil, err
+	}
+	class, err := s.orgRepo.GetClass(ctx, student.ClassID)
+	if err != nil || class == nil {
+		return nil, err
+	}
+	grade, err := s.orgRepo.GetGrade(ctx, class.GradeID)
+	if err != nil || grade == nil {
+		return nil, err
+	}
+	return s.orgRepo.GetSchool(ctx, grade.SchoolID)
+}
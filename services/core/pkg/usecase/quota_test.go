@@ -0,0 +1,88 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/ratelimit"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_SchoolQuotas(t *testing.T) {
+	fx := fixtures.NewSchool().WithSettings(domain.SchoolSettings{
+		Quotas: domain.SchoolQuotas{SubmissionsPerMinute: 1, ExportJobsPerDay: 1},
+	}).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	service.SetQuotas(ratelimit.NewLimiter())
+	ctx := context.Background()
+
+	_, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Quota",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "Q1", Points: 1}, {Prompt: "Q2", Points: 1}},
+		StudentIDs: []domain.StudentID{fx.Student(0)},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+
+	submit := func(q domain.Question) error {
+		_, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: q.TestID, QuestionID: q.ID, StudentID: fx.Student(0), Response: "x"})
+		return err
+	}
+	if err := submit(questions[0]); err != nil {
+		t.Fatalf("first submission failed: %v", err)
+	}
+	if err := submit(questions[1]); err != errs.ErrQuotaExceeded {
+		t.Fatalf("expected ErrQuotaExceeded, got %v", err)
+	}
+
+	if err := service.ReserveExportJob(ctx, fx.Teacher(0)); err != nil {
+		t.Fatalf("first export failed: %v", err)
+	}
+	if err := service.ReserveExportJob(ctx, fx.Teacher(0)); err != errs.ErrQuotaExceeded {
+		t.Fatalf("expected ErrQuotaExceeded, got %v", err)
+	}
+}
+
+func TestAssessmentService_ResponseLength(t *testing.T) {
+	for _, tc := range []struct {
+		policy  domain.LengthPolicy
+		wantErr error
+		want    string
+	}{
+		{policy: "", wantErr: errs.ErrResponseTooLong},
+		{policy: domain.LengthPolicyTruncate, want: "héllo"},
+	} {
+		fx := fixtures.NewSchool().WithSettings(domain.SchoolSettings{
+			Quotas: domain.SchoolQuotas{MaxResponseLength: 5, ResponseLengthPolicy: tc.policy},
+		}).Build()
+		service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+		ctx := context.Background()
+		_, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+			Title:      "Essay",
+			TeacherID:  fx.Teacher(0),
+			Questions:  []usecase.QuestionDraft{{Prompt: "Q1", Points: 1}},
+			StudentIDs: []domain.StudentID{fx.Student(0)},
+		})
+		if err != nil {
+			t.Fatalf("CreateTest failed: %v", err)
+		}
+
+		answer := &domain.Answer{TestID: questions[0].TestID, QuestionID: questions[0].ID, StudentID: fx.Student(0), Response: "héllo"}
+		if saved, err := service.SubmitAnswer(ctx, answer); err != nil || saved.Truncated {
+			t.Fatalf("expected a response at the cap to be kept, got %+v, %v", saved, err)
+		}
+		answer = &domain.Answer{TestID: questions[0].TestID, QuestionID: questions[0].ID, StudentID: fx.Student(0), Response: "héllo world"}
+		saved, err := service.SubmitAnswer(ctx, answer)
+		if err != tc.wantErr {
+			t.Fatalf("policy %q: expected %v, got %v", tc.policy, tc.wantErr, err)
+		}
+		if tc.wantErr == nil && (saved.Response != tc.want || !saved.Truncated) {
+			t.Fatalf("policy %q: expected a truncated %q, got %+v", tc.policy, tc.want, saved)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package usecase_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/readmodel"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_ReadModelMatchesRepositories(t *testing.T) {
+	fx := fixtures.NewSchool().WithStudents(3).Build()
+	projected := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	projected.SetReadModel(readmodel.NewProjector(fx.Repo, 0))
+	direct := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+	teacher := fx.Teacher(0)
+
+	passing := domain.Score(6)
+	deadline := time.Now().Add(time.Hour)
+	test, questions, err := projected.CreateTest(ctx, usecase.CreateTestInput{
+		Title:           "Projected",
+		TeacherID:       teacher,
+		Questions:       []usecase.QuestionDraft{{Prompt: "Q1", Points: 5}, {Prompt: "Q2", Points: 5}},
+		StudentIDs:      []domain.StudentID{fx.Student(0), fx.Student(1), fx.Student(2)},
+		PassingScore:    &passing,
+		GradingDeadline: &deadline,
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	compare := func(step string) {
+		t.Helper()
+		want, err := direct.TestStatistics(ctx, teacher, test.ID)
+		if err != nil {
+			t.Fatalf("%s: TestStatistics failed: %v", step, err)
+		}
+		got, err := projected.TestStatistics(ctx, teacher, test.ID)
+		if err != nil {
+			t.Fatalf("%s: projected TestStatistics failed: %v", step, err)
+		}
+		got.ComputedAt = want.ComputedAt
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s: expected statistics %+v, got %+v", step, want, got)
+		}
+		wantOutcomes, _ := direct.TestOutcomes(ctx, teacher, test.ID)
+		if gotOutcomes, _ := projected.TestOutcomes(ctx, teacher, test.ID); !reflect.DeepEqual(gotOutcomes, wantOutcomes) {
+			t.Fatalf("%s: expected outcomes %+v, got %+v", step, wantOutcomes, gotOutcomes)
+		}
+		wantBacklog, _ := direct.ListGradingBacklog(ctx, teacher, 2*time.Hour)
+		if gotBacklog, _ := projected.ListGradingBacklog(ctx, teacher, 2*time.Hour); !reflect.DeepEqual(gotBacklog, wantBacklog) {
+			t.Fatalf("%s: expected backlog %+v, got %+v", step, wantBacklog, gotBacklog)
+		}
+		direct.FlushStatistics()
+		projected.FlushStatistics()
+	}
+
+	compare("empty")
+	for i := range 3 {
+		for _, q := range questions {
+			if _, err := projected.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: q.ID, StudentID: fx.Student(i), Response: "x"}); err != nil {
+				t.Fatalf("SubmitAnswer failed: %v", err)
+			}
+		}
+	}
+	compare("answered")
+	for i, score := range []domain.Score{5, 3} {
+		for _, q := range questions {
+			if _, err := projected.GradeAnswer(ctx, usecase.GradeInput{TeacherID: teacher, TestID: test.ID, QuestionID: q.ID, StudentID: fx.Student(i), Score: score, Completed: i == 0}); err != nil {
+				t.Fatalf("GradeAnswer failed: %v", err)
+			}
+		}
+	}
+	compare("graded")
+	if err := projected.DeleteAnswer(ctx, teacher, test.ID, questions[1].ID, fx.Student(2)); err != nil {
+		t.Fatalf("DeleteAnswer failed: %v", err)
+	}
+	compare("deleted")
+}
@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+// severityThreshold is how many percentage points a grader may lie above or
+// below their colleagues before they are flagged as inconsistent.
+const severityThreshold = 10
+
+// GraderReliability compares the teachers who graded a test, such as the
+// owner and a substitute, so inconsistent grading is spotted before results
+// are released. Each answer is graded once, so graders are compared by the
+// scores they gave to the same questions rather than to the same answers.
+// Automatically graded results are left out.
+type GraderReliability struct {
+	TestID    domain.TestID
+	Graders   []GraderSeverity
+	Questions []QuestionAgreement
+	// Agreement is the mean agreement of the questions graded by more than
+	// one teacher, or nil when there are none.
+	Agreement *float64
+}
+
+// GraderSeverity summarizes the scores one teacher gave. Severity is how many
+// percentage points their scores lie below those of the other graders on the
+// same questions: positive means stricter, negative more lenient.
+type GraderSeverity struct {
+	TeacherID    domain.TeacherID
+	Graded       int
+	MeanPercent  float64
+	Severity     float64
+	Inconsistent bool
+}
+
+// QuestionAgreement compares the graders of one question. Agreement is 1 when
+// every grader gave the same average percentage and falls towards 0 as their
+// averages drift apart by up to the full points.
+type QuestionAgreement struct {
+	QuestionID domain.QuestionID
+	Graders    int
+	Spread     float64
+	Agreement  float64
+}
+
+// GraderReport computes the inter-rater reliability of a test, ensuring the
+// teacher may access it.
+func (s *AssessmentService) GraderReport(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID) (*GraderReliability, error) {
+	ctx, span := s.trace(ctx, "GraderReport")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	questions, err := s.testRepo.ListQuestions(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := s.resultRepo.SnapshotGrading(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make(map[domain.QuestionID]domain.Points, len(questions))
+	for _, q := range questions {
+		points[q.ID] = q.Points
+	}
+	questionOf := make(map[domain.AnswerID]domain.QuestionID, len(snapshot.Answers))
+	for _, a := range snapshot.Answers {
+		questionOf[a.ID] = a.QuestionID
+	}
+
+	// percents[question][grader] holds the percentage scores a grader gave.
+	percents := make(map[domain.QuestionID]map[domain.TeacherID][]float64)
+	for _, res := range snapshot.Results {
+		questionID, ok := questionOf[res.AnswerID]
+		if !ok || res.GradedBy == "" || points[questionID] <= 0 {
+			continue
+		}
+		score := res.Score
+		if res.RawScore != nil {
+			score = *res.RawScore
+		}
+		if percents[questionID] == nil {
+			percents[questionID] = make(map[domain.TeacherID][]float64)
+		}
+		percents[questionID][res.GradedBy] = append(percents[questionID][res.GradedBy], 100*float64(score)/float64(points[questionID]))
+	}
+
+	report := &GraderReliability{TestID: testID}
+	graders := make(map[domain.TeacherID]*graderTally)
+	var agreements []float64
+	for _, q := range questions {
+		byGrader := percents[q.ID]
+		means := make(map[domain.TeacherID]float64, len(byGrader))
+		var overall float64
+		for grader, scores := range byGrader {
+			means[grader], _ = meanAndStdDev(scores)
+			overall += means[grader]
+			tally := graders[grader]
+			if tally == nil {
+				tally = &graderTally{}
+				graders[grader] = tally
+			}
+			tally.percents = append(tally.percents, scores...)
+		}
+		if len(means) < 2 {
+			continue
+		}
+		overall /= float64(len(means))
+
+		agreement := QuestionAgreement{QuestionID: q.ID, Graders: len(means)}
+		lowest, highest := math.Inf(1), math.Inf(-1)
+		var gaps float64
+		pairs := 0
+		for grader, mean := range means {
+			lowest, highest = math.Min(lowest, mean), math.Max(highest, mean)
+			for other, otherMean := range means {
+				if grader < other {
+					gaps += math.Abs(mean - otherMean)
+					pairs++
+				}
+			}
+			weight := float64(len(byGrader[grader]))
+			graders[grader].deviation += (overall - mean) * weight
+			graders[grader].shared += weight
+		}
+		agreement.Spread = highest - lowest
+		agreement.Agreement = 1 - gaps/float64(pairs)/100
+		report.Questions = append(report.Questions, agreement)
+		agreements = append(agreements, agreement.Agreement)
+	}
+	if len(agreements) > 0 {
+		mean, _ := meanAndStdDev(agreements)
+		report.Agreement = &mean
+	}
+
+	for grader, tally := range graders {
+		severity := GraderSeverity{TeacherID: grader, Graded: len(tally.percents)}
+		severity.MeanPercent, _ = meanAndStdDev(tally.percents)
+		if tally.shared > 0 {
+			severity.Severity = tally.deviation / tally.shared
+			severity.Inconsistent = math.Abs(severity.Severity) >= severityThreshold
+		}
+		report.Graders = append(report.Graders, severity)
+	}
+	sort.Slice(report.Graders, func(i, j int) bool { return report.Graders[i].TeacherID < report.Graders[j].TeacherID })
+	return report, nil
+}
+
+// graderTally accumulates the scores of one grader. deviation sums how far
+// the grader lies below the average of the graders of each shared question,
+// weighted by the results they graded there, and shared sums the weights.
+type graderTally struct {
+	percents  []float64
+	deviation float64
+	shared    float64
+}
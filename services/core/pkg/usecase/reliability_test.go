@@ -0,0 +1,77 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_GraderReport(t *testing.T) {
+	fx := fixtures.NewSchool().WithTeachers(3).WithStudents(4).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	service.SetDelegations(fx.Repo)
+	delegations := usecase.NewDelegationService(fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+	owner, substitute := fx.Teacher(0), fx.Teacher(1)
+
+	students := []domain.StudentID{fx.Student(0), fx.Student(1), fx.Student(2), fx.Student(3)}
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Essays",
+		TeacherID:  owner,
+		Questions:  []usecase.QuestionDraft{{Prompt: "Argue for recycling", Points: 10}, {Prompt: "Argue against", Points: 10}},
+		StudentIDs: students,
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	if _, err := delegations.Grant(ctx, usecase.DelegationInput{TeacherID: owner, DelegateID: substitute, ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("Grant failed: %v", err)
+	}
+
+	grade := func(grader domain.TeacherID, question domain.QuestionID, student domain.StudentID, score domain.Score) {
+		t.Helper()
+		if _, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: question, StudentID: student, Response: "essay"}); err != nil {
+			t.Fatalf("SubmitAnswer failed: %v", err)
+		}
+		if _, err := service.GradeAnswer(ctx, usecase.GradeInput{TeacherID: grader, TestID: test.ID, QuestionID: question, StudentID: student, Score: score}); err != nil {
+			t.Fatalf("GradeAnswer failed: %v", err)
+		}
+	}
+	grade(owner, questions[0].ID, students[0], 8)
+	grade(owner, questions[0].ID, students[1], 8)
+	grade(substitute, questions[0].ID, students[2], 4)
+	grade(substitute, questions[0].ID, students[3], 4)
+	grade(owner, questions[1].ID, students[0], 5)
+
+	report, err := service.GraderReport(ctx, owner, test.ID)
+	if err != nil {
+		t.Fatalf("GraderReport failed: %v", err)
+	}
+	if len(report.Questions) != 1 || report.Questions[0].QuestionID != questions[0].ID || report.Questions[0].Spread != 40 {
+		t.Fatalf("expected only the shared question to be compared, got %+v", report.Questions)
+	}
+	if report.Agreement == nil || math.Abs(*report.Agreement-0.6) > 1e-9 {
+		t.Fatalf("expected an agreement of 0.6, got %v", report.Agreement)
+	}
+	if len(report.Graders) != 2 {
+		t.Fatalf("expected two graders, got %+v", report.Graders)
+	}
+	lenient, strict := report.Graders[0], report.Graders[1]
+	if lenient.TeacherID != owner || lenient.Graded != 3 || lenient.Severity != -20 || !lenient.Inconsistent {
+		t.Fatalf("expected the owner to grade 20 points more leniently, got %+v", lenient)
+	}
+	if strict.TeacherID != substitute || strict.MeanPercent != 40 || strict.Severity != 20 || !strict.Inconsistent {
+		t.Fatalf("expected the substitute to grade 20 points more strictly, got %+v", strict)
+	}
+
+	if _, err := service.GraderReport(ctx, fx.Teacher(2), test.ID); !errors.Is(err, errs.ErrForbiddenTeacher) {
+		t.Fatalf("expected ErrForbiddenTeacher for an unrelated teacher, got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/id"
+)
+
+const (
+	// maxResultsLinkLifetime bounds how long a results link may stay valid.
+	maxResultsLinkLifetime = 90 * 24 * time.Hour
+	// publicResultsMinStudents is the fewest graded students whose scores a
+	// results link reveals; smaller groups would let parents tell who scored
+	// what.
+	publicResultsMinStudents = 5
+)
+
+// PublicResults are the anonymized results of a test shown through a
+// results link. The score figures are percentages of the test's points and
+// are left out, with Suppressed set, while fewer than five students are
+// graded. PassRate is nil without a passing score.
+type PublicResults struct {
+	Title      string
+	Questions  int
+	MaxScore   domain.Points
+	Students   int
+	Suppressed bool
+	Mean       float64
+	Median     float64
+	Histogram  []int
+	PassRate   *float64
+	ExpiresAt  time.Time
+	ComputedAt time.Time
+}
+
+// CreateResultsLink creates a results link for the test, valid until
+// expiresAt, ensuring teacher ownership. It replaces any previous link of
+// the test, which stops working.
+func (s *AssessmentService) CreateResultsLink(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID, expiresAt time.Time) (*domain.ResultsLink, error) {
+	ctx, span := s.trace(ctx, "CreateResultsLink")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if !expiresAt.After(now) || expiresAt.Sub(now) > maxResultsLinkLifetime {
+		return nil, errs.ErrInvalidResultsLink
+	}
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The token names its test so it can be resolved without an index.
+	test.ResultsLink = &domain.ResultsLink{
+		Token:     base64.RawURLEncoding.EncodeToString([]byte(testID)) + "." + id.New(),
+		ExpiresAt: expiresAt.UTC(),
+		CreatedAt: now,
+	}
+	test.UpdatedAt = now
+	if err := s.testRepo.UpdateTest(ctx, test); err != nil {
+		return nil, err
+	}
+	return test.ResultsLink, nil
+}
+
+// ResultsLink returns the test's results link, ensuring teacher ownership.
+// An expired link is reported as missing.
+func (s *AssessmentService) ResultsLink(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID) (*domain.ResultsLink, error) {
+	ctx, span := s.trace(ctx, "ResultsLink")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	if !test.ResultsLink.Active(time.Now()) {
+		return nil, errs.ErrResultsLinkNotFound
+	}
+	return test.ResultsLink, nil
+}
+
+// RevokeResultsLink stops the test's results link from working, ensuring
+// teacher ownership.
+func (s *AssessmentService) RevokeResultsLink(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID) error {
+	ctx, span := s.trace(ctx, "RevokeResultsLink")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return err
+	}
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return err
+	}
+	if test.ResultsLink == nil {
+		return errs.ErrResultsLinkNotFound
+	}
+	test.ResultsLink = nil
+	test.UpdatedAt = time.Now().UTC()
+	return s.testRepo.UpdateTest(ctx, test)
+}
+
+// PublicResults returns the anonymized results behind a results link token.
+// Unknown, revoked and expired tokens are all reported as not found.
+func (s *AssessmentService) PublicResults(ctx context.Context, token string) (*PublicResults, error) {
+	ctx, span := s.trace(ctx, "PublicResults")
+	defer span.End()
+
+	encoded, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, errs.ErrResultsLinkNotFound
+	}
+	testID, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errs.ErrResultsLinkNotFound
+	}
+	test, err := s.testRepo.GetTest(ctx, domain.TestID(testID))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if test == nil || !test.ResultsLink.Active(now) ||
+		subtle.ConstantTimeCompare([]byte(test.ResultsLink.Token), []byte(token)) != 1 {
+		return nil, errs.ErrResultsLinkNotFound
+	}
+
+	questions, err := s.testRepo.ListQuestions(ctx, test.ID)
+	if err != nil {
+		return nil, err
+	}
+	outcomes, err := s.studentOutcomes(ctx, test)
+	if err != nil {
+		return nil, err
+	}
+
+	results := &PublicResults{
+		Title:      test.Title,
+		Questions:  len(questions),
+		MaxScore:   domain.TotalPoints(questions),
+		ExpiresAt:  test.ResultsLink.ExpiresAt,
+		ComputedAt: now,
+	}
+	group := ComparisonGroup{TestID: test.ID}
+	var decided, passed int
+	for _, o := range outcomes {
+		if o.Answered == 0 || o.Graded < o.Answered {
+			continue
+		}
+		group.scores = append(group.scores, o.Percent())
+		if o.Passed != nil {
+			decided++
+			if *o.Passed {
+				passed++
+			}
+		}
+	}
+	group.describe()
+	results.Students = group.Students
+	if results.Students < publicResultsMinStudents {
+		results.Suppressed = true
+		return results, nil
+	}
+	results.Mean, results.Median = group.Mean, median(group.scores)
+	results.Histogram = group.Histogram
+	if test.PassingScore != nil && decided > 0 {
+		rate := float64(passed) / float64(decided)
+		results.PassRate = &rate
+	}
+	return results, nil
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 0 {
+		return (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+	return sorted[n/2]
+}
@@ -0,0 +1,89 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_PublicResults(t *testing.T) {
+	fx := fixtures.NewSchool().WithStudents(6).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	students := make([]domain.StudentID, 6)
+	for i := range students {
+		students[i] = fx.Student(i)
+	}
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Shared",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "Q1", Points: 10}},
+		StudentIDs: students,
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	grade := func(i int, score domain.Score) {
+		t.Helper()
+		if _, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: fx.Student(i), Response: "x"}); err != nil {
+			t.Fatalf("SubmitAnswer failed: %v", err)
+		}
+		if _, err := service.GradeAnswer(ctx, usecase.GradeInput{TeacherID: fx.Teacher(0), TestID: test.ID, QuestionID: questions[0].ID, StudentID: fx.Student(i), Score: score, Completed: true}); err != nil {
+			t.Fatalf("GradeAnswer failed: %v", err)
+		}
+	}
+
+	if _, err := service.CreateResultsLink(ctx, fx.Teacher(0), test.ID, time.Now().Add(100*24*time.Hour)); err != errs.ErrInvalidResultsLink {
+		t.Fatalf("expected ErrInvalidResultsLink beyond the maximum lifetime, got %v", err)
+	}
+	link, err := service.CreateResultsLink(ctx, fx.Teacher(0), test.ID, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("CreateResultsLink failed: %v", err)
+	}
+
+	for i, score := range []domain.Score{2, 4, 6, 8} {
+		grade(i, score)
+	}
+	results, err := service.PublicResults(ctx, link.Token)
+	if err != nil {
+		t.Fatalf("PublicResults failed: %v", err)
+	}
+	if !results.Suppressed || results.Students != 4 || results.Histogram != nil {
+		t.Fatalf("expected scores of four students to be suppressed, got %+v", results)
+	}
+
+	grade(4, 10)
+	results, err = service.PublicResults(ctx, link.Token)
+	if err != nil {
+		t.Fatalf("PublicResults failed: %v", err)
+	}
+	if results.Suppressed || results.Students != 5 || results.Mean != 60 || results.Median != 60 || results.Histogram[9] != 1 {
+		t.Fatalf("unexpected public results: %+v", results)
+	}
+
+	if _, err := service.PublicResults(ctx, link.Token+"x"); err != errs.ErrResultsLinkNotFound {
+		t.Fatalf("expected a tampered token to be rejected, got %v", err)
+	}
+	replaced, err := service.CreateResultsLink(ctx, fx.Teacher(0), test.ID, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("CreateResultsLink failed: %v", err)
+	}
+	if _, err := service.PublicResults(ctx, link.Token); err != errs.ErrResultsLinkNotFound {
+		t.Fatalf("expected the replaced link to stop working, got %v", err)
+	}
+	if err := service.RevokeResultsLink(ctx, fx.Teacher(0), test.ID); err != nil {
+		t.Fatalf("RevokeResultsLink failed: %v", err)
+	}
+	if _, err := service.PublicResults(ctx, replaced.Token); err != errs.ErrResultsLinkNotFound {
+		t.Fatalf("expected the revoked link to stop working, got %v", err)
+	}
+	if _, err := service.ResultsLink(ctx, fx.Teacher(0), test.ID); err != errs.ErrResultsLinkNotFound {
+		t.Fatalf("expected no link after revocation, got %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/audit"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/health"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// RetentionReport lists what a retention pass erased or, for a preview,
+// would erase. Schools without a retention policy are left out.
+type RetentionReport struct {
+	GeneratedAt time.Time
+	DryRun      bool
+	Schools     []SchoolRetention
+}
+
+// SchoolRetention counts the records of one school past its retention
+// periods. AnswersPurged counts answers whose response and note are erased;
+// AnswersDeleted counts answers deleted outright, ResultsDeleted the results
+// deleted with them.
+type SchoolRetention struct {
+	SchoolID       domain.SchoolID
+	Policy         domain.RetentionPolicy
+	AnswersPurged  int
+	AnswersDeleted int
+	ResultsDeleted int
+}
+
+// RunRetention applies the retention policies of every school every
+// interval.
+func (s *AssessmentService) RunRetention(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := s.ApplyRetention(ctx, time.Now().UTC())
+			if err != nil {
+				log.Printf("retention failed: %v", err)
+			}
+			health.Report(ctx, err)
+		}
+	}
+}
+
+// PreviewRetention reports what ApplyRetention would erase at now without
+// changing anything.
+func (s *AssessmentService) PreviewRetention(ctx context.Context, now time.Time) (*RetentionReport, error) {
+	ctx, span := s.trace(ctx, "PreviewRetention")
+	defer span.End()
+
+	return s.retain(ctx, now, true)
+}
+
+// ApplyRetention erases the answers and results older than the retention
+// periods of their school at now. Every erasure is written to the audit log.
+func (s *AssessmentService) ApplyRetention(ctx context.Context, now time.Time) (*RetentionReport, error) {
+	ctx, span := s.trace(ctx, "ApplyRetention")
+	defer span.End()
+
+	return s.retain(ctx, now, false)
+}
+
+func (s *AssessmentService) retain(ctx context.Context, now time.Time, dryRun bool) (*RetentionReport, error) {
+	report := &RetentionReport{GeneratedAt: now, DryRun: dryRun}
+	schools, err := repository.Collect(s.orgRepo.ListSchools(ctx, repository.All))
+	if err != nil {
+		return nil, err
+	}
+	for _, school := range schools {
+		policy := school.Settings.Retention
+		if policy.AnswerYears == 0 && policy.ResultYears == 0 {
+			continue
+		}
+		summary := SchoolRetention{SchoolID: school.ID, Policy: policy}
+		teachers, err := repository.Collect(s.orgRepo.ListTeachers(ctx, school.ID, repository.All))
+		if err != nil {
+			return nil, err
+		}
+		for _, teacher := range teachers {
+			tests, err := repository.Collect(s.testRepo.ListTestsByTeacher(ctx, teacher.ID, repository.All))
+			if err != nil {
+				return nil, err
+			}
+			for _, test := range tests {
+				if err := s.retainTest(ctx, test.ID, policy, now, dryRun, &summary); err != nil {
+					return nil, err
+				}
+			}
+		}
+		report.Schools = append(report.Schools, summary)
+	}
+	return report, nil
+}
+
+// retainTest erases the answers of a test past policy, adding them to
+// summary. A record's age counts from when its answer or result last changed,
+// whichever is later.
+func (s *AssessmentService) retainTest(ctx context.Context, testID domain.TestID, policy domain.RetentionPolicy, now time.Time, dryRun bool, summary *SchoolRetention) error {
+	snapshot, err := s.resultRepo.SnapshotGrading(ctx, testID)
+	if err != nil {
+		return err
+	}
+	results := make(map[domain.AnswerID]domain.Result, len(snapshot.Results))
+	for _, res := range snapshot.Results {
+		results[res.AnswerID] = res
+	}
+
+	changed := false
+	for _, answer := range snapshot.Answers {
+		lastChanged := answer.UpdatedAt
+		result, graded := results[answer.ID]
+		if graded && result.UpdatedAt.After(lastChanged) {
+			lastChanged = result.UpdatedAt
+		}
+
+		switch {
+		case policy.ResultYears > 0 && lastChanged.Before(now.AddDate(-policy.ResultYears, 0, 0)):
+			summary.AnswersDeleted++
+			if graded {
+				summary.ResultsDeleted++
+			}
+			if dryRun {
+				continue
+			}
+			if err := s.answerRepo.DeleteAnswer(ctx, testID, answer.QuestionID, answer.StudentID); err != nil {
+				return err
+			}
+			s.record(domain.AnswerDeleted{TestID: testID, QuestionID: answer.QuestionID, StudentID: answer.StudentID})
+			changed = true
+			s.audit(ctx, audit.AnswerErased(answer))
+		case policy.AnswerYears > 0 && answer.PurgedAt == nil && lastChanged.Before(now.AddDate(-policy.AnswerYears, 0, 0)):
+			summary.AnswersPurged++
+			if dryRun {
+				continue
+			}
+			purged := now
+			answer.Response, answer.Note = "", ""
+			answer.PurgedAt = &purged
+			if err := s.answerRepo.UpsertAnswer(ctx, &answer); err != nil {
+				return err
+			}
+			changed = true
+			s.audit(ctx, audit.AnswerPurged(answer))
+		}
+	}
+	if changed {
+		s.stats.entries.Invalidate(testID)
+		s.record(domain.TestChanged{TestID: testID})
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_ApplyRetention(t *testing.T) {
+	fx := fixtures.NewSchool().WithStudents(2).WithSettings(domain.SchoolSettings{
+		Retention: domain.RetentionPolicy{AnswerYears: 5, ResultYears: 10},
+	}).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "History",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "Describe the Meiji Restoration", Points: 10}},
+		StudentIDs: []domain.StudentID{fx.Student(0), fx.Student(1)},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	questionID := questions[0].ID
+	for _, student := range []domain.StudentID{fx.Student(0), fx.Student(1)} {
+		if _, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questionID, StudentID: student, Response: "essay", Note: "draft"}); err != nil {
+			t.Fatalf("SubmitAnswer failed: %v", err)
+		}
+	}
+	if _, err := service.GradeAnswer(ctx, usecase.GradeInput{TeacherID: fx.Teacher(0), TestID: test.ID, QuestionID: questionID, StudentID: fx.Student(0), Score: 7}); err != nil {
+		t.Fatalf("GradeAnswer failed: %v", err)
+	}
+
+	sixYears := time.Now().AddDate(6, 0, 0)
+	preview, err := service.PreviewRetention(ctx, sixYears)
+	if err != nil {
+		t.Fatalf("PreviewRetention failed: %v", err)
+	}
+	if !preview.DryRun || len(preview.Schools) != 1 || preview.Schools[0].AnswersPurged != 2 || preview.Schools[0].AnswersDeleted != 0 {
+		t.Fatalf("expected a preview purging two answers, got %+v", preview)
+	}
+	if answer, _ := fx.Repo.GetAnswer(ctx, test.ID, questionID, fx.Student(0)); answer.Response != "essay" || answer.PurgedAt != nil {
+		t.Fatalf("expected the preview to keep the answer, got %+v", answer)
+	}
+
+	if _, err := service.ApplyRetention(ctx, sixYears); err != nil {
+		t.Fatalf("ApplyRetention failed: %v", err)
+	}
+	answer, _ := fx.Repo.GetAnswer(ctx, test.ID, questionID, fx.Student(0))
+	if answer == nil || answer.Response != "" || answer.Note != "" || answer.PurgedAt == nil {
+		t.Fatalf("expected the response and note to be erased, got %+v", answer)
+	}
+	if result, _ := fx.Repo.GetResult(ctx, answer.ID); result == nil || result.Score != 7 {
+		t.Fatalf("expected the result to be kept, got %+v", result)
+	}
+	again, _ := service.PreviewRetention(ctx, sixYears)
+	if again.Schools[0].AnswersPurged != 0 {
+		t.Fatalf("expected purged answers not to be purged again, got %+v", again.Schools[0])
+	}
+
+	report, err := service.ApplyRetention(ctx, time.Now().AddDate(11, 0, 0))
+	if err != nil {
+		t.Fatalf("ApplyRetention failed: %v", err)
+	}
+	if got := report.Schools[0]; got.AnswersDeleted != 2 || got.ResultsDeleted != 1 {
+		t.Fatalf("expected two answers and one result to be deleted, got %+v", got)
+	}
+	if answer, _ := fx.Repo.GetAnswer(ctx, test.ID, questionID, fx.Student(0)); answer != nil {
+		t.Fatalf("expected the answer to be deleted, got %+v", answer)
+	}
+}
@@ -0,0 +1,310 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/export"
+	"github.com/sky0621/go_work_sample/core/pkg/id"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// RubricService manages teachers' rubrics and feedback template banks and
+// moves them between teachers and schools as JSON documents.
+type RubricService struct {
+	orgRepo    repository.OrganizationReader
+	rubricRepo repository.RubricRepository
+}
+
+// NewRubricService constructs a rubric service.
+func NewRubricService(org repository.OrganizationReader, rubrics repository.RubricRepository) *RubricService {
+	return &RubricService{orgRepo: org, rubricRepo: rubrics}
+}
+
+// RubricInput describes a new rubric.
+type RubricInput struct {
+	TeacherID domain.TeacherID
+	Title     string
+	Criteria  []CriterionDraft
+}
+
+// CriterionDraft holds criterion details when creating a rubric.
+type CriterionDraft struct {
+	Title       string
+	Description string
+	Points      domain.Points
+}
+
+// FeedbackTemplateInput describes a new feedback template. RubricID and
+// CriterionID are optional.
+type FeedbackTemplateInput struct {
+	TeacherID   domain.TeacherID
+	RubricID    domain.RubricID
+	CriterionID domain.CriterionID
+	Title       string
+	Body        string
+}
+
+// BankImport reports what an import created, mapping the IDs found in the
+// document to the newly assigned ones.
+type BankImport struct {
+	Rubrics     []domain.Rubric
+	Templates   []domain.FeedbackTemplate
+	RubricIDs   map[string]domain.RubricID
+	TemplateIDs map[string]domain.FeedbackTemplateID
+}
+
+// CreateRubric stores a rubric owned by the teacher.
+func (s *RubricService) CreateRubric(ctx context.Context, input RubricInput) (*domain.Rubric, error) {
+	if err := s.ensureTeacherExists(ctx, input.TeacherID); err != nil {
+		return nil, err
+	}
+
+	criteria := make([]export.CriterionEntry, len(input.Criteria))
+	for i, c := range input.Criteria {
+		criteria[i] = export.CriterionEntry{Title: c.Title, Description: c.Description, Points: int(c.Points)}
+	}
+	rubric, _, err := newRubric(input.TeacherID, export.RubricEntry{Title: input.Title, Criteria: criteria}, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.rubricRepo.SaveRubrics(ctx, []domain.Rubric{*rubric}, nil); err != nil {
+		return nil, err
+	}
+	return rubric, nil
+}
+
+// ListRubrics lists a page of the teacher's rubrics, oldest first.
+func (s *RubricService) ListRubrics(ctx context.Context, teacherID domain.TeacherID, page repository.PageRequest) (repository.Page[domain.Rubric], error) {
+	if err := s.ensureTeacherExists(ctx, teacherID); err != nil {
+		return repository.Page[domain.Rubric]{}, err
+	}
+	return s.rubricRepo.ListRubrics(ctx, teacherID, page)
+}
+
+// CreateFeedbackTemplate stores a feedback template in the teacher's bank.
+// A linked rubric must belong to the same teacher.
+func (s *RubricService) CreateFeedbackTemplate(ctx context.Context, input FeedbackTemplateInput) (*domain.FeedbackTemplate, error) {
+	if err := s.ensureTeacherExists(ctx, input.TeacherID); err != nil {
+		return nil, err
+	}
+	title, body := strings.TrimSpace(input.Title), strings.TrimSpace(input.Body)
+	if title == "" || body == "" || (input.RubricID == "" && input.CriterionID != "") {
+		return nil, errs.ErrInvalidRubric
+	}
+
+	if input.RubricID != "" {
+		rubric, err := s.rubricRepo.GetRubric(ctx, input.RubricID)
+		if err != nil {
+			return nil, err
+		}
+		if rubric == nil || rubric.TeacherID != input.TeacherID {
+			return nil, errs.ErrRubricNotFound
+		}
+		if input.CriterionID != "" && !hasCriterion(rubric, input.CriterionID) {
+			return nil, errs.ErrInvalidRubric
+		}
+	}
+
+	tmpl := &domain.FeedbackTemplate{
+		ID:          domain.FeedbackTemplateID(id.New()),
+		TeacherID:   input.TeacherID,
+		RubricID:    input.RubricID,
+		CriterionID: input.CriterionID,
+		Title:       title,
+		Body:        body,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := s.rubricRepo.SaveRubrics(ctx, nil, []domain.FeedbackTemplate{*tmpl}); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// ListFeedbackTemplates lists a page of the teacher's feedback templates,
+// oldest first.
+func (s *RubricService) ListFeedbackTemplates(ctx context.Context, teacherID domain.TeacherID, page repository.PageRequest) (repository.Page[domain.FeedbackTemplate], error) {
+	if err := s.ensureTeacherExists(ctx, teacherID); err != nil {
+		return repository.Page[domain.FeedbackTemplate]{}, err
+	}
+	return s.rubricRepo.ListFeedbackTemplates(ctx, teacherID, page)
+}
+
+// ExportBank renders every rubric and feedback template of the teacher as a
+// portable bank.
+func (s *RubricService) ExportBank(ctx context.Context, teacherID domain.TeacherID) (*export.RubricBank, error) {
+	rubrics, err := repository.Collect(s.ListRubrics(ctx, teacherID, repository.All))
+	if err != nil {
+		return nil, err
+	}
+	templates, err := repository.Collect(s.rubricRepo.ListFeedbackTemplates(ctx, teacherID, repository.All))
+	if err != nil {
+		return nil, err
+	}
+
+	bank := &export.RubricBank{
+		Version:    export.RubricBankVersion,
+		ExportedAt: time.Now().UTC(),
+		Rubrics:    make([]export.RubricEntry, len(rubrics)),
+		Templates:  make([]export.FeedbackTemplateEntry, len(templates)),
+	}
+	for i, rubric := range rubrics {
+		entry := export.RubricEntry{
+			RubricID: string(rubric.ID),
+			Title:    rubric.Title,
+			Criteria: make([]export.CriterionEntry, len(rubric.Criteria)),
+		}
+		for j, c := range rubric.Criteria {
+			entry.Criteria[j] = export.CriterionEntry{
+				CriterionID: string(c.ID),
+				Title:       c.Title,
+				Description: c.Description,
+				Points:      int(c.Points),
+			}
+		}
+		bank.Rubrics[i] = entry
+	}
+	for i, tmpl := range templates {
+		bank.Templates[i] = export.FeedbackTemplateEntry{
+			TemplateID:  string(tmpl.ID),
+			RubricID:    string(tmpl.RubricID),
+			CriterionID: string(tmpl.CriterionID),
+			Title:       tmpl.Title,
+			Body:        tmpl.Body,
+		}
+	}
+	return bank, nil
+}
+
+// ImportBank copies a bank into the teacher's own rubrics and templates. Every
+// rubric, criterion and template gets a fresh ID and template links are
+// remapped accordingly, so the same bank can be imported repeatedly and by
+// teachers of other schools. The import is all or nothing.
+func (s *RubricService) ImportBank(ctx context.Context, teacherID domain.TeacherID, bank export.RubricBank) (*BankImport, error) {
+	if err := s.ensureTeacherExists(ctx, teacherID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	result := &BankImport{
+		RubricIDs:   make(map[string]domain.RubricID, len(bank.Rubrics)),
+		TemplateIDs: make(map[string]domain.FeedbackTemplateID, len(bank.Templates)),
+	}
+	criterionIDs := make(map[string]map[string]domain.CriterionID, len(bank.Rubrics))
+
+	for _, entry := range bank.Rubrics {
+		if _, dup := result.RubricIDs[entry.RubricID]; dup || entry.RubricID == "" {
+			return nil, errs.ErrInvalidRubric
+		}
+		rubric, criteria, err := newRubric(teacherID, entry, now)
+		if err != nil {
+			return nil, err
+		}
+		result.RubricIDs[entry.RubricID] = rubric.ID
+		criterionIDs[entry.RubricID] = criteria
+		result.Rubrics = append(result.Rubrics, *rubric)
+	}
+
+	for _, entry := range bank.Templates {
+		if _, dup := result.TemplateIDs[entry.TemplateID]; dup || entry.TemplateID == "" {
+			return nil, errs.ErrInvalidRubric
+		}
+		title, body := strings.TrimSpace(entry.Title), strings.TrimSpace(entry.Body)
+		if title == "" || body == "" {
+			return nil, errs.ErrInvalidRubric
+		}
+
+		tmpl := domain.FeedbackTemplate{
+			ID:        domain.FeedbackTemplateID(id.New()),
+			TeacherID: teacherID,
+			Title:     title,
+			Body:      body,
+			CreatedAt: now,
+		}
+		if entry.RubricID != "" {
+			rubricID, ok := result.RubricIDs[entry.RubricID]
+			if !ok {
+				return nil, errs.ErrInvalidRubric
+			}
+			tmpl.RubricID = rubricID
+			if entry.CriterionID != "" {
+				criterionID, ok := criterionIDs[entry.RubricID][entry.CriterionID]
+				if !ok {
+					return nil, errs.ErrInvalidRubric
+				}
+				tmpl.CriterionID = criterionID
+			}
+		} else if entry.CriterionID != "" {
+			return nil, errs.ErrInvalidRubric
+		}
+		result.TemplateIDs[entry.TemplateID] = tmpl.ID
+		result.Templates = append(result.Templates, tmpl)
+	}
+
+	if err := s.rubricRepo.SaveRubrics(ctx, result.Rubrics, result.Templates); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// newRubric validates entry and builds a rubric with fresh IDs, returning the
+// mapping from the entry's criterion IDs to the new ones.
+func newRubric(teacherID domain.TeacherID, entry export.RubricEntry, now time.Time) (*domain.Rubric, map[string]domain.CriterionID, error) {
+	title := strings.TrimSpace(entry.Title)
+	if title == "" || len(entry.Criteria) == 0 {
+		return nil, nil, errs.ErrInvalidRubric
+	}
+
+	rubric := &domain.Rubric{
+		ID:        domain.RubricID(id.New()),
+		TeacherID: teacherID,
+		Title:     title,
+		Criteria:  make([]domain.RubricCriterion, len(entry.Criteria)),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	mapping := make(map[string]domain.CriterionID, len(entry.Criteria))
+	for i, c := range entry.Criteria {
+		ctitle := strings.TrimSpace(c.Title)
+		if ctitle == "" || c.Points < 0 {
+			return nil, nil, errs.ErrInvalidRubric
+		}
+		rubric.Criteria[i] = domain.RubricCriterion{
+			ID:          domain.CriterionID(id.New()),
+			Title:       ctitle,
+			Description: strings.TrimSpace(c.Description),
+			Points:      domain.Points(c.Points),
+		}
+		if c.CriterionID != "" {
+			if _, dup := mapping[c.CriterionID]; dup {
+				return nil, nil, errs.ErrInvalidRubric
+			}
+			mapping[c.CriterionID] = rubric.Criteria[i].ID
+		}
+	}
+	return rubric, mapping, nil
+}
+
+func hasCriterion(rubric *domain.Rubric, criterionID domain.CriterionID) bool {
+	for _, c := range rubric.Criteria {
+		if c.ID == criterionID {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *RubricService) ensureTeacherExists(ctx context.Context, teacherID domain.TeacherID) error {
+	teacher, err := s.orgRepo.GetTeacher(ctx, teacherID)
+	if err != nil {
+		return err
+	}
+	if teacher == nil {
+		return errs.ErrTeacherNotFound
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/export"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/memory"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestRubricService_ExportImport(t *testing.T) {
+	seed := fixtures.Merge(
+		fixtures.NewSchool().Seed(),
+		fixtures.NewSchool().WithPrefix("b-").Seed(),
+	)
+	repo := memory.NewRepository(seed)
+	service := usecase.NewRubricService(repo, repo)
+	ctx := context.Background()
+	author, importer := seed.Teachers[0].ID, seed.Teachers[1].ID
+
+	rubric, err := service.CreateRubric(ctx, usecase.RubricInput{
+		TeacherID: author,
+		Title:     "Essay",
+		Criteria:  []usecase.CriterionDraft{{Title: "Thesis", Points: 4}, {Title: "Evidence", Points: 6}},
+	})
+	if err != nil {
+		t.Fatalf("CreateRubric failed: %v", err)
+	}
+	if _, err := service.CreateFeedbackTemplate(ctx, usecase.FeedbackTemplateInput{
+		TeacherID: importer, RubricID: rubric.ID, Title: "Stolen", Body: "x",
+	}); err != errs.ErrRubricNotFound {
+		t.Fatalf("expected ErrRubricNotFound for another teacher's rubric, got %v", err)
+	}
+	if _, err := service.CreateFeedbackTemplate(ctx, usecase.FeedbackTemplateInput{
+		TeacherID: author, RubricID: rubric.ID, CriterionID: rubric.Criteria[1].ID, Title: "Cite sources", Body: "Back claims with quotes.",
+	}); err != nil {
+		t.Fatalf("CreateFeedbackTemplate failed: %v", err)
+	}
+
+	bank, err := service.ExportBank(ctx, author)
+	if err != nil {
+		t.Fatalf("ExportBank failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := export.WriteRubricBank(&buf, *bank); err != nil {
+		t.Fatalf("WriteRubricBank failed: %v", err)
+	}
+	decoded, err := export.ReadRubricBank(&buf)
+	if err != nil {
+		t.Fatalf("ReadRubricBank failed: %v", err)
+	}
+
+	imported, err := service.ImportBank(ctx, importer, *decoded)
+	if err != nil {
+		t.Fatalf("ImportBank failed: %v", err)
+	}
+	if len(imported.Rubrics) != 1 || len(imported.Templates) != 1 {
+		t.Fatalf("expected one rubric and one template, got %d and %d", len(imported.Rubrics), len(imported.Templates))
+	}
+	copied, tmpl := imported.Rubrics[0], imported.Templates[0]
+	if copied.ID == rubric.ID || imported.RubricIDs[string(rubric.ID)] != copied.ID {
+		t.Fatalf("expected a fresh rubric ID mapped from %s, got %s", rubric.ID, copied.ID)
+	}
+	if copied.TeacherID != importer || tmpl.TeacherID != importer {
+		t.Fatal("expected the imported items to belong to the importing teacher")
+	}
+	if tmpl.RubricID != copied.ID || tmpl.CriterionID != copied.Criteria[1].ID {
+		t.Fatalf("expected the template to link to the copied criterion, got %s/%s", tmpl.RubricID, tmpl.CriterionID)
+	}
+
+	decoded.Templates[0].CriterionID = "unknown"
+	if _, err := service.ImportBank(ctx, importer, *decoded); err != errs.ErrInvalidRubric {
+		t.Fatalf("expected ErrInvalidRubric for a dangling criterion, got %v", err)
+	}
+	rubrics, err := repository.Collect(service.ListRubrics(ctx, importer, repository.All))
+	if err != nil {
+		t.Fatalf("ListRubrics failed: %v", err)
+	}
+	if len(rubrics) != 1 {
+		t.Fatalf("expected the failed import to add nothing, got %d rubrics", len(rubrics))
+	}
+}
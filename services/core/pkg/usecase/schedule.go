@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/audit"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+// defaultMaxTestsPerDay is how many scheduled tests a student may sit in one
+// day before teachers are warned.
+const defaultMaxTestsPerDay = 2
+
+// ScheduleConflictKind names how a student's exam schedule is overloaded.
+type ScheduleConflictKind string
+
+const (
+	// ConflictOverlap means the student is assigned another test whose
+	// window overlaps.
+	ConflictOverlap ScheduleConflictKind = "overlap"
+	// ConflictCapacity means the student would sit more tests on Day than
+	// the daily capacity allows.
+	ConflictCapacity ScheduleConflictKind = "capacity"
+)
+
+// ScheduleConflict warns that a student assigned to a test is also assigned
+// Others, tests of any teacher, in the same window or on the same UTC day.
+// Conflicts are warnings: the test is scheduled regardless.
+type ScheduleConflict struct {
+	Kind      ScheduleConflictKind
+	StudentID domain.StudentID
+	Day       time.Time
+	Others    []domain.Test
+}
+
+// SetDailyTestCapacity sets how many scheduled tests a student may sit in
+// one day before ScheduleConflicts reports a capacity conflict. Zero
+// disables the capacity check.
+func (s *AssessmentService) SetDailyTestCapacity(maxTestsPerDay int) {
+	s.maxTestsPerDay = maxTestsPerDay
+}
+
+// SetTestWindow sets or, with nil bounds, clears the window in which students
+// sit a test.
+func (s *AssessmentService) SetTestWindow(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID, opensAt, closesAt *time.Time) (*domain.Test, error) {
+	ctx, span := s.trace(ctx, "SetTestWindow")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+
+	test.OpensAt = utcPtr(opensAt)
+	test.ClosesAt = utcPtr(closesAt)
+	if err := test.Validate(); err != nil {
+		return nil, err
+	}
+	test.UpdatedAt = time.Now().UTC()
+	if err := s.testRepo.UpdateTest(ctx, test); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, audit.TestUpdated(teacherID, *test, "window"))
+	return test, nil
+}
+
+// ScheduleConflicts checks the assigned students of a scheduled test against
+// the tests every other teacher has scheduled for them, so the owning teacher
+// can be warned about exam-week pile-ups.
+func (s *AssessmentService) ScheduleConflicts(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID) ([]ScheduleConflict, error) {
+	ctx, span := s.trace(ctx, "ScheduleConflicts")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+
+	opens, closes, ok := test.Window()
+	if !ok || len(test.AssignedTo) == 0 {
+		return []ScheduleConflict{}, nil
+	}
+	firstDay := startOfDay(opens)
+	lastDay := startOfDay(closes.Add(-time.Nanosecond))
+
+	candidates, err := s.testRepo.ListTestsInWindow(ctx, firstDay, lastDay.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	assigned := make(map[domain.StudentID]bool, len(test.AssignedTo))
+	for _, studentID := range test.AssignedTo {
+		assigned[studentID] = true
+	}
+	othersByStudent := make(map[domain.StudentID][]domain.Test)
+	for _, other := range candidates {
+		if other.ID == test.ID {
+			continue
+		}
+		for _, studentID := range other.AssignedTo {
+			if assigned[studentID] {
+				othersByStudent[studentID] = append(othersByStudent[studentID], other)
+			}
+		}
+	}
+
+	conflicts := make([]ScheduleConflict, 0)
+	for _, studentID := range test.AssignedTo {
+		others := othersByStudent[studentID]
+		if overlapping := testsInWindow(others, opens, closes); len(overlapping) > 0 {
+			conflicts = append(conflicts, ScheduleConflict{
+				Kind:      ConflictOverlap,
+				StudentID: studentID,
+				Others:    overlapping,
+			})
+		}
+		if s.maxTestsPerDay <= 0 {
+			continue
+		}
+		for day := firstDay; !day.After(lastDay); day = day.AddDate(0, 0, 1) {
+			sameDay := testsInWindow(others, day, day.AddDate(0, 0, 1))
+			if len(sameDay)+1 > s.maxTestsPerDay {
+				conflicts = append(conflicts, ScheduleConflict{
+					Kind:      ConflictCapacity,
+					StudentID: studentID,
+					Day:       day,
+					Others:    sameDay,
+				})
+			}
+		}
+	}
+	return conflicts, nil
+}
+
+// testsInWindow returns the tests whose window overlaps [from, to).
+func testsInWindow(tests []domain.Test, from, to time.Time) []domain.Test {
+	var out []domain.Test
+	for _, t := range tests {
+		opens, closes, ok := t.Window()
+		if ok && opens.Before(to) && from.Before(closes) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func utcPtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	v := t.UTC()
+	return &v
+}
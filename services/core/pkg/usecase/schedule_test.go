@@ -0,0 +1,147 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_ScheduleConflicts(t *testing.T) {
+	fx := fixtures.NewSchool().WithTeachers(2).WithStudents(2).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	day := time.Date(2026, 6, 8, 0, 0, 0, 0, time.UTC)
+	at := func(hour int) *time.Time {
+		t := day.Add(time.Duration(hour) * time.Hour)
+		return &t
+	}
+	create := func(teacher int, title string, students []domain.StudentID, opens, closes *time.Time) *domain.Test {
+		t.Helper()
+		test, _, err := service.CreateTest(ctx, usecase.CreateTestInput{
+			Title:      title,
+			TeacherID:  fx.Teacher(teacher),
+			Questions:  []usecase.QuestionDraft{{Prompt: "Q", Points: 5}},
+			StudentIDs: students,
+			OpensAt:    opens,
+			ClosesAt:   closes,
+		})
+		if err != nil {
+			t.Fatalf("CreateTest failed: %v", err)
+		}
+		return test
+	}
+
+	math := create(0, "Math", []domain.StudentID{fx.Student(0), fx.Student(1)}, at(9), at(11))
+	conflicts, err := service.ScheduleConflicts(ctx, fx.Teacher(0), math.ID)
+	if err != nil {
+		t.Fatalf("ScheduleConflicts failed: %v", err)
+	}
+	if len(conflicts) != 0 {
+		t.Fatalf("expected no conflicts for the first test, got %+v", conflicts)
+	}
+
+	history := create(1, "History", []domain.StudentID{fx.Student(0)}, at(10), at(12))
+	conflicts, err = service.ScheduleConflicts(ctx, fx.Teacher(1), history.ID)
+	if err != nil {
+		t.Fatalf("ScheduleConflicts failed: %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].Kind != usecase.ConflictOverlap || conflicts[0].StudentID != fx.Student(0) {
+		t.Fatalf("expected one overlap for student 0, got %+v", conflicts)
+	}
+	if len(conflicts[0].Others) != 1 || conflicts[0].Others[0].ID != math.ID {
+		t.Fatalf("expected the overlap to name the other teacher's test, got %+v", conflicts[0].Others)
+	}
+
+	// Moving the test later in the day clears the overlap, but a third test
+	// that day exceeds the default capacity of two.
+	if _, err := service.SetTestWindow(ctx, fx.Teacher(1), history.ID, at(13), at(14)); err != nil {
+		t.Fatalf("SetTestWindow failed: %v", err)
+	}
+	create(1, "Art", []domain.StudentID{fx.Student(0)}, at(15), at(16))
+	conflicts, err = service.ScheduleConflicts(ctx, fx.Teacher(0), math.ID)
+	if err != nil {
+		t.Fatalf("ScheduleConflicts failed: %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].Kind != usecase.ConflictCapacity || !conflicts[0].Day.Equal(day) || len(conflicts[0].Others) != 2 {
+		t.Fatalf("expected one capacity conflict for student 0, got %+v", conflicts)
+	}
+
+	service.SetDailyTestCapacity(0)
+	conflicts, err = service.ScheduleConflicts(ctx, fx.Teacher(0), math.ID)
+	if err != nil {
+		t.Fatalf("ScheduleConflicts failed: %v", err)
+	}
+	if len(conflicts) != 0 {
+		t.Fatalf("expected no conflicts with the capacity check disabled, got %+v", conflicts)
+	}
+
+	if _, err := service.SetTestWindow(ctx, fx.Teacher(0), math.ID, at(11), at(9)); !errors.Is(err, errs.ErrInvalidTest) {
+		t.Fatalf("expected ErrInvalidTest for a window closing before it opens, got %v", err)
+	}
+	if _, err := service.ScheduleConflicts(ctx, fx.Teacher(1), math.ID); !errors.Is(err, errs.ErrForbiddenTeacher) {
+		t.Fatalf("expected ErrForbiddenTeacher for another teacher's test, got %v", err)
+	}
+}
+
+func TestAssessmentService_SubmitAnswerWindow(t *testing.T) {
+	fx := fixtures.NewSchool().WithStudents(1).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	create := func(opensAt, closesAt time.Time) (*domain.Test, []domain.Question) {
+		t.Helper()
+		test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+			Title:      "Windowed",
+			TeacherID:  fx.Teacher(0),
+			Questions:  []usecase.QuestionDraft{{Prompt: "Q1", Points: 1}},
+			StudentIDs: []domain.StudentID{fx.Student(0)},
+			OpensAt:    &opensAt,
+			ClosesAt:   &closesAt,
+		})
+		if err != nil {
+			t.Fatalf("CreateTest failed: %v", err)
+		}
+		return test, questions
+	}
+	submit := func(test *domain.Test, questions []domain.Question) error {
+		_, err := service.SubmitAnswer(ctx, &domain.Answer{
+			TestID:     test.ID,
+			QuestionID: questions[0].ID,
+			StudentID:  fx.Student(0),
+			Response:   "answer",
+		})
+		return err
+	}
+
+	cases := []struct {
+		name              string
+		opensAt, closesAt time.Time
+		want              domain.Availability
+		err               error
+	}{
+		{"upcoming", now.Add(time.Hour), now.Add(2 * time.Hour), domain.TestUpcoming, errs.ErrTestClosed},
+		{"open", now.Add(-time.Hour), now.Add(time.Hour), domain.TestOpen, nil},
+		{"closed", now.Add(-2 * time.Hour), now.Add(-time.Hour), domain.TestClosed, errs.ErrTestClosed},
+	}
+	for _, tc := range cases {
+		test, questions := create(tc.opensAt, tc.closesAt)
+		if got := test.AvailabilityAt(now); got != tc.want {
+			t.Fatalf("%s: expected availability %s, got %s", tc.name, tc.want, got)
+		}
+		if err := submit(test, questions); err != tc.err {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.err, err)
+		}
+	}
+
+	if got := (domain.Test{}).AvailabilityAt(now); got != domain.TestOpen {
+		t.Fatalf("expected a test without a window to be open, got %s", got)
+	}
+}
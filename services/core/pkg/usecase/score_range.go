@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/audit"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+)
+
+// SetUnboundedScores lets graders of the test award scores below zero or
+// above a question's points, or restores the default range.
+func (s *AssessmentService) SetUnboundedScores(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID, unbounded bool) (*domain.Test, error) {
+	ctx, span := s.trace(ctx, "SetUnboundedScores")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+
+	test.UnboundedScores = unbounded
+	test.UpdatedAt = time.Now().UTC()
+	if err := s.testRepo.UpdateTest(ctx, test); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, audit.TestUpdated(teacherID, *test, "score range"))
+	return test, nil
+}
+
+// checkScoreRange refuses a score outside zero to the question's points,
+// unless the test allows unbounded scores.
+func (s *AssessmentService) checkScoreRange(ctx context.Context, testID domain.TestID, questionID domain.QuestionID, score domain.Score) error {
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return err
+	}
+	if test == nil {
+		return errs.ErrTestNotFound
+	}
+	if test.UnboundedScores {
+		return nil
+	}
+	question, err := s.findQuestion(ctx, testID, questionID)
+	if err != nil {
+		return err
+	}
+	if !score.Within(question.Points) {
+		return errs.ErrScoreOutOfRange
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_GradeAnswerScoreRange(t *testing.T) {
+	fx := fixtures.NewSchool().WithTeachers(2).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Range",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "Q1", Points: 5}},
+		StudentIDs: []domain.StudentID{fx.Student(0)},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	if _, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: fx.Student(0), Response: "a"}); err != nil {
+		t.Fatalf("SubmitAnswer failed: %v", err)
+	}
+
+	grade := func(score domain.Score) error {
+		_, err := service.GradeAnswer(ctx, usecase.GradeInput{TeacherID: fx.Teacher(0), TestID: test.ID, QuestionID: questions[0].ID, StudentID: fx.Student(0), Score: score})
+		return err
+	}
+	for _, score := range []domain.Score{-1, 6, 100} {
+		if err := grade(score); err != errs.ErrScoreOutOfRange {
+			t.Fatalf("expected score %d to be refused, got %v", score, err)
+		}
+	}
+	for _, score := range []domain.Score{0, 5} {
+		if err := grade(score); err != nil {
+			t.Fatalf("expected score %d to be accepted, got %v", score, err)
+		}
+	}
+
+	if _, err := service.SetUnboundedScores(ctx, fx.Teacher(1), test.ID, true); err != errs.ErrForbiddenTeacher {
+		t.Fatalf("expected another teacher to be refused, got %v", err)
+	}
+	if updated, err := service.SetUnboundedScores(ctx, fx.Teacher(0), test.ID, true); err != nil || !updated.UnboundedScores {
+		t.Fatalf("SetUnboundedScores failed: %+v, %v", updated, err)
+	}
+	for _, score := range []domain.Score{-2, 7} {
+		if err := grade(score); err != nil {
+			t.Fatalf("expected score %d to be accepted on an unbounded test, got %v", score, err)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// SessionService keeps a student's in-progress state for a test, such as the
+// questions they flagged to review later.
+type SessionService struct {
+	testRepo    repository.TestReader
+	sessionRepo repository.TestSessionRepository
+}
+
+// NewSessionService constructs a session service.
+func NewSessionService(tests repository.TestReader, sessions repository.TestSessionRepository) *SessionService {
+	return &SessionService{testRepo: tests, sessionRepo: sessions}
+}
+
+// GetSession returns the student's session for a test. A student who has not
+// changed anything yet gets an empty session.
+func (s *SessionService) GetSession(ctx context.Context, studentID domain.StudentID, testID domain.TestID) (*domain.TestSession, error) {
+	if err := s.ensureAssigned(ctx, studentID, testID); err != nil {
+		return nil, err
+	}
+	return s.load(ctx, studentID, testID)
+}
+
+// FlagQuestion marks a question for review, or clears the mark when flagged
+// is false.
+func (s *SessionService) FlagQuestion(ctx context.Context, studentID domain.StudentID, testID domain.TestID, questionID domain.QuestionID, flagged bool) (*domain.TestSession, error) {
+	if err := s.ensureAssigned(ctx, studentID, testID); err != nil {
+		return nil, err
+	}
+	found, err := s.testRepo.HasQuestion(ctx, testID, questionID)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errs.ErrQuestionNotFound
+	}
+
+	session, err := s.load(ctx, studentID, testID)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := session.Flagged[:0]
+	for _, qid := range session.Flagged {
+		if qid != questionID {
+			kept = append(kept, qid)
+		}
+	}
+	session.Flagged = kept
+	if flagged {
+		session.Flagged = append(session.Flagged, questionID)
+	}
+
+	now := time.Now().UTC()
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = now
+	}
+	session.UpdatedAt = now
+	if err := s.sessionRepo.SaveTestSession(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (s *SessionService) load(ctx context.Context, studentID domain.StudentID, testID domain.TestID) (*domain.TestSession, error) {
+	session, err := s.sessionRepo.GetTestSession(ctx, testID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		session = &domain.TestSession{TestID: testID, StudentID: studentID}
+	}
+	return session, nil
+}
+
+func (s *SessionService) ensureAssigned(ctx context.Context, studentID domain.StudentID, testID domain.TestID) error {
+	assigned, err := s.testRepo.IsStudentAssigned(ctx, testID, studentID)
+	if err != nil {
+		return err
+	}
+	if !assigned {
+		return errs.ErrStudentNotAssigned
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/memory"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestSessionService_FlagQuestion(t *testing.T) {
+	fx := fixtures.NewSchool().WithStudents(2).Build()
+	assessments := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	sessions := usecase.NewSessionService(fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	test, questions, err := assessments.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Review",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "Q1", Points: 1}, {Prompt: "Q2", Points: 1}},
+		StudentIDs: []domain.StudentID{fx.Student(0)},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+
+	if _, err := sessions.FlagQuestion(ctx, fx.Student(1), test.ID, questions[0].ID, true); err != errs.ErrStudentNotAssigned {
+		t.Fatalf("expected ErrStudentNotAssigned, got %v", err)
+	}
+	if _, err := sessions.FlagQuestion(ctx, fx.Student(0), test.ID, "missing", true); err != errs.ErrQuestionNotFound {
+		t.Fatalf("expected ErrQuestionNotFound, got %v", err)
+	}
+
+	for _, qid := range []domain.QuestionID{questions[0].ID, questions[1].ID, questions[1].ID} {
+		if _, err := sessions.FlagQuestion(ctx, fx.Student(0), test.ID, qid, true); err != nil {
+			t.Fatalf("FlagQuestion failed: %v", err)
+		}
+	}
+	if _, err := sessions.FlagQuestion(ctx, fx.Student(0), test.ID, questions[0].ID, false); err != nil {
+		t.Fatalf("unflag failed: %v", err)
+	}
+
+	// A resumed session, e.g. after a restart, sees the same flags.
+	restored := usecase.NewSessionService(fx.Repo, memory.NewRepositoryFromState(fx.Repo.ExportState()))
+	session, err := restored.GetSession(ctx, fx.Student(0), test.ID)
+	if err != nil {
+		t.Fatalf("GetSession failed: %v", err)
+	}
+	if len(session.Flagged) != 1 || session.Flagged[0] != questions[1].ID {
+		t.Fatalf("expected only the second question flagged, got %v", session.Flagged)
+	}
+}
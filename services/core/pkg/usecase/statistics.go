@@ -0,0 +1,200 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/cache"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/health"
+)
+
+const (
+	defaultStatisticsTTL = 30 * time.Second
+	hotTestWindow        = 15 * time.Minute
+)
+
+// TestStatistics summarises grading progress and scores of a test. Pass
+// metrics count students whose outcome is decided and are only filled in when
+// the test has a passing score.
+type TestStatistics struct {
+	TestID       domain.TestID
+	Answers      int
+	Graded       int
+	Completed    int
+	MinScore     domain.Score
+	MaxScore     domain.Score
+	MeanScore    float64
+	PassingScore *domain.Score
+	Decided      int
+	Passed       int
+	PassRate     float64
+	Classes      []ClassPassRate
+	ComputedAt   time.Time
+}
+
+// statisticsCache keeps computed statistics for a short time and remembers
+// which tests were requested recently so they can be refreshed ahead of time.
+type statisticsCache struct {
+	entries *cache.TTL[domain.TestID, TestStatistics]
+
+	mu  sync.Mutex
+	hot map[domain.TestID]time.Time
+}
+
+func newStatisticsCache(ttl time.Duration) *statisticsCache {
+	return &statisticsCache{
+		entries: cache.NewTTL[domain.TestID, TestStatistics](ttl),
+		hot:     make(map[domain.TestID]time.Time),
+	}
+}
+
+func (c *statisticsCache) touch(testID domain.TestID) {
+	c.mu.Lock()
+	c.hot[testID] = time.Now()
+	c.mu.Unlock()
+}
+
+// hotTests returns tests requested within the hot window and forgets the rest.
+func (c *statisticsCache) hotTests() []domain.TestID {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cutoff := time.Now().Add(-hotTestWindow)
+	tests := make([]domain.TestID, 0, len(c.hot))
+	for testID, seen := range c.hot {
+		if seen.Before(cutoff) {
+			delete(c.hot, testID)
+			continue
+		}
+		tests = append(tests, testID)
+	}
+	return tests
+}
+
+// SetStatisticsTTL changes how long computed statistics are served from cache.
+func (s *AssessmentService) SetStatisticsTTL(ttl time.Duration) {
+	s.stats = newStatisticsCache(ttl)
+}
+
+// FlushStatistics drops every cached statistic and returns how many there
+// were.
+func (s *AssessmentService) FlushStatistics() int {
+	n := s.stats.entries.Len()
+	s.stats.entries.Flush()
+	return n
+}
+
+// TestStatistics returns grading statistics for a test ensuring teacher
+// ownership. Results are cached and invalidated whenever a grade is saved.
+func (s *AssessmentService) TestStatistics(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID) (*TestStatistics, error) {
+	ctx, span := s.trace(ctx, "TestStatistics")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+
+	s.stats.touch(testID)
+	if stats, ok := s.stats.entries.Get(testID); ok {
+		return &stats, nil
+	}
+
+	stats, err := s.computeStatistics(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	s.stats.entries.Set(testID, *stats)
+	return stats, nil
+}
+
+// RunStatisticsRefresher recomputes statistics of recently requested tests
+// every interval so grading sessions keep hitting a warm cache.
+func (s *AssessmentService) RunStatisticsRefresher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, testID := range s.stats.hotTests() {
+				stats, err := s.computeStatistics(ctx, testID)
+				if err != nil {
+					log.Printf("refresh statistics for %s: %v", testID, err)
+					continue
+				}
+				s.stats.entries.Set(testID, *stats)
+			}
+			health.Report(ctx, nil)
+		}
+	}
+}
+
+func (s *AssessmentService) computeStatistics(ctx context.Context, testID domain.TestID) (*TestStatistics, error) {
+	stats := &TestStatistics{
+		TestID:     testID,
+		ComputedAt: time.Now().UTC(),
+	}
+	if s.readModel != nil {
+		gradebook, err := s.readModel.Gradebook(ctx, testID)
+		if err != nil {
+			return nil, err
+		}
+		counters := gradebook.Counters
+		stats.Answers, stats.Graded, stats.Completed = counters.Answers, counters.Graded, counters.Completed
+		stats.MinScore, stats.MaxScore = gradebook.ScoreRange()
+		stats.MeanScore = counters.MeanScore()
+	} else {
+		snapshot, err := s.resultRepo.SnapshotGrading(ctx, testID)
+		if err != nil {
+			return nil, err
+		}
+		stats.Answers, stats.Graded = len(snapshot.Answers), len(snapshot.Results)
+		for i, res := range snapshot.Results {
+			if res.Completed {
+				stats.Completed++
+			}
+			if i == 0 || res.Score < stats.MinScore {
+				stats.MinScore = res.Score
+			}
+			if i == 0 || res.Score > stats.MaxScore {
+				stats.MaxScore = res.Score
+			}
+		}
+		stats.MeanScore = domain.MeanScore(snapshot.Results)
+	}
+
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	if test == nil || test.PassingScore == nil {
+		return stats, nil
+	}
+	outcomes, err := s.studentOutcomes(ctx, test)
+	if err != nil {
+		return nil, err
+	}
+	stats.PassingScore = test.PassingScore
+	for _, o := range outcomes {
+		if o.Passed == nil {
+			continue
+		}
+		stats.Decided++
+		if *o.Passed {
+			stats.Passed++
+		}
+	}
+	if stats.Decided > 0 {
+		stats.PassRate = float64(stats.Passed) / float64(stats.Decided)
+	}
+	if stats.Classes, err = s.classPassRates(ctx, outcomes); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
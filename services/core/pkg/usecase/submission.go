@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+	"github.com/sky0621/go_work_sample/core/pkg/tracing"
+)
+
+type submissionEvent struct {
+	TestID      string    `json:"test_id"`
+	StudentID   string    `json:"student_id"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+// SetSubmissions lets students finalize tests, after which their answers are
+// locked. Without a repository tests cannot be submitted.
+func (s *AssessmentService) SetSubmissions(submissions repository.SubmissionRepository) {
+	s.submissionRepo = tracing.SubmissionRepository(submissions)
+}
+
+// SubmitTest records that a student is done with an open test. Their answers
+// to it can no longer be changed afterwards.
+func (s *AssessmentService) SubmitTest(ctx context.Context, studentID domain.StudentID, testID domain.TestID) (*domain.Submission, error) {
+	ctx, span := s.trace(ctx, "SubmitTest")
+	defer span.End()
+
+	if s.submissionRepo == nil {
+		return nil, errs.ErrSubmitUnavailable
+	}
+	if err := s.ensureStudentExists(ctx, studentID); err != nil {
+		return nil, err
+	}
+	test, err := s.publishedTestFor(ctx, studentID, testID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if test.AvailabilityFor(studentID, now) != domain.TestOpen {
+		return nil, errs.ErrTestClosed
+	}
+	if err := s.ensureNotSubmitted(ctx, testID, studentID); err != nil {
+		return nil, err
+	}
+
+	submission := &domain.Submission{TestID: testID, StudentID: studentID, SubmittedAt: now}
+	if err := s.submissionRepo.SaveSubmission(ctx, submission); err != nil {
+		return nil, err
+	}
+	s.publish(EventTestSubmitted, submissionEvent{
+		TestID:      string(testID),
+		StudentID:   string(studentID),
+		SubmittedAt: now,
+	})
+	return submission, nil
+}
+
+// ListSubmissions lists the students who submitted a test, ensuring teacher
+// access. It is empty when tests cannot be submitted.
+func (s *AssessmentService) ListSubmissions(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID) ([]domain.Submission, error) {
+	ctx, span := s.trace(ctx, "ListSubmissions")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	if s.submissionRepo == nil {
+		return []domain.Submission{}, nil
+	}
+	return s.submissionRepo.ListSubmissionsByTest(ctx, testID)
+}
+
+// ensureNotSubmitted refuses changes to the answers of a submitted test.
+func (s *AssessmentService) ensureNotSubmitted(ctx context.Context, testID domain.TestID, studentID domain.StudentID) error {
+	if s.submissionRepo == nil {
+		return nil
+	}
+	submission, err := s.submissionRepo.GetSubmission(ctx, testID, studentID)
+	if err != nil {
+		return err
+	}
+	if submission != nil {
+		return errs.ErrTestSubmitted
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/audit"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+)
+
+// SetSubmissionLimits replaces the limits protecting a test from scripted
+// answer spam. Zero limits turn the protection off.
+func (s *AssessmentService) SetSubmissionLimits(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID, limits domain.SubmissionLimits) (*domain.Test, error) {
+	ctx, span := s.trace(ctx, "SetSubmissionLimits")
+	defer span.End()
+
+	if limits.PerQuestionPerMinute < 0 || limits.DuplicateWindow < 0 {
+		return nil, errs.ErrInvalidTest
+	}
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+
+	test.Limits = limits
+	test.UpdatedAt = time.Now().UTC()
+	if err := s.testRepo.UpdateTest(ctx, test); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, audit.TestUpdated(teacherID, *test, "submission limits"))
+	return test, nil
+}
+
+// checkSubmissionLimits refuses an answer that repeats the saved one within
+// the test's duplicate window, then counts it against the per-question rate.
+// Duplicates are checked first so they do not use up the rate.
+func (s *AssessmentService) checkSubmissionLimits(test *domain.Test, answer, existing *domain.Answer, now time.Time) error {
+	limits := test.Limits
+	if existing != nil && limits.DuplicateWindow > 0 &&
+		existing.Response == answer.Response && existing.Note == answer.Note &&
+		now.Sub(existing.UpdatedAt) < limits.DuplicateWindow {
+		return errs.ErrDuplicateAnswer
+	}
+	key := "answers:" + string(test.ID) + ":" + string(answer.QuestionID) + ":" + string(answer.StudentID)
+	if !s.resubmissions.Allow(key, limits.PerQuestionPerMinute, time.Minute) {
+		return errs.ErrAnswerThrottled
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_SubmissionLimits(t *testing.T) {
+	fx := fixtures.NewSchool().WithTeachers(2).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Spam",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "Q1", Points: 1}, {Prompt: "Q2", Points: 1}},
+		StudentIDs: []domain.StudentID{fx.Student(0)},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	if _, err := service.SetSubmissionLimits(ctx, fx.Teacher(0), test.ID, domain.SubmissionLimits{PerQuestionPerMinute: -1}); err != errs.ErrInvalidTest {
+		t.Fatalf("expected negative limits to be refused, got %v", err)
+	}
+	limits := domain.SubmissionLimits{PerQuestionPerMinute: 2, DuplicateWindow: time.Minute}
+	if _, err := service.SetSubmissionLimits(ctx, fx.Teacher(1), test.ID, limits); err != errs.ErrForbiddenTeacher {
+		t.Fatalf("expected another teacher to be refused, got %v", err)
+	}
+	if updated, err := service.SetSubmissionLimits(ctx, fx.Teacher(0), test.ID, limits); err != nil || updated.Limits != limits {
+		t.Fatalf("SetSubmissionLimits failed: %+v, %v", updated, err)
+	}
+
+	submit := func(q domain.Question, response string) error {
+		_, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: q.TestID, QuestionID: q.ID, StudentID: fx.Student(0), Response: response})
+		return err
+	}
+	if err := submit(questions[0], "a"); err != nil {
+		t.Fatalf("first submission failed: %v", err)
+	}
+	if err := submit(questions[0], "a"); err != errs.ErrDuplicateAnswer {
+		t.Fatalf("expected ErrDuplicateAnswer, got %v", err)
+	}
+	if err := submit(questions[0], "b"); err != nil {
+		t.Fatalf("changed answer failed: %v", err)
+	}
+	if err := submit(questions[0], "c"); err != errs.ErrAnswerThrottled {
+		t.Fatalf("expected ErrAnswerThrottled, got %v", err)
+	}
+	if err := submit(questions[1], "c"); err != nil {
+		t.Fatalf("expected the limit to be per question, got %v", err)
+	}
+}
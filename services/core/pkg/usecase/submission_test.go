@@ -0,0 +1,57 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_SubmitTest(t *testing.T) {
+	fx := fixtures.NewSchool().WithStudents(2).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Final",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "Q1", Points: 1}},
+		StudentIDs: []domain.StudentID{fx.Student(0), fx.Student(1)},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	if _, err := service.SubmitTest(ctx, fx.Student(0), test.ID); err != errs.ErrSubmitUnavailable {
+		t.Fatalf("expected submitting to need a repository, got %v", err)
+	}
+	service.SetSubmissions(fx.Repo)
+
+	answer := func(studentID domain.StudentID, response string) error {
+		_, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: studentID, Response: response})
+		return err
+	}
+	if err := answer(fx.Student(0), "a"); err != nil {
+		t.Fatalf("SubmitAnswer failed: %v", err)
+	}
+	submission, err := service.SubmitTest(ctx, fx.Student(0), test.ID)
+	if err != nil || submission.SubmittedAt.IsZero() {
+		t.Fatalf("SubmitTest failed: %+v, %v", submission, err)
+	}
+	if _, err := service.SubmitTest(ctx, fx.Student(0), test.ID); err != errs.ErrTestSubmitted {
+		t.Fatalf("expected a second submission to be refused, got %v", err)
+	}
+	if err := answer(fx.Student(0), "b"); err != errs.ErrTestSubmitted {
+		t.Fatalf("expected answers of a submitted test to be locked, got %v", err)
+	}
+	if err := answer(fx.Student(1), "b"); err != nil {
+		t.Fatalf("expected other students to keep answering, got %v", err)
+	}
+
+	submissions, err := service.ListSubmissions(ctx, fx.Teacher(0), test.ID)
+	if err != nil || len(submissions) != 1 || submissions[0].StudentID != fx.Student(0) {
+		t.Fatalf("expected the teacher to see the submission, got %+v, %v", submissions, err)
+	}
+}
@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+// CompletionStatus tells how far a student has got with a test.
+type CompletionStatus string
+
+const (
+	// CompletionNotStarted means the student has not answered any question.
+	CompletionNotStarted CompletionStatus = "not_started"
+	// CompletionInProgress means some questions are still unanswered.
+	CompletionInProgress CompletionStatus = "in_progress"
+	// CompletionAwaitingGrading means every question is answered but some
+	// answers have no result yet.
+	CompletionAwaitingGrading CompletionStatus = "awaiting_grading"
+	// CompletionGraded means every question is answered and graded.
+	CompletionGraded CompletionStatus = "graded"
+)
+
+// StudentSummary is a student's total on a test with their progress.
+type StudentSummary struct {
+	StudentOutcome
+	Status CompletionStatus
+}
+
+// ScoreDistribution summarises the totals of the students counted in it.
+type ScoreDistribution struct {
+	Students int
+	Min      domain.Score
+	Max      domain.Score
+	Mean     float64
+	Median   float64
+}
+
+// TestSummary aggregates a test's results per student and class-wide. The
+// class-wide distribution counts students whose answers are all graded, so
+// partly graded totals do not drag it down.
+type TestSummary struct {
+	TestID     domain.TestID
+	Questions  int
+	MaxScore   domain.Points
+	Students   []StudentSummary
+	Scores     ScoreDistribution
+	ComputedAt time.Time
+}
+
+// TestSummary returns the per-student totals and class-wide score
+// distribution of a test, ensuring teacher ownership.
+func (s *AssessmentService) TestSummary(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID) (*TestSummary, error) {
+	ctx, span := s.trace(ctx, "TestSummary")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	questions, err := s.testRepo.ListQuestions(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	outcomes, err := s.studentOutcomes(ctx, test)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &TestSummary{
+		TestID:     testID,
+		Questions:  len(questions),
+		MaxScore:   domain.TotalPoints(questions),
+		Students:   make([]StudentSummary, len(outcomes)),
+		ComputedAt: time.Now().UTC(),
+	}
+	var scored []domain.Score
+	for i, o := range outcomes {
+		status := completionStatus(o, len(questions))
+		summary.Students[i] = StudentSummary{StudentOutcome: o, Status: status}
+		if o.Answered > 0 && o.Graded == o.Answered {
+			scored = append(scored, o.Score)
+		}
+	}
+	summary.Scores = distribution(scored)
+	return summary, nil
+}
+
+func completionStatus(o StudentOutcome, questions int) CompletionStatus {
+	switch {
+	case o.Answered == 0:
+		return CompletionNotStarted
+	case o.Answered < questions:
+		return CompletionInProgress
+	case o.Graded < o.Answered:
+		return CompletionAwaitingGrading
+	default:
+		return CompletionGraded
+	}
+}
+
+func distribution(scores []domain.Score) ScoreDistribution {
+	if len(scores) == 0 {
+		return ScoreDistribution{}
+	}
+	sorted := append([]domain.Score(nil), scores...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total float64
+	for _, score := range sorted {
+		total += float64(score)
+	}
+	n := len(sorted)
+	median := float64(sorted[n/2])
+	if n%2 == 0 {
+		median = float64(sorted[n/2-1]+sorted[n/2]) / 2
+	}
+	return ScoreDistribution{
+		Students: n,
+		Min:      sorted[0],
+		Max:      sorted[n-1],
+		Mean:     total / float64(n),
+		Median:   median,
+	}
+}
@@ -0,0 +1,90 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_TestSummary(t *testing.T) {
+	fx := fixtures.NewSchool().WithTeachers(2).WithStudents(6).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	students := make([]domain.StudentID, 6)
+	for i := range students {
+		students[i] = fx.Student(i)
+	}
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Summary",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "Q1", Points: 5}, {Prompt: "Q2", Points: 5}},
+		StudentIDs: students,
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+
+	submit := func(sid domain.StudentID, q domain.Question) {
+		t.Helper()
+		if _, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: q.ID, StudentID: sid, Response: "x"}); err != nil {
+			t.Fatalf("SubmitAnswer failed: %v", err)
+		}
+	}
+	grade := func(sid domain.StudentID, q domain.Question, score domain.Score) {
+		t.Helper()
+		if _, err := service.GradeAnswer(ctx, usecase.GradeInput{TeacherID: fx.Teacher(0), TestID: test.ID, QuestionID: q.ID, StudentID: sid, Score: score, Completed: true}); err != nil {
+			t.Fatalf("GradeAnswer failed: %v", err)
+		}
+	}
+	// Students 0 to 2 are fully graded with 10, 4 and 7 points.
+	for i, pair := range [][2]domain.Score{{5, 5}, {2, 2}, {3, 4}} {
+		for j, q := range questions {
+			submit(students[i], q)
+			grade(students[i], q, pair[j])
+		}
+	}
+	// Student 3 answered everything but one answer is ungraded, student 4
+	// answered one question and student 5 nothing.
+	submit(students[3], questions[0])
+	submit(students[3], questions[1])
+	grade(students[3], questions[0], 1)
+	submit(students[4], questions[0])
+
+	if _, err := service.TestSummary(ctx, fx.Teacher(1), test.ID); err != errs.ErrForbiddenTeacher {
+		t.Fatalf("expected ErrForbiddenTeacher, got %v", err)
+	}
+	summary, err := service.TestSummary(ctx, fx.Teacher(0), test.ID)
+	if err != nil {
+		t.Fatalf("TestSummary failed: %v", err)
+	}
+	if summary.Questions != 2 || summary.MaxScore != 10 || len(summary.Students) != 6 {
+		t.Fatalf("unexpected summary header: %+v", summary)
+	}
+
+	want := map[domain.StudentID]usecase.CompletionStatus{
+		students[0]: usecase.CompletionGraded,
+		students[1]: usecase.CompletionGraded,
+		students[2]: usecase.CompletionGraded,
+		students[3]: usecase.CompletionAwaitingGrading,
+		students[4]: usecase.CompletionInProgress,
+		students[5]: usecase.CompletionNotStarted,
+	}
+	for _, st := range summary.Students {
+		if st.Status != want[st.StudentID] {
+			t.Fatalf("student %s: expected %s, got %s", st.StudentID, want[st.StudentID], st.Status)
+		}
+		if st.StudentID == students[2] && (st.Score != 7 || st.Percent() != 70) {
+			t.Fatalf("expected student 2 to total 7 (70%%), got %+v", st)
+		}
+	}
+
+	scores := summary.Scores
+	if scores.Students != 3 || scores.Min != 4 || scores.Max != 10 || scores.Mean != 7 || scores.Median != 7 {
+		t.Fatalf("unexpected score distribution: %+v", scores)
+	}
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// StudentSync is what changed for a student since a cursor, for clients that
+// keep a copy of the student's tests while offline. TestIDs lists every test
+// the student currently sees, so clients can drop the tests missing from it;
+// Tests and Results only hold what changed. Passing Cursor to the next sync
+// continues from here.
+type StudentSync struct {
+	Cursor  time.Time
+	TestIDs []domain.TestID
+	Tests   []SyncedTest
+	Results []SyncedResult
+}
+
+// SyncedTest is a test with its questions in the student's locale.
+type SyncedTest struct {
+	Test      domain.Test
+	Questions []LocalizedQuestion
+}
+
+// SyncedResult is a released result with the question it grades.
+type SyncedResult struct {
+	TestID     domain.TestID
+	QuestionID domain.QuestionID
+	Result     domain.Result
+}
+
+// SyncForStudent returns the published tests and released results of the
+// student changed at or after since; a zero since returns everything. Records
+// changing while the sync runs may be returned again by the next one, so
+// clients must apply the delta idempotently.
+func (s *AssessmentService) SyncForStudent(ctx context.Context, studentID domain.StudentID, since time.Time) (*StudentSync, error) {
+	ctx, span := s.trace(ctx, "SyncForStudent")
+	defer span.End()
+
+	delta := &StudentSync{Cursor: time.Now().UTC()}
+	student, err := s.orgRepo.GetStudent(ctx, studentID)
+	if err != nil {
+		return nil, err
+	}
+	if student == nil {
+		return nil, errs.ErrStudentNotFound
+	}
+	assigned, err := repository.Collect(s.testRepo.ListTestsForStudent(ctx, studentID, repository.All))
+	if err != nil {
+		return nil, err
+	}
+
+	delta.TestIDs = make([]domain.TestID, 0, len(assigned))
+	for _, test := range assigned {
+		if !test.Published {
+			continue
+		}
+		delta.TestIDs = append(delta.TestIDs, test.ID)
+		if !test.UpdatedAt.Before(since) {
+			questions, err := s.listQuestions(ctx, test.ID)
+			if err != nil {
+				return nil, err
+			}
+			delta.Tests = append(delta.Tests, SyncedTest{Test: test, Questions: localizeQuestions(questions, student.Locale)})
+		}
+
+		results, err := s.releasedSince(ctx, test.ID, studentID, since)
+		if err != nil {
+			return nil, err
+		}
+		delta.Results = append(delta.Results, results...)
+	}
+	return delta, nil
+}
+
+// releasedSince returns the completed results of the student on a test
+// changed at or after since, oldest first.
+func (s *AssessmentService) releasedSince(ctx context.Context, testID domain.TestID, studentID domain.StudentID, since time.Time) ([]SyncedResult, error) {
+	results, err := s.resultRepo.ListResultsByStudent(ctx, testID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	changed := results[:0]
+	for _, res := range results {
+		if res.Completed && !res.UpdatedAt.Before(since) {
+			changed = append(changed, res)
+		}
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	answers, err := s.answerRepo.ListAnswers(ctx, testID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	questionOf := make(map[domain.AnswerID]domain.QuestionID, len(answers))
+	for _, a := range answers {
+		questionOf[a.ID] = a.QuestionID
+	}
+	sort.SliceStable(changed, func(i, j int) bool { return changed[i].UpdatedAt.Before(changed[j].UpdatedAt) })
+	synced := make([]SyncedResult, len(changed))
+	for i, res := range changed {
+		synced[i] = SyncedResult{TestID: testID, QuestionID: questionOf[res.AnswerID], Result: res}
+	}
+	return synced, nil
+}
@@ -0,0 +1,63 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_SyncForStudent(t *testing.T) {
+	fx := fixtures.NewSchool().WithStudents(2).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+	student := fx.Student(0)
+
+	create := func(title string, students ...domain.StudentID) (*domain.Test, []domain.Question) {
+		t.Helper()
+		test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+			Title:      title,
+			TeacherID:  fx.Teacher(0),
+			Questions:  []usecase.QuestionDraft{{Prompt: "2 + 2", Points: 1}},
+			StudentIDs: students,
+		})
+		if err != nil {
+			t.Fatalf("CreateTest failed: %v", err)
+		}
+		return test, questions
+	}
+	math, questions := create("Math", student)
+	other, _ := create("Science", fx.Student(1))
+
+	full, err := service.SyncForStudent(ctx, student, time.Time{})
+	if err != nil {
+		t.Fatalf("SyncForStudent failed: %v", err)
+	}
+	if len(full.TestIDs) != 1 || len(full.Tests) != 1 || full.Tests[0].Test.ID != math.ID || len(full.Tests[0].Questions) != 1 || len(full.Results) != 0 {
+		t.Fatalf("expected a full sync of the assigned test, got %+v", full)
+	}
+
+	if _, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: math.ID, QuestionID: questions[0].ID, StudentID: student, Response: "4"}); err != nil {
+		t.Fatalf("SubmitAnswer failed: %v", err)
+	}
+	if _, err := service.GradeAnswer(ctx, usecase.GradeInput{TeacherID: fx.Teacher(0), TestID: math.ID, QuestionID: questions[0].ID, StudentID: student, Score: 1, Completed: true}); err != nil {
+		t.Fatalf("GradeAnswer failed: %v", err)
+	}
+	if _, err := service.UpdateAssignees(ctx, fx.Teacher(0), other.ID, usecase.AssigneeChange{AddStudentIDs: []domain.StudentID{student}}); err != nil {
+		t.Fatalf("UpdateAssignees failed: %v", err)
+	}
+
+	delta, err := service.SyncForStudent(ctx, student, full.Cursor)
+	if err != nil {
+		t.Fatalf("SyncForStudent failed: %v", err)
+	}
+	if len(delta.TestIDs) != 2 || len(delta.Tests) != 1 || delta.Tests[0].Test.ID != other.ID {
+		t.Fatalf("expected only the newly assigned test, got %+v", delta)
+	}
+	if len(delta.Results) != 1 || delta.Results[0].QuestionID != questions[0].ID || delta.Results[0].Result.Score != 1 {
+		t.Fatalf("expected the released result, got %+v", delta.Results)
+	}
+}
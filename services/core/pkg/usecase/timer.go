@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/audit"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+	"github.com/sky0621/go_work_sample/core/pkg/tracing"
+)
+
+// SetSessions lets students start timed tests and enforces their timers.
+// Without a repository timed tests cannot be started and their timers are
+// not enforced.
+func (s *AssessmentService) SetSessions(sessions repository.TestSessionRepository) {
+	s.sessionRepo = tracing.TestSessionRepository(sessions)
+}
+
+// SetTestDuration times the test, giving each student minutes to answer from
+// when they start it; zero makes it untimed. Students who already started
+// keep their start time.
+func (s *AssessmentService) SetTestDuration(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID, minutes int) (*domain.Test, error) {
+	ctx, span := s.trace(ctx, "SetTestDuration")
+	defer span.End()
+
+	if err := s.ensureTeacherOwnsTest(ctx, teacherID, testID); err != nil {
+		return nil, err
+	}
+	test, err := s.testRepo.GetTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+
+	test.DurationMinutes = minutes
+	if err := test.Validate(); err != nil {
+		return nil, err
+	}
+	test.UpdatedAt = time.Now().UTC()
+	if err := s.testRepo.UpdateTest(ctx, test); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, audit.TestUpdated(teacherID, *test, "duration"))
+	return test, nil
+}
+
+// StartTest records that the student started an open test, which starts the
+// timer of a timed test. Starting again keeps the first start time.
+func (s *AssessmentService) StartTest(ctx context.Context, studentID domain.StudentID, testID domain.TestID) (*domain.TestSession, error) {
+	ctx, span := s.trace(ctx, "StartTest")
+	defer span.End()
+
+	if s.sessionRepo == nil {
+		return nil, errs.ErrTimerUnavailable
+	}
+	if err := s.ensureStudentExists(ctx, studentID); err != nil {
+		return nil, err
+	}
+	test, err := s.publishedTestFor(ctx, studentID, testID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if test.AvailabilityFor(studentID, now) != domain.TestOpen {
+		return nil, errs.ErrTestClosed
+	}
+	if err := s.ensureNotSubmitted(ctx, testID, studentID); err != nil {
+		return nil, err
+	}
+
+	session, err := s.sessionRepo.GetTestSession(ctx, testID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		session = &domain.TestSession{TestID: testID, StudentID: studentID, CreatedAt: now}
+	}
+	if session.StartedAt != nil {
+		return session, nil
+	}
+	session.StartedAt = &now
+	session.UpdatedAt = now
+	if err := s.sessionRepo.SaveTestSession(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// ensureTimeLeft refuses answers to a timed test the student has not started
+// or whose time has run out at now.
+func (s *AssessmentService) ensureTimeLeft(ctx context.Context, test *domain.Test, studentID domain.StudentID, now time.Time) error {
+	if _, timed := test.Duration(); !timed || s.sessionRepo == nil {
+		return nil
+	}
+	session, err := s.sessionRepo.GetTestSession(ctx, test.ID, studentID)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.StartedAt == nil {
+		return errs.ErrTestNotStarted
+	}
+	if endsAt, _ := session.EndsAt(*test); !now.Before(endsAt) {
+		return errs.ErrTimeExpired
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_TimedTests(t *testing.T) {
+	fx := fixtures.NewSchool().WithStudents(2).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:           "Quiz",
+		TeacherID:       fx.Teacher(0),
+		Questions:       []usecase.QuestionDraft{{Prompt: "Q1", Points: 1}},
+		StudentIDs:      []domain.StudentID{fx.Student(0), fx.Student(1)},
+		DurationMinutes: 30,
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	if _, err := service.StartTest(ctx, fx.Student(0), test.ID); err != errs.ErrTimerUnavailable {
+		t.Fatalf("expected starting to need a repository, got %v", err)
+	}
+	service.SetSessions(fx.Repo)
+
+	answer := func(studentID domain.StudentID) error {
+		_, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: studentID, Response: "a"})
+		return err
+	}
+	if err := answer(fx.Student(0)); err != errs.ErrTestNotStarted {
+		t.Fatalf("expected answers before the start to be refused, got %v", err)
+	}
+	session, err := service.StartTest(ctx, fx.Student(0), test.ID)
+	if err != nil || session.StartedAt == nil {
+		t.Fatalf("StartTest failed: %+v, %v", session, err)
+	}
+	if endsAt, ok := session.EndsAt(*test); !ok || !endsAt.Equal(session.StartedAt.Add(30*time.Minute)) {
+		t.Fatalf("expected the timer to run 30 minutes, got %v", endsAt)
+	}
+	again, err := service.StartTest(ctx, fx.Student(0), test.ID)
+	if err != nil || !again.StartedAt.Equal(*session.StartedAt) {
+		t.Fatalf("expected a second start to keep the first, got %+v, %v", again, err)
+	}
+	if err := answer(fx.Student(0)); err != nil {
+		t.Fatalf("SubmitAnswer failed: %v", err)
+	}
+
+	started := time.Now().UTC().Add(-31 * time.Minute)
+	if err := fx.Repo.SaveTestSession(ctx, &domain.TestSession{TestID: test.ID, StudentID: fx.Student(1), StartedAt: &started}); err != nil {
+		t.Fatalf("SaveTestSession failed: %v", err)
+	}
+	if err := answer(fx.Student(1)); err != errs.ErrTimeExpired {
+		t.Fatalf("expected answers after the timer to be refused, got %v", err)
+	}
+
+	if _, err := service.SetTestDuration(ctx, fx.Teacher(0), test.ID, -1); err == nil {
+		t.Fatal("expected a negative duration to be refused")
+	}
+	if updated, err := service.SetTestDuration(ctx, fx.Teacher(0), test.ID, 0); err != nil || updated.DurationMinutes != 0 {
+		t.Fatalf("SetTestDuration failed: %+v, %v", updated, err)
+	}
+	if err := answer(fx.Student(1)); err != nil {
+		t.Fatalf("expected an untimed test to accept answers, got %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package usecase_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/tracing"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []tracing.SpanData
+}
+
+func (r *spanRecorder) Export(_ context.Context, spans []tracing.SpanData) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, spans...)
+	return nil
+}
+
+func TestAssessmentService_TracesGradingDownToStorage(t *testing.T) {
+	fx := fixtures.NewSchool().WithStudents(1).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	test, questions, err := service.CreateTest(context.Background(), usecase.CreateTestInput{
+		Title:      "Traced",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "1+1?", Points: 5}},
+		StudentIDs: []domain.StudentID{fx.Student(0)},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	if _, err := service.SubmitAnswer(context.Background(), &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: fx.Student(0), Response: "2"}); err != nil {
+		t.Fatalf("SubmitAnswer failed: %v", err)
+	}
+
+	rec := &spanRecorder{}
+	provider := tracing.NewProvider(tracing.Options{Exporter: rec, SampleRatio: 1})
+	tracing.SetProvider(provider)
+	t.Cleanup(func() { tracing.SetProvider(nil) })
+
+	ctx, request := tracing.Start(context.Background(), "POST /api/grades")
+	if _, err := service.GradeAnswer(ctx, usecase.GradeInput{
+		TeacherID:  fx.Teacher(0),
+		TestID:     test.ID,
+		QuestionID: questions[0].ID,
+		StudentID:  fx.Student(0),
+		Score:      4,
+	}); err != nil {
+		t.Fatalf("GradeAnswer failed: %v", err)
+	}
+	request.End()
+	if err := provider.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	byName := make(map[string][]tracing.SpanData)
+	for _, span := range rec.spans {
+		byName[span.Name] = append(byName[span.Name], span)
+	}
+	grade := byName["AssessmentService.GradeAnswer"]
+	if len(grade) != 1 || grade[0].ParentID != request.SpanContext().SpanID {
+		t.Fatalf("expected one service span under the request, got %+v", grade)
+	}
+	saves := byName["ResultRepository.SaveResult"]
+	if len(saves) != 1 || saves[0].ParentID != grade[0].SpanID || saves[0].TraceID != grade[0].TraceID {
+		t.Fatalf("expected the stored result under the service span, got %+v", saves)
+	}
+}
@@ -0,0 +1,36 @@
+package usecase
+
+import "github.com/sky0621/go_work_sample/core/pkg/domain"
+
+// LocalizedQuestion is a question in the language served to a student.
+// Locale names the translation used and is empty for the canonical
+// question. The ID and choice keys are those of the canonical question, so
+// answers and grading need no mapping.
+type LocalizedQuestion struct {
+	domain.Question
+	Locale string
+}
+
+// localizeQuestions renders questions for a reader preferring locale,
+// falling back to the canonical text question by question.
+func localizeQuestions(questions []domain.Question, locale string) []LocalizedQuestion {
+	out := make([]LocalizedQuestion, len(questions))
+	for i, q := range questions {
+		localized, match := q.Localized(locale)
+		localized.Translations = nil
+		out[i] = LocalizedQuestion{Question: localized, Locale: match}
+	}
+	return out
+}
+
+func copyTranslations(in map[string]domain.Translation) map[string]domain.Translation {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make(map[string]domain.Translation, len(in))
+	for locale, tr := range in {
+		tr.Choices = append([]domain.Choice(nil), tr.Choices...)
+		out[locale] = tr
+	}
+	return out
+}
@@ -0,0 +1,90 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_TranslatedQuestions(t *testing.T) {
+	fx := fixtures.NewSchool().WithStudents(2).Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	service.SetAutograder(exactGrader{})
+	profiles := usecase.NewProfileService(fx.Repo)
+	ctx := context.Background()
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:     "Colours",
+		TeacherID: fx.Teacher(0),
+		Questions: []usecase.QuestionDraft{{
+			Prompt:           "Pick red",
+			Points:           1,
+			Type:             domain.QuestionMultipleChoice,
+			Choices:          []domain.Choice{{Key: "a", Label: "Red"}, {Key: "b", Label: "Blue"}},
+			ExpectedResponse: "a",
+			Translations: map[string]domain.Translation{
+				"ja": {Prompt: "赤を選んで", Choices: []domain.Choice{{Key: "a", Label: "赤"}, {Key: "b", Label: "青"}}},
+			},
+		}, {
+			Prompt: "Why?",
+			Points: 1,
+		}},
+		StudentIDs: []domain.StudentID{fx.Student(0), fx.Student(1)},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+
+	bad := "japanese please"
+	if _, err := profiles.UpdateStudentProfile(ctx, fx.Student(0), usecase.ProfileUpdate{Locale: &bad}); err != errs.ErrInvalidProfile {
+		t.Fatalf("expected ErrInvalidProfile, got %v", err)
+	}
+	locale := "ja_JP"
+	student, err := profiles.UpdateStudentProfile(ctx, fx.Student(0), usecase.ProfileUpdate{Locale: &locale})
+	if err != nil {
+		t.Fatalf("UpdateStudentProfile failed: %v", err)
+	}
+	if student.Locale != "ja-JP" {
+		t.Fatalf("expected locale ja-JP, got %q", student.Locale)
+	}
+
+	served, err := service.GetQuestionsForStudent(ctx, fx.Student(0), test.ID)
+	if err != nil {
+		t.Fatalf("GetQuestionsForStudent failed: %v", err)
+	}
+	if served[0].Locale != "ja" || served[0].Prompt != "赤を選んで" || served[0].Choices[0].Label != "赤" {
+		t.Fatalf("expected the Japanese translation, got %+v", served[0])
+	}
+	if served[1].Locale != "" || served[1].Prompt != "Why?" {
+		t.Fatalf("expected the canonical question without a translation, got %+v", served[1])
+	}
+	if served[0].ID != questions[0].ID || served[0].Translations != nil {
+		t.Fatalf("expected the canonical ID without other translations, got %+v", served[0])
+	}
+
+	other, err := service.GetQuestionsForStudent(ctx, fx.Student(1), test.ID)
+	if err != nil {
+		t.Fatalf("GetQuestionsForStudent failed: %v", err)
+	}
+	if other[0].Locale != "" || other[0].Prompt != "Pick red" {
+		t.Fatalf("expected the canonical question, got %+v", other[0])
+	}
+
+	answer, err := service.SubmitAnswer(ctx, &domain.Answer{
+		TestID:     test.ID,
+		QuestionID: served[0].ID,
+		StudentID:  fx.Student(0),
+		Response:   "a",
+	})
+	if err != nil {
+		t.Fatalf("SubmitAnswer failed: %v", err)
+	}
+	result, err := fx.Repo.GetResult(ctx, answer.ID)
+	if err != nil || result == nil || result.Score != 1 {
+		t.Fatalf("expected the translated answer graded against the canonical question, got %+v (%v)", result, err)
+	}
+}
@@ -0,0 +1,62 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+func TestAssessmentService_OptimisticConcurrency(t *testing.T) {
+	fx := fixtures.NewSchool().Build()
+	service := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+	version := func(v int) *int { return &v }
+
+	test, questions, err := service.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Quiz",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "Q1", Points: 10}},
+		StudentIDs: []domain.StudentID{fx.Student(0)},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	if test.Version != 1 {
+		t.Fatalf("expected a new test at version 1, got %d", test.Version)
+	}
+
+	title := "Quiz 2"
+	updated, err := service.UpdateTestDetails(ctx, fx.Teacher(0), test.ID, usecase.TestDetailsInput{Title: &title, ExpectedVersion: version(1)})
+	if err != nil || updated.Version != 2 {
+		t.Fatalf("expected version 2, got %+v, %v", updated, err)
+	}
+	if _, err := service.UpdateTestDetails(ctx, fx.Teacher(0), test.ID, usecase.TestDetailsInput{Title: &title, ExpectedVersion: version(1)}); err != errs.ErrConflict {
+		t.Fatalf("expected ErrConflict for a stale test, got %v", err)
+	}
+	// Updates without an expected version still bump the version.
+	if changed, err := service.SetTestDuration(ctx, fx.Teacher(0), test.ID, 30); err != nil || changed.Version != 3 {
+		t.Fatalf("expected version 3 after setting a duration, got %+v, %v", changed, err)
+	}
+
+	if _, err := service.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: fx.Student(0), Response: "x"}); err != nil {
+		t.Fatalf("SubmitAnswer failed: %v", err)
+	}
+	grade := usecase.GradeInput{TeacherID: fx.Teacher(0), TestID: test.ID, QuestionID: questions[0].ID, StudentID: fx.Student(0), Score: 7, ExpectedVersion: version(0)}
+	result, err := service.GradeAnswer(ctx, grade)
+	if err != nil || result.Version != 1 {
+		t.Fatalf("expected the first grade at version 1, got %+v, %v", result, err)
+	}
+	// A second teacher grading from the ungraded copy loses.
+	grade.Score = 4
+	if _, err := service.GradeAnswer(ctx, grade); err != errs.ErrConflict {
+		t.Fatalf("expected ErrConflict for a stale grade, got %v", err)
+	}
+	grade.ExpectedVersion = version(1)
+	if result, err = service.GradeAnswer(ctx, grade); err != nil || result.Version != 2 || result.Score != 4 {
+		t.Fatalf("expected the regrade at version 2, got %+v, %v", result, err)
+	}
+}
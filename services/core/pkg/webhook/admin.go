@@ -0,0 +1,103 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+)
+
+// AdminHandler exposes dead-lettered deliveries of the dispatcher running in
+// the same process:
+//
+//	GET  /api/admin/webhooks/deliveries?status=dead|pending
+//	POST /api/admin/webhooks/deliveries/{id}/redrive
+type AdminHandler struct {
+	dispatcher *Dispatcher
+}
+
+// NewAdminHandler builds the webhook administration handler.
+func NewAdminHandler(dispatcher *Dispatcher) *AdminHandler {
+	return &AdminHandler{dispatcher: dispatcher}
+}
+
+// AdminPrefix is the path the admin handler must be mounted on.
+const AdminPrefix = "/api/admin/webhooks/"
+
+type deliveryResponse struct {
+	DeliveryID    string    `json:"delivery_id"`
+	EndpointID    string    `json:"endpoint_id"`
+	Event         string    `json:"event"`
+	Status        string    `json:"status"`
+	Attempts      int       `json:"attempts"`
+	LastError     string    `json:"last_error,omitempty"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func (h *AdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, AdminPrefix), "/"), "/")
+	if len(parts) == 0 || parts[0] != "deliveries" {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		status := Status(r.URL.Query().Get("status"))
+		if status == "" {
+			status = StatusDead
+		}
+		if status != StatusDead && status != StatusPending {
+			writeError(w, http.StatusBadRequest, "status must be dead or pending")
+			return
+		}
+		deliveries := h.dispatcher.Deliveries(status)
+		resp := make([]deliveryResponse, len(deliveries))
+		for i, d := range deliveries {
+			resp[i] = toDeliveryResponse(d)
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"deliveries": resp})
+	case len(parts) == 3 && parts[2] == "redrive" && r.Method == http.MethodPost:
+		delivery, err := h.dispatcher.Redrive(parts[1])
+		switch err {
+		case nil:
+			writeJSON(w, http.StatusOK, toDeliveryResponse(delivery))
+		case errs.ErrDeliveryNotFound:
+			writeError(w, http.StatusNotFound, err.Error())
+		case errs.ErrDeliveryNotDead:
+			writeError(w, http.StatusConflict, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, err.Error())
+		}
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func toDeliveryResponse(d Delivery) deliveryResponse {
+	return deliveryResponse{
+		DeliveryID:    d.ID,
+		EndpointID:    d.EndpointID,
+		Event:         d.Event,
+		Status:        string(d.Status),
+		Attempts:      d.Attempts,
+		LastError:     d.LastError,
+		NextAttemptAt: d.NextAttemptAt,
+		CreatedAt:     d.CreatedAt,
+		UpdatedAt:     d.UpdatedAt,
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, payload any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
@@ -0,0 +1,347 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/health"
+	"github.com/sky0621/go_work_sample/core/pkg/id"
+)
+
+// Headers set on every delivery.
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Endpoint is a receiver subscribed to a set of events. An empty Events list
+// subscribes to every event.
+type Endpoint struct {
+	ID     string
+	URL    string
+	Secret string
+	Events []string
+}
+
+func (e Endpoint) subscribes(event string) bool {
+	if len(e.Events) == 0 {
+		return true
+	}
+	for _, ev := range e.Events {
+		if ev == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Status describes where a delivery is in its lifecycle.
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusDead    Status = "dead"
+)
+
+// Delivery is one event addressed to one endpoint. Successful deliveries are
+// dropped; deliveries that exhaust their attempts are kept as dead letters
+// until redriven.
+type Delivery struct {
+	ID            string
+	EndpointID    string
+	Event         string
+	Payload       []byte
+	Status        Status
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Options tunes delivery retries.
+type Options struct {
+	Client      *http.Client
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// Dispatcher signs and posts events to subscribed endpoints, retrying failed
+// deliveries with exponential backoff and dead-lettering them once the
+// attempts are exhausted.
+type Dispatcher struct {
+	endpoints map[string]Endpoint
+	client    *http.Client
+	maxTries  int
+	baseDelay time.Duration
+	maxDelay  time.Duration
+	now       func() time.Time
+
+	mu         sync.Mutex
+	deliveries map[string]*Delivery
+}
+
+// NewDispatcher creates a dispatcher for the given endpoints.
+func NewDispatcher(endpoints []Endpoint, opts Options) *Dispatcher {
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if opts.MaxAttempts < 1 {
+		opts.MaxAttempts = 1
+	}
+	if opts.BaseDelay <= 0 {
+		opts.BaseDelay = time.Second
+	}
+	if opts.MaxDelay < opts.BaseDelay {
+		opts.MaxDelay = opts.BaseDelay
+	}
+
+	d := &Dispatcher{
+		endpoints:  make(map[string]Endpoint, len(endpoints)),
+		client:     opts.Client,
+		maxTries:   opts.MaxAttempts,
+		baseDelay:  opts.BaseDelay,
+		maxDelay:   opts.MaxDelay,
+		now:        time.Now,
+		deliveries: make(map[string]*Delivery),
+	}
+	for _, ep := range endpoints {
+		d.endpoints[ep.ID] = ep
+	}
+	return d
+}
+
+type envelope struct {
+	ID         string    `json:"id"`
+	Event      string    `json:"event"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+// Publish queues event for every subscribed endpoint. Deliveries are made by
+// Run; Publish itself never performs network calls.
+func (d *Dispatcher) Publish(event string, data any) error {
+	now := d.now().UTC()
+	payload, err := json.Marshal(envelope{ID: id.New(), Event: event, OccurredAt: now, Data: data})
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, ep := range d.endpoints {
+		if !ep.subscribes(event) {
+			continue
+		}
+		delivery := &Delivery{
+			ID:            id.New(),
+			EndpointID:    ep.ID,
+			Event:         event,
+			Payload:       payload,
+			Status:        StatusPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		d.deliveries[delivery.ID] = delivery
+	}
+	return nil
+}
+
+// Run attempts due deliveries every interval until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.DeliverDue(ctx)
+			health.Report(ctx, nil)
+		}
+	}
+}
+
+// DeliverDue attempts every pending delivery whose retry time has come.
+func (d *Dispatcher) DeliverDue(ctx context.Context) {
+	now := d.now().UTC()
+
+	d.mu.Lock()
+	var due []Delivery
+	for _, delivery := range d.deliveries {
+		if delivery.Status == StatusPending && !delivery.NextAttemptAt.After(now) {
+			due = append(due, *delivery)
+		}
+	}
+	d.mu.Unlock()
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].CreatedAt.Before(due[j].CreatedAt)
+	})
+	for _, delivery := range due {
+		err := d.send(ctx, delivery)
+		d.record(delivery.ID, err)
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery Delivery) error {
+	ep, ok := d.endpoints[delivery.EndpointID]
+	if !ok {
+		return fmt.Errorf("endpoint %s is no longer configured", delivery.EndpointID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	timestamp := d.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderSignature, "t="+strconv.FormatInt(timestamp, 10)+",v1="+Sign(ep.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("endpoint responded %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (d *Dispatcher) record(deliveryID string, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delivery, ok := d.deliveries[deliveryID]
+	if !ok {
+		return
+	}
+	if err == nil {
+		delete(d.deliveries, deliveryID)
+		return
+	}
+
+	now := d.now().UTC()
+	delivery.Attempts++
+	delivery.LastError = err.Error()
+	delivery.UpdatedAt = now
+	if delivery.Attempts >= d.maxTries {
+		delivery.Status = StatusDead
+		log.Printf("webhook delivery %s to %s dead-lettered after %d attempts: %v", delivery.ID, delivery.EndpointID, delivery.Attempts, err)
+		return
+	}
+	delivery.NextAttemptAt = now.Add(d.backoff(delivery.Attempts))
+}
+
+// backoff doubles the base delay for every failed attempt, capped at the
+// maximum delay.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.baseDelay
+	for i := 1; i < attempts && delay < d.maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > d.maxDelay {
+		delay = d.maxDelay
+	}
+	return delay
+}
+
+// Deliveries lists deliveries with the given status, oldest first.
+func (d *Dispatcher) Deliveries(status Status) []Delivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	list := make([]Delivery, 0)
+	for _, delivery := range d.deliveries {
+		if delivery.Status == status {
+			list = append(list, *delivery)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+	return list
+}
+
+// Redrive moves a dead-lettered delivery back to the pending queue with a
+// fresh set of attempts.
+func (d *Dispatcher) Redrive(deliveryID string) (Delivery, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delivery, ok := d.deliveries[deliveryID]
+	if !ok {
+		return Delivery{}, errs.ErrDeliveryNotFound
+	}
+	if delivery.Status != StatusDead {
+		return Delivery{}, errs.ErrDeliveryNotDead
+	}
+
+	now := d.now().UTC()
+	delivery.Status = StatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = now
+	delivery.UpdatedAt = now
+	return *delivery, nil
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<payload>" keyed with
+// secret. Receivers recompute it from the X-Webhook-Signature timestamp to
+// authenticate a delivery and reject replays.
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the X-Webhook-Signature header of a delivery against its
+// payload. Signatures more than tolerance away from now are rejected so a
+// captured delivery cannot be replayed later.
+func Verify(secret, header string, payload []byte, now time.Time, tolerance time.Duration) error {
+	var timestamp int64
+	var signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp, _ = strconv.ParseInt(value, 10, 64)
+		case "v1":
+			signature = value
+		}
+	}
+	if timestamp == 0 || signature == "" {
+		return errs.ErrInvalidSignature
+	}
+	if skew := now.Sub(time.Unix(timestamp, 0)); skew > tolerance || skew < -tolerance {
+		return errs.ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, payload))) {
+		return errs.ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/webhook"
+)
+
+func TestDispatcherSignsDeliveries(t *testing.T) {
+	received := make(chan bool, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var ts int64
+		var sig string
+		for _, part := range strings.Split(r.Header.Get(webhook.HeaderSignature), ",") {
+			switch {
+			case strings.HasPrefix(part, "t="):
+				ts, _ = strconv.ParseInt(strings.TrimPrefix(part, "t="), 10, 64)
+			case strings.HasPrefix(part, "v1="):
+				sig = strings.TrimPrefix(part, "v1=")
+			}
+		}
+		received <- sig == webhook.Sign("endpoint-secret", ts, body) && r.Header.Get(webhook.HeaderEvent) == "result.graded"
+	}))
+	defer server.Close()
+
+	d := webhook.NewDispatcher([]webhook.Endpoint{
+		{ID: "analytics", URL: server.URL, Secret: "endpoint-secret", Events: []string{"result.graded"}},
+	}, webhook.Options{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+	if err := d.Publish("test.created", map[string]string{"test_id": "t1"}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if err := d.Publish("result.graded", map[string]string{"result_id": "r1"}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if pending := d.Deliveries(webhook.StatusPending); len(pending) != 1 {
+		t.Fatalf("expected only the subscribed event to be queued, got %d", len(pending))
+	}
+
+	d.DeliverDue(context.Background())
+	if ok := <-received; !ok {
+		t.Fatal("expected a valid signature and event header")
+	}
+	if pending := d.Deliveries(webhook.StatusPending); len(pending) != 0 {
+		t.Fatalf("expected delivered event to be dropped, got %d pending", len(pending))
+	}
+}
+
+func TestDispatcherDeadLettersAndRedrives(t *testing.T) {
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	d := webhook.NewDispatcher([]webhook.Endpoint{{ID: "lms", URL: server.URL, Secret: "s"}},
+		webhook.Options{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+	if err := d.Publish("answer.submitted", nil); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		time.Sleep(2 * time.Millisecond)
+		d.DeliverDue(context.Background())
+	}
+	dead := d.Deliveries(webhook.StatusDead)
+	if len(dead) != 1 || dead[0].Attempts != 2 || dead[0].LastError == "" {
+		t.Fatalf("expected one dead letter after 2 attempts, got %+v", dead)
+	}
+
+	if _, err := d.Redrive("missing"); !errors.Is(err, errs.ErrDeliveryNotFound) {
+		t.Fatalf("expected ErrDeliveryNotFound, got %v", err)
+	}
+	if _, err := d.Redrive(dead[0].ID); err != nil {
+		t.Fatalf("Redrive failed: %v", err)
+	}
+	if _, err := d.Redrive(dead[0].ID); !errors.Is(err, errs.ErrDeliveryNotDead) {
+		t.Fatalf("expected ErrDeliveryNotDead on pending delivery, got %v", err)
+	}
+
+	healthy.Store(true)
+	d.DeliverDue(context.Background())
+	if left := len(d.Deliveries(webhook.StatusPending)) + len(d.Deliveries(webhook.StatusDead)); left != 0 {
+		t.Fatalf("expected redriven delivery to succeed, %d left", left)
+	}
+}
+
+func TestVerifyChecksSignatureAndAge(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	payload := []byte(`{"event":"result.graded"}`)
+	header := "t=" + strconv.FormatInt(now.Unix(), 10) + ",v1=" + webhook.Sign("secret", now.Unix(), payload)
+
+	if err := webhook.Verify("secret", header, payload, now.Add(time.Minute), 5*time.Minute); err != nil {
+		t.Fatalf("expected a valid signature, got %v", err)
+	}
+	cases := map[string]error{
+		"wrong secret": webhook.Verify("other", header, payload, now, 5*time.Minute),
+		"edited":       webhook.Verify("secret", header, []byte(`{}`), now, 5*time.Minute),
+		"replayed":     webhook.Verify("secret", header, payload, now.Add(time.Hour), 5*time.Minute),
+		"missing":      webhook.Verify("secret", "", payload, now, 5*time.Minute),
+	}
+	for name, err := range cases {
+		if !errors.Is(err, errs.ErrInvalidSignature) {
+			t.Fatalf("%s: expected ErrInvalidSignature, got %v", name, err)
+		}
+	}
+}
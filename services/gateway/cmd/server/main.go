@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
+	"github.com/sky0621/go_work_sample/core/pkg/config"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/health"
+	"github.com/sky0621/go_work_sample/core/pkg/httpmw"
+	"github.com/sky0621/go_work_sample/core/pkg/logfile"
+	"github.com/sky0621/go_work_sample/core/pkg/memory"
+	"github.com/sky0621/go_work_sample/core/pkg/ops"
+	"github.com/sky0621/go_work_sample/core/pkg/tracing"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+	"github.com/sky0621/go_work_sample/gateway/internal/graph"
+)
+
+func main() {
+	serverCfg, err := config.LoadServer(config.ServerDefaults{
+		Name:            "gateway",
+		Addr:            ":8100",
+		ReadTimeout:     5 * time.Second,
+		WriteTimeout:    15 * time.Second,
+		ShutdownTimeout: 10 * time.Second,
+	}, os.Args[1:])
+	if err != nil {
+		log.Fatalf("invalid server configuration: %v", err)
+	}
+
+	logs, err := logfile.Open(serverCfg.LogFile)
+	if err != nil {
+		log.Fatalf("invalid log configuration: %v", err)
+	}
+	defer logs.Close()
+	logLevel := new(slog.LevelVar)
+	logger := slog.New(slog.NewJSONHandler(logs, &slog.HandlerOptions{Level: logLevel}))
+	slog.SetDefault(logger)
+	logging := httpmw.Logging(httpmw.LoggingConfig{Logger: logger})
+
+	// Runtime settings change on SIGHUP or through the admin reload
+	// endpoint; everything else needs a restart.
+	runtimeCfg, err := config.NewReloader()
+	if err != nil {
+		log.Fatalf("invalid runtime configuration: %v", err)
+	}
+	runtimeCfg.Subscribe(func(c config.Runtime) { logLevel.Set(c.LogLevel) })
+
+	tracingCfg, err := config.LoadTracing("gateway-api")
+	if err != nil {
+		log.Fatalf("invalid tracing configuration: %v", err)
+	}
+	tracer := tracingCfg.Provider()
+	tracing.SetProvider(tracer)
+	traced := httpmw.Tracing()
+	cors := httpmw.CORS(httpmw.CORSConfig{AllowedOrigins: func() []string { return runtimeCfg.Current().AllowedOrigins }})
+	rateLimit := httpmw.RateLimit(httpmw.RateLimitConfig{Rules: func() []httpmw.RateRule { return runtimeCfg.Current().RateLimits.Routes }})
+	deprecationUsage := httpmw.NewDeprecationUsage()
+	deprecated := httpmw.Deprecation(httpmw.DeprecationConfig{Routes: func() []httpmw.DeprecatedRoute { return runtimeCfg.Current().Deprecations }, Usage: deprecationUsage})
+
+	storageCfg, err := config.LoadStorage()
+	if err != nil {
+		log.Fatalf("invalid storage configuration: %v", err)
+	}
+	repo, _, err := storageCfg.Open(memory.SampleSeed())
+	if err != nil {
+		log.Fatalf("failed to initialise repository: %v", err)
+	}
+	// The gateway only reads, so it needs no autograder, events or webhooks.
+	assessment := usecase.NewAssessmentService(repo, repo, repo, repo)
+	assessment.SetSubmissions(repo)
+	assessment.SetSessions(repo)
+	profiles := usecase.NewProfileService(repo)
+	devices := usecase.NewDeviceService(repo, repo)
+
+	gql, err := graph.NewHandler(graph.Services{Org: repo, Assessments: assessment, Profiles: profiles})
+	if err != nil {
+		log.Fatalf("invalid GraphQL schema: %v", err)
+	}
+
+	authCfg, err := config.LoadAuth()
+	if err != nil {
+		log.Fatalf("invalid auth configuration: %v", err)
+	}
+	authMiddleware := httpmw.JWT(httpmw.JWTConfig{
+		Signer:  auth.NewSigner(authCfg.Secret),
+		Roles:   []domain.Role{domain.RoleTeacher, domain.RoleStudent, domain.RoleGuardian},
+		Revoked: devices.Revoked,
+	})
+	adminAuth := httpmw.APIKey(httpmw.APIKeyConfig{Key: serverCfg.AdminKey, Prefix: "Bearer "})
+	opsCfg, err := config.LoadOps()
+	if err != nil {
+		log.Fatalf("invalid ops configuration: %v", err)
+	}
+	runbook := ops.NewRunbook("gateway-api", logger, opsCfg.Settings())
+	runbook.AddCache("statistics", assessment.FlushStatistics)
+	runbook.AddIndex("storage", repo.Reindex)
+	runbook.SetLogs(logs)
+
+	mux := http.NewServeMux()
+	gql.Register(mux)
+
+	workers := health.NewRegistry()
+	root := http.NewServeMux()
+	root.Handle(health.Path, workers)
+	root.HandleFunc("/health", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	})
+	root.Handle(config.ReloadPath, adminAuth(runtimeCfg))
+	root.Handle(httpmw.DeprecationsPath, adminAuth(deprecationUsage))
+	root.Handle(ops.AdminPrefix, adminAuth(runbook))
+	root.Handle("/", authMiddleware(deprecated(rateLimit(mux))))
+
+	server := serverCfg.HTTPServer(traced(logging(cors(httpmw.Timezone()(root)))))
+
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	workers.Go(bgCtx, "config-reload", health.WorkerOptions{}, runtimeCfg.WatchSignals)
+	if tracer != nil {
+		workers.Go(bgCtx, "trace-exporter", health.WorkerOptions{}, func(ctx context.Context) {
+			tracer.Run(ctx, tracingCfg.Interval)
+		})
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("gateway-api listening on %s", serverCfg.Addr)
+		if err := serverCfg.ListenAndServe(server); err != nil {
+			errCh <- err
+		}
+	}()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+
+	exitCode := 0
+	select {
+	case sig := <-sigCh:
+		log.Printf("gateway-api shutting down: %s", sig)
+	case worker := <-workers.Dead():
+		log.Printf("gateway-api shutting down: critical worker %s died", worker)
+		exitCode = 1
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("gateway-api failed: %v", err)
+		}
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), serverCfg.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("gateway-api shutdown error: %v", err)
+	}
+	if err := tracer.Flush(ctx); err != nil {
+		log.Printf("gateway-api trace flush error: %v", err)
+	}
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
+}
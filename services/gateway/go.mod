@@ -0,0 +1,9 @@
+module github.com/sky0621/go_work_sample/gateway
+
+go 1.24.3
+
+require github.com/sky0621/go_work_sample/core v0.0.0
+
+require github.com/mattn/go-sqlite3 v1.14.33 // indirect
+
+replace github.com/sky0621/go_work_sample/core => ../core
@@ -0,0 +1,115 @@
+// Package graph serves the GraphQL schema of the gateway, which lets
+// frontends fetch schools, tests and their questions, answers and results in
+// one round trip. Every field resolves through the same usecases and access
+// checks as the REST services, as the signed-in teacher, student or guardian.
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/graphql"
+	"github.com/sky0621/go_work_sample/core/pkg/httpmw"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+// Path is where the schema is served; its SDL is served under SchemaPath.
+const (
+	Path       = "/graphql"
+	SchemaPath = "/graphql/schema"
+)
+
+// Services are what the resolvers read from.
+type Services struct {
+	Org         repository.OrganizationReader
+	Assessments *usecase.AssessmentService
+	Profiles    *usecase.ProfileService
+}
+
+// Handler serves the GraphQL endpoint.
+type Handler struct {
+	svc    Services
+	schema *graphql.Schema
+}
+
+// NewHandler builds the schema over the services.
+func NewHandler(svc Services) (*Handler, error) {
+	schema, err := newSchema(&resolver{svc: svc})
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{svc: svc, schema: schema}, nil
+}
+
+// Register wires the endpoints onto the mux.
+func (h *Handler) Register(mux *http.ServeMux) {
+	mux.Handle(Path, h.withLoader(graphql.Handler(h.schema)))
+	mux.Handle(SchemaPath, graphql.SDLHandler(h.schema))
+}
+
+// withLoader prepares the request's loader for the signed-in principal and
+// resolves the zone timestamps are shown in: the X-Timezone header first,
+// then the viewer's and their school's settings.
+func (h *Handler) withLoader(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		viewer, ok := auth.PrincipalFrom(r.Context())
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		ctx := context.WithValue(r.Context(), loaderKey{}, newLoader(viewer))
+
+		loc, ok := httpmw.LocationFrom(ctx)
+		if !ok {
+			var err error
+			if viewer.Role == domain.RoleTeacher {
+				loc, err = h.svc.Profiles.TeacherLocation(ctx, domain.TeacherID(viewer.ID))
+			} else {
+				loc, err = h.svc.Profiles.StudentLocation(ctx, domain.StudentID(viewer.ID))
+			}
+			if err != nil {
+				loc = time.UTC
+			}
+		}
+		w.Header().Set(httpmw.TimezoneHeader, loc.String())
+		next.ServeHTTP(w, r.WithContext(httpmw.WithLocation(ctx, loc)))
+	})
+}
+
+// loader caches what one request reads, so nested selections over many
+// answers do not fetch a test's questions or results once per answer.
+// Fields resolve one after another, so it needs no locking.
+type loader struct {
+	viewer    auth.Principal
+	school    domain.SchoolID
+	questions map[domain.TestID][]usecase.LocalizedQuestion
+	answers   map[domain.TestID][]domain.Answer
+	results   map[domain.TestID]map[domain.AnswerID]domain.Result
+}
+
+type loaderKey struct{}
+
+func newLoader(viewer auth.Principal) *loader {
+	return &loader{
+		viewer:    viewer,
+		questions: make(map[domain.TestID][]usecase.LocalizedQuestion),
+		answers:   make(map[domain.TestID][]domain.Answer),
+		results:   make(map[domain.TestID]map[domain.AnswerID]domain.Result),
+	}
+}
+
+func loaderFrom(ctx context.Context) *loader {
+	l, _ := ctx.Value(loaderKey{}).(*loader)
+	return l
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
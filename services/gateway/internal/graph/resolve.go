@@ -0,0 +1,387 @@
+package graph
+
+import (
+	"context"
+	"slices"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+// resolver resolves the fields that read the services. Teachers read through
+// the teacher usecases; students, and guardians on their behalf, through the
+// student ones.
+type resolver struct {
+	svc Services
+}
+
+func (r *resolver) school(ctx context.Context, _ any, args map[string]any) (any, error) {
+	l := loaderFrom(ctx)
+	own, err := r.viewerSchool(ctx, l)
+	if err != nil {
+		return nil, err
+	}
+	id := domain.SchoolID(args["id"].(string))
+	if id != own {
+		return nil, l.forbidden()
+	}
+	return r.svc.Org.GetSchool(ctx, id)
+}
+
+func (r *resolver) teacher(ctx context.Context, _ any, args map[string]any) (any, error) {
+	l := loaderFrom(ctx)
+	teacher, err := r.svc.Org.GetTeacher(ctx, domain.TeacherID(args["id"].(string)))
+	if err != nil || teacher == nil {
+		return nil, err
+	}
+	own, err := r.viewerSchool(ctx, l)
+	if err != nil {
+		return nil, err
+	}
+	if teacher.SchoolID != own {
+		return nil, l.forbidden()
+	}
+	return teacher, nil
+}
+
+func (r *resolver) student(ctx context.Context, _ any, args map[string]any) (any, error) {
+	l := loaderFrom(ctx)
+	id := domain.StudentID(args["id"].(string))
+	if _, ok := l.teacher(); !ok && id != l.student() {
+		return nil, l.forbidden()
+	}
+	student, err := r.svc.Org.GetStudent(ctx, id)
+	if err != nil || student == nil {
+		return nil, err
+	}
+	if _, ok := l.teacher(); ok {
+		own, err := r.viewerSchool(ctx, l)
+		if err != nil {
+			return nil, err
+		}
+		school, err := r.studentSchool(ctx, student)
+		if err != nil {
+			return nil, err
+		}
+		if school != own {
+			return nil, l.forbidden()
+		}
+	}
+	return student, nil
+}
+
+func (r *resolver) test(ctx context.Context, _ any, args map[string]any) (any, error) {
+	l := loaderFrom(ctx)
+	id := domain.TestID(args["id"].(string))
+	if teacherID, ok := l.teacher(); ok {
+		return r.svc.Assessments.GetTestForTeacher(ctx, teacherID, id)
+	}
+	return r.svc.Assessments.GetTestForStudent(ctx, l.student(), id)
+}
+
+func (r *resolver) tests(ctx context.Context, _ any, _ map[string]any) (any, error) {
+	l := loaderFrom(ctx)
+	var tests []domain.Test
+	var err error
+	if teacherID, ok := l.teacher(); ok {
+		tests, err = repository.Collect(r.svc.Assessments.ListTestsByTeacher(ctx, teacherID, repository.All))
+	} else {
+		tests, err = repository.Collect(r.svc.Assessments.ListTestsForStudent(ctx, l.student(), repository.All))
+	}
+	return pointers(tests), err
+}
+
+func (r *resolver) schoolGrades(ctx context.Context, source any, _ map[string]any) (any, error) {
+	grades, err := repository.Collect(r.svc.Org.ListGrades(ctx, source.(*domain.School).ID, repository.All))
+	return pointers(grades), err
+}
+
+func (r *resolver) schoolTeachers(ctx context.Context, source any, _ map[string]any) (any, error) {
+	if _, ok := loaderFrom(ctx).teacher(); !ok {
+		return nil, errs.ErrForbiddenStudent
+	}
+	teachers, err := repository.Collect(r.svc.Org.ListTeachers(ctx, source.(*domain.School).ID, repository.All))
+	return pointers(teachers), err
+}
+
+func (r *resolver) gradeSchool(ctx context.Context, source any, _ map[string]any) (any, error) {
+	return r.svc.Org.GetSchool(ctx, source.(*domain.Grade).SchoolID)
+}
+
+func (r *resolver) gradeClasses(ctx context.Context, source any, _ map[string]any) (any, error) {
+	classes, err := repository.Collect(r.svc.Org.ListClasses(ctx, source.(*domain.Grade).ID, repository.All))
+	return pointers(classes), err
+}
+
+func (r *resolver) classGrade(ctx context.Context, source any, _ map[string]any) (any, error) {
+	return r.svc.Org.GetGrade(ctx, source.(*domain.Class).GradeID)
+}
+
+func (r *resolver) classStudents(ctx context.Context, source any, _ map[string]any) (any, error) {
+	if _, ok := loaderFrom(ctx).teacher(); !ok {
+		return nil, errs.ErrForbiddenStudent
+	}
+	students, err := repository.Collect(r.svc.Org.ListStudents(ctx, source.(*domain.Class).ID, repository.All))
+	return pointers(students), err
+}
+
+func (r *resolver) teacherSchool(ctx context.Context, source any, _ map[string]any) (any, error) {
+	return r.svc.Org.GetSchool(ctx, source.(*domain.Teacher).SchoolID)
+}
+
+func (r *resolver) teacherTests(ctx context.Context, source any, _ map[string]any) (any, error) {
+	l := loaderFrom(ctx)
+	teacherID, ok := l.teacher()
+	if !ok || teacherID != source.(*domain.Teacher).ID {
+		return nil, l.forbidden()
+	}
+	tests, err := repository.Collect(r.svc.Assessments.ListTestsByTeacher(ctx, teacherID, repository.All))
+	return pointers(tests), err
+}
+
+func (r *resolver) studentClass(ctx context.Context, source any, _ map[string]any) (any, error) {
+	return r.svc.Org.GetClass(ctx, source.(*domain.Student).ClassID)
+}
+
+func (r *resolver) studentTests(ctx context.Context, source any, _ map[string]any) (any, error) {
+	l := loaderFrom(ctx)
+	studentID := source.(*domain.Student).ID
+	teacherID, ok := l.teacher()
+	if !ok {
+		if studentID != l.student() {
+			return nil, l.forbidden()
+		}
+		tests, err := repository.Collect(r.svc.Assessments.ListTestsForStudent(ctx, studentID, repository.All))
+		return pointers(tests), err
+	}
+
+	tests, err := repository.Collect(r.svc.Assessments.ListTestsByTeacher(ctx, teacherID, repository.All))
+	if err != nil {
+		return nil, err
+	}
+	assigned := make([]domain.Test, 0, len(tests))
+	for _, test := range tests {
+		if slices.Contains(test.AssignedTo, studentID) {
+			assigned = append(assigned, test)
+		}
+	}
+	return pointers(assigned), nil
+}
+
+func (r *resolver) testTeacher(ctx context.Context, source any, _ map[string]any) (any, error) {
+	return r.svc.Org.GetTeacher(ctx, source.(*domain.Test).TeacherID)
+}
+
+func (r *resolver) testQuestions(ctx context.Context, source any, _ map[string]any) (any, error) {
+	return r.questions(ctx, source.(*domain.Test).ID)
+}
+
+func (r *resolver) testAnswers(ctx context.Context, source any, args map[string]any) (any, error) {
+	answers, err := r.answers(ctx, source.(*domain.Test).ID)
+	if err != nil {
+		return nil, err
+	}
+	return filterAnswers(answers, "", args), nil
+}
+
+func (r *resolver) questionAnswers(ctx context.Context, source any, args map[string]any) (any, error) {
+	question := source.(usecase.LocalizedQuestion)
+	answers, err := r.answers(ctx, question.TestID)
+	if err != nil {
+		return nil, err
+	}
+	return filterAnswers(answers, question.ID, args), nil
+}
+
+func (r *resolver) answerQuestion(ctx context.Context, source any, _ map[string]any) (any, error) {
+	answer := source.(domain.Answer)
+	questions, err := r.questions(ctx, answer.TestID)
+	if err != nil {
+		return nil, err
+	}
+	for _, q := range questions {
+		if q.ID == answer.QuestionID {
+			return q, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *resolver) answerStudent(ctx context.Context, source any, _ map[string]any) (any, error) {
+	return r.svc.Org.GetStudent(ctx, source.(domain.Answer).StudentID)
+}
+
+func (r *resolver) answerResult(ctx context.Context, source any, _ map[string]any) (any, error) {
+	answer := source.(domain.Answer)
+	results, err := r.results(ctx, answer.TestID)
+	if err != nil {
+		return nil, err
+	}
+	if res, ok := results[answer.ID]; ok {
+		return res, nil
+	}
+	return nil, nil
+}
+
+// questions returns the test's questions, in the student's locale for
+// students.
+func (r *resolver) questions(ctx context.Context, testID domain.TestID) ([]usecase.LocalizedQuestion, error) {
+	l := loaderFrom(ctx)
+	if questions, ok := l.questions[testID]; ok {
+		return questions, nil
+	}
+	var questions []usecase.LocalizedQuestion
+	if teacherID, ok := l.teacher(); ok {
+		canonical, err := r.svc.Assessments.GetQuestionsForTeacher(ctx, teacherID, testID)
+		if err != nil {
+			return nil, err
+		}
+		for _, q := range canonical {
+			questions = append(questions, usecase.LocalizedQuestion{Question: q})
+		}
+	} else {
+		var err error
+		if questions, err = r.svc.Assessments.GetQuestionsForStudent(ctx, l.student(), testID); err != nil {
+			return nil, err
+		}
+	}
+	l.questions[testID] = questions
+	return questions, nil
+}
+
+// answers returns every answer to the test for teachers and the student's
+// own answers for students.
+func (r *resolver) answers(ctx context.Context, testID domain.TestID) ([]domain.Answer, error) {
+	l := loaderFrom(ctx)
+	if answers, ok := l.answers[testID]; ok {
+		return answers, nil
+	}
+	var answers []domain.Answer
+	var err error
+	if teacherID, ok := l.teacher(); ok {
+		answers, err = repository.Collect(r.svc.Assessments.ListAnswersByTest(ctx, teacherID, testID, repository.All))
+	} else {
+		answers, err = r.svc.Assessments.ListAnswersForStudent(ctx, l.student(), testID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	l.answers[testID] = answers
+	return answers, nil
+}
+
+// results returns the results of the answers the viewer may read, by
+// answer.
+func (r *resolver) results(ctx context.Context, testID domain.TestID) (map[domain.AnswerID]domain.Result, error) {
+	l := loaderFrom(ctx)
+	if results, ok := l.results[testID]; ok {
+		return results, nil
+	}
+	var list []domain.Result
+	var err error
+	if teacherID, ok := l.teacher(); ok {
+		list, err = repository.Collect(r.svc.Assessments.ListResultsByTest(ctx, teacherID, testID, repository.All))
+	} else {
+		list, err = r.svc.Assessments.ListResultsForStudent(ctx, l.student(), testID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[domain.AnswerID]domain.Result, len(list))
+	for _, res := range list {
+		results[res.AnswerID] = res
+	}
+	l.results[testID] = results
+	return results, nil
+}
+
+// viewerSchool returns the school of the viewer.
+func (r *resolver) viewerSchool(ctx context.Context, l *loader) (domain.SchoolID, error) {
+	if l.school != "" {
+		return l.school, nil
+	}
+	if teacherID, ok := l.teacher(); ok {
+		teacher, err := r.svc.Org.GetTeacher(ctx, teacherID)
+		if err != nil {
+			return "", err
+		}
+		if teacher == nil {
+			return "", errs.ErrTeacherNotFound
+		}
+		l.school = teacher.SchoolID
+		return l.school, nil
+	}
+	student, err := r.svc.Org.GetStudent(ctx, l.student())
+	if err != nil {
+		return "", err
+	}
+	if student == nil {
+		return "", errs.ErrStudentNotFound
+	}
+	if l.school, err = r.studentSchool(ctx, student); err != nil {
+		return "", err
+	}
+	return l.school, nil
+}
+
+// studentSchool returns the school of the student's class, or "" when the
+// student is in no class.
+func (r *resolver) studentSchool(ctx context.Context, student *domain.Student) (domain.SchoolID, error) {
+	class, err := r.svc.Org.GetClass(ctx, student.ClassID)
+	if err != nil || class == nil {
+		return "", err
+	}
+	grade, err := r.svc.Org.GetGrade(ctx, class.GradeID)
+	if err != nil || grade == nil {
+		return "", err
+	}
+	return grade.SchoolID, nil
+}
+
+// teacher returns the viewer's ID when they are a teacher.
+func (l *loader) teacher() (domain.TeacherID, bool) {
+	if l.viewer.Role != domain.RoleTeacher {
+		return "", false
+	}
+	return domain.TeacherID(l.viewer.ID), true
+}
+
+// student returns the student a student or guardian viewer reads as.
+func (l *loader) student() domain.StudentID {
+	return domain.StudentID(l.viewer.ID)
+}
+
+func (l *loader) forbidden() error {
+	if _, ok := l.teacher(); ok {
+		return errs.ErrForbiddenTeacher
+	}
+	return errs.ErrForbiddenStudent
+}
+
+// filterAnswers keeps the answers to the question, any when it is empty, of
+// the student named by the studentId argument, if given.
+func filterAnswers(answers []domain.Answer, questionID domain.QuestionID, args map[string]any) []domain.Answer {
+	studentID, _ := args["studentId"].(string)
+	out := make([]domain.Answer, 0, len(answers))
+	for _, a := range answers {
+		if questionID != "" && a.QuestionID != questionID {
+			continue
+		}
+		if studentID != "" && a.StudentID != domain.StudentID(studentID) {
+			continue
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// pointers returns pointers to the items, which object fields resolve from.
+func pointers[T any](items []T) []*T {
+	out := make([]*T, len(items))
+	for i := range items {
+		out[i] = &items[i]
+	}
+	return out
+}
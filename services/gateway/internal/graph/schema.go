@@ -0,0 +1,184 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/graphql"
+	"github.com/sky0621/go_work_sample/core/pkg/httpmw"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+// dateTime is an RFC 3339 timestamp in the viewer's zone.
+var dateTime = &graphql.Scalar{
+	Name:        "DateTime",
+	Description: "An RFC 3339 timestamp in the viewer's time zone.",
+	Serialize: func(v any) (any, error) {
+		t, ok := v.(time.Time)
+		if !ok {
+			return nil, fmt.Errorf("DateTime cannot represent %v", v)
+		}
+		return t.Format(time.RFC3339), nil
+	},
+	Parse: func(v any) (any, error) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("DateTime cannot represent %v", v)
+		}
+		return time.Parse(time.RFC3339, s)
+	},
+}
+
+func newSchema(r *resolver) (*graphql.Schema, error) {
+	school := &graphql.Object{Name: "School"}
+	grade := &graphql.Object{Name: "Grade"}
+	class := &graphql.Object{Name: "Class"}
+	teacher := &graphql.Object{Name: "Teacher"}
+	student := &graphql.Object{Name: "Student"}
+	test := &graphql.Object{Name: "Test"}
+	question := &graphql.Object{Name: "Question"}
+	choice := &graphql.Object{Name: "Choice"}
+	answer := &graphql.Object{Name: "Answer"}
+	result := &graphql.Object{Name: "Result"}
+
+	studentFilter := graphql.Args{"studentId": {Type: graphql.ID, Description: "Only the answers of this student."}}
+
+	school.Fields = graphql.Fields{
+		"id":       value(graphql.NonNullOf(graphql.ID), func(s *domain.School) any { return s.ID }),
+		"name":     value(graphql.NonNullOf(graphql.String), func(s *domain.School) any { return s.Name }),
+		"timezone": value(graphql.String, func(s *domain.School) any { return s.Settings.Timezone }),
+		"grades":   {Type: listOf(grade), Resolve: r.schoolGrades},
+		"teachers": {Type: listOf(teacher), Description: "Visible to teachers only.", Resolve: r.schoolTeachers},
+	}
+	grade.Fields = graphql.Fields{
+		"id":      value(graphql.NonNullOf(graphql.ID), func(g *domain.Grade) any { return g.ID }),
+		"name":    value(graphql.NonNullOf(graphql.String), func(g *domain.Grade) any { return g.Name }),
+		"school":  {Type: school, Resolve: r.gradeSchool},
+		"classes": {Type: listOf(class), Resolve: r.gradeClasses},
+	}
+	class.Fields = graphql.Fields{
+		"id":       value(graphql.NonNullOf(graphql.ID), func(c *domain.Class) any { return c.ID }),
+		"name":     value(graphql.NonNullOf(graphql.String), func(c *domain.Class) any { return c.Name }),
+		"grade":    {Type: grade, Resolve: r.classGrade},
+		"students": {Type: listOf(student), Description: "Visible to teachers only.", Resolve: r.classStudents},
+	}
+	teacher.Fields = graphql.Fields{
+		"id":          value(graphql.NonNullOf(graphql.ID), func(t *domain.Teacher) any { return t.ID }),
+		"name":        value(graphql.NonNullOf(graphql.String), func(t *domain.Teacher) any { return t.Name }),
+		"displayName": value(graphql.String, func(t *domain.Teacher) any { return t.DisplayName }),
+		"school":      {Type: school, Resolve: r.teacherSchool},
+		"tests":       {Type: listOf(test), Description: "Visible to the teacher only.", Resolve: r.teacherTests},
+	}
+	student.Fields = graphql.Fields{
+		"id":          value(graphql.NonNullOf(graphql.ID), func(s *domain.Student) any { return s.ID }),
+		"name":        value(graphql.NonNullOf(graphql.String), func(s *domain.Student) any { return s.Name }),
+		"displayName": value(graphql.String, func(s *domain.Student) any { return s.DisplayName }),
+		"class":       {Type: class, Resolve: r.studentClass},
+		"tests": {
+			Type:        listOf(test),
+			Description: "The published tests assigned to the student, or for a teacher their own tests assigned to the student.",
+			Resolve:     r.studentTests,
+		},
+	}
+	test.Fields = graphql.Fields{
+		"id":              value(graphql.NonNullOf(graphql.ID), func(t *domain.Test) any { return t.ID }),
+		"title":           value(graphql.NonNullOf(graphql.String), func(t *domain.Test) any { return t.Title }),
+		"instructions":    value(graphql.String, func(t *domain.Test) any { return t.Instructions }),
+		"published":       value(graphql.NonNullOf(graphql.Boolean), func(t *domain.Test) any { return t.Published }),
+		"durationMinutes": value(graphql.Int, func(t *domain.Test) any { return t.DurationMinutes }),
+		"opensAt":         timestamp(func(t *domain.Test) *time.Time { return t.OpensAt }),
+		"closesAt":        timestamp(func(t *domain.Test) *time.Time { return t.ClosesAt }),
+		"createdAt":       timestamp(func(t *domain.Test) *time.Time { return &t.CreatedAt }),
+		"teacher":         {Type: teacher, Resolve: r.testTeacher},
+		"questions":       {Type: listOf(question), Resolve: r.testQuestions},
+		"answers": {
+			Type:        listOf(answer),
+			Description: "Every answer for a teacher; a student's own answers for the student.",
+			Args:        studentFilter,
+			Resolve:     r.testAnswers,
+		},
+	}
+	question.Fields = graphql.Fields{
+		"id":         value(graphql.NonNullOf(graphql.ID), func(q usecase.LocalizedQuestion) any { return q.ID }),
+		"sequence":   value(graphql.NonNullOf(graphql.Int), func(q usecase.LocalizedQuestion) any { return q.Sequence }),
+		"prompt":     value(graphql.NonNullOf(graphql.String), func(q usecase.LocalizedQuestion) any { return q.Prompt }),
+		"points":     value(graphql.NonNullOf(graphql.Int), func(q usecase.LocalizedQuestion) any { return q.Points }),
+		"type":       value(graphql.NonNullOf(graphql.String), func(q usecase.LocalizedQuestion) any { return q.AnswerType() }),
+		"difficulty": value(graphql.String, func(q usecase.LocalizedQuestion) any { return q.Difficulty }),
+		"locale":     value(graphql.String, func(q usecase.LocalizedQuestion) any { return q.Locale }),
+		"choices":    value(listOf(choice), func(q usecase.LocalizedQuestion) any { return q.Choices }),
+		"answers": {
+			Type:        listOf(answer),
+			Description: "The answers to the question the viewer may read.",
+			Args:        studentFilter,
+			Resolve:     r.questionAnswers,
+		},
+	}
+	choice.Fields = graphql.Fields{
+		"key":   value(graphql.NonNullOf(graphql.String), func(c domain.Choice) any { return c.Key }),
+		"label": value(graphql.NonNullOf(graphql.String), func(c domain.Choice) any { return c.Label }),
+	}
+	answer.Fields = graphql.Fields{
+		"id":        value(graphql.NonNullOf(graphql.ID), func(a domain.Answer) any { return a.ID }),
+		"response":  value(graphql.NonNullOf(graphql.String), func(a domain.Answer) any { return a.Response }),
+		"note":      value(graphql.String, func(a domain.Answer) any { return a.Note }),
+		"createdAt": timestamp(func(a domain.Answer) *time.Time { return &a.CreatedAt }),
+		"updatedAt": timestamp(func(a domain.Answer) *time.Time { return &a.UpdatedAt }),
+		"question":  {Type: question, Resolve: r.answerQuestion},
+		"student":   {Type: student, Resolve: r.answerStudent},
+		"result":    {Type: result, Description: "Null until the answer is graded.", Resolve: r.answerResult},
+	}
+	result.Fields = graphql.Fields{
+		"id":        value(graphql.NonNullOf(graphql.ID), func(res domain.Result) any { return res.ID }),
+		"score":     value(graphql.NonNullOf(graphql.Int), func(res domain.Result) any { return res.Score }),
+		"rawScore":  value(graphql.Int, func(res domain.Result) any { return res.RawScore }),
+		"feedback":  value(graphql.String, func(res domain.Result) any { return res.Feedback }),
+		"completed": value(graphql.NonNullOf(graphql.Boolean), func(res domain.Result) any { return res.Completed }),
+		"createdAt": timestamp(func(res domain.Result) *time.Time { return &res.CreatedAt }),
+		"updatedAt": timestamp(func(res domain.Result) *time.Time { return &res.UpdatedAt }),
+	}
+
+	id := graphql.Args{"id": {Type: graphql.NonNullOf(graphql.ID)}}
+	query := &graphql.Object{Name: "Query", Fields: graphql.Fields{
+		"school":  {Type: school, Description: "The viewer's school.", Args: id, Resolve: r.school},
+		"teacher": {Type: teacher, Description: "A teacher of the viewer's school.", Args: id, Resolve: r.teacher},
+		"student": {Type: student, Description: "The student themselves, or for a teacher a student of their school.", Args: id, Resolve: r.student},
+		"test":    {Type: test, Args: id, Resolve: r.test},
+		"tests":   {Type: listOf(test), Description: "The teacher's tests, or the published tests assigned to the student.", Resolve: r.tests},
+	}}
+	return graphql.NewSchema(query)
+}
+
+// value declares a field read straight off its source.
+func value[T any](t graphql.Type, get func(T) any) *graphql.Field {
+	return &graphql.Field{
+		Type: t,
+		Resolve: func(_ context.Context, source any, _ map[string]any) (any, error) {
+			return get(source.(T)), nil
+		},
+	}
+}
+
+// timestamp declares a DateTime field shown in the viewer's zone.
+func timestamp[T any](get func(T) *time.Time) *graphql.Field {
+	return &graphql.Field{
+		Type: dateTime,
+		Resolve: func(ctx context.Context, source any, _ map[string]any) (any, error) {
+			t := get(source.(T))
+			if t == nil {
+				return nil, nil
+			}
+			if loc, ok := httpmw.LocationFrom(ctx); ok {
+				return t.In(loc), nil
+			}
+			return t.UTC(), nil
+		},
+	}
+}
+
+// listOf is a list of objects that is never null.
+func listOf(obj *graphql.Object) graphql.Type {
+	return graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(obj)))
+}
@@ -4,28 +4,124 @@ import (
 	"context"
 	"errors"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/sky0621/go_work_sample/core/pkg/audit"
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
+	"github.com/sky0621/go_work_sample/core/pkg/backup"
+	"github.com/sky0621/go_work_sample/core/pkg/config"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/health"
 	"github.com/sky0621/go_work_sample/core/pkg/httpmw"
+	"github.com/sky0621/go_work_sample/core/pkg/logfile"
+	"github.com/sky0621/go_work_sample/core/pkg/magiclink"
 	corememory "github.com/sky0621/go_work_sample/core/pkg/memory"
+	"github.com/sky0621/go_work_sample/core/pkg/openapi"
+	"github.com/sky0621/go_work_sample/core/pkg/ops"
 	"github.com/sky0621/go_work_sample/core/pkg/storage/filedb"
+	"github.com/sky0621/go_work_sample/core/pkg/tracing"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
 	orghttp "github.com/sky0621/go_work_sample/organization/internal/http"
 )
 
 func main() {
-	addr := envOrDefault("ORGANIZATION_API_ADDR", ":8090")
+	serverCfg, err := config.LoadServer(config.ServerDefaults{
+		Name:            "organization",
+		Addr:            ":8090",
+		ReadTimeout:     5 * time.Second,
+		WriteTimeout:    10 * time.Second,
+		ShutdownTimeout: 10 * time.Second,
+	}, os.Args[1:])
+	if err != nil {
+		log.Fatalf("invalid server configuration: %v", err)
+	}
 
-	dataPath := envOrDefault("DATA_STORE_PATH", "./data/state.json")
-	repo, err := filedb.NewRepository(dataPath, corememory.SampleSeed())
+	logs, err := logfile.Open(serverCfg.LogFile)
+	if err != nil {
+		log.Fatalf("invalid log configuration: %v", err)
+	}
+	defer logs.Close()
+	logLevel := new(slog.LevelVar)
+	logger := slog.New(slog.NewJSONHandler(logs, &slog.HandlerOptions{Level: logLevel}))
+	slog.SetDefault(logger)
+	logging := httpmw.Logging(httpmw.LoggingConfig{Logger: logger})
+
+	// Runtime settings change on SIGHUP or through the admin reload
+	// endpoint; everything else needs a restart.
+	runtimeCfg, err := config.NewReloader()
+	if err != nil {
+		log.Fatalf("invalid runtime configuration: %v", err)
+	}
+	runtimeCfg.Subscribe(func(c config.Runtime) { logLevel.Set(c.LogLevel) })
+
+	tracingCfg, err := config.LoadTracing("organization-api")
+	if err != nil {
+		log.Fatalf("invalid tracing configuration: %v", err)
+	}
+	tracer := tracingCfg.Provider()
+	tracing.SetProvider(tracer)
+	traced := httpmw.Tracing()
+	cors := httpmw.CORS(httpmw.CORSConfig{AllowedOrigins: func() []string { return runtimeCfg.Current().AllowedOrigins }})
+	rateLimit := httpmw.RateLimit(httpmw.RateLimitConfig{Rules: func() []httpmw.RateRule { return runtimeCfg.Current().RateLimits.Routes }})
+	deprecationUsage := httpmw.NewDeprecationUsage()
+	deprecated := httpmw.Deprecation(httpmw.DeprecationConfig{Routes: func() []httpmw.DeprecatedRoute { return runtimeCfg.Current().Deprecations }, Usage: deprecationUsage})
+
+	storageCfg, err := config.LoadStorage()
+	if err != nil {
+		log.Fatalf("invalid storage configuration: %v", err)
+	}
+	repo, shared, err := storageCfg.Open(corememory.SampleSeed())
 	if err != nil {
 		log.Fatalf("failed to initialise repository: %v", err)
 	}
 
+	backupCfg, err := config.LoadBackup()
+	if err != nil {
+		log.Fatalf("invalid backup configuration: %v", err)
+	}
+	// Backups cover the shared store; schools with their own store are backed
+	// up where their data resides.
+	backups, err := backup.NewManager(backupCfg.Dir, backupCfg.RestoreDir, backupCfg.Retention, shared)
+	if err != nil {
+		log.Fatalf("failed to initialise backups: %v", err)
+	}
+
+	datasetCfg, err := config.LoadDataset()
+	if err != nil {
+		log.Fatalf("invalid dataset configuration: %v", err)
+	}
+	datasets := usecase.NewDatasetService(repo, repo, repo, repo, datasetCfg.Salt, datasetCfg.MinK)
+
+	authCfg, err := config.LoadAuth()
+	if err != nil {
+		log.Fatalf("invalid auth configuration: %v", err)
+	}
+	signer := auth.NewSigner(authCfg.Secret)
+	tokens := orghttp.NewTokenHandler(repo, repo, orghttp.TokenSettings{
+		Signer: signer,
+		TTL:    authCfg.TTL,
+		MaxTTL: authCfg.MaxTTL,
+	})
+
+	linkCfg, err := config.LoadMagicLink()
+	if err != nil {
+		log.Fatalf("invalid magic link configuration: %v", err)
+	}
+	// Invitations are redeemed by the student service, which shares the
+	// link secret.
+	provisioning := usecase.NewProvisioningService(repo, magiclink.NewSigner(linkCfg.Secret), usecase.ProvisioningSettings{
+		BaseURL:       linkCfg.BaseURL,
+		InvitationTTL: linkCfg.InvitationTTL,
+	})
+
 	handler := orghttp.NewHandler(repo)
+	districts := usecase.NewDistrictService(repo, repo, repo, repo, repo)
+	statuses := usecase.NewGradingStatusService(repo, repo, repo)
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/health", func(w http.ResponseWriter, _ *http.Request) {
@@ -33,23 +129,66 @@ func main() {
 		_, _ = w.Write([]byte("ok"))
 	})
 	handler.Register(mux)
+	// Snapshot staging swaps JSON files, so it is only offered with them.
+	files, _ := shared.(*filedb.Repository)
+	orghttp.NewAdminHandler(backups, files, repo, datasets).Register(mux)
+	orghttp.NewDistrictAdminHandler(districts).Register(mux)
+	orghttp.NewGradingStatusHandler(statuses).Register(mux)
+	orghttp.NewAuditHandler(audit.NewLog(repo)).Register(mux)
+	orghttp.NewProvisioningHandler(provisioning).Register(mux)
+	tokens.Register(mux)
+	mux.Handle(config.ReloadPath, runtimeCfg)
+	mux.Handle(httpmw.DeprecationsPath, deprecationUsage)
+	opsCfg, err := config.LoadOps()
+	if err != nil {
+		log.Fatalf("invalid ops configuration: %v", err)
+	}
+	runbook := ops.NewRunbook("organization-api", logger, opsCfg.Settings())
+	runbook.AddIndex("storage", repo.Reindex)
+	runbook.SetLogs(logs)
+	mux.Handle(ops.AdminPrefix, runbook)
+
+	// District staff sign in with their own tokens rather than the admin key.
+	districtMux := http.NewServeMux()
+	orghttp.NewDistrictHandler(districts).Register(districtMux)
+	districtAuth := httpmw.JWT(httpmw.JWTConfig{
+		Signer: signer,
+		Roles:  []domain.Role{domain.RoleDistrictStaff},
+	})
 
-	adminKey := envOrDefault("ADMIN_API_KEY", "admin-secret")
-	authMiddleware := httpmw.APIKey(httpmw.APIKeyConfig{Key: adminKey, Prefix: "Bearer "})
-
-	server := &http.Server{
-		Addr:              addr,
-		Handler:           logMiddleware(authMiddleware(mux)),
-		ReadTimeout:       5 * time.Second,
-		ReadHeaderTimeout: 5 * time.Second,
-		WriteTimeout:      10 * time.Second,
-		IdleTimeout:       120 * time.Second,
+	authMiddleware := httpmw.APIKey(httpmw.APIKeyConfig{Key: serverCfg.AdminKey, Prefix: "Bearer "})
+
+	workers := health.NewRegistry()
+	root := http.NewServeMux()
+	root.Handle(health.Path, workers)
+	openapi.Register(root, orghttp.OpenAPI())
+	orghttp.RegisterUI(root)
+	root.Handle("/api/districts/", districtAuth(deprecated(rateLimit(districtMux))))
+	root.Handle("/", authMiddleware(deprecated(rateLimit(mux))))
+
+	server := serverCfg.HTTPServer(traced(logging(cors(root))))
+
+	backupCtx, stopBackups := context.WithCancel(context.Background())
+	defer stopBackups()
+	workers.Go(backupCtx, "config-reload", health.WorkerOptions{}, runtimeCfg.WatchSignals)
+	if tracer != nil {
+		workers.Go(backupCtx, "trace-exporter", health.WorkerOptions{}, func(ctx context.Context) {
+			tracer.Run(ctx, tracingCfg.Interval)
+		})
+	}
+	if backupCfg.Enabled() {
+		workers.Go(backupCtx, "backups", health.WorkerOptions{
+			Critical:   true,
+			StaleAfter: 3 * backupCfg.Interval,
+		}, func(ctx context.Context) {
+			backups.Run(ctx, backupCfg.Interval)
+		})
 	}
 
 	errCh := make(chan error, 1)
 	go func() {
-		log.Printf("organization-api listening on %s", addr)
-		if err := server.ListenAndServe(); err != nil {
+		log.Printf("organization-api listening on %s", serverCfg.Addr)
+		if err := serverCfg.ListenAndServe(server); err != nil {
 			errCh <- err
 		}
 	}()
@@ -57,9 +196,13 @@ func main() {
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
+	exitCode := 0
 	select {
 	case sig := <-sigCh:
 		log.Printf("organization-api shutting down: %s", sig)
+	case worker := <-workers.Dead():
+		log.Printf("organization-api shutting down: critical worker %s died", worker)
+		exitCode = 1
 	case err := <-errCh:
 		if !errors.Is(err, http.ErrServerClosed) {
 			log.Fatalf("organization-api failed: %v", err)
@@ -67,35 +210,15 @@ func main() {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), serverCfg.ShutdownTimeout)
 	defer cancel()
 	if err := server.Shutdown(ctx); err != nil {
 		log.Printf("organization-api shutdown error: %v", err)
 	}
-}
-
-func envOrDefault(key, fallback string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
+	if err := tracer.Flush(ctx); err != nil {
+		log.Printf("organization-api trace flush error: %v", err)
+	}
+	if exitCode != 0 {
+		os.Exit(exitCode)
 	}
-	return fallback
-}
-
-func logMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		lw := &loggingResponseWriter{ResponseWriter: w, status: http.StatusOK}
-		next.ServeHTTP(lw, r)
-		log.Printf("%s %s %d %s", r.Method, r.URL.Path, lw.status, time.Since(start))
-	})
-}
-
-type loggingResponseWriter struct {
-	http.ResponseWriter
-	status int
-}
-
-func (w *loggingResponseWriter) WriteHeader(statusCode int) {
-	w.status = statusCode
-	w.ResponseWriter.WriteHeader(statusCode)
 }
@@ -0,0 +1,387 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/backup"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+	"github.com/sky0621/go_work_sample/core/pkg/storage/filedb"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+// AdminHandler exposes operational endpoints for administrators. Snapshot
+// staging works on store, the shared file, and is unavailable when store is
+// nil because another storage backend is configured; school settings and guardians are
+// read and written through org, which may route to per-school stores.
+type AdminHandler struct {
+	backups  *backup.Manager
+	store    *filedb.Repository
+	org      repository.OrganizationRepository
+	datasets *usecase.DatasetService
+}
+
+// NewAdminHandler creates an admin handler instance.
+func NewAdminHandler(backups *backup.Manager, store *filedb.Repository, org repository.OrganizationRepository, datasets *usecase.DatasetService) *AdminHandler {
+	return &AdminHandler{backups: backups, store: store, org: org, datasets: datasets}
+}
+
+// Register wires admin endpoints onto the mux.
+func (h *AdminHandler) Register(mux *http.ServeMux) {
+	mux.Handle("/api/admin/backups", http.HandlerFunc(h.handleBackups))
+	mux.Handle("/api/admin/backups/", http.HandlerFunc(h.handleBackupScoped))
+	mux.Handle("/api/admin/snapshot", http.HandlerFunc(h.handleSnapshot))
+	mux.Handle("/api/admin/snapshot/", http.HandlerFunc(h.handleSnapshotAction))
+	mux.Handle("/api/admin/schools/", http.HandlerFunc(h.handleSchoolSettings))
+	mux.Handle("/api/admin/students/", http.HandlerFunc(h.handleStudentGuardian))
+	mux.Handle("/api/admin/dataset", http.HandlerFunc(h.handleDataset))
+}
+
+type backupResponse struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (h *AdminHandler) handleBackups(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		backups, err := h.backups.List()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		payload := make([]backupResponse, len(backups))
+		for i, b := range backups {
+			payload[i] = toBackupResponse(b)
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"backups": payload})
+	case http.MethodPost:
+		info, err := h.backups.Create(r.Context())
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusCreated, toBackupResponse(info))
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (h *AdminHandler) handleBackupScoped(w http.ResponseWriter, r *http.Request) {
+	parts := splitPath(strings.TrimPrefix(r.URL.Path, "/api/admin/backups/"))
+	if len(parts) != 2 || parts[1] != "restore" {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		Path string `json:"path"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	path := strings.TrimSpace(req.Path)
+	if err := h.backups.Restore(parts[0], path); err != nil {
+		switch err {
+		case errs.ErrBackupNotFound:
+			writeError(w, http.StatusNotFound, err.Error())
+		case errs.ErrInvalidRestoreTarget:
+			writeError(w, http.StatusBadRequest, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"name": parts[0],
+		"path": path,
+	})
+}
+
+type candidateResponse struct {
+	Path     string         `json:"path"`
+	StagedAt time.Time      `json:"staged_at"`
+	Counts   map[string]int `json:"counts"`
+}
+
+// requireFileStore reports whether snapshot staging is available, writing an
+// error when it is not.
+func (h *AdminHandler) requireFileStore(w http.ResponseWriter) bool {
+	if h.store == nil {
+		writeError(w, http.StatusNotImplemented, "snapshot staging needs the file storage backend")
+		return false
+	}
+	return true
+}
+
+func (h *AdminHandler) handleSnapshot(w http.ResponseWriter, r *http.Request) {
+	if !h.requireFileStore(w) {
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		candidate := h.store.Staged()
+		if candidate == nil {
+			writeError(w, http.StatusNotFound, errs.ErrNoCandidate.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, toCandidateResponse(candidate))
+	case http.MethodDelete:
+		h.store.Discard()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (h *AdminHandler) handleSnapshotAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !h.requireFileStore(w) {
+		return
+	}
+
+	switch strings.TrimPrefix(r.URL.Path, "/api/admin/snapshot/") {
+	case "stage":
+		var req struct {
+			Path string `json:"path"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid json payload")
+			return
+		}
+		candidate, err := h.store.Stage(strings.TrimSpace(req.Path))
+		if err != nil {
+			if errors.Is(err, errs.ErrInvalidSnapshot) {
+				writeError(w, http.StatusUnprocessableEntity, err.Error())
+				return
+			}
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, toCandidateResponse(candidate))
+	case "promote":
+		candidate, err := h.store.Promote()
+		if err != nil {
+			if errors.Is(err, errs.ErrNoCandidate) || errors.Is(err, errs.ErrStaleCandidate) {
+				writeError(w, http.StatusConflict, err.Error())
+				return
+			}
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, toCandidateResponse(candidate))
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+type quotasPayload struct {
+	SubmissionsPerMinute int `json:"submissions_per_minute"`
+	ExportJobsPerDay     int `json:"export_jobs_per_day"`
+	// MaxResponseLength caps answer responses in characters; longer ones
+	// are rejected or, with the "truncate" policy, cut to the cap.
+	MaxResponseLength    int    `json:"max_response_length"`
+	ResponseLengthPolicy string `json:"response_length_policy,omitempty"`
+}
+
+func (p quotasPayload) toDomain() domain.SchoolQuotas {
+	return domain.SchoolQuotas{
+		SubmissionsPerMinute: p.SubmissionsPerMinute,
+		ExportJobsPerDay:     p.ExportJobsPerDay,
+		MaxResponseLength:    p.MaxResponseLength,
+		ResponseLengthPolicy: domain.LengthPolicy(p.ResponseLengthPolicy),
+	}
+}
+
+// retentionPayload is how many years answers and results are kept after
+// they last changed; zero keeps them forever.
+type retentionPayload struct {
+	AnswerYears int `json:"answer_years"`
+	ResultYears int `json:"result_years"`
+}
+
+func (p retentionPayload) toDomain() domain.RetentionPolicy {
+	return domain.RetentionPolicy{AnswerYears: p.AnswerYears, ResultYears: p.ResultYears}
+}
+
+type moderationPayload struct {
+	ScoreJump    int `json:"score_jump"`
+	PerfectClass int `json:"perfect_class"`
+}
+
+func (p moderationPayload) toDomain() domain.ModerationSettings {
+	return domain.ModerationSettings{ScoreJump: p.ScoreJump, PerfectClass: p.PerfectClass}
+}
+
+type schoolSettingsPayload struct {
+	Quotas     quotasPayload     `json:"quotas"`
+	Retention  retentionPayload  `json:"retention"`
+	Moderation moderationPayload `json:"moderation"`
+	// Timezone is the IANA zone the school's timestamps are shown in when
+	// users have not picked their own; empty means UTC.
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// handleSchoolSettings serves GET and PUT /api/admin/schools/{id}/settings.
+func (h *AdminHandler) handleSchoolSettings(w http.ResponseWriter, r *http.Request) {
+	parts := splitPath(strings.TrimPrefix(r.URL.Path, "/api/admin/schools/"))
+	if len(parts) != 2 || parts[1] != "settings" {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	school, err := h.org.GetSchool(r.Context(), domain.SchoolID(parts[0]))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if school == nil {
+		writeError(w, http.StatusNotFound, errs.ErrSchoolNotFound.Error())
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, toSchoolSettingsPayload(school.Settings))
+	case http.MethodPut:
+		var req schoolSettingsPayload
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid json payload")
+			return
+		}
+		quotas := req.Quotas.toDomain()
+		if !quotas.Valid() {
+			writeError(w, http.StatusBadRequest, errs.ErrInvalidQuota.Error())
+			return
+		}
+		retention := req.Retention.toDomain()
+		if !retention.Valid() {
+			writeError(w, http.StatusBadRequest, errs.ErrInvalidRetention.Error())
+			return
+		}
+		moderation := req.Moderation.toDomain()
+		if !moderation.Valid() {
+			writeError(w, http.StatusBadRequest, errs.ErrInvalidModeration.Error())
+			return
+		}
+		timezone := strings.TrimSpace(req.Timezone)
+		if timezone != "" {
+			if _, ok := domain.LoadTimezone(timezone); !ok {
+				writeError(w, http.StatusBadRequest, errs.ErrInvalidTimezone.Error())
+				return
+			}
+		}
+		school.Settings.Quotas = quotas
+		school.Settings.Retention = retention
+		school.Settings.Moderation = moderation
+		school.Settings.Timezone = timezone
+		if err := h.org.UpdateSchool(r.Context(), school); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, toSchoolSettingsPayload(school.Settings))
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+type guardianPayload struct {
+	Email string `json:"email"`
+}
+
+// handleStudentGuardian serves GET and PUT /api/admin/students/{id}/guardian.
+// The address receives magic links signing in as the student's guardian; an
+// empty address turns guardian access off.
+func (h *AdminHandler) handleStudentGuardian(w http.ResponseWriter, r *http.Request) {
+	parts := splitPath(strings.TrimPrefix(r.URL.Path, "/api/admin/students/"))
+	if len(parts) != 2 || parts[1] != "guardian" {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	student, err := h.org.GetStudent(r.Context(), domain.StudentID(parts[0]))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if student == nil {
+		writeError(w, http.StatusNotFound, errs.ErrStudentNotFound.Error())
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, guardianPayload{Email: student.GuardianEmail})
+	case http.MethodPut:
+		var req guardianPayload
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid json payload")
+			return
+		}
+		email := strings.TrimSpace(req.Email)
+		if email != "" && !domain.ValidEmail(email) {
+			writeError(w, http.StatusBadRequest, errs.ErrInvalidEmail.Error())
+			return
+		}
+		student.GuardianEmail = email
+		if err := h.org.UpdateStudent(r.Context(), student); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, guardianPayload{Email: student.GuardianEmail})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func toSchoolSettingsPayload(settings domain.SchoolSettings) schoolSettingsPayload {
+	return schoolSettingsPayload{
+		Quotas: quotasPayload{
+			SubmissionsPerMinute: settings.Quotas.SubmissionsPerMinute,
+			ExportJobsPerDay:     settings.Quotas.ExportJobsPerDay,
+			MaxResponseLength:    settings.Quotas.MaxResponseLength,
+			ResponseLengthPolicy: string(settings.Quotas.ResponseLengthPolicy),
+		},
+		Retention: retentionPayload{
+			AnswerYears: settings.Retention.AnswerYears,
+			ResultYears: settings.Retention.ResultYears,
+		},
+		Moderation: moderationPayload{
+			ScoreJump:    settings.Moderation.ScoreJump,
+			PerfectClass: settings.Moderation.PerfectClass,
+		},
+		Timezone: settings.Timezone,
+	}
+}
+
+func toCandidateResponse(c *filedb.Candidate) candidateResponse {
+	return candidateResponse{
+		Path:     c.Path,
+		StagedAt: c.StagedAt,
+		Counts:   c.Counts,
+	}
+}
+
+func toBackupResponse(info backup.Info) backupResponse {
+	return backupResponse{
+		Name:      info.Name,
+		Size:      info.Size,
+		CreatedAt: info.CreatedAt,
+	}
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/audit"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// AuditHandler lets administrators query the trail of who changed tests,
+// answers and grades.
+type AuditHandler struct {
+	log *audit.Log
+}
+
+// NewAuditHandler creates an audit handler instance.
+func NewAuditHandler(log *audit.Log) *AuditHandler {
+	return &AuditHandler{log: log}
+}
+
+// Register wires the audit endpoint onto the mux.
+func (h *AuditHandler) Register(mux *http.ServeMux) {
+	mux.Handle("/api/admin/audit", http.HandlerFunc(h.handleAudit))
+}
+
+// auditEntryResponse is one entry of GET /api/admin/audit.
+type auditEntryResponse struct {
+	EntryID       string     `json:"entry_id"`
+	Action        string     `json:"action"`
+	PrincipalRole string     `json:"principal_role"`
+	PrincipalID   string     `json:"principal_id"`
+	TestID        string     `json:"test_id"`
+	StudentID     string     `json:"student_id,omitempty"`
+	Subject       string     `json:"subject"`
+	OldScore      *int       `json:"old_score,omitempty"`
+	NewScore      *int       `json:"new_score,omitempty"`
+	OldClosesAt   *time.Time `json:"old_closes_at,omitempty"`
+	NewClosesAt   *time.Time `json:"new_closes_at,omitempty"`
+	Reason        string     `json:"reason,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// handleAudit serves GET /api/admin/audit, newest entries first, filtered by
+// ?action=, ?principal_id=, ?test_id=, ?since= and ?until=.
+func (h *AuditHandler) handleAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	page, err := parsePageRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	query := r.URL.Query()
+	filter := repository.AuditFilter{
+		Action:      domain.AuditAction(query.Get("action")),
+		PrincipalID: query.Get("principal_id"),
+		TestID:      domain.TestID(query.Get("test_id")),
+	}
+	if filter.Since, err = parseAuditTime(query.Get("since")); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("since %v", err))
+		return
+	}
+	if filter.Until, err = parseAuditTime(query.Get("until")); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("until %v", err))
+		return
+	}
+
+	entries, err := h.log.Query(r.Context(), filter, page)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"entries": mapSlice(entries.Items, toAuditEntryResponse),
+		"page":    toPageInfo(page, entries.NextCursor),
+	})
+}
+
+// parseAuditTime reads an RFC 3339 bound; empty leaves it open.
+func parseAuditTime(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, errors.New("must be an RFC 3339 time")
+	}
+	return t, nil
+}
+
+func toAuditEntryResponse(e domain.AuditEntry) auditEntryResponse {
+	return auditEntryResponse{
+		EntryID:       string(e.ID),
+		Action:        string(e.Action),
+		PrincipalRole: string(e.PrincipalRole),
+		PrincipalID:   e.PrincipalID,
+		TestID:        string(e.TestID),
+		StudentID:     string(e.StudentID),
+		Subject:       e.Subject,
+		OldScore:      (*int)(e.OldScore),
+		NewScore:      (*int)(e.NewScore),
+		OldClosesAt:   e.OldClosesAt,
+		NewClosesAt:   e.NewClosesAt,
+		Reason:        e.Reason,
+		CreatedAt:     e.CreatedAt,
+	}
+}
@@ -0,0 +1,58 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/export"
+)
+
+// handleDataset serves GET /api/admin/dataset?k=&format=, the anonymized
+// training dataset. With format=ndjson only the rows are streamed.
+func (h *AdminHandler) handleDataset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	format, err := export.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	k := 0
+	if raw := r.URL.Query().Get("k"); raw != "" {
+		k, err = strconv.Atoi(raw)
+		if err != nil || k <= 0 {
+			writeError(w, http.StatusBadRequest, "k must be a positive integer")
+			return
+		}
+	}
+
+	dataset, err := h.datasets.BuildDataset(r.Context(), k)
+	if err != nil {
+		switch err {
+		case errs.ErrInvalidAnonymity:
+			writeError(w, http.StatusBadRequest, err.Error())
+		case errs.ErrDatasetTooSmall:
+			writeError(w, http.StatusUnprocessableEntity, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	if format == export.FormatNDJSON {
+		w.Header().Set("Content-Type", export.ContentTypeNDJSON)
+		w.WriteHeader(http.StatusOK)
+		_ = export.WriteNDJSON(w, dataset.Rows)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"k":                dataset.K,
+		"tests":            dataset.Tests,
+		"suppressed_tests": dataset.SuppressedTests,
+		"rows":             dataset.Rows,
+	})
+}
@@ -0,0 +1,197 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+// DistrictHandler exposes cross-school endpoints to district staff signed in
+// with a district_staff access token.
+type DistrictHandler struct {
+	districts *usecase.DistrictService
+}
+
+// NewDistrictHandler creates a district handler instance.
+func NewDistrictHandler(districts *usecase.DistrictService) *DistrictHandler {
+	return &DistrictHandler{districts: districts}
+}
+
+// Register wires district endpoints onto the mux.
+func (h *DistrictHandler) Register(mux *http.ServeMux) {
+	mux.Handle("/api/districts/", http.HandlerFunc(h.route))
+}
+
+type districtResponse struct {
+	DistrictID string    `json:"district_id"`
+	Name       string    `json:"name"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type schoolAnalyticsResponse struct {
+	SchoolID  string  `json:"school_id,omitempty"`
+	Name      string  `json:"name,omitempty"`
+	Teachers  int     `json:"teachers"`
+	Students  int     `json:"students"`
+	Tests     int     `json:"tests"`
+	Answers   int     `json:"answers"`
+	Graded    int     `json:"graded"`
+	MeanScore float64 `json:"mean_score"`
+}
+
+type districtAnalyticsResponse struct {
+	DistrictID string                    `json:"district_id"`
+	Schools    []schoolAnalyticsResponse `json:"schools"`
+	Totals     schoolAnalyticsResponse   `json:"totals"`
+	ComputedAt time.Time                 `json:"computed_at"`
+}
+
+func (h *DistrictHandler) route(w http.ResponseWriter, r *http.Request) {
+	parts := splitPath(strings.TrimPrefix(r.URL.Path, "/api/districts/"))
+	if len(parts) == 0 {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	principal, ok := auth.PrincipalFrom(r.Context())
+	if !ok || principal.Role != domain.RoleDistrictStaff {
+		writeError(w, http.StatusForbidden, errs.ErrForbiddenDistrict.Error())
+		return
+	}
+	staffID := domain.DistrictStaffID(principal.ID)
+	districtID := domain.DistrictID(parts[0])
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		h.getDistrict(w, r, staffID, districtID)
+	case len(parts) == 2 && parts[1] == "schools" && r.Method == http.MethodGet:
+		h.listSchools(w, r, staffID, districtID)
+	case len(parts) == 2 && parts[1] == "teachers" && r.Method == http.MethodGet:
+		h.listTeachers(w, r, staffID, districtID)
+	case len(parts) == 2 && parts[1] == "analytics" && r.Method == http.MethodGet:
+		h.analytics(w, r, staffID, districtID)
+	case len(parts) == 4 && parts[1] == "schools" && parts[3] == "settings" && r.Method == http.MethodPut:
+		h.updateSchoolSettings(w, r, staffID, districtID, domain.SchoolID(parts[2]))
+	case len(parts) <= 4:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (h *DistrictHandler) getDistrict(w http.ResponseWriter, r *http.Request, staffID domain.DistrictStaffID, districtID domain.DistrictID) {
+	district, err := h.districts.GetDistrict(r.Context(), staffID, districtID)
+	if err != nil {
+		handleDistrictError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toDistrictResponse(*district))
+}
+
+func (h *DistrictHandler) listSchools(w http.ResponseWriter, r *http.Request, staffID domain.DistrictStaffID, districtID domain.DistrictID) {
+	page, err := parsePageRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	schools, err := h.districts.ListSchools(r.Context(), staffID, districtID, page)
+	if err != nil {
+		handleDistrictError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"schools": mapSlice(schools.Items, toSchoolResponse),
+		"page":    toPageInfo(page, schools.NextCursor),
+	})
+}
+
+func (h *DistrictHandler) listTeachers(w http.ResponseWriter, r *http.Request, staffID domain.DistrictStaffID, districtID domain.DistrictID) {
+	page, err := parsePageRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	teachers, err := h.districts.ListTeachers(r.Context(), staffID, districtID, page)
+	if err != nil {
+		handleDistrictError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"teachers": mapSlice(teachers.Items, toTeacherResponse),
+		"page":     toPageInfo(page, teachers.NextCursor),
+	})
+}
+
+func (h *DistrictHandler) analytics(w http.ResponseWriter, r *http.Request, staffID domain.DistrictStaffID, districtID domain.DistrictID) {
+	analytics, err := h.districts.Analytics(r.Context(), staffID, districtID)
+	if err != nil {
+		handleDistrictError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, districtAnalyticsResponse{
+		DistrictID: string(analytics.District.ID),
+		Schools:    mapSlice(analytics.Schools, toSchoolAnalyticsResponse),
+		Totals:     toSchoolAnalyticsResponse(analytics.Totals),
+		ComputedAt: analytics.ComputedAt,
+	})
+}
+
+// updateSchoolSettings serves PUT /api/districts/{id}/schools/{schoolID}/settings
+// for staff granted the school. Only the quotas change; the retention policy
+// is left to administrators.
+func (h *DistrictHandler) updateSchoolSettings(w http.ResponseWriter, r *http.Request, staffID domain.DistrictStaffID, districtID domain.DistrictID, schoolID domain.SchoolID) {
+	var req schoolSettingsPayload
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+	school, err := h.districts.UpdateSchoolQuotas(r.Context(), staffID, districtID, schoolID, req.Quotas.toDomain())
+	if err != nil {
+		handleDistrictError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toSchoolSettingsPayload(school.Settings))
+}
+
+func handleDistrictError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errs.ErrDistrictNotFound),
+		errors.Is(err, errs.ErrStaffNotFound),
+		errors.Is(err, errs.ErrSchoolNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, errs.ErrForbiddenDistrict):
+		writeError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, errs.ErrInvalidDistrict),
+		errors.Is(err, errs.ErrInvalidQuota):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func toDistrictResponse(d domain.District) districtResponse {
+	return districtResponse{
+		DistrictID: string(d.ID),
+		Name:       d.Name,
+		CreatedAt:  d.CreatedAt,
+	}
+}
+
+func toSchoolAnalyticsResponse(a usecase.SchoolAnalytics) schoolAnalyticsResponse {
+	return schoolAnalyticsResponse{
+		SchoolID:  string(a.School.ID),
+		Name:      a.School.Name,
+		Teachers:  a.Teachers,
+		Students:  a.Students,
+		Tests:     a.Tests,
+		Answers:   a.Answers,
+		Graded:    a.Graded,
+		MeanScore: a.MeanScore,
+	}
+}
@@ -0,0 +1,192 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+// DistrictAdminHandler lets administrators create districts, move schools
+// into them and manage district staff with their school grants.
+type DistrictAdminHandler struct {
+	districts *usecase.DistrictService
+}
+
+// NewDistrictAdminHandler creates a district admin handler instance.
+func NewDistrictAdminHandler(districts *usecase.DistrictService) *DistrictAdminHandler {
+	return &DistrictAdminHandler{districts: districts}
+}
+
+// Register wires district admin endpoints onto the mux.
+func (h *DistrictAdminHandler) Register(mux *http.ServeMux) {
+	mux.Handle("/api/admin/districts", http.HandlerFunc(h.handleDistricts))
+	mux.Handle("/api/admin/districts/", http.HandlerFunc(h.handleDistrictScoped))
+	mux.Handle("/api/admin/district-staff/", http.HandlerFunc(h.handleStaffScoped))
+}
+
+type districtStaffResponse struct {
+	StaffID        string    `json:"staff_id"`
+	DistrictID     string    `json:"district_id"`
+	Name           string    `json:"name"`
+	Email          string    `json:"email"`
+	ManagedSchools []string  `json:"managed_schools"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// handleDistricts serves GET and POST /api/admin/districts.
+func (h *DistrictAdminHandler) handleDistricts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		page, err := parsePageRequest(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		districts, err := h.districts.ListDistricts(r.Context(), page)
+		if err != nil {
+			handleDistrictError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"districts": mapSlice(districts.Items, toDistrictResponse),
+			"page":      toPageInfo(page, districts.NextCursor),
+		})
+	case http.MethodPost:
+		var req struct {
+			Name string `json:"name"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid json payload")
+			return
+		}
+		district, err := h.districts.CreateDistrict(r.Context(), req.Name)
+		if err != nil {
+			handleDistrictError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, toDistrictResponse(*district))
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleDistrictScoped serves POST /api/admin/districts/{id}/schools, which
+// moves a school into the district, and POST /api/admin/districts/{id}/staff.
+func (h *DistrictAdminHandler) handleDistrictScoped(w http.ResponseWriter, r *http.Request) {
+	parts := splitPath(strings.TrimPrefix(r.URL.Path, "/api/admin/districts/"))
+	if len(parts) != 2 {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	districtID := domain.DistrictID(parts[0])
+
+	switch parts[1] {
+	case "schools":
+		var req struct {
+			SchoolID string `json:"school_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid json payload")
+			return
+		}
+		school, err := h.districts.AssignSchool(r.Context(), domain.SchoolID(req.SchoolID), districtID)
+		if err != nil {
+			handleDistrictError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, toSchoolResponse(*school))
+	case "staff":
+		var req struct {
+			Name           string   `json:"name"`
+			Email          string   `json:"email"`
+			ManagedSchools []string `json:"managed_schools"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid json payload")
+			return
+		}
+		staff, err := h.districts.CreateStaff(r.Context(), usecase.StaffInput{
+			DistrictID:     districtID,
+			Name:           req.Name,
+			Email:          req.Email,
+			ManagedSchools: toSchoolIDs(req.ManagedSchools),
+		})
+		if err != nil {
+			handleDistrictError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, toDistrictStaffResponse(*staff))
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// handleStaffScoped serves GET /api/admin/district-staff/{id} and PUT
+// /api/admin/district-staff/{id}/grants, which replaces the schools the staff
+// member may modify.
+func (h *DistrictAdminHandler) handleStaffScoped(w http.ResponseWriter, r *http.Request) {
+	parts := splitPath(strings.TrimPrefix(r.URL.Path, "/api/admin/district-staff/"))
+	staffID := domain.DistrictStaffID("")
+	if len(parts) > 0 {
+		staffID = domain.DistrictStaffID(parts[0])
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		staff, err := h.districts.GetStaff(r.Context(), staffID)
+		if err != nil {
+			handleDistrictError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, toDistrictStaffResponse(*staff))
+	case len(parts) == 2 && parts[1] == "grants" && r.Method == http.MethodPut:
+		var req struct {
+			ManagedSchools []string `json:"managed_schools"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid json payload")
+			return
+		}
+		staff, err := h.districts.GrantSchools(r.Context(), staffID, toSchoolIDs(req.ManagedSchools))
+		if err != nil {
+			handleDistrictError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, toDistrictStaffResponse(*staff))
+	case len(parts) == 1 || (len(parts) == 2 && parts[1] == "grants"):
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func toSchoolIDs(ids []string) []domain.SchoolID {
+	out := make([]domain.SchoolID, len(ids))
+	for i, id := range ids {
+		out[i] = domain.SchoolID(strings.TrimSpace(id))
+	}
+	return out
+}
+
+func toDistrictStaffResponse(s domain.DistrictStaff) districtStaffResponse {
+	managed := make([]string, len(s.ManagedSchools))
+	for i, id := range s.ManagedSchools {
+		managed[i] = string(id)
+	}
+	return districtStaffResponse{
+		StaffID:        string(s.ID),
+		DistrictID:     string(s.DistrictID),
+		Name:           s.Name,
+		Email:          s.Email,
+		ManagedSchools: managed,
+		CreatedAt:      s.CreatedAt,
+	}
+}
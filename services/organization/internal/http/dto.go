@@ -0,0 +1,119 @@
+package http
+
+import (
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+// The response types below are the wire schema of the organization API.
+// Fields may be added but are never renamed or removed, so domain changes do
+// not leak to clients. Per-user settings such as notification preferences are
+// served by the teacher and student services, and school settings by the
+// admin API, so they are not part of these payloads.
+
+// schoolResponse is returned by GET /api/schools and /api/schools/{id}.
+// DistrictID is omitted for schools outside any district.
+type schoolResponse struct {
+	SchoolID   string    `json:"school_id"`
+	DistrictID string    `json:"district_id,omitempty"`
+	Name       string    `json:"name"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// gradeResponse is returned by /api/schools/{id}/grades and /api/grades/{id}.
+type gradeResponse struct {
+	GradeID   string    `json:"grade_id"`
+	SchoolID  string    `json:"school_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// classResponse is returned by /api/grades/{id}/classes and /api/classes/{id}.
+type classResponse struct {
+	ClassID   string    `json:"class_id"`
+	GradeID   string    `json:"grade_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// teacherResponse is returned by /api/schools/{id}/teachers and
+// /api/teachers/{id}.
+type teacherResponse struct {
+	TeacherID   string    `json:"teacher_id"`
+	SchoolID    string    `json:"school_id"`
+	Name        string    `json:"name"`
+	DisplayName string    `json:"display_name"`
+	Email       string    `json:"email"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// studentResponse is returned by /api/classes/{id}/students and
+// /api/students/{id}.
+type studentResponse struct {
+	StudentID   string    `json:"student_id"`
+	ClassID     string    `json:"class_id"`
+	Name        string    `json:"name"`
+	DisplayName string    `json:"display_name"`
+	Email       string    `json:"email"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func toSchoolResponse(s domain.School) schoolResponse {
+	return schoolResponse{
+		SchoolID:   string(s.ID),
+		DistrictID: string(s.DistrictID),
+		Name:       s.Name,
+		CreatedAt:  s.CreatedAt,
+	}
+}
+
+func toGradeResponse(g domain.Grade) gradeResponse {
+	return gradeResponse{
+		GradeID:   string(g.ID),
+		SchoolID:  string(g.SchoolID),
+		Name:      g.Name,
+		CreatedAt: g.CreatedAt,
+	}
+}
+
+func toClassResponse(c domain.Class) classResponse {
+	return classResponse{
+		ClassID:   string(c.ID),
+		GradeID:   string(c.GradeID),
+		Name:      c.Name,
+		CreatedAt: c.CreatedAt,
+	}
+}
+
+func toTeacherResponse(t domain.Teacher) teacherResponse {
+	return teacherResponse{
+		TeacherID:   string(t.ID),
+		SchoolID:    string(t.SchoolID),
+		Name:        t.Name,
+		DisplayName: t.DisplayName,
+		Email:       t.Email,
+		CreatedAt:   t.CreatedAt,
+	}
+}
+
+func toStudentResponse(s domain.Student) studentResponse {
+	return studentResponse{
+		StudentID:   string(s.ID),
+		ClassID:     string(s.ClassID),
+		Name:        s.Name,
+		DisplayName: s.DisplayName,
+		Email:       s.Email,
+		CreatedAt:   s.CreatedAt,
+	}
+}
+
+// mapSlice converts every element of in with convert, never returning nil so
+// empty lists encode as [].
+func mapSlice[T, R any](in []T, convert func(T) R) []R {
+	out := make([]R, len(in))
+	for i, v := range in {
+		out[i] = convert(v)
+	}
+	return out
+}
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+// GradingStatusHandler lets administrators see any teacher's tests with how
+// many of their answers are graded.
+type GradingStatusHandler struct {
+	statuses *usecase.GradingStatusService
+}
+
+// NewGradingStatusHandler creates a grading status handler instance.
+func NewGradingStatusHandler(statuses *usecase.GradingStatusService) *GradingStatusHandler {
+	return &GradingStatusHandler{statuses: statuses}
+}
+
+// Register wires the grading status endpoint onto the mux.
+func (h *GradingStatusHandler) Register(mux *http.ServeMux) {
+	mux.Handle("/api/admin/teachers/", http.HandlerFunc(h.handleTeacherTests))
+}
+
+// testStatusResponse is one entry of GET /api/admin/teachers/{id}/tests.
+type testStatusResponse struct {
+	TestID          string     `json:"test_id"`
+	Title           string     `json:"title"`
+	Published       bool       `json:"published"`
+	GradingDeadline *time.Time `json:"grading_deadline,omitempty"`
+	Answers         int        `json:"answers"`
+	Graded          int        `json:"graded"`
+	Ungraded        int        `json:"ungraded"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// handleTeacherTests serves GET /api/admin/teachers/{id}/tests.
+func (h *GradingStatusHandler) handleTeacherTests(w http.ResponseWriter, r *http.Request) {
+	parts := splitPath(strings.TrimPrefix(r.URL.Path, "/api/admin/teachers/"))
+	if len(parts) != 2 || parts[1] != "tests" {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	page, err := parsePageRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	tests, err := h.statuses.ListTeacherTests(r.Context(), domain.TeacherID(parts[0]), page)
+	if err != nil {
+		if errors.Is(err, errs.ErrTeacherNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"tests": mapSlice(tests.Items, toTestStatusResponse),
+		"page":  toPageInfo(page, tests.NextCursor),
+	})
+}
+
+func toTestStatusResponse(s usecase.TestGradingStatus) testStatusResponse {
+	return testStatusResponse{
+		TestID:          string(s.Test.ID),
+		Title:           s.Test.Title,
+		Published:       s.Test.Published,
+		GradingDeadline: s.Test.GradingDeadline,
+		Answers:         s.Answers,
+		Graded:          s.Graded,
+		Ungraded:        s.Ungraded(),
+		CreatedAt:       s.Test.CreatedAt,
+	}
+}
@@ -12,17 +12,18 @@ import (
 
 // Handler exposes read-only organization endpoints.
 type Handler struct {
-	org repository.OrganizationRepository
+	org repository.OrganizationReader
 }
 
 // NewHandler creates a handler instance.
-func NewHandler(org repository.OrganizationRepository) *Handler {
+func NewHandler(org repository.OrganizationReader) *Handler {
 	return &Handler{org: org}
 }
 
 // Register wires endpoints onto the mux.
 func (h *Handler) Register(mux *http.ServeMux) {
 	mux.Handle("/api/schools", h.handleSchools())
+	mux.Handle("/api/schools/", h.handleSchools())
 	mux.Handle("/api/grades/", http.HandlerFunc(h.handleGradeScoped))
 	mux.Handle("/api/classes/", http.HandlerFunc(h.handleClassScoped))
 	mux.Handle("/api/teachers/", http.HandlerFunc(h.handleTeacherScoped))
@@ -59,7 +60,7 @@ func (h *Handler) handleSchoolScoped(w http.ResponseWriter, r *http.Request) {
 	schoolID := domain.SchoolID(parts[0])
 
 	if len(parts) == 1 {
-		school, err := h.org.GetSchool(schoolID)
+		school, err := h.org.GetSchool(r.Context(), schoolID)
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, err.Error())
 			return
@@ -68,27 +69,43 @@ func (h *Handler) handleSchoolScoped(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusNotFound, errs.ErrSchoolNotFound.Error())
 			return
 		}
-		writeJSON(w, http.StatusOK, school)
+		writeJSON(w, http.StatusOK, toSchoolResponse(*school))
 		return
 	}
 
 	if len(parts) == 2 {
 		switch parts[1] {
 		case "grades":
-			grades, err := h.org.ListGrades(schoolID)
+			page, err := parsePageRequest(r)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			grades, err := h.org.ListGrades(r.Context(), schoolID, page)
 			if err != nil {
 				writeError(w, http.StatusInternalServerError, err.Error())
 				return
 			}
-			writeJSON(w, http.StatusOK, map[string]any{"grades": grades})
+			writeJSON(w, http.StatusOK, map[string]any{
+				"grades": mapSlice(grades.Items, toGradeResponse),
+				"page":   toPageInfo(page, grades.NextCursor),
+			})
 			return
 		case "teachers":
-			teachers, err := h.org.ListTeachers(schoolID)
+			page, err := parsePageRequest(r)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			teachers, err := h.org.ListTeachers(r.Context(), schoolID, page)
 			if err != nil {
 				writeError(w, http.StatusInternalServerError, err.Error())
 				return
 			}
-			writeJSON(w, http.StatusOK, map[string]any{"teachers": teachers})
+			writeJSON(w, http.StatusOK, map[string]any{
+				"teachers": mapSlice(teachers.Items, toTeacherResponse),
+				"page":     toPageInfo(page, teachers.NextCursor),
+			})
 			return
 		}
 	}
@@ -111,7 +128,7 @@ func (h *Handler) handleGradeScoped(w http.ResponseWriter, r *http.Request) {
 	gradeID := domain.GradeID(parts[0])
 
 	if len(parts) == 1 {
-		grade, err := h.org.GetGrade(gradeID)
+		grade, err := h.org.GetGrade(r.Context(), gradeID)
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, err.Error())
 			return
@@ -120,17 +137,25 @@ func (h *Handler) handleGradeScoped(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusNotFound, errs.ErrGradeNotFound.Error())
 			return
 		}
-		writeJSON(w, http.StatusOK, grade)
+		writeJSON(w, http.StatusOK, toGradeResponse(*grade))
 		return
 	}
 
 	if len(parts) == 2 && parts[1] == "classes" {
-		classes, err := h.org.ListClasses(gradeID)
+		page, err := parsePageRequest(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		classes, err := h.org.ListClasses(r.Context(), gradeID, page)
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
-		writeJSON(w, http.StatusOK, map[string]any{"classes": classes})
+		writeJSON(w, http.StatusOK, map[string]any{
+			"classes": mapSlice(classes.Items, toClassResponse),
+			"page":    toPageInfo(page, classes.NextCursor),
+		})
 		return
 	}
 
@@ -151,7 +176,7 @@ func (h *Handler) handleClassScoped(w http.ResponseWriter, r *http.Request) {
 	classID := domain.ClassID(parts[0])
 
 	if len(parts) == 1 {
-		class, err := h.org.GetClass(classID)
+		class, err := h.org.GetClass(r.Context(), classID)
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, err.Error())
 			return
@@ -160,17 +185,25 @@ func (h *Handler) handleClassScoped(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusNotFound, errs.ErrClassNotFound.Error())
 			return
 		}
-		writeJSON(w, http.StatusOK, class)
+		writeJSON(w, http.StatusOK, toClassResponse(*class))
 		return
 	}
 
 	if len(parts) == 2 && parts[1] == "students" {
-		students, err := h.org.ListStudents(classID)
+		page, err := parsePageRequest(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		students, err := h.org.ListStudents(r.Context(), classID, page)
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
-		writeJSON(w, http.StatusOK, map[string]any{"students": students})
+		writeJSON(w, http.StatusOK, map[string]any{
+			"students": mapSlice(students.Items, toStudentResponse),
+			"page":     toPageInfo(page, students.NextCursor),
+		})
 		return
 	}
 
@@ -189,7 +222,7 @@ func (h *Handler) handleTeacherScoped(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	teacher, err := h.org.GetTeacher(domain.TeacherID(parts[0]))
+	teacher, err := h.org.GetTeacher(r.Context(), domain.TeacherID(parts[0]))
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -199,7 +232,7 @@ func (h *Handler) handleTeacherScoped(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, teacher)
+	writeJSON(w, http.StatusOK, toTeacherResponse(*teacher))
 }
 
 func (h *Handler) handleStudentScoped(w http.ResponseWriter, r *http.Request) {
@@ -214,7 +247,7 @@ func (h *Handler) handleStudentScoped(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	student, err := h.org.GetStudent(domain.StudentID(parts[0]))
+	student, err := h.org.GetStudent(r.Context(), domain.StudentID(parts[0]))
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -224,17 +257,25 @@ func (h *Handler) handleStudentScoped(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, student)
+	writeJSON(w, http.StatusOK, toStudentResponse(*student))
 }
 
 func (h *Handler) listSchools(w http.ResponseWriter, r *http.Request) {
-	schools, err := h.org.ListSchools()
+	page, err := parsePageRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	schools, err := h.org.ListSchools(r.Context(), page)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]any{"schools": schools})
+	writeJSON(w, http.StatusOK, map[string]any{
+		"schools": mapSlice(schools.Items, toSchoolResponse),
+		"page":    toPageInfo(page, schools.NextCursor),
+	})
 }
 
 func splitPath(path string) []string {
@@ -0,0 +1,183 @@
+package http
+
+import (
+	"github.com/sky0621/go_work_sample/core/pkg/export"
+	"github.com/sky0621/go_work_sample/core/pkg/openapi"
+	"github.com/sky0621/go_work_sample/core/pkg/ops"
+)
+
+// OpenAPI describes the organization API.
+func OpenAPI() *openapi.Document {
+	const district = "/api/districts/{districtID}"
+	b := openapi.New(openapi.Info{
+		Title:   "Organization API",
+		Version: "1.0.0",
+		Description: "Schools, grades, classes and people, plus administration. " +
+			"The /api/districts routes take a district staff token from POST /api/auth/token; " +
+			"every other route takes the admin API key as the bearer token.",
+	})
+
+	b.Add("GET", "/api/schools", openapi.Route{
+		Summary:  "List schools",
+		Tag:      "organization",
+		Query:    openapi.PageQuery(),
+		Response: openapi.Object{"schools": []schoolResponse{}, "page": pageInfo{}},
+	})
+	b.Add("GET", "/api/schools/{schoolID}", openapi.Route{Summary: "Get a school", Tag: "organization", Response: schoolResponse{}})
+	b.Add("GET", "/api/schools/{schoolID}/grades", openapi.Route{
+		Summary:  "List a school's grades",
+		Tag:      "organization",
+		Query:    openapi.PageQuery(),
+		Response: openapi.Object{"grades": []gradeResponse{}, "page": pageInfo{}},
+	})
+	b.Add("GET", "/api/schools/{schoolID}/teachers", openapi.Route{
+		Summary:  "List a school's teachers",
+		Tag:      "organization",
+		Query:    openapi.PageQuery(),
+		Response: openapi.Object{"teachers": []teacherResponse{}, "page": pageInfo{}},
+	})
+	b.Add("GET", "/api/grades/{gradeID}", openapi.Route{Summary: "Get a grade", Tag: "organization", Response: gradeResponse{}})
+	b.Add("GET", "/api/grades/{gradeID}/classes", openapi.Route{
+		Summary:  "List a grade's classes",
+		Tag:      "organization",
+		Query:    openapi.PageQuery(),
+		Response: openapi.Object{"classes": []classResponse{}, "page": pageInfo{}},
+	})
+	b.Add("GET", "/api/classes/{classID}", openapi.Route{Summary: "Get a class", Tag: "organization", Response: classResponse{}})
+	b.Add("GET", "/api/classes/{classID}/students", openapi.Route{
+		Summary:  "List a class's students",
+		Tag:      "organization",
+		Query:    openapi.PageQuery(),
+		Response: openapi.Object{"students": []studentResponse{}, "page": pageInfo{}},
+	})
+	b.Add("GET", "/api/teachers/{teacherID}", openapi.Route{Summary: "Get a teacher", Tag: "organization", Response: teacherResponse{}})
+	b.Add("GET", "/api/students/{studentID}", openapi.Route{Summary: "Get a student", Tag: "organization", Response: studentResponse{}})
+
+	b.Add("POST", "/api/auth/token", openapi.Route{
+		Summary:  "Issue an access token for a teacher, student or district staff member",
+		Tag:      "auth",
+		Request:  openapi.Object{"role": "", "subject_id": "", "ttl_minutes": 0},
+		Status:   201,
+		Response: tokenResponse{},
+	})
+
+	b.Add("GET", "/api/admin/backups", openapi.Route{Summary: "List backups", Tag: "admin", Response: openapi.Object{"backups": []backupResponse{}}})
+	b.Add("POST", "/api/admin/backups", openapi.Route{Summary: "Take a backup", Tag: "admin", Status: 201, Response: backupResponse{}})
+	b.Add("POST", "/api/admin/backups/{name}/restore", openapi.Route{
+		Summary:  "Restore a backup to a new path in the restore directory",
+		Tag:      "admin",
+		Request:  openapi.Object{"path": ""},
+		Response: openapi.Object{"name": "", "path": ""},
+	})
+	b.Add("GET", "/api/admin/snapshot", openapi.Route{Summary: "Get the staged snapshot", Tag: "admin", Response: candidateResponse{}})
+	b.Add("DELETE", "/api/admin/snapshot", openapi.Route{Summary: "Discard the staged snapshot", Tag: "admin", Status: 204})
+	b.Add("POST", "/api/admin/snapshot/stage", openapi.Route{Summary: "Stage a snapshot", Tag: "admin", Request: openapi.Object{"path": ""}, Response: candidateResponse{}})
+	b.Add("POST", "/api/admin/snapshot/promote", openapi.Route{Summary: "Promote the staged snapshot", Tag: "admin", Response: candidateResponse{}})
+	b.Add("GET", ops.AdminPrefix, openapi.Route{
+		Summary:  "List the maintenance operations and their recent runs",
+		Tag:      "admin",
+		Response: openapi.Object{"service": "", "verbs": []openapi.Object{{"verb": "", "description": "", "enabled": true, "targets": []string{}}}, "history": []ops.Run{}},
+	})
+	b.Add("POST", ops.AdminPrefix+"{verb}", openapi.Route{
+		Summary:  "Run a maintenance operation; the X-Operator header names who runs it",
+		Tag:      "admin",
+		Request:  openapi.Object{"reason": ""},
+		Response: ops.Run{},
+	})
+	b.Add("GET", "/api/admin/schools/{schoolID}/settings", openapi.Route{Summary: "Get a school's settings", Tag: "admin", Response: schoolSettingsPayload{}})
+	b.Add("PUT", "/api/admin/schools/{schoolID}/settings", openapi.Route{Summary: "Update a school's settings", Tag: "admin", Request: schoolSettingsPayload{}, Response: schoolSettingsPayload{}})
+	b.Add("GET", "/api/admin/students/{studentID}/guardian", openapi.Route{Summary: "Get a student's guardian email", Tag: "admin", Response: guardianPayload{}})
+	b.Add("PUT", "/api/admin/students/{studentID}/guardian", openapi.Route{Summary: "Set or clear a student's guardian email", Tag: "admin", Request: guardianPayload{}, Response: guardianPayload{}})
+	credentials := openapi.Query("format", "json (default) or csv, the class's credential sheet.")
+	b.Add("POST", "/api/admin/classes/{classID}/students", openapi.Route{
+		Summary:  "Create student accounts in bulk with invitation links",
+		Tag:      "admin",
+		Query:    []openapi.Parameter{credentials},
+		Request:  openapi.Object{"students": []rosterEntry{}},
+		Status:   201,
+		Response: openapi.Object{"class_id": "", "invitations": []invitationResponse{}},
+	})
+	b.Add("POST", "/api/admin/classes/{classID}/invitations", openapi.Route{
+		Summary:  "Reissue the invitation links of a class's students",
+		Tag:      "admin",
+		Query:    []openapi.Parameter{credentials},
+		Response: openapi.Object{"class_id": "", "invitations": []invitationResponse{}},
+	})
+	b.Add("GET", "/api/admin/dataset", openapi.Route{
+		Summary: "Build the anonymized research dataset",
+		Tag:     "admin",
+		Query: []openapi.Parameter{
+			openapi.Query("format", "json (default) or ndjson, which streams the rows."),
+			openapi.Query("k", "Minimum students per released test."),
+		},
+		Response: openapi.Object{"k": 0, "tests": 0, "suppressed_tests": 0, "rows": []export.DatasetRow{}},
+	})
+	b.Add("GET", "/api/admin/teachers/{teacherID}/tests", openapi.Route{
+		Summary:  "List a teacher's tests with their grading status",
+		Tag:      "admin",
+		Query:    openapi.PageQuery(),
+		Response: openapi.Object{"tests": []testStatusResponse{}, "page": pageInfo{}},
+	})
+	b.Add("GET", "/api/admin/audit", openapi.Route{
+		Summary: "Query the audit trail of changes to tests, answers and grades, newest first",
+		Tag:     "admin",
+		Query: append([]openapi.Parameter{
+			openapi.Query("action", "test.created, test.published, answer.submitted, answer.deleted or grade.changed."),
+			openapi.Query("principal_id", "Only entries by this teacher or student."),
+			openapi.Query("test_id", "Only entries about this test."),
+			openapi.Query("since", "RFC 3339 time of the oldest entry."),
+			openapi.Query("until", "RFC 3339 time the entries precede."),
+		}, openapi.PageQuery()...),
+		Response: openapi.Object{"entries": []auditEntryResponse{}, "page": pageInfo{}},
+	})
+
+	b.Add("GET", "/api/admin/districts", openapi.Route{
+		Summary:  "List districts",
+		Tag:      "districts",
+		Query:    openapi.PageQuery(),
+		Response: openapi.Object{"districts": []districtResponse{}, "page": pageInfo{}},
+	})
+	b.Add("POST", "/api/admin/districts", openapi.Route{Summary: "Create a district", Tag: "districts", Request: openapi.Object{"name": ""}, Status: 201, Response: districtResponse{}})
+	b.Add("POST", "/api/admin/districts/{districtID}/schools", openapi.Route{
+		Summary:  "Move a school into the district",
+		Tag:      "districts",
+		Request:  openapi.Object{"school_id": ""},
+		Response: schoolResponse{},
+	})
+	b.Add("POST", "/api/admin/districts/{districtID}/staff", openapi.Route{
+		Summary:  "Create a district staff member",
+		Tag:      "districts",
+		Request:  openapi.Object{"name": "", "email": "", "managed_schools": []string{}},
+		Status:   201,
+		Response: districtStaffResponse{},
+	})
+	b.Add("GET", "/api/admin/district-staff/{staffID}", openapi.Route{Summary: "Get a district staff member", Tag: "districts", Response: districtStaffResponse{}})
+	b.Add("PUT", "/api/admin/district-staff/{staffID}/grants", openapi.Route{
+		Summary:  "Replace the schools a staff member may modify",
+		Tag:      "districts",
+		Request:  openapi.Object{"managed_schools": []string{}},
+		Response: districtStaffResponse{},
+	})
+
+	b.Add("GET", district, openapi.Route{Summary: "Get the staff member's district", Tag: "district staff", Response: districtResponse{}})
+	b.Add("GET", district+"/schools", openapi.Route{
+		Summary:  "List the district's schools",
+		Tag:      "district staff",
+		Query:    openapi.PageQuery(),
+		Response: openapi.Object{"schools": []schoolResponse{}, "page": pageInfo{}},
+	})
+	b.Add("GET", district+"/teachers", openapi.Route{
+		Summary:  "List the district's teachers",
+		Tag:      "district staff",
+		Query:    openapi.PageQuery(),
+		Response: openapi.Object{"teachers": []teacherResponse{}, "page": pageInfo{}},
+	})
+	b.Add("GET", district+"/analytics", openapi.Route{Summary: "Get per-school and district totals", Tag: "district staff", Response: districtAnalyticsResponse{}})
+	b.Add("PUT", district+"/schools/{schoolID}/settings", openapi.Route{
+		Summary:  "Update the quotas of a granted school",
+		Tag:      "district staff",
+		Request:  schoolSettingsPayload{},
+		Response: schoolSettingsPayload{},
+	})
+	return b.Document()
+}
@@ -0,0 +1,46 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+var errInvalidLimit = fmt.Errorf("limit must be between 1 and %d", maxPageLimit)
+
+// pageInfo is added as "page" next to the items of every list response.
+// Clients pass next_cursor back as ?cursor= for the following page; it is
+// absent on the last page.
+type pageInfo struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// parsePageRequest reads ?limit= and ?cursor=, defaulting the limit to 50.
+func parsePageRequest(r *http.Request) (repository.PageRequest, error) {
+	page := repository.PageRequest{Limit: defaultPageLimit, Cursor: r.URL.Query().Get("cursor")}
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return repository.PageRequest{}, errInvalidLimit
+		}
+		page.Limit = limit
+	}
+	if page.Cursor != "" {
+		if _, _, err := repository.DecodeCursor(page.Cursor); err != nil {
+			return repository.PageRequest{}, err
+		}
+	}
+	return page, nil
+}
+
+func toPageInfo(req repository.PageRequest, nextCursor string) pageInfo {
+	return pageInfo{Limit: req.Limit, NextCursor: nextCursor}
+}
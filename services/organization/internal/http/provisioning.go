@@ -0,0 +1,160 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/export"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+// ProvisioningHandler creates student accounts in bulk and hands out the
+// credential sheets of their classes.
+type ProvisioningHandler struct {
+	provisioning *usecase.ProvisioningService
+}
+
+// NewProvisioningHandler creates a provisioning handler instance.
+func NewProvisioningHandler(provisioning *usecase.ProvisioningService) *ProvisioningHandler {
+	return &ProvisioningHandler{provisioning: provisioning}
+}
+
+// Register wires the provisioning endpoints onto the mux.
+func (h *ProvisioningHandler) Register(mux *http.ServeMux) {
+	mux.Handle("/api/admin/classes/", http.HandlerFunc(h.handleClassScoped))
+}
+
+type rosterEntry struct {
+	Name          string `json:"name"`
+	DisplayName   string `json:"display_name"`
+	Email         string `json:"email"`
+	GuardianEmail string `json:"guardian_email"`
+}
+
+type invitationResponse struct {
+	Student    studentResponse `json:"student"`
+	SignInLink string          `json:"sign_in_link"`
+	ExpiresAt  time.Time       `json:"expires_at"`
+}
+
+// handleClassScoped serves POST /api/admin/classes/{id}/students, which
+// provisions a roster, and POST /api/admin/classes/{id}/invitations, which
+// reissues the invitation of every student of the class. Both answer with
+// the invitations, or with format=csv with the class's credential sheet.
+func (h *ProvisioningHandler) handleClassScoped(w http.ResponseWriter, r *http.Request) {
+	parts := splitPath(strings.TrimPrefix(r.URL.Path, "/api/admin/classes/"))
+	if len(parts) != 2 || (parts[1] != "students" && parts[1] != "invitations") {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	if format != "" && format != "json" && format != "csv" {
+		writeError(w, http.StatusBadRequest, errs.ErrUnsupportedFormat.Error())
+		return
+	}
+	classID := domain.ClassID(parts[0])
+
+	var (
+		invitations []usecase.Invitation
+		err         error
+	)
+	if parts[1] == "students" {
+		var req struct {
+			Students []rosterEntry `json:"students"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid json payload")
+			return
+		}
+		roster := make([]usecase.StudentDraft, len(req.Students))
+		for i, entry := range req.Students {
+			roster[i] = usecase.StudentDraft{
+				Name:          entry.Name,
+				DisplayName:   entry.DisplayName,
+				Email:         entry.Email,
+				GuardianEmail: entry.GuardianEmail,
+			}
+		}
+		invitations, err = h.provisioning.ProvisionStudents(r.Context(), classID, roster)
+	} else {
+		invitations, err = h.provisioning.ClassInvitations(r.Context(), classID)
+	}
+	if err != nil {
+		handleProvisioningError(w, err)
+		return
+	}
+
+	// The links sign the students in, so no cache may keep them.
+	w.Header().Set("Cache-Control", "no-store")
+	status := http.StatusOK
+	if parts[1] == "students" {
+		status = http.StatusCreated
+	}
+	if format == "csv" {
+		writeCredentialSheet(w, status, classID, invitations)
+		return
+	}
+	writeJSON(w, status, map[string]any{
+		"class_id":    string(classID),
+		"invitations": mapSlice(invitations, toInvitationResponse),
+	})
+}
+
+// writeCredentialSheet sends the sheet as a download. It is built in memory
+// first, as rosters are small, so a failure still gets a JSON error.
+func writeCredentialSheet(w http.ResponseWriter, status int, classID domain.ClassID, invitations []usecase.Invitation) {
+	rows := make([]export.CredentialSheetRow, len(invitations))
+	for i, inv := range invitations {
+		name := inv.Student.Name
+		if inv.Student.DisplayName != "" {
+			name = inv.Student.DisplayName
+		}
+		rows[i] = export.CredentialSheetRow{
+			StudentID:   string(inv.Student.ID),
+			StudentName: name,
+			Email:       inv.Student.Email,
+			SignInLink:  inv.Link,
+			ExpiresAt:   inv.ExpiresAt,
+		}
+	}
+	var sheet bytes.Buffer
+	if err := export.WriteCredentialSheet(&sheet, rows); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", export.ContentTypeCSV+"; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="credentials-`+string(classID)+`.csv"`)
+	w.WriteHeader(status)
+	_, _ = w.Write(sheet.Bytes())
+}
+
+func handleProvisioningError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errs.ErrClassNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, errs.ErrInvalidRoster), errors.Is(err, errs.ErrInvalidProfile), errors.Is(err, errs.ErrInvalidEmail):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, errs.ErrEmailTaken):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func toInvitationResponse(inv usecase.Invitation) invitationResponse {
+	return invitationResponse{
+		Student:    toStudentResponse(inv.Student),
+		SignInLink: inv.Link,
+		ExpiresAt:  inv.ExpiresAt,
+	}
+}
@@ -0,0 +1,125 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+)
+
+// TokenSettings configures issuance of access tokens.
+type TokenSettings struct {
+	Signer *auth.Signer
+	TTL    time.Duration
+	MaxTTL time.Duration
+}
+
+// TokenHandler issues access tokens for teachers, students and district
+// staff on behalf of a trusted sign-in front end holding the admin key.
+type TokenHandler struct {
+	org       repository.OrganizationReader
+	districts repository.DistrictReader
+	settings  TokenSettings
+}
+
+// NewTokenHandler creates a token handler instance.
+func NewTokenHandler(org repository.OrganizationReader, districts repository.DistrictReader, settings TokenSettings) *TokenHandler {
+	return &TokenHandler{org: org, districts: districts, settings: settings}
+}
+
+// Register wires the token endpoint onto the mux.
+func (h *TokenHandler) Register(mux *http.ServeMux) {
+	mux.Handle("/api/auth/token", http.HandlerFunc(h.issueToken))
+}
+
+type tokenResponse struct {
+	Token     string    `json:"token"`
+	Role      string    `json:"role"`
+	SubjectID string    `json:"subject_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// issueToken serves POST /api/auth/token, signing a token whose claims name
+// an existing teacher, student or district staff member.
+func (h *TokenHandler) issueToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		Role       string `json:"role"`
+		SubjectID  string `json:"subject_id"`
+		TTLMinutes int    `json:"ttl_minutes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	ttl := h.settings.TTL
+	if req.TTLMinutes > 0 {
+		ttl = time.Duration(req.TTLMinutes) * time.Minute
+	}
+	if ttl > h.settings.MaxTTL {
+		writeError(w, http.StatusBadRequest, "ttl_minutes exceeds the allowed maximum")
+		return
+	}
+
+	principal := auth.Principal{Role: domain.Role(req.Role), ID: strings.TrimSpace(req.SubjectID)}
+	if !principal.Valid() {
+		writeError(w, http.StatusBadRequest, auth.ErrInvalidPrincipal.Error())
+		return
+	}
+
+	switch principal.Role {
+	case domain.RoleTeacher:
+		teacher, err := h.org.GetTeacher(r.Context(), domain.TeacherID(principal.ID))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if teacher == nil {
+			writeError(w, http.StatusNotFound, errs.ErrTeacherNotFound.Error())
+			return
+		}
+	case domain.RoleStudent:
+		student, err := h.org.GetStudent(r.Context(), domain.StudentID(principal.ID))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if student == nil {
+			writeError(w, http.StatusNotFound, errs.ErrStudentNotFound.Error())
+			return
+		}
+	case domain.RoleDistrictStaff:
+		staff, err := h.districts.GetDistrictStaff(r.Context(), domain.DistrictStaffID(principal.ID))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if staff == nil {
+			writeError(w, http.StatusNotFound, errs.ErrStaffNotFound.Error())
+			return
+		}
+	}
+
+	token, claims, err := h.settings.Signer.Issue(principal, ttl)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, tokenResponse{
+		Token:     token,
+		Role:      string(claims.Role),
+		SubjectID: claims.Subject,
+		ExpiresAt: claims.Expiry(),
+	})
+}
@@ -0,0 +1,35 @@
+package http
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// UIPath serves the admin UI.
+const UIPath = "/admin/"
+
+// uiFiles is a small browser for schools, teachers and the grading status of
+// their tests. It is compiled into the binary and calls the JSON API with the
+// admin key the operator enters, so it needs no separate deployment.
+//
+//go:embed ui
+var uiFiles embed.FS
+
+// RegisterUI serves the admin UI at UIPath. The pages hold no data and carry
+// no credentials, so they belong outside the authentication middleware.
+func RegisterUI(mux *http.ServeMux) {
+	files, err := fs.Sub(uiFiles, "ui")
+	if err != nil {
+		panic("ui: " + err.Error())
+	}
+	static := http.StripPrefix(UIPath, http.FileServerFS(files))
+	mux.HandleFunc(UIPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Cache-Control", "no-cache")
+		static.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,123 @@
+// Browses the organization API with the admin key kept for this tab only.
+(function () {
+  "use strict";
+
+  const view = document.getElementById("view");
+  const crumbs = document.getElementById("crumbs");
+  const errorBox = document.getElementById("error");
+  const keyInput = document.getElementById("key");
+  let trail = [];
+
+  document.getElementById("key-form").addEventListener("submit", function (e) {
+    e.preventDefault();
+    sessionStorage.setItem("adminKey", keyInput.value);
+    keyInput.value = "";
+    go([{ label: "Schools", show: showSchools }]);
+  });
+
+  async function api(path) {
+    const res = await fetch(path, {
+      headers: { Authorization: "Bearer " + (sessionStorage.getItem("adminKey") || "") },
+    });
+    const body = await res.json().catch(function () { return {}; });
+    if (!res.ok) {
+      throw new Error(body.error || res.status + " " + res.statusText);
+    }
+    return body;
+  }
+
+  function go(next) {
+    trail = next;
+    crumbs.replaceChildren();
+    trail.forEach(function (step, i) {
+      if (i > 0) crumbs.append(" / ");
+      const a = document.createElement("a");
+      a.textContent = step.label;
+      a.addEventListener("click", function () { go(trail.slice(0, i + 1)); });
+      crumbs.append(a);
+    });
+    errorBox.hidden = true;
+    view.replaceChildren();
+    trail[trail.length - 1].show().catch(function (err) {
+      errorBox.textContent = err.message;
+      errorBox.hidden = false;
+    });
+  }
+
+  function drill(label, show) {
+    return function () { go(trail.concat([{ label: label, show: show }])); };
+  }
+
+  // table renders rows and a "More" button while the list has further pages.
+  async function table(path, key, columns, rowClass) {
+    const t = document.createElement("table");
+    const head = t.createTHead().insertRow();
+    columns.forEach(function (c) {
+      const th = document.createElement("th");
+      th.textContent = c.title;
+      head.append(th);
+    });
+    const tbody = t.createTBody();
+    view.append(t);
+
+    let cursor = "";
+    const more = document.createElement("button");
+    more.className = "more";
+    more.textContent = "More";
+    async function load() {
+      const sep = path.includes("?") ? "&" : "?";
+      const page = await api(path + (cursor ? sep + "cursor=" + encodeURIComponent(cursor) : ""));
+      page[key].forEach(function (item) {
+        const tr = tbody.insertRow();
+        if (rowClass) tr.className = rowClass(item);
+        columns.forEach(function (c) {
+          const td = tr.insertCell();
+          const value = c.value(item);
+          if (c.open) {
+            const a = document.createElement("a");
+            a.textContent = value;
+            a.addEventListener("click", c.open(item));
+            td.append(a);
+          } else {
+            td.textContent = value;
+          }
+          if (c.num) td.className = "num";
+        });
+      });
+      cursor = (page.page && page.page.next_cursor) || "";
+      if (cursor) view.append(more); else more.remove();
+    }
+    more.addEventListener("click", function () { load(); });
+    await load();
+  }
+
+  function showSchools() {
+    return table("/api/schools", "schools", [
+      { title: "School", value: function (s) { return s.name; }, open: function (s) { return drill(s.name, function () { return showSchool(s); }); } },
+      { title: "ID", value: function (s) { return s.school_id; } },
+      { title: "District", value: function (s) { return s.district_id || "-"; } },
+    ]);
+  }
+
+  function showSchool(school) {
+    return table("/api/schools/" + encodeURIComponent(school.school_id) + "/teachers", "teachers", [
+      { title: "Teacher", value: function (t) { return t.display_name || t.name; }, open: function (t) { return drill(t.display_name || t.name, function () { return showTeacher(t); }); } },
+      { title: "Email", value: function (t) { return t.email; } },
+    ]);
+  }
+
+  function showTeacher(teacher) {
+    return table("/api/admin/teachers/" + encodeURIComponent(teacher.teacher_id) + "/tests", "tests", [
+      { title: "Test", value: function (t) { return t.title; } },
+      { title: "Published", value: function (t) { return t.published ? "yes" : "draft"; } },
+      { title: "Grading deadline", value: function (t) { return t.grading_deadline ? new Date(t.grading_deadline).toLocaleString() : "-"; } },
+      { title: "Answers", num: true, value: function (t) { return t.answers; } },
+      { title: "Graded", num: true, value: function (t) { return t.graded; } },
+      { title: "Ungraded", num: true, value: function (t) { return t.ungraded; } },
+    ], function (t) { return t.ungraded > 0 ? "pending" : ""; });
+  }
+
+  if (sessionStorage.getItem("adminKey")) {
+    go([{ label: "Schools", show: showSchools }]);
+  }
+})();
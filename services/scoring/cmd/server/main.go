@@ -4,54 +4,244 @@ import (
 	"context"
 	"errors"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/sky0621/go_work_sample/core/pkg/audit"
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
+	"github.com/sky0621/go_work_sample/core/pkg/config"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/health"
 	"github.com/sky0621/go_work_sample/core/pkg/httpmw"
+	"github.com/sky0621/go_work_sample/core/pkg/jobs"
+	"github.com/sky0621/go_work_sample/core/pkg/logfile"
 	"github.com/sky0621/go_work_sample/core/pkg/memory"
-	"github.com/sky0621/go_work_sample/core/pkg/storage/filedb"
+	"github.com/sky0621/go_work_sample/core/pkg/notify"
+	"github.com/sky0621/go_work_sample/core/pkg/openapi"
+	"github.com/sky0621/go_work_sample/core/pkg/ops"
+	"github.com/sky0621/go_work_sample/core/pkg/rpc"
+	"github.com/sky0621/go_work_sample/core/pkg/tracing"
 	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+	"github.com/sky0621/go_work_sample/core/pkg/webhook"
 	scoringhttp "github.com/sky0621/go_work_sample/scoring/internal/http"
 	"github.com/sky0621/go_work_sample/scoring/pkg/grading"
 )
 
 func main() {
-	addr := envOrDefault("SCORING_API_ADDR", ":8091")
+	serverCfg, err := config.LoadServer(config.ServerDefaults{
+		Name:            "scoring",
+		Addr:            ":8091",
+		GRPCAddr:        ":9091",
+		ReadTimeout:     5 * time.Second,
+		WriteTimeout:    10 * time.Second,
+		ShutdownTimeout: 10 * time.Second,
+	}, os.Args[1:])
+	if err != nil {
+		log.Fatalf("invalid server configuration: %v", err)
+	}
+
+	logs, err := logfile.Open(serverCfg.LogFile)
+	if err != nil {
+		log.Fatalf("invalid log configuration: %v", err)
+	}
+	defer logs.Close()
+	logLevel := new(slog.LevelVar)
+	logger := slog.New(slog.NewJSONHandler(logs, &slog.HandlerOptions{Level: logLevel}))
+	slog.SetDefault(logger)
+	logging := httpmw.Logging(httpmw.LoggingConfig{Logger: logger})
+
+	// Runtime settings change on SIGHUP or through the admin reload
+	// endpoint; everything else needs a restart.
+	runtimeCfg, err := config.NewReloader()
+	if err != nil {
+		log.Fatalf("invalid runtime configuration: %v", err)
+	}
+	runtimeCfg.Subscribe(func(c config.Runtime) { logLevel.Set(c.LogLevel) })
+
+	tracingCfg, err := config.LoadTracing("scoring-api")
+	if err != nil {
+		log.Fatalf("invalid tracing configuration: %v", err)
+	}
+	tracer := tracingCfg.Provider()
+	tracing.SetProvider(tracer)
+	traced := httpmw.Tracing()
+	cors := httpmw.CORS(httpmw.CORSConfig{AllowedOrigins: func() []string { return runtimeCfg.Current().AllowedOrigins }})
+	rateLimit := httpmw.RateLimit(httpmw.RateLimitConfig{Rules: func() []httpmw.RateRule { return runtimeCfg.Current().RateLimits.Routes }})
+	deprecationUsage := httpmw.NewDeprecationUsage()
+	deprecated := httpmw.Deprecation(httpmw.DeprecationConfig{Routes: func() []httpmw.DeprecatedRoute { return runtimeCfg.Current().Deprecations }, Usage: deprecationUsage})
+
+	authCfg, err := config.LoadAuth()
+	if err != nil {
+		log.Fatalf("invalid auth configuration: %v", err)
+	}
+	signer := auth.NewSigner(authCfg.Secret)
+	authMiddleware := httpmw.JWT(httpmw.JWTConfig{
+		Signer: signer,
+		Roles:  []domain.Role{domain.RoleTeacher},
+	})
 
-	dataPath := envOrDefault("DATA_STORE_PATH", "./data/state.json")
-	repo, err := filedb.NewRepository(dataPath, memory.SampleSeed())
+	scoringCfg, err := config.LoadScoring()
 	if err != nil {
-		log.Fatalf("failed to initialise repository: %v", err)
+		log.Fatalf("invalid scoring configuration: %v", err)
+	}
+
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	workers := health.NewRegistry()
+	workers.Go(bgCtx, "config-reload", health.WorkerOptions{}, runtimeCfg.WatchSignals)
+	if tracer != nil {
+		workers.Go(bgCtx, "trace-exporter", health.WorkerOptions{}, func(ctx context.Context) {
+			tracer.Run(ctx, tracingCfg.Interval)
+		})
+	}
+
+	webhookCfg, err := config.LoadWebhooks()
+	if err != nil {
+		log.Fatalf("invalid webhook configuration: %v", err)
+	}
+	dispatcher := webhookCfg.Dispatcher()
+	workers.Go(bgCtx, "webhook-dispatcher", health.WorkerOptions{
+		Critical:   true,
+		StaleAfter: 3 * webhookCfg.PollInterval,
+		QueueDepth: func() int { return len(dispatcher.Deliveries(webhook.StatusPending)) },
+	}, func(ctx context.Context) {
+		dispatcher.Run(ctx, webhookCfg.PollInterval)
+	})
+
+	jobQueue := jobs.NewQueue(4, 256)
+	workers.Go(bgCtx, "jobs", health.WorkerOptions{Critical: true, QueueDepth: jobQueue.Depth}, jobQueue.Run)
+
+	opsCfg, err := config.LoadOps()
+	if err != nil {
+		log.Fatalf("invalid ops configuration: %v", err)
+	}
+	runbook := ops.NewRunbook("scoring-api", logger, opsCfg.Settings())
+	runbook.AddQueue("jobs", jobQueue.Unfinished)
+	runbook.SetLogs(logs)
+
+	var gradingSvc *grading.Service
+	var sandbox http.Handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotImplemented)
+		_, _ = w.Write([]byte(`{"error":"sandbox is not available in remote scoring mode"}`))
+	})
+	if scoringCfg.Mode == config.ScoringRemote {
+		// The teacher service owns the data and stores the grades, sending
+		// its own notifications and events; this service keeps no state.
+		rpcCfg, err := config.LoadRPC()
+		if err != nil {
+			log.Fatalf("invalid rpc configuration: %v", err)
+		}
+		gradingSvc = grading.NewService(grading.NewUpstream(rpc.NewClient(scoringCfg.TeacherTarget, rpc.ClientOptions{
+			Token:   rpc.ForwardPrincipal(signer, rpcCfg.TokenTTL),
+			Timeout: rpcCfg.Timeout,
+		})))
+	} else {
+		storageCfg, err := config.LoadStorage()
+		if err != nil {
+			log.Fatalf("invalid storage configuration: %v", err)
+		}
+		repo, _, err := storageCfg.Open(memory.SampleSeed())
+		if err != nil {
+			log.Fatalf("failed to initialise repository: %v", err)
+		}
+		assessment := usecase.NewAssessmentService(repo, repo, repo, repo)
+		assessment.SetSubmissions(repo)
+		assessment.SetSessions(repo)
+		assessment.SetDelegations(repo)
+		assessment.SetAuditLog(audit.NewLog(repo))
+		assessment.SetAutograder(grading.NewEngine())
+		assessment.SetWebhooks(dispatcher)
+		gradingSvc = grading.NewService(assessment)
+		runbook.AddCache("statistics", assessment.FlushStatistics)
+		runbook.AddIndex("storage", repo.Reindex)
+
+		notifyCfg, err := config.LoadNotify()
+		if err != nil {
+			log.Fatalf("invalid notification configuration: %v", err)
+		}
+		notifier := notify.NewService(notifyCfg.Mailer(), repo, repo)
+		assessment.SetNotifier(notifier)
+		workers.Go(bgCtx, "notification-digests", health.WorkerOptions{}, func(ctx context.Context) {
+			notifier.RunDigests(ctx, notifyCfg.DigestHour)
+		})
+
+		brokerCfg, err := config.LoadBroker()
+		if err != nil {
+			log.Fatalf("invalid event broker configuration: %v", err)
+		}
+		eventQueue, err := brokerCfg.Queue()
+		if err != nil {
+			log.Fatalf("invalid event broker configuration: %v", err)
+		}
+		if eventQueue != nil {
+			assessment.SetBroker(eventQueue)
+			workers.Go(bgCtx, "event-publisher", health.WorkerOptions{QueueDepth: eventQueue.Depth}, eventQueue.Run)
+			runbook.AddQueue("events", eventQueue.Depth)
+		}
+
+		// Sandbox requests run against an in-memory copy of the data without
+		// notifications or webhooks. Every principal gets a copy of their
+		// own, taken on their first sandbox request and dropped once idle,
+		// and the sandboxes share a job queue apart from the live one.
+		sandboxJobs := jobs.NewQueue(1, 16)
+		workers.Go(bgCtx, "sandbox-jobs", health.WorkerOptions{QueueDepth: sandboxJobs.Depth}, sandboxJobs.Run)
+		sandbox = httpmw.NewSandboxPool(func(auth.Principal) (http.Handler, error) {
+			sandboxRepo, err := repo.Sandbox()
+			if err != nil {
+				return nil, err
+			}
+			sandboxAssessment := usecase.NewAssessmentService(sandboxRepo, sandboxRepo, sandboxRepo, sandboxRepo)
+			sandboxAssessment.SetSubmissions(sandboxRepo)
+			sandboxAssessment.SetSessions(sandboxRepo)
+			sandboxAssessment.SetDelegations(sandboxRepo)
+			sandboxAssessment.SetAutograder(grading.NewEngine())
+			sandboxMux := http.NewServeMux()
+			scoringhttp.NewHandler(grading.NewService(sandboxAssessment), sandboxJobs).Register(sandboxMux)
+			return sandboxMux, nil
+		}, httpmw.DefaultSandboxIdle)
 	}
-	assessment := usecase.NewAssessmentService(repo, repo, repo, repo)
-	gradingSvc := grading.NewService(assessment)
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/health", func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("ok"))
 	})
-	scoringhttp.NewHandler(gradingSvc).Register(mux)
-
-	teacherKey := envOrDefault("TEACHER_API_KEY", "teacher-secret")
-	authMiddleware := httpmw.APIKey(httpmw.APIKeyConfig{Key: teacherKey, Prefix: "Bearer "})
-
-	server := &http.Server{
-		Addr:              addr,
-		Handler:           logMiddleware(authMiddleware(mux)),
-		ReadTimeout:       5 * time.Second,
-		ReadHeaderTimeout: 5 * time.Second,
-		WriteTimeout:      10 * time.Second,
-		IdleTimeout:       120 * time.Second,
-	}
+	scoringhttp.NewHandler(gradingSvc, jobQueue).Register(mux)
+
+	adminAuth := httpmw.APIKey(httpmw.APIKeyConfig{Key: serverCfg.AdminKey, Prefix: "Bearer "})
+
+	root := http.NewServeMux()
+	root.Handle(health.Path, workers)
+	openapi.Register(root, scoringhttp.OpenAPI())
+	root.Handle(webhook.AdminPrefix, adminAuth(webhook.NewAdminHandler(dispatcher)))
+	root.Handle(config.ReloadPath, adminAuth(runtimeCfg))
+	root.Handle(httpmw.DeprecationsPath, adminAuth(deprecationUsage))
+	root.Handle(ops.AdminPrefix, adminAuth(runbook))
+	root.Handle("/", authMiddleware(deprecated(rateLimit(httpmw.Sandbox(sandbox)(mux)))))
 
-	errCh := make(chan error, 1)
+	server := serverCfg.HTTPServer(traced(logging(cors(root))))
+
+	// Other services call this one over gRPC on a separate HTTP/2 port,
+	// authenticated with the same tokens as the HTTP API.
+	grpcServer := rpc.NewServer()
+	rpc.RegisterGrading(grpcServer, grading.RPCServer(gradingSvc))
+	grpcHTTP := rpc.NewHTTPServer(serverCfg.GRPCAddr, traced(logging(authMiddleware(grpcServer))))
+
+	errCh := make(chan error, 2)
 	go func() {
-		log.Printf("scoring-api listening on %s", addr)
-		if err := server.ListenAndServe(); err != nil {
+		log.Printf("scoring-api listening on %s", serverCfg.Addr)
+		if err := serverCfg.ListenAndServe(server); err != nil {
+			errCh <- err
+		}
+	}()
+	go func() {
+		log.Printf("scoring-api serving gRPC on %s", serverCfg.GRPCAddr)
+		if err := grpcHTTP.ListenAndServe(); err != nil {
 			errCh <- err
 		}
 	}()
@@ -59,9 +249,13 @@ func main() {
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
+	exitCode := 0
 	select {
 	case sig := <-sigCh:
 		log.Printf("scoring-api shutting down: %s", sig)
+	case worker := <-workers.Dead():
+		log.Printf("scoring-api shutting down: critical worker %s died", worker)
+		exitCode = 1
 	case err := <-errCh:
 		if !errors.Is(err, http.ErrServerClosed) {
 			log.Fatalf("scoring-api failed: %v", err)
@@ -69,35 +263,18 @@ func main() {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), serverCfg.ShutdownTimeout)
 	defer cancel()
 	if err := server.Shutdown(ctx); err != nil {
 		log.Printf("scoring-api shutdown error: %v", err)
 	}
-}
-
-func envOrDefault(key, fallback string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
+	if err := grpcHTTP.Shutdown(ctx); err != nil {
+		log.Printf("scoring-api gRPC shutdown error: %v", err)
+	}
+	if err := tracer.Flush(ctx); err != nil {
+		log.Printf("scoring-api trace flush error: %v", err)
+	}
+	if exitCode != 0 {
+		os.Exit(exitCode)
 	}
-	return fallback
-}
-
-func logMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		lw := &loggingResponseWriter{ResponseWriter: w, status: http.StatusOK}
-		next.ServeHTTP(lw, r)
-		log.Printf("%s %s %d %s", r.Method, r.URL.Path, lw.status, time.Since(start))
-	})
-}
-
-type loggingResponseWriter struct {
-	http.ResponseWriter
-	status int
-}
-
-func (w *loggingResponseWriter) WriteHeader(statusCode int) {
-	w.status = statusCode
-	w.ResponseWriter.WriteHeader(statusCode)
 }
@@ -1,12 +1,17 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
 	"github.com/sky0621/go_work_sample/core/pkg/domain"
 	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/jobs"
 	"github.com/sky0621/go_work_sample/core/pkg/usecase"
 	"github.com/sky0621/go_work_sample/scoring/pkg/grading"
 )
@@ -14,11 +19,12 @@ import (
 // Handler exposes grading endpoints.
 type Handler struct {
 	grading *grading.Service
+	jobs    *jobs.Queue
 }
 
 // NewHandler creates a handler instance.
-func NewHandler(grading *grading.Service) *Handler {
-	return &Handler{grading: grading}
+func NewHandler(grading *grading.Service, jobs *jobs.Queue) *Handler {
+	return &Handler{grading: grading, jobs: jobs}
 }
 
 // Register wires endpoints onto mux.
@@ -27,20 +33,65 @@ func (h *Handler) Register(mux *http.ServeMux) {
 }
 
 func (h *Handler) route(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
-		return
-	}
-
 	parts := splitPath(strings.TrimPrefix(r.URL.Path, "/api/teachers/"))
-	if len(parts) != 4 || parts[1] != "tests" || parts[3] != "grade" {
+
+	switch {
+	case len(parts) > 0 && !auth.IsTeacher(r.Context(), domain.TeacherID(parts[0])):
+		writeError(w, http.StatusForbidden, errs.ErrForbiddenTeacher.Error())
+	case len(parts) == 4 && parts[1] == "tests" && parts[3] == "grade":
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		h.grade(w, r, domain.TeacherID(parts[0]), domain.TestID(parts[2]))
+	case len(parts) == 4 && parts[1] == "tests" && parts[3] == "autograde":
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		h.autograde(w, r, domain.TeacherID(parts[0]), domain.TestID(parts[2]))
+	case len(parts) == 3 && parts[1] == "jobs":
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		h.getJob(w, domain.TeacherID(parts[0]), parts[2])
+	default:
 		writeError(w, http.StatusNotFound, "not found")
-		return
 	}
+}
+
+type resultResponse struct {
+	ResultID  string    `json:"result_id"`
+	AnswerID  string    `json:"answer_id"`
+	Score     int       `json:"score"`
+	Feedback  string    `json:"feedback"`
+	Completed bool      `json:"completed"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type autogradeResponse struct {
+	TestID string `json:"test_id"`
+	Graded int    `json:"graded"`
+	Kept   int    `json:"kept"`
+	Manual int    `json:"manual"`
+}
 
-	teacherID := domain.TeacherID(parts[0])
-	testID := domain.TestID(parts[2])
+type jobResponse struct {
+	JobID     string          `json:"job_id"`
+	Kind      string          `json:"kind"`
+	Status    string          `json:"status"`
+	Error     string          `json:"error,omitempty"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
 
+// grade stores a grade synchronously, or with ?async=true accepts it onto the
+// job queue and answers 202 with a job to poll.
+func (h *Handler) grade(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
 	var req struct {
 		QuestionID string `json:"question_id"`
 		StudentID  string `json:"student_id"`
@@ -52,43 +103,148 @@ func (h *Handler) route(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "invalid json payload")
 		return
 	}
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
 
 	payload := usecase.GradeInput{
 		TestID:     testID,
 		QuestionID: domain.QuestionID(strings.TrimSpace(req.QuestionID)),
 		StudentID:  domain.StudentID(strings.TrimSpace(req.StudentID)),
-		Score:      req.Score,
+		Score:      domain.Score(req.Score),
 		Feedback:   strings.TrimSpace(req.Feedback),
 		Completed:  req.Completed,
+
+		ExpectedVersion: version,
 	}
 
-	result, err := h.grading.GradeAnswer(r.Context(), teacherID, payload)
-	if err != nil {
-		switch err {
-		case errs.ErrTeacherNotFound, errs.ErrTestNotFound:
-			writeError(w, http.StatusNotFound, err.Error())
-			return
-		case errs.ErrStudentNotFound, errs.ErrStudentNotAssigned, errs.ErrAnswerNotFound:
-			writeError(w, http.StatusBadRequest, err.Error())
-			return
-		case errs.ErrForbiddenTeacher:
-			writeError(w, http.StatusForbidden, err.Error())
-			return
-		default:
-			writeError(w, http.StatusInternalServerError, err.Error())
+	async, _ := strconv.ParseBool(r.URL.Query().Get("async"))
+	if !async {
+		result, err := h.grading.GradeAnswer(r.Context(), teacherID, payload)
+		if err != nil {
+			handleServiceError(w, err)
 			return
 		}
+		w.Header().Set("ETag", `"`+strconv.Itoa(result.Version)+`"`)
+		writeJSON(w, http.StatusOK, toResultResponse(result))
+		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]any{
-		"result_id":  string(result.ID),
-		"answer_id":  string(result.AnswerID),
-		"score":      result.Score,
-		"feedback":   result.Feedback,
-		"completed":  result.Completed,
-		"created_at": result.CreatedAt,
-		"updated_at": result.UpdatedAt,
+	// Validate access up front so callers get an immediate error rather than
+	// a failed job.
+	if err := h.grading.CheckAccess(r.Context(), teacherID, testID); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	job, err := h.jobs.Enqueue("grade", string(teacherID), func(ctx context.Context) (*jobs.Artifact, error) {
+		result, err := h.grading.GradeAnswer(ctx, teacherID, payload)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(toResultResponse(result))
+		if err != nil {
+			return nil, err
+		}
+		return &jobs.Artifact{Name: "result.json", ContentType: "application/json", Data: data}, nil
 	})
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, toJobResponse(job, nil))
+}
+
+// autograde grades every answer to an objective question of the test that has
+// no result yet and reports how many answers are left for manual grading.
+func (h *Handler) autograde(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	summary, err := h.grading.Autograde(r.Context(), teacherID, testID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, autogradeResponse{
+		TestID: string(testID),
+		Graded: summary.Graded,
+		Kept:   summary.Kept,
+		Manual: summary.Manual,
+	})
+}
+
+func (h *Handler) getJob(w http.ResponseWriter, teacherID domain.TeacherID, jobID string) {
+	job, ok := h.jobs.Get(jobID)
+	if !ok || job.Owner != string(teacherID) {
+		writeError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	artifact, _ := h.jobs.Artifact(jobID)
+	writeJSON(w, http.StatusOK, toJobResponse(job, artifact))
+}
+
+func toResultResponse(result *domain.Result) resultResponse {
+	return resultResponse{
+		ResultID:  string(result.ID),
+		AnswerID:  string(result.AnswerID),
+		Score:     int(result.Score),
+		Feedback:  result.Feedback,
+		Completed: result.Completed,
+		Version:   result.Version,
+		CreatedAt: result.CreatedAt,
+		UpdatedAt: result.UpdatedAt,
+	}
+}
+
+func toJobResponse(job jobs.Job, artifact *jobs.Artifact) jobResponse {
+	resp := jobResponse{
+		JobID:     job.ID,
+		Kind:      job.Kind,
+		Status:    string(job.Status),
+		Error:     job.Error,
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+	}
+	if artifact != nil {
+		resp.Result = artifact.Data
+	}
+	return resp
+}
+
+func handleServiceError(w http.ResponseWriter, err error) {
+	switch err {
+	case errs.ErrTeacherNotFound, errs.ErrTestNotFound:
+		writeError(w, http.StatusNotFound, err.Error())
+	case errs.ErrStudentNotFound, errs.ErrStudentNotAssigned, errs.ErrAnswerNotFound, errs.ErrScoreOutOfRange:
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errs.ErrForbiddenTeacher:
+		writeError(w, http.StatusForbidden, err.Error())
+	case errs.ErrConflict:
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// requireIfMatch reads the result version a grade was made from, sent as
+// If-Match: "0" for an ungraded answer, "*" for whatever the current
+// version is, which yields nil. A missing or malformed header is answered
+// here and ok is false.
+func requireIfMatch(w http.ResponseWriter, r *http.Request) (version *int, ok bool) {
+	raw := strings.TrimSpace(r.Header.Get("If-Match"))
+	if raw == "" {
+		writeError(w, http.StatusPreconditionRequired, "If-Match header with the version being updated is required")
+		return nil, false
+	}
+	if raw == "*" {
+		return nil, true
+	}
+	n, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(raw, "W/"), `"`))
+	if err != nil || n < 0 {
+		writeError(w, http.StatusBadRequest, "If-Match must be a single version such as \"3\"")
+		return nil, false
+	}
+	return &n, true
 }
 
 func splitPath(path string) []string {
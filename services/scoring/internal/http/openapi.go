@@ -0,0 +1,30 @@
+package http
+
+import "github.com/sky0621/go_work_sample/core/pkg/openapi"
+
+// OpenAPI describes the scoring API.
+func OpenAPI() *openapi.Document {
+	const teacher = "/api/teachers/{teacherID}"
+	b := openapi.New(openapi.Info{
+		Title:       "Scoring API",
+		Version:     "1.0.0",
+		Description: "Grades answers, synchronously or through the job queue.",
+	})
+	b.Add("POST", teacher+"/tests/{testID}/grade", openapi.Route{
+		Summary: "Grade an answer; with async=true the grade is queued and a job is returned with 202",
+		Tag:     "grading",
+		Query: []openapi.Parameter{
+			openapi.Query("async", "Queue the grade and answer 202 with a job to poll."),
+			openapi.Header("If-Match", "Version of the result being regraded, \"0\" for an ungraded answer or * for any; a stale version answers 409."),
+		},
+		Request:  openapi.Object{"question_id": "", "student_id": "", "score": 0, "feedback": "", "completed": false},
+		Response: resultResponse{},
+	})
+	b.Add("POST", teacher+"/tests/{testID}/autograde", openapi.Route{
+		Summary:  "Grade every ungraded answer to an objective question",
+		Tag:      "grading",
+		Response: autogradeResponse{},
+	})
+	b.Add("GET", teacher+"/jobs/{jobID}", openapi.Route{Summary: "Get a grading job", Tag: "jobs", Response: jobResponse{}})
+	return b.Document()
+}
@@ -0,0 +1,280 @@
+// Package analytics turns the graded work of a class into the figures
+// teachers use to plan lessons: how each test went, which questions were
+// hardest and which students need attention.
+package analytics
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+const (
+	// DistributionBuckets splits percentage scores into bands of ten
+	// points; a full score falls into the last band.
+	DistributionBuckets = 10
+	// HardestQuestions is how many questions a report ranks as hardest.
+	HardestQuestions = 5
+	// AttentionPercent is the average percentage below which a student
+	// needs attention.
+	AttentionPercent = 50
+)
+
+// Reasons a student needs attention.
+const (
+	ReasonLowAverage = "low_average"
+	ReasonFailed     = "failed"
+	ReasonMissing    = "missing"
+)
+
+// Source provides the graded work of a class: the assessment use cases, or
+// anything checking access the same way.
+type Source interface {
+	ClassRecord(ctx context.Context, teacherID domain.TeacherID, classID domain.ClassID) (*usecase.ClassRecord, error)
+}
+
+var _ Source = (*usecase.AssessmentService)(nil)
+
+// Service computes class analytics.
+type Service struct {
+	source Source
+}
+
+// NewService creates an analytics service reading from source.
+func NewService(source Source) *Service {
+	return &Service{source: source}
+}
+
+// ClassReport summarises a class's work on the teacher's published tests.
+type ClassReport struct {
+	ClassID    domain.ClassID
+	ClassName  string
+	Students   int
+	Tests      []TestSummary
+	Hardest    []QuestionSummary
+	Attention  []StudentAttention
+	ComputedAt time.Time
+}
+
+// TestSummary is how the class did on one test. Scores are percentages of
+// the test's points and only count students whose answers are all graded;
+// Mean is zero and Distribution empty while there are none.
+type TestSummary struct {
+	TestID       domain.TestID
+	Title        string
+	Assigned     int
+	Graded       int
+	MeanScore    float64
+	Mean         float64
+	Distribution []int
+}
+
+// QuestionSummary is how the class did on one question, over its graded
+// answers. Share is MeanPoints as a fraction of the question's points.
+type QuestionSummary struct {
+	TestID     domain.TestID
+	QuestionID domain.QuestionID
+	Prompt     string
+	Points     domain.Points
+	Answers    int
+	MeanPoints float64
+	Share      float64
+}
+
+// StudentAttention names a student who needs attention and why. Mean is the
+// student's average percentage over fully graded tests, nil without any.
+type StudentAttention struct {
+	StudentID domain.StudentID
+	Name      string
+	Mean      *float64
+	Reasons   []string
+}
+
+// studentTotals accumulates one student's work across the class's tests.
+type studentTotals struct {
+	percents []float64
+	failed   bool
+	missing  bool
+}
+
+// Class returns the analytics of a class of the teacher's school.
+// Questions rank as hardest by the share of their points the class earned
+// on average, so questions of different worth compare fairly.
+func (s *Service) Class(ctx context.Context, teacherID domain.TeacherID, classID domain.ClassID) (*ClassReport, error) {
+	record, err := s.source.ClassRecord(ctx, teacherID, classID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ClassReport{
+		ClassID:    record.Class.ID,
+		ClassName:  record.Class.Name,
+		Students:   len(record.Students),
+		Tests:      make([]TestSummary, 0, len(record.Tests)),
+		ComputedAt: record.ReadAt,
+	}
+	totals := make(map[domain.StudentID]*studentTotals, len(record.Students))
+	for _, st := range record.Students {
+		totals[st.ID] = &studentTotals{}
+	}
+	var questions []QuestionSummary
+	for _, t := range record.Tests {
+		summary, perQuestion := summarizeTest(t, totals, record.ReadAt)
+		report.Tests = append(report.Tests, summary)
+		questions = append(questions, perQuestion...)
+	}
+
+	sort.SliceStable(questions, func(i, j int) bool {
+		return questions[i].Share < questions[j].Share
+	})
+	if len(questions) > HardestQuestions {
+		questions = questions[:HardestQuestions]
+	}
+	report.Hardest = questions
+
+	report.Attention = make([]StudentAttention, 0)
+	for _, st := range record.Students {
+		if a, ok := attention(st, totals[st.ID]); ok {
+			report.Attention = append(report.Attention, a)
+		}
+	}
+	return report, nil
+}
+
+// summarizeTest summarises one test, adding each student's outcome to
+// totals, and returns the summaries of its questions with graded answers.
+func summarizeTest(t usecase.ClassTestRecord, totals map[domain.StudentID]*studentTotals, now time.Time) (TestSummary, []QuestionSummary) {
+	scores := make(map[domain.AnswerID]domain.Score, len(t.Results))
+	for _, res := range t.Results {
+		scores[res.AnswerID] = res.Score
+	}
+	maxScore := domain.TotalPoints(t.Questions)
+
+	type outcome struct {
+		score            domain.Score
+		answered, graded int
+	}
+	outcomes := make(map[domain.StudentID]*outcome, len(t.Assigned))
+	for _, sid := range t.Assigned {
+		outcomes[sid] = &outcome{}
+	}
+	type questionTotal struct {
+		sum     domain.Score
+		answers int
+	}
+	byQuestion := make(map[domain.QuestionID]*questionTotal, len(t.Questions))
+	for _, ans := range t.Answers {
+		o, ok := outcomes[ans.StudentID]
+		if !ok {
+			continue
+		}
+		o.answered++
+		score, graded := scores[ans.ID]
+		if !graded {
+			continue
+		}
+		o.graded++
+		o.score += score
+		q := byQuestion[ans.QuestionID]
+		if q == nil {
+			q = &questionTotal{}
+			byQuestion[ans.QuestionID] = q
+		}
+		q.sum += score
+		q.answers++
+	}
+
+	summary := TestSummary{
+		TestID:       t.Test.ID,
+		Title:        t.Test.Title,
+		Assigned:     len(t.Assigned),
+		Distribution: make([]int, DistributionBuckets),
+	}
+	closed := t.Test.ClosesAt != nil && !now.Before(*t.Test.ClosesAt)
+	var sumScore domain.Score
+	var sumPercent float64
+	for _, sid := range t.Assigned {
+		o, student := outcomes[sid], totals[sid]
+		if o.answered == 0 {
+			if closed && student != nil {
+				student.missing = true
+			}
+			continue
+		}
+		if o.graded < o.answered {
+			continue
+		}
+		percent := o.score.Percent(maxScore)
+		summary.Graded++
+		sumScore += o.score
+		sumPercent += percent
+		summary.Distribution[bucket(percent)]++
+		if student != nil {
+			student.percents = append(student.percents, percent)
+			if t.Test.PassingScore != nil && o.score < *t.Test.PassingScore {
+				student.failed = true
+			}
+		}
+	}
+	if summary.Graded > 0 {
+		summary.MeanScore = float64(sumScore) / float64(summary.Graded)
+		summary.Mean = sumPercent / float64(summary.Graded)
+	}
+
+	var questions []QuestionSummary
+	for _, q := range t.Questions {
+		total := byQuestion[q.ID]
+		if total == nil || q.Points <= 0 {
+			continue
+		}
+		mean := float64(total.sum) / float64(total.answers)
+		questions = append(questions, QuestionSummary{
+			TestID:     t.Test.ID,
+			QuestionID: q.ID,
+			Prompt:     q.Prompt,
+			Points:     q.Points,
+			Answers:    total.answers,
+			MeanPoints: mean,
+			Share:      mean / float64(q.Points),
+		})
+	}
+	return summary, questions
+}
+
+// attention reports whether the student needs attention.
+func attention(st domain.Student, totals *studentTotals) (StudentAttention, bool) {
+	a := StudentAttention{StudentID: st.ID, Name: st.Name}
+	if len(totals.percents) > 0 {
+		var sum float64
+		for _, p := range totals.percents {
+			sum += p
+		}
+		mean := sum / float64(len(totals.percents))
+		a.Mean = &mean
+		if mean < AttentionPercent {
+			a.Reasons = append(a.Reasons, ReasonLowAverage)
+		}
+	}
+	if totals.failed {
+		a.Reasons = append(a.Reasons, ReasonFailed)
+	}
+	if totals.missing {
+		a.Reasons = append(a.Reasons, ReasonMissing)
+	}
+	return a, len(a.Reasons) > 0
+}
+
+// bucket returns the distribution band of a percentage score.
+func bucket(percent float64) int {
+	b := int(percent) / (100 / DistributionBuckets)
+	if b >= DistributionBuckets {
+		return DistributionBuckets - 1
+	}
+	if b < 0 {
+		return 0
+	}
+	return b
+}
@@ -0,0 +1,68 @@
+package analytics_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+	"github.com/sky0621/go_work_sample/scoring/pkg/analytics"
+)
+
+func TestServiceClass(t *testing.T) {
+	fx := fixtures.NewSchool().WithStudents(3).Build()
+	assessments := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+
+	passing := domain.Score(10)
+	test, questions, err := assessments.CreateTest(ctx, usecase.CreateTestInput{
+		Title:        "Fractions",
+		TeacherID:    fx.Teacher(0),
+		Questions:    []usecase.QuestionDraft{{Prompt: "Q1", Points: 10}, {Prompt: "Q2", Points: 10}},
+		ClassIDs:     []domain.ClassID{fx.Classes[0].ID},
+		PassingScore: &passing,
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	grade := func(student int, scores ...domain.Score) {
+		t.Helper()
+		for i, score := range scores {
+			if _, err := assessments.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[i].ID, StudentID: fx.Student(student), Response: "x"}); err != nil {
+				t.Fatalf("SubmitAnswer failed: %v", err)
+			}
+			if _, err := assessments.GradeAnswer(ctx, usecase.GradeInput{TeacherID: fx.Teacher(0), TestID: test.ID, QuestionID: questions[i].ID, StudentID: fx.Student(student), Score: score, Completed: true}); err != nil {
+				t.Fatalf("GradeAnswer failed: %v", err)
+			}
+		}
+	}
+	grade(0, 10, 8)
+	grade(1, 2, 0)
+
+	service := analytics.NewService(assessments)
+	report, err := service.Class(ctx, fx.Teacher(0), fx.Classes[0].ID)
+	if err != nil {
+		t.Fatalf("Class failed: %v", err)
+	}
+	if report.Students != 3 || len(report.Tests) != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	summary := report.Tests[0]
+	if summary.Assigned != 3 || summary.Graded != 2 || summary.Mean != 50 || summary.MeanScore != 10 ||
+		summary.Distribution[9] != 1 || summary.Distribution[1] != 1 {
+		t.Fatalf("unexpected test summary: %+v", summary)
+	}
+	if len(report.Hardest) != 2 || report.Hardest[0].QuestionID != questions[1].ID || report.Hardest[0].MeanPoints != 4 {
+		t.Fatalf("expected Q2 to rank hardest, got %+v", report.Hardest)
+	}
+	if len(report.Attention) != 1 || report.Attention[0].StudentID != fx.Student(1) ||
+		len(report.Attention[0].Reasons) != 2 || report.Attention[0].Reasons[1] != analytics.ReasonFailed {
+		t.Fatalf("expected the failing student to need attention, got %+v", report.Attention)
+	}
+
+	if _, err := service.Class(ctx, fx.Teacher(0), "missing"); err != errs.ErrClassNotFound {
+		t.Fatalf("expected ErrClassNotFound, got %v", err)
+	}
+}
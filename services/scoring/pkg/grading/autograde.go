@@ -0,0 +1,48 @@
+package grading
+
+import (
+	"context"
+	"strings"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+// Engine grades objective questions: multiple-choice and true/false answers
+// must equal the expected response, and free-text answers must match it
+// exactly apart from case and surrounding space. A correct answer earns the
+// question's full points and any other answer none.
+type Engine struct{}
+
+var _ usecase.Autograder = Engine{}
+
+// NewEngine creates an autograding engine.
+func NewEngine() Engine {
+	return Engine{}
+}
+
+// Grade scores response to the question, reporting false when the question
+// has no expected response and needs a teacher.
+func (Engine) Grade(question domain.Question, response string) (domain.Score, bool) {
+	expected := question.ExpectedResponse
+	if expected == "" {
+		return 0, false
+	}
+
+	var correct bool
+	switch question.AnswerType() {
+	case domain.QuestionMultipleChoice, domain.QuestionTrueFalse:
+		correct = response == expected
+	default:
+		correct = strings.EqualFold(strings.TrimSpace(response), strings.TrimSpace(expected))
+	}
+	if !correct {
+		return 0, true
+	}
+	return domain.Score(question.Points), true
+}
+
+// Autograde grades the answers of the test that have no result yet.
+func (s *Service) Autograde(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID) (*usecase.AutogradeSummary, error) {
+	return s.assessments.AutogradeTest(ctx, teacherID, testID)
+}
@@ -0,0 +1,31 @@
+package grading_test
+
+import (
+	"testing"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/scoring/pkg/grading"
+)
+
+func TestEngineGrade(t *testing.T) {
+	choices := []domain.Choice{{Key: "a", Label: "Milan"}, {Key: "b", Label: "Rome"}}
+	cases := []struct {
+		name      string
+		question  domain.Question
+		response  string
+		wantScore domain.Score
+		wantOK    bool
+	}{
+		{"correct choice", domain.Question{Points: 2, Type: domain.QuestionMultipleChoice, Choices: choices, ExpectedResponse: "b"}, "b", 2, true},
+		{"wrong choice", domain.Question{Points: 2, Type: domain.QuestionMultipleChoice, Choices: choices, ExpectedResponse: "b"}, "a", 0, true},
+		{"true/false", domain.Question{Points: 1, Type: domain.QuestionTrueFalse, ExpectedResponse: "false"}, "false", 1, true},
+		{"exact match ignores case", domain.Question{Points: 3, Type: domain.QuestionFreeText, ExpectedResponse: "Paris"}, "  paris ", 3, true},
+		{"free text without expectation", domain.Question{Points: 3, Type: domain.QuestionFreeText}, "anything", 0, false},
+	}
+	for _, tc := range cases {
+		score, ok := grading.NewEngine().Grade(tc.question, tc.response)
+		if score != tc.wantScore || ok != tc.wantOK {
+			t.Fatalf("%s: got (%d, %v), want (%d, %v)", tc.name, score, ok, tc.wantScore, tc.wantOK)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package grading
+
+import (
+	"context"
+
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/rpc"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+// Grader grades answers, either in process through Service or over the
+// network through Remote.
+type Grader interface {
+	GradeAnswer(ctx context.Context, teacherID domain.TeacherID, payload usecase.GradeInput) (*domain.Result, error)
+}
+
+// RPCServer serves the Grading gRPC service from s. The caller must be
+// authenticated as the teacher named in each request.
+func RPCServer(s *Service) rpc.GradingServer {
+	return rpcServer{service: s}
+}
+
+type rpcServer struct {
+	service *Service
+}
+
+func (s rpcServer) GradeAnswer(ctx context.Context, req *rpc.GradeAnswerRequest) (*rpc.Result, error) {
+	input := req.Input()
+	if !auth.IsTeacher(ctx, input.TeacherID) {
+		return nil, rpc.Errorf(rpc.PermissionDenied, "caller is not teacher %s", req.TeacherID)
+	}
+	result, err := s.service.GradeAnswer(ctx, input.TeacherID, input)
+	if err != nil {
+		return nil, err
+	}
+	return rpc.NewResult(result), nil
+}
+
+func (s rpcServer) AutogradeTest(ctx context.Context, req *rpc.AutogradeTestRequest) (*rpc.AutogradeSummary, error) {
+	teacherID := domain.TeacherID(req.TeacherID)
+	if !auth.IsTeacher(ctx, teacherID) {
+		return nil, rpc.Errorf(rpc.PermissionDenied, "caller is not teacher %s", req.TeacherID)
+	}
+	summary, err := s.service.Autograde(ctx, teacherID, domain.TestID(req.TestID))
+	if err != nil {
+		return nil, err
+	}
+	return &rpc.AutogradeSummary{
+		Graded: int64(summary.Graded),
+		Kept:   int64(summary.Kept),
+		Manual: int64(summary.Manual),
+	}, nil
+}
+
+// Remote grades answers through the scoring service's gRPC API, for
+// deployments that run scoring separately.
+type Remote struct {
+	client *rpc.GradingClient
+}
+
+// NewRemote returns a grader calling the Grading service over conn.
+func NewRemote(conn *rpc.Client) *Remote {
+	return &Remote{client: rpc.NewGradingClient(conn)}
+}
+
+// GradeAnswer grades remotely. Errors reported by the scoring service come
+// back as the errs sentinels Service would have returned.
+func (r *Remote) GradeAnswer(ctx context.Context, teacherID domain.TeacherID, payload usecase.GradeInput) (*domain.Result, error) {
+	payload.TeacherID = teacherID
+	result, err := r.client.GradeAnswer(ctx, rpc.NewGradeAnswerRequest(payload))
+	if err != nil {
+		return nil, rpc.ServiceError(err)
+	}
+	return result.Domain(), nil
+}
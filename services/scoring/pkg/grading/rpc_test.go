@@ -0,0 +1,73 @@
+package grading_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/fixtures"
+	"github.com/sky0621/go_work_sample/core/pkg/httpmw"
+	"github.com/sky0621/go_work_sample/core/pkg/rpc"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+	"github.com/sky0621/go_work_sample/scoring/pkg/grading"
+)
+
+func TestRemote_GradesThroughScoringService(t *testing.T) {
+	fx := fixtures.NewSchool().WithTeachers(2).WithStudents(1).Build()
+	assessments := usecase.NewAssessmentService(fx.Repo, fx.Repo, fx.Repo, fx.Repo)
+	ctx := context.Background()
+	test, questions, err := assessments.CreateTest(ctx, usecase.CreateTestInput{
+		Title:      "Quiz",
+		TeacherID:  fx.Teacher(0),
+		Questions:  []usecase.QuestionDraft{{Prompt: "2 + 2", Points: 2}},
+		StudentIDs: []domain.StudentID{fx.Student(0)},
+	})
+	if err != nil {
+		t.Fatalf("CreateTest failed: %v", err)
+	}
+	answer, err := assessments.SubmitAnswer(ctx, &domain.Answer{TestID: test.ID, QuestionID: questions[0].ID, StudentID: fx.Student(0), Response: "4"})
+	if err != nil {
+		t.Fatalf("SubmitAnswer failed: %v", err)
+	}
+
+	signer := auth.NewSigner("secret")
+	server := rpc.NewServer()
+	rpc.RegisterGrading(server, grading.RPCServer(grading.NewService(assessments)))
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	httpServer := rpc.NewHTTPServer("", httpmw.JWT(httpmw.JWTConfig{Signer: signer, Roles: []domain.Role{domain.RoleTeacher}})(server))
+	go func() { _ = httpServer.Serve(ln) }()
+	t.Cleanup(func() { _ = httpServer.Close() })
+
+	remote := grading.NewRemote(rpc.NewClient(ln.Addr().String(), rpc.ClientOptions{
+		Token:   rpc.ForwardPrincipal(signer, time.Minute),
+		Timeout: 5 * time.Second,
+	}))
+	payload := usecase.GradeInput{TestID: test.ID, QuestionID: questions[0].ID, StudentID: fx.Student(0), Score: 2, Feedback: "well done", Completed: true}
+
+	owner := auth.WithPrincipal(ctx, auth.Principal{Role: domain.RoleTeacher, ID: string(fx.Teacher(0))})
+	result, err := remote.GradeAnswer(owner, fx.Teacher(0), payload)
+	if err != nil {
+		t.Fatalf("GradeAnswer failed: %v", err)
+	}
+	if result.AnswerID != answer.ID || result.Score != 2 || result.Feedback != "well done" || !result.Completed {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if stored, _ := fx.Repo.GetResult(ctx, answer.ID); stored == nil || stored.Score != 2 {
+		t.Fatalf("expected the result stored by the scoring side, got %+v", stored)
+	}
+
+	other := auth.WithPrincipal(ctx, auth.Principal{Role: domain.RoleTeacher, ID: string(fx.Teacher(1))})
+	if _, err := remote.GradeAnswer(other, fx.Teacher(1), payload); err != errs.ErrForbiddenTeacher {
+		t.Fatalf("expected ErrForbiddenTeacher, got %v", err)
+	}
+	if _, err := remote.GradeAnswer(other, fx.Teacher(0), payload); rpc.CodeOf(err) != rpc.PermissionDenied {
+		t.Fatalf("expected grading on behalf of another teacher to be refused, got %v", err)
+	}
+}
@@ -7,13 +7,24 @@ import (
 	"github.com/sky0621/go_work_sample/core/pkg/usecase"
 )
 
+// Backend checks access to tests and stores grades: the assessment use cases
+// over a store shared with the other services, or the teacher service
+// through Upstream.
+type Backend interface {
+	GradeAnswer(ctx context.Context, input usecase.GradeInput) (*domain.Result, error)
+	AutogradeTest(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID) (*usecase.AutogradeSummary, error)
+	GetTestForTeacher(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID) (*domain.Test, error)
+}
+
+var _ Backend = (*usecase.AssessmentService)(nil)
+
 // Service wraps assessment use cases to expose grading specific APIs.
 type Service struct {
-	assessments *usecase.AssessmentService
+	assessments Backend
 }
 
 // NewService creates a grading service instance.
-func NewService(assessments *usecase.AssessmentService) *Service {
+func NewService(assessments Backend) *Service {
 	return &Service{assessments: assessments}
 }
 
@@ -22,3 +33,9 @@ func (s *Service) GradeAnswer(ctx context.Context, teacherID domain.TeacherID, p
 	payload.TeacherID = teacherID
 	return s.assessments.GradeAnswer(ctx, payload)
 }
+
+// CheckAccess reports whether the teacher may grade answers of the test.
+func (s *Service) CheckAccess(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID) error {
+	_, err := s.assessments.GetTestForTeacher(ctx, teacherID, testID)
+	return err
+}
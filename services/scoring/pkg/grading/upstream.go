@@ -0,0 +1,60 @@
+package grading
+
+import (
+	"context"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/rpc"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+// Upstream is the Backend of a scoring service that keeps no state: it
+// checks access and stores grades through the teacher service's gRPC API,
+// which owns the data. Calls carry the principal of the incoming request, so
+// the teacher service checks access against the same teacher.
+type Upstream struct {
+	grading     *rpc.GradingClient
+	assessments *rpc.AssessmentClient
+}
+
+var _ Backend = (*Upstream)(nil)
+
+// NewUpstream returns a backend calling the teacher service over conn.
+func NewUpstream(conn *rpc.Client) *Upstream {
+	return &Upstream{
+		grading:     rpc.NewGradingClient(conn),
+		assessments: rpc.NewAssessmentClient(conn),
+	}
+}
+
+// GradeAnswer stores the grade in the teacher service.
+func (u *Upstream) GradeAnswer(ctx context.Context, input usecase.GradeInput) (*domain.Result, error) {
+	result, err := u.grading.GradeAnswer(ctx, rpc.NewGradeAnswerRequest(input))
+	if err != nil {
+		return nil, rpc.ServiceError(err)
+	}
+	return result.Domain(), nil
+}
+
+// AutogradeTest autogrades the test in the teacher service.
+func (u *Upstream) AutogradeTest(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID) (*usecase.AutogradeSummary, error) {
+	summary, err := u.grading.AutogradeTest(ctx, &rpc.AutogradeTestRequest{TeacherID: string(teacherID), TestID: string(testID)})
+	if err != nil {
+		return nil, rpc.ServiceError(err)
+	}
+	return &usecase.AutogradeSummary{
+		Graded: int(summary.Graded),
+		Kept:   int(summary.Kept),
+		Manual: int(summary.Manual),
+	}, nil
+}
+
+// GetTestForTeacher reads a test the teacher owns from the teacher service.
+func (u *Upstream) GetTestForTeacher(ctx context.Context, teacherID domain.TeacherID, testID domain.TestID) (*domain.Test, error) {
+	resp, err := u.assessments.GetTest(ctx, &rpc.GetTestRequest{TeacherID: string(teacherID), TestID: string(testID)})
+	if err != nil {
+		return nil, rpc.ServiceError(err)
+	}
+	test, _ := resp.Domain()
+	return test, nil
+}
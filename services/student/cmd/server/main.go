@@ -4,52 +4,249 @@ import (
 	"context"
 	"errors"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/sky0621/go_work_sample/core/pkg/audit"
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
+	"github.com/sky0621/go_work_sample/core/pkg/certificate"
+	"github.com/sky0621/go_work_sample/core/pkg/config"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/events"
+	"github.com/sky0621/go_work_sample/core/pkg/health"
 	"github.com/sky0621/go_work_sample/core/pkg/httpmw"
+	"github.com/sky0621/go_work_sample/core/pkg/kiosk"
+	"github.com/sky0621/go_work_sample/core/pkg/logfile"
+	"github.com/sky0621/go_work_sample/core/pkg/magiclink"
 	"github.com/sky0621/go_work_sample/core/pkg/memory"
-	"github.com/sky0621/go_work_sample/core/pkg/storage/filedb"
+	"github.com/sky0621/go_work_sample/core/pkg/openapi"
+	"github.com/sky0621/go_work_sample/core/pkg/ops"
+	"github.com/sky0621/go_work_sample/core/pkg/ratelimit"
+	"github.com/sky0621/go_work_sample/core/pkg/tracing"
 	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+	"github.com/sky0621/go_work_sample/core/pkg/webhook"
+	"github.com/sky0621/go_work_sample/scoring/pkg/grading"
 	studenthttp "github.com/sky0621/go_work_sample/student/internal/http"
 )
 
 func main() {
-	addr := envOrDefault("STUDENT_API_ADDR", ":8081")
+	serverCfg, err := config.LoadServer(config.ServerDefaults{
+		Name:            "student",
+		Addr:            ":8081",
+		ReadTimeout:     3 * time.Second,
+		WriteTimeout:    6 * time.Second,
+		ShutdownTimeout: 5 * time.Second,
+	}, os.Args[1:])
+	if err != nil {
+		log.Fatalf("invalid server configuration: %v", err)
+	}
+
+	logs, err := logfile.Open(serverCfg.LogFile)
+	if err != nil {
+		log.Fatalf("invalid log configuration: %v", err)
+	}
+	defer logs.Close()
+	logLevel := new(slog.LevelVar)
+	logger := slog.New(slog.NewJSONHandler(logs, &slog.HandlerOptions{Level: logLevel}))
+	slog.SetDefault(logger)
+	logging := httpmw.Logging(httpmw.LoggingConfig{Logger: logger})
+
+	// Runtime settings change on SIGHUP or through the admin reload
+	// endpoint; everything else needs a restart.
+	runtimeCfg, err := config.NewReloader()
+	if err != nil {
+		log.Fatalf("invalid runtime configuration: %v", err)
+	}
+	runtimeCfg.Subscribe(func(c config.Runtime) { logLevel.Set(c.LogLevel) })
+
+	tracingCfg, err := config.LoadTracing("student-api")
+	if err != nil {
+		log.Fatalf("invalid tracing configuration: %v", err)
+	}
+	tracer := tracingCfg.Provider()
+	tracing.SetProvider(tracer)
+	traced := httpmw.Tracing()
+	cors := httpmw.CORS(httpmw.CORSConfig{AllowedOrigins: func() []string { return runtimeCfg.Current().AllowedOrigins }})
+	// Requests are metered after sign-in, so each student spends their own
+	// budget of answer submissions.
+	rateLimit := httpmw.RateLimit(httpmw.RateLimitConfig{Rules: func() []httpmw.RateRule { return runtimeCfg.Current().RateLimits.Routes }})
+	deprecationUsage := httpmw.NewDeprecationUsage()
+	deprecated := httpmw.Deprecation(httpmw.DeprecationConfig{Routes: func() []httpmw.DeprecatedRoute { return runtimeCfg.Current().Deprecations }, Usage: deprecationUsage})
 
-	dataPath := envOrDefault("DATA_STORE_PATH", "./data/state.json")
-	repo, err := filedb.NewRepository(dataPath, memory.SampleSeed())
+	storageCfg, err := config.LoadStorage()
+	if err != nil {
+		log.Fatalf("invalid storage configuration: %v", err)
+	}
+	repo, _, err := storageCfg.Open(memory.SampleSeed())
 	if err != nil {
 		log.Fatalf("failed to initialise repository: %v", err)
 	}
 	assessment := usecase.NewAssessmentService(repo, repo, repo, repo)
+	assessment.SetSubmissions(repo)
+	assessment.SetSessions(repo)
+	assessment.SetAnswerComments(repo)
+	assessment.SetAuditLog(audit.NewLog(repo))
+	assessment.SetAutograder(grading.NewEngine())
+	certificates := certificate.NewSigner(config.LoadCertificates().Secret)
+	assessment.SetCertificateSigner(certificates)
+	bus := events.NewBus()
+	assessment.SetEvents(bus)
+	profiles := usecase.NewProfileService(repo)
+	inbox := usecase.NewInboxService(repo)
+	sessions := usecase.NewSessionService(repo, repo)
+	devices := usecase.NewDeviceService(repo, repo)
+
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	webhookCfg, err := config.LoadWebhooks()
+	if err != nil {
+		log.Fatalf("invalid webhook configuration: %v", err)
+	}
+	dispatcher := webhookCfg.Dispatcher()
+	assessment.SetWebhooks(dispatcher)
+	assessment.SetQuotas(ratelimit.NewLimiter())
+	workers := health.NewRegistry()
+	workers.Go(bgCtx, "config-reload", health.WorkerOptions{}, runtimeCfg.WatchSignals)
+	if tracer != nil {
+		workers.Go(bgCtx, "trace-exporter", health.WorkerOptions{}, func(ctx context.Context) {
+			tracer.Run(ctx, tracingCfg.Interval)
+		})
+	}
+	workers.Go(bgCtx, "webhook-dispatcher", health.WorkerOptions{
+		Critical:   true,
+		StaleAfter: 3 * webhookCfg.PollInterval,
+		QueueDepth: func() int { return len(dispatcher.Deliveries(webhook.StatusPending)) },
+	}, func(ctx context.Context) {
+		dispatcher.Run(ctx, webhookCfg.PollInterval)
+	})
+
+	brokerCfg, err := config.LoadBroker()
+	if err != nil {
+		log.Fatalf("invalid event broker configuration: %v", err)
+	}
+	eventQueue, err := brokerCfg.Queue()
+	if err != nil {
+		log.Fatalf("invalid event broker configuration: %v", err)
+	}
+	if eventQueue != nil {
+		assessment.SetBroker(eventQueue)
+		workers.Go(bgCtx, "event-publisher", health.WorkerOptions{QueueDepth: eventQueue.Depth}, eventQueue.Run)
+	}
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/health", func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("ok"))
 	})
-	studenthttp.NewHandler(assessment).Register(mux)
+	studenthttp.NewHandler(assessment, profiles, inbox, sessions, devices, bus).Register(mux)
+
+	// Sandbox requests run against an in-memory copy of the data without
+	// notifications or webhooks. Every student gets a copy of their own,
+	// taken on their first sandbox request and dropped once idle.
+	sandboxes := httpmw.NewSandboxPool(func(auth.Principal) (http.Handler, error) {
+		sandboxRepo, err := repo.Sandbox()
+		if err != nil {
+			return nil, err
+		}
+		sandboxAssessment := usecase.NewAssessmentService(sandboxRepo, sandboxRepo, sandboxRepo, sandboxRepo)
+		sandboxAssessment.SetSubmissions(sandboxRepo)
+		sandboxAssessment.SetSessions(sandboxRepo)
+		sandboxAssessment.SetAnswerComments(sandboxRepo)
+		sandboxAssessment.SetAutograder(grading.NewEngine())
+		sandboxBus := events.NewBus()
+		sandboxAssessment.SetEvents(sandboxBus)
+		sandboxMux := http.NewServeMux()
+		studenthttp.NewHandler(
+			sandboxAssessment,
+			usecase.NewProfileService(sandboxRepo),
+			usecase.NewInboxService(sandboxRepo),
+			usecase.NewSessionService(sandboxRepo, sandboxRepo),
+			usecase.NewDeviceService(sandboxRepo, sandboxRepo),
+			sandboxBus,
+		).Register(sandboxMux)
+		return sandboxMux, nil
+	}, httpmw.DefaultSandboxIdle)
+
+	kioskCfg, err := config.LoadKiosk()
+	if err != nil {
+		log.Fatalf("invalid kiosk configuration: %v", err)
+	}
+
+	authCfg, err := config.LoadAuth()
+	if err != nil {
+		log.Fatalf("invalid auth configuration: %v", err)
+	}
+	signer := auth.NewSigner(authCfg.Secret)
+	authMiddleware := httpmw.Kiosk(httpmw.KioskConfig{
+		Signer: kiosk.NewSigner(kioskCfg.Secret),
+		Fallback: httpmw.JWT(httpmw.JWTConfig{
+			Signer:  signer,
+			Roles:   []domain.Role{domain.RoleStudent, domain.RoleGuardian},
+			Revoked: devices.Revoked,
+		}),
+	})
 
-	studentKey := envOrDefault("STUDENT_API_KEY", "student-secret")
-	authMiddleware := httpmw.APIKey(httpmw.APIKeyConfig{Key: studentKey, Prefix: "Bearer "})
+	notifyCfg, err := config.LoadNotify()
+	if err != nil {
+		log.Fatalf("invalid notification configuration: %v", err)
+	}
+	linkCfg, err := config.LoadMagicLink()
+	if err != nil {
+		log.Fatalf("invalid magic link configuration: %v", err)
+	}
+	magicLinks := usecase.NewMagicLinkService(repo, magiclink.NewSigner(linkCfg.Secret), signer, notifyCfg.Mailer(), usecase.MagicLinkSettings{
+		BaseURL:    linkCfg.BaseURL,
+		LinkTTL:    linkCfg.LinkTTL,
+		SessionTTL: linkCfg.SessionTTL,
+	})
+	magicLinks.SetDevices(devices)
+	workers.Go(bgCtx, "device-session-expiry", health.WorkerOptions{StaleAfter: 3 * linkCfg.PruneInterval}, func(ctx context.Context) {
+		devices.RunExpiry(ctx, linkCfg.PruneInterval)
+	})
+	runtimeCfg.Subscribe(func(c config.Runtime) {
+		magicLinks.SetRateLimits(c.RateLimits.MagicLinkPerEmail, c.RateLimits.MagicLinkPerClient)
+	})
+	publicMux := http.NewServeMux()
+	studenthttp.NewMagicLinkHandler(magicLinks).Register(publicMux)
+	studenthttp.NewCertificateHandler(certificates).Register(publicMux)
+	adminAuth := httpmw.APIKey(httpmw.APIKeyConfig{Key: serverCfg.AdminKey, Prefix: "Bearer "})
+	opsCfg, err := config.LoadOps()
+	if err != nil {
+		log.Fatalf("invalid ops configuration: %v", err)
+	}
+	runbook := ops.NewRunbook("student-api", logger, opsCfg.Settings())
+	runbook.AddCache("statistics", assessment.FlushStatistics)
+	runbook.AddIndex("storage", repo.Reindex)
+	if eventQueue != nil {
+		runbook.AddQueue("events", eventQueue.Depth)
+	}
+	runbook.SetLogs(logs)
 
-	server := &http.Server{
-		Addr:              addr,
-		Handler:           logMiddleware(authMiddleware(mux)),
-		ReadTimeout:       3 * time.Second,
-		ReadHeaderTimeout: 3 * time.Second,
-		WriteTimeout:      6 * time.Second,
-		IdleTimeout:       120 * time.Second,
+	root := http.NewServeMux()
+	root.Handle(health.Path, workers)
+	root.Handle("/api/auth/", publicMux)
+	root.Handle("/api/certificates/", publicMux)
+	openapi.Register(root, studenthttp.OpenAPI())
+	root.Handle(webhook.AdminPrefix, adminAuth(webhook.NewAdminHandler(dispatcher)))
+	root.Handle(config.ReloadPath, adminAuth(runtimeCfg))
+	root.Handle(httpmw.DeprecationsPath, adminAuth(deprecationUsage))
+	root.Handle(ops.AdminPrefix, adminAuth(runbook))
+	root.Handle(studenthttp.DeviceAdminPrefix, adminAuth(studenthttp.NewDeviceAdminHandler(devices)))
+	if streamCfg := config.LoadStream(); streamCfg.WebhookSecret != "" {
+		root.Handle(studenthttp.EventReceiverPath, studenthttp.NewEventReceiver(bus, streamCfg.WebhookSecret))
 	}
+	root.Handle("/", authMiddleware(deprecated(rateLimit(httpmw.Sandbox(sandboxes)(mux)))))
+
+	server := serverCfg.HTTPServer(traced(logging(cors(httpmw.Timezone()(root)))))
 
 	errCh := make(chan error, 1)
 	go func() {
-		log.Printf("student-api listening on %s", addr)
-		if err := server.ListenAndServe(); err != nil {
+		log.Printf("student-api listening on %s", serverCfg.Addr)
+		if err := serverCfg.ListenAndServe(server); err != nil {
 			errCh <- err
 		}
 	}()
@@ -57,9 +254,13 @@ func main() {
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
+	exitCode := 0
 	select {
 	case sig := <-sigCh:
 		log.Printf("student-api shutting down: %s", sig)
+	case worker := <-workers.Dead():
+		log.Printf("student-api shutting down: critical worker %s died", worker)
+		exitCode = 1
 	case err := <-errCh:
 		if !errors.Is(err, http.ErrServerClosed) {
 			log.Fatalf("student-api failed: %v", err)
@@ -67,35 +268,15 @@ func main() {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), serverCfg.ShutdownTimeout)
 	defer cancel()
 	if err := server.Shutdown(ctx); err != nil {
 		log.Printf("student-api shutdown error: %v", err)
 	}
-}
-
-func envOrDefault(key, fallback string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
+	if err := tracer.Flush(ctx); err != nil {
+		log.Printf("student-api trace flush error: %v", err)
+	}
+	if exitCode != 0 {
+		os.Exit(exitCode)
 	}
-	return fallback
-}
-
-func logMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		lw := &loggingResponseWriter{ResponseWriter: w, status: http.StatusOK}
-		next.ServeHTTP(lw, r)
-		log.Printf("%s %s %d %s", r.Method, r.URL.Path, lw.status, time.Since(start))
-	})
-}
-
-type loggingResponseWriter struct {
-	http.ResponseWriter
-	status int
-}
-
-func (w *loggingResponseWriter) WriteHeader(statusCode int) {
-	w.status = statusCode
-	w.ResponseWriter.WriteHeader(statusCode)
 }
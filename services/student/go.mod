@@ -2,6 +2,14 @@ module github.com/sky0621/go_work_sample/student
 
 go 1.24.3
 
-require github.com/sky0621/go_work_sample/core v0.0.0
+require (
+	github.com/sky0621/go_work_sample/core v0.0.0
+	github.com/sky0621/go_work_sample/scoring v0.0.0
+)
 
-replace github.com/sky0621/go_work_sample/core => ../core
+require github.com/mattn/go-sqlite3 v1.14.33 // indirect
+
+replace (
+	github.com/sky0621/go_work_sample/core => ../core
+	github.com/sky0621/go_work_sample/scoring => ../scoring
+)
@@ -0,0 +1,130 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+type answerCommentResponse struct {
+	CommentID  string                  `json:"comment_id"`
+	TestID     string                  `json:"test_id"`
+	AnswerID   string                  `json:"answer_id"`
+	ParentID   string                  `json:"parent_id,omitempty"`
+	AuthorRole string                  `json:"author_role"`
+	AuthorID   string                  `json:"author_id"`
+	Body       string                  `json:"body"`
+	CreatedAt  time.Time               `json:"created_at"`
+	ReadAt     *time.Time              `json:"read_at,omitempty"`
+	Replies    []answerCommentResponse `json:"replies"`
+}
+
+// routeAnswerComments serves the comment thread on one of the student's
+// answers; rest is the path after ".../answers/{answerID}/comments".
+func (h *Handler) routeAnswerComments(w http.ResponseWriter, r *http.Request, studentID domain.StudentID, testID domain.TestID, answerID domain.AnswerID, rest []string) {
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		h.listAnswerComments(w, r, studentID, testID, answerID)
+	case len(rest) == 0 && r.Method == http.MethodPost:
+		h.addAnswerComment(w, r, studentID, testID, answerID)
+	case len(rest) == 1 && rest[0] == "read" && r.Method == http.MethodPost:
+		h.markAnswerCommentsRead(w, r, studentID, testID, answerID)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (h *Handler) listAnswerComments(w http.ResponseWriter, r *http.Request, studentID domain.StudentID, testID domain.TestID, answerID domain.AnswerID) {
+	threads, err := h.assessments.ListAnswerComments(r.Context(), domain.RoleStudent, string(studentID), testID, answerID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"test_id":   string(testID),
+		"answer_id": string(answerID),
+		"comments":  toAnswerCommentThreads(threads),
+	})
+}
+
+func (h *Handler) addAnswerComment(w http.ResponseWriter, r *http.Request, studentID domain.StudentID, testID domain.TestID, answerID domain.AnswerID) {
+	var req struct {
+		ParentID string `json:"parent_id"`
+		Body     string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	comment, err := h.assessments.AddAnswerComment(r.Context(), usecase.AnswerCommentInput{
+		Role:     domain.RoleStudent,
+		AuthorID: string(studentID),
+		TestID:   testID,
+		AnswerID: answerID,
+		ParentID: domain.AnswerCommentID(strings.TrimSpace(req.ParentID)),
+		Body:     req.Body,
+	})
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toAnswerCommentResponse(*comment, nil))
+}
+
+func (h *Handler) markAnswerCommentsRead(w http.ResponseWriter, r *http.Request, studentID domain.StudentID, testID domain.TestID, answerID domain.AnswerID) {
+	marked, err := h.assessments.MarkAnswerCommentsRead(r.Context(), domain.RoleStudent, string(studentID), testID, answerID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"marked": marked})
+}
+
+// listUnreadAnswerComments lists teacher feedback the student has not read
+// yet across all of their answers.
+func (h *Handler) listUnreadAnswerComments(w http.ResponseWriter, r *http.Request, studentID domain.StudentID) {
+	comments, err := h.assessments.UnreadAnswerComments(r.Context(), studentID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	resp := make([]answerCommentResponse, len(comments))
+	for i, c := range comments {
+		resp[i] = toAnswerCommentResponse(c, nil)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"student_id": string(studentID),
+		"comments":   resp,
+	})
+}
+
+func toAnswerCommentThreads(threads []usecase.AnswerCommentThread) []answerCommentResponse {
+	resp := make([]answerCommentResponse, len(threads))
+	for i, t := range threads {
+		resp[i] = toAnswerCommentResponse(t.Comment, t.Replies)
+	}
+	return resp
+}
+
+func toAnswerCommentResponse(c domain.AnswerComment, replies []usecase.AnswerCommentThread) answerCommentResponse {
+	return answerCommentResponse{
+		CommentID:  string(c.ID),
+		TestID:     string(c.TestID),
+		AnswerID:   string(c.AnswerID),
+		ParentID:   string(c.ParentID),
+		AuthorRole: string(c.AuthorRole),
+		AuthorID:   c.AuthorID,
+		Body:       c.Body,
+		CreatedAt:  c.CreatedAt,
+		ReadAt:     c.ReadAt,
+		Replies:    toAnswerCommentThreads(replies),
+	}
+}
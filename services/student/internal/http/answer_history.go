@@ -0,0 +1,85 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+)
+
+// answerRevisionResponse is one version of an answer. The last entry of a
+// history is the current response.
+type answerRevisionResponse struct {
+	Revision  int       `json:"revision"`
+	Response  string    `json:"response"`
+	Note      string    `json:"note,omitempty"`
+	Truncated bool      `json:"truncated,omitempty"`
+	Current   bool      `json:"current,omitempty"`
+	SavedAt   time.Time `json:"saved_at"`
+}
+
+type answerHistoryResponse struct {
+	AnswerID   string                   `json:"answer_id"`
+	QuestionID string                   `json:"question_id"`
+	Revisions  []answerRevisionResponse `json:"revisions"`
+}
+
+// getAnswerHistory serves GET .../answers/{questionID}/history, the earlier
+// drafts of the student's answer.
+func (h *Handler) getAnswerHistory(w http.ResponseWriter, r *http.Request, studentID domain.StudentID, testID domain.TestID, questionID domain.QuestionID) {
+	answer, err := h.assessments.StudentAnswerHistory(r.Context(), studentID, testID, questionID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toAnswerHistoryResponse(*answer, locationOf(r)))
+}
+
+// restoreAnswerRevision serves POST .../answers/{questionID}/history/{revision}/restore,
+// which makes an earlier draft the current response again.
+func (h *Handler) restoreAnswerRevision(w http.ResponseWriter, r *http.Request, studentID domain.StudentID, testID domain.TestID, questionID domain.QuestionID, rawRevision string) {
+	revision, err := strconv.Atoi(rawRevision)
+	if err != nil || revision < 1 {
+		writeError(w, http.StatusNotFound, errs.ErrRevisionNotFound.Error())
+		return
+	}
+
+	if _, err := h.assessments.RestoreAnswerRevision(r.Context(), studentID, testID, questionID, revision); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	answer, err := h.assessments.StudentAnswerHistory(r.Context(), studentID, testID, questionID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toAnswerHistoryResponse(*answer, locationOf(r)))
+}
+
+func toAnswerHistoryResponse(answer domain.Answer, loc *time.Location) answerHistoryResponse {
+	revisions := make([]answerRevisionResponse, 0, len(answer.Revisions)+1)
+	for _, rev := range answer.Revisions {
+		revisions = append(revisions, answerRevisionResponse{
+			Revision:  rev.Revision,
+			Response:  rev.Response,
+			Note:      rev.Note,
+			Truncated: rev.Truncated,
+			SavedAt:   localTime(rev.SavedAt, loc),
+		})
+	}
+	revisions = append(revisions, answerRevisionResponse{
+		Revision:  answer.CurrentRevision(),
+		Response:  answer.Response,
+		Note:      answer.Note,
+		Truncated: answer.Truncated,
+		Current:   true,
+		SavedAt:   localTime(answer.UpdatedAt, loc),
+	})
+	return answerHistoryResponse{
+		AnswerID:   string(answer.ID),
+		QuestionID: string(answer.QuestionID),
+		Revisions:  revisions,
+	}
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/certificate"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+// downloadCertificate serves the student's completion certificate for a
+// passed test as a PDF. Every download issues a new certificate.
+func (h *Handler) downloadCertificate(w http.ResponseWriter, r *http.Request, studentID domain.StudentID, testID domain.TestID) {
+	cert, code, err := h.assessments.IssueCertificate(r.Context(), studentID, testID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", certificate.ContentTypePDF)
+	w.Header().Set("Content-Disposition", `attachment; filename="certificate-`+string(testID)+`.pdf"`)
+	_ = certificate.WritePDF(w, *cert, code, locationOf(r))
+}
+
+// CertificateHandler lets anyone holding a certificate check it. Its
+// endpoints are public and must be mounted outside the token middleware.
+type CertificateHandler struct {
+	signer *certificate.Signer
+}
+
+// NewCertificateHandler creates a certificate verification handler.
+func NewCertificateHandler(signer *certificate.Signer) *CertificateHandler {
+	return &CertificateHandler{signer: signer}
+}
+
+// Register wires the certificate endpoints onto the mux.
+func (h *CertificateHandler) Register(mux *http.ServeMux) {
+	mux.Handle("/api/certificates/verify", http.HandlerFunc(h.verify))
+	mux.Handle("/api/certificates/key", http.HandlerFunc(h.publicKey))
+}
+
+type certificateResponse struct {
+	Valid       bool      `json:"valid"`
+	ID          string    `json:"id"`
+	StudentName string    `json:"student_name"`
+	SchoolName  string    `json:"school_name,omitempty"`
+	TestTitle   string    `json:"test_title"`
+	Score       int       `json:"score"`
+	MaxScore    int       `json:"max_score"`
+	IssuedAt    time.Time `json:"issued_at"`
+}
+
+// verify serves POST /api/certificates/verify with the code printed on a
+// certificate.
+func (h *CertificateHandler) verify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	cert, err := h.signer.Verify(req.Code)
+	if err != nil {
+		writeJSON(w, http.StatusOK, map[string]any{"valid": false})
+		return
+	}
+	writeJSON(w, http.StatusOK, certificateResponse{
+		Valid:       true,
+		ID:          cert.ID,
+		StudentName: cert.StudentName,
+		SchoolName:  cert.SchoolName,
+		TestTitle:   cert.TestTitle,
+		Score:       cert.Score,
+		MaxScore:    cert.MaxScore,
+		IssuedAt:    cert.IssuedAt,
+	})
+}
+
+// publicKey serves GET /api/certificates/key, the Ed25519 key that verifies
+// certificate codes offline.
+func (h *CertificateHandler) publicKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"algorithm":  "Ed25519",
+		"public_key": base64.StdEncoding.EncodeToString(h.signer.PublicKey()),
+	})
+}
@@ -0,0 +1,106 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+type deviceResponse struct {
+	SessionID  string    `json:"session_id"`
+	Role       string    `json:"role"`
+	Device     string    `json:"device"`
+	Client     string    `json:"client"`
+	Current    bool      `json:"current"`
+	SignedInAt time.Time `json:"signed_in_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+func toDeviceResponses(sessions []domain.DeviceSession, current string) []deviceResponse {
+	resp := make([]deviceResponse, len(sessions))
+	for i, s := range sessions {
+		resp[i] = deviceResponse{
+			SessionID:  string(s.ID),
+			Role:       string(s.Role),
+			Device:     s.Device,
+			Client:     s.Client,
+			Current:    current != "" && string(s.ID) == current,
+			SignedInAt: s.CreatedAt,
+			ExpiresAt:  s.ExpiresAt,
+		}
+	}
+	return resp
+}
+
+// routeDevices serves the sign-ins of the caller, a student or a guardian
+// of the student, under /devices:
+//
+//	GET    .../devices              list active sessions
+//	DELETE .../devices              sign out every other session
+//	DELETE .../devices/{sessionID}  sign out one session
+func (h *Handler) routeDevices(w http.ResponseWriter, r *http.Request, studentID domain.StudentID, role domain.Role, parts []string) {
+	serveDevices(w, r, h.devices, studentID, role, parts[2:])
+}
+
+// DeviceAdminHandler lets administrators list and sign out the sessions of
+// any student and their guardians:
+//
+//	GET    /api/admin/students/{studentID}/devices
+//	DELETE /api/admin/students/{studentID}/devices
+//	DELETE /api/admin/students/{studentID}/devices/{sessionID}
+type DeviceAdminHandler struct {
+	devices *usecase.DeviceService
+}
+
+// NewDeviceAdminHandler builds the device administration handler.
+func NewDeviceAdminHandler(devices *usecase.DeviceService) *DeviceAdminHandler {
+	return &DeviceAdminHandler{devices: devices}
+}
+
+// DeviceAdminPrefix is the path the device admin handler must be mounted on.
+const DeviceAdminPrefix = "/api/admin/students/"
+
+func (h *DeviceAdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := splitPath(strings.TrimPrefix(r.URL.Path, DeviceAdminPrefix))
+	if len(parts) < 2 || parts[1] != "devices" {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	serveDevices(w, r, h.devices, domain.StudentID(parts[0]), "", parts[2:])
+}
+
+// serveDevices serves the device endpoints of a student for role, or for
+// every role when role is empty. rest is the path after /devices.
+func serveDevices(w http.ResponseWriter, r *http.Request, devices *usecase.DeviceService, studentID domain.StudentID, role domain.Role, rest []string) {
+	current := auth.TokenIDFrom(r.Context())
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		sessions, err := devices.ListDevices(r.Context(), studentID, role)
+		if err != nil {
+			handleServiceError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"devices": toDeviceResponses(sessions, current)})
+	case len(rest) == 0 && r.Method == http.MethodDelete:
+		revoked, err := devices.RevokeAll(r.Context(), studentID, role, domain.DeviceSessionID(current))
+		if err != nil {
+			handleServiceError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]int{"revoked": revoked})
+	case len(rest) == 1 && r.Method == http.MethodDelete:
+		if err := devices.Revoke(r.Context(), studentID, role, domain.DeviceSessionID(rest[0])); err != nil {
+			handleServiceError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case len(rest) <= 1:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
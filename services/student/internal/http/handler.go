@@ -2,23 +2,31 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
 	"github.com/sky0621/go_work_sample/core/pkg/domain"
 	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/events"
 	"github.com/sky0621/go_work_sample/core/pkg/usecase"
 )
 
 // Handler exposes student-facing endpoints.
 type Handler struct {
 	assessments *usecase.AssessmentService
+	profiles    *usecase.ProfileService
+	inbox       *usecase.InboxService
+	sessions    *usecase.SessionService
+	devices     *usecase.DeviceService
+	events      *events.Bus
 }
 
-// NewHandler builds a handler.
-func NewHandler(assessments *usecase.AssessmentService) *Handler {
-	return &Handler{assessments: assessments}
+// NewHandler builds a handler. The student's event stream subscribes to bus.
+func NewHandler(assessments *usecase.AssessmentService, profiles *usecase.ProfileService, inbox *usecase.InboxService, sessions *usecase.SessionService, devices *usecase.DeviceService, bus *events.Bus) *Handler {
+	return &Handler{assessments: assessments, profiles: profiles, inbox: inbox, sessions: sessions, devices: devices, events: bus}
 }
 
 // Register wires endpoints.
@@ -34,6 +42,75 @@ func (h *Handler) route(w http.ResponseWriter, r *http.Request) {
 	}
 
 	studentID := domain.StudentID(parts[0])
+	if auth.IsGuardianOf(r.Context(), studentID) {
+		r = h.withLocation(w, r, studentID)
+		h.routeGuardian(w, r, studentID, parts)
+		return
+	}
+	if !auth.IsStudent(r.Context(), studentID) {
+		writeError(w, http.StatusForbidden, errs.ErrForbiddenStudent.Error())
+		return
+	}
+	r = h.withLocation(w, r, studentID)
+
+	if len(parts) == 2 && parts[1] == "profile" {
+		switch r.Method {
+		case http.MethodGet:
+			h.getProfile(w, r, studentID)
+			return
+		case http.MethodPatch:
+			h.updateProfile(w, r, studentID)
+			return
+		}
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if len(parts) >= 2 && parts[1] == "devices" {
+		h.routeDevices(w, r, studentID, domain.RoleStudent, parts)
+		return
+	}
+
+	if len(parts) == 2 && parts[1] == "stream" {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		h.streamEvents(w, r, studentID)
+		return
+	}
+
+	if len(parts) == 2 && parts[1] == "sync" {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		h.syncStudent(w, r, studentID)
+		return
+	}
+
+	if len(parts) >= 2 && parts[1] == "notifications" {
+		switch {
+		case len(parts) == 2 && r.Method == http.MethodGet:
+			h.listNotifications(w, r, studentID)
+			return
+		case len(parts) == 3 && parts[2] == "read" && r.Method == http.MethodPost:
+			h.markNotificationsRead(w, r, studentID)
+			return
+		case len(parts) <= 3:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+	}
+
+	if len(parts) == 3 && parts[1] == "comments" && parts[2] == "unread" {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		h.listUnreadAnswerComments(w, r, studentID)
+		return
+	}
 
 	if len(parts) == 2 && parts[1] == "tests" {
 		if r.Method != http.MethodGet {
@@ -48,6 +125,14 @@ func (h *Handler) route(w http.ResponseWriter, r *http.Request) {
 		testID := domain.TestID(parts[2])
 		switch parts[3] {
 		case "questions":
+			if len(parts) == 6 && parts[5] == "flag" {
+				if r.Method != http.MethodPut {
+					writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+					return
+				}
+				h.flagQuestion(w, r, studentID, testID, domain.QuestionID(parts[4]))
+				return
+			}
 			if r.Method != http.MethodGet {
 				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
 				return
@@ -55,12 +140,46 @@ func (h *Handler) route(w http.ResponseWriter, r *http.Request) {
 			h.getQuestions(w, r, studentID, testID)
 			return
 		case "answers":
+			if len(parts) == 6 && parts[5] == "history" {
+				if r.Method != http.MethodGet {
+					writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+					return
+				}
+				h.getAnswerHistory(w, r, studentID, testID, domain.QuestionID(parts[4]))
+				return
+			}
+			if len(parts) == 8 && parts[5] == "history" && parts[7] == "restore" {
+				if r.Method != http.MethodPost {
+					writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+					return
+				}
+				h.restoreAnswerRevision(w, r, studentID, testID, domain.QuestionID(parts[4]), parts[6])
+				return
+			}
+			if len(parts) >= 6 && parts[5] == "comments" {
+				h.routeAnswerComments(w, r, studentID, testID, domain.AnswerID(parts[4]), parts[6:])
+				return
+			}
 			if r.Method != http.MethodPost {
 				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
 				return
 			}
 			h.submitAnswer(w, r, studentID, testID)
 			return
+		case "start":
+			if len(parts) != 4 || r.Method != http.MethodPost {
+				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+				return
+			}
+			h.startTest(w, r, studentID, testID)
+			return
+		case "submit":
+			if len(parts) != 4 || r.Method != http.MethodPost {
+				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+				return
+			}
+			h.submitTest(w, r, studentID, testID)
+			return
 		case "results":
 			if r.Method != http.MethodGet {
 				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
@@ -68,25 +187,86 @@ func (h *Handler) route(w http.ResponseWriter, r *http.Request) {
 			}
 			h.listResults(w, r, studentID, testID)
 			return
+		case "certificate":
+			if len(parts) != 4 || r.Method != http.MethodGet {
+				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+				return
+			}
+			h.downloadCertificate(w, r, studentID, testID)
+			return
 		}
 	}
 
 	writeError(w, http.StatusNotFound, "not found")
 }
 
+// routeGuardian serves the read-only view of a guardian following the
+// student: the student's tests and their results. Guardians also manage
+// their own sign-ins.
+func (h *Handler) routeGuardian(w http.ResponseWriter, r *http.Request, studentID domain.StudentID, parts []string) {
+	switch {
+	case len(parts) >= 2 && parts[1] == "devices":
+		h.routeDevices(w, r, studentID, domain.RoleGuardian, parts)
+	case len(parts) == 2 && parts[1] == "tests" && r.Method == http.MethodGet:
+		h.listTests(w, r, studentID)
+	case len(parts) == 4 && parts[1] == "tests" && parts[3] == "results" && r.Method == http.MethodGet:
+		h.listResults(w, r, studentID, domain.TestID(parts[2]))
+	default:
+		writeError(w, http.StatusForbidden, errs.ErrForbiddenStudent.Error())
+	}
+}
+
 type testSummary struct {
-	TestID    string    `json:"test_id"`
-	Title     string    `json:"title"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	TestID   string     `json:"test_id"`
+	Title    string     `json:"title"`
+	Status   string     `json:"status"`
+	OpensAt  *time.Time `json:"opens_at,omitempty"`
+	ClosesAt *time.Time `json:"closes_at,omitempty"`
+	// DurationMinutes is how long a timed test lasts from its start.
+	DurationMinutes int       `json:"duration_minutes,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// toTestSummary describes the test's availability to the student at now,
+// counting their deadline extension, with its timestamps in loc.
+func toTestSummary(test domain.Test, studentID domain.StudentID, now time.Time, loc *time.Location) testSummary {
+	return testSummary{
+		TestID:          string(test.ID),
+		Title:           test.Title,
+		Status:          string(test.AvailabilityFor(studentID, now)),
+		OpensAt:         localTimePtr(test.OpensAt, loc),
+		ClosesAt:        localTimePtr(test.ClosesAtFor(studentID), loc),
+		DurationMinutes: test.DurationMinutes,
+		CreatedAt:       localTime(test.CreatedAt, loc),
+		UpdatedAt:       localTime(test.UpdatedAt, loc),
+	}
+}
+
+type sectionResponse struct {
+	SectionID    string `json:"section_id"`
+	Title        string `json:"title"`
+	Instructions string `json:"instructions"`
 }
 
 type questionResponse struct {
-	QuestionID string    `json:"question_id"`
-	Sequence   int       `json:"sequence"`
-	Prompt     string    `json:"prompt"`
-	Points     int       `json:"points"`
-	CreatedAt  time.Time `json:"created_at"`
+	QuestionID string           `json:"question_id"`
+	SectionID  string           `json:"section_id,omitempty"`
+	Sequence   int              `json:"sequence"`
+	Prompt     string           `json:"prompt"`
+	Points     int              `json:"points"`
+	Type       string           `json:"type"`
+	Choices    []choiceResponse `json:"choices,omitempty"`
+	Locale     string           `json:"locale,omitempty"`
+	Flagged    bool             `json:"flagged"`
+	CreatedAt  time.Time        `json:"created_at"`
+}
+
+// choiceResponse is one option of a multiple-choice question; answers submit
+// its key.
+type choiceResponse struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
 }
 
 type answerResponse struct {
@@ -95,10 +275,19 @@ type answerResponse struct {
 	QuestionID string    `json:"question_id"`
 	StudentID  string    `json:"student_id"`
 	Response   string    `json:"response"`
+	Note       string    `json:"note,omitempty"`
+	Truncated  bool      `json:"truncated,omitempty"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
 }
 
+type outcomeResponse struct {
+	Score    int     `json:"score"`
+	MaxScore int     `json:"max_score"`
+	Percent  float64 `json:"percent"`
+	Passed   *bool   `json:"passed"`
+}
+
 type resultResponse struct {
 	ResultID  string    `json:"result_id"`
 	AnswerID  string    `json:"answer_id"`
@@ -110,53 +299,91 @@ type resultResponse struct {
 }
 
 func (h *Handler) listTests(w http.ResponseWriter, r *http.Request, studentID domain.StudentID) {
-	tests, err := h.assessments.ListTestsForStudent(r.Context(), studentID)
+	page, err := parsePageRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	tests, err := h.assessments.ListTestsForStudent(r.Context(), studentID, page)
 	if err != nil {
 		handleServiceError(w, err)
 		return
 	}
 
-	payload := make([]testSummary, len(tests))
-	for i, test := range tests {
-		payload[i] = testSummary{
-			TestID:    string(test.ID),
-			Title:     test.Title,
-			CreatedAt: test.CreatedAt,
-			UpdatedAt: test.UpdatedAt,
-		}
+	now := time.Now().UTC()
+	loc := locationOf(r)
+	payload := make([]testSummary, len(tests.Items))
+	for i, test := range tests.Items {
+		payload[i] = toTestSummary(test, studentID, now, loc)
 	}
 
-	writeJSON(w, http.StatusOK, map[string]any{"tests": payload})
+	writeJSON(w, http.StatusOK, map[string]any{
+		"tests": payload,
+		"page":  toPageInfo(page, tests.NextCursor),
+	})
 }
 
 func (h *Handler) getQuestions(w http.ResponseWriter, r *http.Request, studentID domain.StudentID, testID domain.TestID) {
+	test, err := h.assessments.GetTestForStudent(r.Context(), studentID, testID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
 	questions, err := h.assessments.GetQuestionsForStudent(r.Context(), studentID, testID)
 	if err != nil {
 		handleServiceError(w, err)
 		return
 	}
+	session, err := h.sessions.GetSession(r.Context(), studentID, testID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	flagged := flaggedSet(session)
 
 	payload := make([]questionResponse, len(questions))
 	for i, q := range questions {
+		_, isFlagged := flagged[q.ID]
 		payload[i] = questionResponse{
 			QuestionID: string(q.ID),
+			SectionID:  string(q.SectionID),
 			Sequence:   q.Sequence,
 			Prompt:     q.Prompt,
-			Points:     q.Points,
-			CreatedAt:  q.CreatedAt,
+			Points:     int(q.Points),
+			Type:       string(q.AnswerType()),
+			Choices:    toChoiceResponses(q.Choices),
+			Locale:     q.Locale,
+			Flagged:    isFlagged,
+			CreatedAt:  localTime(q.CreatedAt, locationOf(r)),
 		}
 	}
 
-	writeJSON(w, http.StatusOK, map[string]any{
-		"test_id":   string(testID),
-		"questions": payload,
-	})
+	sections := make([]sectionResponse, len(test.Sections))
+	for i, sec := range test.Sections {
+		sections[i] = sectionResponse{
+			SectionID:    string(sec.ID),
+			Title:        sec.Title,
+			Instructions: sec.Instructions,
+		}
+	}
+
+	resp := map[string]any{
+		"test_id":      string(testID),
+		"instructions": test.Instructions,
+		"sections":     sections,
+		"questions":    payload,
+	}
+	if timer := toTimerResponse(*test, *session, time.Now().UTC(), locationOf(r)); timer != nil {
+		resp["timer"] = timer
+	}
+	writeJSON(w, http.StatusOK, resp)
 }
 
 func (h *Handler) submitAnswer(w http.ResponseWriter, r *http.Request, studentID domain.StudentID, testID domain.TestID) {
 	var req struct {
 		QuestionID string `json:"question_id"`
 		Response   string `json:"response"`
+		Note       string `json:"note"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid json payload")
@@ -168,6 +395,7 @@ func (h *Handler) submitAnswer(w http.ResponseWriter, r *http.Request, studentID
 		QuestionID: domain.QuestionID(strings.TrimSpace(req.QuestionID)),
 		StudentID:  studentID,
 		Response:   strings.TrimSpace(req.Response),
+		Note:       strings.TrimSpace(req.Note),
 	}
 
 	saved, err := h.assessments.SubmitAnswer(r.Context(), answer)
@@ -182,8 +410,10 @@ func (h *Handler) submitAnswer(w http.ResponseWriter, r *http.Request, studentID
 		QuestionID: string(saved.QuestionID),
 		StudentID:  string(saved.StudentID),
 		Response:   saved.Response,
-		CreatedAt:  saved.CreatedAt,
-		UpdatedAt:  saved.UpdatedAt,
+		Note:       saved.Note,
+		Truncated:  saved.Truncated,
+		CreatedAt:  localTime(saved.CreatedAt, locationOf(r)),
+		UpdatedAt:  localTime(saved.UpdatedAt, locationOf(r)),
 	})
 }
 
@@ -193,23 +423,27 @@ func (h *Handler) listResults(w http.ResponseWriter, r *http.Request, studentID
 		handleServiceError(w, err)
 		return
 	}
+	outcome, err := h.assessments.StudentTestOutcome(r.Context(), studentID, testID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
 
+	loc := locationOf(r)
 	payload := make([]resultResponse, len(results))
 	for i, res := range results {
-		payload[i] = resultResponse{
-			ResultID:  string(res.ID),
-			AnswerID:  string(res.AnswerID),
-			Score:     res.Score,
-			Feedback:  res.Feedback,
-			Completed: res.Completed,
-			CreatedAt: res.CreatedAt,
-			UpdatedAt: res.UpdatedAt,
-		}
+		payload[i] = toResultResponse(res, loc)
 	}
 
 	writeJSON(w, http.StatusOK, map[string]any{
 		"test_id": string(testID),
 		"results": payload,
+		"outcome": outcomeResponse{
+			Score:    int(outcome.Score),
+			MaxScore: int(outcome.MaxScore),
+			Percent:  outcome.Percent(),
+			Passed:   outcome.Passed,
+		},
 	})
 }
 
@@ -228,16 +462,44 @@ func splitPath(path string) []string {
 }
 
 func handleServiceError(w http.ResponseWriter, err error) {
+	var invariant *domain.InvariantError
+	if errors.As(err, &invariant) {
+		writeError(w, http.StatusBadRequest, invariant.Error())
+		return
+	}
+
 	switch err {
-	case errs.ErrStudentNotFound, errs.ErrTestNotFound:
+	case errs.ErrStudentNotFound, errs.ErrTestNotFound, errs.ErrDeviceNotFound, errs.ErrAnswerNotFound, errs.ErrRevisionNotFound, errs.ErrNoCertificate, errs.ErrCommentNotFound:
 		writeError(w, http.StatusNotFound, err.Error())
-	case errs.ErrStudentNotAssigned, errs.ErrInvalidAnswer, errs.ErrQuestionNotFound:
+	case errs.ErrStudentNotAssigned, errs.ErrInvalidAnswer, errs.ErrQuestionNotFound, errs.ErrInvalidProfile, errs.ErrInvalidCursor, errs.ErrInvalidComment:
 		writeError(w, http.StatusBadRequest, err.Error())
+	case errs.ErrTestClosed, errs.ErrTestSubmitted, errs.ErrTestNotStarted, errs.ErrTimeExpired, errs.ErrTestNotPassed:
+		writeError(w, http.StatusConflict, err.Error())
+	case errs.ErrSubmitUnavailable, errs.ErrTimerUnavailable, errs.ErrSigningUnavailable, errs.ErrCommentsUnavailable:
+		writeError(w, http.StatusNotImplemented, err.Error())
+	case errs.ErrQuotaExceeded, errs.ErrDuplicateAnswer:
+		writeError(w, http.StatusTooManyRequests, err.Error())
+	case errs.ErrAnswerThrottled:
+		w.Header().Set("Retry-After", "60")
+		writeError(w, http.StatusTooManyRequests, err.Error())
+	case errs.ErrResponseTooLong:
+		writeError(w, http.StatusRequestEntityTooLarge, err.Error())
 	default:
 		writeError(w, http.StatusInternalServerError, err.Error())
 	}
 }
 
+func toChoiceResponses(choices []domain.Choice) []choiceResponse {
+	if len(choices) == 0 {
+		return nil
+	}
+	out := make([]choiceResponse, len(choices))
+	for i, c := range choices {
+		out[i] = choiceResponse{Key: c.Key, Label: c.Label}
+	}
+	return out
+}
+
 func writeError(w http.ResponseWriter, status int, message string) {
 	writeJSON(w, status, map[string]string{"error": message})
 }
@@ -247,3 +509,15 @@ func writeJSON(w http.ResponseWriter, status int, payload any) {
 	w.WriteHeader(status)
 	_ = json.NewEncoder(w).Encode(payload)
 }
+
+func toResultResponse(res domain.Result, loc *time.Location) resultResponse {
+	return resultResponse{
+		ResultID:  string(res.ID),
+		AnswerID:  string(res.AnswerID),
+		Score:     int(res.Score),
+		Feedback:  res.Feedback,
+		Completed: res.Completed,
+		CreatedAt: localTime(res.CreatedAt, loc),
+		UpdatedAt: localTime(res.UpdatedAt, loc),
+	}
+}
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+type notificationResponse struct {
+	NotificationID string     `json:"notification_id"`
+	Kind           string     `json:"kind"`
+	Subject        string     `json:"subject"`
+	Body           string     `json:"body"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ReadAt         *time.Time `json:"read_at"`
+}
+
+func (h *Handler) listNotifications(w http.ResponseWriter, r *http.Request, studentID domain.StudentID) {
+	page, err := parsePageRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	inbox, err := h.inbox.List(r.Context(), domain.RoleStudent, string(studentID), page)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	payload := make([]notificationResponse, len(inbox.Notifications))
+	for i, n := range inbox.Notifications {
+		payload[i] = notificationResponse{
+			NotificationID: string(n.ID),
+			Kind:           n.Kind,
+			Subject:        n.Subject,
+			Body:           n.Body,
+			CreatedAt:      n.CreatedAt,
+			ReadAt:         n.ReadAt,
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"notifications": payload,
+		"unread_count":  inbox.Unread,
+		"page":          toPageInfo(page, inbox.NextCursor),
+	})
+}
+
+func (h *Handler) markNotificationsRead(w http.ResponseWriter, r *http.Request, studentID domain.StudentID) {
+	var req struct {
+		NotificationIDs []string `json:"notification_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	ids := make([]domain.NotificationID, 0, len(req.NotificationIDs))
+	for _, nid := range req.NotificationIDs {
+		if nid = strings.TrimSpace(nid); nid != "" {
+			ids = append(ids, domain.NotificationID(nid))
+		}
+	}
+
+	if err := h.inbox.MarkRead(r.Context(), domain.RoleStudent, string(studentID), ids); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	h.listNotifications(w, r, studentID)
+}
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+// MagicLinkHandler serves passwordless sign-in for students and guardians.
+// Its endpoints are public and must be mounted outside the token middleware.
+type MagicLinkHandler struct {
+	links *usecase.MagicLinkService
+}
+
+// NewMagicLinkHandler creates a magic-link handler instance.
+func NewMagicLinkHandler(links *usecase.MagicLinkService) *MagicLinkHandler {
+	return &MagicLinkHandler{links: links}
+}
+
+// Register wires the magic-link endpoints onto the mux.
+func (h *MagicLinkHandler) Register(mux *http.ServeMux) {
+	mux.Handle("/api/auth/magic-link", http.HandlerFunc(h.requestLink))
+	mux.Handle("/api/auth/magic-link/redeem", http.HandlerFunc(h.redeem))
+}
+
+type signInResponse struct {
+	Token     string    `json:"token"`
+	Role      string    `json:"role"`
+	SubjectID string    `json:"subject_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// requestLink serves POST /api/auth/magic-link. It answers 202 whether or not
+// the address is registered.
+func (h *MagicLinkHandler) requestLink(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	if err := h.links.RequestLink(r.Context(), req.Email, clientKey(r)); err != nil {
+		handleMagicLinkError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "sent"})
+}
+
+// redeem serves POST /api/auth/magic-link/redeem, exchanging the token of a
+// link for an access token.
+func (h *MagicLinkHandler) redeem(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	token, claims, err := h.links.Redeem(r.Context(), req.Token, clientKey(r), r.UserAgent())
+	if err != nil {
+		handleMagicLinkError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, signInResponse{
+		Token:     token,
+		Role:      string(claims.Role),
+		SubjectID: claims.Subject,
+		ExpiresAt: claims.Expiry(),
+	})
+}
+
+func handleMagicLinkError(w http.ResponseWriter, err error) {
+	switch err {
+	case errs.ErrInvalidEmail:
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errs.ErrInvalidMagicLink:
+		writeError(w, http.StatusUnauthorized, err.Error())
+	case errs.ErrTooManyRequests:
+		w.Header().Set("Retry-After", "3600")
+		writeError(w, http.StatusTooManyRequests, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// clientKey identifies the caller for throttling by its IP address.
+func clientKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
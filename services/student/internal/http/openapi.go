@@ -0,0 +1,173 @@
+package http
+
+import (
+	"github.com/sky0621/go_work_sample/core/pkg/certificate"
+	"github.com/sky0621/go_work_sample/core/pkg/openapi"
+)
+
+// OpenAPI describes the student API.
+func OpenAPI() *openapi.Document {
+	const student = "/api/students/{studentID}"
+	const test = student + "/tests/{testID}"
+	notifications := openapi.Object{
+		"notifications": []notificationResponse{},
+		"unread_count":  0,
+		"page":          pageInfo{},
+	}
+
+	b := openapi.New(openapi.Info{
+		Title:       "Student API",
+		Version:     "1.0.0",
+		Description: "Students sit their assigned tests; guardians signed in by magic link may read the tests and results of their student.",
+	})
+	b.Add("POST", "/api/auth/magic-link", openapi.Route{
+		Summary:  "Email sign-in links to a student or guardian address",
+		Tag:      "auth",
+		Request:  openapi.Object{"email": ""},
+		Status:   202,
+		Response: openapi.Object{"status": ""},
+		Public:   true,
+	})
+	b.Add("POST", "/api/auth/magic-link/redeem", openapi.Route{
+		Summary:  "Exchange a sign-in link token for an access token",
+		Tag:      "auth",
+		Request:  openapi.Object{"token": ""},
+		Response: signInResponse{},
+		Public:   true,
+	})
+
+	b.Add("GET", student+"/profile", openapi.Route{Summary: "Get the student's profile", Tag: "profile", Response: profileResponse{}})
+	b.Add("PATCH", student+"/profile", openapi.Route{Summary: "Update the student's profile", Tag: "profile", Request: profileRequest{}, Response: profileResponse{}})
+
+	b.Add("GET", student+"/devices", openapi.Route{
+		Summary:  "List the devices the caller is signed in on",
+		Tag:      "auth",
+		Response: openapi.Object{"devices": []deviceResponse{}},
+	})
+	b.Add("DELETE", student+"/devices", openapi.Route{
+		Summary:  "Sign out every device but the current one",
+		Tag:      "auth",
+		Response: openapi.Object{"revoked": 0},
+	})
+	b.Add("DELETE", student+"/devices/{sessionID}", openapi.Route{Summary: "Sign out one device", Tag: "auth", Status: 204})
+
+	b.Add("GET", student+"/comments/unread", openapi.Route{
+		Summary:  "List teacher feedback on answers not read yet",
+		Tag:      "notifications",
+		Response: openapi.Object{"student_id": "", "comments": []answerCommentResponse{}},
+	})
+	b.Add("GET", student+"/notifications", openapi.Route{Summary: "List notifications", Tag: "notifications", Query: openapi.PageQuery(), Response: notifications})
+	b.Add("POST", student+"/notifications/read", openapi.Route{
+		Summary:  "Mark notifications as read",
+		Tag:      "notifications",
+		Request:  openapi.Object{"notification_ids": []string{}},
+		Response: notifications,
+	})
+
+	b.Add("GET", student+"/stream", openapi.Route{
+		Summary:      "Stream test.assigned and result.saved events as server-sent events",
+		Tag:          "notifications",
+		Response:     streamEvent{},
+		ResponseType: "text/event-stream",
+	})
+
+	b.Add("GET", student+"/sync", openapi.Route{
+		Summary:  "Get the tests and released results changed since the cursor of a previous sync",
+		Tag:      "tests",
+		Query:    []openapi.Parameter{openapi.Query("since", "cursor returned by the previous sync; omit for everything")},
+		Response: syncResponse{},
+	})
+	b.Add("GET", student+"/tests", openapi.Route{
+		Summary:  "List assigned tests with their availability",
+		Tag:      "tests",
+		Query:    openapi.PageQuery(),
+		Response: openapi.Object{"tests": []testSummary{}, "page": pageInfo{}},
+	})
+	b.Add("GET", test+"/questions", openapi.Route{
+		Summary: "Get a test's questions in the student's locale",
+		Tag:     "tests",
+		Response: openapi.Object{
+			"test_id":      "",
+			"instructions": "",
+			"sections":     []sectionResponse{},
+			"questions":    []questionResponse{},
+			"timer":        timerResponse{},
+		},
+	})
+	b.Add("PUT", test+"/questions/{questionID}/flag", openapi.Route{
+		Summary:  "Flag a question for review",
+		Tag:      "tests",
+		Request:  flagRequest{},
+		Response: sessionResponse{},
+	})
+	b.Add("POST", test+"/answers", openapi.Route{
+		Summary:  "Submit or replace an answer",
+		Tag:      "tests",
+		Request:  openapi.Object{"question_id": "", "response": "", "note": ""},
+		Status:   202,
+		Response: answerResponse{},
+	})
+	b.Add("GET", test+"/answers/{questionID}/history", openapi.Route{
+		Summary:  "List the saved versions of an answer, oldest first",
+		Tag:      "tests",
+		Response: answerHistoryResponse{},
+	})
+	b.Add("POST", test+"/answers/{questionID}/history/{revision}/restore", openapi.Route{
+		Summary:  "Make an earlier version the current answer",
+		Tag:      "tests",
+		Response: answerHistoryResponse{},
+	})
+	answerComments := openapi.Object{"test_id": "", "answer_id": "", "comments": []answerCommentResponse{}}
+	b.Add("GET", test+"/answers/{answerID}/comments", openapi.Route{
+		Summary:  "List the comment threads on an answer",
+		Tag:      "tests",
+		Response: answerComments,
+	})
+	b.Add("POST", test+"/answers/{answerID}/comments", openapi.Route{
+		Summary:  "Comment on an answer or reply to a comment",
+		Tag:      "tests",
+		Request:  openapi.Object{"parent_id": "", "body": ""},
+		Status:   201,
+		Response: answerCommentResponse{},
+	})
+	b.Add("POST", test+"/answers/{answerID}/comments/read", openapi.Route{
+		Summary:  "Mark the other side's comments on an answer as read",
+		Tag:      "tests",
+		Response: openapi.Object{"marked": 0},
+	})
+	b.Add("POST", test+"/start", openapi.Route{
+		Summary:  "Start the test, which starts the timer of a timed test",
+		Tag:      "tests",
+		Response: timerResponse{},
+	})
+	b.Add("POST", test+"/submit", openapi.Route{
+		Summary:  "Finalize the test; answers can no longer be changed",
+		Tag:      "tests",
+		Response: submissionResponse{},
+	})
+	b.Add("GET", test+"/results", openapi.Route{
+		Summary:  "List results and the total outcome",
+		Tag:      "tests",
+		Response: openapi.Object{"test_id": "", "results": []resultResponse{}, "outcome": outcomeResponse{}},
+	})
+	b.Add("GET", test+"/certificate", openapi.Route{
+		Summary:      "Download a signed completion certificate for a passed test",
+		Tag:          "tests",
+		Response:     openapi.Binary{},
+		ResponseType: certificate.ContentTypePDF,
+	})
+	b.Add("POST", "/api/certificates/verify", openapi.Route{
+		Summary:  "Check the code printed on a completion certificate",
+		Tag:      "certificates",
+		Request:  openapi.Object{"code": ""},
+		Response: certificateResponse{},
+		Public:   true,
+	})
+	b.Add("GET", "/api/certificates/key", openapi.Route{
+		Summary:  "Get the public key that verifies certificate codes offline",
+		Tag:      "certificates",
+		Response: openapi.Object{"algorithm": "", "public_key": ""},
+		Public:   true,
+	})
+	return b.Document()
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+type notificationPreferences struct {
+	Delivery       string `json:"delivery"`
+	TestAssigned   bool   `json:"test_assigned"`
+	ResultReleased bool   `json:"result_released"`
+}
+
+// notificationRequest changes notification preferences. An event left out
+// stays subscribed.
+type notificationRequest struct {
+	Delivery       string `json:"delivery"`
+	TestAssigned   *bool  `json:"test_assigned"`
+	ResultReleased *bool  `json:"result_released"`
+}
+
+func (n notificationRequest) preferences() domain.NotificationPreferences {
+	return domain.NotificationPreferences{
+		Delivery:           domain.NotificationDelivery(strings.TrimSpace(n.Delivery)),
+		MuteTestAssigned:   n.TestAssigned != nil && !*n.TestAssigned,
+		MuteResultReleased: n.ResultReleased != nil && !*n.ResultReleased,
+	}
+}
+
+type profileResponse struct {
+	StudentID     string                  `json:"student_id"`
+	Name          string                  `json:"name"`
+	DisplayName   string                  `json:"display_name"`
+	Email         string                  `json:"email"`
+	Timezone      string                  `json:"timezone,omitempty"`
+	Locale        string                  `json:"locale,omitempty"`
+	Notifications notificationPreferences `json:"notifications"`
+}
+
+type profileRequest struct {
+	DisplayName   *string              `json:"display_name"`
+	Notifications *notificationRequest `json:"notifications"`
+	Timezone      *string              `json:"timezone"`
+	Locale        *string              `json:"locale"`
+}
+
+func (h *Handler) getProfile(w http.ResponseWriter, r *http.Request, studentID domain.StudentID) {
+	student, err := h.profiles.GetStudentProfile(r.Context(), studentID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toProfileResponse(*student))
+}
+
+func (h *Handler) updateProfile(w http.ResponseWriter, r *http.Request, studentID domain.StudentID) {
+	var req profileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	update := usecase.ProfileUpdate{DisplayName: req.DisplayName, Locale: req.Locale, Timezone: req.Timezone}
+	if req.Notifications != nil {
+		prefs := req.Notifications.preferences()
+		update.Notifications = &prefs
+	}
+
+	student, err := h.profiles.UpdateStudentProfile(r.Context(), studentID, update)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toProfileResponse(*student))
+}
+
+func toProfileResponse(student domain.Student) profileResponse {
+	return profileResponse{
+		StudentID:   string(student.ID),
+		Name:        student.Name,
+		DisplayName: student.DisplayName,
+		Email:       student.Email,
+		Locale:      student.Locale,
+		Timezone:    student.Timezone,
+		Notifications: notificationPreferences{
+			Delivery:       string(student.Notifications.Delivery),
+			TestAssigned:   !student.Notifications.MuteTestAssigned,
+			ResultReleased: !student.Notifications.MuteResultReleased,
+		},
+	}
+}
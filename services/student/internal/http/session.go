@@ -0,0 +1,45 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+type flagRequest struct {
+	Flagged bool `json:"flagged"`
+}
+
+type sessionResponse struct {
+	TestID  string   `json:"test_id"`
+	Flagged []string `json:"flagged_question_ids"`
+}
+
+func (h *Handler) flagQuestion(w http.ResponseWriter, r *http.Request, studentID domain.StudentID, testID domain.TestID, questionID domain.QuestionID) {
+	var req flagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	session, err := h.sessions.FlagQuestion(r.Context(), studentID, testID, questionID, req.Flagged)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	resp := sessionResponse{TestID: string(session.TestID), Flagged: make([]string, len(session.Flagged))}
+	for i, qid := range session.Flagged {
+		resp.Flagged[i] = string(qid)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func flaggedSet(session *domain.TestSession) map[domain.QuestionID]struct{} {
+	set := make(map[domain.QuestionID]struct{}, len(session.Flagged))
+	for _, qid := range session.Flagged {
+		set[qid] = struct{}{}
+	}
+	return set
+}
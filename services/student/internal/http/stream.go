@@ -0,0 +1,183 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/events"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+	"github.com/sky0621/go_work_sample/core/pkg/webhook"
+)
+
+const (
+	// streamHeartbeat keeps idle streams from being cut by proxies.
+	streamHeartbeat = 25 * time.Second
+	// streamWriteTimeout bounds each write, replacing the server's write
+	// timeout that would otherwise end every stream after a few seconds.
+	streamWriteTimeout = 10 * time.Second
+	// streamRetry tells clients how long to wait before reconnecting.
+	streamRetry = 3 * time.Second
+)
+
+type streamEvent struct {
+	TestID     string    `json:"test_id"`
+	Title      string    `json:"title,omitempty"`
+	QuestionID string    `json:"question_id,omitempty"`
+	Score      *int      `json:"score,omitempty"`
+	Completed  *bool     `json:"completed,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func toStreamEvent(e events.Event) streamEvent {
+	payload := streamEvent{TestID: string(e.TestID), Title: e.Title, OccurredAt: e.OccurredAt}
+	if e.Kind == events.KindResultSaved {
+		score, completed := int(e.Score), e.Completed
+		payload.QuestionID = string(e.QuestionID)
+		payload.Score = &score
+		payload.Completed = &completed
+	}
+	return payload
+}
+
+// streamEvents pushes the student's assignments and grades as server-sent
+// events until the client disconnects. A stream that falls behind is ended;
+// the client reconnects and reloads its view.
+func (h *Handler) streamEvents(w http.ResponseWriter, r *http.Request, studentID domain.StudentID) {
+	sub := h.events.Subscribe(studentID)
+	defer sub.Close()
+
+	rc := http.NewResponseController(w)
+	write := func(frame string) bool {
+		_ = rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		if _, err := io.WriteString(w, frame); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if !write(fmt.Sprintf("retry: %d\n\n", streamRetry.Milliseconds())) {
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if !write(": keep-alive\n\n") {
+				return
+			}
+		case e, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(toStreamEvent(e))
+			if err != nil {
+				log.Printf("stream event %s: %v", e.ID, err)
+				continue
+			}
+			if !write(fmt.Sprintf("id: %s\nevent: %s\ndata: %s\n\n", e.ID, e.Kind, data)) {
+				return
+			}
+		}
+	}
+}
+
+// EventReceiverPath is where other services post the webhooks that feed the
+// student event stream.
+const EventReceiverPath = "/internal/events/webhook"
+
+// maxSignatureAge rejects replayed webhooks.
+const maxSignatureAge = 5 * time.Minute
+
+// EventReceiver turns the signed test and result webhooks of other services
+// into student events. The teacher and scoring services list it as a webhook
+// endpoint subscribed to test.created, test.published, test.assigned and
+// result.graded with the same secret.
+type EventReceiver struct {
+	bus    *events.Bus
+	secret string
+}
+
+// NewEventReceiver creates a receiver publishing to bus.
+func NewEventReceiver(bus *events.Bus, secret string) *EventReceiver {
+	return &EventReceiver{bus: bus, secret: secret}
+}
+
+type receivedWebhook struct {
+	ID         string    `json:"id"`
+	Event      string    `json:"event"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       struct {
+		TestID     string   `json:"test_id"`
+		Title      string   `json:"title"`
+		StudentIDs []string `json:"student_ids"`
+		Published  bool     `json:"published"`
+		QuestionID string   `json:"question_id"`
+		StudentID  string   `json:"student_id"`
+		Score      int      `json:"score"`
+		Completed  bool     `json:"completed"`
+	} `json:"data"`
+}
+
+func (h *EventReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	if err := webhook.Verify(h.secret, r.Header.Get(webhook.HeaderSignature), body, time.Now(), maxSignatureAge); err != nil {
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	var hook receivedWebhook
+	if err := json.Unmarshal(body, &hook); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	data := hook.Data
+	switch hook.Event {
+	case usecase.EventTestCreated, usecase.EventTestPublished, usecase.EventTestAssigned:
+		if !data.Published && hook.Event != usecase.EventTestPublished {
+			break
+		}
+		for _, studentID := range data.StudentIDs {
+			h.bus.Publish(events.Event{
+				ID:         hook.ID,
+				Kind:       events.KindTestAssigned,
+				StudentID:  domain.StudentID(studentID),
+				TestID:     domain.TestID(data.TestID),
+				Title:      data.Title,
+				OccurredAt: hook.OccurredAt,
+			})
+		}
+	case usecase.EventResultGraded:
+		h.bus.Publish(events.Event{
+			ID:         hook.ID,
+			Kind:       events.KindResultSaved,
+			StudentID:  domain.StudentID(data.StudentID),
+			TestID:     domain.TestID(data.TestID),
+			QuestionID: domain.QuestionID(data.QuestionID),
+			Score:      domain.Score(data.Score),
+			Completed:  data.Completed,
+			OccurredAt: hook.OccurredAt,
+		})
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,27 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+type submissionResponse struct {
+	TestID      string    `json:"test_id"`
+	StudentID   string    `json:"student_id"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+func (h *Handler) submitTest(w http.ResponseWriter, r *http.Request, studentID domain.StudentID, testID domain.TestID) {
+	submission, err := h.assessments.SubmitTest(r.Context(), studentID, testID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, submissionResponse{
+		TestID:      string(submission.TestID),
+		StudentID:   string(submission.StudentID),
+		SubmittedAt: localTime(submission.SubmittedAt, locationOf(r)),
+	})
+}
@@ -0,0 +1,107 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+type syncResponse struct {
+	Cursor  string               `json:"cursor"`
+	TestIDs []string             `json:"test_ids"`
+	Tests   []syncedTestResponse `json:"tests"`
+	Results []syncedResult       `json:"results"`
+}
+
+type syncedTestResponse struct {
+	testSummary
+	Instructions string             `json:"instructions"`
+	Sections     []sectionResponse  `json:"sections"`
+	Questions    []questionResponse `json:"questions"`
+}
+
+type syncedResult struct {
+	resultResponse
+	TestID     string `json:"test_id"`
+	QuestionID string `json:"question_id"`
+}
+
+// syncStudent serves GET /api/students/{id}/sync?since=, the changes an
+// offline client missed since the cursor of its previous sync. Without since
+// everything is returned. Questions carry no flags: those live in the test
+// session, which is not synced.
+func (h *Handler) syncStudent(w http.ResponseWriter, r *http.Request, studentID domain.StudentID) {
+	var since time.Time
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "since must be a cursor returned by a previous sync")
+			return
+		}
+		since = parsed
+	}
+
+	delta, err := h.assessments.SyncForStudent(r.Context(), studentID, since)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	resp := syncResponse{
+		Cursor:  delta.Cursor.Format(time.RFC3339Nano),
+		TestIDs: make([]string, len(delta.TestIDs)),
+		Tests:   make([]syncedTestResponse, len(delta.Tests)),
+		Results: make([]syncedResult, len(delta.Results)),
+	}
+	for i, id := range delta.TestIDs {
+		resp.TestIDs[i] = string(id)
+	}
+	now := time.Now().UTC()
+	loc := locationOf(r)
+	for i, synced := range delta.Tests {
+		resp.Tests[i] = toSyncedTestResponse(synced, studentID, now, loc)
+	}
+	for i, synced := range delta.Results {
+		res := synced.Result
+		resp.Results[i] = syncedResult{
+			resultResponse: toResultResponse(res, loc),
+			TestID:         string(synced.TestID),
+			QuestionID:     string(synced.QuestionID),
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func toSyncedTestResponse(synced usecase.SyncedTest, studentID domain.StudentID, now time.Time, loc *time.Location) syncedTestResponse {
+	test := synced.Test
+	sections := make([]sectionResponse, len(test.Sections))
+	for i, sec := range test.Sections {
+		sections[i] = sectionResponse{
+			SectionID:    string(sec.ID),
+			Title:        sec.Title,
+			Instructions: sec.Instructions,
+		}
+	}
+	questions := make([]questionResponse, len(synced.Questions))
+	for i, q := range synced.Questions {
+		questions[i] = questionResponse{
+			QuestionID: string(q.ID),
+			SectionID:  string(q.SectionID),
+			Sequence:   q.Sequence,
+			Prompt:     q.Prompt,
+			Points:     int(q.Points),
+			Type:       string(q.AnswerType()),
+			Choices:    toChoiceResponses(q.Choices),
+			Locale:     q.Locale,
+			CreatedAt:  localTime(q.CreatedAt, loc),
+		}
+	}
+	return syncedTestResponse{
+		testSummary:  toTestSummary(test, studentID, now, loc),
+		Instructions: test.Instructions,
+		Sections:     sections,
+		Questions:    questions,
+	}
+}
@@ -0,0 +1,51 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+// timerResponse tells a student how long they have left on a timed test.
+// StartedAt, EndsAt and RemainingSeconds are omitted until they start it.
+type timerResponse struct {
+	DurationMinutes  int        `json:"duration_minutes"`
+	StartedAt        *time.Time `json:"started_at,omitempty"`
+	EndsAt           *time.Time `json:"ends_at,omitempty"`
+	RemainingSeconds *int       `json:"remaining_seconds,omitempty"`
+}
+
+// toTimerResponse describes the student's timer at now, or nil for an
+// untimed test.
+func toTimerResponse(test domain.Test, session domain.TestSession, now time.Time, loc *time.Location) *timerResponse {
+	if _, timed := test.Duration(); !timed {
+		return nil
+	}
+	resp := &timerResponse{DurationMinutes: test.DurationMinutes}
+	if endsAt, ok := session.EndsAt(test); ok {
+		remaining := max(int(endsAt.Sub(now)/time.Second), 0)
+		resp.StartedAt = localTimePtr(session.StartedAt, loc)
+		resp.EndsAt = localTimePtr(&endsAt, loc)
+		resp.RemainingSeconds = &remaining
+	}
+	return resp
+}
+
+func (h *Handler) startTest(w http.ResponseWriter, r *http.Request, studentID domain.StudentID, testID domain.TestID) {
+	session, err := h.assessments.StartTest(r.Context(), studentID, testID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	test, err := h.assessments.GetTestForStudent(r.Context(), studentID, testID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	timer := toTimerResponse(*test, *session, time.Now().UTC(), locationOf(r))
+	if timer == nil {
+		timer = &timerResponse{StartedAt: localTimePtr(session.StartedAt, locationOf(r))}
+	}
+	writeJSON(w, http.StatusOK, timer)
+}
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/httpmw"
+)
+
+// withLocation resolves the zone the student's timestamps are shown in, the
+// X-Timezone header first, then the student's and the school's settings,
+// and stores it in the request for locationOf. The zone is echoed in the
+// response. Timestamps are stored in UTC either way.
+func (h *Handler) withLocation(w http.ResponseWriter, r *http.Request, studentID domain.StudentID) *http.Request {
+	loc, ok := httpmw.LocationFrom(r.Context())
+	if !ok {
+		var err error
+		if loc, err = h.profiles.StudentLocation(r.Context(), studentID); err != nil {
+			loc = time.UTC
+		}
+	}
+	w.Header().Set(httpmw.TimezoneHeader, loc.String())
+	return r.WithContext(httpmw.WithLocation(r.Context(), loc))
+}
+
+// locationOf returns the zone resolved by withLocation, or UTC.
+func locationOf(r *http.Request) *time.Location {
+	if loc, ok := httpmw.LocationFrom(r.Context()); ok {
+		return loc
+	}
+	return time.UTC
+}
+
+// localTime returns a stored timestamp in the reader's zone.
+func localTime(t time.Time, loc *time.Location) time.Time {
+	return t.In(loc)
+}
+
+// localTimePtr is localTime for optional timestamps.
+func localTimePtr(t *time.Time, loc *time.Location) *time.Time {
+	if t == nil {
+		return nil
+	}
+	local := t.In(loc)
+	return &local
+}
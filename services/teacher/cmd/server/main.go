@@ -4,54 +4,392 @@ import (
 	"context"
 	"errors"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/sky0621/go_work_sample/core/pkg/audit"
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
+	"github.com/sky0621/go_work_sample/core/pkg/config"
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/health"
 	"github.com/sky0621/go_work_sample/core/pkg/httpmw"
+	"github.com/sky0621/go_work_sample/core/pkg/id"
+	"github.com/sky0621/go_work_sample/core/pkg/jobs"
+	"github.com/sky0621/go_work_sample/core/pkg/kiosk"
+	"github.com/sky0621/go_work_sample/core/pkg/logfile"
 	"github.com/sky0621/go_work_sample/core/pkg/memory"
-	"github.com/sky0621/go_work_sample/core/pkg/storage/filedb"
+	"github.com/sky0621/go_work_sample/core/pkg/notify"
+	"github.com/sky0621/go_work_sample/core/pkg/openapi"
+	"github.com/sky0621/go_work_sample/core/pkg/ops"
+	"github.com/sky0621/go_work_sample/core/pkg/ratelimit"
+	"github.com/sky0621/go_work_sample/core/pkg/readmodel"
+	"github.com/sky0621/go_work_sample/core/pkg/rpc"
+	"github.com/sky0621/go_work_sample/core/pkg/search"
+	"github.com/sky0621/go_work_sample/core/pkg/storage/blob"
+	"github.com/sky0621/go_work_sample/core/pkg/tracing"
+	"github.com/sky0621/go_work_sample/core/pkg/upload"
 	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+	"github.com/sky0621/go_work_sample/core/pkg/webhook"
+	"github.com/sky0621/go_work_sample/scoring/pkg/analytics"
 	scoring "github.com/sky0621/go_work_sample/scoring/pkg/grading"
 	teacherhttp "github.com/sky0621/go_work_sample/teacher/internal/http"
 )
 
 func main() {
-	addr := envOrDefault("TEACHER_API_ADDR", ":8080")
+	// Listener settings come from flags, the environment or the
+	// configuration file, which the other loaders read as well.
+	serverCfg, err := config.LoadServer(config.ServerDefaults{
+		Name:            "teacher",
+		Addr:            ":8080",
+		GRPCAddr:        ":9090",
+		ReadTimeout:     5 * time.Second,
+		WriteTimeout:    10 * time.Second,
+		ShutdownTimeout: 10 * time.Second,
+	}, os.Args[1:])
+	if err != nil {
+		log.Fatalf("invalid server configuration: %v", err)
+	}
 
-	dataPath := envOrDefault("DATA_STORE_PATH", "./data/state.json")
-	repo, err := filedb.NewRepository(dataPath, memory.SampleSeed())
+	logs, err := logfile.Open(serverCfg.LogFile)
+	if err != nil {
+		log.Fatalf("invalid log configuration: %v", err)
+	}
+	defer logs.Close()
+	logLevel := new(slog.LevelVar)
+	logger := slog.New(slog.NewJSONHandler(logs, &slog.HandlerOptions{Level: logLevel}))
+	slog.SetDefault(logger)
+	logging := httpmw.Logging(httpmw.LoggingConfig{Logger: logger})
+
+	// Runtime settings change on SIGHUP or through the admin reload
+	// endpoint; everything else needs a restart.
+	runtimeCfg, err := config.NewReloader()
+	if err != nil {
+		log.Fatalf("invalid runtime configuration: %v", err)
+	}
+	runtimeCfg.Subscribe(func(c config.Runtime) { logLevel.Set(c.LogLevel) })
+
+	tracingCfg, err := config.LoadTracing("teacher-api")
+	if err != nil {
+		log.Fatalf("invalid tracing configuration: %v", err)
+	}
+	tracer := tracingCfg.Provider()
+	tracing.SetProvider(tracer)
+	traced := httpmw.Tracing()
+	cors := httpmw.CORS(httpmw.CORSConfig{AllowedOrigins: func() []string { return runtimeCfg.Current().AllowedOrigins }})
+	rateLimit := httpmw.RateLimit(httpmw.RateLimitConfig{Rules: func() []httpmw.RateRule { return runtimeCfg.Current().RateLimits.Routes }})
+	deprecationUsage := httpmw.NewDeprecationUsage()
+	deprecated := httpmw.Deprecation(httpmw.DeprecationConfig{Routes: func() []httpmw.DeprecatedRoute { return runtimeCfg.Current().Deprecations }, Usage: deprecationUsage})
+
+	storageCfg, err := config.LoadStorage()
+	if err != nil {
+		log.Fatalf("invalid storage configuration: %v", err)
+	}
+	repo, _, err := storageCfg.Open(memory.SampleSeed())
 	if err != nil {
 		log.Fatalf("failed to initialise repository: %v", err)
 	}
 	assessment := usecase.NewAssessmentService(repo, repo, repo, repo)
-	gradingSvc := scoring.NewService(assessment)
+	assessment.SetSubmissions(repo)
+	assessment.SetSessions(repo)
+	assessment.SetAutograder(scoring.NewEngine())
+	profiles := usecase.NewProfileService(repo)
+	inbox := usecase.NewInboxService(repo)
+
+	authCfg, err := config.LoadAuth()
+	if err != nil {
+		log.Fatalf("invalid auth configuration: %v", err)
+	}
+	signer := auth.NewSigner(authCfg.Secret)
+	rpcCfg, err := config.LoadRPC()
+	if err != nil {
+		log.Fatalf("invalid rpc configuration: %v", err)
+	}
+	var grader scoring.Grader = scoring.NewService(assessment)
+	if rpcCfg.ScoringTarget != "" {
+		// Grading runs in the scoring service, which checks access against
+		// the teacher making the request.
+		grader = scoring.NewRemote(rpc.NewClient(rpcCfg.ScoringTarget, rpc.ClientOptions{
+			Token:   rpc.ForwardPrincipal(signer, rpcCfg.TokenTTL),
+			Timeout: rpcCfg.Timeout,
+		}))
+	}
+
+	statsCfg, err := config.LoadStatistics()
+	if err != nil {
+		log.Fatalf("invalid statistics configuration: %v", err)
+	}
+	assessment.SetStatisticsTTL(statsCfg.CacheTTL)
+
+	readModelCfg, err := config.LoadReadModel()
+	if err != nil {
+		log.Fatalf("invalid read model configuration: %v", err)
+	}
+	var projector *readmodel.Projector
+	if readModelCfg.MaxAge > 0 {
+		projector = readmodel.NewProjector(repo, readModelCfg.MaxAge)
+		assessment.SetReadModel(projector)
+	}
+
+	searchCfg, err := config.LoadSearch()
+	if err != nil {
+		log.Fatalf("invalid search configuration: %v", err)
+	}
+	searcher := search.NewService(search.NewMemoryIndex(), repo, searchCfg.MaxAge)
+	assessment.SetSearch(searcher)
+
+	reminderCfg, err := config.LoadGradingReminders()
+	if err != nil {
+		log.Fatalf("invalid grading reminder configuration: %v", err)
+	}
+
+	schedulingCfg, err := config.LoadScheduling()
+	if err != nil {
+		log.Fatalf("invalid scheduling configuration: %v", err)
+	}
+	assessment.SetDailyTestCapacity(schedulingCfg.MaxTestsPerDay)
+
+	draftLockCfg, err := config.LoadDraftLocks()
+	if err != nil {
+		log.Fatalf("invalid draft lock configuration: %v", err)
+	}
+	assessment.SetDraftLockTTL(draftLockCfg.TTL)
+
+	notifyCfg, err := config.LoadNotify()
+	if err != nil {
+		log.Fatalf("invalid notification configuration: %v", err)
+	}
+	notifier := notify.NewService(notifyCfg.Mailer(), repo, repo)
+	assessment.SetNotifier(notifier)
+	authoring := usecase.NewAuthoringService(repo, repo, repo, notifier)
+	rubrics := usecase.NewRubricService(repo, repo)
+	bank := usecase.NewQuestionBankService(repo, repo, repo, repo)
+	assessment.SetQuestionBank(repo)
+	assessment.SetDelegations(repo)
+	assessment.SetAnswerComments(repo)
+	auditLog := audit.NewLog(repo)
+	assessment.SetAuditLog(auditLog)
+	authoring.SetDelegations(repo)
+	delegations := usecase.NewDelegationService(repo, repo, repo)
+	delegations.SetAuditLog(auditLog)
+
+	delegationCfg, err := config.LoadDelegation()
+	if err != nil {
+		log.Fatalf("invalid delegation configuration: %v", err)
+	}
+	retentionCfg, err := config.LoadRetention()
+	if err != nil {
+		log.Fatalf("invalid retention configuration: %v", err)
+	}
+
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	workers := health.NewRegistry()
+	workers.Go(bgCtx, "config-reload", health.WorkerOptions{}, runtimeCfg.WatchSignals)
+	if tracer != nil {
+		workers.Go(bgCtx, "trace-exporter", health.WorkerOptions{}, func(ctx context.Context) {
+			tracer.Run(ctx, tracingCfg.Interval)
+		})
+	}
+	workers.Go(bgCtx, "notification-digests", health.WorkerOptions{}, func(ctx context.Context) {
+		notifier.RunDigests(ctx, notifyCfg.DigestHour)
+	})
+	workers.Go(bgCtx, "statistics-refresher", health.WorkerOptions{StaleAfter: 3 * statsCfg.RefreshInterval}, func(ctx context.Context) {
+		assessment.RunStatisticsRefresher(ctx, statsCfg.RefreshInterval)
+	})
+	workers.Go(bgCtx, "grading-reminders", health.WorkerOptions{StaleAfter: 3 * reminderCfg.Interval}, func(ctx context.Context) {
+		assessment.RunGradingReminders(ctx, reminderCfg.Interval, reminderCfg.Lead)
+	})
+	workers.Go(bgCtx, "delegation-expiry", health.WorkerOptions{StaleAfter: 3 * delegationCfg.ExpiryInterval}, func(ctx context.Context) {
+		delegations.RunExpiry(ctx, delegationCfg.ExpiryInterval)
+	})
+	workers.Go(bgCtx, "retention", health.WorkerOptions{StaleAfter: 3 * retentionCfg.Interval}, func(ctx context.Context) {
+		assessment.RunRetention(ctx, retentionCfg.Interval)
+	})
+	webhookCfg, err := config.LoadWebhooks()
+	if err != nil {
+		log.Fatalf("invalid webhook configuration: %v", err)
+	}
+	dispatcher := webhookCfg.Dispatcher()
+	assessment.SetWebhooks(dispatcher)
+	assessment.SetQuotas(ratelimit.NewLimiter())
+	workers.Go(bgCtx, "webhook-dispatcher", health.WorkerOptions{
+		Critical:   true,
+		StaleAfter: 3 * webhookCfg.PollInterval,
+		QueueDepth: func() int { return len(dispatcher.Deliveries(webhook.StatusPending)) },
+	}, func(ctx context.Context) {
+		dispatcher.Run(ctx, webhookCfg.PollInterval)
+	})
+
+	brokerCfg, err := config.LoadBroker()
+	if err != nil {
+		log.Fatalf("invalid event broker configuration: %v", err)
+	}
+	eventQueue, err := brokerCfg.Queue()
+	if err != nil {
+		log.Fatalf("invalid event broker configuration: %v", err)
+	}
+	if eventQueue != nil {
+		assessment.SetBroker(eventQueue)
+		workers.Go(bgCtx, "event-publisher", health.WorkerOptions{QueueDepth: eventQueue.Depth}, eventQueue.Run)
+	}
+	jobQueue := jobs.NewQueue(2, 64)
+	workers.Go(bgCtx, "jobs", health.WorkerOptions{Critical: true, QueueDepth: jobQueue.Depth}, jobQueue.Run)
+
+	blobs, err := blob.NewFileStore(config.LoadBlob().Dir)
+	if err != nil {
+		log.Fatalf("failed to initialise blob storage: %v", err)
+	}
+	jobQueue.OnEvict(func(artifact jobs.Artifact) {
+		if artifact.BlobKey == "" {
+			return
+		}
+		if err := blobs.Delete(context.Background(), artifact.BlobKey); err != nil {
+			log.Printf("delete expired job output %s: %v", artifact.BlobKey, err)
+		}
+	})
+	uploadCfg, err := config.LoadUpload()
+	if err != nil {
+		log.Fatalf("invalid upload configuration: %v", err)
+	}
+	uploads := upload.NewManager(blobs, upload.Options{PartSize: uploadCfg.PartSize, MaxSize: uploadCfg.MaxSize, TTL: uploadCfg.TTL})
+
+	kioskCfg, err := config.LoadKiosk()
+	if err != nil {
+		log.Fatalf("invalid kiosk configuration: %v", err)
+	}
+	kioskSettings := teacherhttp.KioskSettings{
+		Signer: kiosk.NewSigner(kioskCfg.Secret),
+		TTL:    kioskCfg.TTL,
+		MaxTTL: kioskCfg.MaxTTL,
+	}
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/health", func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("ok"))
 	})
-	teacherhttp.NewHandler(assessment, gradingSvc).Register(mux)
+	teacherhttp.NewHandler(assessment, profiles, inbox, authoring, rubrics, bank, delegations, grader, analytics.NewService(assessment), searcher, jobQueue, blobs, uploads, kioskSettings).Register(mux)
 
-	teacherKey := envOrDefault("TEACHER_API_KEY", "teacher-secret")
-	authMiddleware := httpmw.APIKey(httpmw.APIKeyConfig{Key: teacherKey, Prefix: "Bearer "})
+	// Sandbox requests run against an in-memory copy of the data without
+	// notifications or webhooks. Every teacher gets a copy of their own,
+	// taken on their first sandbox request and dropped once idle. The
+	// sandboxes share a job queue and a blob namespace apart from the live
+	// ones, and sign kiosk tokens with a key of their own so a sandbox token
+	// never signs a student into live data.
+	sandboxJobs := jobs.NewQueue(1, 16)
+	workers.Go(bgCtx, "sandbox-jobs", health.WorkerOptions{QueueDepth: sandboxJobs.Depth}, sandboxJobs.Run)
+	sandboxBlobs := blob.NewPrefixed(blobs, "sandbox")
+	sandboxJobs.OnEvict(func(artifact jobs.Artifact) {
+		if artifact.BlobKey == "" {
+			return
+		}
+		if err := sandboxBlobs.Delete(context.Background(), artifact.BlobKey); err != nil {
+			log.Printf("delete expired sandbox job output %s: %v", artifact.BlobKey, err)
+		}
+	})
+	sandboxUploads := upload.NewManager(sandboxBlobs, upload.Options{PartSize: uploadCfg.PartSize, MaxSize: uploadCfg.MaxSize, TTL: uploadCfg.TTL})
+	sandboxKiosk := teacherhttp.KioskSettings{
+		Signer: kiosk.NewSigner(id.New()),
+		TTL:    kioskCfg.TTL,
+		MaxTTL: kioskCfg.MaxTTL,
+	}
+	sandboxes := httpmw.NewSandboxPool(func(auth.Principal) (http.Handler, error) {
+		sandboxRepo, err := repo.Sandbox()
+		if err != nil {
+			return nil, err
+		}
+		sandboxAssessment := usecase.NewAssessmentService(sandboxRepo, sandboxRepo, sandboxRepo, sandboxRepo)
+		sandboxAssessment.SetSubmissions(sandboxRepo)
+		sandboxAssessment.SetSessions(sandboxRepo)
+		sandboxAssessment.SetDelegations(sandboxRepo)
+		sandboxAssessment.SetAnswerComments(sandboxRepo)
+		sandboxAssessment.SetQuestionBank(sandboxRepo)
+		sandboxAssessment.SetAutograder(scoring.NewEngine())
+		sandboxSearch := search.NewService(search.NewMemoryIndex(), sandboxRepo, 0)
+		sandboxAssessment.SetSearch(sandboxSearch)
+		sandboxAuthoring := usecase.NewAuthoringService(sandboxRepo, sandboxRepo, sandboxRepo, nil)
+		sandboxAuthoring.SetDelegations(sandboxRepo)
+		sandboxMux := http.NewServeMux()
+		teacherhttp.NewHandler(
+			sandboxAssessment,
+			usecase.NewProfileService(sandboxRepo),
+			usecase.NewInboxService(sandboxRepo),
+			sandboxAuthoring,
+			usecase.NewRubricService(sandboxRepo, sandboxRepo),
+			usecase.NewQuestionBankService(sandboxRepo, sandboxRepo, sandboxRepo, sandboxRepo),
+			usecase.NewDelegationService(sandboxRepo, sandboxRepo, sandboxRepo),
+			scoring.NewService(sandboxAssessment),
+			analytics.NewService(sandboxAssessment),
+			sandboxSearch,
+			sandboxJobs,
+			sandboxBlobs,
+			sandboxUploads,
+			sandboxKiosk,
+		).Register(sandboxMux)
+		return sandboxMux, nil
+	}, httpmw.DefaultSandboxIdle)
+
+	authMiddleware := httpmw.JWT(httpmw.JWTConfig{
+		Signer: signer,
+		Roles:  []domain.Role{domain.RoleTeacher},
+	})
+	adminAuth := httpmw.APIKey(httpmw.APIKeyConfig{Key: serverCfg.AdminKey, Prefix: "Bearer "})
 
-	server := &http.Server{
-		Addr:              addr,
-		Handler:           logMiddleware(authMiddleware(mux)),
-		ReadTimeout:       5 * time.Second,
-		ReadHeaderTimeout: 5 * time.Second,
-		WriteTimeout:      10 * time.Second,
-		IdleTimeout:       120 * time.Second,
+	// Operators flush, reindex, drain and rotate through the runbook
+	// instead of restarting the service; each run is logged.
+	opsCfg, err := config.LoadOps()
+	if err != nil {
+		log.Fatalf("invalid ops configuration: %v", err)
+	}
+	runbook := ops.NewRunbook("teacher-api", logger, opsCfg.Settings())
+	runbook.AddCache("statistics", assessment.FlushStatistics)
+	if projector != nil {
+		runbook.AddCache("read-model", projector.Reset)
+	}
+	runbook.AddIndex("storage", repo.Reindex)
+	runbook.AddIndex("search", func(context.Context) error { return searcher.Reset() })
+	runbook.AddQueue("jobs", jobQueue.Unfinished)
+	if eventQueue != nil {
+		runbook.AddQueue("events", eventQueue.Depth)
 	}
+	runbook.SetLogs(logs)
 
-	errCh := make(chan error, 1)
+	root := http.NewServeMux()
+	root.Handle(health.Path, workers)
+	openapi.Register(root, teacherhttp.OpenAPI())
+	root.Handle(webhook.AdminPrefix, adminAuth(webhook.NewAdminHandler(dispatcher)))
+	root.Handle(teacherhttp.RetentionAdminPrefix, adminAuth(teacherhttp.NewRetentionAdminHandler(assessment)))
+	root.Handle(config.ReloadPath, adminAuth(runtimeCfg))
+	root.Handle(httpmw.DeprecationsPath, adminAuth(deprecationUsage))
+	root.Handle(ops.AdminPrefix, adminAuth(runbook))
+	// Results links are opened by parents without an account.
+	root.Handle(teacherhttp.PublicResultsPrefix, deprecated(rateLimit(teacherhttp.NewPublicResultsHandler(assessment))))
+	root.Handle("/", authMiddleware(deprecated(rateLimit(httpmw.Sandbox(sandboxes)(mux)))))
+
+	server := serverCfg.HTTPServer(traced(logging(cors(httpmw.Timezone()(root)))))
+
+	// Other services call this one over gRPC on a separate HTTP/2 port,
+	// authenticated with the same tokens as the HTTP API. A scoring service
+	// in remote mode grades through it against this service's store.
+	grpcServer := rpc.NewServer()
+	rpc.RegisterAssessment(grpcServer, rpc.NewAssessmentServer(assessment))
+	rpc.RegisterGrading(grpcServer, scoring.RPCServer(scoring.NewService(assessment)))
+	grpcHTTP := rpc.NewHTTPServer(serverCfg.GRPCAddr, traced(logging(authMiddleware(grpcServer))))
+
+	errCh := make(chan error, 2)
 	go func() {
-		log.Printf("teacher-api listening on %s", addr)
-		if err := server.ListenAndServe(); err != nil {
+		log.Printf("teacher-api listening on %s", serverCfg.Addr)
+		if err := serverCfg.ListenAndServe(server); err != nil {
+			errCh <- err
+		}
+	}()
+	go func() {
+		log.Printf("teacher-api serving gRPC on %s", serverCfg.GRPCAddr)
+		if err := grpcHTTP.ListenAndServe(); err != nil {
 			errCh <- err
 		}
 	}()
@@ -59,9 +397,13 @@ func main() {
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
+	exitCode := 0
 	select {
 	case sig := <-sigCh:
 		log.Printf("teacher-api shutting down: %s", sig)
+	case worker := <-workers.Dead():
+		log.Printf("teacher-api shutting down: critical worker %s died", worker)
+		exitCode = 1
 	case err := <-errCh:
 		if !errors.Is(err, http.ErrServerClosed) {
 			log.Fatalf("teacher-api failed: %v", err)
@@ -69,35 +411,18 @@ func main() {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), serverCfg.ShutdownTimeout)
 	defer cancel()
 	if err := server.Shutdown(ctx); err != nil {
 		log.Printf("teacher-api shutdown error: %v", err)
 	}
-}
-
-func envOrDefault(key, fallback string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
+	if err := grpcHTTP.Shutdown(ctx); err != nil {
+		log.Printf("teacher-api gRPC shutdown error: %v", err)
+	}
+	if err := tracer.Flush(ctx); err != nil {
+		log.Printf("teacher-api trace flush error: %v", err)
+	}
+	if exitCode != 0 {
+		os.Exit(exitCode)
 	}
-	return fallback
-}
-
-func logMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		lw := &loggingResponseWriter{ResponseWriter: w, status: http.StatusOK}
-		next.ServeHTTP(lw, r)
-		log.Printf("%s %s %d %s", r.Method, r.URL.Path, lw.status, time.Since(start))
-	})
-}
-
-type loggingResponseWriter struct {
-	http.ResponseWriter
-	status int
-}
-
-func (w *loggingResponseWriter) WriteHeader(statusCode int) {
-	w.status = statusCode
-	w.ResponseWriter.WriteHeader(statusCode)
 }
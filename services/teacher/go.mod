@@ -7,6 +7,8 @@ require (
 	github.com/sky0621/go_work_sample/scoring v0.0.0
 )
 
+require github.com/mattn/go-sqlite3 v1.14.33 // indirect
+
 replace github.com/sky0621/go_work_sample/core => ../core
 
 replace github.com/sky0621/go_work_sample/scoring => ../scoring
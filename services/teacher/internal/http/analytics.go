@@ -0,0 +1,100 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+)
+
+type classAnalyticsResponse struct {
+	ClassID    string                      `json:"class_id"`
+	ClassName  string                      `json:"class_name"`
+	Students   int                         `json:"students"`
+	Tests      []testAnalyticsResponse     `json:"tests"`
+	Hardest    []questionAnalyticsResponse `json:"hardest_questions"`
+	Attention  []studentAttentionResponse  `json:"students_needing_attention"`
+	ComputedAt time.Time                   `json:"computed_at"`
+}
+
+type testAnalyticsResponse struct {
+	TestID       string  `json:"test_id"`
+	Title        string  `json:"title"`
+	Assigned     int     `json:"assigned"`
+	Graded       int     `json:"graded"`
+	MeanScore    float64 `json:"mean_score"`
+	MeanPercent  float64 `json:"mean_percent"`
+	Distribution []int   `json:"distribution"`
+}
+
+type questionAnalyticsResponse struct {
+	TestID     string  `json:"test_id"`
+	QuestionID string  `json:"question_id"`
+	Prompt     string  `json:"prompt"`
+	Points     int     `json:"points"`
+	Answers    int     `json:"answers"`
+	MeanPoints float64 `json:"mean_points"`
+	Share      float64 `json:"share"`
+}
+
+type studentAttentionResponse struct {
+	StudentID   string   `json:"student_id"`
+	Name        string   `json:"name"`
+	MeanPercent *float64 `json:"mean_percent"`
+	Reasons     []string `json:"reasons"`
+}
+
+func (h *Handler) classAnalytics(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, classID domain.ClassID) {
+	report, err := h.analytics.Class(r.Context(), teacherID, classID)
+	if err != nil {
+		if errors.Is(err, errs.ErrClassNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		handleServiceError(w, err)
+		return
+	}
+
+	resp := classAnalyticsResponse{
+		ClassID:    string(report.ClassID),
+		ClassName:  report.ClassName,
+		Students:   report.Students,
+		Tests:      make([]testAnalyticsResponse, len(report.Tests)),
+		Hardest:    make([]questionAnalyticsResponse, len(report.Hardest)),
+		Attention:  make([]studentAttentionResponse, len(report.Attention)),
+		ComputedAt: report.ComputedAt,
+	}
+	for i, t := range report.Tests {
+		resp.Tests[i] = testAnalyticsResponse{
+			TestID:       string(t.TestID),
+			Title:        t.Title,
+			Assigned:     t.Assigned,
+			Graded:       t.Graded,
+			MeanScore:    t.MeanScore,
+			MeanPercent:  t.Mean,
+			Distribution: t.Distribution,
+		}
+	}
+	for i, q := range report.Hardest {
+		resp.Hardest[i] = questionAnalyticsResponse{
+			TestID:     string(q.TestID),
+			QuestionID: string(q.QuestionID),
+			Prompt:     q.Prompt,
+			Points:     int(q.Points),
+			Answers:    q.Answers,
+			MeanPoints: q.MeanPoints,
+			Share:      q.Share,
+		}
+	}
+	for i, a := range report.Attention {
+		resp.Attention[i] = studentAttentionResponse{
+			StudentID:   string(a.StudentID),
+			Name:        a.Name,
+			MeanPercent: a.Mean,
+			Reasons:     a.Reasons,
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,119 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+type answerCommentResponse struct {
+	CommentID  string                  `json:"comment_id"`
+	ParentID   string                  `json:"parent_id,omitempty"`
+	AuthorRole string                  `json:"author_role"`
+	AuthorID   string                  `json:"author_id"`
+	Body       string                  `json:"body"`
+	CreatedAt  time.Time               `json:"created_at"`
+	ReadAt     *time.Time              `json:"read_at,omitempty"`
+	Replies    []answerCommentResponse `json:"replies"`
+}
+
+// routeAnswerComments serves the comment thread on an answer; rest is the
+// path after ".../answers/{answerID}/comments".
+func (h *Handler) routeAnswerComments(w http.ResponseWriter, r *http.Request, role domain.Role, userID string, testID domain.TestID, answerID domain.AnswerID, rest []string) {
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		h.listAnswerComments(w, r, role, userID, testID, answerID)
+	case len(rest) == 0 && r.Method == http.MethodPost:
+		h.addAnswerComment(w, r, role, userID, testID, answerID)
+	case len(rest) == 1 && rest[0] == "read" && r.Method == http.MethodPost:
+		h.markAnswerCommentsRead(w, r, role, userID, testID, answerID)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (h *Handler) listAnswerComments(w http.ResponseWriter, r *http.Request, role domain.Role, userID string, testID domain.TestID, answerID domain.AnswerID) {
+	threads, err := h.assessments.ListAnswerComments(r.Context(), role, userID, testID, answerID)
+	if err != nil {
+		handleAnswerCommentError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"test_id":   string(testID),
+		"answer_id": string(answerID),
+		"comments":  toAnswerCommentThreads(threads),
+	})
+}
+
+func (h *Handler) addAnswerComment(w http.ResponseWriter, r *http.Request, role domain.Role, userID string, testID domain.TestID, answerID domain.AnswerID) {
+	var req struct {
+		ParentID string `json:"parent_id"`
+		Body     string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	comment, err := h.assessments.AddAnswerComment(r.Context(), usecase.AnswerCommentInput{
+		Role:     role,
+		AuthorID: userID,
+		TestID:   testID,
+		AnswerID: answerID,
+		ParentID: domain.AnswerCommentID(strings.TrimSpace(req.ParentID)),
+		Body:     req.Body,
+	})
+	if err != nil {
+		handleAnswerCommentError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toAnswerCommentResponse(*comment, nil))
+}
+
+func (h *Handler) markAnswerCommentsRead(w http.ResponseWriter, r *http.Request, role domain.Role, userID string, testID domain.TestID, answerID domain.AnswerID) {
+	marked, err := h.assessments.MarkAnswerCommentsRead(r.Context(), role, userID, testID, answerID)
+	if err != nil {
+		handleAnswerCommentError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"marked": marked})
+}
+
+// handleAnswerCommentError reports a missing answer as 404; elsewhere in the
+// teacher API it names a bad request.
+func handleAnswerCommentError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errs.ErrAnswerNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	handleServiceError(w, err)
+}
+
+func toAnswerCommentThreads(threads []usecase.AnswerCommentThread) []answerCommentResponse {
+	resp := make([]answerCommentResponse, len(threads))
+	for i, t := range threads {
+		resp[i] = toAnswerCommentResponse(t.Comment, t.Replies)
+	}
+	return resp
+}
+
+func toAnswerCommentResponse(c domain.AnswerComment, replies []usecase.AnswerCommentThread) answerCommentResponse {
+	return answerCommentResponse{
+		CommentID:  string(c.ID),
+		ParentID:   string(c.ParentID),
+		AuthorRole: string(c.AuthorRole),
+		AuthorID:   c.AuthorID,
+		Body:       c.Body,
+		CreatedAt:  c.CreatedAt,
+		ReadAt:     c.ReadAt,
+		Replies:    toAnswerCommentThreads(replies),
+	}
+}
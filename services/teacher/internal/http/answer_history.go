@@ -0,0 +1,77 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+)
+
+// answerRevisionResponse is one version of an answer. The last entry of a
+// history is the current response.
+type answerRevisionResponse struct {
+	Revision  int       `json:"revision"`
+	Response  string    `json:"response"`
+	Note      string    `json:"note,omitempty"`
+	Truncated bool      `json:"truncated,omitempty"`
+	Current   bool      `json:"current,omitempty"`
+	SavedAt   time.Time `json:"saved_at"`
+}
+
+type answerHistoryResponse struct {
+	AnswerID   string                   `json:"answer_id"`
+	QuestionID string                   `json:"question_id"`
+	StudentID  string                   `json:"student_id"`
+	Revisions  []answerRevisionResponse `json:"revisions"`
+}
+
+// getAnswerHistory serves GET .../answers/{questionID}/history?student_id=,
+// showing how a student's response evolved.
+func (h *Handler) getAnswerHistory(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID, questionID domain.QuestionID) {
+	studentID := strings.TrimSpace(r.URL.Query().Get("student_id"))
+	if studentID == "" {
+		writeError(w, http.StatusBadRequest, "student_id is required")
+		return
+	}
+
+	answer, err := h.assessments.AnswerHistory(r.Context(), teacherID, testID, questionID, domain.StudentID(studentID))
+	if err != nil {
+		if errors.Is(err, errs.ErrAnswerNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toAnswerHistoryResponse(*answer, locationOf(r)))
+}
+
+func toAnswerHistoryResponse(answer domain.Answer, loc *time.Location) answerHistoryResponse {
+	revisions := make([]answerRevisionResponse, 0, len(answer.Revisions)+1)
+	for _, rev := range answer.Revisions {
+		revisions = append(revisions, answerRevisionResponse{
+			Revision:  rev.Revision,
+			Response:  rev.Response,
+			Note:      rev.Note,
+			Truncated: rev.Truncated,
+			SavedAt:   localTime(rev.SavedAt, loc),
+		})
+	}
+	revisions = append(revisions, answerRevisionResponse{
+		Revision:  answer.CurrentRevision(),
+		Response:  answer.Response,
+		Note:      answer.Note,
+		Truncated: answer.Truncated,
+		Current:   true,
+		SavedAt:   localTime(answer.UpdatedAt, loc),
+	})
+	return answerHistoryResponse{
+		AnswerID:   string(answer.ID),
+		QuestionID: string(answer.QuestionID),
+		StudentID:  string(answer.StudentID),
+		Revisions:  revisions,
+	}
+}
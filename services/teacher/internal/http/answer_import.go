@@ -0,0 +1,48 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/export"
+)
+
+type answerImportErrorResponse struct {
+	Line   int    `json:"line"`
+	Reason string `json:"reason"`
+}
+
+type answerImportResponse struct {
+	TestID  string                      `json:"test_id"`
+	Created int                         `json:"created"`
+	Updated int                         `json:"updated"`
+	Errors  []answerImportErrorResponse `json:"errors"`
+}
+
+// importAnswers serves POST /api/teachers/{id}/tests/{id}/answers/import. The
+// body is a CSV file with student_id, question and response columns, where
+// question is a question ID or sequence number.
+func (h *Handler) importAnswers(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	rows, err := export.ReadAnswerCSV(r.Body)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	imported, err := h.assessments.ImportAnswers(r.Context(), teacherID, testID, rows)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	resp := answerImportResponse{
+		TestID:  string(testID),
+		Created: imported.Created,
+		Updated: imported.Updated,
+		Errors:  make([]answerImportErrorResponse, len(imported.Errors)),
+	}
+	for i, e := range imported.Errors {
+		resp.Errors[i] = answerImportErrorResponse{Line: e.Line, Reason: e.Reason}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,60 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+type updateAssigneesRequest struct {
+	AddStudentIDs    []string `json:"add_student_ids"`
+	AddClassIDs      []string `json:"add_class_ids"`
+	AddGradeIDs      []string `json:"add_grade_ids"`
+	RemoveStudentIDs []string `json:"remove_student_ids"`
+}
+
+type assigneesResponse struct {
+	TestID     string   `json:"test_id"`
+	StudentIDs []string `json:"student_ids"`
+}
+
+// updateAssignees serves PATCH .../tests/{testID}/assignees, adding students
+// one by one or by class or grade and removing others.
+func (h *Handler) updateAssignees(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	var req updateAssigneesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	test, err := h.assessments.UpdateAssignees(r.Context(), teacherID, testID, usecase.AssigneeChange{
+		AddStudentIDs:    parseIDs[domain.StudentID](req.AddStudentIDs),
+		AddClassIDs:      parseIDs[domain.ClassID](req.AddClassIDs),
+		AddGradeIDs:      parseIDs[domain.GradeID](req.AddGradeIDs),
+		RemoveStudentIDs: parseIDs[domain.StudentID](req.RemoveStudentIDs),
+	})
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	resp := assigneesResponse{TestID: string(test.ID), StudentIDs: make([]string, len(test.AssignedTo))}
+	for i, sid := range test.AssignedTo {
+		resp.StudentIDs[i] = string(sid)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// parseIDs trims the IDs of a request and drops empty ones.
+func parseIDs[T ~string](raw []string) []T {
+	var ids []T
+	for _, id := range raw {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, T(id))
+		}
+	}
+	return ids
+}
@@ -0,0 +1,212 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+type bankQuestionRequest struct {
+	Prompt           string          `json:"prompt"`
+	Points           int             `json:"points"`
+	Difficulty       string          `json:"difficulty"`
+	Type             string          `json:"type"`
+	Choices          []choicePayload `json:"choices"`
+	ExpectedResponse string          `json:"expected_response"`
+	Tags             []string        `json:"tags"`
+	Shared           bool            `json:"shared"`
+}
+
+type bankQuestionResponse struct {
+	BankQuestionID   string          `json:"bank_question_id"`
+	TeacherID        string          `json:"teacher_id"`
+	Prompt           string          `json:"prompt"`
+	Points           int             `json:"points"`
+	Difficulty       string          `json:"difficulty,omitempty"`
+	Type             string          `json:"type"`
+	Choices          []choicePayload `json:"choices,omitempty"`
+	ExpectedResponse string          `json:"expected_response,omitempty"`
+	Tags             []string        `json:"tags"`
+	Shared           bool            `json:"shared"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+}
+
+func (req bankQuestionRequest) input(teacherID domain.TeacherID) usecase.BankQuestionInput {
+	return usecase.BankQuestionInput{
+		TeacherID:        teacherID,
+		Prompt:           req.Prompt,
+		Points:           domain.Points(req.Points),
+		Difficulty:       domain.Difficulty(req.Difficulty),
+		Type:             domain.QuestionType(strings.ToLower(strings.TrimSpace(req.Type))),
+		Choices:          toDomainChoices(req.Choices),
+		ExpectedResponse: strings.TrimSpace(req.ExpectedResponse),
+		Tags:             req.Tags,
+		Shared:           req.Shared,
+	}
+}
+
+func toBankQuestionResponse(q domain.BankQuestion) bankQuestionResponse {
+	tags := q.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return bankQuestionResponse{
+		BankQuestionID:   string(q.ID),
+		TeacherID:        string(q.TeacherID),
+		Prompt:           q.Prompt,
+		Points:           int(q.Points),
+		Difficulty:       string(q.Difficulty),
+		Type:             string(q.Type),
+		Choices:          toChoicePayloads(q.Choices),
+		ExpectedResponse: q.ExpectedResponse,
+		Tags:             tags,
+		Shared:           q.Shared,
+		CreatedAt:        q.CreatedAt,
+		UpdatedAt:        q.UpdatedAt,
+	}
+}
+
+// routeBank serves the teacher's question bank under /questions:
+//
+//	GET    .../questions                 search the bank
+//	POST   .../questions                 add a question
+//	GET    .../questions/pool            search the school's shared questions
+//	GET    .../questions/usage           report how exposed bank questions are
+//	GET    .../questions/{questionID}    show a question
+//	PATCH  .../questions/{questionID}    replace a question's content
+//	DELETE .../questions/{questionID}    remove a question
+func (h *Handler) routeBank(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, parts []string) {
+	switch {
+	case len(parts) == 2 && r.Method == http.MethodGet:
+		h.searchBankQuestions(w, r, teacherID, h.bank.SearchBankQuestions)
+	case len(parts) == 3 && parts[2] == "pool" && r.Method == http.MethodGet:
+		h.searchBankQuestions(w, r, teacherID, h.bank.SearchQuestionPool)
+	case len(parts) == 3 && parts[2] == "usage" && r.Method == http.MethodGet:
+		h.questionUsage(w, r, teacherID)
+	case len(parts) == 2 && r.Method == http.MethodPost:
+		h.createBankQuestion(w, r, teacherID)
+	case len(parts) == 3 && r.Method == http.MethodGet:
+		question, err := h.bank.GetBankQuestion(r.Context(), teacherID, domain.BankQuestionID(parts[2]))
+		if err != nil {
+			handleServiceError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, toBankQuestionResponse(*question))
+	case len(parts) == 3 && r.Method == http.MethodPatch:
+		h.updateBankQuestion(w, r, teacherID, domain.BankQuestionID(parts[2]))
+	case len(parts) == 3 && r.Method == http.MethodDelete:
+		if err := h.bank.DeleteBankQuestion(r.Context(), teacherID, domain.BankQuestionID(parts[2])); err != nil {
+			handleServiceError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case len(parts) <= 3:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (h *Handler) createBankQuestion(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID) {
+	var req bankQuestionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+	question, err := h.bank.CreateBankQuestion(r.Context(), req.input(teacherID))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, toBankQuestionResponse(*question))
+}
+
+func (h *Handler) updateBankQuestion(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, questionID domain.BankQuestionID) {
+	var req bankQuestionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+	question, err := h.bank.UpdateBankQuestion(r.Context(), questionID, req.input(teacherID))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toBankQuestionResponse(*question))
+}
+
+// bankSearch is a search of the teacher's bank or of the school's pool.
+type bankSearch func(ctx context.Context, teacherID domain.TeacherID, query usecase.BankQuery, page repository.PageRequest) (repository.Page[domain.BankQuestion], error)
+
+// searchBankQuestions filters the bank by ?q= text in the prompt, ?tag= tags
+// that must all be present (repeated or comma-separated), ?difficulty= and
+// ?type=.
+func (h *Handler) searchBankQuestions(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, search bankSearch) {
+	page, err := parsePageRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	query := r.URL.Query()
+	filter := usecase.BankQuery{
+		Text:       query.Get("q"),
+		Difficulty: domain.Difficulty(query.Get("difficulty")),
+		Type:       domain.QuestionType(query.Get("type")),
+	}
+	for _, tags := range query["tag"] {
+		filter.Tags = append(filter.Tags, strings.Split(tags, ",")...)
+	}
+
+	questions, err := search(r.Context(), teacherID, filter, page)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	payload := make([]bankQuestionResponse, len(questions.Items))
+	for i, q := range questions.Items {
+		payload[i] = toBankQuestionResponse(q)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"questions": payload,
+		"page":      toPageInfo(page, questions.NextCursor),
+	})
+}
+
+// bankQuestionUsageResponse is one entry of GET .../questions/usage.
+// Facility is omitted until an answer to the question is graded.
+type bankQuestionUsageResponse struct {
+	Question bankQuestionResponse `json:"question"`
+	Tests    int                  `json:"tests"`
+	Students int                  `json:"students"`
+	Graded   int                  `json:"graded"`
+	Facility *float64             `json:"facility,omitempty"`
+}
+
+func (h *Handler) questionUsage(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID) {
+	report, err := h.bank.QuestionUsage(r.Context(), teacherID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	payload := make([]bankQuestionUsageResponse, len(report))
+	for i, usage := range report {
+		payload[i] = bankQuestionUsageResponse{
+			Question: toBankQuestionResponse(usage.Question),
+			Tests:    usage.Tests,
+			Students: usage.Students,
+			Graded:   usage.Graded,
+		}
+		if usage.Graded > 0 {
+			facility := usage.Facility
+			payload[i].Facility = &facility
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"questions": payload})
+}
@@ -0,0 +1,35 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+// certificatesRequest turns completion certificates on or off for a test.
+// Turning them on needs a passing score.
+type certificatesRequest struct {
+	Certificates bool `json:"certificates"`
+}
+
+func (h *Handler) setCertificates(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	var req certificatesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	test, err := h.assessments.SetCertificates(r.Context(), teacherID, testID, req.Certificates)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	questions, err := h.assessments.GetQuestionsForTeacher(r.Context(), teacherID, testID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toTestResponse(*test, questions, locationOf(r)))
+}
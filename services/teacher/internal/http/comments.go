@@ -0,0 +1,84 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+type commentResponse struct {
+	CommentID string            `json:"comment_id"`
+	ParentID  string            `json:"parent_id,omitempty"`
+	AuthorID  string            `json:"author_id"`
+	Body      string            `json:"body"`
+	Mentions  []string          `json:"mentions"`
+	CreatedAt time.Time         `json:"created_at"`
+	Replies   []commentResponse `json:"replies"`
+}
+
+func (h *Handler) listComments(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID, questionID domain.QuestionID) {
+	threads, err := h.authoring.ListComments(r.Context(), teacherID, testID, questionID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"test_id":     string(testID),
+		"question_id": string(questionID),
+		"comments":    toCommentThreads(threads),
+	})
+}
+
+func (h *Handler) addComment(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID, questionID domain.QuestionID) {
+	var req struct {
+		ParentID string `json:"parent_id"`
+		Body     string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	comment, err := h.authoring.AddComment(r.Context(), usecase.CommentInput{
+		TeacherID:  teacherID,
+		TestID:     testID,
+		QuestionID: questionID,
+		ParentID:   domain.QuestionCommentID(strings.TrimSpace(req.ParentID)),
+		Body:       req.Body,
+	})
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toCommentResponse(*comment, nil))
+}
+
+func toCommentThreads(threads []usecase.CommentThread) []commentResponse {
+	resp := make([]commentResponse, len(threads))
+	for i, t := range threads {
+		resp[i] = toCommentResponse(t.Comment, t.Replies)
+	}
+	return resp
+}
+
+func toCommentResponse(c domain.QuestionComment, replies []usecase.CommentThread) commentResponse {
+	resp := commentResponse{
+		CommentID: string(c.ID),
+		ParentID:  string(c.ParentID),
+		AuthorID:  string(c.AuthorID),
+		Body:      c.Body,
+		Mentions:  make([]string, len(c.Mentions)),
+		CreatedAt: c.CreatedAt,
+		Replies:   toCommentThreads(replies),
+	}
+	for i, m := range c.Mentions {
+		resp.Mentions[i] = string(m)
+	}
+	return resp
+}
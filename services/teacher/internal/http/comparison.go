@@ -0,0 +1,82 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+type comparisonResponse struct {
+	Groups []comparisonGroupResponse `json:"groups"`
+	Hints  []significanceResponse    `json:"hints"`
+}
+
+type comparisonGroupResponse struct {
+	TestID    string  `json:"test_id"`
+	ClassID   string  `json:"class_id,omitempty"`
+	Students  int     `json:"students"`
+	Mean      float64 `json:"mean_percent"`
+	StdDev    float64 `json:"stddev_percent"`
+	Histogram []int   `json:"histogram"`
+}
+
+type significanceResponse struct {
+	Group       int     `json:"group"`
+	Difference  float64 `json:"difference"`
+	EffectSize  float64 `json:"effect_size"`
+	PValue      float64 `json:"p_value"`
+	Significant bool    `json:"significant"`
+}
+
+// compareScores compares the test with the equivalent tests listed in with,
+// and splits each by class when by is "class".
+func (h *Handler) compareScores(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	query := r.URL.Query()
+	input := usecase.CompareInput{TeacherID: teacherID, Tests: []domain.TestID{testID}}
+	for _, other := range strings.Split(query.Get("with"), ",") {
+		if other = strings.TrimSpace(other); other != "" {
+			input.Tests = append(input.Tests, domain.TestID(other))
+		}
+	}
+	switch query.Get("by") {
+	case "", "test":
+	case "class":
+		input.ByClass = true
+	default:
+		writeError(w, http.StatusBadRequest, "by must be test or class")
+		return
+	}
+
+	comparison, err := h.assessments.CompareScores(r.Context(), input)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	resp := comparisonResponse{
+		Groups: make([]comparisonGroupResponse, len(comparison.Groups)),
+		Hints:  make([]significanceResponse, len(comparison.Hints)),
+	}
+	for i, g := range comparison.Groups {
+		resp.Groups[i] = comparisonGroupResponse{
+			TestID:    string(g.TestID),
+			ClassID:   string(g.ClassID),
+			Students:  g.Students,
+			Mean:      g.Mean,
+			StdDev:    g.StdDev,
+			Histogram: g.Histogram,
+		}
+	}
+	for i, hint := range comparison.Hints {
+		resp.Hints[i] = significanceResponse{
+			Group:       hint.Group,
+			Difference:  hint.Difference,
+			EffectSize:  hint.EffectSize,
+			PValue:      hint.PValue,
+			Significant: hint.Significant,
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,68 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+type questionDifficultyRequest struct {
+	Difficulty string `json:"difficulty"`
+}
+
+type composeTestRequest struct {
+	Title        string         `json:"title"`
+	TotalPoints  int            `json:"total_points"`
+	Distribution map[string]int `json:"distribution"`
+	StudentIDs   []string       `json:"student_ids"`
+	Draft        bool           `json:"draft"`
+}
+
+func (h *Handler) setQuestionDifficulty(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID, questionID domain.QuestionID) {
+	var req questionDifficultyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	q, err := h.assessments.SetQuestionDifficulty(r.Context(), teacherID, testID, questionID, domain.Difficulty(strings.ToLower(strings.TrimSpace(req.Difficulty))))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toQuestionResponse(*q))
+}
+
+func (h *Handler) composeTest(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID) {
+	var req composeTestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	input := usecase.ComposeInput{
+		TeacherID:    teacherID,
+		Title:        strings.TrimSpace(req.Title),
+		TotalPoints:  domain.Points(req.TotalPoints),
+		Distribution: make(map[domain.Difficulty]int, len(req.Distribution)),
+		Draft:        req.Draft,
+	}
+	for d, pct := range req.Distribution {
+		input.Distribution[domain.Difficulty(strings.ToLower(d))] = pct
+	}
+	for _, sid := range req.StudentIDs {
+		input.StudentIDs = append(input.StudentIDs, domain.StudentID(strings.TrimSpace(sid)))
+	}
+
+	test, questions, err := h.assessments.ComposeTest(r.Context(), input)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toTestResponse(*test, questions, locationOf(r)))
+}
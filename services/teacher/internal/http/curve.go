@@ -0,0 +1,98 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+type curveRequest struct {
+	Kind    string      `json:"kind"`
+	Points  int         `json:"points"`
+	Mapping map[int]int `json:"mapping"`
+}
+
+type curveResponse struct {
+	Kind      string      `json:"kind"`
+	Points    int         `json:"points,omitempty"`
+	Mapping   map[int]int `json:"mapping,omitempty"`
+	AppliedBy string      `json:"applied_by"`
+	AppliedAt time.Time   `json:"applied_at"`
+}
+
+func (h *Handler) applyCurve(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	var req curveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	test, err := h.assessments.ApplyCurve(r.Context(), usecase.CurveInput{
+		TeacherID: teacherID,
+		TestID:    testID,
+		Kind:      domain.CurveKind(req.Kind),
+		Points:    domain.Score(req.Points),
+		Mapping:   toScoreMapping(req.Mapping),
+	})
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	h.writeTest(w, r, teacherID, test)
+}
+
+func (h *Handler) revertCurve(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	test, err := h.assessments.RevertCurve(r.Context(), teacherID, testID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	h.writeTest(w, r, teacherID, test)
+}
+
+func (h *Handler) writeTest(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, test *domain.Test) {
+	questions, err := h.assessments.GetQuestionsForTeacher(r.Context(), teacherID, test.ID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toTestResponse(*test, questions, locationOf(r)))
+}
+
+func toCurveResponse(curve *domain.Curve) *curveResponse {
+	if curve == nil {
+		return nil
+	}
+	return &curveResponse{
+		Kind:      string(curve.Kind),
+		Points:    int(curve.Points),
+		Mapping:   fromScoreMapping(curve.Mapping),
+		AppliedBy: string(curve.AppliedBy),
+		AppliedAt: curve.AppliedAt,
+	}
+}
+
+func toScoreMapping(mapping map[int]int) map[domain.Score]domain.Score {
+	if mapping == nil {
+		return nil
+	}
+	out := make(map[domain.Score]domain.Score, len(mapping))
+	for from, to := range mapping {
+		out[domain.Score(from)] = domain.Score(to)
+	}
+	return out
+}
+
+func fromScoreMapping(mapping map[domain.Score]domain.Score) map[int]int {
+	if mapping == nil {
+		return nil
+	}
+	out := make(map[int]int, len(mapping))
+	for from, to := range mapping {
+		out[int(from)] = int(to)
+	}
+	return out
+}
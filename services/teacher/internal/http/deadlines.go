@@ -0,0 +1,77 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+type gradingDeadlineRequest struct {
+	GradingDeadline *time.Time `json:"grading_deadline"`
+}
+
+type gradingBacklogResponse struct {
+	TestID          string    `json:"test_id"`
+	Title           string    `json:"title"`
+	GradingDeadline time.Time `json:"grading_deadline"`
+	Ungraded        int       `json:"ungraded"`
+	Overdue         bool      `json:"overdue"`
+}
+
+// defaultBacklogWindow is how far ahead the grading backlog looks when the
+// request does not say.
+const defaultBacklogWindow = 48 * time.Hour
+
+func (h *Handler) setGradingDeadline(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	var req gradingDeadlineRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	test, err := h.assessments.SetGradingDeadline(r.Context(), teacherID, testID, req.GradingDeadline)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	questions, err := h.assessments.GetQuestionsForTeacher(r.Context(), teacherID, testID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toTestResponse(*test, questions, locationOf(r)))
+}
+
+func (h *Handler) listGradingBacklog(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID) {
+	within := defaultBacklogWindow
+	if raw := r.URL.Query().Get("within"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < 0 {
+			writeError(w, http.StatusBadRequest, "within must be a non-negative duration such as 48h")
+			return
+		}
+		within = d
+	}
+
+	backlog, err := h.assessments.ListGradingBacklog(r.Context(), teacherID, within)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	loc := locationOf(r)
+	payload := make([]gradingBacklogResponse, len(backlog))
+	for i, item := range backlog {
+		payload[i] = gradingBacklogResponse{
+			TestID:          string(item.Test.ID),
+			Title:           item.Test.Title,
+			GradingDeadline: localTime(*item.Test.GradingDeadline, loc),
+			Ungraded:        item.Ungraded,
+			Overdue:         item.Overdue,
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"tests": payload})
+}
@@ -0,0 +1,127 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+type delegationRequest struct {
+	DelegateID string    `json:"delegate_id"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+type delegationResponse struct {
+	DelegationID string    `json:"delegation_id"`
+	TeacherID    string    `json:"teacher_id"`
+	DelegateID   string    `json:"delegate_id"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	Active       bool      `json:"active"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (h *Handler) grantDelegation(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID) {
+	var req delegationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	delegation, err := h.delegations.Grant(r.Context(), usecase.DelegationInput{
+		TeacherID:  teacherID,
+		DelegateID: domain.TeacherID(req.DelegateID),
+		ExpiresAt:  req.ExpiresAt,
+	})
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, toDelegationResponse(*delegation, time.Now()))
+}
+
+func (h *Handler) listDelegations(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID) {
+	page, err := parsePageRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	delegations, err := h.delegations.ListGranted(r.Context(), teacherID, page)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	now := time.Now()
+	payload := make([]delegationResponse, len(delegations.Items))
+	for i, d := range delegations.Items {
+		payload[i] = toDelegationResponse(d, now)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"delegations": payload,
+		"page":        toPageInfo(page, delegations.NextCursor),
+	})
+}
+
+func (h *Handler) revokeDelegation(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, delegationID domain.DelegationID) {
+	if err := h.delegations.Revoke(r.Context(), teacherID, delegationID); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) listReceivedDelegations(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID) {
+	delegations, err := h.delegations.ListReceived(r.Context(), teacherID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	now := time.Now()
+	payload := make([]delegationResponse, len(delegations))
+	for i, d := range delegations {
+		payload[i] = toDelegationResponse(d, now)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"delegations": payload})
+}
+
+func (h *Handler) listDelegatedTests(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID) {
+	page, err := parsePageRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	tests, err := h.delegations.ListDelegatedTests(r.Context(), teacherID, page)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	payload := make([]testResponse, 0, len(tests.Items))
+	for _, test := range tests.Items {
+		questions, qErr := h.assessments.GetQuestionsForTeacher(r.Context(), teacherID, test.ID)
+		if qErr != nil {
+			handleServiceError(w, qErr)
+			return
+		}
+		payload = append(payload, toTestResponse(test, questions, locationOf(r)))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"tests": payload,
+		"page":  toPageInfo(page, tests.NextCursor),
+	})
+}
+
+func toDelegationResponse(d domain.Delegation, now time.Time) delegationResponse {
+	return delegationResponse{
+		DelegationID: string(d.ID),
+		TeacherID:    string(d.TeacherID),
+		DelegateID:   string(d.DelegateID),
+		ExpiresAt:    d.ExpiresAt,
+		Active:       d.Active(now),
+		CreatedAt:    d.CreatedAt,
+	}
+}
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+type choiceSelectionResponse struct {
+	Key     string  `json:"key"`
+	Label   string  `json:"label"`
+	Correct bool    `json:"correct"`
+	Count   int     `json:"count"`
+	Share   float64 `json:"share"`
+}
+
+type distractorAnalysisResponse struct {
+	TestID       string                    `json:"test_id"`
+	QuestionID   string                    `json:"question_id"`
+	Answers      int                       `json:"answers"`
+	Choices      []choiceSelectionResponse `json:"choices"`
+	Distractors  []choiceSelectionResponse `json:"distractors"`
+	Unrecognized int                       `json:"unrecognized"`
+	ComputedAt   time.Time                 `json:"computed_at"`
+}
+
+func (h *Handler) getDistractors(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID, questionID domain.QuestionID) {
+	analysis, err := h.assessments.QuestionDistractors(r.Context(), teacherID, testID, questionID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, distractorAnalysisResponse{
+		TestID:       string(analysis.TestID),
+		QuestionID:   string(analysis.QuestionID),
+		Answers:      analysis.Answers,
+		Choices:      toChoiceSelectionResponses(analysis.Choices),
+		Distractors:  toChoiceSelectionResponses(analysis.Distractors),
+		Unrecognized: analysis.Unrecognized,
+		ComputedAt:   analysis.ComputedAt,
+	})
+}
+
+func toChoiceSelectionResponses(choices []usecase.ChoiceSelection) []choiceSelectionResponse {
+	out := make([]choiceSelectionResponse, len(choices))
+	for i, c := range choices {
+		out[i] = choiceSelectionResponse{Key: c.Key, Label: c.Label, Correct: c.Correct, Count: c.Count, Share: c.Share}
+	}
+	return out
+}
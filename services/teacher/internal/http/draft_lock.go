@@ -0,0 +1,68 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+type draftLockResponse struct {
+	TestID     string    `json:"test_id"`
+	LockedBy   string    `json:"locked_by"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+func toDraftLockResponse(lock usecase.DraftLock) draftLockResponse {
+	return draftLockResponse{
+		TestID:     string(lock.TestID),
+		LockedBy:   string(lock.TeacherID),
+		AcquiredAt: lock.AcquiredAt,
+		ExpiresAt:  lock.ExpiresAt,
+	}
+}
+
+func (h *Handler) getDraftLock(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	lock, err := h.assessments.GetDraftLock(r.Context(), teacherID, testID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	if lock == nil {
+		writeJSON(w, http.StatusOK, map[string]any{"locked": false})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"locked": true, "lock": toDraftLockResponse(*lock)})
+}
+
+// lockDraft takes or renews the teacher's lock on a draft. A lock held by a
+// co-teacher is answered with 409 naming them, unless steal=true.
+func (h *Handler) lockDraft(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	steal := false
+	if raw := r.URL.Query().Get("steal"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "steal must be true or false")
+			return
+		}
+		steal = parsed
+	}
+
+	lock, err := h.assessments.LockDraft(r.Context(), teacherID, testID, steal)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toDraftLockResponse(*lock))
+}
+
+func (h *Handler) unlockDraft(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	if err := h.assessments.UnlockDraft(r.Context(), teacherID, testID); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,34 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+// durationRequest times a test; zero minutes makes it untimed.
+type durationRequest struct {
+	DurationMinutes int `json:"duration_minutes"`
+}
+
+func (h *Handler) setTestDuration(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	var req durationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	test, err := h.assessments.SetTestDuration(r.Context(), teacherID, testID, req.DurationMinutes)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	questions, err := h.assessments.GetQuestionsForTeacher(r.Context(), teacherID, testID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toTestResponse(*test, questions, locationOf(r)))
+}
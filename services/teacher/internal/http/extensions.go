@@ -0,0 +1,114 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+type extendTestRequest struct {
+	ClosesAt time.Time `json:"closes_at"`
+	Reason   string    `json:"reason"`
+}
+
+type grantExtensionsRequest struct {
+	StudentIDs []string  `json:"student_ids"`
+	ClosesAt   time.Time `json:"closes_at"`
+	Reason     string    `json:"reason"`
+}
+
+type extensionsResponse struct {
+	TestID     string              `json:"test_id"`
+	ClosesAt   *time.Time          `json:"closes_at,omitempty"`
+	Extensions []extensionResponse `json:"extensions"`
+}
+
+type extensionResponse struct {
+	StudentID string    `json:"student_id"`
+	ClosesAt  time.Time `json:"closes_at"`
+	Reason    string    `json:"reason"`
+	GrantedBy string    `json:"granted_by"`
+	GrantedAt time.Time `json:"granted_at"`
+}
+
+// routeExtensions serves /api/teachers/{id}/tests/{testID}/extensions: GET
+// lists the students given longer, POST grants extensions and DELETE
+// {studentID} revokes one.
+func (h *Handler) routeExtensions(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID, parts []string) {
+	switch {
+	case len(parts) == 4 && r.Method == http.MethodGet:
+		test, err := h.assessments.GetTestForTeacher(r.Context(), teacherID, testID)
+		if err != nil {
+			handleServiceError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, toExtensionsResponse(*test, locationOf(r)))
+	case len(parts) == 4 && r.Method == http.MethodPost:
+		h.grantExtensions(w, r, teacherID, testID)
+	case len(parts) == 5 && r.Method == http.MethodDelete:
+		test, err := h.assessments.RevokeExtension(r.Context(), teacherID, testID, domain.StudentID(parts[4]))
+		if err != nil {
+			handleServiceError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, toExtensionsResponse(*test, locationOf(r)))
+	case len(parts) <= 5:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// extendTest moves the test's close time later for every student.
+func (h *Handler) extendTest(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	var req extendTestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+	test, err := h.assessments.ExtendTest(r.Context(), teacherID, testID, req.ClosesAt, req.Reason)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toExtensionsResponse(*test, locationOf(r)))
+}
+
+func (h *Handler) grantExtensions(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	var req grantExtensionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+	input := usecase.ExtensionInput{ClosesAt: req.ClosesAt, Reason: req.Reason}
+	for _, studentID := range req.StudentIDs {
+		input.StudentIDs = append(input.StudentIDs, domain.StudentID(studentID))
+	}
+	test, err := h.assessments.GrantExtensions(r.Context(), teacherID, testID, input)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toExtensionsResponse(*test, locationOf(r)))
+}
+
+func toExtensionsResponse(test domain.Test, loc *time.Location) extensionsResponse {
+	resp := extensionsResponse{
+		TestID:     string(test.ID),
+		ClosesAt:   localTimePtr(test.ClosesAt, loc),
+		Extensions: make([]extensionResponse, len(test.Extensions)),
+	}
+	for i, ext := range test.Extensions {
+		resp.Extensions[i] = extensionResponse{
+			StudentID: string(ext.StudentID),
+			ClosesAt:  localTime(ext.ClosesAt, loc),
+			Reason:    ext.Reason,
+			GrantedBy: string(ext.GrantedBy),
+			GrantedAt: localTime(ext.GrantedAt, loc),
+		}
+	}
+	return resp
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/export"
+)
+
+// exportGradeSheet streams the grade sheet of a test as a CSV download.
+// Only format=csv, the default, is supported.
+func (h *Handler) exportGradeSheet(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	if format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format"))); format != "" && format != "csv" {
+		handleServiceError(w, errs.ErrUnsupportedFormat)
+		return
+	}
+
+	download := &downloadWriter{
+		w:           w,
+		contentType: export.ContentTypeCSV + "; charset=utf-8",
+		filename:    "grades-" + string(testID) + ".csv",
+	}
+	if err := h.assessments.ExportGradeSheet(r.Context(), teacherID, testID, download); err != nil {
+		if !download.started {
+			handleServiceError(w, err)
+			return
+		}
+		// The status is already sent, so the truncated sheet is all the
+		// client gets.
+		log.Printf("export grade sheet of test %s: %v", testID, err)
+	}
+}
+
+// downloadWriter sends attachment headers with the first write, so a request
+// that fails before producing output still gets a JSON error.
+type downloadWriter struct {
+	w           http.ResponseWriter
+	contentType string
+	filename    string
+	started     bool
+}
+
+func (d *downloadWriter) Write(p []byte) (int, error) {
+	if !d.started {
+		d.started = true
+		d.w.Header().Set("Content-Type", d.contentType)
+		d.w.Header().Set("Content-Disposition", `attachment; filename="`+d.filename+`"`)
+		d.w.WriteHeader(http.StatusOK)
+	}
+	return d.w.Write(p)
+}
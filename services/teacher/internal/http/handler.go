@@ -1,26 +1,81 @@
 package http
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/sky0621/go_work_sample/core/pkg/auth"
 	"github.com/sky0621/go_work_sample/core/pkg/domain"
 	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/export"
+	"github.com/sky0621/go_work_sample/core/pkg/id"
+	"github.com/sky0621/go_work_sample/core/pkg/jobs"
+	"github.com/sky0621/go_work_sample/core/pkg/repository"
+	"github.com/sky0621/go_work_sample/core/pkg/search"
+	"github.com/sky0621/go_work_sample/core/pkg/storage/blob"
+	"github.com/sky0621/go_work_sample/core/pkg/upload"
 	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+	"github.com/sky0621/go_work_sample/scoring/pkg/analytics"
 	"github.com/sky0621/go_work_sample/scoring/pkg/grading"
 )
 
 // Handler exposes teacher-facing endpoints.
 type Handler struct {
 	assessments *usecase.AssessmentService
-	grading     *grading.Service
+	profiles    *usecase.ProfileService
+	inbox       *usecase.InboxService
+	authoring   *usecase.AuthoringService
+	rubrics     *usecase.RubricService
+	bank        *usecase.QuestionBankService
+	delegations *usecase.DelegationService
+	grading     grading.Grader
+	analytics   *analytics.Service
+	search      *search.Service
+	jobs        *jobs.Queue
+	blobs       blob.Store
+	uploads     *upload.Manager
+	kiosk       KioskSettings
 }
 
 // NewHandler builds a handler with required services.
-func NewHandler(assessments *usecase.AssessmentService, grading *grading.Service) *Handler {
-	return &Handler{assessments: assessments, grading: grading}
+func NewHandler(
+	assessments *usecase.AssessmentService,
+	profiles *usecase.ProfileService,
+	inbox *usecase.InboxService,
+	authoring *usecase.AuthoringService,
+	rubrics *usecase.RubricService,
+	bank *usecase.QuestionBankService,
+	delegations *usecase.DelegationService,
+	grading grading.Grader,
+	analytics *analytics.Service,
+	search *search.Service,
+	jobs *jobs.Queue,
+	blobs blob.Store,
+	uploads *upload.Manager,
+	kiosk KioskSettings,
+) *Handler {
+	return &Handler{
+		assessments: assessments,
+		profiles:    profiles,
+		inbox:       inbox,
+		authoring:   authoring,
+		rubrics:     rubrics,
+		bank:        bank,
+		delegations: delegations,
+		grading:     grading,
+		analytics:   analytics,
+		search:      search,
+		jobs:        jobs,
+		blobs:       blobs,
+		uploads:     uploads,
+		kiosk:       kiosk,
+	}
 }
 
 // Register wires HTTP endpoints.
@@ -36,6 +91,38 @@ func (h *Handler) route(w http.ResponseWriter, r *http.Request) {
 	}
 
 	teacherID := domain.TeacherID(parts[0])
+	if !auth.IsTeacher(r.Context(), teacherID) {
+		writeError(w, http.StatusForbidden, errs.ErrForbiddenTeacher.Error())
+		return
+	}
+	r = h.withLocation(w, r, teacherID)
+
+	if len(parts) == 2 && parts[1] == "profile" {
+		switch r.Method {
+		case http.MethodGet:
+			h.getProfile(w, r, teacherID)
+			return
+		case http.MethodPatch:
+			h.updateProfile(w, r, teacherID)
+			return
+		}
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if len(parts) >= 2 && parts[1] == "notifications" {
+		switch {
+		case len(parts) == 2 && r.Method == http.MethodGet:
+			h.listNotifications(w, r, teacherID)
+			return
+		case len(parts) == 3 && parts[2] == "read" && r.Method == http.MethodPost:
+			h.markNotificationsRead(w, r, teacherID)
+			return
+		case len(parts) <= 3:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+	}
 
 	if len(parts) == 2 && parts[1] == "tests" {
 		switch r.Method {
@@ -50,28 +137,278 @@ func (h *Handler) route(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if len(parts) >= 2 && parts[1] == "rubrics" {
+		switch {
+		case len(parts) == 2 && r.Method == http.MethodGet:
+			h.listRubrics(w, r, teacherID)
+			return
+		case len(parts) == 2 && r.Method == http.MethodPost:
+			h.createRubric(w, r, teacherID)
+			return
+		case len(parts) == 3 && parts[2] == "export" && r.Method == http.MethodGet:
+			h.exportRubrics(w, r, teacherID)
+			return
+		case len(parts) == 3 && parts[2] == "import" && r.Method == http.MethodPost:
+			h.importRubrics(w, r, teacherID)
+			return
+		case len(parts) <= 3:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+	}
+
+	if len(parts) == 2 && parts[1] == "feedback-templates" {
+		switch r.Method {
+		case http.MethodGet:
+			h.listFeedbackTemplates(w, r, teacherID)
+			return
+		case http.MethodPost:
+			h.createFeedbackTemplate(w, r, teacherID)
+			return
+		}
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if len(parts) >= 2 && parts[1] == "questions" {
+		h.routeBank(w, r, teacherID, parts)
+		return
+	}
+
+	if len(parts) >= 2 && parts[1] == "delegations" {
+		switch {
+		case len(parts) == 2 && r.Method == http.MethodGet:
+			h.listDelegations(w, r, teacherID)
+			return
+		case len(parts) == 2 && r.Method == http.MethodPost:
+			h.grantDelegation(w, r, teacherID)
+			return
+		case len(parts) == 3 && r.Method == http.MethodDelete:
+			h.revokeDelegation(w, r, teacherID, domain.DelegationID(parts[2]))
+			return
+		case len(parts) <= 3:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+	}
+
+	if len(parts) == 2 && (parts[1] == "received-delegations" || parts[1] == "delegated-tests") {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		if parts[1] == "received-delegations" {
+			h.listReceivedDelegations(w, r, teacherID)
+		} else {
+			h.listDelegatedTests(w, r, teacherID)
+		}
+		return
+	}
+
+	if len(parts) == 4 && parts[1] == "classes" && parts[3] == "analytics" {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		h.classAnalytics(w, r, teacherID, domain.ClassID(parts[2]))
+		return
+	}
+
+	if len(parts) == 2 && parts[1] == "search" {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		h.searchTests(w, r, teacherID)
+		return
+	}
+
+	if len(parts) >= 2 && parts[1] == "uploads" {
+		h.routeUploads(w, r, teacherID, parts)
+		return
+	}
+
+	if len(parts) == 2 && parts[1] == "grading-backlog" {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		h.listGradingBacklog(w, r, teacherID)
+		return
+	}
+
+	if len(parts) >= 3 && parts[1] == "jobs" {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		switch {
+		case len(parts) == 3:
+			h.getJob(w, r, teacherID, parts[2])
+			return
+		case len(parts) == 4 && parts[3] == "download":
+			h.downloadJob(w, r, teacherID, parts[2])
+			return
+		}
+	}
+
+	if len(parts) == 3 && parts[1] == "tests" && parts[2] == "compose" {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		h.composeTest(w, r, teacherID)
+		return
+	}
+
+	if len(parts) == 3 && parts[1] == "tests" && parts[2] == "quick-import" {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		h.quickImportTest(w, r, teacherID)
+		return
+	}
+
+	if len(parts) == 3 && parts[1] == "tests" {
+		if r.Method != http.MethodPatch {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		h.updateTestDetails(w, r, teacherID, domain.TestID(parts[2]))
+		return
+	}
+
 	if len(parts) >= 4 && parts[1] == "tests" {
 		testID := domain.TestID(parts[2])
 		switch parts[3] {
 		case "questions":
-			if r.Method != http.MethodGet {
+			if len(parts) == 6 && parts[5] == "comments" {
+				questionID := domain.QuestionID(parts[4])
+				switch r.Method {
+				case http.MethodGet:
+					h.listComments(w, r, teacherID, testID, questionID)
+				case http.MethodPost:
+					h.addComment(w, r, teacherID, testID, questionID)
+				default:
+					writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+				}
+				return
+			}
+			if len(parts) == 5 {
+				if r.Method != http.MethodPatch {
+					writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+					return
+				}
+				h.updateQuestion(w, r, teacherID, testID, domain.QuestionID(parts[4]))
+				return
+			}
+			if len(parts) == 6 && parts[5] == "distractors" {
+				if r.Method != http.MethodGet {
+					writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+					return
+				}
+				h.getDistractors(w, r, teacherID, testID, domain.QuestionID(parts[4]))
+				return
+			}
+			if len(parts) == 6 && parts[5] == "difficulty" {
+				if r.Method != http.MethodPut {
+					writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+					return
+				}
+				h.setQuestionDifficulty(w, r, teacherID, testID, domain.QuestionID(parts[4]))
+				return
+			}
+			switch r.Method {
+			case http.MethodGet:
+				h.getQuestions(w, r, teacherID, testID)
+			case http.MethodPut:
+				h.reorderQuestions(w, r, teacherID, testID)
+			default:
+				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			}
+			return
+		case "lock":
+			if len(parts) != 4 {
+				break
+			}
+			switch r.Method {
+			case http.MethodGet:
+				h.getDraftLock(w, r, teacherID, testID)
+			case http.MethodPut:
+				h.lockDraft(w, r, teacherID, testID)
+			case http.MethodDelete:
+				h.unlockDraft(w, r, teacherID, testID)
+			default:
+				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			}
+			return
+		case "assignees":
+			if len(parts) != 4 {
+				break
+			}
+			if r.Method != http.MethodPatch {
 				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
 				return
 			}
-			h.getQuestions(w, r, teacherID, testID)
+			h.updateAssignees(w, r, teacherID, testID)
 			return
-		case "answers":
-			if r.Method != http.MethodGet {
+		case "publish", "unpublish":
+			if r.Method != http.MethodPost {
 				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
 				return
 			}
-			h.listAnswers(w, r, teacherID, testID)
+			h.setPublished(w, r, teacherID, testID, parts[3] == "publish")
+			return
+		case "answers":
+			if len(parts) == 5 && parts[4] == "import" {
+				if r.Method != http.MethodPost {
+					writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+					return
+				}
+				h.importAnswers(w, r, teacherID, testID)
+				return
+			}
+			if len(parts) == 6 && parts[5] == "history" {
+				if r.Method != http.MethodGet {
+					writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+					return
+				}
+				h.getAnswerHistory(w, r, teacherID, testID, domain.QuestionID(parts[4]))
+				return
+			}
+			if len(parts) >= 6 && parts[5] == "comments" {
+				h.routeAnswerComments(w, r, domain.RoleTeacher, string(teacherID), testID, domain.AnswerID(parts[4]), parts[6:])
+				return
+			}
+			switch r.Method {
+			case http.MethodGet:
+				h.listAnswers(w, r, teacherID, testID)
+			case http.MethodDelete:
+				h.deleteAnswer(w, r, teacherID, testID)
+			default:
+				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			}
+			return
+		case "holds":
+			switch {
+			case len(parts) == 4 && r.Method == http.MethodGet:
+				h.listHeldResults(w, r, teacherID, testID)
+			case len(parts) == 6 && parts[5] == "release" && r.Method == http.MethodPost:
+				h.releaseHeldResult(w, r, teacherID, testID, domain.ResultID(parts[4]))
+			default:
+				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			}
 			return
 		case "results":
 			if r.Method != http.MethodGet {
 				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
 				return
 			}
+			if len(parts) == 5 && parts[4] == "export" {
+				h.exportGradeSheet(w, r, teacherID, testID)
+				return
+			}
 			h.listResults(w, r, teacherID, testID)
 			return
 		case "grade":
@@ -81,6 +418,139 @@ func (h *Handler) route(w http.ResponseWriter, r *http.Request) {
 			}
 			h.gradeAnswer(w, r, teacherID, testID)
 			return
+		case "statistics":
+			if r.Method != http.MethodGet {
+				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+				return
+			}
+			h.getStatistics(w, r, teacherID, testID)
+			return
+		case "kiosk-tokens":
+			if r.Method != http.MethodPost {
+				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+				return
+			}
+			h.issueKioskToken(w, r, teacherID, testID)
+			return
+		case "export":
+			if r.Method != http.MethodPost {
+				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+				return
+			}
+			h.exportPackage(w, r, teacherID, testID)
+			return
+		case "curve":
+			switch r.Method {
+			case http.MethodPost:
+				h.applyCurve(w, r, teacherID, testID)
+			case http.MethodDelete:
+				h.revertCurve(w, r, teacherID, testID)
+			default:
+				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			}
+			return
+		case "passing-score":
+			if r.Method != http.MethodPut {
+				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+				return
+			}
+			h.setPassingScore(w, r, teacherID, testID)
+			return
+		case "submission-limits":
+			if r.Method != http.MethodPut {
+				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+				return
+			}
+			h.setSubmissionLimits(w, r, teacherID, testID)
+			return
+		case "duration":
+			if r.Method != http.MethodPut {
+				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+				return
+			}
+			h.setTestDuration(w, r, teacherID, testID)
+			return
+		case "score-range":
+			if r.Method != http.MethodPut {
+				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+				return
+			}
+			h.setScoreRange(w, r, teacherID, testID)
+			return
+		case "certificates":
+			if r.Method != http.MethodPut {
+				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+				return
+			}
+			h.setCertificates(w, r, teacherID, testID)
+			return
+		case "results-link":
+			switch r.Method {
+			case http.MethodGet:
+				h.getResultsLink(w, r, teacherID, testID)
+			case http.MethodPost:
+				h.createResultsLink(w, r, teacherID, testID)
+			case http.MethodDelete:
+				h.revokeResultsLink(w, r, teacherID, testID)
+			default:
+				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			}
+			return
+		case "outcomes":
+			if r.Method != http.MethodGet {
+				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+				return
+			}
+			h.listOutcomes(w, r, teacherID, testID)
+			return
+		case "comparison":
+			if r.Method != http.MethodGet {
+				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+				return
+			}
+			h.compareScores(w, r, teacherID, testID)
+			return
+		case "graders":
+			if r.Method != http.MethodGet {
+				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+				return
+			}
+			h.graderReport(w, r, teacherID, testID)
+			return
+		case "summary":
+			if r.Method != http.MethodGet {
+				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+				return
+			}
+			h.getSummary(w, r, teacherID, testID)
+			return
+		case "grading-deadline":
+			if r.Method != http.MethodPut {
+				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+				return
+			}
+			h.setGradingDeadline(w, r, teacherID, testID)
+			return
+		case "schedule":
+			switch r.Method {
+			case http.MethodGet:
+				h.getSchedule(w, r, teacherID, testID)
+			case http.MethodPut:
+				h.setSchedule(w, r, teacherID, testID)
+			default:
+				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			}
+			return
+		case "extend":
+			if r.Method != http.MethodPost {
+				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+				return
+			}
+			h.extendTest(w, r, teacherID, testID)
+			return
+		case "extensions":
+			h.routeExtensions(w, r, teacherID, testID, parts)
+			return
 		}
 	}
 
@@ -88,29 +558,91 @@ func (h *Handler) route(w http.ResponseWriter, r *http.Request) {
 }
 
 type createTestRequest struct {
-	Title     string `json:"title"`
+	Title        string `json:"title"`
+	Instructions string `json:"instructions"`
+	Sections     []struct {
+		Title        string `json:"title"`
+		Instructions string `json:"instructions"`
+	} `json:"sections"`
 	Questions []struct {
-		Prompt string `json:"prompt"`
-		Points int    `json:"points"`
+		Prompt           string                        `json:"prompt"`
+		Points           int                           `json:"points"`
+		Section          int                           `json:"section"`
+		Sequence         int                           `json:"sequence"`
+		Difficulty       string                        `json:"difficulty"`
+		Type             string                        `json:"type"`
+		Choices          []choicePayload               `json:"choices"`
+		ExpectedResponse string                        `json:"expected_response"`
+		Translations     map[string]translationPayload `json:"translations"`
+		BankQuestionID   string                        `json:"bank_question_id"`
 	} `json:"questions"`
-	StudentIDs []string `json:"student_ids"`
+	StudentIDs      []string   `json:"student_ids"`
+	ClassIDs        []string   `json:"class_ids"`
+	GradeIDs        []string   `json:"grade_ids"`
+	GradingDeadline *time.Time `json:"grading_deadline"`
+	PassingScore    *int       `json:"passing_score"`
+	OpensAt         *time.Time `json:"opens_at"`
+	ClosesAt        *time.Time `json:"closes_at"`
+	DurationMinutes int        `json:"duration_minutes"`
+	Draft           bool       `json:"draft"`
 }
 
 type testResponse struct {
-	TestID     string             `json:"test_id"`
-	Title      string             `json:"title"`
-	CreatedAt  time.Time          `json:"created_at"`
-	UpdatedAt  time.Time          `json:"updated_at"`
-	StudentIDs []string           `json:"student_ids"`
-	Questions  []questionResponse `json:"questions"`
+	TestID           string                     `json:"test_id"`
+	Title            string                     `json:"title"`
+	Instructions     string                     `json:"instructions"`
+	Sections         []sectionResponse          `json:"sections"`
+	Published        bool                       `json:"published"`
+	GradingDeadline  *time.Time                 `json:"grading_deadline,omitempty"`
+	PassingScore     *int                       `json:"passing_score,omitempty"`
+	Curve            *curveResponse             `json:"curve,omitempty"`
+	OpensAt          *time.Time                 `json:"opens_at,omitempty"`
+	ClosesAt         *time.Time                 `json:"closes_at,omitempty"`
+	SubmissionLimits *submissionLimitsPayload   `json:"submission_limits,omitempty"`
+	UnboundedScores  bool                       `json:"unbounded_scores"`
+	Certificates     bool                       `json:"certificates"`
+	DurationMinutes  int                        `json:"duration_minutes,omitempty"`
+	Version          int                        `json:"version"`
+	CreatedAt        time.Time                  `json:"created_at"`
+	UpdatedAt        time.Time                  `json:"updated_at"`
+	StudentIDs       []string                   `json:"student_ids"`
+	Questions        []questionResponse         `json:"questions"`
+	ScheduleWarnings []scheduleConflictResponse `json:"schedule_warnings,omitempty"`
+}
+
+type sectionResponse struct {
+	SectionID    string `json:"section_id"`
+	Title        string `json:"title"`
+	Instructions string `json:"instructions"`
 }
 
 type questionResponse struct {
-	QuestionID string    `json:"question_id"`
-	Sequence   int       `json:"sequence"`
-	Prompt     string    `json:"prompt"`
-	Points     int       `json:"points"`
-	CreatedAt  time.Time `json:"created_at"`
+	QuestionID       string                        `json:"question_id"`
+	SectionID        string                        `json:"section_id,omitempty"`
+	Sequence         int                           `json:"sequence"`
+	Prompt           string                        `json:"prompt"`
+	Points           int                           `json:"points"`
+	Difficulty       string                        `json:"difficulty,omitempty"`
+	Type             string                        `json:"type"`
+	Choices          []choicePayload               `json:"choices,omitempty"`
+	ExpectedResponse string                        `json:"expected_response,omitempty"`
+	Translations     map[string]translationPayload `json:"translations,omitempty"`
+	BankQuestionID   string                        `json:"bank_question_id,omitempty"`
+	CreatedAt        time.Time                     `json:"created_at"`
+}
+
+// choicePayload is one option of a multiple-choice question, both in
+// requests and responses.
+type choicePayload struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+}
+
+// translationPayload is a question in another language. Choices reuse the
+// keys of the question's choices.
+type translationPayload struct {
+	Prompt  string          `json:"prompt"`
+	Choices []choicePayload `json:"choices,omitempty"`
 }
 
 type answerResponse struct {
@@ -118,18 +650,27 @@ type answerResponse struct {
 	QuestionID string    `json:"question_id"`
 	StudentID  string    `json:"student_id"`
 	Response   string    `json:"response"`
+	Note       string    `json:"note,omitempty"`
+	Truncated  bool      `json:"truncated,omitempty"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
+	// SubmittedAt is when the student submitted the test, locking the
+	// answer; nil while the student may still change it.
+	SubmittedAt *time.Time `json:"submitted_at,omitempty"`
 }
 
 type resultResponse struct {
-	ResultID  string    `json:"result_id"`
-	AnswerID  string    `json:"answer_id"`
-	Score     int       `json:"score"`
-	Feedback  string    `json:"feedback"`
-	Completed bool      `json:"completed"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ResultID  string              `json:"result_id"`
+	AnswerID  string              `json:"answer_id"`
+	Score     int                 `json:"score"`
+	RawScore  *int                `json:"raw_score,omitempty"`
+	Feedback  string              `json:"feedback"`
+	Completed bool                `json:"completed"`
+	GradedBy  string              `json:"graded_by,omitempty"`
+	Hold      *resultHoldResponse `json:"hold,omitempty"`
+	Version   int                 `json:"version"`
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at"`
 }
 
 func (h *Handler) createTest(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID) {
@@ -140,52 +681,141 @@ func (h *Handler) createTest(w http.ResponseWriter, r *http.Request, teacherID d
 	}
 
 	input := usecase.CreateTestInput{
-		Title:     strings.TrimSpace(req.Title),
-		TeacherID: teacherID,
+		Title:           strings.TrimSpace(req.Title),
+		Instructions:    strings.TrimSpace(req.Instructions),
+		TeacherID:       teacherID,
+		GradingDeadline: req.GradingDeadline,
+		PassingScore:    (*domain.Score)(req.PassingScore),
+		OpensAt:         req.OpensAt,
+		ClosesAt:        req.ClosesAt,
+		DurationMinutes: req.DurationMinutes,
+		Draft:           req.Draft,
+	}
+
+	for _, sec := range req.Sections {
+		input.Sections = append(input.Sections, usecase.SectionDraft{
+			Title:        strings.TrimSpace(sec.Title),
+			Instructions: strings.TrimSpace(sec.Instructions),
+		})
 	}
 
 	for _, q := range req.Questions {
 		input.Questions = append(input.Questions, usecase.QuestionDraft{
-			Prompt: strings.TrimSpace(q.Prompt),
-			Points: q.Points,
+			Prompt:           strings.TrimSpace(q.Prompt),
+			Points:           domain.Points(q.Points),
+			Section:          q.Section,
+			Sequence:         q.Sequence,
+			Difficulty:       domain.Difficulty(q.Difficulty),
+			Type:             domain.QuestionType(strings.ToLower(strings.TrimSpace(q.Type))),
+			Choices:          toDomainChoices(q.Choices),
+			ExpectedResponse: strings.TrimSpace(q.ExpectedResponse),
+			Translations:     toDomainTranslations(q.Translations),
+			BankQuestionID:   domain.BankQuestionID(strings.TrimSpace(q.BankQuestionID)),
 		})
 	}
 
-	for _, sid := range req.StudentIDs {
-		sid = strings.TrimSpace(sid)
-		if sid == "" {
-			continue
-		}
-		input.StudentIDs = append(input.StudentIDs, domain.StudentID(sid))
-	}
+	input.StudentIDs = parseIDs[domain.StudentID](req.StudentIDs)
+	input.ClassIDs = parseIDs[domain.ClassID](req.ClassIDs)
+	input.GradeIDs = parseIDs[domain.GradeID](req.GradeIDs)
 
 	test, questions, err := h.assessments.CreateTest(r.Context(), input)
 	if err != nil {
 		handleServiceError(w, err)
 		return
 	}
+	conflicts, err := h.assessments.ScheduleConflicts(r.Context(), teacherID, test.ID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
 
-	writeJSON(w, http.StatusCreated, toTestResponse(*test, questions))
+	resp := toTestResponse(*test, questions, locationOf(r))
+	resp.ScheduleWarnings = toScheduleConflictResponses(conflicts, locationOf(r))
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+type updateTestRequest struct {
+	Title        *string `json:"title"`
+	Instructions *string `json:"instructions"`
+	Sections     []struct {
+		SectionID    string  `json:"section_id"`
+		Title        *string `json:"title"`
+		Instructions *string `json:"instructions"`
+	} `json:"sections"`
+}
+
+func (h *Handler) updateTestDetails(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	var req updateTestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	input := usecase.TestDetailsInput{
+		Title:           trimmed(req.Title),
+		Instructions:    trimmed(req.Instructions),
+		ExpectedVersion: version,
+	}
+	for _, sec := range req.Sections {
+		input.Sections = append(input.Sections, usecase.SectionEdit{
+			ID:           domain.SectionID(sec.SectionID),
+			Title:        trimmed(sec.Title),
+			Instructions: trimmed(sec.Instructions),
+		})
+	}
+
+	test, err := h.assessments.UpdateTestDetails(r.Context(), teacherID, testID, input)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	questions, err := h.assessments.GetQuestionsForTeacher(r.Context(), teacherID, testID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	w.Header().Set("ETag", etag(test.Version))
+	writeJSON(w, http.StatusOK, toTestResponse(*test, questions, locationOf(r)))
+}
+
+func trimmed(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	t := strings.TrimSpace(*s)
+	return &t
 }
 
 func (h *Handler) listTests(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID) {
-	tests, err := h.assessments.ListTestsByTeacher(r.Context(), teacherID)
+	page, err := parsePageRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	tests, err := h.assessments.ListTestsByTeacher(r.Context(), teacherID, page)
 	if err != nil {
 		handleServiceError(w, err)
 		return
 	}
 
-	payload := make([]testResponse, 0, len(tests))
-	for _, test := range tests {
+	payload := make([]testResponse, 0, len(tests.Items))
+	for _, test := range tests.Items {
 		questions, qErr := h.assessments.GetQuestionsForTeacher(r.Context(), teacherID, test.ID)
 		if qErr != nil {
 			handleServiceError(w, qErr)
 			return
 		}
-		payload = append(payload, toTestResponse(test, questions))
+		payload = append(payload, toTestResponse(test, questions, locationOf(r)))
 	}
 
-	writeJSON(w, http.StatusOK, map[string]any{"tests": payload})
+	writeJSON(w, http.StatusOK, map[string]any{
+		"tests": payload,
+		"page":  toPageInfo(page, tests.NextCursor),
+	})
 }
 
 func (h *Handler) getQuestions(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
@@ -197,13 +827,7 @@ func (h *Handler) getQuestions(w http.ResponseWriter, r *http.Request, teacherID
 
 	resp := make([]questionResponse, len(questions))
 	for i, q := range questions {
-		resp[i] = questionResponse{
-			QuestionID: string(q.ID),
-			Sequence:   q.Sequence,
-			Prompt:     q.Prompt,
-			Points:     q.Points,
-			CreatedAt:  q.CreatedAt,
-		}
+		resp[i] = toQuestionResponse(q)
 	}
 
 	writeJSON(w, http.StatusOK, map[string]any{
@@ -212,54 +836,136 @@ func (h *Handler) getQuestions(w http.ResponseWriter, r *http.Request, teacherID
 	})
 }
 
+// deleteAnswer clears the answer of the student named by student_id to the
+// question named by question_id, together with its result.
+func (h *Handler) deleteAnswer(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	questionID := strings.TrimSpace(r.URL.Query().Get("question_id"))
+	studentID := strings.TrimSpace(r.URL.Query().Get("student_id"))
+	if questionID == "" || studentID == "" {
+		writeError(w, http.StatusBadRequest, "question_id and student_id are required")
+		return
+	}
+
+	err := h.assessments.DeleteAnswer(r.Context(), teacherID, testID, domain.QuestionID(questionID), domain.StudentID(studentID))
+	if err != nil {
+		if errors.Is(err, errs.ErrAnswerNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		handleServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (h *Handler) listAnswers(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
-	answers, err := h.assessments.ListAnswersByTest(r.Context(), teacherID, testID)
+	format, err := export.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	// NDJSON is the bulk export format and always streams every answer.
+	page := repository.All
+	if format != export.FormatNDJSON {
+		if page, err = parsePageRequest(r); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	answers, err := h.assessments.ListAnswersByTest(r.Context(), teacherID, testID, page)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	submissions, err := h.assessments.ListSubmissions(r.Context(), teacherID, testID)
 	if err != nil {
 		handleServiceError(w, err)
 		return
 	}
+	submittedAt := make(map[domain.StudentID]time.Time, len(submissions))
+	for _, sub := range submissions {
+		submittedAt[sub.StudentID] = sub.SubmittedAt
+	}
 
-	resp := make([]answerResponse, len(answers))
-	for i, ans := range answers {
+	loc := locationOf(r)
+	resp := make([]answerResponse, len(answers.Items))
+	for i, ans := range answers.Items {
 		resp[i] = answerResponse{
 			AnswerID:   string(ans.ID),
 			QuestionID: string(ans.QuestionID),
 			StudentID:  string(ans.StudentID),
 			Response:   ans.Response,
-			CreatedAt:  ans.CreatedAt,
-			UpdatedAt:  ans.UpdatedAt,
+			Note:       ans.Note,
+			Truncated:  ans.Truncated,
+			CreatedAt:  localTime(ans.CreatedAt, loc),
+			UpdatedAt:  localTime(ans.UpdatedAt, loc),
+		}
+		if at, ok := submittedAt[ans.StudentID]; ok {
+			resp[i].SubmittedAt = localTimePtr(&at, loc)
 		}
 	}
 
+	if format == export.FormatNDJSON {
+		writeNDJSON(w, resp)
+		return
+	}
 	writeJSON(w, http.StatusOK, map[string]any{
 		"test_id": string(testID),
 		"answers": resp,
+		"page":    toPageInfo(page, answers.NextCursor),
 	})
 }
 
 func (h *Handler) listResults(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
-	results, err := h.assessments.ListResultsByTest(r.Context(), teacherID, testID)
+	format, err := export.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	// NDJSON is the bulk export format and always streams every result.
+	page := repository.All
+	if format != export.FormatNDJSON {
+		if page, err = parsePageRequest(r); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	results, err := h.assessments.ListResultsByTest(r.Context(), teacherID, testID, page)
 	if err != nil {
 		handleServiceError(w, err)
 		return
 	}
 
-	resp := make([]resultResponse, len(results))
-	for i, res := range results {
+	loc := locationOf(r)
+	resp := make([]resultResponse, len(results.Items))
+	for i, res := range results.Items {
 		resp[i] = resultResponse{
 			ResultID:  string(res.ID),
 			AnswerID:  string(res.AnswerID),
-			Score:     res.Score,
+			Score:     int(res.Score),
+			RawScore:  (*int)(res.RawScore),
 			Feedback:  res.Feedback,
 			Completed: res.Completed,
-			CreatedAt: res.CreatedAt,
-			UpdatedAt: res.UpdatedAt,
+			GradedBy:  string(res.GradedBy),
+			Hold:      toResultHoldResponse(res.Hold),
+			Version:   res.Version,
+			CreatedAt: localTime(res.CreatedAt, loc),
+			UpdatedAt: localTime(res.UpdatedAt, loc),
 		}
 	}
 
+	if format == export.FormatNDJSON {
+		writeNDJSON(w, resp)
+		return
+	}
 	writeJSON(w, http.StatusOK, map[string]any{
 		"test_id": string(testID),
 		"results": resp,
+		"page":    toPageInfo(page, results.NextCursor),
 	})
 }
 
@@ -275,14 +981,20 @@ func (h *Handler) gradeAnswer(w http.ResponseWriter, r *http.Request, teacherID
 		writeError(w, http.StatusBadRequest, "invalid json payload")
 		return
 	}
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
 
 	payload := usecase.GradeInput{
 		TestID:     testID,
 		QuestionID: domain.QuestionID(strings.TrimSpace(req.QuestionID)),
 		StudentID:  domain.StudentID(strings.TrimSpace(req.StudentID)),
-		Score:      req.Score,
+		Score:      domain.Score(req.Score),
 		Feedback:   strings.TrimSpace(req.Feedback),
 		Completed:  req.Completed,
+
+		ExpectedVersion: version,
 	}
 
 	result, err := h.grading.GradeAnswer(r.Context(), teacherID, payload)
@@ -291,25 +1003,244 @@ func (h *Handler) gradeAnswer(w http.ResponseWriter, r *http.Request, teacherID
 		return
 	}
 
+	w.Header().Set("ETag", etag(result.Version))
 	writeJSON(w, http.StatusOK, resultResponse{
 		ResultID:  string(result.ID),
 		AnswerID:  string(result.AnswerID),
-		Score:     result.Score,
+		Score:     int(result.Score),
+		RawScore:  (*int)(result.RawScore),
 		Feedback:  result.Feedback,
 		Completed: result.Completed,
+		GradedBy:  string(result.GradedBy),
+		Hold:      toResultHoldResponse(result.Hold),
+		Version:   result.Version,
 		CreatedAt: result.CreatedAt,
 		UpdatedAt: result.UpdatedAt,
 	})
 }
 
-func toTestResponse(test domain.Test, questions []domain.Question) testResponse {
+type statisticsResponse struct {
+	TestID       string              `json:"test_id"`
+	Answers      int                 `json:"answers"`
+	Graded       int                 `json:"graded"`
+	Completed    int                 `json:"completed"`
+	MinScore     int                 `json:"min_score"`
+	MaxScore     int                 `json:"max_score"`
+	MeanScore    float64             `json:"mean_score"`
+	PassingScore *int                `json:"passing_score,omitempty"`
+	Decided      int                 `json:"decided"`
+	Passed       int                 `json:"passed"`
+	PassRate     float64             `json:"pass_rate"`
+	Classes      []classPassResponse `json:"classes"`
+	ComputedAt   time.Time           `json:"computed_at"`
+}
+
+type classPassResponse struct {
+	ClassID  string  `json:"class_id"`
+	Decided  int     `json:"decided"`
+	Passed   int     `json:"passed"`
+	PassRate float64 `json:"pass_rate"`
+}
+
+func (h *Handler) getStatistics(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	stats, err := h.assessments.TestStatistics(r.Context(), teacherID, testID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	resp := statisticsResponse{
+		TestID:       string(stats.TestID),
+		Answers:      stats.Answers,
+		Graded:       stats.Graded,
+		Completed:    stats.Completed,
+		MinScore:     int(stats.MinScore),
+		MaxScore:     int(stats.MaxScore),
+		MeanScore:    stats.MeanScore,
+		PassingScore: (*int)(stats.PassingScore),
+		Decided:      stats.Decided,
+		Passed:       stats.Passed,
+		PassRate:     stats.PassRate,
+		Classes:      make([]classPassResponse, len(stats.Classes)),
+		ComputedAt:   stats.ComputedAt,
+	}
+	for i, c := range stats.Classes {
+		resp.Classes[i] = classPassResponse{
+			ClassID:  string(c.ClassID),
+			Decided:  c.Decided,
+			Passed:   c.Passed,
+			PassRate: c.PassRate,
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+type jobResponse struct {
+	JobID     string    `json:"job_id"`
+	Kind      string    `json:"kind"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (h *Handler) exportPackage(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	// The default export is the ZIP grading package; ?format=ndjson and
+	// ?format=parquet produce one row per submission instead.
+	format := r.URL.Query().Get("format")
+	switch format {
+	case "", "zip", string(export.FormatNDJSON), "parquet":
+	default:
+		handleServiceError(w, errs.ErrUnsupportedFormat)
+		return
+	}
+
+	// Validate ownership up front so callers get an immediate error rather
+	// than a failed job.
+	if _, err := h.assessments.GetQuestionsForTeacher(r.Context(), teacherID, testID); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	if err := h.assessments.ReserveExportJob(r.Context(), teacherID); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	kind := "grading-package"
+	if format == "parquet" {
+		kind = "submissions-parquet"
+	}
+
+	loc := locationOf(r)
+	job, err := h.jobs.Enqueue(kind, string(teacherID), func(ctx context.Context) (*jobs.Artifact, error) {
+		pkg, err := h.assessments.CollectGradingPackage(ctx, teacherID, testID)
+		if err != nil {
+			return nil, err
+		}
+		pkg.Location = loc
+		var buf bytes.Buffer
+		switch format {
+		case string(export.FormatNDJSON):
+			if err := export.WriteNDJSON(&buf, export.SubmissionRows(*pkg)); err != nil {
+				return nil, err
+			}
+			return &jobs.Artifact{
+				Name:        "test-" + string(testID) + ".ndjson",
+				ContentType: export.ContentTypeNDJSON,
+				Data:        buf.Bytes(),
+			}, nil
+		case "parquet":
+			// Parquet files feed analytics pipelines and can be large, so they
+			// live in blob storage rather than in the job queue's memory.
+			if err := export.WriteSubmissionsParquet(&buf, export.SubmissionRows(*pkg)); err != nil {
+				return nil, err
+			}
+			key := "exports/" + string(teacherID) + "/" + id.New() + ".parquet"
+			if err := h.blobs.Put(ctx, key, &buf); err != nil {
+				return nil, err
+			}
+			return &jobs.Artifact{
+				Name:        "test-" + string(testID) + ".parquet",
+				ContentType: export.ContentTypeParquet,
+				BlobKey:     key,
+			}, nil
+		}
+		if err := export.WriteZIP(&buf, *pkg); err != nil {
+			return nil, err
+		}
+		return &jobs.Artifact{
+			Name:        "test-" + string(testID) + ".zip",
+			ContentType: "application/zip",
+			Data:        buf.Bytes(),
+		}, nil
+	})
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, toJobResponse(job))
+}
+
+func (h *Handler) getJob(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, jobID string) {
+	job, ok := h.jobs.Get(jobID)
+	if !ok || job.Owner != string(teacherID) {
+		writeError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, toJobResponse(job))
+}
+
+func (h *Handler) downloadJob(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, jobID string) {
+	job, ok := h.jobs.Get(jobID)
+	if !ok || job.Owner != string(teacherID) {
+		writeError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	artifact, ok := h.jobs.Artifact(jobID)
+	if !ok {
+		writeError(w, http.StatusConflict, "job has not completed")
+		return
+	}
+
+	if artifact.BlobKey == "" {
+		w.Header().Set("Content-Type", artifact.ContentType)
+		w.Header().Set("Content-Disposition", `attachment; filename="`+artifact.Name+`"`)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(artifact.Data)
+		return
+	}
+
+	body, err := h.blobs.Open(r.Context(), artifact.BlobKey)
+	if err != nil {
+		if errors.Is(err, errs.ErrBlobNotFound) {
+			writeError(w, http.StatusGone, "job output is no longer available")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Type", artifact.ContentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+artifact.Name+`"`)
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.Copy(w, body)
+}
+
+func toJobResponse(job jobs.Job) jobResponse {
+	return jobResponse{
+		JobID:     job.ID,
+		Kind:      job.Kind,
+		Status:    string(job.Status),
+		Error:     job.Error,
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+	}
+}
+
+// toTestResponse shows the test's timestamps in loc.
+func toTestResponse(test domain.Test, questions []domain.Question, loc *time.Location) testResponse {
 	resp := testResponse{
-		TestID:     string(test.ID),
-		Title:      test.Title,
-		CreatedAt:  test.CreatedAt,
-		UpdatedAt:  test.UpdatedAt,
-		StudentIDs: make([]string, len(test.AssignedTo)),
-		Questions:  make([]questionResponse, len(questions)),
+		TestID:           string(test.ID),
+		Title:            test.Title,
+		Instructions:     test.Instructions,
+		Sections:         toSectionResponses(test.Sections),
+		Published:        test.Published,
+		GradingDeadline:  localTimePtr(test.GradingDeadline, loc),
+		PassingScore:     (*int)(test.PassingScore),
+		Curve:            toCurveResponse(test.Curve),
+		OpensAt:          localTimePtr(test.OpensAt, loc),
+		ClosesAt:         localTimePtr(test.ClosesAt, loc),
+		SubmissionLimits: toSubmissionLimitsPayload(test.Limits),
+		UnboundedScores:  test.UnboundedScores,
+		Certificates:     test.Certificates,
+		DurationMinutes:  test.DurationMinutes,
+		Version:          test.Version,
+		CreatedAt:        localTime(test.CreatedAt, loc),
+		UpdatedAt:        localTime(test.UpdatedAt, loc),
+		StudentIDs:       make([]string, len(test.AssignedTo)),
+		Questions:        make([]questionResponse, len(questions)),
 	}
 
 	for i, sid := range test.AssignedTo {
@@ -317,15 +1248,87 @@ func toTestResponse(test domain.Test, questions []domain.Question) testResponse
 	}
 
 	for i, q := range questions {
-		resp.Questions[i] = questionResponse{
-			QuestionID: string(q.ID),
-			Sequence:   q.Sequence,
-			Prompt:     q.Prompt,
-			Points:     q.Points,
-			CreatedAt:  q.CreatedAt,
+		resp.Questions[i] = toQuestionResponse(q)
+	}
+
+	return resp
+}
+
+func toQuestionResponse(q domain.Question) questionResponse {
+	return questionResponse{
+		QuestionID:       string(q.ID),
+		SectionID:        string(q.SectionID),
+		Sequence:         q.Sequence,
+		Prompt:           q.Prompt,
+		Points:           int(q.Points),
+		Difficulty:       string(q.Difficulty),
+		Type:             string(q.AnswerType()),
+		Choices:          toChoicePayloads(q.Choices),
+		ExpectedResponse: q.ExpectedResponse,
+		Translations:     toTranslationPayloads(q.Translations),
+		BankQuestionID:   string(q.BankQuestionID),
+		CreatedAt:        q.CreatedAt,
+	}
+}
+
+// toDomainTranslations normalises the locale keys; keys that are not
+// locales are passed on for validation to reject.
+func toDomainTranslations(translations map[string]translationPayload) map[string]domain.Translation {
+	if translations == nil {
+		return nil
+	}
+	out := make(map[string]domain.Translation, len(translations))
+	for locale, tr := range translations {
+		if canonical, ok := domain.CanonicalLocale(locale); ok {
+			locale = canonical
+		}
+		out[locale] = domain.Translation{
+			Prompt:  strings.TrimSpace(tr.Prompt),
+			Choices: toDomainChoices(tr.Choices),
 		}
 	}
+	return out
+}
+
+func toTranslationPayloads(translations map[string]domain.Translation) map[string]translationPayload {
+	if len(translations) == 0 {
+		return nil
+	}
+	out := make(map[string]translationPayload, len(translations))
+	for locale, tr := range translations {
+		out[locale] = translationPayload{Prompt: tr.Prompt, Choices: toChoicePayloads(tr.Choices)}
+	}
+	return out
+}
 
+func toDomainChoices(choices []choicePayload) []domain.Choice {
+	out := make([]domain.Choice, len(choices))
+	for i, c := range choices {
+		out[i] = domain.Choice{Key: strings.TrimSpace(c.Key), Label: strings.TrimSpace(c.Label)}
+	}
+	return out
+}
+
+func toChoicePayloads(choices []domain.Choice) []choicePayload {
+	if len(choices) == 0 {
+		return nil
+	}
+	out := make([]choicePayload, len(choices))
+	for i, c := range choices {
+		out[i] = choicePayload{Key: c.Key, Label: c.Label}
+	}
+	return out
+}
+
+func toSectionResponses(sections []domain.Section) []sectionResponse {
+	resp := make([]sectionResponse, len(sections))
+	for i, sec := range sections {
+		resp[i] = sectionResponse{
+			SectionID:    string(sec.ID),
+			Title:        sec.Title,
+			Instructions: sec.Instructions,
+		}
+	}
 	return resp
 }
 
@@ -344,13 +1347,37 @@ func splitPath(path string) []string {
 }
 
 func handleServiceError(w http.ResponseWriter, err error) {
+	var invariant *domain.InvariantError
+	if errors.As(err, &invariant) {
+		writeError(w, http.StatusBadRequest, invariant.Error())
+		return
+	}
+	var locked *usecase.DraftLockedError
+	if errors.As(err, &locked) {
+		writeJSON(w, http.StatusConflict, map[string]any{"error": locked.Error(), "lock": toDraftLockResponse(locked.Lock)})
+		return
+	}
+	var quickImport *usecase.QuickImportError
+	if errors.As(err, &quickImport) {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": quickImport.Error(), "line": quickImport.Line})
+		return
+	}
+
 	switch err {
-	case errs.ErrTeacherNotFound, errs.ErrTestNotFound:
+	case errs.ErrTeacherNotFound, errs.ErrTestNotFound, errs.ErrQuestionNotFound, errs.ErrCommentNotFound, errs.ErrSectionNotFound, errs.ErrRubricNotFound, errs.ErrBankQuestionNotFound, errs.ErrDelegationNotFound, errs.ErrResultsLinkNotFound, errs.ErrResultNotHeld, errs.ErrExtensionNotFound:
 		writeError(w, http.StatusNotFound, err.Error())
-	case errs.ErrStudentNotFound, errs.ErrStudentNotAssigned, errs.ErrInvalidTest, errs.ErrInvalidQuestion, errs.ErrInvalidAnswer, errs.ErrAnswerNotFound:
+	case errs.ErrStudentNotFound, errs.ErrClassNotFound, errs.ErrGradeNotFound, errs.ErrStudentNotAssigned, errs.ErrInvalidTest, errs.ErrInvalidQuestion, errs.ErrInvalidAnswer, errs.ErrAnswerNotFound, errs.ErrInvalidProfile, errs.ErrInvalidComment, errs.ErrUnsupportedFormat, errs.ErrInvalidCurve, errs.ErrInvalidRubric, errs.ErrInvalidComposition, errs.ErrInvalidCursor, errs.ErrInvalidDelegation, errs.ErrInvalidAnswerCSV, errs.ErrScoreOutOfRange, errs.ErrInvalidResultsLink, errs.ErrNoQuestions, errs.ErrInvalidSearch, errs.ErrInvalidExtension:
 		writeError(w, http.StatusBadRequest, err.Error())
 	case errs.ErrForbiddenTeacher:
 		writeError(w, http.StatusForbidden, err.Error())
+	case errs.ErrNoCurve, errs.ErrTestPublished, errs.ErrTestAnswered, errs.ErrStudentAnswered, errs.ErrConflict:
+		writeError(w, http.StatusConflict, err.Error())
+	case errs.ErrNotEnoughQuestions, errs.ErrNotMultipleChoice:
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+	case errs.ErrQuotaExceeded:
+		writeError(w, http.StatusTooManyRequests, err.Error())
+	case errs.ErrCommentsUnavailable:
+		writeError(w, http.StatusNotImplemented, err.Error())
 	default:
 		writeError(w, http.StatusInternalServerError, err.Error())
 	}
@@ -365,3 +1392,9 @@ func writeJSON(w http.ResponseWriter, status int, payload any) {
 	w.WriteHeader(status)
 	_ = json.NewEncoder(w).Encode(payload)
 }
+
+func writeNDJSON[T any](w http.ResponseWriter, rows []T) {
+	w.Header().Set("Content-Type", export.ContentTypeNDJSON)
+	w.WriteHeader(http.StatusOK)
+	_ = export.WriteNDJSON(w, rows)
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/kiosk"
+)
+
+// KioskSettings configures issuance of kiosk tokens.
+type KioskSettings struct {
+	Signer *kiosk.Signer
+	TTL    time.Duration
+	MaxTTL time.Duration
+}
+
+type kioskTokenResponse struct {
+	Token     string    `json:"token"`
+	StudentID string    `json:"student_id"`
+	TestID    string    `json:"test_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (h *Handler) issueKioskToken(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	var req struct {
+		StudentID  string `json:"student_id"`
+		TTLMinutes int    `json:"ttl_minutes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	ttl := h.kiosk.TTL
+	if req.TTLMinutes > 0 {
+		ttl = time.Duration(req.TTLMinutes) * time.Minute
+	}
+	if ttl > h.kiosk.MaxTTL {
+		writeError(w, http.StatusBadRequest, "ttl_minutes exceeds the allowed maximum")
+		return
+	}
+
+	studentID := domain.StudentID(strings.TrimSpace(req.StudentID))
+	if err := h.assessments.AuthorizeKiosk(r.Context(), teacherID, testID, studentID); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	token, claims, err := h.kiosk.Signer.Issue(studentID, testID, ttl)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, kioskTokenResponse{
+		Token:     token,
+		StudentID: string(claims.StudentID),
+		TestID:    string(claims.TestID),
+		ExpiresAt: claims.ExpiresAt,
+	})
+}
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+// submissionLimitsPayload is a test's protection from answer spam, both in
+// requests and responses. Zero values disable a limit.
+type submissionLimitsPayload struct {
+	PerQuestionPerMinute   int `json:"per_question_per_minute"`
+	DuplicateWindowSeconds int `json:"duplicate_window_seconds"`
+}
+
+func (h *Handler) setSubmissionLimits(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	var req submissionLimitsPayload
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	test, err := h.assessments.SetSubmissionLimits(r.Context(), teacherID, testID, domain.SubmissionLimits{
+		PerQuestionPerMinute: req.PerQuestionPerMinute,
+		DuplicateWindow:      time.Duration(req.DuplicateWindowSeconds) * time.Second,
+	})
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	questions, err := h.assessments.GetQuestionsForTeacher(r.Context(), teacherID, testID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toTestResponse(*test, questions, locationOf(r)))
+}
+
+func toSubmissionLimitsPayload(limits domain.SubmissionLimits) *submissionLimitsPayload {
+	if limits == (domain.SubmissionLimits{}) {
+		return nil
+	}
+	return &submissionLimitsPayload{
+		PerQuestionPerMinute:   limits.PerQuestionPerMinute,
+		DuplicateWindowSeconds: int(limits.DuplicateWindow / time.Second),
+	}
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+// resultHoldResponse explains why a result the teacher marked completed
+// waits for review before the student sees it.
+type resultHoldResponse struct {
+	Reasons   []string  `json:"reasons"`
+	FlaggedAt time.Time `json:"flagged_at"`
+}
+
+type heldResultResponse struct {
+	QuestionID string         `json:"question_id"`
+	StudentID  string         `json:"student_id"`
+	Result     resultResponse `json:"result"`
+}
+
+func toResultHoldResponse(hold *domain.ResultHold) *resultHoldResponse {
+	if hold == nil {
+		return nil
+	}
+	resp := &resultHoldResponse{Reasons: make([]string, len(hold.Reasons)), FlaggedAt: hold.FlaggedAt}
+	for i, reason := range hold.Reasons {
+		resp.Reasons[i] = string(reason)
+	}
+	return resp
+}
+
+func toHeldResultResponse(held usecase.HeldResult) heldResultResponse {
+	res := held.Result
+	return heldResultResponse{
+		QuestionID: string(held.QuestionID),
+		StudentID:  string(held.StudentID),
+		Result: resultResponse{
+			ResultID:  string(res.ID),
+			AnswerID:  string(res.AnswerID),
+			Score:     int(res.Score),
+			RawScore:  (*int)(res.RawScore),
+			Feedback:  res.Feedback,
+			Completed: res.Completed,
+			GradedBy:  string(res.GradedBy),
+			Hold:      toResultHoldResponse(res.Hold),
+			Version:   res.Version,
+			CreatedAt: res.CreatedAt,
+			UpdatedAt: res.UpdatedAt,
+		},
+	}
+}
+
+func (h *Handler) listHeldResults(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	held, err := h.assessments.ListHeldResults(r.Context(), teacherID, testID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	resp := make([]heldResultResponse, len(held))
+	for i, item := range held {
+		resp[i] = toHeldResultResponse(item)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"test_id": string(testID),
+		"holds":   resp,
+	})
+}
+
+func (h *Handler) releaseHeldResult(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID, resultID domain.ResultID) {
+	released, err := h.assessments.ReleaseHeldResult(r.Context(), teacherID, testID, resultID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toHeldResultResponse(*released))
+}
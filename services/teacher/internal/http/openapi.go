@@ -0,0 +1,394 @@
+package http
+
+import (
+	"github.com/sky0621/go_work_sample/core/pkg/export"
+	"github.com/sky0621/go_work_sample/core/pkg/openapi"
+)
+
+// OpenAPI describes the teacher API.
+func OpenAPI() *openapi.Document {
+	const teacher = "/api/teachers/{teacherID}"
+	const test = teacher + "/tests/{testID}"
+	const question = test + "/questions/{questionID}"
+	format := openapi.Query("format", "json (default) or ndjson, which streams every row.")
+	notifications := openapi.Object{
+		"notifications": []notificationResponse{},
+		"unread_count":  0,
+		"page":          pageInfo{},
+	}
+
+	b := openapi.New(openapi.Info{
+		Title:       "Teacher API",
+		Version:     "1.0.0",
+		Description: "Teachers author tests, grade answers and follow their students' progress.",
+	})
+
+	b.Add("GET", teacher+"/profile", openapi.Route{Summary: "Get the teacher's profile", Tag: "profile", Response: profileResponse{}})
+	b.Add("PATCH", teacher+"/profile", openapi.Route{Summary: "Update the teacher's profile", Tag: "profile", Request: profileRequest{}, Response: profileResponse{}})
+	b.Add("GET", teacher+"/notifications", openapi.Route{Summary: "List notifications", Tag: "notifications", Query: openapi.PageQuery(), Response: notifications})
+	b.Add("POST", teacher+"/notifications/read", openapi.Route{
+		Summary:  "Mark notifications as read",
+		Tag:      "notifications",
+		Request:  openapi.Object{"notification_ids": []string{}},
+		Response: notifications,
+	})
+
+	b.Add("GET", teacher+"/tests", openapi.Route{
+		Summary:  "List the teacher's tests",
+		Tag:      "tests",
+		Query:    openapi.PageQuery(),
+		Response: openapi.Object{"tests": []testResponse{}, "page": pageInfo{}},
+	})
+	b.Add("POST", teacher+"/tests", openapi.Route{Summary: "Create a test", Tag: "tests", Request: createTestRequest{}, Status: 201, Response: testResponse{}})
+	b.Add("POST", teacher+"/tests/compose", openapi.Route{Summary: "Compose a test from earlier questions", Tag: "tests", Request: composeTestRequest{}, Status: 201, Response: testResponse{}})
+	b.Add("POST", teacher+"/tests/quick-import", openapi.Route{
+		Summary: "Create a draft test from questions written as plain text or Markdown",
+		Tag:     "tests",
+		Query: []openapi.Parameter{
+			openapi.Query("title", "Title of the test, replacing a leading \"# \" heading of the document."),
+			openapi.Query("preview", "When true, only parse the document and return its title and questions without creating a test."),
+		},
+		Request:     "",
+		RequestType: "text/markdown",
+		Status:      201,
+		Response:    testResponse{},
+	})
+	b.Add("PATCH", test, openapi.Route{
+		Summary:  "Edit a test's title, instructions and sections",
+		Tag:      "tests",
+		Query:    []openapi.Parameter{openapi.Header("If-Match", "Version of the test being edited, or * for any; a stale version answers 409.")},
+		Request:  updateTestRequest{},
+		Response: testResponse{},
+	})
+	b.Add("PATCH", test+"/assignees", openapi.Route{
+		Summary:  "Add students, classes or grades to a test and remove students",
+		Tag:      "tests",
+		Request:  updateAssigneesRequest{},
+		Response: assigneesResponse{},
+	})
+	b.Add("POST", test+"/publish", openapi.Route{Summary: "Publish a draft test", Tag: "tests", Response: testResponse{}})
+	b.Add("POST", test+"/unpublish", openapi.Route{Summary: "Return a test without answers to draft", Tag: "tests", Response: testResponse{}})
+	b.Add("GET", test+"/lock", openapi.Route{
+		Summary:  "Show who is editing a draft test",
+		Tag:      "tests",
+		Response: openapi.Object{"locked": false, "lock": draftLockResponse{}},
+	})
+	b.Add("PUT", test+"/lock", openapi.Route{
+		Summary:  "Lock a draft test for editing, or renew the lock; answers 409 with the holder when a co-teacher has it",
+		Tag:      "tests",
+		Query:    []openapi.Parameter{openapi.Query("steal", "true takes the lock over from the co-teacher holding it.")},
+		Response: draftLockResponse{},
+	})
+	b.Add("DELETE", test+"/lock", openapi.Route{Summary: "Release the lock on a draft test", Tag: "tests", Status: 204})
+	b.Add("GET", test+"/questions", openapi.Route{
+		Summary:  "List a test's questions",
+		Tag:      "tests",
+		Response: openapi.Object{"test_id": "", "questions": []questionResponse{}},
+	})
+	b.Add("PUT", test+"/questions", openapi.Route{
+		Summary:  "Reorder the questions of a draft test",
+		Tag:      "tests",
+		Request:  questionOrderRequest{},
+		Response: openapi.Object{"test_id": "", "questions": []questionResponse{}},
+	})
+	b.Add("PATCH", question, openapi.Route{Summary: "Edit a question of a draft test", Tag: "tests", Request: questionEditRequest{}, Response: questionResponse{}})
+	b.Add("PUT", question+"/difficulty", openapi.Route{Summary: "Set a question's difficulty", Tag: "tests", Request: questionDifficultyRequest{}, Response: questionResponse{}})
+	b.Add("GET", question+"/distractors", openapi.Route{
+		Summary:  "Show which choices students picked for a multiple-choice question",
+		Tag:      "reports",
+		Response: distractorAnalysisResponse{},
+	})
+	b.Add("GET", question+"/comments", openapi.Route{
+		Summary:  "List comment threads on a question",
+		Tag:      "comments",
+		Response: openapi.Object{"test_id": "", "question_id": "", "comments": []commentResponse{}},
+	})
+	b.Add("POST", question+"/comments", openapi.Route{
+		Summary:  "Comment on a question or reply to a comment",
+		Tag:      "comments",
+		Request:  openapi.Object{"parent_id": "", "body": ""},
+		Status:   201,
+		Response: commentResponse{},
+	})
+	b.Add("GET", test+"/schedule", openapi.Route{Summary: "Get a test's window and schedule conflicts", Tag: "tests", Response: scheduleResponse{}})
+	b.Add("PUT", test+"/schedule", openapi.Route{Summary: "Set or clear a test's window", Tag: "tests", Request: scheduleRequest{}, Response: scheduleResponse{}})
+	b.Add("POST", test+"/extend", openapi.Route{Summary: "Close a test later for every student, with a reason", Tag: "tests", Request: extendTestRequest{}, Response: extensionsResponse{}})
+	b.Add("GET", test+"/extensions", openapi.Route{Summary: "List students' deadline extensions", Tag: "tests", Response: extensionsResponse{}})
+	b.Add("POST", test+"/extensions", openapi.Route{Summary: "Give students longer to sit a test, with a reason", Tag: "tests", Request: grantExtensionsRequest{}, Response: extensionsResponse{}})
+	b.Add("DELETE", test+"/extensions/{studentID}", openapi.Route{Summary: "Revoke a student's deadline extension", Tag: "tests", Response: extensionsResponse{}})
+	b.Add("PUT", test+"/grading-deadline", openapi.Route{Summary: "Set or clear the grading deadline", Tag: "tests", Request: gradingDeadlineRequest{}, Response: testResponse{}})
+	b.Add("PUT", test+"/passing-score", openapi.Route{Summary: "Set or clear the passing score", Tag: "tests", Request: passingScoreRequest{}, Response: testResponse{}})
+	b.Add("PUT", test+"/submission-limits", openapi.Route{Summary: "Limit how often students may submit answers", Tag: "tests", Request: submissionLimitsPayload{}, Response: testResponse{}})
+	b.Add("PUT", test+"/duration", openapi.Route{Summary: "Time the test from when each student starts it", Tag: "tests", Request: durationRequest{}, Response: testResponse{}})
+	b.Add("PUT", test+"/score-range", openapi.Route{Summary: "Allow scores outside zero to a question's points", Tag: "tests", Request: scoreRangeRequest{}, Response: testResponse{}})
+	b.Add("PUT", test+"/certificates", openapi.Route{Summary: "Award completion certificates to students who pass", Tag: "tests", Request: certificatesRequest{}, Response: testResponse{}})
+	b.Add("GET", test+"/results-link", openapi.Route{Summary: "Get the test's public results link", Tag: "tests", Response: resultsLinkResponse{}})
+	b.Add("POST", test+"/results-link", openapi.Route{
+		Summary:  "Create a link to the test's anonymized results for parents, replacing any earlier one",
+		Tag:      "tests",
+		Request:  resultsLinkRequest{},
+		Status:   201,
+		Response: resultsLinkResponse{},
+	})
+	b.Add("DELETE", test+"/results-link", openapi.Route{Summary: "Revoke the test's public results link", Tag: "tests", Status: 204})
+	b.Add("GET", PublicResultsPrefix+"{token}", openapi.Route{
+		Summary:  "Show the anonymized results behind a results link",
+		Tag:      "public",
+		Response: publicResultsResponse{},
+		Public:   true,
+	})
+	b.Add("POST", test+"/kiosk-tokens", openapi.Route{
+		Summary:  "Issue a kiosk token for a student to sit the test",
+		Tag:      "tests",
+		Request:  openapi.Object{"student_id": "", "ttl_minutes": 0},
+		Status:   201,
+		Response: kioskTokenResponse{},
+	})
+
+	b.Add("GET", test+"/answers", openapi.Route{
+		Summary:  "List answers",
+		Tag:      "grading",
+		Query:    append(openapi.PageQuery(), format),
+		Response: openapi.Object{"test_id": "", "answers": []answerResponse{}, "page": pageInfo{}},
+	})
+	b.Add("DELETE", test+"/answers", openapi.Route{
+		Summary: "Delete a student's answer to a question together with its result",
+		Tag:     "grading",
+		Query: []openapi.Parameter{
+			openapi.Query("question_id", "Question of the answer. Required."),
+			openapi.Query("student_id", "Student who gave the answer. Required."),
+		},
+		Status: 204,
+	})
+	b.Add("GET", test+"/answers/{questionID}/history", openapi.Route{
+		Summary:  "Show how a student's answer evolved, oldest version first",
+		Tag:      "grading",
+		Query:    []openapi.Parameter{openapi.Query("student_id", "Student who gave the answer. Required.")},
+		Response: answerHistoryResponse{},
+	})
+	answerComments := openapi.Object{"test_id": "", "answer_id": "", "comments": []answerCommentResponse{}}
+	b.Add("GET", test+"/answers/{answerID}/comments", openapi.Route{
+		Summary:  "List the comment threads on an answer",
+		Tag:      "grading",
+		Response: answerComments,
+	})
+	b.Add("POST", test+"/answers/{answerID}/comments", openapi.Route{
+		Summary:  "Comment on an answer or reply to a comment",
+		Tag:      "grading",
+		Request:  openapi.Object{"parent_id": "", "body": ""},
+		Status:   201,
+		Response: answerCommentResponse{},
+	})
+	b.Add("POST", test+"/answers/{answerID}/comments/read", openapi.Route{
+		Summary:  "Mark the other side's comments on an answer as read",
+		Tag:      "grading",
+		Response: openapi.Object{"marked": 0},
+	})
+	b.Add("POST", test+"/answers/import", openapi.Route{
+		Summary:     "Upload paper answers as CSV with student_id, question and response columns",
+		Tag:         "grading",
+		Request:     "",
+		RequestType: export.ContentTypeCSV,
+		Response:    answerImportResponse{},
+	})
+	b.Add("GET", test+"/results", openapi.Route{
+		Summary:  "List results",
+		Tag:      "grading",
+		Query:    append(openapi.PageQuery(), format),
+		Response: openapi.Object{"test_id": "", "results": []resultResponse{}, "page": pageInfo{}},
+	})
+	b.Add("GET", test+"/results/export", openapi.Route{
+		Summary:      "Download the grade sheet of a test",
+		Tag:          "grading",
+		Query:        []openapi.Parameter{openapi.Query("format", "csv, the default and only format")},
+		Response:     openapi.Binary{},
+		ResponseType: export.ContentTypeCSV,
+	})
+	b.Add("POST", test+"/grade", openapi.Route{
+		Summary:  "Grade an answer",
+		Tag:      "grading",
+		Query:    []openapi.Parameter{openapi.Header("If-Match", "Version of the result being regraded, \"0\" for an ungraded answer or * for any; a stale version answers 409.")},
+		Request:  openapi.Object{"question_id": "", "student_id": "", "score": 0, "feedback": "", "completed": false},
+		Response: resultResponse{},
+	})
+	b.Add("GET", test+"/holds", openapi.Route{
+		Summary:  "List results held for review because their scores look anomalous",
+		Tag:      "grading",
+		Response: openapi.Object{"test_id": "", "holds": []heldResultResponse{}},
+	})
+	b.Add("POST", test+"/holds/{resultID}/release", openapi.Route{
+		Summary:  "Release a held result to the student",
+		Tag:      "grading",
+		Response: heldResultResponse{},
+	})
+	b.Add("POST", test+"/curve", openapi.Route{Summary: "Curve the test's scores", Tag: "grading", Request: curveRequest{}, Response: testResponse{}})
+	b.Add("DELETE", test+"/curve", openapi.Route{Summary: "Revert the curve", Tag: "grading", Response: testResponse{}})
+	b.Add("GET", teacher+"/grading-backlog", openapi.Route{
+		Summary:  "List tests with ungraded answers due soon",
+		Tag:      "grading",
+		Query:    []openapi.Parameter{openapi.Query("within", "Look-ahead window such as 48h.")},
+		Response: openapi.Object{"tests": []gradingBacklogResponse{}},
+	})
+	b.Add("GET", teacher+"/search", openapi.Route{
+		Summary: "Search the teacher's questions, student answers and feedback by keyword",
+		Tag:     "tests",
+		Query: []openapi.Parameter{
+			openapi.Query("q", "Words every hit contains."),
+			openapi.Query("kind", "question, answer or feedback; all kinds when omitted."),
+			openapi.Query("limit", "Hits to return, 20 by default and at most 100."),
+		},
+		Response: openapi.Object{"query": "", "hits": []searchHitResponse{}},
+	})
+
+	b.Add("GET", test+"/statistics", openapi.Route{Summary: "Get grading statistics", Tag: "reports", Response: statisticsResponse{}})
+	b.Add("GET", teacher+"/classes/{classID}/analytics", openapi.Route{
+		Summary:  "Summarise a class's tests, hardest questions and students needing attention",
+		Tag:      "reports",
+		Response: classAnalyticsResponse{},
+	})
+	b.Add("GET", test+"/outcomes", openapi.Route{
+		Summary:  "List every student's total and pass/fail outcome",
+		Tag:      "reports",
+		Response: openapi.Object{"test_id": "", "outcomes": []outcomeResponse{}},
+	})
+	b.Add("GET", test+"/summary", openapi.Route{Summary: "Get per-student totals and the score distribution", Tag: "reports", Response: testSummaryResponse{}})
+	b.Add("GET", test+"/comparison", openapi.Route{
+		Summary: "Compare score distributions across classes or equivalent tests",
+		Tag:     "reports",
+		Query: []openapi.Parameter{
+			openapi.Query("with", "Comma-separated equivalent tests to compare with, such as the same test in other terms."),
+			openapi.Query("by", "test (default) or class, which splits every test by class."),
+		},
+		Response: comparisonResponse{},
+	})
+	b.Add("GET", test+"/graders", openapi.Route{
+		Summary:  "Compare the severity and agreement of the teachers who graded a test",
+		Tag:      "reports",
+		Response: graderReportResponse{},
+	})
+	b.Add("POST", test+"/export", openapi.Route{
+		Summary:  "Start an export job",
+		Tag:      "reports",
+		Query:    []openapi.Parameter{openapi.Query("format", "zip (default), ndjson or parquet.")},
+		Status:   202,
+		Response: jobResponse{},
+	})
+	b.Add("GET", teacher+"/jobs/{jobID}", openapi.Route{Summary: "Get an export job", Tag: "reports", Response: jobResponse{}})
+	b.Add("GET", teacher+"/jobs/{jobID}/download", openapi.Route{
+		Summary:      "Download a finished export",
+		Tag:          "reports",
+		Response:     openapi.Binary{},
+		ResponseType: "application/octet-stream",
+	})
+
+	const upload = teacher + "/uploads/{uploadID}"
+	b.Add("POST", teacher+"/uploads", openapi.Route{
+		Summary:  "Start a resumable upload of a file with its size and SHA-256",
+		Tag:      "uploads",
+		Request:  uploadInitRequest{},
+		Status:   201,
+		Response: uploadResponse{},
+	})
+	b.Add("GET", upload, openapi.Route{Summary: "Get an upload's received and missing parts", Tag: "uploads", Response: uploadResponse{}})
+	b.Add("PUT", upload+"/parts/{part}", openapi.Route{
+		Summary:     "Send or resend a part; an X-Part-SHA256 header is checked against it",
+		Tag:         "uploads",
+		Request:     openapi.Binary{},
+		RequestType: "application/octet-stream",
+		Response:    uploadResponse{},
+	})
+	b.Add("POST", upload+"/complete", openapi.Route{Summary: "Assemble the parts and verify the file's checksum", Tag: "uploads", Response: uploadResponse{}})
+	b.Add("GET", upload+"/content", openapi.Route{
+		Summary:      "Download a completed upload",
+		Tag:          "uploads",
+		Response:     openapi.Binary{},
+		ResponseType: "application/octet-stream",
+	})
+	b.Add("DELETE", upload, openapi.Route{Summary: "Discard an upload", Tag: "uploads", Status: 204})
+
+	b.Add("GET", teacher+"/rubrics", openapi.Route{
+		Summary:  "List rubrics",
+		Tag:      "rubrics",
+		Query:    openapi.PageQuery(),
+		Response: openapi.Object{"rubrics": []rubricResponse{}, "page": pageInfo{}},
+	})
+	b.Add("POST", teacher+"/rubrics", openapi.Route{Summary: "Create a rubric", Tag: "rubrics", Request: rubricRequest{}, Status: 201, Response: rubricResponse{}})
+	b.Add("GET", teacher+"/rubrics/export", openapi.Route{Summary: "Export rubrics and feedback templates", Tag: "rubrics", Response: export.RubricBank{}})
+	b.Add("POST", teacher+"/rubrics/import", openapi.Route{
+		Summary: "Import a rubric bank",
+		Tag:     "rubrics",
+		Request: export.RubricBank{},
+		Status:  201,
+		Response: openapi.Object{
+			"rubrics":            []rubricResponse{},
+			"feedback_templates": []feedbackTemplateResponse{},
+			"rubric_ids":         map[string]string{},
+			"template_ids":       map[string]string{},
+		},
+	})
+	b.Add("GET", teacher+"/feedback-templates", openapi.Route{
+		Summary:  "List feedback templates",
+		Tag:      "rubrics",
+		Query:    openapi.PageQuery(),
+		Response: openapi.Object{"feedback_templates": []feedbackTemplateResponse{}, "page": pageInfo{}},
+	})
+	b.Add("POST", teacher+"/feedback-templates", openapi.Route{Summary: "Create a feedback template", Tag: "rubrics", Request: feedbackTemplateRequest{}, Status: 201, Response: feedbackTemplateResponse{}})
+
+	b.Add("GET", teacher+"/questions", openapi.Route{
+		Summary: "Search the question bank",
+		Tag:     "bank",
+		Query: append([]openapi.Parameter{
+			openapi.Query("q", "Text the prompt must contain, ignoring case."),
+			openapi.Query("tag", "Tags the question must all have; repeat or separate with commas."),
+			openapi.Query("difficulty", "easy, medium or hard."),
+			openapi.Query("type", "free_text, multiple_choice or true_false."),
+		}, openapi.PageQuery()...),
+		Response: openapi.Object{"questions": []bankQuestionResponse{}, "page": pageInfo{}},
+	})
+	b.Add("POST", teacher+"/questions", openapi.Route{Summary: "Add a question to the bank", Tag: "bank", Request: bankQuestionRequest{}, Status: 201, Response: bankQuestionResponse{}})
+	b.Add("GET", teacher+"/questions/pool", openapi.Route{
+		Summary: "Search the questions shared by teachers of the school",
+		Tag:     "bank",
+		Query: append([]openapi.Parameter{
+			openapi.Query("q", "Text the prompt must contain, ignoring case."),
+			openapi.Query("tag", "Tags the question must all have; repeat or separate with commas."),
+			openapi.Query("difficulty", "easy, medium or hard."),
+			openapi.Query("type", "free_text, multiple_choice or true_false."),
+		}, openapi.PageQuery()...),
+		Response: openapi.Object{"questions": []bankQuestionResponse{}, "page": pageInfo{}},
+	})
+	b.Add("GET", teacher+"/questions/usage", openapi.Route{
+		Summary:  "Report how many tests and students the bank and pool questions were used with",
+		Tag:      "bank",
+		Response: openapi.Object{"questions": []bankQuestionUsageResponse{}},
+	})
+	b.Add("GET", teacher+"/questions/{bankQuestionID}", openapi.Route{Summary: "Get a bank question", Tag: "bank", Response: bankQuestionResponse{}})
+	b.Add("PATCH", teacher+"/questions/{bankQuestionID}", openapi.Route{
+		Summary:  "Replace a bank question; tests that used it keep their copy",
+		Tag:      "bank",
+		Request:  bankQuestionRequest{},
+		Response: bankQuestionResponse{},
+	})
+	b.Add("DELETE", teacher+"/questions/{bankQuestionID}", openapi.Route{Summary: "Remove a question from the bank", Tag: "bank", Status: 204})
+
+	b.Add("GET", teacher+"/delegations", openapi.Route{
+		Summary:  "List delegations the teacher granted",
+		Tag:      "delegations",
+		Query:    openapi.PageQuery(),
+		Response: openapi.Object{"delegations": []delegationResponse{}, "page": pageInfo{}},
+	})
+	b.Add("POST", teacher+"/delegations", openapi.Route{Summary: "Delegate a test to a substitute", Tag: "delegations", Request: delegationRequest{}, Status: 201, Response: delegationResponse{}})
+	b.Add("DELETE", teacher+"/delegations/{delegationID}", openapi.Route{Summary: "Revoke a delegation", Tag: "delegations", Status: 204})
+	b.Add("GET", teacher+"/received-delegations", openapi.Route{
+		Summary:  "List delegations granted to the teacher",
+		Tag:      "delegations",
+		Response: openapi.Object{"delegations": []delegationResponse{}},
+	})
+	b.Add("GET", teacher+"/delegated-tests", openapi.Route{
+		Summary:  "List tests delegated to the teacher",
+		Tag:      "delegations",
+		Query:    openapi.PageQuery(),
+		Response: openapi.Object{"tests": []testResponse{}, "page": pageInfo{}},
+	})
+	return b.Document()
+}
@@ -0,0 +1,68 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+type passingScoreRequest struct {
+	PassingScore *int `json:"passing_score"`
+}
+
+type outcomeResponse struct {
+	StudentID string  `json:"student_id"`
+	Score     int     `json:"score"`
+	MaxScore  int     `json:"max_score"`
+	Percent   float64 `json:"percent"`
+	Answered  int     `json:"answered"`
+	Graded    int     `json:"graded"`
+	Passed    *bool   `json:"passed"`
+}
+
+func (h *Handler) setPassingScore(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	var req passingScoreRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	test, err := h.assessments.SetPassingScore(r.Context(), teacherID, testID, (*domain.Score)(req.PassingScore))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	questions, err := h.assessments.GetQuestionsForTeacher(r.Context(), teacherID, testID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toTestResponse(*test, questions, locationOf(r)))
+}
+
+func (h *Handler) listOutcomes(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	outcomes, err := h.assessments.TestOutcomes(r.Context(), teacherID, testID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	payload := make([]outcomeResponse, len(outcomes))
+	for i, o := range outcomes {
+		payload[i] = outcomeResponse{
+			StudentID: string(o.StudentID),
+			Score:     int(o.Score),
+			MaxScore:  int(o.MaxScore),
+			Percent:   o.Percent(),
+			Answered:  o.Answered,
+			Graded:    o.Graded,
+			Passed:    o.Passed,
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"test_id":  string(testID),
+		"outcomes": payload,
+	})
+}
@@ -0,0 +1,92 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+type notificationPreferences struct {
+	Delivery       string `json:"delivery"`
+	TestAssigned   bool   `json:"test_assigned"`
+	ResultReleased bool   `json:"result_released"`
+}
+
+// notificationRequest changes notification preferences. An event left out
+// stays subscribed.
+type notificationRequest struct {
+	Delivery       string `json:"delivery"`
+	TestAssigned   *bool  `json:"test_assigned"`
+	ResultReleased *bool  `json:"result_released"`
+}
+
+func (n notificationRequest) preferences() domain.NotificationPreferences {
+	return domain.NotificationPreferences{
+		Delivery:           domain.NotificationDelivery(strings.TrimSpace(n.Delivery)),
+		MuteTestAssigned:   n.TestAssigned != nil && !*n.TestAssigned,
+		MuteResultReleased: n.ResultReleased != nil && !*n.ResultReleased,
+	}
+}
+
+type profileResponse struct {
+	TeacherID     string                  `json:"teacher_id"`
+	Name          string                  `json:"name"`
+	DisplayName   string                  `json:"display_name"`
+	Email         string                  `json:"email"`
+	Timezone      string                  `json:"timezone,omitempty"`
+	Notifications notificationPreferences `json:"notifications"`
+}
+
+type profileRequest struct {
+	DisplayName   *string              `json:"display_name"`
+	Notifications *notificationRequest `json:"notifications"`
+	Timezone      *string              `json:"timezone"`
+}
+
+func (h *Handler) getProfile(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID) {
+	teacher, err := h.profiles.GetTeacherProfile(r.Context(), teacherID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toProfileResponse(*teacher))
+}
+
+func (h *Handler) updateProfile(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID) {
+	var req profileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	update := usecase.ProfileUpdate{DisplayName: req.DisplayName, Timezone: req.Timezone}
+	if req.Notifications != nil {
+		prefs := req.Notifications.preferences()
+		update.Notifications = &prefs
+	}
+
+	teacher, err := h.profiles.UpdateTeacherProfile(r.Context(), teacherID, update)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toProfileResponse(*teacher))
+}
+
+func toProfileResponse(teacher domain.Teacher) profileResponse {
+	return profileResponse{
+		TeacherID:   string(teacher.ID),
+		Name:        teacher.Name,
+		DisplayName: teacher.DisplayName,
+		Email:       teacher.Email,
+		Timezone:    teacher.Timezone,
+		Notifications: notificationPreferences{
+			Delivery:       string(teacher.Notifications.Delivery),
+			TestAssigned:   !teacher.Notifications.MuteTestAssigned,
+			ResultReleased: !teacher.Notifications.MuteResultReleased,
+		},
+	}
+}
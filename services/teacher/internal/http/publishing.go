@@ -0,0 +1,96 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+type questionEditRequest struct {
+	Prompt       *string                       `json:"prompt"`
+	Points       *int                          `json:"points"`
+	Translations map[string]translationPayload `json:"translations"`
+}
+
+type questionOrderRequest struct {
+	QuestionIDs []string `json:"question_ids"`
+}
+
+func (h *Handler) setPublished(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID, published bool) {
+	var (
+		test *domain.Test
+		err  error
+	)
+	if published {
+		test, err = h.assessments.PublishTest(r.Context(), teacherID, testID)
+	} else {
+		test, err = h.assessments.UnpublishTest(r.Context(), teacherID, testID)
+	}
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	questions, err := h.assessments.GetQuestionsForTeacher(r.Context(), teacherID, testID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toTestResponse(*test, questions, locationOf(r)))
+}
+
+func (h *Handler) updateQuestion(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID, questionID domain.QuestionID) {
+	var req questionEditRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	edit := usecase.QuestionEdit{
+		Points:       (*domain.Points)(req.Points),
+		Translations: toDomainTranslations(req.Translations),
+	}
+	if req.Prompt != nil {
+		prompt := strings.TrimSpace(*req.Prompt)
+		edit.Prompt = &prompt
+	}
+	q, err := h.assessments.UpdateQuestion(r.Context(), teacherID, testID, questionID, edit)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toQuestionResponse(*q))
+}
+
+// reorderQuestions puts the questions of a draft test in the order of
+// question_ids, which must name each of them once.
+func (h *Handler) reorderQuestions(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	var req questionOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	order := make([]domain.QuestionID, len(req.QuestionIDs))
+	for i, questionID := range req.QuestionIDs {
+		order[i] = domain.QuestionID(strings.TrimSpace(questionID))
+	}
+	questions, err := h.assessments.ReorderQuestions(r.Context(), teacherID, testID, order)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	resp := make([]questionResponse, len(questions))
+	for i, q := range questions {
+		resp[i] = toQuestionResponse(q)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"test_id":   string(testID),
+		"questions": resp,
+	})
+}
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+// maxQuickImportBytes caps the size of a quick import document.
+const maxQuickImportBytes = 1 << 20
+
+type quickImportPreviewResponse struct {
+	Title     string             `json:"title"`
+	Questions []questionResponse `json:"questions"`
+}
+
+// quickImportTest creates a draft test from a plain-text or Markdown document
+// in the request body. With preview=true the parsed questions are returned
+// instead, so teachers can check the document before importing it.
+func (h *Handler) quickImportTest(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxQuickImportBytes))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, "quick import document is too large")
+		return
+	}
+	title := r.URL.Query().Get("title")
+
+	if preview, _ := strconv.ParseBool(r.URL.Query().Get("preview")); preview {
+		parsed, err := usecase.ParseQuickImport(string(body))
+		if err != nil {
+			handleServiceError(w, err)
+			return
+		}
+		if title == "" {
+			title = parsed.Title
+		}
+		resp := quickImportPreviewResponse{Title: title, Questions: make([]questionResponse, len(parsed.Questions))}
+		for i, d := range parsed.Questions {
+			resp.Questions[i] = toQuestionResponse(domain.Question{
+				Sequence:         i + 1,
+				Prompt:           d.Prompt,
+				Points:           d.Points,
+				Type:             d.Type,
+				Choices:          d.Choices,
+				ExpectedResponse: d.ExpectedResponse,
+			})
+		}
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	test, questions, err := h.assessments.QuickImportTest(r.Context(), teacherID, title, string(body))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, toTestResponse(*test, questions, locationOf(r)))
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+type graderReportResponse struct {
+	TestID    string                      `json:"test_id"`
+	Agreement *float64                    `json:"agreement"`
+	Graders   []graderSeverityResponse    `json:"graders"`
+	Questions []questionAgreementResponse `json:"questions"`
+}
+
+type graderSeverityResponse struct {
+	TeacherID    string  `json:"teacher_id"`
+	Graded       int     `json:"graded"`
+	MeanPercent  float64 `json:"mean_percent"`
+	Severity     float64 `json:"severity"`
+	Inconsistent bool    `json:"inconsistent"`
+}
+
+type questionAgreementResponse struct {
+	QuestionID string  `json:"question_id"`
+	Graders    int     `json:"graders"`
+	Spread     float64 `json:"spread_percent"`
+	Agreement  float64 `json:"agreement"`
+}
+
+// graderReport reports how strictly each teacher who graded the test scored
+// and how well they agree on the questions they shared.
+func (h *Handler) graderReport(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	report, err := h.assessments.GraderReport(r.Context(), teacherID, testID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	resp := graderReportResponse{
+		TestID:    string(report.TestID),
+		Agreement: report.Agreement,
+		Graders:   make([]graderSeverityResponse, len(report.Graders)),
+		Questions: make([]questionAgreementResponse, len(report.Questions)),
+	}
+	for i, g := range report.Graders {
+		resp.Graders[i] = graderSeverityResponse{
+			TeacherID:    string(g.TeacherID),
+			Graded:       g.Graded,
+			MeanPercent:  g.MeanPercent,
+			Severity:     g.Severity,
+			Inconsistent: g.Inconsistent,
+		}
+	}
+	for i, q := range report.Questions {
+		resp.Questions[i] = questionAgreementResponse{
+			QuestionID: string(q.QuestionID),
+			Graders:    q.Graders,
+			Spread:     q.Spread,
+			Agreement:  q.Agreement,
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,130 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+// PublicResultsPrefix is the path the public results handler must be mounted
+// on. Anyone holding a results link token may read the results behind it, so
+// it is served without authentication.
+const PublicResultsPrefix = "/api/public/results/"
+
+type resultsLinkRequest struct {
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type resultsLinkResponse struct {
+	Token     string    `json:"token"`
+	Path      string    `json:"path"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func toResultsLinkResponse(link domain.ResultsLink) resultsLinkResponse {
+	return resultsLinkResponse{
+		Token:     link.Token,
+		Path:      PublicResultsPrefix + link.Token,
+		ExpiresAt: link.ExpiresAt,
+		CreatedAt: link.CreatedAt,
+	}
+}
+
+func (h *Handler) createResultsLink(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	var req resultsLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	link, err := h.assessments.CreateResultsLink(r.Context(), teacherID, testID, req.ExpiresAt)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, toResultsLinkResponse(*link))
+}
+
+func (h *Handler) getResultsLink(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	link, err := h.assessments.ResultsLink(r.Context(), teacherID, testID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toResultsLinkResponse(*link))
+}
+
+func (h *Handler) revokeResultsLink(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	if err := h.assessments.RevokeResultsLink(r.Context(), teacherID, testID); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PublicResultsHandler shows parents and other holders of a results link the
+// anonymized results of a test.
+type PublicResultsHandler struct {
+	assessment *usecase.AssessmentService
+}
+
+// NewPublicResultsHandler creates the public results handler.
+func NewPublicResultsHandler(assessment *usecase.AssessmentService) *PublicResultsHandler {
+	return &PublicResultsHandler{assessment: assessment}
+}
+
+type publicResultsResponse struct {
+	Title      string    `json:"title"`
+	Questions  int       `json:"questions"`
+	MaxScore   int       `json:"max_score"`
+	Students   int       `json:"students"`
+	Suppressed bool      `json:"suppressed"`
+	Mean       float64   `json:"mean_percent"`
+	Median     float64   `json:"median_percent"`
+	Histogram  []int     `json:"histogram"`
+	PassRate   *float64  `json:"pass_rate,omitempty"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	ComputedAt time.Time `json:"computed_at"`
+}
+
+func (h *PublicResultsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := strings.Trim(strings.TrimPrefix(r.URL.Path, PublicResultsPrefix), "/")
+	if token == "" || strings.Contains(token, "/") {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	results, err := h.assessment.PublicResults(r.Context(), token)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	histogram := results.Histogram
+	if histogram == nil {
+		histogram = []int{}
+	}
+	// Links are revocable, so shared caches must not keep serving them.
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, publicResultsResponse{
+		Title:      results.Title,
+		Questions:  results.Questions,
+		MaxScore:   int(results.MaxScore),
+		Students:   results.Students,
+		Suppressed: results.Suppressed,
+		Mean:       results.Mean,
+		Median:     results.Median,
+		Histogram:  histogram,
+		PassRate:   results.PassRate,
+		ExpiresAt:  results.ExpiresAt,
+		ComputedAt: results.ComputedAt,
+	})
+}
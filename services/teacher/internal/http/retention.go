@@ -0,0 +1,84 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+// RetentionAdminPrefix is the path the retention admin handler must be
+// mounted on.
+const RetentionAdminPrefix = "/api/admin/retention/"
+
+// RetentionAdminHandler lets administrators preview the records the schools'
+// retention policies will erase and apply them ahead of the scheduled job.
+type RetentionAdminHandler struct {
+	assessment *usecase.AssessmentService
+}
+
+// NewRetentionAdminHandler constructs the retention admin handler.
+func NewRetentionAdminHandler(assessment *usecase.AssessmentService) *RetentionAdminHandler {
+	return &RetentionAdminHandler{assessment: assessment}
+}
+
+type retentionReportResponse struct {
+	GeneratedAt time.Time                 `json:"generated_at"`
+	DryRun      bool                      `json:"dry_run"`
+	Schools     []schoolRetentionResponse `json:"schools"`
+}
+
+type schoolRetentionResponse struct {
+	SchoolID       string `json:"school_id"`
+	AnswerYears    int    `json:"answer_years"`
+	ResultYears    int    `json:"result_years"`
+	AnswersPurged  int    `json:"answers_purged"`
+	AnswersDeleted int    `json:"answers_deleted"`
+	ResultsDeleted int    `json:"results_deleted"`
+}
+
+// ServeHTTP serves GET .../preview, which reports what a pass would erase
+// now, and POST .../run, which applies the policies.
+func (h *RetentionAdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	action := strings.Trim(strings.TrimPrefix(r.URL.Path, RetentionAdminPrefix), "/")
+
+	var report *usecase.RetentionReport
+	var err error
+	switch {
+	case action == "preview" && r.Method == http.MethodGet:
+		report, err = h.assessment.PreviewRetention(r.Context(), time.Now().UTC())
+	case action == "run" && r.Method == http.MethodPost:
+		report, err = h.assessment.ApplyRetention(r.Context(), time.Now().UTC())
+	case action == "preview" || action == "run":
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, toRetentionReportResponse(report))
+}
+
+func toRetentionReportResponse(report *usecase.RetentionReport) retentionReportResponse {
+	schools := make([]schoolRetentionResponse, len(report.Schools))
+	for i, s := range report.Schools {
+		schools[i] = schoolRetentionResponse{
+			SchoolID:       string(s.SchoolID),
+			AnswerYears:    s.Policy.AnswerYears,
+			ResultYears:    s.Policy.ResultYears,
+			AnswersPurged:  s.AnswersPurged,
+			AnswersDeleted: s.AnswersDeleted,
+			ResultsDeleted: s.ResultsDeleted,
+		}
+	}
+	return retentionReportResponse{
+		GeneratedAt: report.GeneratedAt,
+		DryRun:      report.DryRun,
+		Schools:     schools,
+	}
+}
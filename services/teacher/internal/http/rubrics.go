@@ -0,0 +1,221 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/export"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+type rubricRequest struct {
+	Title    string `json:"title"`
+	Criteria []struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Points      int    `json:"points"`
+	} `json:"criteria"`
+}
+
+type rubricResponse struct {
+	RubricID  string              `json:"rubric_id"`
+	Title     string              `json:"title"`
+	Criteria  []criterionResponse `json:"criteria"`
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
+type criterionResponse struct {
+	CriterionID string `json:"criterion_id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Points      int    `json:"points"`
+}
+
+type feedbackTemplateRequest struct {
+	RubricID    string `json:"rubric_id"`
+	CriterionID string `json:"criterion_id"`
+	Title       string `json:"title"`
+	Body        string `json:"body"`
+}
+
+type feedbackTemplateResponse struct {
+	TemplateID  string    `json:"template_id"`
+	RubricID    string    `json:"rubric_id,omitempty"`
+	CriterionID string    `json:"criterion_id,omitempty"`
+	Title       string    `json:"title"`
+	Body        string    `json:"body"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (h *Handler) createRubric(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID) {
+	var req rubricRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	input := usecase.RubricInput{TeacherID: teacherID, Title: req.Title}
+	for _, c := range req.Criteria {
+		input.Criteria = append(input.Criteria, usecase.CriterionDraft{
+			Title:       c.Title,
+			Description: c.Description,
+			Points:      domain.Points(c.Points),
+		})
+	}
+
+	rubric, err := h.rubrics.CreateRubric(r.Context(), input)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, toRubricResponse(*rubric))
+}
+
+func (h *Handler) listRubrics(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID) {
+	page, err := parsePageRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	rubrics, err := h.rubrics.ListRubrics(r.Context(), teacherID, page)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	payload := make([]rubricResponse, len(rubrics.Items))
+	for i, rubric := range rubrics.Items {
+		payload[i] = toRubricResponse(rubric)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"rubrics": payload,
+		"page":    toPageInfo(page, rubrics.NextCursor),
+	})
+}
+
+func (h *Handler) createFeedbackTemplate(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID) {
+	var req feedbackTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	tmpl, err := h.rubrics.CreateFeedbackTemplate(r.Context(), usecase.FeedbackTemplateInput{
+		TeacherID:   teacherID,
+		RubricID:    domain.RubricID(req.RubricID),
+		CriterionID: domain.CriterionID(req.CriterionID),
+		Title:       req.Title,
+		Body:        req.Body,
+	})
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, toFeedbackTemplateResponse(*tmpl))
+}
+
+func (h *Handler) listFeedbackTemplates(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID) {
+	page, err := parsePageRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	templates, err := h.rubrics.ListFeedbackTemplates(r.Context(), teacherID, page)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	payload := make([]feedbackTemplateResponse, len(templates.Items))
+	for i, tmpl := range templates.Items {
+		payload[i] = toFeedbackTemplateResponse(tmpl)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"feedback_templates": payload,
+		"page":               toPageInfo(page, templates.NextCursor),
+	})
+}
+
+func (h *Handler) exportRubrics(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID) {
+	bank, err := h.rubrics.ExportBank(r.Context(), teacherID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="rubrics-`+string(teacherID)+`.json"`)
+	w.WriteHeader(http.StatusOK)
+	_ = export.WriteRubricBank(w, *bank)
+}
+
+func (h *Handler) importRubrics(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID) {
+	bank, err := export.ReadRubricBank(r.Body)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	imported, err := h.rubrics.ImportBank(r.Context(), teacherID, *bank)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	rubricIDs := make(map[string]string, len(imported.RubricIDs))
+	for from, to := range imported.RubricIDs {
+		rubricIDs[from] = string(to)
+	}
+	templateIDs := make(map[string]string, len(imported.TemplateIDs))
+	for from, to := range imported.TemplateIDs {
+		templateIDs[from] = string(to)
+	}
+	rubrics := make([]rubricResponse, len(imported.Rubrics))
+	for i, rubric := range imported.Rubrics {
+		rubrics[i] = toRubricResponse(rubric)
+	}
+	templates := make([]feedbackTemplateResponse, len(imported.Templates))
+	for i, tmpl := range imported.Templates {
+		templates[i] = toFeedbackTemplateResponse(tmpl)
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"rubrics":            rubrics,
+		"feedback_templates": templates,
+		"rubric_ids":         rubricIDs,
+		"template_ids":       templateIDs,
+	})
+}
+
+func toRubricResponse(rubric domain.Rubric) rubricResponse {
+	resp := rubricResponse{
+		RubricID:  string(rubric.ID),
+		Title:     rubric.Title,
+		Criteria:  make([]criterionResponse, len(rubric.Criteria)),
+		CreatedAt: rubric.CreatedAt,
+		UpdatedAt: rubric.UpdatedAt,
+	}
+	for i, c := range rubric.Criteria {
+		resp.Criteria[i] = criterionResponse{
+			CriterionID: string(c.ID),
+			Title:       c.Title,
+			Description: c.Description,
+			Points:      int(c.Points),
+		}
+	}
+	return resp
+}
+
+func toFeedbackTemplateResponse(tmpl domain.FeedbackTemplate) feedbackTemplateResponse {
+	return feedbackTemplateResponse{
+		TemplateID:  string(tmpl.ID),
+		RubricID:    string(tmpl.RubricID),
+		CriterionID: string(tmpl.CriterionID),
+		Title:       tmpl.Title,
+		Body:        tmpl.Body,
+		CreatedAt:   tmpl.CreatedAt,
+	}
+}
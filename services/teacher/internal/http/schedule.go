@@ -0,0 +1,106 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/usecase"
+)
+
+type scheduleRequest struct {
+	OpensAt  *time.Time `json:"opens_at"`
+	ClosesAt *time.Time `json:"closes_at"`
+}
+
+type scheduleResponse struct {
+	TestID    string                     `json:"test_id"`
+	OpensAt   *time.Time                 `json:"opens_at,omitempty"`
+	ClosesAt  *time.Time                 `json:"closes_at,omitempty"`
+	Conflicts []scheduleConflictResponse `json:"conflicts"`
+}
+
+type scheduleConflictResponse struct {
+	Kind      string                  `json:"kind"`
+	StudentID string                  `json:"student_id"`
+	Day       string                  `json:"day,omitempty"`
+	Tests     []scheduledTestResponse `json:"tests"`
+}
+
+type scheduledTestResponse struct {
+	TestID    string    `json:"test_id"`
+	TeacherID string    `json:"teacher_id"`
+	Title     string    `json:"title"`
+	OpensAt   time.Time `json:"opens_at"`
+	ClosesAt  time.Time `json:"closes_at"`
+}
+
+// getSchedule reports a test's window and the exam-schedule conflicts of its
+// students as of now, since other teachers may have scheduled tests since.
+func (h *Handler) getSchedule(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	test, err := h.assessments.GetTestForTeacher(r.Context(), teacherID, testID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	h.writeSchedule(w, r, *test)
+}
+
+// setSchedule sets the test's window and answers with the conflicts it
+// causes; conflicts are warnings and do not stop the change.
+func (h *Handler) setSchedule(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	var req scheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+
+	test, err := h.assessments.SetTestWindow(r.Context(), teacherID, testID, req.OpensAt, req.ClosesAt)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	h.writeSchedule(w, r, *test)
+}
+
+func (h *Handler) writeSchedule(w http.ResponseWriter, r *http.Request, test domain.Test) {
+	conflicts, err := h.assessments.ScheduleConflicts(r.Context(), test.TeacherID, test.ID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	loc := locationOf(r)
+	writeJSON(w, http.StatusOK, scheduleResponse{
+		TestID:    string(test.ID),
+		OpensAt:   localTimePtr(test.OpensAt, loc),
+		ClosesAt:  localTimePtr(test.ClosesAt, loc),
+		Conflicts: toScheduleConflictResponses(conflicts, loc),
+	})
+}
+
+func toScheduleConflictResponses(conflicts []usecase.ScheduleConflict, loc *time.Location) []scheduleConflictResponse {
+	out := make([]scheduleConflictResponse, len(conflicts))
+	for i, c := range conflicts {
+		resp := scheduleConflictResponse{
+			Kind:      string(c.Kind),
+			StudentID: string(c.StudentID),
+			Tests:     make([]scheduledTestResponse, len(c.Others)),
+		}
+		if !c.Day.IsZero() {
+			resp.Day = c.Day.Format(time.DateOnly)
+		}
+		for j, other := range c.Others {
+			opens, closes, _ := other.Window()
+			resp.Tests[j] = scheduledTestResponse{
+				TestID:    string(other.ID),
+				TeacherID: string(other.TeacherID),
+				Title:     other.Title,
+				OpensAt:   localTime(opens, loc),
+				ClosesAt:  localTime(closes, loc),
+			}
+		}
+		out[i] = resp
+	}
+	return out
+}
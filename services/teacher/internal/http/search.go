@@ -0,0 +1,69 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/search"
+)
+
+type searchHitResponse struct {
+	Kind       string    `json:"kind"`
+	TestID     string    `json:"test_id"`
+	TestTitle  string    `json:"test_title"`
+	QuestionID string    `json:"question_id"`
+	StudentID  string    `json:"student_id,omitempty"`
+	Text       string    `json:"text"`
+	Score      float64   `json:"score"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// searchTests serves GET /api/teachers/{id}/search?q=, matching every word
+// of q in the teacher's questions, their students' answers and the feedback
+// on them. ?kind= narrows the hits to question, answer or feedback.
+func (h *Handler) searchTests(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID) {
+	query := r.URL.Query()
+	kind := search.Kind(query.Get("kind"))
+	switch kind {
+	case "", search.KindQuestion, search.KindAnswer, search.KindFeedback:
+	default:
+		writeError(w, http.StatusBadRequest, "kind must be question, answer or feedback")
+		return
+	}
+	limit := search.DefaultLimit
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > search.MaxLimit {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", search.MaxLimit))
+			return
+		}
+		limit = parsed
+	}
+
+	hits, err := h.search.Search(r.Context(), teacherID, query.Get("q"), kind, limit)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	resp := make([]searchHitResponse, len(hits))
+	for i, hit := range hits {
+		resp[i] = searchHitResponse{
+			Kind:       string(hit.Kind),
+			TestID:     string(hit.TestID),
+			TestTitle:  hit.TestTitle,
+			QuestionID: string(hit.QuestionID),
+			StudentID:  string(hit.StudentID),
+			Text:       hit.Text,
+			Score:      hit.Score,
+			UpdatedAt:  hit.UpdatedAt,
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"query": query.Get("q"),
+		"hits":  resp,
+	})
+}
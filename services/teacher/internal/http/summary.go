@@ -0,0 +1,70 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+)
+
+type studentSummaryResponse struct {
+	StudentID string  `json:"student_id"`
+	Score     int     `json:"score"`
+	Percent   float64 `json:"percent"`
+	Answered  int     `json:"answered"`
+	Graded    int     `json:"graded"`
+	Status    string  `json:"status"`
+	Passed    *bool   `json:"passed"`
+}
+
+type scoreDistributionResponse struct {
+	Students int     `json:"students"`
+	Min      int     `json:"min"`
+	Max      int     `json:"max"`
+	Mean     float64 `json:"mean"`
+	Median   float64 `json:"median"`
+}
+
+type testSummaryResponse struct {
+	TestID     string                    `json:"test_id"`
+	Questions  int                       `json:"questions"`
+	MaxScore   int                       `json:"max_score"`
+	Students   []studentSummaryResponse  `json:"students"`
+	Scores     scoreDistributionResponse `json:"scores"`
+	ComputedAt time.Time                 `json:"computed_at"`
+}
+
+func (h *Handler) getSummary(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, testID domain.TestID) {
+	summary, err := h.assessments.TestSummary(r.Context(), teacherID, testID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	resp := testSummaryResponse{
+		TestID:    string(summary.TestID),
+		Questions: summary.Questions,
+		MaxScore:  int(summary.MaxScore),
+		Students:  make([]studentSummaryResponse, len(summary.Students)),
+		Scores: scoreDistributionResponse{
+			Students: summary.Scores.Students,
+			Min:      int(summary.Scores.Min),
+			Max:      int(summary.Scores.Max),
+			Mean:     summary.Scores.Mean,
+			Median:   summary.Scores.Median,
+		},
+		ComputedAt: summary.ComputedAt,
+	}
+	for i, st := range summary.Students {
+		resp.Students[i] = studentSummaryResponse{
+			StudentID: string(st.StudentID),
+			Score:     int(st.Score),
+			Percent:   st.Percent(),
+			Answered:  st.Answered,
+			Graded:    st.Graded,
+			Status:    string(st.Status),
+			Passed:    st.Passed,
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,173 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sky0621/go_work_sample/core/pkg/domain"
+	"github.com/sky0621/go_work_sample/core/pkg/errs"
+	"github.com/sky0621/go_work_sample/core/pkg/upload"
+)
+
+// partChecksumHeader optionally carries the hex SHA-256 of an uploaded part.
+const partChecksumHeader = "X-Part-SHA256"
+
+type uploadInitRequest struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256"`
+}
+
+type uploadResponse struct {
+	UploadID      string     `json:"upload_id"`
+	Filename      string     `json:"filename"`
+	ContentType   string     `json:"content_type"`
+	Size          int64      `json:"size"`
+	SHA256        string     `json:"sha256"`
+	PartSize      int64      `json:"part_size"`
+	PartCount     int        `json:"part_count"`
+	ReceivedParts []int      `json:"received_parts"`
+	MissingParts  []int      `json:"missing_parts"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+}
+
+// routeUploads serves /api/teachers/{id}/uploads: POST starts an upload,
+// PUT {uploadID}/parts/{n} sends a part, POST {uploadID}/complete assembles
+// the file, GET {uploadID} reports progress so an interrupted client knows
+// which parts to send again, and DELETE {uploadID} discards it.
+func (h *Handler) routeUploads(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, parts []string) {
+	switch {
+	case len(parts) == 2 && r.Method == http.MethodPost:
+		h.initUpload(w, r, teacherID)
+	case len(parts) == 3 && r.Method == http.MethodGet:
+		session, err := h.uploads.Get(r.Context(), string(teacherID), parts[2])
+		if err != nil {
+			handleUploadError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, toUploadResponse(*session))
+	case len(parts) == 3 && r.Method == http.MethodDelete:
+		if err := h.uploads.Abort(r.Context(), string(teacherID), parts[2]); err != nil {
+			handleUploadError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case len(parts) == 5 && parts[3] == "parts" && r.Method == http.MethodPut:
+		h.putUploadPart(w, r, teacherID, parts[2], parts[4])
+	case len(parts) == 4 && parts[3] == "complete" && r.Method == http.MethodPost:
+		session, err := h.uploads.Complete(r.Context(), string(teacherID), parts[2])
+		if err != nil {
+			handleUploadError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, toUploadResponse(*session))
+	case len(parts) == 4 && parts[3] == "content" && r.Method == http.MethodGet:
+		h.downloadUpload(w, r, teacherID, parts[2])
+	case len(parts) <= 3, len(parts) == 4 && (parts[3] == "complete" || parts[3] == "content"), len(parts) == 5 && parts[3] == "parts":
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (h *Handler) initUpload(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID) {
+	var req uploadInitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+	session, err := h.uploads.Init(r.Context(), string(teacherID), upload.InitInput{
+		Filename:    req.Filename,
+		ContentType: req.ContentType,
+		Size:        req.Size,
+		SHA256:      req.SHA256,
+	})
+	if err != nil {
+		handleUploadError(w, err)
+		return
+	}
+	w.Header().Set("Location", "/api/teachers/"+string(teacherID)+"/uploads/"+session.ID)
+	writeJSON(w, http.StatusCreated, toUploadResponse(*session))
+}
+
+// putUploadPart streams the request body into part n; it is never held in
+// memory as a whole.
+func (h *Handler) putUploadPart(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, uploadID, rawPart string) {
+	n, err := strconv.Atoi(rawPart)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "part must be a number")
+		return
+	}
+	session, err := h.uploads.PutPart(r.Context(), string(teacherID), uploadID, n, r.Body, r.Header.Get(partChecksumHeader))
+	if err != nil {
+		handleUploadError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toUploadResponse(*session))
+}
+
+func (h *Handler) downloadUpload(w http.ResponseWriter, r *http.Request, teacherID domain.TeacherID, uploadID string) {
+	session, body, err := h.uploads.Open(r.Context(), string(teacherID), uploadID)
+	if err != nil {
+		handleUploadError(w, err)
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Type", session.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(session.Size, 10))
+	w.Header().Set("Content-Disposition", `attachment; filename="`+session.Filename+`"`)
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.Copy(w, body)
+}
+
+// handleUploadError maps the upload manager's errors, which carry details
+// wrapped around the sentinel, to statuses.
+func handleUploadError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errs.ErrUploadNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, errs.ErrInvalidUpload):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, errs.ErrUploadIncomplete):
+		writeError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, errs.ErrChecksumMismatch):
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+	default:
+		handleServiceError(w, err)
+	}
+}
+
+func toUploadResponse(session upload.Session) uploadResponse {
+	missing := session.Missing()
+	received := make([]int, 0, len(session.Parts))
+	for n := 1; n <= session.PartCount(); n++ {
+		if _, ok := session.Parts[n]; ok {
+			received = append(received, n)
+		}
+	}
+	if missing == nil {
+		missing = []int{}
+	}
+	return uploadResponse{
+		UploadID:      session.ID,
+		Filename:      session.Filename,
+		ContentType:   session.ContentType,
+		Size:          session.Size,
+		SHA256:        session.SHA256,
+		PartSize:      session.PartSize,
+		PartCount:     session.PartCount(),
+		ReceivedParts: received,
+		MissingParts:  missing,
+		CreatedAt:     session.CreatedAt,
+		ExpiresAt:     session.ExpiresAt,
+		CompletedAt:   session.CompletedAt,
+	}
+}
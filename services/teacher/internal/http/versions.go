@@ -0,0 +1,35 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// etag is the entity tag of a test or result version.
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// requireIfMatch reads the version an update was made from, which clients
+// send back as If-Match: the ETag or version of the copy they edited, "0"
+// for an answer not graded yet. "*" updates whatever the current version
+// is and yields nil. A missing or malformed header is answered here and ok
+// is false.
+func requireIfMatch(w http.ResponseWriter, r *http.Request) (version *int, ok bool) {
+	raw := strings.TrimSpace(r.Header.Get("If-Match"))
+	if raw == "" {
+		writeError(w, http.StatusPreconditionRequired, "If-Match header with the version being updated is required")
+		return nil, false
+	}
+	if raw == "*" {
+		return nil, true
+	}
+	raw = strings.Trim(strings.TrimPrefix(raw, "W/"), `"`)
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		writeError(w, http.StatusBadRequest, "If-Match must be a single version such as \"3\"")
+		return nil, false
+	}
+	return &n, true
+}